@@ -0,0 +1,103 @@
+package gorgonia
+
+import "sync"
+
+// adjacency holds the parents of the nodes of a graph. The nodes of a graph are numbered from 0 by the graph, so the
+// parents are held in a slice indexed by the IDs rather than in a map keyed by the pointers, which keeps the graphs of
+// hundreds of thousands of nodes compact and cheap to scan for the GC. The nodes whose IDs are not of the graph (such as
+// the nodes that were never added to it, or a node of another graph of the same ID) are held in a map.
+type adjacency struct {
+	byID   []adjEntry
+	others map[*Node]Nodes
+}
+
+type adjEntry struct {
+	n       *Node
+	parents Nodes
+}
+
+func newAdjacency() *adjacency { return new(adjacency) }
+
+// get returns the parents of the node.
+func (a *adjacency) get(n *Node) Nodes {
+	ps, _ := a.lookup(n)
+	return ps
+}
+
+// lookup returns the parents of the node, and whether the node is held at all.
+func (a *adjacency) lookup(n *Node) (Nodes, bool) {
+	if n.id >= 0 && n.id < int64(len(a.byID)) && a.byID[n.id].n == n {
+		return a.byID[n.id].parents, true
+	}
+	ps, ok := a.others[n]
+	return ps, ok
+}
+
+// set sets the parents of the node.
+func (a *adjacency) set(n *Node, parents Nodes) {
+	if n.id >= 0 && n.id < maxAdjacencyID {
+		if n.id >= int64(len(a.byID)) {
+			size := 2 * len(a.byID)
+			if size <= int(n.id) {
+				size = int(n.id) + 1
+			}
+			byID := make([]adjEntry, len(a.byID), size)
+			copy(byID, a.byID)
+			a.byID = byID[:n.id+1]
+		}
+		if e := &a.byID[n.id]; e.n == nil || e.n == n {
+			e.n, e.parents = n, parents
+			return
+		}
+	}
+	if a.others == nil {
+		a.others = make(map[*Node]Nodes)
+	}
+	a.others[n] = parents
+}
+
+// del removes the node.
+func (a *adjacency) del(n *Node) {
+	if n.id >= 0 && n.id < int64(len(a.byID)) && a.byID[n.id].n == n {
+		a.byID[n.id] = adjEntry{}
+		return
+	}
+	delete(a.others, n)
+}
+
+// each calls fn with every node held and its parents: first the nodes of the graph, in the order of their IDs.
+func (a *adjacency) each(fn func(n *Node, parents Nodes)) {
+	for _, e := range a.byID {
+		if e.n != nil {
+			fn(e.n, e.parents)
+		}
+	}
+	for n, ps := range a.others {
+		fn(n, ps)
+	}
+}
+
+// maxAdjacencyID bounds the slice of the adjacency, in case of a node of a bogus ID.
+const maxAdjacencyID = 1 << 31
+
+// nodeSlabSize is the number of nodes allocated at once by allocNode.
+const nodeSlabSize = 256
+
+// nodeSlab is the arena the new nodes are carved from: allocating them by slabs rather than one by one makes the
+// construction of large graphs allocate far less often. A slab is only freed once all of its nodes are unreachable,
+// but its nodes are recycled through the pool of the nodes anyway.
+var nodeSlab struct {
+	sync.Mutex
+	free []Node
+}
+
+func allocNode() *Node {
+	nodeSlab.Lock()
+	if len(nodeSlab.free) == 0 {
+		nodeSlab.free = make([]Node, nodeSlabSize)
+	}
+	n := &nodeSlab.free[0]
+	nodeSlab.free = nodeSlab.free[1:]
+	nodeSlab.Unlock()
+	return n
+}
@@ -0,0 +1,52 @@
+package gorgonia
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdjacency(t *testing.T) {
+	assert := assert.New(t)
+	a := newAdjacency()
+	x, y, z := &Node{id: 0}, &Node{id: 5}, &Node{id: -1}
+	other := &Node{id: 5} // a node of another graph
+	a.set(x, Nodes{y})
+	a.set(y, nil)
+	a.set(z, Nodes{x})
+	a.set(other, Nodes{z})
+
+	assert.Equal(Nodes{y}, a.get(x))
+	assert.Equal(Nodes{x}, a.get(z))
+	assert.Equal(Nodes{z}, a.get(other))
+	ps, ok := a.lookup(y)
+	assert.True(ok)
+	assert.Nil(ps)
+	_, ok = a.lookup(&Node{id: 1})
+	assert.False(ok)
+
+	var held Nodes
+	a.each(func(n *Node, _ Nodes) { held = append(held, n) })
+	assert.Len(held, 4)
+	assert.True(held[0] == x && held[1] == y)
+
+	a.del(y)
+	a.del(other)
+	_, ok = a.lookup(y)
+	assert.False(ok)
+	assert.Nil(a.get(other))
+}
+
+func TestCloneAddNode(t *testing.T) {
+	g := NewGraph()
+	x := NewScalar(g, Float64, WithName("x"))
+	Must(Exp(x))
+	g2 := g.Clone().(*ExprGraph)
+	x2 := g2.ByName("x")[0]
+
+	// the nodes added to the clone do not take the IDs of the cloned nodes
+	y2 := Must(Neg(x2))
+	assert.NotEqual(t, x2.ID(), y2.ID())
+	assert.True(t, g2.to.get(x2).Contains(y2))
+	assert.Len(t, g2.to.get(x2), 2)
+}
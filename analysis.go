@@ -46,31 +46,13 @@ func (df *dataflow) vn(n *Node) (retVal *Node, unique bool) {
 	return n, true
 }
 
-// analyzeDevice records which node is supposed to be executed on which device.
-//
-// Currently it will only use Device 0. In the future, we can be smart about which device to use
-func (df *dataflow) analyzeDevice(n *Node) {
-	switch n.op.(type) {
-	case CUDADoer:
-		if n.dataOn == CPU {
-			n.dataOn = Device(0)
-		}
-	case CLDoer:
-		if n.dataOn == CPU {
-			n.dataOn = Device(0)
-		}
-	default:
-		n.dataOn = CPU
-	}
-}
-
 // replaceWithSelf fills the replacement map with itself. This is the method used in the lispMachine only, as it skips value numbering
 func (df *dataflow) replaceWithSelf(sorted Nodes) {
 	df.replacements = make(map[*Node]*Node)
 	for _, n := range sorted {
 		df.replacements[n] = n
-		df.analyzeDevice(n) // Device Targeting
 	}
+	df.placeDevices(sorted) // Device Targeting
 }
 
 // fixIntervalDevices is used only by the lispMachine. It fixes the intervals to have the correct devices
@@ -97,8 +79,8 @@ func analyze(g *ExprGraph, sorted Nodes) *dataflow {
 	for _, n := range sorted {
 		r, _ := df.vn(n)
 		replacements[n] = r // CSE
-		df.analyzeDevice(n) // Device targeting
 	}
+	df.placeDevices(sorted) // Device targeting
 	df.replacements = replacements
 	compileLogf("replacements: %-p", FmtNodeMap(replacements))
 
@@ -126,6 +108,7 @@ func newDevTransNode(read, write *Node, from, to Device) *Node {
 	n := borrowNode()
 	n.id = -1
 	n.op = op
+	n.dataOn = to
 	n.shape = read.shape.Clone()
 	n.t = read.t
 	n.isStmt = true
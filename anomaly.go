@@ -0,0 +1,82 @@
+package gorgonia
+
+import (
+	"bytes"
+	"fmt"
+	"sync/atomic"
+)
+
+/*
+This file holds the anomaly detection mode of the backward pass. In this mode, every node records where it was
+constructed, and every node created by the symbolic differentiation records the forward node it differentiates. When
+a NaN or an infinity is computed, the error of the machine then tells which line of the program built the forward op
+whose gradient failed:
+
+		gorgonia.SetDetectAnomaly(true)
+		// build the graph and its gradients
+		m := gorgonia.NewTapeMachine(g)
+		if err := m.RunAll(); err != nil {
+			var anomaly *gorgonia.AnomalyError
+			if errors.As(err, &anomaly) { ... }
+		}
+
+The machines check the values of every op for NaNs and infinities in this mode, as with WithNaNWatch and WithInfWatch.
+The mode slows down the construction of the graphs and their executions, and is meant for debugging.
+*/
+
+var anomalyMode int32
+
+// SetDetectAnomaly turns the anomaly detection mode on or off. Only the nodes created while it is on record their
+// construction, as with WithConstructionStacks.
+func SetDetectAnomaly(on bool) {
+	var v int32
+	if on {
+		v = 1
+	}
+	atomic.StoreInt32(&anomalyMode, v)
+}
+
+func detectAnomaly() bool { return atomic.LoadInt32(&anomalyMode) == 1 }
+
+// BackwardOf returns the forward node whose differentiation created the node, if the node was created by Grad or
+// Backpropagate in the anomaly detection mode. It returns nil otherwise.
+func (n *Node) BackwardOf() *Node { return n.backwardOf }
+
+// AnomalyError is the error of a machine that computed a NaN or an infinity in the anomaly detection mode.
+type AnomalyError struct {
+	Node    *Node  // the node whose value is not finite
+	Forward *Node  // the forward node whose gradient Node computes, or nil if Node is a forward node
+	Kind    string // "NaN" or "Inf"
+}
+
+func (err *AnomalyError) Error() string {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%s found in value. Node: %v(%x)", err.Kind, err.Node, err.Node.ID())
+	origin := err.Node
+	if err.Forward != nil {
+		fmt.Fprintf(&buf, ", in the backward pass of %v(%x)", err.Forward, err.Forward.ID())
+		origin = err.Forward
+	}
+	if stack := origin.ConstructionStack(); stack != "" {
+		fmt.Fprintf(&buf, ", which was constructed at:\n%s", stack)
+	}
+	return buf.String()
+}
+
+// checkAnomaly returns an *AnomalyError if the value of the node, computed in the backward pass of forward if it is
+// not nil, is not finite.
+func checkAnomaly(n, forward *Node, v Value, dev Device) error {
+	if dv, ok := v.(*dualValue); ok {
+		v = dv.Value
+	}
+	kind := ""
+	switch {
+	case hasNaN(v, dev):
+		kind = "NaN"
+	case hasInf(v, dev):
+		kind = "Inf"
+	default:
+		return nil
+	}
+	return &AnomalyError{Node: n, Forward: forward, Kind: kind}
+}
@@ -0,0 +1,59 @@
+package gorgonia
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectAnomaly(t *testing.T) {
+	SetDetectAnomaly(true)
+	defer SetDetectAnomaly(false)
+
+	machines := map[string]func(*ExprGraph) VM{
+		"tape": func(g *ExprGraph) VM { return NewTapeMachine(g) },
+		"lisp": func(g *ExprGraph) VM { return NewLispMachine(g) },
+	}
+	for name, machine := range machines {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+			g := NewGraph()
+			x := NewScalar(g, Float64, WithName("x"), WithValue(0.0))
+			sqrt := Must(Sqrt(x)) // the gradient of the square root is infinite at 0
+			cost := Must(Mul(sqrt, NewConstant(2.0)))
+			if name == "tape" {
+				if _, err := Grad(cost, x); err != nil {
+					t.Fatal(err)
+				}
+			}
+			assert.Contains(sqrt.ConstructionStack(), "anomaly_test.go:23")
+
+			m := machine(g)
+			defer m.Close()
+			err := m.RunAll()
+			if err == nil {
+				t.Fatal("expected an anomaly")
+			}
+			anomaly, ok := errors.Cause(err).(*AnomalyError)
+			if !ok {
+				t.Fatalf("expected an *AnomalyError, got %v", err)
+			}
+			assert.Equal("Inf", anomaly.Kind)
+			assert.True(anomaly.Forward == sqrt)
+			assert.Contains(err.Error(), "anomaly_test.go:23")
+		})
+	}
+
+	// without the mode, the nodes do not record their construction
+	SetDetectAnomaly(false)
+	g := NewGraph()
+	x := NewScalar(g, Float64, WithName("x"))
+	y := Must(Sqrt(x))
+	assert.Equal(t, "", y.ConstructionStack())
+	grads, err := Grad(y, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Nil(t, grads[0].BackwardOf())
+}
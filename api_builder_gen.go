@@ -0,0 +1,170 @@
+package gorgonia
+
+// Code generated by genapi, which is a API generation tool for Gorgonia. DO NOT EDIT.
+
+import "gorgonia.org/tensor"
+
+// Abs performs a pointwise absolute value. It is the Builder version of Abs(a).
+func (b *Builder) Abs(a *Node) *Node { return b.do1(Abs, a) }
+
+// Sign performs a pointwise sign: -1 for negative values, 1 for positive values and 0 for 0. It is the Builder version of Sign(a).
+func (b *Builder) Sign(a *Node) *Node { return b.do1(Sign, a) }
+
+// Ceil performs a pointwise ceil. It is the Builder version of Ceil(a).
+func (b *Builder) Ceil(a *Node) *Node { return b.do1(Ceil, a) }
+
+// Floor performs a pointwise floor. It is the Builder version of Floor(a).
+func (b *Builder) Floor(a *Node) *Node { return b.do1(Floor, a) }
+
+// Sin performs a pointwise sin. It is the Builder version of Sin(a).
+func (b *Builder) Sin(a *Node) *Node { return b.do1(Sin, a) }
+
+// Cos performs a pointwise cos. It is the Builder version of Cos(a).
+func (b *Builder) Cos(a *Node) *Node { return b.do1(Cos, a) }
+
+// Exp performs a pointwise exp. It is the Builder version of Exp(a).
+func (b *Builder) Exp(a *Node) *Node { return b.do1(Exp, a) }
+
+// Log performs a pointwise natural log. It is the Builder version of Log(a).
+func (b *Builder) Log(a *Node) *Node { return b.do1(Log, a) }
+
+// Log2 performs a pointwise log2. It is the Builder version of Log2(a).
+func (b *Builder) Log2(a *Node) *Node { return b.do1(Log2, a) }
+
+// Neg performs a pointwise negation. It is the Builder version of Neg(a).
+func (b *Builder) Neg(a *Node) *Node { return b.do1(Neg, a) }
+
+// Square performs a pointwise square. It is the Builder version of Square(a).
+func (b *Builder) Square(a *Node) *Node { return b.do1(Square, a) }
+
+// Sqrt performs a pointwise square root. It is the Builder version of Sqrt(a).
+func (b *Builder) Sqrt(a *Node) *Node { return b.do1(Sqrt, a) }
+
+// Inverse performs a pointwise multiplicative inverse: 1/x. It is the Builder version of Inverse(a).
+func (b *Builder) Inverse(a *Node) *Node { return b.do1(Inverse, a) }
+
+// InverseSqrt performs a pointwise 1/sqrt(x). It is the Builder version of InverseSqrt(a).
+func (b *Builder) InverseSqrt(a *Node) *Node { return b.do1(InverseSqrt, a) }
+
+// Cube performs a pointwise cube. It is the Builder version of Cube(a).
+func (b *Builder) Cube(a *Node) *Node { return b.do1(Cube, a) }
+
+// Tanh performs a pointwise tanh. It is the Builder version of Tanh(a).
+func (b *Builder) Tanh(a *Node) *Node { return b.do1(Tanh, a) }
+
+// Sigmoid performs a pointwise sigmoid: 1/(1+exp(-x)). It is the Builder version of Sigmoid(a).
+func (b *Builder) Sigmoid(a *Node) *Node { return b.do1(Sigmoid, a) }
+
+// Log1p performs a pointwise log(1+x). It is the Builder version of Log1p(a).
+func (b *Builder) Log1p(a *Node) *Node { return b.do1(Log1p, a) }
+
+// Expm1 performs a pointwise exp(x)-1. It is the Builder version of Expm1(a).
+func (b *Builder) Expm1(a *Node) *Node { return b.do1(Expm1, a) }
+
+// Softplus performs a pointwise softplus: log(1+exp(x)). It is the Builder version of Softplus(a).
+func (b *Builder) Softplus(a *Node) *Node { return b.do1(Softplus, a) }
+
+// Add performs a pointwise add operation. It is the Builder version of Add(x, y).
+func (b *Builder) Add(x, y *Node) *Node { return b.do2(Add, x, y) }
+
+// Sub performs a pointwise sub operation. It is the Builder version of Sub(x, y).
+func (b *Builder) Sub(x, y *Node) *Node { return b.do2(Sub, x, y) }
+
+// HadamardProd performs a pointwise multiplication. It is the Builder version of HadamardProd(x, y).
+func (b *Builder) HadamardProd(x, y *Node) *Node { return b.do2(HadamardProd, x, y) }
+
+// HadamardDiv performs a pointwise division. It is the Builder version of HadamardDiv(x, y).
+func (b *Builder) HadamardDiv(x, y *Node) *Node { return b.do2(HadamardDiv, x, y) }
+
+// Pow performs a pointwise exponentiation. It is the Builder version of Pow(x, y).
+func (b *Builder) Pow(x, y *Node) *Node { return b.do2(Pow, x, y) }
+
+// Lt performs a pointwise less than comparison. It is the Builder version of Lt(x, y, retSame).
+func (b *Builder) Lt(x, y *Node, retSame bool) *Node { return b.doCmp(Lt, x, y, retSame) }
+
+// Gt performs a pointwise greater than comparison. It is the Builder version of Gt(x, y, retSame).
+func (b *Builder) Gt(x, y *Node, retSame bool) *Node { return b.doCmp(Gt, x, y, retSame) }
+
+// Lte performs a pointwise less than or equal comparison. It is the Builder version of Lte(x, y, retSame).
+func (b *Builder) Lte(x, y *Node, retSame bool) *Node { return b.doCmp(Lte, x, y, retSame) }
+
+// Gte performs a pointwise greater than or equal comparison. It is the Builder version of Gte(x, y, retSame).
+func (b *Builder) Gte(x, y *Node, retSame bool) *Node { return b.doCmp(Gte, x, y, retSame) }
+
+// Eq performs a pointwise equality comparison. It is the Builder version of Eq(x, y, retSame).
+func (b *Builder) Eq(x, y *Node, retSame bool) *Node { return b.doCmp(Eq, x, y, retSame) }
+
+// Ne performs a pointwise inequality comparison. It is the Builder version of Ne(x, y, retSame).
+func (b *Builder) Ne(x, y *Node, retSame bool) *Node { return b.doCmp(Ne, x, y, retSame) }
+
+// Mul performs a matrix multiplication, a matrix-vector multiplication, a vector dot product, or a scalar multiplication, depending on the shapes. It is the Builder version of Mul(x, y).
+func (b *Builder) Mul(x, y *Node) *Node { return b.do2(Mul, x, y) }
+
+// MatMul is an alias for Mul. It is the Builder version of Mul(x, y).
+func (b *Builder) MatMul(x, y *Node) *Node { return b.do2(Mul, x, y) }
+
+// Div performs a division. If b is a scalar, a broadcasted division is performed. It is the Builder version of Div(x, y).
+func (b *Builder) Div(x, y *Node) *Node { return b.do2(Div, x, y) }
+
+// OuterProd returns the outer product of two vectors. It is the Builder version of OuterProd(x, y).
+func (b *Builder) OuterProd(x, y *Node) *Node { return b.do2(OuterProd, x, y) }
+
+// Rectify applies the rectified linear unit: max(0, x). It is the Builder version of Rectify(a).
+func (b *Builder) Rectify(a *Node) *Node { return b.do1(Rectify, a) }
+
+// Relu is an alias for Rectify. It is the Builder version of Rectify(a).
+func (b *Builder) Relu(a *Node) *Node { return b.do1(Rectify, a) }
+
+// Mish applies the mish activation function. It is the Builder version of Mish(a).
+func (b *Builder) Mish(a *Node) *Node { return b.do1(Mish, a) }
+
+// SoftMax applies the softmax along the given axes (the last axis if none are given). It is the Builder version of SoftMax(a, axes...).
+func (b *Builder) SoftMax(a *Node, axes ...int) *Node { return b.doAxial(SoftMax, a, axes) }
+
+// Sum sums along the given axes. All axes are summed if none are given. It is the Builder version of Sum(a, axes...).
+func (b *Builder) Sum(a *Node, axes ...int) *Node { return b.doAxial(Sum, a, axes) }
+
+// Mean returns the mean along the given axes. The mean of all the elements is returned if no axes are given. It is the Builder version of Mean(a, axes...).
+func (b *Builder) Mean(a *Node, axes ...int) *Node { return b.doAxial(Mean, a, axes) }
+
+// Max returns the max value along the given axes. The max of all the elements is returned if no axes are given. It is the Builder version of Max(a, axes...).
+func (b *Builder) Max(a *Node, axes ...int) *Node { return b.doAxial(Max, a, axes) }
+
+// Transpose transposes the node along the given axes. The axes are reversed if none are given. It is the Builder version of Transpose(a, axes...).
+func (b *Builder) Transpose(a *Node, axes ...int) *Node { return b.doAxial(Transpose, a, axes) }
+
+// Reshape reshapes the node to the given shape. It is the Builder version of Reshape(a, s).
+func (b *Builder) Reshape(a *Node, s tensor.Shape) *Node { return b.doShape(Reshape, a, s) }
+
+// Cast converts the node to the given Dtype. It is the Builder version of Cast(a, dt).
+func (b *Builder) Cast(a *Node, dt tensor.Dtype) *Node { return b.doDtype(Cast, a, dt) }
+
+// Not performs a logical negation. It is the Builder version of Not(a).
+func (b *Builder) Not(a *Node) *Node { return b.do1(Not, a) }
+
+// And performs a logical and. It is the Builder version of And(x, y).
+func (b *Builder) And(x, y *Node) *Node { return b.do2(And, x, y) }
+
+// Or performs a logical or. It is the Builder version of Or(x, y).
+func (b *Builder) Or(x, y *Node) *Node { return b.do2(Or, x, y) }
+
+// Xor performs a logical xor. It is the Builder version of Xor(x, y).
+func (b *Builder) Xor(x, y *Node) *Node { return b.do2(Xor, x, y) }
+
+// Any returns true if any of the elements along the given axes are true. It is the Builder version of Any(a, axes...).
+func (b *Builder) Any(a *Node, axes ...int) *Node { return b.doAxial(Any, a, axes) }
+
+// All returns true if all of the elements along the given axes are true. It is the Builder version of All(a, axes...).
+func (b *Builder) All(a *Node, axes ...int) *Node { return b.doAxial(All, a, axes) }
+
+// IsNaN returns a bool node that is true where the node is NaN. It is the Builder version of IsNaN(a).
+func (b *Builder) IsNaN(a *Node) *Node { return b.do1(IsNaN, a) }
+
+// IsInf returns a bool node that is true where the node is an infinity. It is the Builder version of IsInf(a).
+func (b *Builder) IsInf(a *Node) *Node { return b.do1(IsInf, a) }
+
+// IsFinite returns a bool node that is true where the node is neither NaN nor an infinity. It is the Builder version of IsFinite(a).
+func (b *Builder) IsFinite(a *Node) *Node { return b.do1(IsFinite, a) }
+
+// StopGrad returns a node with the same value through which no gradient flows. It is the Builder version of StopGrad(a).
+func (b *Builder) StopGrad(a *Node) *Node { return b.do1(StopGrad, a) }
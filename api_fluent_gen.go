@@ -0,0 +1,170 @@
+package gorgonia
+
+// Code generated by genapi, which is a API generation tool for Gorgonia. DO NOT EDIT.
+
+import "gorgonia.org/tensor"
+
+// Abs performs a pointwise absolute value. It is the fluent version of Abs(n).
+func (n *Node) Abs() *Node { return fluent1(Abs, n) }
+
+// Sign performs a pointwise sign: -1 for negative values, 1 for positive values and 0 for 0. It is the fluent version of Sign(n).
+func (n *Node) Sign() *Node { return fluent1(Sign, n) }
+
+// Ceil performs a pointwise ceil. It is the fluent version of Ceil(n).
+func (n *Node) Ceil() *Node { return fluent1(Ceil, n) }
+
+// Floor performs a pointwise floor. It is the fluent version of Floor(n).
+func (n *Node) Floor() *Node { return fluent1(Floor, n) }
+
+// Sin performs a pointwise sin. It is the fluent version of Sin(n).
+func (n *Node) Sin() *Node { return fluent1(Sin, n) }
+
+// Cos performs a pointwise cos. It is the fluent version of Cos(n).
+func (n *Node) Cos() *Node { return fluent1(Cos, n) }
+
+// Exp performs a pointwise exp. It is the fluent version of Exp(n).
+func (n *Node) Exp() *Node { return fluent1(Exp, n) }
+
+// Log performs a pointwise natural log. It is the fluent version of Log(n).
+func (n *Node) Log() *Node { return fluent1(Log, n) }
+
+// Log2 performs a pointwise log2. It is the fluent version of Log2(n).
+func (n *Node) Log2() *Node { return fluent1(Log2, n) }
+
+// Neg performs a pointwise negation. It is the fluent version of Neg(n).
+func (n *Node) Neg() *Node { return fluent1(Neg, n) }
+
+// Square performs a pointwise square. It is the fluent version of Square(n).
+func (n *Node) Square() *Node { return fluent1(Square, n) }
+
+// Sqrt performs a pointwise square root. It is the fluent version of Sqrt(n).
+func (n *Node) Sqrt() *Node { return fluent1(Sqrt, n) }
+
+// Inverse performs a pointwise multiplicative inverse: 1/x. It is the fluent version of Inverse(n).
+func (n *Node) Inverse() *Node { return fluent1(Inverse, n) }
+
+// InverseSqrt performs a pointwise 1/sqrt(x). It is the fluent version of InverseSqrt(n).
+func (n *Node) InverseSqrt() *Node { return fluent1(InverseSqrt, n) }
+
+// Cube performs a pointwise cube. It is the fluent version of Cube(n).
+func (n *Node) Cube() *Node { return fluent1(Cube, n) }
+
+// Tanh performs a pointwise tanh. It is the fluent version of Tanh(n).
+func (n *Node) Tanh() *Node { return fluent1(Tanh, n) }
+
+// Sigmoid performs a pointwise sigmoid: 1/(1+exp(-x)). It is the fluent version of Sigmoid(n).
+func (n *Node) Sigmoid() *Node { return fluent1(Sigmoid, n) }
+
+// Log1p performs a pointwise log(1+x). It is the fluent version of Log1p(n).
+func (n *Node) Log1p() *Node { return fluent1(Log1p, n) }
+
+// Expm1 performs a pointwise exp(x)-1. It is the fluent version of Expm1(n).
+func (n *Node) Expm1() *Node { return fluent1(Expm1, n) }
+
+// Softplus performs a pointwise softplus: log(1+exp(x)). It is the fluent version of Softplus(n).
+func (n *Node) Softplus() *Node { return fluent1(Softplus, n) }
+
+// Add performs a pointwise add operation. It is the fluent version of Add(n, b).
+func (n *Node) Add(b *Node) *Node { return fluent2(Add, n, b) }
+
+// Sub performs a pointwise sub operation. It is the fluent version of Sub(n, b).
+func (n *Node) Sub(b *Node) *Node { return fluent2(Sub, n, b) }
+
+// HadamardProd performs a pointwise multiplication. It is the fluent version of HadamardProd(n, b).
+func (n *Node) HadamardProd(b *Node) *Node { return fluent2(HadamardProd, n, b) }
+
+// HadamardDiv performs a pointwise division. It is the fluent version of HadamardDiv(n, b).
+func (n *Node) HadamardDiv(b *Node) *Node { return fluent2(HadamardDiv, n, b) }
+
+// Pow performs a pointwise exponentiation. It is the fluent version of Pow(n, b).
+func (n *Node) Pow(b *Node) *Node { return fluent2(Pow, n, b) }
+
+// Lt performs a pointwise less than comparison. It is the fluent version of Lt(n, b, retSame).
+func (n *Node) Lt(b *Node, retSame bool) *Node { return fluentCmp(Lt, n, b, retSame) }
+
+// Gt performs a pointwise greater than comparison. It is the fluent version of Gt(n, b, retSame).
+func (n *Node) Gt(b *Node, retSame bool) *Node { return fluentCmp(Gt, n, b, retSame) }
+
+// Lte performs a pointwise less than or equal comparison. It is the fluent version of Lte(n, b, retSame).
+func (n *Node) Lte(b *Node, retSame bool) *Node { return fluentCmp(Lte, n, b, retSame) }
+
+// Gte performs a pointwise greater than or equal comparison. It is the fluent version of Gte(n, b, retSame).
+func (n *Node) Gte(b *Node, retSame bool) *Node { return fluentCmp(Gte, n, b, retSame) }
+
+// Eq performs a pointwise equality comparison. It is the fluent version of Eq(n, b, retSame).
+func (n *Node) Eq(b *Node, retSame bool) *Node { return fluentCmp(Eq, n, b, retSame) }
+
+// Ne performs a pointwise inequality comparison. It is the fluent version of Ne(n, b, retSame).
+func (n *Node) Ne(b *Node, retSame bool) *Node { return fluentCmp(Ne, n, b, retSame) }
+
+// Mul performs a matrix multiplication, a matrix-vector multiplication, a vector dot product, or a scalar multiplication, depending on the shapes. It is the fluent version of Mul(n, b).
+func (n *Node) Mul(b *Node) *Node { return fluent2(Mul, n, b) }
+
+// MatMul is an alias for Mul. It is the fluent version of Mul(n, b).
+func (n *Node) MatMul(b *Node) *Node { return fluent2(Mul, n, b) }
+
+// Div performs a division. If b is a scalar, a broadcasted division is performed. It is the fluent version of Div(n, b).
+func (n *Node) Div(b *Node) *Node { return fluent2(Div, n, b) }
+
+// OuterProd returns the outer product of two vectors. It is the fluent version of OuterProd(n, b).
+func (n *Node) OuterProd(b *Node) *Node { return fluent2(OuterProd, n, b) }
+
+// Rectify applies the rectified linear unit: max(0, x). It is the fluent version of Rectify(n).
+func (n *Node) Rectify() *Node { return fluent1(Rectify, n) }
+
+// Relu is an alias for Rectify. It is the fluent version of Rectify(n).
+func (n *Node) Relu() *Node { return fluent1(Rectify, n) }
+
+// Mish applies the mish activation function. It is the fluent version of Mish(n).
+func (n *Node) Mish() *Node { return fluent1(Mish, n) }
+
+// SoftMax applies the softmax along the given axes (the last axis if none are given). It is the fluent version of SoftMax(n, axes...).
+func (n *Node) SoftMax(axes ...int) *Node { return fluentAxial(SoftMax, n, axes) }
+
+// Sum sums along the given axes. All axes are summed if none are given. It is the fluent version of Sum(n, axes...).
+func (n *Node) Sum(axes ...int) *Node { return fluentAxial(Sum, n, axes) }
+
+// Mean returns the mean along the given axes. The mean of all the elements is returned if no axes are given. It is the fluent version of Mean(n, axes...).
+func (n *Node) Mean(axes ...int) *Node { return fluentAxial(Mean, n, axes) }
+
+// Max returns the max value along the given axes. The max of all the elements is returned if no axes are given. It is the fluent version of Max(n, axes...).
+func (n *Node) Max(axes ...int) *Node { return fluentAxial(Max, n, axes) }
+
+// Transpose transposes the node along the given axes. The axes are reversed if none are given. It is the fluent version of Transpose(n, axes...).
+func (n *Node) Transpose(axes ...int) *Node { return fluentAxial(Transpose, n, axes) }
+
+// Reshape reshapes the node to the given shape. It is the fluent version of Reshape(n, s).
+func (n *Node) Reshape(s tensor.Shape) *Node { return fluentShape(Reshape, n, s) }
+
+// Cast converts the node to the given Dtype. It is the fluent version of Cast(n, dt).
+func (n *Node) Cast(dt tensor.Dtype) *Node { return fluentDtype(Cast, n, dt) }
+
+// Not performs a logical negation. It is the fluent version of Not(n).
+func (n *Node) Not() *Node { return fluent1(Not, n) }
+
+// And performs a logical and. It is the fluent version of And(n, b).
+func (n *Node) And(b *Node) *Node { return fluent2(And, n, b) }
+
+// Or performs a logical or. It is the fluent version of Or(n, b).
+func (n *Node) Or(b *Node) *Node { return fluent2(Or, n, b) }
+
+// Xor performs a logical xor. It is the fluent version of Xor(n, b).
+func (n *Node) Xor(b *Node) *Node { return fluent2(Xor, n, b) }
+
+// Any returns true if any of the elements along the given axes are true. It is the fluent version of Any(n, axes...).
+func (n *Node) Any(axes ...int) *Node { return fluentAxial(Any, n, axes) }
+
+// All returns true if all of the elements along the given axes are true. It is the fluent version of All(n, axes...).
+func (n *Node) All(axes ...int) *Node { return fluentAxial(All, n, axes) }
+
+// IsNaN returns a bool node that is true where the node is NaN. It is the fluent version of IsNaN(n).
+func (n *Node) IsNaN() *Node { return fluent1(IsNaN, n) }
+
+// IsInf returns a bool node that is true where the node is an infinity. It is the fluent version of IsInf(n).
+func (n *Node) IsInf() *Node { return fluent1(IsInf, n) }
+
+// IsFinite returns a bool node that is true where the node is neither NaN nor an infinity. It is the fluent version of IsFinite(n).
+func (n *Node) IsFinite() *Node { return fluent1(IsFinite, n) }
+
+// StopGrad returns a node with the same value through which no gradient flows. It is the fluent version of StopGrad(n).
+func (n *Node) StopGrad() *Node { return fluent1(StopGrad, n) }
@@ -2,10 +2,10 @@ package gorgonia
 
 // Code generated by genapi, which is a API generation tool for Gorgonia. DO NOT EDIT.
 
-// Abs performs a pointwise abs.
+// Abs performs a pointwise absolute value.
 func Abs(a *Node) (*Node, error) { return unaryOpNode(newElemUnaryOp(absOpType, a), a) }
 
-// Sign performs a pointwise sign.
+// Sign performs a pointwise sign: -1 for negative values, 1 for positive values and 0 for 0.
 func Sign(a *Node) (*Node, error) { return unaryOpNode(newElemUnaryOp(signOpType, a), a) }
 
 // Ceil performs a pointwise ceil.
@@ -23,25 +23,25 @@ func Cos(a *Node) (*Node, error) { return unaryOpNode(newElemUnaryOp(cosOpType,
 // Exp performs a pointwise exp.
 func Exp(a *Node) (*Node, error) { return unaryOpNode(newElemUnaryOp(expOpType, a), a) }
 
-// Log performs a pointwise log.
+// Log performs a pointwise natural log.
 func Log(a *Node) (*Node, error) { return unaryOpNode(newElemUnaryOp(lnOpType, a), a) }
 
 // Log2 performs a pointwise log2.
 func Log2(a *Node) (*Node, error) { return unaryOpNode(newElemUnaryOp(log2OpType, a), a) }
 
-// Neg performs a pointwise neg.
+// Neg performs a pointwise negation.
 func Neg(a *Node) (*Node, error) { return unaryOpNode(newElemUnaryOp(negOpType, a), a) }
 
 // Square performs a pointwise square.
 func Square(a *Node) (*Node, error) { return unaryOpNode(newElemUnaryOp(squareOpType, a), a) }
 
-// Sqrt performs a pointwise sqrt.
+// Sqrt performs a pointwise square root.
 func Sqrt(a *Node) (*Node, error) { return unaryOpNode(newElemUnaryOp(sqrtOpType, a), a) }
 
-// Inverse performs a pointwise inverse.
+// Inverse performs a pointwise multiplicative inverse: 1/x.
 func Inverse(a *Node) (*Node, error) { return unaryOpNode(newElemUnaryOp(inverseOpType, a), a) }
 
-// InverseSqrt performs a pointwise inversesqrt.
+// InverseSqrt performs a pointwise 1/sqrt(x).
 func InverseSqrt(a *Node) (*Node, error) { return unaryOpNode(newElemUnaryOp(inverseSqrtOpType, a), a) }
 
 // Cube performs a pointwise cube.
@@ -50,16 +50,16 @@ func Cube(a *Node) (*Node, error) { return unaryOpNode(newElemUnaryOp(cubeOpType
 // Tanh performs a pointwise tanh.
 func Tanh(a *Node) (*Node, error) { return unaryOpNode(newElemUnaryOp(tanhOpType, a), a) }
 
-// Sigmoid performs a pointwise sigmoid.
+// Sigmoid performs a pointwise sigmoid: 1/(1+exp(-x)).
 func Sigmoid(a *Node) (*Node, error) { return unaryOpNode(newElemUnaryOp(sigmoidOpType, a), a) }
 
-// Log1p performs a pointwise log1p.
+// Log1p performs a pointwise log(1+x).
 func Log1p(a *Node) (*Node, error) { return unaryOpNode(newElemUnaryOp(log1pOpType, a), a) }
 
-// Expm1 performs a pointwise expm1.
+// Expm1 performs a pointwise exp(x)-1.
 func Expm1(a *Node) (*Node, error) { return unaryOpNode(newElemUnaryOp(expm1OpType, a), a) }
 
-// Softplus performs a pointwise softplus.
+// Softplus performs a pointwise softplus: log(1+exp(x)).
 func Softplus(a *Node) (*Node, error) { return unaryOpNode(newElemUnaryOp(softplusOpType, a), a) }
 
 // Add performs a pointwise add operation.
@@ -68,16 +68,16 @@ func Add(a, b *Node) (*Node, error) { return binOpNode(newElemBinOp(addOpType, a
 // Sub performs a pointwise sub operation.
 func Sub(a, b *Node) (*Node, error) { return binOpNode(newElemBinOp(subOpType, a, b), a, b) }
 
-// HadamardProd performs a pointwise hadamardprod operation.
+// HadamardProd performs a pointwise multiplication.
 func HadamardProd(a, b *Node) (*Node, error) { return binOpNode(newElemBinOp(mulOpType, a, b), a, b) }
 
-// HadamardDiv performs a pointwise hadamarddiv operation.
+// HadamardDiv performs a pointwise division.
 func HadamardDiv(a, b *Node) (*Node, error) { return binOpNode(newElemBinOp(divOpType, a, b), a, b) }
 
-// Pow performs a pointwise pow operation.
+// Pow performs a pointwise exponentiation.
 func Pow(a, b *Node) (*Node, error) { return binOpNode(newElemBinOp(powOpType, a, b), a, b) }
 
-// Lt performs a pointwise lt operation.
+// Lt performs a pointwise less than comparison.
 // retSame indicates if the data type of the return value should be the same as the input data type. It defaults to Bool otherwise.
 func Lt(a, b *Node, retSame bool) (*Node, error) {
 	op := newElemBinOp(ltOpType, a, b)
@@ -85,7 +85,7 @@ func Lt(a, b *Node, retSame bool) (*Node, error) {
 	return binOpNode(op, a, b)
 }
 
-// Gt performs a pointwise gt operation.
+// Gt performs a pointwise greater than comparison.
 // retSame indicates if the data type of the return value should be the same as the input data type. It defaults to Bool otherwise.
 func Gt(a, b *Node, retSame bool) (*Node, error) {
 	op := newElemBinOp(gtOpType, a, b)
@@ -93,7 +93,7 @@ func Gt(a, b *Node, retSame bool) (*Node, error) {
 	return binOpNode(op, a, b)
 }
 
-// Lte performs a pointwise lte operation.
+// Lte performs a pointwise less than or equal comparison.
 // retSame indicates if the data type of the return value should be the same as the input data type. It defaults to Bool otherwise.
 func Lte(a, b *Node, retSame bool) (*Node, error) {
 	op := newElemBinOp(lteOpType, a, b)
@@ -101,7 +101,7 @@ func Lte(a, b *Node, retSame bool) (*Node, error) {
 	return binOpNode(op, a, b)
 }
 
-// Gte performs a pointwise gte operation.
+// Gte performs a pointwise greater than or equal comparison.
 // retSame indicates if the data type of the return value should be the same as the input data type. It defaults to Bool otherwise.
 func Gte(a, b *Node, retSame bool) (*Node, error) {
 	op := newElemBinOp(gteOpType, a, b)
@@ -109,7 +109,7 @@ func Gte(a, b *Node, retSame bool) (*Node, error) {
 	return binOpNode(op, a, b)
 }
 
-// Eq performs a pointwise eq operation.
+// Eq performs a pointwise equality comparison.
 // retSame indicates if the data type of the return value should be the same as the input data type. It defaults to Bool otherwise.
 func Eq(a, b *Node, retSame bool) (*Node, error) {
 	op := newElemBinOp(eqOpType, a, b)
@@ -117,7 +117,7 @@ func Eq(a, b *Node, retSame bool) (*Node, error) {
 	return binOpNode(op, a, b)
 }
 
-// Ne performs a pointwise ne operation.
+// Ne performs a pointwise inequality comparison.
 // retSame indicates if the data type of the return value should be the same as the input data type. It defaults to Bool otherwise.
 func Ne(a, b *Node, retSame bool) (*Node, error) {
 	op := newElemBinOp(neOpType, a, b)
@@ -125,7 +125,7 @@ func Ne(a, b *Node, retSame bool) (*Node, error) {
 	return binOpNode(op, a, b)
 }
 
-//Add performs a add. The operation is precomposed with a broadcast such that the shapes matches before operations commence.
+// BroadcastAdd performs a add. The operation is precomposed with a broadcast such that the shapes matches before operations commence.
 func BroadcastAdd(a, b *Node, leftPattern, rightPattern []byte) (*Node, error) {
 	a2, b2, err := Broadcast(a, b, NewBroadcastPattern(leftPattern, rightPattern))
 	if err != nil {
@@ -134,7 +134,7 @@ func BroadcastAdd(a, b *Node, leftPattern, rightPattern []byte) (*Node, error) {
 	return Add(a2, b2)
 }
 
-//Sub performs a sub. The operation is precomposed with a broadcast such that the shapes matches before operations commence.
+// BroadcastSub performs a sub. The operation is precomposed with a broadcast such that the shapes matches before operations commence.
 func BroadcastSub(a, b *Node, leftPattern, rightPattern []byte) (*Node, error) {
 	a2, b2, err := Broadcast(a, b, NewBroadcastPattern(leftPattern, rightPattern))
 	if err != nil {
@@ -143,7 +143,7 @@ func BroadcastSub(a, b *Node, leftPattern, rightPattern []byte) (*Node, error) {
 	return Sub(a2, b2)
 }
 
-//HadamardProd performs a hadamardprod. The operation is precomposed with a broadcast such that the shapes matches before operations commence.
+// BroadcastHadamardProd performs a hadamardprod. The operation is precomposed with a broadcast such that the shapes matches before operations commence.
 func BroadcastHadamardProd(a, b *Node, leftPattern, rightPattern []byte) (*Node, error) {
 	a2, b2, err := Broadcast(a, b, NewBroadcastPattern(leftPattern, rightPattern))
 	if err != nil {
@@ -152,7 +152,7 @@ func BroadcastHadamardProd(a, b *Node, leftPattern, rightPattern []byte) (*Node,
 	return HadamardProd(a2, b2)
 }
 
-//HadamardDiv performs a hadamarddiv. The operation is precomposed with a broadcast such that the shapes matches before operations commence.
+// BroadcastHadamardDiv performs a hadamarddiv. The operation is precomposed with a broadcast such that the shapes matches before operations commence.
 func BroadcastHadamardDiv(a, b *Node, leftPattern, rightPattern []byte) (*Node, error) {
 	a2, b2, err := Broadcast(a, b, NewBroadcastPattern(leftPattern, rightPattern))
 	if err != nil {
@@ -161,7 +161,7 @@ func BroadcastHadamardDiv(a, b *Node, leftPattern, rightPattern []byte) (*Node,
 	return HadamardDiv(a2, b2)
 }
 
-//Pow performs a pow. The operation is precomposed with a broadcast such that the shapes matches before operations commence.
+// BroadcastPow performs a pow. The operation is precomposed with a broadcast such that the shapes matches before operations commence.
 func BroadcastPow(a, b *Node, leftPattern, rightPattern []byte) (*Node, error) {
 	a2, b2, err := Broadcast(a, b, NewBroadcastPattern(leftPattern, rightPattern))
 	if err != nil {
@@ -170,7 +170,7 @@ func BroadcastPow(a, b *Node, leftPattern, rightPattern []byte) (*Node, error) {
 	return Pow(a2, b2)
 }
 
-//Lt performs a lt. The operation is precomposed with a broadcast such that the shapes matches before operations commence.
+// BroadcastLt performs a lt. The operation is precomposed with a broadcast such that the shapes matches before operations commence.
 func BroadcastLt(a, b *Node, retSame bool, leftPattern, rightPattern []byte) (*Node, error) {
 	a2, b2, err := Broadcast(a, b, NewBroadcastPattern(leftPattern, rightPattern))
 	if err != nil {
@@ -179,7 +179,7 @@ func BroadcastLt(a, b *Node, retSame bool, leftPattern, rightPattern []byte) (*N
 	return Lt(a2, b2, retSame)
 }
 
-//Gt performs a gt. The operation is precomposed with a broadcast such that the shapes matches before operations commence.
+// BroadcastGt performs a gt. The operation is precomposed with a broadcast such that the shapes matches before operations commence.
 func BroadcastGt(a, b *Node, retSame bool, leftPattern, rightPattern []byte) (*Node, error) {
 	a2, b2, err := Broadcast(a, b, NewBroadcastPattern(leftPattern, rightPattern))
 	if err != nil {
@@ -188,7 +188,7 @@ func BroadcastGt(a, b *Node, retSame bool, leftPattern, rightPattern []byte) (*N
 	return Gt(a2, b2, retSame)
 }
 
-//Lte performs a lte. The operation is precomposed with a broadcast such that the shapes matches before operations commence.
+// BroadcastLte performs a lte. The operation is precomposed with a broadcast such that the shapes matches before operations commence.
 func BroadcastLte(a, b *Node, retSame bool, leftPattern, rightPattern []byte) (*Node, error) {
 	a2, b2, err := Broadcast(a, b, NewBroadcastPattern(leftPattern, rightPattern))
 	if err != nil {
@@ -197,7 +197,7 @@ func BroadcastLte(a, b *Node, retSame bool, leftPattern, rightPattern []byte) (*
 	return Lte(a2, b2, retSame)
 }
 
-//Gte performs a gte. The operation is precomposed with a broadcast such that the shapes matches before operations commence.
+// BroadcastGte performs a gte. The operation is precomposed with a broadcast such that the shapes matches before operations commence.
 func BroadcastGte(a, b *Node, retSame bool, leftPattern, rightPattern []byte) (*Node, error) {
 	a2, b2, err := Broadcast(a, b, NewBroadcastPattern(leftPattern, rightPattern))
 	if err != nil {
@@ -206,7 +206,7 @@ func BroadcastGte(a, b *Node, retSame bool, leftPattern, rightPattern []byte) (*
 	return Gte(a2, b2, retSame)
 }
 
-//Eq performs a eq. The operation is precomposed with a broadcast such that the shapes matches before operations commence.
+// BroadcastEq performs a eq. The operation is precomposed with a broadcast such that the shapes matches before operations commence.
 func BroadcastEq(a, b *Node, retSame bool, leftPattern, rightPattern []byte) (*Node, error) {
 	a2, b2, err := Broadcast(a, b, NewBroadcastPattern(leftPattern, rightPattern))
 	if err != nil {
@@ -215,7 +215,7 @@ func BroadcastEq(a, b *Node, retSame bool, leftPattern, rightPattern []byte) (*N
 	return Eq(a2, b2, retSame)
 }
 
-//Ne performs a ne. The operation is precomposed with a broadcast such that the shapes matches before operations commence.
+// BroadcastNe performs a ne. The operation is precomposed with a broadcast such that the shapes matches before operations commence.
 func BroadcastNe(a, b *Node, retSame bool, leftPattern, rightPattern []byte) (*Node, error) {
 	a2, b2, err := Broadcast(a, b, NewBroadcastPattern(leftPattern, rightPattern))
 	if err != nil {
@@ -0,0 +1,147 @@
+package gorgonia
+
+// Code generated by genapi, which is a API generation tool for Gorgonia. DO NOT EDIT.
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorgonia.org/tensor"
+)
+
+var unaryOpAPITests = []struct {
+	name   string
+	fn     func(*Node) (*Node, error)
+	op     ʘUnaryOperatorType
+	diff   bool
+	onnx   string
+	dtypes []tensor.Dtype
+}{
+	{"Abs", Abs, absOpType, true, "Abs", []tensor.Dtype{Float64, Float32}},
+	{"Sign", Sign, signOpType, false, "Sign", []tensor.Dtype{Float64, Float32}},
+	{"Ceil", Ceil, ceilOpType, false, "Ceil", []tensor.Dtype{Float64, Float32}},
+	{"Floor", Floor, floorOpType, false, "Floor", []tensor.Dtype{Float64, Float32}},
+	{"Sin", Sin, sinOpType, true, "Sin", []tensor.Dtype{Float64, Float32}},
+	{"Cos", Cos, cosOpType, true, "Cos", []tensor.Dtype{Float64, Float32}},
+	{"Exp", Exp, expOpType, true, "Exp", []tensor.Dtype{Float64, Float32}},
+	{"Log", Log, lnOpType, true, "Log", []tensor.Dtype{Float64, Float32}},
+	{"Log2", Log2, log2OpType, true, "", []tensor.Dtype{Float64, Float32}},
+	{"Neg", Neg, negOpType, true, "Neg", []tensor.Dtype{Float64, Float32}},
+	{"Square", Square, squareOpType, true, "", []tensor.Dtype{Float64, Float32}},
+	{"Sqrt", Sqrt, sqrtOpType, true, "Sqrt", []tensor.Dtype{Float64, Float32}},
+	{"Inverse", Inverse, inverseOpType, true, "Reciprocal", []tensor.Dtype{Float64, Float32}},
+	{"InverseSqrt", InverseSqrt, inverseSqrtOpType, true, "", []tensor.Dtype{Float64, Float32}},
+	{"Cube", Cube, cubeOpType, true, "", []tensor.Dtype{Float64, Float32}},
+	{"Tanh", Tanh, tanhOpType, true, "Tanh", []tensor.Dtype{Float64, Float32}},
+	{"Sigmoid", Sigmoid, sigmoidOpType, true, "Sigmoid", []tensor.Dtype{Float64, Float32}},
+	{"Log1p", Log1p, log1pOpType, true, "", []tensor.Dtype{Float64, Float32}},
+	{"Expm1", Expm1, expm1OpType, true, "", []tensor.Dtype{Float64, Float32}},
+	{"Softplus", Softplus, softplusOpType, true, "Softplus", []tensor.Dtype{Float64, Float32}},
+}
+
+var binaryOpAPITests = []struct {
+	name   string
+	fn     func(a, b *Node) (*Node, error)
+	op     ʘBinaryOperatorType
+	diff   bool
+	onnx   string
+	dtypes []tensor.Dtype
+}{
+	{"Add", Add, addOpType, true, "Add", []tensor.Dtype{Float64, Float32}},
+	{"Sub", Sub, subOpType, true, "Sub", []tensor.Dtype{Float64, Float32}},
+	{"HadamardProd", HadamardProd, mulOpType, true, "Mul", []tensor.Dtype{Float64, Float32}},
+	{"HadamardDiv", HadamardDiv, divOpType, true, "Div", []tensor.Dtype{Float64, Float32}},
+	{"Pow", Pow, powOpType, true, "Pow", []tensor.Dtype{Float64, Float32}},
+	{"Lt", func(a, b *Node) (*Node, error) { return Lt(a, b, true) }, ltOpType, false, "Less", []tensor.Dtype{Float64, Float32}},
+	{"Gt", func(a, b *Node) (*Node, error) { return Gt(a, b, true) }, gtOpType, false, "Greater", []tensor.Dtype{Float64, Float32}},
+	{"Lte", func(a, b *Node) (*Node, error) { return Lte(a, b, true) }, lteOpType, false, "LessOrEqual", []tensor.Dtype{Float64, Float32}},
+	{"Gte", func(a, b *Node) (*Node, error) { return Gte(a, b, true) }, gteOpType, false, "GreaterOrEqual", []tensor.Dtype{Float64, Float32}},
+	{"Eq", func(a, b *Node) (*Node, error) { return Eq(a, b, true) }, eqOpType, false, "Equal", []tensor.Dtype{Float64, Float32}},
+	{"Ne", func(a, b *Node) (*Node, error) { return Ne(a, b, true) }, neOpType, false, "", []tensor.Dtype{Float64, Float32}},
+}
+
+// TestCUDAKernelCoverage checks that every CUDA kernel registered for an op exists in the CUDA sources.
+func TestCUDAKernelCoverage(t *testing.T) {
+	unary, err := ioutil.ReadFile(filepath.Join("cuda modules", "src", "elemunaryop.cu"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	binary, err := ioutil.ReadFile(filepath.Join("cuda modules", "src", "elembinop.cu"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range unaryOpAPITests {
+		if k := tc.op.cudaKernel(); k != "" && !strings.Contains(string(unary), "UNARYOP("+k+",") {
+			t.Errorf("CUDA kernel for %v (%q) not found", tc.name, k)
+		}
+	}
+	for _, tc := range binaryOpAPITests {
+		if k := tc.op.cudaKernel(); k != "" && !strings.Contains(string(binary), "BINOP("+k+",") {
+			t.Errorf("CUDA kernel for %v (%q) not found", tc.name, k)
+		}
+	}
+}
+
+func TestGeneratedUnaryOps(t *testing.T) {
+	assert := assert.New(t)
+	for _, tc := range unaryOpAPITests {
+		assert.Equal(tc.onnx, tc.op.onnx(), "%v", tc.name)
+		for _, dt := range tc.dtypes {
+			g := NewGraph()
+			x := NewVector(g, dt, WithShape(3), WithName("x"), WithInit(RangedFrom(1)))
+			y, err := tc.fn(x)
+			if err != nil {
+				t.Errorf("%v(%v): %v", tc.name, dt, err)
+				continue
+			}
+			op, ok := y.op.(elemUnaryOp)
+			if !ok {
+				t.Errorf("%v(%v): expected an elemUnaryOp. Got %T instead", tc.name, dt, y.op)
+				continue
+			}
+			assert.Equal(tc.op, op.unaryOpType(), "%v(%v)", tc.name, dt)
+			assert.Equal([]bool{tc.diff}, op.DiffWRT(1), "%v(%v)", tc.name, dt)
+			assert.Equal(dt, y.Dtype(), "%v(%v)", tc.name, dt)
+
+			m := NewTapeMachine(g)
+			if err = m.RunAll(); err != nil {
+				t.Errorf("%v(%v): %v", tc.name, dt, err)
+			}
+			m.Close()
+		}
+	}
+}
+
+func TestGeneratedBinaryOps(t *testing.T) {
+	assert := assert.New(t)
+	for _, tc := range binaryOpAPITests {
+		assert.Equal(tc.onnx, tc.op.onnx(), "%v", tc.name)
+		for _, dt := range tc.dtypes {
+			g := NewGraph()
+			a := NewVector(g, dt, WithShape(3), WithName("a"), WithInit(RangedFrom(1)))
+			b := NewVector(g, dt, WithShape(3), WithName("b"), WithInit(RangedFrom(2)))
+			c, err := tc.fn(a, b)
+			if err != nil {
+				t.Errorf("%v(%v): %v", tc.name, dt, err)
+				continue
+			}
+			op, ok := c.op.(elemBinOp)
+			if !ok {
+				t.Errorf("%v(%v): expected an elemBinOp. Got %T instead", tc.name, dt, c.op)
+				continue
+			}
+			assert.Equal(tc.op, op.binOpType(), "%v(%v)", tc.name, dt)
+			assert.Equal([]bool{tc.diff, tc.diff}, op.DiffWRT(2), "%v(%v)", tc.name, dt)
+			assert.Equal(dt, c.Dtype(), "%v(%v)", tc.name, dt)
+
+			m := NewTapeMachine(g)
+			if err = m.RunAll(); err != nil {
+				t.Errorf("%v(%v): %v", tc.name, dt, err)
+			}
+			m.Close()
+		}
+	}
+}
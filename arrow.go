@@ -0,0 +1,139 @@
+package gorgonia
+
+import (
+	"reflect"
+	"strconv"
+	"unsafe"
+
+	"github.com/apache/arrow/go/arrow"
+	arrowArray "github.com/apache/arrow/go/arrow/array"
+	"github.com/apache/arrow/go/arrow/memory"
+	arrowTensor "github.com/apache/arrow/go/arrow/tensor"
+	"github.com/pkg/errors"
+	"gorgonia.org/tensor"
+)
+
+// arrowDtypes are the Dtypes of the Arrow tensors. Int and Uint are converted to Int64 and Uint64 on 64-bit platforms.
+var arrowDtypes = []struct {
+	dt tensor.Dtype
+	at arrow.DataType
+}{
+	{tensor.Int8, arrow.PrimitiveTypes.Int8},
+	{tensor.Int16, arrow.PrimitiveTypes.Int16},
+	{tensor.Int32, arrow.PrimitiveTypes.Int32},
+	{tensor.Int64, arrow.PrimitiveTypes.Int64},
+	{tensor.Uint8, arrow.PrimitiveTypes.Uint8},
+	{tensor.Uint16, arrow.PrimitiveTypes.Uint16},
+	{tensor.Uint32, arrow.PrimitiveTypes.Uint32},
+	{tensor.Uint64, arrow.PrimitiveTypes.Uint64},
+	{tensor.Float32, arrow.PrimitiveTypes.Float32},
+	{tensor.Float64, arrow.PrimitiveTypes.Float64},
+}
+
+// ToArrowTensor returns an Arrow tensor of the elements of a tensor, with the given names of the dimensions, if any.
+// A contiguous tensor shares its memory with the Arrow tensor. The other tensors, such as the transposes, are copied.
+// The Arrow tensor has no validity bitmap, so masked tensors are not supported.
+func ToArrowTensor(t tensor.Tensor, names ...string) (arrowTensor.Interface, error) {
+	if d, ok := t.(*tensor.Dense); ok && d.IsMasked() {
+		return nil, errors.New("Cannot convert a masked tensor to an Arrow tensor")
+	}
+	if len(names) > 0 && len(names) != t.Dims() {
+		return nil, errors.Errorf("Expected %d names of dimensions. Got %v instead", t.Dims(), names)
+	}
+	dt := t.Dtype()
+	if (dt == tensor.Int || dt == tensor.Uint) && strconv.IntSize == 64 {
+		dt = tensor.Int64
+		if t.Dtype() == tensor.Uint {
+			dt = tensor.Uint64
+		}
+	}
+	var at arrow.DataType
+	for _, d := range arrowDtypes {
+		if d.dt == dt {
+			at = d.at
+		}
+	}
+	if at == nil {
+		return nil, errors.Errorf(nyiTypeFail, "ToArrowTensor", t.Dtype())
+	}
+
+	s, err := valueSlice(t)
+	if err != nil {
+		return nil, err
+	}
+	buf := memory.NewBufferBytes(sliceBytes(s))
+	data := arrowArray.NewData(at, s.Len(), []*memory.Buffer{nil, buf}, nil, 0, 0)
+	defer data.Release()
+
+	shape := make([]int64, t.Dims())
+	for i, d := range t.Shape() {
+		shape[i] = int64(d)
+	}
+	return arrowTensor.New(data, shape, nil, names), nil
+}
+
+// FromArrowTensor returns a *tensor.Dense of the elements of an Arrow tensor. A row major tensor shares its memory with
+// the returned tensor, which keeps the memory from being collected but does not Retain the Arrow tensor: the caller
+// must not Release the Arrow tensor while the returned tensor is in use. The other Arrow tensors are copied.
+func FromArrowTensor(a arrowTensor.Interface) (*tensor.Dense, error) {
+	var dt tensor.Dtype
+	for _, d := range arrowDtypes {
+		if arrow.TypeEqual(d.at, a.DataType()) {
+			dt = d.dt
+		}
+	}
+	if dt.Type == nil {
+		return nil, errors.Errorf(nyiTypeFail, "FromArrowTensor", a.DataType())
+	}
+	if a.NumDims() == 0 {
+		return nil, errors.New("Cannot convert an Arrow tensor of no dimensions")
+	}
+
+	shape := make(tensor.Shape, a.NumDims())
+	for i, d := range a.Shape() {
+		shape[i] = int(d)
+	}
+	elemSize := int(dt.Size())
+	n := shape.TotalSize()
+	var raw []byte
+	if vals := a.Data().Buffers()[1]; vals != nil {
+		raw = vals.Bytes()[a.Data().Offset()*elemSize:]
+	}
+	if n == 0 {
+		return tensor.New(tensor.WithShape(shape...), tensor.Of(dt)), nil
+	}
+
+	var b []byte
+	if a.IsRowMajor() {
+		b = raw[:n*elemSize]
+	} else {
+		// walk the elements in the row major order, through the strides in bytes
+		b = make([]byte, 0, n*elemSize)
+		strides := a.Strides()
+		idx := make([]int, len(shape))
+		for i := 0; i < n; i++ {
+			var off int64
+			for j, k := range idx {
+				off += int64(k) * strides[j]
+			}
+			b = append(b, raw[off:off+int64(elemSize)]...)
+			for j := len(idx) - 1; j >= 0; j-- {
+				if idx[j]++; idx[j] < shape[j] {
+					break
+				}
+				idx[j] = 0
+			}
+		}
+	}
+	backing := reflect.NewAt(reflect.ArrayOf(n, dt.Type), unsafe.Pointer(&b[0])).Elem().Slice(0, n)
+	return tensor.New(tensor.WithShape(shape...), tensor.WithBacking(backing.Interface())), nil
+}
+
+// sliceBytes returns the bytes of the elements of a slice, without copying them.
+func sliceBytes(s reflect.Value) []byte {
+	n := s.Len() * int(s.Type().Elem().Size())
+	if n == 0 {
+		return nil
+	}
+	return reflect.NewAt(reflect.ArrayOf(n, reflect.TypeOf(byte(0))), unsafe.Pointer(s.Index(0).UnsafeAddr())).Elem().Slice(0, n).Bytes()
+}
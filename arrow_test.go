@@ -0,0 +1,78 @@
+package gorgonia
+
+import (
+	"testing"
+
+	"github.com/apache/arrow/go/arrow"
+	arrowArray "github.com/apache/arrow/go/arrow/array"
+	"github.com/apache/arrow/go/arrow/memory"
+	arrowTensor "github.com/apache/arrow/go/arrow/tensor"
+	"github.com/stretchr/testify/assert"
+	"gorgonia.org/tensor"
+)
+
+func TestToArrowTensor(t *testing.T) {
+	assert := assert.New(t)
+	a := tensor.New(tensor.WithShape(2, 3), tensor.WithBacking([]float64{1, 2, 3, 4, 5, 6}))
+	at, err := ToArrowTensor(a, "rows", "cols")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer at.Release()
+	f64, ok := at.(*arrowTensor.Float64)
+	if !assert.True(ok, "%T", at) {
+		return
+	}
+	assert.Equal([]int64{2, 3}, at.Shape())
+	assert.True(at.IsRowMajor())
+	assert.Equal([]string{"rows", "cols"}, at.DimNames())
+	assert.Equal(6.0, f64.Value([]int64{1, 2}))
+	a.Float64s()[0] = 10
+	assert.Equal(10.0, f64.Float64Values()[0], "the memory is shared")
+
+	back, err := FromArrowTensor(at)
+	if assert.NoError(err) {
+		assert.Equal(tensor.Shape{2, 3}, back.Shape())
+		assert.Equal(a.Data(), back.Data())
+	}
+
+	i := tensor.New(tensor.WithShape(2, 2), tensor.WithBacking([]int{1, 2, 3, 4}))
+	if err = i.T(); err != nil {
+		t.Fatal(err)
+	}
+	if at, err = ToArrowTensor(i); assert.NoError(err) {
+		assert.Equal(arrow.PrimitiveTypes.Int64, at.DataType(), "ints are 64 bits")
+		assert.Equal([]int64{1, 3, 2, 4}, at.(*arrowTensor.Int64).Int64Values(), "the transpose is copied")
+	}
+
+	_, err = ToArrowTensor(tensor.New(tensor.WithShape(2), tensor.WithBacking([]bool{true, false})))
+	assert.Error(err)
+	_, err = ToArrowTensor(a, "rows")
+	assert.Error(err)
+}
+
+func TestFromArrowTensor(t *testing.T) {
+	assert := assert.New(t)
+	bld := arrowArray.NewInt16Builder(memory.NewGoAllocator())
+	defer bld.Release()
+	bld.AppendValues([]int16{0, 1, 2, 3, 4, 5, 6}, nil)
+	arr := bld.NewInt16Array()
+	defer arr.Release()
+	sliced := arrowArray.NewSliceData(arr.Data(), 1, 7)
+	defer sliced.Release()
+
+	row := arrowTensor.New(sliced, []int64{2, 3}, nil, nil)
+	defer row.Release()
+	d, err := FromArrowTensor(row)
+	if assert.NoError(err) {
+		assert.Equal(tensor.Shape{2, 3}, d.Shape())
+		assert.Equal([]int16{1, 2, 3, 4, 5, 6}, d.Data(), "the offset of the data is kept")
+	}
+
+	col := arrowTensor.New(sliced, []int64{2, 3}, []int64{2, 4}, nil)
+	defer col.Release()
+	assert.True(col.IsColMajor())
+	if d, err = FromArrowTensor(col); assert.NoError(err) {
+		assert.Equal([]int16{1, 3, 5, 2, 4, 6}, d.Data())
+	}
+}
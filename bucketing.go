@@ -0,0 +1,324 @@
+package gorgonia
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"gorgonia.org/gorgonia/internal/logging"
+	"gorgonia.org/tensor"
+)
+
+// BuildFunc builds the graph of a BucketedProgram for inputs of the given shapes. It returns the input nodes, in the
+// order the values are passed to Run, and the output nodes.
+type BuildFunc func(g *ExprGraph, shapes []tensor.Shape) (inputs, outputs Nodes, err error)
+
+// BucketOpt is an option of a BucketedProgram.
+type BucketOpt func(*BucketedProgram)
+
+// BucketedProgram runs a graph on inputs of varying shapes by compiling one program per shape "bucket". The bucketed
+// sizes of the inputs are rounded up, to multiples of 32 by default, and the inputs are padded with zeros to the sizes of
+// their bucket, so that the inputs of similar shapes share a program. Coarser buckets compile fewer programs and waste
+// more computation on padding; the Stats tell how much.
+//
+// The outputs have the padded shapes: the graph has to mask the padding where it matters, for instance by also taking
+// the lengths of the sequences as an input. A BucketedProgram may be used concurrently, but runs one program at a time.
+type BucketedProgram struct {
+	build       BuildFunc
+	round       func(int) int
+	axes        map[int][]int // the axes bucketed for each input. If empty, every axis of every input is bucketed
+	maxPrograms int
+	vmOpts      []VMOpt
+
+	sync.Mutex
+	buckets map[string]*bucket
+	clock   int
+	stats   BucketStats
+}
+
+// bucket is the compiled program of a bucket.
+type bucket struct {
+	g       *ExprGraph
+	inputs  Nodes
+	outputs Nodes
+	m       VM
+	lastUse int
+}
+
+// BucketStats are the statistics of a BucketedProgram.
+type BucketStats struct {
+	Programs  int // the number of programs that are compiled
+	Hits      int // the runs that used a compiled program
+	Misses    int // the runs that compiled a program
+	Evictions int // the programs closed to keep under the maximum
+
+	Elements       int64 // the elements of the inputs that were run
+	PaddedElements int64 // the elements of padding added to them
+}
+
+// Waste is the fraction of the elements run that are padding.
+func (s BucketStats) Waste() float64 {
+	if s.Elements+s.PaddedElements == 0 {
+		return 0
+	}
+	return float64(s.PaddedElements) / float64(s.Elements+s.PaddedElements)
+}
+
+// RoundToMultiple returns a function that rounds sizes up to multiples of n, for WithBucketing.
+func RoundToMultiple(n int) func(int) int {
+	return func(size int) int {
+		if size%n == 0 {
+			return size
+		}
+		return (size/n + 1) * n
+	}
+}
+
+// RoundToPowerOfTwo returns a function that rounds sizes up to powers of two that are at least min, for WithBucketing.
+// The padding wasted is less than half of the elements, with a number of programs that grows with the logarithm of the
+// largest size.
+func RoundToPowerOfTwo(min int) func(int) int {
+	return func(size int) int {
+		retVal := 1
+		for retVal < min {
+			retVal <<= 1
+		}
+		for retVal < size {
+			retVal <<= 1
+		}
+		return retVal
+	}
+}
+
+// WithBucketing sets the function that rounds the bucketed sizes up to the sizes of their buckets. A result smaller than
+// the size is an error when the program is run.
+func WithBucketing(round func(int) int) BucketOpt {
+	return func(p *BucketedProgram) { p.round = round }
+}
+
+// WithBucketAxes sets the axes of an input that are bucketed. The other axes of the input keep their sizes, and each of
+// their sizes compiles its own program. If no axes are set for any input, every axis of every input is bucketed; once
+// they are set for one input, the inputs that have no axes set are not bucketed.
+func WithBucketAxes(input int, axes ...int) BucketOpt {
+	return func(p *BucketedProgram) { p.axes[input] = axes }
+}
+
+// WithMaxPrograms bounds the number of compiled programs. When a bucket needs a new program, the least recently used
+// one is closed. 0, the default, means no bound.
+func WithMaxPrograms(n int) BucketOpt {
+	return func(p *BucketedProgram) { p.maxPrograms = n }
+}
+
+// WithProgramOpts sets the options of the machines running each program.
+func WithProgramOpts(opts ...VMOpt) BucketOpt {
+	return func(p *BucketedProgram) { p.vmOpts = opts }
+}
+
+// NewBucketedProgram creates a BucketedProgram. The programs are built and compiled when they are first run.
+func NewBucketedProgram(build BuildFunc, opts ...BucketOpt) *BucketedProgram {
+	p := &BucketedProgram{
+		build:   build,
+		round:   RoundToMultiple(32),
+		axes:    make(map[int][]int),
+		buckets: make(map[string]*bucket),
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// Run pads the inputs to the shapes of their bucket and runs its program, which is compiled if it is the first run of
+// the bucket. It returns copies of the values of the outputs, which have the padded shapes.
+func (p *BucketedProgram) Run(inputs ...Value) ([]Value, error) {
+	shapes := make([]tensor.Shape, len(inputs))
+	key := make([]string, len(inputs))
+	for i, v := range inputs {
+		if v == nil {
+			return nil, errors.Errorf("Input %d is nil", i)
+		}
+		s, err := p.bucketShape(i, v.Shape())
+		if err != nil {
+			return nil, err
+		}
+		shapes[i] = s
+		key[i] = fmt.Sprintf("%v%v", v.Dtype(), s)
+	}
+
+	p.Lock()
+	defer p.Unlock()
+	b, err := p.bucket(strings.Join(key, " "), shapes)
+	if err != nil {
+		return nil, err
+	}
+	if len(b.inputs) != len(inputs) {
+		return nil, errors.Errorf("Expected %d inputs. Got %d instead", len(b.inputs), len(inputs))
+	}
+
+	for i, v := range inputs {
+		padded, err := padValue(v, shapes[i])
+		if err != nil {
+			return nil, errors.Wrapf(err, "Failed to pad input %d", i)
+		}
+		if err = Let(b.inputs[i], padded); err != nil {
+			return nil, errors.Wrapf(err, "Failed to bind input %d", i)
+		}
+		size := int64(v.Shape().TotalSize())
+		p.stats.Elements += size
+		p.stats.PaddedElements += int64(shapes[i].TotalSize()) - size
+	}
+
+	defer b.m.Reset()
+	if err = b.m.RunAll(); err != nil {
+		return nil, err
+	}
+	retVal := make([]Value, len(b.outputs))
+	for i, n := range b.outputs {
+		if retVal[i], err = CloneValue(n.Value()); err != nil {
+			return nil, errors.Wrapf(err, "Failed to copy output %d", i)
+		}
+	}
+	return retVal, nil
+}
+
+// bucketShape returns the shape of the bucket of input i.
+func (p *BucketedProgram) bucketShape(i int, s tensor.Shape) (tensor.Shape, error) {
+	retVal := s.Clone()
+	if s.IsScalar() {
+		return retVal, nil
+	}
+	axes, ok := p.axes[i]
+	if len(p.axes) == 0 {
+		ok = true
+		axes = make([]int, len(s))
+		for a := range axes {
+			axes[a] = a
+		}
+	}
+	if !ok {
+		return retVal, nil
+	}
+	for _, a := range axes {
+		if a < 0 || a >= len(s) {
+			return nil, errors.Errorf("Cannot bucket axis %d of input %d of shape %v", a, i, s)
+		}
+		if retVal[a] = p.round(s[a]); retVal[a] < s[a] {
+			return nil, errors.Errorf("The bucketing rounded the size %d of axis %d of input %d down to %d", s[a], a, i, retVal[a])
+		}
+	}
+	return retVal, nil
+}
+
+// bucket returns the program of a bucket, building and compiling it if needed.
+func (p *BucketedProgram) bucket(key string, shapes []tensor.Shape) (*bucket, error) {
+	p.clock++
+	if b, ok := p.buckets[key]; ok {
+		p.stats.Hits++
+		b.lastUse = p.clock
+		return b, nil
+	}
+	p.stats.Misses++
+
+	g := NewGraph()
+	inputs, outputs, err := p.build(g, shapes)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to build the program of the bucket %v", key)
+	}
+	prog, locMap, err := Compile(g)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to compile the program of the bucket %v", key)
+	}
+
+	if p.maxPrograms > 0 && len(p.buckets) >= p.maxPrograms {
+		var victim string
+		for k, b := range p.buckets {
+			if victim == "" || b.lastUse < p.buckets[victim].lastUse {
+				victim = k
+			}
+		}
+		p.buckets[victim].m.Close()
+		delete(p.buckets, victim)
+		p.stats.Evictions++
+	}
+
+	opts := append([]VMOpt{WithPrecompiled(prog, locMap)}, p.vmOpts...)
+	b := &bucket{
+		g:       g,
+		inputs:  inputs,
+		outputs: outputs,
+		m:       NewTapeMachine(g, opts...),
+		lastUse: p.clock,
+	}
+	p.buckets[key] = b
+	logging.Log(logging.Compile, logging.Debug, "compiled a bucket", "bucket", key, "programs", len(p.buckets))
+	return b, nil
+}
+
+// Stats returns the statistics of the program.
+func (p *BucketedProgram) Stats() BucketStats {
+	p.Lock()
+	defer p.Unlock()
+	retVal := p.stats
+	retVal.Programs = len(p.buckets)
+	return retVal
+}
+
+// Close closes the machines of every program.
+func (p *BucketedProgram) Close() error {
+	p.Lock()
+	defer p.Unlock()
+	for k, b := range p.buckets {
+		b.m.Close()
+		delete(p.buckets, k)
+	}
+	return nil
+}
+
+// padValue returns a copy of v padded with zeros at the end of every axis up to shape, or v itself if it has the shape.
+func padValue(v Value, shape tensor.Shape) (Value, error) {
+	if v.Shape().Eq(shape) {
+		return v, nil
+	}
+	t, ok := v.(*tensor.Dense)
+	if !ok {
+		return nil, errors.Errorf(nyiTypeFail, "padValue", v)
+	}
+	if t.IsView() {
+		t = t.Materialize().(*tensor.Dense)
+	}
+
+	from := t.Shape()
+	retVal := tensor.New(tensor.Of(t.Dtype()), tensor.WithShape(shape.Clone()...))
+	strides := retVal.Strides()
+	src := reflect.ValueOf(t.Data())
+	if src.Kind() != reflect.Slice {
+		// the data of a tensor of one element is the element
+		one := reflect.MakeSlice(reflect.SliceOf(src.Type()), 1, 1)
+		one.Index(0).Set(src)
+		src = one
+	}
+	dst := reflect.ValueOf(retVal.Data())
+
+	// copy the rows of the last axis to their places in the padded tensor
+	row := from[len(from)-1]
+	if row == 0 {
+		return retVal, nil
+	}
+	idx := make([]int, len(from)-1)
+	for start := 0; start < src.Len(); start += row {
+		var off int
+		for a, i := range idx {
+			off += i * strides[a]
+		}
+		reflect.Copy(dst.Slice(off, off+row), src.Slice(start, start+row))
+		for a := len(idx) - 1; a >= 0; a-- {
+			if idx[a]++; idx[a] < from[a] {
+				break
+			}
+			idx[a] = 0
+		}
+	}
+	return retVal, nil
+}
@@ -0,0 +1,78 @@
+package gorgonia
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorgonia.org/tensor"
+)
+
+func TestBucketedProgram(t *testing.T) {
+	assert := assert.New(t)
+	var built []tensor.Shape
+	build := func(g *ExprGraph, shapes []tensor.Shape) (Nodes, Nodes, error) {
+		built = append(built, shapes[0])
+		x := NewMatrix(g, Float64, WithShape(shapes[0]...), WithName("x"))
+		y, err := Sum(Must(Square(x)), 1)
+		return Nodes{x}, Nodes{y}, err
+	}
+	p := NewBucketedProgram(build, WithBucketing(RoundToMultiple(4)), WithBucketAxes(0, 1), WithMaxPrograms(2))
+	defer p.Close()
+
+	run := func(rows, cols int) []float64 {
+		backing := make([]float64, rows*cols)
+		for i := range backing {
+			backing[i] = 1
+		}
+		out, err := p.Run(tensor.New(tensor.WithShape(rows, cols), tensor.WithBacking(backing)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return out[0].Data().([]float64)
+	}
+
+	assert.Equal([]float64{3, 3}, run(2, 3), "the padding is zeros")
+	assert.Equal([]float64{4, 4}, run(2, 4), "3 and 4 are in the same bucket")
+	assert.Equal([]tensor.Shape{{2, 4}}, built)
+	assert.Equal([]float64{5, 5}, run(2, 5))
+	assert.Equal([]float64{1, 1, 1}, run(3, 1), "the rows are not bucketed")
+	assert.Equal([]tensor.Shape{{2, 4}, {2, 8}, {3, 4}}, built)
+
+	s := p.Stats()
+	assert.Equal(2, s.Programs)
+	assert.Equal(1, s.Hits)
+	assert.Equal(3, s.Misses)
+	assert.Equal(1, s.Evictions)
+	assert.Equal(int64(6+8+10+3), s.Elements)
+	assert.Equal(int64(2+0+6+9), s.PaddedElements)
+	assert.InDelta(17.0/44, s.Waste(), 1e-9)
+
+	run(2, 3)
+	assert.Equal(4, len(built), "the least recently used program was closed")
+
+	_, err := p.Run(tensor.New(tensor.WithShape(2), tensor.WithBacking([]float64{1, 2})))
+	assert.Error(err)
+}
+
+func TestRoundToPowerOfTwo(t *testing.T) {
+	round := RoundToPowerOfTwo(8)
+	assert.Equal(t, 8, round(1))
+	assert.Equal(t, 8, round(8))
+	assert.Equal(t, 16, round(9))
+	assert.Equal(t, 64, round(33))
+}
+
+func TestPadValue(t *testing.T) {
+	v := tensor.New(tensor.WithShape(2, 2), tensor.WithBacking([]float64{1, 2, 3, 4}))
+	padded, err := padValue(v, tensor.Shape{3, 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []float64{1, 2, 0, 3, 4, 0, 0, 0, 0}, padded.Data())
+
+	one := tensor.New(tensor.WithShape(1, 1), tensor.WithBacking([]float32{5}))
+	if padded, err = padValue(one, tensor.Shape{1, 2}); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []float32{5, 0}, padded.Data())
+}
@@ -0,0 +1,186 @@
+package gorgonia
+
+import (
+	"github.com/pkg/errors"
+	"gorgonia.org/tensor"
+)
+
+// Builder wraps the construction of an expression graph. It records the first error that happens while building the
+// graph. Once an error has been recorded, every subsequent operation is a no-op that returns a node holding that
+// error, so a model can be written without checking errors on every line:
+//		b := NewBuilder(g)
+//		x := b.Matrix(Float64, WithShape(2, 3), WithName("x"))
+//		w := b.Matrix(Float64, WithShape(3, 2), WithName("w"), WithInit(GlorotU(1)))
+//		y := b.Rectify(b.Mul(x, w))
+//		cost := b.Mean(y)
+//		if err := b.Err(); err != nil {
+//			...
+//		}
+// The operations available on Builder are generated in api_builder_gen.go. Other functions may be used with Apply.
+type Builder struct {
+	g   *ExprGraph
+	err error
+}
+
+// NewBuilder creates a Builder for the given graph. If g is nil, a new graph is created.
+func NewBuilder(g *ExprGraph) *Builder {
+	if g == nil {
+		g = NewGraph()
+	}
+	return &Builder{g: g}
+}
+
+// Graph returns the graph being built.
+func (b *Builder) Graph() *ExprGraph { return b.g }
+
+// Err returns the first error that happened while building the graph.
+func (b *Builder) Err() error { return b.err }
+
+// Reset clears the recorded error, returning it.
+func (b *Builder) Reset() error {
+	err := b.err
+	b.err = nil
+	return err
+}
+
+// Apply records the error, if any. It is meant to be used with functions that do not have a Builder method:
+//		y := b.Apply(Conv2d(x, filter, kernelShape, pad, stride, dilation))
+// Note that unlike the Builder methods, the function passed in is evaluated even if an error has already been recorded.
+func (b *Builder) Apply(n *Node, err error) *Node {
+	if b.err != nil {
+		return errNode(b.err)
+	}
+	return b.record(n, err)
+}
+
+// ApplyOp applies the op to the children and records the error, if any. It is a no-op if an error has already been recorded.
+func (b *Builder) ApplyOp(op Op, children ...*Node) *Node {
+	if b.err != nil {
+		return errNode(b.err)
+	}
+	if err := checkFluent(children...); err != nil {
+		return b.record(nil, err)
+	}
+	return b.record(ApplyOp(op, children...))
+}
+
+// Grad performs symbolic differentiation of cost with regards to WRTs. It returns nil if an error has been recorded.
+func (b *Builder) Grad(cost *Node, WRTs ...*Node) Nodes {
+	if b.err != nil {
+		return nil
+	}
+	if err := checkFluent(append(Nodes{cost}, WRTs...)...); err != nil {
+		b.record(nil, err)
+		return nil
+	}
+	retVal, err := Grad(cost, WRTs...)
+	if err != nil {
+		b.record(nil, err)
+		return nil
+	}
+	return retVal
+}
+
+// Scalar creates a scalar input node in the graph. Any panics from bad construction options are recorded as errors.
+// The same goes for Vector, Matrix and Tensor.
+func (b *Builder) Scalar(t tensor.Dtype, opts ...NodeConsOpt) *Node {
+	return b.input(func() *Node { return NewScalar(b.g, t, opts...) })
+}
+
+// Vector creates a vector input node in the graph.
+func (b *Builder) Vector(t tensor.Dtype, opts ...NodeConsOpt) *Node {
+	return b.input(func() *Node { return NewVector(b.g, t, opts...) })
+}
+
+// Matrix creates a matrix input node in the graph.
+func (b *Builder) Matrix(t tensor.Dtype, opts ...NodeConsOpt) *Node {
+	return b.input(func() *Node { return NewMatrix(b.g, t, opts...) })
+}
+
+// Tensor creates an input node of the given dimensions in the graph.
+func (b *Builder) Tensor(t tensor.Dtype, dims int, opts ...NodeConsOpt) *Node {
+	return b.input(func() *Node { return NewTensor(b.g, t, dims, opts...) })
+}
+
+// Constant creates a constant node. Unlike NewConstant, a value that cannot be made into a constant is recorded as an error.
+func (b *Builder) Constant(v interface{}, opts ...NodeConsOpt) *Node {
+	return b.input(func() *Node { return NewConstant(v, opts...) })
+}
+
+// input calls fn, turning any panics into a recorded error.
+func (b *Builder) input(fn func() *Node) (retVal *Node) {
+	if b.err != nil {
+		return errNode(b.err)
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err, ok := r.(error)
+			if !ok {
+				err = errors.Errorf("%v", r)
+			}
+			retVal = b.record(nil, err)
+		}
+	}()
+	return fn()
+}
+
+// record records err if it's the first error, and returns the node that a Builder method should return.
+func (b *Builder) record(n *Node, err error) *Node {
+	if err != nil {
+		if b.err == nil {
+			b.err = err
+		}
+		return errNode(err)
+	}
+	return n
+}
+
+// result records the error held by n, if any.
+func (b *Builder) result(n *Node) *Node {
+	if err := n.Err(); err != nil && b.err == nil {
+		b.err = err
+	}
+	return n
+}
+
+func (b *Builder) do1(fn func(a *Node) (*Node, error), x *Node) *Node {
+	if b.err != nil {
+		return errNode(b.err)
+	}
+	return b.result(fluent1(fn, x))
+}
+
+func (b *Builder) do2(fn func(a, b *Node) (*Node, error), x, y *Node) *Node {
+	if b.err != nil {
+		return errNode(b.err)
+	}
+	return b.result(fluent2(fn, x, y))
+}
+
+func (b *Builder) doCmp(fn func(a, b *Node, retSame bool) (*Node, error), x, y *Node, retSame bool) *Node {
+	if b.err != nil {
+		return errNode(b.err)
+	}
+	return b.result(fluentCmp(fn, x, y, retSame))
+}
+
+func (b *Builder) doAxial(fn func(a *Node, axes ...int) (*Node, error), x *Node, axes []int) *Node {
+	if b.err != nil {
+		return errNode(b.err)
+	}
+	return b.result(fluentAxial(fn, x, axes))
+}
+
+func (b *Builder) doShape(fn func(a *Node, s tensor.Shape) (*Node, error), x *Node, s tensor.Shape) *Node {
+	if b.err != nil {
+		return errNode(b.err)
+	}
+	return b.result(fluentShape(fn, x, s))
+}
+
+func (b *Builder) doDtype(fn func(a *Node, dt tensor.Dtype) (*Node, error), x *Node, dt tensor.Dtype) *Node {
+	if b.err != nil {
+		return errNode(b.err)
+	}
+	return b.result(fluentDtype(fn, x, dt))
+}
@@ -0,0 +1,66 @@
+package gorgonia
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorgonia.org/tensor"
+)
+
+func TestBuilder(t *testing.T) {
+	assert := assert.New(t)
+	b := NewBuilder(nil)
+	x := b.Matrix(Float64, WithShape(2, 2), WithName("x"), WithValue(tensor.New(tensor.WithShape(2, 2), tensor.WithBacking([]float64{1, -2, 3, -4}))))
+	w := b.Matrix(Float64, WithShape(2, 2), WithName("w"), WithValue(tensor.New(tensor.WithShape(2, 2), tensor.WithBacking([]float64{1, 0, 0, 1}))))
+	y := b.Relu(b.Mul(x, w))
+	cost := b.Sum(y)
+	grads := b.Grad(cost, w)
+	if err := b.Err(); err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(grads, 1)
+
+	m := NewTapeMachine(b.Graph())
+	defer m.Close()
+	if err := m.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]float64{1, 0, 3, 0}, y.Value().Data())
+	assert.Equal(4.0, cost.Value().Data())
+}
+
+func TestBuilder_Errors(t *testing.T) {
+	assert := assert.New(t)
+	g := NewGraph()
+	b := NewBuilder(g)
+	x := b.Matrix(Float64, WithShape(2, 3), WithName("x"))
+	v := b.Vector(Float64, WithShape(2), WithName("v"))
+
+	bad := b.Add(x, v)
+	assert.Error(bad.Err())
+	firstErr := b.Err()
+	assert.Equal(firstErr, bad.Err())
+
+	// subsequent ops are no-ops
+	before := len(g.AllNodes())
+	y := b.Sigmoid(x)
+	assert.Equal(firstErr, y.Err())
+	assert.Nil(b.Grad(b.Sum(x), x))
+	assert.Equal(firstErr, b.Apply(Neg(x)).Err())
+	assert.Equal(firstErr, b.ApplyOp(newElemUnaryOp(negOpType, x), x).Err())
+	assert.Equal(firstErr, b.Scalar(Float64).Err())
+	assert.Equal(before, len(g.AllNodes())-1, "only the Neg passed to Apply should have been added")
+	assert.Equal(firstErr, b.Err())
+
+	assert.Equal(firstErr, b.Reset())
+	assert.Nil(b.Err())
+
+	// construction panics are recorded
+	s := b.Matrix(Float64, WithShape(2, 3, 4))
+	assert.Error(s.Err())
+	assert.Error(b.Reset())
+
+	// so are errors from Apply
+	_ = b.Apply(nil, firstErr)
+	assert.Equal(firstErr, b.Err())
+}
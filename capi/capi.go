@@ -0,0 +1,229 @@
+package main
+
+/*
+#include <stdint.h>
+#include <stddef.h>
+
+// The element types of the inputs and outputs, which are the data types of ONNX.
+enum {
+	GORGONIA_FLOAT = 1,
+	GORGONIA_UINT8 = 2,
+	GORGONIA_INT8 = 3,
+	GORGONIA_UINT16 = 4,
+	GORGONIA_INT16 = 5,
+	GORGONIA_INT32 = 6,
+	GORGONIA_INT64 = 7,
+	GORGONIA_BOOL = 9,
+	GORGONIA_DOUBLE = 11,
+	GORGONIA_UINT32 = 12,
+	GORGONIA_UINT64 = 13,
+};
+*/
+import "C"
+
+import (
+	"io/ioutil"
+	"unsafe"
+
+	"gorgonia.org/gorgonia/onnx"
+)
+
+func main() {}
+
+// gorgonia_load_onnx loads the ONNX model of a file, and returns the handle of its session, or 0 on error. The shapes of
+// the inputs that are only known at run time are given as "name=d0,d1,...;name=...", or NULL.
+//
+//export gorgonia_load_onnx
+func gorgonia_load_onnx(path, shapes *C.char) C.int64_t {
+	p, err := ioutil.ReadFile(C.GoString(path))
+	if err != nil {
+		fail(err)
+		return 0
+	}
+	return loadC(p, shapes)
+}
+
+// gorgonia_load_onnx_bytes is gorgonia_load_onnx of the n bytes of an ONNX model in memory.
+//
+//export gorgonia_load_onnx_bytes
+func gorgonia_load_onnx_bytes(data unsafe.Pointer, n C.size_t, shapes *C.char) C.int64_t {
+	return loadC(C.GoBytes(data, C.int(n)), shapes)
+}
+
+func loadC(p []byte, shapes *C.char) C.int64_t {
+	var s string
+	if shapes != nil {
+		s = C.GoString(shapes)
+	}
+	h, err := load(p, s)
+	if err != nil {
+		fail(err)
+		return 0
+	}
+	return C.int64_t(h)
+}
+
+// gorgonia_last_error returns the message of the error of the last failed call of any thread, or NULL. The string must
+// be freed by the caller.
+//
+//export gorgonia_last_error
+func gorgonia_last_error() *C.char {
+	mu.Lock()
+	defer mu.Unlock()
+	if lastErr == nil {
+		return nil
+	}
+	return C.CString(lastErr.Error())
+}
+
+// gorgonia_free frees the session of a handle.
+//
+//export gorgonia_free
+func gorgonia_free(h C.int64_t) { free(int64(h)) }
+
+// gorgonia_num_inputs returns the number of inputs of a model, or -1 on error.
+//
+//export gorgonia_num_inputs
+func gorgonia_num_inputs(h C.int64_t) C.int {
+	s, err := get(int64(h))
+	if err != nil {
+		return C.int(fail(err))
+	}
+	return C.int(len(s.model.Inputs))
+}
+
+// gorgonia_num_outputs returns the number of outputs of a model, or -1 on error.
+//
+//export gorgonia_num_outputs
+func gorgonia_num_outputs(h C.int64_t) C.int {
+	s, err := get(int64(h))
+	if err != nil {
+		return C.int(fail(err))
+	}
+	return C.int(len(s.model.Outputs))
+}
+
+// gorgonia_name writes the name of the input (or the output if output is not 0) i of a model to buf, truncated to size
+// bytes including the ending NUL, and returns the length of the name, or -1 on error.
+//
+//export gorgonia_name
+func gorgonia_name(h C.int64_t, i C.int, output C.int, buf *C.char, size C.size_t) C.int {
+	s, err := get(int64(h))
+	if err != nil {
+		return C.int(fail(err))
+	}
+	_, name, err := s.node(int(i), output != 0)
+	if err != nil {
+		return C.int(fail(err))
+	}
+	if size > 0 {
+		dst := cBytes(unsafe.Pointer(buf), int(size))
+		dst[copy(dst[:len(dst)-1], name)] = 0
+	}
+	return C.int(len(name))
+}
+
+// gorgonia_shape writes the data type of the input (or the output if output is not 0) i of a model to dtype, and up to
+// ndims of its dimensions to dims, and returns its number of dimensions, or -1 on error.
+//
+//export gorgonia_shape
+func gorgonia_shape(h C.int64_t, i C.int, output C.int, dtype *C.int, dims *C.int64_t, ndims C.int) C.int {
+	s, err := get(int64(h))
+	if err != nil {
+		return C.int(fail(err))
+	}
+	n, _, err := s.node(int(i), output != 0)
+	if err != nil {
+		return C.int(fail(err))
+	}
+	dt, err := onnx.DataTypeOf(n.Dtype())
+	if err != nil {
+		return C.int(fail(err))
+	}
+	if dtype != nil {
+		*dtype = C.int(dt)
+	}
+	shape := n.Shape()
+	if dims != nil {
+		ds := (*[1 << 28]C.int64_t)(unsafe.Pointer(dims))[:ndims:ndims]
+		for j := 0; j < len(shape) && j < len(ds); j++ {
+			ds[j] = C.int64_t(shape[j])
+		}
+	}
+	return C.int(len(shape))
+}
+
+// gorgonia_set_input copies the elements of an input of a model, of the given data type and shape, in the row-major
+// order, and returns 0, or -1 on error. The data type and the shape must be those of the input.
+//
+//export gorgonia_set_input
+func gorgonia_set_input(h C.int64_t, name *C.char, dtype C.int, data unsafe.Pointer, dims *C.int64_t, ndims C.int) C.int {
+	s, err := get(int64(h))
+	if err != nil {
+		return C.int(fail(err))
+	}
+	shape := make([]int, int(ndims))
+	if ndims > 0 {
+		for j, d := range (*[1 << 28]C.int64_t)(unsafe.Pointer(dims))[:ndims:ndims] {
+			shape[j] = int(d)
+		}
+	}
+
+	s.Lock()
+	defer s.Unlock()
+	n, err := s.input(C.GoString(name), onnx.DataType(dtype), shape)
+	if err != nil {
+		return C.int(fail(err))
+	}
+	size := int(n.Dtype().Size())
+	for _, d := range shape {
+		size *= d
+	}
+	if err = s.setInput(C.GoString(name), onnx.DataType(dtype), C.GoBytes(data, C.int(size)), shape); err != nil {
+		return C.int(fail(err))
+	}
+	return 0
+}
+
+// gorgonia_run runs a model on its inputs, and returns 0, or -1 on error.
+//
+//export gorgonia_run
+func gorgonia_run(h C.int64_t) C.int {
+	s, err := get(int64(h))
+	if err != nil {
+		return C.int(fail(err))
+	}
+	s.Lock()
+	defer s.Unlock()
+	if err = s.run(); err != nil {
+		return C.int(fail(err))
+	}
+	return 0
+}
+
+// gorgonia_get_output copies the elements of the output i of the last run of a model to data, which holds size bytes,
+// and returns the number of bytes of the output, or -1 on error. Nothing is copied if size is too small, so the number
+// of bytes can be queried with a size of 0. The elements of the integer types of Go are copied as 64-bit integers.
+//
+//export gorgonia_get_output
+func gorgonia_get_output(h C.int64_t, i C.int, data unsafe.Pointer, size C.size_t) C.int64_t {
+	s, err := get(int64(h))
+	if err != nil {
+		return C.int64_t(fail(err))
+	}
+	s.Lock()
+	defer s.Unlock()
+	tp, err := s.output(int(i))
+	if err != nil {
+		return C.int64_t(fail(err))
+	}
+	if len(tp.RawData) <= int(size) && len(tp.RawData) > 0 {
+		copy(cBytes(data, len(tp.RawData)), tp.RawData)
+	}
+	return C.int64_t(len(tp.RawData))
+}
+
+// cBytes returns the n bytes of C memory at p.
+func cBytes(p unsafe.Pointer, n int) []byte {
+	return (*[1 << 30]byte)(p)[:n:n]
+}
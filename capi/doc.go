@@ -0,0 +1,20 @@
+// Capi is a shared library of C bindings for running ONNX models with Gorgonia, so that the inference can be embedded
+// in programs written in C, or in any language that calls C, such as Rust or Python. It is built with
+//
+//	go build -buildmode=c-shared -o libgorgonia.so gorgonia.org/gorgonia/capi
+//
+// which also writes the header libgorgonia.h. A model is loaded into a session, which is referred to by a handle:
+//
+//	int64_t h = gorgonia_load_onnx("model.onnx", "x=1,784");
+//	if (!h) { char *err = gorgonia_last_error(); ...; free(err); }
+//	int64_t dims[] = {1, 784};
+//	gorgonia_set_input(h, "x", GORGONIA_FLOAT, x, dims, 2);
+//	gorgonia_run(h);
+//	gorgonia_get_output(h, 0, y, sizeof y);
+//	gorgonia_free(h);
+//
+// The functions return -1, or a handle of 0, on error; gorgonia_last_error then describes it. The elements are copied
+// in and out of the sessions, in the row-major order, and have the element types of ONNX, of which the header defines
+// the constants. The calls of a session are serialized, so a session may be shared by threads, which then run it in
+// turn; independent sessions run in parallel.
+package main
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	G "gorgonia.org/gorgonia"
+	"gorgonia.org/gorgonia/onnx"
+	"gorgonia.org/tensor"
+)
+
+// session is a loaded model and the machine that runs it. The calls of a session are serialized.
+type session struct {
+	sync.Mutex
+	model *onnx.Model
+	vm    G.VM
+}
+
+var (
+	mu       sync.Mutex
+	sessions       = make(map[int64]*session)
+	next     int64 = 1
+	lastErr  error
+)
+
+// fail records the error of the last failed call, and returns the error code of the C functions.
+func fail(err error) int {
+	mu.Lock()
+	lastErr = err
+	mu.Unlock()
+	return -1
+}
+
+// load imports an ONNX model and returns the handle of its session. The shapes of the inputs that are only known at run
+// time are given as "name=d0,d1,...;name=...".
+func load(p []byte, shapes string) (int64, error) {
+	opts, err := parseShapes(shapes)
+	if err != nil {
+		return 0, err
+	}
+	m := new(onnx.ModelProto)
+	if err = m.Unmarshal(p); err != nil {
+		return 0, errors.Wrap(err, "Cannot decode the ONNX model")
+	}
+	model, err := onnx.Import(m, opts...)
+	if err != nil {
+		return 0, err
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	h := next
+	next++
+	sessions[h] = &session{model: model, vm: G.NewTapeMachine(model.Graph)}
+	return h, nil
+}
+
+func parseShapes(s string) ([]onnx.ImportOpt, error) {
+	var opts []onnx.ImportOpt
+	for _, kv := range strings.Split(s, ";") {
+		if strings.TrimSpace(kv) == "" {
+			continue
+		}
+		i := strings.Index(kv, "=")
+		if i < 0 {
+			return nil, errors.Errorf("Expected name=d0,d1,... Got %q instead", kv)
+		}
+		var shape []int
+		for _, d := range strings.Split(kv[i+1:], ",") {
+			n, err := strconv.Atoi(strings.TrimSpace(d))
+			if err != nil || n <= 0 {
+				return nil, errors.Errorf("Invalid dimension %q of the input %q", d, kv[:i])
+			}
+			shape = append(shape, n)
+		}
+		opts = append(opts, onnx.WithInputShape(strings.TrimSpace(kv[:i]), shape...))
+	}
+	return opts, nil
+}
+
+// get returns the session of a handle.
+func get(h int64) (*session, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	s, ok := sessions[h]
+	if !ok {
+		return nil, errors.Errorf("Invalid handle %d", h)
+	}
+	return s, nil
+}
+
+// free closes the session of a handle.
+func free(h int64) {
+	mu.Lock()
+	s, ok := sessions[h]
+	delete(sessions, h)
+	mu.Unlock()
+	if ok {
+		s.Lock()
+		s.vm.Close()
+		s.Unlock()
+	}
+}
+
+// node returns an input or output node, and its ONNX name.
+func (s *session) node(i int, output bool) (*G.Node, string, error) {
+	nodes, names := s.model.Inputs, s.model.InputNames
+	if output {
+		nodes, names = s.model.Outputs, s.model.OutputNames
+	}
+	if i < 0 || i >= len(nodes) {
+		return nil, "", errors.Errorf("Index %d out of range of %d values", i, len(nodes))
+	}
+	return nodes[i], names[i], nil
+}
+
+// input returns the input node of the given name, after checking that it is of the given data type and shape.
+func (s *session) input(name string, dt onnx.DataType, shape []int) (*G.Node, error) {
+	n := s.model.Input(name)
+	if n == nil {
+		return nil, errors.Errorf("The model has no input %q", name)
+	}
+	want, err := onnx.DataTypeOf(n.Dtype())
+	if err != nil {
+		return nil, err
+	}
+	if dt != want {
+		return nil, errors.Errorf("The input %q is of the data type %d. Got %d instead", name, want, dt)
+	}
+	if !n.Shape().Eq(tensor.Shape(shape)) {
+		return nil, errors.Errorf("The input %q has the shape %v. Got %v instead", name, n.Shape(), shape)
+	}
+	return n, nil
+}
+
+// setInput binds the little-endian elements of data to an input, which must be of the given data type and shape.
+func (s *session) setInput(name string, dt onnx.DataType, data []byte, shape []int) error {
+	n, err := s.input(name, dt, shape)
+	if err != nil {
+		return err
+	}
+	tp := &onnx.TensorProto{Name: name, DataType: dt, RawData: data}
+	for _, d := range shape {
+		tp.Dims = append(tp.Dims, int64(d))
+	}
+	t, err := tp.Tensor()
+	if err != nil {
+		return err
+	}
+	if n.IsScalar() {
+		return G.Let(n, t.Data())
+	}
+	return G.Let(n, t)
+}
+
+func (s *session) run() error {
+	defer s.vm.Reset()
+	return s.vm.RunAll()
+}
+
+// output returns the value of an output after a run, as a TensorProto of RawData.
+func (s *session) output(i int) (*onnx.TensorProto, error) {
+	n, name, err := s.node(i, true)
+	if err != nil {
+		return nil, err
+	}
+	v := n.Value()
+	if v == nil {
+		return nil, errors.Errorf("The output %q has no value: the model has not run", name)
+	}
+	t, ok := v.(tensor.Tensor)
+	if !ok {
+		t = tensor.New(tensor.FromScalar(v.Data()))
+	}
+	return onnx.NewTensorProto(name, t)
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorgonia.org/gorgonia/onnx"
+)
+
+// affine is a model of y = x * w + b, whose batch size is only known at run time.
+func affine() []byte {
+	m := &onnx.ModelProto{
+		IRVersion:   7,
+		OpsetImport: []*onnx.OperatorSetID{{Version: 13}},
+		Graph: &onnx.GraphProto{
+			Node: []*onnx.NodeProto{{OpType: "Gemm", Input: []string{"x", "w", "b"}, Output: []string{"y"}}},
+			Initializer: []*onnx.TensorProto{
+				{Name: "w", Dims: []int64{2, 1}, DataType: onnx.Float, FloatData: []float32{2, -1}},
+				{Name: "b", Dims: []int64{1}, DataType: onnx.Float, FloatData: []float32{0.5}},
+			},
+			Input:  []*onnx.ValueInfoProto{{Name: "x", ElemType: onnx.Float, Shape: []onnx.Dimension{{Param: "N"}, {Value: 2}}}},
+			Output: []*onnx.ValueInfoProto{{Name: "y", ElemType: onnx.Float}},
+		},
+	}
+	return m.Marshal()
+}
+
+func float32Bytes(vs ...float32) []byte {
+	p := make([]byte, 4*len(vs))
+	for i, v := range vs {
+		binary.LittleEndian.PutUint32(p[4*i:], math.Float32bits(v))
+	}
+	return p
+}
+
+func TestSession(t *testing.T) {
+	assert := assert.New(t)
+	h, err := load(affine(), "x=3,2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer free(h)
+	s, err := get(h)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	n, name, err := s.node(0, false)
+	assert.NoError(err)
+	assert.Equal("x", name)
+	assert.Equal([]int{3, 2}, []int(n.Shape()))
+	_, name, err = s.node(0, true)
+	assert.NoError(err)
+	assert.Equal("y", name)
+	_, _, err = s.node(1, true)
+	assert.Error(err)
+
+	// the session runs again on new inputs
+	for _, c := range []struct{ x, y []float32 }{
+		{[]float32{1, 0, 0, 1, 1, 1}, []float32{2.5, -0.5, 1.5}},
+		{[]float32{2, 2, 0, 0, -1, 1}, []float32{2.5, 0.5, -2.5}},
+	} {
+		if err = s.setInput("x", onnx.Float, float32Bytes(c.x...), []int{3, 2}); err != nil {
+			t.Fatal(err)
+		}
+		if err = s.run(); err != nil {
+			t.Fatal(err)
+		}
+		out, err := s.output(0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal([]int64{3, 1}, out.Dims)
+		assert.Equal(float32Bytes(c.y...), out.RawData)
+	}
+
+	assert.Error(s.setInput("z", onnx.Float, float32Bytes(1, 2), []int{1, 2}), "no input z")
+	assert.Error(s.setInput("x", onnx.Double, make([]byte, 48), []int{3, 2}), "x is of float32")
+	assert.Error(s.setInput("x", onnx.Float, float32Bytes(1, 2), []int{1, 2}), "x is of shape (3, 2)")
+	assert.Error(s.setInput("x", onnx.Float, float32Bytes(1, 2), []int{3, 2}), "too few bytes")
+}
+
+func TestSession_errors(t *testing.T) {
+	assert := assert.New(t)
+	_, err := load(affine(), "")
+	assert.Error(err, "the batch size is only known at run time")
+	_, err = load(affine(), "x=3")
+	assert.Error(err, "x has 2 dimensions")
+	_, err = load([]byte{0x3a, 10}, "")
+	assert.Error(err, "malformed")
+	for _, shapes := range []string{"x", "x=a,2", "x=0,2"} {
+		_, err = load(affine(), shapes)
+		assert.Error(err, shapes)
+	}
+
+	h, err := load(affine(), " x = 1, 2 ; ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := get(h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = s.output(0)
+	assert.Error(err, "the model has not run")
+
+	free(h)
+	_, err = get(h)
+	assert.Error(err, "freed")
+	free(h) // freeing twice is harmless
+	_, err = get(0)
+	assert.Error(err)
+
+	fail(err)
+	assert.Equal(err, lastErr)
+}
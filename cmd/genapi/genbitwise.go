@@ -0,0 +1,97 @@
+package main
+
+import (
+	"io"
+	"text/template"
+)
+
+type BitwiseOpData struct {
+	OpType string // the bitwiseOpType
+	Name   string // name used in the kernel function
+	Symbol string // Go operator
+	Shift  bool   // shift operators take the RHS as a uint
+}
+
+type BitwiseDtype struct {
+	Title string // I, I8..., U...
+	Type  string // Go type
+}
+
+type BitwiseKernelData struct {
+	Ops    []BitwiseOpData
+	Dtypes []BitwiseDtype
+}
+
+var bitwiseOps = []BitwiseOpData{
+	{"bitAndOpType", "And", "&", false},
+	{"bitOrOpType", "Or", "|", false},
+	{"bitXorOpType", "Xor", "^", false},
+	{"shlOpType", "Shl", "<<", true},
+	{"shrOpType", "Shr", ">>", true},
+}
+
+var bitwiseDtypes = []BitwiseDtype{
+	{"I", "int"},
+	{"I8", "int8"},
+	{"I16", "int16"},
+	{"I32", "int32"},
+	{"I64", "int64"},
+	{"U", "uint"},
+	{"U8", "uint8"},
+	{"U16", "uint16"},
+	{"U32", "uint32"},
+	{"U64", "uint64"},
+}
+
+const bitwiseKernelRaw = `{{$ops := .Ops -}}
+{{range $dt := .Dtypes -}}
+{{range $op := $ops -}}
+// bit{{$op.Name}}{{$dt.Title}} performs a[i*as] {{$op.Symbol}} b[i*bs] for each element of retVal. as and bs are the strides of a and b, which are either 0 (scalar) or 1.
+func bit{{$op.Name}}{{$dt.Title}}(a, b, retVal []{{$dt.Type}}, as, bs int) {
+	for i := range retVal {
+		retVal[i] = a[i*as] {{$op.Symbol}} {{if $op.Shift}}uint(b[i*bs]){{else}}b[i*bs]{{end}}
+	}
+}
+
+{{end -}}
+{{end -}}
+
+// bitwiseKernel dispatches to the correct kernel given the op and the dtype of the slices. a, b and retVal must be slices of the same type.
+func bitwiseKernel(op bitwiseOpType, a, b, retVal interface{}, as, bs int) error {
+	switch at := a.(type) {
+	{{range $dt := .Dtypes -}}
+	case []{{$dt.Type}}:
+		bt, ok := b.([]{{$dt.Type}})
+		if !ok {
+			return errors.Errorf(typeMismatchFail, a, b)
+		}
+		rt, ok := retVal.([]{{$dt.Type}})
+		if !ok {
+			return errors.Errorf(typeMismatchFail, a, retVal)
+		}
+		switch op {
+		{{range $op := $ops -}}
+		case {{$op.OpType}}:
+			bit{{$op.Name}}{{$dt.Title}}(at, bt, rt, as, bs)
+		{{end -}}
+		default:
+			return errors.Errorf(nyiFail, "bitwiseKernel", op)
+		}
+	{{end -}}
+	default:
+		return errors.Errorf(nyiTypeFail, "bitwiseKernel", a)
+	}
+	return nil
+}
+`
+
+var bitwiseKernel *template.Template
+
+func init() {
+	bitwiseKernel = template.Must(template.New("BitwiseKernel").Funcs(funcmap).Parse(bitwiseKernelRaw))
+}
+
+func generateBitwiseKernels(outFile io.Writer) {
+	data := BitwiseKernelData{bitwiseOps, bitwiseDtypes}
+	bitwiseKernel.Execute(outFile, data)
+}
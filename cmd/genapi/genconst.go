@@ -0,0 +1,299 @@
+package main
+
+import (
+	"io"
+	"text/template"
+)
+
+const unaryConstRaw = `import (
+	"fmt"
+	"math"
+
+	"github.com/chewxy/math32"
+)
+
+var (
+	{{range $dt := .Dtypes -}}
+	/* {{$dt.Name}} */
+	{{range .Ops -}}
+	{{if .Group}}
+	// {{.Group}}
+	{{end -}}
+	{{.Op}}{{$dt.Suffix}} = s{{$dt.Suffix}}UnaryOperator({{if eq $dt.Suffix "f64"}}{{.F64}}{{else}}{{.F32}}{{end}})
+	{{end}}
+	{{end -}}
+)
+
+type ʘUnaryOperatorType byte
+
+const (
+	{{range $i, $op := .Ops -}}
+	{{if and $op.Group $i}}
+	// {{$op.Group}}
+	{{end -}}
+	{{$op.Op}}OpType{{if eq $i 0}} ʘUnaryOperatorType = iota{{end}}
+	{{end}}
+	maxʘUnaryOperator // delimits end of all possible unary ops
+)
+
+func (u ʘUnaryOperatorType) String() string {
+	if u >= maxʘUnaryOperator {
+		return fmt.Sprintf("UNSUPPORTED UNARY OPERATOR (%d); max: %d", u, maxʘUnaryOperator)
+	}
+
+	return ʘUnaryOpStrs[u]
+}
+
+// onnx returns the name of the equivalent ONNX operator. It returns "" if there is none.
+func (u ʘUnaryOperatorType) onnx() string {
+	if u >= maxʘUnaryOperator {
+		return ""
+	}
+	return ʘUnaryOpONNX[u]
+}
+
+// cudaKernel returns the name of the CUDA kernel (without the dtype suffix) in the elemunaryop module. It returns "" if there is none.
+func (u ʘUnaryOperatorType) cudaKernel() string {
+	if u >= maxʘUnaryOperator {
+		return ""
+	}
+	return ʘUnaryOpCUDA[u]
+}
+
+// ʘUnaryOpStrs is the string representation for a unaryOpType
+// It should be held constant.
+var ʘUnaryOpStrs = [maxʘUnaryOperator]string{
+	{{range .Ops -}}
+	"{{.Symbol}}",
+	{{end -}}
+}
+
+// ʘUnaryOpONNX holds the names of the equivalent ONNX operators
+// It should be held constant.
+var ʘUnaryOpONNX = [maxʘUnaryOperator]string{
+	{{range .Ops -}}
+	"{{.ONNX}}",
+	{{end -}}
+}
+
+// ʘUnaryOpCUDA holds the names of the CUDA kernels generated for each unaryOpType
+// It should be held constant.
+var ʘUnaryOpCUDA = [maxʘUnaryOperator]string{
+	{{range .Ops -}}
+	"{{if .CUDA64}}{{.Op}}{{end}}",
+	{{end -}}
+}
+
+// ʘUnaryOpDifferentiable is the array of whether a unary operator is differentiable
+// It should be held constant
+var ʘUnaryOpDifferentiable = [maxʘUnaryOperator]bool{
+	{{range .Ops -}}
+	{{.Diff}},
+	{{end -}}
+}
+
+var ʘUnaryOpDiffExprs = [maxʘUnaryOperator]func(x, y, gradY *Node) (*Node, error){
+	{{range .Ops -}}
+	{{if .Diff}}{{.Op}}DiffExpr{{else}}nondiffUnaryOpExpr{{end}},
+	{{end -}}
+}
+
+var ʘUnaryOpDiffFns = [maxʘUnaryOperator]func(x, y *Node) error{
+	{{range .Ops -}}
+	{{if .Diff}}{{.Op}}Diff{{else}}nondiffUnaryOp{{end}},
+	{{end -}}
+}
+{{range $dt := .Dtypes}}
+var s{{$dt.Suffix}}UnaryOperators = [maxʘUnaryOperator]*s{{$dt.Suffix}}UnaryOperator{
+	{{range .Ops -}}
+	&{{.Op}}{{$dt.Suffix}},
+	{{end -}}
+}
+{{end -}}
+`
+
+const binaryConstRaw = `import "gorgonia.org/tensor"
+
+var (
+	/* scalar-tensor float64 and vice versa */
+
+	{{range .Ops -}}
+	t{{.Op}} = {{if .Cmp}}denseCmpOp{{else}}denseBinOp{{end}}({{.Kernel}})
+	{{end -}}
+)
+
+type denseBinOp func(a, b interface{}, opts ...tensor.FuncOpt) (tensor.Tensor, error)
+type denseCmpOp func(a, b interface{}, opts ...tensor.FuncOpt) (tensor.Tensor, error)
+
+type ʘBinaryOperatorType byte
+
+const (
+	{{range $i, $op := .Ops -}}
+	{{$op.Op}}OpType{{if eq $i 0}} ʘBinaryOperatorType = iota{{end}}
+	{{end}}
+	maxʘBinaryOpType // delimits the end of all possible binOpType
+)
+
+func (op ʘBinaryOperatorType) String() string {
+	return ʘBinOpStrs[op]
+}
+
+// onnx returns the name of the equivalent ONNX operator. It returns "" if there is none.
+func (op ʘBinaryOperatorType) onnx() string {
+	if op >= maxʘBinaryOpType {
+		return ""
+	}
+	return ʘBinOpONNX[op]
+}
+
+// cudaKernel returns the name of the CUDA kernels (without the suffixes) in the elembinop module. It returns "" if there are none.
+func (op ʘBinaryOperatorType) cudaKernel() string {
+	if op >= maxʘBinaryOpType {
+		return ""
+	}
+	return ʘBinOpCUDA[op]
+}
+
+// ʘBinOpStrs is the string representation for a binOpType
+// It should be held constant.
+var ʘBinOpStrs = [maxʘBinaryOpType]string{
+	{{range .Ops -}}
+	"{{.Symbol}}",
+	{{end -}}
+}
+
+// ʘBinOpNames is the string representation for a binOpType
+// It should be held constant.
+var ʘBinOpNames = [maxʘBinaryOpType]string{
+	{{range .Ops -}}
+	"{{.Op}}",
+	{{end -}}
+}
+
+// ʘBinOpONNX holds the names of the equivalent ONNX operators
+// It should be held constant.
+var ʘBinOpONNX = [maxʘBinaryOpType]string{
+	{{range .Ops -}}
+	"{{.ONNX}}",
+	{{end -}}
+}
+
+// ʘBinOpCUDA holds the names of the CUDA kernels generated for each binOpType
+// It should be held constant.
+var ʘBinOpCUDA = [maxʘBinaryOpType]string{
+	{{range .Ops -}}
+	"{{if .CUDA}}{{.Op}}{{end}}",
+	{{end -}}
+}
+
+// ʘBinOpCommutative is the array that stores whether a binary operator is commutative
+// It should be held constant.
+var ʘBinOpCommutative = [maxʘBinaryOpType]bool{
+	{{range .Ops -}}
+	{{.Commutative}},
+	{{end -}}
+}
+
+var ʘBinOpDiffExprs = [maxʘBinaryOpType]func(x, y, z, gradZ *Node) (Nodes, error){
+	{{range .Ops -}}
+	{{if .Cmp}}nondiffBinOpExpr{{else}}{{.Diff}}DiffExpr{{end}},
+	{{end -}}
+}
+
+var ʘBinOpDiffFns = [maxʘBinaryOpType]func(ctx ExecutionContext, x, y, z *Node) error{
+	{{range .Ops -}}
+	{{if .Cmp}}nondiffBinOp{{else}}{{.Diff}}Diff{{end}},
+	{{end -}}
+}
+
+// isCommutative gives info about whether the operator is commutative
+// For example:
+//		a + b == b + a
+// will ALWAYS evaluate to true. The same cannot be said about subtraction:
+// 		a - b != b - a
+// While a-b *may* be equal to b-a, it is not guaranteed. Therefore subtraction
+// is not commutative
+func (op ʘBinaryOperatorType) isCommutative() bool {
+	if op >= maxʘBinaryOpType {
+		panic("isCommutative() for unsupported BinOp undefined")
+	}
+	return ʘBinOpCommutative[op]
+}
+
+func (op ʘBinaryOperatorType) diffWRT(inputs int) []bool {
+	if inputs != 2 {
+		panic("binary operator only supports 2 inputs")
+	}
+
+	if op.isArith() {
+		return []bool{true, true}
+	}
+	return []bool{false, false}
+}
+
+// isArith indicates if the binary operator is an arithmetic type
+func (op ʘBinaryOperatorType) isArith() bool {
+	switch op {
+	case {{join .ArithOpTypes ", "}}:
+		return true
+	default:
+		return false
+	}
+}
+
+var binOps = [maxʘBinaryOpType]*denseBinOp{
+	{{range .Ops -}}
+	{{if .Cmp}}nil, // {{.Op}}{{else}}&t{{.Op}},{{end}}
+	{{end -}}
+}
+
+var cmpOps = [maxʘBinaryOpType]*denseCmpOp{
+	{{range .Ops -}}
+	{{if .Cmp}}&t{{.Op}},{{else}}nil, // {{.Op}}{{end}}
+	{{end -}}
+}
+`
+
+type unaryDtype struct {
+	Name   string // for the comments
+	Suffix string // f64, f32
+	Ops    []UnaryOpSpec
+}
+
+var (
+	unaryConst  *template.Template
+	binaryConst *template.Template
+)
+
+func init() {
+	unaryConst = template.Must(template.New("UnaryConst").Funcs(funcmap).Parse(unaryConstRaw))
+	binaryConst = template.Must(template.New("BinaryConst").Funcs(funcmap).Parse(binaryConstRaw))
+}
+
+func generateUnaryConst(outFile io.Writer) {
+	data := struct {
+		Ops    []UnaryOpSpec
+		Dtypes []unaryDtype
+	}{
+		Ops: unaryOpSpecs,
+		Dtypes: []unaryDtype{
+			{"float64", "f64", unaryOpSpecs},
+			{"float32", "f32", unaryOpSpecs},
+		},
+	}
+	unaryConst.Execute(outFile, data)
+}
+
+func generateBinaryConst(outFile io.Writer) {
+	var arith []string
+	for _, op := range binaryOpSpecs {
+		if !op.Cmp {
+			arith = append(arith, op.Op+"OpType")
+		}
+	}
+	data := struct {
+		Ops          []BinaryOpSpec
+		ArithOpTypes []string
+	}{binaryOpSpecs, arith}
+	binaryConst.Execute(outFile, data)
+}
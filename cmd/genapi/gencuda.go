@@ -0,0 +1,88 @@
+package main
+
+import (
+	"io"
+	"text/template"
+)
+
+const cudaPreamble = `#define _USE_MATH_DEFINES
+#include <math.h>
+
+#define THREADID \
+	int blockId = blockIdx.x + blockIdx.y * gridDim.x + gridDim.x * gridDim.y * blockIdx.z;\
+	int idx = blockId * (blockDim.x * blockDim.y * blockDim.z) + (threadIdx.z * (blockDim.x * blockDim.y)) + (threadIdx.y * blockDim.x) + threadIdx.x;
+
+#define CHECKSIZE \
+	if (idx >= size) { \
+		return; \
+	}
+`
+
+const cudaUnaryRaw = `
+#define UNARYOP(name, t, type, expr)\
+	__global__ void name ##_##t (type* A, int size) { \
+		THREADID \
+		CHECKSIZE \
+		type x = A[idx]; \
+		A[idx] = expr; \
+	}
+
+{{range .}}{{if .CUDA64 -}}
+extern "C" { UNARYOP({{.Op}}, f64, double, {{.CUDA64}}) }
+extern "C" { UNARYOP({{.Op}}, f32, float, {{.CUDA32}}) }
+{{end}}{{end -}}
+`
+
+const cudaBinaryRaw = `
+#define BINOP(name, t, type, expr)\
+	__global__ void  name ##_vv_ ##t(type* A, type* B, int size) { \
+		THREADID \
+		CHECKSIZE \
+		type a = A[idx]; type b = B[idx]; \
+		A[idx] = expr;} \
+	__global__ void  name ##_vs_ ##t(type* A, type* B, int size) { \
+		THREADID \
+		CHECKSIZE \
+		type a = A[idx]; type b = B[0]; \
+		A[idx] = expr;} \
+	__global__ void  name ##_sv_ ##t(type* A, type* B, int size) { \
+		THREADID \
+		CHECKSIZE \
+		type a = A[0]; type b = B[idx]; \
+		B[idx] = expr;} \
+	__global__ void  name ##_ss_ ##t(type* A, type* B, int size) { \
+		THREADID \
+		CHECKSIZE \
+		type a = A[0]; type b = B[0]; \
+		A[0] = expr;}
+
+{{range .}}{{if .CUDA -}}
+{{if .CUDAFn -}}
+extern "C" { BINOP({{.Op}}, f64, double, {{.CUDA}}(a, b)) }
+extern "C" { BINOP({{.Op}}, f32, float, {{.CUDA}}f(a, b)) }
+{{else -}}
+extern "C" { BINOP({{.Op}}, f64, double, a {{.CUDA}} b) }
+extern "C" { BINOP({{.Op}}, f32, float, a {{.CUDA}} b) }
+{{end}}
+{{end}}{{end -}}
+`
+
+var (
+	cudaUnary  *template.Template
+	cudaBinary *template.Template
+)
+
+func init() {
+	cudaUnary = template.Must(template.New("CUDAUnary").Funcs(funcmap).Parse(cudaUnaryRaw))
+	cudaBinary = template.Must(template.New("CUDABinary").Funcs(funcmap).Parse(cudaBinaryRaw))
+}
+
+func generateCUDAUnary(outFile io.Writer) {
+	io.WriteString(outFile, cudaPreamble)
+	cudaUnary.Execute(outFile, unaryOpSpecs)
+}
+
+func generateCUDABinary(outFile io.Writer) {
+	io.WriteString(outFile, cudaPreamble)
+	cudaBinary.Execute(outFile, binaryOpSpecs)
+}
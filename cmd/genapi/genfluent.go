@@ -0,0 +1,139 @@
+package main
+
+import (
+	"io"
+	"text/template"
+)
+
+// FluentSpec describes a fluent method on *Node (and the equivalent method on *Builder) that is not derived from the pointwise op specs.
+type FluentSpec struct {
+	Method string // name of the method
+	Func   string // the package level function the method calls
+	Kind   string // the signature of the function: unary, binary, cmp, axial, shape or dtype
+	Doc    string // the doc string, following the method name
+}
+
+// fluentOps are the non-pointwise functions that get a fluent method.
+var fluentOps = []FluentSpec{
+	{"Mul", "Mul", "binary", "performs a matrix multiplication, a matrix-vector multiplication, a vector dot product, or a scalar multiplication, depending on the shapes."},
+	{"MatMul", "Mul", "binary", "is an alias for Mul."},
+	{"Div", "Div", "binary", "performs a division. If b is a scalar, a broadcasted division is performed."},
+	{"OuterProd", "OuterProd", "binary", "returns the outer product of two vectors."},
+	{"Rectify", "Rectify", "unary", "applies the rectified linear unit: max(0, x)."},
+	{"Relu", "Rectify", "unary", "is an alias for Rectify."},
+	{"Mish", "Mish", "unary", "applies the mish activation function."},
+	{"SoftMax", "SoftMax", "axial", "applies the softmax along the given axes (the last axis if none are given)."},
+	{"Sum", "Sum", "axial", "sums along the given axes. All axes are summed if none are given."},
+	{"Mean", "Mean", "axial", "returns the mean along the given axes. The mean of all the elements is returned if no axes are given."},
+	{"Max", "Max", "axial", "returns the max value along the given axes. The max of all the elements is returned if no axes are given."},
+	{"Transpose", "Transpose", "axial", "transposes the node along the given axes. The axes are reversed if none are given."},
+	{"Reshape", "Reshape", "shape", "reshapes the node to the given shape."},
+	{"Cast", "Cast", "dtype", "converts the node to the given Dtype."},
+	{"Not", "Not", "unary", "performs a logical negation."},
+	{"And", "And", "binary", "performs a logical and."},
+	{"Or", "Or", "binary", "performs a logical or."},
+	{"Xor", "Xor", "binary", "performs a logical xor."},
+	{"Any", "Any", "axial", "returns true if any of the elements along the given axes are true."},
+	{"All", "All", "axial", "returns true if all of the elements along the given axes are true."},
+	{"IsNaN", "IsNaN", "unary", "returns a bool node that is true where the node is NaN."},
+	{"IsInf", "IsInf", "unary", "returns a bool node that is true where the node is an infinity."},
+	{"IsFinite", "IsFinite", "unary", "returns a bool node that is true where the node is neither NaN nor an infinity."},
+	{"StopGrad", "StopGrad", "unary", "returns a node with the same value through which no gradient flows."},
+}
+
+const fluentRaw = `import "gorgonia.org/tensor"
+
+{{range .Unary -}}
+// {{.Name}} {{.Doc}} It is the fluent version of {{.Name}}(n).
+func (n *Node) {{.Name}}() *Node { return fluent1({{.Name}}, n) }
+
+{{end -}}
+{{range .Binary -}}
+{{if .Cmp -}}
+// {{.Name}} {{.Doc}} It is the fluent version of {{.Name}}(n, b, retSame).
+func (n *Node) {{.Name}}(b *Node, retSame bool) *Node { return fluentCmp({{.Name}}, n, b, retSame) }
+{{else -}}
+// {{.Name}} {{.Doc}} It is the fluent version of {{.Name}}(n, b).
+func (n *Node) {{.Name}}(b *Node) *Node { return fluent2({{.Name}}, n, b) }
+{{end}}
+{{end -}}
+{{range .Others -}}
+{{if eq .Kind "unary" -}}
+// {{.Method}} {{.Doc}} It is the fluent version of {{.Func}}(n).
+func (n *Node) {{.Method}}() *Node { return fluent1({{.Func}}, n) }
+{{else if eq .Kind "binary" -}}
+// {{.Method}} {{.Doc}} It is the fluent version of {{.Func}}(n, b).
+func (n *Node) {{.Method}}(b *Node) *Node { return fluent2({{.Func}}, n, b) }
+{{else if eq .Kind "axial" -}}
+// {{.Method}} {{.Doc}} It is the fluent version of {{.Func}}(n, axes...).
+func (n *Node) {{.Method}}(axes ...int) *Node { return fluentAxial({{.Func}}, n, axes) }
+{{else if eq .Kind "shape" -}}
+// {{.Method}} {{.Doc}} It is the fluent version of {{.Func}}(n, s).
+func (n *Node) {{.Method}}(s tensor.Shape) *Node { return fluentShape({{.Func}}, n, s) }
+{{else if eq .Kind "dtype" -}}
+// {{.Method}} {{.Doc}} It is the fluent version of {{.Func}}(n, dt).
+func (n *Node) {{.Method}}(dt tensor.Dtype) *Node { return fluentDtype({{.Func}}, n, dt) }
+{{end}}
+{{end -}}
+`
+
+const builderRaw = `import "gorgonia.org/tensor"
+
+{{range .Unary -}}
+// {{.Name}} {{.Doc}} It is the Builder version of {{.Name}}(a).
+func (b *Builder) {{.Name}}(a *Node) *Node { return b.do1({{.Name}}, a) }
+
+{{end -}}
+{{range .Binary -}}
+{{if .Cmp -}}
+// {{.Name}} {{.Doc}} It is the Builder version of {{.Name}}(x, y, retSame).
+func (b *Builder) {{.Name}}(x, y *Node, retSame bool) *Node { return b.doCmp({{.Name}}, x, y, retSame) }
+{{else -}}
+// {{.Name}} {{.Doc}} It is the Builder version of {{.Name}}(x, y).
+func (b *Builder) {{.Name}}(x, y *Node) *Node { return b.do2({{.Name}}, x, y) }
+{{end}}
+{{end -}}
+{{range .Others -}}
+{{if eq .Kind "unary" -}}
+// {{.Method}} {{.Doc}} It is the Builder version of {{.Func}}(a).
+func (b *Builder) {{.Method}}(a *Node) *Node { return b.do1({{.Func}}, a) }
+{{else if eq .Kind "binary" -}}
+// {{.Method}} {{.Doc}} It is the Builder version of {{.Func}}(x, y).
+func (b *Builder) {{.Method}}(x, y *Node) *Node { return b.do2({{.Func}}, x, y) }
+{{else if eq .Kind "axial" -}}
+// {{.Method}} {{.Doc}} It is the Builder version of {{.Func}}(a, axes...).
+func (b *Builder) {{.Method}}(a *Node, axes ...int) *Node { return b.doAxial({{.Func}}, a, axes) }
+{{else if eq .Kind "shape" -}}
+// {{.Method}} {{.Doc}} It is the Builder version of {{.Func}}(a, s).
+func (b *Builder) {{.Method}}(a *Node, s tensor.Shape) *Node { return b.doShape({{.Func}}, a, s) }
+{{else if eq .Kind "dtype" -}}
+// {{.Method}} {{.Doc}} It is the Builder version of {{.Func}}(a, dt).
+func (b *Builder) {{.Method}}(a *Node, dt tensor.Dtype) *Node { return b.doDtype({{.Func}}, a, dt) }
+{{end}}
+{{end -}}
+`
+
+var fluent, builder *template.Template
+
+func init() {
+	fluent = template.Must(template.New("Fluent").Funcs(funcmap).Parse(fluentRaw))
+	builder = template.Must(template.New("Builder").Funcs(funcmap).Parse(builderRaw))
+}
+
+func generateFluentMethods(outFile io.Writer) {
+	data := struct {
+		Unary  []UnaryOpSpec
+		Binary []BinaryOpSpec
+		Others []FluentSpec
+	}{unaryOpSpecs, binaryOpSpecs, fluentOps}
+	fluent.Execute(outFile, data)
+}
+
+func generateBuilderMethods(outFile io.Writer) {
+	data := struct {
+		Unary  []UnaryOpSpec
+		Binary []BinaryOpSpec
+		Others []FluentSpec
+	}{unaryOpSpecs, binaryOpSpecs, fluentOps}
+	builder.Execute(outFile, data)
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"io"
+	"text/template"
+)
+
+type FMADtype struct {
+	Title string // F32, F64
+	Type  string // Go type
+}
+
+var fmaDtypes = []FMADtype{
+	{"F32", "float32"},
+	{"F64", "float64"},
+}
+
+// the kernels are unrolled by 4 and reslice their inputs so that bounds checks are eliminated from the inner loop.
+const fmaKernelRaw = `{{range . -}}
+// fma{{.Title}} computes retVal[i] = a[i]*b[i] + c[i]. All slices must be at least as long as retVal.
+func fma{{.Title}}(a, b, c, retVal []{{.Type}}) {
+	n := len(retVal)
+	a, b, c = a[:n], b[:n], c[:n]
+	i := 0
+	for ; i+4 <= n; i += 4 {
+		retVal[i] = a[i]*b[i] + c[i]
+		retVal[i+1] = a[i+1]*b[i+1] + c[i+1]
+		retVal[i+2] = a[i+2]*b[i+2] + c[i+2]
+		retVal[i+3] = a[i+3]*b[i+3] + c[i+3]
+	}
+	for ; i < n; i++ {
+		retVal[i] = a[i]*b[i] + c[i]
+	}
+}
+
+// scaleAdd{{.Title}} computes retVal[i] = a[i]*s + c[i]. All slices must be at least as long as retVal.
+func scaleAdd{{.Title}}(a []{{.Type}}, s {{.Type}}, c, retVal []{{.Type}}) {
+	n := len(retVal)
+	a, c = a[:n], c[:n]
+	i := 0
+	for ; i+4 <= n; i += 4 {
+		retVal[i] = a[i]*s + c[i]
+		retVal[i+1] = a[i+1]*s + c[i+1]
+		retVal[i+2] = a[i+2]*s + c[i+2]
+		retVal[i+3] = a[i+3]*s + c[i+3]
+	}
+	for ; i < n; i++ {
+		retVal[i] = a[i]*s + c[i]
+	}
+}
+
+// axpy{{.Title}} computes y[i] += alpha*x[i] in place. x must be at least as long as y.
+func axpy{{.Title}}(alpha {{.Type}}, x, y []{{.Type}}) {
+	n := len(y)
+	x = x[:n]
+	i := 0
+	for ; i+4 <= n; i += 4 {
+		y[i] += alpha * x[i]
+		y[i+1] += alpha * x[i+1]
+		y[i+2] += alpha * x[i+2]
+		y[i+3] += alpha * x[i+3]
+	}
+	for ; i < n; i++ {
+		y[i] += alpha * x[i]
+	}
+}
+
+{{end -}}
+`
+
+var fmaKernel *template.Template
+
+func init() {
+	fmaKernel = template.Must(template.New("FMAKernel").Funcs(funcmap).Parse(fmaKernelRaw))
+}
+
+func generateFMAKernels(outFile io.Writer) {
+	fmaKernel.Execute(outFile, fmaDtypes)
+}
@@ -1,12 +1,7 @@
 package main
 
 import (
-	"go/parser"
-	"go/token"
 	"io"
-	"log"
-	"path"
-	"strings"
 	"text/template"
 )
 
@@ -37,19 +32,9 @@ func init() {
 }
 
 func generateUnaryInterface(outFile io.Writer) {
-	// parse operator_unary_const.go
-	filename := path.Join(gorgonialoc, unaryOps)
-	fset := token.NewFileSet()
-	file, err := parser.ParseFile(fset, filename, nil, parser.AllErrors)
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	unaryNames := constTypes(file.Decls, "ʘUnaryOperatorType", "maxʘUnaryOperator")
 	var opNames []string
-	for _, v := range unaryNames {
-		op := strings.TrimSuffix(v, "OpType")
-		opNames = append(opNames, op)
+	for _, op := range unaryOpSpecs {
+		opNames = append(opNames, op.Op)
 	}
 
 	dtypes := []string{"f32", "f64"}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"io"
+	"text/template"
+)
+
+type IntArithOpData struct {
+	OpType string // the intArithOpType
+	Name   string // name used in the kernel function
+	Symbol string // Go operator
+}
+
+type IntArithDtype struct {
+	Title    string // I8, U8, I16
+	Type     string // Go type
+	Wide     string // a type that holds the exact results of the ops
+	Min, Max string
+}
+
+type IntArithKernelData struct {
+	Ops    []IntArithOpData
+	Dtypes []IntArithDtype
+}
+
+var intArithOps = []IntArithOpData{
+	{"AddOpType", "Add", "+"},
+	{"SubOpType", "Sub", "-"},
+	{"MulOpType", "Mul", "*"},
+}
+
+var intArithDtypes = []IntArithDtype{
+	{"I8", "int8", "int32", "math.MinInt8", "math.MaxInt8"},
+	{"U8", "uint8", "int32", "0", "math.MaxUint8"},
+	{"I16", "int16", "int32", "math.MinInt16", "math.MaxInt16"},
+}
+
+const intArithKernelRaw = `{{$ops := .Ops -}}
+{{range $dt := .Dtypes -}}
+{{range $op := $ops -}}
+// sat{{$op.Name}}{{$dt.Title}} performs a[i*as] {{$op.Symbol}} b[i*bs], clamped to the range of {{$dt.Type}}, for each element of retVal. as and bs are the strides of a and b, which are either 0 (scalar) or 1.
+func sat{{$op.Name}}{{$dt.Title}}(a, b, retVal []{{$dt.Type}}, as, bs int) {
+	for i := range retVal {
+		r := {{$dt.Wide}}(a[i*as]) {{$op.Symbol}} {{$dt.Wide}}(b[i*bs])
+		switch {
+		case r > {{$dt.Max}}:
+			r = {{$dt.Max}}
+		case r < {{$dt.Min}}:
+			r = {{$dt.Min}}
+		}
+		retVal[i] = {{$dt.Type}}(r)
+	}
+}
+
+// wrap{{$op.Name}}{{$dt.Title}} performs a[i*as] {{$op.Symbol}} b[i*bs], wrapping around on overflow, for each element of retVal. as and bs are the strides of a and b, which are either 0 (scalar) or 1.
+func wrap{{$op.Name}}{{$dt.Title}}(a, b, retVal []{{$dt.Type}}, as, bs int) {
+	for i := range retVal {
+		retVal[i] = a[i*as] {{$op.Symbol}} b[i*bs]
+	}
+}
+
+{{end -}}
+{{end -}}
+
+// intArithKernel dispatches to the correct kernel given the op and the dtype of the slices. a, b and retVal must be slices of the same type.
+func intArithKernel(op intArithOpType, a, b, retVal interface{}, as, bs int) error {
+	switch at := a.(type) {
+	{{range $dt := .Dtypes -}}
+	case []{{$dt.Type}}:
+		bt, ok := b.([]{{$dt.Type}})
+		if !ok {
+			return errors.Errorf(typeMismatchFail, a, b)
+		}
+		rt, ok := retVal.([]{{$dt.Type}})
+		if !ok {
+			return errors.Errorf(typeMismatchFail, a, retVal)
+		}
+		switch op {
+		{{range $op := $ops -}}
+		case sat{{$op.OpType}}:
+			sat{{$op.Name}}{{$dt.Title}}(at, bt, rt, as, bs)
+		case wrap{{$op.OpType}}:
+			wrap{{$op.Name}}{{$dt.Title}}(at, bt, rt, as, bs)
+		{{end -}}
+		default:
+			return errors.Errorf(nyiFail, "intArithKernel", op)
+		}
+	{{end -}}
+	default:
+		return errors.Errorf(nyiTypeFail, "intArithKernel", a)
+	}
+	return nil
+}
+`
+
+var intArithKernel *template.Template
+
+func init() {
+	intArithKernel = template.Must(template.New("IntArithKernel").Funcs(funcmap).Parse(intArithKernelRaw))
+}
+
+func generateIntArithKernels(outFile io.Writer) {
+	data := IntArithKernelData{intArithOps, intArithDtypes}
+	intArithKernel.Execute(outFile, data)
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"io"
+	"text/template"
+)
+
+const apiTestsRaw = `import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorgonia.org/tensor"
+)
+
+var unaryOpAPITests = []struct {
+	name   string
+	fn     func(*Node) (*Node, error)
+	op     ʘUnaryOperatorType
+	diff   bool
+	onnx   string
+	dtypes []tensor.Dtype
+}{
+	{{range .Unary -}}
+	{"{{.Name}}", {{.Name}}, {{.Op}}OpType, {{.Diff}}, "{{.ONNX}}", []tensor.Dtype{ {{- join .Dtypes ", " -}} }},
+	{{end -}}
+}
+
+var binaryOpAPITests = []struct {
+	name   string
+	fn     func(a, b *Node) (*Node, error)
+	op     ʘBinaryOperatorType
+	diff   bool
+	onnx   string
+	dtypes []tensor.Dtype
+}{
+	{{range .Binary -}}
+	{"{{.Name}}", {{if .Cmp}}func(a, b *Node) (*Node, error) { return {{.Name}}(a, b, true) }{{else}}{{.Name}}{{end}}, {{.Op}}OpType, {{not .Cmp}}, "{{.ONNX}}", []tensor.Dtype{ {{- join .Dtypes ", " -}} }},
+	{{end -}}
+}
+
+// TestCUDAKernelCoverage checks that every CUDA kernel registered for an op exists in the CUDA sources.
+func TestCUDAKernelCoverage(t *testing.T) {
+	unary, err := ioutil.ReadFile(filepath.Join("cuda modules", "src", "elemunaryop.cu"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	binary, err := ioutil.ReadFile(filepath.Join("cuda modules", "src", "elembinop.cu"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range unaryOpAPITests {
+		if k := tc.op.cudaKernel(); k != "" && !strings.Contains(string(unary), "UNARYOP("+k+",") {
+			t.Errorf("CUDA kernel for %v (%q) not found", tc.name, k)
+		}
+	}
+	for _, tc := range binaryOpAPITests {
+		if k := tc.op.cudaKernel(); k != "" && !strings.Contains(string(binary), "BINOP("+k+",") {
+			t.Errorf("CUDA kernel for %v (%q) not found", tc.name, k)
+		}
+	}
+}
+
+func TestGeneratedUnaryOps(t *testing.T) {
+	assert := assert.New(t)
+	for _, tc := range unaryOpAPITests {
+		assert.Equal(tc.onnx, tc.op.onnx(), "%v", tc.name)
+		for _, dt := range tc.dtypes {
+			g := NewGraph()
+			x := NewVector(g, dt, WithShape(3), WithName("x"), WithInit(RangedFrom(1)))
+			y, err := tc.fn(x)
+			if err != nil {
+				t.Errorf("%v(%v): %v", tc.name, dt, err)
+				continue
+			}
+			op, ok := y.op.(elemUnaryOp)
+			if !ok {
+				t.Errorf("%v(%v): expected an elemUnaryOp. Got %T instead", tc.name, dt, y.op)
+				continue
+			}
+			assert.Equal(tc.op, op.unaryOpType(), "%v(%v)", tc.name, dt)
+			assert.Equal([]bool{tc.diff}, op.DiffWRT(1), "%v(%v)", tc.name, dt)
+			assert.Equal(dt, y.Dtype(), "%v(%v)", tc.name, dt)
+
+			m := NewTapeMachine(g)
+			if err = m.RunAll(); err != nil {
+				t.Errorf("%v(%v): %v", tc.name, dt, err)
+			}
+			m.Close()
+		}
+	}
+}
+
+func TestGeneratedBinaryOps(t *testing.T) {
+	assert := assert.New(t)
+	for _, tc := range binaryOpAPITests {
+		assert.Equal(tc.onnx, tc.op.onnx(), "%v", tc.name)
+		for _, dt := range tc.dtypes {
+			g := NewGraph()
+			a := NewVector(g, dt, WithShape(3), WithName("a"), WithInit(RangedFrom(1)))
+			b := NewVector(g, dt, WithShape(3), WithName("b"), WithInit(RangedFrom(2)))
+			c, err := tc.fn(a, b)
+			if err != nil {
+				t.Errorf("%v(%v): %v", tc.name, dt, err)
+				continue
+			}
+			op, ok := c.op.(elemBinOp)
+			if !ok {
+				t.Errorf("%v(%v): expected an elemBinOp. Got %T instead", tc.name, dt, c.op)
+				continue
+			}
+			assert.Equal(tc.op, op.binOpType(), "%v(%v)", tc.name, dt)
+			assert.Equal([]bool{tc.diff, tc.diff}, op.DiffWRT(2), "%v(%v)", tc.name, dt)
+			assert.Equal(dt, c.Dtype(), "%v(%v)", tc.name, dt)
+
+			m := NewTapeMachine(g)
+			if err = m.RunAll(); err != nil {
+				t.Errorf("%v(%v): %v", tc.name, dt, err)
+			}
+			m.Close()
+		}
+	}
+}
+`
+
+var apiTests *template.Template
+
+func init() {
+	apiTests = template.Must(template.New("APITests").Funcs(funcmap).Parse(apiTestsRaw))
+}
+
+func generateAPITests(outFile io.Writer) {
+	data := struct {
+		Unary  []UnaryOpSpec
+		Binary []BinaryOpSpec
+	}{unaryOpSpecs, binaryOpSpecs}
+	apiTests.Execute(outFile, data)
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"io"
+	"text/template"
+)
+
+// WidenAcc is an accumulator type of a WidenInput.
+type WidenAcc struct {
+	Title string // I32, I64
+	Type  string // Go type
+	Dtype string // the tensor.Dtype
+}
+
+// WidenInput is a small input type, with the wider types its values are accumulated into.
+type WidenInput struct {
+	Title string // I8, I16
+	Type  string // Go type
+	Accs  []WidenAcc
+}
+
+var widenAccs = []WidenAcc{
+	{"I32", "int32", "tensor.Int32"},
+	{"I64", "int64", "tensor.Int64"},
+}
+
+var widenInputs = []WidenInput{
+	{"I8", "int8", widenAccs},
+	{"I16", "int16", widenAccs},
+}
+
+const widenKernelRaw = `{{range $in := . -}}
+{{range $acc := $in.Accs -}}
+// wideSum{{$in.Title}}{{$acc.Title}} sums the elements of a into a {{$acc.Type}}.
+func wideSum{{$in.Title}}{{$acc.Title}}(a []{{$in.Type}}) (retVal {{$acc.Type}}) {
+	for _, v := range a {
+		retVal += {{$acc.Type}}(v)
+	}
+	return
+}
+
+// wideDot{{$in.Title}}{{$acc.Title}} is the dot product of a and b, accumulated into a {{$acc.Type}}. b must be at least as long as a.
+func wideDot{{$in.Title}}{{$acc.Title}}(a, b []{{$in.Type}}) (retVal {{$acc.Type}}) {
+	b = b[:len(a)]
+	for i, v := range a {
+		retVal += {{$acc.Type}}(v) * {{$acc.Type}}(b[i])
+	}
+	return
+}
+
+// wideAddScalar{{$in.Title}}{{$acc.Title}} computes retVal[i] = {{$acc.Type}}(a[i]) + s. retVal must be at least as long as a.
+func wideAddScalar{{$in.Title}}{{$acc.Title}}(a []{{$in.Type}}, s {{$acc.Type}}, retVal []{{$acc.Type}}) {
+	retVal = retVal[:len(a)]
+	for i, v := range a {
+		retVal[i] = {{$acc.Type}}(v) + s
+	}
+}
+
+{{end -}}
+{{end -}}
+
+// wideSumKernel sums the elements of the slice a into a scalar of the accumulator dtype.
+func wideSumKernel(a interface{}, acc tensor.Dtype) (interface{}, error) {
+	switch at := a.(type) {
+	{{range $in := . -}}
+	case []{{$in.Type}}:
+		switch acc {
+		{{range $acc := $in.Accs -}}
+		case {{$acc.Dtype}}:
+			return wideSum{{$in.Title}}{{$acc.Title}}(at), nil
+		{{end -}}
+		}
+	{{end -}}
+	}
+	return nil, errors.Errorf(wideAccFail, a, acc)
+}
+
+// wideDotKernel is the dot product of the slices a and b, which must be of the same type, accumulated into a scalar of the accumulator dtype.
+func wideDotKernel(a, b interface{}, acc tensor.Dtype) (interface{}, error) {
+	switch at := a.(type) {
+	{{range $in := . -}}
+	case []{{$in.Type}}:
+		bt, ok := b.([]{{$in.Type}})
+		if !ok {
+			return nil, errors.Errorf(typeMismatchFail, a, b)
+		}
+		switch acc {
+		{{range $acc := $in.Accs -}}
+		case {{$acc.Dtype}}:
+			return wideDot{{$in.Title}}{{$acc.Title}}(at, bt), nil
+		{{end -}}
+		}
+	{{end -}}
+	}
+	return nil, errors.Errorf(wideAccFail, a, acc)
+}
+
+// wideAddScalarKernel adds the scalar s to the elements of the slice a, writing the results to retVal. s and retVal are of the accumulator type.
+func wideAddScalarKernel(a, s, retVal interface{}) error {
+	switch at := a.(type) {
+	{{range $in := . -}}
+	case []{{$in.Type}}:
+		switch st := s.(type) {
+		{{range $acc := $in.Accs -}}
+		case {{$acc.Type}}:
+			rt, ok := retVal.([]{{$acc.Type}})
+			if !ok {
+				return errors.Errorf(typeMismatchFail, s, retVal)
+			}
+			wideAddScalar{{$in.Title}}{{$acc.Title}}(at, st, rt)
+			return nil
+		{{end -}}
+		}
+	{{end -}}
+	}
+	return errors.Errorf(wideAccFail, a, s)
+}
+`
+
+var widenKernel *template.Template
+
+func init() {
+	widenKernel = template.Must(template.New("WidenKernel").Funcs(funcmap).Parse(widenKernelRaw))
+}
+
+func generateWidenKernels(outFile io.Writer) {
+	widenKernel.Execute(outFile, widenInputs)
+}
@@ -2,9 +2,6 @@ package main
 
 import (
 	"fmt"
-	"go/ast"
-	"go/parser"
-	"go/token"
 	"io"
 	"log"
 	"os"
@@ -19,6 +16,17 @@ const genmsg = "// Code generated by genapi, which is a API generation tool for
 const (
 	apigenOut = "api_gen.go"
 	unOpOut   = "operatorPointwise_unary_gen.go"
+	bitOpOut  = "operatorBitwise_gen.go"
+	fmaOut    = "operatorFMA_gen.go"
+	intOpOut  = "operatorIntArith_gen.go"
+	wideOpOut = "operatorWiden_gen.go"
+
+	apiTestOut = "api_gen_test.go"
+	fluentOut  = "api_fluent_gen.go"
+	builderOut = "api_builder_gen.go"
+	cudaSrc    = "cuda modules/src"
+	cuUnaryOut = "elemunaryop.cu"
+	cuBinOut   = "elembinop.cu"
 
 	// broadcastOpOut = "operations_broadcast.go"
 	unaryOps  = "operatorPointwise_unary_const.go"
@@ -31,6 +39,7 @@ var (
 
 var funcmap = template.FuncMap{
 	"lower": strings.ToLower,
+	"join":  strings.Join,
 }
 
 var (
@@ -40,42 +49,42 @@ var (
 	maybeBroadcastTemplate *template.Template
 )
 
-const unaryTemplateRaw = ` // {{.FnName}} performs a pointwise {{lower .FnName}}.
-func {{.FnName}}(a *Node) (*Node, error) { return unaryOpNode(newElemUnaryOp({{.OpType}}, a), a) }
+const unaryTemplateRaw = `// {{.Name}} {{.Doc}}
+func {{.Name}}(a *Node) (*Node, error) { return unaryOpNode(newElemUnaryOp({{.Op}}OpType, a), a) }
 `
 
-const binaryTemplateRaw = `// {{.FnName}} performs a pointwise {{lower .FnName}} operation.
-{{if .AsSame -}}// retSame indicates if the data type of the return value should be the same as the input data type. It defaults to Bool otherwise.
+const binaryTemplateRaw = `// {{.Name}} {{.Doc}}
+{{if .Cmp -}}// retSame indicates if the data type of the return value should be the same as the input data type. It defaults to Bool otherwise.
 {{end -}}
-func {{.FnName}}(a, b *Node{{if .AsSame}}, retSame bool{{end}}) (*Node, error) { {{if not .AsSame -}}return binOpNode(newElemBinOp({{.OpType}}, a, b), a, b) {{else -}}
-	op := newElemBinOp({{.OpType}}, a, b)
+func {{.Name}}(a, b *Node{{if .Cmp}}, retSame bool{{end}}) (*Node, error) { {{if not .Cmp -}}return binOpNode(newElemBinOp({{.Op}}OpType, a, b), a, b) {{else -}}
+	op := newElemBinOp({{.Op}}OpType, a, b)
 	op.retSame = retSame
 	return binOpNode(op, a, b)
 {{end -}}
 }
 `
 
-const broadcastTemplateRaw = `// Broadcast{{.FnName}} performs a {{lower .FnName}}. The operation is precomposed with a broadcast such that the shapes matches before operations commence.
-func Broadcast{{.FnName}}(a, b *Node{{if .AsSame}}, retSame bool{{end}}, leftPattern, rightPattern []byte)(*Node, error) {
+const broadcastTemplateRaw = `// Broadcast{{.Name}} performs a {{lower .Name}}. The operation is precomposed with a broadcast such that the shapes matches before operations commence.
+func Broadcast{{.Name}}(a, b *Node{{if .Cmp}}, retSame bool{{end}}, leftPattern, rightPattern []byte)(*Node, error) {
 	a2, b2, err := Broadcast(a, b, NewBroadcastPattern(leftPattern, rightPattern))
 	if err != nil {
 		return nil, err
 	}
-	return {{.FnName}}(a2, b2{{if .AsSame}}, retSame{{end}})
+	return {{.Name}}(a2, b2{{if .Cmp}}, retSame{{end}})
 }
 `
 
 // maybeBroadcast is the set of Broadcast functions in Golgi
-const maybeBroadcastTemplateRaw = `// Broadcast{{.FnName}} performs a {{lower .FnName}}. The operation is precomposed with a broadcast such that the shapes matches before operations commence.
-func Broadcast{{.FnName}}(a, b *G.Node{{if .AsSame}}, retSame bool{{end}}, leftPattern, rightPattern []byte)(*G.Node, error) {
+const maybeBroadcastTemplateRaw = `// Broadcast{{.Name}} performs a {{lower .Name}}. The operation is precomposed with a broadcast such that the shapes matches before operations commence.
+func Broadcast{{.Name}}(a, b *G.Node{{if .Cmp}}, retSame bool{{end}}, leftPattern, rightPattern []byte)(*G.Node, error) {
 	if a.Shape().Eq(b.Shape()){
-		return G.{{.FnName}}(a, b{{if .AsSame}}, retSame{{end}})
+		return G.{{.Name}}(a, b{{if .Cmp}}, retSame{{end}})
 	}
 	a2, b2, err := G.Broadcast(a, b, G.NewBroadcastPattern(leftPattern, rightPattern))
 	if err != nil {
 		return nil, err
 	}
-	return G.{{.FnName}}(a2, b2{{if .AsSame}}, retSame{{end}})
+	return G.{{.Name}}(a2, b2{{if .Cmp}}, retSame{{end}})
 }
 `
 
@@ -105,141 +114,99 @@ func init() {
 }
 
 func generateUnary(outFile io.Writer) {
-	// parse operator_unary_const.go
-	filename := path.Join(gorgonialoc, unaryOps)
-	fset := token.NewFileSet()
-	file, err := parser.ParseFile(fset, filename, nil, parser.AllErrors)
-	if err != nil {
-		log.Fatal(err)
+	for _, op := range unaryOpSpecs {
+		unaryTemplate.Execute(outFile, op)
 	}
+}
 
-	unaryNames := constTypes(file.Decls, "ʘUnaryOperatorType", "maxʘUnaryOperator")
-	for _, v := range unaryNames {
-		apiName := strings.Title(strings.TrimSuffix(v, "OpType"))
-		// legacy issue
-		if apiName == "Ln" {
-			apiName = "Log"
-		}
-		data := struct{ FnName, OpType string }{apiName, v}
-		unaryTemplate.Execute(outFile, data)
+func generateBinary(outFile io.Writer) {
+	for _, op := range binaryOpSpecs {
+		binaryTemplate.Execute(outFile, op)
 	}
+}
 
+func generateBroadcastBinOps(tmpl *template.Template, outFile io.Writer) {
+	for _, op := range binaryOpSpecs {
+		tmpl.Execute(outFile, op)
+	}
 }
 
-func generateBinary(outFile io.Writer) {
-	// parse operator_binary_const.go
-	filename := path.Join(gorgonialoc, binaryOps)
-	fset := token.NewFileSet()
-	file, err := parser.ParseFile(fset, filename, nil, parser.AllErrors)
+func generateAPI() {
+	outFileName := path.Join(gorgonialoc, apigenOut)
+	outFile, err := os.OpenFile(outFileName, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
 	if err != nil {
 		log.Fatal(err)
 	}
+	defer outFile.Close()
+	fmt.Fprintf(outFile, "package gorgonia\n\n%v\n\n", genmsg)
+	generateUnary(outFile)
+	generateBinary(outFile)
+	generateBroadcastBinOps(broadcastTemplate, outFile)
+}
 
-	binaryNames := constTypes(file.Decls, "ʘBinaryOperatorType", "maxʘBinaryOpType")
-	log.Printf("%v", binaryNames)
-	for _, v := range binaryNames {
-		apiName := strings.Title(strings.TrimSuffix(v, "OpType"))
-		// legacy issue
-		switch apiName {
-		case "Mul":
-			apiName = "HadamardProd"
-		case "Div":
-			apiName = "HadamardDiv"
+func generateConsts() {
+	for _, f := range []struct {
+		name string
+		gen  func(io.Writer)
+	}{{unaryOps, generateUnaryConst}, {binaryOps, generateBinaryConst}} {
+		outFileName := path.Join(gorgonialoc, f.name)
+		outFile, err := os.OpenFile(outFileName, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+		if err != nil {
+			log.Fatal(err)
 		}
-		data := struct {
-			FnName, OpType string
-			AsSame         bool
-		}{apiName, v, false}
-		switch apiName {
-		case "Lt", "Gt", "Lte", "Gte", "Eq", "Ne":
-			data.AsSame = true
+		fmt.Fprintf(outFile, "package gorgonia\n\n%v\n\n", genmsg)
+		f.gen(outFile)
+		outFile.Close()
+	}
+}
+
+func generateCUDA() {
+	for _, f := range []struct {
+		name string
+		gen  func(io.Writer)
+	}{{cuUnaryOut, generateCUDAUnary}, {cuBinOut, generateCUDABinary}} {
+		outFileName := path.Join(gorgonialoc, cudaSrc, f.name)
+		outFile, err := os.OpenFile(outFileName, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+		if err != nil {
+			log.Fatal(err)
 		}
-		binaryTemplate.Execute(outFile, data)
+		fmt.Fprintf(outFile, "%v\n\n", genmsg)
+		f.gen(outFile)
+		outFile.Close()
 	}
 }
 
-func generateBroadcastBinOps(tmpl *template.Template, outFile io.Writer) {
-	// parse operator_binary_const.go
-	filename := path.Join(gorgonialoc, binaryOps)
-	fset := token.NewFileSet()
-	file, err := parser.ParseFile(fset, filename, nil, parser.AllErrors)
+func generateFluent() {
+	outFileName := path.Join(gorgonialoc, fluentOut)
+	outFile, err := os.OpenFile(outFileName, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
 	if err != nil {
 		log.Fatal(err)
 	}
-
-	binaryNames := constTypes(file.Decls, "ʘBinaryOperatorType", "maxʘBinaryOpType")
-	log.Printf("%v", binaryNames)
-	for _, v := range binaryNames {
-		apiName := strings.Title(strings.TrimSuffix(v, "OpType"))
-		// legacy issue
-		switch apiName {
-		case "Mul":
-			apiName = "HadamardProd"
-		case "Div":
-			apiName = "HadamardDiv"
-		}
-		data := struct {
-			FnName, OpType string
-			AsSame         bool
-		}{apiName, v, false}
-		switch apiName {
-		case "Lt", "Gt", "Lte", "Gte", "Eq", "Ne":
-			data.AsSame = true
-		}
-		tmpl.Execute(outFile, data)
-	}
+	defer outFile.Close()
+	fmt.Fprintf(outFile, "package gorgonia\n\n%v\n\n", genmsg)
+	generateFluentMethods(outFile)
 }
 
-func constTypes(decls []ast.Decl, accept, max string) (names []string) {
-	for i, decl := range decls {
-		log.Printf("DECL %d: %T", i, decl)
-		switch d := decl.(type) {
-		case *ast.GenDecl:
-			if d.Tok.IsKeyword() && d.Tok.String() == "const" {
-				log.Printf("\t%v", d.Tok.String())
-
-				// get the type
-				if len(d.Specs) == 0 {
-					continue
-				}
-
-				var typename string
-				typ := d.Specs[0].(*ast.ValueSpec).Type
-				if typ == nil {
-					continue
-				}
-				if id, ok := typ.(*ast.Ident); ok {
-					typename = id.Name
-				}
-				if typename != accept {
-					continue
-				}
-
-				for _, spec := range d.Specs {
-					name := spec.(*ast.ValueSpec).Names[0].Name
-					if name == max {
-						continue
-					}
-					names = append(names, name)
-				}
-			}
-		default:
-		}
+func generateBuilder() {
+	outFileName := path.Join(gorgonialoc, builderOut)
+	outFile, err := os.OpenFile(outFileName, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		log.Fatal(err)
 	}
-	return
+	defer outFile.Close()
+	fmt.Fprintf(outFile, "package gorgonia\n\n%v\n\n", genmsg)
+	generateBuilderMethods(outFile)
 }
 
-func generateAPI() {
-	outFileName := path.Join(gorgonialoc, apigenOut)
+func generateTests() {
+	outFileName := path.Join(gorgonialoc, apiTestOut)
 	outFile, err := os.OpenFile(outFileName, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
 	if err != nil {
 		log.Fatal(err)
 	}
 	defer outFile.Close()
 	fmt.Fprintf(outFile, "package gorgonia\n\n%v\n\n", genmsg)
-	generateUnary(outFile)
-	generateBinary(outFile)
-	generateBroadcastBinOps(broadcastTemplate, outFile)
+	generateAPITests(outFile)
 }
 
 func generateInterfaces() {
@@ -253,6 +220,50 @@ func generateInterfaces() {
 	generateUnaryInterface(outFile)
 }
 
+func generateBitwise() {
+	outFileName := path.Join(gorgonialoc, bitOpOut)
+	outFile, err := os.OpenFile(outFileName, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer outFile.Close()
+	fmt.Fprintf(outFile, "package gorgonia\n\n%v\n\nimport \"github.com/pkg/errors\"\n\n", genmsg)
+	generateBitwiseKernels(outFile)
+}
+
+func generateFMA() {
+	outFileName := path.Join(gorgonialoc, fmaOut)
+	outFile, err := os.OpenFile(outFileName, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer outFile.Close()
+	fmt.Fprintf(outFile, "package gorgonia\n\n%v\n\n", genmsg)
+	generateFMAKernels(outFile)
+}
+
+func generateIntArith() {
+	outFileName := path.Join(gorgonialoc, intOpOut)
+	outFile, err := os.OpenFile(outFileName, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer outFile.Close()
+	fmt.Fprintf(outFile, "package gorgonia\n\n%v\n\nimport (\n\t\"math\"\n\n\t\"github.com/pkg/errors\"\n)\n\n", genmsg)
+	generateIntArithKernels(outFile)
+}
+
+func generateWiden() {
+	outFileName := path.Join(gorgonialoc, wideOpOut)
+	outFile, err := os.OpenFile(outFileName, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer outFile.Close()
+	fmt.Fprintf(outFile, "package gorgonia\n\n%v\n\nimport (\n\t\"github.com/pkg/errors\"\n\t\"gorgonia.org/tensor\"\n)\n\n", genmsg)
+	generateWidenKernels(outFile)
+}
+
 func generateGolgiAPI() {
 	outFileName := path.Join(golgiloc, apigenOut)
 	outFile, err := os.OpenFile(outFileName, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
@@ -265,8 +276,17 @@ func generateGolgiAPI() {
 }
 
 func main() {
+	// generateConsts()
 	// generateAPI()
+	// generateFluent()
+	// generateBuilder()
 	// generateInterfaces()
+	// generateCUDA()
+	// generateTests()
 	// functionSignatures()
+	// generateBitwise()
+	// generateFMA()
+	// generateIntArith()
+	// generateWiden()
 	generateGolgiAPI()
 }
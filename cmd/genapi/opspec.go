@@ -0,0 +1,116 @@
+package main
+
+// This file holds the declarative definitions of the pointwise ops. Everything genapi generates for the pointwise ops
+// (the op type constants and their tables, the API functions, the operator interfaces, the CUDA kernels and the tests) is derived from
+// the tables below. Adding a new unary or binary op only requires adding an entry here, and writing its kernels and
+// gradient functions.
+//
+// The order of the entries matters: it is the order of the op type constants.
+
+// UnaryOpSpec describes a pointwise unary op.
+type UnaryOpSpec struct {
+	Name   string // name of the API function
+	Op     string // prefix of the op type. The op type is {{Op}}OpType, and the kernels are {{Op}}f64 and {{Op}}f32
+	Symbol string // the string representation of the op
+	F64    string // the float64 kernel
+	F32    string // the float32 kernel
+	Diff   bool   // if true, {{Op}}DiffExpr and {{Op}}Diff must be defined
+	CUDA64 string // the CUDA expression of the float64 kernel in terms of x. No CUDA kernel is generated if empty
+	CUDA32 string // the CUDA expression of the float32 kernel in terms of x
+	ONNX   string // the equivalent ONNX operator, if any
+	Doc    string // the doc string of the API function, following the function name
+	Group  string // the comment that starts a group of ops
+}
+
+// BinaryOpSpec describes a pointwise binary op.
+type BinaryOpSpec struct {
+	Name        string // name of the API function
+	Op          string // prefix of the op type. The op type is {{Op}}OpType, and the kernel is t{{Op}}
+	Symbol      string // the string representation of the op
+	Kernel      string // the tensor function that performs the op
+	Cmp         bool   // comparison ops return Bool unless retSame is set. They are not differentiable
+	Commutative bool
+	Diff        string // prefix of the gradient functions: {{Diff}}DiffExpr and {{Diff}}Diff. Ignored for comparison ops
+	CUDA        string // the CUDA infix operator, or the CUDA function if CUDAFn is set. No CUDA kernels are generated if empty
+	CUDAFn      bool   // CUDA is a function, with the float32 version suffixed with "f" (e.g. pow and powf)
+	ONNX        string // the equivalent ONNX operator, if any
+	Doc         string // the doc string of the API function, following the function name
+}
+
+// Dtypes returns the dtypes that the generated tests exercise
+func (s UnaryOpSpec) Dtypes() []string { return []string{"Float64", "Float32"} }
+
+// Dtypes returns the dtypes that the generated tests exercise
+func (s BinaryOpSpec) Dtypes() []string { return []string{"Float64", "Float32"} }
+
+var unaryOpSpecs = []UnaryOpSpec{
+	{Name: "Abs", Op: "abs", Symbol: "abs", F64: "math.Abs", F32: "math32.Abs", Diff: true, CUDA64: "fabs(x)", CUDA32: "fabsf(x)", ONNX: "Abs",
+		Doc: "performs a pointwise absolute value.", Group: "non differentiable"},
+	{Name: "Sign", Op: "sign", Symbol: "sign", F64: "_signf64", F32: "_signf32", CUDA64: "(x > 0.0) - (x < 0.0)", CUDA32: "(x > 0.0f) - (x < 0.0f)", ONNX: "Sign",
+		Doc: "performs a pointwise sign: -1 for negative values, 1 for positive values and 0 for 0."},
+	{Name: "Ceil", Op: "ceil", Symbol: "ceil", F64: "math.Ceil", F32: "math32.Ceil", CUDA64: "ceil(x)", CUDA32: "ceilf(x)", ONNX: "Ceil",
+		Doc: "performs a pointwise ceil."},
+	{Name: "Floor", Op: "floor", Symbol: "floor", F64: "math.Floor", F32: "math32.Floor", CUDA64: "floor(x)", CUDA32: "floorf(x)", ONNX: "Floor",
+		Doc: "performs a pointwise floor."},
+
+	{Name: "Sin", Op: "sin", Symbol: "sin", F64: "math.Sin", F32: "math32.Sin", Diff: true, CUDA64: "sin(x)", CUDA32: "sinf(x)", ONNX: "Sin",
+		Doc: "performs a pointwise sin.", Group: "differentiable"},
+	{Name: "Cos", Op: "cos", Symbol: "cos", F64: "math.Cos", F32: "math32.Cos", Diff: true, CUDA64: "cos(x)", CUDA32: "cosf(x)", ONNX: "Cos",
+		Doc: "performs a pointwise cos."},
+	{Name: "Exp", Op: "exp", Symbol: "exp", F64: "math.Exp", F32: "math32.Exp", Diff: true, CUDA64: "exp(x)", CUDA32: "expf(x)", ONNX: "Exp",
+		Doc: "performs a pointwise exp."},
+	{Name: "Log", Op: "ln", Symbol: "ln", F64: "math.Log", F32: "math32.Log", Diff: true, CUDA64: "log(x)", CUDA32: "logf(x)", ONNX: "Log",
+		Doc: "performs a pointwise natural log."},
+	{Name: "Log2", Op: "log2", Symbol: "log2", F64: "math.Log2", F32: "math32.Log2", Diff: true, CUDA64: "log2(x)", CUDA32: "log2f(x)",
+		Doc: "performs a pointwise log2."},
+	{Name: "Neg", Op: "neg", Symbol: "neg", F64: "_negf64", F32: "_negf32", Diff: true, CUDA64: "-x", CUDA32: "-x", ONNX: "Neg",
+		Doc: "performs a pointwise negation."},
+	{Name: "Square", Op: "square", Symbol: "square", F64: "_squaref64", F32: "_squaref32", Diff: true, CUDA64: "x * x", CUDA32: "x * x",
+		Doc: "performs a pointwise square."},
+	{Name: "Sqrt", Op: "sqrt", Symbol: "sqrt", F64: "math.Sqrt", F32: "math32.Sqrt", Diff: true, CUDA64: "sqrt(x)", CUDA32: "sqrtf(x)", ONNX: "Sqrt",
+		Doc: "performs a pointwise square root."},
+	{Name: "Inverse", Op: "inverse", Symbol: "inv", F64: "_inversef64", F32: "_inversef32", Diff: true, CUDA64: "1.0 / x", CUDA32: "1.0f / x", ONNX: "Reciprocal",
+		Doc: "performs a pointwise multiplicative inverse: 1/x."},
+	{Name: "InverseSqrt", Op: "inverseSqrt", Symbol: "invSqrt", F64: "_inverseSqrtf64", F32: "_inverseSqrtf32", Diff: true, CUDA64: "rsqrt(x)", CUDA32: "rsqrtf(x)",
+		Doc: "performs a pointwise 1/sqrt(x)."},
+
+	{Name: "Cube", Op: "cube", Symbol: "cube", F64: "_cubef64", F32: "_cubef32", Diff: true, CUDA64: "x * x * x", CUDA32: "x * x * x",
+		Doc: "performs a pointwise cube.", Group: "typically used in activation functions"},
+	{Name: "Tanh", Op: "tanh", Symbol: "tanh", F64: "_tanhf64", F32: "_tanhf32", Diff: true, CUDA64: "tanh(x)", CUDA32: "tanhf(x)", ONNX: "Tanh",
+		Doc: "performs a pointwise tanh."},
+	{Name: "Sigmoid", Op: "sigmoid", Symbol: "sigmoid", F64: "_sigmoidf64", F32: "_sigmoidf32", Diff: true, CUDA64: "x < -709.0 ? 0.0 : (x > 19.0 ? 1.0 : 1.0 / (1.0 + exp(-x)))", CUDA32: "x < -88.0f ? 0.0f : (x > 15.0f ? 1.0f : 1.0f / (1.0f + expf(-x)))", ONNX: "Sigmoid",
+		Doc: "performs a pointwise sigmoid: 1/(1+exp(-x))."},
+
+	{Name: "Log1p", Op: "log1p", Symbol: "log1p", F64: "math.Log1p", F32: "math32.Log1p", Diff: true, CUDA64: "log1p(x)", CUDA32: "log1pf(x)",
+		Doc: "performs a pointwise log(1+x).", Group: "numerical stabilization optimization"},
+	{Name: "Expm1", Op: "expm1", Symbol: "expm1", F64: "math.Expm1", F32: "math32.Expm1", Diff: true, CUDA64: "expm1(x)", CUDA32: "expm1f(x)",
+		Doc: "performs a pointwise exp(x)-1."},
+	{Name: "Softplus", Op: "softplus", Symbol: "softplus", F64: "_softplusf64", F32: "_softplusf32", Diff: true, CUDA64: "x < -708.0 ? 0.0 : (x > 16.0 ? x : log1p(exp(x)))", CUDA32: "x < -103.0f ? 0.0f : (x > 14.0f ? x : log1pf(expf(x)))", ONNX: "Softplus",
+		Doc: "performs a pointwise softplus: log(1+exp(x))."},
+}
+
+var binaryOpSpecs = []BinaryOpSpec{
+	{Name: "Add", Op: "add", Symbol: "+", Kernel: "tensor.Add", Commutative: true, Diff: "add", CUDA: "+", ONNX: "Add",
+		Doc: "performs a pointwise add operation."},
+	{Name: "Sub", Op: "sub", Symbol: "-", Kernel: "tensor.Sub", Diff: "sub", CUDA: "-", ONNX: "Sub",
+		Doc: "performs a pointwise sub operation."},
+	{Name: "HadamardProd", Op: "mul", Symbol: "⊙", Kernel: "tensor.Mul", Commutative: true, Diff: "hadamardProd", CUDA: "*", ONNX: "Mul",
+		Doc: "performs a pointwise multiplication."},
+	{Name: "HadamardDiv", Op: "div", Symbol: "÷", Kernel: "tensor.Div", Diff: "hadamardDiv", CUDA: "/", ONNX: "Div",
+		Doc: "performs a pointwise division."},
+	{Name: "Pow", Op: "pow", Symbol: "^", Kernel: "tensor.Pow", Diff: "hadamardPow", CUDA: "pow", CUDAFn: true, ONNX: "Pow",
+		Doc: "performs a pointwise exponentiation."},
+
+	{Name: "Lt", Op: "lt", Symbol: "<", Kernel: "tensor.Lt", Cmp: true, CUDA: "<", ONNX: "Less",
+		Doc: "performs a pointwise less than comparison."},
+	{Name: "Gt", Op: "gt", Symbol: ">", Kernel: "tensor.Gt", Cmp: true, CUDA: ">", ONNX: "Greater",
+		Doc: "performs a pointwise greater than comparison."},
+	{Name: "Lte", Op: "lte", Symbol: "<=", Kernel: "tensor.Lte", Cmp: true, CUDA: "<=", ONNX: "LessOrEqual",
+		Doc: "performs a pointwise less than or equal comparison."},
+	{Name: "Gte", Op: "gte", Symbol: ">=", Kernel: "tensor.Gte", Cmp: true, CUDA: ">=", ONNX: "GreaterOrEqual",
+		Doc: "performs a pointwise greater than or equal comparison."},
+	{Name: "Eq", Op: "eq", Symbol: "==", Kernel: "tensor.ElEq", Cmp: true, Commutative: true, CUDA: "==", ONNX: "Equal",
+		Doc: "performs a pointwise equality comparison."},
+	{Name: "Ne", Op: "ne", Symbol: "!=", Kernel: "tensor.ElNe", Cmp: true, Commutative: true, CUDA: "!=",
+		Doc: "performs a pointwise inequality comparison."},
+}
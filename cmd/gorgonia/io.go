@@ -0,0 +1,254 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	G "gorgonia.org/gorgonia"
+	"gorgonia.org/gorgonia/onnx"
+	"gorgonia.org/tensor"
+)
+
+// namedFlag is a repeated flag of name=value pairs.
+type namedFlag struct {
+	names  []string
+	values []string
+}
+
+func (f *namedFlag) String() string {
+	var s []string
+	for i, n := range f.names {
+		s = append(s, n+"="+f.values[i])
+	}
+	return strings.Join(s, " ")
+}
+
+func (f *namedFlag) Set(s string) error {
+	i := strings.Index(s, "=")
+	if i <= 0 {
+		return errors.Errorf("Expected name=value. Got %q instead", s)
+	}
+	f.names = append(f.names, s[:i])
+	f.values = append(f.values, s[i+1:])
+	return nil
+}
+
+// shapes parses the values of a -shape flag, of the form name=d0,d1,...
+func (f *namedFlag) shapes() (map[string]tensor.Shape, error) {
+	retVal := make(map[string]tensor.Shape)
+	for i, name := range f.names {
+		var shape tensor.Shape
+		for _, d := range strings.Split(f.values[i], ",") {
+			n, err := strconv.Atoi(strings.TrimSpace(d))
+			if err != nil || n <= 0 {
+				return nil, errors.Errorf("Invalid dimension %q of the shape of %q", d, name)
+			}
+			shape = append(shape, n)
+		}
+		retVal[name] = shape
+	}
+	return retVal, nil
+}
+
+// readModel reads the ONNX model of a file. It is the only serialized form of the graphs that can be loaded: the other
+// encodings of the graphs, such as the dot files, cannot be read back.
+func readModel(path string) (*onnx.ModelProto, error) {
+	if ext := filepath.Ext(path); ext != ".onnx" && ext != ".pb" {
+		return nil, errors.Errorf("Cannot load %v: only the ONNX models (.onnx) are supported", path)
+	}
+	p, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	m := new(onnx.ModelProto)
+	if err = m.Unmarshal(p); err != nil {
+		return nil, errors.Wrapf(err, "Cannot decode the ONNX model %v", path)
+	}
+	return m, nil
+}
+
+// importModel imports a model with the given shapes of its inputs.
+func importModel(m *onnx.ModelProto, shapes map[string]tensor.Shape) (*onnx.Model, error) {
+	var opts []onnx.ImportOpt
+	for name, s := range shapes {
+		opts = append(opts, onnx.WithInputShape(name, s...))
+	}
+	return onnx.Import(m, opts...)
+}
+
+// readInput reads a value from a .npy file, or from a .json file of the JSON encoding of MarshalValue or of nested
+// arrays of numbers. The elements of the nested arrays are converted to the given Dtype.
+func readInput(path string, dt tensor.Dtype) (tensor.Tensor, error) {
+	p, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	switch filepath.Ext(path) {
+	case ".npy":
+		t := new(tensor.Dense)
+		if err = t.ReadNpy(bytes.NewReader(p)); err != nil {
+			return nil, errors.Wrapf(err, "Cannot read %v", path)
+		}
+		return t, nil
+	case ".json":
+		if trimmed := bytes.TrimSpace(p); len(trimmed) > 0 && trimmed[0] == '{' {
+			v, err := G.UnmarshalValue(p, G.JSONEncoding)
+			if err != nil {
+				return nil, errors.Wrapf(err, "Cannot read %v", path)
+			}
+			return asTensor(v), nil
+		}
+		var nested interface{}
+		if err = json.Unmarshal(p, &nested); err != nil {
+			return nil, errors.Wrapf(err, "Cannot read %v", path)
+		}
+		t, err := fromNested(nested, dt)
+		return t, errors.Wrapf(err, "Cannot read %v", path)
+	}
+	return nil, errors.Errorf("Cannot read %v: expected a .npy or a .json file", path)
+}
+
+// fromNested returns the tensor of nested arrays of numbers or booleans, whose shape is the lengths of the arrays.
+func fromNested(nested interface{}, dt tensor.Dtype) (tensor.Tensor, error) {
+	var shape tensor.Shape
+	for v := nested; ; {
+		a, ok := v.([]interface{})
+		if !ok {
+			break
+		}
+		shape = append(shape, len(a))
+		if len(a) == 0 {
+			return nil, errors.New("Empty arrays are not supported")
+		}
+		v = a[0]
+	}
+
+	backing := reflect.MakeSlice(reflect.SliceOf(dt.Type), 0, shape.TotalSize())
+	var walk func(v interface{}, dim int) error
+	walk = func(v interface{}, dim int) error {
+		if a, ok := v.([]interface{}); ok {
+			if dim >= len(shape) || len(a) != shape[dim] {
+				return errors.Errorf("The arrays are ragged: expected the shape %v", shape)
+			}
+			for _, e := range a {
+				if err := walk(e, dim+1); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		if dim != len(shape) {
+			return errors.Errorf("The arrays are ragged: expected the shape %v", shape)
+		}
+		var e reflect.Value
+		switch x := v.(type) {
+		case float64:
+			if dt == tensor.Bool {
+				e = reflect.ValueOf(x != 0)
+				break
+			}
+			e = reflect.ValueOf(x).Convert(dt.Type)
+		case bool:
+			if dt != tensor.Bool {
+				return errors.Errorf("Expected numbers of %v. Got %v instead", dt, x)
+			}
+			e = reflect.ValueOf(x)
+		default:
+			return errors.Errorf("Expected numbers. Got %v instead", v)
+		}
+		backing = reflect.Append(backing, e)
+		return nil
+	}
+	if err := walk(nested, 0); err != nil {
+		return nil, err
+	}
+	if len(shape) == 0 {
+		return tensor.New(tensor.FromScalar(backing.Index(0).Interface())), nil
+	}
+	return tensor.New(tensor.WithShape(shape...), tensor.WithBacking(backing.Interface())), nil
+}
+
+// asTensor returns a value as a tensor. The scalars are tensors of no dimensions.
+func asTensor(v G.Value) tensor.Tensor {
+	if t, ok := v.(tensor.Tensor); ok {
+		return t
+	}
+	return tensor.New(tensor.FromScalar(v.Data()))
+}
+
+// bind binds the values of the inputs of a model. The scalar inputs are bound to the scalars of the values.
+func bind(m *onnx.Model, values map[string]tensor.Tensor) error {
+	for name, t := range values {
+		n := m.Input(name)
+		if n == nil {
+			return errors.Errorf("The model has no input %q. Its inputs are %v", name, m.InputNames)
+		}
+		if !n.Shape().Eq(t.Shape()) || n.Dtype() != t.Dtype() {
+			return errors.Errorf("The input %q is a %v of %v. Got a %v of %v instead", name, n.Shape(), n.Dtype(), t.Shape(), t.Dtype())
+		}
+		var err error
+		if n.IsScalar() {
+			err = G.Let(n, t.Data())
+		} else {
+			err = G.Let(n, t)
+		}
+		if err != nil {
+			return errors.Wrapf(err, "Input %q", name)
+		}
+	}
+	for i, n := range m.Inputs {
+		if n.Value() == nil {
+			return errors.Errorf("The input %q of the model is not given", m.InputNames[i])
+		}
+	}
+	return nil
+}
+
+// writeOutputs writes the outputs of a model, as .npy files in a directory, or else as a JSON object of the JSON
+// encoding of MarshalValue of every output, to w.
+func writeOutputs(w io.Writer, m *onnx.Model, dir string) error {
+	if dir == "" {
+		fields := make(map[string]json.RawMessage)
+		for i, n := range m.Outputs {
+			p, err := G.MarshalValue(n.Value(), G.JSONEncoding)
+			if err != nil {
+				return errors.Wrapf(err, "Output %q", m.OutputNames[i])
+			}
+			fields[m.OutputNames[i]] = p
+		}
+		p, err := json.Marshal(fields)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, "%s\n", p)
+		return err
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	for i, n := range m.Outputs {
+		d, ok := asTensor(n.Value()).(*tensor.Dense)
+		if !ok {
+			return errors.Errorf("Cannot write the output %q of %T", m.OutputNames[i], n.Value())
+		}
+		var buf bytes.Buffer
+		if err := d.WriteNpy(&buf); err != nil {
+			return errors.Wrapf(err, "Output %q", m.OutputNames[i])
+		}
+		name := strings.NewReplacer("/", "_", string(filepath.Separator), "_").Replace(m.OutputNames[i]) + ".npy"
+		if err := ioutil.WriteFile(filepath.Join(dir, name), buf.Bytes(), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
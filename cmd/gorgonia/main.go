@@ -0,0 +1,461 @@
+// Command gorgonia inspects, runs and benchmarks ONNX models with Gorgonia, for debugging deployments without writing Go:
+//
+//	gorgonia inspect [-shape name=d0,d1,...] model.onnx
+//	gorgonia run [-input name=file.npy|file.json]... [-o dir] model.onnx
+//	gorgonia benchmark [-shape name=d0,d1,...] [-n 100] [-warmup 10] [-seed 0] model.onnx
+//	gorgonia replay [-model model.onnx] crashdump
+//
+// inspect prints the inputs, outputs and operators of a model, and the summary of its graph (see gorgonia.Summarize).
+// run reads the inputs from .npy files, or from .json files of nested arrays or of the JSON encoding of
+// gorgonia.MarshalValue, and prints the outputs in that encoding, or writes them as .npy files to a directory. benchmark
+// runs a model on random inputs and prints the statistics of the latencies. The shapes of the inputs whose dimensions
+// are only known at run time are given by -shape, or by the values of -input. replay runs the model of a crash dump
+// (see gorgonia.WithCrashDump) again on the inputs of the failed run, and prints the failure if it reproduces; the model
+// is that of -model, or else that of the dump. The dumps without models, of the graphs built in Go, are replayed by
+// running the programs of their graphs, if their ops could be serialized (see gorgonia.MarshalProgram).
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"os"
+	"reflect"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pkg/errors"
+	G "gorgonia.org/gorgonia"
+	"gorgonia.org/gorgonia/onnx"
+	"gorgonia.org/tensor"
+)
+
+var commands = map[string]func(args []string, w io.Writer) error{
+	"inspect":   inspect,
+	"run":       run,
+	"benchmark": benchmark,
+	"replay":    replay,
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: gorgonia inspect|run|benchmark [flags] model.onnx\n       gorgonia replay [flags] crashdump\nRun gorgonia <command> -h for the flags of a command.\n")
+	os.Exit(2)
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+	cmd, ok := commands[os.Args[1]]
+	if !ok {
+		usage()
+	}
+	if err := cmd(os.Args[2:], os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "gorgonia %v: %v\n", os.Args[1], err)
+		os.Exit(1)
+	}
+}
+
+// parse parses the flags of a command, whose only argument is the path of the model.
+func parse(fs *flag.FlagSet, args []string) (*onnx.ModelProto, error) {
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if fs.NArg() != 1 {
+		return nil, errors.Errorf("Expected the path of a model. Got %v instead", fs.Args())
+	}
+	return readModel(fs.Arg(0))
+}
+
+func inspect(args []string, w io.Writer) error {
+	fs := flag.NewFlagSet("inspect", flag.ContinueOnError)
+	var shapeFlag namedFlag
+	fs.Var(&shapeFlag, "shape", "the shape `name=d0,d1,...` of an input, for the dimensions that are only known at run time (repeated)")
+	m, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+	shapes, err := shapeFlag.shapes()
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "IR version: %d\n", m.IRVersion)
+	if m.ProducerName != "" {
+		fmt.Fprintf(w, "Producer: %s %s\n", m.ProducerName, m.ProducerVersion)
+	}
+	for _, o := range m.OpsetImport {
+		domain := o.Domain
+		if domain == "" {
+			domain = "ai.onnx"
+		}
+		fmt.Fprintf(w, "Opset: %s %d\n", domain, o.Version)
+	}
+	if m.Graph == nil {
+		return errors.New("The model has no graph")
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "\n\tName\tType\tShape\t\n")
+	inits := make(map[string]struct{})
+	for _, t := range m.Graph.Initializer {
+		inits[t.Name] = struct{}{}
+	}
+	for _, vi := range m.Graph.Input {
+		if _, ok := inits[vi.Name]; !ok {
+			fmt.Fprintf(tw, "input\t%s\t%s\t%s\t\n", vi.Name, dtypeName(vi.ElemType), dimensions(vi.Shape))
+		}
+	}
+	for _, vi := range m.Graph.Output {
+		fmt.Fprintf(tw, "output\t%s\t%s\t%s\t\n", vi.Name, dtypeName(vi.ElemType), dimensions(vi.Shape))
+	}
+	tw.Flush()
+
+	fmt.Fprintf(w, "\n%d nodes, %d initializers\n", len(m.Graph.Node), len(m.Graph.Initializer))
+	c, err := onnx.CoverageReport(m)
+	if err != nil {
+		fmt.Fprintf(w, "\nThe graph cannot be imported: %v\n", err)
+		return nil
+	}
+	fmt.Fprintf(w, "\n%v", c)
+
+	model, err := importModel(m, shapes)
+	if err != nil {
+		fmt.Fprintf(w, "\nThe graph cannot be imported: %v\n", err)
+		return nil
+	}
+	s, err := G.Summarize(model.Graph, model.Initializers...)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "\n%v", s)
+	return nil
+}
+
+func dtypeName(dt onnx.DataType) string {
+	if d, err := dt.Dtype(); err == nil {
+		return d.String()
+	}
+	return fmt.Sprintf("onnx type %d", dt)
+}
+
+func dimensions(dims []onnx.Dimension) string {
+	if dims == nil {
+		return "unknown"
+	}
+	s := "("
+	for i, d := range dims {
+		if i > 0 {
+			s += ", "
+		}
+		if d.Value > 0 {
+			s += fmt.Sprint(d.Value)
+		} else if d.Param != "" {
+			s += d.Param
+		} else {
+			s += "?"
+		}
+	}
+	return s + ")"
+}
+
+func run(args []string, w io.Writer) error {
+	fs := flag.NewFlagSet("run", flag.ContinueOnError)
+	var inputFlag namedFlag
+	fs.Var(&inputFlag, "input", "the `name=file` of an input, of a .npy or .json file (repeated)")
+	dir := fs.String("o", "", "the `directory` to write the outputs to, as .npy files, instead of printing them as JSON")
+	m, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if m.Graph == nil {
+		return errors.New("The model has no graph")
+	}
+
+	// the inputs are read first, for their shapes
+	dtypes := make(map[string]tensor.Dtype)
+	for _, vi := range m.Graph.Input {
+		if dt, err := vi.ElemType.Dtype(); err == nil {
+			dtypes[vi.Name] = dt
+		}
+	}
+	values := make(map[string]tensor.Tensor)
+	shapes := make(map[string]tensor.Shape)
+	for i, name := range inputFlag.names {
+		dt, ok := dtypes[name]
+		if !ok {
+			return errors.Errorf("The model has no input %q", name)
+		}
+		t, err := readInput(inputFlag.values[i], dt)
+		if err != nil {
+			return err
+		}
+		values[name] = t
+		if !t.Shape().IsScalar() {
+			shapes[name] = t.Shape()
+		}
+	}
+
+	model, err := importModel(m, shapes)
+	if err != nil {
+		return err
+	}
+	if err = bind(model, values); err != nil {
+		return err
+	}
+	vm := G.NewTapeMachine(model.Graph)
+	defer vm.Close()
+	if err = vm.RunAll(); err != nil {
+		return err
+	}
+	return writeOutputs(w, model, *dir)
+}
+
+func benchmark(args []string, w io.Writer) error {
+	fs := flag.NewFlagSet("benchmark", flag.ContinueOnError)
+	var shapeFlag namedFlag
+	fs.Var(&shapeFlag, "shape", "the shape `name=d0,d1,...` of an input, for the dimensions that are only known at run time (repeated)")
+	n := fs.Int("n", 100, "the number of timed runs")
+	warmup := fs.Int("warmup", 10, "the number of runs before the timed runs")
+	seed := fs.Int64("seed", 0, "the seed of the random inputs")
+	m, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if *n <= 0 {
+		return errors.Errorf("Expected a positive number of runs. Got %d instead", *n)
+	}
+	shapes, err := shapeFlag.shapes()
+	if err != nil {
+		return err
+	}
+	model, err := importModel(m, shapes)
+	if err != nil {
+		return err
+	}
+
+	rnd := rand.New(rand.NewSource(*seed))
+	values := make(map[string]tensor.Tensor)
+	for i, in := range model.Inputs {
+		values[model.InputNames[i]] = randomValue(rnd, in.Dtype(), in.Shape())
+	}
+	if err = bind(model, values); err != nil {
+		return err
+	}
+
+	vm := G.NewTapeMachine(model.Graph)
+	defer vm.Close()
+	latencies := make([]time.Duration, *n)
+	var total time.Duration
+	for i := -*warmup; i < *n; i++ {
+		start := time.Now()
+		if err = vm.RunAll(); err != nil {
+			return err
+		}
+		elapsed := time.Since(start)
+		vm.Reset()
+		if i >= 0 {
+			latencies[i] = elapsed
+			total += elapsed
+		}
+	}
+
+	sorted := append([]time.Duration(nil), latencies...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	quantile := func(q float64) time.Duration { return sorted[int(q*float64(len(sorted)-1)+0.5)] }
+	mean := total / time.Duration(*n)
+	fmt.Fprintf(w, "runs: %d (after %d warmup runs)\n", *n, *warmup)
+	fmt.Fprintf(w, "mean: %v | min: %v | p50: %v | p90: %v | p99: %v | max: %v\n",
+		mean, sorted[0], quantile(0.5), quantile(0.9), quantile(0.99), sorted[len(sorted)-1])
+	fmt.Fprintf(w, "throughput: %.2f runs/s\n", float64(*n)/total.Seconds())
+	return nil
+}
+
+// replay runs the model of a crash dump again, on the inputs of the failed run. The initializers of the model are
+// those of the model rather than those of the dump. A dump without a model is replayed from its program.
+func replay(args []string, w io.Writer) error {
+	fs := flag.NewFlagSet("replay", flag.ContinueOnError)
+	modelPath := fs.String("model", "", "the ONNX `model` of the run, for the dumps that have none")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.Errorf("Expected the directory of a crash dump. Got %v instead", fs.Args())
+	}
+	d, err := G.ReadCrashDump(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "%v machine failed at %d", d.Machine, d.PC)
+	if d.NodeID >= 0 {
+		fmt.Fprintf(w, " (node %d %q, %v)", d.NodeID, d.Node, d.Op)
+	}
+	fmt.Fprintf(w, " on %v, %v/%v, %v\n%v\n\n", d.Engine, d.GOOS, d.GOARCH, d.GoVersion, d.Error)
+
+	var m *onnx.ModelProto
+	switch {
+	case *modelPath != "":
+		if m, err = readModel(*modelPath); err != nil {
+			return err
+		}
+	case !d.HasModel && d.HasProgram:
+		return replayProgram(d, w)
+	default:
+		p, err := d.Model()
+		if err != nil {
+			return errors.Wrap(err, "Give the model with -model")
+		}
+		m = new(onnx.ModelProto)
+		if err = m.Unmarshal(p); err != nil {
+			return errors.Wrap(err, "Cannot decode the ONNX model of the crash dump")
+		}
+	}
+	if m.Graph == nil {
+		return errors.New("The model has no graph")
+	}
+
+	isInput := make(map[string]bool)
+	for _, vi := range m.Graph.Input {
+		isInput[vi.Name] = true
+	}
+	for _, t := range m.Graph.Initializer {
+		delete(isInput, t.Name)
+	}
+	values := make(map[string]tensor.Tensor)
+	shapes := make(map[string]tensor.Shape)
+	for _, in := range d.Inputs {
+		if !isInput[in.Name] {
+			continue
+		}
+		v, err := d.Value(in)
+		if err != nil {
+			return err
+		}
+		t := asTensor(v)
+		values[in.Name] = t
+		if !t.Shape().IsScalar() {
+			shapes[in.Name] = t.Shape()
+		}
+	}
+	model, err := importModel(m, shapes)
+	if err != nil {
+		return err
+	}
+	if err = bind(model, values); err != nil {
+		return err
+	}
+
+	var opts []G.VMOpt
+	for _, watch := range d.Watch {
+		switch watch {
+		case "nan":
+			opts = append(opts, G.WithNaNWatch())
+		case "inf":
+			opts = append(opts, G.WithInfWatch())
+		}
+	}
+	var vm G.VM
+	if d.Machine == "lisp" {
+		vm = G.NewLispMachine(model.Graph, append(opts, G.ExecuteFwdOnly())...)
+	} else {
+		vm = G.NewTapeMachine(model.Graph, opts...)
+	}
+	defer vm.Close()
+	if err = vm.RunAll(); err != nil {
+		return errors.Wrap(err, "The failure reproduces")
+	}
+	fmt.Fprintf(w, "The run succeeded: the failure does not reproduce\n")
+	return nil
+}
+
+// replayProgram runs the program of a crash dump on the inputs of the failed run. The programs do not watch their
+// values, so the NaNs and the infinities watched by the failed run are looked for in the outputs of the program.
+func replayProgram(d *G.CrashDump, w io.Writer) error {
+	p, err := d.Program()
+	if err != nil {
+		return err
+	}
+	m := G.NewProgramMachine(p)
+	for _, in := range d.Inputs {
+		v, err := d.Value(in)
+		if err != nil {
+			return err
+		}
+		if err = m.Set(in.Name, v); err != nil {
+			return err
+		}
+	}
+	if err = m.RunAll(); err != nil {
+		return errors.Wrap(err, "The failure reproduces")
+	}
+	var nan, inf bool
+	for _, watch := range d.Watch {
+		nan = nan || watch == "nan"
+		inf = inf || watch == "inf"
+	}
+	for i, out := range p.Outputs() {
+		v, err := m.Output(i)
+		if err != nil {
+			return err
+		}
+		if watched(v, nan, inf) {
+			return errors.Errorf("The failure reproduces: the output %d %q has NaNs or infinities", out.ID, out.Name)
+		}
+	}
+	fmt.Fprintf(w, "The run succeeded: the failure does not reproduce\n")
+	return nil
+}
+
+// watched reports whether the floats of a value have a NaN, if nan, or an infinity, if inf.
+func watched(v G.Value, nan, inf bool) bool {
+	var data []float64
+	switch d := v.Data().(type) {
+	case float64:
+		data = []float64{d}
+	case float32:
+		data = []float64{float64(d)}
+	case []float64:
+		data = d
+	case []float32:
+		for _, f := range d {
+			data = append(data, float64(f))
+		}
+	}
+	for _, f := range data {
+		if nan && math.IsNaN(f) || inf && math.IsInf(f, 0) {
+			return true
+		}
+	}
+	return false
+}
+
+// randomValue returns a value of normally distributed floats, integers from 0 to 9, or random booleans.
+func randomValue(rnd *rand.Rand, dt tensor.Dtype, shape tensor.Shape) tensor.Tensor {
+	size := shape.TotalSize()
+	if shape.IsScalar() {
+		size = 1
+	}
+	backing := reflect.MakeSlice(reflect.SliceOf(dt.Type), size, size)
+	for i := 0; i < size; i++ {
+		e := backing.Index(i)
+		switch e.Kind() {
+		case reflect.Float32, reflect.Float64:
+			e.SetFloat(rnd.NormFloat64())
+		case reflect.Bool:
+			e.SetBool(rnd.Intn(2) == 1)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			e.SetUint(uint64(rnd.Intn(10)))
+		case reflect.Complex64, reflect.Complex128:
+			e.SetComplex(complex(rnd.NormFloat64(), rnd.NormFloat64()))
+		default:
+			e.SetInt(int64(rnd.Intn(10)))
+		}
+	}
+	if shape.IsScalar() {
+		return tensor.New(tensor.FromScalar(backing.Index(0).Interface()))
+	}
+	return tensor.New(tensor.WithShape(shape...), tensor.WithBacking(backing.Interface()))
+}
@@ -0,0 +1,281 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	G "gorgonia.org/gorgonia"
+	"gorgonia.org/gorgonia/onnx"
+	"gorgonia.org/tensor"
+)
+
+// testModel writes a model of y = relu(x * w) to a directory, and returns its path. The batch size of x is only known
+// at run time.
+func testModel(t *testing.T, dir string) string {
+	m := &onnx.ModelProto{
+		IRVersion:    7,
+		ProducerName: "test",
+		OpsetImport:  []*onnx.OperatorSetID{{Version: 13}},
+		Graph: &onnx.GraphProto{
+			Node: []*onnx.NodeProto{
+				{OpType: "MatMul", Input: []string{"x", "w"}, Output: []string{"h"}},
+				{OpType: "Relu", Input: []string{"h"}, Output: []string{"y"}},
+			},
+			Initializer: []*onnx.TensorProto{{Name: "w", Dims: []int64{2, 2}, DataType: onnx.Float, FloatData: []float32{1, -1, 1, 1}}},
+			Input:       []*onnx.ValueInfoProto{{Name: "x", ElemType: onnx.Float, Shape: []onnx.Dimension{{Param: "N"}, {Value: 2}}}},
+			Output:      []*onnx.ValueInfoProto{{Name: "y", ElemType: onnx.Float}},
+		},
+	}
+	path := filepath.Join(dir, "model.onnx")
+	if err := ioutil.WriteFile(path, m.Marshal(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// printed returns the data of an output printed by run.
+func printed(t *testing.T, p []byte, name string, shape tensor.Shape) interface{} {
+	var outputs map[string]json.RawMessage
+	if err := json.Unmarshal(p, &outputs); err != nil {
+		t.Fatal(err)
+	}
+	v, err := G.UnmarshalValue(outputs[name], G.JSONEncoding)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, shape, v.Shape())
+	return v.Data()
+}
+
+func TestCommands(t *testing.T) {
+	assert := assert.New(t)
+	dir, err := ioutil.TempDir("", "gorgonia")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	model := testModel(t, dir)
+
+	var out bytes.Buffer
+	if err = inspect([]string{"-shape", "x=3,2", model}, &out); err != nil {
+		t.Fatal(err)
+	}
+	assert.Contains(out.String(), "Producer: test")
+	assert.Contains(out.String(), "(N, 2)")
+	assert.Contains(out.String(), "MatMul")
+	assert.Contains(out.String(), "since opset 1")
+	assert.Contains(out.String(), "Params: 4")
+
+	out.Reset()
+	assert.NoError(inspect([]string{model}, &out))
+	assert.Contains(out.String(), "cannot be imported", "the batch size is not given")
+
+	// the inputs of nested arrays, and of the JSON encoding of MarshalValue
+	nested := filepath.Join(dir, "x.json")
+	if err = ioutil.WriteFile(nested, []byte("[[1, 2], [3, -4]]"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	x := tensor.New(tensor.WithShape(1, 2), tensor.WithBacking([]float32{1, 2}))
+	p, err := G.MarshalValue(x, G.JSONEncoding)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encoded := filepath.Join(dir, "encoded.json")
+	if err = ioutil.WriteFile(encoded, p, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	out.Reset()
+	if err = run([]string{"-input", "x=" + nested, model}, &out); err != nil {
+		t.Fatal(err)
+	}
+	// x * w = [[3, 1], [-1, -7]]
+	assert.Equal([]float32{3, 1, 0, 0}, printed(t, out.Bytes(), "y", tensor.Shape{2, 2}))
+
+	out.Reset()
+	if err = run([]string{"-input", "x=" + encoded, model}, &out); err != nil {
+		t.Fatal(err)
+	}
+	assert.Contains(out.String(), `"shape":[1,2],"data":[3,1]`)
+
+	// the outputs are written as .npy files, which can be read back as inputs
+	outs := filepath.Join(dir, "outputs")
+	if err = run([]string{"-input", "x=" + nested, "-o", outs, model}, &out); err != nil {
+		t.Fatal(err)
+	}
+	y, err := readInput(filepath.Join(outs, "y.npy"), tensor.Float32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]float32{3, 1, 0, 0}, y.Data())
+	out.Reset()
+	if err = run([]string{"-input", "x=" + filepath.Join(outs, "y.npy"), model}, &out); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]float32{4, 0, 0, 0}, printed(t, out.Bytes(), "y", tensor.Shape{2, 2}))
+
+	out.Reset()
+	if err = benchmark([]string{"-shape", "x=8,2", "-n", "5", "-warmup", "1", model}, &out); err != nil {
+		t.Fatal(err)
+	}
+	assert.Contains(out.String(), "runs: 5 (after 1 warmup runs)")
+	assert.Contains(out.String(), "p99")
+
+	for _, args := range [][]string{
+		{model},                                   // the input is not given
+		{"-input", "z=" + nested, model},          // the model has no input z
+		{"-input", "x=" + model, model},           // not a .npy or .json file
+		{"-input", "x=" + nested},                 // no model
+		{"-input", "x=" + nested, nested},         // not an ONNX model
+		{"-input", "x=" + nested, model, "extra"}, // too many arguments
+	} {
+		assert.Error(run(args, &out), "%v", args)
+	}
+	assert.Error(benchmark([]string{model}, &out), "the batch size is not given")
+	assert.Error(benchmark([]string{"-shape", "x=8,2", "-n", "0", model}, &out))
+	assert.Error(benchmark([]string{"-shape", "x=8,a", model}, &out))
+}
+
+func TestFromNested(t *testing.T) {
+	assert := assert.New(t)
+	v, err := fromNested([]interface{}{[]interface{}{1.0, 2.0}, []interface{}{3.0, 4.0}}, tensor.Int64)
+	if assert.NoError(err) {
+		assert.Equal(tensor.Shape{2, 2}, v.Shape())
+		assert.Equal([]int64{1, 2, 3, 4}, v.Data())
+	}
+	v, err = fromNested(2.5, tensor.Float64)
+	if assert.NoError(err) {
+		assert.True(v.Shape().IsScalar())
+		assert.Equal(2.5, v.Data())
+	}
+	v, err = fromNested([]interface{}{true, false}, tensor.Bool)
+	if assert.NoError(err) {
+		assert.Equal([]bool{true, false}, v.Data())
+	}
+
+	for _, nested := range []interface{}{
+		[]interface{}{[]interface{}{1.0, 2.0}, []interface{}{3.0}}, // ragged
+		[]interface{}{[]interface{}{1.0}, 2.0},                     // ragged
+		[]interface{}{},                                            // empty
+		[]interface{}{"a"},                                         // not a number
+	} {
+		_, err = fromNested(nested, tensor.Float32)
+		assert.Error(err, "%v", nested)
+	}
+	_, err = fromNested([]interface{}{true}, tensor.Float32)
+	assert.Error(err)
+}
+
+// crashDump fails a run of the test model on x, and returns the directory of its crash dump.
+func crashDump(t *testing.T, dir, path string, x tensor.Tensor, withModel bool) string {
+	p, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := new(onnx.ModelProto)
+	if err = m.Unmarshal(p); err != nil {
+		t.Fatal(err)
+	}
+	model, err := importModel(m, map[string]tensor.Shape{"x": x.Shape()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = bind(model, map[string]tensor.Tensor{"x": x}); err != nil {
+		t.Fatal(err)
+	}
+	if !withModel {
+		p = nil
+	}
+	dumps := filepath.Join(dir, "dumps")
+	vm := G.NewTapeMachine(model.Graph, G.WithNaNWatch(), G.WithCrashDump(dumps, p))
+	defer vm.Close()
+	runErr := vm.RunAll()
+	if runErr == nil {
+		t.Fatal("expected the run to fail")
+	}
+	files, err := ioutil.ReadDir(dumps)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range files {
+		crash := filepath.Join(dumps, f.Name())
+		if strings.Contains(runErr.Error(), crash) {
+			return crash
+		}
+	}
+	t.Fatalf("no crash dump in %v", runErr)
+	return ""
+}
+
+func TestReplay(t *testing.T) {
+	assert := assert.New(t)
+	dir, err := ioutil.TempDir("", "gorgonia")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	model := testModel(t, dir)
+
+	nan := float32(math.NaN())
+	crash := crashDump(t, dir, model, tensor.New(tensor.WithShape(1, 2), tensor.WithBacking([]float32{1, nan})), true)
+	var out bytes.Buffer
+	err = replay([]string{crash}, &out)
+	assert.Error(err)
+	assert.Contains(err.Error(), "The failure reproduces")
+	assert.Contains(err.Error(), "NaN")
+	assert.Contains(out.String(), "tape machine failed at")
+
+	// the dumps without their models are replayed with -model, or else from their programs
+	crash = crashDump(t, dir, model, tensor.New(tensor.WithShape(2, 2), tensor.WithBacking([]float32{nan, 1, 1, 1})), false)
+	out.Reset()
+	err = replay([]string{"-model", model, crash}, &out)
+	assert.Error(err)
+	assert.Contains(err.Error(), "The failure reproduces")
+	err = replay([]string{crash}, &out)
+	assert.Error(err)
+	assert.Contains(err.Error(), "The failure reproduces")
+	assert.Contains(err.Error(), "NaN")
+
+	// the graphs built in Go are replayed from their programs
+	g := G.NewGraph()
+	x := G.NewVector(g, G.Float64, G.WithShape(3), G.WithName("x"), G.WithValue(tensor.New(tensor.WithBacking([]float64{1, -1, 4}))))
+	G.Must(G.Sum(G.Must(G.Sqrt(x))))
+	dumps := filepath.Join(dir, "go")
+	vm := G.NewTapeMachine(g, G.WithNaNWatch(), G.WithCrashDump(dumps, nil))
+	assert.Error(vm.RunAll())
+	vm.Close()
+	files, err := ioutil.ReadDir(dumps)
+	if err != nil || len(files) != 1 {
+		t.Fatalf("expected a crash dump in %v: %v", dumps, err)
+	}
+	crash = filepath.Join(dumps, files[0].Name())
+	err = replay([]string{crash}, &out)
+	assert.Error(err)
+	assert.Contains(err.Error(), "The failure reproduces")
+
+	// once the input is fixed, the failure does not reproduce
+	d, err := G.ReadCrashDump(crash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fixed, err := G.MarshalValue(tensor.New(tensor.WithBacking([]float64{1, 1, 4})), G.ProtoEncoding)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = ioutil.WriteFile(filepath.Join(crash, d.Inputs[0].File), fixed, 0644); err != nil {
+		t.Fatal(err)
+	}
+	out.Reset()
+	assert.NoError(replay([]string{crash}, &out))
+	assert.Contains(out.String(), "does not reproduce")
+
+	assert.Error(replay([]string{dir}, &out), "not a crash dump")
+	assert.Error(replay(nil, &out))
+}
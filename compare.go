@@ -0,0 +1,252 @@
+package gorgonia
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/cmplx"
+	"reflect"
+
+	"github.com/chewxy/math32"
+	"github.com/pkg/errors"
+	"gorgonia.org/tensor"
+)
+
+// CompareOpt is an option of CompareValues, ValuesEqual and ValuesClose.
+type CompareOpt func(*compareOpts)
+
+type compareOpts struct {
+	nanEqual bool
+	promote  bool
+}
+
+// NaNEqual makes the NaNs equal to the NaNs at the same positions. By default a NaN is not equal to anything, as in
+// IEEE 754.
+func NaNEqual() CompareOpt { return func(o *compareOpts) { o.nanEqual = true } }
+
+// PromoteDtypes compares values of different Dtypes, after converting both to the Dtype returned by PromoteTypes. By
+// default, values of different Dtypes are an error.
+func PromoteDtypes() CompareOpt { return func(o *compareOpts) { o.promote = true } }
+
+// ValueDiff is the result of comparing two values elementwise. See CompareValues.
+type ValueDiff struct {
+	Elements   int
+	Mismatches int
+	First      int     // the index of the first mismatch in the flattened values, or -1 if there is none
+	MaxAbsDiff float64 // the largest |a - b| of the elements that are finite in both values
+	MaxRelDiff float64 // the largest |a - b| / |b| of the same elements
+}
+
+// Equal returns true if there are no mismatches.
+func (d ValueDiff) Equal() bool { return d.Mismatches == 0 }
+
+func (d ValueDiff) String() string {
+	if d.Equal() {
+		return fmt.Sprintf("all %d elements match", d.Elements)
+	}
+	return fmt.Sprintf("%d of %d elements mismatch, the first at %d. Max absolute difference: %g. Max relative difference: %g", d.Mismatches, d.Elements, d.First, d.MaxAbsDiff, d.MaxRelDiff)
+}
+
+// CompareValues compares a and b elementwise. The floats and complex numbers match if they are close, as for AllClose:
+//
+//	|a - b| <= atol + rtol * |b|
+//
+// and the values of the other Dtypes match if they are equal. The values must have the same shape, and the same Dtype
+// unless PromoteDtypes is given.
+func CompareValues(a, b Value, rtol, atol float64, opts ...CompareOpt) (retVal ValueDiff, err error) {
+	var o compareOpts
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if !a.Shape().Eq(b.Shape()) {
+		return retVal, errors.Errorf("Shape mismatch: %v and %v", a.Shape(), b.Shape())
+	}
+	if a.Dtype() != b.Dtype() {
+		if !o.promote {
+			return retVal, errors.Errorf("Dtype mismatch: %v and %v", a.Dtype(), b.Dtype())
+		}
+		var dt tensor.Dtype
+		if dt, err = PromoteTypes(a.Dtype(), b.Dtype()); err != nil {
+			return retVal, err
+		}
+		if a, err = castValue(a, dt, false); err != nil {
+			return retVal, err
+		}
+		if b, err = castValue(b, dt, false); err != nil {
+			return retVal, err
+		}
+	}
+
+	var av, bv reflect.Value
+	if av, err = valueSlice(a); err != nil {
+		return
+	}
+	if bv, err = valueSlice(b); err != nil {
+		return
+	}
+	retVal = ValueDiff{Elements: av.Len(), First: -1}
+	mismatch := func(i int) {
+		if retVal.First < 0 {
+			retVal.First = i
+		}
+		retVal.Mismatches++
+	}
+
+	switch categoryOf(a.Dtype()) {
+	case floatCategory:
+		var ad, bd interface{}
+		f64 := reflect.TypeOf(float64(0))
+		if ad, err = castData(av.Interface(), f64, false); err != nil {
+			return
+		}
+		if bd, err = castData(bv.Interface(), f64, false); err != nil {
+			return
+		}
+		bs := bd.([]float64)
+		for i, x := range ad.([]float64) {
+			y := bs[i]
+			if math.IsNaN(x) || math.IsNaN(y) {
+				if !o.nanEqual || math.IsNaN(x) != math.IsNaN(y) {
+					mismatch(i)
+				}
+				continue
+			}
+			if !math.IsInf(x, 0) && !math.IsInf(y, 0) {
+				retVal.observe(math.Abs(x-y), math.Abs(y))
+			}
+			if !closeF64(x, y, rtol, atol) {
+				mismatch(i)
+			}
+		}
+	case complexCategory:
+		for i := 0; i < av.Len(); i++ {
+			x, y := av.Index(i).Complex(), bv.Index(i).Complex()
+			if cmplx.IsNaN(x) || cmplx.IsNaN(y) {
+				if !o.nanEqual || cmplx.IsNaN(x) != cmplx.IsNaN(y) {
+					mismatch(i)
+				}
+				continue
+			}
+			if !cmplx.IsInf(x) && !cmplx.IsInf(y) {
+				retVal.observe(cmplx.Abs(x-y), cmplx.Abs(y))
+			}
+			if !closeC128(x, y, rtol, atol) {
+				mismatch(i)
+			}
+		}
+	default:
+		for i := 0; i < av.Len(); i++ {
+			if av.Index(i).Interface() != bv.Index(i).Interface() {
+				mismatch(i)
+			}
+		}
+	}
+	return retVal, nil
+}
+
+func (d *ValueDiff) observe(abs, ref float64) {
+	if abs > d.MaxAbsDiff {
+		d.MaxAbsDiff = abs
+	}
+	if abs > 0 {
+		rel := math.Inf(1)
+		if ref > 0 {
+			rel = abs / ref
+		}
+		if rel > d.MaxRelDiff {
+			d.MaxRelDiff = rel
+		}
+	}
+}
+
+// ValuesEqual returns true if a and b have the same shape and their elements are equal. -0 is equal to 0.
+func ValuesEqual(a, b Value, opts ...CompareOpt) (bool, error) {
+	d, err := CompareValues(a, b, 0, 0, opts...)
+	return d.Equal(), err
+}
+
+// ValuesClose returns true if a and b have the same shape and their elements are close. See CompareValues.
+func ValuesClose(a, b Value, rtol, atol float64, opts ...CompareOpt) (bool, error) {
+	d, err := CompareValues(a, b, rtol, atol, opts...)
+	return d.Equal(), err
+}
+
+// HashValue returns a hash of the Dtype, shape and elements of a value, which is stable across runs, platforms and
+// versions. Views and the values that are not contiguous hash as their contiguous copies. The NaNs all hash the same,
+// and so do -0 and 0, so that the values that ValuesEqual with NaNEqual reports as equal have the same hash.
+func HashValue(v Value) (uint64, error) {
+	sv, err := valueSlice(v)
+	if err != nil {
+		return 0, err
+	}
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%v%v", v.Dtype(), v.Shape())
+
+	var data interface{}
+	switch d := sv.Interface().(type) {
+	case []float64:
+		c := make([]float64, len(d))
+		for i, x := range d {
+			c[i] = canonicalF64(x)
+		}
+		data = c
+	case []float32:
+		c := make([]float32, len(d))
+		for i, x := range d {
+			c[i] = canonicalF32(x)
+		}
+		data = c
+	case []complex128:
+		c := make([]complex128, len(d))
+		for i, x := range d {
+			c[i] = complex(canonicalF64(real(x)), canonicalF64(imag(x)))
+		}
+		data = c
+	case []complex64:
+		c := make([]complex64, len(d))
+		for i, x := range d {
+			c[i] = complex(canonicalF32(real(x)), canonicalF32(imag(x)))
+		}
+		data = c
+	case []int:
+		c := make([]int64, len(d))
+		for i, x := range d {
+			c[i] = int64(x)
+		}
+		data = c
+	case []uint:
+		c := make([]uint64, len(d))
+		for i, x := range d {
+			c[i] = uint64(x)
+		}
+		data = c
+	default:
+		data = d
+	}
+	if err = binary.Write(h, binary.LittleEndian, data); err != nil {
+		return 0, errors.Wrapf(err, "Cannot hash a value of %v", v.Dtype())
+	}
+	return h.Sum64(), nil
+}
+
+// canonicalF64 returns the same NaN for every NaN, and 0 for -0.
+func canonicalF64(a float64) float64 {
+	switch {
+	case math.IsNaN(a):
+		return math.NaN()
+	case a == 0:
+		return 0
+	}
+	return a
+}
+
+func canonicalF32(a float32) float32 {
+	switch {
+	case math32.IsNaN(a):
+		return math32.NaN()
+	case a == 0:
+		return 0
+	}
+	return a
+}
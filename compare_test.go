@@ -0,0 +1,77 @@
+package gorgonia
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorgonia.org/tensor"
+)
+
+func TestCompareValues(t *testing.T) {
+	assert := assert.New(t)
+	nan := math.NaN()
+	a := tensor.New(tensor.WithShape(2, 2), tensor.WithBacking([]float64{1, 2, nan, 4}))
+	b := tensor.New(tensor.WithShape(2, 2), tensor.WithBacking([]float64{1, 2.1, nan, 4}))
+
+	d, err := CompareValues(a, b, 0, 0)
+	if assert.NoError(err) {
+		assert.Equal(2, d.Mismatches, "NaNs are not equal by default")
+		assert.Equal(1, d.First)
+		assert.InDelta(0.1, d.MaxAbsDiff, 1e-12)
+		assert.InDelta(0.1/2.1, d.MaxRelDiff, 1e-12)
+	}
+	if d, err = CompareValues(a, b, 0.05, 0, NaNEqual()); assert.NoError(err) {
+		assert.True(d.Equal(), d.String())
+	}
+	ok, err := ValuesEqual(a, a.Clone().(Value), NaNEqual())
+	assert.NoError(err)
+	assert.True(ok)
+
+	f32 := tensor.New(tensor.WithShape(2, 2), tensor.WithBacking([]float32{1, 2, 3, 4}))
+	i := tensor.New(tensor.WithShape(2, 2), tensor.WithBacking([]int{1, 2, 3, 4}))
+	_, err = ValuesEqual(f32, i)
+	assert.Error(err, "the dtypes are not promoted by default")
+	ok, err = ValuesEqual(f32, i, PromoteDtypes())
+	assert.NoError(err)
+	assert.True(ok)
+	ok, err = ValuesClose(newF64(1), newF32(1.0000001), 1e-6, 0, PromoteDtypes())
+	assert.NoError(err)
+	assert.True(ok)
+
+	_, err = ValuesEqual(a, tensor.New(tensor.WithShape(4), tensor.WithBacking([]float64{1, 2, 3, 4})))
+	assert.Error(err, "shape mismatch")
+
+	ok, err = ValuesEqual(i, tensor.New(tensor.WithShape(2, 2), tensor.WithBacking([]int{1, 2, 3, 5})))
+	assert.NoError(err)
+	assert.False(ok)
+}
+
+func TestHashValue(t *testing.T) {
+	assert := assert.New(t)
+	hash := func(v Value) uint64 {
+		h, err := HashValue(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return h
+	}
+
+	a := tensor.New(tensor.WithShape(2, 2), tensor.WithBacking([]float64{0, 1, math.NaN(), 3}))
+	b := tensor.New(tensor.WithShape(2, 2), tensor.WithBacking([]float64{math.Copysign(0, -1), 1, math.Float64frombits(0x7ff8000000000123), 3}))
+	assert.Equal(hash(a), hash(b), "NaNs and signed zeros hash the same")
+	assert.Equal(uint64(0xa31b8c549423e7d9), hash(a), "the hash is stable")
+
+	c := tensor.New(tensor.WithShape(4), tensor.WithBacking([]float64{0, 1, math.NaN(), 3}))
+	assert.NotEqual(hash(a), hash(c), "the shape is hashed")
+	d := tensor.New(tensor.WithShape(2, 2), tensor.WithBacking([]float32{0, 1, float32(math.NaN()), 3}))
+	assert.NotEqual(hash(a), hash(d), "the dtype is hashed")
+
+	m := tensor.New(tensor.WithShape(2, 2), tensor.WithBacking([]int{1, 2, 3, 4}))
+	tr := m.Clone().(*tensor.Dense)
+	if err := tr.T(); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(hash(tensor.New(tensor.WithShape(2, 2), tensor.WithBacking([]int{1, 3, 2, 4}))), hash(tr), "views hash as their copies")
+	assert.Equal(hash(newI(3)), hash(newI(3)))
+}
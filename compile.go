@@ -6,6 +6,7 @@ import (
 	"io"
 
 	"github.com/pkg/errors"
+	"gorgonia.org/gorgonia/internal/logging"
 	"gorgonia.org/tensor"
 )
 
@@ -33,6 +34,9 @@ func Compile(g *ExprGraph) (prog *program, locMap map[*Node]register, err error)
 		return nil, nil, errors.Wrap(err, sortFail)
 	}
 	reverseNodes(sortedNodes)
+	if err = checkPlacement(sortedNodes); err != nil {
+		return nil, nil, err
+	}
 
 	df := analyze(g, sortedNodes)
 	sortedNodes = df.insertDeviceInstr(sortedNodes)
@@ -56,6 +60,7 @@ func Compile(g *ExprGraph) (prog *program, locMap map[*Node]register, err error)
 	prog.g = g
 	prog.sorted = sortedNodes
 
+	logging.Log(logging.Compile, logging.Debug, "compiled", "graph", g.name, "instructions", len(prog.instructions), "cpuRegisters", prog.cpulocs, "gpuRegisters", prog.gpulocs)
 	return
 }
 
@@ -84,6 +89,9 @@ func CompileFunction(g *ExprGraph, inputs, outputs Nodes) (prog *program, locMap
 		return nil, nil, errors.Wrap(err, sortFail)
 	}
 	reverseNodes(sortedNodes)
+	if err = checkPlacement(sortedNodes); err != nil {
+		return nil, nil, err
+	}
 
 	df := analyze(subgraph, sortedNodes)
 	sortedNodes = df.insertDeviceInstr(sortedNodes)
@@ -394,7 +402,6 @@ func (cg *codegenerator) addNode(node, replacement *Node, interv *interval, i in
 		if lastWriteNode, ok := cg.lastWrites[read]; ok {
 			instrID := cg.sorted.index(lastWriteNode)
 			var op Op
-			var onDev, nodeOnDev Device
 
 			_, isDevTrans := lastWriteNode.Op().(devTrans)
 			switch {
@@ -403,23 +410,7 @@ func (cg *codegenerator) addNode(node, replacement *Node, interv *interval, i in
 			default:
 				op = lastWriteNode.op
 			}
-			switch op.(type) {
-			case CUDADoer:
-				onDev = Device(0)
-			case CLDoer:
-				onDev = Device(0)
-			default:
-				onDev = CPU
-			}
-
-			switch node.op.(type) {
-			case CUDADoer:
-				nodeOnDev = Device(0)
-			case CLDoer:
-				nodeOnDev = Device(0)
-			default:
-				nodeOnDev = CPU
-			}
+			onDev, nodeOnDev := lastWriteNode.dataOn, node.dataOn
 
 			// if we have sequential Extern calls,  we just add it to the batch.
 			// sequential in this can mean several instructions apart. For example:
@@ -0,0 +1,50 @@
+package gorgonia
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConcurrentConstruction(t *testing.T) {
+	g := NewGraph(WithGraphSeed(1))
+	x := NewMatrix(g, Float64, WithShape(2, 3), WithName("x"))
+	const workers, layers = 8, 20
+
+	outputs := make(Nodes, workers)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			h := x
+			for j := 0; j < layers; j++ {
+				w := NewMatrix(g, Float64, WithShape(3, 3), WithName(fmt.Sprintf("w%d_%d", i, j)), WithInit(GlorotU(1)))
+				h = Must(Tanh(Must(Mul(Must(HadamardProd(h, h)), w))))
+				h = Must(Slice(Must(Concat(1, h, h)), nil, S(0, 3)))
+			}
+			outputs[i] = h
+		}(i)
+	}
+	wg.Wait()
+
+	// the same expressions of x built concurrently are shared, and every branch holds its own weights
+	assert.Len(t, g.Learnables(), 1+workers*layers)
+	seen := NewNodeSet()
+	for _, n := range g.AllNodes() {
+		assert.False(t, seen.Contains(n))
+		seen.Add(n)
+		for _, child := range n.children {
+			assert.True(t, g.to.get(child).Contains(n), "%v is not a parent of %v", n, child)
+		}
+	}
+	cost := Must(Sum(Must(Concat(0, outputs...))))
+	if _, err := Grad(cost, g.Learnables()...); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Sort(g); err != nil {
+		t.Fatal(err)
+	}
+}
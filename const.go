@@ -38,6 +38,8 @@ const (
 	clone0Fail          = "Failed to carry clone0()"
 	nyiTypeFail         = "%s not yet implemented for %T"
 	nyiFail             = "%s not yet implemented for %v"
+	typeMismatchFail    = "Type mismatch: %T and %T"
+	wideAccFail         = "Cannot accumulate %T into %v"
 	dtypeOfFail         = "Failed to carry dtypeOf()"
 	mulFail             = "Failed to carry Mul()"
 	applyOpFail         = "Failed to carryApplyOp()"
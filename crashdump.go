@@ -0,0 +1,268 @@
+package gorgonia
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	"github.com/pkg/errors"
+	"gorgonia.org/gorgonia/internal/logging"
+)
+
+/*
+This file holds the crash dumps: the bundles a machine writes to disk when a run fails, for the bug reports. A crash
+dump is a directory of
+
+	crash.json   the CrashDump: the error, where the run failed, and the machine, engine and platform it ran on
+	graph.dot    the graph (see ToDot)
+	program.bin  the graph compiled into a Program, in the encoding of MarshalProgram
+	program.txt  the instructions of a *tapeMachine
+	inputs/      the values of the inputs of the graph at the failure, in the ProtoEncoding of MarshalValue
+	model.onnx   the ONNX model the graph was imported from, if it was given to WithCrashDump
+
+A dump is replayed with the command gorgonia, which runs the ONNX model of the dump if it has one, and else its
+program:
+
+	gorgonia replay crash-123456
+
+The graphs whose ops have no encoding (see MarshalProgram) have no program.bin, so the dumps of their runs are only
+replayed if they have a model.
+*/
+
+// CrashDump describes a failed run of a machine. See WithCrashDump and ReadCrashDump.
+type CrashDump struct {
+	Error   string    `json:"error"`
+	Time    time.Time `json:"time"`
+	Machine string    `json:"machine"`
+
+	// PC is the index of the instruction of a *tapeMachine, or of the node of a *lispMachine, that failed. Node, NodeID
+	// and Op are those of the node that failed, if it is known.
+	PC     int    `json:"pc"`
+	Node   string `json:"node,omitempty"`
+	NodeID int64  `json:"node_id"`
+	Op     string `json:"op,omitempty"`
+
+	// Watch are the values the machine watched: "nan" for WithNaNWatch, "inf" for WithInfWatch.
+	Watch []string `json:"watch,omitempty"`
+
+	Engine    string `json:"engine"`
+	CUDA      bool   `json:"cuda"`
+	GPUs      int    `json:"gpus"`
+	GoVersion string `json:"go_version"`
+	GOOS      string `json:"goos"`
+	GOARCH    string `json:"goarch"`
+
+	Inputs     []CrashDumpInput `json:"inputs"`
+	HasModel   bool             `json:"has_model"`
+	HasProgram bool             `json:"has_program"`
+
+	dir string
+}
+
+// CrashDumpInput is an input of the graph whose value is in a crash dump.
+type CrashDumpInput struct {
+	Name string `json:"name"`
+	ID   int64  `json:"id"`
+	File string `json:"file"` // relative to the directory of the dump
+}
+
+const (
+	crashManifest = "crash.json"
+	crashModel    = "model.onnx"
+	crashProgram  = "program.bin"
+)
+
+// crashDumper is the configuration of WithCrashDump.
+type crashDumper struct {
+	dir   string
+	model []byte
+}
+
+// WithCrashDump creates a VM that writes a crash dump to a new directory in dir when a run fails. The error of the run
+// tells the directory. model is the serialized ONNX model the graph was imported from, if any; it may be nil, in which
+// case the run is replayed from the program of the graph.
+func WithCrashDump(dir string, model []byte) VMOpt {
+	f := func(m VM) {
+		switch v := m.(type) {
+		case *lispMachine:
+			v.crash = &crashDumper{dir: dir, model: model}
+		case *tapeMachine:
+			v.crash = &crashDumper{dir: dir, model: model}
+		default:
+			panic(nyi("WithCrashDump", v))
+		}
+	}
+	return f
+}
+
+// dump writes the crash dump of a failed run of the graph, and returns the error of the run. If the dump cannot be
+// written, the failure to write it is logged, and the error of the run is returned as it is.
+func (c *crashDumper) dump(g *ExprGraph, d CrashDump, program fmt.Stringer, engine interface{}, runErr error) error {
+	d.Error = runErr.Error()
+	d.Time = time.Now()
+	d.Engine = fmt.Sprintf("%T", engine)
+	d.CUDA = CUDA
+	d.GoVersion, d.GOOS, d.GOARCH = runtime.Version(), runtime.GOOS, runtime.GOARCH
+	dir, err := c.write(g, d, program)
+	if err != nil {
+		logging.Log(logging.VM, logging.Error, "failed to write the crash dump", "dir", c.dir, "err", err)
+		return runErr
+	}
+	return errors.Wrapf(runErr, "Crash dump written to %v", dir)
+}
+
+func (c *crashDumper) write(g *ExprGraph, d CrashDump, program fmt.Stringer) (dir string, err error) {
+	if err = os.MkdirAll(c.dir, 0755); err != nil {
+		return "", err
+	}
+	if dir, err = ioutil.TempDir(c.dir, "crash-"); err != nil {
+		return "", err
+	}
+	if err = os.Mkdir(filepath.Join(dir, "inputs"), 0755); err != nil {
+		return "", err
+	}
+
+	for _, n := range g.Inputs() {
+		v := n.Value()
+		if v == nil {
+			continue
+		}
+		var p []byte
+		if p, err = MarshalValue(v, ProtoEncoding); err != nil {
+			return "", errors.Wrapf(err, "Input %v", n)
+		}
+		in := CrashDumpInput{Name: n.name, ID: n.id, File: filepath.Join("inputs", fmt.Sprintf("%d.pb", n.id))}
+		if err = ioutil.WriteFile(filepath.Join(dir, in.File), p, 0644); err != nil {
+			return "", err
+		}
+		d.Inputs = append(d.Inputs, in)
+	}
+	if err = ioutil.WriteFile(filepath.Join(dir, "graph.dot"), []byte(g.ToDot()), 0644); err != nil {
+		return "", err
+	}
+	// the graphs that cannot be compiled into programs are only replayed from their models
+	if prog, err := NewProgram(g); err != nil {
+		logging.Log(logging.VM, logging.Warn, "the crash dump has no program", "err", err)
+	} else if p, err := MarshalProgram(prog); err != nil {
+		logging.Log(logging.VM, logging.Warn, "the crash dump has no program", "err", err)
+	} else {
+		if err = ioutil.WriteFile(filepath.Join(dir, crashProgram), p, 0644); err != nil {
+			return "", err
+		}
+		d.HasProgram = true
+	}
+	if program != nil {
+		if err = ioutil.WriteFile(filepath.Join(dir, "program.txt"), []byte(program.String()), 0644); err != nil {
+			return "", err
+		}
+	}
+	if c.model != nil {
+		if err = ioutil.WriteFile(filepath.Join(dir, crashModel), c.model, 0644); err != nil {
+			return "", err
+		}
+		d.HasModel = true
+	}
+
+	var p []byte
+	if p, err = json.MarshalIndent(d, "", "\t"); err != nil {
+		return "", err
+	}
+	return dir, ioutil.WriteFile(filepath.Join(dir, crashManifest), p, 0644)
+}
+
+// crashNode fills the node of a crash dump.
+func (d *CrashDump) crashNode(n *Node) {
+	if n == nil {
+		d.NodeID = -1
+		return
+	}
+	d.Node, d.NodeID = n.name, n.id
+	if n.op != nil {
+		d.Op = n.op.String()
+	}
+}
+
+func (d *CrashDump) watch(nan, inf bool) {
+	if nan {
+		d.Watch = append(d.Watch, "nan")
+	}
+	if inf {
+		d.Watch = append(d.Watch, "inf")
+	}
+}
+
+// ReadCrashDump reads the crash dump of a directory written by a machine created WithCrashDump.
+func ReadCrashDump(dir string) (*CrashDump, error) {
+	p, err := ioutil.ReadFile(filepath.Join(dir, crashManifest))
+	if err != nil {
+		return nil, err
+	}
+	d := new(CrashDump)
+	if err = json.Unmarshal(p, d); err != nil {
+		return nil, errors.Wrapf(err, "Cannot decode the crash dump of %v", dir)
+	}
+	d.dir = dir
+	return d, nil
+}
+
+// Dir returns the directory of the crash dump.
+func (d *CrashDump) Dir() string { return d.dir }
+
+// Value reads the value of an input of the crash dump.
+func (d *CrashDump) Value(in CrashDumpInput) (Value, error) {
+	p, err := ioutil.ReadFile(filepath.Join(d.dir, in.File))
+	if err != nil {
+		return nil, err
+	}
+	v, err := UnmarshalValue(p, ProtoEncoding)
+	return v, errors.Wrapf(err, "Input %q", in.Name)
+}
+
+// Model reads the ONNX model of the crash dump. It returns an error if the dump has none.
+func (d *CrashDump) Model() ([]byte, error) {
+	if !d.HasModel {
+		return nil, errors.Errorf("The crash dump %v has no model", d.dir)
+	}
+	return ioutil.ReadFile(filepath.Join(d.dir, crashModel))
+}
+
+// Program reads the program of the graph of the crash dump. It returns an error if the dump has none.
+func (d *CrashDump) Program() (*Program, error) {
+	if !d.HasProgram {
+		return nil, errors.Errorf("The crash dump %v has no program", d.dir)
+	}
+	p, err := ioutil.ReadFile(filepath.Join(d.dir, crashProgram))
+	if err != nil {
+		return nil, err
+	}
+	return UnmarshalProgram(p)
+}
+
+func (m *tapeMachine) dumpCrash(err error) error {
+	d := CrashDump{Machine: "tape", PC: m.pc, GPUs: len(m.gpumem)}
+	var n *Node
+	if m.pc < len(m.p.instructions) {
+		if op, ok := m.p.instructions[m.pc].(*execOp); ok {
+			n, _ = m.p.g.Node(op.id).(*Node)
+		}
+	}
+	d.crashNode(n)
+	d.watch(m.watchNaN(), m.watchInf())
+	return m.crash.dump(m.p.g, d, m.p, m.Engine, err)
+}
+
+func (m *lispMachine) dumpCrash(err error) error {
+	// the machine has moved past the node that failed
+	d := CrashDump{Machine: "lisp", PC: m.fwd - 1, GPUs: len(m.gpumem)}
+	var n *Node
+	if d.PC >= 0 && d.PC < len(m.sorted) {
+		n = m.sorted[d.PC]
+	}
+	d.crashNode(n)
+	d.watch(m.watchNaN(), m.watchInf())
+	return m.crash.dump(m.g, d, nil, m.Engine, err)
+}
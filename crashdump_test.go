@@ -0,0 +1,97 @@
+package gorgonia
+
+import (
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorgonia.org/tensor"
+)
+
+func TestWithCrashDump(t *testing.T) {
+	assert := assert.New(t)
+	dir, err := ioutil.TempDir("", "gorgonia")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	g := NewGraph()
+	x := NewVector(g, Float64, WithShape(3), WithName("x"), WithValue(tensor.New(tensor.WithBacking([]float64{1, -1, 4}))))
+	y := Must(Sqrt(x))
+	Must(Sum(y))
+
+	for _, name := range []string{"tape", "lisp"} {
+		var m VM
+		if name == "tape" {
+			m = NewTapeMachine(g, WithNaNWatch(), WithCrashDump(dir, []byte("model")))
+		} else {
+			m = NewLispMachine(g, ExecuteFwdOnly(), WithNaNWatch(), WithCrashDump(dir, nil))
+		}
+		err = m.RunAll()
+		m.Close()
+		if !assert.Error(err, name) {
+			continue
+		}
+		msg := err.Error()
+		i := strings.Index(msg, "Crash dump written to ")
+		if !assert.True(i >= 0, msg) {
+			continue
+		}
+		crashDir := strings.SplitN(msg[i+len("Crash dump written to "):], ":", 2)[0]
+		assert.Equal(dir, filepath.Dir(crashDir), name)
+
+		d, err := ReadCrashDump(crashDir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(name, d.Machine, name)
+		assert.Contains(d.Error, "NaN", name)
+		assert.Equal("", d.Node, name)
+		assert.Equal(y.ID(), d.NodeID, name)
+		assert.Equal(y.Op().String(), d.Op, name)
+		assert.Equal([]string{"nan"}, d.Watch, name)
+		assert.Equal(CUDA, d.CUDA, name)
+		if assert.Equal(1, len(d.Inputs), name) {
+			assert.Equal("x", d.Inputs[0].Name, name)
+			v, err := d.Value(d.Inputs[0])
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal([]float64{1, -1, 4}, v.Data(), name)
+		}
+		_, err = os.Stat(filepath.Join(crashDir, "graph.dot"))
+		assert.NoError(err, name)
+		prog, err := d.Program()
+		if assert.NoError(err, name) {
+			assert.Equal("x", prog.Inputs()[0].Name, name)
+		}
+
+		model, err := d.Model()
+		if name == "tape" {
+			assert.Equal([]byte("model"), model)
+			_, err = os.Stat(filepath.Join(crashDir, "program.txt"))
+			assert.NoError(err)
+		} else {
+			assert.Error(err)
+		}
+	}
+
+	// the runs that succeed write nothing
+	x.Value().(*tensor.Dense).Set(1, 1.0)
+	empty, err := ioutil.TempDir("", "gorgonia")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(empty)
+	m := NewTapeMachine(g, WithNaNWatch(), WithCrashDump(empty, nil))
+	defer m.Close()
+	assert.NoError(m.RunAll())
+	files, _ := ioutil.ReadDir(empty)
+	assert.Equal(0, len(files))
+	assert.False(math.IsNaN(y.Value().Data().([]float64)[1]))
+}
@@ -0,0 +1,260 @@
+#define _USE_MATH_DEFINES
+#include <math.h>
+
+#define THREADID \
+	int blockId = blockIdx.x + blockIdx.y * gridDim.x + gridDim.x * gridDim.y * blockIdx.z;\
+	int idx = blockId * (blockDim.x * blockDim.y * blockDim.z) + (threadIdx.z * (blockDim.x * blockDim.y)) + (threadIdx.y * blockDim.x) + threadIdx.x;
+
+#define CHECKSIZE \
+	if (idx >= size) { \
+		return; \
+	}
+
+/*
+	Counter based random numbers: Philox4x32-10 (Salmon et al., 2011). Every element is generated from the counter
+	(offset + idx, n) and the key seed, so the kernels need no state on the device. The host advances the offset by
+	the size of the tensor after every launch, so that every run draws new numbers.
+*/
+
+#define PHILOX_M0 0xD2511F53U
+#define PHILOX_M1 0xCD9E8D57U
+#define PHILOX_W0 0x9E3779B9U
+#define PHILOX_W1 0xBB67AE85U
+
+__device__ uint4 philox(unsigned long long seed, unsigned long long ctr, unsigned int n) {
+	uint4 c = make_uint4((unsigned int)ctr, (unsigned int)(ctr >> 32), n, 0);
+	uint2 k = make_uint2((unsigned int)seed, (unsigned int)(seed >> 32));
+	for (int i = 0; i < 10; i++) {
+		unsigned int hi0 = __umulhi(PHILOX_M0, c.x);
+		unsigned int lo0 = PHILOX_M0 * c.x;
+		unsigned int hi1 = __umulhi(PHILOX_M1, c.z);
+		unsigned int lo1 = PHILOX_M1 * c.z;
+		c = make_uint4(hi1 ^ c.y ^ k.x, lo1, hi0 ^ c.w ^ k.y, lo0);
+		k.x += PHILOX_W0;
+		k.y += PHILOX_W1;
+	}
+	return c;
+}
+
+// uniform numbers in (0, 1)
+__device__ float u32(unsigned int x) {
+	return ((float)x + 0.5f) * 2.3283064365386963e-10f;
+}
+
+__device__ double u64(unsigned int x, unsigned int y) {
+	unsigned long long z = ((unsigned long long)x << 21) ^ (unsigned long long)y;
+	return ((double)(z & 0x1FFFFFFFFFFFFFULL) + 0.5) * 1.1102230246251565e-16;
+}
+
+extern "C" {
+	__global__ void uniform_f32(float* A, int size, unsigned long long seed, unsigned long long offset, float a, float b) {
+		THREADID
+		CHECKSIZE
+		uint4 r = philox(seed, offset + idx, 0);
+		A[idx] = a + (b - a) * u32(r.x);
+	}
+}
+
+extern "C" {
+	__global__ void uniform_f64(double* A, int size, unsigned long long seed, unsigned long long offset, double a, double b) {
+		THREADID
+		CHECKSIZE
+		uint4 r = philox(seed, offset + idx, 0);
+		A[idx] = a + (b - a) * u64(r.x, r.y);
+	}
+}
+
+extern "C" {
+	__global__ void gaussian_f32(float* A, int size, unsigned long long seed, unsigned long long offset, float a, float b) {
+		THREADID
+		CHECKSIZE
+		uint4 r = philox(seed, offset + idx, 0);
+		// Box-Muller
+		float z = sqrtf(-2.0f * logf(u32(r.x))) * cosf(2.0f * (float)M_PI * u32(r.y));
+		A[idx] = a + b * z;
+	}
+}
+
+extern "C" {
+	__global__ void gaussian_f64(double* A, int size, unsigned long long seed, unsigned long long offset, double a, double b) {
+		THREADID
+		CHECKSIZE
+		uint4 r = philox(seed, offset + idx, 0);
+		double z = sqrt(-2.0 * log(u64(r.x, r.y))) * cos(2.0 * M_PI * u64(r.z, r.w));
+		A[idx] = a + b * z;
+	}
+}
+
+extern "C" {
+	__global__ void bernoulli_f32(float* A, int size, unsigned long long seed, unsigned long long offset, float a, float b) {
+		THREADID
+		CHECKSIZE
+		uint4 r = philox(seed, offset + idx, 0);
+		A[idx] = u32(r.x) < a ? 1.0f : 0.0f;
+	}
+}
+
+extern "C" {
+	__global__ void bernoulli_f64(double* A, int size, unsigned long long seed, unsigned long long offset, double a, double b) {
+		THREADID
+		CHECKSIZE
+		uint4 r = philox(seed, offset + idx, 0);
+		A[idx] = u64(r.x, r.y) < a ? 1.0 : 0.0;
+	}
+}
+
+/*
+	binomial draws a trials, which use the counters (offset + idx, 0), (offset + idx, 1) ... four at a time.
+*/
+
+extern "C" {
+	__global__ void binomial_f32(float* A, int size, unsigned long long seed, unsigned long long offset, float a, float b) {
+		THREADID
+		CHECKSIZE
+		int trials = (int)a;
+		float count = 0.0f;
+		for (int t = 0; t < trials; t += 4) {
+			uint4 r = philox(seed, offset + idx, t / 4);
+			unsigned int xs[4] = {r.x, r.y, r.z, r.w};
+			for (int j = 0; j < 4 && t + j < trials; j++) {
+				if (u32(xs[j]) < b) {
+					count += 1.0f;
+				}
+			}
+		}
+		A[idx] = count;
+	}
+}
+
+extern "C" {
+	__global__ void binomial_f64(double* A, int size, unsigned long long seed, unsigned long long offset, double a, double b) {
+		THREADID
+		CHECKSIZE
+		int trials = (int)a;
+		double count = 0.0;
+		for (int t = 0; t < trials; t += 4) {
+			uint4 r = philox(seed, offset + idx, t / 4);
+			unsigned int xs[4] = {r.x, r.y, r.z, r.w};
+			for (int j = 0; j < 4 && t + j < trials; j++) {
+				if ((double)u32(xs[j]) < b) {
+					count += 1.0;
+				}
+			}
+		}
+		A[idx] = count;
+	}
+}
+
+/*
+	sample draws an index of every row of A, which holds log-probabilities (or probabilities, if probs is set), with the
+	temperature, top-k and top-p filtering of categoricalOp.sample. A thread samples a row: it ranks the indices of the
+	row in order by a heap sort, with the ties ranked by their indices as the stable sort does on the host, and draws
+	the counter (row, 0) of the stream of the seed. order holds rows * classes indices.
+*/
+
+template <typename T>
+__device__ double logit(const T* A, int i, int probs) {
+	return probs ? log((double)A[i]) : (double)A[i];
+}
+
+// ranked is true if the index i ranks before j
+template <typename T>
+__device__ bool ranked(const T* A, int probs, int i, int j) {
+	double a = logit(A, i, probs);
+	double b = logit(A, j, probs);
+	return a > b || (a == b && i < j);
+}
+
+template <typename T>
+__device__ void sift(const T* A, int probs, int* order, int root, int n) {
+	for (;;) {
+		int child = 2 * root + 1;
+		if (child >= n) {
+			return;
+		}
+		if (child + 1 < n && ranked(A, probs, order[child], order[child + 1])) {
+			child++;
+		}
+		if (!ranked(A, probs, order[root], order[child])) {
+			return;
+		}
+		int tmp = order[root];
+		order[root] = order[child];
+		order[child] = tmp;
+		root = child;
+	}
+}
+
+template <typename T>
+__device__ long long sampleRow(const T* A, int* order, int classes, unsigned long long seed, int row, double temperature, int topK, double topP, int probs) {
+	if (temperature == 0.0) {
+		int best = 0;
+		for (int i = 1; i < classes; i++) {
+			if (ranked(A, probs, i, best)) {
+				best = i;
+			}
+		}
+		return best;
+	}
+
+	for (int i = 0; i < classes; i++) {
+		order[i] = i;
+	}
+	for (int i = classes / 2 - 1; i >= 0; i--) {
+		sift(A, probs, order, i, classes);
+	}
+	for (int end = classes - 1; end > 0; end--) {
+		int tmp = order[0];
+		order[0] = order[end];
+		order[end] = tmp;
+		sift(A, probs, order, 0, end);
+	}
+
+	int n = classes;
+	if (topK > 0 && topK < classes) {
+		n = topK;
+	}
+	double top = logit(A, order[0], probs);
+	double sum = 0.0;
+	for (int i = 0; i < n; i++) {
+		sum += exp((logit(A, order[i], probs) - top) / temperature);
+	}
+	double total = 1.0;
+	if (topP > 0.0) {
+		double cum = 0.0;
+		for (int i = 0; i < n; i++) {
+			cum += exp((logit(A, order[i], probs) - top) / temperature) / sum;
+			if (cum >= topP) {
+				n = i + 1;
+				break;
+			}
+		}
+		total = cum;
+	}
+
+	uint4 r = philox(seed, row, 0);
+	double u = u64(r.x, r.y) * total;
+	for (int i = 0; i < n; i++) {
+		u -= exp((logit(A, order[i], probs) - top) / temperature) / sum;
+		if (u < 0.0) {
+			return order[i];
+		}
+	}
+	return order[n - 1];
+}
+
+extern "C" {
+	__global__ void sample_f32(const float* A, long long* out, int* order, int size, int classes, unsigned long long seed, double temperature, int topK, double topP, int probs) {
+		THREADID
+		CHECKSIZE
+		out[idx] = sampleRow(A + idx * classes, order + idx * classes, classes, seed, idx, temperature, topK, topP, probs);
+	}
+}
+
+extern "C" {
+	__global__ void sample_f64(const double* A, long long* out, int* order, int size, int classes, unsigned long long seed, double temperature, int topK, double topP, int probs) {
+		THREADID
+		CHECKSIZE
+		out[idx] = sampleRow(A + idx * classes, order + idx * classes, classes, seed, idx, temperature, topK, topP, probs);
+	}
+}
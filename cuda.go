@@ -5,13 +5,13 @@ package gorgonia
 // for non-cuda builds, look at noextern.go
 
 import (
-	"log"
 	"sync"
 
 	"github.com/pkg/errors"
 	"gorgonia.org/cu"
 	cudnn "gorgonia.org/cu/dnn"
 	"gorgonia.org/gorgonia/cuda"
+	"gorgonia.org/gorgonia/internal/logging"
 	"gorgonia.org/tensor"
 )
 
@@ -121,7 +121,11 @@ func (m *ExternMetadata) Get(dev Device, size int64) (tensor.Memory, error) {
 	if d >= len(m.engines) {
 		return nil, noopError{} // this should not be a noopError
 	}
-	return m.engines[dev].Get(size)
+	mem, err := m.engines[dev].Get(size)
+	if err == nil {
+		runtimeMetrics.device(dev, size)
+	}
+	return mem, err
 }
 
 // GetFromValue allocates a memory on the GPU, and then copies the data over. v MUST be on CPU.
@@ -136,6 +140,7 @@ func (m *ExternMetadata) GetFromValue(dev Device, v Value) (tensor.Memory, error
 	if err != nil {
 		return nil, err
 	}
+	runtimeMetrics.device(dev, memsize)
 	ptr := cu.DevicePtr(mem.Uintptr())
 	ctx := m.engines[dev].Context()
 	ctx.MemcpyHtoD(ptr, v.Pointer(), memsize)
@@ -150,6 +155,7 @@ func (m *ExternMetadata) Put(dev Device, mem tensor.Memory, size int64) {
 	}
 
 	m.engines[dev].Put(mem, size)
+	runtimeMetrics.device(dev, -size)
 }
 
 // PutValue puts a previously allocated memory slab back into the pool
@@ -160,6 +166,7 @@ func (m *ExternMetadata) PutValue(dev Device, v Value) {
 	}
 	memsize := calcMemSize(v.Dtype(), v.Shape())
 	m.engines[dev].Put(v, memsize)
+	runtimeMetrics.device(dev, -memsize)
 }
 
 // Transfer transfers data from device to device.
@@ -257,6 +264,7 @@ func (m *ExternMetadata) init(sizes []int64) (err error) {
 
 	m.initialized = true
 	cudaLogf("CUDA initialized. Engines: %v", m.engines)
+	logging.Log(logging.Engine, logging.Info, "initialized CUDA", "devices", len(m.engines), "memory", sizes)
 	return nil
 }
 
@@ -306,10 +314,6 @@ func AddToStdLib(name, data string, funcs []string) {
 	})
 }
 
-func init() {
-	log.Println("Using CUDA build")
-}
-
 // ValueOnDevice gets the value of the node as a Value but on the desired device. If the node's valud is not on the same device
 // as the desired device, a copy will be made.
 func (n *Node) ValueOnDevice(toDev Device, extern External) (retVal Value, allocOnExtern bool, err error) {
@@ -2,9 +2,9 @@ package cuda
 
 import (
 	"fmt"
-	"log"
 
 	"github.com/pkg/errors"
+	"gorgonia.org/gorgonia/internal/logging"
 	"gorgonia.org/tensor"
 )
 
@@ -40,7 +40,7 @@ func handleFuncOpts(expShape tensor.Shape, expType tensor.Dtype, o tensor.DataOr
 		}
 
 		if reuse.DataSize() != expShape.TotalSize() && !expShape.IsScalar() {
-			log.Printf("REUSE CHECK reuse shape %v, expected Shape %v", reuse.Shape(), expShape)
+			logging.Log(logging.Engine, logging.Debug, "reuse shape mismatch", "reuse", reuse.Shape(), "expected", expShape)
 			err = errors.Errorf(shapeMismatch, reuse.Shape(), expShape)
 			err = errors.Wrapf(err, "Cannot use reuse: shape mismatch - reuse.len() %v, expShape.TotalSize() %v", reuse.DataSize(), expShape.TotalSize())
 			return
@@ -76,7 +76,7 @@ func binaryCheck(a, b tensor.Tensor) (err error) {
 	}
 
 	if !a.Shape().Eq(b.Shape()) {
-		log.Printf("BINARY CHECK %v %v", a.Shape(), b.Shape())
+		logging.Log(logging.Engine, logging.Debug, "operand shape mismatch", "a", a.Shape(), "b", b.Shape())
 		return errors.Errorf(shapeMismatch, b.Shape(), a.Shape())
 	}
 
@@ -139,3 +139,36 @@ func BenchmarkOneMil(b *testing.B) {
 		m.Reset()
 	}
 }
+
+func TestWithDevice_CUDA(t *testing.T) {
+	run := func(placeOnCPU bool) []float64 {
+		g := NewGraph()
+		x := NewMatrix(g, Float64, WithShape(4, 5), WithName("x"), WithInit(RangedFrom(0)))
+		y := NewMatrix(g, Float64, WithShape(4, 5), WithName("y"), WithInit(ValuesOf(2.0)))
+		xpy := Must(Add(x, y))
+		xpy2 := Must(Square(xpy))
+		if placeOnCPU {
+			WithDevice(CPU)(xpy)
+		}
+		m := NewTapeMachine(g)
+		defer m.Close()
+		if err := m.RunAll(); err != nil {
+			t.Fatalf("%+v", err)
+		}
+		if placeOnCPU && xpy.Device() != CPU {
+			t.Errorf("Expected %v to stay on the CPU. It is on %v", xpy, xpy.Device())
+		}
+		if xpy2.Device() != CUDADevice(0) {
+			t.Errorf("Expected %v to be executed on CUDA:0. It is on %v", xpy2, xpy2.Device())
+		}
+		return append([]float64(nil), xpy2.Value().Data().([]float64)...)
+	}
+
+	onGPU := run(false)
+	mixed := run(true)
+	for i := range onGPU {
+		if onGPU[i] != mixed[i] {
+			t.Fatalf("Expected the values to be the same wherever the nodes are executed. Got %v and %v", onGPU, mixed)
+		}
+	}
+}
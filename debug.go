@@ -9,13 +9,15 @@ import (
 	"os"
 	"strings"
 	"sync/atomic"
+
+	"gorgonia.org/gorgonia/internal/logging"
 )
 
 // DEBUG is a global flag that activates various debugging functions
 const DEBUG = true
 
 func init() {
-	log.Printf("DEBUG")
+	logging.Log(logging.Compile, logging.Info, "built with the debug tag")
 }
 
 // these constants are used during development time - mainly on tracing statements to see the values of certain things.
@@ -77,60 +79,88 @@ func logf(format string, others ...interface{}) {
 func compileLogf(format string, attrs ...interface{}) {
 	if compileDev {
 		logf(format, attrs...)
+		return
 	}
+	debugLogf(logging.Compile, format, attrs...)
 }
 
 func shapeLogf(format string, attrs ...interface{}) {
 	if shapeInferenceDev {
 		logf(format, attrs...)
+		return
 	}
+	debugLogf(logging.Compile, format, attrs...)
 }
 
 func typeSysLogf(format string, attrs ...interface{}) {
 	if typeSystemDev {
 		logf(format, attrs...)
+		return
 	}
+	debugLogf(logging.Compile, format, attrs...)
 }
 
 func symdiffLogf(format string, attrs ...interface{}) {
 	if symdiffDev {
 		logf(format, attrs...)
+		return
 	}
+	debugLogf(logging.Compile, format, attrs...)
 }
 
 func autodiffLogf(format string, attrs ...interface{}) {
 	if autodiffDev {
 		logf(format, attrs...)
+		return
 	}
+	debugLogf(logging.VM, format, attrs...)
 }
 
 func machineLogf(format string, attrs ...interface{}) {
 	if machineDev {
 		logf(format, attrs...)
+		return
 	}
+	debugLogf(logging.VM, format, attrs...)
 }
 
 func stabLogf(format string, attrs ...interface{}) {
 	if stabilizationDev {
 		logf(format, attrs...)
+		return
 	}
+	debugLogf(logging.Compile, format, attrs...)
 }
 
 func solverLogf(format string, attrs ...interface{}) {
 	if solverDev {
 		logf(format, attrs...)
+		return
 	}
+	debugLogf(logging.Solver, format, attrs...)
 }
 
 func cudaLogf(format string, attrs ...interface{}) {
 	if cudaDev {
 		logf(format, attrs...)
+		return
 	}
+	debugLogf(logging.Engine, format, attrs...)
 }
 
 func allocatorLogf(format string, attrs ...interface{}) {
 	if allocatorDev {
 		logf(format, attrs...)
+		return
+	}
+	debugLogf(logging.Engine, format, attrs...)
+}
+
+// debugLogf sends the tracing statements of a subsystem whose dev flag is off to the structured logger, if its level
+// is LevelDebug.
+func debugLogf(s logging.Subsystem, format string, attrs ...interface{}) {
+	if logging.Enabled(s, logging.Debug) {
+		logging.Log(s, logging.Debug, fmt.Sprintf(format, attrs...))
 	}
 }
 
@@ -10,13 +10,12 @@ type Device int
 const (
 	// CPU the only device the graph will be executed on
 	CPU Device = 0
-)
 
-// String implements fmt.Stringer and runtime.Stringer
-func (d Device) String() string { return "CPU" }
+	cudaDevice0 = 1
+)
 
-// IsGPU will always return false in this build
-func (d Device) IsGPU() bool { return false }
+// deviceAvailable returns true if the graph can be executed on the device in this build.
+func deviceAvailable(d Device) bool { return d == CPU }
 
 // Alloc allocates memory on the device. This is currently a NO-OP in this build
 func (d Device) Alloc(extern External, size int64) (tensor.Memory, error) { return nil, nil }
@@ -13,8 +13,10 @@ type Device cu.Device
 // CPU is the default the graph will be executed on.
 const CPU = Device(cu.CPU)
 
-// String implements fmt.Stringer and runtime.Stringer
-func (d Device) String() string { return cu.Device(d).String() }
+const cudaDevice0 = 0
+
+// deviceAvailable returns true if the graph can be executed on the device in this build.
+func deviceAvailable(d Device) bool { return d == CPU || d.Kind() == KindCUDA }
 
 // Alloc allocates memory on the device. If the device is CPU, the allocations is a NO-OP because Go handles all the allocations in the CPU
 func (d Device) Alloc(extern External, size int64) (tensor.Memory, error) {
@@ -17,3 +17,13 @@ func TestDeviceCPU(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestWithDevice_unavailable(t *testing.T) {
+	g := NewGraph()
+	x := NewVector(g, Float64, WithShape(3), WithName("x"))
+	y := Must(Mul(x, x))
+	WithDevice(CUDADevice(0))(y)
+	if _, _, err := Compile(g); err == nil {
+		t.Error("Expected CUDA devices to be unavailable in this build")
+	}
+}
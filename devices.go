@@ -0,0 +1,126 @@
+package gorgonia
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// DeviceKind is the kind of a Device.
+type DeviceKind byte
+
+// Device kinds
+const (
+	KindCPU DeviceKind = iota
+	KindCUDA
+	KindOpenCL
+	KindMetal
+)
+
+func (k DeviceKind) String() string {
+	switch k {
+	case KindCPU:
+		return "CPU"
+	case KindCUDA:
+		return "CUDA"
+	case KindOpenCL:
+		return "OpenCL"
+	case KindMetal:
+		return "Metal"
+	}
+	return fmt.Sprintf("DeviceKind(%d)", byte(k))
+}
+
+// The CUDA devices are numbered from cudaDevice0, so that they are the devices of package cu in the cuda build. The other
+// kinds of devices are numbered above them.
+const (
+	openCLDevice0 = 1 << 20
+	metalDevice   = 2 << 20
+)
+
+// MetalDevice is the GPU of the Apple platforms.
+const MetalDevice = Device(metalDevice)
+
+// CUDADevice returns the nth CUDA device.
+func CUDADevice(n int) Device { return Device(cudaDevice0 + n) }
+
+// OpenCLDevice returns the nth OpenCL device.
+func OpenCLDevice(n int) Device { return Device(openCLDevice0 + n) }
+
+// Kind returns the kind of the device.
+func (d Device) Kind() DeviceKind {
+	switch {
+	case d == CPU:
+		return KindCPU
+	case d >= metalDevice:
+		return KindMetal
+	case d >= openCLDevice0:
+		return KindOpenCL
+	}
+	return KindCUDA
+}
+
+// Ordinal returns the number of the device amongst its kind. It is 0 for the CPU and Metal.
+func (d Device) Ordinal() int {
+	switch d.Kind() {
+	case KindCUDA:
+		return int(d - cudaDevice0)
+	case KindOpenCL:
+		return int(d - openCLDevice0)
+	}
+	return 0
+}
+
+// String implements fmt.Stringer and runtime.Stringer. The devices are written as "CPU", "CUDA:0", "OpenCL:1" or "Metal".
+func (d Device) String() string {
+	switch k := d.Kind(); k {
+	case KindCUDA, KindOpenCL:
+		return fmt.Sprintf("%v:%d", k, d.Ordinal())
+	default:
+		return k.String()
+	}
+}
+
+// IsGPU returns true if the device is not the CPU.
+func (d Device) IsGPU() bool { return d != CPU }
+
+// supports returns true if the op can be executed on the device.
+func (d Device) supports(op Op) bool {
+	switch d.Kind() {
+	case KindCPU:
+		return true
+	case KindCUDA:
+		_, ok := op.(CUDADoer)
+		return ok
+	case KindOpenCL:
+		_, ok := op.(CLDoer)
+		return ok
+	}
+	return false
+}
+
+// checkPlacement checks that the nodes placed with WithDevice can be executed where they are placed.
+func checkPlacement(sorted Nodes) error {
+	for _, n := range sorted {
+		if err := placementError(n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// placementError returns why the node cannot be executed where it was placed with WithDevice, if it cannot.
+func placementError(n *Node) error {
+	if !n.placed || n.dataOn == CPU {
+		return nil
+	}
+	switch {
+	case !deviceAvailable(n.dataOn):
+		return errors.Errorf("Cannot place %v on %v: the device is not supported by this build", n, n.dataOn)
+	case n.op == nil || n.isArg():
+		return errors.Errorf("Cannot place %v on %v: the values of the inputs are bound on the CPU", n, n.dataOn)
+	case !n.dataOn.supports(n.op):
+		return errors.Errorf("Cannot place %v on %v: %v cannot be executed on that device", n, n.dataOn, n.op)
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package gorgonia
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorgonia.org/tensor"
+)
+
+func TestDeviceKind(t *testing.T) {
+	assert := assert.New(t)
+	cases := []struct {
+		dev     Device
+		kind    DeviceKind
+		ordinal int
+		s       string
+	}{
+		{CPU, KindCPU, 0, "CPU"},
+		{CUDADevice(0), KindCUDA, 0, "CUDA:0"},
+		{CUDADevice(3), KindCUDA, 3, "CUDA:3"},
+		{OpenCLDevice(1), KindOpenCL, 1, "OpenCL:1"},
+		{MetalDevice, KindMetal, 0, "Metal"},
+	}
+	for _, c := range cases {
+		assert.Equal(c.kind, c.dev.Kind(), "%v", c.s)
+		assert.Equal(c.ordinal, c.dev.Ordinal(), "%v", c.s)
+		assert.Equal(c.s, c.dev.String())
+		assert.Equal(c.dev != CPU, c.dev.IsGPU(), "%v", c.s)
+	}
+}
+
+func TestWithDevice(t *testing.T) {
+	assert := assert.New(t)
+	g := NewGraph()
+	x := NewVector(g, Float64, WithShape(3), WithName("x"), WithValue(tensor.New(tensor.WithBacking([]float64{1, 2, 3}))))
+	y := Must(HadamardProd(x, x))
+	WithDevice(CPU)(y)
+	assert.True(y.placed)
+	assert.Equal(CPU, y.Device())
+	assert.True(y.Clone().(*Node).placed)
+
+	m := NewTapeMachine(g)
+	if err := m.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+	m.Close()
+	assert.Equal([]float64{1, 4, 9}, y.Value().Data())
+	assert.Equal(CPU, y.Device(), "a node placed on the CPU stays there")
+
+	// no op can be run on Metal or OpenCL devices
+	for _, dev := range []Device{MetalDevice, OpenCLDevice(0)} {
+		WithDevice(dev)(y)
+		_, _, err := Compile(g)
+		assert.Error(err, "%v", dev)
+		assert.Error(NewLispMachine(g, ExecuteFwdOnly()).RunAll(), "%v", dev)
+	}
+
+	// the inputs are bound on the CPU
+	WithDevice(CPU)(y)
+	WithDevice(CUDADevice(0))(x)
+	_, _, err := Compile(g)
+	assert.Error(err)
+}
@@ -140,6 +140,7 @@ func Backpropagate(outputs, gradOutputs, wrt Nodes) (retVal Nodes, err error) {
 	defer leaveLogScope()
 
 	g := outputs[0].g
+	defer func() { g.backwardOf = nil }()
 
 	// this entire section about removing foreveralone nodes need a rethink
 	symdiffLogf("removing foreveralone nodes")
@@ -224,6 +225,9 @@ func Backpropagate(outputs, gradOutputs, wrt Nodes) (retVal Nodes, err error) {
 
 		symdiffLogf("Working on %x %v", node.ID(), node)
 		enterLogScope()
+		if detectAnomaly() {
+			g.backwardOf = node
+		}
 
 		// Check if there is any grads coming into this node
 		if len(nodeGradMap[node]) < 1 {
@@ -238,11 +242,10 @@ func Backpropagate(outputs, gradOutputs, wrt Nodes) (retVal Nodes, err error) {
 		// once we've reached a node, we already backpropagated from its dependents
 		// so we sum up the gradients
 		symdiffLogf("nodeGradMap[%x]: %d", node.ID(), nodeGradMap[node])
+		deriv := nodeGradMap[node][0]
 		if len(nodeGradMap[node]) > 1 {
-
-			var n *Node
 			symdiffLogf("reduce adding")
-			if n, err = ReduceAdd(nodeGradMap[node], WithGroupName(gradClust)); err != nil {
+			if deriv, err = reduceGrads(node, nodeGradMap[node]); err != nil {
 				leaveLogScope()
 				return nil, SymDiffError{
 					single:  node,
@@ -252,17 +255,19 @@ func Backpropagate(outputs, gradOutputs, wrt Nodes) (retVal Nodes, err error) {
 				}
 
 			}
-			symdiffLogf("reduced to... %x", n.ID())
-			// node.derives = append(node.derives, n)
-			n.derivOf = append(n.derivOf, node)
-			node.deriv = n
-			nodeGradMap[node] = Nodes{n}
-			// }
-		} else if len(nodeGradMap[node]) == 1 {
-			deriv := nodeGradMap[node][0]
-			deriv.derivOf = append(deriv.derivOf, node)
-			node.deriv = deriv
+			symdiffLogf("reduced to... %x", deriv.ID())
 		}
+		if deriv, err = applyGradHooks(node, deriv); err != nil {
+			leaveLogScope()
+			return nil, SymDiffError{
+				single:  node,
+				gradMap: nodeGradMap,
+				err:     err,
+			}
+		}
+		deriv.derivOf = append(deriv.derivOf, node)
+		node.deriv = deriv
+		nodeGradMap[node] = Nodes{deriv}
 
 		gradNode := nodeGradMap[node][0]
 		if !node.isInput() {
@@ -345,3 +350,11 @@ func SetDerivOf(deriv, of *Node) {
 	deriv.derivOf = append(deriv.derivOf, of)
 	of.deriv = deriv
 }
+
+// reduceGrads sums up the gradient terms of a node. The gradients of a tuple-valued node are summed element-wise.
+func reduceGrads(node *Node, grads Nodes) (*Node, error) {
+	if isTuple(node) {
+		return sumTupleGrads(node, grads)
+	}
+	return ReduceAdd(grads, WithGroupName(gradClust))
+}
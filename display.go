@@ -0,0 +1,282 @@
+package gorgonia
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"html"
+	"image/png"
+	"reflect"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+	"gorgonia.org/tensor"
+)
+
+// The display hooks of notebooks such as gophernotes: a value that has a method
+//
+//	SimpleRender() map[string]interface{}
+//
+// is displayed in the richest of the MIME types of the returned bundle that the front end supports. The graphs and the
+// nodes have such methods, and DisplayTensor and DisplayImage wrap the tensors, which belong to another package.
+
+// displayEdgeItems is the number of the first and of the last indices of an axis that the HTML tables of the tensors
+// show, if the axis is longer than twice as many. The others are elided, as in the summaries of numpy.
+const displayEdgeItems = 8
+
+// Displayable is a value that notebooks display richly. See DisplayTensor and DisplayImage.
+type Displayable struct {
+	bundle map[string]interface{}
+}
+
+// SimpleRender returns the MIME bundle of the value, which maps the MIME types to their representations.
+func (d Displayable) SimpleRender() map[string]interface{} { return d.bundle }
+
+// DisplayTensor returns a tensor as a value that notebooks display as an HTML table, or as text.
+func DisplayTensor(t tensor.Tensor) Displayable {
+	return Displayable{map[string]interface{}{
+		"text/plain": fmt.Sprintf("%v", t),
+		"text/html":  TensorHTML(t),
+	}}
+}
+
+// DisplayImage returns a tensor of images, as converted by TensorToImages with the given options, as a value that
+// notebooks display as PNG images. The images of a batch are displayed side by side.
+func DisplayImage(t tensor.Tensor, opts ...ImageOpt) (Displayable, error) {
+	imgs, err := TensorToImages(t, opts...)
+	if err != nil {
+		return Displayable{}, err
+	}
+	var buf bytes.Buffer
+	var tags bytes.Buffer
+	for i, img := range imgs {
+		var p bytes.Buffer
+		if err = png.Encode(&p, img); err != nil {
+			return Displayable{}, errors.Wrapf(err, "Cannot encode the image %d", i)
+		}
+		if i == 0 {
+			buf.Write(p.Bytes())
+		}
+		fmt.Fprintf(&tags, `<img src="data:image/png;base64,%s" style="margin:2px"/>`, base64.StdEncoding.EncodeToString(p.Bytes()))
+	}
+	bundle := map[string]interface{}{
+		"text/plain": fmt.Sprintf("%d images of %v", len(imgs), t.Shape()),
+		"text/html":  tags.String(),
+	}
+	if len(imgs) == 1 {
+		bundle["image/png"] = buf.Bytes()
+	}
+	return Displayable{bundle}, nil
+}
+
+// SimpleRender returns the MIME bundle of the graph, which notebooks display as its SVG drawing.
+func (g *ExprGraph) SimpleRender() map[string]interface{} {
+	return map[string]interface{}{
+		"text/plain":    fmt.Sprintf("ExprGraph of %d nodes", len(g.AllNodes())),
+		"image/svg+xml": g.SVG(),
+	}
+}
+
+// SimpleRender returns the MIME bundle of the node, which notebooks display as its type and shape, followed by the
+// HTML table of its value if it has one.
+func (n *Node) SimpleRender() map[string]interface{} {
+	head := fmt.Sprintf("%v :: %v %v", n.Name(), n.t, n.shape)
+	bundle := map[string]interface{}{"text/plain": head}
+	body := "<i>no value</i>"
+	if v := n.Value(); v != nil {
+		bundle["text/plain"] = fmt.Sprintf("%s\n%v", head, v)
+		if t, ok := v.(tensor.Tensor); ok {
+			body = TensorHTML(t)
+		} else {
+			body = html.EscapeString(fmt.Sprintf("%v", v))
+		}
+	}
+	bundle["text/html"] = fmt.Sprintf("<div><code>%s</code></div>%s", html.EscapeString(head), body)
+	return bundle
+}
+
+// TensorHTML returns an HTML table of the elements of a tensor. A tensor of more than two dimensions is shown as a table
+// of each of its matrices, captioned by their indices. The middle indices of the long axes are elided.
+func TensorHTML(t tensor.Tensor) string {
+	var buf bytes.Buffer
+	shape := t.Shape()
+	fmt.Fprintf(&buf, `<div><small>%v %v</small>`, shape, t.Dtype())
+	data, err := valueSlice(t)
+	if err != nil {
+		fmt.Fprintf(&buf, `<pre>%s</pre></div>`, html.EscapeString(err.Error()))
+		return buf.String()
+	}
+
+	switch {
+	case shape.IsScalar():
+		fmt.Fprintf(&buf, "<pre>%v</pre></div>", html.EscapeString(fmt.Sprint(data.Index(0).Interface())))
+		return buf.String()
+	case len(shape) == 1:
+		writeHTMLTable(&buf, data, 0, 1, shape[0], "")
+		buf.WriteString("</div>")
+		return buf.String()
+	}
+
+	// the matrices of the last two axes, whose leading indices are iterated in the row major order
+	lead := shape[:len(shape)-2]
+	rows, cols := shape[len(shape)-2], shape[len(shape)-1]
+	if len(lead) == 0 {
+		writeHTMLTable(&buf, data, 0, rows, cols, "")
+		buf.WriteString("</div>")
+		return buf.String()
+	}
+	idx := make([]int, len(lead))
+	for {
+		var off int
+		for i, k := range idx {
+			off = off*lead[i] + k
+		}
+		caption := strings.Trim(strings.Replace(fmt.Sprint(idx), " ", ", ", -1), "[]")
+		caption = "[" + caption + ", :, :]"
+		writeHTMLTable(&buf, data, off*rows*cols, rows, cols, caption)
+
+		// the next leading index, skipping the elided ones
+		i := len(idx) - 1
+		for ; i >= 0; i-- {
+			idx[i] = nextShown(idx[i], lead[i])
+			if idx[i] < lead[i] {
+				break
+			}
+			idx[i] = 0
+		}
+		if i < 0 {
+			break
+		}
+	}
+	buf.WriteString("</div>")
+	return buf.String()
+}
+
+// nextShown returns the index after i of an axis of length n, skipping the elided ones.
+func nextShown(i, n int) int {
+	i++
+	if n > 2*displayEdgeItems && i == displayEdgeItems {
+		return n - displayEdgeItems
+	}
+	return i
+}
+
+// writeHTMLTable writes the matrix of rows by cols elements from off as a table.
+func writeHTMLTable(buf *bytes.Buffer, data reflect.Value, off, rows, cols int, caption string) {
+	buf.WriteString(`<table style="font-family:monospace">`)
+	if caption != "" {
+		fmt.Fprintf(buf, `<caption style="text-align:left">%s</caption>`, html.EscapeString(caption))
+	}
+	for r := 0; r < rows; r = nextShown(r, rows) {
+		if rows > 2*displayEdgeItems && r == rows-displayEdgeItems {
+			buf.WriteString(`<tr><td>&vellip;</td></tr>`)
+		}
+		buf.WriteString("<tr>")
+		for c := 0; c < cols; c = nextShown(c, cols) {
+			if cols > 2*displayEdgeItems && c == cols-displayEdgeItems {
+				buf.WriteString("<td>&hellip;</td>")
+			}
+			fmt.Fprintf(buf, `<td style="text-align:right">%s</td>`, html.EscapeString(fmt.Sprintf("%v", data.Index(off+r*cols+c).Interface())))
+		}
+		buf.WriteString("</tr>")
+	}
+	buf.WriteString("</table>")
+}
+
+// SVG returns a drawing of the graph, whose nodes are laid out in layers from the inputs at the top down to the roots.
+// The inputs are yellow and the constants pink, as in the dot encoding of the graphs; the edges go from the operands
+// to the nodes that use them.
+func (g *ExprGraph) SVG() string {
+	const (
+		charWidth   = 7
+		boxHeight   = 36
+		layerHeight = 76
+		gap         = 16
+		margin      = 10
+	)
+
+	// the layer of a node is one more than the largest layer of its operands
+	nodes := g.AllNodes()
+	layer := make(map[*Node]int)
+	var depth func(n *Node) int
+	depth = func(n *Node) int {
+		if l, ok := layer[n]; ok {
+			return l
+		}
+		layer[n] = 0 // guards against the cycles of malformed graphs
+		l := 0
+		for _, child := range n.children {
+			if d := depth(child) + 1; d > l {
+				l = d
+			}
+		}
+		layer[n] = l
+		return l
+	}
+	var layers []Nodes
+	for _, n := range nodes {
+		l := depth(n)
+		for len(layers) <= l {
+			layers = append(layers, nil)
+		}
+		layers[l] = append(layers[l], n)
+	}
+
+	type box struct {
+		x, y, w int
+		label   [2]string
+	}
+	boxes := make(map[*Node]box)
+	width := 0
+	for l, ns := range layers {
+		x := margin
+		for _, n := range ns {
+			label := [2]string{n.name, fmt.Sprint(n.shape)}
+			if n.op != nil && (n.name == "" || !n.isConstant()) {
+				label[0] = n.op.String()
+			}
+			chars := utf8.RuneCountInString(label[0])
+			if c := utf8.RuneCountInString(label[1]); c > chars {
+				chars = c
+			}
+			w := chars*charWidth + 2*gap
+			boxes[n] = box{x: x, y: margin + l*layerHeight, w: w, label: label}
+			x += w + gap
+		}
+		if x > width {
+			width = x
+		}
+	}
+	height := 2*margin + len(layers)*layerHeight - (layerHeight - boxHeight)
+	if len(layers) == 0 {
+		height = 2 * margin
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" font-family="monospace" font-size="12">`, width+margin, height)
+	buf.WriteString(`<defs><marker id="arrow" viewBox="0 0 10 10" refX="10" refY="5" markerWidth="6" markerHeight="6" orient="auto"><path d="M0,0L10,5L0,10z"/></marker></defs>`)
+	for _, n := range nodes {
+		b := boxes[n]
+		for _, child := range n.children {
+			c := boxes[child]
+			fmt.Fprintf(&buf, `<line x1="%d" y1="%d" x2="%d" y2="%d" stroke="black" marker-end="url(#arrow)"/>`, c.x+c.w/2, c.y+boxHeight, b.x+b.w/2, b.y)
+		}
+	}
+	for _, n := range nodes {
+		b := boxes[n]
+		fill := "white"
+		switch {
+		case n.isConstant():
+			fill = "pink"
+		case n.isInput():
+			fill = "yellow"
+		}
+		fmt.Fprintf(&buf, `<g><title>%s</title>`, html.EscapeString(fmt.Sprintf("%v :: %v", n.Name(), n.t)))
+		fmt.Fprintf(&buf, `<rect x="%d" y="%d" width="%d" height="%d" rx="6" fill="%s" stroke="black"/>`, b.x, b.y, b.w, boxHeight, fill)
+		fmt.Fprintf(&buf, `<text x="%d" y="%d" text-anchor="middle">%s</text>`, b.x+b.w/2, b.y+15, html.EscapeString(b.label[0]))
+		fmt.Fprintf(&buf, `<text x="%d" y="%d" text-anchor="middle" fill="gray">%s</text></g>`, b.x+b.w/2, b.y+29, html.EscapeString(b.label[1]))
+	}
+	buf.WriteString("</svg>")
+	return buf.String()
+}
@@ -0,0 +1,130 @@
+package gorgonia
+
+import (
+	"bytes"
+	"encoding/xml"
+	"image/png"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorgonia.org/tensor"
+)
+
+// wellFormed checks that s is well formed XML, as the notebooks require of the SVG drawings.
+func wellFormed(t *testing.T, s string) {
+	d := xml.NewDecoder(strings.NewReader(s))
+	for {
+		_, err := d.Token()
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			t.Fatalf("%v: %v", err, s)
+		}
+	}
+}
+
+func TestTensorHTML(t *testing.T) {
+	assert := assert.New(t)
+
+	s := TensorHTML(tensor.New(tensor.WithShape(2, 3), tensor.WithBacking([]float64{1, 2, 3, 4, 5, 6})))
+	assert.Contains(s, "(2, 3) float64")
+	assert.Equal(2, strings.Count(s, "<tr>"))
+	assert.Equal(6, strings.Count(s, "<td"))
+	assert.NotContains(s, "<caption")
+	wellFormed(t, s)
+
+	// the matrices of the leading indices
+	s = TensorHTML(tensor.New(tensor.WithShape(2, 2, 1, 2), tensor.WithBacking(tensor.Range(tensor.Int, 0, 8))))
+	assert.Equal(4, strings.Count(s, "<table"))
+	assert.Contains(s, "[0, 0, :, :]")
+	assert.Contains(s, "[1, 1, :, :]")
+	assert.Contains(s, ">7</td>")
+
+	// the long axes are elided
+	s = TensorHTML(tensor.New(tensor.WithShape(100), tensor.WithBacking(tensor.Range(tensor.Int, 0, 100))))
+	assert.Equal(2*displayEdgeItems+1, strings.Count(s, "<td"))
+	assert.Contains(s, ">7</td><td>&hellip;</td><td style=\"text-align:right\">92</td>")
+	s = TensorHTML(tensor.New(tensor.WithShape(20, 1, 1), tensor.WithBacking(tensor.Range(tensor.Int, 0, 20))))
+	assert.Equal(2*displayEdgeItems, strings.Count(s, "<table"))
+	assert.NotContains(s, "[8, :, :]")
+	assert.Contains(s, "[12, :, :]")
+
+	// the scalars, and the escaping of the elements
+	s = TensorHTML(tensor.New(tensor.FromScalar(3.5)))
+	assert.Contains(s, "<pre>3.5</pre>")
+	s = TensorHTML(tensor.New(tensor.WithShape(1), tensor.WithBacking([]string{"<b>"})))
+	assert.Contains(s, "&lt;b&gt;")
+}
+
+func TestDisplay(t *testing.T) {
+	assert := assert.New(t)
+
+	d := DisplayTensor(tensor.New(tensor.WithShape(2), tensor.WithBacking([]float32{1, 2})))
+	assert.Contains(d.SimpleRender()["text/html"], "<table")
+	assert.Contains(d.SimpleRender()["text/plain"], "[1  2]")
+
+	img := tensor.New(tensor.WithShape(1, 3, 1, 2), tensor.WithBacking([]float32{1, 0, 0, 0.4, 0.2, 1}))
+	d, err := DisplayImage(img)
+	if assert.NoError(err) {
+		p, ok := d.SimpleRender()["image/png"].([]byte)
+		if assert.True(ok) {
+			decoded, err := png.Decode(bytes.NewReader(p))
+			if assert.NoError(err) {
+				assert.Equal(2, decoded.Bounds().Dx())
+			}
+		}
+		assert.Contains(d.SimpleRender()["text/html"], "data:image/png;base64,")
+	}
+
+	// a batch is displayed as the HTML of its images
+	d, err = DisplayImage(tensor.New(tensor.WithShape(2, 1, 1, 2), tensor.WithBacking([]float32{0, 1, 1, 0})))
+	if assert.NoError(err) {
+		assert.Nil(d.SimpleRender()["image/png"])
+		assert.Equal(2, strings.Count(d.SimpleRender()["text/html"].(string), "<img"))
+	}
+	_, err = DisplayImage(tensor.New(tensor.WithShape(2, 2), tensor.WithBacking([]float32{0, 1, 1, 0})))
+	assert.Error(err)
+}
+
+func TestExprGraph_SVG(t *testing.T) {
+	assert := assert.New(t)
+	g := NewGraph()
+	x := NewMatrix(g, Float64, WithShape(2, 3), WithName("x<1>"))
+	w := NewMatrix(g, Float64, WithShape(3, 2), WithName("w"))
+	xw := Must(Mul(x, w))
+	Must(Add(xw, NewConstant(1.0)))
+
+	s := g.SVG()
+	wellFormed(t, s)
+	assert.Equal(len(g.AllNodes()), strings.Count(s, "<rect"))
+	assert.Equal(4, strings.Count(s, "<line"))
+	assert.Equal(2, strings.Count(s, `fill="yellow"`))
+	assert.Equal(1, strings.Count(s, `fill="pink"`))
+	assert.Contains(s, "x&lt;1&gt;")
+	assert.Contains(s, ">(2, 2)</text>")
+	assert.Equal(s, g.SimpleRender()["image/svg+xml"])
+
+	wellFormed(t, NewGraph().SVG())
+}
+
+func TestNode_SimpleRender(t *testing.T) {
+	assert := assert.New(t)
+	g := NewGraph()
+	x := NewVector(g, Float64, WithShape(2), WithName("x"))
+	bundle := x.SimpleRender()
+	assert.Equal("x :: Vector float64 (2)", bundle["text/plain"])
+	assert.Contains(bundle["text/html"], "no value")
+
+	if err := Let(x, tensor.New(tensor.WithShape(2), tensor.WithBacking([]float64{1, 2}))); err != nil {
+		t.Fatal(err)
+	}
+	bundle = x.SimpleRender()
+	assert.Contains(bundle["text/html"], "<table")
+	assert.Contains(bundle["text/plain"], "[1  2]")
+
+	s := NewScalar(g, Float64, WithName("s"), WithValue(2.5))
+	assert.Contains(s.SimpleRender()["text/html"], "2.5")
+}
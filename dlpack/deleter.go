@@ -0,0 +1,20 @@
+package dlpack
+
+/*
+#include <stdlib.h>
+#include "dlpack.h"
+*/
+import "C"
+
+import "unsafe"
+
+// gorgoniaDLPackDeleter releases a tensor exported by ToDLPack. It is called by the consumer of the tensor.
+//
+//export gorgoniaDLPackDeleter
+func gorgoniaDLPackDeleter(m *C.DLManagedTensor) {
+	exportedMu.Lock()
+	delete(exported, uintptr(unsafe.Pointer(m)))
+	exportedMu.Unlock()
+	C.free(unsafe.Pointer(m.dl_tensor.shape))
+	C.free(unsafe.Pointer(m))
+}
@@ -0,0 +1,210 @@
+// Package dlpack exchanges tensors with the other runtimes of the process, such as ONNX Runtime or an embedded Python,
+// through DLPack (https://github.com/dmlc/dlpack). The tensors are shared without copying them when their layouts
+// allow it.
+//
+// ToDLPack and FromDLPack work with the *DLManagedTensor of the DLPack ABI, as an unsafe.Pointer. Bridges to Python
+// wrap it into a PyCapsule named "dltensor", and unwrap the capsules that other runtimes give them, as the DLPack
+// capsule protocol specifies.
+package dlpack
+
+/*
+#include <stdlib.h>
+#include "dlpack.h"
+
+static void setDeleter(DLManagedTensor* m) { m->deleter = gorgoniaDLPackDeleter; }
+static void callDeleter(DLManagedTensor* m) {
+	if (m->deleter != NULL) {
+		m->deleter(m);
+	}
+}
+*/
+import "C"
+
+import (
+	"reflect"
+	"sync"
+	"unsafe"
+
+	"github.com/pkg/errors"
+	"gorgonia.org/tensor"
+)
+
+// the DLDeviceType and DLDataTypeCode of the DLPack ABI
+const (
+	kDLCPU = 1
+
+	kDLInt     = 0
+	kDLUInt    = 1
+	kDLFloat   = 2
+	kDLComplex = 5
+	kDLBool    = 6
+)
+
+var dtypes = []struct {
+	dt   tensor.Dtype
+	code uint8
+}{
+	{tensor.Bool, kDLBool},
+	{tensor.Int, kDLInt}, {tensor.Int8, kDLInt}, {tensor.Int16, kDLInt}, {tensor.Int32, kDLInt}, {tensor.Int64, kDLInt},
+	{tensor.Uint, kDLUInt}, {tensor.Uint8, kDLUInt}, {tensor.Uint16, kDLUInt}, {tensor.Uint32, kDLUInt}, {tensor.Uint64, kDLUInt},
+	{tensor.Float32, kDLFloat}, {tensor.Float64, kDLFloat},
+	{tensor.Complex64, kDLComplex}, {tensor.Complex128, kDLComplex},
+}
+
+var (
+	exportedMu sync.Mutex
+	// exported are the tensors exported by ToDLPack, by the address of their DLManagedTensor. They are kept here so
+	// that their memory is not collected until the consumer calls the deleter.
+	exported = make(map[uintptr]tensor.Tensor)
+)
+
+// ToDLPack exports a tensor on the CPU as a *DLManagedTensor, which the consumer must delete by calling its deleter. A
+// contiguous tensor is shared with the consumer, and must not be written to until the deleter is called. The other
+// tensors, such as the transposes, are copied.
+//
+// The DLManagedTensor is allocated in C, but points to the memory of the tensor, which Go does not move. Programs run
+// with GOEXPERIMENT=cgocheck2 will report it.
+func ToDLPack(t tensor.Tensor) (unsafe.Pointer, error) {
+	var code uint8
+	var ok bool
+	for _, d := range dtypes {
+		if d.dt == t.Dtype() {
+			code, ok = d.code, true
+		}
+	}
+	if !ok {
+		return nil, errors.Errorf("Cannot export a tensor of %v", t.Dtype())
+	}
+	if d, ok := t.(*tensor.Dense); ok && d.IsMasked() {
+		return nil, errors.New("Cannot export a masked tensor")
+	}
+	if t.RequiresIterator() {
+		t = tensor.Materialize(t)
+	}
+
+	m := (*C.DLManagedTensor)(C.calloc(1, C.sizeof_DLManagedTensor))
+	dims := t.Dims()
+	dl := &m.dl_tensor
+	dl.data = t.Pointer()
+	dl.device = C.DLDevice{device_type: kDLCPU}
+	dl.ndim = C.int32_t(dims)
+	dl.dtype = C.DLDataType{code: C.uint8_t(code), bits: C.uint8_t(t.Dtype().Size() * 8), lanes: 1}
+	if dims > 0 {
+		// the shape and the strides share an allocation, which the deleter frees
+		dl.shape = (*C.int64_t)(C.malloc(C.size_t(2 * dims * 8)))
+		dl.strides = (*C.int64_t)(unsafe.Pointer(uintptr(unsafe.Pointer(dl.shape)) + uintptr(dims*8)))
+		shape, strides := int64s(dl.shape, dims), int64s(dl.strides, dims)
+		stride := int64(1)
+		for i := dims - 1; i >= 0; i-- {
+			shape[i], strides[i] = int64(t.Shape()[i]), stride
+			stride *= int64(t.Shape()[i])
+		}
+	}
+	C.setDeleter(m)
+
+	exportedMu.Lock()
+	exported[uintptr(unsafe.Pointer(m))] = t
+	exportedMu.Unlock()
+	return unsafe.Pointer(m), nil
+}
+
+// FromDLPack imports a *DLManagedTensor on the CPU. The returned tensor shares the memory of a compact row major
+// DLTensor, so the caller must call release when it is done with the tensor, which calls the deleter of the
+// DLManagedTensor. The other DLTensors are copied, and deleted before FromDLPack returns. release may be called more
+// than once.
+func FromDLPack(p unsafe.Pointer) (t *tensor.Dense, release func(), err error) {
+	m := (*C.DLManagedTensor)(p)
+	var once sync.Once
+	del := func() { once.Do(func() { C.callDeleter(m) }) }
+	defer func() {
+		if err != nil {
+			del()
+		}
+	}()
+
+	dl := &m.dl_tensor
+	if dl.device.device_type != kDLCPU {
+		return nil, nil, errors.Errorf("Cannot import a tensor of device type %d. Only the CPU (1) is supported", dl.device.device_type)
+	}
+	var dt tensor.Dtype
+	for _, d := range dtypes {
+		if C.uint8_t(d.code) == dl.dtype.code && C.uint8_t(d.dt.Size()*8) == dl.dtype.bits && d.dt != tensor.Int && d.dt != tensor.Uint {
+			dt = d.dt
+		}
+	}
+	if dt.Type == nil || dl.dtype.lanes != 1 {
+		return nil, nil, errors.Errorf("Cannot import a tensor of dtype code %d, %d bits and %d lanes", dl.dtype.code, dl.dtype.bits, dl.dtype.lanes)
+	}
+
+	dims := int(dl.ndim)
+	shape := make(tensor.Shape, dims)
+	compact := true
+	var strides []int64
+	if dims > 0 {
+		stride := int64(1)
+		sh := int64s(dl.shape, dims)
+		if dl.strides != nil {
+			strides = int64s(dl.strides, dims)
+		}
+		for i := dims - 1; i >= 0; i-- {
+			shape[i] = int(sh[i])
+			if strides != nil && sh[i] > 1 && strides[i] != stride {
+				compact = false
+			}
+			stride *= sh[i]
+		}
+	}
+	size := 1
+	for _, d := range shape {
+		size *= d
+	}
+	elemSize := int(dt.Size())
+	base := unsafe.Pointer(uintptr(dl.data) + uintptr(dl.byte_offset))
+
+	if compact && dims > 0 && size > 0 {
+		t = tensor.New(tensor.Of(dt), tensor.WithShape(shape...), tensor.FromMemory(uintptr(base), uintptr(size*elemSize)))
+		return t, del, nil
+	}
+
+	// copy the elements in the row major order
+	data := reflect.MakeSlice(reflect.SliceOf(dt.Type), size, size)
+	if size > 0 {
+		extent := int64(size - 1)
+		if strides != nil {
+			extent = 0
+			for i, d := range shape {
+				extent += int64(d-1) * strides[i]
+			}
+		}
+		src := reflect.NewAt(reflect.ArrayOf(int(extent)+1, dt.Type), base).Elem()
+		idx := make([]int, dims)
+		for i := 0; i < size; i++ {
+			off := i
+			if strides != nil {
+				off = 0
+				for j, k := range idx {
+					off += k * int(strides[j])
+				}
+			}
+			data.Index(i).Set(src.Index(off))
+			for j := dims - 1; j >= 0; j-- {
+				if idx[j]++; idx[j] < shape[j] {
+					break
+				}
+				idx[j] = 0
+			}
+		}
+	}
+	if dims == 0 {
+		t = tensor.New(tensor.FromScalar(data.Index(0).Interface()))
+	} else {
+		t = tensor.New(tensor.WithShape(shape...), tensor.WithBacking(data.Interface()))
+	}
+	del()
+	return t, func() {}, nil
+}
+
+// int64s returns the C array of n int64s at p as a slice.
+func int64s(p *C.int64_t, n int) []int64 {
+	return reflect.NewAt(reflect.ArrayOf(n, reflect.TypeOf(int64(0))), unsafe.Pointer(p)).Elem().Slice(0, n).Interface().([]int64)
+}
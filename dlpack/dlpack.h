@@ -0,0 +1,37 @@
+// The structs of the DLPack ABI (https://github.com/dmlc/dlpack), which the runtimes that exchange tensors agree on.
+#ifndef GORGONIA_DLPACK_H
+#define GORGONIA_DLPACK_H
+
+#include <stdint.h>
+
+typedef struct {
+	int32_t device_type;
+	int32_t device_id;
+} DLDevice;
+
+typedef struct {
+	uint8_t code;
+	uint8_t bits;
+	uint16_t lanes;
+} DLDataType;
+
+typedef struct {
+	void* data;
+	DLDevice device;
+	int32_t ndim;
+	DLDataType dtype;
+	int64_t* shape;
+	int64_t* strides;
+	uint64_t byte_offset;
+} DLTensor;
+
+typedef struct DLManagedTensor {
+	DLTensor dl_tensor;
+	void* manager_ctx;
+	void (*deleter)(struct DLManagedTensor* self);
+} DLManagedTensor;
+
+// gorgoniaDLPackDeleter is the deleter of the tensors exported by ToDLPack. It is defined in Go.
+extern void gorgoniaDLPackDeleter(DLManagedTensor* self);
+
+#endif
@@ -0,0 +1,110 @@
+package dlpack
+
+import (
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/assert"
+	"gorgonia.org/tensor"
+)
+
+// dlTensor mirrors the layout of DLTensor, so that the tests can inspect and change the exported tensors.
+type dlTensor struct {
+	data                 unsafe.Pointer
+	deviceType, deviceID int32
+	ndim                 int32
+	code, bits           uint8
+	lanes                uint16
+	shape, strides       *int64
+	byteOffset           uint64
+}
+
+func TestDLPack(t *testing.T) {
+	assert := assert.New(t)
+	a := tensor.New(tensor.WithShape(2, 3), tensor.WithBacking([]float32{1, 2, 3, 4, 5, 6}))
+	p, err := ToDLPack(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dl := (*dlTensor)(p)
+	assert.Equal(a.Pointer(), dl.data, "the memory is shared")
+	assert.Equal(int32(2), dl.ndim)
+	assert.Equal([]uint8{kDLFloat, 32}, []uint8{dl.code, dl.bits})
+	assert.Equal([]int64{2, 3}, int64s((*_Ctype_int64_t)(unsafe.Pointer(dl.shape)), 2))
+	assert.Equal([]int64{3, 1}, int64s((*_Ctype_int64_t)(unsafe.Pointer(dl.strides)), 2))
+
+	b, release, err := FromDLPack(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(tensor.Shape{2, 3}, b.Shape())
+	assert.Equal(a.Data(), b.Data())
+	b.Set(0, float32(10))
+	assert.Equal(float32(10), a.Float32s()[0], "the import shares the memory of the export")
+	assert.Len(exported, 1)
+	release()
+	release()
+	assert.Len(exported, 0, "the deleter is called once")
+}
+
+func TestDLPack_strided(t *testing.T) {
+	assert := assert.New(t)
+	a := tensor.New(tensor.WithShape(2, 3), tensor.WithBacking([]int64{1, 2, 3, 4, 5, 6}))
+	p, err := ToDLPack(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// describe the transpose, as a runtime with strided tensors would
+	dl := (*dlTensor)(p)
+	shape := int64s((*_Ctype_int64_t)(unsafe.Pointer(dl.shape)), 2)
+	strides := int64s((*_Ctype_int64_t)(unsafe.Pointer(dl.strides)), 2)
+	shape[0], shape[1], strides[0], strides[1] = 3, 2, 1, 3
+
+	b, release, err := FromDLPack(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer release()
+	assert.Equal(tensor.Shape{3, 2}, b.Shape())
+	assert.Equal([]int64{1, 4, 2, 5, 3, 6}, b.Data())
+	assert.Len(exported, 0, "a copied tensor is deleted right away")
+}
+
+func TestDLPack_copies(t *testing.T) {
+	assert := assert.New(t)
+	a := tensor.New(tensor.WithShape(2, 2), tensor.WithBacking([]bool{true, false, false, true}))
+	if err := a.T(); err != nil {
+		t.Fatal(err)
+	}
+	p, err := ToDLPack(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, release, err := FromDLPack(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]bool{true, false, false, true}, b.Data(), "the transpose is exported as a copy")
+	release()
+
+	s := tensor.New(tensor.FromScalar(2.5))
+	if p, err = ToDLPack(s); err != nil {
+		t.Fatal(err)
+	}
+	b, release, err = FromDLPack(p)
+	if assert.NoError(err) {
+		assert.True(b.Shape().IsScalar())
+		assert.Equal(2.5, b.Data())
+		release()
+	}
+
+	_, err = ToDLPack(tensor.New(tensor.WithBacking([]string{"a"})))
+	assert.Error(err)
+	if p, err = ToDLPack(tensor.New(tensor.WithShape(2), tensor.WithBacking([]int{1, 2}))); err != nil {
+		t.Fatal(err)
+	}
+	(*dlTensor)(p).deviceType = 2 // CUDA
+	_, _, err = FromDLPack(p)
+	assert.Error(err)
+	assert.Len(exported, 0, "the deleter is called on errors")
+}
@@ -0,0 +1,283 @@
+package gorgonia
+
+import (
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/pkg/errors"
+	"gorgonia.org/tensor"
+)
+
+// DPSGDSolver makes a solver differentially private (Abadi et al., 2016 - https://arxiv.org/abs/1607.00133).
+// It is given the per-sample gradients of the model (see PerSampleGrads and PerSampleValueGrads). In each step it:
+//		1. clips the gradient of each sample so that its L2 norm, over all the parameters, is at most clipNorm
+//		2. sums the clipped gradients, and adds Gaussian noise of standard deviation noiseMultiplier·clipNorm
+//		3. divides the result by the number of samples, and steps the wrapped solver with it
+//
+// The privacy spent is tracked by an accountant: see Epsilon.
+type DPSGDSolver struct {
+	Solver
+	clipNorm        float64
+	noiseMultiplier float64
+	rand            *rand.Rand
+	accountant      *PrivacyAccountant
+}
+
+// DPSGDOpt is a function that provides construction options for a DPSGDSolver.
+type DPSGDOpt func(*DPSGDSolver)
+
+// WithDPRand sets the source of the noise. By default, the noise is seeded with the current time.
+func WithDPRand(r *rand.Rand) DPSGDOpt {
+	return func(s *DPSGDSolver) { s.rand = r }
+}
+
+// NewDPSGDSolver wraps the solver. samplingRate is the probability of each example of the dataset being in a batch,
+// that is the batch size divided by the size of the dataset; it is only used to account for the privacy spent.
+func NewDPSGDSolver(s Solver, clipNorm, noiseMultiplier, samplingRate float64, opts ...DPSGDOpt) (*DPSGDSolver, error) {
+	if clipNorm <= 0 {
+		return nil, errors.Errorf("Expected a positive clipping norm. Got %v instead", clipNorm)
+	}
+	if noiseMultiplier <= 0 {
+		return nil, errors.Errorf("Expected a positive noise multiplier. Got %v instead", noiseMultiplier)
+	}
+	if samplingRate <= 0 || samplingRate > 1 {
+		return nil, errors.Errorf("Expected a sampling rate in (0, 1]. Got %v instead", samplingRate)
+	}
+	retVal := &DPSGDSolver{
+		Solver:          s,
+		clipNorm:        clipNorm,
+		noiseMultiplier: noiseMultiplier,
+		accountant:      NewPrivacyAccountant(samplingRate, noiseMultiplier),
+	}
+	for _, opt := range opts {
+		opt(retVal)
+	}
+	if retVal.rand == nil {
+		retVal.rand = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	return retVal, nil
+}
+
+// Step performs a private step. The Grad() of each element of the model must be the per-sample gradients of the
+// value: a tensor of shape (B, shape of the value...) for a batch of B samples.
+func (s *DPSGDSolver) Step(model []ValueGrad) (err error) {
+	logStep(s, model)
+	weights := make([]Value, len(model))
+	grads := make([][]float64, len(model))
+	batch := -1
+	for i, n := range model {
+		var grad Value
+		if weights[i], grad, err = extractWeightGrad(n); err != nil {
+			return err
+		}
+		var b int
+		if b, grads[i], err = perSampleData(weights[i], grad); err != nil {
+			return errors.Wrapf(err, "Invalid per-sample gradients for %v", n)
+		}
+		if batch >= 0 && b != batch {
+			return errors.Errorf("Expected the same number of samples for all the gradients. Got %d and %d", batch, b)
+		}
+		batch = b
+	}
+	if batch <= 0 {
+		return errors.New("Cannot step without samples")
+	}
+
+	// the clipping factor of each sample
+	factors := make([]float64, batch)
+	for _, g := range grads {
+		stride := len(g) / batch
+		for b := range factors {
+			for _, v := range g[b*stride : (b+1)*stride] {
+				factors[b] += v * v
+			}
+		}
+	}
+	for b, sq := range factors {
+		factors[b] = math.Min(1, s.clipNorm/math.Sqrt(sq))
+	}
+
+	private := make([]ValueGrad, len(model))
+	stdev := s.noiseMultiplier * s.clipNorm
+	for i, g := range grads {
+		stride := len(g) / batch
+		sum := make([]float64, stride)
+		for b, f := range factors {
+			for j, v := range g[b*stride : (b+1)*stride] {
+				sum[j] += f * v
+			}
+		}
+		for j := range sum {
+			sum[j] = (sum[j] + s.rand.NormFloat64()*stdev) / float64(batch)
+		}
+		var grad Value
+		if grad, err = valueLike(weights[i], sum); err != nil {
+			return err
+		}
+		private[i] = accumulatedGrad{ValueGrad: model[i], grad: grad}
+	}
+	if err = s.Solver.Step(private); err != nil {
+		return err
+	}
+	s.accountant.Step()
+	return nil
+}
+
+// Epsilon returns the ε of the (ε, δ)-differential privacy guaranteed by the steps taken so far.
+func (s *DPSGDSolver) Epsilon(delta float64) float64 { return s.accountant.Epsilon(delta) }
+
+// Accountant returns the privacy accountant of the solver.
+func (s *DPSGDSolver) Accountant() *PrivacyAccountant { return s.accountant }
+
+// perSampleData returns the number of samples and the per-sample gradients as float64s, checking that the gradients
+// have the shape (B, shape of the weights...).
+func perSampleData(weights, grad Value) (batch int, retVal []float64, err error) {
+	t, ok := grad.(tensor.Tensor)
+	if !ok || t.Dims() != weights.Shape().Dims()+1 {
+		return 0, nil, errors.Errorf("Expected a tensor of shape (B, %v...). Got %v instead", weights.Shape(), grad.Shape())
+	}
+	if !t.Shape()[1:].Eq(weights.Shape()) && !(weights.Shape().IsScalar() && t.Dims() == 1) {
+		return 0, nil, errors.Errorf("Expected a tensor of shape (B, %v...). Got %v instead", weights.Shape(), t.Shape())
+	}
+	if t.RequiresIterator() {
+		t = tensor.Materialize(t)
+	}
+	switch data := t.Data().(type) {
+	case []float64:
+		retVal = make([]float64, len(data))
+		copy(retVal, data)
+	case []float32:
+		retVal = make([]float64, len(data))
+		for i, v := range data {
+			retVal[i] = float64(v)
+		}
+	default:
+		return 0, nil, errors.Errorf(nyiFail, "DPSGDSolver", t.Dtype())
+	}
+	return t.Shape()[0], retVal, nil
+}
+
+// valueLike returns a new value of the same type and shape as like, holding the data.
+func valueLike(like Value, data []float64) (Value, error) {
+	switch like.(type) {
+	case *F64:
+		return newF64(data[0]), nil
+	case *F32:
+		return newF32(float32(data[0])), nil
+	}
+	switch like.Dtype() {
+	case tensor.Float64:
+		return tensor.New(tensor.WithShape(like.Shape().Clone()...), tensor.WithBacking(data)), nil
+	case tensor.Float32:
+		f32s := make([]float32, len(data))
+		for i, v := range data {
+			f32s[i] = float32(v)
+		}
+		return tensor.New(tensor.WithShape(like.Shape().Clone()...), tensor.WithBacking(f32s)), nil
+	}
+	return nil, errors.Errorf(nyiFail, "valueLike", like.Dtype())
+}
+
+// PerSampleValueGrads pairs the parameters with their per-sample gradients, as returned by PerSampleGrads, for use by
+// a DPSGDSolver. The gradients are read from the values of the nodes, so they must be read after the machine has run.
+func PerSampleValueGrads(params, perSample Nodes) ([]ValueGrad, error) {
+	if len(params) != len(perSample) {
+		return nil, errors.Errorf("Expected as many per-sample gradients as parameters. Got %d and %d", len(perSample), len(params))
+	}
+	retVal := make([]ValueGrad, len(params))
+	for i, p := range params {
+		retVal[i] = perSampleValueGrad{p, perSample[i]}
+	}
+	return retVal, nil
+}
+
+type perSampleValueGrad struct {
+	param, grad *Node
+}
+
+func (n perSampleValueGrad) Value() Value { return n.param.Value() }
+func (n perSampleValueGrad) Name() string { return n.param.Name() }
+
+func (n perSampleValueGrad) Grad() (Value, error) {
+	v := n.grad.Value()
+	if v == nil {
+		return nil, errors.Errorf("%v has no value", n.grad)
+	}
+	return v, nil
+}
+
+// PrivacyAccountant tracks the privacy spent by repeated applications of the sampled Gaussian mechanism, using Rényi
+// differential privacy (Mironov et al., 2019 - https://arxiv.org/abs/1908.10530). The RDP of a step is computed exactly
+// for integer orders.
+type PrivacyAccountant struct {
+	samplingRate    float64
+	noiseMultiplier float64
+	steps           int
+}
+
+// privacyOrders are the Rényi orders over which the accountant optimizes the ε.
+var privacyOrders = func() (retVal []int) {
+	for a := 2; a <= 64; a++ {
+		retVal = append(retVal, a)
+	}
+	return append(retVal, 80, 96, 128, 192, 256)
+}()
+
+// NewPrivacyAccountant creates an accountant for steps with the given sampling rate and noise multiplier.
+func NewPrivacyAccountant(samplingRate, noiseMultiplier float64) *PrivacyAccountant {
+	return &PrivacyAccountant{samplingRate: samplingRate, noiseMultiplier: noiseMultiplier}
+}
+
+// Step records one step.
+func (a *PrivacyAccountant) Step() { a.steps++ }
+
+// Steps returns the number of steps recorded.
+func (a *PrivacyAccountant) Steps() int { return a.steps }
+
+// Epsilon returns the ε of the (ε, δ)-differential privacy guaranteed by the steps recorded. It is 0 if no step was
+// recorded.
+func (a *PrivacyAccountant) Epsilon(delta float64) float64 {
+	if a.steps == 0 {
+		return 0
+	}
+	eps := math.Inf(1)
+	for _, order := range privacyOrders {
+		rdp := float64(a.steps) * a.rdp(order)
+		eps = math.Min(eps, rdp+math.Log(1/delta)/float64(order-1))
+	}
+	return eps
+}
+
+// rdp returns the Rényi differential privacy of order α of one step:
+//		log(Σ_k C(α, k) (1-q)^(α-k) q^k exp((k²-k) / 2σ²)) / (α-1)
+// computed in log space.
+func (a *PrivacyAccountant) rdp(alpha int) float64 {
+	q, sigma := a.samplingRate, a.noiseMultiplier
+	if q == 1 {
+		return float64(alpha) / (2 * sigma * sigma)
+	}
+	logA := math.Inf(-1)
+	for k := 0; k <= alpha; k++ {
+		term := logBinomial(alpha, k) + float64(alpha-k)*math.Log1p(-q) + float64(k)*math.Log(q) + float64(k*k-k)/(2*sigma*sigma)
+		logA = logAddExp(logA, term)
+	}
+	return logA / float64(alpha-1)
+}
+
+func logBinomial(n, k int) float64 {
+	a, _ := math.Lgamma(float64(n + 1))
+	b, _ := math.Lgamma(float64(k + 1))
+	c, _ := math.Lgamma(float64(n - k + 1))
+	return a - b - c
+}
+
+func logAddExp(a, b float64) float64 {
+	if math.IsInf(a, -1) {
+		return b
+	}
+	if a < b {
+		a, b = b, a
+	}
+	return a + math.Log1p(math.Exp(b-a))
+}
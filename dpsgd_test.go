@@ -0,0 +1,106 @@
+package gorgonia
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorgonia.org/tensor"
+)
+
+type stubValueGrad struct {
+	v, g Value
+}
+
+func (n stubValueGrad) Value() Value         { return n.v }
+func (n stubValueGrad) Grad() (Value, error) { return n.g, nil }
+
+func TestDPSGDSolver(t *testing.T) {
+	assert := assert.New(t)
+	_, err := NewDPSGDSolver(NewVanillaSolver(), 0, 1, 0.1)
+	assert.Error(err)
+	_, err = NewDPSGDSolver(NewVanillaSolver(), 1, 1, 2)
+	assert.Error(err)
+
+	s, err := NewDPSGDSolver(NewVanillaSolver(WithLearnRate(1)), 1, 1e-9, 0.1, WithDPRand(rand.New(rand.NewSource(1))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := tensor.New(tensor.WithBacking([]float64{1, 1}))
+	b := newF64(1)
+	model := []ValueGrad{
+		// the first sample has a norm of 5 over both parameters, so it is clipped to 1. The second one is not clipped.
+		stubValueGrad{w, tensor.New(tensor.WithShape(2, 2), tensor.WithBacking([]float64{3, 0, 0.3, 0}))},
+		stubValueGrad{b, tensor.New(tensor.WithShape(2), tensor.WithBacking([]float64{4, 0.4}))},
+	}
+	if err = s.Step(model); err != nil {
+		t.Fatal(err)
+	}
+	assert.InDeltaSlice([]float64{1 - 0.45, 1}, w.Data(), 1e-6)
+	assert.InDelta(1-0.6, b.Data(), 1e-6)
+	assert.Equal(1, s.Accountant().Steps())
+	assert.True(s.Epsilon(1e-5) > 0)
+
+	// mismatched shapes
+	bad := []ValueGrad{stubValueGrad{w, tensor.New(tensor.WithShape(2, 3), tensor.WithBacking(make([]float64, 6)))}}
+	assert.Error(s.Step(bad))
+	bad = []ValueGrad{model[0], stubValueGrad{b, tensor.New(tensor.WithShape(3), tensor.WithBacking(make([]float64, 3)))}}
+	assert.Error(s.Step(bad))
+}
+
+func TestDPSGDSolver_PerSampleGrads(t *testing.T) {
+	assert := assert.New(t)
+	g := NewGraph()
+	x := NewMatrix(g, Float64, WithShape(4, 3), WithName("x"), WithInit(GlorotU(1)))
+	w := NewMatrix(g, Float64, WithShape(3, 2), WithName("w"), WithInit(GlorotU(1)))
+	losses := Must(Sum(Must(Square(Must(Mul(x, w)))), 1))
+	grads, err := PerSampleGrads(losses, w)
+	if err != nil {
+		t.Fatal(err)
+	}
+	model, err := PerSampleValueGrads(Nodes{w}, grads)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = PerSampleValueGrads(Nodes{w, x}, grads)
+	assert.Error(err)
+
+	m := NewTapeMachine(g)
+	defer m.Close()
+	if err = m.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+	before := w.Value().(tensor.Tensor).Clone().(tensor.Tensor)
+	s, err := NewDPSGDSolver(NewVanillaSolver(WithLearnRate(0.1)), 1, 1, 0.01)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = s.Step(model); err != nil {
+		t.Fatal(err)
+	}
+	assert.NotEqual(before.Data(), w.Value().Data())
+}
+
+func TestPrivacyAccountant(t *testing.T) {
+	assert := assert.New(t)
+	a := NewPrivacyAccountant(256.0/60000, 1.1)
+	assert.Equal(0.0, a.Epsilon(1e-5))
+	steps := 60 * 60000 / 256
+	prev := 0.0
+	for i := 0; i < steps; i++ {
+		a.Step()
+		if i%1000 == 0 {
+			eps := a.Epsilon(1e-5)
+			assert.True(eps > prev, "ε grows with the steps")
+			prev = eps
+		}
+	}
+	// the reference value for 60 epochs of MNIST with batches of 256 is ε ≈ 3
+	assert.InDelta(3.0, a.Epsilon(1e-5), 0.3)
+
+	// without subsampling, this is the Gaussian mechanism
+	full := NewPrivacyAccountant(1, 2)
+	assert.InDelta(3.0/8, full.rdp(3), 1e-12)
+	assert.False(math.IsNaN(full.rdp(256)))
+}
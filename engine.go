@@ -1,15 +1,66 @@
 package gorgonia
 
 import (
+	"fmt"
+
 	"github.com/pkg/errors"
 	"gorgonia.org/tensor"
 )
 
+// NaNMode controls how NaNs are treated by comparisons and by reductions such as Max.
+type NaNMode byte
+
+const (
+	// NaNDefault follows IEEE 754: any comparison involving a NaN is false. This is the default.
+	NaNDefault NaNMode = iota
+	// NaNPropagate propagates NaNs: comparisons that return the same type as their inputs return NaN where either operand is
+	// NaN, and Max returns NaN if any of the reduced values is NaN.
+	NaNPropagate
+	// NaNIgnore ignores NaNs: Max is computed over the values that are not NaN. Comparisons are the same as NaNDefault.
+	NaNIgnore
+	// NaNError returns an error whenever a comparison, Max or MaxPool encounters a NaN.
+	NaNError
+)
+
+func (m NaNMode) String() string {
+	switch m {
+	case NaNDefault:
+		return "NaNDefault"
+	case NaNPropagate:
+		return "NaNPropagate"
+	case NaNIgnore:
+		return "NaNIgnore"
+	case NaNError:
+		return "NaNError"
+	}
+	return fmt.Sprintf("NaNMode(%d)", byte(m))
+}
+
+// NaNModer is any engine that specifies how NaNs are to be treated.
+type NaNModer interface {
+	NaNMode() NaNMode
+}
+
 // StandardEngine is the default CPU engine for gorgonia
 type StandardEngine struct {
 	tensor.StdEng
+
+	nanMode       NaNMode
+	overflowCheck bool
+	summation     Summation
+	workers       int
+}
+
+// WithNaNMode returns a copy of the engine that treats NaNs according to the given mode. Use it with the WithEngine VMOpt:
+//		m := NewTapeMachine(g, WithEngine(StandardEngine{}.WithNaNMode(NaNError)))
+func (e StandardEngine) WithNaNMode(mode NaNMode) StandardEngine {
+	e.nanMode = mode
+	return e
 }
 
+// NaNMode returns the NaNMode of the engine.
+func (e StandardEngine) NaNMode() NaNMode { return e.nanMode }
+
 // Transpose tensor a according to expStrides
 func (e StandardEngine) Transpose(a tensor.Tensor, expStrides []int) error {
 	if !a.IsNativelyAccessible() {
@@ -40,3 +91,17 @@ func (e StandardEngine) Transpose(a tensor.Tensor, expStrides []int) error {
 	}
 	return nil
 }
+
+// nanModeOf returns the NaNMode of the engines of the given values. The first mode that is not NaNDefault is returned.
+func nanModeOf(vals ...Value) NaNMode {
+	for _, v := range vals {
+		t, ok := v.(tensor.Tensor)
+		if !ok {
+			continue
+		}
+		if e, ok := t.Engine().(NaNModer); ok && e.NaNMode() != NaNDefault {
+			return e.NaNMode()
+		}
+	}
+	return NaNDefault
+}
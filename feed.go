@@ -0,0 +1,102 @@
+package gorgonia
+
+import (
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// Run binds the values of the feeds to their input nodes, runs the machine once, and returns the values of the outputs.
+// If no outputs are given, the values of the roots of the graph are returned. The feeds are all checked before any is
+// bound, so a run with a feed of the wrong dtype or shape leaves the graph untouched:
+//
+//	outs, err := gorgonia.Run(m, map[*gorgonia.Node]gorgonia.Value{x: xVal, y: yVal}, cost)
+//	...
+//	fmt.Println(outs[cost])
+//
+// Every input of the graph must be fed, or already hold a value. The machine is reset after the run, so Run may be
+// called again with the next feeds. The values returned are those of the nodes, which the next run overwrites: clone
+// them, or Fetch them from the machine, to keep them.
+func Run(m VM, feeds map[*Node]Value, outputs ...*Node) (map[*Node]Value, error) {
+	var g *ExprGraph
+	var let func(n *Node, v Value) error
+	rewind := m.Reset
+	switch vm := m.(type) {
+	case *tapeMachine:
+		g = vm.p.g
+		let = func(n *Node, v Value) error { return vm.Let(n, v) }
+	case *lispMachine:
+		g = vm.g
+		let = func(n *Node, v Value) error { return Let(n, v) }
+		// the reset of a lisp machine only rewinds it to its last node
+		rewind = func() { vm.fwd, vm.bwd = 0, -1 }
+	default:
+		return nil, nyi("Run", m)
+	}
+
+	fed := make(Nodes, 0, len(feeds))
+	for n := range feeds {
+		fed = append(fed, n)
+	}
+	sort.Slice(fed, func(i, j int) bool { return fed[i].id < fed[j].id })
+	for _, n := range fed {
+		if err := checkFeed(g, n, feeds[n]); err != nil {
+			return nil, err
+		}
+	}
+	for _, n := range g.Inputs() {
+		if _, ok := feeds[n]; !ok && n.boundTo == nil {
+			return nil, errors.Errorf("Input %v is neither fed nor holds a value", n)
+		}
+	}
+	for _, n := range fed {
+		if err := let(n, feeds[n]); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(outputs) == 0 {
+		for _, n := range g.Roots() {
+			if !n.isStmt {
+				outputs = append(outputs, n)
+			}
+		}
+	}
+	for _, n := range outputs {
+		if n.g != g {
+			return nil, errors.Errorf("Output %v is not of the graph of the machine", n)
+		}
+	}
+
+	rewind()
+	defer m.Reset()
+	if err := m.RunAll(); err != nil {
+		return nil, err
+	}
+	retVal := make(map[*Node]Value, len(outputs))
+	for _, n := range outputs {
+		v := n.Value()
+		if v == nil {
+			return nil, errors.Errorf("Output %v holds no value after the run", n)
+		}
+		retVal[n] = v
+	}
+	return retVal, nil
+}
+
+// checkFeed checks that the value may be fed to the node.
+func checkFeed(g *ExprGraph, n *Node, v Value) error {
+	switch {
+	case n.g != g:
+		return errors.Errorf("Cannot feed %v: it is not of the graph of the machine", n)
+	case !n.isInput():
+		return errors.Errorf("Cannot feed %v: it is not an input", n)
+	case v == nil:
+		return errors.Errorf("Cannot feed %v with a nil value", n)
+	case !n.Dtype().Eq(v.Dtype()):
+		return errors.Errorf("Cannot feed %v of %v with a value of %v", n, n.Dtype(), v.Dtype())
+	case !n.Shape().Eq(v.Shape()):
+		return errors.Errorf("Cannot feed %v of shape %v with a value of shape %v", n, n.Shape(), v.Shape())
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package gorgonia
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorgonia.org/tensor"
+)
+
+func TestRun(t *testing.T) {
+	assert := assert.New(t)
+	g := NewGraph()
+	x := NewMatrix(g, Float64, WithShape(2, 2), WithName("x"))
+	w := NewMatrix(g, Float64, WithShape(2, 2), WithName("w"), WithInit(Ones()))
+	b := NewScalar(g, Float64, WithName("b"))
+	xw := Must(Mul(x, w))
+	y := Must(Sum(Must(Add(xw, b))))
+
+	machines := map[string]VM{
+		"tape": NewTapeMachine(g),
+		"lisp": NewLispMachine(g, ExecuteFwdOnly()),
+	}
+	for name, m := range machines {
+		xVal := tensor.New(tensor.WithShape(2, 2), tensor.WithBacking([]float64{1, 2, 3, 4}))
+		outs, err := Run(m, map[*Node]Value{x: xVal, b: newF64(1)}, y)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		assert.Equal(24.0, outs[y].Data(), name)
+		assert.Equal(1, len(outs), name)
+
+		// the next feeds, on the same machine; the roots are returned by default
+		xVal = tensor.New(tensor.WithShape(2, 2), tensor.WithBacking([]float64{0, 0, 0, 1}))
+		if outs, err = Run(m, map[*Node]Value{x: xVal, b: newF64(0)}); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		assert.Equal(2.0, outs[y].Data(), name)
+
+		// nothing is bound unless all the feeds are right
+		_, err = Run(m, map[*Node]Value{b: newF64(5), x: tensor.New(tensor.WithShape(4), tensor.WithBacking([]float64{1, 2, 3, 4}))})
+		assert.Error(err, name)
+		assert.Equal(0.0, b.Value().Data(), name)
+		_, err = Run(m, map[*Node]Value{x: xVal, b: newF32(1)})
+		assert.Error(err, name)
+		_, err = Run(m, map[*Node]Value{xw: xVal})
+		assert.Error(err, name)
+		m.Close()
+	}
+
+	// the inputs must all be fed, or hold values
+	g2 := NewGraph()
+	a := NewScalar(g2, Float64, WithName("a"))
+	Must(Add(a, NewScalar(g2, Float64, WithName("c"))))
+	m := NewTapeMachine(g2)
+	defer m.Close()
+	_, err := Run(m, map[*Node]Value{a: newF64(1)})
+	assert.Error(err)
+	_, err = Run(m, map[*Node]Value{x: tensor.New(tensor.WithShape(2, 2), tensor.WithBacking([]float64{1, 2, 3, 4}))})
+	assert.Error(err)
+}
@@ -0,0 +1,71 @@
+package gorgonia
+
+import (
+	"github.com/pkg/errors"
+	"gorgonia.org/tensor"
+)
+
+// Fetch returns copies of the values of the nodes, on the host, once the work of the devices is done. The tensors are
+// the caller's: unlike the values of the nodes, they are not overwritten by the next runs of the machine. The scalars
+// are returned as tensors of scalar shape.
+func (m *tapeMachine) Fetch(nodes ...*Node) ([]tensor.Tensor, error) {
+	for _, n := range nodes {
+		if n.g != m.p.g {
+			return nil, errors.Errorf("Cannot fetch %v: it is not of the graph of the machine", n)
+		}
+	}
+	return fetch(&m.ExternMetadata, nodes)
+}
+
+// Fetch returns copies of the values of the nodes, on the host, once the work of the devices is done. The tensors are
+// the caller's: unlike the values of the nodes, they are not overwritten by the next runs of the machine. The scalars
+// are returned as tensors of scalar shape.
+func (m *lispMachine) Fetch(nodes ...*Node) ([]tensor.Tensor, error) {
+	for _, n := range nodes {
+		if n.g != m.g {
+			return nil, errors.Errorf("Cannot fetch %v: it is not of the graph of the machine", n)
+		}
+	}
+	return fetch(&m.ExternMetadata, nodes)
+}
+
+func fetch(extern *ExternMetadata, nodes Nodes) ([]tensor.Tensor, error) {
+	if err := extern.DoWork(); err != nil {
+		return nil, errors.Wrap(err, "Failed to synchronize the devices before fetching")
+	}
+	retVal := make([]tensor.Tensor, len(nodes))
+	for i, n := range nodes {
+		v := n.Value()
+		if v == nil {
+			return nil, errors.Errorf("Cannot fetch %v: it holds no value", n)
+		}
+
+		var err error
+		if dev := n.Device(); dev != CPU {
+			// the transfer to the host allocates the value on the host
+			var host Value
+			if host, err = extern.Transfer(CPU, dev, v, true); err != nil {
+				return nil, errors.Wrapf(err, "Failed to copy the value of %v to the host", n)
+			}
+			if host == v {
+				host, err = CloneValue(v)
+			}
+			v = host
+		} else {
+			v, err = CloneValue(v)
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, cloneFail)
+		}
+
+		switch v := v.(type) {
+		case tensor.Tensor:
+			retVal[i] = v
+		case Scalar:
+			retVal[i] = tensor.New(tensor.FromScalar(v.Data()))
+		default:
+			return nil, errors.Errorf(nyiTypeFail, "Fetch", v)
+		}
+	}
+	return retVal, nil
+}
@@ -0,0 +1,47 @@
+package gorgonia
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorgonia.org/tensor"
+)
+
+func TestFetch(t *testing.T) {
+	assert := assert.New(t)
+	g := NewGraph()
+	x := NewVector(g, Float64, WithShape(3), WithName("x"))
+	y := Must(Mul(x, NewConstant(2.0)))
+	sum := Must(Sum(y))
+
+	m := NewTapeMachine(g)
+	defer m.Close()
+	if _, err := Run(m, map[*Node]Value{x: tensor.New(tensor.WithBacking([]float64{1, 2, 3}))}); err != nil {
+		t.Fatal(err)
+	}
+	fetched, err := m.Fetch(y, sum)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]float64{2, 4, 6}, fetched[0].Data())
+	assert.True(fetched[1].Shape().IsScalar())
+	assert.Equal(12.0, fetched[1].Data())
+
+	// the next run does not write into the fetched tensors
+	if _, err = Run(m, map[*Node]Value{x: tensor.New(tensor.WithBacking([]float64{0, 0, 1}))}); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]float64{0, 0, 2}, y.Value().Data())
+	assert.Equal([]float64{2, 4, 6}, fetched[0].Data())
+
+	_, err = m.Fetch(NewScalar(NewGraph(), Float64))
+	assert.Error(err)
+
+	lm := NewLispMachine(g, ExecuteFwdOnly())
+	defer lm.Close()
+	if fetched, err = lm.Fetch(x); err != nil {
+		t.Fatal(err)
+	}
+	fetched[0].(*tensor.Dense).Set(0, 100.0)
+	assert.Equal([]float64{0, 0, 1}, x.Value().Data())
+}
@@ -0,0 +1,91 @@
+package gorgonia
+
+import (
+	"github.com/pkg/errors"
+	"gorgonia.org/tensor"
+)
+
+/*
+This file holds the support for the fluent API on *Node, found in api_fluent_gen.go. The fluent methods allow for
+expressions to be chained:
+		y := x.Mul(w).Add(b).Relu()
+
+Instead of returning an error, a failed fluent method returns a node holding the error. Subsequent fluent method calls
+are skipped, so the first error is carried through to the end of the chain, where it can be checked with Err(),
+or turned into a panic with Must().
+*/
+
+// Must returns n if it does not hold an error. It panics otherwise. It is the fluent equivalent of the Must function:
+//		y := x.Mul(w).Add(b).Must()
+func (n *Node) Must() *Node {
+	if err := n.Err(); err != nil {
+		panic(err)
+	}
+	return n
+}
+
+// errNode returns a node that holds the error. It does not belong to any graph.
+func errNode(err error) *Node { return &Node{err: err, id: -1} }
+
+// checkFluent returns the first error held by the nodes. nil nodes are an error.
+func checkFluent(ns ...*Node) error {
+	for _, n := range ns {
+		if n == nil {
+			return errors.New("Cannot apply a fluent method on a nil *Node")
+		}
+		if err := n.Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fluentResult turns the results of a package level function into the result of a fluent method.
+func fluentResult(retVal *Node, err error) *Node {
+	if err != nil {
+		return errNode(err)
+	}
+	return retVal
+}
+
+func fluent1(fn func(a *Node) (*Node, error), a *Node) *Node {
+	if err := checkFluent(a); err != nil {
+		return errNode(err)
+	}
+	return fluentResult(fn(a))
+}
+
+func fluent2(fn func(a, b *Node) (*Node, error), a, b *Node) *Node {
+	if err := checkFluent(a, b); err != nil {
+		return errNode(err)
+	}
+	return fluentResult(fn(a, b))
+}
+
+func fluentCmp(fn func(a, b *Node, retSame bool) (*Node, error), a, b *Node, retSame bool) *Node {
+	if err := checkFluent(a, b); err != nil {
+		return errNode(err)
+	}
+	return fluentResult(fn(a, b, retSame))
+}
+
+func fluentAxial(fn func(a *Node, axes ...int) (*Node, error), a *Node, axes []int) *Node {
+	if err := checkFluent(a); err != nil {
+		return errNode(err)
+	}
+	return fluentResult(fn(a, axes...))
+}
+
+func fluentShape(fn func(a *Node, s tensor.Shape) (*Node, error), a *Node, s tensor.Shape) *Node {
+	if err := checkFluent(a); err != nil {
+		return errNode(err)
+	}
+	return fluentResult(fn(a, s))
+}
+
+func fluentDtype(fn func(a *Node, dt tensor.Dtype) (*Node, error), a *Node, dt tensor.Dtype) *Node {
+	if err := checkFluent(a); err != nil {
+		return errNode(err)
+	}
+	return fluentResult(fn(a, dt))
+}
@@ -0,0 +1,57 @@
+package gorgonia
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorgonia.org/tensor"
+)
+
+func TestFluent(t *testing.T) {
+	assert := assert.New(t)
+	g := NewGraph()
+	x := NewMatrix(g, Float64, WithShape(2, 3), WithName("x"), WithValue(tensor.New(tensor.WithShape(2, 3), tensor.WithBacking([]float64{1, -2, 3, -4, 5, -6}))))
+	w := NewMatrix(g, Float64, WithShape(3, 2), WithName("w"), WithValue(tensor.New(tensor.WithShape(3, 2), tensor.WithBacking([]float64{1, 0, 0, 1, 1, 1}))))
+	b := NewMatrix(g, Float64, WithShape(2, 2), WithName("b"), WithValue(tensor.New(tensor.WithShape(2, 2), tensor.WithBacking([]float64{1, 1, 1, 1}))))
+
+	y := x.MatMul(w).Add(b).Relu()
+	if err := y.Err(); err != nil {
+		t.Fatal(err)
+	}
+	s := y.Sum().Must()
+
+	m := NewTapeMachine(g)
+	defer m.Close()
+	if err := m.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+	// x×w = [[4, 1], [-10, -1]]
+	assert.Equal([]float64{5, 2, 0, 0}, y.Value().Data())
+	assert.Equal(7.0, s.Value().Data())
+}
+
+func TestFluent_Errors(t *testing.T) {
+	assert := assert.New(t)
+	g := NewGraph()
+	x := NewMatrix(g, Float64, WithShape(2, 3), WithName("x"))
+	v := NewVector(g, Float64, WithShape(2), WithName("v"))
+
+	// shape mismatch in the first op. The error is carried through the chain
+	bad := x.Add(v)
+	assert.Error(bad.Err())
+	chained := bad.Sigmoid().Mul(x).Sum(0)
+	assert.Equal(bad.Err(), chained.Err())
+	assert.Panics(func() { chained.Must() })
+
+	// errored nodes passed as arguments
+	assert.Equal(bad.Err(), x.Sub(bad).Err())
+	_, err := Add(x, bad)
+	assert.Equal(bad.Err(), err)
+
+	var nilNode *Node
+	assert.Error(nilNode.Exp().Err())
+	assert.Nil(nilNode.Err())
+
+	assert.NotPanics(func() { x.Neg().Must() })
+	assert.Nil(x.Err())
+}
@@ -3,7 +3,10 @@ package gorgonia
 import (
 	"bytes"
 	"fmt"
+	"io"
 	"reflect"
+
+	"gorgonia.org/tensor"
 )
 
 type mapFmt struct {
@@ -187,3 +190,90 @@ func (mf mapFmt) Format(s fmt.State, c rune) {
 	}
 	mf.defaultFmt(s, c)
 }
+
+// previewSize is the number of elements of a value shown by the %+v format and Summary of a *Node.
+const previewSize = 6
+
+// Format implements fmt.Formatter, so that nodes print as diagnostics rather than pointers:
+//		%v, %s	the name and type of the node, as String() does
+//		%+v	as Summary(), which adds the shape and device, a preview of the value and the status of the gradient
+//		%d, %x	the ID of the node
+func (n *Node) Format(s fmt.State, c rune) {
+	switch c {
+	case 'd', 'x', 'X':
+		fmt.Fprintf(s, fmt.Sprintf("%%%c", c), n.id)
+	case 'v':
+		if s.Flag('+') {
+			io.WriteString(s, n.Summary())
+			return
+		}
+		io.WriteString(s, n.String())
+	case 'q':
+		fmt.Fprintf(s, "%q", n.String())
+	default:
+		io.WriteString(s, n.String())
+	}
+}
+
+// Summary returns a one line description of the node for logging: its name, type, shape and device, its ID and op,
+// the IDs of its children, a preview of its value and the status of its gradient.
+func (n *Node) Summary() string {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%v %v", n.String(), n.Shape())
+	if n.dataOn != CPU {
+		fmt.Fprintf(&buf, " on %v", n.dataOn)
+	}
+	fmt.Fprintf(&buf, " | id: %d", n.id)
+	if n.op != nil {
+		fmt.Fprintf(&buf, " | op: %v", n.op)
+	}
+	if len(n.children) > 0 {
+		fmt.Fprintf(&buf, " | children: %d", Nodes(n.children))
+	}
+
+	buf.WriteString(" | value: ")
+	if v := n.Value(); v != nil {
+		buf.WriteString(previewValue(v))
+	} else {
+		buf.WriteString("unbound")
+	}
+
+	buf.WriteString(" | grad: ")
+	dv, ok := n.boundTo.(*dualValue)
+	switch {
+	case ok && dv.d != nil:
+		buf.WriteString(previewValue(dv.d))
+	case n.deriv != nil:
+		fmt.Fprintf(&buf, "symbolic (%d)", n.deriv.id)
+	default:
+		buf.WriteString("none")
+	}
+	return buf.String()
+}
+
+// previewValue formats the first elements of a value on a single line.
+func previewValue(v Value) string {
+	switch vt := v.(type) {
+	case Scalar:
+		return fmt.Sprintf("%v", vt)
+	case tensor.Tensor:
+		data := reflect.ValueOf(vt.Data())
+		if data.Kind() != reflect.Slice {
+			return fmt.Sprintf("[%v]", data)
+		}
+		var buf bytes.Buffer
+		buf.WriteByte('[')
+		for i := 0; i < data.Len() && i < previewSize; i++ {
+			if i > 0 {
+				buf.WriteByte(' ')
+			}
+			fmt.Fprintf(&buf, "%v", data.Index(i))
+		}
+		if data.Len() > previewSize {
+			fmt.Fprintf(&buf, " … %d more", data.Len()-previewSize)
+		}
+		buf.WriteByte(']')
+		return buf.String()
+	}
+	return fmt.Sprintf("%v", v)
+}
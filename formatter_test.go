@@ -3,6 +3,8 @@ package gorgonia
 import (
 	"fmt"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
 )
 
 func TestMapFormat(t *testing.T) {
@@ -57,3 +59,41 @@ func TestMapFormat(t *testing.T) {
 	}
 	*/
 }
+
+func TestNodeFormat(t *testing.T) {
+	assert := assert.New(t)
+	g := NewGraph()
+	x := NewMatrix(g, Float64, WithName("x"), WithShape(2, 4), WithInit(RangedFrom(0)))
+	y := NewScalar(g, Float64, WithName("y"))
+	z := Must(Mul(x, y))
+	cost := Must(Sum(z))
+	if _, err := Grad(cost, x); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(x.String(), fmt.Sprintf("%v", x))
+	assert.Equal(x.String(), fmt.Sprintf("%s", x))
+	assert.Equal(fmt.Sprintf("%d", x.ID()), fmt.Sprintf("%d", x))
+	assert.Equal(fmt.Sprintf("%x", z.ID()), fmt.Sprintf("%x", z))
+	assert.Equal(fmt.Sprintf("%+v", x), x.Summary())
+
+	s := y.Summary()
+	assert.Contains(s, "y :: float64 ()")
+	assert.Contains(s, "value: unbound")
+	assert.Contains(s, "grad: none")
+
+	s = x.Summary()
+	assert.Contains(s, "x :: Matrix float64 (2, 4)")
+	assert.Contains(s, "value: [0 1 2 3 4 5 … 2 more]")
+	assert.Contains(s, "grad: symbolic")
+	assert.Contains(z.Summary(), fmt.Sprintf("children: [%x, %x]", x.ID(), y.ID()))
+
+	Let(y, 2.0)
+	m := NewTapeMachine(g, BindDualValues(x))
+	defer m.Close()
+	if err := m.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+	assert.Contains(y.Summary(), "value: 2")
+	assert.Contains(x.Summary(), "grad: [2 2 2 2 2 2 … 2 more]")
+}
@@ -0,0 +1,99 @@
+package gorgonia
+
+/*
+This file holds the freezing of the parameters, for fine-tuning. A frozen node stops the gradients: symbolic
+differentiation does not differentiate with regards to it, so none of the nodes that it depends on are differentiated
+through it either. Freezing the output of a pretrained encoder thus freezes the whole encoder:
+
+		Freeze(encoded)
+		if _, err = Grad(cost, g.Learnables()...); err != nil {
+			...
+		}
+
+A node that is also used outside of the frozen subtrees, such as an embedding shared by a frozen encoder and a decoder,
+is still differentiated through the other uses. The nodes must be frozen before Grad is called.
+*/
+
+// Freeze freezes the nodes, and with them the subtrees that only they use.
+func Freeze(nodes ...*Node) {
+	for _, n := range nodes {
+		n.frozen = true
+	}
+}
+
+// Unfreeze unfreezes nodes frozen by Freeze. The gradients of the graph must be recomputed for it to take effect.
+func Unfreeze(nodes ...*Node) {
+	for _, n := range nodes {
+		n.frozen = false
+	}
+}
+
+// IsFrozen returns true if the node was frozen by Freeze. A node may also be frozen because every node that uses it
+// is: see (*ExprGraph).Frozen.
+func (n *Node) IsFrozen() bool { return n.frozen }
+
+// Frozen returns the nodes whose gradients are stopped: the frozen nodes, and the nodes that are only used by frozen
+// nodes.
+func (g *ExprGraph) Frozen() Nodes {
+	set := g.frozenSet()
+	retVal := make(Nodes, 0, len(set))
+	for _, n := range g.AllNodes() {
+		if set.Contains(n) {
+			retVal = append(retVal, n)
+		}
+	}
+	return retVal
+}
+
+// Learnables returns the input nodes of the graph that may be differentiated with regards to, in the order they were
+// added to the graph: the variables of float dtypes that are not frozen.
+func (g *ExprGraph) Learnables() Nodes {
+	frozen := g.frozenSet()
+	var retVal Nodes
+	for _, n := range g.AllNodes() {
+		if !n.isInput() || n.isConstant() || frozen.Contains(n) {
+			continue
+		}
+		if dt := n.Dtype(); dt != Float64 && dt != Float32 {
+			continue
+		}
+		retVal = append(retVal, n)
+	}
+	return retVal
+}
+
+// frozenSet walks the graph from the roots, so that the users of a node are visited before it.
+func (g *ExprGraph) frozenSet() NodeSet {
+	retVal := NewNodeSet()
+	sorted, err := Sort(g)
+	if err != nil {
+		// a cyclic graph cannot be differentiated anyway
+		for _, n := range g.AllNodes() {
+			if n.frozen {
+				retVal.Add(n)
+			}
+		}
+		return retVal
+	}
+	for _, n := range sorted {
+		if n.frozen {
+			retVal.Add(n)
+			continue
+		}
+		parents := g.to.get(n)
+		if len(parents) == 0 {
+			continue
+		}
+		all := true
+		for _, p := range parents {
+			if !retVal.Contains(p) {
+				all = false
+				break
+			}
+		}
+		if all {
+			retVal.Add(n)
+		}
+	}
+	return retVal
+}
@@ -0,0 +1,98 @@
+package gorgonia
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorgonia.org/tensor"
+)
+
+func TestFreeze(t *testing.T) {
+	assert := assert.New(t)
+	build := func(freeze bool) (g *ExprGraph, w1, w2, cost *Node) {
+		g = NewGraph()
+		x := NewMatrix(g, Float64, WithShape(2, 3), WithName("x"), WithInit(RangedFrom(0)))
+		w1 = NewMatrix(g, Float64, WithShape(3, 4), WithName("w1"), WithInit(RangedFrom(0)))
+		w2 = NewMatrix(g, Float64, WithShape(4, 1), WithName("w2"), WithInit(RangedFrom(0)))
+		NewVector(g, Int, WithShape(2), WithName("ids"), WithValue(tensor.New(tensor.WithBacking([]int{0, 1}))))
+		hidden := Must(Tanh(Must(Mul(x, w1))))
+		if freeze {
+			Freeze(hidden)
+		}
+		cost = Must(Sum(Must(Mul(hidden, w2))))
+		return
+	}
+
+	g, _, w2, _ := build(false)
+	assert.Equal([]string{"x", "w1", "w2"}, nodeNames(g.Learnables()))
+	assert.Empty(g.Frozen())
+	unfrozen := len(g.AllNodes())
+
+	g, w1, w2, cost := build(true)
+	assert.Equal(Nodes{w2}, g.Learnables())
+	// the hidden layer, its product and its inputs
+	assert.Len(g.Frozen(), 4)
+	assert.True(g.Frozen().Contains(w1))
+	assert.False(g.Frozen().Contains(w2))
+	before := len(g.AllNodes())
+	if _, err := Grad(cost, g.Learnables()...); err != nil {
+		t.Fatal(err)
+	}
+	// the frozen layer is not differentiated
+	assert.Nil(w1.Deriv())
+	assert.NotNil(w2.Deriv())
+	frozenGrads := len(g.AllNodes()) - before
+	g2, _, _, cost2 := build(false)
+	before = len(g2.AllNodes())
+	if _, err := Grad(cost2, g2.Learnables()...); err != nil {
+		t.Fatal(err)
+	}
+	assert.True(frozenGrads < len(g2.AllNodes())-before, "%d nodes of gradients with the frozen layer, %d without", frozenGrads, len(g2.AllNodes())-before)
+	assert.Equal(unfrozen, before)
+
+	// the frozen nodes cannot be differentiated with regards to
+	_, err := Grad(cost, w1)
+	assert.Error(err)
+
+	m := NewTapeMachine(g)
+	defer m.Close()
+	if err = m.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(tensor.Shape{4, 1}, w2.Deriv().Value().Shape())
+}
+
+func TestFreezeShared(t *testing.T) {
+	assert := assert.New(t)
+	g := NewGraph()
+	// w is used by a frozen branch and by a trained one
+	w := NewVector(g, Float64, WithShape(3), WithName("w"), WithValue(tensor.New(tensor.WithBacking([]float64{1, 2, 3}))))
+	frozen := Must(Square(w))
+	trained := Must(HadamardProd(w, w))
+	Freeze(frozen)
+	cost := Must(Add(Must(Sum(frozen)), Must(Sum(trained))))
+	assert.Equal(Nodes{w}, g.Learnables())
+	if _, err := Grad(cost, w); err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewTapeMachine(g)
+	defer m.Close()
+	if err := m.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+	// only the gradient 2w of the trained branch
+	assert.Equal([]float64{2, 4, 6}, w.Deriv().Value().Data())
+
+	Unfreeze(frozen)
+	assert.False(frozen.IsFrozen())
+	assert.Empty(g.Frozen())
+}
+
+func nodeNames(ns Nodes) []string {
+	retVal := make([]string, len(ns))
+	for i, n := range ns {
+		retVal[i] = n.Name()
+	}
+	return retVal
+}
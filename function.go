@@ -0,0 +1,295 @@
+package gorgonia
+
+import (
+	"fmt"
+	"hash"
+	"sync"
+
+	"github.com/chewxy/hm"
+	"github.com/pkg/errors"
+	"gorgonia.org/tensor"
+)
+
+// Function is a subgraph that can be called like an op. Every call to a Function adds a single node to the calling
+// graph, no matter how many nodes the body of the Function has, so repeated blocks (like residual layers) do not
+// grow the graph with each use. In the graph's visualization, the call shows up as one node named after the Function.
+//
+// A Function has no parameters of its own: parameters are passed in as arguments. Passing in the same nodes to
+// different calls shares the parameters, and passing in different nodes keeps them separate:
+//		block, err := Define("residual", Nodes{x, w}, func(in Nodes) (*Node, error) {
+//			xw, err := Mul(in[0], in[1])
+//			...
+//			return Add(in[0], act)
+//		})
+//		h1, err := block.Call(x, w1)
+//		h2, err := block.Call(h1, w2)
+//
+// The body of a Function is run by its own machine. Its gradients are computed symbolically when the Function is
+// defined, so calls to a differentiable Function may be differentiated with Grad like any other node.
+type Function struct {
+	name    string
+	g       *ExprGraph
+	inputs  Nodes
+	output  *Node
+	gradOut *Node // the gradient of the output, which is the seed of the gradients
+	grads   Nodes // nil if the body is not differentiable
+
+	sync.Mutex
+	fwd      VM
+	bwd      VM
+	fwdVal   Value
+	gradVals []Value
+}
+
+// Define creates a Function. The inputs are the prototypes of the arguments: every call must have arguments of the same
+// types and shapes. fn is called once with placeholders of the inputs, in a new graph, and returns the output of the Function.
+func Define(name string, inputs Nodes, fn func(inputs Nodes) (*Node, error)) (*Function, error) {
+	if len(inputs) == 0 {
+		return nil, errors.Errorf("Function %q has no inputs", name)
+	}
+
+	f := &Function{name: name, g: NewGraph()}
+	f.inputs = make(Nodes, len(inputs))
+	for i, in := range inputs {
+		if in == nil {
+			return nil, errors.Errorf("Input %d of %q is nil", i, name)
+		}
+		f.inputs[i] = placeholderLike(f.g, in, fmt.Sprintf("%s.in%d", name, i))
+	}
+
+	out, err := fn(f.inputs)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to define %q", name)
+	}
+	if out == nil || out.g != f.g {
+		return nil, errors.Errorf("The output of %q must be a node of the Function's graph", name)
+	}
+	f.output = out
+
+	fwdRead := Read(out, &f.fwdVal)
+	if f.fwd, err = innerMachine(f.g, fwdRead); err != nil {
+		return nil, errors.Wrapf(err, "Failed to compile %q", name)
+	}
+
+	// not every body is differentiable. Such Functions can still be called, but not differentiated
+	f.gradOut = placeholderLike(f.g, out, name+".gradOut")
+	grads, err := Backpropagate(Nodes{out}, Nodes{f.gradOut}, f.inputs)
+	if err != nil {
+		symdiffLogf("Function %q is not differentiable: %v", name, err)
+		return f, nil
+	}
+	f.grads = grads
+	f.gradVals = make([]Value, len(grads))
+	reads := make(Nodes, len(grads))
+	for i, grad := range grads {
+		reads[i] = Read(grad, &f.gradVals[i])
+	}
+	if f.bwd, err = innerMachine(f.g, reads...); err != nil {
+		return nil, errors.Wrapf(err, "Failed to compile the gradients of %q", name)
+	}
+	return f, nil
+}
+
+// Name returns the name of the Function.
+func (f *Function) Name() string { return f.name }
+
+// Graph returns the graph of the body of the Function.
+func (f *Function) Graph() *ExprGraph { return f.g }
+
+// Differentiable returns true if calls to the Function can be differentiated.
+func (f *Function) Differentiable() bool { return f.grads != nil }
+
+// Call applies the Function to the arguments, adding one node to the graph of the arguments.
+func (f *Function) Call(args ...*Node) (*Node, error) {
+	return ApplyOp(callOp{f}, args...)
+}
+
+// run binds the values to the inputs of the body and runs m.
+func (f *Function) run(m VM, values []Value) error {
+	for i, v := range values {
+		if err := Let(f.inputs[i], v); err != nil {
+			return errors.Wrapf(err, "Failed to bind argument %d of %q", i, f.name)
+		}
+	}
+	defer m.Reset()
+	return m.RunAll()
+}
+
+func (f *Function) forward(values []Value) (Value, error) {
+	f.Lock()
+	defer f.Unlock()
+	if err := f.run(f.fwd, values); err != nil {
+		return nil, errors.Wrapf(err, execFail, f.name, f.output)
+	}
+	return CloneValue(f.fwdVal)
+}
+
+func (f *Function) backward(i int, values []Value, grad Value) (Value, error) {
+	f.Lock()
+	defer f.Unlock()
+	if err := Let(f.gradOut, grad); err != nil {
+		return nil, errors.Wrapf(err, "Failed to bind the gradient of %q", f.name)
+	}
+	if err := f.run(f.bwd, values); err != nil {
+		return nil, errors.Wrapf(err, execFail, f.name, f.grads[i])
+	}
+	return CloneValue(f.gradVals[i])
+}
+
+// checkShapes checks that the shapes of the arguments are the shapes of the Function's inputs.
+func (f *Function) checkShapes(shapes []DimSizer) error {
+	for i, s := range shapes {
+		shp, ok := s.(tensor.Shape)
+		if !ok {
+			return errors.Errorf("Expected a shape. Got %v of %T instead", s, s)
+		}
+		if !shp.Eq(f.inputs[i].Shape()) {
+			return errors.Errorf("Argument %d of %q has shape %v. Expected %v", i, f.name, shp, f.inputs[i].Shape())
+		}
+	}
+	return nil
+}
+
+// placeholderLike creates an input node in g with the same type and shape as n.
+func placeholderLike(g *ExprGraph, n *Node, name string) *Node {
+	if n.IsScalar() {
+		return NewScalar(g, n.Dtype(), WithName(name))
+	}
+	return NewTensor(g, n.Dtype(), n.Dims(), WithShape(n.Shape()...), WithName(name))
+}
+
+// innerMachine compiles the subgraph of g needed to compute the roots.
+func innerMachine(g *ExprGraph, roots ...*Node) (VM, error) {
+	sub := g.SubgraphRoots(roots...)
+	prog, locMap, err := Compile(sub)
+	if err != nil {
+		return nil, err
+	}
+	return NewTapeMachine(sub, WithPrecompiled(prog, locMap)), nil
+}
+
+// callOp calls a Function.
+type callOp struct {
+	f *Function
+}
+
+func (op callOp) Arity() int { return len(op.f.inputs) }
+
+// callOp has the type of the Function's body. For example, a Function of a matrix and a vector of float64 returning
+// a vector has this type:
+//		callOp :: Matrix float64 → Vector float64 → Vector float64
+func (op callOp) Type() hm.Type {
+	ts := make([]hm.Type, 0, len(op.f.inputs)+1)
+	for _, in := range op.f.inputs {
+		ts = append(ts, in.t)
+	}
+	ts = append(ts, op.f.output.t)
+	return hm.NewFnType(ts...)
+}
+
+func (op callOp) InferShape(inputs ...DimSizer) (tensor.Shape, error) {
+	if err := checkArity(op, len(inputs)); err != nil {
+		return nil, err
+	}
+	if err := op.f.checkShapes(inputs); err != nil {
+		return nil, err
+	}
+	return op.f.output.Shape(), nil
+}
+
+func (op callOp) Do(inputs ...Value) (Value, error) {
+	if err := checkArity(op, len(inputs)); err != nil {
+		return nil, err
+	}
+	return op.f.forward(inputs)
+}
+
+func (op callOp) ReturnsPtr() bool     { return false }
+func (op callOp) CallsExtern() bool    { return false }
+func (op callOp) OverwritesInput() int { return -1 }
+
+func (op callOp) WriteHash(h hash.Hash) { fmt.Fprintf(h, "call %s %p", op.f.name, op.f) }
+
+func (op callOp) Hashcode() uint32 { return simpleHash(op) }
+
+func (op callOp) String() string { return op.f.name }
+
+func (op callOp) DiffWRT(inputs int) []bool {
+	retVal := make([]bool, inputs)
+	for i := range retVal {
+		retVal[i] = op.f.Differentiable()
+	}
+	return retVal
+}
+
+func (op callOp) SymDiff(inputs Nodes, output, grad *Node) (retVal Nodes, err error) {
+	if err = checkArity(op, len(inputs)); err != nil {
+		return
+	}
+	if !op.f.Differentiable() {
+		return nil, errors.Errorf("Function %q is not differentiable", op.f.name)
+	}
+
+	children := append(append(make(Nodes, 0, len(inputs)+1), inputs...), grad)
+	retVal = make(Nodes, len(inputs))
+	for i := range inputs {
+		if retVal[i], err = ApplyOp(funcGradOp{op.f, i}, children...); err != nil {
+			return nil, errors.Wrapf(err, applyOpFail)
+		}
+	}
+	return
+}
+
+// funcGradOp computes the gradient of a Function with regards to its ith input. Its inputs are the arguments of the
+// call, followed by the gradient of the output.
+type funcGradOp struct {
+	f *Function
+	i int
+}
+
+func (op funcGradOp) Arity() int { return len(op.f.inputs) + 1 }
+
+// funcGradOp has the type of the Function's body, with the gradient of the output as an extra argument, and
+// returns the type of the ith input.
+func (op funcGradOp) Type() hm.Type {
+	ts := make([]hm.Type, 0, len(op.f.inputs)+2)
+	for _, in := range op.f.inputs {
+		ts = append(ts, in.t)
+	}
+	ts = append(ts, op.f.output.t, op.f.inputs[op.i].t)
+	return hm.NewFnType(ts...)
+}
+
+func (op funcGradOp) InferShape(inputs ...DimSizer) (tensor.Shape, error) {
+	if err := checkArity(op, len(inputs)); err != nil {
+		return nil, err
+	}
+	if err := op.f.checkShapes(inputs[:len(inputs)-1]); err != nil {
+		return nil, err
+	}
+	return op.f.inputs[op.i].Shape(), nil
+}
+
+func (op funcGradOp) Do(inputs ...Value) (Value, error) {
+	if err := checkArity(op, len(inputs)); err != nil {
+		return nil, err
+	}
+	last := len(inputs) - 1
+	return op.f.backward(op.i, inputs[:last], inputs[last])
+}
+
+func (op funcGradOp) ReturnsPtr() bool     { return false }
+func (op funcGradOp) CallsExtern() bool    { return false }
+func (op funcGradOp) OverwritesInput() int { return -1 }
+
+func (op funcGradOp) WriteHash(h hash.Hash) { fmt.Fprintf(h, "grad %s %p %d", op.f.name, op.f, op.i) }
+
+func (op funcGradOp) Hashcode() uint32 { return simpleHash(op) }
+
+func (op funcGradOp) String() string { return fmt.Sprintf("∇%s[%d]", op.f.name, op.i) }
+
+func (op funcGradOp) DiffWRT(inputs int) []bool { return make([]bool, inputs) }
+
+func (op funcGradOp) SymDiff(inputs Nodes, output, grad *Node) (Nodes, error) {
+	return nil, errors.Errorf(nyiFail, "SymDiff", op)
+}
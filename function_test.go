@@ -0,0 +1,127 @@
+package gorgonia
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorgonia.org/tensor"
+)
+
+// residual is x + tanh(x×w)
+func residual(in Nodes) (*Node, error) {
+	xw, err := Mul(in[0], in[1])
+	if err != nil {
+		return nil, err
+	}
+	act, err := Tanh(xw)
+	if err != nil {
+		return nil, err
+	}
+	return Add(in[0], act)
+}
+
+func TestDefine(t *testing.T) {
+	assert := assert.New(t)
+
+	// the same model, built with calls to a Function and inline
+	build := func(useFunc bool) (g *ExprGraph, x, w1, w2, cost *Node) {
+		g = NewGraph()
+		x = NewMatrix(g, Float64, WithShape(2, 3), WithName("x"), WithInit(RangedFrom(-2)))
+		w1 = NewMatrix(g, Float64, WithShape(3, 3), WithName("w1"), WithValue(tensor.New(tensor.WithShape(3, 3), tensor.WithBacking([]float64{0.1, -0.2, 0.3, 0.4, 0.5, -0.6, 0.7, 0.8, 0.9}))))
+		w2 = NewMatrix(g, Float64, WithShape(3, 3), WithName("w2"), WithValue(tensor.New(tensor.WithShape(3, 3), tensor.WithBacking([]float64{-0.3, 0.2, 0.1, 0.6, -0.5, 0.4, 0.9, 0.8, -0.7}))))
+
+		var h1, h2 *Node
+		var err error
+		if useFunc {
+			var block *Function
+			if block, err = Define("residual", Nodes{x, w1}, residual); err != nil {
+				t.Fatal(err)
+			}
+			assert.True(block.Differentiable())
+			if h1, err = block.Call(x, w1); err != nil {
+				t.Fatal(err)
+			}
+			// shared parameters
+			if h2, err = block.Call(h1, w1); err != nil {
+				t.Fatal(err)
+			}
+			// separate parameters
+			if h2, err = block.Call(h2, w2); err != nil {
+				t.Fatal(err)
+			}
+		} else {
+			h1 = Must(residual(Nodes{x, w1}))
+			h2 = Must(residual(Nodes{h1, w1}))
+			h2 = Must(residual(Nodes{h2, w2}))
+		}
+		cost = Must(Sum(h2))
+		if _, err = Grad(cost, x, w1, w2); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	g1, x1, w11, w21, cost1 := build(true)
+	g2, x2, w12, w22, cost2 := build(false)
+	assert.True(g1.AllNodes().Len() < g2.AllNodes().Len())
+
+	for _, g := range []*ExprGraph{g1, g2} {
+		m := NewTapeMachine(g)
+		if err := m.RunAll(); err != nil {
+			t.Fatal(err)
+		}
+		m.Close()
+	}
+
+	assert.InDelta(cost2.Value().Data().(float64), cost1.Value().Data().(float64), 1e-10)
+	pairs := [][2]*Node{{x1, x2}, {w11, w12}, {w21, w22}}
+	for _, p := range pairs {
+		g1, err := p[0].Grad()
+		if err != nil {
+			t.Fatal(err)
+		}
+		g2, err := p[1].Grad()
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.InDeltaSlice(g2.Data(), g1.Data(), 1e-10, "%v", p[0])
+	}
+}
+
+func TestDefine_Errors(t *testing.T) {
+	assert := assert.New(t)
+	g := NewGraph()
+	x := NewMatrix(g, Float64, WithShape(2, 3), WithName("x"))
+	w := NewMatrix(g, Float64, WithShape(3, 3), WithName("w"))
+
+	_, err := Define("empty", nil, residual)
+	assert.Error(err)
+
+	_, err = Define("outside", Nodes{x}, func(in Nodes) (*Node, error) { return x, nil })
+	assert.Error(err)
+
+	block, err := Define("residual", Nodes{x, w}, residual)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = block.Call(x)
+	assert.Error(err)
+
+	y := NewMatrix(g, Float64, WithShape(3, 3), WithName("y"))
+	_, err = block.Call(y, w)
+	assert.Error(err)
+
+	// non differentiable bodies can be called, but not differentiated
+	sign, err := Define("sign", Nodes{x}, func(in Nodes) (*Node, error) { return Sign(in[0]) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.False(sign.Differentiable())
+	s, err := sign.Call(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cost := Must(Sum(s))
+	_, err = Grad(cost, x)
+	assert.Error(err)
+}
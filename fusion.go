@@ -0,0 +1,85 @@
+package gorgonia
+
+// Fuse performs a fusion pass over the graph, replacing chains of operations with fused ops where it is safe to do so.
+// Currently the only fusion is:
+//		a*b + c → FMA(a, b, c)
+//		a*s + c → Axpy(s, a, c) (s being a scalar)
+// A multiplication is only fused into the addition if the addition is its only user. Named nodes are never fused
+// away, since the user may want to read them.
+//
+// Fuse should be called after the graph has been fully constructed (including any calls to Grad), and before
+// the graph is compiled into a machine. It returns the number of nodes fused.
+func Fuse(g *ExprGraph) (fused int) {
+	// fusing removes nodes from the graph, so iterate over a copy
+	all := make(Nodes, len(g.all))
+	copy(all, g.all)
+	for _, n := range all {
+		if fuseMulAdd(g, n) {
+			fused++
+		}
+	}
+	if fused > 0 {
+		// the indices of g.all will have changed
+		g.byID = make(map[int64]int)
+	}
+	return fused
+}
+
+// fuseMulAdd fuses n = Add(Mul(a, b), c) into n = FMA(a, b, c), in place.
+func fuseMulAdd(g *ExprGraph, n *Node) bool {
+	op, ok := n.op.(elemBinOp)
+	if !ok || op.binOpType() != addOpType || n.IsScalar() || n.reuse != nil || checkFloatNode(n) != nil {
+		return false
+	}
+
+	var m, c *Node
+	for i, child := range n.children {
+		if isFusableMul(g, n, child) {
+			m, c = child, n.children[1-i]
+			break
+		}
+	}
+	if m == nil || c.IsScalar() || !c.Shape().Eq(n.Shape()) || c.Dtype() != n.Dtype() {
+		return false
+	}
+
+	a, b := m.children[0], m.children[1]
+	if a.IsScalar() {
+		a, b = b, a
+	}
+	if a.IsScalar() || !a.Shape().Eq(n.Shape()) {
+		return false
+	}
+
+	stabLogf("Fusing %v and %v", m, n)
+
+	delete(g.byHash, n.Hashcode())
+	g.RemoveNode(m)
+	for _, child := range m.children {
+		g.to.set(child, g.to.get(child).remove(m))
+		if !g.to.get(child).Contains(n) {
+			g.to.set(child, append(g.to.get(child), n))
+		}
+	}
+
+	n.op = newFMAOp(a, b)
+	n.children = Nodes{a, b, c}
+	n.hashed = false
+	if _, ok := g.byHash[n.Hashcode()]; !ok {
+		g.byHash[n.Hashcode()] = n
+	}
+	return true
+}
+
+// isFusableMul checks that m is a float multiplication whose only user is n.
+func isFusableMul(g *ExprGraph, n, m *Node) bool {
+	op, ok := m.op.(elemBinOp)
+	if !ok || op.binOpType() != mulOpType || m.name != "" || m.reuse != nil || m.boundTo != nil || m.derivOf != nil || m.deriv != nil {
+		return false
+	}
+	if m.Dtype() != n.Dtype() {
+		return false
+	}
+	parents := g.to.get(m)
+	return len(parents) == 1 && parents[0] == n
+}
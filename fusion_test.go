@@ -0,0 +1,80 @@
+package gorgonia
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorgonia.org/tensor"
+)
+
+func fusionTestGraph() (g *ExprGraph, a, b, c, s, f, x *Node) {
+	g = NewGraph()
+	a = NewVector(g, Float64, WithShape(3), WithName("a"), WithValue(tensor.New(tensor.WithBacking([]float64{1, 2, 3}))))
+	b = NewVector(g, Float64, WithShape(3), WithName("b"), WithValue(tensor.New(tensor.WithBacking([]float64{4, 5, 6}))))
+	c = NewVector(g, Float64, WithShape(3), WithName("c"), WithValue(tensor.New(tensor.WithBacking([]float64{1, 1, 1}))))
+	s = NewScalar(g, Float64, WithName("s"), WithValue(2.0))
+	f = Must(Add(Must(HadamardProd(a, b)), c))
+	x = Must(Add(c, Must(Mul(s, a))))
+	return
+}
+
+func TestFuse(t *testing.T) {
+	assert := assert.New(t)
+
+	g, _, _, _, _, f, x := fusionTestGraph()
+	before := len(g.AllNodes())
+	assert.Equal(2, Fuse(g))
+	assert.Equal(before-2, len(g.AllNodes()))
+	assert.IsType(fmaOp{}, f.op)
+	assert.IsType(fmaOp{}, x.op)
+	assert.True(x.op.(fmaOp).scalarB)
+	assert.Equal(0, Fuse(g), "fusing twice should be a noop")
+
+	m := NewTapeMachine(g)
+	defer m.Close()
+	if err := m.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]float64{5, 11, 19}, f.Value().Data())
+	assert.Equal([]float64{3, 5, 7}, x.Value().Data())
+}
+
+func TestFuse_Shared(t *testing.T) {
+	assert := assert.New(t)
+	g := NewGraph()
+	a := NewVector(g, Float64, WithShape(3), WithName("a"))
+	b := NewVector(g, Float64, WithShape(3), WithName("b"))
+	named := Must(HadamardProd(a, b))
+	shared := Must(HadamardProd(b, b))
+	Must(Add(shared, a))
+	Must(Add(shared, b))
+	Must(Add(a, b))
+	named.name = "named"
+	Must(Add(named, a))
+
+	// the multiplications are used twice or named, so nothing may be fused
+	assert.Equal(0, Fuse(g))
+}
+
+func TestFuse_Grad(t *testing.T) {
+	assert := assert.New(t)
+
+	g, a, b, c, _, f, x := fusionTestGraph()
+	cost := Must(Sum(Must(Add(f, x))))
+	if _, err := Grad(cost, a, b, c); err != nil {
+		t.Fatal(err)
+	}
+	assert.NotZero(Fuse(g))
+
+	m := NewTapeMachine(g)
+	defer m.Close()
+	if err := m.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+	ag, _ := a.Grad()
+	bg, _ := b.Grad()
+	cg, _ := c.Grad()
+	assert.Equal([]float64{6, 7, 8}, ag.Data())
+	assert.Equal([]float64{1, 2, 3}, bg.Data())
+	assert.Equal([]float64{2, 2, 2}, cg.Data())
+}
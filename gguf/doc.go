@@ -0,0 +1,22 @@
+// Package gguf reads the GGUF files of llama.cpp, to run the models they hold, such as the llama models, with
+// Gorgonia. The quantized tensors are read as gorgonia.QuantizedTensors, which gorgonia.QuantizedMatMul multiplies
+// without dequantizing them first:
+//
+//	f, err := gguf.Open("llama-2-7b.Q4_K_M.gguf")
+//	...
+//	defer f.Close()
+//	q, err := f.Quantized("blk.0.attn_q.weight")
+//	...
+//	wq, err := gorgonia.NewQuantizedWeight(g, q, "blk.0.attn_q.weight")
+//	...
+//	xq, err := gorgonia.QuantizedMatMul(x, wq)
+//
+// The blocks of the Q4_0, Q4_1, Q5_0, Q5_1 and Q8_0 types, and the super-blocks of the Q4_K, Q5_K and Q6_K types, are
+// read as groups of int8 quants that share a scale and, for the types that have them, a min: a super-block of Q4_K is
+// read as 8 groups of 32 quants, whose scales and mins are those of the super-block multiplied by the 6-bit scales and
+// mins of the groups. The other quantized types are not supported.
+//
+// The tensors that are not quantized, such as the weights of the norms, are read by Dense, which also dequantizes the
+// quantized tensors. The shapes are those of row-major tensors, which are the reverse of the dimensions of GGML: the
+// weight of a linear layer is of the shape (out, in), with the blocks along in.
+package gguf
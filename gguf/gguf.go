@@ -0,0 +1,340 @@
+package gguf
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"reflect"
+
+	"github.com/pkg/errors"
+	"gorgonia.org/tensor"
+)
+
+const magic = "GGUF"
+
+// the alignment of the tensors, unless the metadata general.alignment says otherwise
+const defaultAlignment = 32
+
+// the largest string and array of the metadata that are read, so that the corrupted lengths do not exhaust the memory
+const maxLen = 1 << 28
+
+// File is a GGUF file: its metadata, and the infos of its tensors, whose data are read on demand.
+type File struct {
+	Version  uint32
+	Keys     []string // the keys of the Metadata, in the order of the file
+	Metadata map[string]interface{}
+	Tensors  []TensorInfo
+
+	r      io.ReaderAt
+	closer io.Closer
+	data   int64 // the offset of the data of the tensors
+	size   int64 // the size of the file, or -1 if r does not tell it
+	byName map[string]int
+}
+
+// TensorInfo describes a tensor of a file. The Offset is the offset of its data from the start of the data of the
+// tensors.
+type TensorInfo struct {
+	Name   string
+	Shape  tensor.Shape
+	Type   Type
+	Offset int64
+}
+
+// Open opens a GGUF file. The file must be closed once its tensors are read.
+func Open(path string) (*File, error) {
+	osf, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	f, err := Read(osf)
+	if err != nil {
+		osf.Close()
+		return nil, errors.Wrapf(err, "Cannot read the GGUF file %v", path)
+	}
+	f.closer = osf
+	return f, nil
+}
+
+// Read reads the metadata and the infos of the tensors of a GGUF file, of the version 2 or 3. The data of the tensors
+// are read from r when they are asked for.
+func Read(r io.ReaderAt) (*File, error) {
+	cr := &countingReader{r: bufio.NewReader(io.NewSectionReader(r, 0, math.MaxInt64))}
+	d := &decoder{r: cr}
+	var m [4]byte
+	d.read(m[:])
+	f := &File{Version: d.u32(), Metadata: make(map[string]interface{}), r: r, size: sizeOf(r), byName: make(map[string]int)}
+	if d.err != nil {
+		return nil, d.err
+	}
+	if string(m[:]) != magic {
+		return nil, errors.Errorf("The file is not a GGUF file: its magic is %q", m[:])
+	}
+	if f.Version != 2 && f.Version != 3 {
+		return nil, errors.Errorf("The version %d of GGUF is not supported", f.Version)
+	}
+	tensors, kvs := d.u64(), d.u64()
+	for i := uint64(0); i < kvs && d.err == nil; i++ {
+		key := d.string()
+		v := d.value(valueType(d.u32()), 0)
+		if d.err != nil {
+			return nil, errors.Wrapf(d.err, "The metadata %q", key)
+		}
+		if _, ok := f.Metadata[key]; !ok {
+			f.Keys = append(f.Keys, key)
+		}
+		f.Metadata[key] = v
+	}
+	for i := uint64(0); i < tensors && d.err == nil; i++ {
+		var t TensorInfo
+		t.Name = d.string()
+		dims := d.u32()
+		if dims > 8 {
+			return nil, errors.Errorf("The tensor %q has %d dimensions", t.Name, dims)
+		}
+		t.Shape = make(tensor.Shape, dims)
+		for j := range t.Shape {
+			// the dimensions of GGML are from the innermost
+			n := d.u64()
+			if n > math.MaxInt32 {
+				return nil, errors.Errorf("The tensor %q has a dimension of %d", t.Name, n)
+			}
+			t.Shape[len(t.Shape)-1-j] = int(n)
+		}
+		t.Type = Type(d.u32())
+		t.Offset = int64(d.u64())
+		if d.err != nil {
+			break
+		}
+		if t.Offset < 0 {
+			return nil, errors.Errorf("The tensor %q has the offset %d", t.Name, t.Offset)
+		}
+		f.byName[t.Name] = len(f.Tensors)
+		f.Tensors = append(f.Tensors, t)
+	}
+	if d.err != nil {
+		return nil, d.err
+	}
+
+	align := int64(defaultAlignment)
+	if a, ok := f.Metadata["general.alignment"].(uint32); ok {
+		if a == 0 || a&(a-1) != 0 {
+			return nil, errors.Errorf("The alignment %d is not a power of 2", a)
+		}
+		align = int64(a)
+	}
+	f.data = (cr.n + align - 1) / align * align
+	return f, nil
+}
+
+// Close closes the file opened by Open.
+func (f *File) Close() error {
+	if f.closer == nil {
+		return nil
+	}
+	return f.closer.Close()
+}
+
+// Info returns the info of the tensor of the given name, or nil if there is none.
+func (f *File) Info(name string) *TensorInfo {
+	i, ok := f.byName[name]
+	if !ok {
+		return nil
+	}
+	return &f.Tensors[i]
+}
+
+// raw reads the data of a tensor.
+func (f *File) raw(name string) (*TensorInfo, layout, []byte, error) {
+	t := f.Info(name)
+	if t == nil {
+		return nil, layout{}, nil, errors.Errorf("The file has no tensor %q", name)
+	}
+	l, ok := layouts[t.Type]
+	if !ok {
+		return nil, layout{}, nil, errors.Errorf("The tensor %q is of the type %v, which is not supported", name, t.Type)
+	}
+	if len(t.Shape) == 0 || t.Shape[len(t.Shape)-1]%l.block != 0 {
+		return nil, layout{}, nil, errors.Errorf("The tensor %q of the shape %v is not made of blocks of %d elements of %v", name, t.Shape, l.block, t.Type)
+	}
+	// the sizes of a corrupted header are checked before the data are allocated
+	n := int64(1)
+	for _, d := range t.Shape {
+		if d != 0 && n > math.MaxInt64/int64(d) {
+			return nil, layout{}, nil, errors.Errorf("The tensor %q of the shape %v is too large", name, t.Shape)
+		}
+		n *= int64(d)
+	}
+	if n/int64(l.block) > (math.MaxInt64-f.data-t.Offset)/int64(l.size) {
+		return nil, layout{}, nil, errors.Errorf("The tensor %q of the shape %v is too large", name, t.Shape)
+	}
+	nbytes := n / int64(l.block) * int64(l.size)
+	end := f.data + t.Offset + nbytes
+	if f.size >= 0 && end > f.size {
+		return nil, layout{}, nil, errors.Errorf("The data of the tensor %q end at %d, past the end of the file at %d", name, end, f.size)
+	}
+	if f.size < 0 && nbytes > 0 {
+		// the last byte of the data is read first
+		var last [1]byte
+		if _, err := f.r.ReadAt(last[:], end-1); err != nil {
+			return nil, layout{}, nil, errors.Wrapf(err, "Cannot read the data of the tensor %q, which end at %d", name, end)
+		}
+	}
+	p := make([]byte, nbytes)
+	if _, err := f.r.ReadAt(p, f.data+t.Offset); err != nil {
+		return nil, layout{}, nil, errors.Wrapf(err, "Cannot read the data of the tensor %q", name)
+	}
+	return t, l, p, nil
+}
+
+// sizeOf returns the size of the files and of the readers that tell it, such as *os.File and *bytes.Reader, or -1.
+func sizeOf(r io.ReaderAt) int64 {
+	switch r := r.(type) {
+	case interface{ Size() int64 }:
+		return r.Size()
+	case interface{ Stat() (os.FileInfo, error) }:
+		if fi, err := r.Stat(); err == nil {
+			return fi.Size()
+		}
+	}
+	return -1
+}
+
+/* the metadata */
+
+type valueType uint32
+
+const (
+	typeUint8 valueType = iota
+	typeInt8
+	typeUint16
+	typeInt16
+	typeUint32
+	typeInt32
+	typeFloat32
+	typeBool
+	typeString
+	typeArray
+	typeUint64
+	typeInt64
+	typeFloat64
+)
+
+// the Go types of the values of the metadata
+var valueTypes = map[valueType]reflect.Type{
+	typeUint8:   reflect.TypeOf(uint8(0)),
+	typeInt8:    reflect.TypeOf(int8(0)),
+	typeUint16:  reflect.TypeOf(uint16(0)),
+	typeInt16:   reflect.TypeOf(int16(0)),
+	typeUint32:  reflect.TypeOf(uint32(0)),
+	typeInt32:   reflect.TypeOf(int32(0)),
+	typeFloat32: reflect.TypeOf(float32(0)),
+	typeBool:    reflect.TypeOf(false),
+	typeString:  reflect.TypeOf(""),
+	typeUint64:  reflect.TypeOf(uint64(0)),
+	typeInt64:   reflect.TypeOf(int64(0)),
+	typeFloat64: reflect.TypeOf(float64(0)),
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.n += int64(n)
+	return n, err
+}
+
+// decoder reads the little endian fields of the header. The first error sticks, and the fields read after it are
+// zeroes.
+type decoder struct {
+	r   io.Reader
+	err error
+}
+
+func (d *decoder) read(p []byte) {
+	if d.err != nil {
+		return
+	}
+	if _, d.err = io.ReadFull(d.r, p); d.err == io.EOF || d.err == io.ErrUnexpectedEOF {
+		d.err = errors.New("The header is truncated")
+	}
+}
+
+func (d *decoder) u32() uint32 {
+	var p [4]byte
+	d.read(p[:])
+	return binary.LittleEndian.Uint32(p[:])
+}
+
+func (d *decoder) u64() uint64 {
+	var p [8]byte
+	d.read(p[:])
+	return binary.LittleEndian.Uint64(p[:])
+}
+
+func (d *decoder) string() string {
+	n := d.u64()
+	if d.err == nil && n > maxLen {
+		d.err = errors.Errorf("The string of %d bytes is too long", n)
+	}
+	if d.err != nil {
+		return ""
+	}
+	p := make([]byte, n)
+	d.read(p)
+	return string(p)
+}
+
+// value reads a value of the metadata. The arrays of the arrays are of []interface{}, and the other arrays of the
+// slices of the types of their elements, such as the []string of tokenizer.ggml.tokens.
+func (d *decoder) value(typ valueType, depth int) interface{} {
+	if d.err != nil {
+		return nil
+	}
+	switch typ {
+	case typeString:
+		return d.string()
+	case typeArray:
+		if depth > 4 {
+			d.err = errors.New("The arrays are nested too deeply")
+			return nil
+		}
+		elem := valueType(d.u32())
+		n := d.u64()
+		if d.err == nil && n > maxLen {
+			d.err = errors.Errorf("The array of %d elements is too long", n)
+		}
+		et, ok := valueTypes[elem]
+		if !ok {
+			et = reflect.TypeOf((*interface{})(nil)).Elem()
+		}
+		s := reflect.MakeSlice(reflect.SliceOf(et), 0, 0)
+		for i := uint64(0); i < n && d.err == nil; i++ {
+			v := d.value(elem, depth+1)
+			if d.err != nil {
+				break
+			}
+			s = reflect.Append(s, reflect.ValueOf(v))
+		}
+		return s.Interface()
+	}
+	t, ok := valueTypes[typ]
+	if !ok {
+		d.err = errors.Errorf("The type %d of a value is not supported", typ)
+		return nil
+	}
+	v := reflect.New(t)
+	if d.err = binary.Read(d.r, binary.LittleEndian, v.Interface()); d.err != nil {
+		d.err = errors.New("The header is truncated")
+		return nil
+	}
+	return v.Elem().Interface()
+}
+
+func (t TensorInfo) String() string { return fmt.Sprintf("%v %v %v", t.Name, t.Type, t.Shape) }
@@ -0,0 +1,249 @@
+package gguf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorgonia.org/tensor"
+)
+
+// kv is a key and a value of the metadata of the files of the tests.
+type kv struct {
+	key string
+	typ valueType
+	v   interface{}
+}
+
+// savedTensor is a tensor of the files of the tests, whose dimensions are those of GGML.
+type savedTensor struct {
+	name string
+	dims []int
+	typ  Type
+	data []byte
+}
+
+type writer struct{ bytes.Buffer }
+
+func (w *writer) le(v interface{}) { binary.Write(w, binary.LittleEndian, v) }
+
+func (w *writer) str(s string) {
+	w.le(uint64(len(s)))
+	w.WriteString(s)
+}
+
+func (w *writer) value(typ valueType, v interface{}) {
+	switch typ {
+	case typeString:
+		w.str(v.(string))
+	case typeArray:
+		a := v.(kv) // the type of the elements, and a slice of them
+		w.le(uint32(a.typ))
+		switch vs := a.v.(type) {
+		case []string:
+			w.le(uint64(len(vs)))
+			for _, s := range vs {
+				w.str(s)
+			}
+		case []float32:
+			w.le(uint64(len(vs)))
+			w.le(vs)
+		}
+	default:
+		w.le(v)
+	}
+}
+
+// file returns a GGUF file of the metadata and of the tensors, whose data are aligned to align.
+func file(version uint32, align int, kvs []kv, ts []savedTensor) []byte {
+	w := new(writer)
+	w.WriteString(magic)
+	w.le(version)
+	w.le(uint64(len(ts)))
+	w.le(uint64(len(kvs)))
+	for _, kv := range kvs {
+		w.str(kv.key)
+		w.le(uint32(kv.typ))
+		w.value(kv.typ, kv.v)
+	}
+	var data bytes.Buffer
+	for _, t := range ts {
+		for data.Len()%align != 0 {
+			data.WriteByte(0)
+		}
+		w.str(t.name)
+		w.le(uint32(len(t.dims)))
+		for _, d := range t.dims {
+			w.le(uint64(d))
+		}
+		w.le(uint32(t.typ))
+		w.le(uint64(data.Len()))
+		data.Write(t.data)
+	}
+	for w.Len()%align != 0 {
+		w.WriteByte(0)
+	}
+	w.Write(data.Bytes())
+	return w.Bytes()
+}
+
+func float32Bytes(vs ...float32) []byte {
+	var w writer
+	w.le(vs)
+	return w.Bytes()
+}
+
+func TestOpen(t *testing.T) {
+	assert := assert.New(t)
+	kvs := []kv{
+		{key: "general.architecture", typ: typeString, v: "llama"},
+		{key: "general.alignment", typ: typeUint32, v: uint32(64)},
+		{key: "llama.context_length", typ: typeUint64, v: uint64(4096)},
+		{key: "llama.rope.freq_base", typ: typeFloat32, v: float32(10000)},
+		{key: "tokenizer.ggml.tokens", typ: typeArray, v: kv{typ: typeString, v: []string{"<s>", "</s>", "▁the"}}},
+		{key: "tokenizer.ggml.scores", typ: typeArray, v: kv{typ: typeFloat32, v: []float32{0, 0, -1.5}}},
+		{key: "tokenizer.ggml.add_bos_token", typ: typeBool, v: true},
+	}
+	ts := []savedTensor{
+		{name: "output_norm.weight", dims: []int{3}, typ: F32, data: float32Bytes(1, 2, 3)},
+		{name: "token_embd.weight", dims: []int{2, 3}, typ: F16, data: []byte{0x00, 0x3c, 0x00, 0xc0, 0, 0, 0, 0, 0, 0, 0x00, 0x3c}},
+	}
+	dir, err := ioutil.TempDir("", "gguf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "model.gguf")
+	if err = ioutil.WriteFile(path, file(3, 64, kvs, ts), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	assert.Equal(uint32(3), f.Version)
+	assert.Equal([]string{"general.architecture", "general.alignment", "llama.context_length", "llama.rope.freq_base", "tokenizer.ggml.tokens", "tokenizer.ggml.scores", "tokenizer.ggml.add_bos_token"}, f.Keys)
+	assert.Equal("llama", f.Metadata["general.architecture"])
+	assert.Equal(uint64(4096), f.Metadata["llama.context_length"])
+	assert.Equal(float32(10000), f.Metadata["llama.rope.freq_base"])
+	assert.Equal([]string{"<s>", "</s>", "▁the"}, f.Metadata["tokenizer.ggml.tokens"])
+	assert.Equal([]float32{0, 0, -1.5}, f.Metadata["tokenizer.ggml.scores"])
+	assert.Equal(true, f.Metadata["tokenizer.ggml.add_bos_token"])
+
+	assert.Len(f.Tensors, 2)
+	info := f.Info("token_embd.weight")
+	if assert.NotNil(info) {
+		assert.Equal(tensor.Shape{3, 2}, info.Shape, "the reverse of the dimensions of GGML")
+		assert.Equal(F16, info.Type)
+		assert.Equal("token_embd.weight F16 (3, 2)", info.String())
+	}
+	assert.Nil(f.Info("nope"))
+	norm, err := f.Dense("output_norm.weight")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]float32{1, 2, 3}, norm.Data())
+	embd, err := f.Dense("token_embd.weight")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]float32{1, -2, 0, 0, 0, 1}, embd.Data())
+	_, err = f.Quantized("output_norm.weight")
+	assert.Error(err, "not quantized")
+	_, err = f.Dense("nope")
+	assert.Error(err)
+}
+
+func TestRead_errors(t *testing.T) {
+	assert := assert.New(t)
+	norm := savedTensor{name: "norm", dims: []int{2}, typ: F32, data: float32Bytes(1, 2)}
+	read := func(p []byte) (*File, error) { return Read(bytes.NewReader(p)) }
+
+	_, err := Open("nope.gguf")
+	assert.Error(err, "no file")
+	p := file(3, 32, nil, []savedTensor{norm})
+	_, err = read(append([]byte("GGML"), p[4:]...))
+	assert.Error(err, "not GGUF")
+	_, err = read(file(1, 32, nil, []savedTensor{norm}))
+	assert.Error(err, "an unsupported version")
+	_, err = read(p[:30])
+	assert.Error(err, "a truncated header")
+	_, err = read(file(3, 32, []kv{{key: "x", typ: 13, v: uint8(0)}}, nil))
+	assert.Error(err, "an unsupported value type")
+	_, err = read(file(3, 32, []kv{{key: "general.alignment", typ: typeUint32, v: uint32(24)}}, nil))
+	assert.Error(err, "an alignment that is not a power of 2")
+
+	f, err := read(file(2, 32, nil, []savedTensor{
+		{name: "q2", dims: []int{256}, typ: Q2_K, data: make([]byte, 84)},
+		{name: "q8", dims: []int{16}, typ: Q8_0, data: make([]byte, 34)},
+		{name: "short", dims: []int{4}, typ: F32, data: float32Bytes(1)},
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = f.Dense("q2")
+	if assert.Error(err) {
+		assert.Contains(err.Error(), "Q2_K")
+	}
+	_, err = f.Quantized("q8")
+	assert.Error(err, "not made of blocks")
+	_, err = f.Dense("short")
+	assert.Error(err, "the data are truncated")
+
+	// the sizes of the corrupted headers, which are not allocated
+	huge := savedTensor{name: "huge", dims: []int{math.MaxInt32, math.MaxInt32, math.MaxInt32}, typ: F32, data: float32Bytes(1)}
+	past := savedTensor{name: "past", dims: []int{1 << 30, 4}, typ: Q8_0, data: make([]byte, 34)}
+	p = file(3, 32, nil, []savedTensor{huge, past})
+	for _, r := range []io.ReaderAt{bytes.NewReader(p), readerAt{p}} {
+		if f, err = Read(r); err != nil {
+			t.Fatal(err)
+		}
+		_, err = f.Dense("huge")
+		assert.Error(err, "the product of the dimensions overflows")
+		_, err = f.Quantized("past")
+		assert.Error(err, "the data end past the end of the file")
+	}
+	p = file(3, 32, nil, []savedTensor{norm})
+	binary.LittleEndian.PutUint64(p[52:], 1<<63) // the offset of norm, after the header and its name, dims and type
+	_, err = read(p)
+	assert.Error(err, "a negative offset")
+}
+
+// readerAt is an io.ReaderAt that does not tell its size.
+type readerAt struct{ p []byte }
+
+func (r readerAt) ReadAt(p []byte, off int64) (int, error) {
+	return bytes.NewReader(r.p).ReadAt(p, off)
+}
+
+func TestRead_corrupt(t *testing.T) {
+	ts := []savedTensor{
+		{name: "norm", dims: []int{2}, typ: F32, data: float32Bytes(1, 2)},
+		{name: "q", dims: []int{32, 2}, typ: Q4_0, data: make([]byte, 36)},
+	}
+	kvs := []kv{{key: "tokens", typ: typeArray, v: kv{typ: typeString, v: []string{"a", "b"}}}}
+	p := file(3, 32, kvs, ts)
+	// every byte is corrupted in turn: the errors are returned, and nothing is allocated from the corrupted sizes
+	for i := range p {
+		for _, b := range []byte{0xff, 0x7f, 0} {
+			c := append([]byte{}, p...)
+			c[i] = b
+			f, err := Read(bytes.NewReader(c))
+			if err != nil {
+				continue
+			}
+			for _, info := range f.Tensors {
+				f.Dense(info.Name)
+				f.Quantized(info.Name)
+			}
+		}
+	}
+}
@@ -0,0 +1,250 @@
+package gguf
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/pkg/errors"
+	G "gorgonia.org/gorgonia"
+	"gorgonia.org/gorgonia/internal/weights"
+	"gorgonia.org/tensor"
+)
+
+// Type is the type of the elements of a tensor, which is a ggml_type.
+type Type uint32
+
+// The Types of GGML.
+const (
+	F32  Type = 0
+	F16  Type = 1
+	Q4_0 Type = 2
+	Q4_1 Type = 3
+	Q5_0 Type = 6
+	Q5_1 Type = 7
+	Q8_0 Type = 8
+	Q8_1 Type = 9
+	Q2_K Type = 10
+	Q3_K Type = 11
+	Q4_K Type = 12
+	Q5_K Type = 13
+	Q6_K Type = 14
+	Q8_K Type = 15
+	I8   Type = 24
+	I16  Type = 25
+	I32  Type = 26
+	I64  Type = 27
+	F64  Type = 28
+	BF16 Type = 30
+)
+
+var typeNames = map[Type]string{
+	F32: "F32", F16: "F16", Q4_0: "Q4_0", Q4_1: "Q4_1", Q5_0: "Q5_0", Q5_1: "Q5_1", Q8_0: "Q8_0", Q8_1: "Q8_1",
+	Q2_K: "Q2_K", Q3_K: "Q3_K", Q4_K: "Q4_K", Q5_K: "Q5_K", Q6_K: "Q6_K", Q8_K: "Q8_K",
+	I8: "I8", I16: "I16", I32: "I32", I64: "I64", F64: "F64", BF16: "BF16",
+}
+
+func (t Type) String() string {
+	if s, ok := typeNames[t]; ok {
+		return s
+	}
+	return fmt.Sprintf("Type(%d)", uint32(t))
+}
+
+// layout is the layout of the blocks of a type. The blocks of the types that are not quantized are their elements.
+type layout struct {
+	block, size int // the elements and the bytes of a block
+	group       int // the elements that share a scale
+	mins        bool
+	// decode decodes a block into its quants, and the scales and mins of its groups
+	decode func(b []byte, q []int8, scales, mins []float32)
+
+	dense weights.Type // of the types that are not quantized
+}
+
+var layouts = map[Type]layout{
+	F32:  {block: 1, size: 4, dense: weights.Type{Dtype: tensor.Float32, Size: 4}},
+	F16:  {block: 1, size: 2, dense: weights.Type{Dtype: tensor.Float32, Size: 2, Conv: weights.Float16}},
+	BF16: {block: 1, size: 2, dense: weights.Type{Dtype: tensor.Float32, Size: 2, Conv: weights.BFloat16}},
+	F64:  {block: 1, size: 8, dense: weights.Type{Dtype: tensor.Float64, Size: 8}},
+	I8:   {block: 1, size: 1, dense: weights.Type{Dtype: tensor.Int8, Size: 1}},
+	I16:  {block: 1, size: 2, dense: weights.Type{Dtype: tensor.Int16, Size: 2}},
+	I32:  {block: 1, size: 4, dense: weights.Type{Dtype: tensor.Int32, Size: 4}},
+	I64:  {block: 1, size: 8, dense: weights.Type{Dtype: tensor.Int64, Size: 8}},
+
+	Q4_0: {block: 32, size: 18, group: 32, decode: decodeQ4_0},
+	Q4_1: {block: 32, size: 20, group: 32, mins: true, decode: decodeQ4_1},
+	Q5_0: {block: 32, size: 22, group: 32, decode: decodeQ5_0},
+	Q5_1: {block: 32, size: 24, group: 32, mins: true, decode: decodeQ5_1},
+	Q8_0: {block: 32, size: 34, group: 32, decode: decodeQ8_0},
+	Q4_K: {block: 256, size: 144, group: 32, mins: true, decode: decodeQ4_K},
+	Q5_K: {block: 256, size: 176, group: 32, mins: true, decode: decodeQ5_K},
+	Q6_K: {block: 256, size: 210, group: 16, decode: decodeQ6_K},
+}
+
+// Quantized reads a quantized tensor, of one of the types Q4_0, Q4_1, Q5_0, Q5_1, Q8_0, Q4_K, Q5_K and Q6_K.
+func (f *File) Quantized(name string) (*G.QuantizedTensor, error) {
+	t, l, p, err := f.raw(name)
+	if err != nil {
+		return nil, err
+	}
+	if l.decode == nil {
+		return nil, errors.Errorf("The tensor %q is of the type %v, which is not quantized: it is read by Dense", name, t.Type)
+	}
+	size := t.Shape.TotalSize()
+	q := make([]int8, size)
+	scales := make([]float32, size/l.group)
+	var mins []float32
+	if l.mins {
+		mins = make([]float32, len(scales))
+	}
+	groups := l.block / l.group
+	for b := 0; b < size/l.block; b++ {
+		var bm []float32
+		if mins != nil {
+			bm = mins[b*groups : (b+1)*groups]
+		}
+		l.decode(p[b*l.size:(b+1)*l.size], q[b*l.block:(b+1)*l.block], scales[b*groups:(b+1)*groups], bm)
+	}
+	groupShape := t.Shape.Clone()
+	groupShape[len(groupShape)-1] /= l.group
+	retVal := &G.QuantizedTensor{
+		Quants: tensor.New(tensor.WithShape(t.Shape.Clone()...), tensor.WithBacking(q)),
+		Scales: tensor.New(tensor.WithShape(groupShape...), tensor.WithBacking(scales)),
+		Group:  l.group,
+	}
+	if mins != nil {
+		retVal.Mins = tensor.New(tensor.WithShape(groupShape.Clone()...), tensor.WithBacking(mins))
+	}
+	return retVal, nil
+}
+
+// Dense reads a tensor. The quantized tensors are dequantized into Float32 tensors, as are the tensors of F16 and of
+// BF16.
+func (f *File) Dense(name string) (*tensor.Dense, error) {
+	if t := f.Info(name); t != nil && layouts[t.Type].decode != nil {
+		q, err := f.Quantized(name)
+		if err != nil {
+			return nil, err
+		}
+		return q.Dequantize()
+	}
+	t, l, p, err := f.raw(name)
+	if err != nil {
+		return nil, err
+	}
+	d, err := l.dense.Decode(p, binary.LittleEndian, t.Shape)
+	return d, errors.Wrapf(err, "Tensor %q", name)
+}
+
+/* the blocks, as in ggml-quants.c */
+
+func f16(b []byte) float32 { return weights.Float16(b) }
+
+func decodeQ4_0(b []byte, q []int8, scales, _ []float32) {
+	scales[0] = f16(b)
+	for j, v := range b[2:18] {
+		q[j] = int8(v&0xf) - 8
+		q[j+16] = int8(v>>4) - 8
+	}
+}
+
+func decodeQ4_1(b []byte, q []int8, scales, mins []float32) {
+	scales[0], mins[0] = f16(b), f16(b[2:])
+	for j, v := range b[4:20] {
+		q[j] = int8(v & 0xf)
+		q[j+16] = int8(v >> 4)
+	}
+}
+
+func decodeQ5_0(b []byte, q []int8, scales, _ []float32) {
+	scales[0] = f16(b)
+	qh := binary.LittleEndian.Uint32(b[2:])
+	for j, v := range b[6:22] {
+		h0 := byte((qh>>uint(j))<<4) & 0x10
+		h1 := byte(qh>>uint(j+12)) & 0x10
+		q[j] = int8(v&0xf|h0) - 16
+		q[j+16] = int8(v>>4|h1) - 16
+	}
+}
+
+func decodeQ5_1(b []byte, q []int8, scales, mins []float32) {
+	scales[0], mins[0] = f16(b), f16(b[2:])
+	qh := binary.LittleEndian.Uint32(b[4:])
+	for j, v := range b[8:24] {
+		h0 := byte((qh>>uint(j))<<4) & 0x10
+		h1 := byte(qh>>uint(j+12)) & 0x10
+		q[j] = int8(v&0xf | h0)
+		q[j+16] = int8(v>>4 | h1)
+	}
+}
+
+func decodeQ8_0(b []byte, q []int8, scales, _ []float32) {
+	scales[0] = f16(b)
+	for j, v := range b[2:34] {
+		q[j] = int8(v)
+	}
+}
+
+// scaleMinK4 returns the 6-bit scale and min of the j-th group of a super-block of Q4_K or Q5_K.
+func scaleMinK4(j int, s []byte) (sc, m byte) {
+	if j < 4 {
+		return s[j] & 63, s[j+4] & 63
+	}
+	return s[j+4]&0xf | (s[j-4]>>6)<<4, s[j+4]>>4 | (s[j]>>6)<<4
+}
+
+// the scales and the mins of the 8 groups of a super-block, whose mins are subtracted
+func kScales(b []byte, scales, mins []float32) {
+	d, dmin := f16(b), f16(b[2:])
+	for j := range scales {
+		sc, m := scaleMinK4(j, b[4:16])
+		scales[j] = d * float32(sc)
+		mins[j] = -dmin * float32(m)
+	}
+}
+
+func decodeQ4_K(b []byte, q []int8, scales, mins []float32) {
+	kScales(b, scales, mins)
+	qs := b[16:144]
+	for j := 0; j < 4; j++ {
+		for l, v := range qs[32*j : 32*(j+1)] {
+			q[64*j+l] = int8(v & 0xf)
+			q[64*j+32+l] = int8(v >> 4)
+		}
+	}
+}
+
+func decodeQ5_K(b []byte, q []int8, scales, mins []float32) {
+	kScales(b, scales, mins)
+	qh, qs := b[16:48], b[48:176]
+	for j := 0; j < 4; j++ {
+		u1, u2 := byte(1)<<uint(2*j), byte(2)<<uint(2*j)
+		for l, v := range qs[32*j : 32*(j+1)] {
+			lo, hi := int8(v&0xf), int8(v>>4)
+			if qh[l]&u1 != 0 {
+				lo += 16
+			}
+			if qh[l]&u2 != 0 {
+				hi += 16
+			}
+			q[64*j+l], q[64*j+32+l] = lo, hi
+		}
+	}
+}
+
+func decodeQ6_K(b []byte, q []int8, scales, _ []float32) {
+	ql, qh, sc := b[:128], b[128:192], b[192:208]
+	d := f16(b[208:])
+	for g := range scales {
+		scales[g] = d * float32(int8(sc[g]))
+	}
+	for n := 0; n < 2; n++ {
+		l4, h2, y := ql[64*n:], qh[32*n:], q[128*n:]
+		for l := 0; l < 32; l++ {
+			y[l] = int8(l4[l]&0xf|(h2[l]>>0&3)<<4) - 32
+			y[l+32] = int8(l4[l+32]&0xf|(h2[l]>>2&3)<<4) - 32
+			y[l+64] = int8(l4[l]>>4|(h2[l]>>4&3)<<4) - 32
+			y[l+96] = int8(l4[l+32]>>4|(h2[l]>>6&3)<<4) - 32
+		}
+	}
+}
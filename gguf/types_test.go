@@ -0,0 +1,277 @@
+package gguf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	G "gorgonia.org/gorgonia"
+	"gorgonia.org/tensor"
+)
+
+// The reference dequantizations, transcribed from the loops of dequantize_row_* of ggml-quants.c. y is the row of the
+// blocks of b.
+
+func refQ4_0(b []byte, y []float32) {
+	for i := 0; i < len(y)/32; i++ {
+		x := b[i*18:]
+		d := f16(x)
+		for j := 0; j < 16; j++ {
+			y[i*32+j] = float32(int(x[2+j]&0x0F)-8) * d
+			y[i*32+j+16] = float32(int(x[2+j]>>4)-8) * d
+		}
+	}
+}
+
+func refQ4_1(b []byte, y []float32) {
+	for i := 0; i < len(y)/32; i++ {
+		x := b[i*20:]
+		d, m := f16(x), f16(x[2:])
+		for j := 0; j < 16; j++ {
+			y[i*32+j] = float32(x[4+j]&0x0F)*d + m
+			y[i*32+j+16] = float32(x[4+j]>>4)*d + m
+		}
+	}
+}
+
+func refQ5_0(b []byte, y []float32) {
+	for i := 0; i < len(y)/32; i++ {
+		x := b[i*22:]
+		d := f16(x)
+		qh := binary.LittleEndian.Uint32(x[2:])
+		for j := 0; j < 16; j++ {
+			xh0 := byte(((qh >> uint(j)) << 4) & 0x10)
+			xh1 := byte((qh >> uint(j+12)) & 0x10)
+			y[i*32+j] = float32(int((x[6+j]&0x0F)|xh0)-16) * d
+			y[i*32+j+16] = float32(int((x[6+j]>>4)|xh1)-16) * d
+		}
+	}
+}
+
+func refQ5_1(b []byte, y []float32) {
+	for i := 0; i < len(y)/32; i++ {
+		x := b[i*24:]
+		d, m := f16(x), f16(x[2:])
+		qh := binary.LittleEndian.Uint32(x[4:])
+		for j := 0; j < 16; j++ {
+			xh0 := byte(((qh >> uint(j)) << 4) & 0x10)
+			xh1 := byte((qh >> uint(j+12)) & 0x10)
+			y[i*32+j] = float32((x[8+j]&0x0F)|xh0)*d + m
+			y[i*32+j+16] = float32((x[8+j]>>4)|xh1)*d + m
+		}
+	}
+}
+
+func refQ8_0(b []byte, y []float32) {
+	for i := 0; i < len(y)/32; i++ {
+		x := b[i*34:]
+		d := f16(x)
+		for j := 0; j < 32; j++ {
+			y[i*32+j] = float32(int8(x[2+j])) * d
+		}
+	}
+}
+
+func refScaleMinK4(j int, q []byte) (d, m byte) {
+	if j < 4 {
+		return q[j] & 63, q[j+4] & 63
+	}
+	return (q[j+4] & 0xF) | ((q[j-4] >> 6) << 4), (q[j+4] >> 4) | ((q[j] >> 6) << 4)
+}
+
+func refQ4_K(b []byte, y []float32) {
+	for i := 0; i < len(y)/256; i++ {
+		x := b[i*144:]
+		d, min := f16(x), f16(x[2:])
+		scales, q := x[4:16], x[16:144]
+		out := y[i*256:]
+		is := 0
+		for j := 0; j < 256; j += 64 {
+			sc, m := refScaleMinK4(is+0, scales)
+			d1, m1 := d*float32(sc), min*float32(m)
+			sc, m = refScaleMinK4(is+1, scales)
+			d2, m2 := d*float32(sc), min*float32(m)
+			for l := 0; l < 32; l++ {
+				out[j+l] = d1*float32(q[l]&0xF) - m1
+			}
+			for l := 0; l < 32; l++ {
+				out[j+32+l] = d2*float32(q[l]>>4) - m2
+			}
+			q = q[32:]
+			is += 2
+		}
+	}
+}
+
+func refQ5_K(b []byte, y []float32) {
+	for i := 0; i < len(y)/256; i++ {
+		x := b[i*176:]
+		d, min := f16(x), f16(x[2:])
+		scales, qh, ql := x[4:16], x[16:48], x[48:176]
+		out := y[i*256:]
+		is := 0
+		u1, u2 := byte(1), byte(2)
+		for j := 0; j < 256; j += 64 {
+			sc, m := refScaleMinK4(is+0, scales)
+			d1, m1 := d*float32(sc), min*float32(m)
+			sc, m = refScaleMinK4(is+1, scales)
+			d2, m2 := d*float32(sc), min*float32(m)
+			for l := 0; l < 32; l++ {
+				h := 0
+				if qh[l]&u1 != 0 {
+					h = 16
+				}
+				out[j+l] = d1*float32(int(ql[l]&0xF)+h) - m1
+			}
+			for l := 0; l < 32; l++ {
+				h := 0
+				if qh[l]&u2 != 0 {
+					h = 16
+				}
+				out[j+32+l] = d2*float32(int(ql[l]>>4)+h) - m2
+			}
+			ql = ql[32:]
+			is += 2
+			u1 <<= 2
+			u2 <<= 2
+		}
+	}
+}
+
+func refQ6_K(b []byte, y []float32) {
+	for i := 0; i < len(y)/256; i++ {
+		x := b[i*210:]
+		ql, qh, sc := x[0:128], x[128:192], x[192:208]
+		d := f16(x[208:])
+		out := y[i*256:]
+		for n := 0; n < 256; n += 128 {
+			for l := 0; l < 32; l++ {
+				is := l / 16
+				q1 := int8((ql[l+0]&0xF)|(((qh[l]>>0)&3)<<4)) - 32
+				q2 := int8((ql[l+32]&0xF)|(((qh[l]>>2)&3)<<4)) - 32
+				q3 := int8((ql[l+0]>>4)|(((qh[l]>>4)&3)<<4)) - 32
+				q4 := int8((ql[l+32]>>4)|(((qh[l]>>6)&3)<<4)) - 32
+				out[n+l+0] = d * float32(int8(sc[is+0])) * float32(q1)
+				out[n+l+32] = d * float32(int8(sc[is+2])) * float32(q2)
+				out[n+l+64] = d * float32(int8(sc[is+4])) * float32(q3)
+				out[n+l+96] = d * float32(int8(sc[is+6])) * float32(q4)
+			}
+			ql, qh, sc = ql[64:], qh[32:], sc[8:]
+		}
+	}
+}
+
+// blocks returns n random blocks of the type, whose half precision scales d (and dmin) are finite.
+func blocks(r *rand.Rand, typ Type, n int) []byte {
+	l := layouts[typ]
+	p := make([]byte, n*l.size)
+	r.Read(p)
+	var halves []int // the offsets of the halves in a block
+	switch typ {
+	case Q4_0, Q5_0, Q8_0:
+		halves = []int{0}
+	case Q4_1, Q5_1, Q4_K, Q5_K:
+		halves = []int{0, 2}
+	case Q6_K:
+		halves = []int{208}
+	}
+	for i := 0; i < n; i++ {
+		for _, o := range halves {
+			// an exponent of 2⁻⁸ to 2⁻¹, and a random sign and fraction
+			h := uint16(r.Intn(1<<10)) | uint16(7+r.Intn(8))<<10 | uint16(r.Intn(2))<<15
+			binary.LittleEndian.PutUint16(p[i*l.size+o:], h)
+		}
+	}
+	return p
+}
+
+func TestDequantize(t *testing.T) {
+	r := rand.New(rand.NewSource(1337))
+	refs := []struct {
+		typ Type
+		ref func(b []byte, y []float32)
+	}{
+		{Q4_0, refQ4_0}, {Q4_1, refQ4_1}, {Q5_0, refQ5_0}, {Q5_1, refQ5_1}, {Q8_0, refQ8_0},
+		{Q4_K, refQ4_K}, {Q5_K, refQ5_K}, {Q6_K, refQ6_K},
+	}
+	const rows, cols = 3, 512
+	var ts []savedTensor
+	for _, ref := range refs {
+		ts = append(ts, savedTensor{name: ref.typ.String(), dims: []int{cols, rows}, typ: ref.typ, data: blocks(r, ref.typ, rows*cols/layouts[ref.typ].block)})
+	}
+	f, err := Read(bytes.NewReader(file(3, 32, nil, ts)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, ref := range refs {
+		want := make([]float32, rows*cols)
+		ref.ref(ts[i].data, want)
+
+		q, err := f.Quantized(ref.typ.String())
+		if err != nil {
+			t.Errorf("%v: %v", ref.typ, err)
+			continue
+		}
+		assert.Equal(t, tensor.Shape{rows, cols}, q.Shape(), "%v", ref.typ)
+		assert.Equal(t, layouts[ref.typ].group, q.Group, "%v", ref.typ)
+		assert.Equal(t, layouts[ref.typ].mins, q.Mins != nil, "%v", ref.typ)
+		d, err := f.Dense(ref.typ.String())
+		if err != nil {
+			t.Errorf("%v: %v", ref.typ, err)
+			continue
+		}
+		assert.Equal(t, tensor.Shape{rows, cols}, d.Shape(), "%v", ref.typ)
+		assert.InDeltaSlice(t, want, d.Data(), 1e-6, "%v", ref.typ)
+	}
+}
+
+func TestQuantizedMatMul(t *testing.T) {
+	assert := assert.New(t)
+	r := rand.New(rand.NewSource(42))
+	const out, in = 4, 256
+	data := blocks(r, Q4_K, out*in/256)
+	f, err := Read(bytes.NewReader(file(3, 32, nil, []savedTensor{{name: "blk.0.attn_q.weight", dims: []int{in, out}, typ: Q4_K, data: data}})))
+	if err != nil {
+		t.Fatal(err)
+	}
+	q, err := f.Quantized("blk.0.attn_q.weight")
+	if err != nil {
+		t.Fatal(err)
+	}
+	xs := make([]float32, 2*in)
+	for i := range xs {
+		xs[i] = r.Float32() - 0.5
+	}
+
+	g := G.NewGraph()
+	w, err := G.NewQuantizedWeight(g, q, "blk.0.attn_q.weight")
+	if err != nil {
+		t.Fatal(err)
+	}
+	x := G.NewMatrix(g, G.Float32, G.WithShape(2, in), G.WithName("x"), G.WithValue(tensor.New(tensor.WithShape(2, in), tensor.WithBacking(xs))))
+	y, err := G.QuantizedMatMul(x, w)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := G.NewTapeMachine(g)
+	defer m.Close()
+	if err = m.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+
+	ws := make([]float32, out*in)
+	refQ4_K(data, ws)
+	want := make([]float32, 2*out)
+	for i := 0; i < 2; i++ {
+		for j := 0; j < out; j++ {
+			for k := 0; k < in; k++ {
+				want[i*out+j] += xs[i*in+k] * ws[j*in+k]
+			}
+		}
+	}
+	assert.Equal(tensor.Shape{2, out}, y.Shape())
+	assert.InDeltaSlice(want, y.Value().Data(), 1e-3)
+}
@@ -3,6 +3,7 @@ module gorgonia.org/gorgonia
 go 1.12
 
 require (
+	github.com/apache/arrow/go/arrow v0.0.0-20200909005831-30143fc493df
 	github.com/awalterschulze/gographviz v0.0.0-20190221210632-1e9ccb565bca
 	github.com/chewxy/hm v1.0.0
 	github.com/chewxy/math32 v1.0.6
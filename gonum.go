@@ -0,0 +1,95 @@
+package gorgonia
+
+import (
+	"reflect"
+
+	"github.com/pkg/errors"
+	"gonum.org/v1/gonum/mat"
+	"gorgonia.org/tensor"
+)
+
+// DenseFromMat returns a *tensor.Dense of the elements of a gonum matrix. A *mat.VecDense becomes a vector, and the
+// other matrices become matrices of the same dimensions.
+//
+// The *mat.Dense whose rows are contiguous and the *mat.VecDense of unit increment share their memory with the
+// returned tensor, so that writes to either are seen by the other. The other matrices, such as the submatrices of
+// Slice and the transposes, are copied.
+//
+// NewConstant and WithValue accept gonum matrices, and convert them with DenseFromMat:
+//		w := NewMatrix(g, Float64, WithName("w"), WithValue(m))
+func DenseFromMat(m mat.Matrix) *tensor.Dense {
+	switch mt := m.(type) {
+	case *mat.VecDense:
+		raw := mt.RawVector()
+		if raw.Inc == 1 {
+			return tensor.New(tensor.WithShape(raw.N), tensor.WithBacking(raw.Data[:raw.N]))
+		}
+		data := make([]float64, raw.N)
+		for i := range data {
+			data[i] = raw.Data[i*raw.Inc]
+		}
+		return tensor.New(tensor.WithShape(raw.N), tensor.WithBacking(data))
+	case *mat.Dense:
+		raw := mt.RawMatrix()
+		if raw.Stride == raw.Cols || raw.Rows == 1 {
+			return tensor.New(tensor.WithShape(raw.Rows, raw.Cols), tensor.WithBacking(raw.Data[:raw.Rows*raw.Cols]))
+		}
+	}
+
+	r, c := m.Dims()
+	data := make([]float64, 0, r*c)
+	for i := 0; i < r; i++ {
+		for j := 0; j < c; j++ {
+			data = append(data, m.At(i, j))
+		}
+	}
+	return tensor.New(tensor.WithShape(r, c), tensor.WithBacking(data))
+}
+
+// MatFromDense returns a *mat.Dense of the elements of a matrix. A contiguous Float64 tensor shares its memory with the
+// returned matrix. The other tensors, such as the transposes and the tensors of other Dtypes, are copied, and their
+// elements converted to float64.
+func MatFromDense(t tensor.Tensor) (*mat.Dense, error) {
+	if t.Dims() != 2 {
+		return nil, errors.Errorf("Expected a matrix. Got a tensor of shape %v instead", t.Shape())
+	}
+	data, err := float64Data(t)
+	if err != nil {
+		return nil, err
+	}
+	return mat.NewDense(t.Shape()[0], t.Shape()[1], data), nil
+}
+
+// VecFromDense returns a *mat.VecDense of the elements of a vector, which may be a row or column vector. It shares the
+// memory of the vector as MatFromDense does.
+func VecFromDense(t tensor.Tensor) (*mat.VecDense, error) {
+	if !t.Shape().IsVector() {
+		return nil, errors.Errorf("Expected a vector. Got a tensor of shape %v instead", t.Shape())
+	}
+	data, err := float64Data(t)
+	if err != nil {
+		return nil, err
+	}
+	return mat.NewVecDense(len(data), data), nil
+}
+
+// float64Data returns the backing slice of a contiguous Float64 tensor, or a copy of its elements converted to float64.
+func float64Data(t tensor.Tensor) ([]float64, error) {
+	if t.Dtype() == tensor.Float64 && !t.RequiresIterator() {
+		if data, ok := t.Data().([]float64); ok {
+			return data, nil
+		}
+	}
+	s, err := valueSlice(t)
+	if err != nil {
+		return nil, err
+	}
+	if data, ok := s.Interface().([]float64); ok {
+		return data, nil // the materialized copy
+	}
+	conv, err := castData(s.Interface(), reflect.TypeOf(float64(0)), false)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Cannot convert a tensor of %v to float64", t.Dtype())
+	}
+	return conv.([]float64), nil
+}
@@ -0,0 +1,90 @@
+package gorgonia
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gonum.org/v1/gonum/mat"
+	"gorgonia.org/tensor"
+)
+
+func TestDenseFromMat(t *testing.T) {
+	assert := assert.New(t)
+	m := mat.NewDense(2, 3, []float64{1, 2, 3, 4, 5, 6})
+	d := DenseFromMat(m)
+	assert.Equal(tensor.Shape{2, 3}, d.Shape())
+	m.Set(0, 0, 10)
+	assert.Equal(10.0, d.Float64s()[0], "a contiguous matrix is shared")
+
+	sub := DenseFromMat(m.Slice(0, 2, 1, 3))
+	assert.Equal(tensor.Shape{2, 2}, sub.Shape())
+	assert.Equal([]float64{2, 3, 5, 6}, sub.Data())
+	m.Set(0, 1, 20)
+	assert.Equal(2.0, sub.Float64s()[0], "a submatrix is copied")
+
+	tr := DenseFromMat(m.T())
+	assert.Equal(tensor.Shape{3, 2}, tr.Shape())
+	assert.Equal([]float64{10, 4, 20, 5, 3, 6}, tr.Data())
+
+	v := mat.NewVecDense(3, []float64{1, 2, 3})
+	dv := DenseFromMat(v)
+	assert.Equal(tensor.Shape{3}, dv.Shape())
+	v.SetVec(2, 30)
+	assert.Equal(30.0, dv.Float64s()[2], "a vector of unit increment is shared")
+	col := DenseFromMat(m.ColView(0))
+	assert.Equal(tensor.Shape{2}, col.Shape())
+	assert.Equal([]float64{10, 4}, col.Data(), "a column is strided, and copied")
+}
+
+func TestMatFromDense(t *testing.T) {
+	assert := assert.New(t)
+	d := tensor.New(tensor.WithShape(2, 3), tensor.WithBacking([]float64{1, 2, 3, 4, 5, 6}))
+	m, err := MatFromDense(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(5.0, m.At(1, 1))
+	m.Set(1, 1, 50)
+	assert.Equal(50.0, d.Float64s()[4], "a contiguous Float64 tensor is shared")
+
+	tr := d.Clone().(*tensor.Dense)
+	if err = tr.T(); err != nil {
+		t.Fatal(err)
+	}
+	if m, err = MatFromDense(tr); assert.NoError(err) {
+		r, c := m.Dims()
+		assert.Equal([]int{3, 2}, []int{r, c})
+		assert.Equal(4.0, m.At(0, 1))
+	}
+
+	f32 := tensor.New(tensor.WithShape(2, 2), tensor.WithBacking([]float32{1, 2, 3, 4}))
+	if m, err = MatFromDense(f32); assert.NoError(err) {
+		assert.Equal(3.0, m.At(1, 0))
+	}
+	_, err = MatFromDense(tensor.New(tensor.WithShape(2), tensor.WithBacking([]float64{1, 2})))
+	assert.Error(err)
+
+	v, err := VecFromDense(tensor.New(tensor.WithShape(3, 1), tensor.WithBacking([]int{1, 2, 3})))
+	if assert.NoError(err) {
+		assert.Equal(3, v.Len())
+		assert.Equal(2.0, v.AtVec(1))
+	}
+	_, err = VecFromDense(d)
+	assert.Error(err)
+}
+
+func TestNewConstant_mat(t *testing.T) {
+	assert := assert.New(t)
+	g := NewGraph()
+	a := NewConstant(mat.NewDense(2, 2, []float64{1, 2, 3, 4}), WithName("a"))
+	x := NewMatrix(g, Float64, WithName("x"), WithValue(mat.NewDense(2, 2, []float64{1, 0, 0, 1})))
+	assert.Equal(tensor.Shape{2, 2}, x.Shape())
+	ax := Must(Mul(a, x))
+
+	m := NewTapeMachine(g)
+	defer m.Close()
+	if err := m.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]float64{1, 2, 3, 4}, ax.Value().Data())
+}
@@ -295,7 +295,7 @@ func Set(a, b *Node) (retVal *Node) {
 // To achieve this, a pointer to a Value (*Value) is passed into this function, not a Value.
 // The 'into' value remains nil until the execution of the graph (via a call to the Run() methods of the VM)
 func Read(n *Node, into *Value) (retVal *Node) {
-	op := readOp{into}
+	op := readOp{into: into, id: uniqueOpID()}
 	name := fmt.Sprintf("read %v into %v", n, into)
 	retVal = NewUniqueNode(WithOp(op), WithChildren(Nodes{n}), WithName(name), In(n.g))
 	retVal.op = op // this ensures the correct pointer is written
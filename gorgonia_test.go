@@ -102,3 +102,19 @@ func TestRandomNodeBackprop(t *testing.T) {
 	vm.RunAll()
 	t.Logf("d.Value %v", d.Value())
 }
+
+func TestReadTwice(t *testing.T) {
+	g := NewGraph()
+	x := NewScalar(g, Float64, WithName("x"), WithValue(2.0))
+	y := Must(Square(x))
+	var a, b Value
+	Read(y, &a)
+	Read(y, &b)
+	m := NewTapeMachine(g)
+	defer m.Close()
+	if err := m.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 4.0, a.Data())
+	assert.Equal(t, 4.0, b.Data(), "the reads of the same node are not merged")
+}
@@ -0,0 +1,89 @@
+package gorgonia
+
+import "github.com/pkg/errors"
+
+// RBFKernel returns the kernel matrix of the radial basis function (or squared exponential) kernel between the rows of
+// x1 and x2:
+//		K[i, j] = variance · exp(-|x1[i] - x2[j]|² / (2·lengthscale²))
+// x1 and x2 are (n, d) and (m, d) matrices, and lengthscale and variance are scalars, all of the same float Dtype. The
+// kernel matrix is differentiable with regards to all of them.
+func RBFKernel(x1, x2, lengthscale, variance *Node) (*Node, error) {
+	return stationaryKernel(rbfKernel, x1, x2, lengthscale, variance)
+}
+
+// MaternKernel returns the kernel matrix of the Matérn kernel of smoothness nu, which must be 0.5, 1.5 or 2.5, between
+// the rows of x1 and x2. With u = |x1[i] - x2[j]| / lengthscale:
+//		ν = 1/2 :	K[i, j] = variance · exp(-u)
+//		ν = 3/2 :	K[i, j] = variance · (1 + √3u) · exp(-√3u)
+//		ν = 5/2 :	K[i, j] = variance · (1 + √5u + 5u²/3) · exp(-√5u)
+// The arguments are as in RBFKernel.
+func MaternKernel(x1, x2, lengthscale, variance *Node, nu float64) (*Node, error) {
+	var kind kernelKind
+	switch nu {
+	case 0.5:
+		kind = matern12Kernel
+	case 1.5:
+		kind = matern32Kernel
+	case 2.5:
+		kind = matern52Kernel
+	default:
+		return nil, errors.Errorf("Expected a smoothness of 0.5, 1.5 or 2.5. Got %v instead", nu)
+	}
+	return stationaryKernel(kind, x1, x2, lengthscale, variance)
+}
+
+// LinearKernel returns the kernel matrix of the linear kernel between the rows of x1 and x2:
+//		K = variance · x1·x2ᵀ
+func LinearKernel(x1, x2, variance *Node) (*Node, error) {
+	if err := checkKernelInputs(x1, x2, variance, variance); err != nil {
+		return nil, err
+	}
+	x2T, err := Transpose(x2)
+	if err != nil {
+		return nil, errors.Wrap(err, operationError)
+	}
+	k, err := Mul(x1, x2T)
+	if err != nil {
+		return nil, errors.Wrap(err, operationError)
+	}
+	return HadamardProd(variance, k)
+}
+
+// GPLogMarginalLikelihood returns the log marginal likelihood of the targets y of a Gaussian process regression with
+// the (n, n) kernel matrix k of the inputs, and the scalar noise variance:
+//		log p(y) = -½·yᵀ(k + noise·I)⁻¹y - ½·log|k + noise·I| - n/2·log 2π
+// It is computed from the Cholesky decomposition of k + noise·I, and is differentiable with regards to k, y and the
+// noise, so that the hyperparameters of the kernel can be fitted by maximizing it.
+func GPLogMarginalLikelihood(k, y, noise *Node) (*Node, error) {
+	for _, n := range []*Node{k, y, noise} {
+		if err := checkFloatNode(n); err != nil {
+			return nil, errors.Wrap(err, operationError)
+		}
+	}
+	if !noise.IsScalar() {
+		return nil, errors.Errorf("Expected a scalar noise. Got %v instead", noise.Shape())
+	}
+	return ApplyOp(gpLMLOp{}, k, y, noise)
+}
+
+func stationaryKernel(kind kernelKind, x1, x2, lengthscale, variance *Node) (*Node, error) {
+	if err := checkKernelInputs(x1, x2, lengthscale, variance); err != nil {
+		return nil, err
+	}
+	return ApplyOp(kernelOp{kind: kind}, x1, x2, lengthscale, variance)
+}
+
+func checkKernelInputs(x1, x2, lengthscale, variance *Node) error {
+	for _, n := range []*Node{x1, x2, lengthscale, variance} {
+		if err := checkFloatNode(n); err != nil {
+			return errors.Wrap(err, operationError)
+		}
+	}
+	if x1.Dims() != 2 || x2.Dims() != 2 {
+		return errors.Errorf("Expected the inputs of a kernel to be matrices. Got %v and %v instead", x1.Shape(), x2.Shape())
+	}
+	if !lengthscale.IsScalar() || !variance.IsScalar() {
+		return errors.Errorf("Expected scalar hyperparameters. Got %v and %v instead", lengthscale.Shape(), variance.Shape())
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package gorgonia
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorgonia.org/tensor"
+)
+
+func TestGPLogMarginalLikelihood(t *testing.T) {
+	assert := assert.New(t)
+	xs := []float64{-2, -1, 0, 1, 2}
+	ys := make([]float64, len(xs))
+	for i, x := range xs {
+		ys[i] = math.Sin(x)
+	}
+
+	g := NewGraph()
+	x := NewMatrix(g, Float64, WithShape(5, 1), WithName("x"), WithValue(tensor.New(tensor.WithShape(5, 1), tensor.WithBacking(xs))))
+	y := NewVector(g, Float64, WithShape(5), WithName("y"), WithValue(tensor.New(tensor.WithBacking(ys))))
+	lengthscale := NewScalar(g, Float64, WithName("lengthscale"), WithValue(0.5))
+	variance := NewScalar(g, Float64, WithName("variance"), WithValue(1.0))
+	noise := NewScalar(g, Float64, WithName("noise"), WithValue(0.01))
+
+	k, err := RBFKernel(x, x, lengthscale, variance)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(tensor.Shape{5, 5}, k.Shape())
+	lml, err := GPLogMarginalLikelihood(k, y, noise)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(lml.IsScalar())
+	grads, err := Grad(lml, lengthscale, variance, noise)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewTapeMachine(g)
+	defer m.Close()
+	// gradient ascent on the hyperparameters increases the likelihood
+	var first, last float64
+	for i := 0; i < 20; i++ {
+		m.Reset()
+		if err := m.RunAll(); err != nil {
+			t.Fatal(err)
+		}
+		last = lml.Value().Data().(float64)
+		if i == 0 {
+			first = last
+		}
+		for j, p := range []*Node{lengthscale, variance, noise} {
+			v := p.Value().Data().(float64) + 0.01*grads[j].Value().Data().(float64)
+			if err := Let(p, math.Max(v, 1e-3)); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	assert.True(last > first, "%v should be greater than %v", last, first)
+}
+
+func TestKernels(t *testing.T) {
+	assert := assert.New(t)
+	g := NewGraph()
+	x1 := NewMatrix(g, Float64, WithShape(2, 2), WithName("x1"), WithValue(tensor.New(tensor.WithShape(2, 2), tensor.WithBacking([]float64{1, 0, 0, 2}))))
+	x2 := NewMatrix(g, Float64, WithShape(3, 2), WithName("x2"), WithValue(tensor.New(tensor.WithShape(3, 2), tensor.WithBacking([]float64{1, 0, 1, 1, 3, 2}))))
+	lengthscale := NewScalar(g, Float64, WithName("lengthscale"), WithValue(1.0))
+	variance := NewScalar(g, Float64, WithName("variance"), WithValue(2.0))
+
+	matern, err := MaternKernel(x1, x2, lengthscale, variance, 2.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	linear, err := LinearKernel(x1, x2, variance)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cost := Must(Add(Must(Sum(matern)), Must(Sum(linear))))
+	if _, err := Grad(cost, x1, variance); err != nil {
+		t.Fatal(err)
+	}
+	m := NewTapeMachine(g)
+	defer m.Close()
+	if err := m.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(tensor.Shape{2, 3}, matern.Shape())
+	assert.InDelta(2, matern.Value().Data().([]float64)[0], 1e-12)
+	u := math.Sqrt(5)
+	assert.InDelta(2*(1+math.Sqrt(5)*u+5*u*u/3)*math.Exp(-math.Sqrt(5)*u), matern.Value().Data().([]float64)[3], 1e-12)
+	assert.Equal([]float64{2, 2, 6, 0, 4, 8}, linear.Value().Data())
+
+	_, err = MaternKernel(x1, x2, lengthscale, variance, 1)
+	assert.Error(err)
+	_, err = RBFKernel(x1, x2, x1, variance)
+	assert.Error(err, "the hyperparameters must be scalars")
+	_, err = RBFKernel(NewVector(g, Float64, WithShape(2)), x2, lengthscale, variance)
+	assert.Error(err)
+	_, err = LinearKernel(NewMatrix(g, Int, WithShape(2, 2)), x2, variance)
+	assert.Error(err)
+	_, err = GPLogMarginalLikelihood(matern, NewVector(g, Float64, WithShape(2)), x1)
+	assert.Error(err)
+}
@@ -0,0 +1,196 @@
+package gorgonia
+
+import (
+	"github.com/pkg/errors"
+	"gorgonia.org/tensor"
+)
+
+// GradAccumulator accumulates the gradients of a model over several forward/backward passes (micro-batches) before
+// a single solver step, so that the effective batch size is not limited by the memory available for one pass:
+//		acc := NewGradAccumulator(NodesToValueGrads(learnables))
+//		for i := 0; i < microBatches; i++ {
+//			// let the inputs of the i-th micro-batch
+//			if err := m.RunAll(); err != nil {
+//				...
+//			}
+//			if err := acc.Accumulate(); err != nil {
+//				...
+//			}
+//			m.Reset()
+//		}
+//		if err := acc.Step(solver); err != nil {
+//			...
+//		}
+//
+// By default, the accumulated gradients are averaged over the passes, which is correct when the cost of each pass is
+// a mean over its micro-batch. Use WithSummedGrads if the costs are sums.
+type GradAccumulator struct {
+	model  []ValueGrad
+	sums   []Value
+	count  int
+	summed bool
+}
+
+// GradAccumulatorOpt is a function that provides construction options for a GradAccumulator.
+type GradAccumulatorOpt func(*GradAccumulator)
+
+// WithSummedGrads makes the GradAccumulator sum the gradients of the passes instead of averaging them.
+func WithSummedGrads() GradAccumulatorOpt {
+	return func(a *GradAccumulator) { a.summed = true }
+}
+
+// NewGradAccumulator creates a GradAccumulator for the gradients of the model.
+func NewGradAccumulator(model []ValueGrad, opts ...GradAccumulatorOpt) *GradAccumulator {
+	a := &GradAccumulator{model: model}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
+}
+
+// Accumulate adds the current gradients of the model to the accumulated gradients. The accumulation buffers are
+// allocated on the first call, and reused afterwards.
+func (a *GradAccumulator) Accumulate() (err error) {
+	if a.sums == nil {
+		a.sums = make([]Value, len(a.model))
+	}
+	for i, n := range a.model {
+		var grad Value
+		if _, grad, err = extractWeightGrad(n); err != nil {
+			return err
+		}
+		if a.sums[i] == nil {
+			if a.sums[i], err = CloneValue(grad); err != nil {
+				return errors.Wrapf(err, cloneFail, grad)
+			}
+			continue
+		}
+		if a.count == 0 {
+			if _, err = Copy(a.sums[i], grad); err != nil {
+				return errors.Wrap(err, "Failed to copy gradient")
+			}
+			continue
+		}
+		if err = addInPlace(a.sums[i], grad); err != nil {
+			return err
+		}
+	}
+	a.count++
+	return nil
+}
+
+// Count returns the number of passes accumulated since the last reset.
+func (a *GradAccumulator) Count() int { return a.count }
+
+// Grads returns the accumulated gradients, in the order of the model, scaled as they will be passed to the solver.
+// The returned values are new values, and nil is returned if nothing has been accumulated.
+func (a *GradAccumulator) Grads() (retVal []Value, err error) {
+	if a.count == 0 {
+		return nil, nil
+	}
+	retVal = make([]Value, len(a.sums))
+	for i, sum := range a.sums {
+		if retVal[i], err = CloneValue(sum); err != nil {
+			return nil, errors.Wrapf(err, cloneFail, sum)
+		}
+		if err = scaleInPlace(retVal[i], a.scale()); err != nil {
+			return nil, err
+		}
+	}
+	return retVal, nil
+}
+
+// Step performs one step of the solver with the accumulated gradients, then resets the accumulator.
+func (a *GradAccumulator) Step(s Solver) (err error) {
+	if a.count == 0 {
+		return errors.New("Cannot step: no gradients have been accumulated")
+	}
+	model := make([]ValueGrad, len(a.model))
+	for i, n := range a.model {
+		if err = scaleInPlace(a.sums[i], a.scale()); err != nil {
+			return err
+		}
+		model[i] = accumulatedGrad{ValueGrad: n, grad: a.sums[i]}
+	}
+	err = s.Step(model)
+	a.Reset()
+	return err
+}
+
+// Reset discards the accumulated gradients. The accumulation buffers are kept for the next passes.
+func (a *GradAccumulator) Reset() { a.count = 0 }
+
+func (a *GradAccumulator) scale() float64 {
+	if a.summed {
+		return 1
+	}
+	return 1 / float64(a.count)
+}
+
+// accumulatedGrad is a ValueGrad whose gradient is the accumulated gradient.
+type accumulatedGrad struct {
+	ValueGrad
+	grad Value
+}
+
+func (n accumulatedGrad) Grad() (Value, error) { return n.grad, nil }
+
+func (n accumulatedGrad) Name() string {
+	if nm, ok := n.ValueGrad.(Namer); ok {
+		return nm.Name()
+	}
+	return ""
+}
+
+// addInPlace adds b to a, storing the result in a.
+func addInPlace(a, b Value) (err error) {
+	switch at := a.(type) {
+	case *F64:
+		bt, ok := b.(*F64)
+		if !ok {
+			return errors.Errorf("Expected *F64. Got %T instead", b)
+		}
+		*at += *bt
+	case *F32:
+		bt, ok := b.(*F32)
+		if !ok {
+			return errors.Errorf("Expected *F32. Got %T instead", b)
+		}
+		*at += *bt
+	case tensor.Tensor:
+		bt, ok := b.(tensor.Tensor)
+		if !ok {
+			return errors.Errorf("Expected a tensor. Got %T instead", b)
+		}
+		if _, err = tensor.Add(at, bt, tensor.UseUnsafe()); err != nil {
+			return errors.Wrap(err, addFail)
+		}
+	default:
+		return errors.Errorf(nyiTypeFail, "addInPlace", a)
+	}
+	return nil
+}
+
+// scaleInPlace multiplies a by s, storing the result in a.
+func scaleInPlace(a Value, s float64) (err error) {
+	if s == 1 {
+		return nil
+	}
+	switch at := a.(type) {
+	case *F64:
+		*at *= F64(s)
+	case *F32:
+		*at *= F32(s)
+	case tensor.Tensor:
+		var scale interface{} = s
+		if at.Dtype() == tensor.Float32 {
+			scale = float32(s)
+		}
+		if _, err = tensor.Mul(at, scale, tensor.UseUnsafe()); err != nil {
+			return errors.Wrap(err, pointWiseMulFail)
+		}
+	default:
+		return errors.Errorf(nyiTypeFail, "scaleInPlace", a)
+	}
+	return nil
+}
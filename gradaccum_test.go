@@ -0,0 +1,81 @@
+package gorgonia
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorgonia.org/tensor"
+)
+
+func TestGradAccumulator(t *testing.T) {
+	assert := assert.New(t)
+	g := NewGraph()
+	w := NewVector(g, Float64, WithShape(2), WithName("w"), WithValue(tensor.New(tensor.WithBacking([]float64{10, 10}))))
+	x := NewVector(g, Float64, WithShape(2), WithName("x"))
+	cost := Must(Sum(Must(HadamardProd(w, x))))
+	if _, err := Grad(cost, w); err != nil {
+		t.Fatal(err)
+	}
+	m := NewTapeMachine(g)
+	defer m.Close()
+
+	pass := func(acc *GradAccumulator, xs ...float64) {
+		if err := Let(x, tensor.New(tensor.WithBacking(xs))); err != nil {
+			t.Fatal(err)
+		}
+		if err := m.RunAll(); err != nil {
+			t.Fatal(err)
+		}
+		if err := acc.Accumulate(); err != nil {
+			t.Fatal(err)
+		}
+		m.Reset()
+	}
+
+	acc := NewGradAccumulator(NodesToValueGrads(Nodes{w}))
+	grads, err := acc.Grads()
+	assert.NoError(err)
+	assert.Nil(grads)
+	assert.Error(acc.Step(NewVanillaSolver()))
+
+	pass(acc, 1, 2)
+	pass(acc, 3, 4)
+	assert.Equal(2, acc.Count())
+	if grads, err = acc.Grads(); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]float64{2, 3}, grads[0].Data(), "gradients are averaged")
+
+	if err = acc.Step(NewVanillaSolver(WithLearnRate(1))); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]float64{8, 7}, w.Value().Data())
+	assert.Equal(0, acc.Count())
+
+	// the buffers are reused after a step
+	pass(acc, 5, 6)
+	if grads, err = acc.Grads(); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]float64{5, 6}, grads[0].Data())
+	acc.Reset()
+	assert.Equal(0, acc.Count())
+
+	summed := NewGradAccumulator(NodesToValueGrads(Nodes{w}), WithSummedGrads())
+	pass(summed, 1, 2)
+	pass(summed, 3, 4)
+	if grads, err = summed.Grads(); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]float64{4, 6}, grads[0].Data())
+}
+
+func TestAddScaleInPlace(t *testing.T) {
+	assert := assert.New(t)
+	a := newF32(1)
+	assert.NoError(addInPlace(a, newF32(2)))
+	assert.NoError(scaleInPlace(a, 0.5))
+	assert.Equal(float32(1.5), a.Data())
+	assert.Error(addInPlace(a, newF64(1)))
+	assert.Error(addInPlace(tensor.New(tensor.WithBacking([]float64{1})), a))
+}
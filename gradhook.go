@@ -0,0 +1,70 @@
+package gorgonia
+
+import "github.com/pkg/errors"
+
+// A GradHook transforms the gradient of a node during symbolic differentiation. It is given the node holding the sum
+// of the gradients flowing into the node, and returns the node to use as the gradient instead. The returned node must
+// have the same shape as grad.
+//
+// The returned gradient is the one that is backpropagated to the children of the node, and the one that Grad returns
+// if the node is one of the WRTs.
+type GradHook func(grad *Node) (*Node, error)
+
+// RegisterGradHook registers hooks on n. The hooks are applied in order when the gradient of n is computed by
+// Backpropagate (or Grad), so they must be registered before calling either. For example, to clip the gradient of a
+// layer's output, and print it:
+//		RegisterGradHook(h, ClipGrad(-1, 1), InspectGrad(func(v Value) { log.Printf("dh: %v", v) }))
+func RegisterGradHook(n *Node, hooks ...GradHook) {
+	n.gradHooks = append(n.gradHooks, hooks...)
+}
+
+// ClearGradHooks removes all the hooks registered on n.
+func ClearGradHooks(n *Node) { n.gradHooks = nil }
+
+// applyGradHooks applies the hooks registered on n to its gradient.
+func applyGradHooks(n, grad *Node) (retVal *Node, err error) {
+	retVal = grad
+	for _, hook := range n.gradHooks {
+		var hooked *Node
+		if hooked, err = hook(retVal); err != nil {
+			return nil, errors.Wrapf(err, "Gradient hook of %v failed", n)
+		}
+		if hooked == nil || !hooked.Shape().Eq(retVal.Shape()) {
+			return nil, errors.Errorf("Gradient hook of %v must return a node of shape %v. Got %v instead", n, retVal.Shape(), hooked)
+		}
+		retVal = hooked
+	}
+	return retVal, nil
+}
+
+// ScaleGrad returns a GradHook that multiplies the gradient by s.
+func ScaleGrad(s float64) GradHook {
+	return func(grad *Node) (*Node, error) {
+		c, err := floatConstant(grad.Dtype(), s)
+		if err != nil {
+			return nil, err
+		}
+		return HadamardProd(grad, c)
+	}
+}
+
+// ClipGrad returns a GradHook that clamps each element of the gradient to [min, max].
+func ClipGrad(min, max float64) GradHook {
+	return func(grad *Node) (*Node, error) {
+		if min > max {
+			return nil, errors.Errorf("Expected min <= max. Got %v and %v instead", min, max)
+		}
+		if err := checkFloatNode(grad); err != nil {
+			return nil, err
+		}
+		return ApplyOp(clipOp{min: min, max: max}, grad)
+	}
+}
+
+// InspectGrad returns a GradHook that calls fn with the value of the gradient every time it is computed, leaving the
+// gradient unchanged. The value passed to fn is a copy, which fn may keep.
+func InspectGrad(fn func(Value)) GradHook {
+	return func(grad *Node) (*Node, error) {
+		return ApplyOp(newInspectOp(fn), grad)
+	}
+}
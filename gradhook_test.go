@@ -0,0 +1,95 @@
+package gorgonia
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorgonia.org/tensor"
+)
+
+func gradHookGrad(t *testing.T, hooked func(x, h *Node)) []float64 {
+	g := NewGraph()
+	x := NewVector(g, Float64, WithShape(3), WithName("x"), WithValue(tensor.New(tensor.WithBacking([]float64{1, 2, 3}))))
+	h := Must(Square(x))
+	cost := Must(Sum(h))
+	hooked(x, h)
+	if _, err := Grad(cost, x); err != nil {
+		t.Fatal(err)
+	}
+	m := NewTapeMachine(g)
+	defer m.Close()
+	if err := m.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+	grad, err := x.Grad()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return grad.Data().([]float64)
+}
+
+func TestRegisterGradHook(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal([]float64{2, 4, 6}, gradHookGrad(t, func(x, h *Node) {}))
+
+	// hooks on an intermediate node affect the gradients of its children
+	assert.Equal([]float64{6, 12, 18}, gradHookGrad(t, func(x, h *Node) { RegisterGradHook(h, ScaleGrad(3)) }))
+
+	// hooks on a WRT change the gradient returned
+	assert.Equal([]float64{2, 3, 3}, gradHookGrad(t, func(x, h *Node) { RegisterGradHook(x, ClipGrad(-3, 3)) }))
+
+	// hooks are applied in order
+	assert.Equal([]float64{1, 1, 1}, gradHookGrad(t, func(x, h *Node) { RegisterGradHook(x, ClipGrad(0, 0.5), ScaleGrad(2)) }))
+
+	// cleared hooks are not applied
+	assert.Equal([]float64{2, 4, 6}, gradHookGrad(t, func(x, h *Node) {
+		RegisterGradHook(h, ScaleGrad(3))
+		ClearGradHooks(h)
+	}))
+
+	var seen []Value
+	record := func(v Value) { seen = append(seen, v) }
+	assert.Equal([]float64{2, 4, 6}, gradHookGrad(t, func(x, h *Node) {
+		RegisterGradHook(h, InspectGrad(record))
+		RegisterGradHook(x, InspectGrad(record))
+	}))
+	if assert.Equal(2, len(seen)) {
+		assert.Equal([]float64{1, 1, 1}, seen[0].Data())
+		assert.Equal([]float64{2, 4, 6}, seen[1].Data())
+	}
+
+	// hooks must keep the shape of the gradient
+	g := NewGraph()
+	x := NewVector(g, Float64, WithShape(3), WithName("x"))
+	cost := Must(Sum(Must(Square(x))))
+	RegisterGradHook(x, func(grad *Node) (*Node, error) { return Sum(grad) })
+	_, err := Grad(cost, x)
+	assert.Error(err)
+}
+
+func TestGradReversal(t *testing.T) {
+	assert := assert.New(t)
+	g := NewGraph()
+	x := NewVector(g, Float64, WithShape(3), WithName("x"), WithValue(tensor.New(tensor.WithBacking([]float64{1, 2, 3}))))
+	y := NewVector(g, Float64, WithShape(3), WithName("y"), WithValue(tensor.New(tensor.WithBacking([]float64{4, 5, 6}))))
+	z := NewVector(g, Float64, WithShape(3), WithName("z"), WithValue(tensor.New(tensor.WithBacking([]float64{2, 2, 2}))))
+	rx := Must(GradReversal(x, 0.5))
+	cost := Must(Add(Must(Sum(Must(HadamardProd(x, y)))), Must(Sum(Must(HadamardProd(rx, z))))))
+
+	if _, err := Grad(cost, x, z); err != nil {
+		t.Fatal(err)
+	}
+	m := NewTapeMachine(g)
+	defer m.Close()
+	if err := m.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]float64{1, 2, 3}, rx.Value().Data())
+	xGrad, _ := x.Grad()
+	assert.Equal([]float64{3, 4, 5}, xGrad.Data())
+	zGrad, _ := z.Grad()
+	assert.Equal([]float64{1, 2, 3}, zGrad.Data())
+
+	_, err := GradReversal(NewScalar(g, Int, WithName("i")), 1)
+	assert.Error(err)
+}
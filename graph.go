@@ -3,6 +3,7 @@ package gorgonia
 import (
 	"bytes"
 	"fmt"
+	"sync"
 
 	"github.com/awalterschulze/gographviz"
 	"gonum.org/v1/gonum/graph"
@@ -11,6 +12,10 @@ import (
 
 // ExprGraph is a data structure for a directed acyclic graph (of expressions). This structure is the main entry point
 // for Gorgonia.
+//
+// The nodes of a graph may be constructed from concurrent goroutines, such as the builders of the replicas of a model or
+// the workers of a search. The scopes (see Scope) and the differentiation (see Grad) are those of the whole graph, and
+// are not safe to enter concurrently.
 type ExprGraph struct {
 	name string
 
@@ -19,12 +24,24 @@ type ExprGraph struct {
 	byID   map[int64]int
 	byHash map[uint32]*Node
 	evac   map[uint32]Nodes
-	to     map[*Node]Nodes
+	to     *adjacency // the parents of the nodes
 
 	leaves    Nodes
 	constants Nodes
 	roots     Nodes
 	counter   uint
+
+	sorted Nodes       // the cache of Sort, reset whenever the graph changes
+	mu     *sync.Mutex // guards the construction of the graph. It is shared by the subgraphs
+
+	autoCast bool   // promote mixed Dtype binary operations instead of failing
+	scope    string // the current naming scope. See Scope()
+
+	seed   *int64 // the seed the random nodes derive their seeds from. See WithGraphSeed()
+	random int64  // the number of random nodes seeded from the graph seed
+
+	stacks     bool  // do the nodes record their construction (see WithConstructionStacks)
+	backwardOf *Node // the node being differentiated, in the anomaly detection mode
 }
 
 // graphconopt sets options
@@ -38,13 +55,34 @@ func WithGraphName(name string) graphconopt {
 	return f
 }
 
+// WithAutoCast is a ExprGraph construction option that enables automatic type promotion. When enabled, a binary
+// operation on nodes of differing Dtypes will have Cast ops inserted such that both operands are of the Dtype
+// returned by PromoteTypes. Without this option, mixing Dtypes is an error.
+func WithAutoCast() graphconopt {
+	f := func(g *ExprGraph) {
+		g.autoCast = true
+	}
+	return f
+}
+
+// WithGraphSeed is a ExprGraph construction option that seeds the random nodes created by RandomNormal, RandomUniform
+// and RandomBernoulli without a seed of their own. Every such node gets a seed derived from the graph seed and the
+// number of random nodes created before it, so that the same program builds the same random nodes.
+func WithGraphSeed(seed int64) graphconopt {
+	f := func(g *ExprGraph) {
+		g.seed = &seed
+	}
+	return f
+}
+
 // NewGraph creates a new graph. Duh
 func NewGraph(opts ...graphconopt) *ExprGraph {
 	g := &ExprGraph{
 		byID:   make(map[int64]int),
 		byHash: make(map[uint32]*Node),
 		evac:   make(map[uint32]Nodes),
-		to:     make(map[*Node]Nodes),
+		to:     newAdjacency(),
+		mu:     new(sync.Mutex),
 
 		leaves:    make(Nodes, 0, 64),
 		constants: make(Nodes, 0, 8),
@@ -61,6 +99,12 @@ func NewGraph(opts ...graphconopt) *ExprGraph {
 func (g *ExprGraph) Clone() interface{} {
 	g2 := new(ExprGraph)
 	g2.name = g.name
+	g2.autoCast = g.autoCast
+	g2.seed, g2.random = g.seed, g.random
+	g2.scope = g.scope
+	g2.counter = g.counter
+	g2.stacks = g.stacks
+	g2.mu = new(sync.Mutex)
 
 	mapping := make(map[*Node]*Node) // a map of old nodes to new nodes
 	g2.all = make(Nodes, len(g.all))
@@ -105,14 +149,14 @@ func (g *ExprGraph) Clone() interface{} {
 		}
 	}
 
-	g2.to = make(map[*Node]Nodes)
-	for k, v := range g.to {
-		to := mapping[k]
-		g2.to[to] = make(Nodes, len(v))
+	g2.to = newAdjacency()
+	g.to.each(func(k *Node, v Nodes) {
+		parents := make(Nodes, len(v))
 		for i, n := range v {
-			g2.to[to][i] = mapping[n]
+			parents[i] = mapping[n]
 		}
-	}
+		g2.to.set(mapping[k], parents)
+	})
 
 	g2.leaves = make(Nodes, len(g.leaves))
 	for i, n := range g.leaves {
@@ -135,9 +179,15 @@ func (g *ExprGraph) Clone() interface{} {
 
 // AddNode adds n to the graph. It panics if the added node ID matches an existing node ID.
 func (g *ExprGraph) AddNode(n *Node) (retVal *Node) {
+	g.lock()
+	defer g.unlock()
+	return g.addNode(n)
+}
+
+func (g *ExprGraph) addNode(n *Node) (retVal *Node) {
 	defer func() {
-		if _, ok := g.to[retVal]; !ok {
-			g.to[retVal] = nil
+		if _, ok := g.to.lookup(retVal); !ok {
+			g.to.set(retVal, nil)
 		}
 	}()
 	// check for node with the same name in the graph
@@ -190,6 +240,7 @@ func (g *ExprGraph) addToAll(n *Node) {
 		panic("HELP! trying to add nil")
 	}
 	g.all = append(g.all, n)
+	g.sorted = nil
 	n.id = int64(g.counter)
 	g.counter++
 }
@@ -197,7 +248,12 @@ func (g *ExprGraph) addToAll(n *Node) {
 // RemoveNode removes n from the graph, as well as any edges attached to it. If the node
 // is not in the graph it is a no-op.
 func (g *ExprGraph) RemoveNode(node graph.Node) {
-	n := node.(*Node)
+	g.lock()
+	defer g.unlock()
+	g.removeNode(node.(*Node))
+}
+
+func (g *ExprGraph) removeNode(n *Node) {
 	if n.id == -1 {
 		return // if it's -1, it was never in the graph to begin with
 	}
@@ -205,14 +261,21 @@ func (g *ExprGraph) RemoveNode(node graph.Node) {
 	hash := n.Hashcode()
 
 	delete(g.byHash, hash)
-	delete(g.to, n)
+	g.to.del(n)
 	g.evac[hash] = g.evac[hash].remove(n)
 	g.all = g.all.remove(n)
+	g.sorted = nil
 }
 
 // SetEdge adds e, an edge from one node to another. If the nodes do not exist, they are added.
 // It will panic if the IDs of the e.From and e.To are equal.
 func (g *ExprGraph) SetEdge(e graph.Edge) {
+	g.lock()
+	defer g.unlock()
+	g.setEdge(e)
+}
+
+func (g *ExprGraph) setEdge(e graph.Edge) {
 	from := e.From().(*Node)
 	to := e.To().(*Node)
 
@@ -221,15 +284,16 @@ func (g *ExprGraph) SetEdge(e graph.Edge) {
 	}
 
 	if !g.Has(from.ID()) {
-		from = g.AddNode(from)
+		from = g.addNode(from)
 	}
 
 	if !g.Has(to.ID()) {
-		to = g.AddNode(to)
+		to = g.addNode(to)
 	}
 
 	// g.to[to] = g.to[to].Add(from)
-	g.to[to] = append(g.to[to], from)
+	g.to.set(to, append(g.to.get(to), from))
+	g.sorted = nil
 }
 
 // Roots returns a list of nodes that are not children of any other nodes
@@ -239,18 +303,18 @@ func (g *ExprGraph) Roots() (retVal Nodes) {
 		return g.roots
 	}
 
-	for n, tos := range g.to {
+	g.to.each(func(n *Node, tos Nodes) {
 		if len(tos) == 0 {
 			retVal = append(retVal, n)
 		}
 		// if the root is a statement (typically a read), and it only has one child
 		if len(n.children) == 1 && n.isStmt {
 			child := n.children[0]
-			if len(g.to[child]) == 1 {
+			if len(g.to.get(child)) == 1 {
 				retVal = append(retVal, child)
 			}
 		}
-	}
+	})
 	g.roots = retVal
 	return retVal
 }
@@ -409,7 +473,7 @@ func (g *ExprGraph) ToDot() string {
 			if n.derivOf != nil {
 				id := fmt.Sprintf("Node_%p", n)
 				for _, derivOf := range n.derivOf {
-					if _, ok := g.to[derivOf]; !ok {
+					if _, ok := g.to.lookup(derivOf); !ok {
 						continue
 					}
 					ofID := fmt.Sprintf("Node_%p", derivOf)
@@ -492,7 +556,7 @@ func (g *ExprGraph) ToDot() string {
 func (g *ExprGraph) Edges() graph.Edges {
 	var edges []graph.Edge
 	for _, n := range g.all {
-		for _, toN := range g.to[n] {
+		for _, toN := range g.to.get(n) {
 			edges = append(edges, edge{
 				from: n,
 				to:   toN,
@@ -507,9 +571,10 @@ func (g *ExprGraph) Edges() graph.Edges {
 
 // other private methods
 func (g *ExprGraph) removeAllEdgesFrom(n *Node) {
-	for k, ns := range g.to {
-		g.to[k] = ns.remove(n)
-	}
+	g.sorted = nil
+	g.to.each(func(k *Node, ns Nodes) {
+		g.to.set(k, ns.remove(n))
+	})
 }
 
 /* Graph interface */
@@ -527,10 +592,8 @@ func (g *ExprGraph) Node(id int64) graph.Node {
 }
 
 func (g *ExprGraph) node(id int64) *Node {
-	if idx, ok := g.byID[id]; ok {
-		if idx >= len(g.all) {
-			return nil
-		}
+	// the indices are stale once nodes are removed
+	if idx, ok := g.byID[id]; ok && idx < len(g.all) && g.all[idx].id == id {
 		return g.all[idx]
 	}
 	for i, n := range g.all {
@@ -617,9 +680,9 @@ func (g *ExprGraph) To(nid int64) graph.Nodes {
 		return nil
 	}
 
-	ns := g.to[n]
+	ns := g.to.get(n)
 	ns = ns.Set()
-	g.to[n] = ns
+	g.to.set(n, ns)
 	return nodeToGraphNode(ns)
 }
 
@@ -631,7 +694,7 @@ func (g *ExprGraph) subgraph(ns Nodes, findMissing bool, opts ...Nodes) *ExprGra
 	// add missing stuff first
 	if findMissing {
 		for _, n := range ns {
-			for _, parent := range g.to[n] {
+			for _, parent := range g.to.get(n) {
 				if parent.isStmt {
 					roots = append(roots, parent)
 					ns = append(ns, parent)
@@ -644,7 +707,7 @@ func (g *ExprGraph) subgraph(ns Nodes, findMissing bool, opts ...Nodes) *ExprGra
 	allset := ns.mapSet()
 	if len(opts) == 0 {
 		for _, n := range ns {
-			if len(g.to[n]) == 0 {
+			if len(g.to.get(n)) == 0 {
 				if n.isStmt {
 					roots = append(roots, n.children[0])
 				} else {
@@ -654,7 +717,7 @@ func (g *ExprGraph) subgraph(ns Nodes, findMissing bool, opts ...Nodes) *ExprGra
 			}
 
 			var hasParent bool
-			for _, parent := range g.to[n] {
+			for _, parent := range g.to.get(n) {
 				if allset.Contains(parent) {
 					hasParent = true
 					break
@@ -698,6 +761,7 @@ func (g *ExprGraph) subgraph(ns Nodes, findMissing bool, opts ...Nodes) *ExprGra
 		leaves:    leaves,
 		constants: g.constants,
 		roots:     roots,
+		mu:        g.mu,
 	}
 
 	return retVal
@@ -761,3 +825,16 @@ func (e edge) From() graph.Node         { return e.from }
 func (e edge) To() graph.Node           { return e.to }
 func (e edge) ReversedEdge() graph.Edge { e.from, e.to = e.to, e.from; return e }
 func (e edge) Weight() float64          { return e.weight }
+
+// lock locks the construction of the graph.
+func (g *ExprGraph) lock() {
+	if g.mu != nil {
+		g.mu.Lock()
+	}
+}
+
+func (g *ExprGraph) unlock() {
+	if g.mu != nil {
+		g.mu.Unlock()
+	}
+}
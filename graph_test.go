@@ -29,8 +29,8 @@ func TestGraphBasics(t *testing.T) {
 
 	var correctTo Nodes
 	correctTo = Nodes{xy}
-	assert.Equal(correctTo, g.to[x])
-	assert.Equal(correctTo, g.to[y])
+	assert.Equal(correctTo, g.to.get(x))
+	assert.Equal(correctTo, g.to.get(y))
 
 	// test Uniquifying ability of ExprGraph
 	newX := g.AddNode(x)
@@ -42,8 +42,8 @@ func TestGraphBasics(t *testing.T) {
 	newXY := Must(Add(x, y))
 	correctTo = append(correctTo, xy) // note this is correct. .Set() will be called when graph.To() is called
 	assert.Equal(xy, newXY)
-	assert.Equal(correctTo, g.to[y])
-	assert.Equal(correctTo, g.to[x])
+	assert.Equal(correctTo, g.to.get(y))
+	assert.Equal(correctTo, g.to.get(x))
 
 	correctTo = Nodes{xy}
 	assert.Equal(correctTo, sliceNodesToNodes(graph.NodesOf(g.To(y.ID()))))
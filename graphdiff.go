@@ -0,0 +1,163 @@
+package gorgonia
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// GraphDiff holds the structural differences between two graphs. See Diff.
+type GraphDiff struct {
+	Removed Nodes        // nodes of the first graph that have no counterpart in the second graph
+	Added   Nodes        // nodes of the second graph that have no counterpart in the first graph
+	Changed []NodeChange // nodes that have a counterpart, but differ from it
+}
+
+// NodeChange describes how a node of the first graph differs from its counterpart in the second graph.
+type NodeChange struct {
+	A, B    *Node
+	Changes []string // human readable descriptions of the differences, e.g. "shape: (2, 3) → (2, 4)"
+}
+
+// Equal returns true if there are no differences.
+func (d *GraphDiff) Equal() bool {
+	return len(d.Removed) == 0 && len(d.Added) == 0 && len(d.Changed) == 0
+}
+
+func (d *GraphDiff) String() string {
+	var buf bytes.Buffer
+	for _, n := range d.Removed {
+		fmt.Fprintf(&buf, "- %v :: %v %v\n", n.Name(), n.t, n.shape)
+	}
+	for _, n := range d.Added {
+		fmt.Fprintf(&buf, "+ %v :: %v %v\n", n.Name(), n.t, n.shape)
+	}
+	for _, c := range d.Changed {
+		fmt.Fprintf(&buf, "~ %v\n", c.A.Name())
+		for _, change := range c.Changes {
+			fmt.Fprintf(&buf, "\t%v\n", change)
+		}
+	}
+	return buf.String()
+}
+
+// Diff reports the structural differences between the graphs a and b. Nodes are matched up in three ways:
+//		1. Nodes that are structurally equal (the same op, type, shape and children) are the same node.
+//		2. Remaining nodes with the same (user supplied) name are counterparts.
+//		3. Remaining nodes with the same kind of op, whose children are counterparts, are counterparts.
+// Counterparts that differ in type, shape, op (including the op's hyperparameters) or number of children are reported
+// as changed. Nodes without a counterpart are reported as removed or added.
+//
+// Diff is useful for debugging nondeterministic graph construction, and in tests:
+//		if d := Diff(expected, got); !d.Equal() {
+//			t.Errorf("Unexpected graph:\n%v", d)
+//		}
+func Diff(a, b *ExprGraph) *GraphDiff {
+	matched := make(map[*Node]*Node) // a → b
+	taken := make(map[*Node]bool)    // b nodes that have been matched
+
+	byHash := make(map[uint32]Nodes)
+	byName := make(map[string]Nodes)
+	for _, n := range b.all {
+		byHash[n.Hashcode()] = append(byHash[n.Hashcode()], n)
+		if n.name != "" {
+			byName[n.name] = append(byName[n.name], n)
+		}
+	}
+	match := func(n, m *Node) {
+		matched[n] = m
+		taken[m] = true
+	}
+
+	// structurally equal nodes
+	for _, n := range a.all {
+		for _, m := range byHash[n.Hashcode()] {
+			if !taken[m] && nodeEq(n, m) {
+				match(n, m)
+				break
+			}
+		}
+	}
+
+	// nodes with the same name
+	for _, n := range a.all {
+		if _, ok := matched[n]; ok || n.name == "" {
+			continue
+		}
+		for _, m := range byName[n.name] {
+			if !taken[m] && n.isInput() == m.isInput() {
+				match(n, m)
+				break
+			}
+		}
+	}
+
+	// nodes with the same kind of op over counterparts. Children are added to the graph before their parents,
+	// so the children of n have been matched up before n
+	for _, n := range a.all {
+		if _, ok := matched[n]; ok || n.isInput() {
+			continue
+		}
+		for _, m := range b.all {
+			if !taken[m] && !m.isInput() && sameOpOver(n, m, matched) {
+				match(n, m)
+				break
+			}
+		}
+	}
+
+	retVal := new(GraphDiff)
+	for _, n := range a.all {
+		m, ok := matched[n]
+		if !ok {
+			retVal.Removed = append(retVal.Removed, n)
+			continue
+		}
+		if changes := nodeChanges(n, m); len(changes) > 0 {
+			retVal.Changed = append(retVal.Changed, NodeChange{A: n, B: m, Changes: changes})
+		}
+	}
+	for _, m := range b.all {
+		if !taken[m] {
+			retVal.Added = append(retVal.Added, m)
+		}
+	}
+	return retVal
+}
+
+// sameOpOver checks that n and m have the same kind of op, and that their children are counterparts.
+func sameOpOver(n, m *Node, matched map[*Node]*Node) bool {
+	if fmt.Sprintf("%T", n.op) != fmt.Sprintf("%T", m.op) || len(n.children) != len(m.children) {
+		return false
+	}
+	for i, child := range n.children {
+		if matched[child] != m.children[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// nodeChanges describes how the node n differs from its counterpart m, without regards to their children.
+func nodeChanges(n, m *Node) (retVal []string) {
+	if !n.shape.Eq(m.shape) {
+		retVal = append(retVal, fmt.Sprintf("shape: %v → %v", n.shape, m.shape))
+	}
+	if !n.t.Eq(m.t) {
+		retVal = append(retVal, fmt.Sprintf("type: %v → %v", n.t, m.t))
+	}
+	switch {
+	case n.op == nil && m.op == nil:
+	case n.op == nil || m.op == nil:
+		retVal = append(retVal, fmt.Sprintf("op: %v → %v", n.op, m.op))
+	case n.op.Hashcode() != m.op.Hashcode():
+		if ns, ms := n.op.String(), m.op.String(); ns != ms {
+			retVal = append(retVal, fmt.Sprintf("op: %v → %v", ns, ms))
+		} else {
+			retVal = append(retVal, fmt.Sprintf("op: hyperparameters of %v differ", ns))
+		}
+	}
+	if len(n.children) != len(m.children) {
+		retVal = append(retVal, fmt.Sprintf("children: %d → %d", len(n.children), len(m.children)))
+	}
+	return
+}
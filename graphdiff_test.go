@@ -0,0 +1,59 @@
+package gorgonia
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiff(t *testing.T) {
+	assert := assert.New(t)
+	build := func(cols int, act func(*Node) (*Node, error), axis int, extra bool) *ExprGraph {
+		g := NewGraph()
+		x := NewMatrix(g, Float64, WithShape(2, 3), WithName("x"))
+		w := NewMatrix(g, Float64, WithShape(3, cols), WithName("w"))
+		xw := Must(Mul(x, w))
+		h := Must(act(xw))
+		s := Must(Sum(h, axis))
+		if extra {
+			Must(Neg(s))
+		}
+		return g
+	}
+
+	a := build(4, Tanh, 0, false)
+	d := Diff(a, build(4, Tanh, 0, false))
+	assert.True(d.Equal(), "%v", d)
+	assert.Equal("", d.String())
+
+	// changed shape of an input, and all that depends on it
+	d = Diff(a, build(5, Tanh, 0, false))
+	assert.False(d.Equal())
+	assert.Empty(d.Added)
+	assert.Empty(d.Removed)
+	assert.Equal(4, len(d.Changed), "%v", d)
+	assert.Equal("w", d.Changed[0].A.Name())
+	assert.Equal([]string{"shape: (3, 4) → (3, 5)"}, d.Changed[0].Changes)
+
+	// changed op
+	d = Diff(a, build(4, Sigmoid, 0, false))
+	assert.Equal(1, len(d.Changed), "%v", d)
+	assert.Equal([]string{"op: tanh → sigmoid"}, d.Changed[0].Changes)
+
+	// changed hyperparameters
+	d = Diff(a, build(4, Tanh, 1, false))
+	assert.Equal(1, len(d.Changed), "%v", d)
+	assert.Contains(d.Changed[0].Changes[0], "shape")
+	assert.Contains(d.Changed[0].Changes[1], "op")
+
+	// added and removed nodes
+	b := build(4, Tanh, 0, true)
+	d = Diff(a, b)
+	assert.Equal(1, len(d.Added), "%v", d)
+	assert.Empty(d.Removed)
+	assert.Empty(d.Changed)
+	d = Diff(b, a)
+	assert.Equal(1, len(d.Removed), "%v", d)
+	assert.Empty(d.Added)
+	assert.Contains(d.String(), "- ")
+}
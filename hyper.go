@@ -0,0 +1,118 @@
+package gorgonia
+
+import (
+	"github.com/pkg/errors"
+	"gorgonia.org/tensor"
+)
+
+/*
+This file holds the hyperparameters: the scalars, such as a dropout rate, a scale that depends on the learning rate or
+the weight of a loss, that are inputs of the graph rather than constants. A compiled machine loads the values of its
+inputs every time it runs, so a hyperparameter may be changed between two runs without rebuilding or recompiling the
+graph:
+
+		weight := gorgonia.NewHyperparameter(g, gorgonia.Float64, "aux_weight", 0.5)
+		cost := gorgonia.Must(gorgonia.Add(mainLoss, gorgonia.Must(gorgonia.Mul(auxLoss, weight))))
+		...
+		gorgonia.SetHyperparameter(weight, 0.1) // for the next runs
+
+The hyperparameters are frozen (see Freeze): they are not learnables, and no gradient flows into them.
+*/
+
+// NewHyperparameter returns a scalar input of the graph, of the dtype, holding the value.
+func NewHyperparameter(g *ExprGraph, dt tensor.Dtype, name string, value float64) *Node {
+	v, err := hyperValue(dt, value)
+	if err != nil {
+		panic(err)
+	}
+	n := NewScalar(g, dt, WithName(name), WithValue(v))
+	n.frozen = true
+	n.hyper = true
+	return n
+}
+
+// SetHyperparameter sets the value of a hyperparameter for the next runs of the machines of the graph.
+func SetHyperparameter(n *Node, value float64) error {
+	if !n.hyper {
+		return errors.Errorf("%v is not a hyperparameter", n)
+	}
+	v, err := hyperValue(n.Dtype(), value)
+	if err != nil {
+		return err
+	}
+	return Let(n, v)
+}
+
+// HyperparameterValue returns the value of a hyperparameter.
+func HyperparameterValue(n *Node) (float64, error) {
+	if !n.hyper {
+		return 0, errors.Errorf("%v is not a hyperparameter", n)
+	}
+	switch v := n.Value().(type) {
+	case *F64:
+		return float64(*v), nil
+	case *F32:
+		return float64(*v), nil
+	}
+	return 0, errors.Errorf("%v holds no value", n)
+}
+
+// IsHyperparameter returns true if the node was created by NewHyperparameter.
+func (n *Node) IsHyperparameter() bool { return n.hyper }
+
+// Hyperparameters returns the hyperparameters of the graph, in the order they were added to the graph.
+func (g *ExprGraph) Hyperparameters() Nodes {
+	var retVal Nodes
+	for _, n := range g.AllNodes() {
+		if n.hyper {
+			retVal = append(retVal, n)
+		}
+	}
+	return retVal
+}
+
+// DropoutWith is Dropout, with the probability of dropping an element given by a scalar node, such as a
+// hyperparameter, so that it may be changed between runs. Unlike Dropout, the graph holds the dropout even if the
+// probability is 0.
+func DropoutWith(x, dropProb *Node) (retVal *Node, err error) {
+	if !dropProb.IsScalar() {
+		return nil, errors.Errorf("Expected the probability of dropout to be a scalar. Got %v", dropProb.Shape())
+	}
+	var dt tensor.Dtype
+	if dt, err = dtypeOf(x.t); err != nil {
+		return nil, errors.Wrap(err, dtypeOfFail)
+	}
+	if dt != Float64 && dt != Float32 {
+		return nil, errors.Errorf(nyiTypeFail, "DropoutWith()", dt)
+	}
+
+	var keepProb *Node
+	if keepProb, err = Sub(NewConstant(hyperOne(dt), In(x.g)), dropProb); err != nil {
+		return nil, errors.Wrap(err, subFail)
+	}
+	m := UniformRandomNode(x.g, dt, 0, 1, x.shape...)
+	if retVal, err = Lt(m, keepProb, true); err != nil {
+		return nil, errors.Wrap(err, "Greater Than failed")
+	}
+	if retVal, err = HadamardProd(x, retVal); err != nil {
+		return nil, errors.Wrap(err, mulFail)
+	}
+	return HadamardDiv(retVal, keepProb)
+}
+
+func hyperValue(dt tensor.Dtype, value float64) (Value, error) {
+	switch dt {
+	case Float64:
+		return newF64(value), nil
+	case Float32:
+		return newF32(float32(value)), nil
+	}
+	return nil, errors.Errorf(nyiTypeFail, "hyperparameters", dt)
+}
+
+func hyperOne(dt tensor.Dtype) Value {
+	if dt == Float32 {
+		return newF32(1)
+	}
+	return newF64(1)
+}
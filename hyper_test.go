@@ -0,0 +1,58 @@
+package gorgonia
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorgonia.org/tensor"
+)
+
+func TestHyperparameter(t *testing.T) {
+	assert := assert.New(t)
+	g := NewGraph()
+	x := NewVector(g, Float64, WithShape(4), WithName("x"), WithValue(tensor.New(tensor.WithBacking([]float64{1, 2, 3, 4}))))
+	weight := NewHyperparameter(g, Float64, "weight", 0.5)
+	rate := NewHyperparameter(g, Float64, "rate", 0)
+	dropped := Must(DropoutWith(x, rate))
+	cost := Must(Mul(Must(Sum(dropped)), weight))
+	if _, err := Grad(cost, x); err != nil {
+		t.Fatal(err)
+	}
+	learnables, hypers := g.Learnables(), g.Hyperparameters()
+	assert.True(learnables.Contains(x) && !learnables.Contains(weight) && !learnables.Contains(rate), "%v", learnables)
+	assert.True(len(hypers) == 2 && hypers[0] == weight && hypers[1] == rate, "%v", hypers)
+	assert.Nil(weight.Deriv())
+
+	var costVal, gradVal Value
+	Read(cost, &costVal)
+	Read(x.Deriv(), &gradVal)
+	m := NewTapeMachine(g)
+	defer m.Close()
+	if err := m.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(5.0, costVal.Data())
+	assert.Equal([]float64{0.5, 0.5, 0.5, 0.5}, gradVal.Data())
+
+	// the hyperparameters change between runs of the same machine
+	if err := SetHyperparameter(weight, 2); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetHyperparameter(rate, 0.5); err != nil {
+		t.Fatal(err)
+	}
+	m.Reset()
+	if err := m.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+	for _, g := range gradVal.Data().([]float64) {
+		assert.True(g == 0 || g == 4, "%v", g)
+	}
+	w, err := HyperparameterValue(weight)
+	assert.NoError(err)
+	assert.Equal(2.0, w)
+
+	assert.Error(SetHyperparameter(x, 1))
+	_, err = DropoutWith(x, x)
+	assert.Error(err)
+}
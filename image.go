@@ -0,0 +1,269 @@
+package gorgonia
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/pkg/errors"
+	"gorgonia.org/tensor"
+)
+
+// Layout is the order of the axes of a batch of images.
+type Layout byte
+
+const (
+	// NCHW is the batch, channel, height, width order. It is the default.
+	NCHW Layout = iota
+	// NHWC is the batch, height, width, channel order, in which the channels of a pixel are next to each other.
+	NHWC
+)
+
+func (l Layout) String() string {
+	switch l {
+	case NCHW:
+		return "NCHW"
+	case NHWC:
+		return "NHWC"
+	}
+	return fmt.Sprintf("Layout(%d)", byte(l))
+}
+
+// ImageOpt is an option of ImagesToTensor and TensorToImages.
+type ImageOpt func(*imageConv)
+
+type imageConv struct {
+	layout   Layout
+	channels int
+	dt       tensor.Dtype
+}
+
+// WithLayout sets the order of the axes of the tensor. By default it is NCHW.
+func WithLayout(l Layout) ImageOpt { return func(c *imageConv) { c.layout = l } }
+
+// WithChannels sets the number of channels that the images are converted to: 1 for gray, 3 for RGB and 4 for RGBA. By
+// default it is 3.
+func WithChannels(n int) ImageOpt { return func(c *imageConv) { c.channels = n } }
+
+// WithImageDtype sets the Dtype of the tensor: Float32 or Float64, whose values are in [0, 1], or Uint8. By default it
+// is Float32.
+func WithImageDtype(dt tensor.Dtype) ImageOpt { return func(c *imageConv) { c.dt = dt } }
+
+func newImageConv(opts []ImageOpt) (c imageConv, err error) {
+	c = imageConv{layout: NCHW, channels: 3, dt: tensor.Float32}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	switch {
+	case c.layout != NCHW && c.layout != NHWC:
+		return c, errors.Errorf("Unknown image layout %v", c.layout)
+	case c.channels != 1 && c.channels != 3 && c.channels != 4:
+		return c, errors.Errorf("Expected 1, 3 or 4 channels. Got %d instead", c.channels)
+	case c.dt != tensor.Float32 && c.dt != tensor.Float64 && c.dt != tensor.Uint8:
+		return c, errors.Errorf(nyiTypeFail, "image conversion", c.dt)
+	}
+	return c, nil
+}
+
+// ImageToTensor converts an image into a batch of one image. See ImagesToTensor.
+func ImageToTensor(img image.Image, opts ...ImageOpt) (*tensor.Dense, error) {
+	return ImagesToTensor([]image.Image{img}, opts...)
+}
+
+// ImagesToTensor converts images of the same size into a tensor of shape (n, c, h, w), or (n, h, w, c) with NHWC. The
+// gray channel is that of color.GrayModel, the RGB channels are premultiplied by the alpha as in image.RGBA, and the RGBA
+// channels are not, as in image.NRGBA. The pixels of *image.Gray, *image.RGBA and *image.NRGBA are read directly, and
+// the other images through their color model.
+func ImagesToTensor(imgs []image.Image, opts ...ImageOpt) (*tensor.Dense, error) {
+	c, err := newImageConv(opts)
+	if err != nil {
+		return nil, err
+	}
+	if len(imgs) == 0 {
+		return nil, errors.New("Expected at least one image")
+	}
+	b := imgs[0].Bounds()
+	h, w, ch := b.Dy(), b.Dx(), c.channels
+	for _, img := range imgs[1:] {
+		if img.Bounds().Dx() != w || img.Bounds().Dy() != h {
+			return nil, errors.Errorf("Expected images of %dx%d. Got an image of %dx%d instead", w, h, img.Bounds().Dx(), img.Bounds().Dy())
+		}
+	}
+
+	// the pixels are read in the NHWC order, then reordered and converted in one pass
+	size := h * w * ch
+	pix := make([]uint8, len(imgs)*size)
+	for i, img := range imgs {
+		readPixels(img, ch, pix[i*size:(i+1)*size])
+	}
+
+	shape := tensor.Shape{len(imgs), h, w, ch}
+	if c.layout == NCHW {
+		shape = tensor.Shape{len(imgs), ch, h, w}
+	}
+	if c.dt == tensor.Uint8 && c.layout == NHWC {
+		return tensor.New(tensor.WithShape(shape...), tensor.WithBacking(pix)), nil
+	}
+	index := func(p int) int { return p }
+	if c.layout == NCHW {
+		index = func(p int) int {
+			n, q := p/size, p%size
+			return n*size + q%ch*h*w + q/ch
+		}
+	}
+	var backing interface{}
+	switch c.dt {
+	case tensor.Float32:
+		data := make([]float32, len(pix))
+		for p, v := range pix {
+			data[index(p)] = float32(v) / 255
+		}
+		backing = data
+	case tensor.Float64:
+		data := make([]float64, len(pix))
+		for p, v := range pix {
+			data[index(p)] = float64(v) / 255
+		}
+		backing = data
+	default:
+		data := make([]uint8, len(pix))
+		for p, v := range pix {
+			data[index(p)] = v
+		}
+		backing = data
+	}
+	return tensor.New(tensor.WithShape(shape...), tensor.WithBacking(backing)), nil
+}
+
+// readPixels writes the pixels of img into pix, with ch channels per pixel.
+func readPixels(img image.Image, ch int, pix []uint8) {
+	b := img.Bounds()
+	w := b.Dx()
+	switch im := img.(type) {
+	case *image.Gray:
+		if ch == 1 {
+			for y := 0; y < b.Dy(); y++ {
+				copy(pix[y*w:(y+1)*w], im.Pix[im.PixOffset(b.Min.X, b.Min.Y+y):])
+			}
+			return
+		}
+	case *image.RGBA:
+		if ch == 3 {
+			for y := 0; y < b.Dy(); y++ {
+				row := im.Pix[im.PixOffset(b.Min.X, b.Min.Y+y):]
+				for x := 0; x < w; x++ {
+					copy(pix[(y*w+x)*3:(y*w+x)*3+3], row[x*4:x*4+3])
+				}
+			}
+			return
+		}
+	case *image.NRGBA:
+		if ch == 4 {
+			for y := 0; y < b.Dy(); y++ {
+				copy(pix[y*w*4:(y+1)*w*4], im.Pix[im.PixOffset(b.Min.X, b.Min.Y+y):])
+			}
+			return
+		}
+	}
+
+	p := 0
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			switch ch {
+			case 1:
+				pix[p] = color.GrayModel.Convert(img.At(x, y)).(color.Gray).Y
+			case 3:
+				r, g, bl, _ := img.At(x, y).RGBA()
+				pix[p], pix[p+1], pix[p+2] = uint8(r>>8), uint8(g>>8), uint8(bl>>8)
+			case 4:
+				c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+				pix[p], pix[p+1], pix[p+2], pix[p+3] = c.R, c.G, c.B, c.A
+			}
+			p += ch
+		}
+	}
+}
+
+// TensorToImages converts a tensor of shape (n, c, h, w), or (n, h, w, c) with NHWC, into images: *image.Gray for 1
+// channel, *image.RGBA for 3 and *image.NRGBA for 4. A tensor of 3 dimensions is one image. The floats are clamped to
+// [0, 1]. The number of channels is read from the shape, so WithChannels is ignored.
+func TensorToImages(t tensor.Tensor, opts ...ImageOpt) ([]image.Image, error) {
+	c, err := newImageConv(opts)
+	if err != nil {
+		return nil, err
+	}
+	shape := t.Shape().Clone()
+	switch shape.Dims() {
+	case 3:
+		shape = append(tensor.Shape{1}, shape...)
+	case 4:
+	default:
+		return nil, errors.Errorf("Expected a tensor of 3 or 4 dimensions. Got a tensor of shape %v instead", t.Shape())
+	}
+	n, ch, h, w := shape[0], shape[1], shape[2], shape[3]
+	if c.layout == NHWC {
+		h, w, ch = shape[1], shape[2], shape[3]
+	}
+	if ch != 1 && ch != 3 && ch != 4 {
+		return nil, errors.Errorf("Expected 1, 3 or 4 channels. Got a tensor of shape %v in %v instead", t.Shape(), c.layout)
+	}
+
+	s, err := valueSlice(t)
+	if err != nil {
+		return nil, err
+	}
+	var at func(i int) uint8
+	switch data := s.Interface().(type) {
+	case []float32:
+		at = func(i int) uint8 { return unitToUint8(float64(data[i])) }
+	case []float64:
+		at = func(i int) uint8 { return unitToUint8(data[i]) }
+	case []uint8:
+		at = func(i int) uint8 { return data[i] }
+	default:
+		return nil, errors.Errorf(nyiTypeFail, "TensorToImages", t.Dtype())
+	}
+
+	size := h * w * ch
+	retVal := make([]image.Image, n)
+	for i := range retVal {
+		rect := image.Rect(0, 0, w, h)
+		var pix []uint8
+		stride := ch
+		switch ch {
+		case 1:
+			im := image.NewGray(rect)
+			pix, retVal[i] = im.Pix, im
+		case 3:
+			im := image.NewRGBA(rect)
+			pix, retVal[i], stride = im.Pix, im, 4
+			for p := 3; p < len(pix); p += 4 {
+				pix[p] = 255
+			}
+		case 4:
+			im := image.NewNRGBA(rect)
+			pix, retVal[i] = im.Pix, im
+		}
+		for q := 0; q < size; q++ {
+			px, k := q/ch, q%ch // the pixel and channel in the NHWC order
+			src := i*size + q
+			if c.layout == NCHW {
+				src = i*size + k*h*w + px
+			}
+			pix[px*stride+k] = at(src)
+		}
+	}
+	return retVal, nil
+}
+
+// unitToUint8 converts a value in [0, 1] to a byte.
+func unitToUint8(v float64) uint8 {
+	switch {
+	case v <= 0 || math.IsNaN(v):
+		return 0
+	case v >= 1:
+		return 255
+	}
+	return uint8(v*255 + 0.5)
+}
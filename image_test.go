@@ -0,0 +1,88 @@
+package gorgonia
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorgonia.org/tensor"
+)
+
+func testImage() *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	img.SetNRGBA(0, 0, color.NRGBA{255, 0, 51, 255})
+	img.SetNRGBA(1, 0, color.NRGBA{0, 102, 255, 255})
+	return img
+}
+
+func TestImagesToTensor(t *testing.T) {
+	assert := assert.New(t)
+	img := testImage()
+
+	nchw, err := ImageToTensor(img)
+	if assert.NoError(err) {
+		assert.Equal(tensor.Shape{1, 3, 1, 2}, nchw.Shape())
+		assert.Equal([]float32{1, 0, 0, 0.4, 0.2, 1}, nchw.Data())
+	}
+	nhwc, err := ImageToTensor(img, WithLayout(NHWC), WithImageDtype(tensor.Uint8), WithChannels(4))
+	if assert.NoError(err) {
+		assert.Equal(tensor.Shape{1, 1, 2, 4}, nhwc.Shape())
+		assert.Equal(img.Pix, nhwc.Data())
+	}
+	gray, err := ImageToTensor(img, WithChannels(1), WithImageDtype(tensor.Uint8))
+	if assert.NoError(err) {
+		assert.Equal(tensor.Shape{1, 1, 1, 2}, gray.Shape())
+		assert.Equal(color.GrayModel.Convert(img.At(0, 0)).(color.Gray).Y, gray.Data().([]uint8)[0])
+	}
+
+	// the fast paths and the color models agree
+	rgba := image.NewRGBA(img.Bounds())
+	for x := 0; x < 2; x++ {
+		rgba.Set(x, 0, img.At(x, 0))
+	}
+	batch, err := ImagesToTensor([]image.Image{rgba, img}, WithImageDtype(tensor.Float64))
+	if assert.NoError(err) {
+		assert.Equal(tensor.Shape{2, 3, 1, 2}, batch.Shape())
+		data := batch.Float64s()
+		assert.Equal(data[:6], data[6:])
+	}
+
+	_, err = ImagesToTensor([]image.Image{img, image.NewGray(image.Rect(0, 0, 3, 3))})
+	assert.Error(err, "the sizes differ")
+	_, err = ImageToTensor(img, WithChannels(2))
+	assert.Error(err)
+	_, err = ImageToTensor(img, WithImageDtype(tensor.Int))
+	assert.Error(err)
+}
+
+func TestTensorToImages(t *testing.T) {
+	assert := assert.New(t)
+	img := testImage()
+	for _, l := range []Layout{NCHW, NHWC} {
+		for _, ch := range []int{1, 3, 4} {
+			tt, err := ImageToTensor(img, WithLayout(l), WithChannels(ch))
+			if err != nil {
+				t.Fatal(err)
+			}
+			imgs, err := TensorToImages(tt, WithLayout(l))
+			if !assert.NoError(err) || !assert.Len(imgs, 1) {
+				continue
+			}
+			back, err := ImageToTensor(imgs[0], WithLayout(l), WithChannels(ch))
+			if assert.NoError(err) {
+				assert.Equal(tt.Data(), back.Data(), "%v with %d channels", l, ch)
+			}
+		}
+	}
+
+	rgb := tensor.New(tensor.WithShape(1, 1, 3), tensor.WithBacking([]float64{-1, 0.5, 2}))
+	imgs, err := TensorToImages(rgb, WithLayout(NHWC))
+	if assert.NoError(err) {
+		assert.Equal(color.RGBA{0, 128, 255, 255}, imgs[0].At(0, 0), "the floats are clamped")
+	}
+	_, err = TensorToImages(tensor.New(tensor.WithShape(2, 2), tensor.WithBacking([]float64{1, 2, 3, 4})))
+	assert.Error(err)
+	_, err = TensorToImages(tensor.New(tensor.WithShape(1, 2, 1, 1), tensor.WithBacking([]float64{1, 2})))
+	assert.Error(err, "2 channels")
+}
@@ -0,0 +1,390 @@
+package gorgonia
+
+import (
+	"encoding/json"
+	"io"
+	"math"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+/*
+This file holds the instrumentation of the training, for the diagnosis of the vanishing and exploding gradients. A
+StatsRecorder reads the outputs of the layers of the graph (the last forward op of every scope, see Scope), their
+gradients, and the weights and gradients of the learnables, and summarizes them after a run:
+
+		if _, err = Grad(cost, learnables...); err != nil {
+			...
+		}
+		rec, err := Instrument(g, WithSummaryWriter(NewJSONSummaryWriter(f)), WithStatsEvery(100))
+		...
+		m := NewTapeMachine(g) // after Instrument, which adds nodes to the graph
+		for step := 0; step < steps; step++ {
+			if err = m.RunAll(); err != nil {
+				...
+			}
+			if err = rec.Record(step); err != nil {
+				...
+			}
+			solver.Step(model)
+			m.Reset()
+		}
+
+The activations are copied when they are computed, so a recorded step costs a copy of the outputs of the layers.
+*/
+
+// SummaryWriter receives the summaries of the training by tag, such as "dense1/activations/std".
+type SummaryWriter interface {
+	WriteScalar(tag string, step int, v float64) error
+	WriteHistogram(tag string, step int, h HistogramSummary) error
+}
+
+// HistogramSummary is a histogram of values: Counts[i] values are in [Edges[i], Edges[i+1]), the last bin including
+// its upper edge.
+type HistogramSummary struct {
+	Edges  []float64 `json:"edges"`
+	Counts []float64 `json:"counts"`
+}
+
+// TensorStats are the statistics of the elements of a value. The non-finite elements are counted, and excluded from
+// the other statistics.
+type TensorStats struct {
+	Mean, Std float64
+	Min, Max  float64
+	Norm      float64 // the L2 norm
+	Zeros     float64 // the fraction of elements that are 0, such as the dead units of a ReLU
+	NonFinite int
+	Histogram HistogramSummary
+}
+
+// LayerStats are the statistics of the output of a layer, and of its gradient if it has one.
+type LayerStats struct {
+	Layer        string
+	Node         *Node
+	Activations  TensorStats
+	Gradients    TensorStats
+	HasGradients bool
+}
+
+// ParamStats are the statistics of a learnable and of its gradient.
+type ParamStats struct {
+	Node      *Node
+	Layer     string
+	Weights   TensorStats
+	Gradients TensorStats
+}
+
+// StatsSnapshot is what a StatsRecorder recorded at a step.
+type StatsSnapshot struct {
+	Step   int
+	Layers []LayerStats
+	Params []ParamStats
+}
+
+// StatsRecorderOpt is a function that provides construction options for a StatsRecorder.
+type StatsRecorderOpt func(*StatsRecorder)
+
+// WithSummaryWriter makes the StatsRecorder write the statistics it records.
+func WithSummaryWriter(w SummaryWriter) StatsRecorderOpt {
+	return func(r *StatsRecorder) { r.w = w }
+}
+
+// WithStatsEvery makes the StatsRecorder record every n steps only. By default it records every step.
+func WithStatsEvery(n int) StatsRecorderOpt {
+	return func(r *StatsRecorder) { r.every = n }
+}
+
+// WithStatsBins sets the number of bins of the histograms. The default is 30.
+func WithStatsBins(n int) StatsRecorderOpt {
+	return func(r *StatsRecorder) { r.bins = n }
+}
+
+// WithStatsParams sets the learnables whose weights and gradients are recorded. By default they are the Learnables
+// of the graph that have gradients.
+func WithStatsParams(params ...*Node) StatsRecorderOpt {
+	return func(r *StatsRecorder) { r.params = params }
+}
+
+// StatsRecorder records the statistics of the activations and gradients of a graph. See Instrument.
+type StatsRecorder struct {
+	w      SummaryWriter
+	every  int
+	bins   int
+	params Nodes
+
+	layers []recordedLayer
+
+	sync.Mutex
+	last *StatsSnapshot
+}
+
+type recordedLayer struct {
+	name        string
+	node        *Node
+	act, grad   *Value
+	hasGradient bool
+}
+
+// Instrument adds the reads of the outputs of the layers of the graph and of their gradients to the graph, and returns
+// the StatsRecorder of their values. It must be called after the gradients are added to the graph, and before the
+// machine is created.
+func Instrument(g *ExprGraph, opts ...StatsRecorderOpt) (*StatsRecorder, error) {
+	r := &StatsRecorder{every: 1, bins: 30}
+	for _, opt := range opts {
+		opt(r)
+	}
+	if r.every < 1 || r.bins < 1 {
+		return nil, errors.Errorf("Expected a positive period and number of bins. Got %d and %d instead", r.every, r.bins)
+	}
+	if r.params == nil {
+		for _, n := range g.Learnables() {
+			if n.deriv != nil {
+				r.params = append(r.params, n)
+			}
+		}
+	}
+
+	summary, err := Summarize(g, r.params...)
+	if err != nil {
+		return nil, err
+	}
+	backward, err := gradientNodes(g)
+	if err != nil {
+		return nil, err
+	}
+	// the output of a layer is its last op
+	index := make(map[string]int)
+	for _, op := range summary.Ops {
+		if _, ok := op.Node.op.(readOp); ok || op.Layer == "" || backward.Contains(op.Node) {
+			continue
+		}
+		if i, ok := index[op.Layer]; ok {
+			r.layers[i].node = op.Node
+			continue
+		}
+		index[op.Layer] = len(r.layers)
+		r.layers = append(r.layers, recordedLayer{name: op.Layer, node: op.Node})
+	}
+	for i := range r.layers {
+		l := &r.layers[i]
+		l.act = new(Value)
+		Read(l.node, l.act)
+		if l.node.deriv != nil {
+			l.grad = new(Value)
+			l.hasGradient = true
+			Read(l.node.deriv, l.grad)
+		}
+	}
+	return r, nil
+}
+
+// gradientNodes returns the nodes that compute the gradients: the nodes that depend on a gradient.
+func gradientNodes(g *ExprGraph) (NodeSet, error) {
+	sorted, err := Sort(g)
+	if err != nil {
+		return nil, errors.Wrap(err, sortFail)
+	}
+	retVal := NewNodeSet()
+	for i := len(sorted) - 1; i >= 0; i-- {
+		n := sorted[i]
+		if len(n.derivOf) > 0 {
+			retVal.Add(n)
+			continue
+		}
+		for _, child := range n.children {
+			if retVal.Contains(child) {
+				retVal.Add(n)
+				break
+			}
+		}
+	}
+	return retVal, nil
+}
+
+// Record summarizes the values of the last run, if the step is one to record, and writes the summaries to the
+// SummaryWriter, if any. It is to be called after the machine is run, and before the solver steps and the machine is
+// reset.
+func (r *StatsRecorder) Record(step int) (err error) {
+	if step%r.every != 0 {
+		return nil
+	}
+	snap := &StatsSnapshot{Step: step}
+	for _, l := range r.layers {
+		if *l.act == nil {
+			return errors.Errorf("The output of the layer %q has not been computed. Was the machine created before Instrument?", l.name)
+		}
+		ls := LayerStats{Layer: l.name, Node: l.node, HasGradients: l.hasGradient}
+		if ls.Activations, err = r.stats(*l.act); err != nil {
+			return errors.Wrapf(err, "Layer %q", l.name)
+		}
+		if l.hasGradient && *l.grad != nil {
+			if ls.Gradients, err = r.stats(*l.grad); err != nil {
+				return errors.Wrapf(err, "Layer %q", l.name)
+			}
+		}
+		snap.Layers = append(snap.Layers, ls)
+	}
+	for _, p := range r.params {
+		ps := ParamStats{Node: p, Layer: nameScope(p.name)}
+		if ps.Weights, err = r.stats(p.Value()); err != nil {
+			return errors.Wrapf(err, "Param %v", p.name)
+		}
+		var grad Value
+		if grad, err = p.Grad(); err != nil {
+			return errors.Wrapf(err, "Param %v", p.name)
+		}
+		if ps.Gradients, err = r.stats(grad); err != nil {
+			return errors.Wrapf(err, "Param %v", p.name)
+		}
+		snap.Params = append(snap.Params, ps)
+	}
+
+	r.Lock()
+	r.last = snap
+	r.Unlock()
+	if r.w == nil {
+		return nil
+	}
+	for _, l := range snap.Layers {
+		if err = writeStats(r.w, l.Layer+"/activations", step, l.Activations); err != nil {
+			return err
+		}
+		if l.HasGradients {
+			if err = writeStats(r.w, l.Layer+"/activation_grads", step, l.Gradients); err != nil {
+				return err
+			}
+		}
+	}
+	for _, p := range snap.Params {
+		if err = writeStats(r.w, p.Node.name+"/weights", step, p.Weights); err != nil {
+			return err
+		}
+		if err = writeStats(r.w, p.Node.name+"/grads", step, p.Gradients); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Last returns the last snapshot recorded, or nil if none has been.
+func (r *StatsRecorder) Last() *StatsSnapshot {
+	r.Lock()
+	defer r.Unlock()
+	return r.last
+}
+
+func (r *StatsRecorder) stats(v Value) (retVal TensorStats, err error) {
+	var data []float64
+	if data, err = floatData(v); err != nil {
+		return
+	}
+	return summarizeFloats(data, r.bins), nil
+}
+
+func summarizeFloats(data []float64, bins int) (retVal TensorStats) {
+	retVal.Min, retVal.Max = math.Inf(1), math.Inf(-1)
+	var n, sum, sq float64
+	for _, v := range data {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			retVal.NonFinite++
+			continue
+		}
+		n++
+		sum += v
+		sq += v * v
+		retVal.Min = math.Min(retVal.Min, v)
+		retVal.Max = math.Max(retVal.Max, v)
+		if v == 0 {
+			retVal.Zeros++
+		}
+	}
+	if n == 0 {
+		nan := math.NaN()
+		retVal.Mean, retVal.Std, retVal.Min, retVal.Max = nan, nan, nan, nan
+		return retVal
+	}
+	retVal.Mean = sum / n
+	retVal.Std = math.Sqrt(math.Max(sq/n-retVal.Mean*retVal.Mean, 0))
+	retVal.Norm = math.Sqrt(sq)
+	retVal.Zeros /= float64(len(data))
+
+	lo, hi := retVal.Min, retVal.Max
+	if lo == hi {
+		lo, hi = lo-0.5, hi+0.5
+	}
+	h := HistogramSummary{Edges: make([]float64, bins+1), Counts: make([]float64, bins)}
+	width := (hi - lo) / float64(bins)
+	for i := range h.Edges {
+		h.Edges[i] = lo + float64(i)*width
+	}
+	h.Edges[bins] = hi
+	for _, v := range data {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			continue
+		}
+		b := int((v - lo) / width)
+		if b >= bins {
+			b = bins - 1
+		}
+		h.Counts[b]++
+	}
+	retVal.Histogram = h
+	return retVal
+}
+
+func writeStats(w SummaryWriter, tag string, step int, s TensorStats) error {
+	for _, scalar := range []struct {
+		name string
+		v    float64
+	}{{"mean", s.Mean}, {"std", s.Std}, {"min", s.Min}, {"max", s.Max}, {"norm", s.Norm}, {"zeros", s.Zeros}} {
+		if err := w.WriteScalar(tag+"/"+scalar.name, step, scalar.v); err != nil {
+			return err
+		}
+	}
+	if s.NonFinite > 0 {
+		if err := w.WriteScalar(tag+"/nonfinite", step, float64(s.NonFinite)); err != nil {
+			return err
+		}
+	}
+	if len(s.Histogram.Counts) == 0 {
+		return nil
+	}
+	return w.WriteHistogram(tag, step, s.Histogram)
+}
+
+// NewJSONSummaryWriter returns a SummaryWriter that writes every summary as a line of JSON, such as
+//		{"tag":"dense1/activations/std","step":100,"value":0.52}
+// The values that are not finite are written as null.
+func NewJSONSummaryWriter(w io.Writer) SummaryWriter {
+	return &jsonSummaryWriter{enc: json.NewEncoder(w)}
+}
+
+type jsonSummaryWriter struct {
+	sync.Mutex
+	enc *json.Encoder
+}
+
+func (w *jsonSummaryWriter) WriteScalar(tag string, step int, v float64) error {
+	var value interface{} = v
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		value = nil
+	}
+	w.Lock()
+	defer w.Unlock()
+	return w.enc.Encode(struct {
+		Tag   string      `json:"tag"`
+		Step  int         `json:"step"`
+		Value interface{} `json:"value"`
+	}{tag, step, value})
+}
+
+func (w *jsonSummaryWriter) WriteHistogram(tag string, step int, h HistogramSummary) error {
+	w.Lock()
+	defer w.Unlock()
+	return w.enc.Encode(struct {
+		Tag       string           `json:"tag"`
+		Step      int              `json:"step"`
+		Histogram HistogramSummary `json:"histogram"`
+	}{tag, step, h})
+}
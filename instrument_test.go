@@ -0,0 +1,143 @@
+package gorgonia
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorgonia.org/tensor"
+)
+
+type testSummaryWriter struct {
+	scalars    map[string]float64
+	histograms map[string]HistogramSummary
+	steps      []int
+}
+
+func (w *testSummaryWriter) WriteScalar(tag string, step int, v float64) error {
+	w.scalars[tag] = v
+	if len(w.steps) == 0 || w.steps[len(w.steps)-1] != step {
+		w.steps = append(w.steps, step)
+	}
+	return nil
+}
+
+func (w *testSummaryWriter) WriteHistogram(tag string, step int, h HistogramSummary) error {
+	w.histograms[tag] = h
+	return nil
+}
+
+func TestInstrument(t *testing.T) {
+	assert := assert.New(t)
+	g := NewGraph()
+	x := NewMatrix(g, Float64, WithShape(4, 3), WithName("x"), WithValue(tensor.New(tensor.WithShape(4, 3), tensor.WithBacking([]float64{1, -2, 3, -4, 5, -6, 7, -8, 9, -1, 2, -3}))))
+	l1 := g.Scope("dense1")
+	w1 := NewMatrix(g, Float64, WithShape(3, 5), WithName("w"), WithInit(RangedFrom(-7)))
+	l1()
+	l2 := g.Scope("dense2")
+	w2 := NewMatrix(g, Float64, WithShape(5, 2), WithName("w"), WithInit(RangedFrom(0)))
+	l2()
+	h := Must(Rectify(Must(Mul(x, w1))))
+	out := Must(Mul(h, w2))
+	cost := Must(Mean(out))
+	if _, err := Grad(cost, w1, w2); err != nil {
+		t.Fatal(err)
+	}
+	var hVal Value
+	Read(h, &hVal)
+
+	w := &testSummaryWriter{scalars: make(map[string]float64), histograms: make(map[string]HistogramSummary)}
+	rec, err := Instrument(g, WithSummaryWriter(w), WithStatsEvery(2), WithStatsBins(4), WithStatsParams(w1, w2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := NewTapeMachine(g)
+	defer m.Close()
+	assert.Nil(rec.Last())
+	for step := 0; step < 3; step++ {
+		if err = m.RunAll(); err != nil {
+			t.Fatal(err)
+		}
+		if err = rec.Record(step); err != nil {
+			t.Fatal(err)
+		}
+		m.Reset()
+	}
+	assert.Equal([]int{0, 2}, w.steps)
+
+	snap := rec.Last()
+	assert.Equal(2, snap.Step)
+	if !assert.Len(snap.Layers, 2) {
+		t.FailNow()
+	}
+	assert.Equal("dense1", snap.Layers[0].Layer)
+	assert.True(h == snap.Layers[0].Node, "the output of dense1 is the ReLU, not %v", snap.Layers[0].Node)
+	assert.True(snap.Layers[0].HasGradients)
+	assert.Equal("dense2", snap.Layers[1].Layer)
+
+	data := hVal.Data().([]float64)
+	act := snap.Layers[0].Activations
+	want := summarizeFloats(data, 4)
+	assert.Equal(want, act)
+	var zeros, sum float64
+	for _, v := range data {
+		sum += v
+		if v == 0 {
+			zeros++
+		}
+	}
+	assert.InDelta(sum/20, act.Mean, 1e-9)
+	assert.Equal(zeros/20, act.Zeros)
+	assert.Equal(20.0, act.Histogram.Counts[0]+act.Histogram.Counts[1]+act.Histogram.Counts[2]+act.Histogram.Counts[3])
+
+	w1Grad, err := w1.Grad()
+	if err != nil {
+		t.Fatal(err)
+	}
+	gradData := w1Grad.Data().([]float64)
+	assert.Equal(summarizeFloats(gradData, 4), snap.Params[0].Gradients)
+	assert.Equal("dense1", snap.Params[0].Layer)
+	assert.Equal(snap.Params[1].Gradients.Std, w.scalars["dense2/w/grads/std"])
+	assert.Equal(act.Zeros, w.scalars["dense1/activations/zeros"])
+	assert.Contains(w.histograms, "dense1/activation_grads")
+	assert.Len(w.histograms["dense2/w/weights"].Edges, 5)
+
+	_, err = Instrument(g, WithStatsBins(0))
+	assert.Error(err)
+}
+
+func TestSummarizeFloats(t *testing.T) {
+	assert := assert.New(t)
+	s := summarizeFloats([]float64{0, 1, 2, 3, math.NaN(), math.Inf(1)}, 3)
+	assert.Equal(1.5, s.Mean)
+	assert.InDelta(math.Sqrt(1.25), s.Std, 1e-12)
+	assert.Equal(0.0, s.Min)
+	assert.Equal(3.0, s.Max)
+	assert.InDelta(math.Sqrt(14), s.Norm, 1e-12)
+	assert.Equal(1.0/6, s.Zeros)
+	assert.Equal(2, s.NonFinite)
+	assert.Equal([]float64{0, 1, 2, 3}, s.Histogram.Edges)
+	assert.Equal([]float64{1, 1, 2}, s.Histogram.Counts, "the last bin includes its upper edge")
+
+	constant := summarizeFloats([]float64{2, 2}, 2)
+	assert.Equal([]float64{1.5, 2, 2.5}, constant.Histogram.Edges)
+	assert.Equal([]float64{0, 2}, constant.Histogram.Counts)
+	assert.True(math.IsNaN(summarizeFloats([]float64{math.NaN()}, 2).Mean))
+}
+
+func TestJSONSummaryWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewJSONSummaryWriter(&buf)
+	assert.NoError(t, w.WriteScalar("a/mean", 1, 0.5))
+	assert.NoError(t, w.WriteScalar("a/std", 1, math.NaN()))
+	assert.NoError(t, w.WriteHistogram("a", 1, HistogramSummary{Edges: []float64{0, 1}, Counts: []float64{3}}))
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Equal(t, `{"tag":"a/mean","step":1,"value":0.5}`, lines[0])
+	assert.Equal(t, `{"tag":"a/std","step":1,"value":null}`, lines[1])
+	var h struct{ Histogram HistogramSummary }
+	assert.NoError(t, json.Unmarshal([]byte(lines[2]), &h))
+	assert.Equal(t, []float64{3}, h.Histogram.Counts)
+}
@@ -0,0 +1,138 @@
+// Package logging holds the logger shared by the packages of Gorgonia, so that the engine in gorgonia.org/gorgonia/cuda
+// and the VMs and solvers in gorgonia.org/gorgonia log to the same place. Users configure it from the gorgonia package,
+// with SetLogger and SetLogLevel.
+package logging
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+)
+
+// Logger is a structured logger. Its methods take a message followed by alternating keys and values, as those of
+// *slog.Logger do, so that a *slog.Logger can be used directly.
+type Logger interface {
+	Debug(msg string, args ...interface{})
+	Info(msg string, args ...interface{})
+	Warn(msg string, args ...interface{})
+	Error(msg string, args ...interface{})
+}
+
+// Level is the importance of a log record. The values are those of slog.Level.
+type Level int
+
+// Levels
+const (
+	Debug Level = -4
+	Info  Level = 0
+	Warn  Level = 4
+	Error Level = 8
+	Off   Level = 1 << 30 // silences a subsystem
+)
+
+func (l Level) String() string {
+	switch l {
+	case Debug:
+		return "DEBUG"
+	case Info:
+		return "INFO"
+	case Warn:
+		return "WARN"
+	case Error:
+		return "ERROR"
+	case Off:
+		return "OFF"
+	}
+	return fmt.Sprintf("Level(%d)", int(l))
+}
+
+// Subsystem is the part of Gorgonia a record comes from. Every subsystem has its own level.
+type Subsystem string
+
+// Subsystems
+const (
+	VM      Subsystem = "vm"
+	Compile Subsystem = "compile"
+	Solver  Subsystem = "solver"
+	Engine  Subsystem = "engine"
+)
+
+// DefaultLevel is the level of the subsystems whose level has not been set.
+const DefaultLevel = Info
+
+var (
+	current atomic.Value // holder
+	levels  sync.Map     // Subsystem → Level
+)
+
+// holder lets atomic.Value hold loggers of different concrete types.
+type holder struct{ Logger }
+
+// SetLogger sets the logger. A nil logger discards all the records, which is the default.
+func SetLogger(l Logger) { current.Store(holder{l}) }
+
+// SetLevel sets the minimum level of the records logged for a subsystem.
+func SetLevel(s Subsystem, l Level) { levels.Store(s, l) }
+
+// LevelOf returns the level of a subsystem.
+func LevelOf(s Subsystem) Level {
+	if l, ok := levels.Load(s); ok {
+		return l.(Level)
+	}
+	return DefaultLevel
+}
+
+func logger() Logger {
+	h, _ := current.Load().(holder)
+	return h.Logger
+}
+
+// Enabled returns whether a record of level l from the subsystem s would be logged. Callers that compute expensive
+// arguments should check it first.
+func Enabled(s Subsystem, l Level) bool { return logger() != nil && l >= LevelOf(s) }
+
+// Log logs a record of the subsystem s, whose name is added to the arguments under the key "subsystem".
+func Log(s Subsystem, l Level, msg string, args ...interface{}) {
+	lg := logger()
+	if lg == nil || l < LevelOf(s) {
+		return
+	}
+	args = append([]interface{}{"subsystem", string(s)}, args...)
+	switch {
+	case l < Info:
+		lg.Debug(msg, args...)
+	case l < Warn:
+		lg.Info(msg, args...)
+	case l < Error:
+		lg.Warn(msg, args...)
+	default:
+		lg.Error(msg, args...)
+	}
+}
+
+// std adapts a *log.Logger, writing every record on a line as
+//		LEVEL msg key=value key=value
+type std struct{ l *log.Logger }
+
+// Std returns a Logger writing to a *log.Logger, for programs that do not use log/slog.
+func Std(l *log.Logger) Logger { return std{l} }
+
+func (s std) Debug(msg string, args ...interface{}) { s.write(Debug, msg, args) }
+func (s std) Info(msg string, args ...interface{})  { s.write(Info, msg, args) }
+func (s std) Warn(msg string, args ...interface{})  { s.write(Warn, msg, args) }
+func (s std) Error(msg string, args ...interface{}) { s.write(Error, msg, args) }
+
+func (s std) write(l Level, msg string, args []interface{}) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%v %s", l, msg)
+	for i := 0; i < len(args); i += 2 {
+		if i+1 == len(args) {
+			fmt.Fprintf(&buf, " !BADKEY=%v", args[i])
+			break
+		}
+		fmt.Fprintf(&buf, " %v=%v", args[i], args[i+1])
+	}
+	s.l.Println(buf.String())
+}
@@ -0,0 +1,51 @@
+package logging
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type recorder struct{ records []string }
+
+func (r *recorder) record(level, msg string, args []interface{}) {
+	r.records = append(r.records, strings.TrimSpace(fmt.Sprintln(append([]interface{}{level, msg}, args...)...)))
+}
+func (r *recorder) Debug(msg string, args ...interface{}) { r.record("D", msg, args) }
+func (r *recorder) Info(msg string, args ...interface{})  { r.record("I", msg, args) }
+func (r *recorder) Warn(msg string, args ...interface{})  { r.record("W", msg, args) }
+func (r *recorder) Error(msg string, args ...interface{}) { r.record("E", msg, args) }
+
+func TestLog(t *testing.T) {
+	assert := assert.New(t)
+	defer SetLogger(nil)
+	defer SetLevel(VM, DefaultLevel)
+	defer SetLevel(Engine, DefaultLevel)
+
+	Log(VM, Error, "nobody listens")
+	assert.False(Enabled(VM, Error), "there is no logger by default")
+
+	r := new(recorder)
+	SetLogger(r)
+	Log(VM, Debug, "below the default level")
+	Log(VM, Info, "info", "k", 1)
+	Log(Engine, Error, "error")
+	SetLevel(VM, Debug)
+	assert.True(Enabled(VM, Debug))
+	assert.False(Enabled(Engine, Debug))
+	Log(VM, Debug, "debug")
+	SetLevel(Engine, Off)
+	Log(Engine, Error, "silenced")
+	assert.Equal([]string{"I info subsystem vm k 1", "E error subsystem engine", "D debug subsystem vm"}, r.records)
+}
+
+func TestStd(t *testing.T) {
+	var buf bytes.Buffer
+	l := Std(log.New(&buf, "", 0))
+	l.Warn("reuse shape mismatch", "got", "(2, 3)", "want", 6, "odd")
+	assert.Equal(t, "WARN reuse shape mismatch got=(2, 3) want=6 !BADKEY=odd\n", buf.String())
+}
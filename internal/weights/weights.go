@@ -0,0 +1,241 @@
+// Package weights holds the assignment of named tensors to the parameters of a graph, which is shared by the readers of
+// the weights of the models of other frameworks, such as gorgonia.org/gorgonia/torch.
+package weights
+
+import (
+	"encoding/binary"
+	"math"
+
+	"github.com/pkg/errors"
+	G "gorgonia.org/gorgonia"
+	"gorgonia.org/tensor"
+)
+
+// Options are the options of Assign.
+type Options struct {
+	// Match returns the key by which the names of the tensors and of the parameters are matched. The names are matched
+	// as they are if it is nil.
+	Match func(name string) string
+
+	ByShape bool // pair the parameters and tensors left unmatched, in their orders, as long as their shapes agree
+	Strict  bool // fail if a parameter is not assigned or a tensor is not used
+}
+
+// Result tells which tensor was assigned to each parameter.
+type Result struct {
+	Params  G.Nodes
+	Names   []string // the names of the tensors of the Params
+	Missing G.Nodes  // the parameters that were not assigned
+	Unused  []string // the tensors that were not assigned
+}
+
+// Assign assigns the tensors of the given names to the parameters. The tensors are copied into the values of the
+// parameters, or bound to the parameters that have none, and must have their shapes; the floating point tensors are
+// converted to the types of the parameters. Nothing is assigned if an error is returned.
+func Assign(names []string, tensors map[string]*tensor.Dense, params G.Nodes, o Options) (*Result, error) {
+	match := o.Match
+	if match == nil {
+		match = func(s string) string { return s }
+	}
+	byName := make(map[string]string, len(names))
+	for _, name := range names {
+		byName[match(name)] = name
+	}
+	used := make(map[string]bool)
+	retVal := new(Result)
+	for _, n := range params {
+		name, ok := byName[match(n.Name())]
+		if !ok || used[name] {
+			retVal.Missing = append(retVal.Missing, n)
+			continue
+		}
+		used[name] = true
+		retVal.Params = append(retVal.Params, n)
+		retVal.Names = append(retVal.Names, name)
+	}
+	var unused []string
+	for _, name := range names {
+		if !used[name] {
+			unused = append(unused, name)
+		}
+	}
+
+	if o.ByShape {
+		var missing G.Nodes
+		for _, n := range retVal.Missing {
+			if len(unused) > 0 && n.Shape().Eq(tensors[unused[0]].Shape()) {
+				retVal.Params = append(retVal.Params, n)
+				retVal.Names = append(retVal.Names, unused[0])
+				unused = unused[1:]
+				continue
+			}
+			missing = append(missing, n)
+		}
+		retVal.Missing = missing
+	}
+	retVal.Unused = unused
+	if o.Strict && (len(retVal.Missing) > 0 || len(retVal.Unused) > 0) {
+		return nil, errors.Errorf("The parameters %v are missing from the tensors, and the tensors %v are not assigned", retVal.Missing, retVal.Unused)
+	}
+
+	values := make([]tensor.Tensor, len(retVal.Params))
+	for i, n := range retVal.Params {
+		t := tensors[retVal.Names[i]]
+		if !n.Shape().Eq(t.Shape()) {
+			if t.Dims() == 2 && n.Dims() == 2 && n.Shape()[0] == t.Shape()[1] && n.Shape()[1] == t.Shape()[0] {
+				return nil, errors.Errorf("The tensor %q is of the shape %v, the transpose of the shape of %v: it must be transposed first", retVal.Names[i], t.Shape(), n.Name())
+			}
+			return nil, errors.Errorf("The tensor %q is of the shape %v. The parameter %v is of the shape %v", retVal.Names[i], t.Shape(), n.Name(), n.Shape())
+		}
+		var err error
+		if values[i], err = convert(t, n.Dtype()); err != nil {
+			return nil, errors.Wrapf(err, "Tensor %q", retVal.Names[i])
+		}
+	}
+	for i, n := range retVal.Params {
+		var err error
+		if v := n.Value(); v != nil {
+			_, err = G.Copy(v, values[i])
+		} else {
+			err = G.Let(n, values[i])
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "Cannot assign the tensor %q to %v", retVal.Names[i], n.Name())
+		}
+	}
+	return retVal, nil
+}
+
+// convert converts a tensor to a Dtype. Only the floating point tensors are converted.
+func convert(t *tensor.Dense, dt tensor.Dtype) (tensor.Tensor, error) {
+	if t.Dtype() == dt {
+		return t, nil
+	}
+	switch {
+	case t.Dtype() == tensor.Float32 && dt == tensor.Float64:
+		src := t.Data().([]float32)
+		data := make([]float64, len(src))
+		for i, v := range src {
+			data[i] = float64(v)
+		}
+		return tensor.New(tensor.WithShape(t.Shape().Clone()...), tensor.WithBacking(data)), nil
+	case t.Dtype() == tensor.Float64 && dt == tensor.Float32:
+		src := t.Data().([]float64)
+		data := make([]float32, len(src))
+		for i, v := range src {
+			data[i] = float32(v)
+		}
+		return tensor.New(tensor.WithShape(t.Shape().Clone()...), tensor.WithBacking(data)), nil
+	}
+	return nil, errors.Errorf("Cannot convert a tensor of %v to %v", t.Dtype(), dt)
+}
+
+// Float16 converts the little endian IEEE half precision float of p.
+func Float16(p []byte) float32 {
+	h := uint32(binary.LittleEndian.Uint16(p))
+	sign := (h >> 15) << 31
+	exp := (h >> 10) & 0x1f
+	frac := h & 0x3ff
+	switch {
+	case exp == 0x1f: // the infinities and the NaNs
+		return math.Float32frombits(sign | 0xff<<23 | frac<<13)
+	case exp == 0 && frac == 0:
+		return math.Float32frombits(sign)
+	case exp == 0: // the subnormals
+		f := float32(frac) / (1 << 24)
+		if sign != 0 {
+			return -f
+		}
+		return f
+	}
+	return math.Float32frombits(sign | (exp+127-15)<<23 | frac<<13)
+}
+
+// BFloat16 converts the little endian bfloat16 of p, which is the 16 high bits of a float32.
+func BFloat16(p []byte) float32 {
+	return math.Float32frombits(uint32(binary.LittleEndian.Uint16(p)) << 16)
+}
+
+// Type is the encoding of the elements of the tensors of a file.
+type Type struct {
+	Dtype tensor.Dtype           // of the decoded tensors
+	Size  int                    // of an element of the file
+	Conv  func(p []byte) float32 // the little endian conversion of the formats that tensor does not support, such as Float16
+}
+
+// Decode decodes the contiguous elements of a tensor of the shape, in row-major order.
+func (t Type) Decode(p []byte, order binary.ByteOrder, shape []int) (*tensor.Dense, error) {
+	size := 1
+	for _, d := range shape {
+		size *= d
+	}
+	if len(p) != size*t.Size {
+		return nil, errors.Errorf("Expected %d bytes for a tensor of the shape %v. Got %d instead", size*t.Size, shape, len(p))
+	}
+	var backing interface{}
+	switch {
+	case t.Conv != nil:
+		data := make([]float32, size)
+		buf := make([]byte, t.Size)
+		for i := range data {
+			e := p[i*t.Size : (i+1)*t.Size]
+			if order != binary.LittleEndian {
+				for j := range buf {
+					buf[j] = e[t.Size-1-j]
+				}
+				e = buf
+			}
+			data[i] = t.Conv(e)
+		}
+		backing = data
+	case t.Dtype == tensor.Float64 && t.Size == 8:
+		data := make([]float64, size)
+		for i := range data {
+			data[i] = math.Float64frombits(order.Uint64(p[i*8:]))
+		}
+		backing = data
+	case t.Dtype == tensor.Float32 && t.Size == 4:
+		data := make([]float32, size)
+		for i := range data {
+			data[i] = math.Float32frombits(order.Uint32(p[i*4:]))
+		}
+		backing = data
+	case t.Dtype == tensor.Int64 && t.Size == 8:
+		data := make([]int64, size)
+		for i := range data {
+			data[i] = int64(order.Uint64(p[i*8:]))
+		}
+		backing = data
+	case t.Dtype == tensor.Int32 && t.Size == 4:
+		data := make([]int32, size)
+		for i := range data {
+			data[i] = int32(order.Uint32(p[i*4:]))
+		}
+		backing = data
+	case t.Dtype == tensor.Int16 && t.Size == 2:
+		data := make([]int16, size)
+		for i := range data {
+			data[i] = int16(order.Uint16(p[i*2:]))
+		}
+		backing = data
+	case t.Dtype == tensor.Int8 && t.Size == 1:
+		data := make([]int8, size)
+		for i := range data {
+			data[i] = int8(p[i])
+		}
+		backing = data
+	case t.Dtype == tensor.Uint8 && t.Size == 1:
+		data := make([]uint8, size)
+		copy(data, p)
+		backing = data
+	case t.Dtype == tensor.Bool && t.Size == 1:
+		data := make([]bool, size)
+		for i := range data {
+			data[i] = p[i] != 0
+		}
+		backing = data
+	default:
+		return nil, errors.Errorf("Cannot decode the elements of %v of %d bytes", t.Dtype, t.Size)
+	}
+	return tensor.New(tensor.WithShape(shape...), tensor.WithBacking(backing)), nil
+}
@@ -0,0 +1,89 @@
+package weights
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	G "gorgonia.org/gorgonia"
+	"gorgonia.org/tensor"
+)
+
+func TestAssign(t *testing.T) {
+	assert := assert.New(t)
+	g := G.NewGraph()
+	w := G.NewMatrix(g, G.Float64, G.WithShape(2, 2), G.WithName("w"), G.WithInit(G.Zeroes()))
+	b := G.NewVector(g, G.Float32, G.WithShape(2), G.WithName("b"), G.WithInit(G.Zeroes()))
+	tensors := map[string]*tensor.Dense{
+		"w": tensor.New(tensor.WithShape(2, 2), tensor.WithBacking([]float32{1, 2, 3, 4})),
+		"x": tensor.New(tensor.WithShape(2), tensor.WithBacking([]float32{5, 6})),
+	}
+
+	res, err := Assign([]string{"w", "x"}, tensors, G.Nodes{w, b}, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]string{"w"}, res.Names)
+	assert.True(len(res.Missing) == 1 && res.Missing[0] == b)
+	assert.Equal([]string{"x"}, res.Unused)
+	assert.Equal([]float64{1, 2, 3, 4}, w.Value().Data())
+
+	res, err = Assign([]string{"w", "x"}, tensors, G.Nodes{w, b}, Options{ByShape: true, Strict: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]string{"w", "x"}, res.Names)
+	assert.Equal([]float32{5, 6}, b.Value().Data())
+
+	_, err = Assign([]string{"w"}, tensors, G.Nodes{w, b}, Options{Strict: true})
+	assert.Error(err)
+}
+
+func TestFloat16(t *testing.T) {
+	assert := assert.New(t)
+	for h, want := range map[uint16]float32{
+		0x3c00: 1,
+		0xc000: -2,
+		0x3555: 0.33325195,
+		0x0001: 1.0 / (1 << 24), // the smallest subnormal
+		0x8000: float32(math.Copysign(0, -1)),
+	} {
+		p := make([]byte, 2)
+		binary.LittleEndian.PutUint16(p, h)
+		assert.Equal(want, Float16(p), "%#x", h)
+	}
+	p := make([]byte, 2)
+	binary.LittleEndian.PutUint16(p, 0x7e00)
+	assert.True(math.IsNaN(float64(Float16(p))))
+	binary.LittleEndian.PutUint16(p, 0x3f80)
+	assert.Equal(float32(1), BFloat16(p))
+}
+
+func TestType_Decode(t *testing.T) {
+	assert := assert.New(t)
+	p := []byte{0x3f, 0x80, 0, 0, 0xc0, 0, 0, 0}
+	d, err := Type{Dtype: tensor.Float32, Size: 4}.Decode(p, binary.BigEndian, []int{2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]float32{1, -2}, d.Data())
+
+	d, err = Type{Dtype: tensor.Float32, Size: 2, Conv: Float16}.Decode([]byte{0x3c, 0, 0xc0, 0}, binary.BigEndian, []int{1, 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(tensor.Shape{1, 2}, d.Shape())
+	assert.Equal([]float32{1, -2}, d.Data())
+
+	d, err = Type{Dtype: tensor.Int64, Size: 8}.Decode([]byte{42, 0, 0, 0, 0, 0, 0, 0}, binary.LittleEndian, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(int64(42), d.Data())
+
+	_, err = Type{Dtype: tensor.Float32, Size: 4}.Decode(p, binary.BigEndian, []int{3})
+	assert.Error(err, "a short tensor")
+	_, err = Type{Dtype: tensor.Complex64, Size: 8}.Decode(p, binary.BigEndian, []int{1})
+	assert.Error(err, "an unsupported type")
+}
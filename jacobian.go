@@ -0,0 +1,205 @@
+package gorgonia
+
+import (
+	"github.com/pkg/errors"
+	"gorgonia.org/tensor"
+)
+
+// Jacobian returns the Jacobian of y with regards to x: a node of shape y.Shape() ++ x.Shape(), whose element
+// (i..., j...) is the derivative of y[i...] with regards to x[j...]. If y is a scalar, this is the gradient of y.
+//
+// Each row is computed by reverse-mode differentiation with a one-hot seed, so the backward pass is built once for each
+// element of y. The forward pass is shared.
+//
+// Jacobian does not change the derivatives that Grad may have set on the nodes of the graph.
+func Jacobian(y, x *Node) (retVal *Node, err error) {
+	if y.g == nil || y.g != x.g {
+		return nil, errors.Errorf("Expected %v and %v to be in the same graph", y, x)
+	}
+	if err = checkFloatNode(y); err != nil {
+		return nil, errors.Wrap(err, "Cannot compute the Jacobian")
+	}
+
+	m := shapeSize(y.Shape())
+	rows := make(Nodes, 0, m)
+	for i := 0; i < m; i++ {
+		var seed *Node
+		if seed, err = oneHotSeed(y.Dtype(), y.Shape(), i); err != nil {
+			return nil, err
+		}
+		var grads Nodes
+		if grads, err = freshBackprop(Nodes{y}, Nodes{seed}, Nodes{x}); err != nil {
+			return nil, errors.Wrapf(err, "Failed to differentiate element %d of %v", i, y)
+		}
+		rows = append(rows, grads[0])
+	}
+	if y.IsScalar() {
+		return rows[0], nil
+	}
+	return stackRows(rows, y.Shape(), x.Shape())
+}
+
+// PerSampleGrads returns the gradients of each of the losses with regards to each of the WRTs. losses is a vector of B
+// per-sample losses, and the i-th returned node has shape (B, wrt[i].Shape()...): its b-th row is the gradient of
+// losses[b] with regards to wrt[i]. These are the gradients needed, for instance, to clip each sample's contribution in
+// differentially private SGD.
+//
+// The gradients of weights whose only use is the multiplication of a (B, n) matrix of samples, x×w, are computed with a
+// single backward pass: the b-th gradient is the outer product of x[b] and the gradient of losses[b] with regards to
+// row b of x×w. This assumes that the samples are independent: that losses[b] does not depend on the other rows of x×w
+// (as it would with batch normalization, for example). The gradients of the other WRTs are computed as a Jacobian.
+func PerSampleGrads(losses *Node, wrt ...*Node) (retVal Nodes, err error) {
+	if !losses.IsVector() {
+		return nil, errors.Errorf("Expected the per-sample losses to be a vector. Got %v of shape %v instead", losses, losses.Shape())
+	}
+	for _, w := range wrt {
+		var grad *Node
+		if xw, x := perSampleMatMul(losses, w); xw != nil {
+			grad, err = perSampleMatMulGrad(losses, xw, x)
+		} else {
+			grad, err = Jacobian(losses, w)
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "Failed to compute the per-sample gradients of %v", w)
+		}
+		retVal = append(retVal, grad)
+	}
+	return retVal, nil
+}
+
+// perSampleMatMul returns the node x×w and x, if the only use of w is the multiplication of a matrix x of samples.
+func perSampleMatMul(losses, w *Node) (xw, x *Node) {
+	users := w.g.to.get(w)
+	if len(users) != 1 || !w.IsMatrix() {
+		return nil, nil
+	}
+	p := users[0]
+	op, ok := p.op.(linAlgBinOp)
+	if !ok || op.āBinaryOperator != matMulOperator || op.transA || op.transB || p.children[1] != w || p.children[0] == w {
+		return nil, nil
+	}
+	if x = p.children[0]; !x.IsMatrix() || x.Shape()[0] != losses.Shape()[0] {
+		return nil, nil
+	}
+	return p, x
+}
+
+func perSampleMatMulGrad(losses, xw, x *Node) (retVal *Node, err error) {
+	var sum, one *Node
+	if sum, err = Sum(losses); err != nil {
+		return nil, errors.Wrap(err, "Failed to sum the losses")
+	}
+	if one, err = floatConstant(sum.Dtype(), 1); err != nil {
+		return nil, err
+	}
+	var grads Nodes
+	if grads, err = freshBackprop(Nodes{sum}, Nodes{one}, Nodes{xw}); err != nil {
+		return nil, err
+	}
+	b, in, out := x.Shape()[0], x.Shape()[1], xw.Shape()[1]
+	var xs, gs *Node
+	if xs, err = Reshape(x, tensor.Shape{b, in, 1}); err != nil {
+		return nil, errors.Wrap(err, "Failed to reshape the samples")
+	}
+	if gs, err = Reshape(grads[0], tensor.Shape{b, 1, out}); err != nil {
+		return nil, errors.Wrap(err, "Failed to reshape the gradients")
+	}
+	return BatchedMatMul(xs, gs)
+}
+
+// freshBackprop backpropagates as if no derivative had been computed in the graph yet, then restores the derivatives
+// that were there. Backpropagate reuses the derivatives of the nodes that have one, which is only correct when they
+// were computed with the same seed.
+func freshBackprop(outputs, gradOutputs, wrt Nodes) (retVal Nodes, err error) {
+	g := outputs[0].g
+	type derivs struct {
+		deriv   *Node
+		derivOf Nodes
+	}
+	nodes := g.AllNodes()
+	saved := make(map[*Node]derivs, len(nodes))
+	for _, n := range nodes {
+		saved[n] = derivs{n.deriv, n.derivOf}
+		n.deriv, n.derivOf = nil, nil
+	}
+	defer func() {
+		for n, d := range saved {
+			n.deriv, n.derivOf = d.deriv, d.derivOf
+		}
+	}()
+	seeds := make(Nodes, len(gradOutputs))
+	for i, seed := range gradOutputs {
+		seeds[i] = g.AddNode(seed)
+	}
+	return Backpropagate(outputs, seeds, wrt)
+}
+
+// oneHotSeed returns a constant of the given dtype and shape, which is 1 at the flat index i and 0 elsewhere.
+func oneHotSeed(dt tensor.Dtype, shape tensor.Shape, i int) (*Node, error) {
+	if shape.IsScalar() {
+		return floatConstant(dt, 1)
+	}
+	var backing interface{}
+	switch dt {
+	case Float64:
+		data := make([]float64, shapeSize(shape))
+		data[i] = 1
+		backing = data
+	case Float32:
+		data := make([]float32, shapeSize(shape))
+		data[i] = 1
+		backing = data
+	default:
+		return nil, errors.Errorf(nyiFail, "oneHotSeed", dt)
+	}
+	return NewConstant(tensor.New(tensor.WithShape(shape.Clone()...), tensor.WithBacking(backing))), nil
+}
+
+// stackRows stacks the rows, each of shape inner, into a node of shape outer ++ inner.
+func stackRows(rows Nodes, outer, inner tensor.Shape) (retVal *Node, err error) {
+	if inner.IsScalar() {
+		return stackScalars(rows, outer)
+	}
+	size := shapeSize(inner)
+	flat := make(Nodes, len(rows))
+	for i, row := range rows {
+		if flat[i], err = Reshape(row, tensor.Shape{1, size}); err != nil {
+			return nil, errors.Wrap(err, "Failed to reshape a row")
+		}
+	}
+	if retVal = flat[0]; len(flat) > 1 {
+		if retVal, err = Concat(0, flat...); err != nil {
+			return nil, errors.Wrap(err, "Failed to stack the rows")
+		}
+	}
+	shape := append(outer.Clone(), inner...)
+	return Reshape(retVal, shape)
+}
+
+// stackScalars stacks the scalars into a node of shape outer, as the sum of the scalars scaled by one-hot constants.
+// Reshaping scalars would not do: their values are not backed by memory that can be viewed as a tensor.
+func stackScalars(rows Nodes, outer tensor.Shape) (retVal *Node, err error) {
+	terms := make(Nodes, len(rows))
+	for i, row := range rows {
+		var e *Node
+		if e, err = oneHotSeed(row.Dtype(), outer, i); err != nil {
+			return nil, err
+		}
+		if terms[i], err = HadamardProd(e, row); err != nil {
+			return nil, errors.Wrap(err, hadamardProdFail)
+		}
+	}
+	if len(terms) == 1 {
+		return terms[0], nil
+	}
+	return ReduceAdd(terms)
+}
+
+// shapeSize returns the number of elements of a value of the shape. Unlike TotalSize, it is 1 for scalars.
+func shapeSize(s tensor.Shape) int {
+	size := 1
+	for _, d := range s {
+		size *= d
+	}
+	return size
+}
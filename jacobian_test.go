@@ -0,0 +1,109 @@
+package gorgonia
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorgonia.org/tensor"
+)
+
+func runJacobianGraph(t *testing.T, g *ExprGraph, outs ...*Node) []Value {
+	vals := make([]Value, len(outs))
+	for i, n := range outs {
+		Read(n, &vals[i])
+	}
+	m := NewTapeMachine(g)
+	defer m.Close()
+	if err := m.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+	return vals
+}
+
+func TestJacobian(t *testing.T) {
+	assert := assert.New(t)
+	g := NewGraph()
+	a := NewMatrix(g, Float64, WithShape(2, 3), WithName("a"), WithValue(tensor.New(tensor.WithShape(2, 3), tensor.WithBacking([]float64{1, 2, 3, 4, 5, 6}))))
+	x := NewVector(g, Float64, WithShape(3), WithName("x"), WithValue(tensor.New(tensor.WithBacking([]float64{1, -1, 2}))))
+	ax := Must(Mul(a, x))
+	sq := Must(Square(x))
+	cost := Must(Sum(sq))
+
+	if _, err := Grad(cost, x); err != nil {
+		t.Fatal(err)
+	}
+	derivs := map[*Node]*Node{}
+	for _, n := range g.AllNodes() {
+		derivs[n] = n.deriv
+	}
+
+	jax, err := Jacobian(ax, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jsq, err := Jacobian(sq, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jcost, err := Jacobian(cost, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(tensor.Shape{2, 3}, jax.Shape())
+	assert.Equal(tensor.Shape{3, 3}, jsq.Shape())
+	assert.Equal(tensor.Shape{3}, jcost.Shape())
+	for n, d := range derivs {
+		assert.Equal(d, n.deriv, "the derivatives of %v are kept", n)
+	}
+
+	vals := runJacobianGraph(t, g, jax, jsq, jcost)
+	assert.Equal([]float64{1, 2, 3, 4, 5, 6}, vals[0].Data())
+	assert.Equal([]float64{2, 0, 0, 0, -2, 0, 0, 0, 4}, vals[1].Data())
+	assert.Equal([]float64{2, -2, 4}, vals[2].Data())
+	xGrad, err := x.Grad()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]float64{2, -2, 4}, xGrad.Data())
+
+	// errors
+	i := NewVector(g, Int, WithShape(3), WithName("i"))
+	_, err = Jacobian(i, x)
+	assert.Error(err)
+	_, err = Jacobian(ax, NewVector(NewGraph(), Float64, WithShape(3), WithName("z")))
+	assert.Error(err)
+	z := NewScalar(g, Float64, WithName("z"))
+	_, err = Jacobian(ax, z)
+	assert.Error(err, "ax does not depend on z")
+}
+
+func TestPerSampleGrads(t *testing.T) {
+	assert := assert.New(t)
+	g := NewGraph()
+	x := NewMatrix(g, Float64, WithShape(3, 2), WithName("x"), WithValue(tensor.New(tensor.WithShape(3, 2), tensor.WithBacking([]float64{1, 2, 3, 4, 5, 6}))))
+	w := NewMatrix(g, Float64, WithShape(2, 2), WithName("w"), WithValue(tensor.New(tensor.WithShape(2, 2), tensor.WithBacking([]float64{1, 0, 0, 1}))))
+	s := NewScalar(g, Float64, WithName("s"), WithValue(2.0))
+	xw := Must(Mul(x, w))
+	losses := Must(Sum(Must(HadamardProd(Must(Square(xw)), s)), 1)) // s * |x[b]×w|²
+	assert.NotNil(func() *Node { p, _ := perSampleMatMul(losses, w); return p }(), "w takes the fast path")
+
+	grads, err := PerSampleGrads(losses, w, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(tensor.Shape{3, 2, 2}, grads[0].Shape())
+	assert.Equal(tensor.Shape{3}, grads[1].Shape())
+	jw, err := Jacobian(losses, w)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vals := runJacobianGraph(t, g, grads[0], grads[1], jw)
+	// d/dw s|x w|² = 2s xᵀ(x w), w = I
+	assert.Equal([]float64{4, 8, 8, 16, 36, 48, 48, 64, 100, 120, 120, 144}, vals[0].Data())
+	assert.Equal(vals[2].Data(), vals[0].Data(), "the fast path gives the Jacobian")
+	assert.Equal([]float64{5, 25, 61}, vals[1].Data())
+
+	_, err = PerSampleGrads(Must(Sum(losses)), w)
+	assert.Error(err)
+}
@@ -0,0 +1,303 @@
+package gorgonia
+
+import (
+	"fmt"
+	"hash"
+	"math"
+	"reflect"
+	"sync"
+
+	"github.com/chewxy/hm"
+	"github.com/pkg/errors"
+	"gorgonia.org/tensor"
+)
+
+// maskedScore is the additive mask of the positions that may not be attended to. It is finite so that a fully masked
+// row does not produce NaNs.
+const maskedScore = -1e9
+
+// Cache is a persistent buffer of rows that grows across runs of a machine, up to a fixed capacity. It is the building
+// block of key/value caches for autoregressive inference: the graph of a single decoding step appends the keys and
+// values of the new tokens to caches, and attends over everything cached so far, so that the previous tokens are not
+// recomputed.
+//
+// The graph sees the whole buffer, of shape (capacity, row shape...), so its shape is static; the rows that are not
+// filled yet are zeroes, and must be masked (see Mask).
+type Cache struct {
+	mu     sync.Mutex
+	name   string
+	buf    *tensor.Dense
+	stride int
+	len    int
+}
+
+// NewCache creates a cache of up to capacity rows of the given shape.
+func NewCache(name string, dt tensor.Dtype, capacity int, rowShape ...int) (*Cache, error) {
+	if capacity <= 0 {
+		return nil, errors.Errorf("Expected a positive capacity. Got %d instead", capacity)
+	}
+	if dt != Float64 && dt != Float32 {
+		return nil, errors.Errorf(nyiFail, "NewCache", dt)
+	}
+	shape := append(tensor.Shape{capacity}, rowShape...)
+	return &Cache{
+		name:   name,
+		buf:    tensor.New(tensor.WithShape(shape...), tensor.Of(dt)),
+		stride: shapeSize(tensor.Shape(rowShape)),
+	}, nil
+}
+
+// Len returns the number of rows in the cache.
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.len
+}
+
+// Cap returns the capacity of the cache.
+func (c *Cache) Cap() int { return c.buf.Shape()[0] }
+
+// Reset empties the cache, to start a new sequence.
+func (c *Cache) Reset() {
+	c.mu.Lock()
+	c.len = 0
+	c.buf.Zero()
+	c.mu.Unlock()
+}
+
+// Rows returns a copy of the rows in the cache.
+func (c *Cache) Rows() (tensor.Tensor, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.len == 0 {
+		return nil, errors.New("The cache is empty")
+	}
+	v, err := c.buf.Slice(S(0, c.len))
+	if err != nil {
+		return nil, err
+	}
+	return v.(*tensor.Dense).Materialize(), nil
+}
+
+// Append returns a node that appends the rows of x to the cache every time it is executed, and whose value is the
+// whole buffer. x has the shape (n, row shape...).
+func (c *Cache) Append(x *Node) (*Node, error) {
+	if x.Dims() != c.buf.Dims() || !x.Shape()[1:].Eq(c.buf.Shape()[1:]) {
+		return nil, errors.Errorf("Expected rows of shape %v to append to %v. Got %v instead", c.buf.Shape()[1:], c.name, x.Shape())
+	}
+	if x.Dtype() != c.buf.Dtype() {
+		return nil, errors.Errorf("Expected rows of %v to append to %v. Got %v instead", c.buf.Dtype(), c.name, x.Dtype())
+	}
+	return ApplyOp(cacheAppendOp{c}, x)
+}
+
+// Mask returns the additive attention mask of the n rows that the node appended (as returned by Append) appends: a
+// node of shape (n, capacity), which is 0 where the i-th appended row may attend to a row of the cache, and a large
+// negative number elsewhere. Row i may attend to the rows cached before it, and to itself, so the mask is causal.
+func (c *Cache) Mask(appended *Node) (*Node, error) {
+	op, ok := appended.op.(cacheAppendOp)
+	if !ok || op.c != c {
+		return nil, errors.Errorf("Expected a node appending to %v. Got %v instead", c.name, appended)
+	}
+	return ApplyOp(cacheMaskOp{c: c, n: appended.children[0].Shape()[0]}, appended)
+}
+
+// KVCache holds the keys and values attended to by an attention layer during autoregressive inference.
+type KVCache struct {
+	Keys, Values *Cache
+}
+
+// NewKVCache creates the caches of up to capacity keys and values of dimension dim.
+func NewKVCache(dt tensor.Dtype, capacity, dim int) (*KVCache, error) {
+	k, err := NewCache("keys", dt, capacity, dim)
+	if err != nil {
+		return nil, err
+	}
+	v, err := NewCache("values", dt, capacity, dim)
+	if err != nil {
+		return nil, err
+	}
+	return &KVCache{Keys: k, Values: v}, nil
+}
+
+// Reset empties the caches, to start a new sequence.
+func (kv *KVCache) Reset() {
+	kv.Keys.Reset()
+	kv.Values.Reset()
+}
+
+// Attend appends the keys k and values v of n new tokens, of shape (n, dim), to the caches, and returns the scaled
+// dot-product attention of the queries q, of shape (n, dim), over all the cached tokens:
+//
+//	softmax(q×Kᵀ/√dim + mask)×V
+//
+// The graph of one decoding step is built with n = 1, and run once per token, after letting q, k and v. A prompt may be
+// processed in one run with a separate graph for n > 1.
+func (kv *KVCache) Attend(q, k, v *Node) (retVal *Node, err error) {
+	if !q.Shape().Eq(k.Shape()) {
+		return nil, errors.Errorf("Expected the queries and keys to have the same shape. Got %v and %v", q.Shape(), k.Shape())
+	}
+	var keys, values, mask *Node
+	if keys, err = kv.Keys.Append(k); err != nil {
+		return nil, err
+	}
+	if values, err = kv.Values.Append(v); err != nil {
+		return nil, err
+	}
+	if mask, err = kv.Keys.Mask(keys); err != nil {
+		return nil, err
+	}
+
+	var kT, scores, scale, probs *Node
+	if kT, err = Transpose(keys); err != nil {
+		return nil, errors.Wrap(err, "Failed to transpose the keys")
+	}
+	if scores, err = Mul(q, kT); err != nil {
+		return nil, errors.Wrap(err, mulFail)
+	}
+	if scale, err = floatConstant(q.Dtype(), 1/math.Sqrt(float64(q.Shape()[1]))); err != nil {
+		return nil, err
+	}
+	if scores, err = HadamardProd(scores, scale); err != nil {
+		return nil, errors.Wrap(err, hadamardProdFail)
+	}
+	if scores, err = Add(scores, mask); err != nil {
+		return nil, errors.Wrap(err, addFail)
+	}
+	if probs, err = SoftMax(scores, 1); err != nil {
+		return nil, errors.Wrap(err, operationError)
+	}
+	return Mul(probs, values)
+}
+
+// cacheAppendOp appends its input to a cache, and returns a copy of the buffer of the cache.
+type cacheAppendOp struct {
+	c *Cache
+}
+
+func (op cacheAppendOp) Arity() int { return 1 }
+
+// cacheAppendOp has this type:
+//
+//	cacheAppendOp :: Tensor-d a → Tensor-d a
+func (op cacheAppendOp) Type() hm.Type {
+	t := makeTensorType(op.c.buf.Dims(), op.c.buf.Dtype())
+	return hm.NewFnType(t, t)
+}
+
+func (op cacheAppendOp) InferShape(inputs ...DimSizer) (tensor.Shape, error) {
+	if err := checkArity(op, len(inputs)); err != nil {
+		return nil, err
+	}
+	return op.c.buf.Shape().Clone(), nil
+}
+
+func (op cacheAppendOp) Do(inputs ...Value) (retVal Value, err error) {
+	if err = checkArity(op, len(inputs)); err != nil {
+		return
+	}
+	t, ok := inputs[0].(tensor.Tensor)
+	if !ok {
+		return nil, errors.Errorf(nyiTypeFail, op, inputs[0])
+	}
+	if t.RequiresIterator() {
+		t = tensor.Materialize(t)
+	}
+	c := op.c
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	n := t.Shape()[0]
+	if c.len+n > c.Cap() {
+		return nil, errors.Errorf("Cannot append %d rows to %v: it holds %d of %d rows", n, c.name, c.len, c.Cap())
+	}
+	buf := reflect.ValueOf(c.buf.Data())
+	reflect.Copy(buf.Slice(c.len*c.stride, (c.len+n)*c.stride), reflect.ValueOf(t.Data()))
+	c.len += n
+	return c.buf.Clone().(*tensor.Dense), nil
+}
+
+func (op cacheAppendOp) ReturnsPtr() bool     { return false }
+func (op cacheAppendOp) CallsExtern() bool    { return false }
+func (op cacheAppendOp) OverwritesInput() int { return -1 }
+
+// IsStateful is true: the op appends to the cache on every run.
+func (op cacheAppendOp) IsStateful() bool { return true }
+
+func (op cacheAppendOp) WriteHash(h hash.Hash) { fmt.Fprintf(h, "Append(%s %p)", op.c.name, op.c) }
+
+func (op cacheAppendOp) Hashcode() uint32 { return simpleHash(op) }
+
+func (op cacheAppendOp) String() string { return fmt.Sprintf("Append(%s)", op.c.name) }
+
+func (op cacheAppendOp) DiffWRT(inputs int) []bool { return []bool{false} }
+
+func (op cacheAppendOp) SymDiff(inputs Nodes, output, grad *Node) (retVal Nodes, err error) {
+	return nil, nondiffErr(op)
+}
+
+// cacheMaskOp returns the causal attention mask of the last n rows appended to a cache. Its input is the node that
+// appends them, so that the mask is computed after the rows are appended.
+type cacheMaskOp struct {
+	c *Cache
+	n int
+}
+
+func (op cacheMaskOp) Arity() int { return 1 }
+
+// cacheMaskOp has this type:
+//
+//	cacheMaskOp :: Tensor-d a → Matrix a
+func (op cacheMaskOp) Type() hm.Type {
+	dt := op.c.buf.Dtype()
+	return hm.NewFnType(makeTensorType(op.c.buf.Dims(), dt), makeTensorType(2, dt))
+}
+
+func (op cacheMaskOp) InferShape(inputs ...DimSizer) (tensor.Shape, error) {
+	if err := checkArity(op, len(inputs)); err != nil {
+		return nil, err
+	}
+	return tensor.Shape{op.n, op.c.Cap()}, nil
+}
+
+func (op cacheMaskOp) Do(inputs ...Value) (retVal Value, err error) {
+	if err = checkArity(op, len(inputs)); err != nil {
+		return
+	}
+	capacity := op.c.Cap()
+	start := op.c.Len() - op.n
+	mask := make([]float64, op.n*capacity)
+	for i := 0; i < op.n; i++ {
+		for j := start + i + 1; j < capacity; j++ {
+			mask[i*capacity+j] = maskedScore
+		}
+	}
+	if op.c.buf.Dtype() == Float32 {
+		f32s := make([]float32, len(mask))
+		for i, v := range mask {
+			f32s[i] = float32(v)
+		}
+		return tensor.New(tensor.WithShape(op.n, capacity), tensor.WithBacking(f32s)), nil
+	}
+	return tensor.New(tensor.WithShape(op.n, capacity), tensor.WithBacking(mask)), nil
+}
+
+func (op cacheMaskOp) ReturnsPtr() bool     { return false }
+func (op cacheMaskOp) CallsExtern() bool    { return false }
+func (op cacheMaskOp) OverwritesInput() int { return -1 }
+
+// IsStateful is true: the mask depends on the length of the cache.
+func (op cacheMaskOp) IsStateful() bool { return true }
+
+func (op cacheMaskOp) WriteHash(h hash.Hash) {
+	fmt.Fprintf(h, "Mask(%s %p, %d)", op.c.name, op.c, op.n)
+}
+
+func (op cacheMaskOp) Hashcode() uint32 { return simpleHash(op) }
+
+func (op cacheMaskOp) String() string { return fmt.Sprintf("Mask(%s)", op.c.name) }
+
+func (op cacheMaskOp) DiffWRT(inputs int) []bool { return []bool{false} }
+
+func (op cacheMaskOp) SymDiff(inputs Nodes, output, grad *Node) (retVal Nodes, err error) {
+	return nil, nondiffErr(op)
+}
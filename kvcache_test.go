@@ -0,0 +1,194 @@
+package gorgonia
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorgonia.org/tensor"
+)
+
+// naiveAttention computes the causal attention of the queries of the last len(qs) tokens over all the tokens.
+func naiveAttention(qs, ks, vs [][]float64) [][]float64 {
+	d := len(ks[0])
+	start := len(ks) - len(qs)
+	retVal := make([][]float64, len(qs))
+	for i, q := range qs {
+		visible := start + i + 1
+		scores := make([]float64, visible)
+		max := math.Inf(-1)
+		for j := range scores {
+			for k := range q {
+				scores[j] += q[k] * ks[j][k]
+			}
+			scores[j] /= math.Sqrt(float64(d))
+			max = math.Max(max, scores[j])
+		}
+		var sum float64
+		for j := range scores {
+			scores[j] = math.Exp(scores[j] - max)
+			sum += scores[j]
+		}
+		retVal[i] = make([]float64, d)
+		for j, s := range scores {
+			for k := range retVal[i] {
+				retVal[i][k] += s / sum * vs[j][k]
+			}
+		}
+	}
+	return retVal
+}
+
+func randRows(r *rand.Rand, n, d int) [][]float64 {
+	rows := make([][]float64, n)
+	for i := range rows {
+		rows[i] = make([]float64, d)
+		for j := range rows[i] {
+			rows[i][j] = r.NormFloat64()
+		}
+	}
+	return rows
+}
+
+func flatRows(rows [][]float64) tensor.Tensor {
+	var data []float64
+	for _, row := range rows {
+		data = append(data, row...)
+	}
+	return tensor.New(tensor.WithShape(len(rows), len(rows[0])), tensor.WithBacking(data))
+}
+
+// attentionStep builds the graph of the attention of n tokens over a KV cache.
+type attentionStep struct {
+	q, k, v, out *Node
+	m            VM
+}
+
+func newAttentionStep(t *testing.T, kv *KVCache, n, d int) *attentionStep {
+	g := NewGraph()
+	s := &attentionStep{
+		q: NewMatrix(g, Float64, WithShape(n, d), WithName("q")),
+		k: NewMatrix(g, Float64, WithShape(n, d), WithName("k")),
+		v: NewMatrix(g, Float64, WithShape(n, d), WithName("v")),
+	}
+	var err error
+	if s.out, err = kv.Attend(s.q, s.k, s.v); err != nil {
+		t.Fatal(err)
+	}
+	s.m = NewTapeMachine(g)
+	return s
+}
+
+func (s *attentionStep) run(q, k, v [][]float64) ([]float64, error) {
+	Let(s.q, flatRows(q))
+	Let(s.k, flatRows(k))
+	Let(s.v, flatRows(v))
+	defer s.m.Reset()
+	if err := s.m.RunAll(); err != nil {
+		return nil, err
+	}
+	return s.out.Value().Data().([]float64), nil
+}
+
+func TestKVCache_Attend(t *testing.T) {
+	assert := assert.New(t)
+	const d, capacity = 4, 6
+	r := rand.New(rand.NewSource(1337))
+	qs, ks, vs := randRows(r, capacity, d), randRows(r, capacity, d), randRows(r, capacity, d)
+
+	kv, err := NewKVCache(Float64, capacity, d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	prefill := newAttentionStep(t, kv, 2, d)
+	step := newAttentionStep(t, kv, 1, d)
+	defer prefill.m.Close()
+	defer step.m.Close()
+
+	// the prompt is processed at once, then the tokens are generated one by one
+	got, err := prefill.run(qs[:2], ks[:2], vs[:2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := naiveAttention(qs[:2], ks[:2], vs[:2])
+	assert.InDeltaSlice(append(want[0], want[1]...), got, 1e-9)
+	assert.Equal(2, kv.Keys.Len())
+
+	for i := 3; i <= capacity; i++ {
+		got, err := step.run(qs[i-1:i], ks[i-1:i], vs[i-1:i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.InDeltaSlice(naiveAttention(qs[i-1:i], ks[:i], vs[:i])[0], got, 1e-9, "token %d", i)
+		assert.Equal(i, kv.Values.Len())
+	}
+
+	rows, err := kv.Keys.Rows()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(flatRows(ks).Data(), rows.Data())
+
+	// the cache is full
+	_, err = step.run(qs[:1], ks[:1], vs[:1])
+	assert.Error(err)
+
+	kv.Reset()
+	assert.Equal(0, kv.Keys.Len())
+	_, err = kv.Keys.Rows()
+	assert.Error(err)
+	got, err = step.run(qs[:1], ks[:1], vs[:1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.InDeltaSlice(vs[0], got, 1e-9, "a single token attends to itself")
+}
+
+func TestCache_Mask(t *testing.T) {
+	assert := assert.New(t)
+	c, err := NewCache("c", Float32, 4, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := NewGraph()
+	x := NewMatrix(g, Float32, WithShape(2, 2), WithName("x"))
+	appended, err := c.Append(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mask, err := c.Mask(appended)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(tensor.Shape{4, 2}, appended.Shape())
+	assert.Equal(tensor.Shape{2, 4}, mask.Shape())
+
+	m := NewTapeMachine(g)
+	defer m.Close()
+	Let(x, tensor.New(tensor.WithShape(2, 2), tensor.WithBacking([]float32{1, 2, 3, 4})))
+	if err := m.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]float32{1, 2, 3, 4, 0, 0, 0, 0}, appended.Value().Data())
+	assert.Equal([]float32{0, maskedScore, maskedScore, maskedScore, 0, 0, maskedScore, maskedScore}, mask.Value().Data())
+
+	m.Reset()
+	if err := m.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]float32{1, 2, 3, 4, 1, 2, 3, 4}, appended.Value().Data())
+	assert.Equal([]float32{0, 0, 0, maskedScore, 0, 0, 0, 0}, mask.Value().Data())
+
+	// construction errors
+	_, err = c.Append(NewMatrix(g, Float32, WithShape(1, 3)))
+	assert.Error(err)
+	_, err = c.Append(NewMatrix(g, Float64, WithShape(1, 2)))
+	assert.Error(err)
+	_, err = c.Mask(x)
+	assert.Error(err)
+	_, err = NewCache("c", Int, 4)
+	assert.Error(err)
+	_, err = NewCache("c", Float64, 0)
+	assert.Error(err)
+}
@@ -0,0 +1,132 @@
+package gorgonia
+
+import (
+	"github.com/pkg/errors"
+	"gorgonia.org/tensor"
+)
+
+/*
+This file holds the layouts of the image nodes. The NN functions (Conv2d, MaxPool2D, BatchNorm...) take NCHW images
+by default. An image may however be tagged as channel-last with InLayout(NHWC) or ToLayout, in which case the functions
+that have a NHWC kernel use it, and the others convert the image to NCHW and back. NHWC is the faster layout of the
+convolutions on CPUs, as the channels of a pixel are contiguous.
+
+The layouts are propagated when the graph is constructed: the elementwise ops keep the layout of their operands, and the
+NN functions return images of the layout of their inputs. OptimizeLayouts then removes the conversions that cancel out, so
+that the only transposes left are at the boundaries of the NHWC layers.
+*/
+
+var (
+	toNHWC = []int{0, 2, 3, 1}
+	toNCHW = []int{0, 3, 1, 2}
+)
+
+// InLayout is a node construction option to set the layout of a 4D image. By default it is NCHW.
+func InLayout(l Layout) NodeConsOpt {
+	f := func(n *Node) {
+		n.layout = l
+	}
+	return f
+}
+
+// Layout returns the layout of the node, if it is an image.
+func (n *Node) Layout() Layout { return n.layout }
+
+// ToLayout converts the image to the given layout. Converting to the layout that n already has is a no-op, and converting back a conversion returns the original
+// image.
+func ToLayout(n *Node, l Layout) (retVal *Node, err error) {
+	if n.Dims() != 4 {
+		return nil, errors.Errorf("Expected a 4D image. Got %v instead", n.Shape())
+	}
+	var pattern []int
+	switch {
+	case n.layout == l:
+		return n, nil
+	case l == NHWC:
+		pattern = toNHWC
+	case l == NCHW:
+		pattern = toNCHW
+	default:
+		return nil, errors.Errorf("Unknown layout %v", l)
+	}
+	if op, ok := n.op.(transposeOp); ok && isInversePermutation(op.pattern, pattern) && n.children[0].layout == l {
+		return n.children[0], nil
+	}
+	if retVal, err = Transpose(n, pattern...); err != nil {
+		return nil, err
+	}
+	retVal.layout = l
+	return retVal, nil
+}
+
+// inferLayout infers the layout of the result of applying op to the children: the elementwise ops keep the NHWC
+// layout of their operands of the same shape. The other ops return NCHW images, unless they are NHWC kernels.
+func inferLayout(op Op, children Nodes, s tensor.Shape) Layout {
+	if s.Dims() != 4 {
+		return NCHW
+	}
+	switch op.(type) {
+	case elemUnaryOp, elemBinOp, *fmaOp, stopGradOp, gradReversalOp:
+		for _, child := range children {
+			if child.layout == NHWC && child.Shape().Eq(s) {
+				return NHWC
+			}
+		}
+	}
+	return NCHW
+}
+
+// OptimizeLayouts removes the pairs of transposes that cancel out, such as the conversions of an image from NHWC to
+// NCHW and back between two NN functions: the users of the second transpose use the input of the first instead.
+// It returns the number of transposes removed. The named transposes, and those that have derivatives, are kept, so
+// OptimizeLayouts should be called after the graph is constructed but before Grad.
+func OptimizeLayouts(g *ExprGraph) (removed int, err error) {
+	// removing nodes reorders g.all, so iterate over a copy
+	all := make(Nodes, len(g.all))
+	copy(all, g.all)
+	dead := NewNodeSet()
+	for _, n := range all {
+		if dead.Contains(n) || !isRemovableTranspose(n) {
+			continue
+		}
+		inner := n.children[0]
+		if !isRemovableTranspose(inner) {
+			continue
+		}
+		if !isInversePermutation(n.op.(transposeOp).pattern, inner.op.(transposeOp).pattern) {
+			continue
+		}
+		if err = g.ReplaceNode(n, inner.children[0]); err != nil {
+			return removed, err
+		}
+		dead.Add(n)
+		removed++
+		if len(g.to.get(inner)) == 0 {
+			g.detach(inner)
+			g.surgeryDone()
+			dead.Add(inner)
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+func isRemovableTranspose(n *Node) bool {
+	if _, ok := n.op.(transposeOp); !ok {
+		return false
+	}
+	return n.name == "" && n.reuse == nil && n.deriv == nil && len(n.derivOf) == 0
+}
+
+// isInversePermutation checks that applying the permutations a and b one after the other is the identity.
+func isInversePermutation(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] < 0 || a[i] >= len(b) || b[a[i]] != i {
+			return false
+		}
+	}
+	return true
+}
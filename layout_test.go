@@ -0,0 +1,150 @@
+package gorgonia
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorgonia.org/tensor"
+)
+
+func TestConv2dNHWC(t *testing.T) {
+	assert := assert.New(t)
+	g := NewGraph()
+	xT := tensor.New(tensor.WithShape(2, 3, 6, 5), tensor.WithBacking(tensor.Random(tensor.Float64, 2*3*6*5)))
+	filterT := tensor.New(tensor.WithShape(4, 3, 3, 2), tensor.WithBacking(tensor.Random(tensor.Float64, 4*3*3*2)))
+	x := NodeFromAny(g, xT, WithName("x"))
+	filter := NodeFromAny(g, filterT, WithName("filter"))
+	pad, stride, dilation := []int{1, 0}, []int{2, 1}, []int{1, 2}
+
+	conv, err := Conv2d(x, filter, tensor.Shape{3, 2}, pad, stride, dilation)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nhwc, err := ToLayout(x, NHWC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(NHWC, nhwc.Layout())
+	filterNHWC := Must(Transpose(filter, 0, 2, 3, 1))
+	convNHWC, err := Conv2d(nhwc, filterNHWC, tensor.Shape{3, 2}, pad, stride, dilation)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(NHWC, convNHWC.Layout())
+	assert.Equal(tensor.Shape{2, 3, 3, 4}, convNHWC.Shape())
+	expected, err := ToLayout(conv, NHWC)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the elementwise ops keep the layout, the NN functions convert the image and back
+	bias := NewTensor(g, Float64, 4, WithShape(1, 1, 1, 4), WithName("bias"), WithInit(RangedFrom(0)))
+	biased := Must(BroadcastAdd(convNHWC, bias, nil, []byte{0, 1, 2}))
+	activated := Must(Rectify(biased))
+	assert.Equal(NHWC, activated.Layout())
+	pooled, err := MaxPool2D(activated, tensor.Shape{1, 1}, []int{0, 0}, []int{1, 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(NHWC, pooled.Layout())
+	assert.Equal(activated.Shape(), pooled.Shape())
+	gap, err := GlobalAveragePool2D(pooled)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(tensor.Shape{2, 1, 1, 4}, gap.Shape())
+	assert.Equal(NHWC, gap.Layout())
+	normalized, scale, _, _, err := BatchNorm(activated, nil, nil, 0.9, 1e-5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(NHWC, normalized.Layout())
+	assert.Equal(activated.Shape(), scale.Shape())
+	assert.Equal(NCHW, Must(Transpose(activated, 0, 3, 1, 2)).Layout())
+
+	grads, err := Grad(Must(Sum(Must(Square(conv)))), x, filter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nhwcGrads, err := Grad(Must(Sum(Must(Square(convNHWC)))), x, filter)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the value of the reshape is overwritten by the in place gradient ops, so it is read before
+	var convNHWCVal Value
+	Read(convNHWC, &convNHWCVal)
+	m := NewTapeMachine(g)
+	defer m.Close()
+	if err = m.RunAll(); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	assert.InDeltaSlice(expected.Value().Data(), convNHWCVal.Data(), 1e-12)
+	assert.InDeltaSlice(grads[0].Value().Data(), nhwcGrads[0].Value().Data(), 1e-12)
+	assert.InDeltaSlice(grads[1].Value().Data(), nhwcGrads[1].Value().Data(), 1e-12)
+
+	_, err = Conv2d(nhwc, filter, tensor.Shape{3, 2}, pad, stride, dilation)
+	assert.Error(err)
+}
+
+func TestIm2ColNHWCGrad(t *testing.T) {
+	assert := assert.New(t)
+	for _, dt := range []tensor.Dtype{tensor.Float64, tensor.Float32} {
+		xv := tensor.New(tensor.WithShape(2, 5, 4, 3), tensor.WithBacking(tensor.Random(tensor.Float64, 2*5*4*3)))
+		op := im2colNHWCOp{makeIm2ColOp(3, 2, 1, 1, 2, 1, 1, 2)}
+		out, err := op.Do(xv)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(tensor.Shape{2, 3, 4, 18}, out.Shape())
+		grad := tensor.Random(tensor.Float64, out.Shape().TotalSize()).([]float64)
+		expected := numericGrad(t, op, []Value{xv}, 0, grad)
+
+		gv, err := castValue(tensor.New(tensor.WithShape(out.Shape()...), tensor.WithBacking(grad)), dt, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		dx, err := col2imNHWCOp{unpaddedB: 2, unpaddedH: 5, unpaddedW: 4, unpaddedC: 3, im2colNHWCOp: op}.Do(gv)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, _ := floatData(dx)
+		assert.InDeltaSlice(expected, got, 1e-4, "%v", dt)
+	}
+}
+
+func TestOptimizeLayouts(t *testing.T) {
+	assert := assert.New(t)
+	g := NewGraph()
+	x := NewTensor(g, Float64, 4, WithShape(2, 3, 4, 5), WithName("x"), WithInit(RangedFrom(0)), InLayout(NHWC))
+	// converting back a conversion is a no-op
+	nchw := Must(ToLayout(x, NCHW))
+	assert.Equal(x, Must(ToLayout(nchw, NHWC)))
+	assert.Equal(nchw, Must(ToLayout(nchw, NCHW)))
+
+	// but the transposes of the user may cancel out
+	back := Must(Transpose(Must(Transpose(x, 0, 3, 1, 2)), 0, 2, 3, 1))
+	kept := Must(Transpose(Must(Transpose(x, 1, 0, 2, 3)), 0, 1, 3, 2))
+	cost := Must(Add(Must(Add(Must(Sum(Must(Square(back)))), Must(Sum(kept)))), Must(Sum(nchw))))
+	before := len(g.AllNodes())
+
+	removed, err := OptimizeLayouts(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the conversion to NCHW is shared with nchw, so only the outer transpose is removed
+	assert.Equal(1, removed)
+	assert.Equal(before-1, len(g.AllNodes()))
+	assert.False(g.AllNodes().Contains(back))
+
+	m := NewTapeMachine(g)
+	defer m.Close()
+	if err = m.RunAll(); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	var expected float64
+	for i := 0; i < 120; i++ {
+		expected += float64(i*i + 2*i)
+	}
+	assert.Equal(expected, cost.Value().Data())
+}
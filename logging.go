@@ -0,0 +1,46 @@
+package gorgonia
+
+import (
+	"log"
+
+	"gorgonia.org/gorgonia/internal/logging"
+)
+
+// Logger is a structured logger, to which the VMs, the solvers and the CUDA engine report what they do. Its methods
+// take a message followed by alternating keys and values, like those of *slog.Logger, which can be used directly:
+//		gorgonia.SetLogger(slog.Default())
+type Logger = logging.Logger
+
+// LogLevel is the importance of a log record. The levels are those of log/slog.
+type LogLevel = logging.Level
+
+// LogSubsystem is the part of Gorgonia a log record comes from. Every subsystem has its own level.
+type LogSubsystem = logging.Subsystem
+
+// Log levels
+const (
+	LevelDebug = logging.Debug
+	LevelInfo  = logging.Info
+	LevelWarn  = logging.Warn
+	LevelError = logging.Error
+	LevelOff   = logging.Off // silences a subsystem
+)
+
+// Log subsystems
+const (
+	VMLog      = logging.VM      // the tape and lisp machines
+	CompileLog = logging.Compile // compilation, type and shape inference and differentiation
+	SolverLog  = logging.Solver  // the solvers
+	EngineLog  = logging.Engine  // the CUDA engine and device memory
+)
+
+// SetLogger sets the logger of all the subsystems. By default, and when l is nil, nothing is logged.
+func SetLogger(l Logger) { logging.SetLogger(l) }
+
+// SetLogLevel sets the minimum level of the records logged for a subsystem. The default level is LevelInfo.
+func SetLogLevel(s LogSubsystem, l LogLevel) { logging.SetLevel(s, l) }
+
+// StdLogger returns a Logger writing to a *log.Logger, for programs that do not use log/slog. Every record is written
+// on a line as
+//		LEVEL msg subsystem=vm key=value ...
+func StdLogger(l *log.Logger) Logger { return logging.Std(l) }
@@ -0,0 +1,77 @@
+package gorgonia
+
+import (
+	"bytes"
+	"log"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorgonia.org/tensor"
+)
+
+func TestSetLogger(t *testing.T) {
+	assert := assert.New(t)
+	var buf bytes.Buffer
+	SetLogger(StdLogger(log.New(&buf, "", 0)))
+	defer SetLogger(nil)
+	defer SetLogLevel(CompileLog, LevelInfo)
+	defer SetLogLevel(VMLog, LevelInfo)
+
+	g := NewGraph()
+	x := NewVector(g, Float64, WithShape(3), WithName("x"), WithValue(tensor.New(tensor.WithBacking([]float64{1, 2, 3}))))
+	Must(Sum(x))
+	run := func() {
+		m := NewTapeMachine(g)
+		defer m.Close()
+		if err := m.RunAll(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	run()
+	assert.Empty(buf.String(), "the debug records are not logged by default")
+
+	SetLogLevel(VMLog, LevelDebug)
+	run()
+	out := buf.String()
+	assert.True(strings.HasPrefix(out, "DEBUG ran the tape machine subsystem=vm instructions="), out)
+	assert.NotContains(out, "subsystem=compile")
+
+	buf.Reset()
+	SetLogLevel(VMLog, LevelOff)
+	SetLogLevel(CompileLog, LevelDebug)
+	run()
+	out = buf.String()
+	assert.Contains(out, "DEBUG compiled subsystem=compile")
+	assert.NotContains(out, "subsystem=vm")
+
+	buf.Reset()
+	SetLogger(nil)
+	run()
+	assert.Empty(buf.String())
+}
+
+func TestLogStep(t *testing.T) {
+	var buf bytes.Buffer
+	SetLogger(StdLogger(log.New(&buf, "", 0)))
+	defer SetLogger(nil)
+	SetLogLevel(SolverLog, LevelDebug)
+	defer SetLogLevel(SolverLog, LevelInfo)
+
+	g := NewGraph()
+	x := NewScalar(g, Float64, WithName("x"), WithValue(2.0))
+	cost := Must(Square(x))
+	if _, err := Grad(cost, x); err != nil {
+		t.Fatal(err)
+	}
+	m := NewTapeMachine(g)
+	defer m.Close()
+	if err := m.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+	if err := NewVanillaSolver().Step(NodesToValueGrads(Nodes{x})); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "DEBUG step subsystem=solver solver=*gorgonia.VanillaSolver params=1\n", buf.String())
+}
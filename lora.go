@@ -0,0 +1,126 @@
+package gorgonia
+
+import (
+	"github.com/pkg/errors"
+	"gorgonia.org/tensor"
+)
+
+// LoRA is a low-rank adapter on a weight matrix W of shape (m, n). The matrix multiplications that used W use
+//		W + scale·A×B
+// instead, where A has shape (m, r), B has shape (r, n) and scale is alpha/r. B is initialized with zeroes, so the
+// adapted graph initially computes the same thing as the original graph.
+//
+// Only A and B are meant to be trained: the base weights are frozen by not differentiating with regards to them.
+// See Learnables.
+type LoRA struct {
+	W, A, B *Node
+	Merged  *Node // W + scale·A×B
+	Scale   float64
+}
+
+// InjectLoRA adds a low-rank adapter of the given rank on each of the weights. Each weight must be a float matrix used
+// by at least one matrix multiplication; only the uses by matrix multiplications are adapted. The names of the adapter
+// parameters are derived from the name of the weight.
+//
+// InjectLoRA must be called before the gradients are computed:
+//		adapters, err := InjectLoRA(Nodes{wq, wv}, 8, 16)
+//		...
+//		if _, err = Grad(cost, LoRALearnables(adapters)...); err != nil {
+//			...
+//		}
+func InjectLoRA(weights Nodes, rank int, alpha float64) ([]*LoRA, error) {
+	if rank <= 0 {
+		return nil, errors.Errorf("Expected a positive rank. Got %d instead", rank)
+	}
+	retVal := make([]*LoRA, 0, len(weights))
+	for _, w := range weights {
+		l, err := injectLoRA(w, rank, alpha)
+		if err != nil {
+			return nil, err
+		}
+		retVal = append(retVal, l)
+	}
+	return retVal, nil
+}
+
+func injectLoRA(w *Node, rank int, alpha float64) (*LoRA, error) {
+	if w == nil || w.g == nil {
+		return nil, errors.New("Cannot adapt a node that is not in a graph")
+	}
+	if !w.IsMatrix() {
+		return nil, errors.Errorf("Cannot adapt %v: expected a matrix. Got shape %v instead", w, w.Shape())
+	}
+	if err := checkFloatNode(w); err != nil {
+		return nil, errors.Wrapf(err, "Cannot adapt %v", w)
+	}
+
+	g := w.g
+	var users Nodes
+	for _, p := range g.to.get(w).Set() {
+		if op, ok := p.op.(linAlgBinOp); ok && (op.āBinaryOperator == matMulOperator || op.āBinaryOperator == matVecMulOperator) {
+			users = append(users, p)
+		}
+	}
+	if len(users) == 0 {
+		return nil, errors.Errorf("Cannot adapt %v: it is not used by any matrix multiplication", w)
+	}
+
+	dt := w.Dtype()
+	m, n := w.shape[0], w.shape[1]
+	l := &LoRA{W: w, Scale: alpha / float64(rank)}
+	l.A = NewMatrix(g, dt, WithShape(m, rank), WithName(w.Name()+".lora_A"), WithInit(GlorotU(1)))
+	l.B = NewMatrix(g, dt, WithShape(rank, n), WithName(w.Name()+".lora_B"), WithInit(Zeroes()))
+
+	var err error
+	var ab, scale *Node
+	if ab, err = Mul(l.A, l.B); err != nil {
+		return nil, errors.Wrap(err, mulFail)
+	}
+	if scale, err = floatConstant(dt, l.Scale); err != nil {
+		return nil, err
+	}
+	if ab, err = HadamardProd(ab, scale); err != nil {
+		return nil, errors.Wrap(err, hadamardProdFail)
+	}
+	if l.Merged, err = Add(w, ab); err != nil {
+		return nil, errors.Wrap(err, addFail)
+	}
+
+	g.rewire(w, l.Merged, users)
+	g.surgeryDone()
+	return l, nil
+}
+
+// Learnables returns the parameters of the adapter.
+func (l *LoRA) Learnables() Nodes { return Nodes{l.A, l.B} }
+
+// MergedValue computes W + scale·A×B from the current values of W, A and B. It is used to fold the adapter back into
+// the base weights once fine-tuning is done.
+func (l *LoRA) MergedValue() (tensor.Tensor, error) {
+	w, ok1 := l.W.Value().(tensor.Tensor)
+	a, ok2 := l.A.Value().(tensor.Tensor)
+	b, ok3 := l.B.Value().(tensor.Tensor)
+	if !ok1 || !ok2 || !ok3 {
+		return nil, errors.Errorf("Expected W, A and B of %v to have tensor values", l.W)
+	}
+	ab, err := tensor.MatMul(a, b)
+	if err != nil {
+		return nil, errors.Wrap(err, mulFail)
+	}
+	var scale interface{} = l.Scale
+	if ab.Dtype() == tensor.Float32 {
+		scale = float32(l.Scale)
+	}
+	if ab, err = tensor.Mul(ab, scale, tensor.UseUnsafe()); err != nil {
+		return nil, errors.Wrap(err, mulFail)
+	}
+	return tensor.Add(w, ab)
+}
+
+// LoRALearnables returns the parameters of all the adapters.
+func LoRALearnables(adapters []*LoRA) (retVal Nodes) {
+	for _, l := range adapters {
+		retVal = append(retVal, l.Learnables()...)
+	}
+	return
+}
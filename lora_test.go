@@ -0,0 +1,65 @@
+package gorgonia
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorgonia.org/tensor"
+)
+
+func TestInjectLoRA(t *testing.T) {
+	assert := assert.New(t)
+	g := NewGraph()
+	x := NewMatrix(g, Float64, WithShape(2, 3), WithName("x"), WithInit(RangedFrom(0)))
+	w := NewMatrix(g, Float64, WithShape(3, 4), WithName("w"), WithInit(RangedFrom(1)))
+	bias := NewVector(g, Float64, WithShape(4), WithName("bias"), WithInit(Zeroes()))
+	wSum := Must(Sum(w)) // not a matrix multiplication, so it is not adapted
+	y := Must(BroadcastAdd(Must(Mul(x, w)), bias, nil, []byte{0}))
+	cost := Must(Add(Must(Sum(y)), wSum))
+
+	adapters, err := InjectLoRA(Nodes{w}, 2, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := adapters[0]
+	assert.Equal(2.0, l.Scale)
+	assert.Equal(tensor.Shape{3, 2}, l.A.Shape())
+	assert.Equal(tensor.Shape{2, 4}, l.B.Shape())
+	assert.Equal("w.lora_A", l.A.Name())
+	assert.Equal(Nodes{l.A, l.B}, LoRALearnables(adapters))
+	assert.Equal(Nodes{w}, wSum.children)
+
+	if _, err = Grad(cost, LoRALearnables(adapters)...); err != nil {
+		t.Fatal(err)
+	}
+	m := NewTapeMachine(g)
+	defer m.Close()
+	if err = m.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+
+	// B is zero, so the output is unchanged: sum(x×w) + sum(w) = 454 + 78
+	assert.Equal(532.0, cost.Value().Data())
+	_, err = w.Grad()
+	assert.Error(err, "the base weights are frozen")
+	bGrad, err := l.B.Grad()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.NotEqual(make([]float64, 8), bGrad.Data())
+
+	merged, err := l.MergedValue()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(w.Value().Data(), merged.Data())
+
+	// errors
+	_, err = InjectLoRA(Nodes{w}, 0, 1)
+	assert.Error(err)
+	_, err = InjectLoRA(Nodes{bias}, 2, 1)
+	assert.Error(err)
+	z := NewMatrix(g, Float64, WithShape(3, 4), WithName("z"))
+	_, err = InjectLoRA(Nodes{z}, 2, 1)
+	assert.Error(err, "z has no users")
+}
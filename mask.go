@@ -0,0 +1,221 @@
+package gorgonia
+
+import (
+	"github.com/pkg/errors"
+	"gorgonia.org/tensor"
+)
+
+/*
+This file holds the masking of padded sequences. A batch of sequences of different lengths is padded to the length of the
+longest one, and the padded positions must be excluded from the softmaxes and the reductions:
+
+		mask, err := SequenceMask(lengths, maxLen) // (batch, maxLen)
+		...
+		probs, err := MaskedSoftMax(scores, mask, 1)
+		pooled, err := MaskedMean(hidden, mask, 1)
+
+The masks are float tensors of ones, for the positions that are kept, and zeroes, for the positions that are masked
+out. The masked positions have no effect on the values of the masked functions, and get zero gradients, whatever their
+values.
+*/
+
+// SequenceMask returns the mask of the sequences of the given lengths: a node of shape (batch, maxLen), of the dtype of
+// lengths, whose element (i, j) is 1 if j < lengths[i], and 0 otherwise. The lengths are a float vector.
+func SequenceMask(lengths *Node, maxLen int) (retVal *Node, err error) {
+	if !lengths.IsVector() {
+		return nil, errors.Errorf("Expected a vector of lengths. Got %v instead", lengths.Shape())
+	}
+	if maxLen <= 0 {
+		return nil, errors.Errorf("Expected a positive maximum length. Got %d instead", maxLen)
+	}
+	var positions *Node
+	switch lengths.Dtype() {
+	case tensor.Float64:
+		positions = NewConstant(tensor.New(tensor.WithShape(1, maxLen), tensor.WithBacking(tensor.Range(tensor.Float64, 0, maxLen))), In(lengths.g))
+	case tensor.Float32:
+		positions = NewConstant(tensor.New(tensor.WithShape(1, maxLen), tensor.WithBacking(tensor.Range(tensor.Float32, 0, maxLen))), In(lengths.g))
+	default:
+		return nil, errors.Errorf(nyiFail, "SequenceMask", lengths.Dtype())
+	}
+	var col *Node
+	if col, err = Reshape(lengths, tensor.Shape{lengths.Shape().TotalSize(), 1}); err != nil {
+		return nil, err
+	}
+	return BroadcastLt(positions, col, true, []byte{0}, []byte{1})
+}
+
+// CausalMask returns the (n, n) mask of the positions that a position of a sequence may attend to: the element (i, j)
+// is 1 if j <= i, and 0 otherwise. The mask is a constant of the graph.
+func CausalMask(g *ExprGraph, dt tensor.Dtype, n int) (*Node, error) {
+	if n <= 0 {
+		return nil, errors.Errorf("Expected a positive sequence length. Got %d instead", n)
+	}
+	if dt != Float64 && dt != Float32 {
+		return nil, errors.Errorf(nyiFail, "CausalMask", dt)
+	}
+	data := make([]float64, n*n)
+	for i := 0; i < n; i++ {
+		for j := 0; j <= i; j++ {
+			data[i*n+j] = 1
+		}
+	}
+	mask, err := valueLike(tensor.New(tensor.WithShape(n, n), tensor.Of(dt)), data)
+	if err != nil {
+		return nil, err
+	}
+	return NewConstant(mask, In(g)), nil
+}
+
+// MaskedFill returns a with the masked out positions replaced by the value. The gradients of the masked positions are 0.
+func MaskedFill(a, mask *Node, value float64) (retVal *Node, err error) {
+	if err = checkMask(a, mask); err != nil {
+		return nil, err
+	}
+	var kept, inverse, fill *Node
+	if kept, err = HadamardProd(a, mask); err != nil {
+		return nil, errors.Wrap(err, hadamardProdFail)
+	}
+	if value == 0 {
+		return kept, nil
+	}
+	var one *Node
+	if one, err = floatConstant(a.Dtype(), 1); err != nil {
+		return nil, err
+	}
+	if inverse, err = Sub(one, mask); err != nil {
+		return nil, errors.Wrap(err, subFail)
+	}
+	if fill, err = floatConstant(a.Dtype(), value); err != nil {
+		return nil, err
+	}
+	if fill, err = HadamardProd(inverse, fill); err != nil {
+		return nil, errors.Wrap(err, hadamardProdFail)
+	}
+	return Add(kept, fill)
+}
+
+// MaskedSoftMax is the SoftMax of a along the axis over the positions that are not masked out. The masked out
+// positions have a probability of 0, and so have all the positions of a fully masked row.
+func MaskedSoftMax(a, mask *Node, axis int) (retVal *Node, err error) {
+	if err = checkMask(a, mask); err != nil {
+		return nil, err
+	}
+	if axis < 0 || axis >= a.Dims() {
+		return nil, errors.Errorf("Cannot perform MaskedSoftMax on axis %d. Input has shape %v", axis, a.Shape())
+	}
+	var filled, max, exp, sum, fullyMasked *Node
+	if filled, err = MaskedFill(a, mask, maskedScore); err != nil {
+		return nil, err
+	}
+	// the shift by the maximum is for the stability only, the softmax does not depend on it
+	if max, err = Max(filled, axis); err != nil {
+		return nil, errors.Wrap(err, operationError)
+	}
+	if max, err = StopGrad(max); err != nil {
+		return nil, err
+	}
+	if max, err = keepDims(max, a.Shape(), axis); err != nil {
+		return nil, err
+	}
+	if exp, err = BroadcastSub(filled, max, nil, []byte{byte(axis)}); err != nil {
+		return nil, errors.Wrap(err, subFail)
+	}
+	if exp, err = Exp(exp); err != nil {
+		return nil, errors.Wrap(err, operationError)
+	}
+	// the exponentials of a fully masked row are ones
+	if exp, err = HadamardProd(exp, mask); err != nil {
+		return nil, errors.Wrap(err, hadamardProdFail)
+	}
+	if sum, err = Sum(exp, axis); err != nil {
+		return nil, errors.Wrap(err, operationError)
+	}
+	if fullyMasked, err = maskedCount(mask, true, axis); err != nil {
+		return nil, err
+	}
+	if sum, err = Add(sum, fullyMasked); err != nil {
+		return nil, errors.Wrap(err, addFail)
+	}
+	if sum, err = keepDims(sum, a.Shape(), axis); err != nil {
+		return nil, err
+	}
+	return BroadcastHadamardDiv(exp, sum, nil, []byte{byte(axis)})
+}
+
+// MaskedMean is the Mean of a along the axes over the positions that are not masked out. The mean of a fully masked
+// row is 0.
+func MaskedMean(a, mask *Node, along ...int) (retVal *Node, err error) {
+	if err = checkMask(a, mask); err != nil {
+		return nil, err
+	}
+	if len(along) == 0 {
+		along = intRange(0, a.Dims())
+	}
+	var kept, sum, count *Node
+	if kept, err = HadamardProd(a, mask); err != nil {
+		return nil, errors.Wrap(err, hadamardProdFail)
+	}
+	if sum, err = Sum(kept, along...); err != nil {
+		return nil, errors.Wrap(err, operationError)
+	}
+	if count, err = maskedCount(mask, false, along...); err != nil {
+		return nil, err
+	}
+	return HadamardDiv(sum, count)
+}
+
+// MaskedMax is the Max of a along the axes over the positions that are not masked out. The max of a fully masked row
+// is 0.
+func MaskedMax(a, mask *Node, along ...int) (retVal *Node, err error) {
+	if err = checkMask(a, mask); err != nil {
+		return nil, err
+	}
+	if len(along) == 0 {
+		along = intRange(0, a.Dims())
+	}
+	var filled, nonEmpty *Node
+	if filled, err = MaskedFill(a, mask, maskedScore); err != nil {
+		return nil, err
+	}
+	if retVal, err = Max(filled, along...); err != nil {
+		return nil, errors.Wrap(err, operationError)
+	}
+	if nonEmpty, err = Max(mask, along...); err != nil {
+		return nil, errors.Wrap(err, operationError)
+	}
+	return HadamardProd(retVal, nonEmpty)
+}
+
+func checkMask(a, mask *Node) error {
+	if !a.Shape().Eq(mask.Shape()) || a.Dtype() != mask.Dtype() {
+		return errors.Errorf("Cannot mask %v of %v %v with a mask of %v %v", a, a.Dtype(), a.Shape(), mask.Dtype(), mask.Shape())
+	}
+	return nil
+}
+
+// maskedCount counts the positions of the mask along the axes that are not masked out, the count of the fully masked
+// rows being 1 so that it may be divided by. If empty is true, it returns the number of fully masked rows instead: 1
+// for the rows that are fully masked, and 0 for the others.
+func maskedCount(mask *Node, empty bool, along ...int) (retVal *Node, err error) {
+	var count, nonEmpty, one *Node
+	if count, err = Sum(mask, along...); err != nil {
+		return nil, errors.Wrap(err, operationError)
+	}
+	if nonEmpty, err = Max(mask, along...); err != nil {
+		return nil, errors.Wrap(err, operationError)
+	}
+	if one, err = floatConstant(mask.Dtype(), 1); err != nil {
+		return nil, err
+	}
+	if retVal, err = Sub(one, nonEmpty); err != nil || empty {
+		return retVal, err
+	}
+	return Add(count, retVal)
+}
+
+// keepDims reshapes the reduction of a node of shape s along the axis so that it has the same number of dimensions as s.
+func keepDims(reduced *Node, s tensor.Shape, axis int) (*Node, error) {
+	kept := s.Clone()
+	kept[axis] = 1
+	return Reshape(reduced, kept)
+}
@@ -0,0 +1,130 @@
+package gorgonia
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorgonia.org/tensor"
+)
+
+func TestSequenceMask(t *testing.T) {
+	assert := assert.New(t)
+	g := NewGraph()
+	lengths := NewVector(g, Float32, WithShape(3), WithName("lengths"), WithValue(tensor.New(tensor.WithBacking([]float32{2, 0, 4}))))
+	mask, err := SequenceMask(lengths, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(tensor.Shape{3, 4}, mask.Shape())
+	causal, err := CausalMask(g, Float32, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := Must(Sum(causal))
+
+	m := NewTapeMachine(g)
+	defer m.Close()
+	if err = m.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]float32{
+		1, 1, 0, 0,
+		0, 0, 0, 0,
+		1, 1, 1, 1,
+	}, mask.Value().Data())
+	assert.Equal([]float32{
+		1, 0, 0,
+		1, 1, 0,
+		1, 1, 1,
+	}, causal.Value().Data())
+	assert.Equal(float32(6), sum.Value().Data())
+
+	_, err = SequenceMask(lengths, 0)
+	assert.Error(err)
+	_, err = SequenceMask(NewMatrix(g, Float32, WithShape(2, 2)), 2)
+	assert.Error(err)
+	_, err = CausalMask(g, Int, 2)
+	assert.Error(err)
+}
+
+func TestMaskedReductions(t *testing.T) {
+	assert := assert.New(t)
+	g := NewGraph()
+	// the padded positions hold large values, that must not leak into the results
+	x := NewMatrix(g, Float64, WithShape(3, 4), WithName("x"), WithValue(tensor.New(tensor.WithShape(3, 4), tensor.WithBacking([]float64{
+		1, 2, 1000, 1000,
+		-5, 1000, 1000, 1000,
+		1, 2, 3, 4,
+	}))))
+	lengths := NewVector(g, Float64, WithShape(3), WithName("lengths"), WithValue(tensor.New(tensor.WithBacking([]float64{2, 0, 4}))))
+	mask := Must(SequenceMask(lengths, 4))
+	probs, err := MaskedSoftMax(x, mask, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mean, err := MaskedMean(x, mask, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	max, err := MaskedMax(x, mask, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	weights := NewMatrix(g, Float64, WithShape(3, 4), WithName("weights"), WithValue(tensor.New(tensor.WithShape(3, 4), tensor.WithBacking([]float64{
+		1, 2, 3, 4,
+		5, 6, 7, 8,
+		9, 10, 11, 12,
+	}))))
+	cost := Must(Add(Must(Add(Must(Sum(Must(HadamardProd(probs, weights)))), Must(Sum(mean)))), Must(Sum(max))))
+	grads, err := Grad(cost, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the values are overwritten by the in place gradient ops, so they are read before
+	var probsVal, meanVal, maxVal Value
+	Read(probs, &probsVal)
+	Read(mean, &meanVal)
+	Read(max, &maxVal)
+	m := NewTapeMachine(g)
+	defer m.Close()
+	if err = m.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+	e := math.E
+	p0 := []float64{1 / (1 + e), e / (1 + e)}
+	var z float64
+	for i := 1; i <= 4; i++ {
+		z += math.Exp(float64(i))
+	}
+	p2 := make([]float64, 4)
+	for i := range p2 {
+		p2[i] = math.Exp(float64(i+1)) / z
+	}
+	assert.InDeltaSlice([]float64{
+		p0[0], p0[1], 0, 0,
+		0, 0, 0, 0,
+		p2[0], p2[1], p2[2], p2[3],
+	}, probsVal.Data(), 1e-12)
+	assert.InDeltaSlice([]float64{1.5, 0, 2.5}, meanVal.Data(), 1e-12)
+	assert.InDeltaSlice([]float64{2, 0, 4}, maxVal.Data(), 1e-12)
+
+	// the gradient of the weighted softmax is p(w - Σpw), plus 1/n for the mean and 1 at the max
+	sw0 := p0[0]*1 + p0[1]*2
+	var sw2 float64
+	for i, p := range p2 {
+		sw2 += p * float64(9+i)
+	}
+	expected := []float64{
+		p0[0]*(1-sw0) + 0.5, p0[1]*(2-sw0) + 0.5 + 1, 0, 0,
+		0, 0, 0, 0,
+		p2[0]*(9-sw2) + 0.25, p2[1]*(10-sw2) + 0.25, p2[2]*(11-sw2) + 0.25, p2[3]*(12-sw2) + 0.25 + 1,
+	}
+	assert.InDeltaSlice(expected, grads[0].Value().Data(), 1e-12)
+
+	_, err = MaskedMean(x, NewMatrix(g, Float64, WithShape(3, 3)), 1)
+	assert.Error(err)
+	_, err = MaskedSoftMax(x, mask, 2)
+	assert.Error(err)
+}
@@ -0,0 +1,208 @@
+package gorgonia
+
+import (
+	"bytes"
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+)
+
+// MemoryEstimate is an estimate of the memory needed to compute some nodes of a graph. See EstimateMemory.
+type MemoryEstimate struct {
+	Parameters      int64 // the values of the inputs that have a gradient
+	Inputs          int64 // the values of the other inputs and of the constants
+	PeakActivations int64 // the largest amount of intermediate values alive at the same time
+
+	BatchSize int          // the batch size the estimate is for
+	Nodes     []NodeMemory // in the order the nodes are executed
+}
+
+// NodeMemory is the memory of the value of a node.
+type NodeMemory struct {
+	Node      *Node
+	Bytes     int64
+	Parameter bool // the node is an input with a gradient
+	Input     bool // the node is an input without a gradient, or a constant
+	Reuses    bool // the op overwrites the value of a child that is no longer needed, so it needs no new memory
+	AtPeak    bool // the value is alive when the activations take the most memory
+}
+
+// Total is the estimated peak memory.
+func (e MemoryEstimate) Total() int64 { return e.Parameters + e.Inputs + e.PeakActivations }
+
+// EstimateMemory estimates the memory needed to compute the outputs, or every node of the graph if there are none, before
+// anything is run.
+//
+// The values of the inputs and parameters are alive throughout. An intermediate value is alive from the execution of its
+// node until the last node that reads it, or until the end if it is an output; an op that overwrites one of its inputs
+// needs no memory of its own when that input is not read afterwards. This is the memory the VMs need, give or take the
+// registers they reuse and their bookkeeping.
+//
+// If batchSize is positive, the estimate is for that batch size instead of the one the graph is built with, which is the
+// leading dimension of the inputs without a gradient. The values with that leading dimension are scaled accordingly.
+func EstimateMemory(g *ExprGraph, outputs Nodes, batchSize int) (MemoryEstimate, error) {
+	sub := g
+	if len(outputs) > 0 {
+		sub = g.ExactSubgraphRoots(outputs...)
+	} else {
+		outputs = g.Roots()
+	}
+	sorted, err := Sort(sub)
+	if err != nil {
+		return MemoryEstimate{}, errors.Wrap(err, sortFail)
+	}
+	reverseNodes(sorted)
+
+	params := parameters(g)
+	isParam := func(n *Node) bool {
+		_, ok := params[n]
+		return ok
+	}
+	isGradOfParam := func(n *Node) bool {
+		for _, of := range n.derivOf {
+			if isParam(of) {
+				return true
+			}
+		}
+		return false
+	}
+
+	// the batch dimension is the leading dimension of the data inputs
+	built := -1
+	for _, n := range sorted {
+		if !n.isInput() || isParam(n) || n.Shape().IsScalar() {
+			continue
+		}
+		switch {
+		case built < 0:
+			built = n.Shape()[0]
+		case built != n.Shape()[0]:
+			if batchSize > 0 {
+				return MemoryEstimate{}, errors.Errorf("Cannot determine the batch size: the inputs without a gradient have leading dimensions %d and %d", built, n.Shape()[0])
+			}
+		}
+	}
+	if batchSize > 0 && built < 0 {
+		return MemoryEstimate{}, errors.New("Cannot determine the batch size: there are no inputs without a gradient")
+	}
+	if batchSize <= 0 {
+		batchSize = built
+	}
+
+	retVal := MemoryEstimate{BatchSize: batchSize}
+	lastUse := make(map[*Node]int)
+	for i, n := range sorted {
+		for _, child := range n.children {
+			lastUse[child] = i
+		}
+	}
+	for _, n := range outputs {
+		lastUse[n] = len(sorted)
+	}
+
+	// simulate the execution
+	var live, peak int64
+	alive := make(map[*Node]int64)
+	var peakNodes []*Node
+	nodes := make([]NodeMemory, len(sorted))
+	index := make(map[*Node]int, len(sorted))
+	for i, n := range sorted {
+		nm := NodeMemory{Node: n, Bytes: valueBytes(n)}
+		if built > 0 && !n.Shape().IsScalar() && n.Shape()[0] == built && !isParam(n) && !isGradOfParam(n) {
+			nm.Bytes = nm.Bytes / int64(built) * int64(batchSize)
+		}
+		index[n] = i
+
+		switch {
+		case isParam(n):
+			nm.Parameter = true
+			retVal.Parameters += nm.Bytes
+		case n.isInput() || n.isConstant():
+			nm.Input = true
+			retVal.Inputs += nm.Bytes
+		default:
+			if ow := n.op.OverwritesInput(); ow >= 0 && ow < len(n.children) {
+				child := n.children[ow]
+				if size, ok := alive[child]; ok && lastUse[child] == i && size >= nm.Bytes {
+					nm.Reuses = true
+					delete(alive, child)
+					alive[n] = size
+					break
+				}
+			}
+			alive[n] = nm.Bytes
+			live += nm.Bytes
+		}
+		nodes[i] = nm
+
+		if live > peak {
+			peak = live
+			peakNodes = peakNodes[:0]
+			for a := range alive {
+				peakNodes = append(peakNodes, a)
+			}
+		}
+
+		// the values that are not read anymore are released
+		for _, child := range n.children {
+			if size, ok := alive[child]; ok && lastUse[child] == i {
+				delete(alive, child)
+				live -= size
+			}
+		}
+	}
+	for _, n := range peakNodes {
+		nodes[index[n]].AtPeak = true
+	}
+	retVal.PeakActivations = peak
+	retVal.Nodes = nodes
+	return retVal, nil
+}
+
+// parameters returns the inputs of the graph that have a gradient.
+func parameters(g *ExprGraph) map[*Node]struct{} {
+	params := make(map[*Node]struct{})
+	for _, n := range g.AllNodes() {
+		for _, of := range n.derivOf {
+			if of.isInput() {
+				params[of] = struct{}{}
+			}
+		}
+		if n.deriv != nil && n.isInput() {
+			params[n] = struct{}{}
+		}
+	}
+	return params
+}
+
+// valueBytes is the size of the value of a node.
+func valueBytes(n *Node) int64 {
+	dt, err := dtypeOf(n.t)
+	if err != nil {
+		return 0
+	}
+	return calcMemSize(dt, n.Shape())
+}
+
+// String returns the estimate as a table of the nodes, followed by the totals.
+func (e MemoryEstimate) String() string {
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "Node\tBytes\tKind\tAt Peak\t")
+	for _, nm := range e.Nodes {
+		kind := "activation"
+		switch {
+		case nm.Parameter:
+			kind = "parameter"
+		case nm.Input:
+			kind = "input"
+		case nm.Reuses:
+			kind = "in place"
+		}
+		fmt.Fprintf(w, "%v\t%d\t%s\t%t\t\n", nm.Node, nm.Bytes, kind, nm.AtPeak)
+	}
+	w.Flush()
+	fmt.Fprintf(&buf, "Parameters: %d | Inputs: %d | Peak Activations: %d | Total: %d | Batch Size: %d\n", e.Parameters, e.Inputs, e.PeakActivations, e.Total(), e.BatchSize)
+	return buf.String()
+}
@@ -0,0 +1,88 @@
+package gorgonia
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEstimateMemory(t *testing.T) {
+	assert := assert.New(t)
+	g := NewGraph()
+	x := NewMatrix(g, Float64, WithShape(4, 5), WithName("x"))
+	y := Must(Tanh(x))
+	z := Must(Exp(y))
+	s := Must(Sum(z))
+
+	e, err := EstimateMemory(g, Nodes{s}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(int64(0), e.Parameters)
+	assert.Equal(int64(4*5*8), e.Inputs)
+	assert.Equal(int64(4*5*8), e.PeakActivations, "z and s are computed in place of y")
+	assert.Equal(e.Parameters+e.Inputs+e.PeakActivations, e.Total())
+	assert.Equal(4, e.BatchSize)
+	assert.Len(e.Nodes, 4)
+	assert.True(e.Nodes[0].Input)
+	assert.False(e.Nodes[1].Reuses, "the inputs are never overwritten")
+	assert.True(e.Nodes[2].Reuses)
+	assert.True(e.Nodes[3].Reuses)
+	assert.True(e.Nodes[1].AtPeak)
+
+	// a larger batch
+	e, err = EstimateMemory(g, Nodes{s}, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(int64(8*5*8), e.Inputs)
+	assert.Equal(int64(8*5*8), e.PeakActivations)
+	assert.Equal(5, strings.Count(e.String(), "\n")-1)
+
+	// only what is needed to compute the outputs is counted
+	e, err = EstimateMemory(g, Nodes{y}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(e.Nodes, 2)
+	assert.Equal(int64(4*5*8), e.PeakActivations)
+}
+
+func TestEstimateMemory_parameters(t *testing.T) {
+	assert := assert.New(t)
+	g := NewGraph()
+	x := NewMatrix(g, Float64, WithShape(32, 10), WithName("x"))
+	w := NewMatrix(g, Float64, WithShape(10, 32), WithName("w"), WithInit(GlorotU(1)))
+	xw := Must(Mul(x, w))
+	cost := Must(Mean(Must(Tanh(xw))))
+	grads, err := Grad(cost, w)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e, err := EstimateMemory(g, append(grads, cost), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(int64(10*32*8), e.Parameters)
+	assert.Equal(32, e.BatchSize, "the batch size is the leading dimension of x, not of w")
+
+	e64, err := EstimateMemory(g, append(grads, cost), 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(e.Parameters, e64.Parameters, "the parameters do not depend on the batch size")
+	assert.True(e64.PeakActivations > e.PeakActivations)
+	for i, nm := range e64.Nodes {
+		if nm.Node == grads[0] {
+			assert.Equal(int64(10*32*8), nm.Bytes, "the gradient of w has the shape of w")
+			assert.Equal(e.Nodes[i].Bytes, nm.Bytes)
+		}
+	}
+
+	// the batch size cannot be told when the data inputs disagree
+	NewVector(g, Float64, WithShape(3), WithName("v"))
+	_, err = EstimateMemory(g, nil, 64)
+	assert.Error(err)
+}
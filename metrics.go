@@ -0,0 +1,239 @@
+package gorgonia
+
+import (
+	"bufio"
+	"expvar"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Metrics is a snapshot of the counters and gauges of the runtime, for monitoring services that run graphs. The
+// counters are cumulative since the program started or since ResetMetrics.
+type Metrics struct {
+	OpsExecuted    uint64  // the number of ops executed by all the VMs
+	AllocatedBytes int64   // the bytes allocated by the VMs for the results of the ops
+	DeviceBytes    []int64 // the bytes of device memory in use, indexed by device. Empty unless built with cuda
+	PoolGets       uint64  // the number of nodes and dual values taken from the pools
+	PoolMisses     uint64  // the number of nodes and dual values the pools had to allocate
+	PoolHitRate    float64
+
+	// Kernels holds the number of executions and the time spent executing every type of op. It is only filled when
+	// the kernel timing is enabled with EnableMetrics.
+	Kernels map[string]KernelStats
+}
+
+// KernelStats is the number of executions of a type of op and the time spent executing it.
+type KernelStats struct {
+	Count uint64
+	Time  time.Duration
+}
+
+type kernelCounter struct {
+	count uint64
+	nanos int64
+}
+
+// metricsRegistry holds the metrics of the runtime. The counters are updated atomically, as VMs may run concurrently.
+type metricsRegistry struct {
+	// the 64 bit counters come first, to be aligned for the atomic operations on 32 bit platforms
+	ops        uint64
+	allocated  int64
+	poolGets   uint64
+	poolMisses uint64
+	timing     int32 // 1 if the kernels are timed
+
+	sync.RWMutex
+	kernels map[string]*kernelCounter
+	devices []int64
+	names   sync.Map // reflect.Type → string
+}
+
+var runtimeMetrics = &metricsRegistry{kernels: make(map[string]*kernelCounter)}
+
+func init() {
+	expvar.Publish("gorgonia", expvar.Func(func() interface{} { return ReadMetrics() }))
+}
+
+// EnableMetrics turns the timing of the execution of ops on or off. The other metrics are always collected, as they
+// only cost an atomic addition. The timing is off by default.
+func EnableMetrics(timing bool) {
+	var v int32
+	if timing {
+		v = 1
+	}
+	atomic.StoreInt32(&runtimeMetrics.timing, v)
+}
+
+// ReadMetrics returns a snapshot of the metrics. They are also published with expvar, as "gorgonia".
+func ReadMetrics() Metrics {
+	r := runtimeMetrics
+	retVal := Metrics{
+		OpsExecuted:    atomic.LoadUint64(&r.ops),
+		AllocatedBytes: atomic.LoadInt64(&r.allocated),
+		PoolGets:       atomic.LoadUint64(&r.poolGets),
+		PoolMisses:     atomic.LoadUint64(&r.poolMisses),
+		Kernels:        make(map[string]KernelStats),
+	}
+	if retVal.PoolGets > 0 && retVal.PoolMisses <= retVal.PoolGets {
+		retVal.PoolHitRate = 1 - float64(retVal.PoolMisses)/float64(retVal.PoolGets)
+	}
+
+	r.RLock()
+	for name, k := range r.kernels {
+		retVal.Kernels[name] = KernelStats{
+			Count: atomic.LoadUint64(&k.count),
+			Time:  time.Duration(atomic.LoadInt64(&k.nanos)),
+		}
+	}
+	retVal.DeviceBytes = make([]int64, len(r.devices))
+	for i := range r.devices {
+		retVal.DeviceBytes[i] = atomic.LoadInt64(&r.devices[i])
+	}
+	r.RUnlock()
+	return retVal
+}
+
+// ResetMetrics zeroes the counters. The device memory gauges are kept, as the memory is still in use.
+func ResetMetrics() {
+	r := runtimeMetrics
+	atomic.StoreUint64(&r.ops, 0)
+	atomic.StoreInt64(&r.allocated, 0)
+	atomic.StoreUint64(&r.poolGets, 0)
+	atomic.StoreUint64(&r.poolMisses, 0)
+	r.Lock()
+	r.kernels = make(map[string]*kernelCounter)
+	r.Unlock()
+}
+
+// start returns the time the execution of an op starts at, or the zero time if the kernels are not timed.
+func (r *metricsRegistry) start() time.Time {
+	if atomic.LoadInt32(&r.timing) == 0 {
+		return time.Time{}
+	}
+	return time.Now()
+}
+
+// exec records the execution of an op started at the time returned by start.
+func (r *metricsRegistry) exec(op Op, start time.Time) {
+	atomic.AddUint64(&r.ops, 1)
+	if start.IsZero() {
+		return
+	}
+	elapsed := time.Since(start)
+	name := r.opName(op)
+
+	r.RLock()
+	k, ok := r.kernels[name]
+	r.RUnlock()
+	if !ok {
+		r.Lock()
+		if k, ok = r.kernels[name]; !ok {
+			k = new(kernelCounter)
+			r.kernels[name] = k
+		}
+		r.Unlock()
+	}
+	atomic.AddUint64(&k.count, 1)
+	atomic.AddInt64(&k.nanos, int64(elapsed))
+}
+
+// opName is the name of the type of an op, such as "elemBinOp".
+func (r *metricsRegistry) opName(op Op) string {
+	t := reflect.TypeOf(op)
+	if name, ok := r.names.Load(t); ok {
+		return name.(string)
+	}
+	name := t.String()
+	name = name[strings.LastIndex(name, ".")+1:]
+	r.names.Store(t, name)
+	return name
+}
+
+func (r *metricsRegistry) alloc(size int64) { atomic.AddInt64(&r.allocated, size) }
+
+// device adds size bytes to the memory in use on dev. A negative size frees memory.
+func (r *metricsRegistry) device(dev Device, size int64) {
+	d := int(dev)
+	if d < 0 {
+		return
+	}
+	r.RLock()
+	ok := d < len(r.devices)
+	r.RUnlock()
+	if !ok {
+		r.Lock()
+		for len(r.devices) <= d {
+			r.devices = append(r.devices, 0)
+		}
+		r.Unlock()
+	}
+	r.RLock()
+	atomic.AddInt64(&r.devices[d], size)
+	r.RUnlock()
+}
+
+func (r *metricsRegistry) poolGet()  { atomic.AddUint64(&r.poolGets, 1) }
+func (r *metricsRegistry) poolMiss() { atomic.AddUint64(&r.poolMisses, 1) }
+
+// WritePrometheus writes the metrics in the text exposition format of Prometheus.
+func WritePrometheus(w io.Writer) error {
+	m := ReadMetrics()
+	bw := bufio.NewWriter(w)
+	metric := func(name, kind, help string) {
+		fmt.Fprintf(bw, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+	}
+
+	metric("gorgonia_ops_executed_total", "counter", "The number of ops executed by the VMs.")
+	fmt.Fprintf(bw, "gorgonia_ops_executed_total %d\n", m.OpsExecuted)
+	metric("gorgonia_allocated_bytes_total", "counter", "The bytes allocated by the VMs for the results of the ops.")
+	fmt.Fprintf(bw, "gorgonia_allocated_bytes_total %d\n", m.AllocatedBytes)
+	metric("gorgonia_pool_gets_total", "counter", "The number of nodes and dual values taken from the pools.")
+	fmt.Fprintf(bw, "gorgonia_pool_gets_total %d\n", m.PoolGets)
+	metric("gorgonia_pool_misses_total", "counter", "The number of nodes and dual values the pools had to allocate.")
+	fmt.Fprintf(bw, "gorgonia_pool_misses_total %d\n", m.PoolMisses)
+	metric("gorgonia_pool_hit_rate", "gauge", "The fraction of the gets served by the pools.")
+	fmt.Fprintf(bw, "gorgonia_pool_hit_rate %g\n", m.PoolHitRate)
+
+	if len(m.DeviceBytes) > 0 {
+		metric("gorgonia_device_memory_bytes", "gauge", "The bytes of device memory in use.")
+		for i, b := range m.DeviceBytes {
+			fmt.Fprintf(bw, "gorgonia_device_memory_bytes{device=\"%d\"} %d\n", i, b)
+		}
+	}
+
+	if len(m.Kernels) > 0 {
+		names := make([]string, 0, len(m.Kernels))
+		for name := range m.Kernels {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		metric("gorgonia_kernel_executions_total", "counter", "The number of executions of every type of op.")
+		for _, name := range names {
+			fmt.Fprintf(bw, "gorgonia_kernel_executions_total{op=%q} %d\n", name, m.Kernels[name].Count)
+		}
+		metric("gorgonia_kernel_seconds_total", "counter", "The time spent executing every type of op.")
+		for _, name := range names {
+			fmt.Fprintf(bw, "gorgonia_kernel_seconds_total{op=%q} %g\n", name, m.Kernels[name].Time.Seconds())
+		}
+	}
+	return bw.Flush()
+}
+
+// PrometheusHandler returns a handler serving the metrics to a Prometheus scraper, without depending on the
+// Prometheus client library:
+//		http.Handle("/metrics", gorgonia.PrometheusHandler())
+func PrometheusHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		if err := WritePrometheus(w); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
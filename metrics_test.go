@@ -0,0 +1,86 @@
+package gorgonia
+
+import (
+	"bytes"
+	"expvar"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorgonia.org/tensor"
+)
+
+func TestMetrics(t *testing.T) {
+	assert := assert.New(t)
+	EnableMetrics(true)
+	defer EnableMetrics(false)
+	ResetMetrics()
+
+	g := NewGraph()
+	x := NewMatrix(g, Float64, WithName("x"), WithShape(2, 3), WithInit(RangedFrom(0)))
+	y := NewMatrix(g, Float64, WithName("y"), WithShape(3, 2), WithInit(RangedFrom(0)))
+	xy := Must(Mul(x, y))
+	Must(Sum(Must(Tanh(xy))))
+
+	m := NewTapeMachine(g)
+	defer m.Close()
+	for i := 0; i < 3; i++ {
+		if err := m.RunAll(); err != nil {
+			t.Fatal(err)
+		}
+		m.Reset()
+	}
+
+	metrics := ReadMetrics()
+	assert.True(metrics.OpsExecuted >= 9, "%d ops executed", metrics.OpsExecuted)
+	assert.True(metrics.AllocatedBytes >= 2*2*8, "%d bytes allocated", metrics.AllocatedBytes)
+	assert.True(metrics.PoolGets > 0)
+	assert.True(metrics.PoolHitRate >= 0 && metrics.PoolHitRate <= 1)
+	mul, ok := metrics.Kernels["linAlgBinOp"]
+	assert.True(ok, "%v", metrics.Kernels)
+	assert.Equal(uint64(3), mul.Count)
+	assert.True(mul.Time > 0)
+
+	// the expvar is a JSON object of the metrics
+	v := expvar.Get("gorgonia")
+	if assert.NotNil(v) {
+		assert.Contains(v.String(), `"OpsExecuted":`)
+	}
+
+	var buf bytes.Buffer
+	if err := WritePrometheus(&buf); err != nil {
+		t.Fatal(err)
+	}
+	text := buf.String()
+	assert.Contains(text, "# TYPE gorgonia_ops_executed_total counter")
+	assert.Contains(text, `gorgonia_kernel_executions_total{op="linAlgBinOp"} 3`)
+	assert.Contains(text, `gorgonia_kernel_seconds_total{op="elemUnaryOp"}`)
+
+	rec := httptest.NewRecorder()
+	PrometheusHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	assert.Equal(200, rec.Code)
+	assert.True(strings.HasPrefix(rec.Header().Get("Content-Type"), "text/plain"))
+	assert.Contains(rec.Body.String(), "gorgonia_pool_hit_rate")
+
+	ResetMetrics()
+	metrics = ReadMetrics()
+	assert.Zero(metrics.OpsExecuted)
+	assert.Empty(metrics.Kernels)
+}
+
+func TestMetrics_untimed(t *testing.T) {
+	assert := assert.New(t)
+	ResetMetrics()
+	g := NewGraph()
+	x := NewVector(g, Float64, WithName("x"), WithValue(tensor.New(tensor.WithBacking([]float64{1, 2}))))
+	Must(Sum(x))
+	m := NewLispMachine(g, ExecuteFwdOnly())
+	defer m.Close()
+	if err := m.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+	metrics := ReadMetrics()
+	assert.True(metrics.OpsExecuted >= 1)
+	assert.Empty(metrics.Kernels, "the kernels are only timed once enabled")
+}
@@ -0,0 +1,464 @@
+package gorgonia
+
+import (
+	"bytes"
+	"fmt"
+	"hash"
+	"unsafe"
+
+	"github.com/chewxy/hm"
+	"github.com/pkg/errors"
+	"gorgonia.org/tensor"
+)
+
+// A MultiOutputOp is an operation that returns several values, such as the maximum values of a tensor and their
+// indices, or the factors of a decomposition. It is applied with ApplyMultiOp, which returns one node per output: the
+// op is executed once per run, however many of its outputs are used.
+type MultiOutputOp interface {
+	// Arity returns the number of inputs the op expects.
+	Arity() int
+
+	// Type returns the type of the op: a function type whose return type is a *hm.Record of the types of the outputs.
+	// For instance, an op returning the maximum values of a matrix along an axis and their indices has this type:
+	//		Matrix a → (Vector a, Vector Int)
+	Type() hm.Type
+
+	// InferShapes returns the shapes of the outputs as a function of the inputs.
+	InferShapes(inputs ...DimSizer) ([]tensor.Shape, error)
+
+	// DoMulti executes the op, returning one value per output.
+	DoMulti(inputs ...Value) ([]Value, error)
+
+	CallsExtern() bool
+	WriteHash(h hash.Hash)
+	Hashcode() uint32
+	fmt.Stringer
+}
+
+// A MultiOutputSDOp is a MultiOutputOp that supports symbolic differentiation.
+type MultiOutputSDOp interface {
+	MultiOutputOp
+
+	// DiffWRT indicates if the op is differentiable with regards to the given number of inputs.
+	DiffWRT(inputs int) []bool
+
+	// SymDiffMulti symbolically differentiates the op. grads holds the gradient of each output; grads[i] is nil if the
+	// i-th output does not affect the cost.
+	SymDiffMulti(inputs, outputs, grads Nodes) (retVal Nodes, err error)
+}
+
+// ApplyMultiOp applies a MultiOutputOp, and returns one node per output.
+//
+// Under the hood, the op is applied as a single tuple-valued node, whose Value is a Tuple, and each output is a node
+// extracting an element of the tuple.
+func ApplyMultiOp(op MultiOutputOp, children ...*Node) (retVal Nodes, err error) {
+	var shapes []tensor.Shape
+	ds := Nodes(children).dimSizers()
+	shapes, err = op.InferShapes(ds...)
+	returnDimSizers(ds)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to infer shapes. Op: %v", op)
+	}
+
+	var tuple *Node
+	if tuple, err = ApplyOp(tupleOp{op}, children...); err != nil {
+		return nil, err
+	}
+	rec, ok := tuple.t.(*hm.Record)
+	if !ok {
+		return nil, errors.Errorf("Expected %v to return a record type. Got %v instead", op, tuple.t)
+	}
+	ts := rec.Types()
+	if len(ts) != len(shapes) {
+		return nil, errors.Errorf("%v returns %d types but %d shapes", op, len(ts), len(shapes))
+	}
+	for i := range ts {
+		get := tupleGetOp{i: i, tuple: rec, s: shapes[i]}
+		var out *Node
+		if out, err = ApplyOp(get, tuple); err != nil {
+			return nil, err
+		}
+		retVal = append(retVal, out)
+	}
+	return retVal, nil
+}
+
+// Tuple is the Value of a tuple-valued node of a MultiOutputOp: it holds one Value per output.
+type Tuple []Value
+
+// Shape returns a scalar shape: the elements of a tuple have their own shapes.
+func (t Tuple) Shape() tensor.Shape { return scalarShape }
+
+// Size returns the number of elements of the tuple.
+func (t Tuple) Size() int { return len(t) }
+
+// Data returns the elements of the tuple.
+func (t Tuple) Data() interface{} { return []Value(t) }
+
+// Dtype returns the Dtype of the first element of the tuple: a tuple does not have a single Dtype.
+func (t Tuple) Dtype() tensor.Dtype {
+	for _, v := range t {
+		if v != nil {
+			return v.Dtype()
+		}
+	}
+	return tensor.Dtype{}
+}
+
+// MemSize returns the memory used by the elements of the tuple.
+func (t Tuple) MemSize() uintptr {
+	var size uintptr
+	for _, v := range t {
+		if v != nil {
+			size += v.MemSize()
+		}
+	}
+	return size
+}
+
+// Uintptr returns 0: a tuple is not backed by a single memory block.
+func (t Tuple) Uintptr() uintptr { return 0 }
+
+// Pointer returns nil: a tuple is not backed by a single memory block.
+func (t Tuple) Pointer() unsafe.Pointer { return nil }
+
+// Type returns the record of the types of the elements of the tuple.
+func (t Tuple) Type() hm.Type {
+	ts := make([]hm.Type, len(t))
+	for i, v := range t {
+		ts[i] = TypeOf(v)
+	}
+	return hm.NewRecordType("", ts...)
+}
+
+// Clone clones the elements of the tuple.
+func (t Tuple) Clone() (interface{}, error) {
+	retVal := make(Tuple, len(t))
+	for i, v := range t {
+		if v == nil {
+			continue
+		}
+		var err error
+		if retVal[i], err = CloneValue(v); err != nil {
+			return nil, errors.Wrapf(err, cloneFail, v)
+		}
+	}
+	return retVal, nil
+}
+
+// ZeroValue zeroes the elements of the tuple.
+func (t Tuple) ZeroValue() Value {
+	for i, v := range t {
+		if v != nil {
+			t[i] = ZeroValue(v)
+		}
+	}
+	return t
+}
+
+// CopyTo copies the elements of the tuple into the elements of dest, which must be a Tuple of the same length.
+func (t Tuple) CopyTo(dest interface{}) error {
+	d, ok := dest.(Tuple)
+	if !ok || len(d) != len(t) {
+		return errors.Errorf("Expected a Tuple of %d elements. Got %v instead", len(t), dest)
+	}
+	for i, v := range t {
+		if v == nil || d[i] == nil {
+			d[i] = v
+			continue
+		}
+		var err error
+		if d[i], err = Copy(d[i], v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Format formats the tuple as a parenthesized list of its elements.
+func (t Tuple) Format(s fmt.State, c rune) {
+	var buf bytes.Buffer
+	buf.WriteByte('(')
+	for i, v := range t {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		if v == nil {
+			buf.WriteString("nil")
+			continue
+		}
+		fmt.Fprintf(&buf, "%v", v)
+	}
+	buf.WriteByte(')')
+	s.Write(buf.Bytes())
+}
+
+// isTuple returns true if the node is tuple-valued, such as the node of a MultiOutputOp, or its gradient.
+func isTuple(n *Node) bool {
+	_, ok := n.t.(*hm.Record)
+	return ok
+}
+
+// tupleOp adapts a MultiOutputOp to an Op that returns a Tuple.
+type tupleOp struct {
+	MultiOutputOp
+}
+
+func (op tupleOp) InferShape(inputs ...DimSizer) (tensor.Shape, error) {
+	if err := checkArity(op, len(inputs)); err != nil {
+		return nil, err
+	}
+	return scalarShape, nil
+}
+
+func (op tupleOp) Do(inputs ...Value) (Value, error) {
+	if err := checkArity(op, len(inputs)); err != nil {
+		return nil, err
+	}
+	vals, err := op.DoMulti(inputs...)
+	if err != nil {
+		return nil, err
+	}
+	return Tuple(vals), nil
+}
+
+func (op tupleOp) ReturnsPtr() bool     { return false }
+func (op tupleOp) OverwritesInput() int { return -1 }
+
+func (op tupleOp) DiffWRT(inputs int) []bool {
+	if sd, ok := op.MultiOutputOp.(MultiOutputSDOp); ok {
+		return sd.DiffWRT(inputs)
+	}
+	return make([]bool, inputs)
+}
+
+func (op tupleOp) SymDiff(inputs Nodes, output, grad *Node) (retVal Nodes, err error) {
+	sd, ok := op.MultiOutputOp.(MultiOutputSDOp)
+	if !ok {
+		return nil, nondiffErr(op)
+	}
+	rec := output.t.(*hm.Record)
+	outputs := make(Nodes, len(rec.Types()))
+	for _, n := range output.g.to.get(output) {
+		if get, ok := n.op.(tupleGetOp); ok {
+			outputs[get.i] = n
+		}
+	}
+
+	grads := make(Nodes, len(outputs))
+	for i, filled := range gradSlots(grad, len(outputs)) {
+		if !filled || outputs[i] == nil {
+			continue
+		}
+		get := tupleGetOp{i: i, tuple: rec, s: outputs[i].Shape()}
+		if grads[i], err = ApplyOp(get, grad); err != nil {
+			return nil, err
+		}
+	}
+	return sd.SymDiffMulti(inputs, outputs, grads)
+}
+
+func (op tupleOp) String() string { return op.MultiOutputOp.String() }
+
+// tupleGetOp extracts the i-th element of a tuple.
+type tupleGetOp struct {
+	i     int
+	tuple *hm.Record
+	s     tensor.Shape
+}
+
+func (op tupleGetOp) Arity() int { return 1 }
+
+// tupleGetOp has this type, for the second element of a tuple of three elements:
+//		tupleGetOp :: (a, b, c) → b
+func (op tupleGetOp) Type() hm.Type { return hm.NewFnType(op.tuple, op.tuple.Types()[op.i]) }
+
+func (op tupleGetOp) InferShape(inputs ...DimSizer) (tensor.Shape, error) {
+	if err := checkArity(op, len(inputs)); err != nil {
+		return nil, err
+	}
+	return op.s.Clone(), nil
+}
+
+func (op tupleGetOp) Do(inputs ...Value) (Value, error) {
+	if err := checkArity(op, len(inputs)); err != nil {
+		return nil, err
+	}
+	t, ok := inputs[0].(Tuple)
+	if !ok || op.i >= len(t) || t[op.i] == nil {
+		return nil, errors.Errorf("Expected a tuple with an element %d. Got %v instead", op.i, inputs[0])
+	}
+	return t[op.i], nil
+}
+
+func (op tupleGetOp) ReturnsPtr() bool     { return false }
+func (op tupleGetOp) CallsExtern() bool    { return false }
+func (op tupleGetOp) OverwritesInput() int { return -1 }
+
+func (op tupleGetOp) WriteHash(h hash.Hash) { fmt.Fprintf(h, "TupleGet(%d)", op.i) }
+
+func (op tupleGetOp) Hashcode() uint32 { return simpleHash(op) }
+
+func (op tupleGetOp) String() string { return fmt.Sprintf("TupleGet(%d)", op.i) }
+
+// DiffWRT is true if the element is a float: no gradient flows through integer outputs, such as indices.
+func (op tupleGetOp) DiffWRT(inputs int) []bool {
+	dt, err := dtypeOf(op.tuple.Types()[op.i])
+	return []bool{err == nil && (dt == Float64 || dt == Float32)}
+}
+
+func (op tupleGetOp) SymDiff(inputs Nodes, output, grad *Node) (retVal Nodes, err error) {
+	if err = checkArity(op, len(inputs)); err != nil {
+		return nil, err
+	}
+	var tupleGrad *Node
+	if tupleGrad, err = ApplyOp(tupleGradOp{i: op.i, tuple: op.tuple}, grad); err != nil {
+		return nil, err
+	}
+	return Nodes{tupleGrad}, nil
+}
+
+// tupleGradOp makes the gradient of a tuple from the gradient of its i-th element. The other elements are nil.
+type tupleGradOp struct {
+	i     int
+	tuple *hm.Record
+}
+
+func (op tupleGradOp) Arity() int { return 1 }
+
+// tupleGradOp has this type, for the second element of a tuple of three elements:
+//		tupleGradOp :: b → (a, b, c)
+func (op tupleGradOp) Type() hm.Type { return hm.NewFnType(op.tuple.Types()[op.i], op.tuple) }
+
+func (op tupleGradOp) InferShape(inputs ...DimSizer) (tensor.Shape, error) {
+	if err := checkArity(op, len(inputs)); err != nil {
+		return nil, err
+	}
+	return scalarShape, nil
+}
+
+func (op tupleGradOp) Do(inputs ...Value) (Value, error) {
+	if err := checkArity(op, len(inputs)); err != nil {
+		return nil, err
+	}
+	retVal := make(Tuple, len(op.tuple.Types()))
+	retVal[op.i] = inputs[0]
+	return retVal, nil
+}
+
+func (op tupleGradOp) ReturnsPtr() bool     { return false }
+func (op tupleGradOp) CallsExtern() bool    { return false }
+func (op tupleGradOp) OverwritesInput() int { return -1 }
+
+func (op tupleGradOp) WriteHash(h hash.Hash) { fmt.Fprintf(h, "TupleGrad(%d, %v)", op.i, op.tuple) }
+
+func (op tupleGradOp) Hashcode() uint32 { return simpleHash(op) }
+
+func (op tupleGradOp) String() string { return fmt.Sprintf("TupleGrad(%d)", op.i) }
+
+func (op tupleGradOp) DiffWRT(inputs int) []bool { return []bool{false} }
+
+func (op tupleGradOp) SymDiff(inputs Nodes, output, grad *Node) (retVal Nodes, err error) {
+	return nil, nondiffErr(op)
+}
+
+// tupleSumOp sums up tuples element-wise, skipping the nil elements.
+type tupleSumOp struct {
+	n      int
+	tuple  *hm.Record
+	filled []bool // the elements that are not nil in at least one of the tuples
+}
+
+// sumTupleGrads sums up the gradient terms of a tuple-valued node.
+func sumTupleGrads(node *Node, grads Nodes) (*Node, error) {
+	size := len(node.t.(*hm.Record).Types())
+	filled := make([]bool, size)
+	for _, g := range grads {
+		for i, f := range gradSlots(g, size) {
+			filled[i] = filled[i] || f
+		}
+	}
+	return ApplyOp(tupleSumOp{n: len(grads), tuple: node.t.(*hm.Record), filled: filled}, grads...)
+}
+
+// gradSlots returns the elements of a tuple gradient that are not nil.
+func gradSlots(grad *Node, size int) []bool {
+	switch op := grad.op.(type) {
+	case tupleGradOp:
+		retVal := make([]bool, size)
+		retVal[op.i] = true
+		return retVal
+	case tupleSumOp:
+		return op.filled
+	}
+	retVal := make([]bool, size)
+	for i := range retVal {
+		retVal[i] = true
+	}
+	return retVal
+}
+
+func (op tupleSumOp) Arity() int { return op.n }
+
+// tupleSumOp has this type, for two tuples:
+//		tupleSumOp :: (a, b) → (a, b) → (a, b)
+func (op tupleSumOp) Type() hm.Type {
+	ts := make([]hm.Type, op.n+1)
+	for i := range ts {
+		ts[i] = op.tuple
+	}
+	return hm.NewFnType(ts...)
+}
+
+func (op tupleSumOp) InferShape(inputs ...DimSizer) (tensor.Shape, error) {
+	if err := checkArity(op, len(inputs)); err != nil {
+		return nil, err
+	}
+	return scalarShape, nil
+}
+
+func (op tupleSumOp) Do(inputs ...Value) (Value, error) {
+	if err := checkArity(op, len(inputs)); err != nil {
+		return nil, err
+	}
+	retVal := make(Tuple, len(op.filled))
+	for _, in := range inputs {
+		t, ok := in.(Tuple)
+		if !ok || len(t) != len(retVal) {
+			return nil, errors.Errorf("Expected a tuple of %d elements. Got %v instead", len(retVal), in)
+		}
+		for i, v := range t {
+			var err error
+			switch {
+			case v == nil:
+			case retVal[i] == nil:
+				if retVal[i], err = CloneValue(v); err != nil {
+					return nil, errors.Wrapf(err, cloneFail, v)
+				}
+			default:
+				if err = addInPlace(retVal[i], v); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	return retVal, nil
+}
+
+func (op tupleSumOp) ReturnsPtr() bool     { return false }
+func (op tupleSumOp) CallsExtern() bool    { return false }
+func (op tupleSumOp) OverwritesInput() int { return -1 }
+
+func (op tupleSumOp) WriteHash(h hash.Hash) {
+	fmt.Fprintf(h, "TupleSum(%d, %v, %v)", op.n, op.tuple, op.filled)
+}
+
+func (op tupleSumOp) Hashcode() uint32 { return simpleHash(op) }
+
+func (op tupleSumOp) String() string { return fmt.Sprintf("TupleSum(%d)", op.n) }
+
+func (op tupleSumOp) DiffWRT(inputs int) []bool { return make([]bool, inputs) }
+
+func (op tupleSumOp) SymDiff(inputs Nodes, output, grad *Node) (retVal Nodes, err error) {
+	return nil, nondiffErr(op)
+}
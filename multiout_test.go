@@ -0,0 +1,143 @@
+package gorgonia
+
+import (
+	"fmt"
+	"hash"
+	"testing"
+
+	"github.com/chewxy/hm"
+	"github.com/stretchr/testify/assert"
+	"gorgonia.org/tensor"
+)
+
+// splitOp splits a vector into its two halves, counting its executions.
+type splitOp struct {
+	calls *int
+}
+
+func (op splitOp) Arity() int { return 1 }
+
+func (op splitOp) Type() hm.Type {
+	v := makeTensorType(1, hm.TypeVariable('a'))
+	return hm.NewFnType(v, hm.NewRecordType("", v, v))
+}
+
+func (op splitOp) InferShapes(inputs ...DimSizer) ([]tensor.Shape, error) {
+	half := inputs[0].(tensor.Shape)[0] / 2
+	return []tensor.Shape{{half}, {half}}, nil
+}
+
+func (op splitOp) DoMulti(inputs ...Value) ([]Value, error) {
+	*op.calls++
+	data := inputs[0].Data().([]float64)
+	half := len(data) / 2
+	a := tensor.New(tensor.WithBacking(append([]float64(nil), data[:half]...)))
+	b := tensor.New(tensor.WithBacking(append([]float64(nil), data[half:]...)))
+	return []Value{a, b}, nil
+}
+
+func (op splitOp) CallsExtern() bool         { return false }
+func (op splitOp) WriteHash(h hash.Hash)     { fmt.Fprintf(h, "split") }
+func (op splitOp) Hashcode() uint32          { return simpleHash(op) }
+func (op splitOp) String() string            { return "split" }
+func (op splitOp) DiffWRT(inputs int) []bool { return []bool{true} }
+
+func (op splitOp) SymDiffMulti(inputs, outputs, grads Nodes) (Nodes, error) {
+	for i, g := range grads {
+		if g == nil {
+			grads[i] = NewConstant(tensor.New(tensor.WithShape(outputs[i].Shape()...), tensor.Of(Float64)))
+		}
+	}
+	grad, err := Concat(0, grads...)
+	return Nodes{grad}, err
+}
+
+func TestApplyMultiOp(t *testing.T) {
+	assert := assert.New(t)
+	g := NewGraph()
+	x := NewVector(g, Float64, WithShape(4), WithName("x"))
+	var calls int
+	outputs, err := ApplyMultiOp(splitOp{&calls}, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(outputs, 2)
+	assert.Equal(tensor.Shape{2}, outputs[0].Shape())
+	assert.Equal(Float64, outputs[1].Dtype())
+
+	// both outputs are used, so their gradients are summed element-wise in the tuple
+	cost := Must(Add(Must(Sum(Must(Square(outputs[0])))), Must(Sum(outputs[1]))))
+	grads, err := Grad(cost, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var first Value
+	Read(outputs[0], &first)
+
+	Let(x, tensor.New(tensor.WithBacking([]float64{1, 2, 3, 4})))
+	m := NewTapeMachine(g)
+	defer m.Close()
+	if err := m.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(1, calls, "the op is executed once")
+	assert.Equal([]float64{1, 2}, first.Data())
+	assert.Equal(12.0, cost.Value().Data())
+	assert.Equal([]float64{2, 4, 1, 1}, grads[0].Value().Data())
+}
+
+func TestApplyMultiOp_oneOutput(t *testing.T) {
+	assert := assert.New(t)
+	g := NewGraph()
+	x := NewVector(g, Float64, WithShape(4), WithName("x"))
+	var calls int
+	outputs, err := ApplyMultiOp(splitOp{&calls}, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cost := Must(Sum(Must(Square(outputs[1]))))
+	grads, err := Grad(cost, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	Let(x, tensor.New(tensor.WithBacking([]float64{1, 2, 3, 4})))
+	m := NewLispMachine(g, ExecuteFwdOnly())
+	defer m.Close()
+	if err := m.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(1, calls)
+	assert.Equal(25.0, cost.Value().Data())
+	assert.Equal([]float64{0, 0, 6, 8}, grads[0].Value().Data())
+}
+
+func TestTuple(t *testing.T) {
+	assert := assert.New(t)
+	a := tensor.New(tensor.WithBacking([]float64{1, 2}))
+	tup := Tuple{a, newI(3)}
+
+	assert.Equal(2, tup.Size())
+	assert.Equal(Float64, tup.Dtype())
+	assert.Equal(a.MemSize()+newI(3).MemSize(), tup.MemSize())
+	assert.True(hm.NewRecordType("", makeTensorType(1, Float64), Int).Eq(TypeOf(tup)))
+	assert.Equal("([1  2], 3)", fmt.Sprintf("%v", tup))
+
+	c, err := CloneValue(tup)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clone := c.(Tuple)
+	assert.Equal(a.Data(), clone[0].Data())
+	ZeroValue(clone)
+	assert.Equal([]float64{0, 0}, clone[0].Data())
+	assert.Equal([]float64{1, 2}, a.Data(), "the clone is independent")
+
+	if _, err := Copy(clone, tup); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]float64{1, 2}, clone[0].Data())
+	assert.Equal(3, clone[1].Data())
+	_, err = Copy(Tuple{nil}, tup)
+	assert.Error(err)
+}
@@ -59,6 +59,75 @@ func BinaryXent(output, target *Node) (retVal *Node, err error) {
 	return Neg(retVal)
 }
 
+// FocalLoss is the focal loss of Lin et al. (2017), the cross entropy of the probabilities scaled down for the well
+// classified samples, which lets the rare classes weigh more in the training of an imbalanced classification:
+// 		-Σ y(1-p)^γ log(p)
+// The output is the probabilities, such as a SoftMax, and the target is one-hot. For (samples, classes) matrices, the
+// sum is over the classes, and the result is the loss of every sample; otherwise the loss is elementwise. A γ of 0 is
+// the cross entropy.
+func FocalLoss(output, target *Node, gamma float64) (retVal *Node, err error) {
+	if !output.Shape().Eq(target.Shape()) {
+		return nil, errors.Errorf("Expected the output and the target to have the same shape. Got %v and %v instead", output.Shape(), target.Shape())
+	}
+	if gamma < 0 {
+		return nil, errors.Errorf("Expected a non-negative focusing parameter. Got %v instead", gamma)
+	}
+	var logO, one, omo, g *Node
+	if logO, err = Log(output); err != nil {
+		return nil, errors.Wrap(err, operationError)
+	}
+	if retVal, err = HadamardProd(target, logO); err != nil {
+		return nil, errors.Wrap(err, hadamardProdFail)
+	}
+	if gamma != 0 {
+		if one, err = floatConstant(output.Dtype(), 1); err != nil {
+			return nil, err
+		}
+		if omo, err = Sub(one, output); err != nil {
+			return nil, errors.Wrap(err, subFail)
+		}
+		if g, err = floatConstant(output.Dtype(), gamma); err != nil {
+			return nil, err
+		}
+		if omo, err = Pow(omo, g); err != nil {
+			return nil, errors.Wrap(err, operationError)
+		}
+		if retVal, err = HadamardProd(omo, retVal); err != nil {
+			return nil, errors.Wrap(err, hadamardProdFail)
+		}
+	}
+	if output.IsMatrix() {
+		if retVal, err = Sum(retVal, 1); err != nil {
+			return nil, errors.Wrap(err, operationError)
+		}
+	}
+	return Neg(retVal)
+}
+
+// ClassWeightedMean reduces the losses of the samples to their mean weighted by the weights of their classes:
+// 		Σ w(yᵢ)lᵢ / Σ w(yᵢ)
+// The losses are a vector of the samples, the targets a one-hot (samples, classes) matrix, and the class weights a
+// vector of the classes, usually a constant computed from the frequencies of the classes.
+func ClassWeightedMean(losses, target, classWeights *Node) (retVal *Node, err error) {
+	if !losses.IsVector() || !target.IsMatrix() || !classWeights.IsVector() {
+		return nil, errors.Errorf("Expected the losses, the targets and the class weights to be a vector, a matrix and a vector. Got %v, %v and %v instead", losses.Shape(), target.Shape(), classWeights.Shape())
+	}
+	var weights, total *Node
+	if weights, err = Mul(target, classWeights); err != nil {
+		return nil, errors.Wrap(err, mulFail)
+	}
+	if retVal, err = HadamardProd(losses, weights); err != nil {
+		return nil, errors.Wrap(err, hadamardProdFail)
+	}
+	if retVal, err = Sum(retVal); err != nil {
+		return nil, errors.Wrap(err, operationError)
+	}
+	if total, err = Sum(weights); err != nil {
+		return nil, errors.Wrap(err, operationError)
+	}
+	return HadamardDiv(retVal, total)
+}
+
 // Dropout is a convenience function to implement dropout.
 // It uses randomly zeroes out a *Tensor with a probability drawn from
 // a uniform distribution
@@ -192,37 +261,107 @@ func Rectify(x *Node) (retVal *Node, err error) {
 // Im2Col converts a BCHW image block to columns. The kernel, pad and stride parameter must be shape of size 2, no more no less
 // This poor naming scheme clearly comes from matlab
 func Im2Col(n *Node, kernel, pad, stride, dilation tensor.Shape) (retVal *Node, err error) {
+	var op im2colOp
+	if op, err = makeCheckedIm2ColOp(kernel, pad, stride, dilation); err != nil {
+		return nil, err
+	}
+	return ApplyOp(op, n)
+}
+
+// makeCheckedIm2ColOp checks the parameters of Im2Col before making the im2colOp.
+func makeCheckedIm2ColOp(kernel, pad, stride, dilation tensor.Shape) (im2colOp, error) {
 	if kernel.Dims() != 2 {
-		return nil, errors.Errorf("kernel shape is supposed to have a dim of 2")
+		return im2colOp{}, errors.Errorf("kernel shape is supposed to have a dim of 2")
 	}
 	if pad.Dims() != 2 {
-		return nil, errors.Errorf("pad is supposed to have a dim of 2")
+		return im2colOp{}, errors.Errorf("pad is supposed to have a dim of 2")
 	}
 	if stride.Dims() != 2 {
-		return nil, errors.Errorf("strides is supposed to have a dim of 2")
+		return im2colOp{}, errors.Errorf("strides is supposed to have a dim of 2")
 	}
 	if dilation.Dims() != 2 {
-		return nil, errors.Errorf("dilation is supposed to have a dim of 2")
+		return im2colOp{}, errors.Errorf("dilation is supposed to have a dim of 2")
 	}
 
 	if kernel[0] <= 0 || kernel[1] <= 0 {
-		return nil, errors.Errorf("cannot have negative or 0 in kernel shape")
+		return im2colOp{}, errors.Errorf("cannot have negative or 0 in kernel shape")
 	}
 
 	if stride[0] <= 0 || stride[1] <= 0 {
-		return nil, errors.Errorf("cannot have negative or 0 in stride: %v", stride)
+		return im2colOp{}, errors.Errorf("cannot have negative or 0 in stride: %v", stride)
 	}
 
 	if pad[0] < 0 || pad[1] < 0 {
-		return nil, errors.Errorf("cannot have negative padding")
+		return im2colOp{}, errors.Errorf("cannot have negative padding")
 	}
 
 	if dilation[0] <= 0 || dilation[1] <= 0 {
-		return nil, errors.Errorf("canot have negative or 0 in dilation. %v", dilation)
+		return im2colOp{}, errors.Errorf("canot have negative or 0 in dilation. %v", dilation)
 	}
 
-	op := makeIm2ColOp(kernel[0], kernel[1], pad[0], pad[1], stride[0], stride[1], dilation[0], dilation[1])
-	return ApplyOp(op, n)
+	return makeIm2ColOp(kernel[0], kernel[1], pad[0], pad[1], stride[0], stride[1], dilation[0], dilation[1]), nil
+}
+
+// Unfold extracts the sliding blocks of a BCHW image into a (batch, channel×kernel height×kernel width, blocks) tensor,
+// as the Unfold of PyTorch does. Every column is a block, and the blocks are in row major order. The kernel, pad,
+// stride and dilation are as in Im2Col. The result is differentiable, which makes it possible to build custom
+// convolution-like operations and local attention.
+func Unfold(n *Node, kernel, pad, stride, dilation tensor.Shape) (retVal *Node, err error) {
+	if n.Dims() != 4 {
+		return nil, errors.Errorf("Expected a BCHW image. Got %v instead", n.Shape())
+	}
+	var cols *Node
+	if cols, err = Im2Col(n, kernel, pad, stride, dilation); err != nil {
+		return nil, err
+	}
+	s := cols.Shape()
+	if cols, err = Reshape(cols, tensor.Shape{s[0], s[1] * s[2], s[3]}); err != nil {
+		return nil, err
+	}
+	return Transpose(cols, 0, 2, 1)
+}
+
+// Fold is the inverse of Unfold: it sums the blocks of a (batch, channel×kernel height×kernel width, blocks) tensor
+// into a BCHW image of the given height and width. The blocks that overlap are summed, so Fold is also the transpose
+// of Unfold. The result is differentiable.
+func Fold(n *Node, outputSize, kernel, pad, stride, dilation tensor.Shape) (retVal *Node, err error) {
+	if n.Dims() != 3 {
+		return nil, errors.Errorf("Expected a (batch, channel×kernel, blocks) tensor. Got %v instead", n.Shape())
+	}
+	if outputSize.Dims() != 2 || outputSize[0] <= 0 || outputSize[1] <= 0 {
+		return nil, errors.Errorf("Expected a positive output size of 2 values. Got %v instead", outputSize)
+	}
+	var op im2colOp
+	if op, err = makeCheckedIm2ColOp(kernel, pad, stride, dilation); err != nil {
+		return nil, err
+	}
+
+	s := n.Shape()
+	k := kernel[0] * kernel[1]
+	h, w := op.retHW(outputSize[0], outputSize[1])
+	if s[1]%k != 0 {
+		return nil, errors.Errorf("Expected a multiple of the kernel size %d as the second dimension. Got %v instead", k, s)
+	}
+	if h <= 0 || w <= 0 || s[2] != h*w {
+		return nil, errors.Errorf("Expected %d blocks for an output of %v. Got %v instead", h*w, outputSize, s)
+	}
+
+	var cols *Node
+	if cols, err = Transpose(n, 0, 2, 1); err != nil {
+		return nil, err
+	}
+	if cols, err = Reshape(cols, tensor.Shape{s[0], h, w, s[1]}); err != nil {
+		return nil, err
+	}
+	fold := col2imOp{
+		unpaddedB: s[0],
+		unpaddedC: s[1] / k,
+		unpaddedH: outputSize[0],
+		unpaddedW: outputSize[1],
+
+		im2colOp: op,
+	}
+	return ApplyOp(fold, cols)
 }
 
 // Conv2d is a simple 2D convoution, to be used for CPU computation only. If CuDNN is used, use the CUDAConv2D function.
@@ -234,6 +373,14 @@ func Im2Col(n *Node, kernel, pad, stride, dilation tensor.Shape) (retVal *Node,
 // pad: len(pad) == 2
 // stride: len(stride) == 2
 // dilation: len(dilation) == 2
+//
+// If im has the NHWC layout, the result is a NHWC image, and the filter must have the shape (out channels, height,
+// width, channels). The NHWC convolution is the faster one.
+//
+// The grouped convolutions, whose filters have channels / groups input channels, are those of GroupedConv2d.
+//
+// The float32 convolutions of NCHW images by 3x3 kernels of stride and dilation 1 are computed with the Winograd
+// algorithm (see op_winograd.go). The others are matmuls of the Im2Col columns.
 func Conv2d(im, filter *Node, kernelShape tensor.Shape, pad, stride, dilation []int) (retVal *Node, err error) {
 	group := encoding.NewGroup("Convolution")
 	// niceness for defaults
@@ -243,6 +390,9 @@ func Conv2d(im, filter *Node, kernelShape tensor.Shape, pad, stride, dilation []
 	if dilation == nil {
 		dilation = []int{1, 1}
 	}
+	if im.layout == NHWC {
+		return conv2dNHWC(im, filter, kernelShape, pad, stride, dilation)
+	}
 
 	// checks
 	for _, s := range stride {
@@ -263,6 +413,17 @@ func Conv2d(im, filter *Node, kernelShape tensor.Shape, pad, stride, dilation []
 		}
 	}
 
+	// float32 3x3 kernels of stride and dilation 1 use the Winograd convolution
+	if canWinograd(im, filter, kernelShape, pad, stride, dilation) {
+		s := im.Shape()
+		op := winogradOp{m: winogradTile(s[2]+2*pad[0]-2, s[3]+2*pad[1]-2), padH: pad[0], padW: pad[1]}
+		if retVal, err = ApplyOp(op, im, filter); err != nil {
+			return nil, err
+		}
+		retVal.groups = retVal.groups.Upsert(group)
+		return retVal, nil
+	}
+
 	var colIm *Node
 	if colIm, err = Im2Col(im, kernelShape, pad, stride, dilation); err != nil {
 		return
@@ -314,11 +475,389 @@ func Conv2d(im, filter *Node, kernelShape tensor.Shape, pad, stride, dilation []
 	return ret, err
 }
 
+// conv2dNHWC is the Conv2d of a NHWC image. The columns of a pixel are in the order of the filter, so the result of
+// the matmul is already a NHWC image.
+func conv2dNHWC(im, filter *Node, kernelShape tensor.Shape, pad, stride, dilation []int) (retVal *Node, err error) {
+	group := encoding.NewGroup("Convolution")
+	if im.Dims() != 4 || filter.Dims() != 4 {
+		return nil, errors.Errorf("Expected a BHWC image and a 4D filter. Got %v and %v instead", im.Shape(), filter.Shape())
+	}
+	var op im2colOp
+	if op, err = makeCheckedIm2ColOp(kernelShape, pad, stride, dilation); err != nil {
+		return nil, err
+	}
+	layer, chans := filter.Shape()[0], im.Shape()[3]
+	if filter.Shape()[1] != op.h || filter.Shape()[2] != op.w || filter.Shape()[3] != chans {
+		return nil, errors.Errorf("Expected a filter of shape (%d, %d, %d, %d). Got %v instead", layer, op.h, op.w, chans, filter.Shape())
+	}
+
+	var colIm *Node
+	if colIm, err = ApplyOp(im2colNHWCOp{op}, im); err != nil {
+		return
+	}
+	colIm.groups = colIm.groups.Upsert(group)
+	batch, m, n, z := colIm.Shape()[0], colIm.Shape()[1], colIm.Shape()[2], colIm.Shape()[3]
+
+	var patch, flattened *Node
+	if patch, err = Reshape(colIm, tensor.Shape{batch * m * n, z}); err != nil {
+		return
+	}
+	patch.groups = patch.groups.Upsert(group)
+	if flattened, err = Reshape(filter, tensor.Shape{layer, z}); err != nil {
+		return
+	}
+	flattened.groups = flattened.groups.Upsert(group)
+
+	mm := linAlgBinOp{
+		āBinaryOperator: matMulOperator,
+		transA:          false,
+		transB:          true,
+	}
+	var colImLayer *Node
+	if colImLayer, err = ApplyOp(mm, patch, flattened); err != nil {
+		return
+	}
+	colImLayer.groups = colImLayer.groups.Upsert(group)
+
+	if retVal, err = Reshape(colImLayer, tensor.Shape{batch, m, n, layer}); err != nil {
+		return
+	}
+	retVal.layout = NHWC
+	retVal.groups = retVal.groups.Upsert(group)
+	return retVal, nil
+}
+
+// inNCHW applies fn, which takes NCHW images, to the NHWC image x, and converts the result back to NHWC.
+func inNCHW(x *Node, fn func(*Node) (*Node, error)) (retVal *Node, err error) {
+	if x, err = ToLayout(x, NCHW); err != nil {
+		return nil, err
+	}
+	if retVal, err = fn(x); err != nil {
+		return nil, err
+	}
+	return ToLayout(retVal, NHWC)
+}
+
 // Conv1d is a 1D convlution. It relies on Conv2D
 func Conv1d(in, filter *Node, kernel, pad, stride, dilation int) (*Node, error) {
 	return Conv2d(in, filter, tensor.Shape{1, kernel}, []int{0, pad}, []int{1, stride}, []int{1, dilation})
 }
 
+// DepthwiseConv2d is a 2D convolution where every channel of the input is convolved with its own filters, as the
+// depthwise layers of MobileNet do. These are the properties the inputs must fulfil:
+//
+// im: must have 4D shape. Expected format is BCHW (batch, channel, height, width)
+// filter: must have 4D shape: (channel * multiplier, 1, height, width). The output channel c*multiplier+m is the
+// m-th filter of the input channel c
+// kernelShape, pad, stride and dilation are as in Conv2d
+//
+// A NHWC image is converted to NCHW and back.
+func DepthwiseConv2d(im, filter *Node, kernelShape tensor.Shape, pad, stride, dilation []int) (retVal *Node, err error) {
+	if im.Dims() != 4 {
+		return nil, errors.Errorf("Expected a BCHW image. Got %v instead", im.Shape())
+	}
+	if im.layout == NHWC {
+		return inNCHW(im, func(im *Node) (*Node, error) {
+			return groupedConv2d(im, filter, kernelShape, pad, stride, dilation, im.Shape()[1])
+		})
+	}
+	return groupedConv2d(im, filter, kernelShape, pad, stride, dilation, im.Shape()[1])
+}
+
+// GroupedConv2d is a 2D convolution where the channels of the image are split into groups, and every group is
+// convolved with its own filters, as in ResNeXt. These are the properties the inputs must fulfil:
+//
+// im: must have 4D shape. Expected format is BCHW (batch, channel, height, width)
+// filter: must have 4D shape: (out channels, channels / groups, height, width). The out channels must be a multiple of
+// the groups: every group has out channels / groups filters (the depth multiplier), and the output channels are laid
+// out group by group
+// kernelShape, pad, stride and dilation are as in Conv2d
+//
+// A grouped convolution of 1 group is a Conv2d, and one of as many groups as channels is a DepthwiseConv2d. A NHWC
+// image is converted to NCHW and back, and the filter keeps the NCHW shape.
+func GroupedConv2d(im, filter *Node, kernelShape tensor.Shape, pad, stride, dilation []int, groups int) (retVal *Node, err error) {
+	if im.Dims() != 4 {
+		return nil, errors.Errorf("Expected a BCHW image. Got %v instead", im.Shape())
+	}
+	if im.layout == NHWC {
+		return inNCHW(im, func(im *Node) (*Node, error) {
+			return groupedConv2d(im, filter, kernelShape, pad, stride, dilation, groups)
+		})
+	}
+	return groupedConv2d(im, filter, kernelShape, pad, stride, dilation, groups)
+}
+
+// SeparableConv2d is a depthwise-separable 2D convolution: a DepthwiseConv2d of the image with the depthwise filter,
+// followed by a 1x1 Conv2d with the pointwise filter of shape (out channels, channels * multiplier, 1, 1), whatever
+// the layout of the image.
+func SeparableConv2d(im, depthwise, pointwise *Node, kernelShape tensor.Shape, pad, stride, dilation []int) (retVal *Node, err error) {
+	var dw *Node
+	if dw, err = DepthwiseConv2d(im, depthwise, kernelShape, pad, stride, dilation); err != nil {
+		return nil, err
+	}
+	ps := pointwise.Shape()
+	if ps.Dims() != 4 || ps[2] != 1 || ps[3] != 1 {
+		return nil, errors.Errorf("Expected a (out channels, channels, 1, 1) pointwise filter. Got %v instead", ps)
+	}
+	if dw.layout == NHWC {
+		// the NHWC filter has the same data
+		if pointwise, err = Reshape(pointwise, tensor.Shape{ps[0], 1, 1, ps[1]}); err != nil {
+			return nil, err
+		}
+	}
+	return Conv2d(dw, pointwise, tensor.Shape{1, 1}, []int{0, 0}, []int{1, 1}, []int{1, 1})
+}
+
+// groupedConv2d convolves every group of channels of the image with its own filters. The filter has the shape
+// (out channels, channels / groups, height, width), and the output channels are laid out group by group.
+//
+// The columns of the image are split into groups, so that a batched matmul computes all the groups at once.
+func groupedConv2d(im, filter *Node, kernelShape tensor.Shape, pad, stride, dilation []int, groups int) (retVal *Node, err error) {
+	group := encoding.NewGroup("Convolution")
+	if pad == nil {
+		pad = []int{0, 0}
+	}
+	if dilation == nil {
+		dilation = []int{1, 1}
+	}
+	if im.Dims() != 4 || filter.Dims() != 4 {
+		return nil, errors.Errorf("Expected a BCHW image and a 4D filter. Got %v and %v instead", im.Shape(), filter.Shape())
+	}
+	if kernelShape.Dims() != 2 {
+		return nil, errors.Errorf("kernel shape is supposed to have a dim of 2")
+	}
+	channels, layer := im.Shape()[1], filter.Shape()[0]
+	if groups <= 0 || channels%groups != 0 || layer%groups != 0 {
+		return nil, errors.Errorf("Cannot split %d input channels and %d output channels into %d groups", channels, layer, groups)
+	}
+	if filter.Shape()[1]*groups != channels || filter.Shape()[2] != kernelShape[0] || filter.Shape()[3] != kernelShape[1] {
+		return nil, errors.Errorf("Expected a filter of shape (%d, %d, %d, %d). Got %v instead", layer, channels/groups, kernelShape[0], kernelShape[1], filter.Shape())
+	}
+
+	var colIm *Node
+	if colIm, err = Im2Col(im, kernelShape, pad, stride, dilation); err != nil {
+		return
+	}
+	colIm.groups = colIm.groups.Upsert(group)
+
+	// the columns of a pixel are channel major, so the columns of the different groups are contiguous
+	batch, m, n, z := colIm.Shape()[0], colIm.Shape()[1], colIm.Shape()[2], colIm.Shape()[3]
+	var patch *Node
+	if patch, err = Reshape(colIm, tensor.Shape{batch * m * n, groups, z / groups}); err != nil {
+		return
+	}
+	if patch, err = Transpose(patch, 1, 0, 2); err != nil {
+		return
+	}
+	patch.groups = patch.groups.Upsert(group)
+
+	var flattened *Node
+	if flattened, err = Reshape(filter, tensor.Shape{groups, layer / groups, z / groups}); err != nil {
+		return
+	}
+	flattened.groups = flattened.groups.Upsert(group)
+
+	var colImLayer *Node
+	if colImLayer, err = BatchedMatMul(patch, flattened, false, true); err != nil {
+		return
+	}
+	colImLayer.groups = colImLayer.groups.Upsert(group)
+
+	// (groups, pixels, layer/groups) back to BCHW
+	var res *Node
+	if res, err = Transpose(colImLayer, 1, 0, 2); err != nil {
+		return
+	}
+	if res, err = Reshape(res, tensor.Shape{batch, m, n, layer}); err != nil {
+		return
+	}
+	res.groups = res.groups.Upsert(group)
+	ret, err := Transpose(res, 0, 3, 1, 2)
+	if err != nil {
+		return nil, err
+	}
+	ret.groups = ret.groups.Upsert(group)
+	return ret, nil
+}
+
+// ConvTranspose2d is the transposed 2D convolution (sometimes called deconvolution) of the decoders of segmentation
+// networks and GANs: it is the gradient of the Conv2d of the same parameters, so it upsamples the image by the stride.
+// These are the properties the inputs must fulfil:
+//
+// im: must have 4D shape. Expected format is BCHW (batch, channel, height, width)
+// filter: must have 4D shape: (in channels, out channels, height, width)
+// kernelShape, pad, stride and dilation are as in Conv2d
+// outputPadding: len(outputPadding) == 2. As several sizes of images have the same size after a strided Conv2d, the
+// outputPadding are added to the bottom and the right of the output to pick the larger ones. They must be less than
+// the stride or the dilation.
+//
+// The output has the height (h-1)*stride - 2*pad + dilation*(kernel-1) + outputPadding + 1, and likewise the width.
+// A NHWC image is converted to NCHW and back.
+func ConvTranspose2d(im, filter *Node, kernelShape tensor.Shape, pad, stride, dilation, outputPadding []int) (retVal *Node, err error) {
+	if im.layout == NHWC {
+		return inNCHW(im, func(im *Node) (*Node, error) {
+			return ConvTranspose2d(im, filter, kernelShape, pad, stride, dilation, outputPadding)
+		})
+	}
+	group := encoding.NewGroup("Convolution")
+	// niceness for defaults
+	if pad == nil {
+		pad = []int{0, 0}
+	}
+	if dilation == nil {
+		dilation = []int{1, 1}
+	}
+	if outputPadding == nil {
+		outputPadding = []int{0, 0}
+	}
+
+	// checks
+	if im.Dims() != 4 || filter.Dims() != 4 {
+		return nil, errors.Errorf("Expected a BCHW image and a 4D filter. Got %v and %v instead", im.Shape(), filter.Shape())
+	}
+	if kernelShape.Dims() != 2 || len(pad) != 2 || len(stride) != 2 || len(dilation) != 2 || len(outputPadding) != 2 {
+		return nil, errors.Errorf("Expected kernel shape, pad, stride, dilation and output padding of 2 values. Got %v, %v, %v, %v and %v", kernelShape, pad, stride, dilation, outputPadding)
+	}
+	for i := range stride {
+		if stride[i] <= 0 || kernelShape[i] <= 0 || dilation[i] <= 0 {
+			return nil, errors.Errorf("Cannot use kernel shapes, strides or dilation less than or eq 0: %v, %v, %v", kernelShape, stride, dilation)
+		}
+		if pad[i] < 0 {
+			return nil, errors.Errorf("Cannot use padding of less than 0: %v", pad)
+		}
+		if outputPadding[i] < 0 || (outputPadding[i] >= stride[i] && outputPadding[i] >= dilation[i]) {
+			return nil, errors.Errorf("Expected output padding less than the stride %v or the dilation %v. Got %v", stride, dilation, outputPadding)
+		}
+	}
+	b, inChannels, h, w := im.Shape()[0], im.Shape()[1], im.Shape()[2], im.Shape()[3]
+	outChannels := filter.Shape()[1]
+	if filter.Shape()[0] != inChannels || filter.Shape()[2] != kernelShape[0] || filter.Shape()[3] != kernelShape[1] {
+		return nil, errors.Errorf("Expected a filter of shape (%d, _, %d, %d). Got %v instead", inChannels, kernelShape[0], kernelShape[1], filter.Shape())
+	}
+	outH := (h-1)*stride[0] - 2*pad[0] + dilation[0]*(kernelShape[0]-1) + outputPadding[0] + 1
+	outW := (w-1)*stride[1] - 2*pad[1] + dilation[1]*(kernelShape[1]-1) + outputPadding[1] + 1
+	if outH <= 0 || outW <= 0 {
+		return nil, errors.Errorf("The padding %v is too large for an image of %v", pad, im.Shape())
+	}
+
+	// every pixel of the image scatters its channels, multiplied by the filter, into the columns of the output
+	var pixels *Node
+	if pixels, err = Transpose(im, 0, 2, 3, 1); err != nil {
+		return
+	}
+	if pixels, err = Reshape(pixels, tensor.Shape{b * h * w, inChannels}); err != nil {
+		return
+	}
+	pixels.groups = pixels.groups.Upsert(group)
+
+	var flattened *Node
+	if flattened, err = Reshape(filter, tensor.Shape{inChannels, outChannels * kernelShape[0] * kernelShape[1]}); err != nil {
+		return
+	}
+	flattened.groups = flattened.groups.Upsert(group)
+
+	var cols *Node
+	if cols, err = Mul(pixels, flattened); err != nil {
+		return
+	}
+	if cols, err = Reshape(cols, tensor.Shape{b, h, w, outChannels * kernelShape[0] * kernelShape[1]}); err != nil {
+		return
+	}
+	cols.groups = cols.groups.Upsert(group)
+
+	op := col2imOp{
+		unpaddedB: b,
+		unpaddedC: outChannels,
+		unpaddedH: outH,
+		unpaddedW: outW,
+
+		im2colOp: makeIm2ColOp(kernelShape[0], kernelShape[1], pad[0], pad[1], stride[0], stride[1], dilation[0], dilation[1]),
+	}
+	if retVal, err = ApplyOp(op, cols); err != nil {
+		return nil, errors.Wrap(err, operationError)
+	}
+	retVal.groups = retVal.groups.Upsert(group)
+	return retVal, nil
+}
+
+// PixelShuffle rearranges a (N, C×r×r, H, W) tensor into a (N, C, H×r, W×r) tensor, as the sub-pixel convolutions of
+// super-resolution networks do: the channel c×r×r + i×r + j of the input is the pixel (i, j) of every r×r block of the
+// output channel c. This is the CRD mode of the DepthToSpace of ONNX.
+func PixelShuffle(x *Node, r int) (*Node, error) {
+	if err := checkBlockInput(x, r, true); err != nil {
+		return nil, err
+	}
+	s := x.Shape()
+	n, c, h, w := s[0], s[1]/(r*r), s[2], s[3]
+	return blockRearrange(x, tensor.Shape{n, c, r, r, h, w}, []int{0, 1, 4, 2, 5, 3}, tensor.Shape{n, c, h * r, w * r})
+}
+
+// PixelUnshuffle is the inverse of PixelShuffle: it rearranges a (N, C, H×r, W×r) tensor into a (N, C×r×r, H, W)
+// tensor.
+func PixelUnshuffle(x *Node, r int) (*Node, error) {
+	if err := checkBlockInput(x, r, false); err != nil {
+		return nil, err
+	}
+	s := x.Shape()
+	n, c, h, w := s[0], s[1], s[2]/r, s[3]/r
+	return blockRearrange(x, tensor.Shape{n, c, h, r, w, r}, []int{0, 1, 3, 5, 2, 4}, tensor.Shape{n, c * r * r, h, w})
+}
+
+// DepthToSpace rearranges a (N, r×r×C, H, W) tensor into a (N, C, H×r, W×r) tensor, as the DepthToSpace of TensorFlow
+// and the DCR mode of the DepthToSpace of ONNX do: the channel (i×r + j)×C + c of the input is the pixel (i, j) of
+// every r×r block of the output channel c. Use PixelShuffle for the CRD mode.
+func DepthToSpace(x *Node, blockSize int) (*Node, error) {
+	r := blockSize
+	if err := checkBlockInput(x, r, true); err != nil {
+		return nil, err
+	}
+	s := x.Shape()
+	n, c, h, w := s[0], s[1]/(r*r), s[2], s[3]
+	return blockRearrange(x, tensor.Shape{n, r, r, c, h, w}, []int{0, 3, 4, 1, 5, 2}, tensor.Shape{n, c, h * r, w * r})
+}
+
+// SpaceToDepth is the inverse of DepthToSpace: it rearranges a (N, C, H×r, W×r) tensor into a (N, r×r×C, H, W) tensor,
+// which downsamples an image without losing any value.
+func SpaceToDepth(x *Node, blockSize int) (*Node, error) {
+	r := blockSize
+	if err := checkBlockInput(x, r, false); err != nil {
+		return nil, err
+	}
+	s := x.Shape()
+	n, c, h, w := s[0], s[1], s[2]/r, s[3]/r
+	return blockRearrange(x, tensor.Shape{n, c, h, r, w, r}, []int{0, 3, 5, 1, 2, 4}, tensor.Shape{n, r * r * c, h, w})
+}
+
+// checkBlockInput checks that x is a BCHW tensor whose channels (if depth is true) or height and width can be split
+// into blocks of r.
+func checkBlockInput(x *Node, r int, depth bool) error {
+	if x.Dims() != 4 {
+		return errors.Errorf("Expected a BCHW tensor. Got %v instead", x.Shape())
+	}
+	if r <= 0 {
+		return errors.Errorf("Expected a positive block size. Got %d instead", r)
+	}
+	s := x.Shape()
+	if depth && s[1]%(r*r) != 0 {
+		return errors.Errorf("Expected a number of channels divisible by %d. Got %v instead", r*r, s)
+	}
+	if !depth && (s[2]%r != 0 || s[3]%r != 0) {
+		return errors.Errorf("Expected a height and a width divisible by %d. Got %v instead", r, s)
+	}
+	return nil
+}
+
+// blockRearrange splits x into blocks, and transposes them into the given shape.
+func blockRearrange(x *Node, blocks tensor.Shape, pattern []int, to tensor.Shape) (retVal *Node, err error) {
+	if retVal, err = Reshape(x, blocks); err != nil {
+		return nil, err
+	}
+	if retVal, err = Transpose(retVal, pattern...); err != nil {
+		return nil, err
+	}
+	return Reshape(retVal, to)
+}
+
 // MaxPool2D applies the kernel filter to the input node.
 // The pad slice can have two different lengths.
 //
@@ -329,7 +868,12 @@ func Conv1d(in, filter *Node, kernel, pad, stride, dilation int) (*Node, error)
 // - if len(pad) == 4, padding is explicit and can be asymmetric.
 //   paddedOutputH = pad[0] + inputH + pad[1]
 //   paddedOutputW = pad[2] + inputW + pad[3]
+//
+// A NHWC image is converted to NCHW and back.
 func MaxPool2D(x *Node, kernel tensor.Shape, pad, stride []int) (*Node, error) {
+	if x.layout == NHWC {
+		return inNCHW(x, func(x *Node) (*Node, error) { return MaxPool2D(x, kernel, pad, stride) })
+	}
 	group := encoding.NewGroup("Maxpool")
 	xShape := x.Shape()
 	h, w := xShape[2], xShape[3]
@@ -373,6 +917,13 @@ func BatchNorm(x, scale, bias *Node, momentum, epsilon float64) (retVal, γ, β
 	if err != nil {
 		return nil, nil, nil, nil, err
 	}
+	// the op normalizes NCHW images: a NHWC image is converted, and the result converted back
+	orig := x
+	if x.layout == NHWC {
+		if x, err = ToLayout(x, NCHW); err != nil {
+			return nil, nil, nil, nil, err
+		}
+	}
 	batches := x.Shape()[0]
 	channels := x.Shape()[1]
 	spatialDim := x.Shape().TotalSize() / (channels * batches)
@@ -426,15 +977,20 @@ func BatchNorm(x, scale, bias *Node, momentum, epsilon float64) (retVal, γ, β
 	dims := x.Shape().Dims()
 
 	if scale == nil {
-		scale = NewTensor(g, dt, dims, WithShape(x.Shape().Clone()...), WithName(x.Name()+"_γ"), WithInit(GlorotN(1.0)))
+		scale = NewTensor(g, dt, dims, WithShape(orig.Shape().Clone()...), WithName(orig.Name()+"_γ"), WithInit(GlorotN(1.0)))
 	}
 	if bias == nil {
-		bias = NewTensor(g, dt, dims, WithShape(x.Shape().Clone()...), WithName(x.Name()+"_β"), WithInit(GlorotN(1.0)))
+		bias = NewTensor(g, dt, dims, WithShape(orig.Shape().Clone()...), WithName(orig.Name()+"_β"), WithInit(GlorotN(1.0)))
 	}
 
 	if retVal, err = ApplyOp(op, x); err != nil {
 		return nil, nil, nil, nil, err
 	}
+	if orig.layout == NHWC {
+		if retVal, err = ToLayout(retVal, NHWC); err != nil {
+			return nil, nil, nil, nil, err
+		}
+	}
 	if retVal, err = HadamardProd(scale, retVal); err != nil {
 		return nil, nil, nil, nil, err
 	}
@@ -445,6 +1001,68 @@ func BatchNorm(x, scale, bias *Node, momentum, epsilon float64) (retVal, γ, β
 
 // GlobalAveragePool2D consumes an input tensor X and applies average pooling across the values in the same channel.
 // The expected input shape is BCHW where B is the batch size, C is the number of channels, and H and W are the height and the width of the data.
+// With a NHWC image, the result is a (B, 1, 1, C) NHWC image.
 func GlobalAveragePool2D(x *Node) (*Node, error) {
+	if x.layout == NHWC {
+		s := x.Shape()
+		mean, err := Mean(x, 1, 2)
+		if err != nil {
+			return nil, err
+		}
+		if mean, err = Reshape(mean, tensor.Shape{s[0], 1, 1, s[3]}); err != nil {
+			return nil, err
+		}
+		mean.layout = NHWC
+		return mean, nil
+	}
 	return ApplyOp(&globalAveragePoolOp{}, x)
 }
+
+// ROIAlign pools the regions of interest of the (N, C, H, W) feature maps x into (K, C, outH, outW) features, as the
+// heads of Mask R-CNN do. boxes is a (K, 5) matrix of rows (batch index, x1, y1, x2, y2) in the coordinates of the
+// image, which spatialScale scales to the coordinates of the feature maps: 1/16 for a feature map of stride 16.
+//
+// Every bin of a box averages samplingRatio×samplingRatio bilinearly interpolated points, or an adaptive number of
+// points if samplingRatio is 0. If aligned is true, the boxes are shifted by half a cell, so that the pixel model is
+// consistent; this is the aligned=True of torchvision. The result is differentiable with respect to x.
+func ROIAlign(x, boxes *Node, outH, outW int, spatialScale float64, samplingRatio int, aligned bool) (*Node, error) {
+	if err := checkROIInputs(x, boxes, outH, outW); err != nil {
+		return nil, err
+	}
+	if samplingRatio < 0 {
+		return nil, errors.Errorf("Expected a non-negative sampling ratio. Got %d instead", samplingRatio)
+	}
+	op := roiOp{which: roiAlignType, outH: outH, outW: outW, scale: spatialScale, samplingRatio: samplingRatio, aligned: aligned}
+	return ApplyOp(op, x, boxes)
+}
+
+// ROIPool pools the regions of interest of the (N, C, H, W) feature maps x into (K, C, outH, outW) features, taking
+// the maximum of every bin, as the heads of Fast R-CNN and Faster R-CNN do. The boxes are as in ROIAlign, but they are
+// rounded to the cells of the feature maps. The empty bins are 0. The result is differentiable with respect to x.
+func ROIPool(x, boxes *Node, outH, outW int, spatialScale float64) (*Node, error) {
+	if err := checkROIInputs(x, boxes, outH, outW); err != nil {
+		return nil, err
+	}
+	return ApplyOp(roiOp{which: roiPoolType, outH: outH, outW: outW, scale: spatialScale}, x, boxes)
+}
+
+func checkROIInputs(x, boxes *Node, outH, outW int) error {
+	if x.Dims() != 4 {
+		return errors.Errorf("Expected (N, C, H, W) feature maps. Got %v instead", x.Shape())
+	}
+	if boxes.Dims() != 2 || boxes.Shape()[1] != 5 {
+		return errors.Errorf("Expected (K, 5) boxes. Got %v instead", boxes.Shape())
+	}
+	if outH <= 0 || outW <= 0 {
+		return errors.Errorf("Expected a positive output size. Got (%d, %d) instead", outH, outW)
+	}
+	for _, n := range []*Node{x, boxes} {
+		if err := checkFloatNode(n); err != nil {
+			return errors.Wrap(err, operationError)
+		}
+	}
+	if x.Dtype() != boxes.Dtype() {
+		return errors.Errorf("Expected the boxes to be of the Dtype of the feature maps, %v. Got %v instead", x.Dtype(), boxes.Dtype())
+	}
+	return nil
+}
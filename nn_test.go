@@ -3,6 +3,7 @@ package gorgonia
 import (
 	"fmt"
 	"io/ioutil"
+	"math"
 	"runtime"
 	"testing"
 
@@ -598,3 +599,396 @@ func TestGlobalAveragePool2D_fwdPass(t *testing.T) {
 		assert.InDeltaSlice(expectedOutput.Data(), output.Value().Data(), 1e-6, "the two tensors should be equal.")
 	}
 }
+
+// naiveConv2d is the direct grouped convolution of a BCHW image by a (out channels, channels/groups, kh, kw) filter.
+func naiveConv2d(im, filter *tensor.Dense, pad, stride, dilation []int, groups int) []float64 {
+	is, fs := im.Shape(), filter.Shape()
+	b, c, h, w := is[0], is[1], is[2], is[3]
+	o, cg, kh, kw := fs[0], fs[1], fs[2], fs[3]
+	og := o / groups
+	oh := (h+2*pad[0]-dilation[0]*(kh-1)-1)/stride[0] + 1
+	ow := (w+2*pad[1]-dilation[1]*(kw-1)-1)/stride[1] + 1
+	x, f := im.Data().([]float64), filter.Data().([]float64)
+	ret := make([]float64, b*o*oh*ow)
+	for n := 0; n < b; n++ {
+		for oc := 0; oc < o; oc++ {
+			g := oc / og
+			for i := 0; i < oh; i++ {
+				for j := 0; j < ow; j++ {
+					var sum float64
+					for k := 0; k < cg; k++ {
+						ic := g*cg + k
+						for r := 0; r < kh; r++ {
+							for s := 0; s < kw; s++ {
+								y := i*stride[0] - pad[0] + r*dilation[0]
+								z := j*stride[1] - pad[1] + s*dilation[1]
+								if y < 0 || y >= h || z < 0 || z >= w {
+									continue
+								}
+								sum += x[((n*c+ic)*h+y)*w+z] * f[((oc*cg+k)*kh+r)*kw+s]
+							}
+						}
+					}
+					ret[((n*o+oc)*oh+i)*ow+j] = sum
+				}
+			}
+		}
+	}
+	return ret
+}
+
+func TestDepthwiseConv2d(t *testing.T) {
+	assert := assert.New(t)
+	g := NewGraph()
+	imT := tensor.New(tensor.WithShape(2, 3, 5, 6), tensor.WithBacking(tensor.Random(tensor.Float64, 2*3*5*6)))
+	filterT := tensor.New(tensor.WithShape(6, 1, 3, 3), tensor.WithBacking(tensor.Random(tensor.Float64, 6*9)))
+	im := NodeFromAny(g, imT, WithName("im"))
+	filter := NodeFromAny(g, filterT, WithName("filter"))
+	pad, stride, dilation := []int{1, 0}, []int{2, 1}, []int{1, 2}
+
+	conv, err := DepthwiseConv2d(im, filter, tensor.Shape{3, 3}, pad, stride, dilation)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(tensor.Shape{2, 6, 3, 2}, conv.Shape())
+	if _, err = Grad(Must(Sum(conv)), im, filter); err != nil {
+		t.Fatal(err)
+	}
+	pointwise := NewTensor(g, Float64, 4, WithShape(4, 6, 1, 1), WithInit(RangedFrom(0)))
+	sep, err := SeparableConv2d(im, filter, pointwise, tensor.Shape{3, 3}, pad, stride, dilation)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(tensor.Shape{2, 4, 3, 2}, sep.Shape())
+
+	m := NewTapeMachine(g)
+	defer m.Close()
+	if err = m.RunAll(); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	assert.InDeltaSlice(naiveConv2d(imT, filterT, pad, stride, dilation, 3), conv.Value().Data(), 1e-12)
+	assert.Equal(imT.Shape(), im.Deriv().Value().Shape())
+	assert.Equal(filterT.Shape(), filter.Deriv().Value().Shape())
+
+	_, err = DepthwiseConv2d(im, NewTensor(g, Float64, 4, WithShape(4, 1, 3, 3)), tensor.Shape{3, 3}, pad, stride, dilation)
+	assert.Error(err)
+	_, err = DepthwiseConv2d(im, NewTensor(g, Float64, 4, WithShape(6, 3, 3, 3)), tensor.Shape{3, 3}, pad, stride, dilation)
+	assert.Error(err)
+}
+
+func TestGroupedConv2d(t *testing.T) {
+	assert := assert.New(t)
+	for _, tc := range []struct {
+		chans, layers, groups int
+		pad, stride, dilation []int
+	}{
+		{4, 6, 2, []int{1, 1}, []int{1, 1}, []int{1, 1}},
+		{4, 4, 4, []int{2, 1}, []int{1, 2}, []int{2, 1}},
+		{6, 12, 3, []int{0, 2}, []int{2, 1}, []int{2, 3}},
+	} {
+		imT := tensor.New(tensor.WithShape(2, tc.chans, 7, 6), tensor.WithBacking(tensor.Random(tensor.Float64, 2*tc.chans*7*6)))
+		filterT := tensor.New(tensor.WithShape(tc.layers, tc.chans/tc.groups, 3, 2), tensor.WithBacking(tensor.Random(tensor.Float64, tc.layers*tc.chans/tc.groups*6)))
+		expected := naiveConv2d(imT, filterT, tc.pad, tc.stride, tc.dilation, tc.groups)
+		weights := tensor.Random(tensor.Float64, len(expected)).([]float64)
+
+		// the gradients of the weighted sum of the outputs, by central differences of the naive convolution
+		numeric := func(x *tensor.Dense) []float64 {
+			data := x.Data().([]float64)
+			retVal := make([]float64, len(data))
+			for i, v := range data {
+				data[i] = v + 1e-6
+				plus := dot(naiveConv2d(imT, filterT, tc.pad, tc.stride, tc.dilation, tc.groups), weights)
+				data[i] = v - 1e-6
+				minus := dot(naiveConv2d(imT, filterT, tc.pad, tc.stride, tc.dilation, tc.groups), weights)
+				data[i] = v
+				retVal[i] = (plus - minus) / 2e-6
+			}
+			return retVal
+		}
+		dIm, dFilter := numeric(imT), numeric(filterT)
+
+		g := NewGraph()
+		im := NodeFromAny(g, imT, WithName("im"))
+		filter := NodeFromAny(g, filterT, WithName("filter"))
+		conv, err := GroupedConv2d(im, filter, tensor.Shape{3, 2}, tc.pad, tc.stride, tc.dilation, tc.groups)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// a NHWC image keeps the NCHW filter
+		nhwc, err := GroupedConv2d(Must(ToLayout(im, NHWC)), filter, tensor.Shape{3, 2}, tc.pad, tc.stride, tc.dilation, tc.groups)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(NHWC, nhwc.Layout())
+		w := NodeFromAny(g, tensor.New(tensor.WithShape(conv.Shape()...), tensor.WithBacking(weights)), WithName("weights"))
+		grads, err := Grad(Must(Sum(Must(HadamardProd(conv, w)))), im, filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var nhwcVal Value
+		Read(Must(ToLayout(nhwc, NCHW)), &nhwcVal)
+		m := NewTapeMachine(g)
+		if err = m.RunAll(); err != nil {
+			t.Fatalf("%+v", err)
+		}
+		m.Close()
+		assert.InDeltaSlice(expected, conv.Value().Data(), 1e-12, "%v", tc)
+		assert.InDeltaSlice(expected, nhwcVal.Data(), 1e-12, "%v", tc)
+		assert.InDeltaSlice(dIm, grads[0].Value().Data(), 1e-6, "%v", tc)
+		assert.InDeltaSlice(dFilter, grads[1].Value().Data(), 1e-6, "%v", tc)
+	}
+
+	g := NewGraph()
+	im := NewTensor(g, Float64, 4, WithShape(1, 4, 5, 5), WithName("im"))
+	_, err := GroupedConv2d(im, NewTensor(g, Float64, 4, WithShape(6, 2, 3, 3)), tensor.Shape{3, 3}, nil, []int{1, 1}, nil, 4)
+	assert.Error(err)
+	_, err = GroupedConv2d(im, NewTensor(g, Float64, 4, WithShape(3, 2, 3, 3)), tensor.Shape{3, 3}, nil, []int{1, 1}, nil, 2)
+	assert.Error(err)
+	// the groups are not inferred by Conv2d: the filter of 2 of the 4 channels is a mismatch
+	_, err = Conv2d(im, NewTensor(g, Float64, 4, WithShape(6, 2, 3, 3)), tensor.Shape{3, 3}, nil, []int{1, 1}, nil)
+	assert.Error(err)
+}
+
+func TestIm2ColHashDilation(t *testing.T) {
+	// the columns of different dilations must not be deduplicated by the graph
+	g := NewGraph()
+	im := NewTensor(g, Float64, 4, WithShape(1, 1, 5, 5), WithName("im"))
+	a := Must(Im2Col(im, tensor.Shape{3, 3}, []int{2, 2}, []int{4, 4}, []int{1, 1}))
+	b := Must(Im2Col(im, tensor.Shape{3, 3}, []int{2, 2}, []int{4, 4}, []int{2, 2}))
+	assert.Equal(t, a.Shape(), b.Shape())
+	assert.NotEqual(t, a, b)
+}
+
+func TestConvTranspose2d(t *testing.T) {
+	assert := assert.New(t)
+	for _, tc := range []struct {
+		pad, stride, dilation, outputPadding []int
+		h, w                                 int
+	}{
+		{[]int{0, 0}, []int{1, 1}, []int{1, 1}, []int{0, 0}, 6, 4},
+		{[]int{1, 0}, []int{2, 3}, []int{1, 1}, []int{1, 2}, 8, 10},
+		{[]int{1, 2}, []int{2, 2}, []int{2, 1}, []int{0, 1}, 9, 3},
+	} {
+		// the transposed convolution of gy by the filter is the gradient of the Conv2d of x by the same filter
+		g := NewGraph()
+		gyT := tensor.New(tensor.WithShape(2, 3, 4, 3), tensor.WithBacking(tensor.Random(tensor.Float64, 2*3*4*3)))
+		filterT := tensor.New(tensor.WithShape(3, 2, 3, 2), tensor.WithBacking(tensor.Random(tensor.Float64, 3*2*3*2)))
+		gy := NodeFromAny(g, gyT, WithName("gy"))
+		filter := NodeFromAny(g, filterT, WithName("filter"))
+		x := NewTensor(g, Float64, 4, WithShape(2, 2, tc.h, tc.w), WithName("x"), WithInit(Gaussian(0, 1)))
+
+		deconv, err := ConvTranspose2d(gy, filter, tensor.Shape{3, 2}, tc.pad, tc.stride, tc.dilation, tc.outputPadding)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(x.Shape(), deconv.Shape())
+		conv, err := Conv2d(x, filter, tensor.Shape{3, 2}, tc.pad, tc.stride, tc.dilation)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(gy.Shape(), conv.Shape())
+		convGrads, err := Grad(Must(Sum(Must(HadamardProd(conv, gy)))), x)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// and the other way around, the gradient of the transposed convolution is the convolution
+		deconvGrads, err := Grad(Must(Sum(Must(HadamardProd(deconv, x)))), gy)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		m := NewTapeMachine(g)
+		if err = m.RunAll(); err != nil {
+			t.Fatalf("%+v", err)
+		}
+		assert.InDeltaSlice(convGrads[0].Value().Data(), deconv.Value().Data(), 1e-12)
+		assert.InDeltaSlice(conv.Value().Data(), deconvGrads[0].Value().Data(), 1e-12)
+		m.Close()
+	}
+
+	g := NewGraph()
+	im := NewTensor(g, Float64, 4, WithShape(1, 3, 4, 4))
+	filter := NewTensor(g, Float64, 4, WithShape(3, 2, 3, 3))
+	_, err := ConvTranspose2d(im, filter, tensor.Shape{3, 3}, nil, []int{2, 2}, nil, []int{2, 0})
+	assert.Error(err)
+	_, err = ConvTranspose2d(im, NewTensor(g, Float64, 4, WithShape(2, 3, 3, 3)), tensor.Shape{3, 3}, nil, []int{2, 2}, nil, nil)
+	assert.Error(err)
+}
+
+func TestUnfoldFold(t *testing.T) {
+	assert := assert.New(t)
+	g := NewGraph()
+	xT := tensor.New(tensor.WithShape(1, 2, 3, 3), tensor.WithBacking(tensor.Range(tensor.Float64, 0, 18)))
+	x := NodeFromAny(g, xT, WithName("x"))
+	kernel, pad, stride, dilation := tensor.Shape{2, 2}, tensor.Shape{0, 0}, tensor.Shape{1, 1}, tensor.Shape{1, 1}
+
+	unfolded, err := Unfold(x, kernel, pad, stride, dilation)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(tensor.Shape{1, 8, 4}, unfolded.Shape())
+	folded, err := Fold(unfolded, tensor.Shape{3, 3}, kernel, pad, stride, dilation)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(xT.Shape(), folded.Shape())
+	grads, err := Grad(Must(Sum(folded)), x)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewTapeMachine(g)
+	defer m.Close()
+	if err = m.RunAll(); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	// the first row holds the top left value of every 2x2 block of the first channel
+	assert.Equal([]float64{0, 1, 3, 4}, unfolded.Value().Data().([]float64)[:4])
+	assert.Equal([]float64{9, 10, 12, 13}, unfolded.Value().Data().([]float64)[16:20])
+	// folding sums the overlapping blocks, so every value is multiplied by the number of blocks it is in
+	counts := []float64{1, 2, 1, 2, 4, 2, 1, 2, 1}
+	var expected []float64
+	for i, v := range xT.Data().([]float64) {
+		expected = append(expected, v*counts[i%9])
+	}
+	assert.Equal(expected, folded.Value().Data())
+	assert.Equal(append(counts, counts...), grads[0].Value().Data())
+
+	_, err = Fold(unfolded, tensor.Shape{4, 4}, kernel, pad, stride, dilation)
+	assert.Error(err)
+	_, err = Fold(unfolded, tensor.Shape{3, 3}, tensor.Shape{3, 3}, pad, stride, dilation)
+	assert.Error(err)
+	_, err = Unfold(unfolded, kernel, pad, stride, dilation)
+	assert.Error(err)
+}
+
+func TestPixelShuffle(t *testing.T) {
+	assert := assert.New(t)
+	g := NewGraph()
+	const n, c, h, w, r = 2, 3, 2, 3, 2
+	xT := tensor.New(tensor.WithShape(n, c*r*r, h, w), tensor.WithBacking(tensor.Range(tensor.Float64, 0, n*c*r*r*h*w)))
+	x := NodeFromAny(g, xT, WithName("x"))
+
+	shuffled, err := PixelShuffle(x, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(tensor.Shape{n, c, h * r, w * r}, shuffled.Shape())
+	d2s, err := DepthToSpace(x, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unshuffled, err := PixelUnshuffle(shuffled, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s2d, err := SpaceToDepth(d2s, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	weights := NewTensor(g, Float64, 4, WithShape(n, c, h*r, w*r), WithName("weights"), WithInit(RangedFrom(0)))
+	grads, err := Grad(Must(Sum(Must(HadamardProd(shuffled, weights)))), x)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewTapeMachine(g)
+	defer m.Close()
+	if err = m.RunAll(); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	for b := 0; b < n; b++ {
+		for k := 0; k < c; k++ {
+			for y := 0; y < h*r; y++ {
+				for z := 0; z < w*r; z++ {
+					i, j := y%r, z%r
+					crd, _ := xT.At(b, k*r*r+i*r+j, y/r, z/r)
+					dcr, _ := xT.At(b, (i*r+j)*c+k, y/r, z/r)
+					got, _ := shuffled.Value().(tensor.Tensor).At(b, k, y, z)
+					assert.Equal(crd, got)
+					got, _ = d2s.Value().(tensor.Tensor).At(b, k, y, z)
+					assert.Equal(dcr, got)
+				}
+			}
+		}
+	}
+	assert.Equal(xT.Data(), unshuffled.Value().Data())
+	assert.Equal(xT.Data(), s2d.Value().Data())
+	// the gradient of a shuffle is the unshuffled gradient
+	unshuffledWeights, err := PixelUnshuffle(NodeFromAny(NewGraph(), weights.Value()), r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m2 := NewTapeMachine(unshuffledWeights.g)
+	defer m2.Close()
+	if err = m2.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(unshuffledWeights.Value().Data(), grads[0].Value().Data())
+
+	_, err = PixelShuffle(x, 3)
+	assert.Error(err)
+	_, err = SpaceToDepth(x, 4)
+	assert.Error(err)
+	_, err = DepthToSpace(x, 0)
+	assert.Error(err)
+}
+
+func TestFocalLoss(t *testing.T) {
+	assert := assert.New(t)
+	g := NewGraph()
+	probs := NewMatrix(g, Float64, WithShape(2, 2), WithName("probs"), WithValue(tensor.New(tensor.WithShape(2, 2), tensor.WithBacking([]float64{0.8, 0.2, 0.4, 0.6}))))
+	target := NewConstant(tensor.New(tensor.WithShape(2, 2), tensor.WithBacking([]float64{1, 0, 0, 1})), In(g), WithName("target"))
+	weights := NewConstant(tensor.New(tensor.WithShape(2), tensor.WithBacking([]float64{1, 3})), In(g), WithName("weights"))
+	losses, err := FocalLoss(probs, target, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cost, err := ClassWeightedMean(losses, target, weights)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var lossesVal Value
+	Read(losses, &lossesVal)
+	if _, err = Grad(cost, probs); err != nil {
+		t.Fatal(err)
+	}
+	m := NewTapeMachine(g)
+	defer m.Close()
+	if err = m.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+
+	l0, l1 := -0.04*math.Log(0.8), -0.16*math.Log(0.6)
+	assert.InDeltaSlice([]float64{l0, l1}, lossesVal.Data(), 1e-12)
+	assert.InDelta((l0+3*l1)/4, cost.Value().Data(), 1e-12)
+
+	// d/dp -(1-p)^γ log(p) = γ(1-p)^(γ-1) log(p) - (1-p)^γ/p, for the probabilities of the targets only
+	grad, err := probs.Grad()
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := func(p float64) float64 { return 2*(1-p)*math.Log(p) - (1-p)*(1-p)/p }
+	assert.InDeltaSlice([]float64{d(0.8) / 4, 0, 0, 3 * d(0.6) / 4}, grad.Data(), 1e-12)
+
+	// γ = 0 is the cross entropy
+	xent, err := FocalLoss(probs, target, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m2 := NewTapeMachine(g)
+	defer m2.Close()
+	if err = m2.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+	assert.InDeltaSlice([]float64{-math.Log(0.8), -math.Log(0.6)}, xent.Value().Data(), 1e-12)
+
+	// errors
+	_, err = FocalLoss(probs, weights, 2)
+	assert.Error(err)
+	_, err = FocalLoss(probs, target, -1)
+	assert.Error(err)
+	_, err = ClassWeightedMean(probs, target, weights)
+	assert.Error(err)
+}
@@ -39,10 +39,16 @@ type Node struct {
 	// inputs are bound to values directly
 	boundTo Value
 	dataOn  Device // where is the data on
+	reuse   Value  // caller provided buffer that the result of executing the node should be written into
+	layout  Layout // the layout of the dimensions of an image (see layout.go)
 
 	// to track derivations
-	derivOf Nodes
-	deriv   *Node
+	derivOf   Nodes
+	deriv     *Node
+	gradHooks []GradHook // applied to the gradient of the node during symbolic differentiation
+
+	// err is only set on the nodes returned by failed fluent method calls. Such nodes do not belong to any graph.
+	err error
 
 	// for hashing nodes
 	id   int64 // id is the ID at which the node is added to the graph
@@ -53,6 +59,13 @@ type Node struct {
 	unchanged     bool // has this node been modified
 	isStmt        bool // is this a statement node
 	ofInterest    bool // is this node of particular interest? (for debugging)
+	placed        bool // was the device of the node set with WithDevice
+	frozen        bool // are the gradients stopped at this node (see Freeze)
+	hyper         bool // is the node a hyperparameter (see NewHyperparameter)
+
+	// for the construction stacks and the anomaly detection (see WithConstructionStacks and SetDetectAnomaly)
+	stack      []uintptr // the program counters of the construction of the node
+	backwardOf *Node     // the forward node whose differentiation created the node
 }
 
 // NodeConsOpt is a function that provides construction options for any Node.
@@ -149,6 +162,39 @@ func WithValue(any interface{}) NodeConsOpt {
 	return f
 }
 
+// WithReuse is a node construction option that hints to the VMs that the result of executing the *Node should be written
+// into the provided Value, instead of a VM-managed (or freshly allocated) one. This cuts down on allocations when the results
+// of a graph are read out repeatedly (e.g. in inference pipelines).
+//
+// The provided Value is clobbered on every run of the VM. This function may panic if:
+//	- the node is an input node (input nodes are bound, not executed)
+//	- the Dtype or Shape of the Value does not match the Dtype or Shape of the *Node.
+func WithReuse(v Value) NodeConsOpt {
+	f := func(n *Node) {
+		if v == nil {
+			n.reuse = nil
+			return
+		}
+		if n.isInput() {
+			panic(fmt.Sprintf("Cannot reuse a Value for input node %v. Use Let() instead", n))
+		}
+		if n.t != nil {
+			dt, err := dtypeOf(n.t)
+			if err != nil {
+				panic(err)
+			}
+			if dt != v.Dtype() {
+				panic(fmt.Sprintf("TypeError: Node %v has Dtype %v. Reused Value has Dtype %v", n, dt, v.Dtype()))
+			}
+		}
+		if n.shape != nil && !n.shape.Eq(v.Shape()) {
+			panic(fmt.Sprintf("Node %v has shape %v. Reused Value has shape %v", n, n.shape, v.Shape()))
+		}
+		n.reuse = v
+	}
+	return f
+}
+
 // WithGrad is a node construction option that binds the value to the *Node. This function may panic if:
 //	- There isn't already a value associated with the node (.boundTo == nil)
 //	- The type of the Value does not match the value of the node.
@@ -215,6 +261,20 @@ func WithShape(shp ...int) NodeConsOpt {
 	return f
 }
 
+// WithDevice is a node construction option that places the *Node on the given device: the node is executed there, and
+// its value is kept there. The VMs transfer the values between the devices where a node reads the value of a node placed
+// elsewhere. It may also be applied to the nodes returned by the operations:
+//		z := Must(Mul(x, y))
+//		WithDevice(CPU)(z)
+// The nodes that are not placed are placed automatically when the graph is compiled. See PlanPlacement.
+func WithDevice(dev Device) NodeConsOpt {
+	f := func(n *Node) {
+		n.dataOn = dev
+		n.placed = true
+	}
+	return f
+}
+
 // WithGroupName is a node construction option to group a *Node within a particular group. This option is useful for debugging with graphs.
 // This function is deprecated and will proabably be remove in the next version.
 func WithGroupName(name string) NodeConsOpt {
@@ -276,13 +336,24 @@ func NewUniqueNode(opts ...NodeConsOpt) *Node {
 	if n.g == nil {
 		return n
 	}
+	g := n.g
+	g.lock()
 	n.fixChildren() // ensure that all the kids are in the graph first
+	if n.isInput() {
+		// the name of an input is part of its hash, so it has to be scoped before it is added
+		n.name = g.scopedName(n.name)
+	}
 
-	m := n.g.AddNode(n)
+	m := g.addNode(n)
+	if n == m && g.recordsStacks() {
+		m.stack = callers()
+		m.backwardOf = g.backwardOf
+	}
+	m.fixEdges()
+	g.unlock()
 	if n != m {
 		returnNode(n)
 	}
-	m.fixEdges()
 	return m
 }
 
@@ -295,8 +366,14 @@ func (n *Node) Node() *Node { return n }
 // Nodes returns n as a slice of *Node. Again, this is mostly useful for interfaces
 func (n *Node) Nodes() Nodes { return Nodes{n} }
 
-// Err always returns nil. However, this method is implemented to enable nicer composition of functions
-func (n *Node) Err() error { return nil }
+// Err returns the error held by the node. Only nodes returned by a failed fluent method call (e.g. `x.Add(y)`) hold an error;
+// nodes in a graph always return nil. This also enables nicer composition of functions.
+func (n *Node) Err() error {
+	if n == nil {
+		return nil
+	}
+	return n.err
+}
 
 func (n *Node) DataSize() int { return n.Shape().TotalSize() }
 
@@ -315,7 +392,7 @@ func (n *Node) isRoot() bool {
 	if n.g == nil {
 		return true
 	}
-	return len(n.g.to[n]) == 0
+	return len(n.g.to.get(n)) == 0
 }
 
 // IsVar returns true if  the node represents a differentiable variable (i.e. it's an argument to the function that is not a statement)
@@ -414,6 +491,12 @@ func (n *Node) Clone() (retVal interface{}) {
 	n2.unchanged = n.unchanged
 	n2.isStmt = n.isStmt
 	n2.ofInterest = n.ofInterest
+	n2.placed = n.placed
+	n2.layout = n.layout
+	n2.frozen = n.frozen
+	n2.hyper = n.hyper
+	n2.stack = n.stack
+	n2.backwardOf = n.backwardOf
 	return n2
 }
 
@@ -450,6 +533,8 @@ func (n *Node) Dims() int {
 		return nt.Dims
 	case tensor.Dtype:
 		return 0
+	case *hm.Record:
+		return 0 // tuple-valued nodes have a scalar shape
 	default:
 		panic(fmt.Sprintf("Dims undefined for %v(%T)", nt, nt))
 	}
@@ -485,7 +570,8 @@ func (n *Node) Strides() []int {
 	return n.shape.CalcStrides()
 }
 
-// Device returns the device the data will be on
+// Device returns the device the node is executed on and its value is kept on. The device of the nodes that are not
+// placed with WithDevice is only determined when the graph is compiled, or run by a lispMachine.
 func (n *Node) Device() Device { return n.dataOn }
 
 // Op returns the Op of the node
@@ -783,7 +869,7 @@ func (n *Node) fixChildren() {
 	}
 
 	for i, child := range n.children {
-		newChild := n.g.AddNode(child)
+		newChild := n.g.addNode(child)
 		if child != newChild {
 			n.children[i] = newChild
 		}
@@ -798,7 +884,7 @@ func (n *Node) fixEdges() {
 	if len(n.children) > 0 {
 		for _, child := range n.children {
 			e := edge{from: n, to: child}
-			n.g.SetEdge(e)
+			n.g.setEdge(e)
 		}
 	} else {
 		n.g.leaves = append(n.g.leaves, n)
@@ -841,7 +927,15 @@ func (n *Node) clone(opts ...NodeConsOpt) *Node {
 
 func (n *Node) diffWRT() []bool {
 	if sdop, ok := n.op.(SDOp); ok {
-		return sdop.DiffWRT(len(n.children))
+		diffs := sdop.DiffWRT(len(n.children))
+		for i, child := range n.children {
+			if child.frozen && i < len(diffs) && diffs[i] {
+				// the op may share its slice, so it is copied before the frozen children are cleared
+				diffs = append([]bool(nil), diffs...)
+				diffs[i] = false
+			}
+		}
+		return diffs
 	}
 	return nil
 }
@@ -1,9 +1,11 @@
 package gorgonia
 
 import (
+	"math"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"gorgonia.org/tensor"
 )
 
 func TestNodeBasics(t *testing.T) {
@@ -184,3 +186,45 @@ func TestCloneTo(t *testing.T) {
 
 	assert.True(t, nodeEq(g2.AllNodes()[0], n))
 }
+
+func TestWithReuse(t *testing.T) {
+	assert := assert.New(t)
+	for _, vmKind := range []string{"tape", "lisp"} {
+		g := NewGraph()
+		x := NewMatrix(g, Float64, WithShape(2, 2), WithName("x"))
+		y := NewMatrix(g, Float64, WithShape(2, 2), WithName("y"))
+		xpy := Must(Add(x, y))
+		z := Must(Exp(xpy))
+
+		buf := tensor.New(tensor.WithShape(2, 2), tensor.Of(Float64))
+		addBuf := tensor.New(tensor.WithShape(2, 2), tensor.Of(Float64))
+		WithReuse(buf)(z)
+		WithReuse(addBuf)(xpy)
+
+		var m VM
+		switch vmKind {
+		case "tape":
+			m = NewTapeMachine(g)
+		case "lisp":
+			m = NewLispMachine(g, ExecuteFwdOnly())
+		}
+
+		Let(x, tensor.New(tensor.WithShape(2, 2), tensor.WithBacking([]float64{0, 1, 2, 3})))
+		Let(y, tensor.New(tensor.WithShape(2, 2), tensor.WithBacking([]float64{0, 0, 0, 0})))
+		if err := m.RunAll(); err != nil {
+			t.Fatalf("%v: %+v", vmKind, err)
+		}
+		assert.Equal([]float64{0, 1, 2, 3}, addBuf.Data(), vmKind)
+		assert.InDeltaSlice([]float64{1, math.E, math.E * math.E, math.E * math.E * math.E}, buf.Data(), 1e-10, vmKind)
+		assert.True(buf == z.Value().(*tensor.Dense), "%v: expected the reused buffer to be bound to the node", vmKind)
+		m.Close()
+	}
+
+	// bad reuses
+	g := NewGraph()
+	x := NewMatrix(g, Float64, WithShape(2, 2), WithName("x"))
+	z := Must(Exp(x))
+	assert.Panics(func() { WithReuse(tensor.New(tensor.WithShape(2, 2), tensor.Of(Float64)))(x) })
+	assert.Panics(func() { WithReuse(tensor.New(tensor.WithShape(2, 3), tensor.Of(Float64)))(z) })
+	assert.Panics(func() { WithReuse(tensor.New(tensor.WithShape(2, 2), tensor.Of(Float32)))(z) })
+}
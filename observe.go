@@ -0,0 +1,124 @@
+package gorgonia
+
+import "github.com/pkg/errors"
+
+/*
+This file holds the observers of the execution: the hooks the machines call before and after every op they execute,
+for the tools that need to see the execution as it happens, such as profilers, tracers, dumpers of values, and
+injectors of faults:
+
+		timer := gorgonia.ExecObserverFuncs{
+			Before: func(e *gorgonia.ExecEvent) error { start = time.Now(); return nil },
+			After:  func(e *gorgonia.ExecEvent) error { log.Printf("%v took %v", e.Op, time.Since(start)); return nil },
+		}
+		m := gorgonia.NewTapeMachine(g, gorgonia.WithObservers(timer))
+
+An observer that returns an error stops the run with that error. The values of the event are those of the machine: an
+observer may write into them, as an injector of faults would, but must copy them to keep them beyond the call.
+*/
+
+// ExecEvent is the execution of an op by a machine.
+type ExecEvent struct {
+	Node   *Node
+	Op     Op
+	Inputs []Value
+	Output Value // nil before the execution
+
+	// PC is the index of the instruction of a *tapeMachine, or the index of the node in the order of execution of a
+	// *lispMachine.
+	PC int
+}
+
+// ExecObserver is called by the machines before and after the execution of every op (see WithObservers).
+type ExecObserver interface {
+	BeforeExec(e *ExecEvent) error
+	AfterExec(e *ExecEvent) error
+}
+
+// ExecObserverFuncs is an ExecObserver made of functions. A nil function is not called.
+type ExecObserverFuncs struct {
+	Before func(e *ExecEvent) error
+	After  func(e *ExecEvent) error
+}
+
+// BeforeExec calls Before.
+func (o ExecObserverFuncs) BeforeExec(e *ExecEvent) error {
+	if o.Before == nil {
+		return nil
+	}
+	return o.Before(e)
+}
+
+// AfterExec calls After.
+func (o ExecObserverFuncs) AfterExec(e *ExecEvent) error {
+	if o.After == nil {
+		return nil
+	}
+	return o.After(e)
+}
+
+// WithObservers creates a VM that calls the observers before and after the execution of every op, in the order they
+// are given. A *tapeMachine observes the ops of its instructions, including the ops of the gradients; the results of
+// the ops that an incremental machine restores rather than executes are not observed (see WithIncrementalExec). A
+// *lispMachine observes the ops of the nodes it executes forwards.
+func WithObservers(obs ...ExecObserver) VMOpt {
+	f := func(m VM) {
+		switch v := m.(type) {
+		case *lispMachine:
+			v.observers = append(v.observers, obs...)
+		case *tapeMachine:
+			v.observers = append(v.observers, obs...)
+		default:
+			panic(nyi("WithObservers", v))
+		}
+	}
+	return f
+}
+
+func beforeExec(obs []ExecObserver, e *ExecEvent) error {
+	for _, o := range obs {
+		if err := o.BeforeExec(e); err != nil {
+			return errors.Wrapf(err, "Observer failed before executing %v", e.Node)
+		}
+	}
+	return nil
+}
+
+func afterExec(obs []ExecObserver, e *ExecEvent) error {
+	for _, o := range obs {
+		if err := o.AfterExec(e); err != nil {
+			return errors.Wrapf(err, "Observer failed after executing %v", e.Node)
+		}
+	}
+	return nil
+}
+
+// execEvent returns the event of the instruction, or nil if it does not execute an op.
+func (m *tapeMachine) execEvent(instr tapeInstr) *ExecEvent {
+	op, ok := instr.(*execOp)
+	if !ok {
+		return nil
+	}
+	e := &ExecEvent{Op: op.op, PC: m.pc, Inputs: make([]Value, len(op.readFrom))}
+	e.Node, _ = m.p.g.Node(op.id).(*Node)
+	for i, r := range op.readFrom {
+		e.Inputs[i] = m.getValue(r)
+	}
+	return e
+}
+
+// execEvent returns the event of the next node, or nil if it does not execute an op.
+func (m *lispMachine) execEvent() *ExecEvent {
+	if m.fwd < 0 || m.fwd >= len(m.sorted) {
+		return nil
+	}
+	n := m.sorted[m.fwd]
+	if n.isArg() || n.isStmt {
+		return nil
+	}
+	e := &ExecEvent{Node: n, Op: n.op, PC: m.fwd, Inputs: make([]Value, len(n.children))}
+	for i, child := range n.children {
+		e.Inputs[i] = child.Value()
+	}
+	return e
+}
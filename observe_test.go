@@ -0,0 +1,79 @@
+package gorgonia
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"gorgonia.org/tensor"
+)
+
+func TestWithObservers(t *testing.T) {
+	assert := assert.New(t)
+	g := NewGraph()
+	x := NewVector(g, Float64, WithShape(3), WithName("x"), WithValue(tensor.New(tensor.WithBacking([]float64{1, 2, 3}))))
+	y := Must(Square(x))
+	sum := Must(Sum(y))
+
+	for _, name := range []string{"tape", "lisp"} {
+		var before, after []*ExecEvent
+		rec := ExecObserverFuncs{
+			Before: func(e *ExecEvent) error {
+				assert.Nil(e.Output, name)
+				before = append(before, e)
+				return nil
+			},
+			After: func(e *ExecEvent) error {
+				after = append(after, e)
+				return nil
+			},
+		}
+		var m VM
+		if name == "tape" {
+			m = NewTapeMachine(g, WithObservers(rec))
+		} else {
+			m = NewLispMachine(g, ExecuteFwdOnly(), WithObservers(rec))
+		}
+		if err := m.RunAll(); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		m.Close()
+
+		if !assert.Equal(2, len(after), name) {
+			continue
+		}
+		assert.Equal(2, len(before), name)
+		assert.True(after[0].Node == y, name)
+		assert.Equal(y.Op(), after[0].Op, name)
+		assert.Equal([]float64{1, 2, 3}, after[0].Inputs[0].Data(), name)
+		assert.True(after[1].Node == sum, name)
+		assert.Equal(14.0, after[1].Output.Data(), name)
+		assert.True(after[0].PC < after[1].PC, name)
+	}
+
+	// an observer fails the run, before the op is executed
+	injected := errors.New("injected")
+	executed := 0
+	fault := ExecObserverFuncs{
+		Before: func(e *ExecEvent) error {
+			if e.Node == sum {
+				return injected
+			}
+			return nil
+		},
+		After: func(e *ExecEvent) error {
+			executed++
+			return nil
+		},
+	}
+	m := NewTapeMachine(g, WithObservers(fault))
+	defer m.Close()
+	err := m.RunAll()
+	assert.Error(err)
+	assert.True(errors.Cause(err) == injected)
+	assert.Equal(1, executed)
+
+	lm := NewLispMachine(g, ExecuteFwdOnly(), WithObservers(fault))
+	defer lm.Close()
+	assert.Error(lm.RunAll())
+}
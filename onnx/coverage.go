@@ -0,0 +1,130 @@
+package onnx
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+)
+
+// Coverage is the report of the support of the operators of a model by the importer. See CoverageReport.
+type Coverage struct {
+	Opset int64        // the negotiated version of the default operator set
+	Ops   []OpCoverage // sorted by domain and operator
+}
+
+// OpCoverage is the support of an operator used by the nodes of a model.
+type OpCoverage struct {
+	Domain string // empty for the default operator set
+	OpType string
+	Nodes  int // the number of nodes of the operator
+
+	// Since is the version of the operator set from which the converter of the operator for the model is used, or 0 if
+	// the operator is not supported, for the Reason given.
+	Since  int64
+	Reason string
+}
+
+// Supported returns true if the operator is supported.
+func (c OpCoverage) Supported() bool { return c.Since > 0 }
+
+func (c OpCoverage) String() string {
+	if isDefaultDomain(c.Domain) {
+		return c.OpType
+	}
+	return c.Domain + "." + c.OpType
+}
+
+// CoverageReport reports which operators of a model the importer supports, at the version of the default operator set
+// negotiated with the options (see WithOpset), without converting anything. The operators that are supported may still
+// fail to convert with the attributes and shapes of some nodes, such as a MatMul of 4 dimensions.
+func CoverageReport(m *ModelProto, opts ...ImportOpt) (*Coverage, error) {
+	im, err := newImporter(m, opts)
+	if err != nil {
+		return nil, err
+	}
+	return im.coverage(m.Graph), nil
+}
+
+func (im *importer) coverage(g *GraphProto) *Coverage {
+	type key struct{ domain, op string }
+	ops := make(map[key]*OpCoverage)
+	retVal := &Coverage{Opset: im.opset}
+	for _, n := range g.Node {
+		k := key{n.Domain, n.OpType}
+		if isDefaultDomain(k.domain) {
+			k.domain = ""
+		}
+		c, ok := ops[k]
+		if !ok {
+			c = &OpCoverage{Domain: k.domain, OpType: n.OpType}
+			switch conv, err := converterOf(n.OpType, im.opset); {
+			case k.domain != "":
+				c.Reason = fmt.Sprintf("the operators of the domain %q are not supported", k.domain)
+			case err != nil:
+				c.Reason = err.Error()
+			default:
+				c.Since = conv.since
+			}
+			ops[k] = c
+		}
+		c.Nodes++
+	}
+	for _, c := range ops {
+		retVal.Ops = append(retVal.Ops, *c)
+	}
+	sort.Slice(retVal.Ops, func(i, j int) bool {
+		a, b := retVal.Ops[i], retVal.Ops[j]
+		if a.Domain != b.Domain {
+			return a.Domain < b.Domain
+		}
+		return a.OpType < b.OpType
+	})
+	return retVal
+}
+
+// Unsupported returns the operators that are not supported.
+func (c *Coverage) Unsupported() []OpCoverage {
+	var retVal []OpCoverage
+	for _, op := range c.Ops {
+		if !op.Supported() {
+			retVal = append(retVal, op)
+		}
+	}
+	return retVal
+}
+
+// Err returns an error that lists the operators that are not supported, or nil if they all are.
+func (c *Coverage) Err() error {
+	unsupported := c.Unsupported()
+	if len(unsupported) == 0 {
+		return nil
+	}
+	names := make([]string, len(unsupported))
+	for i, op := range unsupported {
+		names[i] = fmt.Sprintf("%v (%d nodes)", op, op.Nodes)
+		if op.Nodes == 1 {
+			names[i] = fmt.Sprintf("%v (1 node)", op)
+		}
+	}
+	return errors.Errorf("The ONNX model uses %d operators that are not supported at the opset %d: %v", len(unsupported), c.Opset, strings.Join(names, ", "))
+}
+
+// String formats the report as a table of the operators.
+func (c *Coverage) String() string {
+	var buf bytes.Buffer
+	tw := tabwriter.NewWriter(&buf, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "Operator\tNodes\tSupport\t\n")
+	for _, op := range c.Ops {
+		support := fmt.Sprintf("since opset %d", op.Since)
+		if !op.Supported() {
+			support = "unsupported: " + op.Reason
+		}
+		fmt.Fprintf(tw, "%v\t%d\t%s\t\n", op, op.Nodes, support)
+	}
+	tw.Flush()
+	return buf.String()
+}
@@ -0,0 +1,84 @@
+package onnx
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCoverageReport(t *testing.T) {
+	assert := assert.New(t)
+	c, err := CoverageReport(mlp(13))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(int64(13), c.Opset)
+	assert.Equal([]OpCoverage{
+		{OpType: "Gemm", Nodes: 1, Since: 1},
+		{OpType: "Relu", Nodes: 1, Since: 1},
+		{OpType: "Softmax", Nodes: 1, Since: 13},
+	}, c.Ops)
+	assert.NoError(c.Err())
+	assert.Nil(c.Unsupported())
+
+	// all the operators that are not supported are reported, before anything is converted
+	m := mlp(13)
+	m.Graph.Node[1].OpType = "Elu"
+	m.Graph.Node = append(m.Graph.Node,
+		&NodeProto{Name: "elu", OpType: "Elu", Input: []string{"y"}, Output: []string{"z"}},
+		&NodeProto{Name: "fused", OpType: "FusedMatMul", Domain: "com.microsoft", Input: []string{"z", "w"}, Output: []string{"u"}})
+	if c, err = CoverageReport(m); err != nil {
+		t.Fatal(err)
+	}
+	unsupported := c.Unsupported()
+	if assert.Equal(2, len(unsupported)) {
+		assert.Equal("Elu", unsupported[0].String())
+		assert.Equal(2, unsupported[0].Nodes)
+		assert.Equal("com.microsoft.FusedMatMul", unsupported[1].String())
+	}
+	assert.True(strings.Contains(c.String(), "unsupported"), c.String())
+	_, err = Import(m, WithInputShape("x", 2, 3))
+	if assert.Error(err) {
+		assert.Contains(err.Error(), "Elu (2 nodes)")
+		assert.Contains(err.Error(), "com.microsoft.FusedMatMul (1 node)")
+	}
+
+	// the converters of the negotiated opset
+	m = mlp(13)
+	m.Graph.Node[2] = &NodeProto{OpType: "Reshape", Input: []string{"r", "shape"}, Output: []string{"y"}}
+	if c, err = CoverageReport(m, WithOpset(4)); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(int64(4), c.Opset)
+	if assert.Equal(1, len(c.Unsupported())) {
+		assert.Contains(c.Unsupported()[0].Reason, "from the opset 5")
+	}
+
+	_, err = CoverageReport(&ModelProto{})
+	assert.Error(err)
+}
+
+func TestImport_opset(t *testing.T) {
+	assert := assert.New(t)
+
+	m := mlp(13)
+	m.OpsetImport = []*OperatorSetID{{Version: 11}, {Domain: "ai.onnx", Version: 13}, {Domain: "ai.onnx.ml", Version: 30}}
+	model, err := Import(m, WithInputShape("x", 2, 3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(int64(13), model.Opset)
+
+	// a model of an opset newer than the converters is only imported as a model of an older opset
+	_, err = Import(mlp(MaxOpset+1), WithInputShape("x", 2, 3))
+	assert.Error(err)
+	if model, err = Import(mlp(MaxOpset+1), WithInputShape("x", 2, 3), WithOpset(MaxOpset)); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(int64(MaxOpset), model.Opset)
+	_, err = Import(mlp(13), WithInputShape("x", 2, 3), WithOpset(MaxOpset+1))
+	assert.Error(err)
+	_, err = Import(mlp(13), WithInputShape("x", 2, 3), WithOpset(-1))
+	assert.Error(err)
+}
@@ -0,0 +1,19 @@
+// Package onnx imports ONNX models into Gorgonia graphs. The models are decoded by a small hand-written reader of the
+// messages of onnx.proto that the importer needs, so the package does not depend on the generated code of the schema.
+//
+// A model is imported into an *ExprGraph whose input nodes are the inputs of the ONNX graph, and whose output nodes
+// are its outputs:
+//
+//	m, err := onnx.ReadFile("model.onnx", onnx.WithInputShape("x", 8, 784))
+//	...
+//	gorgonia.Let(m.Input("x"), x)
+//	vm := gorgonia.NewTapeMachine(m.Graph)
+//	err = vm.RunAll()
+//	y := m.Outputs[0].Value()
+//
+// Only a subset of the ONNX operators is supported; see Supported. An operator is converted by the converter of the
+// newest version of the operator set from which it did not change, up to the version the model imports, which must be
+// at most MaxOpset unless the model is imported as a model of an older version (see WithOpset). CoverageReport tells
+// the operators of a model that are not supported without importing it. The values that an operator needs at import
+// time, such as the shape of a Reshape, must be initializers or the outputs of Constant nodes.
+package onnx
@@ -0,0 +1,278 @@
+package onnx
+
+import (
+	"io/ioutil"
+
+	"github.com/pkg/errors"
+	G "gorgonia.org/gorgonia"
+	"gorgonia.org/tensor"
+)
+
+// Model is an ONNX model that was imported into an *ExprGraph. Inputs are the input nodes of the graph, in the order of
+// the ONNX graph, and have no values until they are Let. Outputs are the nodes of the outputs of the ONNX graph.
+// Initializers are the input nodes of the initializers, bound to their values, which are the parameters of the model.
+type Model struct {
+	Graph        *G.ExprGraph
+	Opset        int64
+	InputNames   []string
+	Inputs       G.Nodes
+	OutputNames  []string
+	Outputs      G.Nodes
+	Initializers G.Nodes
+}
+
+// Input returns the input node of the given name, or nil if there is none.
+func (m *Model) Input(name string) *G.Node {
+	for i, n := range m.InputNames {
+		if n == name {
+			return m.Inputs[i]
+		}
+	}
+	return nil
+}
+
+// Output returns the output node of the given name, or nil if there is none.
+func (m *Model) Output(name string) *G.Node {
+	for i, n := range m.OutputNames {
+		if n == name {
+			return m.Outputs[i]
+		}
+	}
+	return nil
+}
+
+// ImportOpt is an option of Import.
+type ImportOpt func(*importer)
+
+// WithInputShape sets the shape of an input of the model. It is required for the inputs whose shapes have dimensions
+// that are only known at run time, such as a batch size, and must agree with the known dimensions.
+func WithInputShape(name string, shape ...int) ImportOpt {
+	return func(im *importer) { im.shapes[name] = tensor.Shape(shape).Clone() }
+}
+
+// WithOpset imports the model as a model of the given version of the default operator set, instead of the version that
+// it imports. It is how a model of an opset newer than MaxOpset is imported, when its operators are known not to have
+// changed since MaxOpset.
+func WithOpset(version int64) ImportOpt {
+	return func(im *importer) { im.opset = version }
+}
+
+// InGraph imports the model into an existing graph, instead of a new one.
+func InGraph(g *G.ExprGraph) ImportOpt {
+	return func(im *importer) { im.g = g }
+}
+
+// MaxOpset is the newest version of the default operator set that the converters are written for.
+const MaxOpset = 21
+
+// importer is the state of an import.
+type importer struct {
+	g      *G.ExprGraph
+	opset  int64
+	shapes map[string]tensor.Shape
+
+	nodes  map[string]*G.Node       // the nodes of the values of the ONNX graph
+	consts map[string]tensor.Tensor // the values that are known at import time, such as the initializers
+}
+
+// ReadFile reads and imports the ONNX model in a file.
+func ReadFile(path string, opts ...ImportOpt) (*Model, error) {
+	p, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	m := new(ModelProto)
+	if err = m.Unmarshal(p); err != nil {
+		return nil, errors.Wrapf(err, "Cannot decode the ONNX model %v", path)
+	}
+	return Import(m, opts...)
+}
+
+// Import builds the graph of an ONNX model. The initializers become input nodes with their values, which makes them
+// learnable, and the nodes are converted to the operations of Gorgonia. Every operator must be supported by the
+// converters of the opset of the model, which is checked before anything is converted; see CoverageReport.
+func Import(m *ModelProto, opts ...ImportOpt) (*Model, error) {
+	im, err := newImporter(m, opts)
+	if err != nil {
+		return nil, err
+	}
+	if err = im.coverage(m.Graph).Err(); err != nil {
+		return nil, err
+	}
+	if im.g == nil {
+		im.g = G.NewGraph()
+	}
+
+	g := m.Graph
+	retVal := &Model{Graph: im.g, Opset: im.opset}
+	for _, init := range g.Initializer {
+		t, err := init.Tensor()
+		if err != nil {
+			return nil, err
+		}
+		im.consts[init.Name] = t
+		if im.nodes[init.Name], err = im.initializer(init.Name, t); err != nil {
+			return nil, err
+		}
+		retVal.Initializers = append(retVal.Initializers, im.nodes[init.Name])
+	}
+
+	for _, in := range g.Input {
+		if _, ok := im.nodes[in.Name]; ok {
+			continue // before IR version 4 the initializers are also inputs
+		}
+		n, err := im.input(in)
+		if err != nil {
+			return nil, err
+		}
+		im.nodes[in.Name] = n
+		retVal.InputNames = append(retVal.InputNames, in.Name)
+		retVal.Inputs = append(retVal.Inputs, n)
+	}
+
+	for _, n := range g.Node {
+		if err := im.convert(n); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, out := range g.Output {
+		n, ok := im.nodes[out.Name]
+		if !ok {
+			return nil, errors.Errorf("The output %q of the ONNX graph is not computed by any node", out.Name)
+		}
+		retVal.OutputNames = append(retVal.OutputNames, out.Name)
+		retVal.Outputs = append(retVal.Outputs, n)
+	}
+	return retVal, nil
+}
+
+// newImporter returns the importer of a model, with the negotiated version of its default operator set: the version
+// the model imports, or the version given to WithOpset, which must be at most MaxOpset.
+func newImporter(m *ModelProto, opts []ImportOpt) (*importer, error) {
+	if m.Graph == nil {
+		return nil, errors.New("The ONNX model has no graph")
+	}
+	im := &importer{
+		shapes: make(map[string]tensor.Shape),
+		nodes:  make(map[string]*G.Node),
+		consts: make(map[string]tensor.Tensor),
+	}
+	for _, o := range m.OpsetImport {
+		if isDefaultDomain(o.Domain) && o.Version > im.opset {
+			im.opset = o.Version
+		}
+	}
+	imported := im.opset
+	for _, opt := range opts {
+		opt(im)
+	}
+	switch {
+	case im.opset <= 0 && imported == 0:
+		return nil, errors.New("The ONNX model does not import the default operator set")
+	case im.opset <= 0:
+		return nil, errors.Errorf("Cannot import the ONNX model as a model of the opset %d", im.opset)
+	case im.opset > MaxOpset && im.opset == imported:
+		return nil, errors.Errorf("The ONNX model uses the opset %d. The newest supported is %d: use WithOpset to import the model as a model of an older opset", im.opset, MaxOpset)
+	case im.opset > MaxOpset:
+		return nil, errors.Errorf("Cannot import the ONNX model as a model of the opset %d. The newest supported is %d", im.opset, MaxOpset)
+	}
+	return im, nil
+}
+
+func isDefaultDomain(domain string) bool { return domain == "" || domain == "ai.onnx" }
+
+// initializer returns the input node of an initializer, bound to its value.
+func (im *importer) initializer(name string, t tensor.Tensor) (retVal *G.Node, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.Errorf("Initializer %q: %v", name, r) // NodeFromAny panics on the values it does not support
+		}
+	}()
+	if t.Shape().IsScalar() {
+		return G.NodeFromAny(im.g, t.Data(), G.WithName(name)), nil
+	}
+	return G.NodeFromAny(im.g, t, G.WithName(name)), nil
+}
+
+// input returns the input node of the graph input vi.
+func (im *importer) input(vi *ValueInfoProto) (*G.Node, error) {
+	dt, err := vi.ElemType.Dtype()
+	if err != nil {
+		return nil, errors.Wrapf(err, "Input %q", vi.Name)
+	}
+	shape, given := im.shapes[vi.Name]
+	if vi.Shape == nil && !given {
+		return nil, errors.Errorf("The input %q has no shape. Use WithInputShape", vi.Name)
+	}
+	if !given {
+		shape = make(tensor.Shape, len(vi.Shape))
+	}
+	if len(shape) != len(vi.Shape) && vi.Shape != nil {
+		return nil, errors.Errorf("The input %q has %d dimensions. Got the shape %v instead", vi.Name, len(vi.Shape), shape)
+	}
+	for i, d := range vi.Shape {
+		switch {
+		case d.Value > 0 && given && int(d.Value) != shape[i]:
+			return nil, errors.Errorf("The dimension %d of the input %q is %d. Got the shape %v instead", i, vi.Name, d.Value, shape)
+		case d.Value > 0:
+			shape[i] = int(d.Value)
+		case !given:
+			return nil, errors.Errorf("The dimension %d of the input %q is only known at run time (%q). Use WithInputShape", i, vi.Name, d.Param)
+		}
+	}
+
+	if len(shape) == 0 {
+		return G.NewScalar(im.g, dt, G.WithName(vi.Name)), nil
+	}
+	return G.NewTensor(im.g, dt, len(shape), G.WithShape(shape...), G.WithName(vi.Name)), nil
+}
+
+// convert adds the operations of an ONNX node to the graph.
+func (im *importer) convert(n *NodeProto) error {
+	if !isDefaultDomain(n.Domain) {
+		return errors.Errorf("Node %q: the operator %v of the domain %q is not supported", n.Name, n.OpType, n.Domain)
+	}
+	conv, err := converterOf(n.OpType, im.opset)
+	if err != nil {
+		return errors.Wrapf(err, "Node %q", n.Name)
+	}
+
+	inputs := make(G.Nodes, len(n.Input))
+	for i, name := range n.Input {
+		if name == "" {
+			continue // an omitted optional input
+		}
+		in, ok := im.nodes[name]
+		if !ok {
+			return errors.Errorf("Node %q: the input %q is not computed by any previous node", n.Name, name)
+		}
+		inputs[i] = in
+	}
+
+	outputs, err := conv.fn(im, n, inputs)
+	if err != nil {
+		return errors.Wrapf(err, "Node %q (%v)", n.Name, n.OpType)
+	}
+	if len(outputs) < len(n.Output) {
+		return errors.Errorf("Node %q: %v computed %d outputs. Expected %d", n.Name, n.OpType, len(outputs), len(n.Output))
+	}
+	for i, name := range n.Output {
+		if name != "" {
+			im.nodes[name] = outputs[i]
+		}
+	}
+	return nil
+}
+
+// constant returns the value of the input i of a node, which must be known at import time, like the shape of a Reshape.
+func (im *importer) constant(n *NodeProto, i int) (tensor.Tensor, error) {
+	if i >= len(n.Input) || n.Input[i] == "" {
+		return nil, nil
+	}
+	t, ok := im.consts[n.Input[i]]
+	if !ok {
+		return nil, errors.Errorf("The input %q must be an initializer or a Constant: it is not supported to compute it", n.Input[i])
+	}
+	return t, nil
+}
@@ -0,0 +1,174 @@
+package onnx
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	G "gorgonia.org/gorgonia"
+	"gorgonia.org/tensor"
+)
+
+// valueInfo returns the ValueInfoProto of a float tensor. The negative dimensions are only known at run time.
+func valueInfo(name string, dims ...int64) *ValueInfoProto {
+	vi := &ValueInfoProto{Name: name, ElemType: Float, Shape: []Dimension{}}
+	for _, d := range dims {
+		if d < 0 {
+			vi.Shape = append(vi.Shape, Dimension{Param: "N"})
+			continue
+		}
+		vi.Shape = append(vi.Shape, Dimension{Value: d})
+	}
+	return vi
+}
+
+func floats(name string, dims []int64, data ...float32) *TensorProto {
+	return &TensorProto{Name: name, Dims: dims, DataType: Float, FloatData: data}
+}
+
+func int64s(name string, data ...int64) *TensorProto {
+	return &TensorProto{Name: name, Dims: []int64{int64(len(data))}, DataType: Int64, Int64Data: data}
+}
+
+// mlp is a model of a dense layer and a softmax: y = softmax(relu(x * w + b)).
+func mlp(opset int64) *ModelProto {
+	return &ModelProto{
+		IRVersion:   7,
+		OpsetImport: []*OperatorSetID{{Version: opset}},
+		Graph: &GraphProto{
+			Name: "mlp",
+			Node: []*NodeProto{
+				{Name: "dense", OpType: "Gemm", Input: []string{"x", "w", "b"}, Output: []string{"h"}},
+				{Name: "relu", OpType: "Relu", Input: []string{"h"}, Output: []string{"r"}},
+				{Name: "softmax", OpType: "Softmax", Input: []string{"r"}, Output: []string{"y"}},
+			},
+			Initializer: []*TensorProto{
+				floats("w", []int64{3, 2}, 1, -1, 0, 2, 1, 0),
+				floats("b", []int64{2}, 0.5, -0.5),
+			},
+			Input:  []*ValueInfoProto{valueInfo("x", -1, 3)},
+			Output: []*ValueInfoProto{valueInfo("y", -1, 2)},
+		},
+	}
+}
+
+// run imports a model, binds the inputs and returns the values of the outputs.
+func run(t *testing.T, m *ModelProto, inputs map[string]tensor.Tensor, opts ...ImportOpt) []tensor.Tensor {
+	model, err := Import(m, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, v := range inputs {
+		if err = G.Let(model.Input(name), v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	vm := G.NewTapeMachine(model.Graph)
+	defer vm.Close()
+	if err = vm.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+	var retVal []tensor.Tensor
+	for _, n := range model.Outputs {
+		v := n.Value()
+		if s, ok := v.(G.Scalar); ok {
+			v = tensor.New(tensor.FromScalar(s.Data()))
+		}
+		retVal = append(retVal, v.(tensor.Tensor))
+	}
+	return retVal
+}
+
+func TestImport(t *testing.T) {
+	assert := assert.New(t)
+	x := tensor.New(tensor.WithShape(2, 3), tensor.WithBacking([]float32{1, 2, 3, -1, -2, -3}))
+	// x * w + b = [[4.5, 2.5], [-3.5, -5.5]], relu = [[4.5, 2.5], [0, 0]]
+	want := []float32{0.880797, 0.119203, 0.5, 0.5}
+
+	out := run(t, mlp(11), map[string]tensor.Tensor{"x": x}, WithInputShape("x", 2, 3))
+	assert.Equal(tensor.Shape{2, 2}, out[0].Shape())
+	assert.InDeltaSlice(want, out[0].Data(), 1e-5)
+
+	out = run(t, mlp(13), map[string]tensor.Tensor{"x": x}, WithInputShape("x", 2, 3))
+	assert.InDeltaSlice(want, out[0].Data(), 1e-5)
+
+	// the model is read from a file
+	dir, err := ioutil.TempDir("", "onnx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "mlp.onnx")
+	if err = ioutil.WriteFile(path, mlp(13).Marshal(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m, err := ReadFile(path, WithInputShape("x", 4, 3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]string{"x"}, m.InputNames)
+	assert.Equal([]string{"y"}, m.OutputNames)
+	assert.Equal(int64(13), m.Opset)
+	assert.Equal(tensor.Shape{4, 3}, m.Input("x").Shape())
+	assert.Equal(tensor.Shape{4, 2}, m.Output("y").Shape())
+	assert.Nil(m.Input("y"))
+	assert.Equal("w", m.Graph.ByName("w")[0].Name())
+	assert.NotNil(m.Graph.ByName("w")[0].Value(), "the initializers have their values")
+	assert.Equal(G.Nodes{m.Graph.ByName("w")[0], m.Graph.ByName("b")[0]}, m.Initializers)
+
+	// importing into an existing graph
+	g := G.NewGraph()
+	if m, err = Import(mlp(13), InGraph(g), WithInputShape("x", 1, 3)); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(g, m.Graph)
+}
+
+func TestImport_errors(t *testing.T) {
+	assert := assert.New(t)
+
+	_, err := Import(mlp(13))
+	assert.Error(err, "the batch size is only known at run time")
+	_, err = Import(mlp(13), WithInputShape("x", 2, 4))
+	assert.Error(err, "the dimension 1 is 3")
+	_, err = Import(mlp(13), WithInputShape("x", 2))
+	assert.Error(err, "x has 2 dimensions")
+
+	m := mlp(13)
+	m.OpsetImport = nil
+	_, err = Import(m, WithInputShape("x", 2, 3))
+	assert.Error(err, "no opset")
+
+	m = mlp(13)
+	m.Graph.Node[1].OpType = "Elu"
+	_, err = Import(m, WithInputShape("x", 2, 3))
+	assert.Error(err, "Elu is not supported")
+
+	m = mlp(4)
+	m.Graph.Node[1] = &NodeProto{OpType: "Reshape", Input: []string{"h", "shape"}, Output: []string{"r"}}
+	m.Graph.Initializer = append(m.Graph.Initializer, int64s("shape", 4))
+	_, err = Import(m, WithInputShape("x", 2, 3))
+	assert.Error(err, "Reshape is supported from the opset 5")
+
+	m = mlp(13)
+	m.Graph.Node[1].Domain = "com.microsoft"
+	_, err = Import(m, WithInputShape("x", 2, 3))
+	assert.Error(err, "other domains")
+
+	m = mlp(13)
+	m.Graph.Node[1].Input = []string{"nope"}
+	_, err = Import(m, WithInputShape("x", 2, 3))
+	assert.Error(err, "unknown input")
+
+	m = mlp(13)
+	m.Graph.Output[0].Name = "nope"
+	_, err = Import(m, WithInputShape("x", 2, 3))
+	assert.Error(err, "unknown output")
+
+	_, err = Import(&ModelProto{})
+	assert.Error(err, "no graph")
+	_, err = ReadFile("testdata/does-not-exist.onnx")
+	assert.Error(err)
+}
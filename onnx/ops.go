@@ -0,0 +1,540 @@
+package onnx
+
+import (
+	"sort"
+
+	"github.com/pkg/errors"
+	G "gorgonia.org/gorgonia"
+	"gorgonia.org/tensor"
+)
+
+// converter converts the nodes of an ONNX operator of the opset versions from since on, up to the next converter of the
+// operator, into the operations of Gorgonia. The inputs are nil for the omitted optional inputs.
+type converter struct {
+	since int64
+	fn    func(im *importer, n *NodeProto, inputs G.Nodes) (G.Nodes, error)
+}
+
+// converters are the converters of every supported operator, by increasing opset version.
+var converters = map[string][]converter{
+	"Abs":        {{1, unary(G.Abs)}},
+	"Add":        {{1, legacyElementwise(G.Add, G.BroadcastAdd)}, {7, elementwise(G.Add, G.BroadcastAdd)}},
+	"Constant":   {{1, convConstant}},
+	"Div":        {{1, legacyElementwise(G.HadamardDiv, G.BroadcastHadamardDiv)}, {7, elementwise(G.HadamardDiv, G.BroadcastHadamardDiv)}},
+	"Exp":        {{1, unary(G.Exp)}},
+	"Flatten":    {{1, convFlatten}},
+	"Gemm":       {{1, convGemm}},
+	"Identity":   {{1, convIdentity}},
+	"Log":        {{1, unary(G.Log)}},
+	"MatMul":     {{1, convMatMul}},
+	"Mul":        {{1, legacyElementwise(G.HadamardProd, G.BroadcastHadamardProd)}, {7, elementwise(G.HadamardProd, G.BroadcastHadamardProd)}},
+	"Neg":        {{1, unary(G.Neg)}},
+	"Pow":        {{1, legacyElementwise(G.Pow, G.BroadcastPow)}, {7, elementwise(G.Pow, G.BroadcastPow)}},
+	"ReduceMean": {{1, reduce(G.Mean, false)}, {18, reduce(G.Mean, true)}},
+	"ReduceSum":  {{1, reduce(G.Sum, false)}, {13, reduce(G.Sum, true)}},
+	"Relu":       {{1, unary(G.Rectify)}},
+	"Reshape":    {{5, convReshape}},
+	"Sigmoid":    {{1, unary(G.Sigmoid)}},
+	"Softmax":    {{1, convSoftmax(false)}, {13, convSoftmax(true)}},
+	"Sqrt":       {{1, unary(G.Sqrt)}},
+	"Squeeze":    {{1, convSqueeze(false)}, {13, convSqueeze(true)}},
+	"Sub":        {{1, legacyElementwise(G.Sub, G.BroadcastSub)}, {7, elementwise(G.Sub, G.BroadcastSub)}},
+	"Tanh":       {{1, unary(G.Tanh)}},
+	"Transpose":  {{1, convTranspose}},
+	"Unsqueeze":  {{1, convUnsqueeze(false)}, {13, convUnsqueeze(true)}},
+}
+
+// converterOf returns the converter of an operator at an opset version.
+func converterOf(op string, opset int64) (converter, error) {
+	cs, ok := converters[op]
+	if !ok {
+		return converter{}, errors.Errorf("The ONNX operator %v is not supported", op)
+	}
+	for i := len(cs) - 1; i >= 0; i-- {
+		if cs[i].since <= opset {
+			return cs[i], nil
+		}
+	}
+	return converter{}, errors.Errorf("The ONNX operator %v is only supported from the opset %d. The model uses the opset %d", op, cs[0].since, opset)
+}
+
+// Supported returns the sorted names of the operators that are supported at an opset version.
+func Supported(opset int64) []string {
+	var retVal []string
+	for op := range converters {
+		if _, err := converterOf(op, opset); err == nil {
+			retVal = append(retVal, op)
+		}
+	}
+	sort.Strings(retVal)
+	return retVal
+}
+
+/* attributes */
+
+func attr(n *NodeProto, name string) *AttributeProto {
+	for _, a := range n.Attribute {
+		if a.Name == name {
+			return a
+		}
+	}
+	return nil
+}
+
+func attrInt(n *NodeProto, name string, def int64) int64 {
+	if a := attr(n, name); a != nil {
+		return a.I
+	}
+	return def
+}
+
+func attrFloat(n *NodeProto, name string, def float32) float32 {
+	if a := attr(n, name); a != nil {
+		return a.F
+	}
+	return def
+}
+
+func attrInts(n *NodeProto, name string) []int64 {
+	if a := attr(n, name); a != nil {
+		return a.Ints
+	}
+	return nil
+}
+
+// axis returns a possibly negative axis of ONNX as an axis of a value of the given number of dimensions.
+func axis(a int64, dims int) (int, error) {
+	if a < 0 {
+		a += int64(dims)
+	}
+	if a < 0 || a >= int64(dims) {
+		return 0, errors.Errorf("The axis %d is out of the range of %d dimensions", a, dims)
+	}
+	return int(a), nil
+}
+
+// ints returns the elements of an integer tensor, such as a shape.
+func ints(t tensor.Tensor) ([]int, error) {
+	var retVal []int
+	switch d := t.Data().(type) {
+	case []int64:
+		for _, v := range d {
+			retVal = append(retVal, int(v))
+		}
+	case []int32:
+		for _, v := range d {
+			retVal = append(retVal, int(v))
+		}
+	case int64:
+		retVal = []int{int(d)}
+	case int32:
+		retVal = []int{int(d)}
+	default:
+		return nil, errors.Errorf("Expected a tensor of int64. Got %v instead", t.Dtype())
+	}
+	return retVal, nil
+}
+
+// scalar returns a constant of the given value and Dtype.
+func scalar(dt tensor.Dtype, v float32) (*G.Node, error) {
+	switch dt {
+	case tensor.Float64:
+		return G.NewConstant(float64(v)), nil
+	case tensor.Float32:
+		return G.NewConstant(v), nil
+	}
+	return nil, errors.Errorf("Expected a float tensor. Got %v instead", dt)
+}
+
+/* converters */
+
+func unary(fn func(*G.Node) (*G.Node, error)) func(*importer, *NodeProto, G.Nodes) (G.Nodes, error) {
+	return func(im *importer, n *NodeProto, inputs G.Nodes) (G.Nodes, error) {
+		retVal, err := fn(inputs[0])
+		return G.Nodes{retVal}, err
+	}
+}
+
+// elementwise converts the elementwise operators, whose inputs are broadcast as in numpy.
+func elementwise(fn func(a, b *G.Node) (*G.Node, error), bfn func(a, b *G.Node, left, right []byte) (*G.Node, error)) func(*importer, *NodeProto, G.Nodes) (G.Nodes, error) {
+	return func(im *importer, n *NodeProto, inputs G.Nodes) (G.Nodes, error) {
+		retVal, err := broadcast(inputs[0], inputs[1], fn, bfn)
+		return G.Nodes{retVal}, err
+	}
+}
+
+// legacyElementwise converts the elementwise operators of the opsets before 7, whose second input is broadcast only if
+// the attribute broadcast is set, to the dimensions of the first from the attribute axis on, or to its last dimensions.
+func legacyElementwise(fn func(a, b *G.Node) (*G.Node, error), bfn func(a, b *G.Node, left, right []byte) (*G.Node, error)) func(*importer, *NodeProto, G.Nodes) (G.Nodes, error) {
+	return func(im *importer, n *NodeProto, inputs G.Nodes) (G.Nodes, error) {
+		a, b := inputs[0], inputs[1]
+		as, bs := a.Shape(), b.Shape()
+		if attrInt(n, "broadcast", 0) == 0 || b.IsScalar() || as.Eq(bs) {
+			retVal, err := fn(a, b)
+			return G.Nodes{retVal}, err
+		}
+		ax := attrInt(n, "axis", int64(len(as)-len(bs)))
+		if ax < 0 || int(ax)+len(bs) > len(as) {
+			return nil, errors.Errorf("Cannot broadcast the shape %v to the shape %v from the axis %d", bs, as, ax)
+		}
+		to := make(tensor.Shape, len(as))
+		for i := range to {
+			to[i] = 1
+		}
+		copy(to[ax:], bs)
+		b, err := G.Reshape(b, to)
+		if err != nil {
+			return nil, err
+		}
+		retVal, err := broadcast(a, b, fn, bfn)
+		return G.Nodes{retVal}, err
+	}
+}
+
+// broadcast applies an elementwise operator to a and b, after broadcasting them to the same shape as in numpy: the
+// value of fewer dimensions gets leading dimensions of size 1, and the dimensions of size 1 are repeated.
+func broadcast(a, b *G.Node, fn func(a, b *G.Node) (*G.Node, error), bfn func(a, b *G.Node, left, right []byte) (*G.Node, error)) (retVal *G.Node, err error) {
+	as, bs := a.Shape(), b.Shape()
+	if a.IsScalar() || b.IsScalar() || as.Eq(bs) {
+		return fn(a, b)
+	}
+
+	dims := len(as)
+	if len(bs) > dims {
+		dims = len(bs)
+	}
+	if dims > 4 {
+		return nil, errors.Errorf("Broadcasting %v and %v: at most 4 dimensions are supported", as, bs)
+	}
+	if as, a, err = leadingOnes(a, dims); err != nil {
+		return nil, err
+	}
+	if bs, b, err = leadingOnes(b, dims); err != nil {
+		return nil, err
+	}
+	var left, right []byte
+	for i := 0; i < dims; i++ {
+		switch {
+		case as[i] == bs[i]:
+		case as[i] == 1:
+			left = append(left, byte(i))
+		case bs[i] == 1:
+			right = append(right, byte(i))
+		default:
+			return nil, errors.Errorf("Cannot broadcast the shapes %v and %v", a.Shape(), b.Shape())
+		}
+	}
+	return bfn(a, b, left, right)
+}
+
+// leadingOnes reshapes n to the given number of dimensions, with leading dimensions of size 1.
+func leadingOnes(n *G.Node, dims int) (tensor.Shape, *G.Node, error) {
+	s := n.Shape()
+	if len(s) == dims {
+		return s, n, nil
+	}
+	to := make(tensor.Shape, dims)
+	for i := range to {
+		to[i] = 1
+	}
+	copy(to[dims-len(s):], s)
+	r, err := G.Reshape(n, to)
+	return to, r, err
+}
+
+func convIdentity(im *importer, n *NodeProto, inputs G.Nodes) (G.Nodes, error) {
+	return G.Nodes{inputs[0]}, nil
+}
+
+func convConstant(im *importer, n *NodeProto, inputs G.Nodes) (G.Nodes, error) {
+	a := attr(n, "value")
+	if a == nil || a.T == nil {
+		return nil, errors.New("Only the Constants of a tensor value are supported")
+	}
+	t, err := a.T.Tensor()
+	if err != nil {
+		return nil, err
+	}
+	im.consts[n.Output[0]] = t
+	if t.Shape().IsScalar() {
+		return G.Nodes{G.NewConstant(t.Data(), G.WithName(n.Output[0]))}, nil
+	}
+	return G.Nodes{G.NewConstant(t, G.WithName(n.Output[0]))}, nil
+}
+
+func convMatMul(im *importer, n *NodeProto, inputs G.Nodes) (G.Nodes, error) {
+	a, b := inputs[0], inputs[1]
+	var retVal *G.Node
+	var err error
+	switch {
+	case a.Dims() <= 2 && b.Dims() <= 2:
+		retVal, err = G.Mul(a, b)
+	case a.Dims() == 3 && b.Dims() == 3:
+		retVal, err = G.BatchedMatMul(a, b)
+	default:
+		err = errors.Errorf("MatMul of the shapes %v and %v is not supported", a.Shape(), b.Shape())
+	}
+	return G.Nodes{retVal}, err
+}
+
+// convGemm converts Y = alpha * A' * B' + beta * C, where A' and B' are A and B, or their transposes.
+func convGemm(im *importer, n *NodeProto, inputs G.Nodes) (G.Nodes, error) {
+	a, b := inputs[0], inputs[1]
+	var err error
+	if attrInt(n, "transA", 0) != 0 {
+		if a, err = G.Transpose(a); err != nil {
+			return nil, err
+		}
+	}
+	if attrInt(n, "transB", 0) != 0 {
+		if b, err = G.Transpose(b); err != nil {
+			return nil, err
+		}
+	}
+	var retVal *G.Node
+	if retVal, err = G.Mul(a, b); err != nil {
+		return nil, err
+	}
+	if alpha := attrFloat(n, "alpha", 1); alpha != 1 {
+		if retVal, err = scaled(retVal, alpha); err != nil {
+			return nil, err
+		}
+	}
+	if len(inputs) < 3 || inputs[2] == nil {
+		return G.Nodes{retVal}, nil
+	}
+
+	c := inputs[2]
+	if beta := attrFloat(n, "beta", 1); beta != 1 {
+		if c, err = scaled(c, beta); err != nil {
+			return nil, err
+		}
+	}
+	retVal, err = broadcast(retVal, c, G.Add, G.BroadcastAdd)
+	return G.Nodes{retVal}, err
+}
+
+func scaled(a *G.Node, by float32) (*G.Node, error) {
+	s, err := scalar(a.Dtype(), by)
+	if err != nil {
+		return nil, err
+	}
+	return G.HadamardProd(a, s)
+}
+
+func convReshape(im *importer, n *NodeProto, inputs G.Nodes) (G.Nodes, error) {
+	t, err := im.constant(n, 1)
+	if err != nil {
+		return nil, err
+	}
+	var to []int
+	if to, err = ints(t); err != nil {
+		return nil, err
+	}
+	// a 0 keeps the dimension of the input, unless allowzero is set
+	from := inputs[0].Shape()
+	shape := make(tensor.Shape, len(to))
+	for i, d := range to {
+		shape[i] = d
+		if d == 0 && attrInt(n, "allowzero", 0) == 0 {
+			if i >= len(from) {
+				return nil, errors.Errorf("Cannot keep the dimension %d of the shape %v", i, from)
+			}
+			shape[i] = from[i]
+		}
+	}
+	retVal, err := G.Reshape(inputs[0], shape)
+	return G.Nodes{retVal}, err
+}
+
+func convFlatten(im *importer, n *NodeProto, inputs G.Nodes) (G.Nodes, error) {
+	x := inputs[0]
+	shape := x.Shape()
+	ax := int(attrInt(n, "axis", 1))
+	if ax < 0 {
+		ax += len(shape)
+	}
+	if ax < 0 || ax > len(shape) {
+		return nil, errors.Errorf("The axis %d is out of the range of %d dimensions", ax, len(shape))
+	}
+	retVal, err := G.Reshape(x, flattened(shape, ax))
+	return G.Nodes{retVal}, err
+}
+
+// flattened returns the shape of the matrix of the dimensions of a shape before an axis by the dimensions from it on.
+func flattened(shape tensor.Shape, ax int) tensor.Shape {
+	to := tensor.Shape{1, 1}
+	for i, d := range shape {
+		if i < ax {
+			to[0] *= d
+		} else {
+			to[1] *= d
+		}
+	}
+	return to
+}
+
+func convTranspose(im *importer, n *NodeProto, inputs G.Nodes) (G.Nodes, error) {
+	var axes []int
+	for _, p := range attrInts(n, "perm") {
+		axes = append(axes, int(p))
+	}
+	retVal, err := G.Transpose(inputs[0], axes...)
+	return G.Nodes{retVal}, err
+}
+
+// convSoftmax converts the Softmax of the opsets from 13 on, which normalize along one axis (perAxis), or before, which
+// normalize the input as a matrix of the dimensions before the axis by the dimensions from the axis on.
+func convSoftmax(perAxis bool) func(*importer, *NodeProto, G.Nodes) (G.Nodes, error) {
+	return func(im *importer, n *NodeProto, inputs G.Nodes) (G.Nodes, error) {
+		x := inputs[0]
+		def := int64(1)
+		if perAxis {
+			def = -1
+		}
+		ax, err := axis(attrInt(n, "axis", def), x.Dims())
+		if err != nil {
+			return nil, err
+		}
+		var retVal *G.Node
+		if perAxis || ax == x.Dims()-1 {
+			retVal, err = G.SoftMax(x, ax)
+			return G.Nodes{retVal}, err
+		}
+
+		shape := x.Shape()
+		if retVal, err = G.Reshape(x, flattened(shape, ax)); err != nil {
+			return nil, err
+		}
+		if retVal, err = G.SoftMax(retVal, 1); err != nil {
+			return nil, err
+		}
+		retVal, err = G.Reshape(retVal, shape)
+		return G.Nodes{retVal}, err
+	}
+}
+
+// reduce converts the reductions, whose axes are an attribute or, from some opset on, an input (axesInput).
+func reduce(fn func(*G.Node, ...int) (*G.Node, error), axesInput bool) func(*importer, *NodeProto, G.Nodes) (G.Nodes, error) {
+	return func(im *importer, n *NodeProto, inputs G.Nodes) (G.Nodes, error) {
+		x := inputs[0]
+		along, err := im.axes(n, axesInput)
+		if err != nil {
+			return nil, err
+		}
+		if len(along) == 0 && axesInput && attrInt(n, "noop_with_empty_axes", 0) != 0 {
+			return G.Nodes{x}, nil
+		}
+		for i := range along {
+			if along[i], err = axis(int64(along[i]), x.Dims()); err != nil {
+				return nil, err
+			}
+		}
+
+		retVal, err := fn(x, along...)
+		if err != nil || attrInt(n, "keepdims", 1) == 0 {
+			return G.Nodes{retVal}, err
+		}
+		// keep the reduced dimensions, of size 1
+		shape := x.Shape()
+		if len(along) == 0 {
+			for i := range shape {
+				shape[i] = 1
+			}
+		}
+		for _, ax := range along {
+			shape[ax] = 1
+		}
+		retVal, err = G.Reshape(retVal, shape)
+		return G.Nodes{retVal}, err
+	}
+}
+
+// axes returns the axes of a node, which are an attribute or, from some opset on, an input (axesInput).
+func (im *importer) axes(n *NodeProto, axesInput bool) ([]int, error) {
+	var retVal []int
+	if !axesInput {
+		for _, a := range attrInts(n, "axes") {
+			retVal = append(retVal, int(a))
+		}
+		return retVal, nil
+	}
+	t, err := im.constant(n, 1)
+	if err != nil || t == nil {
+		return nil, err
+	}
+	return ints(t)
+}
+
+// convSqueeze converts the Squeeze of the axes of size 1, or of all the dimensions of size 1 if no axes are given.
+func convSqueeze(axesInput bool) func(*importer, *NodeProto, G.Nodes) (G.Nodes, error) {
+	return func(im *importer, n *NodeProto, inputs G.Nodes) (G.Nodes, error) {
+		x := inputs[0]
+		along, err := im.axes(n, axesInput)
+		if err != nil {
+			return nil, err
+		}
+		from := x.Shape()
+		squeezed := make([]bool, len(from))
+		for _, a := range along {
+			var ax int
+			if ax, err = axis(int64(a), len(from)); err != nil {
+				return nil, err
+			}
+			if from[ax] != 1 {
+				return nil, errors.Errorf("Cannot squeeze the dimension %d of the shape %v", ax, from)
+			}
+			squeezed[ax] = true
+		}
+		var shape tensor.Shape
+		for i, d := range from {
+			if !squeezed[i] && (d != 1 || len(along) > 0) {
+				shape = append(shape, d)
+			}
+		}
+		if len(shape) == 0 {
+			return nil, errors.Errorf("Squeezing the shape %v to a scalar is not supported", from)
+		}
+		retVal, err := G.Reshape(x, shape)
+		return G.Nodes{retVal}, err
+	}
+}
+
+// convUnsqueeze converts the Unsqueeze that inserts dimensions of size 1 at the axes of the output.
+func convUnsqueeze(axesInput bool) func(*importer, *NodeProto, G.Nodes) (G.Nodes, error) {
+	return func(im *importer, n *NodeProto, inputs G.Nodes) (G.Nodes, error) {
+		x := inputs[0]
+		along, err := im.axes(n, axesInput)
+		if err != nil {
+			return nil, err
+		}
+		if len(along) == 0 {
+			return nil, errors.New("Unsqueeze needs the axes")
+		}
+		from := x.Shape()
+		dims := len(from) + len(along)
+		inserted := make([]bool, dims)
+		for _, a := range along {
+			var ax int
+			if ax, err = axis(int64(a), dims); err != nil {
+				return nil, err
+			}
+			if inserted[ax] {
+				return nil, errors.Errorf("The axis %d is repeated", ax)
+			}
+			inserted[ax] = true
+		}
+		shape := make(tensor.Shape, dims)
+		j := 0
+		for i := range shape {
+			if inserted[i] {
+				shape[i] = 1
+				continue
+			}
+			shape[i] = from[j]
+			j++
+		}
+		retVal, err := G.Reshape(x, shape)
+		return G.Nodes{retVal}, err
+	}
+}
@@ -0,0 +1,151 @@
+package onnx
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorgonia.org/tensor"
+)
+
+// single returns a model of a single node, whose inputs are a and the given initializers.
+func single(opset int64, n *NodeProto, a tensor.Shape, inits ...*TensorProto) *ModelProto {
+	dims := make([]int64, len(a))
+	for i, d := range a {
+		dims[i] = int64(d)
+	}
+	n.Output = []string{"y"}
+	return &ModelProto{
+		OpsetImport: []*OperatorSetID{{Version: opset}},
+		Graph: &GraphProto{
+			Node:        []*NodeProto{n},
+			Initializer: inits,
+			Input:       []*ValueInfoProto{valueInfo("a", dims...)},
+			Output:      []*ValueInfoProto{{Name: "y"}},
+		},
+	}
+}
+
+func TestConverters(t *testing.T) {
+	a := tensor.New(tensor.WithShape(2, 3), tensor.WithBacking([]float32{1, 2, 3, 4, 5, 6}))
+	cases := []struct {
+		name  string
+		opset int64
+		node  *NodeProto
+		inits []*TensorProto
+		shape tensor.Shape
+		want  []float32
+	}{
+		{"Add of a row", 13, &NodeProto{OpType: "Add", Input: []string{"a", "b"}},
+			[]*TensorProto{floats("b", []int64{3}, 10, 20, 30)}, tensor.Shape{2, 3}, []float32{11, 22, 33, 14, 25, 36}},
+		{"Sub of a column", 13, &NodeProto{OpType: "Sub", Input: []string{"a", "b"}},
+			[]*TensorProto{floats("b", []int64{2, 1}, 1, 2)}, tensor.Shape{2, 3}, []float32{0, 1, 2, 2, 3, 4}},
+		{"Mul broadcast on the left", 13, &NodeProto{OpType: "Mul", Input: []string{"b", "a"}},
+			[]*TensorProto{floats("b", []int64{1, 3}, 1, 0, -1)}, tensor.Shape{2, 3}, []float32{1, 0, -3, 4, 0, -6}},
+		{"Div by a scalar", 13, &NodeProto{OpType: "Div", Input: []string{"a", "b"}},
+			[]*TensorProto{floats("b", nil, 2)}, tensor.Shape{2, 3}, []float32{0.5, 1, 1.5, 2, 2.5, 3}},
+		{"Add of opset 6, broadcast from an axis", 6, &NodeProto{OpType: "Add", Input: []string{"a", "b"}, Attribute: []*AttributeProto{
+			{Name: "broadcast", I: 1}, {Name: "axis", I: 0}}},
+			[]*TensorProto{floats("b", []int64{2}, 10, 20)}, tensor.Shape{2, 3}, []float32{11, 12, 13, 24, 25, 26}},
+		{"Sub of opset 6, broadcast to the last dimensions", 6, &NodeProto{OpType: "Sub", Input: []string{"a", "b"}, Attribute: []*AttributeProto{{Name: "broadcast", I: 1}}},
+			[]*TensorProto{floats("b", []int64{3}, 1, 2, 3)}, tensor.Shape{2, 3}, []float32{0, 0, 0, 3, 3, 3}},
+		{"Pow", 13, &NodeProto{OpType: "Pow", Input: []string{"a", "b"}},
+			[]*TensorProto{floats("b", []int64{2, 3}, 2, 2, 2, 0, 0, 1)}, tensor.Shape{2, 3}, []float32{1, 4, 9, 1, 1, 6}},
+		{"MatMul", 13, &NodeProto{OpType: "MatMul", Input: []string{"a", "b"}},
+			[]*TensorProto{floats("b", []int64{3, 1}, 1, 0, -1)}, tensor.Shape{2, 1}, []float32{-2, -2}},
+		{"Gemm", 13, &NodeProto{OpType: "Gemm", Input: []string{"a", "b", "c"}, Attribute: []*AttributeProto{
+			{Name: "transA", I: 1}, {Name: "alpha", F: 2}, {Name: "beta", F: -1}}},
+			[]*TensorProto{floats("b", []int64{2, 1}, 1, 1), floats("c", nil, 1)}, tensor.Shape{3, 1}, []float32{9, 13, 17}},
+		{"Gemm without C", 13, &NodeProto{OpType: "Gemm", Input: []string{"a", "b"}, Attribute: []*AttributeProto{{Name: "transB", I: 1}}},
+			[]*TensorProto{floats("b", []int64{1, 3}, 1, 1, 1)}, tensor.Shape{2, 1}, []float32{6, 15}},
+		{"Neg", 13, &NodeProto{OpType: "Neg", Input: []string{"a"}}, nil, tensor.Shape{2, 3}, []float32{-1, -2, -3, -4, -5, -6}},
+		{"Identity", 13, &NodeProto{OpType: "Identity", Input: []string{"a"}}, nil, tensor.Shape{2, 3}, []float32{1, 2, 3, 4, 5, 6}},
+		{"Reshape", 13, &NodeProto{OpType: "Reshape", Input: []string{"a", "s"}},
+			[]*TensorProto{int64s("s", 0, -1, 1)}, tensor.Shape{2, 3, 1}, []float32{1, 2, 3, 4, 5, 6}},
+		{"Unsqueeze of an attribute", 11, &NodeProto{OpType: "Unsqueeze", Input: []string{"a"}, Attribute: []*AttributeProto{{Name: "axes", Ints: []int64{0}}}},
+			nil, tensor.Shape{1, 2, 3}, []float32{1, 2, 3, 4, 5, 6}},
+		{"Unsqueeze of an input", 13, &NodeProto{OpType: "Unsqueeze", Input: []string{"a", "axes"}},
+			[]*TensorProto{int64s("axes", 1, -1)}, tensor.Shape{2, 1, 3, 1}, []float32{1, 2, 3, 4, 5, 6}},
+		{"Flatten", 13, &NodeProto{OpType: "Flatten", Input: []string{"a"}, Attribute: []*AttributeProto{{Name: "axis", I: 0}}},
+			nil, tensor.Shape{1, 6}, []float32{1, 2, 3, 4, 5, 6}},
+		{"Transpose", 13, &NodeProto{OpType: "Transpose", Input: []string{"a"}, Attribute: []*AttributeProto{{Name: "perm", Ints: []int64{1, 0}}}},
+			nil, tensor.Shape{3, 2}, []float32{1, 4, 2, 5, 3, 6}},
+		{"ReduceSum of an attribute", 11, &NodeProto{OpType: "ReduceSum", Input: []string{"a"}, Attribute: []*AttributeProto{{Name: "axes", Ints: []int64{-1}}}},
+			nil, tensor.Shape{2, 1}, []float32{6, 15}},
+		{"ReduceSum of an input", 13, &NodeProto{OpType: "ReduceSum", Input: []string{"a", "axes"}, Attribute: []*AttributeProto{{Name: "keepdims", I: 0}}},
+			[]*TensorProto{int64s("axes", 0)}, tensor.Shape{3}, []float32{5, 7, 9}},
+		{"ReduceMean of everything", 13, &NodeProto{OpType: "ReduceMean", Input: []string{"a"}},
+			nil, tensor.Shape{1, 1}, []float32{3.5}},
+		{"Softmax of the coerced matrix", 11, &NodeProto{OpType: "Softmax", Input: []string{"a"}, Attribute: []*AttributeProto{{Name: "axis", I: 0}}},
+			nil, tensor.Shape{2, 3}, []float32{0.0042697, 0.0116064, 0.0315496, 0.0857608, 0.233122, 0.633691}},
+		{"Softmax of an axis", 13, &NodeProto{OpType: "Softmax", Input: []string{"a"}, Attribute: []*AttributeProto{{Name: "axis", I: 0}}},
+			nil, tensor.Shape{2, 3}, []float32{0.0474259, 0.0474259, 0.0474259, 0.952574, 0.952574, 0.952574}},
+	}
+	for _, c := range cases {
+		out := run(t, single(c.opset, c.node, a.Shape(), c.inits...), map[string]tensor.Tensor{"a": a.Clone().(tensor.Tensor)})
+		assert.Equal(t, c.shape, out[0].Shape(), c.name)
+		assert.InDeltaSlice(t, c.want, out[0].Data(), 1e-5, c.name)
+	}
+}
+
+func TestConverters_Constant(t *testing.T) {
+	shape, err := NewTensorProto("", tensor.New(tensor.WithShape(1), tensor.WithBacking([]int64{-1})))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := single(13, &NodeProto{OpType: "Reshape", Input: []string{"a", "s"}}, tensor.Shape{2, 2})
+	m.Graph.Node = append([]*NodeProto{{OpType: "Constant", Output: []string{"s"}, Attribute: []*AttributeProto{
+		{Name: "value", Type: AttributeTensor, T: shape}}}}, m.Graph.Node...)
+	a := tensor.New(tensor.WithShape(2, 2), tensor.WithBacking([]float32{1, 2, 3, 4}))
+	out := run(t, m, map[string]tensor.Tensor{"a": a})
+	assert.Equal(t, tensor.Shape{4}, out[0].Shape())
+}
+
+func TestConverters_Squeeze(t *testing.T) {
+	a := tensor.New(tensor.WithShape(1, 3, 1), tensor.WithBacking([]float32{1, 2, 3}))
+	cases := []struct {
+		opset int64
+		node  *NodeProto
+		inits []*TensorProto
+		shape tensor.Shape
+	}{
+		{11, &NodeProto{OpType: "Squeeze", Input: []string{"a"}}, nil, tensor.Shape{3}},
+		{11, &NodeProto{OpType: "Squeeze", Input: []string{"a"}, Attribute: []*AttributeProto{{Name: "axes", Ints: []int64{-1}}}}, nil, tensor.Shape{1, 3}},
+		{13, &NodeProto{OpType: "Squeeze", Input: []string{"a", "axes"}}, []*TensorProto{int64s("axes", 0)}, tensor.Shape{3, 1}},
+	}
+	for _, c := range cases {
+		out := run(t, single(c.opset, c.node, a.Shape(), c.inits...), map[string]tensor.Tensor{"a": a.Clone().(tensor.Tensor)})
+		assert.Equal(t, c.shape, out[0].Shape(), "%v", c.node.Attribute)
+		assert.Equal(t, []float32{1, 2, 3}, out[0].Data())
+	}
+}
+
+func TestConverters_errors(t *testing.T) {
+	for _, m := range []*ModelProto{
+		single(13, &NodeProto{OpType: "Add", Input: []string{"a", "b"}}, tensor.Shape{2, 3}, floats("b", []int64{2}, 1, 2)),
+		single(13, &NodeProto{OpType: "Reshape", Input: []string{"a", "a"}}, tensor.Shape{2, 3}),
+		single(13, &NodeProto{OpType: "Softmax", Input: []string{"a"}, Attribute: []*AttributeProto{{Name: "axis", I: 2}}}, tensor.Shape{2, 3}),
+		single(13, &NodeProto{OpType: "Constant", Attribute: []*AttributeProto{{Name: "value_float", F: 1}}}, tensor.Shape{2, 3}),
+		single(13, &NodeProto{OpType: "MatMul", Input: []string{"a", "b"}}, tensor.Shape{2, 3, 4, 5}, floats("b", []int64{5, 1}, 1, 1, 1, 1, 1)),
+		single(6, &NodeProto{OpType: "Add", Input: []string{"a", "b"}}, tensor.Shape{2, 3}, floats("b", []int64{3}, 1, 2, 3)),
+		single(6, &NodeProto{OpType: "Add", Input: []string{"a", "b"}, Attribute: []*AttributeProto{{Name: "broadcast", I: 1}, {Name: "axis", I: 1}}},
+			tensor.Shape{2, 3}, floats("b", []int64{2}, 1, 2)),
+		single(13, &NodeProto{OpType: "Squeeze", Input: []string{"a", "axes"}}, tensor.Shape{2, 3}, int64s("axes", 0)),
+		single(13, &NodeProto{OpType: "Unsqueeze", Input: []string{"a", "axes"}}, tensor.Shape{2, 3}, int64s("axes", 0, 0)),
+		single(13, &NodeProto{OpType: "Unsqueeze", Input: []string{"a"}}, tensor.Shape{2, 3}),
+	} {
+		_, err := Import(m)
+		assert.Error(t, err, "%v", m.Graph.Node[0].OpType)
+	}
+}
+
+func TestSupported(t *testing.T) {
+	assert := assert.New(t)
+	s := Supported(13)
+	assert.Contains(s, "Gemm")
+	assert.Contains(s, "Reshape")
+	assert.NotContains(Supported(4), "Reshape")
+	assert.True(len(s) > 20)
+	for i := 1; i < len(s); i++ {
+		assert.True(s[i-1] < s[i], "sorted")
+	}
+}
@@ -0,0 +1,656 @@
+package onnx
+
+import (
+	"encoding/binary"
+	"math"
+
+	"github.com/pkg/errors"
+)
+
+// The messages of onnx.proto that the importer reads. Only the fields that it uses are decoded; the others are
+// skipped. The field numbers are those of onnx.proto, and the messages are encoded and decoded by hand, which saves
+// depending on the generated code of the whole schema.
+
+// ModelProto is the top level message of an ONNX file.
+type ModelProto struct {
+	IRVersion       int64            // 1
+	ProducerName    string           // 2
+	ProducerVersion string           // 3
+	Domain          string           // 4
+	ModelVersion    int64            // 5
+	DocString       string           // 6
+	Graph           *GraphProto      // 7
+	OpsetImport     []*OperatorSetID // 8
+}
+
+// OperatorSetID is an operator set that a model uses. The empty domain is the default ONNX domain.
+type OperatorSetID struct {
+	Domain  string // 1
+	Version int64  // 2
+}
+
+// GraphProto is a computation graph.
+type GraphProto struct {
+	Node        []*NodeProto      // 1
+	Name        string            // 2
+	Initializer []*TensorProto    // 5
+	DocString   string            // 10
+	Input       []*ValueInfoProto // 11
+	Output      []*ValueInfoProto // 12
+	ValueInfo   []*ValueInfoProto // 13
+}
+
+// NodeProto is an operator of a graph, whose inputs and outputs are named values.
+type NodeProto struct {
+	Input     []string          // 1
+	Output    []string          // 2
+	Name      string            // 3
+	OpType    string            // 4
+	Attribute []*AttributeProto // 5
+	DocString string            // 6
+	Domain    string            // 7
+}
+
+// AttributeType is the type of an attribute.
+type AttributeType int32
+
+// The AttributeTypes of the fields of AttributeProto.
+const (
+	AttributeUndefined AttributeType = iota
+	AttributeFloat
+	AttributeInt
+	AttributeString
+	AttributeTensor
+	AttributeGraph
+	AttributeFloats
+	AttributeInts
+	AttributeStrings
+)
+
+// AttributeProto is an attribute of a node.
+type AttributeProto struct {
+	Name    string        // 1
+	F       float32       // 2
+	I       int64         // 3
+	S       []byte        // 4
+	T       *TensorProto  // 5
+	Floats  []float32     // 7
+	Ints    []int64       // 8
+	Strings [][]byte      // 9
+	Type    AttributeType // 20
+}
+
+// DataType is the element type of a tensor.
+type DataType int32
+
+// The DataTypes of onnx.proto.
+const (
+	Undefined DataType = iota
+	Float
+	Uint8
+	Int8
+	Uint16
+	Int16
+	Int32
+	Int64
+	String
+	Bool
+	Float16
+	Double
+	Uint32
+	Uint64
+	Complex64
+	Complex128
+)
+
+// TensorProto is a constant tensor, such as an initializer. The elements are in RawData, little-endian, or in the
+// typed field of the DataType.
+type TensorProto struct {
+	Dims       []int64   // 1
+	DataType   DataType  // 2
+	FloatData  []float32 // 4
+	Int32Data  []int32   // 5
+	Int64Data  []int64   // 7
+	Name       string    // 8
+	RawData    []byte    // 9
+	DoubleData []float64 // 10
+	Uint64Data []uint64  // 11
+}
+
+// ValueInfoProto describes a value of a graph, such as an input. Shape is nil if the shape is unknown.
+type ValueInfoProto struct {
+	Name     string      // 1
+	ElemType DataType    // 2.1.1: type.tensor_type.elem_type
+	Shape    []Dimension // 2.1.2: type.tensor_type.shape
+}
+
+// Dimension is a dimension of a ValueInfoProto: either a size, or the name of a size that is only known at run time.
+type Dimension struct {
+	Value int64  // 1
+	Param string // 2
+}
+
+/* decoding */
+
+// decoder reads the fields of a message.
+type decoder struct{ p []byte }
+
+func (d *decoder) varint() (uint64, error) {
+	v, n := binary.Uvarint(d.p)
+	if n <= 0 {
+		return 0, errors.New("Malformed varint")
+	}
+	d.p = d.p[n:]
+	return v, nil
+}
+
+func (d *decoder) fixed(size int) (uint64, error) {
+	if len(d.p) < size {
+		return 0, errors.New("Truncated fixed-size field")
+	}
+	var v uint64
+	if size == 4 {
+		v = uint64(binary.LittleEndian.Uint32(d.p))
+	} else {
+		v = binary.LittleEndian.Uint64(d.p)
+	}
+	d.p = d.p[size:]
+	return v, nil
+}
+
+func (d *decoder) bytes() ([]byte, error) {
+	n, err := d.varint()
+	if err != nil {
+		return nil, err
+	}
+	if n > uint64(len(d.p)) {
+		return nil, errors.New("Truncated length-delimited field")
+	}
+	b := d.p[:n]
+	d.p = d.p[n:]
+	return b, nil
+}
+
+// field is a decoded field. Only one of v and b is set, depending on the wire type.
+type field struct {
+	n, wire int
+	v       uint64
+	b       []byte
+}
+
+// fields calls fn with every field of the message p.
+func fields(p []byte, fn func(f field) error) error {
+	d := decoder{p}
+	for len(d.p) > 0 {
+		tag, err := d.varint()
+		if err != nil {
+			return err
+		}
+		f := field{n: int(tag >> 3), wire: int(tag & 7)}
+		switch f.wire {
+		case 0:
+			f.v, err = d.varint()
+		case 1:
+			f.v, err = d.fixed(8)
+		case 2:
+			f.b, err = d.bytes()
+		case 5:
+			f.v, err = d.fixed(4)
+		default:
+			err = errors.Errorf("Unsupported wire type %d of field %d", f.wire, f.n)
+		}
+		if err != nil {
+			return err
+		}
+		if err = fn(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// varints returns the values of a repeated varint field, which may be packed or not.
+func (f field) varints() ([]uint64, error) {
+	if f.wire == 0 {
+		return []uint64{f.v}, nil
+	}
+	var vs []uint64
+	d := decoder{f.b}
+	for len(d.p) > 0 {
+		v, err := d.varint()
+		if err != nil {
+			return nil, err
+		}
+		vs = append(vs, v)
+	}
+	return vs, nil
+}
+
+// fixeds returns the values of a repeated fixed-size field, which may be packed or not.
+func (f field) fixeds(size int) ([]uint64, error) {
+	if f.wire != 2 {
+		return []uint64{f.v}, nil
+	}
+	var vs []uint64
+	d := decoder{f.b}
+	for len(d.p) > 0 {
+		v, err := d.fixed(size)
+		if err != nil {
+			return nil, err
+		}
+		vs = append(vs, v)
+	}
+	return vs, nil
+}
+
+func (f field) int64s(dst []int64) ([]int64, error) {
+	vs, err := f.varints()
+	for _, v := range vs {
+		dst = append(dst, int64(v))
+	}
+	return dst, err
+}
+
+func (f field) float32s(dst []float32) ([]float32, error) {
+	vs, err := f.fixeds(4)
+	for _, v := range vs {
+		dst = append(dst, math.Float32frombits(uint32(v)))
+	}
+	return dst, err
+}
+
+// Unmarshal decodes a ModelProto.
+func (m *ModelProto) Unmarshal(p []byte) error {
+	return fields(p, func(f field) (err error) {
+		switch f.n {
+		case 1:
+			m.IRVersion = int64(f.v)
+		case 2:
+			m.ProducerName = string(f.b)
+		case 3:
+			m.ProducerVersion = string(f.b)
+		case 4:
+			m.Domain = string(f.b)
+		case 5:
+			m.ModelVersion = int64(f.v)
+		case 6:
+			m.DocString = string(f.b)
+		case 7:
+			m.Graph = new(GraphProto)
+			err = m.Graph.Unmarshal(f.b)
+		case 8:
+			o := new(OperatorSetID)
+			m.OpsetImport = append(m.OpsetImport, o)
+			err = fields(f.b, func(f field) error {
+				switch f.n {
+				case 1:
+					o.Domain = string(f.b)
+				case 2:
+					o.Version = int64(f.v)
+				}
+				return nil
+			})
+		}
+		return err
+	})
+}
+
+// Unmarshal decodes a GraphProto.
+func (g *GraphProto) Unmarshal(p []byte) error {
+	return fields(p, func(f field) (err error) {
+		switch f.n {
+		case 1:
+			n := new(NodeProto)
+			g.Node = append(g.Node, n)
+			err = n.Unmarshal(f.b)
+		case 2:
+			g.Name = string(f.b)
+		case 5:
+			t := new(TensorProto)
+			g.Initializer = append(g.Initializer, t)
+			err = t.Unmarshal(f.b)
+		case 10:
+			g.DocString = string(f.b)
+		case 11, 12, 13:
+			vi := new(ValueInfoProto)
+			err = vi.Unmarshal(f.b)
+			switch f.n {
+			case 11:
+				g.Input = append(g.Input, vi)
+			case 12:
+				g.Output = append(g.Output, vi)
+			default:
+				g.ValueInfo = append(g.ValueInfo, vi)
+			}
+		}
+		return err
+	})
+}
+
+// Unmarshal decodes a NodeProto.
+func (n *NodeProto) Unmarshal(p []byte) error {
+	return fields(p, func(f field) (err error) {
+		switch f.n {
+		case 1:
+			n.Input = append(n.Input, string(f.b))
+		case 2:
+			n.Output = append(n.Output, string(f.b))
+		case 3:
+			n.Name = string(f.b)
+		case 4:
+			n.OpType = string(f.b)
+		case 5:
+			a := new(AttributeProto)
+			n.Attribute = append(n.Attribute, a)
+			err = a.Unmarshal(f.b)
+		case 6:
+			n.DocString = string(f.b)
+		case 7:
+			n.Domain = string(f.b)
+		}
+		return err
+	})
+}
+
+// Unmarshal decodes an AttributeProto.
+func (a *AttributeProto) Unmarshal(p []byte) error {
+	return fields(p, func(f field) (err error) {
+		switch f.n {
+		case 1:
+			a.Name = string(f.b)
+		case 2:
+			a.F = math.Float32frombits(uint32(f.v))
+		case 3:
+			a.I = int64(f.v)
+		case 4:
+			a.S = f.b
+		case 5:
+			a.T = new(TensorProto)
+			err = a.T.Unmarshal(f.b)
+		case 7:
+			a.Floats, err = f.float32s(a.Floats)
+		case 8:
+			a.Ints, err = f.int64s(a.Ints)
+		case 9:
+			a.Strings = append(a.Strings, f.b)
+		case 20:
+			a.Type = AttributeType(f.v)
+		}
+		return err
+	})
+}
+
+// Unmarshal decodes a TensorProto.
+func (t *TensorProto) Unmarshal(p []byte) error {
+	return fields(p, func(f field) (err error) {
+		switch f.n {
+		case 1:
+			t.Dims, err = f.int64s(t.Dims)
+		case 2:
+			t.DataType = DataType(f.v)
+		case 4:
+			t.FloatData, err = f.float32s(t.FloatData)
+		case 5:
+			var vs []uint64
+			vs, err = f.varints()
+			for _, v := range vs {
+				t.Int32Data = append(t.Int32Data, int32(v))
+			}
+		case 7:
+			t.Int64Data, err = f.int64s(t.Int64Data)
+		case 8:
+			t.Name = string(f.b)
+		case 9:
+			t.RawData = f.b
+		case 10:
+			var vs []uint64
+			vs, err = f.fixeds(8)
+			for _, v := range vs {
+				t.DoubleData = append(t.DoubleData, math.Float64frombits(v))
+			}
+		case 11:
+			t.Uint64Data, err = f.varints()
+		}
+		return err
+	})
+}
+
+// Unmarshal decodes a ValueInfoProto, of which only the tensor types are read.
+func (vi *ValueInfoProto) Unmarshal(p []byte) error {
+	return fields(p, func(f field) error {
+		switch f.n {
+		case 1:
+			vi.Name = string(f.b)
+		case 2:
+			return fields(f.b, func(f field) error { // TypeProto
+				if f.n != 1 {
+					return nil
+				}
+				return fields(f.b, func(f field) error { // TypeProto.Tensor
+					switch f.n {
+					case 1:
+						vi.ElemType = DataType(f.v)
+					case 2:
+						vi.Shape = []Dimension{}
+						return fields(f.b, func(f field) error { // TensorShapeProto
+							if f.n != 1 {
+								return nil
+							}
+							var dim Dimension
+							err := fields(f.b, func(f field) error {
+								switch f.n {
+								case 1:
+									dim.Value = int64(f.v)
+								case 2:
+									dim.Param = string(f.b)
+								}
+								return nil
+							})
+							vi.Shape = append(vi.Shape, dim)
+							return err
+						})
+					}
+					return nil
+				})
+			})
+		}
+		return nil
+	})
+}
+
+/* encoding */
+
+// encoder writes the fields of a message. The fields of zero values are omitted, as in proto3.
+type encoder struct{ buf []byte }
+
+func (e *encoder) rawVarint(v uint64) {
+	var b [binary.MaxVarintLen64]byte
+	e.buf = append(e.buf, b[:binary.PutUvarint(b[:], v)]...)
+}
+
+func (e *encoder) tag(n, wire int) { e.rawVarint(uint64(n<<3 | wire)) }
+
+func (e *encoder) varint(n int, v uint64) {
+	if v != 0 {
+		e.tag(n, 0)
+		e.rawVarint(v)
+	}
+}
+
+func (e *encoder) bytes(n int, b []byte) {
+	if len(b) > 0 {
+		e.tag(n, 2)
+		e.rawVarint(uint64(len(b)))
+		e.buf = append(e.buf, b...)
+	}
+}
+
+func (e *encoder) string(n int, s string) { e.bytes(n, []byte(s)) }
+
+// message writes an embedded message, even if it is empty.
+func (e *encoder) message(n int, b []byte) {
+	e.tag(n, 2)
+	e.rawVarint(uint64(len(b)))
+	e.buf = append(e.buf, b...)
+}
+
+func (e *encoder) packedVarints(n int, vs []uint64) {
+	var p encoder
+	for _, v := range vs {
+		p.rawVarint(v)
+	}
+	e.bytes(n, p.buf)
+}
+
+func (e *encoder) packedInt64s(n int, vs []int64) {
+	us := make([]uint64, len(vs))
+	for i, v := range vs {
+		us[i] = uint64(v)
+	}
+	e.packedVarints(n, us)
+}
+
+func (e *encoder) packedFloat32s(n int, vs []float32) {
+	p := make([]byte, 4*len(vs))
+	for i, v := range vs {
+		binary.LittleEndian.PutUint32(p[4*i:], math.Float32bits(v))
+	}
+	e.bytes(n, p)
+}
+
+// Marshal encodes a ModelProto.
+func (m *ModelProto) Marshal() []byte {
+	var e encoder
+	e.varint(1, uint64(m.IRVersion))
+	e.string(2, m.ProducerName)
+	e.string(3, m.ProducerVersion)
+	e.string(4, m.Domain)
+	e.varint(5, uint64(m.ModelVersion))
+	e.string(6, m.DocString)
+	if m.Graph != nil {
+		e.message(7, m.Graph.Marshal())
+	}
+	for _, o := range m.OpsetImport {
+		var oe encoder
+		oe.string(1, o.Domain)
+		oe.varint(2, uint64(o.Version))
+		e.message(8, oe.buf)
+	}
+	return e.buf
+}
+
+// Marshal encodes a GraphProto.
+func (g *GraphProto) Marshal() []byte {
+	var e encoder
+	for _, n := range g.Node {
+		e.message(1, n.Marshal())
+	}
+	e.string(2, g.Name)
+	for _, t := range g.Initializer {
+		e.message(5, t.Marshal())
+	}
+	e.string(10, g.DocString)
+	for _, vi := range g.Input {
+		e.message(11, vi.Marshal())
+	}
+	for _, vi := range g.Output {
+		e.message(12, vi.Marshal())
+	}
+	for _, vi := range g.ValueInfo {
+		e.message(13, vi.Marshal())
+	}
+	return e.buf
+}
+
+// Marshal encodes a NodeProto.
+func (n *NodeProto) Marshal() []byte {
+	var e encoder
+	for _, in := range n.Input {
+		e.tag(1, 2) // the empty names of the omitted optional inputs are kept
+		e.rawVarint(uint64(len(in)))
+		e.buf = append(e.buf, in...)
+	}
+	for _, out := range n.Output {
+		e.string(2, out)
+	}
+	e.string(3, n.Name)
+	e.string(4, n.OpType)
+	for _, a := range n.Attribute {
+		e.message(5, a.Marshal())
+	}
+	e.string(6, n.DocString)
+	e.string(7, n.Domain)
+	return e.buf
+}
+
+// Marshal encodes an AttributeProto.
+func (a *AttributeProto) Marshal() []byte {
+	var e encoder
+	e.string(1, a.Name)
+	if a.F != 0 {
+		e.tag(2, 5)
+		var b [4]byte
+		binary.LittleEndian.PutUint32(b[:], math.Float32bits(a.F))
+		e.buf = append(e.buf, b[:]...)
+	}
+	e.varint(3, uint64(a.I))
+	e.bytes(4, a.S)
+	if a.T != nil {
+		e.message(5, a.T.Marshal())
+	}
+	e.packedFloat32s(7, a.Floats)
+	e.packedInt64s(8, a.Ints)
+	for _, s := range a.Strings {
+		e.message(9, s)
+	}
+	e.varint(20, uint64(a.Type))
+	return e.buf
+}
+
+// Marshal encodes a TensorProto.
+func (t *TensorProto) Marshal() []byte {
+	var e encoder
+	e.packedInt64s(1, t.Dims)
+	e.varint(2, uint64(t.DataType))
+	e.packedFloat32s(4, t.FloatData)
+	i32 := make([]uint64, len(t.Int32Data))
+	for i, v := range t.Int32Data {
+		i32[i] = uint64(v)
+	}
+	e.packedVarints(5, i32)
+	e.packedInt64s(7, t.Int64Data)
+	e.string(8, t.Name)
+	e.bytes(9, t.RawData)
+	if len(t.DoubleData) > 0 {
+		p := make([]byte, 8*len(t.DoubleData))
+		for i, v := range t.DoubleData {
+			binary.LittleEndian.PutUint64(p[8*i:], math.Float64bits(v))
+		}
+		e.bytes(10, p)
+	}
+	e.packedVarints(11, t.Uint64Data)
+	return e.buf
+}
+
+// Marshal encodes a ValueInfoProto.
+func (vi *ValueInfoProto) Marshal() []byte {
+	var tt encoder // TypeProto.Tensor
+	tt.varint(1, uint64(vi.ElemType))
+	if vi.Shape != nil {
+		var shape encoder
+		for _, d := range vi.Shape {
+			var de encoder
+			de.varint(1, uint64(d.Value))
+			de.string(2, d.Param)
+			shape.message(1, de.buf)
+		}
+		tt.message(2, shape.buf)
+	}
+	var typ encoder
+	typ.message(1, tt.buf)
+
+	var e encoder
+	e.string(1, vi.Name)
+	e.message(2, typ.buf)
+	return e.buf
+}
@@ -0,0 +1,95 @@
+package onnx
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestModelProto_roundTrip(t *testing.T) {
+	assert := assert.New(t)
+	m := &ModelProto{
+		IRVersion:       7,
+		ProducerName:    "test",
+		ProducerVersion: "1.0",
+		ModelVersion:    3,
+		DocString:       "a model",
+		OpsetImport:     []*OperatorSetID{{Version: 13}, {Domain: "ai.onnx.ml", Version: 2}},
+		Graph: &GraphProto{
+			Name: "g",
+			Node: []*NodeProto{{
+				Name:   "gemm",
+				OpType: "Gemm",
+				Input:  []string{"x", "w", ""},
+				Output: []string{"y"},
+				Attribute: []*AttributeProto{
+					{Name: "alpha", Type: AttributeFloat, F: 0.5},
+					{Name: "transB", Type: AttributeInt, I: 1},
+					{Name: "s", Type: AttributeString, S: []byte("str")},
+					{Name: "ints", Type: AttributeInts, Ints: []int64{1, -2, 3}},
+					{Name: "floats", Type: AttributeFloats, Floats: []float32{1.5, -2}},
+					{Name: "strings", Type: AttributeStrings, Strings: [][]byte{[]byte("a"), []byte("b")}},
+					{Name: "t", Type: AttributeTensor, T: &TensorProto{Dims: []int64{2}, DataType: Int64, Int64Data: []int64{4, -5}}},
+				},
+			}},
+			Initializer: []*TensorProto{
+				{Name: "w", Dims: []int64{2, 2}, DataType: Float, FloatData: []float32{1, 2, 3, 4}},
+				{Name: "d", Dims: []int64{1}, DataType: Double, DoubleData: []float64{0.25}},
+				{Name: "i", Dims: []int64{2}, DataType: Int32, Int32Data: []int32{-1, 7}},
+				{Name: "u", Dims: []int64{1}, DataType: Uint64, Uint64Data: []uint64{1 << 63}},
+				{Name: "r", Dims: []int64{1}, DataType: Float, RawData: []byte{0, 0, 128, 63}},
+			},
+			Input: []*ValueInfoProto{
+				{Name: "x", ElemType: Float, Shape: []Dimension{{Param: "N"}, {Value: 2}}},
+				{Name: "unknown", ElemType: Float},
+				{Name: "scalar", ElemType: Float, Shape: []Dimension{}},
+			},
+			Output: []*ValueInfoProto{{Name: "y", ElemType: Float, Shape: []Dimension{{Param: "N"}, {Value: 2}}}},
+		},
+	}
+
+	got := new(ModelProto)
+	if err := got.Unmarshal(m.Marshal()); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(m, got)
+}
+
+func TestProto_unpackedAndUnknownFields(t *testing.T) {
+	assert := assert.New(t)
+	var e encoder
+	// unpacked dims, as written by the proto2 encoders
+	e.varint(1, 2)
+	e.varint(1, 3)
+	e.varint(2, uint64(Int64))
+	e.packedInt64s(7, []int64{1, 2, 3})
+	e.varint(7, 4)
+	e.varint(7, 5)
+	e.varint(7, 6)
+	// fields that the decoder does not know
+	e.string(12, "doc")
+	e.tag(99, 5)
+	e.buf = append(e.buf, 1, 2, 3, 4)
+	e.tag(98, 1)
+	e.buf = append(e.buf, 1, 2, 3, 4, 5, 6, 7, 8)
+
+	var tp TensorProto
+	if err := tp.Unmarshal(e.buf); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]int64{2, 3}, tp.Dims)
+	assert.Equal(Int64, tp.DataType)
+	assert.Equal([]int64{1, 2, 3, 4, 5, 6}, tp.Int64Data)
+}
+
+func TestProto_malformed(t *testing.T) {
+	var m ModelProto
+	for _, p := range [][]byte{
+		{0x3a, 10, 1},      // the graph is longer than the message
+		{0x08},             // a truncated varint
+		{0x0b},             // the unsupported wire type 3
+		{0x3a, 2, 0x0a, 5}, // a truncated node in the graph
+	} {
+		assert.Error(t, m.Unmarshal(p), "%v", p)
+	}
+}
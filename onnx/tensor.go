@@ -0,0 +1,160 @@
+package onnx
+
+import (
+	"bytes"
+	"encoding/binary"
+	"reflect"
+
+	"github.com/pkg/errors"
+	"gorgonia.org/tensor"
+)
+
+// dtypes are the DataTypes that have a tensor.Dtype.
+var dtypes = map[DataType]tensor.Dtype{
+	Float:      tensor.Float32,
+	Double:     tensor.Float64,
+	Int8:       tensor.Int8,
+	Int16:      tensor.Int16,
+	Int32:      tensor.Int32,
+	Int64:      tensor.Int64,
+	Uint8:      tensor.Uint8,
+	Uint16:     tensor.Uint16,
+	Uint32:     tensor.Uint32,
+	Uint64:     tensor.Uint64,
+	Bool:       tensor.Bool,
+	Complex64:  tensor.Complex64,
+	Complex128: tensor.Complex128,
+}
+
+// Dtype returns the tensor.Dtype of a DataType.
+func (dt DataType) Dtype() (tensor.Dtype, error) {
+	if d, ok := dtypes[dt]; ok {
+		return d, nil
+	}
+	return tensor.Dtype{}, errors.Errorf("Unsupported ONNX data type %d", dt)
+}
+
+// DataTypeOf returns the DataType of a tensor.Dtype. Int and Uint are 64-bit DataTypes.
+func DataTypeOf(dt tensor.Dtype) (DataType, error) {
+	switch dt {
+	case tensor.Int:
+		return Int64, nil
+	case tensor.Uint:
+		return Uint64, nil
+	}
+	for k, d := range dtypes {
+		if d == dt {
+			return k, nil
+		}
+	}
+	return Undefined, errors.Errorf("The Dtype %v has no ONNX data type", dt)
+}
+
+// Tensor returns the value of a TensorProto. A TensorProto of no dimensions is a scalar tensor.
+func (t *TensorProto) Tensor() (tensor.Tensor, error) {
+	dt, err := t.DataType.Dtype()
+	if err != nil {
+		return nil, errors.Wrapf(err, "Tensor %q", t.Name)
+	}
+	shape := make(tensor.Shape, len(t.Dims))
+	size := 1
+	for i, d := range t.Dims {
+		shape[i] = int(d)
+		size *= int(d)
+	}
+	if size == 0 {
+		return nil, errors.Errorf("Tensor %q of shape %v has no elements, which is not supported", t.Name, shape)
+	}
+
+	backing := reflect.MakeSlice(reflect.SliceOf(dt.Type), size, size)
+	switch {
+	case len(t.RawData) > 0:
+		if err = binary.Read(bytes.NewReader(t.RawData), binary.LittleEndian, backing.Interface()); err != nil {
+			return nil, errors.Wrapf(err, "Tensor %q: cannot read %d elements of %v from %d bytes", t.Name, size, dt, len(t.RawData))
+		}
+	default:
+		// the typed fields, as described in onnx.proto
+		var src reflect.Value
+		switch t.DataType {
+		case Float:
+			src = reflect.ValueOf(t.FloatData)
+		case Complex64:
+			c := make([]complex64, len(t.FloatData)/2)
+			for i := range c {
+				c[i] = complex(t.FloatData[2*i], t.FloatData[2*i+1])
+			}
+			src = reflect.ValueOf(c)
+		case Double:
+			src = reflect.ValueOf(t.DoubleData)
+		case Complex128:
+			c := make([]complex128, len(t.DoubleData)/2)
+			for i := range c {
+				c[i] = complex(t.DoubleData[2*i], t.DoubleData[2*i+1])
+			}
+			src = reflect.ValueOf(c)
+		case Int64:
+			src = reflect.ValueOf(t.Int64Data)
+		case Uint32, Uint64:
+			src = reflect.ValueOf(t.Uint64Data)
+		default:
+			src = reflect.ValueOf(t.Int32Data)
+		}
+		if src.Len() != size {
+			return nil, errors.Errorf("Tensor %q of shape %v has %d elements", t.Name, shape, src.Len())
+		}
+		for i := 0; i < size; i++ {
+			e := src.Index(i)
+			if dt == tensor.Bool {
+				backing.Index(i).SetBool(e.Int() != 0)
+				continue
+			}
+			backing.Index(i).Set(e.Convert(dt.Type))
+		}
+	}
+
+	if len(shape) == 0 {
+		return tensor.New(tensor.FromScalar(backing.Index(0).Interface())), nil
+	}
+	return tensor.New(tensor.WithShape(shape...), tensor.WithBacking(backing.Interface())), nil
+}
+
+// NewTensorProto returns a TensorProto of the given name and value. The elements are in RawData.
+func NewTensorProto(name string, t tensor.Tensor) (*TensorProto, error) {
+	dt, err := DataTypeOf(t.Dtype())
+	if err != nil {
+		return nil, err
+	}
+	if t.RequiresIterator() {
+		t = tensor.Materialize(t)
+	}
+	data := reflect.ValueOf(t.Data())
+	if data.Kind() != reflect.Slice {
+		data = reflect.Append(reflect.MakeSlice(reflect.SliceOf(data.Type()), 0, 1), data)
+	}
+	switch data.Interface().(type) {
+	case []int:
+		s := make([]int64, data.Len())
+		for i := range s {
+			s[i] = data.Index(i).Int()
+		}
+		data = reflect.ValueOf(s)
+	case []uint:
+		s := make([]uint64, data.Len())
+		for i := range s {
+			s[i] = data.Index(i).Uint()
+		}
+		data = reflect.ValueOf(s)
+	}
+
+	var buf bytes.Buffer
+	if err = binary.Write(&buf, binary.LittleEndian, data.Interface()); err != nil {
+		return nil, errors.Wrapf(err, "Cannot encode a tensor of %v", t.Dtype())
+	}
+	retVal := &TensorProto{Name: name, DataType: dt, RawData: buf.Bytes()}
+	if !t.Shape().IsScalar() {
+		for _, d := range t.Shape() {
+			retVal.Dims = append(retVal.Dims, int64(d))
+		}
+	}
+	return retVal, nil
+}
@@ -0,0 +1,91 @@
+package onnx
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorgonia.org/tensor"
+)
+
+func TestTensorProto_Tensor(t *testing.T) {
+	assert := assert.New(t)
+	cases := []struct {
+		name string
+		tp   *TensorProto
+		want tensor.Tensor
+	}{
+		{"float", &TensorProto{Dims: []int64{2, 2}, DataType: Float, FloatData: []float32{1, 2, 3, 4}},
+			tensor.New(tensor.WithShape(2, 2), tensor.WithBacking([]float32{1, 2, 3, 4}))},
+		{"raw", &TensorProto{Dims: []int64{2}, DataType: Int16, RawData: []byte{1, 0, 0xff, 0xff}},
+			tensor.New(tensor.WithShape(2), tensor.WithBacking([]int16{1, -1}))},
+		{"int32 field of uint8", &TensorProto{Dims: []int64{3}, DataType: Uint8, Int32Data: []int32{0, 7, 255}},
+			tensor.New(tensor.WithShape(3), tensor.WithBacking([]uint8{0, 7, 255}))},
+		{"bool", &TensorProto{Dims: []int64{2}, DataType: Bool, Int32Data: []int32{1, 0}},
+			tensor.New(tensor.WithShape(2), tensor.WithBacking([]bool{true, false}))},
+		{"uint32", &TensorProto{Dims: []int64{1}, DataType: Uint32, Uint64Data: []uint64{42}},
+			tensor.New(tensor.WithShape(1), tensor.WithBacking([]uint32{42}))},
+		{"complex64", &TensorProto{Dims: []int64{1}, DataType: Complex64, FloatData: []float32{1, -2}},
+			tensor.New(tensor.WithShape(1), tensor.WithBacking([]complex64{complex(1, -2)}))},
+		{"scalar", &TensorProto{DataType: Double, DoubleData: []float64{2.5}},
+			tensor.New(tensor.FromScalar(2.5))},
+	}
+	for _, c := range cases {
+		got, err := c.tp.Tensor()
+		if !assert.NoError(err, c.name) {
+			continue
+		}
+		assert.True(c.want.Shape().Eq(got.Shape()), "%v: shape %v", c.name, got.Shape())
+		assert.Equal(c.want.Dtype(), got.Dtype(), c.name)
+		assert.Equal(c.want.Data(), got.Data(), c.name)
+	}
+
+	for _, tp := range []*TensorProto{
+		{Dims: []int64{2}, DataType: Float, FloatData: []float32{1}},
+		{Dims: []int64{2}, DataType: Float, RawData: []byte{1, 2, 3}},
+		{Dims: []int64{1}, DataType: String},
+		{Dims: []int64{1}, DataType: Float16, Int32Data: []int32{1}},
+		{Dims: []int64{0, 3}, DataType: Int64},
+	} {
+		_, err := tp.Tensor()
+		assert.Error(err, "%v", tp)
+	}
+}
+
+func TestNewTensorProto(t *testing.T) {
+	assert := assert.New(t)
+	for _, v := range []tensor.Tensor{
+		tensor.New(tensor.WithShape(2, 3), tensor.WithBacking([]float64{1, 2, 3, 4, 5, 6})),
+		tensor.New(tensor.WithShape(2), tensor.WithBacking([]bool{true, false})),
+		tensor.New(tensor.FromScalar(float32(3))),
+	} {
+		tp, err := NewTensorProto("v", v)
+		if !assert.NoError(err) {
+			continue
+		}
+		got, err := tp.Tensor()
+		if !assert.NoError(err) {
+			continue
+		}
+		assert.True(v.Shape().Eq(got.Shape()))
+		assert.Equal(v.Data(), got.Data())
+	}
+
+	// Int is a 64-bit integer, and the views are materialized
+	v := tensor.New(tensor.WithShape(2, 2), tensor.WithBacking([]int{1, 2, 3, 4}))
+	if err := v.T(); err != nil {
+		t.Fatal(err)
+	}
+	tp, err := NewTensorProto("v", v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(Int64, tp.DataType)
+	got, err := tp.Tensor()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]int64{1, 3, 2, 4}, got.Data())
+
+	_, err = NewTensorProto("s", tensor.New(tensor.WithShape(1), tensor.WithBacking([]string{"a"})))
+	assert.Error(err)
+}
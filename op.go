@@ -103,6 +103,14 @@ type NoRetOp interface {
 	ReturnsNothing() bool
 }
 
+// A StatefulOp is an Op whose result does not only depend on its inputs: for instance an op that draws random numbers,
+// or that keeps state across runs. A *tapeMachine executing incrementally always recomputes stateful ops.
+type StatefulOp interface {
+	Op
+
+	IsStateful() bool
+}
+
 // An ADOp is an Op that supports automatic differentiation.
 type ADOp interface {
 	Op
@@ -144,6 +152,33 @@ type UnsafeDoer interface {
 	UnsafeDo(inputs ...Value) (Value, error)
 }
 
+// UnsafeDoInto executes the op with the given inputs, writing the result into dst.
+// If the op is a UsePreallocDoer, the result is computed directly in dst: such are the elementwise unary and binary
+// ops, the linear algebra ops and the generated integer ops (bitwise, saturating and wrapping arithmetic). Otherwise,
+// as for the reductions, the op is executed as per usual and the result is copied into dst.
+//
+// UNSAFE: whatever was in dst is clobbered. dst must be of the correct Dtype and Shape.
+func UnsafeDoInto(op Op, dst Value, inputs ...Value) (retVal Value, err error) {
+	if dst == nil {
+		return nil, errors.Errorf("Cannot UnsafeDoInto a nil Value. Op: %v", op)
+	}
+
+	if pd, ok := op.(UsePreallocDoer); ok {
+		if retVal, err = pd.UsePreallocDo(dst, inputs...); err != nil {
+			return nil, errors.Wrapf(err, doFail, op)
+		}
+		return
+	}
+
+	if retVal, err = op.Do(inputs...); err != nil {
+		return nil, errors.Wrap(err, opDoFail)
+	}
+	if retVal == dst {
+		return
+	}
+	return Copy(dst, retVal)
+}
+
 // CUDADoer uses CUDA to perform the Op.
 type CUDADoer interface {
 	CUDADo(extern External, dev Device, prealloc Value, inputs ...Value) (retVal Value, err error)
@@ -164,6 +199,12 @@ type CUDAADOp interface {
 func ApplyOp(op Op, children ...*Node) (retVal *Node, err error) {
 	var g *ExprGraph
 
+	for _, child := range children {
+		if err = child.Err(); err != nil {
+			return nil, err
+		}
+	}
+
 	for _, child := range children {
 		if child.g != nil {
 			g = child.g
@@ -185,7 +226,8 @@ func ApplyOp(op Op, children ...*Node) (retVal *Node, err error) {
 	defer leaveLogScope()
 	var retType hm.Type
 	if retType, err = inferNodeType(op, children...); err != nil {
-		return nil, errors.Wrapf(err, "Type inference error. Op: %v. Children: %#Y, OpType:%v", op, Nodes(children), op.Type())
+		err = errors.Wrapf(err, "Type inference error. Op: %v. Children: %#Y, OpType:%v", op, Nodes(children), op.Type())
+		return nil, g.applyStack(err)
 	}
 	typeSysLogf("Done inferring. Return type is: %#v(%T)", retType, retType)
 
@@ -200,18 +242,21 @@ func ApplyOp(op Op, children ...*Node) (retVal *Node, err error) {
 	if s, err = op.InferShape(ds...); err == nil {
 		shapeLogf("inferred shape %v", s)
 		retVal = NewUniqueNode(WithType(retType), WithOp(op), WithChildren(children), In(g), WithShape(s...))
+		if retVal.layout == NCHW {
+			retVal.layout = inferLayout(op, children, s)
+		}
 	} else {
-		err = errors.Wrapf(err, "Failed to infer shape. Op: %v", op)
+		err = g.applyStack(errors.Wrapf(err, "Failed to infer shape. Op: %v", op))
 		// retVal = newUniqueNode(withType(retType), withOp(op), withChildren(children), withGraph(g))
 	}
 	returnDimSizers(ds)
 	return
 }
 
-// ApplyOpWithName applies the op, and then gives the node the given name
+// ApplyOpWithName applies the op, and then gives the node the given name, prefixed with the graph's current scope
 func ApplyOpWithName(op Op, name string, children ...*Node) (retVal *Node, err error) {
 	if retVal, err = ApplyOp(op, children...); err == nil {
-		WithName(name)(retVal)
+		WithName(retVal.g.scopedName(name))(retVal)
 	} else {
 		return nil, errors.Wrap(err, applyOpFail)
 	}
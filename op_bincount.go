@@ -0,0 +1,276 @@
+package gorgonia
+
+/*
+This file holds the Ops that count integer values, which back most classification metrics: Bincount, and the
+confusion matrix of predicted and actual classes.
+*/
+
+import (
+	"fmt"
+	"hash"
+	"reflect"
+
+	"github.com/chewxy/hm"
+	"github.com/pkg/errors"
+	"gorgonia.org/tensor"
+)
+
+// bincountOp counts the occurrences of the values of a tensor of non-negative integers, or sums the weights of the
+// occurrences if it is weighted. As the shapes are static, the result always has the given length.
+type bincountOp struct {
+	length   int
+	weighted bool
+	d        int // dims of the indices
+}
+
+func (op bincountOp) Arity() int {
+	if op.weighted {
+		return 2
+	}
+	return 1
+}
+
+// bincountOp has these types:
+//		bincountOp :: (Integers i) ⇒ Tensor-d i → Vector Int
+//		bincountOp :: (Integers i, Floats a) ⇒ Tensor-d i → Tensor-d a → Vector a
+// With a scalar index, Tensor-d i is i.
+func (op bincountOp) Type() hm.Type {
+	a := hm.TypeVariable('a')
+	var idx hm.Type = hm.TypeVariable('i')
+	var w hm.Type = a
+	if op.d > 0 {
+		idx = makeTensorType(op.d, idx)
+		w = makeTensorType(op.d, a)
+	}
+	if op.weighted {
+		return hm.NewFnType(idx, w, makeTensorType(1, a))
+	}
+	return hm.NewFnType(idx, makeTensorType(1, Int))
+}
+
+func (op bincountOp) InferShape(inputs ...DimSizer) (tensor.Shape, error) {
+	if err := checkArity(op, len(inputs)); err != nil {
+		return nil, err
+	}
+	return tensor.Shape{op.length}, nil
+}
+
+func (op bincountOp) Do(inputs ...Value) (retVal Value, err error) {
+	if err = checkArity(op, len(inputs)); err != nil {
+		return
+	}
+	var indices []int
+	if indices, err = countIndices(inputs[0], op.length); err != nil {
+		return nil, errors.Wrap(err, opDoFail)
+	}
+	if !op.weighted {
+		counts := make([]int, op.length)
+		for _, i := range indices {
+			counts[i]++
+		}
+		return tensor.New(tensor.WithBacking(counts)), nil
+	}
+
+	w := inputs[1]
+	if !w.Shape().Eq(inputs[0].Shape()) {
+		return nil, errors.Errorf("Expected weights of shape %v. Got %v instead", inputs[0].Shape(), w.Shape())
+	}
+	var weights []float64
+	if weights, err = floatData(w); err != nil {
+		return nil, errors.Wrap(err, opDoFail)
+	}
+	sums := make([]float64, op.length)
+	for k, i := range indices {
+		sums[i] += weights[k]
+	}
+	return castValue(tensor.New(tensor.WithBacking(sums)), w.Dtype(), false)
+}
+
+func (op bincountOp) ReturnsPtr() bool     { return false }
+func (op bincountOp) CallsExtern() bool    { return false }
+func (op bincountOp) OverwritesInput() int { return -1 }
+
+func (op bincountOp) WriteHash(h hash.Hash) {
+	fmt.Fprintf(h, "Bincount{%d, %t, %d}", op.length, op.weighted, op.d)
+}
+
+func (op bincountOp) Hashcode() uint32 { return simpleHash(op) }
+
+func (op bincountOp) String() string { return fmt.Sprintf("Bincount{%d}", op.length) }
+
+// DiffWRT returns true for the weights: the indices are not differentiable.
+func (op bincountOp) DiffWRT(inputs int) []bool {
+	if op.weighted {
+		return []bool{false, true}
+	}
+	return []bool{false}
+}
+
+// SymDiff is the gradient of the weights, which is the gradient of the bins they are summed into.
+func (op bincountOp) SymDiff(inputs Nodes, output, grad *Node) (retVal Nodes, err error) {
+	if !op.weighted {
+		return nil, nondiffErr(op)
+	}
+	if err = checkArity(op, len(inputs)); err != nil {
+		return
+	}
+	var ret *Node
+	if ret, err = ApplyOp(binGatherOp{length: op.length, d: op.d}, inputs[0], grad); err != nil {
+		return nil, errors.Wrap(err, operationError)
+	}
+	return Nodes{nil, ret}, nil
+}
+
+// binGatherOp reads the values of a vector at the given indices. It is the derivative of a weighted bincountOp.
+type binGatherOp struct {
+	length int
+	d      int
+}
+
+func (op binGatherOp) Arity() int { return 2 }
+
+// binGatherOp has this type:
+//		binGatherOp :: (Integers i) ⇒ Tensor-d i → Vector a → Tensor-d a
+func (op binGatherOp) Type() hm.Type {
+	a := hm.TypeVariable('a')
+	var idx hm.Type = hm.TypeVariable('i')
+	var ret hm.Type = a
+	if op.d > 0 {
+		idx = makeTensorType(op.d, idx)
+		ret = makeTensorType(op.d, a)
+	}
+	return hm.NewFnType(idx, makeTensorType(1, a), ret)
+}
+
+func (op binGatherOp) InferShape(inputs ...DimSizer) (tensor.Shape, error) {
+	if err := checkArity(op, len(inputs)); err != nil {
+		return nil, err
+	}
+	s, ok := inputs[0].(tensor.Shape)
+	if !ok {
+		return nil, errors.Errorf("Expected a shape. Got %v of %T instead", inputs[0], inputs[0])
+	}
+	return s.Clone(), nil
+}
+
+func (op binGatherOp) Do(inputs ...Value) (retVal Value, err error) {
+	if err = checkArity(op, len(inputs)); err != nil {
+		return
+	}
+	var indices []int
+	if indices, err = countIndices(inputs[0], op.length); err != nil {
+		return nil, errors.Wrap(err, opDoFail)
+	}
+	var src []float64
+	if src, err = floatData(inputs[1]); err != nil {
+		return nil, errors.Wrap(err, opDoFail)
+	}
+	ret := make([]float64, len(indices))
+	for k, i := range indices {
+		ret[k] = src[i]
+	}
+	if op.d == 0 {
+		return castValue(newF64(ret[0]), inputs[1].Dtype(), false)
+	}
+	return castValue(tensor.New(tensor.WithShape(inputs[0].Shape().Clone()...), tensor.WithBacking(ret)), inputs[1].Dtype(), false)
+}
+
+func (op binGatherOp) ReturnsPtr() bool     { return false }
+func (op binGatherOp) CallsExtern() bool    { return false }
+func (op binGatherOp) OverwritesInput() int { return -1 }
+
+func (op binGatherOp) WriteHash(h hash.Hash) { fmt.Fprintf(h, "binGather{%d, %d}", op.length, op.d) }
+
+func (op binGatherOp) Hashcode() uint32 { return simpleHash(op) }
+
+func (op binGatherOp) String() string { return fmt.Sprintf("Bincount{%d}'", op.length) }
+
+func (op binGatherOp) DiffWRT(inputs int) []bool { return []bool{false, false} }
+
+func (op binGatherOp) SymDiff(inputs Nodes, output, grad *Node) (retVal Nodes, err error) {
+	return nil, nondiffErr(op)
+}
+
+// confusionMatrixOp counts the pairs of actual and predicted classes: the element (i, j) of the result is the number of
+// values of class i that were predicted to be of class j.
+type confusionMatrixOp struct {
+	classes int
+	d       int
+}
+
+func (op confusionMatrixOp) Arity() int { return 2 }
+
+// confusionMatrixOp has this type:
+//		confusionMatrixOp :: (Integers i) ⇒ Tensor-d i → Tensor-d i → Matrix Int
+func (op confusionMatrixOp) Type() hm.Type {
+	var t hm.Type = hm.TypeVariable('i')
+	if op.d > 0 {
+		t = makeTensorType(op.d, t)
+	}
+	return hm.NewFnType(t, t, makeTensorType(2, Int))
+}
+
+func (op confusionMatrixOp) InferShape(inputs ...DimSizer) (tensor.Shape, error) {
+	if err := checkArity(op, len(inputs)); err != nil {
+		return nil, err
+	}
+	return tensor.Shape{op.classes, op.classes}, nil
+}
+
+func (op confusionMatrixOp) Do(inputs ...Value) (retVal Value, err error) {
+	if err = checkArity(op, len(inputs)); err != nil {
+		return
+	}
+	if !inputs[0].Shape().Eq(inputs[1].Shape()) {
+		return nil, errors.Errorf("Expected predicted and actual classes of the same shape. Got %v and %v instead", inputs[0].Shape(), inputs[1].Shape())
+	}
+	var predicted, actual []int
+	if predicted, err = countIndices(inputs[0], op.classes); err != nil {
+		return nil, errors.Wrap(err, opDoFail)
+	}
+	if actual, err = countIndices(inputs[1], op.classes); err != nil {
+		return nil, errors.Wrap(err, opDoFail)
+	}
+	counts := make([]int, op.classes*op.classes)
+	for k, p := range predicted {
+		counts[actual[k]*op.classes+p]++
+	}
+	return tensor.New(tensor.WithShape(op.classes, op.classes), tensor.WithBacking(counts)), nil
+}
+
+func (op confusionMatrixOp) ReturnsPtr() bool     { return false }
+func (op confusionMatrixOp) CallsExtern() bool    { return false }
+func (op confusionMatrixOp) OverwritesInput() int { return -1 }
+
+func (op confusionMatrixOp) WriteHash(h hash.Hash) {
+	fmt.Fprintf(h, "ConfusionMatrix{%d, %d}", op.classes, op.d)
+}
+
+func (op confusionMatrixOp) Hashcode() uint32 { return simpleHash(op) }
+
+func (op confusionMatrixOp) String() string { return fmt.Sprintf("ConfusionMatrix{%d}", op.classes) }
+
+func (op confusionMatrixOp) DiffWRT(inputs int) []bool { return []bool{false, false} }
+
+func (op confusionMatrixOp) SymDiff(inputs Nodes, output, grad *Node) (retVal Nodes, err error) {
+	return nil, nondiffErr(op)
+}
+
+// countIndices returns the values of an integer value as ints, checking that they are in [0, length).
+func countIndices(v Value, length int) ([]int, error) {
+	sv, err := valueSlice(v)
+	if err != nil {
+		return nil, err
+	}
+	data, err := castData(sv.Interface(), reflect.TypeOf(int(0)), false)
+	if err != nil {
+		return nil, err
+	}
+	indices := data.([]int)
+	for _, i := range indices {
+		if i < 0 || i >= length {
+			return nil, errors.Errorf("Expected indices in [0, %d). Got %d", length, i)
+		}
+	}
+	return indices, nil
+}
@@ -0,0 +1,86 @@
+package gorgonia
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorgonia.org/tensor"
+)
+
+func TestBincount(t *testing.T) {
+	assert := assert.New(t)
+	g := NewGraph()
+	idx := NewVector(g, Int, WithShape(5), WithName("idx"))
+	w := NewVector(g, Float64, WithShape(5), WithName("w"))
+	counts, err := Bincount(idx, nil, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(tensor.Shape{4}, counts.Shape())
+	assert.Equal(Int, counts.Dtype())
+	sums, err := Bincount(idx, w, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(Float64, sums.Dtype())
+
+	// the gradient of the weights is the gradient of their bins
+	scale := NewConstant(tensor.New(tensor.WithBacking([]float64{1, 2, 3, 4})))
+	cost := Must(Sum(Must(HadamardProd(sums, scale))))
+	grads, err := Grad(cost, w)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	Let(idx, tensor.New(tensor.WithBacking([]int{0, 2, 2, 3, 0})))
+	Let(w, tensor.New(tensor.WithBacking([]float64{0.5, 1, 2, 3, 0.25})))
+	m := NewTapeMachine(g)
+	defer m.Close()
+	if err = m.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]int{2, 0, 2, 1}, counts.Value().Data())
+	assert.Equal([]float64{0.75, 0, 3, 3}, sums.Value().Data())
+	assert.Equal([]float64{1, 3, 3, 4, 1}, grads[0].Value().Data())
+
+	// the indices must fit in the bins
+	m.Reset()
+	Let(idx, tensor.New(tensor.WithBacking([]int{0, 4, 2, 3, 0})))
+	assert.Error(m.RunAll())
+
+	f := NewVector(g, Float64, WithShape(5))
+	_, err = Bincount(f, nil, 4)
+	assert.Error(err)
+	_, err = Bincount(idx, NewVector(g, Float64, WithShape(4)), 4)
+	assert.Error(err)
+	_, err = Bincount(idx, nil, 0)
+	assert.Error(err)
+}
+
+func TestConfusionMatrix(t *testing.T) {
+	assert := assert.New(t)
+	g := NewGraph()
+	predicted := NewVector(g, Int32, WithShape(6), WithName("predicted"))
+	actual := NewVector(g, Int32, WithShape(6), WithName("actual"))
+	cm, err := ConfusionMatrix(predicted, actual, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(tensor.Shape{3, 3}, cm.Shape())
+
+	Let(predicted, tensor.New(tensor.WithBacking([]int32{0, 1, 2, 2, 1, 0})))
+	Let(actual, tensor.New(tensor.WithBacking([]int32{0, 1, 1, 2, 1, 2})))
+	m := NewTapeMachine(g)
+	defer m.Close()
+	if err = m.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]int{
+		1, 0, 0,
+		0, 2, 1,
+		1, 0, 1,
+	}, cm.Value().Data())
+
+	_, err = ConfusionMatrix(predicted, NewVector(g, Int32, WithShape(5)), 3)
+	assert.Error(err)
+}
@@ -0,0 +1,218 @@
+package gorgonia
+
+/*
+This file holds the Ops for bitwise operations on integers. The kernels are generated by genapi into operatorBitwise_gen.go
+*/
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"reflect"
+
+	"github.com/chewxy/hm"
+	"github.com/pkg/errors"
+	"gorgonia.org/tensor"
+)
+
+type bitwiseOpType byte
+
+const (
+	bitAndOpType bitwiseOpType = iota
+	bitOrOpType
+	bitXorOpType
+	shlOpType
+	shrOpType
+)
+
+var bitwiseOpSymbols = [...]string{
+	bitAndOpType: "&",
+	bitOrOpType:  "|",
+	bitXorOpType: "^",
+	shlOpType:    "<<",
+	shrOpType:    ">>",
+}
+
+func (o bitwiseOpType) String() string { return bitwiseOpSymbols[o] }
+
+// bitwiseOp is an elementwise bitwise operation on integers. Either operand may be a scalar.
+type bitwiseOp struct {
+	ʘ      bitwiseOpType
+	ad, bd int // dims of a and b
+}
+
+func newBitwiseOp(ot bitwiseOpType, a, b *Node) bitwiseOp {
+	return bitwiseOp{ʘ: ot, ad: a.Dims(), bd: b.Dims()}
+}
+
+func (op bitwiseOp) Arity() int { return 2 }
+
+// bitwiseOp has either of these types:
+//		bitwiseOp :: (Integer a) ⇒ Tensor a → Tensor a → Tensor a
+//		bitwiseOp :: (Integer a) ⇒ Tensor a → a → Tensor a
+//		bitwiseOp :: (Integer a) ⇒ a → Tensor a → Tensor a
+//		bitwiseOp :: (Integer a) ⇒ a → a → a
+func (op bitwiseOp) Type() hm.Type { return integerBinOpType(op.ad, op.bd) }
+
+func (op bitwiseOp) InferShape(inputs ...DimSizer) (tensor.Shape, error) {
+	if err := checkArity(op, len(inputs)); err != nil {
+		return nil, err
+	}
+	return integerBinOpShape(inputs[0], inputs[1])
+}
+
+func (op bitwiseOp) Do(inputs ...Value) (retVal Value, err error) {
+	if err = checkArity(op, len(inputs)); err != nil {
+		return
+	}
+	return integerBinDo(inputs[0], inputs[1], nil, func(a, b, ret interface{}, as, bs int) error {
+		return bitwiseKernel(op.ʘ, a, b, ret, as, bs)
+	})
+}
+
+// fulfils UsePreallocDoer interface
+func (op bitwiseOp) UsePreallocDo(prealloc Value, inputs ...Value) (retVal Value, err error) {
+	if err = checkArity(op, len(inputs)); err != nil {
+		return
+	}
+	return integerBinDo(inputs[0], inputs[1], prealloc, func(a, b, ret interface{}, as, bs int) error {
+		return bitwiseKernel(op.ʘ, a, b, ret, as, bs)
+	})
+}
+
+func (op bitwiseOp) ReturnsPtr() bool     { return false }
+func (op bitwiseOp) CallsExtern() bool    { return false }
+func (op bitwiseOp) OverwritesInput() int { return -1 }
+
+func (op bitwiseOp) WriteHash(h hash.Hash) {
+	fmt.Fprintf(h, "bitwise%v", op.ʘ)
+	if err := binary.Write(h, binary.LittleEndian, byte(op.ad)); err != nil {
+		panic(err)
+	}
+	if err := binary.Write(h, binary.LittleEndian, byte(op.bd)); err != nil {
+		panic(err)
+	}
+}
+
+func (op bitwiseOp) Hashcode() uint32 { return simpleHash(op) }
+
+func (op bitwiseOp) String() string { return op.ʘ.String() }
+
+func (op bitwiseOp) DiffWRT(inputs int) []bool { return []bool{false, false} }
+
+func (op bitwiseOp) SymDiff(inputs Nodes, output, grad *Node) (retVal Nodes, err error) {
+	return nil, nondiffErr(op)
+}
+
+/* UTILITY FUNCTIONS */
+
+// integerBinOpType is the type of an elementwise binary op on integers whose operands have the given dims.
+func integerBinOpType(ad, bd int) hm.Type {
+	a := hm.TypeVariable('a')
+	var at, bt hm.Type = a, a
+	if ad > 0 {
+		at = makeTensorType(ad, a)
+	}
+	if bd > 0 {
+		bt = makeTensorType(bd, a)
+	}
+	rt := at
+	if ad == 0 {
+		rt = bt
+	}
+	return hm.NewFnType(at, bt, rt)
+}
+
+// integerBinOpShape is the shape of the result of an elementwise binary op on integers. Either operand may be a scalar.
+func integerBinOpShape(as, bs DimSizer) (tensor.Shape, error) {
+	a, aok := as.(tensor.Shape)
+	b, bok := bs.(tensor.Shape)
+	if !aok || !bok {
+		return nil, errors.Errorf("Expected shapes. Got %v and %v instead", as, bs)
+	}
+	switch {
+	case a.IsScalar():
+		return b.Clone(), nil
+	case b.IsScalar():
+		return a.Clone(), nil
+	case !a.Eq(b):
+		return nil, errors.Errorf("Shape mismatch: %v and %v", a, b)
+	}
+	return a.Clone(), nil
+}
+
+// integerBinDo executes the kernel of an elementwise binary op on integer values. Either value may be a scalar: the
+// kernel gets the data of the values as slices, with a stride of 0 for a scalar and 1 otherwise.
+//
+// The result is written into prealloc if it is not nil, which must then be a tensor of the shape and Dtype of the
+// result. The results of two scalars are copied into prealloc.
+func integerBinDo(a, b, prealloc Value, kernel func(a, b, retVal interface{}, as, bs int) error) (retVal Value, err error) {
+	if a.Dtype() != b.Dtype() {
+		return nil, errors.Errorf("Dtype mismatch for integer op: %v and %v", a.Dtype(), b.Dtype())
+	}
+
+	var ad, bd reflect.Value
+	if ad, err = integerSliceOf(a); err != nil {
+		return nil, errors.Wrap(err, opDoFail)
+	}
+	if bd, err = integerSliceOf(b); err != nil {
+		return nil, errors.Wrap(err, opDoFail)
+	}
+
+	shape := a.Shape()
+	size := ad.Len()
+	as, bs := 1, 1
+	switch {
+	case ad.Len() == 1 && bd.Len() == 1:
+		as, bs = 0, 0
+		if shape.IsScalar() {
+			shape = b.Shape()
+		}
+	case ad.Len() == 1:
+		as = 0
+		size = bd.Len()
+		shape = b.Shape()
+	case bd.Len() == 1:
+		bs = 0
+	case ad.Len() != bd.Len():
+		return nil, errors.Errorf("Shape mismatch: %v and %v", a.Shape(), b.Shape())
+	}
+
+	scalar := a.Shape().IsScalar() && b.Shape().IsScalar()
+	var ret reflect.Value
+	if dst, ok := prealloc.(tensor.Tensor); ok && !scalar {
+		if dst.Dtype() != a.Dtype() || !dst.Shape().Eq(shape) {
+			return nil, errors.Errorf("Cannot write the result of %v of shape %v into a value of %v of shape %v", a.Dtype(), shape, dst.Dtype(), dst.Shape())
+		}
+		if ret, err = valueSlice(dst); err != nil {
+			return nil, errors.Wrap(err, opDoFail)
+		}
+	} else {
+		ret = reflect.MakeSlice(ad.Type(), size, size)
+	}
+	if err = kernel(ad.Interface(), bd.Interface(), ret.Interface(), as, bs); err != nil {
+		return nil, errors.Wrap(err, opDoFail)
+	}
+
+	switch {
+	case scalar:
+		if retVal, _, _, err = anyToValue(ret.Index(0).Interface()); err != nil {
+			return nil, errors.Wrapf(err, anyToValueFail, ret.Index(0).Interface(), ret.Index(0).Interface())
+		}
+		if prealloc != nil {
+			return Copy(prealloc, retVal)
+		}
+		return
+	case prealloc != nil:
+		return prealloc, nil
+	}
+	return tensor.New(tensor.WithShape(shape.Clone()...), tensor.WithBacking(ret.Interface())), nil
+}
+
+// integerSliceOf returns the data of an integer Value as a slice. See valueSlice.
+func integerSliceOf(v Value) (retVal reflect.Value, err error) {
+	if c := categoryOf(v.Dtype()); c != intCategory && c != uintCategory {
+		return retVal, errors.Errorf("Expected an integer Value. Got %v instead", v.Dtype())
+	}
+	return valueSlice(v)
+}
@@ -0,0 +1,75 @@
+package gorgonia
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorgonia.org/tensor"
+)
+
+var bitwiseOpTests = []struct {
+	ot      bitwiseOpType
+	a, b    Value
+	correct interface{}
+}{
+	{bitAndOpType, tensor.New(tensor.WithBacking([]int{12, 10})), tensor.New(tensor.WithBacking([]int{10, 6})), []int{8, 2}},
+	{bitOrOpType, tensor.New(tensor.WithBacking([]int32{12, 10})), tensor.New(tensor.WithBacking([]int32{10, 6})), []int32{14, 14}},
+	{bitXorOpType, tensor.New(tensor.WithBacking([]uint8{12, 255})), tensor.New(tensor.WithBacking([]uint8{10, 1})), []uint8{6, 254}},
+	{shlOpType, tensor.New(tensor.WithBacking([]int64{1, 3})), tensor.New(tensor.WithBacking([]int64{4, 1})), []int64{16, 6}},
+	{shrOpType, tensor.New(tensor.WithBacking([]int8{-16, 16})), tensor.New(tensor.WithBacking([]int8{2, 2})), []int8{-4, 4}},
+	{shrOpType, tensor.New(tensor.WithBacking([]uint16{0xff00, 16})), newU8(0), nil}, // dtype mismatch
+
+	// scalars
+	{bitAndOpType, tensor.New(tensor.WithBacking([]uint32{0xff, 0x0f})), tensor.New(tensor.WithBacking([]uint32{0x3c})), []uint32{0x3c, 0x0c}},
+	{shlOpType, newI(1), tensor.New(tensor.WithBacking([]int{0, 1, 2})), []int{1, 2, 4}},
+	{bitXorOpType, newI64(5), newI64(3), int64(6)},
+}
+
+func TestBitwiseOpDo(t *testing.T) {
+	assert := assert.New(t)
+	for i, bot := range bitwiseOpTests {
+		op := bitwiseOp{ʘ: bot.ot, ad: bot.a.Shape().Dims(), bd: bot.b.Shape().Dims()}
+		ret, err := op.Do(bot.a, bot.b)
+		if bot.correct == nil {
+			assert.Error(err, "Test %d", i)
+			continue
+		}
+		if assert.NoError(err, "Test %d", i) {
+			assert.Equal(bot.correct, ret.Data(), "Test %d", i)
+		}
+	}
+}
+
+func TestBitwiseOps(t *testing.T) {
+	assert := assert.New(t)
+	g := NewGraph()
+	a := NewVector(g, Int, WithShape(3), WithName("a"), WithValue(tensor.New(tensor.WithBacking([]int{1, 2, 3}))))
+	b := NewVector(g, Int, WithShape(3), WithName("b"), WithValue(tensor.New(tensor.WithBacking([]int{3, 3, 1}))))
+	s := NewScalar(g, Int, WithName("s"), WithValue(1))
+
+	and := Must(BitwiseAnd(a, b))
+	or := Must(BitwiseOr(a, b))
+	xor := Must(BitwiseXor(a, b))
+	shl := Must(ShiftLeft(a, s))
+	shr := Must(ShiftRight(b, s))
+	assert.Equal(tensor.Shape{3}, shl.Shape())
+
+	m := NewTapeMachine(g)
+	defer m.Close()
+	if err := m.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]int{1, 2, 1}, and.Value().Data())
+	assert.Equal([]int{3, 3, 3}, or.Value().Data())
+	assert.Equal([]int{2, 1, 2}, xor.Value().Data())
+	assert.Equal([]int{2, 4, 6}, shl.Value().Data())
+	assert.Equal([]int{1, 1, 0}, shr.Value().Data())
+
+	// errors
+	f := NewVector(g, Float64, WithShape(3), WithName("f"))
+	_, err := BitwiseAnd(a, f)
+	assert.Error(err)
+	i64 := NewVector(g, Int64, WithShape(3), WithName("i64"))
+	_, err = BitwiseOr(a, i64)
+	assert.Error(err)
+}
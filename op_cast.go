@@ -0,0 +1,258 @@
+package gorgonia
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"math"
+	"reflect"
+
+	"github.com/chewxy/hm"
+	"github.com/pkg/errors"
+	"gorgonia.org/tensor"
+)
+
+// castOp converts a value from one Dtype to another. Conversions follow Go's conversion rules, unless saturate is set,
+// in which case values that are out of the range of an integer target are clamped to the range.
+type castOp struct {
+	from, to tensor.Dtype
+	d        int
+	saturate bool
+}
+
+func newCastOp(a *Node, to tensor.Dtype) castOp {
+	return castOp{
+		from: a.Dtype(),
+		to:   to,
+		d:    a.Dims(),
+	}
+}
+
+// differentiable returns true if the gradient can be passed straight through the cast.
+func (op castOp) differentiable() bool {
+	return categoryOf(op.from) == floatCategory && categoryOf(op.to) == floatCategory
+}
+
+func (op castOp) Arity() int { return 1 }
+
+// castOp is a function with this type:
+//		castOp :: Tensor-d a → Tensor-d b
+//		castOp :: a → b
+func (op castOp) Type() hm.Type {
+	if op.d == 0 {
+		return hm.NewFnType(op.from, op.to)
+	}
+	return hm.NewFnType(makeTensorType(op.d, op.from), makeTensorType(op.d, op.to))
+}
+
+func (op castOp) InferShape(inputs ...DimSizer) (tensor.Shape, error) {
+	if err := checkArity(op, len(inputs)); err != nil {
+		return nil, err
+	}
+	s, ok := inputs[0].(tensor.Shape)
+	if !ok {
+		return nil, errors.Errorf("Expected a shape. Got %v of %T instead", inputs[0], inputs[0])
+	}
+	return s.Clone(), nil
+}
+
+func (op castOp) Do(inputs ...Value) (retVal Value, err error) {
+	if err = checkArity(op, len(inputs)); err != nil {
+		return
+	}
+	return castValue(inputs[0], op.to, op.saturate)
+}
+
+func (op castOp) ReturnsPtr() bool     { return false }
+func (op castOp) CallsExtern() bool    { return false }
+func (op castOp) OverwritesInput() int { return -1 }
+
+func (op castOp) WriteHash(h hash.Hash) {
+	fmt.Fprintf(h, "cast(%v→%v)", op.from, op.to)
+	if op.saturate {
+		h.Write([]byte("saturate"))
+	}
+	if err := binary.Write(h, binary.LittleEndian, byte(op.d)); err != nil {
+		panic(err)
+	}
+}
+
+func (op castOp) Hashcode() uint32 { return simpleHash(op) }
+
+func (op castOp) String() string {
+	if op.saturate {
+		return fmt.Sprintf("SaturatingCast(%v→%v)", op.from, op.to)
+	}
+	return fmt.Sprintf("Cast(%v→%v)", op.from, op.to)
+}
+
+// DiffWRT returns true only for float to float casts. The gradient of those is passed straight through.
+func (op castOp) DiffWRT(inputs int) []bool { return []bool{op.differentiable()} }
+
+func (op castOp) SymDiff(inputs Nodes, output, grad *Node) (retVal Nodes, err error) {
+	if err = checkArity(op, len(inputs)); err != nil {
+		return
+	}
+	if !op.differentiable() {
+		return nil, nondiffErr(op)
+	}
+
+	var ret *Node
+	if ret, err = castTo(grad, op.from); err != nil {
+		return nil, errors.Wrap(err, "Unable to cast gradient")
+	}
+	return Nodes{ret}, nil
+}
+
+func (op castOp) DoDiff(ctx ExecutionContext, inputs Nodes, output *Node) (err error) {
+	if err = checkArity(op, len(inputs)); err != nil {
+		return
+	}
+	if !op.differentiable() {
+		return nondiffErr(op)
+	}
+
+	xdv, ydv := getDV(inputs[0], output)
+	var d Value
+	if d, err = castValue(ydv.d, op.from, false); err != nil {
+		return errors.Wrap(err, "Unable to cast gradient")
+	}
+
+	add := newEBOByType(addOpType, TypeOf(xdv.d), TypeOf(d))
+	if d, err = add.UnsafeDo(xdv.d, d); err != nil {
+		return errors.Wrapf(err, unsafeDoFail, add)
+	}
+
+	if !add.ReturnsPtr() || inputs[0].IsScalar() {
+		err = xdv.SetDeriv(d)
+	}
+	return
+}
+
+// castValue converts v into a new Value of the given Dtype. If saturate is true, values that do not fit in an integer Dtype are clamped.
+func castValue(v Value, to tensor.Dtype, saturate bool) (retVal Value, err error) {
+	switch vt := v.(type) {
+	case Scalar:
+		var conv reflect.Value
+		if conv, err = convertElem(reflect.ValueOf(vt.Data()), to.Type, saturate); err != nil {
+			return
+		}
+		if retVal, _, _, err = anyToValue(conv.Interface()); err != nil {
+			return nil, errors.Wrapf(err, "Cannot cast scalar %v to %v", v, to)
+		}
+		return
+	case tensor.Tensor:
+		if vt.Dtype() == to {
+			return CloneValue(v)
+		}
+		if vt.RequiresIterator() {
+			vt = tensor.Materialize(vt)
+		}
+		var data interface{}
+		if data, err = castData(vt.Data(), to.Type, saturate); err != nil {
+			return nil, err
+		}
+		return tensor.New(tensor.WithShape(vt.Shape().Clone()...), tensor.WithBacking(data)), nil
+	}
+	return nil, errors.Errorf(nyiTypeFail, "castValue", v)
+}
+
+// castData converts each element of the data of a tensor into a new slice of the given type.
+func castData(data interface{}, to reflect.Type, saturate bool) (interface{}, error) {
+	s := reflect.ValueOf(data)
+	if s.Kind() != reflect.Slice {
+		// single element tensors may return the element itself
+		s = reflect.Append(reflect.MakeSlice(reflect.SliceOf(s.Type()), 0, 1), s)
+	}
+
+	d := reflect.MakeSlice(reflect.SliceOf(to), s.Len(), s.Len())
+	for i := 0; i < s.Len(); i++ {
+		conv, err := convertElem(s.Index(i), to, saturate)
+		if err != nil {
+			return nil, err
+		}
+		d.Index(i).Set(conv)
+	}
+	return d.Interface(), nil
+}
+
+// convertElem converts a single element to the given type. Bools are treated as 0 and 1, and complex numbers
+// lose their imaginary part when converted to a real type.
+func convertElem(v reflect.Value, to reflect.Type, saturate bool) (reflect.Value, error) {
+	from := categoryOf(tensor.Dtype{Type: v.Type()})
+	target := categoryOf(tensor.Dtype{Type: to})
+	if from == notPromotable || target == notPromotable {
+		return reflect.Value{}, errors.Errorf("Cannot convert %v to %v", v.Type(), to)
+	}
+
+	if saturate && (target == intCategory || target == uintCategory) && from != boolCategory {
+		if from == complexCategory {
+			v = reflect.ValueOf(real(v.Complex()))
+		}
+		return saturateElem(v, to), nil
+	}
+
+	switch {
+	case from == target || (from != boolCategory && target != boolCategory && from != complexCategory && target != complexCategory):
+		return v.Convert(to), nil
+	case target == boolCategory:
+		return reflect.ValueOf(!v.IsZero()), nil
+	case from == boolCategory:
+		var one int
+		if v.Bool() {
+			one = 1
+		}
+		return convertElem(reflect.ValueOf(one), to, false)
+	case target == complexCategory:
+		// from a real type
+		f := v.Convert(reflect.TypeOf(float64(0)))
+		return reflect.ValueOf(complex(f.Float(), 0)).Convert(to), nil
+	default:
+		// from complex to a real type
+		return convertElem(reflect.ValueOf(real(v.Complex())), to, false)
+	}
+}
+
+// saturateElem converts an int, uint or float value to the integer type `to`, clamping values that are out of range.
+// NaNs are converted to 0.
+func saturateElem(v reflect.Value, to reflect.Type) reflect.Value {
+	bits := uint(to.Bits())
+	signed := categoryOf(tensor.Dtype{Type: to}) == intCategory
+	maxU := ^uint64(0) >> (64 - bits)
+	var minI, maxI int64
+	if signed {
+		maxI = int64(maxU >> 1)
+		minI = -maxI - 1
+		maxU = uint64(maxI)
+	}
+
+	switch categoryOf(tensor.Dtype{Type: v.Type()}) {
+	case floatCategory:
+		f := v.Float()
+		switch {
+		case math.IsNaN(f):
+			return reflect.Zero(to)
+		case signed && f <= float64(minI):
+			return reflect.ValueOf(minI).Convert(to)
+		case !signed && f <= 0:
+			return reflect.Zero(to)
+		case f >= float64(maxU):
+			return reflect.ValueOf(maxU).Convert(to)
+		}
+	case intCategory:
+		i := v.Int()
+		switch {
+		case signed && i < minI:
+			return reflect.ValueOf(minI).Convert(to)
+		case !signed && i < 0:
+			return reflect.Zero(to)
+		case i > 0 && uint64(i) > maxU:
+			return reflect.ValueOf(maxU).Convert(to)
+		}
+	case uintCategory:
+		if v.Uint() > maxU {
+			return reflect.ValueOf(maxU).Convert(to)
+		}
+	}
+	return v.Convert(to)
+}
@@ -0,0 +1,195 @@
+package gorgonia
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorgonia.org/tensor"
+)
+
+var castValueTests = []struct {
+	v       Value
+	to      tensor.Dtype
+	correct interface{}
+}{
+	{tensor.New(tensor.WithBacking([]float64{1.5, -2.5, 3})), Float32, []float32{1.5, -2.5, 3}},
+	{tensor.New(tensor.WithBacking([]float32{1.5, -2.5, 3})), Int, []int{1, -2, 3}},
+	{tensor.New(tensor.WithBacking([]int{0, 1, 2})), Bool, []bool{false, true, true}},
+	{tensor.New(tensor.WithBacking([]bool{false, true})), Float64, []float64{0, 1}},
+	{tensor.New(tensor.WithBacking([]float64{1, 2})), tensor.Complex128, []complex128{1, 2}},
+	{tensor.New(tensor.WithBacking([]complex64{1 + 2i, 3 - 1i})), Float32, []float32{1, 3}},
+	{newF64(3.5), Float32, float32(3.5)},
+	{newI(3), Float64, float64(3)},
+	{newB(true), Int, 1},
+}
+
+func TestCastValue(t *testing.T) {
+	assert := assert.New(t)
+	for i, cvt := range castValueTests {
+		v, err := castValue(cvt.v, cvt.to, false)
+		if !assert.NoError(err, "Test %d", i) {
+			continue
+		}
+		assert.Equal(cvt.to, v.Dtype(), "Test %d", i)
+		assert.Equal(cvt.correct, v.Data(), "Test %d", i)
+	}
+
+	// views are materialized
+	T := tensor.New(tensor.WithShape(2, 2), tensor.WithBacking([]float64{1, 2, 3, 4}))
+	T.T()
+	v, err := castValue(T, Float32, false)
+	if assert.NoError(err) {
+		assert.Equal([]float32{1, 3, 2, 4}, v.Data())
+		assert.Equal(tensor.Shape{2, 2}, v.Shape())
+	}
+
+	// unsupported
+	_, err = castValue(tensor.New(tensor.WithBacking([]string{"a"})), Float64, false)
+	assert.Error(err)
+}
+
+func TestCastOp(t *testing.T) {
+	assert := assert.New(t)
+	g := NewGraph()
+	x := NewMatrix(g, Float64, WithShape(2, 3), WithName("x"), WithInit(RangedFrom(0)))
+	y, err := castTo(x, Float32)
+	if !assert.NoError(err) {
+		t.FailNow()
+	}
+	assert.Equal(Float32, y.Dtype())
+	assert.Equal(tensor.Shape{2, 3}, y.Shape())
+
+	// no op if the dtype is the same
+	z, err := castTo(y, Float32)
+	assert.NoError(err)
+	assert.Equal(y, z)
+
+	m := NewTapeMachine(g)
+	defer m.Close()
+	if err = m.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]float32{0, 1, 2, 3, 4, 5}, y.Value().Data())
+}
+
+var saturatingCastTests = []struct {
+	v       Value
+	to      tensor.Dtype
+	correct interface{}
+}{
+	{tensor.New(tensor.WithBacking([]float64{-300, -1.5, 0, 1.5, 300, math.NaN(), math.Inf(1)})), tensor.Int8, []int8{-128, -1, 0, 1, 127, 0, 127}},
+	{tensor.New(tensor.WithBacking([]float64{-300, 1.5, 300, math.Inf(-1)})), tensor.Uint8, []uint8{0, 1, 255, 0}},
+	{tensor.New(tensor.WithBacking([]float64{1e20, -1e20})), tensor.Int64, []int64{math.MaxInt64, math.MinInt64}},
+	{tensor.New(tensor.WithBacking([]int{-1, 70000, 5})), tensor.Uint16, []uint16{0, math.MaxUint16, 5}},
+	{tensor.New(tensor.WithBacking([]int64{math.MinInt64, 0, math.MaxInt64})), tensor.Int32, []int32{math.MinInt32, 0, math.MaxInt32}},
+	{tensor.New(tensor.WithBacking([]uint64{math.MaxUint64, 3})), tensor.Int64, []int64{math.MaxInt64, 3}},
+	{tensor.New(tensor.WithBacking([]uint64{math.MaxUint64, 3})), tensor.Uint, []uint{math.MaxUint64, 3}},
+	{tensor.New(tensor.WithBacking([]bool{true, false})), tensor.Int8, []int8{1, 0}},
+
+	// non integer targets are unaffected
+	{tensor.New(tensor.WithBacking([]float64{1e300, 1})), Float32, []float32{float32(math.Inf(1)), 1}},
+	{newF64(-1e10), Int32, int32(math.MinInt32)},
+}
+
+func TestSaturatingCast(t *testing.T) {
+	assert := assert.New(t)
+	for i, sct := range saturatingCastTests {
+		v, err := castValue(sct.v, sct.to, true)
+		if !assert.NoError(err, "Test %d", i) {
+			continue
+		}
+		assert.Equal(sct.correct, v.Data(), "Test %d", i)
+	}
+
+	// truncating casts wrap around
+	v, err := castValue(tensor.New(tensor.WithBacking([]int{300, -1})), tensor.Uint8, false)
+	if assert.NoError(err) {
+		assert.Equal([]uint8{44, 255}, v.Data())
+	}
+
+	g := NewGraph()
+	x := NewVector(g, Float64, WithShape(3), WithName("x"), WithValue(tensor.New(tensor.WithBacking([]float64{-1000, 1, 1000}))))
+	y, err := SaturatingCast(x, tensor.Int16)
+	if !assert.NoError(err) {
+		t.FailNow()
+	}
+	z, err := Cast(x, tensor.Int16)
+	if !assert.NoError(err) {
+		t.FailNow()
+	}
+	assert.NotEqual(y.Hashcode(), z.Hashcode())
+
+	m := NewTapeMachine(g)
+	defer m.Close()
+	if err = m.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]int16{-1000, 1, 1000}, y.Value().Data())
+}
+
+func TestCast(t *testing.T) {
+	assert := assert.New(t)
+	g := NewGraph()
+	x := NewVector(g, Float64, WithShape(3), WithName("x"))
+
+	y, err := Cast(x, Float64)
+	assert.NoError(err)
+	assert.Equal(x, y)
+
+	_, err = Cast(x, tensor.String)
+	assert.Error(err)
+
+	s := NewScalar(g, Float64, WithName("s"))
+	_, err = Cast(s, tensor.Int8)
+	assert.Error(err)
+	_, err = Cast(s, Float32)
+	assert.NoError(err)
+
+	i := NewVector(g, Int, WithShape(3), WithName("i"))
+	ic, err := Cast(i, Float64)
+	if assert.NoError(err) {
+		assert.Equal([]bool{false}, ic.op.(castOp).DiffWRT(1))
+	}
+}
+
+func TestCastGrad(t *testing.T) {
+	assert := assert.New(t)
+	xV := tensor.New(tensor.WithBacking([]float64{1, 2, 3}))
+
+	// symbolic
+	g := NewGraph()
+	x := NewVector(g, Float64, WithShape(3), WithName("x"), WithValue(xV))
+	y := Must(Cast(x, Float32))
+	cost := Must(Sum(Must(Square(y))))
+	if _, err := Grad(cost, x); err != nil {
+		t.Fatal(err)
+	}
+	m := NewTapeMachine(g)
+	defer m.Close()
+	if err := m.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+	xG, err := x.Grad()
+	if !assert.NoError(err) {
+		t.FailNow()
+	}
+	assert.Equal(Float64, xG.Dtype())
+	assert.Equal([]float64{2, 4, 6}, xG.Data())
+
+	// autodiff
+	g2 := NewGraph()
+	x2 := NewVector(g2, Float64, WithShape(3), WithName("x"), WithValue(xV.Clone()))
+	y2 := Must(Cast(x2, Float32))
+	Must(Sum(Must(Square(y2))))
+	m2 := NewLispMachine(g2)
+	defer m2.Close()
+	if err := m2.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+	x2G, err := x2.Grad()
+	if !assert.NoError(err) {
+		t.FailNow()
+	}
+	assert.Equal([]float64{2, 4, 6}, x2G.Data())
+}
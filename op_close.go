@@ -0,0 +1,239 @@
+package gorgonia
+
+/*
+This file holds the approximate comparison of floats and complex numbers, both as an Op and as a function on Values.
+*/
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"math"
+	"math/cmplx"
+	"reflect"
+
+	"github.com/chewxy/hm"
+	"github.com/pkg/errors"
+	"gorgonia.org/tensor"
+)
+
+// closeToOp tests if each element of a is close to the corresponding element of b, that is:
+//
+//	|a - b| <= atol + rtol * |b|
+//
+// This is the same definition NumPy uses. NaNs are never close to anything. Infinities are only close to infinities of the same sign.
+type closeToOp struct {
+	rtol, atol float64
+	ad, bd     int
+}
+
+func (op closeToOp) Arity() int { return 2 }
+
+// closeToOp has either of these types:
+//
+//	closeToOp :: (Floats a) ⇒ Tensor a → Tensor a → Tensor Bool
+//	closeToOp :: (Floats a) ⇒ Tensor a → a → Tensor Bool
+//	closeToOp :: (Floats a) ⇒ a → Tensor a → Tensor Bool
+//	closeToOp :: (Floats a) ⇒ a → a → Bool
+func (op closeToOp) Type() hm.Type {
+	a := hm.TypeVariable('a')
+	var at, bt hm.Type = a, a
+	if op.ad > 0 {
+		at = makeTensorType(op.ad, a)
+	}
+	if op.bd > 0 {
+		bt = makeTensorType(op.bd, a)
+	}
+	d := op.ad
+	if d == 0 {
+		d = op.bd
+	}
+	return hm.NewFnType(at, bt, boolType(d))
+}
+
+func (op closeToOp) InferShape(inputs ...DimSizer) (retVal tensor.Shape, err error) {
+	if err = checkArity(op, len(inputs)); err != nil {
+		return
+	}
+	a, aok := inputs[0].(tensor.Shape)
+	b, bok := inputs[1].(tensor.Shape)
+	if !aok || !bok {
+		return nil, errors.Errorf("Expected shapes. Got %v and %v instead", inputs[0], inputs[1])
+	}
+	switch {
+	case a.IsScalar():
+		return b.Clone(), nil
+	case b.IsScalar():
+		return a.Clone(), nil
+	case !a.Eq(b):
+		return nil, errors.Errorf("Shape mismatch: %v and %v", a, b)
+	}
+	return a.Clone(), nil
+}
+
+func (op closeToOp) Do(inputs ...Value) (retVal Value, err error) {
+	if err = checkArity(op, len(inputs)); err != nil {
+		return
+	}
+	a, b := inputs[0], inputs[1]
+
+	var ret []bool
+	var shape tensor.Shape
+	if ret, shape, err = closeTo(a, b, op.rtol, op.atol); err != nil {
+		return nil, errors.Wrap(err, opDoFail)
+	}
+	if a.Shape().IsScalar() && b.Shape().IsScalar() {
+		return newB(ret[0]), nil
+	}
+	return tensor.New(tensor.WithShape(shape...), tensor.WithBacking(ret)), nil
+}
+
+func (op closeToOp) ReturnsPtr() bool     { return false }
+func (op closeToOp) CallsExtern() bool    { return false }
+func (op closeToOp) OverwritesInput() int { return -1 }
+
+func (op closeToOp) WriteHash(h hash.Hash) {
+	h.Write([]byte("closeTo"))
+	if err := binary.Write(h, binary.LittleEndian, []float64{op.rtol, op.atol}); err != nil {
+		panic(err)
+	}
+	if err := binary.Write(h, binary.LittleEndian, []byte{byte(op.ad), byte(op.bd)}); err != nil {
+		panic(err)
+	}
+}
+
+func (op closeToOp) Hashcode() uint32 { return simpleHash(op) }
+
+func (op closeToOp) String() string { return fmt.Sprintf("CloseTo(%v, %v)", op.rtol, op.atol) }
+
+func (op closeToOp) DiffWRT(inputs int) []bool { return []bool{false, false} }
+
+func (op closeToOp) SymDiff(inputs Nodes, output, grad *Node) (retVal Nodes, err error) {
+	return nil, nondiffErr(op)
+}
+
+// AllClose returns true if a and b have the same shape and Dtype, and every element of a is close to the corresponding element of b:
+//
+//	|a - b| <= atol + rtol * |b|
+//
+// For Dtypes other than floats and complex numbers, the values are compared exactly.
+func AllClose(a, b Value, rtol, atol float64) (bool, error) {
+	if a.Dtype() != b.Dtype() {
+		return false, errors.Errorf("Dtype mismatch: %v and %v", a.Dtype(), b.Dtype())
+	}
+	if !a.Shape().Eq(b.Shape()) {
+		return false, nil
+	}
+
+	switch categoryOf(a.Dtype()) {
+	case floatCategory, complexCategory:
+	default:
+		return ValueEq(a, b), nil
+	}
+
+	ret, _, err := closeTo(a, b, rtol, atol)
+	if err != nil {
+		return false, err
+	}
+	for _, v := range ret {
+		if !v {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// closeTo compares the elements of a and b. Either may be a scalar, in which case it is broadcasted.
+func closeTo(a, b Value, rtol, atol float64) (retVal []bool, shape tensor.Shape, err error) {
+	if a.Dtype() != b.Dtype() {
+		return nil, nil, errors.Errorf("Dtype mismatch: %v and %v", a.Dtype(), b.Dtype())
+	}
+
+	var av, bv reflect.Value
+	if av, err = valueSlice(a); err != nil {
+		return
+	}
+	if bv, err = valueSlice(b); err != nil {
+		return
+	}
+	ad, bd := av.Interface(), bv.Interface()
+
+	shape = a.Shape()
+	al, bl := av.Len(), bv.Len()
+	size := al
+	as, bs := 1, 1
+	switch {
+	case al == 1 && bl == 1:
+		as, bs = 0, 0
+		if shape.IsScalar() {
+			shape = b.Shape()
+		}
+	case al == 1:
+		as = 0
+		size = bl
+		shape = b.Shape()
+	case bl == 1:
+		bs = 0
+	case al != bl:
+		return nil, nil, errors.Errorf("Shape mismatch: %v and %v", a.Shape(), b.Shape())
+	}
+
+	retVal = make([]bool, size)
+	switch at := ad.(type) {
+	case []float64:
+		closeToF64(at, bd.([]float64), retVal, as, bs, rtol, atol)
+	case []float32:
+		closeToF32(at, bd.([]float32), retVal, as, bs, rtol, atol)
+	case []complex64:
+		closeToC64(at, bd.([]complex64), retVal, as, bs, rtol, atol)
+	case []complex128:
+		closeToC128(at, bd.([]complex128), retVal, as, bs, rtol, atol)
+	default:
+		return nil, nil, errors.Errorf(nyiFail, "closeTo", a.Dtype())
+	}
+	return retVal, shape.Clone(), nil
+}
+
+func closeToF64(a, b []float64, retVal []bool, as, bs int, rtol, atol float64) {
+	for i := range retVal {
+		retVal[i] = closeF64(a[i*as], b[i*bs], rtol, atol)
+	}
+}
+
+func closeToF32(a, b []float32, retVal []bool, as, bs int, rtol, atol float64) {
+	for i := range retVal {
+		retVal[i] = closeF64(float64(a[i*as]), float64(b[i*bs]), rtol, atol)
+	}
+}
+
+func closeToC64(a, b []complex64, retVal []bool, as, bs int, rtol, atol float64) {
+	for i := range retVal {
+		retVal[i] = closeC128(complex128(a[i*as]), complex128(b[i*bs]), rtol, atol)
+	}
+}
+
+func closeToC128(a, b []complex128, retVal []bool, as, bs int, rtol, atol float64) {
+	for i := range retVal {
+		retVal[i] = closeC128(a[i*as], b[i*bs], rtol, atol)
+	}
+}
+
+func closeF64(a, b, rtol, atol float64) bool {
+	switch {
+	case a == b:
+		return true // handles infinities
+	case math.IsNaN(a) || math.IsNaN(b) || math.IsInf(a, 0) || math.IsInf(b, 0):
+		return false
+	}
+	return math.Abs(a-b) <= atol+rtol*math.Abs(b)
+}
+
+func closeC128(a, b complex128, rtol, atol float64) bool {
+	switch {
+	case a == b:
+		return true
+	case cmplx.IsNaN(a) || cmplx.IsNaN(b) || cmplx.IsInf(a) || cmplx.IsInf(b):
+		return false
+	}
+	return cmplx.Abs(a-b) <= atol+rtol*cmplx.Abs(b)
+}
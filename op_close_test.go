@@ -0,0 +1,86 @@
+package gorgonia
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorgonia.org/tensor"
+)
+
+func TestCloseF64(t *testing.T) {
+	assert := assert.New(t)
+	nan, inf := math.NaN(), math.Inf(1)
+	assert.True(closeF64(1, 1+1e-9, 1e-5, 1e-8))
+	assert.False(closeF64(1, 1.1, 1e-5, 1e-8))
+	assert.True(closeF64(1, 1.1, 0.1, 0))
+	assert.True(closeF64(0, 1e-9, 0, 1e-8))
+	assert.True(closeF64(inf, inf, 0, 0))
+	assert.False(closeF64(inf, -inf, 0, 0))
+	assert.False(closeF64(inf, 1, 1, 1))
+	assert.False(closeF64(nan, nan, 1, 1))
+	assert.True(closeC128(1+1i, 1+1.000000001i, 1e-5, 1e-8))
+	assert.False(closeC128(1+1i, 1+2i, 1e-5, 1e-8))
+}
+
+func TestAllClose(t *testing.T) {
+	assert := assert.New(t)
+	a := tensor.New(tensor.WithBacking([]float64{1, 2, 3}))
+	b := tensor.New(tensor.WithBacking([]float64{1, 2.0000001, 3}))
+	c := tensor.New(tensor.WithBacking([]float64{1, 2.1, 3}))
+
+	ok, err := AllClose(a, b, 1e-5, 1e-8)
+	assert.NoError(err)
+	assert.True(ok)
+	ok, err = AllClose(a, c, 1e-5, 1e-8)
+	assert.NoError(err)
+	assert.False(ok)
+
+	// shape mismatch
+	d := tensor.New(tensor.WithShape(2, 2), tensor.WithBacking([]float64{1, 2, 3, 4}))
+	ok, err = AllClose(a, d, 1e-5, 1e-8)
+	assert.NoError(err)
+	assert.False(ok)
+
+	// dtype mismatch
+	_, err = AllClose(a, tensor.New(tensor.WithBacking([]float32{1, 2, 3})), 1e-5, 1e-8)
+	assert.Error(err)
+
+	// exact comparison for non floats
+	ok, err = AllClose(tensor.New(tensor.WithBacking([]int{1, 2})), tensor.New(tensor.WithBacking([]int{1, 2})), 1, 1)
+	assert.NoError(err)
+	assert.True(ok)
+
+	// scalars and complex numbers
+	ok, err = AllClose(newF32(1), newF32(1.0000001), 1e-5, 1e-8)
+	assert.NoError(err)
+	assert.True(ok)
+	ok, err = AllClose(tensor.New(tensor.WithBacking([]complex64{1, 2i})), tensor.New(tensor.WithBacking([]complex64{1, 2.5i})), 0.1, 0)
+	assert.NoError(err)
+	assert.False(ok)
+}
+
+func TestCloseTo(t *testing.T) {
+	assert := assert.New(t)
+	g := NewGraph()
+	a := NewVector(g, Float32, WithShape(3), WithName("a"), WithValue(tensor.New(tensor.WithBacking([]float32{1, 2, 3}))))
+	b := NewVector(g, Float32, WithShape(3), WithName("b"), WithValue(tensor.New(tensor.WithBacking([]float32{1.05, 2.5, 3}))))
+	s := NewScalar(g, Float32, WithName("s"), WithValue(float32(2)))
+
+	ab := Must(CloseTo(a, b, 0.1, 0))
+	as := Must(CloseTo(a, s, 0, 0.5))
+	assert.Equal(Bool, ab.Dtype())
+	assert.Equal(tensor.Shape{3}, as.Shape())
+
+	m := NewTapeMachine(g)
+	defer m.Close()
+	if err := m.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]bool{true, false, true}, ab.Value().Data())
+	assert.Equal([]bool{false, true, false}, as.Value().Data())
+
+	i := NewVector(g, Int, WithShape(3), WithName("i"))
+	_, err := CloseTo(i, i, 0, 0)
+	assert.Error(err)
+}
@@ -0,0 +1,394 @@
+package gorgonia
+
+import (
+	"fmt"
+	"hash"
+	"math"
+
+	"github.com/chewxy/hm"
+	"github.com/pkg/errors"
+	"gorgonia.org/tensor"
+)
+
+/*
+This file holds the linear-chain conditional random field of the sequence taggers. A sequence of T tokens is scored by
+a (T, K) matrix of emissions, the scores of the K tags of every token, and a (K, K) matrix of transitions, the scores
+of the tag j following the tag i. The score of a sequence of tags y is
+
+		score(y) = Σₜ emissions[t, yₜ] + Σₜ transitions[yₜ₋₁, yₜ]
+
+and its probability is exp(score(y)) / Z, where the partition function Z sums the exponentials of the scores of all the
+Kᵀ sequences of tags. The model is trained by maximizing CRFLogLikelihood, and the tags are decoded by CRFDecode.
+*/
+
+// CRFLogPartition returns the log of the partition function of the CRF, computed by the forward algorithm in the log
+// domain. Its gradients are the marginal probabilities of the tags and of the transitions.
+func CRFLogPartition(emissions, transitions *Node) (*Node, error) {
+	return ApplyOp(crfOp{}, emissions, transitions)
+}
+
+// CRFLogLikelihood returns the log probability of the tags under the CRF: score(tags) - log Z. The tags are a (T, K)
+// one-hot matrix, so that they may be let for every sequence.
+func CRFLogLikelihood(emissions, transitions, tags *Node) (retVal *Node, err error) {
+	if !emissions.Shape().Eq(tags.Shape()) {
+		return nil, errors.Errorf("Expected one-hot tags of shape %v. Got %v instead", emissions.Shape(), tags.Shape())
+	}
+	var logZ, score, prod *Node
+	if logZ, err = CRFLogPartition(emissions, transitions); err != nil {
+		return nil, err
+	}
+	if prod, err = HadamardProd(emissions, tags); err != nil {
+		return nil, errors.Wrap(err, hadamardProdFail)
+	}
+	if score, err = Sum(prod); err != nil {
+		return nil, errors.Wrap(err, operationError)
+	}
+	if steps := tags.Shape()[0]; steps > 1 {
+		// the counts of the transitions: counts[i, j] = Σₜ tags[t-1, i]·tags[t, j]
+		var prev, next, counts *Node
+		if prev, err = Slice(tags, S(0, steps-1)); err != nil {
+			return nil, err
+		}
+		if next, err = Slice(tags, S(1, steps)); err != nil {
+			return nil, err
+		}
+		if prev, err = Transpose(prev); err != nil {
+			return nil, err
+		}
+		if counts, err = Mul(prev, next); err != nil {
+			return nil, errors.Wrap(err, mulFail)
+		}
+		if prod, err = HadamardProd(transitions, counts); err != nil {
+			return nil, errors.Wrap(err, hadamardProdFail)
+		}
+		if prod, err = Sum(prod); err != nil {
+			return nil, errors.Wrap(err, operationError)
+		}
+		if score, err = Add(score, prod); err != nil {
+			return nil, errors.Wrap(err, addFail)
+		}
+	}
+	return Sub(score, logZ)
+}
+
+// CRFDecode returns the most likely tags of the sequence under the CRF, as a vector of T Ints, found by the Viterbi
+// algorithm. The decoding is not differentiable.
+func CRFDecode(emissions, transitions *Node) (*Node, error) {
+	return ApplyOp(crfDecodeOp{}, emissions, transitions)
+}
+
+// crfShapes checks the shapes of the emissions and transitions of a CRF, and returns the number of steps and of tags.
+func crfShapes(op Op, emissions, transitions DimSizer) (steps, tags int, err error) {
+	e, ok1 := emissions.(tensor.Shape)
+	t, ok2 := transitions.(tensor.Shape)
+	if !ok1 || !ok2 || e.Dims() != 2 || t.Dims() != 2 || t[0] != t[1] || e[1] != t[0] || e[0] == 0 {
+		return 0, 0, errors.Errorf("%v expects (T, K) emissions and (K, K) transitions. Got %v and %v instead", op, emissions, transitions)
+	}
+	return e[0], e[1], nil
+}
+
+// crfLattice holds the forward and backward log scores of a CRF: alpha[t*K+k] is the log sum of the exponentials of
+// the scores of the tags up to t that end with k, and beta[t*K+k] that of the tags after t, starting from k.
+type crfLattice struct {
+	steps, tags      int
+	emissions, trans []float64
+	alpha, beta      []float64
+	logZ             float64
+}
+
+func newCRFLattice(op Op, emissions, transitions Value, backward bool) (l *crfLattice, err error) {
+	l = new(crfLattice)
+	if l.steps, l.tags, err = crfShapes(op, emissions.Shape(), transitions.Shape()); err != nil {
+		return nil, err
+	}
+	if l.emissions, err = floatData(emissions); err != nil {
+		return nil, errors.Wrap(err, opDoFail)
+	}
+	if l.trans, err = floatData(transitions); err != nil {
+		return nil, errors.Wrap(err, opDoFail)
+	}
+	k := l.tags
+	l.alpha = make([]float64, l.steps*k)
+	copy(l.alpha, l.emissions[:k])
+	scores := make([]float64, k)
+	for t := 1; t < l.steps; t++ {
+		for j := 0; j < k; j++ {
+			for i := 0; i < k; i++ {
+				scores[i] = l.alpha[(t-1)*k+i] + l.trans[i*k+j]
+			}
+			l.alpha[t*k+j] = logSumExpF64s(scores) + l.emissions[t*k+j]
+		}
+	}
+	l.logZ = logSumExpF64s(l.alpha[(l.steps-1)*k:])
+	if !backward {
+		return l, nil
+	}
+	l.beta = make([]float64, l.steps*k)
+	for t := l.steps - 2; t >= 0; t-- {
+		for i := 0; i < k; i++ {
+			for j := 0; j < k; j++ {
+				scores[j] = l.trans[i*k+j] + l.emissions[(t+1)*k+j] + l.beta[(t+1)*k+j]
+			}
+			l.beta[t*k+i] = logSumExpF64s(scores)
+		}
+	}
+	return l, nil
+}
+
+// marginals returns the probabilities of the tags of every step.
+func (l *crfLattice) marginals() []float64 {
+	retVal := make([]float64, len(l.alpha))
+	for i := range retVal {
+		retVal[i] = math.Exp(l.alpha[i] + l.beta[i] - l.logZ)
+	}
+	return retVal
+}
+
+// transitionMarginals returns the expected counts of the transitions: the probabilities of the tag j following the tag
+// i, summed over the steps.
+func (l *crfLattice) transitionMarginals() []float64 {
+	k := l.tags
+	retVal := make([]float64, k*k)
+	for t := 1; t < l.steps; t++ {
+		for i := 0; i < k; i++ {
+			for j := 0; j < k; j++ {
+				retVal[i*k+j] += math.Exp(l.alpha[(t-1)*k+i] + l.trans[i*k+j] + l.emissions[t*k+j] + l.beta[t*k+j] - l.logZ)
+			}
+		}
+	}
+	return retVal
+}
+
+func logSumExpF64s(a []float64) float64 {
+	max := math.Inf(-1)
+	for _, v := range a {
+		max = math.Max(max, v)
+	}
+	if math.IsInf(max, -1) {
+		return max
+	}
+	var sum float64
+	for _, v := range a {
+		sum += math.Exp(v - max)
+	}
+	return max + math.Log(sum)
+}
+
+// crfOp computes the log partition function of a linear-chain CRF of (T, K) emissions and (K, K) transitions.
+type crfOp struct{}
+
+func (op crfOp) Arity() int { return 2 }
+
+// crfOp has this type:
+//		crfOp :: (Floats a) ⇒ Matrix a → Matrix a → a
+func (op crfOp) Type() hm.Type {
+	a := hm.TypeVariable('a')
+	m := makeTensorType(2, a)
+	return hm.NewFnType(m, m, a)
+}
+
+func (op crfOp) InferShape(inputs ...DimSizer) (tensor.Shape, error) {
+	if err := checkArity(op, len(inputs)); err != nil {
+		return nil, err
+	}
+	if _, _, err := crfShapes(op, inputs[0], inputs[1]); err != nil {
+		return nil, err
+	}
+	return scalarShape, nil
+}
+
+func (op crfOp) Do(inputs ...Value) (retVal Value, err error) {
+	if err = checkArity(op, len(inputs)); err != nil {
+		return
+	}
+	var l *crfLattice
+	if l, err = newCRFLattice(op, inputs[0], inputs[1], false); err != nil {
+		return nil, err
+	}
+	switch inputs[0].Dtype() {
+	case tensor.Float64:
+		return newF64(l.logZ), nil
+	case tensor.Float32:
+		return newF32(float32(l.logZ)), nil
+	}
+	return nil, errors.Errorf(nyiFail, "CRFLogPartition", inputs[0].Dtype())
+}
+
+func (op crfOp) ReturnsPtr() bool     { return false }
+func (op crfOp) CallsExtern() bool    { return false }
+func (op crfOp) OverwritesInput() int { return -1 }
+
+func (op crfOp) WriteHash(h hash.Hash) { fmt.Fprintf(h, "CRFLogPartition") }
+
+func (op crfOp) Hashcode() uint32 { return simpleHash(op) }
+
+func (op crfOp) String() string { return "CRFLogPartition" }
+
+func (op crfOp) DiffWRT(inputs int) []bool { return []bool{true, true} }
+
+func (op crfOp) SymDiff(inputs Nodes, output, grad *Node) (retVal Nodes, err error) {
+	if err = checkArity(op, len(inputs)); err != nil {
+		return
+	}
+	retVal = make(Nodes, 2)
+	for i := range retVal {
+		if retVal[i], err = ApplyOp(crfGradOp{wrt: i}, inputs[0], inputs[1], grad); err != nil {
+			return nil, errors.Wrapf(err, "Failed to differentiate %v with regards to input %d", op, i)
+		}
+	}
+	return
+}
+
+// crfGradOp is the gradient of a crfOp with regards to the emissions (wrt 0), which is the marginal probabilities of
+// the tags, or to the transitions (wrt 1), which is the expected counts of the transitions.
+type crfGradOp struct {
+	wrt int
+}
+
+func (op crfGradOp) Arity() int { return 3 }
+
+// crfGradOp has this type:
+//		crfGradOp :: (Floats a) ⇒ Matrix a → Matrix a → a → Matrix a
+func (op crfGradOp) Type() hm.Type {
+	a := hm.TypeVariable('a')
+	m := makeTensorType(2, a)
+	return hm.NewFnType(m, m, a, m)
+}
+
+func (op crfGradOp) InferShape(inputs ...DimSizer) (tensor.Shape, error) {
+	if err := checkArity(op, len(inputs)); err != nil {
+		return nil, err
+	}
+	s, ok := inputs[op.wrt].(tensor.Shape)
+	if !ok {
+		return nil, errors.Errorf("Expected a shape. Got %v of %T instead", inputs[op.wrt], inputs[op.wrt])
+	}
+	return s.Clone(), nil
+}
+
+func (op crfGradOp) Do(inputs ...Value) (retVal Value, err error) {
+	if err = checkArity(op, len(inputs)); err != nil {
+		return
+	}
+	var l *crfLattice
+	if l, err = newCRFLattice(op, inputs[0], inputs[1], true); err != nil {
+		return nil, err
+	}
+	var g []float64
+	if g, err = floatData(inputs[2]); err != nil {
+		return
+	}
+	if len(g) != 1 {
+		return nil, errors.Errorf("%v expects a scalar gradient. Got %v instead", op, inputs[2].Shape())
+	}
+	var data []float64
+	if op.wrt == 0 {
+		data = l.marginals()
+	} else {
+		data = l.transitionMarginals()
+	}
+	for i := range data {
+		data[i] *= g[0]
+	}
+	return valueLike(inputs[op.wrt], data)
+}
+
+func (op crfGradOp) ReturnsPtr() bool     { return false }
+func (op crfGradOp) CallsExtern() bool    { return false }
+func (op crfGradOp) OverwritesInput() int { return -1 }
+
+func (op crfGradOp) WriteHash(h hash.Hash) { fmt.Fprintf(h, "CRFLogPartitionGrad%d", op.wrt) }
+
+func (op crfGradOp) Hashcode() uint32 { return simpleHash(op) }
+
+func (op crfGradOp) String() string { return fmt.Sprintf("CRFLogPartitionGrad{%d}", op.wrt) }
+
+func (op crfGradOp) DiffWRT(inputs int) []bool { return []bool{false, false, false} }
+
+func (op crfGradOp) SymDiff(inputs Nodes, output, grad *Node) (retVal Nodes, err error) {
+	return nil, nondiffErr(op)
+}
+
+// crfDecodeOp finds the most likely tags of a linear-chain CRF by the Viterbi algorithm.
+type crfDecodeOp struct{}
+
+func (op crfDecodeOp) Arity() int { return 2 }
+
+// crfDecodeOp has this type:
+//		crfDecodeOp :: (Floats a) ⇒ Matrix a → Matrix a → Vector Int
+func (op crfDecodeOp) Type() hm.Type {
+	m := makeTensorType(2, hm.TypeVariable('a'))
+	return hm.NewFnType(m, m, makeTensorType(1, Int))
+}
+
+func (op crfDecodeOp) InferShape(inputs ...DimSizer) (tensor.Shape, error) {
+	if err := checkArity(op, len(inputs)); err != nil {
+		return nil, err
+	}
+	steps, _, err := crfShapes(op, inputs[0], inputs[1])
+	if err != nil {
+		return nil, err
+	}
+	return tensor.Shape{steps}, nil
+}
+
+func (op crfDecodeOp) Do(inputs ...Value) (retVal Value, err error) {
+	if err = checkArity(op, len(inputs)); err != nil {
+		return
+	}
+	var steps, k int
+	if steps, k, err = crfShapes(op, inputs[0].Shape(), inputs[1].Shape()); err != nil {
+		return nil, err
+	}
+	var e, trans []float64
+	if e, err = floatData(inputs[0]); err != nil {
+		return nil, errors.Wrap(err, opDoFail)
+	}
+	if trans, err = floatData(inputs[1]); err != nil {
+		return nil, errors.Wrap(err, opDoFail)
+	}
+
+	// best[t*K+j] is the score of the best tags up to t ending with j, and back[t*K+j] the tag before j in them
+	best := make([]float64, steps*k)
+	back := make([]int, steps*k)
+	copy(best, e[:k])
+	for t := 1; t < steps; t++ {
+		for j := 0; j < k; j++ {
+			arg, max := 0, math.Inf(-1)
+			for i := 0; i < k; i++ {
+				if s := best[(t-1)*k+i] + trans[i*k+j]; s > max {
+					arg, max = i, s
+				}
+			}
+			best[t*k+j] = max + e[t*k+j]
+			back[t*k+j] = arg
+		}
+	}
+	tags := make([]int, steps)
+	last := best[(steps-1)*k:]
+	for j, s := range last {
+		if s > last[tags[steps-1]] {
+			tags[steps-1] = j
+		}
+	}
+	for t := steps - 1; t > 0; t-- {
+		tags[t-1] = back[t*k+tags[t]]
+	}
+	return tensor.New(tensor.WithShape(steps), tensor.WithBacking(tags)), nil
+}
+
+func (op crfDecodeOp) ReturnsPtr() bool     { return false }
+func (op crfDecodeOp) CallsExtern() bool    { return false }
+func (op crfDecodeOp) OverwritesInput() int { return -1 }
+
+func (op crfDecodeOp) WriteHash(h hash.Hash) { fmt.Fprintf(h, "CRFDecode") }
+
+func (op crfDecodeOp) Hashcode() uint32 { return simpleHash(op) }
+
+func (op crfDecodeOp) String() string { return "CRFDecode" }
+
+func (op crfDecodeOp) DiffWRT(inputs int) []bool { return []bool{false, false} }
+
+func (op crfDecodeOp) SymDiff(inputs Nodes, output, grad *Node) (retVal Nodes, err error) {
+	return nil, nondiffErr(op)
+}
@@ -0,0 +1,133 @@
+package gorgonia
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorgonia.org/tensor"
+)
+
+// crfBruteForce enumerates the sequences of T tags of a CRF, and returns the log partition function, and the
+// sequence of the highest score with its score.
+func crfBruteForce(e, trans []float64, steps, k int) (logZ float64, best []int, bestScore float64) {
+	tags := make([]int, steps)
+	bestScore = math.Inf(-1)
+	var z float64
+	for n := 0; n < int(math.Pow(float64(k), float64(steps))); n++ {
+		for t, rest := 0, n; t < steps; t, rest = t+1, rest/k {
+			tags[t] = rest % k
+		}
+		score := e[tags[0]]
+		for t := 1; t < steps; t++ {
+			score += e[t*k+tags[t]] + trans[tags[t-1]*k+tags[t]]
+		}
+		z += math.Exp(score)
+		if score > bestScore {
+			bestScore, best = score, append([]int(nil), tags...)
+		}
+	}
+	return math.Log(z), best, bestScore
+}
+
+func TestCRF(t *testing.T) {
+	assert := assert.New(t)
+	const steps, k = 4, 3
+	eT := tensor.New(tensor.WithShape(steps, k), tensor.WithBacking(tensor.Random(tensor.Float64, steps*k)))
+	transT := tensor.New(tensor.WithShape(k, k), tensor.WithBacking(tensor.Random(tensor.Float64, k*k)))
+	e, trans := eT.Data().([]float64), transT.Data().([]float64)
+	for i := range e {
+		e[i] = 4*e[i] - 2
+	}
+	for i := range trans {
+		trans[i] = 4*trans[i] - 2
+	}
+	logZ, best, bestScore := crfBruteForce(e, trans, steps, k)
+
+	g := NewGraph()
+	emissions := NodeFromAny(g, eT, WithName("emissions"))
+	transitions := NodeFromAny(g, transT, WithName("transitions"))
+	gold := []int{0, 2, 2, 1}
+	oneHot := make([]float64, steps*k)
+	goldScore := e[gold[0]]
+	for t, y := range gold {
+		oneHot[t*k+y] = 1
+		if t > 0 {
+			goldScore += e[t*k+y] + trans[gold[t-1]*k+y]
+		}
+	}
+	tags := NewMatrix(g, Float64, WithShape(steps, k), WithName("tags"), WithValue(tensor.New(tensor.WithShape(steps, k), tensor.WithBacking(oneHot))))
+	ll, err := CRFLogLikelihood(emissions, transitions, tags)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := CRFDecode(emissions, transitions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(Int, decoded.Dtype())
+	if _, err = Grad(ll, emissions, transitions); err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewTapeMachine(g)
+	defer m.Close()
+	if err = m.RunAll(); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	assert.InDelta(goldScore-logZ, ll.Value().Data(), 1e-12)
+	assert.Equal(best, decoded.Value().Data())
+	assert.True(bestScore <= logZ)
+
+	// the gradients of the log partition function are the marginal probabilities
+	inputs := []Value{eT, transT}
+	for wrt := range inputs {
+		dx, err := crfGradOp{wrt: wrt}.Do(eT, transT, newF64(1))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, _ := floatData(dx)
+		assert.InDeltaSlice(numericGrad(t, crfOp{}, inputs, wrt, []float64{1}), got, 1e-6, "wrt %d", wrt)
+	}
+	mv, err := crfGradOp{}.Do(eT, transT, newF64(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	marginals, _ := floatData(mv)
+	for step := 0; step < steps; step++ {
+		var sum float64
+		for _, p := range marginals[step*k : (step+1)*k] {
+			sum += p
+		}
+		assert.InDelta(1, sum, 1e-12)
+	}
+
+	// the gradients of the log likelihood are the differences of the gold tags and the marginals
+	got, _ := floatData(emissions.Deriv().Value())
+	for i := range got {
+		assert.InDelta(oneHot[i]-marginals[i], got[i], 1e-12)
+	}
+
+	_, err = CRFLogPartition(emissions, NewMatrix(g, Float64, WithShape(2, 2)))
+	assert.Error(err)
+	_, err = CRFLogLikelihood(emissions, transitions, NewMatrix(g, Float64, WithShape(steps, 2)))
+	assert.Error(err)
+}
+
+func TestCRFSingleStep(t *testing.T) {
+	assert := assert.New(t)
+	g := NewGraph()
+	emissions := NewMatrix(g, Float32, WithShape(1, 2), WithName("emissions"), WithValue(tensor.New(tensor.WithShape(1, 2), tensor.WithBacking([]float32{0, 1}))))
+	transitions := NewMatrix(g, Float32, WithShape(2, 2), WithName("transitions"), WithInit(Zeroes()))
+	tags := NewMatrix(g, Float32, WithShape(1, 2), WithName("tags"), WithValue(tensor.New(tensor.WithShape(1, 2), tensor.WithBacking([]float32{1, 0}))))
+	ll := Must(CRFLogLikelihood(emissions, transitions, tags))
+	decoded := Must(CRFDecode(emissions, transitions))
+
+	m := NewTapeMachine(g)
+	defer m.Close()
+	if err := m.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+	assert.InDelta(-math.Log(1+math.E), ll.Value().Data(), 1e-6)
+	assert.Equal(1, decoded.Value().Data())
+}
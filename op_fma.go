@@ -0,0 +1,280 @@
+package gorgonia
+
+/*
+This file holds the fused multiply-add op. The kernels are generated by genapi into operatorFMA_gen.go
+*/
+
+import (
+	"encoding/binary"
+	"hash"
+	"reflect"
+
+	"github.com/chewxy/hm"
+	"github.com/pkg/errors"
+	"gorgonia.org/tensor"
+)
+
+// fmaOp computes a*b + c elementwise. a and c are tensors of the same shape. b is either a tensor of the same shape,
+// or a scalar, in which case the op is an axpy.
+type fmaOp struct {
+	d       int
+	scalarB bool
+}
+
+func newFMAOp(a, b *Node) fmaOp {
+	return fmaOp{d: a.Dims(), scalarB: b.IsScalar()}
+}
+
+func (op fmaOp) Arity() int { return 3 }
+
+// fmaOp has either of these types:
+//		fmaOp :: (Floats a) ⇒ Tensor a → Tensor a → Tensor a → Tensor a
+//		fmaOp :: (Floats a) ⇒ Tensor a → a → Tensor a → Tensor a
+func (op fmaOp) Type() hm.Type {
+	a := hm.TypeVariable('a')
+	t := makeTensorType(op.d, a)
+	if op.scalarB {
+		return hm.NewFnType(t, a, t, t)
+	}
+	return hm.NewFnType(t, t, t, t)
+}
+
+func (op fmaOp) InferShape(inputs ...DimSizer) (retVal tensor.Shape, err error) {
+	if err = checkArity(op, len(inputs)); err != nil {
+		return
+	}
+	shapes := make([]tensor.Shape, 3)
+	for i, in := range inputs {
+		var ok bool
+		if shapes[i], ok = in.(tensor.Shape); !ok {
+			return nil, errors.Errorf("Expected a shape. Got %v of %T instead", in, in)
+		}
+	}
+	if !shapes[0].Eq(shapes[2]) {
+		return nil, errors.Errorf("Shape mismatch: %v and %v", shapes[0], shapes[2])
+	}
+	if !(op.scalarB && shapes[1].IsScalar()) && !shapes[0].Eq(shapes[1]) {
+		return nil, errors.Errorf("Shape mismatch: %v and %v", shapes[0], shapes[1])
+	}
+	return shapes[0].Clone(), nil
+}
+
+func (op fmaOp) Do(inputs ...Value) (retVal Value, err error) {
+	if err = checkArity(op, len(inputs)); err != nil {
+		return
+	}
+	c, ok := inputs[2].(tensor.Tensor)
+	if !ok {
+		return nil, errors.Errorf("Expected c to be a tensor. Got %v of %T instead", inputs[2], inputs[2])
+	}
+	ret := tensor.New(tensor.Of(c.Dtype()), tensor.WithShape(c.Shape().Clone()...), tensor.WithEngine(c.Engine()))
+	return op.do(ret, inputs...)
+}
+
+// UsePreallocDo writes the result into prealloc.
+func (op fmaOp) UsePreallocDo(prealloc Value, inputs ...Value) (retVal Value, err error) {
+	if err = checkArity(op, len(inputs)); err != nil {
+		return
+	}
+	return op.do(prealloc, inputs...)
+}
+
+// UnsafeDo overwrites c with the result. If b is a scalar, this is done with an in place axpy.
+func (op fmaOp) UnsafeDo(inputs ...Value) (retVal Value, err error) {
+	if err = checkArity(op, len(inputs)); err != nil {
+		return
+	}
+	if c, ok := inputs[2].(tensor.Tensor); ok && c.RequiresIterator() {
+		// views cannot be safely overwritten
+		return op.Do(inputs...)
+	}
+	return op.do(inputs[2], inputs...)
+}
+
+func (op fmaOp) do(dst Value, inputs ...Value) (retVal Value, err error) {
+	var av, bv, cv, dv reflect.Value
+	if av, err = valueSlice(inputs[0]); err != nil {
+		return nil, errors.Wrap(err, opDoFail)
+	}
+	if bv, err = valueSlice(inputs[1]); err != nil {
+		return nil, errors.Wrap(err, opDoFail)
+	}
+	if cv, err = valueSlice(inputs[2]); err != nil {
+		return nil, errors.Wrap(err, opDoFail)
+	}
+	if dv, err = valueSlice(dst); err != nil {
+		return nil, errors.Wrap(err, opDoFail)
+	}
+
+	n := dv.Len()
+	if av.Len() != n || cv.Len() != n || (bv.Len() != n && bv.Len() != 1) {
+		return nil, errors.Errorf("Size mismatch in %v: %d, %d, %d into %d", op, av.Len(), bv.Len(), cv.Len(), n)
+	}
+	scalar := bv.Len() == 1 && n != 1
+
+	inplace := cv.Pointer() == dv.Pointer()
+	switch a := av.Interface().(type) {
+	case []float64:
+		b, ok1 := bv.Interface().([]float64)
+		c, ok2 := cv.Interface().([]float64)
+		d, ok3 := dv.Interface().([]float64)
+		if !ok1 || !ok2 || !ok3 {
+			return nil, errors.Errorf("Dtype mismatch in %v", op)
+		}
+		switch {
+		case scalar && inplace:
+			axpyF64(b[0], a, d)
+		case scalar:
+			scaleAddF64(a, b[0], c, d)
+		default:
+			fmaF64(a, b, c, d)
+		}
+	case []float32:
+		b, ok1 := bv.Interface().([]float32)
+		c, ok2 := cv.Interface().([]float32)
+		d, ok3 := dv.Interface().([]float32)
+		if !ok1 || !ok2 || !ok3 {
+			return nil, errors.Errorf("Dtype mismatch in %v", op)
+		}
+		switch {
+		case scalar && inplace:
+			axpyF32(b[0], a, d)
+		case scalar:
+			scaleAddF32(a, b[0], c, d)
+		default:
+			fmaF32(a, b, c, d)
+		}
+	default:
+		return nil, errors.Errorf(nyiFail, op, inputs[0].Dtype())
+	}
+
+	// single element tensors do not return their backing slice, so the result has to be written back
+	if t, ok := dst.(tensor.Tensor); ok && n == 1 {
+		if err = t.SetAt(dv.Index(0).Interface(), make([]int, t.Dims())...); err != nil {
+			return nil, errors.Wrap(err, opDoFail)
+		}
+	}
+	return dst, nil
+}
+
+func (op fmaOp) ReturnsPtr() bool     { return true }
+func (op fmaOp) CallsExtern() bool    { return false }
+func (op fmaOp) OverwritesInput() int { return 2 }
+
+func (op fmaOp) WriteHash(h hash.Hash) {
+	h.Write([]byte("fma"))
+	if op.scalarB {
+		h.Write([]byte("scalar"))
+	}
+	if err := binary.Write(h, binary.LittleEndian, byte(op.d)); err != nil {
+		panic(err)
+	}
+}
+
+func (op fmaOp) Hashcode() uint32 { return simpleHash(op) }
+
+func (op fmaOp) String() string {
+	if op.scalarB {
+		return "Axpy"
+	}
+	return "FMA"
+}
+
+func (op fmaOp) DiffWRT(inputs int) []bool { return []bool{true, true, true} }
+
+func (op fmaOp) SymDiff(inputs Nodes, output, grad *Node) (retVal Nodes, err error) {
+	if err = checkArity(op, len(inputs)); err != nil {
+		return
+	}
+	a, b := inputs[0], inputs[1]
+
+	var da, db *Node
+	if da, err = HadamardProd(grad, b); err != nil {
+		return nil, errors.Wrap(err, hadamardProdFail)
+	}
+	if db, err = HadamardProd(grad, a); err != nil {
+		return nil, errors.Wrap(err, hadamardProdFail)
+	}
+	if op.scalarB {
+		if db, err = Sum(db); err != nil {
+			return nil, errors.Wrap(err, operationError)
+		}
+	}
+	return Nodes{da, db, grad}, nil
+}
+
+func (op fmaOp) DoDiff(ctx ExecutionContext, inputs Nodes, output *Node) (err error) {
+	if err = checkArity(op, len(inputs)); err != nil {
+		return
+	}
+	adv, bdv, cdv := getDV3(inputs[0], inputs[1], inputs[2])
+	odv := output.boundTo.(*dualValue)
+
+	// da += grad * b
+	if _, err = op.do(adv.d, odv.d, bdv.Value, adv.d); err != nil {
+		return errors.Wrapf(err, autodiffFail, op)
+	}
+
+	// db += grad * a
+	if op.scalarB {
+		var sum Value
+		if sum, err = sumProd(odv.d, adv.Value); err != nil {
+			return errors.Wrapf(err, autodiffFail, op)
+		}
+		add := newEBOByType(addOpType, TypeOf(bdv.d), TypeOf(sum))
+		var d Value
+		if d, err = add.UnsafeDo(bdv.d, sum); err != nil {
+			return errors.Wrapf(err, unsafeDoFail, add)
+		}
+		if err = bdv.SetDeriv(d); err != nil {
+			return errors.Wrapf(err, autodiffFail, op)
+		}
+	} else if _, err = op.do(bdv.d, odv.d, adv.Value, bdv.d); err != nil {
+		return errors.Wrapf(err, autodiffFail, op)
+	}
+
+	// dc += grad
+	add := newEBOByType(addOpType, TypeOf(cdv.d), TypeOf(odv.d))
+	if _, err = add.UnsafeDo(cdv.d, odv.d); err != nil {
+		return errors.Wrapf(err, unsafeDoFail, add)
+	}
+	return nil
+}
+
+// sumProd returns the scalar Σ a[i]*b[i]
+func sumProd(a, b Value) (retVal Value, err error) {
+	var av, bv reflect.Value
+	if av, err = valueSlice(a); err != nil {
+		return
+	}
+	if bv, err = valueSlice(b); err != nil {
+		return
+	}
+	if av.Len() != bv.Len() {
+		return nil, errors.Errorf("Size mismatch: %d and %d", av.Len(), bv.Len())
+	}
+
+	switch as := av.Interface().(type) {
+	case []float64:
+		bs, ok := bv.Interface().([]float64)
+		if !ok {
+			return nil, errors.Errorf(typeMismatchFail, a, b)
+		}
+		var sum float64
+		for i := range as {
+			sum += as[i] * bs[i]
+		}
+		return newF64(sum), nil
+	case []float32:
+		bs, ok := bv.Interface().([]float32)
+		if !ok {
+			return nil, errors.Errorf(typeMismatchFail, a, b)
+		}
+		var sum float32
+		for i := range as {
+			sum += as[i] * bs[i]
+		}
+		return newF32(sum), nil
+	}
+	return nil, errors.Errorf(nyiTypeFail, "sumProd", a)
+}
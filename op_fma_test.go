@@ -0,0 +1,104 @@
+package gorgonia
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorgonia.org/tensor"
+)
+
+func TestFMAKernels(t *testing.T) {
+	assert := assert.New(t)
+	a := []float64{1, 2, 3, 4, 5}
+	b := []float64{2, 2, 2, 2, 2}
+	c := []float64{1, 1, 1, 1, 1}
+	ret := make([]float64, 5)
+
+	fmaF64(a, b, c, ret)
+	assert.Equal([]float64{3, 5, 7, 9, 11}, ret)
+
+	scaleAddF64(a, 3, c, ret)
+	assert.Equal([]float64{4, 7, 10, 13, 16}, ret)
+
+	axpyF64(2, a, c)
+	assert.Equal([]float64{3, 5, 7, 9, 11}, c)
+
+	a32 := []float32{1, 2, 3, 4, 5, 6}
+	c32 := []float32{1, 1, 1, 1, 1, 1}
+	ret32 := make([]float32, 6)
+	fmaF32(a32, a32, c32, ret32)
+	assert.Equal([]float32{2, 5, 10, 17, 26, 37}, ret32)
+	axpyF32(-1, a32, c32)
+	assert.Equal([]float32{0, -1, -2, -3, -4, -5}, c32)
+}
+
+func TestFMA(t *testing.T) {
+	assert := assert.New(t)
+	g := NewGraph()
+	a := NewVector(g, Float64, WithShape(3), WithName("a"), WithValue(tensor.New(tensor.WithBacking([]float64{1, 2, 3}))))
+	b := NewVector(g, Float64, WithShape(3), WithName("b"), WithValue(tensor.New(tensor.WithBacking([]float64{4, 5, 6}))))
+	c := NewVector(g, Float64, WithShape(3), WithName("c"), WithValue(tensor.New(tensor.WithBacking([]float64{1, 1, 1}))))
+	s := NewScalar(g, Float64, WithName("s"), WithValue(2.0))
+
+	f := Must(FMA(a, b, c))
+	x := Must(Axpy(s, a, c))
+	cost := Must(Sum(Must(Add(f, x))))
+	if _, err := Grad(cost, a, b, c, s); err != nil {
+		t.Fatal(err)
+	}
+
+	var fv, xv Value
+	Read(f, &fv)
+	Read(x, &xv)
+
+	m := NewTapeMachine(g)
+	defer m.Close()
+	if err := m.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]float64{5, 11, 19}, fv.Data())
+	assert.Equal([]float64{3, 5, 7}, xv.Data())
+	assert.Equal([]float64{1, 1, 1}, c.Value().Data(), "c should not be overwritten")
+
+	ag, _ := a.Grad()
+	bg, _ := b.Grad()
+	cg, _ := c.Grad()
+	sg, _ := s.Grad()
+	assert.Equal([]float64{6, 7, 8}, ag.Data())
+	assert.Equal([]float64{1, 2, 3}, bg.Data())
+	assert.Equal([]float64{2, 2, 2}, cg.Data())
+	assert.Equal(6.0, sg.Data())
+
+	// bad inputs
+	i := NewVector(g, Int, WithShape(3), WithName("i"))
+	_, err := FMA(a, b, i)
+	assert.Error(err)
+	_, err = Axpy(a, b, c)
+	assert.Error(err)
+	_, err = FMA(s, a, c)
+	assert.Error(err)
+}
+
+func TestFMALispMachine(t *testing.T) {
+	assert := assert.New(t)
+	g := NewGraph()
+	a := NewVector(g, Float32, WithShape(2), WithName("a"), WithValue(tensor.New(tensor.WithBacking([]float32{1, 2}))))
+	s := NewScalar(g, Float32, WithName("s"), WithValue(float32(3)))
+	c := NewVector(g, Float32, WithShape(2), WithName("c"), WithValue(tensor.New(tensor.WithBacking([]float32{1, 1}))))
+	x := Must(Axpy(s, a, c))
+	Must(Sum(x))
+
+	m := NewLispMachine(g)
+	defer m.Close()
+	if err := m.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]float32{4, 7}, x.Value().Data())
+
+	ag, _ := a.Grad()
+	sg, _ := s.Grad()
+	cg, _ := c.Grad()
+	assert.Equal([]float32{3, 3}, ag.Data())
+	assert.Equal(float32(3), sg.Data())
+	assert.Equal([]float32{1, 1}, cg.Data())
+}
@@ -0,0 +1,587 @@
+package gorgonia
+
+import (
+	"fmt"
+	"hash"
+	"math"
+
+	"github.com/chewxy/hm"
+	"github.com/pkg/errors"
+	"gorgonia.org/tensor"
+)
+
+// kernelKind is a stationary covariance function k(u) of the scaled distance u = |x1 - x2| / lengthscale.
+type kernelKind byte
+
+const (
+	rbfKernel kernelKind = iota
+	matern12Kernel
+	matern32Kernel
+	matern52Kernel
+)
+
+func (k kernelKind) String() string {
+	switch k {
+	case rbfKernel:
+		return "RBF"
+	case matern12Kernel:
+		return "Matérn1/2"
+	case matern32Kernel:
+		return "Matérn3/2"
+	case matern52Kernel:
+		return "Matérn5/2"
+	}
+	return fmt.Sprintf("kernel(%d)", byte(k))
+}
+
+// eval returns k(u), and k'(u)/u which, unlike k'(u), is finite at u = 0 (except for Matérn 1/2, whose derivative is
+// taken to be 0 there).
+func (k kernelKind) eval(u float64) (f, h float64) {
+	switch k {
+	case rbfKernel:
+		f = math.Exp(-u * u / 2)
+		return f, -f
+	case matern12Kernel:
+		f = math.Exp(-u)
+		if u == 0 {
+			return f, 0
+		}
+		return f, -f / u
+	case matern32Kernel:
+		e := math.Exp(-math.Sqrt(3) * u)
+		return (1 + math.Sqrt(3)*u) * e, -3 * e
+	case matern52Kernel:
+		e := math.Exp(-math.Sqrt(5) * u)
+		return (1 + math.Sqrt(5)*u + 5*u*u/3) * e, -5.0 / 3 * (1 + math.Sqrt(5)*u) * e
+	}
+	panic(fmt.Sprintf("Unknown kernel %d", byte(k)))
+}
+
+// kernelOp computes the kernel matrix K[i, j] = variance · k(|x1[i] - x2[j]| / lengthscale) of the rows of two
+// matrices.
+type kernelOp struct {
+	kind kernelKind
+}
+
+func (op kernelOp) Arity() int { return 4 }
+
+// kernelOp has this type:
+//		kernelOp :: (Floats a) ⇒ Matrix a → Matrix a → a → a → Matrix a
+func (op kernelOp) Type() hm.Type {
+	a := hm.TypeVariable('a')
+	m := makeTensorType(2, a)
+	return hm.NewFnType(m, m, a, a, m)
+}
+
+func (op kernelOp) InferShape(inputs ...DimSizer) (tensor.Shape, error) {
+	if err := checkArity(op, len(inputs)); err != nil {
+		return nil, err
+	}
+	s1, ok1 := inputs[0].(tensor.Shape)
+	s2, ok2 := inputs[1].(tensor.Shape)
+	if !ok1 || !ok2 || s1.Dims() != 2 || s2.Dims() != 2 || s1[1] != s2[1] {
+		return nil, errors.Errorf("Expected two matrices with the same number of columns. Got %v and %v instead", inputs[0], inputs[1])
+	}
+	return tensor.Shape{s1[0], s2[0]}, nil
+}
+
+func (op kernelOp) Do(inputs ...Value) (retVal Value, err error) {
+	if err = checkArity(op, len(inputs)); err != nil {
+		return
+	}
+	var k *kernelInputs
+	if k, err = newKernelInputs(op, inputs[:4]); err != nil {
+		return
+	}
+	data := make([]float64, k.n*k.m)
+	for i := 0; i < k.n; i++ {
+		for j := 0; j < k.m; j++ {
+			f, _ := op.kind.eval(k.u(i, j))
+			data[i*k.m+j] = k.variance * f
+		}
+	}
+	return k.matrix(k.n, k.m, data)
+}
+
+func (op kernelOp) ReturnsPtr() bool     { return false }
+func (op kernelOp) CallsExtern() bool    { return false }
+func (op kernelOp) OverwritesInput() int { return -1 }
+
+func (op kernelOp) WriteHash(h hash.Hash) { fmt.Fprintf(h, "Kernel{%v}", op.kind) }
+
+func (op kernelOp) Hashcode() uint32 { return simpleHash(op) }
+
+func (op kernelOp) String() string { return fmt.Sprintf("%vKernel", op.kind) }
+
+func (op kernelOp) DiffWRT(inputs int) []bool { return []bool{true, true, true, true} }
+
+func (op kernelOp) SymDiff(inputs Nodes, output, grad *Node) (retVal Nodes, err error) {
+	if err = checkArity(op, len(inputs)); err != nil {
+		return
+	}
+	retVal = make(Nodes, 4)
+	for i := range retVal {
+		children := append(inputs[:4:4], grad)
+		if retVal[i], err = ApplyOp(kernelGradOp{kind: op.kind, wrt: i}, children...); err != nil {
+			return nil, errors.Wrapf(err, "Failed to differentiate %v with regards to input %d", op, i)
+		}
+	}
+	return
+}
+
+// kernelGradOp is the gradient of a kernelOp with regards to one of its inputs.
+type kernelGradOp struct {
+	kind kernelKind
+	wrt  int
+}
+
+func (op kernelGradOp) Arity() int { return 5 }
+
+// kernelGradOp has these types:
+//		kernelGradOp :: (Floats a) ⇒ Matrix a → Matrix a → a → a → Matrix a → Matrix a
+//		kernelGradOp :: (Floats a) ⇒ Matrix a → Matrix a → a → a → Matrix a → a
+func (op kernelGradOp) Type() hm.Type {
+	a := hm.TypeVariable('a')
+	m := makeTensorType(2, a)
+	if op.wrt < 2 {
+		return hm.NewFnType(m, m, a, a, m, m)
+	}
+	return hm.NewFnType(m, m, a, a, m, a)
+}
+
+func (op kernelGradOp) InferShape(inputs ...DimSizer) (tensor.Shape, error) {
+	if err := checkArity(op, len(inputs)); err != nil {
+		return nil, err
+	}
+	if op.wrt >= 2 {
+		return scalarShape, nil
+	}
+	s, ok := inputs[op.wrt].(tensor.Shape)
+	if !ok {
+		return nil, errors.Errorf("Expected a shape. Got %v of %T instead", inputs[op.wrt], inputs[op.wrt])
+	}
+	return s.Clone(), nil
+}
+
+func (op kernelGradOp) Do(inputs ...Value) (retVal Value, err error) {
+	if err = checkArity(op, len(inputs)); err != nil {
+		return
+	}
+	var k *kernelInputs
+	if k, err = newKernelInputs(op, inputs[:4]); err != nil {
+		return
+	}
+	var grad []float64
+	if grad, err = floatData(inputs[4]); err != nil {
+		return nil, err
+	}
+	if len(grad) != k.n*k.m {
+		return nil, errors.Errorf("Expected a gradient of shape (%d, %d). Got %v instead", k.n, k.m, inputs[4].Shape())
+	}
+
+	var data []float64
+	switch op.wrt {
+	case 0, 1:
+		rows := k.n
+		if op.wrt == 1 {
+			rows = k.m
+		}
+		data = make([]float64, rows*k.d)
+		l2 := k.lengthscale * k.lengthscale
+		for i := 0; i < k.n; i++ {
+			for j := 0; j < k.m; j++ {
+				_, h := op.kind.eval(k.u(i, j))
+				c := grad[i*k.m+j] * k.variance * h / l2
+				for d := 0; d < k.d; d++ {
+					diff := k.x1[i*k.d+d] - k.x2[j*k.d+d]
+					if op.wrt == 0 {
+						data[i*k.d+d] += c * diff
+					} else {
+						data[j*k.d+d] -= c * diff
+					}
+				}
+			}
+		}
+		return k.matrix(rows, k.d, data)
+	case 2, 3:
+		var sum float64
+		for i := 0; i < k.n; i++ {
+			for j := 0; j < k.m; j++ {
+				u := k.u(i, j)
+				f, h := op.kind.eval(u)
+				if op.wrt == 2 {
+					sum -= grad[i*k.m+j] * k.variance * h * u * u / k.lengthscale
+				} else {
+					sum += grad[i*k.m+j] * f
+				}
+			}
+		}
+		return valueLike(inputs[2], []float64{sum})
+	}
+	return nil, errors.Errorf("%v: invalid input %d", op, op.wrt)
+}
+
+func (op kernelGradOp) ReturnsPtr() bool     { return false }
+func (op kernelGradOp) CallsExtern() bool    { return false }
+func (op kernelGradOp) OverwritesInput() int { return -1 }
+
+func (op kernelGradOp) WriteHash(h hash.Hash) { fmt.Fprintf(h, "KernelGrad{%v, %d}", op.kind, op.wrt) }
+
+func (op kernelGradOp) Hashcode() uint32 { return simpleHash(op) }
+
+func (op kernelGradOp) String() string { return fmt.Sprintf("%vKernelGrad{%d}", op.kind, op.wrt) }
+
+// kernelInputs holds the inputs of a kernel as float64s.
+type kernelInputs struct {
+	x1, x2                []float64
+	n, m, d               int
+	lengthscale, variance float64
+	dt                    tensor.Dtype
+}
+
+func newKernelInputs(op Op, inputs []Value) (*kernelInputs, error) {
+	t1, ok1 := inputs[0].(tensor.Tensor)
+	t2, ok2 := inputs[1].(tensor.Tensor)
+	if !ok1 || !ok2 || t1.Dims() != 2 || t2.Dims() != 2 || t1.Shape()[1] != t2.Shape()[1] {
+		return nil, errors.Errorf("%v expects two matrices with the same number of columns. Got %v and %v instead", op, inputs[0].Shape(), inputs[1].Shape())
+	}
+	k := &kernelInputs{n: t1.Shape()[0], m: t2.Shape()[0], d: t1.Shape()[1], dt: t1.Dtype()}
+	var err error
+	if k.x1, err = floatData(t1); err != nil {
+		return nil, err
+	}
+	if k.x2, err = floatData(t2); err != nil {
+		return nil, err
+	}
+	l, err := floatData(inputs[2])
+	if err != nil {
+		return nil, err
+	}
+	v, err := floatData(inputs[3])
+	if err != nil {
+		return nil, err
+	}
+	if len(l) != 1 || len(v) != 1 {
+		return nil, errors.Errorf("%v expects a scalar lengthscale and variance. Got %v and %v instead", op, inputs[2].Shape(), inputs[3].Shape())
+	}
+	if k.lengthscale, k.variance = l[0], v[0]; k.lengthscale <= 0 {
+		return nil, errors.Errorf("%v expects a positive lengthscale. Got %v instead", op, k.lengthscale)
+	}
+	return k, nil
+}
+
+// u returns the scaled distance between x1[i] and x2[j].
+func (k *kernelInputs) u(i, j int) float64 {
+	var r2 float64
+	for d := 0; d < k.d; d++ {
+		diff := k.x1[i*k.d+d] - k.x2[j*k.d+d]
+		r2 += diff * diff
+	}
+	return math.Sqrt(r2) / k.lengthscale
+}
+
+func (k *kernelInputs) matrix(rows, cols int, data []float64) (Value, error) {
+	if k.dt == tensor.Float32 {
+		f32s := make([]float32, len(data))
+		for i, v := range data {
+			f32s[i] = float32(v)
+		}
+		return tensor.New(tensor.WithShape(rows, cols), tensor.WithBacking(f32s)), nil
+	}
+	return tensor.New(tensor.WithShape(rows, cols), tensor.WithBacking(data)), nil
+}
+
+// gpLMLOp computes the log marginal likelihood of the targets y of a Gaussian process with the kernel matrix K and the
+// noise variance σ²:
+//		log p(y) = -½·yᵀ(K + σ²I)⁻¹y - ½·log|K + σ²I| - n/2·log 2π
+// The inverse and the determinant are computed from the Cholesky decomposition of K + σ²I.
+type gpLMLOp struct{}
+
+func (op gpLMLOp) Arity() int { return 3 }
+
+// gpLMLOp has this type:
+//		gpLMLOp :: (Floats a) ⇒ Matrix a → Vector a → a → a
+func (op gpLMLOp) Type() hm.Type {
+	a := hm.TypeVariable('a')
+	return hm.NewFnType(makeTensorType(2, a), makeTensorType(1, a), a, a)
+}
+
+func (op gpLMLOp) InferShape(inputs ...DimSizer) (tensor.Shape, error) {
+	if err := checkArity(op, len(inputs)); err != nil {
+		return nil, err
+	}
+	k, ok1 := inputs[0].(tensor.Shape)
+	y, ok2 := inputs[1].(tensor.Shape)
+	if !ok1 || !ok2 || k.Dims() != 2 || k[0] != k[1] || y.Dims() != 1 || y[0] != k[0] {
+		return nil, errors.Errorf("Expected an (n, n) kernel matrix and n targets. Got %v and %v instead", inputs[0], inputs[1])
+	}
+	return scalarShape, nil
+}
+
+func (op gpLMLOp) Do(inputs ...Value) (retVal Value, err error) {
+	if err = checkArity(op, len(inputs)); err != nil {
+		return
+	}
+	var gp *gpFit
+	if gp, err = newGPFit(op, inputs); err != nil {
+		return
+	}
+	lml := -0.5*dot(gp.y, gp.alpha) - float64(gp.n)/2*math.Log(2*math.Pi)
+	for i := 0; i < gp.n; i++ {
+		lml -= math.Log(gp.l[i*gp.n+i])
+	}
+	return valueLike(inputs[2], []float64{lml})
+}
+
+func (op gpLMLOp) ReturnsPtr() bool     { return false }
+func (op gpLMLOp) CallsExtern() bool    { return false }
+func (op gpLMLOp) OverwritesInput() int { return -1 }
+
+func (op gpLMLOp) WriteHash(h hash.Hash) { fmt.Fprintf(h, "GPLogMarginalLikelihood") }
+
+func (op gpLMLOp) Hashcode() uint32 { return simpleHash(op) }
+
+func (op gpLMLOp) String() string { return "GPLogMarginalLikelihood" }
+
+func (op gpLMLOp) DiffWRT(inputs int) []bool { return []bool{true, true, true} }
+
+func (op gpLMLOp) SymDiff(inputs Nodes, output, grad *Node) (retVal Nodes, err error) {
+	if err = checkArity(op, len(inputs)); err != nil {
+		return
+	}
+	retVal = make(Nodes, 3)
+	for i := range retVal {
+		children := append(inputs[:3:3], grad)
+		if retVal[i], err = ApplyOp(gpLMLGradOp{wrt: i}, children...); err != nil {
+			return nil, errors.Wrapf(err, "Failed to differentiate %v with regards to input %d", op, i)
+		}
+	}
+	return
+}
+
+// gpLMLGradOp is the gradient of a gpLMLOp with regards to one of its inputs. With A = K + σ²I and α = A⁻¹y:
+//		∂/∂K = ½(ααᵀ - A⁻¹)
+//		∂/∂y = -α
+//		∂/∂σ² = ½(αᵀα - tr(A⁻¹))
+type gpLMLGradOp struct {
+	wrt int
+}
+
+func (op gpLMLGradOp) Arity() int { return 4 }
+
+// gpLMLGradOp has these types:
+//		gpLMLGradOp :: (Floats a) ⇒ Matrix a → Vector a → a → a → Matrix a
+//		gpLMLGradOp :: (Floats a) ⇒ Matrix a → Vector a → a → a → Vector a
+//		gpLMLGradOp :: (Floats a) ⇒ Matrix a → Vector a → a → a → a
+func (op gpLMLGradOp) Type() hm.Type {
+	a := hm.TypeVariable('a')
+	m, v := makeTensorType(2, a), makeTensorType(1, a)
+	switch op.wrt {
+	case 0:
+		return hm.NewFnType(m, v, a, a, m)
+	case 1:
+		return hm.NewFnType(m, v, a, a, v)
+	}
+	return hm.NewFnType(m, v, a, a, a)
+}
+
+func (op gpLMLGradOp) InferShape(inputs ...DimSizer) (tensor.Shape, error) {
+	if err := checkArity(op, len(inputs)); err != nil {
+		return nil, err
+	}
+	if op.wrt == 2 {
+		return scalarShape, nil
+	}
+	s, ok := inputs[op.wrt].(tensor.Shape)
+	if !ok {
+		return nil, errors.Errorf("Expected a shape. Got %v of %T instead", inputs[op.wrt], inputs[op.wrt])
+	}
+	return s.Clone(), nil
+}
+
+func (op gpLMLGradOp) Do(inputs ...Value) (retVal Value, err error) {
+	if err = checkArity(op, len(inputs)); err != nil {
+		return
+	}
+	var gp *gpFit
+	if gp, err = newGPFit(op, inputs[:3]); err != nil {
+		return
+	}
+	var g []float64
+	if g, err = floatData(inputs[3]); err != nil {
+		return
+	}
+	if len(g) != 1 {
+		return nil, errors.Errorf("%v expects a scalar gradient. Got %v instead", op, inputs[3].Shape())
+	}
+	n := gp.n
+	switch op.wrt {
+	case 0:
+		inv := gp.inverse()
+		data := make([]float64, n*n)
+		for i := 0; i < n; i++ {
+			for j := 0; j < n; j++ {
+				data[i*n+j] = g[0] * 0.5 * (gp.alpha[i]*gp.alpha[j] - inv[i*n+j])
+			}
+		}
+		return valueLike(inputs[0], data)
+	case 1:
+		data := make([]float64, n)
+		for i, a := range gp.alpha {
+			data[i] = -g[0] * a
+		}
+		return valueLike(inputs[1], data)
+	case 2:
+		inv := gp.inverse()
+		var trace float64
+		for i := 0; i < n; i++ {
+			trace += inv[i*n+i]
+		}
+		return valueLike(inputs[2], []float64{g[0] * 0.5 * (dot(gp.alpha, gp.alpha) - trace)})
+	}
+	return nil, errors.Errorf("%v: invalid input %d", op, op.wrt)
+}
+
+func (op gpLMLGradOp) ReturnsPtr() bool     { return false }
+func (op gpLMLGradOp) CallsExtern() bool    { return false }
+func (op gpLMLGradOp) OverwritesInput() int { return -1 }
+
+func (op gpLMLGradOp) WriteHash(h hash.Hash) {
+	fmt.Fprintf(h, "GPLogMarginalLikelihoodGrad{%d}", op.wrt)
+}
+
+func (op gpLMLGradOp) Hashcode() uint32 { return simpleHash(op) }
+
+func (op gpLMLGradOp) String() string { return fmt.Sprintf("GPLogMarginalLikelihoodGrad{%d}", op.wrt) }
+
+// gpFit is the Cholesky factor L of K + σ²I, and α = (K + σ²I)⁻¹y.
+type gpFit struct {
+	n        int
+	l        []float64
+	y, alpha []float64
+}
+
+func newGPFit(op Op, inputs []Value) (*gpFit, error) {
+	k, err := floatData(inputs[0])
+	if err != nil {
+		return nil, err
+	}
+	y, err := floatData(inputs[1])
+	if err != nil {
+		return nil, err
+	}
+	noise, err := floatData(inputs[2])
+	if err != nil {
+		return nil, err
+	}
+	n := len(y)
+	if len(k) != n*n || len(noise) != 1 {
+		return nil, errors.Errorf("%v expects an (n, n) kernel matrix, n targets and a scalar noise. Got %v, %v and %v instead", op, inputs[0].Shape(), inputs[1].Shape(), inputs[2].Shape())
+	}
+	a := make([]float64, n*n)
+	copy(a, k)
+	for i := 0; i < n; i++ {
+		a[i*n+i] += noise[0]
+	}
+	gp := &gpFit{n: n, y: y}
+	if gp.l, err = cholesky(a, n); err != nil {
+		return nil, errors.Wrapf(err, "%v", op)
+	}
+	gp.alpha = gp.solve(y)
+	return gp, nil
+}
+
+// solve returns (LLᵀ)⁻¹b.
+func (gp *gpFit) solve(b []float64) []float64 {
+	n, l := gp.n, gp.l
+	x := make([]float64, n)
+	copy(x, b)
+	for i := 0; i < n; i++ {
+		for j := 0; j < i; j++ {
+			x[i] -= l[i*n+j] * x[j]
+		}
+		x[i] /= l[i*n+i]
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := i + 1; j < n; j++ {
+			x[i] -= l[j*n+i] * x[j]
+		}
+		x[i] /= l[i*n+i]
+	}
+	return x
+}
+
+// inverse returns (LLᵀ)⁻¹.
+func (gp *gpFit) inverse() []float64 {
+	n := gp.n
+	inv := make([]float64, n*n)
+	e := make([]float64, n)
+	for j := 0; j < n; j++ {
+		e[j] = 1
+		col := gp.solve(e)
+		e[j] = 0
+		for i, v := range col {
+			inv[i*n+j] = v
+		}
+	}
+	return inv
+}
+
+// cholesky returns the lower triangular L such that a = LLᵀ, for a symmetric positive definite (n, n) matrix a.
+func cholesky(a []float64, n int) ([]float64, error) {
+	l := make([]float64, n*n)
+	for j := 0; j < n; j++ {
+		d := a[j*n+j]
+		for k := 0; k < j; k++ {
+			d -= l[j*n+k] * l[j*n+k]
+		}
+		if d <= 0 || math.IsNaN(d) {
+			return nil, errors.New("The matrix is not positive definite")
+		}
+		l[j*n+j] = math.Sqrt(d)
+		for i := j + 1; i < n; i++ {
+			s := a[i*n+j]
+			for k := 0; k < j; k++ {
+				s -= l[i*n+k] * l[j*n+k]
+			}
+			l[i*n+j] = s / l[j*n+j]
+		}
+	}
+	return l, nil
+}
+
+func dot(a, b []float64) (retVal float64) {
+	for i := range a {
+		retVal += a[i] * b[i]
+	}
+	return
+}
+
+// floatData returns the data of a float value as float64s.
+func floatData(v Value) ([]float64, error) {
+	switch x := v.(type) {
+	case *F64:
+		return []float64{float64(*x)}, nil
+	case *F32:
+		return []float64{float64(*x)}, nil
+	case tensor.Tensor:
+		if x.RequiresIterator() {
+			x = tensor.Materialize(x)
+		}
+		switch data := x.Data().(type) {
+		case []float64:
+			return data, nil
+		case []float32:
+			retVal := make([]float64, len(data))
+			for i, f := range data {
+				retVal[i] = float64(f)
+			}
+			return retVal, nil
+		case float64:
+			return []float64{data}, nil
+		case float32:
+			return []float64{float64(data)}, nil
+		}
+	}
+	return nil, errors.Errorf(nyiTypeFail, "floatData", v)
+}
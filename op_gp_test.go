@@ -0,0 +1,177 @@
+package gorgonia
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorgonia.org/tensor"
+)
+
+// numericGrad returns the central finite difference of the sum of grad·f(inputs) with regards to the input wrt.
+func numericGrad(t *testing.T, f Op, inputs []Value, wrt int, grad []float64) []float64 {
+	in, err := floatData(inputs[wrt])
+	if err != nil {
+		t.Fatal(err)
+	}
+	eval := func(data []float64) float64 {
+		perturbed := append([]Value(nil), inputs...)
+		v, err := valueLike(inputs[wrt], data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		perturbed[wrt] = v
+		out, err := f.Do(perturbed...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		outData, _ := floatData(out)
+		return dot(outData, grad)
+	}
+	const h = 1e-6
+	retVal := make([]float64, len(in))
+	for i := range in {
+		data := append([]float64(nil), in...)
+		data[i] = in[i] + h
+		plus := eval(data)
+		data[i] = in[i] - h
+		retVal[i] = (plus - eval(data)) / (2 * h)
+	}
+	return retVal
+}
+
+func TestKernelOp(t *testing.T) {
+	assert := assert.New(t)
+	x1 := tensor.New(tensor.WithShape(3, 2), tensor.WithBacking([]float64{0, 0, 1, 0, 0.5, -1}))
+	x2 := tensor.New(tensor.WithShape(2, 2), tensor.WithBacking([]float64{0, 0, 2, 1}))
+	inputs := []Value{x1, x2, newF64(0.8), newF64(1.5)}
+	grad := []float64{1, -2, 0.5, 3, -1, 0.25}
+
+	for _, kind := range []kernelKind{rbfKernel, matern12Kernel, matern32Kernel, matern52Kernel} {
+		op := kernelOp{kind: kind}
+		k, err := op.Do(inputs...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(tensor.Shape{3, 2}, k.Shape())
+		assert.InDelta(1.5, k.Data().([]float64)[0], 1e-12, "%v of identical points is the variance", kind)
+
+		for wrt := 0; wrt < 4; wrt++ {
+			g, err := kernelGradOp{kind: kind, wrt: wrt}.Do(append(inputs, tensor.New(tensor.WithShape(3, 2), tensor.WithBacking(grad)))...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, _ := floatData(g)
+			numeric := numericGrad(t, op, inputs, wrt, grad)
+			// Matérn 1/2 is not differentiable where x1[i] = x2[j]
+			if kind == matern12Kernel && wrt < 2 {
+				continue
+			}
+			assert.InDeltaSlice(numeric, got, 1e-5, "%v with regards to input %d", kind, wrt)
+		}
+	}
+
+	// RBF values
+	k, _ := kernelOp{kind: rbfKernel}.Do(inputs...)
+	assert.InDelta(1.5*math.Exp(-1/(2*0.64)), k.Data().([]float64)[2], 1e-12)
+
+	// float32
+	x32 := tensor.New(tensor.WithShape(1, 1), tensor.WithBacking([]float32{1}))
+	k, err := kernelOp{kind: matern32Kernel}.Do(x32, x32, newF32(1), newF32(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]float32{2}, k.Data())
+
+	_, err = kernelOp{kind: rbfKernel}.Do(x1, x2, newF64(0), newF64(1))
+	assert.Error(err, "the lengthscale must be positive")
+	_, err = kernelOp{kind: rbfKernel}.Do(x1, x32, newF64(1), newF64(1))
+	assert.Error(err)
+	_, err = kernelOp{kind: rbfKernel}.InferShape(tensor.Shape{3, 2}, tensor.Shape{2, 3})
+	assert.Error(err)
+}
+
+func TestGPLMLOp(t *testing.T) {
+	assert := assert.New(t)
+	k := tensor.New(tensor.WithShape(3, 3), tensor.WithBacking([]float64{
+		2, 0.5, 0.1,
+		0.5, 1.5, 0.3,
+		0.1, 0.3, 1,
+	}))
+	y := tensor.New(tensor.WithBacking([]float64{1, -0.5, 0.25}))
+	inputs := []Value{k, y, newF64(0.1)}
+
+	lml, err := gpLMLOp{}.Do(inputs...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// closed form with an explicit inverse and determinant
+	a := []float64{2.1, 0.5, 0.1, 0.5, 1.6, 0.3, 0.1, 0.3, 1.1}
+	det := a[0]*(a[4]*a[8]-a[5]*a[7]) - a[1]*(a[3]*a[8]-a[5]*a[6]) + a[2]*(a[3]*a[7]-a[4]*a[6])
+	gp, err := newGPFit(gpLMLOp{}, inputs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	inv := gp.inverse()
+	yv := y.Data().([]float64)
+	var quad float64
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			quad += yv[i] * inv[i*3+j] * yv[j]
+		}
+	}
+	want := -0.5*quad - 0.5*math.Log(det) - 1.5*math.Log(2*math.Pi)
+	assert.InDelta(want, float64(*lml.(*F64)), 1e-12)
+
+	// the inverse is an inverse
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			var s float64
+			for l := 0; l < 3; l++ {
+				s += a[i*3+l] * inv[l*3+j]
+			}
+			if i == j {
+				assert.InDelta(1, s, 1e-12)
+			} else {
+				assert.InDelta(0, s, 1e-12)
+			}
+		}
+	}
+
+	for wrt := 0; wrt < 3; wrt++ {
+		g, err := gpLMLGradOp{wrt: wrt}.Do(append(inputs, newF64(2))...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, _ := floatData(g)
+		if wrt == 0 {
+			// only the lower triangle of k is read, and the gradient is symmetric
+			got = append([]float64(nil), got...)
+			for i := 0; i < 3; i++ {
+				for j := 0; j < i; j++ {
+					got[i*3+j], got[j*3+i] = got[i*3+j]+got[j*3+i], 0
+				}
+			}
+		}
+		assert.InDeltaSlice(numericGrad(t, gpLMLOp{}, inputs, wrt, []float64{2}), got, 1e-6, "input %d", wrt)
+	}
+
+	notPD := tensor.New(tensor.WithShape(2, 2), tensor.WithBacking([]float64{1, 2, 2, 1}))
+	_, err = gpLMLOp{}.Do(notPD, tensor.New(tensor.WithBacking([]float64{1, 1})), newF64(0))
+	assert.Error(err)
+	_, err = gpLMLOp{}.InferShape(tensor.Shape{3, 3}, tensor.Shape{2}, scalarShape)
+	assert.Error(err)
+}
+
+func TestCholesky(t *testing.T) {
+	assert := assert.New(t)
+	a := []float64{4, 2, 2, 3}
+	l, err := cholesky(a, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.InDeltaSlice([]float64{2, 0, 1, math.Sqrt(2)}, l, 1e-12)
+	_, err = cholesky([]float64{0, 1, 1, 0}, 2)
+	assert.Error(err)
+}
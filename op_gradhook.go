@@ -0,0 +1,132 @@
+package gorgonia
+
+/*
+This file holds the Ops used by the built-in gradient hooks.
+*/
+
+import (
+	"fmt"
+	"hash"
+
+	"github.com/chewxy/hm"
+	"github.com/pkg/errors"
+	"gorgonia.org/tensor"
+)
+
+// clipOp clamps each element of a float value to [min, max].
+type clipOp struct {
+	min, max float64
+}
+
+func (op clipOp) Arity() int { return 1 }
+
+// clipOp has this type:
+//		clipOp :: (Floats a) ⇒ a → a
+func (op clipOp) Type() hm.Type {
+	a := hm.TypeVariable('a')
+	return hm.NewFnType(a, a)
+}
+
+func (op clipOp) InferShape(inputs ...DimSizer) (tensor.Shape, error) {
+	return stopGradOp{}.InferShape(inputs...)
+}
+
+func (op clipOp) Do(inputs ...Value) (retVal Value, err error) {
+	if err = checkArity(op, len(inputs)); err != nil {
+		return
+	}
+	switch v := inputs[0].(type) {
+	case *F64:
+		return newF64(clip64(float64(*v), op.min, op.max)), nil
+	case *F32:
+		return newF32(float32(clip64(float64(*v), op.min, op.max))), nil
+	case tensor.Tensor:
+		switch v.Dtype() {
+		case tensor.Float64:
+			return tensor.Clamp(v, op.min, op.max)
+		case tensor.Float32:
+			return tensor.Clamp(v, float32(op.min), float32(op.max))
+		}
+		return nil, errors.Errorf(nyiFail, op, v.Dtype())
+	}
+	return nil, errors.Errorf(nyiTypeFail, op, inputs[0])
+}
+
+func (op clipOp) ReturnsPtr() bool     { return false }
+func (op clipOp) CallsExtern() bool    { return false }
+func (op clipOp) OverwritesInput() int { return -1 }
+
+func (op clipOp) WriteHash(h hash.Hash) { fmt.Fprintf(h, "Clip{%v, %v}", op.min, op.max) }
+
+func (op clipOp) Hashcode() uint32 { return simpleHash(op) }
+
+func (op clipOp) String() string { return fmt.Sprintf("Clip{%v, %v}", op.min, op.max) }
+
+func (op clipOp) DiffWRT(inputs int) []bool { return []bool{false} }
+
+func (op clipOp) SymDiff(inputs Nodes, output, grad *Node) (retVal Nodes, err error) {
+	return nil, nondiffErr(op)
+}
+
+func clip64(a, min, max float64) float64 {
+	switch {
+	case a < min:
+		return min
+	case a > max:
+		return max
+	}
+	return a
+}
+
+// inspectOp is the identity function. It calls fn with its input every time it is executed.
+type inspectOp struct {
+	fn func(Value)
+	id uint64
+}
+
+func newInspectOp(fn func(Value)) inspectOp {
+	return inspectOp{fn: fn, id: uniqueOpID()}
+}
+
+func (op inspectOp) Arity() int { return 1 }
+
+// inspectOp has this type:
+//		inspectOp :: a → a
+func (op inspectOp) Type() hm.Type { return stopGradOp{}.Type() }
+
+func (op inspectOp) InferShape(inputs ...DimSizer) (tensor.Shape, error) {
+	return stopGradOp{}.InferShape(inputs...)
+}
+
+func (op inspectOp) Do(inputs ...Value) (retVal Value, err error) {
+	if err = checkArity(op, len(inputs)); err != nil {
+		return
+	}
+	// fn gets its own copy, as the returned value may be overwritten by the ops that use it
+	var seen Value
+	if seen, err = CloneValue(inputs[0]); err != nil {
+		return nil, err
+	}
+	op.fn(seen)
+	return CloneValue(inputs[0])
+}
+
+func (op inspectOp) ReturnsPtr() bool     { return false }
+func (op inspectOp) CallsExtern() bool    { return false }
+func (op inspectOp) OverwritesInput() int { return -1 }
+
+// IsStateful is true: the callback has to be called on every run.
+func (op inspectOp) IsStateful() bool { return true }
+
+// WriteHash includes the id of the op: funcs cannot be compared, so every inspectOp is distinct.
+func (op inspectOp) WriteHash(h hash.Hash) { fmt.Fprintf(h, "Inspect{%d}", op.id) }
+
+func (op inspectOp) Hashcode() uint32 { return simpleHash(op) }
+
+func (op inspectOp) String() string { return "Inspect" }
+
+func (op inspectOp) DiffWRT(inputs int) []bool { return []bool{false} }
+
+func (op inspectOp) SymDiff(inputs Nodes, output, grad *Node) (retVal Nodes, err error) {
+	return nil, nondiffErr(op)
+}
@@ -0,0 +1,44 @@
+package gorgonia
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorgonia.org/tensor"
+)
+
+func TestClipOp(t *testing.T) {
+	assert := assert.New(t)
+	op := clipOp{min: -1, max: 2}
+
+	v, err := op.Do(tensor.New(tensor.WithBacking([]float32{-3, 0.5, 3})))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]float32{-1, 0.5, 2}, v.Data())
+
+	if v, err = op.Do(newF64(-5)); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(-1.0, v.Data())
+
+	_, err = op.Do(tensor.New(tensor.WithBacking([]int{1})))
+	assert.Error(err)
+}
+
+func TestInspectOp(t *testing.T) {
+	assert := assert.New(t)
+	var seen Value
+	op := newInspectOp(func(v Value) { seen = v })
+	assert.NotEqual(op.Hashcode(), newInspectOp(op.fn).Hashcode())
+
+	in := tensor.New(tensor.WithBacking([]float64{1, 2}))
+	v, err := op.Do(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(in.Data(), v.Data())
+	assert.Equal(v.Data(), seen.Data())
+	assert.False(in == v, "a copy is returned")
+	assert.False(seen == v, "the callback gets its own copy")
+}
@@ -42,6 +42,7 @@ func (op letOp) isStmt() bool { return true }
 // readOp reads a value off the input. This op ensures that a value used, and hence codegen'd out
 type readOp struct {
 	into *Value // no, it's not a mistake. It's a pointer to a Value (which is an interface{} type)
+	id   uint64 // every read is distinct, so that the reads of the same node are not merged
 }
 
 func (op readOp) Arity() int                                                      { return 0 }
@@ -54,7 +55,7 @@ func (op readOp) DiffWRT(int) []bool
 func (op readOp) SymDiff(inputs Nodes, outputNode, gradNode *Node) (Nodes, error) { return nil, nil }
 func (op readOp) Do(vals ...Value) (Value, error)                                 { return nil, nil }
 func (op readOp) String() string                                                  { return "print" }
-func (op readOp) WriteHash(h hash.Hash)                                           { h.Write([]byte("print")) }
+func (op readOp) WriteHash(h hash.Hash)                                           { fmt.Fprintf(h, "print%d", op.id) }
 func (op readOp) Hashcode() uint32                                                { return simpleHash(op) }
 
 func (op readOp) isStmt() bool { return true }
@@ -0,0 +1,133 @@
+package gorgonia
+
+/*
+This file holds the Ops for the saturating and wrapping arithmetic on small integers. The kernels are generated by genapi
+into operatorIntArith_gen.go
+*/
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash"
+
+	"github.com/chewxy/hm"
+	"github.com/pkg/errors"
+	"gorgonia.org/tensor"
+)
+
+type intArithOpType byte
+
+const (
+	satAddOpType intArithOpType = iota
+	satSubOpType
+	satMulOpType
+	wrapAddOpType
+	wrapSubOpType
+	wrapMulOpType
+)
+
+var intArithOpNames = [...]string{
+	satAddOpType:  "SaturatingAdd",
+	satSubOpType:  "SaturatingSub",
+	satMulOpType:  "SaturatingMul",
+	wrapAddOpType: "WrappingAdd",
+	wrapSubOpType: "WrappingSub",
+	wrapMulOpType: "WrappingMul",
+}
+
+func (o intArithOpType) String() string { return intArithOpNames[o] }
+
+// intArithDtypes are the dtypes that the saturating and wrapping ops support.
+var intArithDtypes = []tensor.Dtype{tensor.Int8, tensor.Uint8, tensor.Int16}
+
+// intArithOp is an elementwise saturating or wrapping arithmetic operation on small integers. Either operand may be a
+// scalar.
+type intArithOp struct {
+	ʘ      intArithOpType
+	ad, bd int // dims of a and b
+}
+
+func newIntArithOp(ot intArithOpType, a, b *Node) intArithOp {
+	return intArithOp{ʘ: ot, ad: a.Dims(), bd: b.Dims()}
+}
+
+func (op intArithOp) Arity() int { return 2 }
+
+// intArithOp has either of these types:
+//		intArithOp :: (Integer a) ⇒ Tensor a → Tensor a → Tensor a
+//		intArithOp :: (Integer a) ⇒ Tensor a → a → Tensor a
+//		intArithOp :: (Integer a) ⇒ a → Tensor a → Tensor a
+//		intArithOp :: (Integer a) ⇒ a → a → a
+func (op intArithOp) Type() hm.Type { return integerBinOpType(op.ad, op.bd) }
+
+func (op intArithOp) InferShape(inputs ...DimSizer) (tensor.Shape, error) {
+	if err := checkArity(op, len(inputs)); err != nil {
+		return nil, err
+	}
+	return integerBinOpShape(inputs[0], inputs[1])
+}
+
+func (op intArithOp) Do(inputs ...Value) (retVal Value, err error) {
+	if err = checkArity(op, len(inputs)); err != nil {
+		return
+	}
+	return integerBinDo(inputs[0], inputs[1], nil, func(a, b, ret interface{}, as, bs int) error {
+		return intArithKernel(op.ʘ, a, b, ret, as, bs)
+	})
+}
+
+// fulfils UsePreallocDoer interface
+func (op intArithOp) UsePreallocDo(prealloc Value, inputs ...Value) (retVal Value, err error) {
+	if err = checkArity(op, len(inputs)); err != nil {
+		return
+	}
+	return integerBinDo(inputs[0], inputs[1], prealloc, func(a, b, ret interface{}, as, bs int) error {
+		return intArithKernel(op.ʘ, a, b, ret, as, bs)
+	})
+}
+
+func (op intArithOp) ReturnsPtr() bool     { return false }
+func (op intArithOp) CallsExtern() bool    { return false }
+func (op intArithOp) OverwritesInput() int { return -1 }
+
+func (op intArithOp) WriteHash(h hash.Hash) {
+	fmt.Fprintf(h, "intArith%v", op.ʘ)
+	if err := binary.Write(h, binary.LittleEndian, byte(op.ad)); err != nil {
+		panic(err)
+	}
+	if err := binary.Write(h, binary.LittleEndian, byte(op.bd)); err != nil {
+		panic(err)
+	}
+}
+
+func (op intArithOp) Hashcode() uint32 { return simpleHash(op) }
+
+func (op intArithOp) String() string { return op.ʘ.String() }
+
+func (op intArithOp) DiffWRT(inputs int) []bool { return []bool{false, false} }
+
+func (op intArithOp) SymDiff(inputs Nodes, output, grad *Node) (retVal Nodes, err error) {
+	return nil, nondiffErr(op)
+}
+
+func intArithOpNode(ot intArithOpType, a, b *Node) (retVal *Node, err error) {
+	for _, n := range []*Node{a, b} {
+		var dt tensor.Dtype
+		if dt, err = dtypeOf(n.t); err != nil {
+			return nil, errors.Wrap(err, dtypeOfFail)
+		}
+		if !dtypeIn(dt, intArithDtypes) {
+			return nil, errors.Errorf("%v supports %v. Got %v of %v instead", ot, intArithDtypes, n, dt)
+		}
+	}
+	return ApplyOp(newIntArithOp(ot, a, b), a, b)
+}
+
+func dtypeIn(dt tensor.Dtype, dts []tensor.Dtype) bool {
+	for _, d := range dts {
+		if d == dt {
+			return true
+		}
+	}
+	return false
+}
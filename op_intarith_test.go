@@ -0,0 +1,61 @@
+package gorgonia
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorgonia.org/tensor"
+)
+
+var intArithOpTests = []struct {
+	ot      intArithOpType
+	a, b    Value
+	correct interface{}
+}{
+	{satAddOpType, tensor.New(tensor.WithBacking([]int8{100, -100, 1})), tensor.New(tensor.WithBacking([]int8{100, -100, 2})), []int8{127, -128, 3}},
+	{satSubOpType, tensor.New(tensor.WithBacking([]uint8{1, 200})), tensor.New(tensor.WithBacking([]uint8{2, 100})), []uint8{0, 100}},
+	{satMulOpType, tensor.New(tensor.WithBacking([]int16{300, -300, 3})), tensor.New(tensor.WithBacking([]int16{300, 300, -3})), []int16{32767, -32768, -9}},
+	{satAddOpType, tensor.New(tensor.WithBacking([]uint8{250, 5})), tensor.New(tensor.WithBacking([]uint8{10})), []uint8{255, 15}},
+	{wrapAddOpType, tensor.New(tensor.WithBacking([]int8{100, 1})), tensor.New(tensor.WithBacking([]int8{100, 2})), []int8{-56, 3}},
+	{wrapSubOpType, tensor.New(tensor.WithBacking([]uint8{1})), tensor.New(tensor.WithBacking([]uint8{2})), uint8(255)},
+	{wrapMulOpType, newU8(16), newU8(17), uint8(16)},
+	{satAddOpType, tensor.New(tensor.WithBacking([]int32{1})), tensor.New(tensor.WithBacking([]int32{1})), nil}, // unsupported dtype
+}
+
+func TestIntArithOpDo(t *testing.T) {
+	assert := assert.New(t)
+	for i, iot := range intArithOpTests {
+		op := intArithOp{ʘ: iot.ot, ad: iot.a.Shape().Dims(), bd: iot.b.Shape().Dims()}
+		ret, err := op.Do(iot.a, iot.b)
+		if iot.correct == nil {
+			assert.Error(err, "Test %d", i)
+			continue
+		}
+		if assert.NoError(err, "Test %d", i) {
+			assert.Equal(iot.correct, ret.Data(), "Test %d", i)
+		}
+	}
+}
+
+func TestIntArithOps(t *testing.T) {
+	assert := assert.New(t)
+	g := NewGraph()
+	a := NewVector(g, tensor.Uint8, WithShape(3), WithName("a"), WithValue(tensor.New(tensor.WithBacking([]uint8{10, 128, 255}))))
+	b := NewVector(g, tensor.Uint8, WithShape(3), WithName("b"), WithValue(tensor.New(tensor.WithBacking([]uint8{20, 128, 1}))))
+
+	sat := Must(SaturatingAdd(a, b))
+	wrap := Must(WrappingAdd(a, b))
+	m := NewTapeMachine(g, WithEngine(StandardEngine{}.WithOverflowCheck(true)))
+	defer m.Close()
+	if err := m.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]uint8{30, 255, 255}, sat.Value().Data())
+	assert.Equal([]uint8{30, 0, 0}, wrap.Value().Data(), "the wrapping is not an overflow")
+
+	f := NewVector(g, Float64, WithShape(3), WithName("f"))
+	_, err := SaturatingMul(f, f)
+	assert.Error(err)
+	_, err = WrappingSub(NewVector(g, Int, WithShape(3), WithName("i")), a)
+	assert.Error(err)
+}
@@ -0,0 +1,348 @@
+package gorgonia
+
+/*
+This file holds the Ops for boolean tensors: elementwise logical operations (And, Or, Xor, Not), and the Any/All reductions.
+*/
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash"
+
+	"github.com/chewxy/hm"
+	"github.com/pkg/errors"
+	"gorgonia.org/tensor"
+)
+
+type logicalOpType byte
+
+const (
+	andOpType logicalOpType = iota
+	orOpType
+	xorOpType
+)
+
+var logicalOpSymbols = [...]string{
+	andOpType: "∧",
+	orOpType:  "∨",
+	xorOpType: "⊻",
+}
+
+var logicalOpFns = [...]func(a, b bool) bool{
+	andOpType: func(a, b bool) bool { return a && b },
+	orOpType:  func(a, b bool) bool { return a || b },
+	xorOpType: func(a, b bool) bool { return a != b },
+}
+
+func (o logicalOpType) String() string { return logicalOpSymbols[o] }
+
+// logicalOp is an elementwise logical operation on bools. Either operand may be a scalar.
+type logicalOp struct {
+	ʘ      logicalOpType
+	ad, bd int // dims of a and b
+}
+
+func newLogicalOp(ot logicalOpType, a, b *Node) logicalOp {
+	return logicalOp{ʘ: ot, ad: a.Dims(), bd: b.Dims()}
+}
+
+func (op logicalOp) Arity() int { return 2 }
+
+// logicalOp has either of these types:
+//		logicalOp :: Tensor Bool → Tensor Bool → Tensor Bool
+//		logicalOp :: Tensor Bool → Bool → Tensor Bool
+//		logicalOp :: Bool → Tensor Bool → Tensor Bool
+//		logicalOp :: Bool → Bool → Bool
+func (op logicalOp) Type() hm.Type {
+	at := boolType(op.ad)
+	bt := boolType(op.bd)
+	rt := at
+	if op.ad == 0 {
+		rt = bt
+	}
+	return hm.NewFnType(at, bt, rt)
+}
+
+func (op logicalOp) InferShape(inputs ...DimSizer) (retVal tensor.Shape, err error) {
+	if err = checkArity(op, len(inputs)); err != nil {
+		return
+	}
+	a, aok := inputs[0].(tensor.Shape)
+	b, bok := inputs[1].(tensor.Shape)
+	if !aok || !bok {
+		return nil, errors.Errorf("Expected shapes. Got %v and %v instead", inputs[0], inputs[1])
+	}
+	switch {
+	case a.IsScalar():
+		return b.Clone(), nil
+	case b.IsScalar():
+		return a.Clone(), nil
+	case !a.Eq(b):
+		return nil, errors.Errorf("Shape mismatch: %v and %v", a, b)
+	}
+	return a.Clone(), nil
+}
+
+func (op logicalOp) Do(inputs ...Value) (retVal Value, err error) {
+	if err = checkArity(op, len(inputs)); err != nil {
+		return
+	}
+	var a, b []bool
+	if a, err = boolsOf(inputs[0]); err != nil {
+		return nil, errors.Wrap(err, opDoFail)
+	}
+	if b, err = boolsOf(inputs[1]); err != nil {
+		return nil, errors.Wrap(err, opDoFail)
+	}
+
+	fn := logicalOpFns[op.ʘ]
+	shape := inputs[0].Shape()
+	var ret []bool
+	switch {
+	case len(a) == 1 && len(b) == 1:
+		if inputs[0].Shape().IsScalar() && inputs[1].Shape().IsScalar() {
+			return newB(fn(a[0], b[0])), nil
+		}
+		if shape.IsScalar() {
+			shape = inputs[1].Shape()
+		}
+		ret = []bool{fn(a[0], b[0])}
+	case len(a) == 1:
+		shape = inputs[1].Shape()
+		ret = make([]bool, len(b))
+		for i := range b {
+			ret[i] = fn(a[0], b[i])
+		}
+	case len(b) == 1:
+		ret = make([]bool, len(a))
+		for i := range a {
+			ret[i] = fn(a[i], b[0])
+		}
+	default:
+		if len(a) != len(b) {
+			return nil, errors.Errorf("Shape mismatch: %v and %v", inputs[0].Shape(), inputs[1].Shape())
+		}
+		ret = make([]bool, len(a))
+		for i := range a {
+			ret[i] = fn(a[i], b[i])
+		}
+	}
+	return tensor.New(tensor.WithShape(shape.Clone()...), tensor.WithBacking(ret)), nil
+}
+
+func (op logicalOp) ReturnsPtr() bool     { return false }
+func (op logicalOp) CallsExtern() bool    { return false }
+func (op logicalOp) OverwritesInput() int { return -1 }
+
+func (op logicalOp) WriteHash(h hash.Hash) {
+	fmt.Fprintf(h, "logical%v", op.ʘ)
+	if err := binary.Write(h, binary.LittleEndian, byte(op.ad)); err != nil {
+		panic(err)
+	}
+	if err := binary.Write(h, binary.LittleEndian, byte(op.bd)); err != nil {
+		panic(err)
+	}
+}
+
+func (op logicalOp) Hashcode() uint32 { return simpleHash(op) }
+
+func (op logicalOp) String() string { return op.ʘ.String() }
+
+func (op logicalOp) DiffWRT(inputs int) []bool { return []bool{false, false} }
+
+func (op logicalOp) SymDiff(inputs Nodes, output, grad *Node) (retVal Nodes, err error) {
+	return nil, nondiffErr(op)
+}
+
+// notOp is the elementwise logical negation of bools
+type notOp struct {
+	d int
+}
+
+func (op notOp) Arity() int { return 1 }
+
+// notOp has either of these types:
+//		notOp :: Tensor Bool → Tensor Bool
+//		notOp :: Bool → Bool
+func (op notOp) Type() hm.Type {
+	t := boolType(op.d)
+	return hm.NewFnType(t, t)
+}
+
+func (op notOp) InferShape(inputs ...DimSizer) (tensor.Shape, error) {
+	if err := checkArity(op, len(inputs)); err != nil {
+		return nil, err
+	}
+	s, ok := inputs[0].(tensor.Shape)
+	if !ok {
+		return nil, errors.Errorf("Expected a shape. Got %v of %T instead", inputs[0], inputs[0])
+	}
+	return s.Clone(), nil
+}
+
+func (op notOp) Do(inputs ...Value) (retVal Value, err error) {
+	if err = checkArity(op, len(inputs)); err != nil {
+		return
+	}
+	var a []bool
+	if a, err = boolsOf(inputs[0]); err != nil {
+		return nil, errors.Wrap(err, opDoFail)
+	}
+	if _, ok := inputs[0].(Scalar); ok {
+		return newB(!a[0]), nil
+	}
+
+	ret := make([]bool, len(a))
+	for i := range a {
+		ret[i] = !a[i]
+	}
+	return tensor.New(tensor.WithShape(inputs[0].Shape().Clone()...), tensor.WithBacking(ret)), nil
+}
+
+func (op notOp) ReturnsPtr() bool     { return false }
+func (op notOp) CallsExtern() bool    { return false }
+func (op notOp) OverwritesInput() int { return -1 }
+
+func (op notOp) WriteHash(h hash.Hash) {
+	h.Write([]byte("not"))
+	if err := binary.Write(h, binary.LittleEndian, byte(op.d)); err != nil {
+		panic(err)
+	}
+}
+
+func (op notOp) Hashcode() uint32 { return simpleHash(op) }
+
+func (op notOp) String() string { return "¬" }
+
+func (op notOp) DiffWRT(inputs int) []bool { return []bool{false} }
+
+func (op notOp) SymDiff(inputs Nodes, output, grad *Node) (retVal Nodes, err error) {
+	return nil, nondiffErr(op)
+}
+
+// boolReductionOp reduces bools along the given axes. If any is true, the result is true when any of the reduced elements
+// is true. Otherwise the result is true only when all of the reduced elements are true.
+type boolReductionOp struct {
+	along axes
+	d     int
+	any   bool
+}
+
+func (op boolReductionOp) Arity() int { return 1 }
+
+// boolReductionOp is a function with this type:
+//		boolReductionOp :: Tensor d Bool → Tensor d-1 Bool
+func (op boolReductionOp) Type() hm.Type {
+	return reductionType(op.d, op.along)
+}
+
+func (op boolReductionOp) InferShape(inputs ...DimSizer) (tensor.Shape, error) {
+	if err := checkArity(op, len(inputs)); err != nil {
+		return nil, err
+	}
+	return reductionInferShape(op.along, inputs[0].(tensor.Shape))
+}
+
+func (op boolReductionOp) Do(inputs ...Value) (retVal Value, err error) {
+	if err = checkArity(op, len(inputs)); err != nil {
+		return
+	}
+	var a []bool
+	if a, err = boolsOf(inputs[0]); err != nil {
+		return nil, errors.Wrap(err, opDoFail)
+	}
+
+	var shape tensor.Shape
+	if shape, err = reductionInferShape(op.along, inputs[0].Shape()); err != nil {
+		return nil, errors.Wrap(err, opDoFail)
+	}
+
+	ret := reduceBools(a, inputs[0].Shape(), op.along, op.any)
+	if shape.IsScalar() {
+		return newB(ret[0]), nil
+	}
+	return tensor.New(tensor.WithShape(shape...), tensor.WithBacking(ret)), nil
+}
+
+func (op boolReductionOp) ReturnsPtr() bool     { return false }
+func (op boolReductionOp) CallsExtern() bool    { return false }
+func (op boolReductionOp) OverwritesInput() int { return -1 }
+
+func (op boolReductionOp) WriteHash(h hash.Hash) {
+	if op.any {
+		fmt.Fprintf(h, "any%v", op.along)
+	} else {
+		fmt.Fprintf(h, "all%v", op.along)
+	}
+	if err := binary.Write(h, binary.LittleEndian, byte(op.d)); err != nil {
+		panic(err)
+	}
+}
+
+func (op boolReductionOp) Hashcode() uint32 { return simpleHash(op) }
+
+func (op boolReductionOp) String() string {
+	if op.any {
+		return fmt.Sprintf("Any%v", op.along)
+	}
+	return fmt.Sprintf("All%v", op.along)
+}
+
+func (op boolReductionOp) DiffWRT(inputs int) []bool { return []bool{false} }
+
+func (op boolReductionOp) SymDiff(inputs Nodes, output, grad *Node) (retVal Nodes, err error) {
+	return nil, nondiffErr(op)
+}
+
+/* UTILITY FUNCTIONS */
+
+// boolType returns the type of a bool value of the given dims
+func boolType(d int) hm.Type {
+	if d == 0 {
+		return Bool
+	}
+	return makeTensorType(d, Bool)
+}
+
+// boolsOf returns the bools held in a Value. Views are materialized.
+func boolsOf(v Value) ([]bool, error) {
+	switch vt := v.(type) {
+	case *B:
+		return []bool{bool(*vt)}, nil
+	case tensor.Tensor:
+		if vt.Dtype() != Bool {
+			return nil, errors.Errorf("Expected a tensor of %v. Got %v instead", Bool, vt.Dtype())
+		}
+		if vt.RequiresIterator() {
+			vt = tensor.Materialize(vt)
+		}
+		switch data := vt.Data().(type) {
+		case []bool:
+			return data, nil
+		case bool:
+			return []bool{data}, nil
+		}
+	}
+	return nil, errors.Errorf(nyiTypeFail, "boolsOf", v)
+}
+
+// reduceBools reduces the row major data of the given shape along the given axes.
+func reduceBools(data []bool, shape tensor.Shape, along []int, any bool) []bool {
+	idx, size := reductionIndices(shape, along)
+	ret := make([]bool, size)
+	if !any {
+		for i := range ret {
+			ret[i] = true
+		}
+	}
+
+	for i, v := range data {
+		j := idx[i]
+		if any {
+			ret[j] = ret[j] || v
+		} else {
+			ret[j] = ret[j] && v
+		}
+	}
+	return ret
+}
@@ -0,0 +1,104 @@
+package gorgonia
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorgonia.org/tensor"
+)
+
+func TestLogicalOps(t *testing.T) {
+	assert := assert.New(t)
+	g := NewGraph()
+	a := NewVector(g, Bool, WithShape(4), WithName("a"), WithValue(tensor.New(tensor.WithBacking([]bool{true, true, false, false}))))
+	b := NewVector(g, Bool, WithShape(4), WithName("b"), WithValue(tensor.New(tensor.WithBacking([]bool{true, false, true, false}))))
+	s := NewScalar(g, Bool, WithName("s"), WithValue(true))
+
+	and := Must(And(a, b))
+	or := Must(Or(a, b))
+	xor := Must(Xor(a, b))
+	not := Must(Not(a))
+	sand := Must(And(s, b))
+	sxor := Must(Xor(a, s))
+	ss := Must(Or(s, Must(Not(s))))
+
+	assert.Equal(tensor.Shape{4}, sand.Shape())
+	assert.True(ss.IsScalar())
+
+	m := NewTapeMachine(g)
+	defer m.Close()
+	if err := m.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]bool{true, false, false, false}, and.Value().Data())
+	assert.Equal([]bool{true, true, true, false}, or.Value().Data())
+	assert.Equal([]bool{false, true, true, false}, xor.Value().Data())
+	assert.Equal([]bool{false, false, true, true}, not.Value().Data())
+	assert.Equal([]bool{true, false, true, false}, sand.Value().Data())
+	assert.Equal([]bool{false, false, true, true}, sxor.Value().Data())
+	assert.Equal(true, ss.Value().Data())
+
+	// errors
+	f := NewVector(g, Float64, WithShape(4), WithName("f"))
+	_, err := And(a, f)
+	assert.Error(err)
+	_, err = Not(f)
+	assert.Error(err)
+	c := NewVector(g, Bool, WithShape(3), WithName("c"))
+	_, err = Or(a, c)
+	assert.Error(err)
+}
+
+func TestAnyAll(t *testing.T) {
+	assert := assert.New(t)
+	g := NewGraph()
+	backing := []bool{
+		true, false, true,
+		true, true, true,
+	}
+	a := NewMatrix(g, Bool, WithShape(2, 3), WithName("a"), WithValue(tensor.New(tensor.WithShape(2, 3), tensor.WithBacking(backing))))
+
+	any := Must(Any(a))
+	all := Must(All(a))
+	any0 := Must(Any(a, 0))
+	all0 := Must(All(a, 0))
+	all1 := Must(All(a, 1))
+	any1 := Must(Any(Must(Not(a)), 1))
+
+	assert.True(any.IsScalar())
+	assert.Equal(tensor.Shape{3}, all0.Shape())
+	assert.Equal(tensor.Shape{2}, all1.Shape())
+
+	m := NewTapeMachine(g)
+	defer m.Close()
+	if err := m.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(true, any.Value().Data())
+	assert.Equal(false, all.Value().Data())
+	assert.Equal([]bool{true, true, true}, any0.Value().Data())
+	assert.Equal([]bool{true, false, true}, all0.Value().Data())
+	assert.Equal([]bool{false, true}, all1.Value().Data())
+	assert.Equal([]bool{true, false}, any1.Value().Data())
+
+	_, err := Any(a, 2)
+	assert.Error(err)
+}
+
+func TestReduceBools(t *testing.T) {
+	assert := assert.New(t)
+	// 2x2x2
+	data := []bool{
+		true, false,
+		false, false,
+
+		true, true,
+		false, true,
+	}
+	shape := tensor.Shape{2, 2, 2}
+	assert.Equal([]bool{true, true, false, true}, reduceBools(data, shape, []int{0}, true))
+	assert.Equal([]bool{true, false, true, true}, reduceBools(data, shape, []int{1}, true))
+	assert.Equal([]bool{false, false}, reduceBools(data, shape, []int{1, 2}, false))
+	assert.Equal([]bool{true, true}, reduceBools(data, shape, []int{0, 2}, true))
+	assert.Equal([]bool{false}, reduceBools(data, shape, []int{0, 1, 2}, false))
+}
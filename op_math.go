@@ -481,6 +481,22 @@ func (op elemUnaryOp) UnsafeDo(inputs ...Value) (Value, error) {
 	return op.do(inputs[0], tensor.UseUnsafe())
 }
 
+// fulfils UsePreallocDoer interface
+func (op elemUnaryOp) UsePreallocDo(prealloc Value, inputs ...Value) (retVal Value, err error) {
+	if err = checkArity(op, len(inputs)); err != nil {
+		return
+	}
+
+	t, ok := prealloc.(tensor.Tensor)
+	if !ok {
+		if retVal, err = op.do(inputs[0]); err != nil {
+			return
+		}
+		return Copy(prealloc, retVal)
+	}
+	return op.do(inputs[0], tensor.WithReuse(t))
+}
+
 // fulfils UnaryOp interface
 
 func (op elemUnaryOp) isUnary() bool { return true }
@@ -490,6 +506,11 @@ func (op elemUnaryOp) isUnary() bool { return true }
 func (op elemUnaryOp) do(a Value, opts ...tensor.FuncOpt) (retVal Value, err error) {
 	switch v := a.(type) {
 	case tensor.Tensor:
+		if checkOverflowOf(v) {
+			if err = unaryOpOverflow(op.unaryOpType(), v); err != nil {
+				return nil, err
+			}
+		}
 		return unaryCheckApply(op.ʘUnaryOperator, v, opts...)
 	case Scalar:
 		vt := v.Dtype()
@@ -35,11 +35,11 @@ func (op elemUnaryOp) CUDADo(extern External, dev Device, prealloc Value, inputs
 	dt := a.Dtype()
 
 	// build name
-	name := fmt.Sprintf("%v.%v_f%d", elemUnaryOpMod, op.unaryOpType(), int(dt.Size())*8)
+	name := fmt.Sprintf("%v.%v_f%d", elemUnaryOpMod, op.unaryOpType().cudaKernel(), int(dt.Size())*8)
 
 	machine := extern.(CUDAMachine)
 	eng := machine.Engines()[int(dev)]
-	if !eng.HasFunc(name) {
+	if op.unaryOpType().cudaKernel() == "" || !eng.HasFunc(name) {
 		cudaLogf("extern does not have func %q", name)
 		extern.Signal()
 
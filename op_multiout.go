@@ -0,0 +1,147 @@
+package gorgonia
+
+import (
+	"fmt"
+	"hash"
+
+	"github.com/chewxy/hm"
+	"github.com/pkg/errors"
+	"gorgonia.org/tensor"
+)
+
+// maxIndicesOp returns the maximum values of a tensor along an axis, and their indices.
+type maxIndicesOp struct {
+	axis int
+	d    int
+}
+
+func (op maxIndicesOp) Arity() int { return 1 }
+
+// maxIndicesOp has these types:
+//		maxIndicesOp :: Tensor-d a → (Tensor-(d-1) a, Tensor-(d-1) Int)
+//		maxIndicesOp :: Vector a → (a, Int)
+func (op maxIndicesOp) Type() hm.Type {
+	a := hm.TypeVariable('a')
+	if op.d == 1 {
+		return hm.NewFnType(makeTensorType(1, a), hm.NewRecordType("", a, Int))
+	}
+	return hm.NewFnType(makeTensorType(op.d, a), hm.NewRecordType("", makeTensorType(op.d-1, a), makeTensorType(op.d-1, Int)))
+}
+
+func (op maxIndicesOp) InferShapes(inputs ...DimSizer) ([]tensor.Shape, error) {
+	if len(inputs) != 1 {
+		return nil, errors.Errorf("%v expects 1 input. Got %d instead", op, len(inputs))
+	}
+	s, ok := inputs[0].(tensor.Shape)
+	if !ok {
+		return nil, errors.Errorf("Expected a shape. Got %v of %T instead", inputs[0], inputs[0])
+	}
+	retShape, err := reductionInferShape([]int{op.axis}, s)
+	if err != nil {
+		return nil, err
+	}
+	return []tensor.Shape{retShape, retShape.Clone()}, nil
+}
+
+func (op maxIndicesOp) DoMulti(inputs ...Value) ([]Value, error) {
+	if len(inputs) != 1 {
+		return nil, errors.Errorf("%v expects 1 input. Got %d instead", op, len(inputs))
+	}
+	t, ok := inputs[0].(*tensor.Dense)
+	if !ok {
+		return nil, errors.Errorf(nyiTypeFail, op, inputs[0])
+	}
+	max, err := t.Max(op.axis)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to apply *tensor.Dense.Max()")
+	}
+	indices, err := t.Argmax(op.axis)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to apply *tensor.Dense.Argmax()")
+	}
+	if op.d == 1 {
+		maxV, _ := anyToScalar(max.ScalarValue())
+		indexV, _ := anyToScalar(indices.ScalarValue())
+		return []Value{maxV, indexV}, nil
+	}
+	return []Value{max, indices}, nil
+}
+
+func (op maxIndicesOp) CallsExtern() bool { return false }
+
+func (op maxIndicesOp) WriteHash(h hash.Hash) {
+	fmt.Fprintf(h, "MaxWithIndices{%d, %d}", op.axis, op.d)
+}
+
+func (op maxIndicesOp) Hashcode() uint32 { return simpleHash(op) }
+
+func (op maxIndicesOp) String() string { return fmt.Sprintf("MaxWithIndices{%d}", op.axis) }
+
+func (op maxIndicesOp) DiffWRT(inputs int) []bool { return []bool{true} }
+
+// SymDiffMulti is the derivative of the maximum values: the indices are not differentiable.
+func (op maxIndicesOp) SymDiffMulti(inputs, outputs, grads Nodes) (retVal Nodes, err error) {
+	if grads[0] == nil {
+		return nil, errors.Errorf("%v: no gradient flows through the indices", op)
+	}
+	return maxOp{along: axes{op.axis}, d: op.d}.SymDiff(inputs, outputs[0], grads[0])
+}
+
+// svdOp returns the singular value decomposition of a matrix: U, S and V such that A = U·diag(S)·Vᵀ.
+type svdOp struct {
+	full bool
+}
+
+func (op svdOp) Arity() int { return 1 }
+
+// svdOp has this type:
+//		svdOp :: Matrix a → (Matrix a, Vector a, Matrix a)
+func (op svdOp) Type() hm.Type {
+	a := hm.TypeVariable('a')
+	m := makeTensorType(2, a)
+	return hm.NewFnType(m, hm.NewRecordType("", m, makeTensorType(1, a), m))
+}
+
+// InferShapes returns the shapes of U, S and V. For an (m, n) matrix, with k = min(m, n), they are (m, k), (k) and
+// (n, k), or (m, m), (k) and (n, n) for the full decomposition.
+func (op svdOp) InferShapes(inputs ...DimSizer) ([]tensor.Shape, error) {
+	if len(inputs) != 1 {
+		return nil, errors.Errorf("%v expects 1 input. Got %d instead", op, len(inputs))
+	}
+	s, ok := inputs[0].(tensor.Shape)
+	if !ok || s.Dims() != 2 {
+		return nil, errors.Errorf("Expected the shape of a matrix. Got %v instead", inputs[0])
+	}
+	m, n := s[0], s[1]
+	k := m
+	if n < k {
+		k = n
+	}
+	if op.full {
+		return []tensor.Shape{{m, m}, {k}, {n, n}}, nil
+	}
+	return []tensor.Shape{{m, k}, {k}, {n, k}}, nil
+}
+
+func (op svdOp) DoMulti(inputs ...Value) ([]Value, error) {
+	if len(inputs) != 1 {
+		return nil, errors.Errorf("%v expects 1 input. Got %d instead", op, len(inputs))
+	}
+	t, ok := inputs[0].(*tensor.Dense)
+	if !ok {
+		return nil, errors.Errorf(nyiTypeFail, op, inputs[0])
+	}
+	s, u, v, err := t.SVD(true, op.full)
+	if err != nil {
+		return nil, err
+	}
+	return []Value{u, s, v}, nil
+}
+
+func (op svdOp) CallsExtern() bool { return false }
+
+func (op svdOp) WriteHash(h hash.Hash) { fmt.Fprintf(h, "SVD{%t}", op.full) }
+
+func (op svdOp) Hashcode() uint32 { return simpleHash(op) }
+
+func (op svdOp) String() string { return fmt.Sprintf("SVD{full=%t}", op.full) }
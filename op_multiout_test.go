@@ -0,0 +1,108 @@
+package gorgonia
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorgonia.org/tensor"
+)
+
+func TestMaxWithIndices(t *testing.T) {
+	assert := assert.New(t)
+	g := NewGraph()
+	x := NewMatrix(g, Float64, WithShape(2, 3), WithName("x"))
+	values, indices, err := MaxWithIndices(x, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(tensor.Shape{2}, values.Shape())
+	assert.Equal(Int, indices.Dtype())
+
+	cost := Must(Sum(values))
+	grads, err := Grad(cost, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var vals, idx Value
+	Read(values, &vals)
+	Read(indices, &idx)
+
+	Let(x, tensor.New(tensor.WithShape(2, 3), tensor.WithBacking([]float64{1, 5, 2, 7, 0, 3})))
+	m := NewTapeMachine(g)
+	defer m.Close()
+	if err := m.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]float64{5, 7}, vals.Data())
+	assert.Equal([]int{1, 0}, idx.Data())
+	assert.Equal(12.0, cost.Value().Data())
+	assert.Equal([]float64{0, 1, 0, 1, 0, 0}, grads[0].Value().Data())
+
+	// vectors reduce to scalars
+	g2 := NewGraph()
+	v := NewVector(g2, Float64, WithShape(3), WithName("v"))
+	max, index, err := MaxWithIndices(v, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(max.IsScalar())
+	assert.True(index.IsScalar())
+	Let(v, tensor.New(tensor.WithBacking([]float64{1, 3, 2})))
+	m2 := NewLispMachine(g2, ExecuteFwdOnly())
+	defer m2.Close()
+	if err := m2.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(3.0, max.Value().Data())
+	assert.Equal(1, index.Value().Data())
+
+	_, _, err = MaxWithIndices(x, 2)
+	assert.Error(err)
+}
+
+func TestSVD(t *testing.T) {
+	assert := assert.New(t)
+	g := NewGraph()
+	a := NewMatrix(g, Float64, WithShape(3, 2), WithName("a"))
+	u, s, v, err := SVD(a, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(tensor.Shape{3, 2}, u.Shape())
+	assert.Equal(tensor.Shape{2}, s.Shape())
+	assert.Equal(tensor.Shape{2, 2}, v.Shape())
+
+	uF, _, vF, err := SVD(a, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(tensor.Shape{3, 3}, uF.Shape())
+	assert.Equal(tensor.Shape{2, 2}, vF.Shape())
+
+	data := []float64{3, 1, 1, 3, 0, 2}
+	Let(a, tensor.New(tensor.WithShape(3, 2), tensor.WithBacking(data)))
+	m := NewTapeMachine(g)
+	defer m.Close()
+	if err := m.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(tensor.Shape{3, 3}, uF.Value().Shape())
+
+	// a = u·diag(s)·vᵀ
+	uT, sT, vT := u.Value().(*tensor.Dense), s.Value().Data().([]float64), v.Value().(*tensor.Dense)
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 2; j++ {
+			var sum float64
+			for k := range sT {
+				uik, _ := uT.At(i, k)
+				vjk, _ := vT.At(j, k)
+				sum += uik.(float64) * sT[k] * vjk.(float64)
+			}
+			assert.InDelta(data[i*2+j], sum, 1e-9)
+		}
+	}
+	assert.True(sT[0] >= sT[1])
+
+	_, _, _, err = SVD(NewVector(g, Float64, WithShape(3)), false)
+	assert.Error(err)
+}
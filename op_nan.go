@@ -0,0 +1,350 @@
+package gorgonia
+
+/*
+This file holds the Ops that detect and sanitize NaNs and infinities.
+*/
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"math"
+	"reflect"
+
+	"github.com/chewxy/hm"
+	"github.com/pkg/errors"
+	"gorgonia.org/tensor"
+)
+
+type floatPredType byte
+
+const (
+	isNaNPred floatPredType = iota
+	isInfPred
+	isFinitePred
+)
+
+var floatPredNames = [...]string{
+	isNaNPred:    "IsNaN",
+	isInfPred:    "IsInf",
+	isFinitePred: "IsFinite",
+}
+
+var floatPredFns = [...]func(float64) bool{
+	isNaNPred:    math.IsNaN,
+	isInfPred:    func(a float64) bool { return math.IsInf(a, 0) },
+	isFinitePred: func(a float64) bool { return !math.IsNaN(a) && !math.IsInf(a, 0) },
+}
+
+func (p floatPredType) String() string { return floatPredNames[p] }
+
+// floatPredOp tests each element of a float value, returning a bool value of the same shape.
+type floatPredOp struct {
+	pred floatPredType
+	d    int
+}
+
+func (op floatPredOp) Arity() int { return 1 }
+
+// floatPredOp has either of these types:
+//		floatPredOp :: (Floats a) ⇒ Tensor a → Tensor Bool
+//		floatPredOp :: (Floats a) ⇒ a → Bool
+func (op floatPredOp) Type() hm.Type {
+	a := hm.TypeVariable('a')
+	if op.d == 0 {
+		return hm.NewFnType(a, Bool)
+	}
+	return hm.NewFnType(makeTensorType(op.d, a), makeTensorType(op.d, Bool))
+}
+
+func (op floatPredOp) InferShape(inputs ...DimSizer) (tensor.Shape, error) {
+	if err := checkArity(op, len(inputs)); err != nil {
+		return nil, err
+	}
+	s, ok := inputs[0].(tensor.Shape)
+	if !ok {
+		return nil, errors.Errorf("Expected a shape. Got %v of %T instead", inputs[0], inputs[0])
+	}
+	return s.Clone(), nil
+}
+
+func (op floatPredOp) Do(inputs ...Value) (retVal Value, err error) {
+	if err = checkArity(op, len(inputs)); err != nil {
+		return
+	}
+	fn := floatPredFns[op.pred]
+
+	switch v := inputs[0].(type) {
+	case *F64:
+		return newB(fn(float64(*v))), nil
+	case *F32:
+		return newB(fn(float64(*v))), nil
+	case tensor.Tensor:
+		if v.RequiresIterator() {
+			v = tensor.Materialize(v)
+		}
+		var ret []bool
+		switch data := v.Data().(type) {
+		case []float64:
+			ret = make([]bool, len(data))
+			for i, a := range data {
+				ret[i] = fn(a)
+			}
+		case []float32:
+			ret = make([]bool, len(data))
+			for i, a := range data {
+				ret[i] = fn(float64(a))
+			}
+		case float64:
+			ret = []bool{fn(data)}
+		case float32:
+			ret = []bool{fn(float64(data))}
+		default:
+			return nil, errors.Errorf(nyiFail, op, v.Dtype())
+		}
+		return tensor.New(tensor.WithShape(v.Shape().Clone()...), tensor.WithBacking(ret)), nil
+	}
+	return nil, errors.Errorf(nyiTypeFail, op, inputs[0])
+}
+
+func (op floatPredOp) ReturnsPtr() bool     { return false }
+func (op floatPredOp) CallsExtern() bool    { return false }
+func (op floatPredOp) OverwritesInput() int { return -1 }
+
+func (op floatPredOp) WriteHash(h hash.Hash) {
+	h.Write([]byte(op.pred.String()))
+	if err := binary.Write(h, binary.LittleEndian, byte(op.d)); err != nil {
+		panic(err)
+	}
+}
+
+func (op floatPredOp) Hashcode() uint32 { return simpleHash(op) }
+
+func (op floatPredOp) String() string { return op.pred.String() }
+
+func (op floatPredOp) DiffWRT(inputs int) []bool { return []bool{false} }
+
+func (op floatPredOp) SymDiff(inputs Nodes, output, grad *Node) (retVal Nodes, err error) {
+	return nil, nondiffErr(op)
+}
+
+// nanToNumOp replaces NaNs, positive infinities and negative infinities with the given values.
+type nanToNumOp struct {
+	nan, posInf, negInf float64
+	d                   int
+}
+
+func (op nanToNumOp) Arity() int { return 1 }
+
+// nanToNumOp has either of these types:
+//		nanToNumOp :: (Floats a) ⇒ Tensor a → Tensor a
+//		nanToNumOp :: (Floats a) ⇒ a → a
+func (op nanToNumOp) Type() hm.Type {
+	a := hm.TypeVariable('a')
+	if op.d == 0 {
+		return hm.NewFnType(a, a)
+	}
+	t := makeTensorType(op.d, a)
+	return hm.NewFnType(t, t)
+}
+
+func (op nanToNumOp) InferShape(inputs ...DimSizer) (tensor.Shape, error) {
+	if err := checkArity(op, len(inputs)); err != nil {
+		return nil, err
+	}
+	s, ok := inputs[0].(tensor.Shape)
+	if !ok {
+		return nil, errors.Errorf("Expected a shape. Got %v of %T instead", inputs[0], inputs[0])
+	}
+	return s.Clone(), nil
+}
+
+func (op nanToNumOp) replace(a float64) float64 {
+	switch {
+	case math.IsNaN(a):
+		return op.nan
+	case math.IsInf(a, 1):
+		return op.posInf
+	case math.IsInf(a, -1):
+		return op.negInf
+	}
+	return a
+}
+
+func (op nanToNumOp) Do(inputs ...Value) (retVal Value, err error) {
+	if err = checkArity(op, len(inputs)); err != nil {
+		return
+	}
+
+	switch v := inputs[0].(type) {
+	case *F64:
+		return newF64(op.replace(float64(*v))), nil
+	case *F32:
+		return newF32(float32(op.replace(float64(*v)))), nil
+	case tensor.Tensor:
+		var cloned Value
+		if cloned, err = CloneValue(v); err != nil {
+			return nil, errors.Wrapf(err, cloneFail, v)
+		}
+		ret := cloned.(tensor.Tensor)
+		if ret.RequiresIterator() {
+			ret = tensor.Materialize(ret)
+		}
+		switch data := ret.Data().(type) {
+		case []float64:
+			for i, a := range data {
+				data[i] = op.replace(a)
+			}
+		case []float32:
+			for i, a := range data {
+				data[i] = float32(op.replace(float64(a)))
+			}
+		case float64:
+			err = ret.SetAt(op.replace(data), make([]int, ret.Dims())...)
+		case float32:
+			err = ret.SetAt(float32(op.replace(float64(data))), make([]int, ret.Dims())...)
+		default:
+			return nil, errors.Errorf(nyiFail, op, v.Dtype())
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, opDoFail)
+		}
+		return ret, nil
+	}
+	return nil, errors.Errorf(nyiTypeFail, op, inputs[0])
+}
+
+func (op nanToNumOp) ReturnsPtr() bool     { return false }
+func (op nanToNumOp) CallsExtern() bool    { return false }
+func (op nanToNumOp) OverwritesInput() int { return -1 }
+
+func (op nanToNumOp) WriteHash(h hash.Hash) {
+	h.Write([]byte("nanToNum"))
+	if err := binary.Write(h, binary.LittleEndian, []float64{op.nan, op.posInf, op.negInf}); err != nil {
+		panic(err)
+	}
+	if err := binary.Write(h, binary.LittleEndian, byte(op.d)); err != nil {
+		panic(err)
+	}
+}
+
+func (op nanToNumOp) Hashcode() uint32 { return simpleHash(op) }
+
+func (op nanToNumOp) String() string {
+	return fmt.Sprintf("NanToNum(%v, %v, %v)", op.nan, op.posInf, op.negInf)
+}
+
+// DiffWRT returns true. The gradient is passed through for finite values, and is 0 for values that were replaced.
+func (op nanToNumOp) DiffWRT(inputs int) []bool { return []bool{true} }
+
+func (op nanToNumOp) SymDiff(inputs Nodes, output, grad *Node) (retVal Nodes, err error) {
+	if err = checkArity(op, len(inputs)); err != nil {
+		return
+	}
+	x := inputs[0]
+
+	var finite, mask, ret *Node
+	if finite, err = ApplyOp(floatPredOp{pred: isFinitePred, d: x.Dims()}, x); err != nil {
+		return nil, errors.Wrap(err, operationError)
+	}
+	if mask, err = Cast(finite, x.Dtype()); err != nil {
+		return nil, errors.Wrap(err, operationError)
+	}
+	if ret, err = HadamardProd(grad, mask); err != nil {
+		return nil, errors.Wrap(err, hadamardProdFail)
+	}
+	return Nodes{ret}, nil
+}
+
+// nanMask returns a mask of where either a or b is NaN. Scalars are broadcasted.
+func nanMask(a, b Value) (retVal []bool, err error) {
+	pred := floatPredOp{pred: isNaNPred}
+	var av, bv Value
+	if av, err = pred.Do(a); err != nil {
+		return nil, err
+	}
+	if bv, err = pred.Do(b); err != nil {
+		return nil, err
+	}
+	return logicalOp{ʘ: orOpType}.mask(av, bv)
+}
+
+// mask performs the logical op, returning the resulting bools.
+func (op logicalOp) mask(a, b Value) ([]bool, error) {
+	ret, err := op.Do(a, b)
+	if err != nil {
+		return nil, err
+	}
+	return boolsOf(ret)
+}
+
+// setNaNs sets the values of v to NaN wherever mask is true.
+func setNaNs(v Value, mask []bool) error {
+	t, ok := v.(tensor.Tensor)
+	if !ok {
+		return errors.Errorf(nyiTypeFail, "setNaNs", v)
+	}
+	switch data := t.Data().(type) {
+	case []float64:
+		for i, m := range mask {
+			if m {
+				data[i] = math.NaN()
+			}
+		}
+	case []float32:
+		for i, m := range mask {
+			if m {
+				data[i] = float32(math.NaN())
+			}
+		}
+	}
+	return nil
+}
+
+// nanMax is the NaN aware version of max along the given axes. If ignore is true, NaNs are skipped. Otherwise any NaN
+// in the values being reduced results in a NaN.
+func nanMax(v Value, along []int, ignore bool) (retVal Value, err error) {
+	var sv reflect.Value
+	if sv, err = valueSlice(v); err != nil {
+		return nil, err
+	}
+	var data interface{}
+	if data, err = castData(sv.Interface(), reflect.TypeOf(float64(0)), false); err != nil {
+		return nil, err
+	}
+	idx, size := reductionIndices(v.Shape(), along)
+
+	ret := make([]float64, size)
+	seen := make([]bool, size) // a value that is not NaN has been seen
+	nans := make([]bool, size) // a NaN has been propagated
+	for i, a := range data.([]float64) {
+		j := idx[i]
+		switch {
+		case math.IsNaN(a):
+			if !ignore {
+				ret[j] = a
+				nans[j] = true
+			}
+		case nans[j]:
+		case !seen[j] || a > ret[j]:
+			ret[j] = a
+			seen[j] = true
+		}
+	}
+
+	// the max of nothing but NaNs is NaN
+	for j := range ret {
+		if !seen[j] && !nans[j] {
+			ret[j] = math.NaN()
+		}
+	}
+
+	var shape tensor.Shape
+	if shape, err = reductionInferShape(along, v.Shape()); err != nil {
+		return nil, err
+	}
+	if shape.IsScalar() {
+		return castValue(newF64(ret[0]), v.Dtype(), false)
+	}
+	return castValue(tensor.New(tensor.WithShape(shape...), tensor.WithBacking(ret)), v.Dtype(), false)
+}
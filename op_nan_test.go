@@ -0,0 +1,150 @@
+package gorgonia
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorgonia.org/tensor"
+)
+
+func TestNaNOps(t *testing.T) {
+	assert := assert.New(t)
+	nan, inf := math.NaN(), math.Inf(1)
+
+	g := NewGraph()
+	x := NewVector(g, Float64, WithShape(4), WithName("x"), WithValue(tensor.New(tensor.WithBacking([]float64{1, nan, inf, -inf}))))
+	y := NewVector(g, Float32, WithShape(3), WithName("y"), WithValue(tensor.New(tensor.WithBacking([]float32{float32(nan), 2, float32(-inf)}))))
+	s := NewScalar(g, Float64, WithName("s"), WithValue(nan))
+
+	isNaN := Must(IsNaN(x))
+	isInf := Must(IsInf(x))
+	isFinite := Must(IsFinite(y))
+	sIsNaN := Must(IsNaN(s))
+	assert.Equal(Bool, isNaN.Dtype())
+	assert.True(sIsNaN.IsScalar())
+
+	clean := Must(NanToNum(x, 0, 100, -100))
+	cleanY := Must(NanToNum(y, -1, 0, 0))
+	cleanS := Must(NanToNum(s, 3, 0, 0))
+
+	m := NewTapeMachine(g)
+	defer m.Close()
+	if err := m.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]bool{false, true, false, false}, isNaN.Value().Data())
+	assert.Equal([]bool{false, false, true, true}, isInf.Value().Data())
+	assert.Equal([]bool{false, true, false}, isFinite.Value().Data())
+	assert.Equal(true, sIsNaN.Value().Data())
+	assert.Equal([]float64{1, 0, 100, -100}, clean.Value().Data())
+	assert.Equal([]float32{-1, 2, 0}, cleanY.Value().Data())
+	assert.Equal(3.0, cleanS.Value().Data())
+
+	// the input is not modified
+	assert.True(math.IsNaN(x.Value().Data().([]float64)[1]))
+
+	i := NewVector(g, Int, WithShape(4), WithName("i"))
+	_, err := IsNaN(i)
+	assert.Error(err)
+	_, err = NanToNum(i, 0, 0, 0)
+	assert.Error(err)
+}
+
+func TestNanToNumGrad(t *testing.T) {
+	assert := assert.New(t)
+	g := NewGraph()
+	x := NewVector(g, Float64, WithShape(3), WithName("x"), WithValue(tensor.New(tensor.WithBacking([]float64{2, math.NaN(), 3}))))
+	cost := Must(Sum(Must(Square(Must(NanToNum(x, 5, 0, 0))))))
+	if _, err := Grad(cost, x); err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewTapeMachine(g)
+	defer m.Close()
+	if err := m.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(38.0, cost.Value().Data())
+	xG, err := x.Grad()
+	if assert.NoError(err) {
+		assert.Equal([]float64{4, 0, 6}, xG.Data())
+	}
+}
+
+func TestNaNMode(t *testing.T) {
+	assert := assert.New(t)
+	nan := math.NaN()
+
+	run := func(mode NaNMode) (gt, gtSame, max, max0 Value, err error) {
+		g := NewGraph()
+		x := NewMatrix(g, Float64, WithShape(2, 2), WithName("x"), WithValue(tensor.New(tensor.WithShape(2, 2), tensor.WithBacking([]float64{1, nan, 3, 2}))))
+		y := NewMatrix(g, Float64, WithShape(2, 2), WithName("y"), WithValue(tensor.New(tensor.WithShape(2, 2), tensor.WithBacking([]float64{0, 0, 4, 1}))))
+		gtN := Must(Gt(x, y, false))
+		gtSameN := Must(Gt(x, y, true))
+		maxN := Must(Max(x, 1))
+		max0N := Must(Max(x))
+		Read(gtN, &gt)
+		Read(gtSameN, &gtSame)
+
+		m := NewTapeMachine(g, WithEngine(StandardEngine{}.WithNaNMode(mode)))
+		defer m.Close()
+		if err = m.RunAll(); err != nil {
+			return
+		}
+		return gt, gtSame, maxN.Value(), max0N.Value(), nil
+	}
+
+	// propagate
+	gt, gtSame, max, max0, err := run(NaNPropagate)
+	if assert.NoError(err) {
+		assert.Equal([]bool{true, false, false, true}, gt.Data())
+		same := gtSame.Data().([]float64)
+		assert.Equal(1.0, same[0])
+		assert.True(math.IsNaN(same[1]))
+		assert.Equal([]float64{0, 1}, same[2:])
+		maxs := max.Data().([]float64)
+		assert.True(math.IsNaN(maxs[0]))
+		assert.Equal(3.0, maxs[1])
+		assert.True(math.IsNaN(max0.Data().(float64)))
+	}
+
+	// ignore
+	_, _, max, max0, err = run(NaNIgnore)
+	if assert.NoError(err) {
+		assert.Equal([]float64{1, 3}, max.Data())
+		assert.Equal(3.0, max0.Data())
+	}
+
+	// error
+	_, _, _, _, err = run(NaNError)
+	assert.Error(err)
+
+	assert.Equal(NaNDefault, nanModeOf(newF64(1), tensor.New(tensor.WithBacking([]float64{1}))))
+}
+
+func TestNanMax(t *testing.T) {
+	assert := assert.New(t)
+	nan := float32(math.NaN())
+	v := tensor.New(tensor.WithShape(2, 3), tensor.WithBacking([]float32{nan, nan, nan, 1, nan, 2}))
+
+	ret, err := nanMax(v, []int{1}, true)
+	if assert.NoError(err) {
+		data := ret.Data().([]float32)
+		assert.True(math.IsNaN(float64(data[0])))
+		assert.Equal(float32(2), data[1])
+	}
+
+	ret, err = nanMax(v, []int{0}, true)
+	if assert.NoError(err) {
+		data := ret.Data().([]float32)
+		assert.Equal(float32(1), data[0])
+		assert.True(math.IsNaN(float64(data[1])))
+		assert.Equal(float32(2), data[2])
+	}
+
+	ret, err = nanMax(v, []int{0, 1}, false)
+	if assert.NoError(err) {
+		assert.True(math.IsNaN(float64(ret.Data().(float32))))
+	}
+}
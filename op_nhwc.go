@@ -0,0 +1,311 @@
+package gorgonia
+
+import (
+	"fmt"
+	"hash"
+
+	"github.com/pkg/errors"
+	"gorgonia.org/tensor"
+)
+
+// im2colNHWCOp is the im2col of a BHWC image. The columns of an output pixel are all the channels of the first pixel of
+// the kernel, then all the channels of the second one and so on, so that they are copied in contiguous runs of
+// channels. The result has the shape (b, h, w, kernel height × kernel width × c).
+type im2colNHWCOp struct {
+	im2colOp
+}
+
+func (op im2colNHWCOp) InferShape(shapes ...DimSizer) (retVal tensor.Shape, err error) {
+	if err = checkArity(op, len(shapes)); err != nil {
+		return
+	}
+	s, ok := shapes[0].(tensor.Shape)
+	if !ok {
+		return nil, errors.Errorf("expected tensor.Shape. got %T instead", shapes[0])
+	}
+	return op.calcShape(s), nil
+}
+
+func (op im2colNHWCOp) calcShape(s tensor.Shape) tensor.Shape {
+	retHeight, retWidth := op.retHW(s[1], s[2])
+	return tensor.Shape{s[0], retHeight, retWidth, op.h * op.w * s[3]}
+}
+
+func (op im2colNHWCOp) Do(inputs ...Value) (retVal Value, err error) {
+	if err = checkArity(op, len(inputs)); err != nil {
+		return
+	}
+	im := inputs[0]
+	if im.Shape().Dims() != 4 {
+		return nil, errors.Errorf("Expected a BHWC image. Got %v instead", im.Shape())
+	}
+	prealloc := tensor.New(tensor.Of(im.Dtype()), tensor.WithShape(op.calcShape(im.Shape())...))
+	return op.do(prealloc, im)
+}
+
+func (op im2colNHWCOp) UsePreallocDo(prealloc Value, inputs ...Value) (Value, error) {
+	if err := checkArity(op, len(inputs)); err != nil {
+		return nil, err
+	}
+	return op.do(prealloc, inputs[0])
+}
+
+func (op im2colNHWCOp) do(prealloc, input Value) (retVal Value, err error) {
+	s := input.Shape()
+	b, h, w, c := s[0], s[1], s[2], s[3]
+	retHeight, retWidth := op.retHW(h, w)
+	switch input.Dtype() {
+	case tensor.Float64:
+		op.f64s(b, h, w, c, retHeight, retWidth, input.Data().([]float64), prealloc.Data().([]float64))
+	case tensor.Float32:
+		op.f32s(b, h, w, c, retHeight, retWidth, input.Data().([]float32), prealloc.Data().([]float32))
+	default:
+		return nil, errors.Errorf(nyiFail, "im2col", input.Dtype())
+	}
+	return prealloc, nil
+}
+
+func (op im2colNHWCOp) f64s(b, height, width, chans, retHeight, retWidth int, im, col []float64) {
+	colIdx := 0
+	for i := 0; i < b; i++ {
+		img := im[i*height*width*chans : (i+1)*height*width*chans]
+		for outputRow := 0; outputRow < retHeight; outputRow++ {
+			for outputCol := 0; outputCol < retWidth; outputCol++ {
+				for kernelRow := 0; kernelRow < op.h; kernelRow++ {
+					inputRow := -op.padH + kernelRow*op.dilationH + outputRow*op.strideH
+					for kernelCol := 0; kernelCol < op.w; kernelCol++ {
+						inputCol := -op.padW + kernelCol*op.dilationW + outputCol*op.strideW
+						dst := col[colIdx : colIdx+chans]
+						if inputRow < 0 || inputRow >= height || inputCol < 0 || inputCol >= width {
+							for j := range dst {
+								dst[j] = 0
+							}
+						} else {
+							start := (inputRow*width + inputCol) * chans
+							copy(dst, img[start:start+chans])
+						}
+						colIdx += chans
+					}
+				}
+			}
+		}
+	}
+}
+
+func (op im2colNHWCOp) f32s(b, height, width, chans, retHeight, retWidth int, im, col []float32) {
+	colIdx := 0
+	for i := 0; i < b; i++ {
+		img := im[i*height*width*chans : (i+1)*height*width*chans]
+		for outputRow := 0; outputRow < retHeight; outputRow++ {
+			for outputCol := 0; outputCol < retWidth; outputCol++ {
+				for kernelRow := 0; kernelRow < op.h; kernelRow++ {
+					inputRow := -op.padH + kernelRow*op.dilationH + outputRow*op.strideH
+					for kernelCol := 0; kernelCol < op.w; kernelCol++ {
+						inputCol := -op.padW + kernelCol*op.dilationW + outputCol*op.strideW
+						dst := col[colIdx : colIdx+chans]
+						if inputRow < 0 || inputRow >= height || inputCol < 0 || inputCol >= width {
+							for j := range dst {
+								dst[j] = 0
+							}
+						} else {
+							start := (inputRow*width + inputCol) * chans
+							copy(dst, img[start:start+chans])
+						}
+						colIdx += chans
+					}
+				}
+			}
+		}
+	}
+}
+
+func (op im2colNHWCOp) WriteHash(h hash.Hash) {
+	fmt.Fprintf(h, "im2colNHWC:%d-%d-%d-%d-%d-%d-%d-%d", op.h, op.w, op.padH, op.padW, op.strideH, op.strideW, op.dilationH, op.dilationW)
+}
+
+func (op im2colNHWCOp) Hashcode() uint32 { return simpleHash(op) }
+
+func (op im2colNHWCOp) String() string {
+	return fmt.Sprintf("im2colNHWC<(%d,%d), (%d, %d), (%d,%d) (%d, %d)>", op.h, op.w, op.padH, op.padW, op.strideH, op.strideW, op.dilationH, op.dilationW)
+}
+
+func (op im2colNHWCOp) SymDiff(inputs Nodes, output, grad *Node) (retVal Nodes, err error) {
+	if err = checkArity(op, len(inputs)); err != nil {
+		return
+	}
+	s := inputs[0].Shape()
+	if s.Dims() != 4 {
+		return nil, errors.Errorf("Expected input to have a shape with 4 dims")
+	}
+	diffOp := col2imNHWCOp{
+		unpaddedB: s[0],
+		unpaddedH: s[1],
+		unpaddedW: s[2],
+		unpaddedC: s[3],
+
+		im2colNHWCOp: op,
+	}
+	var ret *Node
+	if ret, err = ApplyOp(diffOp, grad); err != nil {
+		return
+	}
+	ret.layout = NHWC
+	return Nodes{ret}, nil
+}
+
+func (op im2colNHWCOp) DoDiff(ctx ExecutionContext, inputs Nodes, output *Node) (err error) {
+	if err = checkArity(op, len(inputs)); err != nil {
+		return
+	}
+	s := inputs[0].Shape()
+	imv, colv := getDV(inputs[0], output)
+	diffOp := col2imNHWCOp{
+		unpaddedB: s[0],
+		unpaddedH: s[1],
+		unpaddedW: s[2],
+		unpaddedC: s[3],
+
+		im2colNHWCOp: op,
+	}
+	if _, err = diffOp.UsePreallocDo(imv.d, colv.d); err != nil {
+		return errors.Wrapf(err, doFail, diffOp)
+	}
+	return
+}
+
+// col2imNHWCOp is the transpose of im2colNHWCOp: it sums the columns back into a BHWC image.
+type col2imNHWCOp struct {
+	// input shapes of im2col
+	unpaddedB int
+	unpaddedH int
+	unpaddedW int
+	unpaddedC int
+
+	im2colNHWCOp
+}
+
+func (op col2imNHWCOp) InferShape(shapes ...DimSizer) (retVal tensor.Shape, err error) {
+	return tensor.Shape{op.unpaddedB, op.unpaddedH, op.unpaddedW, op.unpaddedC}, nil
+}
+
+func (op col2imNHWCOp) Do(inputs ...Value) (retVal Value, err error) {
+	if err = checkArity(op, len(inputs)); err != nil {
+		return
+	}
+	col := inputs[0]
+	prealloc := tensor.New(tensor.Of(col.Dtype()), tensor.WithShape(op.unpaddedB, op.unpaddedH, op.unpaddedW, op.unpaddedC))
+	return op.do(prealloc, col)
+}
+
+func (op col2imNHWCOp) UsePreallocDo(prealloc Value, inputs ...Value) (Value, error) {
+	if err := checkArity(op, len(inputs)); err != nil {
+		return nil, err
+	}
+	return op.do(prealloc, inputs[0])
+}
+
+func (op col2imNHWCOp) do(prealloc, input Value) (retVal Value, err error) {
+	want := op.im2colNHWCOp.calcShape(tensor.Shape{op.unpaddedB, op.unpaddedH, op.unpaddedW, op.unpaddedC})
+	if !input.Shape().Eq(want) {
+		return nil, errors.Errorf("Expected columns of shape %v. Got %v instead", want, input.Shape())
+	}
+	switch input.Dtype() {
+	case tensor.Float64:
+		op.f64s(input.Data().([]float64), prealloc.Data().([]float64))
+	case tensor.Float32:
+		op.f32s(input.Data().([]float32), prealloc.Data().([]float32))
+	default:
+		return nil, errors.Errorf(nyiFail, "col2im", input.Dtype())
+	}
+	return prealloc, nil
+}
+
+func (op col2imNHWCOp) f64s(col, im []float64) {
+	for i := range im {
+		im[i] = 0
+	}
+	height, width, chans := op.unpaddedH, op.unpaddedW, op.unpaddedC
+	retHeight, retWidth := op.retHW(height, width)
+	colIdx := 0
+	for i := 0; i < op.unpaddedB; i++ {
+		img := im[i*height*width*chans : (i+1)*height*width*chans]
+		for outputRow := 0; outputRow < retHeight; outputRow++ {
+			for outputCol := 0; outputCol < retWidth; outputCol++ {
+				for kernelRow := 0; kernelRow < op.h; kernelRow++ {
+					inputRow := -op.padH + kernelRow*op.dilationH + outputRow*op.strideH
+					for kernelCol := 0; kernelCol < op.w; kernelCol++ {
+						inputCol := -op.padW + kernelCol*op.dilationW + outputCol*op.strideW
+						if inputRow >= 0 && inputRow < height && inputCol >= 0 && inputCol < width {
+							dst := img[(inputRow*width+inputCol)*chans:]
+							for j, v := range col[colIdx : colIdx+chans] {
+								dst[j] += v
+							}
+						}
+						colIdx += chans
+					}
+				}
+			}
+		}
+	}
+}
+
+func (op col2imNHWCOp) f32s(col, im []float32) {
+	for i := range im {
+		im[i] = 0
+	}
+	height, width, chans := op.unpaddedH, op.unpaddedW, op.unpaddedC
+	retHeight, retWidth := op.retHW(height, width)
+	colIdx := 0
+	for i := 0; i < op.unpaddedB; i++ {
+		img := im[i*height*width*chans : (i+1)*height*width*chans]
+		for outputRow := 0; outputRow < retHeight; outputRow++ {
+			for outputCol := 0; outputCol < retWidth; outputCol++ {
+				for kernelRow := 0; kernelRow < op.h; kernelRow++ {
+					inputRow := -op.padH + kernelRow*op.dilationH + outputRow*op.strideH
+					for kernelCol := 0; kernelCol < op.w; kernelCol++ {
+						inputCol := -op.padW + kernelCol*op.dilationW + outputCol*op.strideW
+						if inputRow >= 0 && inputRow < height && inputCol >= 0 && inputCol < width {
+							dst := img[(inputRow*width+inputCol)*chans:]
+							for j, v := range col[colIdx : colIdx+chans] {
+								dst[j] += v
+							}
+						}
+						colIdx += chans
+					}
+				}
+			}
+		}
+	}
+}
+
+func (op col2imNHWCOp) WriteHash(h hash.Hash) {
+	fmt.Fprintf(h, "col2imNHWC:%d-%d-%d-%d-%d-%d-%d-%d:%d-%d", op.h, op.w, op.padH, op.padW, op.strideH, op.strideW, op.dilationH, op.dilationW, op.unpaddedH, op.unpaddedW)
+}
+
+func (op col2imNHWCOp) Hashcode() uint32 { return simpleHash(op) }
+
+func (op col2imNHWCOp) String() string {
+	return fmt.Sprintf("col2imNHWC<(%d,%d), (%d, %d), (%d,%d) (%d, %d)>", op.h, op.w, op.padH, op.padW, op.strideH, op.strideW, op.dilationH, op.dilationW)
+}
+
+func (op col2imNHWCOp) SymDiff(inputs Nodes, output, grad *Node) (retVal Nodes, err error) {
+	if err = checkArity(op, len(inputs)); err != nil {
+		return
+	}
+	var ret *Node
+	if ret, err = ApplyOp(op.im2colNHWCOp, grad); err != nil {
+		return
+	}
+	return Nodes{ret}, nil
+}
+
+func (op col2imNHWCOp) DoDiff(ctx ExecutionContext, inputs Nodes, output *Node) (err error) {
+	if err = checkArity(op, len(inputs)); err != nil {
+		return
+	}
+	colv, imv := getDV(inputs[0], output)
+	if _, err = op.im2colNHWCOp.do(colv.d, imv.d); err != nil {
+		return errors.Wrapf(err, doFail, op.im2colNHWCOp)
+	}
+	return
+}
@@ -40,14 +40,31 @@ const (
 	uniform randomness = iota
 	gaussian
 	binomial
+	bernoulli
 )
 
+func (r randomness) String() string {
+	switch r {
+	case uniform:
+		return "uniform"
+	case gaussian:
+		return "gaussian"
+	case binomial:
+		return "binomial"
+	case bernoulli:
+		return "bernoulli"
+	}
+	return fmt.Sprintf("randomness(%d)", byte(r))
+}
+
 type randomOp struct {
 	which randomness
 	shape tensor.Shape
 	dt    tensor.Dtype
 
 	a, b float64 // when uniform, a,b = low, high; when gaussian, a,b = mean, stdev
+
+	src *rngSource // if nil, the values are drawn from a generator seeded with the current time
 }
 
 func makeRandomOp(which randomness, dt tensor.Dtype, a, b float64, shape ...int) randomOp {
@@ -75,6 +92,9 @@ func (op randomOp) Type() hm.Type {
 func (op randomOp) InferShape(...DimSizer) (tensor.Shape, error) { return op.shape, nil }
 
 func (op randomOp) Do(...Value) (retVal Value, err error) {
+	if op.src != nil {
+		return op.draw()
+	}
 	if op.shape.IsScalar() {
 		var v interface{}
 		switch op.dt {
@@ -145,13 +165,22 @@ func (op randomOp) Do(...Value) (retVal Value, err error) {
 func (op randomOp) ReturnsPtr() bool     { return false }
 func (op randomOp) CallsExtern() bool    { return false }
 func (op randomOp) OverwritesInput() int { return -1 }
+
+// IsStateful is true: a random op draws new numbers on every run.
+func (op randomOp) IsStateful() bool { return true }
 func (op randomOp) WriteHash(h hash.Hash) {
 	fmt.Fprintf(h, "%d%v%f%f", op.which, op.shape, op.a, op.b)
+	if op.src != nil {
+		fmt.Fprintf(h, "seed%d", op.src.seed)
+	}
 }
 
 func (op randomOp) Hashcode() uint32 { return simpleHash(op) }
 
 func (op randomOp) String() string {
+	if op.src != nil {
+		return fmt.Sprintf("%v(%v, %v; seed %d) - %v", op.which, op.a, op.b, op.src.seed, op.shape)
+	}
 	return fmt.Sprintf("%v(%v, %v) - %v", op.which, op.a, op.b, op.shape)
 }
 
@@ -215,7 +244,7 @@ func (op im2colOp) CallsExtern() bool    { return false }
 func (op im2colOp) OverwritesInput() int { return -1 }
 
 func (op im2colOp) WriteHash(h hash.Hash) {
-	fmt.Fprintf(h, "im2col:%d-%d-%d-%d-%d-%d", op.h, op.w, op.padH, op.padW, op.strideH, op.strideW)
+	fmt.Fprintf(h, "im2col:%d-%d-%d-%d-%d-%d-%d-%d", op.h, op.w, op.padH, op.padW, op.strideH, op.strideW, op.dilationH, op.dilationW)
 }
 
 func (op im2colOp) Hashcode() uint32 { return simpleHash(op) }
@@ -469,13 +498,13 @@ func (op col2imOp) CallsExtern() bool    { return false }
 func (op col2imOp) OverwritesInput() int { return -1 }
 
 func (op col2imOp) WriteHash(h hash.Hash) {
-	fmt.Fprintf(h, "col2im:%d-%d-%d-%d-%d-%d", op.h, op.w, op.padH, op.padW, op.strideH, op.strideW)
+	fmt.Fprintf(h, "col2im:%d-%d-%d-%d-%d-%d-%d-%d:%d-%d", op.h, op.w, op.padH, op.padW, op.strideH, op.strideW, op.dilationH, op.dilationW, op.unpaddedH, op.unpaddedW)
 }
 
 func (op col2imOp) Hashcode() uint32 { return simpleHash(op) }
 
 func (op col2imOp) String() string {
-	return fmt.Sprintf("col2im<(%d,%d), (%d, %d), (%d,%d)>", op.h, op.w, op.padH, op.padW, op.strideH, op.strideW)
+	return fmt.Sprintf("col2im<(%d,%d), (%d, %d), (%d,%d) (%d, %d)>", op.h, op.w, op.padH, op.padW, op.strideH, op.strideW, op.dilationH, op.dilationW)
 }
 
 func (op col2imOp) UsePreallocDo(prealloc Value, inputs ...Value) (Value, error) {
@@ -485,6 +514,33 @@ func (op col2imOp) UsePreallocDo(prealloc Value, inputs ...Value) (Value, error)
 	return op.do(prealloc, inputs[0])
 }
 
+func (op col2imOp) DiffWRT(i int) []bool { return []bool{true} }
+
+// SymDiff of col2im is the im2col of the gradient, as col2im is the transpose of im2col.
+func (op col2imOp) SymDiff(inputs Nodes, output, grad *Node) (retVal Nodes, err error) {
+	if err = checkArity(op, len(inputs)); err != nil {
+		return
+	}
+	var ret *Node
+	if ret, err = ApplyOp(op.im2colOp, grad); err != nil {
+		return
+	}
+	retVal = Nodes{ret}
+	return
+}
+
+func (op col2imOp) DoDiff(ctx ExecutionContext, inputs Nodes, output *Node) (err error) {
+	if err = checkArity(op, len(inputs)); err != nil {
+		return
+	}
+
+	colv, imv := getDV(inputs[0], output)
+	if _, err = op.im2colOp.do(colv.d, imv.d); err != nil {
+		return errors.Wrapf(err, doFail, op.im2colOp)
+	}
+	return
+}
+
 func (op col2imOp) do(prealloc, input Value) (retVal Value, err error) {
 	b := op.unpaddedB
 	c := op.unpaddedC
@@ -689,6 +745,9 @@ func (op *maxPoolOp) Do(inputs ...Value) (retVal Value, err error) {
 	if in, err = op.checkInput(inputs...); err != nil {
 		return nil, err
 	}
+	if nanModeOf(in) == NaNError && hasNaN(in, CPU) {
+		return nil, errors.Errorf("NaN encountered in %v", op)
+	}
 	inShp := in.Shape()
 	out = tensor.New(tensor.Of(in.Dtype()), tensor.WithShape(op.calcShape(inShp)...), tensor.WithEngine(in.Engine()))
 	op.do(out, in)
@@ -719,6 +778,9 @@ func (op *maxPoolOp) UsePreallocDo(prealloc Value, inputs ...Value) (Value, erro
 		return nil, err
 	}
 
+	if nanModeOf(in) == NaNError && hasNaN(in, CPU) {
+		return nil, errors.Errorf("NaN encountered in %v", op)
+	}
 	if p, ok := prealloc.(tensor.Tensor); ok {
 		op.do(p, in)
 		return p, nil
@@ -1173,6 +1235,9 @@ func (op *BatchNormOp) CallsExtern() bool { return false }
 // OverwritesInput is -1 (operator doesn't overwrite any input value)
 func (op *BatchNormOp) OverwritesInput() int { return -1 }
 
+// IsStateful is true: the moving statistics are updated when training
+func (op *BatchNormOp) IsStateful() bool { return true }
+
 // WriteHash ...
 func (op *BatchNormOp) WriteHash(h hash.Hash) {
 	fmt.Fprintf(h, "batchnorm-%1.1f-%1.1f", op.momentum, op.epsilon)
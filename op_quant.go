@@ -0,0 +1,294 @@
+package gorgonia
+
+/*
+This file holds the Op of QuantizedMatMul, whose kernels dequantize the weight on the fly, group by group, or multiply
+its quants by the quants of the activations quantized when the op is run.
+*/
+
+import (
+	"fmt"
+	"hash"
+	"math"
+	"reflect"
+
+	"github.com/chewxy/hm"
+	"github.com/chewxy/math32"
+	"github.com/pkg/errors"
+	"gorgonia.org/tensor"
+)
+
+// quantMatMulOp multiplies x by the transpose of a quantized weight W of shape (out, in), whose groups are of the given
+// size. Its inputs are x, the quants, the scales and, if mins is true, the mins of W. The transposed op multiplies x by
+// W instead, which is the derivative of the other with regards to x. If actGroup is not 0, x is quantized by groups of
+// actGroup before being multiplied.
+type quantMatMulOp struct {
+	group      int
+	mins       bool
+	transposed bool
+	d          int // the dims of x
+	actGroup   int
+}
+
+func (op quantMatMulOp) Arity() int {
+	if op.mins {
+		return 4
+	}
+	return 3
+}
+
+// quantMatMulOp has one of these types:
+//
+//	quantMatMulOp :: Tensor-d Float32 → Matrix Int8 → Matrix Float32 → Tensor-d Float32
+//	quantMatMulOp :: Tensor-d Float32 → Matrix Int8 → Matrix Float32 → Matrix Float32 → Tensor-d Float32
+func (op quantMatMulOp) Type() hm.Type {
+	x := makeTensorType(op.d, tensor.Float32)
+	scales := makeTensorType(2, tensor.Float32)
+	ts := []hm.Type{x, makeTensorType(2, tensor.Int8), scales}
+	if op.mins {
+		ts = append(ts, scales)
+	}
+	return hm.NewFnType(append(ts, x)...)
+}
+
+func (op quantMatMulOp) InferShape(inputs ...DimSizer) (tensor.Shape, error) {
+	if err := checkArity(op, len(inputs)); err != nil {
+		return nil, err
+	}
+	x, ok1 := inputs[0].(tensor.Shape)
+	w, ok2 := inputs[1].(tensor.Shape)
+	if !ok1 || !ok2 {
+		return nil, errors.Errorf("Expected the shapes of x and of a matrix. Got %v and %v instead", inputs[0], inputs[1])
+	}
+	return op.outShape(x, w)
+}
+
+func (op quantMatMulOp) outShape(x, w tensor.Shape) (tensor.Shape, error) {
+	if len(w) != 2 {
+		return nil, errors.Errorf("Expected the shape of a matrix. Got %v instead", w)
+	}
+	in, out := w[1], w[0]
+	if op.transposed {
+		in, out = out, in
+	}
+	if len(x) == 0 || x[len(x)-1] != in {
+		return nil, errors.Errorf("Shape mismatch: cannot multiply %v by the quantized weight of the shape %v", x, w)
+	}
+	retVal := x.Clone()
+	retVal[len(retVal)-1] = out
+	return retVal, nil
+}
+
+func (op quantMatMulOp) Do(inputs ...Value) (retVal Value, err error) {
+	if err = checkArity(op, len(inputs)); err != nil {
+		return
+	}
+	var data [4]interface{}
+	for i, v := range inputs {
+		var r reflect.Value
+		if r, err = valueSlice(v); err != nil {
+			return nil, errors.Wrap(err, opDoFail)
+		}
+		data[i] = r.Interface()
+	}
+	x, ok1 := data[0].([]float32)
+	quants, ok2 := data[1].([]int8)
+	scales, ok3 := data[2].([]float32)
+	if !ok1 || !ok2 || !ok3 {
+		return nil, errors.Errorf("Expected Float32, Int8 and Float32 values. Got %T, %T and %T instead", data[0], data[1], data[2])
+	}
+	var mins []float32
+	if op.mins {
+		if mins, ok1 = data[3].([]float32); !ok1 {
+			return nil, errors.Errorf("Expected the Float32 mins. Got %T instead", data[3])
+		}
+	}
+	var shape tensor.Shape
+	if shape, err = op.outShape(inputs[0].Shape(), inputs[1].Shape()); err != nil {
+		return nil, errors.Wrap(err, opDoFail)
+	}
+	w := inputs[1].Shape()
+	if len(quants) != w.TotalSize() || len(scales)*op.group != len(quants) || (mins != nil && len(mins) != len(scales)) || w[1]%op.group != 0 {
+		return nil, errors.Errorf("The groups of %d do not match the quants of the shape %v", op.group, w)
+	}
+
+	out := make([]float32, shape.TotalSize())
+	qw := quantWeight{quants: quants, scales: scales, mins: mins, group: op.group, in: w[1]}
+	switch {
+	case op.transposed:
+		qw.mulT(x, out, w[0])
+	case op.actGroup > 0:
+		xq, xs := quantizeActivations(x, op.actGroup)
+		qw.mulQ(xq, xs, op.actGroup, out, w[0])
+	default:
+		qw.mul(x, out, w[0])
+	}
+	return tensor.New(tensor.WithShape(shape...), tensor.WithBacking(out)), nil
+}
+
+func (op quantMatMulOp) ReturnsPtr() bool     { return false }
+func (op quantMatMulOp) CallsExtern() bool    { return false }
+func (op quantMatMulOp) OverwritesInput() int { return -1 }
+
+func (op quantMatMulOp) WriteHash(h hash.Hash) {
+	fmt.Fprintf(h, "QuantizedMatMul %d %t %t %d %d", op.group, op.mins, op.transposed, op.d, op.actGroup)
+}
+
+func (op quantMatMulOp) Hashcode() uint32 { return simpleHash(op) }
+
+func (op quantMatMulOp) String() string {
+	if op.transposed {
+		return fmt.Sprintf("QuantizedMatMulᵀ(%d)", op.group)
+	}
+	if op.actGroup > 0 {
+		return fmt.Sprintf("QuantizedMatMul(%d, %d)", op.group, op.actGroup)
+	}
+	return fmt.Sprintf("QuantizedMatMul(%d)", op.group)
+}
+
+// DiffWRT returns true for x: the quantized weight is not differentiable.
+func (op quantMatMulOp) DiffWRT(inputs int) []bool {
+	retVal := make([]bool, inputs)
+	retVal[0] = true
+	return retVal
+}
+
+func (op quantMatMulOp) SymDiff(inputs Nodes, output, grad *Node) (retVal Nodes, err error) {
+	if err = checkArity(op, len(inputs)); err != nil {
+		return
+	}
+	diff := op
+	diff.transposed = !op.transposed
+	diff.d = grad.Dims()
+	diff.actGroup = 0 // the gradient is not quantized
+	var dx *Node
+	if dx, err = ApplyOp(diff, append(Nodes{grad}, inputs[1:]...)...); err != nil {
+		return nil, errors.Wrap(err, operationError)
+	}
+	retVal = make(Nodes, len(inputs))
+	retVal[0] = dx
+	return retVal, nil
+}
+
+// quantWeight is a quantized weight of shape (out, in), whose rows are made of in/group groups.
+type quantWeight struct {
+	quants       []int8
+	scales, mins []float32
+	group, in    int
+}
+
+// mul computes the rows of out = x×Wᵀ, where the rows of x are of length in, and those of out of length n.
+func (w quantWeight) mul(x, out []float32, n int) {
+	groups := w.in / w.group
+	for r := 0; r < len(x)/w.in; r++ {
+		xr := x[r*w.in : (r+1)*w.in]
+		// the sums of the groups of the row, by which the mins are multiplied
+		var sums []float32
+		if w.mins != nil {
+			sums = make([]float32, groups)
+			for g := range sums {
+				for _, v := range xr[g*w.group : (g+1)*w.group] {
+					sums[g] += v
+				}
+			}
+		}
+		for j := 0; j < n; j++ {
+			q := w.quants[j*w.in : (j+1)*w.in]
+			var acc float32
+			for g := 0; g < groups; g++ {
+				var dot float32
+				for k := g * w.group; k < (g+1)*w.group; k++ {
+					dot += xr[k] * float32(q[k])
+				}
+				acc += w.scales[j*groups+g] * dot
+				if sums != nil {
+					acc += w.mins[j*groups+g] * sums[g]
+				}
+			}
+			out[r*n+j] = acc
+		}
+	}
+}
+
+// mulT computes the rows of out = x×W, where the rows of x are of length n, and those of out of length in.
+func (w quantWeight) mulT(x, out []float32, n int) {
+	groups := w.in / w.group
+	for r := 0; r < len(x)/n; r++ {
+		or := out[r*w.in : (r+1)*w.in]
+		for j, v := range x[r*n : (r+1)*n] {
+			if v == 0 {
+				continue
+			}
+			q := w.quants[j*w.in : (j+1)*w.in]
+			for g := 0; g < groups; g++ {
+				scale := v * w.scales[j*groups+g]
+				var off float32
+				if w.mins != nil {
+					off = v * w.mins[j*groups+g]
+				}
+				for k := g * w.group; k < (g+1)*w.group; k++ {
+					or[k] += scale*float32(q[k]) + off
+				}
+			}
+		}
+	}
+}
+
+// mulQ computes the rows of out = x×Wᵀ as mul does, of the activations quantized by groups of ag: the quants of x and
+// of W are multiplied in int32, by chunks that are within a group of both.
+func (w quantWeight) mulQ(xq []int8, xs []float32, ag int, out []float32, n int) {
+	groups := w.in / w.group
+	chunk := w.group
+	if ag < chunk {
+		chunk = ag
+	}
+	for r := 0; r < len(xq)/w.in; r++ {
+		xr := xq[r*w.in : (r+1)*w.in]
+		xsr := xs[r*w.in/ag : (r+1)*w.in/ag]
+		var sums []float32
+		if w.mins != nil {
+			sums = make([]float32, groups)
+			for k := 0; k < w.in; k += chunk {
+				var sum int32
+				for _, v := range xr[k : k+chunk] {
+					sum += int32(v)
+				}
+				sums[k/w.group] += xsr[k/ag] * float32(sum)
+			}
+		}
+		for j := 0; j < n; j++ {
+			q := w.quants[j*w.in : (j+1)*w.in]
+			var acc float32
+			for k := 0; k < w.in; k += chunk {
+				var dot int32
+				for l := k; l < k+chunk; l++ {
+					dot += int32(xr[l]) * int32(q[l])
+				}
+				acc += w.scales[j*groups+k/w.group] * xsr[k/ag] * float32(dot)
+			}
+			for g := range sums {
+				acc += w.mins[j*groups+g] * sums[g]
+			}
+			out[r*n+j] = acc
+		}
+	}
+}
+
+// quantizeActivations quantizes x symmetrically by groups of group, and returns the quants and the scales.
+func quantizeActivations(x []float32, group int) (quants []int8, scales []float32) {
+	quants = make([]int8, len(x))
+	scales = make([]float32, len(x)/group)
+	for g := range scales {
+		var amax float32
+		for _, v := range x[g*group : (g+1)*group] {
+			amax = math32.Max(amax, math32.Abs(v))
+		}
+		if amax == 0 {
+			continue
+		}
+		scales[g] = amax / 127
+		for i := g * group; i < (g+1)*group; i++ {
+			quants[i] = int8(math.Round(float64(x[i] / scales[g])))
+		}
+	}
+	return quants, scales
+}
@@ -0,0 +1,162 @@
+package gorgonia
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorgonia.org/tensor"
+)
+
+func TestQuantizedMatMul(t *testing.T) {
+	assert := assert.New(t)
+	for _, withMins := range []bool{false, true} {
+		g := NewGraph()
+		// a weight of (out, in) = (3, 4), in groups of 2
+		q, err := Quantize(tensor.New(tensor.WithShape(3, 4), tensor.WithBacking([]float32{
+			1, -2, 0.5, 4,
+			0, 3, -1, 1,
+			2, 2, -3, 0.25,
+		})), 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if withMins {
+			q.Mins = tensor.New(tensor.WithShape(3, 2), tensor.WithBacking([]float32{0.5, 0, -1, 0, 0, 2}))
+		}
+		w, err := NewQuantizedWeight(g, q, "w")
+		if err != nil {
+			t.Fatal(err)
+		}
+		xv := tensor.New(tensor.WithShape(2, 4), tensor.WithBacking([]float32{1, 2, 3, 4, -1, 0.5, 0, 2}))
+		x := NewMatrix(g, Float32, WithShape(2, 4), WithName("x"), WithValue(xv))
+		v := NewVector(g, Float32, WithShape(4), WithName("v"), WithValue(tensor.New(tensor.WithShape(4), tensor.WithBacking([]float32{1, 2, 3, 4}))))
+		y, err := QuantizedMatMul(x, w)
+		if err != nil {
+			t.Fatal(err)
+		}
+		yv, err := QuantizedMatMul(v, w)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(tensor.Shape{2, 3}, y.Shape())
+		assert.Equal(tensor.Shape{3}, yv.Shape())
+		// the gradient of Σ c⊙y is c×W
+		c := NewMatrix(g, Float32, WithShape(2, 3), WithName("c"), WithValue(tensor.New(tensor.WithShape(2, 3), tensor.WithBacking([]float32{1, 0, -1, 2, 1, 0.5}))))
+		grads, err := Grad(Must(Sum(Must(HadamardProd(y, c)))), x)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		m := NewTapeMachine(g)
+		if err = m.RunAll(); err != nil {
+			t.Fatal(err)
+		}
+		m.Close()
+
+		wd, err := q.Dequantize()
+		if err != nil {
+			t.Fatal(err)
+		}
+		wt := wd.Clone().(*tensor.Dense)
+		if err = wt.T(); err != nil {
+			t.Fatal(err)
+		}
+		want, err := tensor.MatMul(xv, wt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.InDeltaSlice(want.Data(), y.Value().Data(), 1e-5, "mins: %t", withMins)
+		assert.InDeltaSlice(want.Data().([]float32)[:3], yv.Value().Data(), 1e-5, "the vector of the first row")
+		wantGrad, err := tensor.MatMul(c.Value().(tensor.Tensor), wd)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.InDeltaSlice(wantGrad.Data(), grads[0].Value().Data(), 1e-5, "mins: %t", withMins)
+	}
+}
+
+func TestQuantizedMatMul_errors(t *testing.T) {
+	assert := assert.New(t)
+	g := NewGraph()
+	q, _ := Quantize(tensor.New(tensor.WithShape(3, 4), tensor.WithBacking(make([]float32, 12))), 4)
+	w, err := NewQuantizedWeight(g, q, "w")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = QuantizedMatMul(NewMatrix(g, Float32, WithShape(2, 3)), w)
+	assert.Error(err, "a shape mismatch")
+	_, err = QuantizedMatMul(NewMatrix(g, Float64, WithShape(2, 4)), w)
+	assert.Error(err, "not Float32")
+	_, err = QuantizedMatMul(NewTensor(g, Float32, 3, WithShape(1, 2, 4)), w)
+	assert.Error(err, "not a matrix")
+	_, err = QuantizedMatMul(NewMatrix(g, Float32, WithShape(2, 4)), &QuantizedWeight{Quants: w.Quants})
+	assert.Error(err, "no scales")
+}
+
+func TestQuantizedMatMul_activationGroups(t *testing.T) {
+	assert := assert.New(t)
+	wv := []float32{
+		1, -2, 0.5, 4, 0, 3, -1, 1,
+		2, 2, -3, 0.25, 1, -1, 0.5, 0,
+	}
+	xs := []float32{1, 2, 3, 4, -1, 0.5, 0, 2, 0.1, -0.2, 0.3, 0, 5, -4, 1, 1}
+	for _, groups := range [][2]int{{4, 2}, {2, 4}, {8, 8}} {
+		wg, ag := groups[0], groups[1]
+		g := NewGraph()
+		q, err := Quantize(tensor.New(tensor.WithShape(2, 8), tensor.WithBacking(wv)), wg, WithZeroPoints())
+		if err != nil {
+			t.Fatal(err)
+		}
+		w, err := NewQuantizedWeight(g, q, "w")
+		if err != nil {
+			t.Fatal(err)
+		}
+		xv := tensor.New(tensor.WithShape(2, 8), tensor.WithBacking(xs))
+		x := NewMatrix(g, Float32, WithShape(2, 8), WithName("x"), WithValue(xv))
+		y, err := QuantizedMatMul(x, w, WithActivationGroups(ag))
+		if err != nil {
+			t.Fatal(err)
+		}
+		grads, err := Grad(Must(Sum(y)), x)
+		if err != nil {
+			t.Fatal(err)
+		}
+		m := NewTapeMachine(g)
+		if err = m.RunAll(); err != nil {
+			t.Fatal(err)
+		}
+		m.Close()
+
+		// the product of the dequantized activations and weight
+		xq, scales := quantizeActivations(xs, ag)
+		xd := make([]float32, len(xs))
+		for i, v := range xq {
+			xd[i] = scales[i/ag] * float32(v)
+		}
+		wd, _ := q.Dequantize()
+		wt := wd.Clone().(*tensor.Dense)
+		if err = wt.T(); err != nil {
+			t.Fatal(err)
+		}
+		want, err := tensor.MatMul(tensor.New(tensor.WithShape(2, 8), tensor.WithBacking(xd)), wt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.InDeltaSlice(want.Data(), y.Value().Data(), 1e-4, "groups %v", groups)
+		exact, _ := tensor.MatMul(xv, tensor.New(tensor.WithShape(8, 2), tensor.WithBacking([]float32{1, 2, -2, 2, 0.5, -3, 4, 0.25, 0, 1, 3, -1, -1, 0.5, 1, 0})))
+		assert.InDeltaSlice(exact.Data(), y.Value().Data(), 0.5, "close to the float product; groups %v", groups)
+		// the straight-through gradient: the sums of the columns of W, for each row of x
+		colSums, _ := tensor.Sum(wd, 0)
+		cs := colSums.Data().([]float32)
+		assert.InDeltaSlice(append(append([]float32{}, cs...), cs...), grads[0].Value().Data(), 1e-5, "groups %v", groups)
+	}
+
+	g := NewGraph()
+	q, _ := Quantize(tensor.New(tensor.WithShape(2, 8), tensor.WithBacking(wv)), 4)
+	w, _ := NewQuantizedWeight(g, q, "w")
+	x := NewMatrix(g, Float32, WithShape(2, 8), WithName("x"))
+	_, err := QuantizedMatMul(x, w, WithActivationGroups(3))
+	assert.Error(err, "the groups do not divide the activations")
+	_, err = QuantizedMatMul(x, w, WithActivationGroups(-1))
+	assert.Error(err)
+}
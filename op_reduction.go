@@ -67,6 +67,44 @@ func reductionInferShape(along []int, in tensor.Shape) (tensor.Shape, error) {
 	return tensor.Shape(dims), nil
 }
 
+// reductionIndices returns, for each element of row major data of the given shape, the index of the element it is reduced into
+// when reducing along the given axes. size is the number of elements after the reduction.
+func reductionIndices(shape tensor.Shape, along []int) (idx []int, size int) {
+	reduced := make([]bool, len(shape))
+	for _, axis := range along {
+		reduced[axis] = true
+	}
+
+	// strides of the output, with the reduced axes having a stride of 0
+	strides := make([]int, len(shape))
+	size = 1
+	for i := len(shape) - 1; i >= 0; i-- {
+		if reduced[i] {
+			continue
+		}
+		strides[i] = size
+		size *= shape[i]
+	}
+
+	idx = make([]int, shape.TotalSize())
+	coord := make([]int, len(shape))
+	for i := range idx {
+		for k, c := range coord {
+			idx[i] += c * strides[k]
+		}
+
+		// increment the coordinate
+		for k := len(coord) - 1; k >= 0; k-- {
+			coord[k]++
+			if coord[k] < shape[k] {
+				break
+			}
+			coord[k] = 0
+		}
+	}
+	return idx, size
+}
+
 func reductionDo(op Op, s string, f func(*tensor.Dense, ...int) (*tensor.Dense, error), along []int, inputs ...Value) (retVal Value, err error) {
 	if err = checkArity(op, len(inputs)); err != nil {
 		return
@@ -162,6 +200,15 @@ func (op maxOp) SymDiff(inputs Nodes, output, gradNode *Node) (retVal Nodes, err
 }
 
 func (op maxOp) Do(inputs ...Value) (retVal Value, err error) {
+	if err = checkArity(op, len(inputs)); err != nil {
+		return
+	}
+	if mode := nanModeOf(inputs...); mode != NaNDefault && hasNaN(inputs[0], CPU) {
+		if mode == NaNError {
+			return nil, errors.Errorf("NaN encountered in %v", op)
+		}
+		return nanMax(inputs[0], op.along, mode == NaNIgnore)
+	}
 	return reductionDo(op, "max", (*tensor.Dense).Max, op.along, inputs...)
 }
 
@@ -339,6 +386,14 @@ func (op sumOp) DoDiff(ctx ExecutionContext, inputs Nodes, output *Node) (err er
 }
 
 func (op sumOp) Do(inputs ...Value) (retVal Value, err error) {
+	if err = checkArity(op, len(inputs)); err != nil {
+		return
+	}
+	if alg, workers := summationOf(inputs...), workersOf(inputs...); alg != SumNaive || workers > 0 {
+		if dt := inputs[0].Dtype(); dt == Float64 || dt == Float32 {
+			return sumAlong(inputs[0], op.along, alg, workers)
+		}
+	}
 	return reductionDo(op, "sum", (*tensor.Dense).Sum, op.along, inputs...)
 }
 
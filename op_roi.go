@@ -0,0 +1,331 @@
+package gorgonia
+
+/*
+This file holds the region of interest Ops of the detection heads of Faster R-CNN and Mask R-CNN: ROIPool and ROIAlign.
+They follow the definitions of torchvision.
+*/
+
+import (
+	"fmt"
+	"hash"
+	"math"
+
+	"github.com/chewxy/hm"
+	"github.com/pkg/errors"
+	"gorgonia.org/tensor"
+)
+
+type roiOpType byte
+
+const (
+	roiAlignType roiOpType = iota
+	roiPoolType
+)
+
+func (t roiOpType) String() string {
+	if t == roiPoolType {
+		return "ROIPool"
+	}
+	return "ROIAlign"
+}
+
+// roiOp pools the regions of interest of a (N, C, H, W) feature map into (K, C, outH, outW) features. The K boxes are
+// given as a (K, 5) matrix of rows (batch index, x1, y1, x2, y2), in the coordinates of the image: they are scaled by
+// scale to the coordinates of the feature map.
+//
+// ROIPool takes the maximum of the cells of the feature map in every bin of a box, rounded to the cells. ROIAlign
+// averages samplingRatio×samplingRatio bilinearly interpolated points of every bin, without rounding. With a sampling
+// ratio of 0, the number of points adapts to the size of the bins. If aligned is true, the boxes are shifted by half a
+// cell, so that they are aligned with the centers of the cells.
+type roiOp struct {
+	which         roiOpType
+	outH, outW    int
+	scale         float64
+	samplingRatio int
+	aligned       bool
+}
+
+func (op roiOp) Arity() int { return 2 }
+
+// roiOp has this type:
+//		roiOp :: Tensor-4 a → Matrix a → Tensor-4 a
+func (op roiOp) Type() hm.Type {
+	a := hm.TypeVariable('a')
+	t := makeTensorType(4, a)
+	return hm.NewFnType(t, makeTensorType(2, a), t)
+}
+
+func (op roiOp) InferShape(inputs ...DimSizer) (tensor.Shape, error) {
+	if err := checkArity(op, len(inputs)); err != nil {
+		return nil, err
+	}
+	x, ok := inputs[0].(tensor.Shape)
+	if !ok || x.Dims() != 4 {
+		return nil, errors.Errorf("Expected the shape of a (N, C, H, W) feature map. Got %v instead", inputs[0])
+	}
+	boxes, ok := inputs[1].(tensor.Shape)
+	if !ok || boxes.Dims() != 2 || boxes[1] != 5 {
+		return nil, errors.Errorf("Expected the shape of (K, 5) boxes. Got %v instead", inputs[1])
+	}
+	return tensor.Shape{boxes[0], x[1], op.outH, op.outW}, nil
+}
+
+func (op roiOp) Do(inputs ...Value) (retVal Value, err error) {
+	if err = checkArity(op, len(inputs)); err != nil {
+		return
+	}
+	var shape tensor.Shape
+	if shape, err = op.InferShape(inputs[0].Shape(), inputs[1].Shape()); err != nil {
+		return nil, err
+	}
+	var x, boxes []float64
+	if x, boxes, err = op.data(inputs[0], inputs[1]); err != nil {
+		return nil, err
+	}
+
+	ret := make([]float64, shape.TotalSize())
+	op.walk(inputs[0].Shape(), boxes, func(out, in int, w float64) {
+		if op.which == roiPoolType {
+			if w > 0 || x[in] > ret[out] {
+				ret[out] = x[in]
+			}
+			return
+		}
+		ret[out] += w * x[in]
+	})
+	return castValue(tensor.New(tensor.WithShape(shape...), tensor.WithBacking(ret)), inputs[0].Dtype(), false)
+}
+
+// data returns the data of the feature map and of the boxes as float64s, checking the batch indices of the boxes.
+func (op roiOp) data(xv, boxesV Value) (x, boxes []float64, err error) {
+	if x, err = floatData(xv); err != nil {
+		return nil, nil, errors.Wrap(err, opDoFail)
+	}
+	if boxes, err = floatData(boxesV); err != nil {
+		return nil, nil, errors.Wrap(err, opDoFail)
+	}
+	n := xv.Shape()[0]
+	for k := 0; k < len(boxes); k += 5 {
+		if b := boxes[k]; b != math.Trunc(b) || b < 0 || int(b) >= n {
+			return nil, nil, errors.Errorf("Box %d has the batch index %v, which is not an index of the %d feature maps", k/5, b, n)
+		}
+	}
+	return x, boxes, nil
+}
+
+// walk calls fn for every pair of an element of the output and an element of the feature map it is computed from,
+// with the weight of the element of the feature map. For ROIPool, the weight is 1 for the first element of every bin,
+// and 0 for the next ones: the output is the maximum of the elements. Empty bins have no element.
+func (op roiOp) walk(xShape tensor.Shape, boxes []float64, fn func(out, in int, w float64)) {
+	c, h, w := xShape[1], xShape[2], xShape[3]
+	for k := 0; k < len(boxes)/5; k++ {
+		box := boxes[k*5 : k*5+5]
+		n := int(box[0])
+		for ci := 0; ci < c; ci++ {
+			outBase := ((k*c + ci) * op.outH) * op.outW
+			inBase := (n*c + ci) * h * w
+			if op.which == roiPoolType {
+				op.poolBox(box, h, w, func(out, in int, first bool) {
+					wt := 0.0
+					if first {
+						wt = 1
+					}
+					fn(outBase+out, inBase+in, wt)
+				})
+			} else {
+				op.alignBox(box, h, w, func(out, in int, wt float64) { fn(outBase+out, inBase+in, wt) })
+			}
+		}
+	}
+}
+
+// poolBox calls fn with the cells of every bin of the box, as ROIPool does. first is true for the first cell of a bin.
+func (op roiOp) poolBox(box []float64, h, w int, fn func(out, in int, first bool)) {
+	startW, startH := int(math.Round(box[1]*op.scale)), int(math.Round(box[2]*op.scale))
+	endW, endH := int(math.Round(box[3]*op.scale)), int(math.Round(box[4]*op.scale))
+	roiW, roiH := maxInt(endW-startW+1, 1), maxInt(endH-startH+1, 1)
+	binH, binW := float64(roiH)/float64(op.outH), float64(roiW)/float64(op.outW)
+
+	for ph := 0; ph < op.outH; ph++ {
+		hStart := clampInt(int(math.Floor(float64(ph)*binH))+startH, 0, h)
+		hEnd := clampInt(int(math.Ceil(float64(ph+1)*binH))+startH, 0, h)
+		for pw := 0; pw < op.outW; pw++ {
+			wStart := clampInt(int(math.Floor(float64(pw)*binW))+startW, 0, w)
+			wEnd := clampInt(int(math.Ceil(float64(pw+1)*binW))+startW, 0, w)
+			first := true
+			for y := hStart; y < hEnd; y++ {
+				for x := wStart; x < wEnd; x++ {
+					fn(ph*op.outW+pw, y*w+x, first)
+					first = false
+				}
+			}
+		}
+	}
+}
+
+// alignBox calls fn with the cells and the weights of the bilinear interpolation of the sampling points of every bin of
+// the box, as ROIAlign does.
+func (op roiOp) alignBox(box []float64, h, w int, fn func(out, in int, wt float64)) {
+	var offset float64
+	if op.aligned {
+		offset = 0.5
+	}
+	startW, startH := box[1]*op.scale-offset, box[2]*op.scale-offset
+	roiW, roiH := box[3]*op.scale-offset-startW, box[4]*op.scale-offset-startH
+	if !op.aligned {
+		roiW, roiH = math.Max(roiW, 1), math.Max(roiH, 1)
+	}
+	binH, binW := roiH/float64(op.outH), roiW/float64(op.outW)
+	gridH, gridW := op.samplingRatio, op.samplingRatio
+	if gridH <= 0 {
+		gridH = int(math.Ceil(roiH / float64(op.outH)))
+		gridW = int(math.Ceil(roiW / float64(op.outW)))
+	}
+	count := float64(maxInt(gridH*gridW, 1))
+
+	for ph := 0; ph < op.outH; ph++ {
+		for pw := 0; pw < op.outW; pw++ {
+			out := ph*op.outW + pw
+			for iy := 0; iy < gridH; iy++ {
+				y := startH + float64(ph)*binH + (float64(iy)+0.5)*binH/float64(gridH)
+				for ix := 0; ix < gridW; ix++ {
+					x := startW + float64(pw)*binW + (float64(ix)+0.5)*binW/float64(gridW)
+					bilinear(y, x, h, w, func(in int, wt float64) { fn(out, in, wt/count) })
+				}
+			}
+		}
+	}
+}
+
+// bilinear calls fn with the cells of an (h, w) map around the point (y, x), and their weights in the bilinear
+// interpolation of the point. The points more than a cell outside of the map are 0.
+func bilinear(y, x float64, h, w int, fn func(in int, wt float64)) {
+	if y < -1 || y > float64(h) || x < -1 || x > float64(w) {
+		return
+	}
+	y, x = math.Max(y, 0), math.Max(x, 0)
+	yLow, xLow := int(y), int(x)
+	yHigh, xHigh := yLow+1, xLow+1
+	if yLow >= h-1 {
+		yLow, yHigh = h-1, h-1
+		y = float64(yLow)
+	}
+	if xLow >= w-1 {
+		xLow, xHigh = w-1, w-1
+		x = float64(xLow)
+	}
+	ly, lx := y-float64(yLow), x-float64(xLow)
+	hy, hx := 1-ly, 1-lx
+	fn(yLow*w+xLow, hy*hx)
+	fn(yLow*w+xHigh, hy*lx)
+	fn(yHigh*w+xLow, ly*hx)
+	fn(yHigh*w+xHigh, ly*lx)
+}
+
+func (op roiOp) ReturnsPtr() bool     { return false }
+func (op roiOp) CallsExtern() bool    { return false }
+func (op roiOp) OverwritesInput() int { return -1 }
+
+func (op roiOp) WriteHash(h hash.Hash) {
+	fmt.Fprintf(h, "%v{%d, %d, %v, %d, %t}", op.which, op.outH, op.outW, op.scale, op.samplingRatio, op.aligned)
+}
+
+func (op roiOp) Hashcode() uint32 { return simpleHash(op) }
+
+func (op roiOp) String() string {
+	return fmt.Sprintf("%v{(%d, %d), scale=%v}", op.which, op.outH, op.outW, op.scale)
+}
+
+// DiffWRT returns true for the feature map: the boxes are not differentiable.
+func (op roiOp) DiffWRT(inputs int) []bool { return []bool{true, false} }
+
+func (op roiOp) SymDiff(inputs Nodes, output, grad *Node) (retVal Nodes, err error) {
+	if err = checkArity(op, len(inputs)); err != nil {
+		return
+	}
+	var ret *Node
+	if ret, err = ApplyOp(roiDiffOp{op}, inputs[0], inputs[1], grad); err != nil {
+		return nil, errors.Wrap(err, operationError)
+	}
+	return Nodes{ret, nil}, nil
+}
+
+// roiDiffOp is the derivative of a roiOp with respect to the feature map. It takes the feature map, the boxes and the
+// gradient of the output.
+type roiDiffOp struct{ roiOp }
+
+func (op roiDiffOp) Arity() int { return 3 }
+
+// roiDiffOp has this type:
+//		roiDiffOp :: Tensor-4 a → Matrix a → Tensor-4 a → Tensor-4 a
+func (op roiDiffOp) Type() hm.Type {
+	a := hm.TypeVariable('a')
+	t := makeTensorType(4, a)
+	return hm.NewFnType(t, makeTensorType(2, a), t, t)
+}
+
+func (op roiDiffOp) InferShape(inputs ...DimSizer) (tensor.Shape, error) {
+	if err := checkArity(op, len(inputs)); err != nil {
+		return nil, err
+	}
+	s, ok := inputs[0].(tensor.Shape)
+	if !ok {
+		return nil, errors.Errorf("Expected a shape. Got %v of %T instead", inputs[0], inputs[0])
+	}
+	return s.Clone(), nil
+}
+
+func (op roiDiffOp) Do(inputs ...Value) (retVal Value, err error) {
+	if err = checkArity(op, len(inputs)); err != nil {
+		return
+	}
+	var x, boxes, grad []float64
+	if x, boxes, err = op.data(inputs[0], inputs[1]); err != nil {
+		return nil, err
+	}
+	if grad, err = floatData(inputs[2]); err != nil {
+		return nil, errors.Wrap(err, opDoFail)
+	}
+
+	dx := make([]float64, len(x))
+	if op.which == roiPoolType {
+		// the gradient flows to the maximum of every bin, which is the first maximum, as in Do
+		argmax := make(map[int]int)
+		op.walk(inputs[0].Shape(), boxes, func(out, in int, w float64) {
+			if j, ok := argmax[out]; !ok || w > 0 || x[in] > x[j] {
+				argmax[out] = in
+			}
+		})
+		for out, in := range argmax {
+			dx[in] += grad[out]
+		}
+	} else {
+		op.walk(inputs[0].Shape(), boxes, func(out, in int, w float64) { dx[in] += w * grad[out] })
+	}
+	return castValue(tensor.New(tensor.WithShape(inputs[0].Shape().Clone()...), tensor.WithBacking(dx)), inputs[0].Dtype(), false)
+}
+
+func (op roiDiffOp) WriteHash(h hash.Hash) {
+	h.Write([]byte("diff"))
+	op.roiOp.WriteHash(h)
+}
+
+func (op roiDiffOp) Hashcode() uint32 { return simpleHash(op) }
+
+func (op roiDiffOp) String() string { return op.roiOp.String() + "'" }
+
+func (op roiDiffOp) DiffWRT(inputs int) []bool { return []bool{false, false, false} }
+
+func (op roiDiffOp) SymDiff(inputs Nodes, output, grad *Node) (retVal Nodes, err error) {
+	return nil, nondiffErr(op)
+}
+
+func clampInt(a, lo, hi int) int {
+	switch {
+	case a < lo:
+		return lo
+	case a > hi:
+		return hi
+	}
+	return a
+}
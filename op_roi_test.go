@@ -0,0 +1,105 @@
+package gorgonia
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorgonia.org/tensor"
+)
+
+func TestROIPool(t *testing.T) {
+	assert := assert.New(t)
+	g := NewGraph()
+	x := NewTensor(g, Float64, 4, WithShape(1, 1, 4, 4), WithName("x"), WithValue(tensor.New(tensor.WithShape(1, 1, 4, 4), tensor.WithBacking(tensor.Range(tensor.Float64, 0, 16)))))
+	boxes := NewMatrix(g, Float64, WithShape(2, 5), WithName("boxes"), WithValue(tensor.New(tensor.WithShape(2, 5), tensor.WithBacking([]float64{
+		0, 0, 0, 3, 3,
+		0, 2, 2, 3, 3,
+	}))))
+	pooled, err := ROIPool(x, boxes, 2, 2, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(tensor.Shape{2, 1, 2, 2}, pooled.Shape())
+	grads, err := Grad(Must(Sum(pooled)), x)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewTapeMachine(g)
+	defer m.Close()
+	if err = m.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]float64{5, 7, 13, 15, 10, 11, 14, 15}, pooled.Value().Data())
+	assert.Equal([]float64{
+		0, 0, 0, 0,
+		0, 1, 0, 1,
+		0, 0, 1, 1,
+		0, 1, 1, 2,
+	}, grads[0].Value().Data())
+
+	_, err = ROIPool(x, NewMatrix(g, Float64, WithShape(2, 4)), 2, 2, 1)
+	assert.Error(err)
+	_, err = ROIPool(x, boxes, 0, 2, 1)
+	assert.Error(err)
+}
+
+func TestROIAlign(t *testing.T) {
+	assert := assert.New(t)
+	g := NewGraph()
+	x := NewTensor(g, Float64, 4, WithShape(1, 1, 4, 4), WithName("x"), WithValue(tensor.New(tensor.WithShape(1, 1, 4, 4), tensor.WithBacking(tensor.Range(tensor.Float64, 0, 16)))))
+	boxes := NewMatrix(g, Float64, WithShape(1, 5), WithName("boxes"), WithValue(tensor.New(tensor.WithShape(1, 5), tensor.WithBacking([]float64{0, 0, 0, 3, 3}))))
+	aligned, err := ROIAlign(x, boxes, 1, 1, 1, 1, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	grads, err := Grad(Must(Sum(aligned)), x)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewTapeMachine(g)
+	defer m.Close()
+	if err = m.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+	// the only sampling point is at (1.5, 1.5), between 5, 6, 9 and 10
+	assert.InDeltaSlice([]float64{7.5}, aligned.Value().Data(), 1e-12)
+	assert.InDeltaSlice([]float64{
+		0, 0, 0, 0,
+		0, 0.25, 0.25, 0,
+		0, 0.25, 0.25, 0,
+		0, 0, 0, 0,
+	}, grads[0].Value().Data(), 1e-12)
+}
+
+func TestROIAlignGrad(t *testing.T) {
+	assert := assert.New(t)
+	xv := tensor.New(tensor.WithShape(2, 2, 5, 6), tensor.WithBacking(tensor.Random(tensor.Float64, 2*2*5*6)))
+	boxes := tensor.New(tensor.WithShape(3, 5), tensor.WithBacking([]float64{
+		0, 0.3, 0.7, 9.1, 7.4,
+		1, 2.2, 1.1, 5.3, 8.9,
+		1, -1, -2, 4, 3,
+	}))
+	inputs := []Value{xv, boxes}
+	for _, aligned := range []bool{false, true} {
+		op := roiOp{which: roiAlignType, outH: 3, outW: 2, scale: 0.5, samplingRatio: 0, aligned: aligned}
+		out, err := op.Do(inputs...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(tensor.Shape{3, 2, 3, 2}, out.Shape())
+		grad := tensor.Random(tensor.Float64, out.Shape().TotalSize()).([]float64)
+		dx, err := roiDiffOp{op}.Do(xv, boxes, tensor.New(tensor.WithShape(out.Shape()...), tensor.WithBacking(grad)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, _ := floatData(dx)
+		assert.InDeltaSlice(numericGrad(t, op, inputs, 0, grad), got, 1e-6, "aligned: %t", aligned)
+	}
+
+	// the batch indices must be indices of the feature maps
+	bad := tensor.New(tensor.WithShape(1, 5), tensor.WithBacking([]float64{2, 0, 0, 1, 1}))
+	_, err := roiOp{which: roiPoolType, outH: 1, outW: 1, scale: 1}.Do(xv, bad)
+	assert.Error(err)
+}
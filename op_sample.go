@@ -0,0 +1,225 @@
+package gorgonia
+
+import (
+	"fmt"
+	"hash"
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/chewxy/hm"
+	"github.com/pkg/errors"
+	"gorgonia.org/tensor"
+)
+
+// categoricalOp samples an index along the last axis of its input, which holds log-probabilities (or logits) or
+// probabilities. The sampling distribution may be sharpened or flattened by a temperature, and restricted to the k most
+// probable indices (top-k) or to the smallest set of indices whose probabilities sum to at least p (top-p, or nucleus
+// sampling). A temperature of 0 always picks the most probable index.
+//
+// On a device, the rows are sampled by the kernels of random.cu, one thread per row (see CUDADo in op_sample_cuda.go).
+type categoricalOp struct {
+	temperature float64
+	topK        int
+	topP        float64
+	probs       bool // the input holds probabilities rather than log-probabilities
+	d           int
+	id          uint64
+
+	sync.Mutex
+	rand *rand.Rand
+}
+
+// SamplingOpt is a function that provides construction options for SampleCategorical.
+type SamplingOpt func(*categoricalOp)
+
+// WithTemperature divides the log-probabilities by t before sampling. A temperature of 0 is greedy sampling.
+func WithTemperature(t float64) SamplingOpt {
+	return func(op *categoricalOp) { op.temperature = t }
+}
+
+// WithTopK restricts the sampling to the k most probable indices.
+func WithTopK(k int) SamplingOpt {
+	return func(op *categoricalOp) { op.topK = k }
+}
+
+// WithTopP restricts the sampling to the smallest set of most probable indices whose probabilities sum to at least p.
+func WithTopP(p float64) SamplingOpt {
+	return func(op *categoricalOp) { op.topP = p }
+}
+
+// FromProbabilities indicates that the input holds probabilities. By default, it holds log-probabilities or logits.
+func FromProbabilities() SamplingOpt {
+	return func(op *categoricalOp) { op.probs = true }
+}
+
+// WithSamplingSeed seeds the source of randomness of the sampling. By default, it is seeded with the current time.
+func WithSamplingSeed(seed int64) SamplingOpt {
+	return func(op *categoricalOp) { op.rand = rand.New(rand.NewSource(seed)) }
+}
+
+func newCategoricalOp(d int, opts ...SamplingOpt) (*categoricalOp, error) {
+	op := &categoricalOp{temperature: 1, d: d, id: uniqueOpID()}
+	for _, opt := range opts {
+		opt(op)
+	}
+	switch {
+	case op.temperature < 0:
+		return nil, errors.Errorf("Expected a non-negative temperature. Got %v instead", op.temperature)
+	case op.topK < 0:
+		return nil, errors.Errorf("Expected a non-negative top-k. Got %d instead", op.topK)
+	case op.topP < 0 || op.topP > 1:
+		return nil, errors.Errorf("Expected a top-p in [0, 1]. Got %v instead", op.topP)
+	}
+	if op.rand == nil {
+		op.rand = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	return op, nil
+}
+
+func (op *categoricalOp) Arity() int { return 1 }
+
+// categoricalOp has these types:
+//		categoricalOp :: (Floats a) ⇒ Tensor-d a → Tensor-(d-1) Int
+//		categoricalOp :: (Floats a) ⇒ Vector a → Int
+func (op *categoricalOp) Type() hm.Type {
+	a := hm.TypeVariable('a')
+	if op.d == 1 {
+		return hm.NewFnType(makeTensorType(1, a), Int)
+	}
+	return hm.NewFnType(makeTensorType(op.d, a), makeTensorType(op.d-1, Int))
+}
+
+func (op *categoricalOp) InferShape(inputs ...DimSizer) (tensor.Shape, error) {
+	if err := checkArity(op, len(inputs)); err != nil {
+		return nil, err
+	}
+	s, ok := inputs[0].(tensor.Shape)
+	if !ok {
+		return nil, errors.Errorf("Expected a shape. Got %v of %T instead", inputs[0], inputs[0])
+	}
+	if len(s) == 0 {
+		return nil, errors.New("Cannot sample from a scalar")
+	}
+	if len(s) == 1 {
+		return scalarShape, nil
+	}
+	return s[:len(s)-1].Clone(), nil
+}
+
+func (op *categoricalOp) Do(inputs ...Value) (retVal Value, err error) {
+	if err = checkArity(op, len(inputs)); err != nil {
+		return
+	}
+	t, ok := inputs[0].(tensor.Tensor)
+	if !ok {
+		return nil, errors.Errorf(nyiTypeFail, op, inputs[0])
+	}
+	if t.RequiresIterator() {
+		t = tensor.Materialize(t)
+	}
+	var scores []float64
+	switch data := t.Data().(type) {
+	case []float64:
+		scores = make([]float64, len(data))
+		copy(scores, data)
+	case []float32:
+		scores = make([]float64, len(data))
+		for i, v := range data {
+			scores[i] = float64(v)
+		}
+	default:
+		return nil, errors.Errorf(nyiFail, op, t.Dtype())
+	}
+
+	shape := t.Shape()
+	classes := shape[len(shape)-1]
+	samples := make([]int, len(scores)/classes)
+	op.Lock()
+	for i := range samples {
+		samples[i] = op.sample(scores[i*classes : (i+1)*classes])
+	}
+	op.Unlock()
+
+	if op.d == 1 {
+		return newI(samples[0]), nil
+	}
+	return tensor.New(tensor.WithShape(shape[:len(shape)-1].Clone()...), tensor.WithBacking(samples)), nil
+}
+
+// sample samples an index of a row of scores, which it overwrites.
+func (op *categoricalOp) sample(scores []float64) int {
+	if op.probs {
+		for i, p := range scores {
+			scores[i] = math.Log(p)
+		}
+	}
+
+	order := make([]int, len(scores))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return scores[order[a]] > scores[order[b]] })
+	if op.temperature == 0 {
+		return order[0]
+	}
+	if op.topK > 0 && op.topK < len(order) {
+		order = order[:op.topK]
+	}
+
+	// softmax over the kept indices
+	max := scores[order[0]]
+	probs := make([]float64, len(order))
+	var sum float64
+	for i, idx := range order {
+		probs[i] = math.Exp((scores[idx] - max) / op.temperature)
+		sum += probs[i]
+	}
+	for i := range probs {
+		probs[i] /= sum
+	}
+	if op.topP > 0 {
+		var cum float64
+		for i, p := range probs {
+			if cum += p; cum >= op.topP {
+				order, probs = order[:i+1], probs[:i+1]
+				break
+			}
+		}
+		sum = cum
+	} else {
+		sum = 1
+	}
+
+	r := op.rand.Float64() * sum
+	for i, p := range probs {
+		if r -= p; r < 0 {
+			return order[i]
+		}
+	}
+	return order[len(order)-1]
+}
+
+func (op *categoricalOp) ReturnsPtr() bool     { return false }
+func (op *categoricalOp) CallsExtern() bool    { return false }
+func (op *categoricalOp) OverwritesInput() int { return -1 }
+
+// IsStateful is true: the sampling draws new numbers on every run.
+func (op *categoricalOp) IsStateful() bool { return true }
+
+// WriteHash includes the id of the op: every sampling op is distinct, as it has its own source of randomness.
+func (op *categoricalOp) WriteHash(h hash.Hash) { fmt.Fprintf(h, "SampleCategorical{%d}", op.id) }
+
+func (op *categoricalOp) Hashcode() uint32 { return simpleHash(op) }
+
+func (op *categoricalOp) String() string {
+	return fmt.Sprintf("SampleCategorical{T=%v, k=%d, p=%v}", op.temperature, op.topK, op.topP)
+}
+
+func (op *categoricalOp) DiffWRT(inputs int) []bool { return []bool{false} }
+
+func (op *categoricalOp) SymDiff(inputs Nodes, output, grad *Node) (retVal Nodes, err error) {
+	return nil, nondiffErr(op)
+}
@@ -0,0 +1,101 @@
+// +build cuda
+
+package gorgonia
+
+import (
+	"fmt"
+	"unsafe"
+
+	"github.com/chewxy/hm"
+	"github.com/pkg/errors"
+	"gorgonia.org/cu"
+	"gorgonia.org/tensor"
+)
+
+// CUDADo samples the rows on the device with the sample kernels of random.cu, so that the logits computed on the device
+// are not copied to the host at every step of a generation. Every launch draws a new seed from the source of
+// randomness of the op, so that the samples of a seeded op are reproducible. If the kernels are not loaded, the input
+// is copied to the host and sampled there.
+func (op *categoricalOp) CUDADo(extern External, dev Device, prealloc Value, inputs ...Value) (retVal Value, err error) {
+	if err = checkArity(op, len(inputs)); err != nil {
+		return
+	}
+	machine := extern.(CUDAMachine)
+	eng := &machine.Engines()[int(dev)]
+	ctx := machine.Contexts()[int(dev)]
+
+	a := inputs[0]
+	dt := a.Dtype()
+	shape := a.Shape()
+	var s tensor.Shape
+	if s, err = op.InferShape(shape); err != nil {
+		return nil, err
+	}
+
+	if prealloc == nil || !prealloc.Shape().Eq(s) || prealloc.Dtype() != Int {
+		rt := op.Type().(*hm.FunctionType).Ret(false)
+		memsize := calcMemSize(Int, s)
+		var mem tensor.Memory
+		if mem, err = extern.Get(dev, memsize); err != nil {
+			return nil, errors.Wrapf(err, "Unable to allocate %v bytes from %v", memsize, dev)
+		}
+		if prealloc, err = makeValueFromMem(rt, s, mem); err != nil {
+			return nil, err
+		}
+	}
+	out := cu.DevicePtr(prealloc.Uintptr())
+
+	// the kernels write the indices as 64 bit integers
+	name := fmt.Sprintf("%v.sample_f%d", randomMod, int(dt.Size())*8)
+	if !eng.HasFunc(name) || Int.Size() != 8 {
+		cudaLogf("extern does not have func %q", name)
+		extern.Signal()
+		var host, v Value
+		if host, err = extern.Transfer(CPU, dev, a, true); err != nil {
+			return nil, errors.Wrapf(err, "Unable to transfer %v to the host", a)
+		}
+		if v, err = op.Do(host); err != nil {
+			return nil, err
+		}
+		ctx.MemcpyHtoD(out, v.Pointer(), int64(v.MemSize()))
+		return prealloc, nil
+	}
+
+	classes := shape[len(shape)-1]
+	size := logicalSize(s)
+	ordersize := int64(size * classes * 4)
+	var order tensor.Memory
+	if order, err = extern.Get(dev, ordersize); err != nil {
+		return nil, errors.Wrapf(err, "Unable to allocate %v bytes from %v", ordersize, dev)
+	}
+	defer extern.Put(dev, order, ordersize)
+
+	op.Lock()
+	seed := uint64(op.rand.Int63())
+	op.Unlock()
+
+	mem := cu.DevicePtr(a.Uintptr())
+	orderMem := cu.DevicePtr(order.Uintptr())
+	var probs int
+	if op.probs {
+		probs = 1
+	}
+	fn := eng.Functions()[name]
+	args := []unsafe.Pointer{
+		unsafe.Pointer(&mem),
+		unsafe.Pointer(&out),
+		unsafe.Pointer(&orderMem),
+		unsafe.Pointer(&size),
+		unsafe.Pointer(&classes),
+		unsafe.Pointer(&seed),
+		unsafe.Pointer(&op.temperature),
+		unsafe.Pointer(&op.topK),
+		unsafe.Pointer(&op.topP),
+		unsafe.Pointer(&probs),
+	}
+
+	gridDimX, gridDimY, gridDimZ, blockDimX, blockDimY, blockDimZ := machine.ElemGridSize(size, int(dev))
+	cudaLogf("CUDADO %q, Mem: %v rows %v classes %v seed %v", name, mem, size, classes, seed)
+	ctx.LaunchAndSync(fn, gridDimX, gridDimY, gridDimZ, blockDimX, blockDimY, blockDimZ, 0, cu.NoStream, args)
+	return prealloc, nil
+}
@@ -0,0 +1,63 @@
+// +build cuda
+
+package gorgonia
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorgonia.org/tensor"
+)
+
+func TestCUDASampleCategorical(t *testing.T) {
+	defer runtime.GC()
+
+	assert := assert.New(t)
+	probs := []float32{0.1, 0.2, 0.3, 0.4}
+	const n = 4096
+	rows := make([]float32, 0, n*len(probs))
+	for i := 0; i < n; i++ {
+		rows = append(rows, probs...)
+	}
+
+	g := NewGraph()
+	p := NewMatrix(g, Float32, WithShape(n, len(probs)), WithName("p"), WithValue(tensor.New(tensor.WithShape(n, len(probs)), tensor.WithBacking(rows))))
+	logits := Must(Log(p))
+	sampled := Must(SampleCategorical(logits, WithSamplingSeed(1)))
+	topK := Must(SampleCategorical(logits, WithTopK(2), WithSamplingSeed(1)))
+	greedy := Must(SampleCategorical(logits, WithTemperature(0)))
+	var sampledVal, topKVal, greedyVal Value
+	Read(sampled, &sampledVal)
+	Read(topK, &topKVal)
+	Read(greedy, &greedyVal)
+
+	m := NewTapeMachine(g)
+	defer m.Close()
+	if err := m.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+
+	counts := func(v Value) []int {
+		c := make([]int, len(probs))
+		for _, i := range v.Data().([]int) {
+			c[i]++
+		}
+		return c
+	}
+	c := counts(sampledVal)
+	for i, p := range probs {
+		assert.InDelta(p, float64(c[i])/n, 0.03, "index %d", i)
+	}
+	c = counts(topKVal)
+	assert.Equal(0, c[0]+c[1], "the top-k sampling only draws the 2 most probable indices")
+	assert.InDelta(3.0/7, float64(c[2])/n, 0.03)
+	assert.Equal([]int{0, 0, 0, n}, counts(greedyVal))
+
+	first := append([]int(nil), sampledVal.Data().([]int)...)
+	m.Reset()
+	if err := m.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+	assert.NotEqual(first, sampledVal.Data().([]int), "every run draws new samples")
+}
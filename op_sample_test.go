@@ -0,0 +1,85 @@
+package gorgonia
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorgonia.org/tensor"
+)
+
+func sampleCounts(t *testing.T, probs []float64, n int, opts ...SamplingOpt) []int {
+	op, err := newCategoricalOp(2, append([]SamplingOpt{FromProbabilities(), WithSamplingSeed(1)}, opts...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows := make([]float64, 0, n*len(probs))
+	for i := 0; i < n; i++ {
+		rows = append(rows, probs...)
+	}
+	v, err := op.Do(tensor.New(tensor.WithShape(n, len(probs)), tensor.WithBacking(rows)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	counts := make([]int, len(probs))
+	for _, i := range v.Data().([]int) {
+		counts[i]++
+	}
+	return counts
+}
+
+func TestCategoricalOp(t *testing.T) {
+	assert := assert.New(t)
+	probs := []float64{0.1, 0.2, 0.3, 0.4}
+	const n = 10000
+
+	counts := sampleCounts(t, probs, n)
+	for i, p := range probs {
+		assert.InDelta(p, float64(counts[i])/n, 0.02, "index %d", i)
+	}
+	assert.Equal([]int{0, 0, 0, n}, sampleCounts(t, probs, n, WithTemperature(0)))
+
+	counts = sampleCounts(t, probs, n, WithTopK(2))
+	assert.Equal(0, counts[0]+counts[1])
+	assert.InDelta(3.0/7, float64(counts[2])/n, 0.02)
+
+	counts = sampleCounts(t, probs, n, WithTopP(0.6))
+	assert.Equal(0, counts[0]+counts[1], "0.4 + 0.3 >= 0.6")
+
+	// a low temperature sharpens the distribution
+	counts = sampleCounts(t, probs, n, WithTemperature(0.1))
+	assert.True(counts[3] > n*9/10)
+
+	_, err := newCategoricalOp(1, WithTopP(2))
+	assert.Error(err)
+	_, err = newCategoricalOp(1, WithTemperature(-1))
+	assert.Error(err)
+}
+
+func TestSampleCategorical(t *testing.T) {
+	assert := assert.New(t)
+	g := NewGraph()
+	logits := NewMatrix(g, Float32, WithShape(2, 3), WithName("logits"), WithValue(tensor.New(tensor.WithShape(2, 3), tensor.WithBacking([]float32{0, 5, 0, 9, 0, 0}))))
+	v := NewVector(g, Float64, WithShape(3), WithName("v"), WithValue(tensor.New(tensor.WithBacking([]float64{math.Log(0.5), math.Log(0.5), math.Inf(-1)}))))
+
+	greedy := Must(SampleCategorical(logits, WithTemperature(0)))
+	s1 := Must(SampleCategorical(v, WithSamplingSeed(3)))
+	s2 := Must(SampleCategorical(v, WithSamplingSeed(3)))
+	assert.NotEqual(s1, s2, "sampling nodes are never hash consed")
+	assert.Equal(tensor.Shape{2}, greedy.Shape())
+	assert.True(s1.IsScalar())
+	assert.Equal(Int, s1.Dtype())
+
+	m := NewTapeMachine(g)
+	defer m.Close()
+	if err := m.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]int{1, 0}, greedy.Value().Data())
+	assert.True(s1.Value().Data().(int) < 2, "impossible indices are never sampled")
+
+	_, err := SampleCategorical(NewVector(g, Int, WithShape(3), WithName("i")))
+	assert.Error(err)
+	_, err = SampleCategorical(NewScalar(g, Float64, WithName("s")))
+	assert.Error(err)
+}
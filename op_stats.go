@@ -0,0 +1,403 @@
+package gorgonia
+
+/*
+This file holds the Ops that compute statistics of the values of a tensor: histograms and quantiles.
+*/
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"math"
+	"sort"
+
+	"github.com/chewxy/hm"
+	"github.com/pkg/errors"
+	"gorgonia.org/tensor"
+)
+
+// histogramOp counts the values of a tensor falling into bins. The bins are either given by their edges, or are
+// equally wide between lo and hi. If lo and hi are equal, the range is the range of the values. The counts and the
+// edges of the bins are both returned, as the edges depend on the values when the range is automatic.
+type histogramOp struct {
+	bins   int
+	lo, hi float64
+	edges  []float64 // fixed edges, if any
+	d      int
+}
+
+// HistogramOpt is a function that provides construction options for Histogram.
+type HistogramOpt func(*histogramOp)
+
+// HistogramRange sets the range of the bins of a histogram. The values outside of the range are not counted. By
+// default, the range is the range of the values.
+func HistogramRange(lo, hi float64) HistogramOpt {
+	return func(op *histogramOp) { op.lo, op.hi = lo, hi }
+}
+
+// HistogramBinEdges sets the edges of the bins of a histogram, which must be increasing. The values outside of the
+// first and last edges are not counted.
+func HistogramBinEdges(edges ...float64) HistogramOpt {
+	return func(op *histogramOp) { op.edges = edges }
+}
+
+func newHistogramOp(bins int, shape tensor.Shape, opts ...HistogramOpt) (*histogramOp, error) {
+	op := &histogramOp{bins: bins, d: shape.Dims()}
+	for _, opt := range opts {
+		opt(op)
+	}
+	if op.edges != nil {
+		if len(op.edges) < 2 {
+			return nil, errors.Errorf("Expected at least 2 bin edges. Got %d instead", len(op.edges))
+		}
+		if !sort.Float64sAreSorted(op.edges) {
+			return nil, errors.Errorf("Expected increasing bin edges. Got %v instead", op.edges)
+		}
+		if op.bins != 0 && op.bins != len(op.edges)-1 {
+			return nil, errors.Errorf("%d bin edges make %d bins, not %d", len(op.edges), len(op.edges)-1, op.bins)
+		}
+		op.bins = len(op.edges) - 1
+		op.lo, op.hi = op.edges[0], op.edges[op.bins]
+	}
+	switch {
+	case op.bins < 0:
+		return nil, errors.Errorf("Expected a non-negative number of bins. Got %d instead", op.bins)
+	case op.bins == 0:
+		// Sturges' rule, which only depends on the number of values
+		op.bins = 1
+		if n := shape.TotalSize(); n > 1 {
+			op.bins += int(math.Ceil(math.Log2(float64(n))))
+		}
+	}
+	if op.hi < op.lo || math.IsNaN(op.lo) || math.IsNaN(op.hi) || math.IsInf(op.lo, 0) || math.IsInf(op.hi, 0) {
+		return nil, errors.Errorf("Expected a finite range. Got [%v, %v] instead", op.lo, op.hi)
+	}
+	return op, nil
+}
+
+func (op *histogramOp) Arity() int { return 1 }
+
+// histogramOp has these types:
+//		histogramOp :: (Floats a) ⇒ Tensor-d a → (Vector Int, Vector a)
+//		histogramOp :: (Floats a) ⇒ a → (Vector Int, Vector a)
+func (op *histogramOp) Type() hm.Type {
+	a := hm.TypeVariable('a')
+	ret := hm.NewRecordType("", makeTensorType(1, Int), makeTensorType(1, a))
+	if op.d == 0 {
+		return hm.NewFnType(a, ret)
+	}
+	return hm.NewFnType(makeTensorType(op.d, a), ret)
+}
+
+func (op *histogramOp) InferShapes(inputs ...DimSizer) ([]tensor.Shape, error) {
+	if len(inputs) != 1 {
+		return nil, errors.Errorf("%v expects 1 input. Got %d instead", op, len(inputs))
+	}
+	return []tensor.Shape{{op.bins}, {op.bins + 1}}, nil
+}
+
+func (op *histogramOp) DoMulti(inputs ...Value) ([]Value, error) {
+	if len(inputs) != 1 {
+		return nil, errors.Errorf("%v expects 1 input. Got %d instead", op, len(inputs))
+	}
+	data, err := floatData(inputs[0])
+	if err != nil {
+		return nil, errors.Wrap(err, opDoFail)
+	}
+
+	edges := op.binEdges(data)
+	counts := make([]int, op.bins)
+	lo, hi := edges[0], edges[op.bins]
+	for _, v := range data {
+		if math.IsNaN(v) || v < lo || v > hi {
+			continue
+		}
+		// the last bin includes its upper edge
+		var bin int
+		if op.edges != nil {
+			bin = sort.SearchFloat64s(edges, v)
+			if bin == len(edges) || edges[bin] != v {
+				bin--
+			}
+		} else {
+			bin = int((v - lo) / (hi - lo) * float64(op.bins))
+		}
+		if bin >= op.bins {
+			bin = op.bins - 1
+		}
+		counts[bin]++
+	}
+
+	var edgesV Value
+	if edgesV, err = castValue(tensor.New(tensor.WithBacking(edges)), inputs[0].Dtype(), false); err != nil {
+		return nil, err
+	}
+	return []Value{tensor.New(tensor.WithBacking(counts)), edgesV}, nil
+}
+
+// binEdges returns the edges of the bins for the values.
+func (op *histogramOp) binEdges(data []float64) []float64 {
+	if op.edges != nil {
+		retVal := make([]float64, len(op.edges))
+		copy(retVal, op.edges)
+		return retVal
+	}
+	lo, hi := op.lo, op.hi
+	if lo == hi {
+		lo, hi = math.Inf(1), math.Inf(-1)
+		for _, v := range data {
+			if math.IsNaN(v) || math.IsInf(v, 0) {
+				continue
+			}
+			lo = math.Min(lo, v)
+			hi = math.Max(hi, v)
+		}
+		if lo > hi {
+			lo, hi = 0, 1 // no finite values
+		}
+	}
+	if lo == hi {
+		// as numpy does, a range of width 1 around the only value
+		lo, hi = lo-0.5, hi+0.5
+	}
+	retVal := make([]float64, op.bins+1)
+	for i := range retVal {
+		retVal[i] = lo + (hi-lo)*float64(i)/float64(op.bins)
+	}
+	retVal[op.bins] = hi
+	return retVal
+}
+
+func (op *histogramOp) CallsExtern() bool { return false }
+
+func (op *histogramOp) WriteHash(h hash.Hash) {
+	fmt.Fprintf(h, "Histogram{%d, %v, %v, %v, %d}", op.bins, op.lo, op.hi, op.edges, op.d)
+}
+
+func (op *histogramOp) Hashcode() uint32 { return simpleHash(op) }
+
+func (op *histogramOp) String() string {
+	if op.lo == op.hi {
+		return fmt.Sprintf("Histogram{%d}", op.bins)
+	}
+	return fmt.Sprintf("Histogram{%d, [%v, %v]}", op.bins, op.lo, op.hi)
+}
+
+func (op *histogramOp) DiffWRT(inputs int) []bool { return []bool{false} }
+
+// quantileOp computes the q-th quantile of the values of a tensor along the given axes, interpolating linearly between
+// the two nearest values, as numpy does by default.
+//
+// NaNs are propagated, unless the engine of the input ignores them (see NaNMode), in which case they are skipped.
+type quantileOp struct {
+	q     float64
+	along axes
+	d     int
+}
+
+func (op quantileOp) Arity() int { return 1 }
+
+func (op quantileOp) Type() hm.Type { return reductionType(op.d, op.along) }
+
+func (op quantileOp) InferShape(inputs ...DimSizer) (tensor.Shape, error) {
+	if err := checkArity(op, len(inputs)); err != nil {
+		return nil, err
+	}
+	s, ok := inputs[0].(tensor.Shape)
+	if !ok {
+		return nil, errors.Errorf("Expected a shape. Got %v of %T instead", inputs[0], inputs[0])
+	}
+	return reductionInferShape(op.along, s)
+}
+
+func (op quantileOp) Do(inputs ...Value) (retVal Value, err error) {
+	if err = checkArity(op, len(inputs)); err != nil {
+		return
+	}
+	v := inputs[0]
+	var data []float64
+	if data, err = floatData(v); err != nil {
+		return nil, errors.Wrap(err, opDoFail)
+	}
+	mode := nanModeOf(v)
+	if mode == NaNError && hasNaN(v, CPU) {
+		return nil, errors.Errorf("NaN encountered in %v", op)
+	}
+
+	groups := quantileGroups(v.Shape(), op.along, data, mode == NaNIgnore)
+	ret := make([]float64, len(groups))
+	for j, g := range groups {
+		ret[j] = g.quantile(data, op.q)
+	}
+
+	var shape tensor.Shape
+	if shape, err = reductionInferShape(op.along, v.Shape()); err != nil {
+		return nil, err
+	}
+	if shape.IsScalar() {
+		return castValue(newF64(ret[0]), v.Dtype(), false)
+	}
+	return castValue(tensor.New(tensor.WithShape(shape...), tensor.WithBacking(ret)), v.Dtype(), false)
+}
+
+func (op quantileOp) ReturnsPtr() bool     { return false }
+func (op quantileOp) CallsExtern() bool    { return false }
+func (op quantileOp) OverwritesInput() int { return -1 }
+
+func (op quantileOp) WriteHash(h hash.Hash) {
+	h.Write([]byte("quantile"))
+	if err := binary.Write(h, binary.LittleEndian, op.q); err != nil {
+		panic(err)
+	}
+	fmt.Fprintf(h, "%v->%v", op.d, op.along)
+}
+
+func (op quantileOp) Hashcode() uint32 { return simpleHash(op) }
+
+func (op quantileOp) String() string { return fmt.Sprintf("Quantile{%v}Along%v", op.q, op.along) }
+
+// DiffWRT returns true. The gradient flows to the two values the quantile is interpolated between.
+func (op quantileOp) DiffWRT(inputs int) []bool { return []bool{true} }
+
+func (op quantileOp) SymDiff(inputs Nodes, output, grad *Node) (retVal Nodes, err error) {
+	if err = checkArity(op, len(inputs)); err != nil {
+		return
+	}
+	var ret *Node
+	if ret, err = ApplyOp(quantileDiffOp{op}, inputs[0], grad); err != nil {
+		return nil, errors.Wrap(err, operationError)
+	}
+	return Nodes{ret}, nil
+}
+
+// quantileDiffOp is the derivative of a quantileOp. It takes the input of the quantileOp and the gradient of its output.
+type quantileDiffOp struct{ quantileOp }
+
+func (op quantileDiffOp) Arity() int { return 2 }
+
+func (op quantileDiffOp) Type() hm.Type {
+	a := hm.TypeVariable('a')
+	x := makeTensorType(op.d, a)
+	if op.d == 0 {
+		return hm.NewFnType(a, a, a)
+	}
+	reduced := reductionType(op.d, op.along).(*hm.FunctionType).Ret(false)
+	return hm.NewFnType(x, reduced, x)
+}
+
+func (op quantileDiffOp) InferShape(inputs ...DimSizer) (tensor.Shape, error) {
+	if err := checkArity(op, len(inputs)); err != nil {
+		return nil, err
+	}
+	s, ok := inputs[0].(tensor.Shape)
+	if !ok {
+		return nil, errors.Errorf("Expected a shape. Got %v of %T instead", inputs[0], inputs[0])
+	}
+	return s.Clone(), nil
+}
+
+func (op quantileDiffOp) Do(inputs ...Value) (retVal Value, err error) {
+	if err = checkArity(op, len(inputs)); err != nil {
+		return
+	}
+	x := inputs[0]
+	var data, grad []float64
+	if data, err = floatData(x); err != nil {
+		return nil, errors.Wrap(err, opDoFail)
+	}
+	if grad, err = floatData(inputs[1]); err != nil {
+		return nil, errors.Wrap(err, opDoFail)
+	}
+
+	groups := quantileGroups(x.Shape(), op.along, data, nanModeOf(x) == NaNIgnore)
+	if len(groups) != len(grad) {
+		return nil, errors.Errorf("Expected a gradient of %d values. Got %d instead", len(groups), len(grad))
+	}
+	ret := make([]float64, len(data))
+	for j, g := range groups {
+		g.scatter(ret, op.q, grad[j])
+	}
+	if x.Shape().IsScalar() {
+		return castValue(newF64(ret[0]), x.Dtype(), false)
+	}
+	return castValue(tensor.New(tensor.WithShape(x.Shape().Clone()...), tensor.WithBacking(ret)), x.Dtype(), false)
+}
+
+func (op quantileDiffOp) WriteHash(h hash.Hash) {
+	h.Write([]byte("quantileDiff"))
+	op.quantileOp.WriteHash(h)
+}
+
+func (op quantileDiffOp) Hashcode() uint32 { return simpleHash(op) }
+
+func (op quantileDiffOp) String() string { return fmt.Sprintf("Quantile{%v}Along%v'", op.q, op.along) }
+
+func (op quantileDiffOp) DiffWRT(inputs int) []bool { return []bool{false, false} }
+
+func (op quantileDiffOp) SymDiff(inputs Nodes, output, grad *Node) (retVal Nodes, err error) {
+	return nil, nondiffErr(op)
+}
+
+// quantileGroup is the indices of the values reduced together, sorted by value. nan is true if one of them is NaN and
+// NaNs are propagated.
+type quantileGroup struct {
+	idx []int
+	nan bool
+}
+
+// quantileGroups groups the indices of the row major data of the given shape along the axes, and sorts every group.
+func quantileGroups(shape tensor.Shape, along []int, data []float64, ignoreNaN bool) []quantileGroup {
+	var idx []int
+	var size int
+	if len(along) == 0 {
+		idx, size = make([]int, len(data)), 1
+	} else {
+		idx, size = reductionIndices(shape, along)
+	}
+	groups := make([]quantileGroup, size)
+	for i, j := range idx {
+		if math.IsNaN(data[i]) {
+			groups[j].nan = groups[j].nan || !ignoreNaN
+			continue
+		}
+		groups[j].idx = append(groups[j].idx, i)
+	}
+	for _, g := range groups {
+		sort.SliceStable(g.idx, func(a, b int) bool { return data[g.idx[a]] < data[g.idx[b]] })
+	}
+	return groups
+}
+
+// position returns the indices in the group of the two values the q-th quantile is interpolated between, and the
+// weight of the upper one.
+func (g quantileGroup) position(q float64) (lo, hi int, frac float64) {
+	pos := q * float64(len(g.idx)-1)
+	lo = int(math.Floor(pos))
+	hi = lo + 1
+	if hi >= len(g.idx) {
+		hi = lo
+	}
+	return lo, hi, pos - float64(lo)
+}
+
+func (g quantileGroup) quantile(data []float64, q float64) float64 {
+	if g.nan || len(g.idx) == 0 {
+		return math.NaN()
+	}
+	lo, hi, frac := g.position(q)
+	a, b := data[g.idx[lo]], data[g.idx[hi]]
+	if frac == 0 {
+		return a
+	}
+	return a + (b-a)*frac
+}
+
+// scatter adds the gradient of the quantile of the group to the gradient of the values.
+func (g quantileGroup) scatter(dx []float64, q, grad float64) {
+	if g.nan || len(g.idx) == 0 {
+		return
+	}
+	lo, hi, frac := g.position(q)
+	dx[g.idx[lo]] += grad * (1 - frac)
+	dx[g.idx[hi]] += grad * frac
+}
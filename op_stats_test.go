@@ -0,0 +1,122 @@
+package gorgonia
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorgonia.org/tensor"
+)
+
+func TestHistogram(t *testing.T) {
+	assert := assert.New(t)
+	g := NewGraph()
+	x := NewVector(g, Float64, WithShape(8), WithName("x"))
+	counts, edges, err := Histogram(x, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(tensor.Shape{4}, counts.Shape())
+	assert.Equal(tensor.Shape{5}, edges.Shape())
+	assert.Equal(Int, counts.Dtype())
+
+	fixed, _, err := Histogram(x, 0, HistogramBinEdges(0, 1, 10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ranged, _, err := Histogram(x, 2, HistogramRange(0, 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	auto, _, err := Histogram(x, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(tensor.Shape{4}, auto.Shape()) // Sturges' rule: 1 + log2(8)
+
+	Let(x, tensor.New(tensor.WithBacking([]float64{0, 1, 1, 2, 3, 4, math.NaN(), 10})))
+	m := NewTapeMachine(g)
+	defer m.Close()
+	if err = m.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]int{4, 2, 0, 1}, counts.Value().Data())
+	assert.Equal([]float64{0, 2.5, 5, 7.5, 10}, edges.Value().Data())
+	assert.Equal([]int{1, 6}, fixed.Value().Data())
+	assert.Equal([]int{1, 3}, ranged.Value().Data())
+
+	_, _, err = Histogram(x, 3, HistogramBinEdges(0, 1))
+	assert.Error(err)
+	_, _, err = Histogram(x, 2, HistogramRange(1, 0))
+	assert.Error(err)
+	_, _, err = Histogram(x, 0, HistogramBinEdges(1, 0))
+	assert.Error(err)
+}
+
+func TestQuantile(t *testing.T) {
+	assert := assert.New(t)
+	g := NewGraph()
+	x := NewMatrix(g, Float64, WithShape(2, 4), WithName("x"))
+	q, err := Quantile(x, 0.25, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(tensor.Shape{2}, q.Shape())
+	med, err := Median(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(med.IsScalar())
+	cols, err := Median(x, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cost := Must(Sum(q))
+	grads, err := Grad(cost, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var qVal, medVal, colsVal Value
+	Read(q, &qVal)
+	Read(med, &medVal)
+	Read(cols, &colsVal)
+
+	Let(x, tensor.New(tensor.WithShape(2, 4), tensor.WithBacking([]float64{4, 1, 3, 2, 10, 30, 20, 40})))
+	m := NewTapeMachine(g)
+	defer m.Close()
+	if err = m.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+	// the 0.25 quantile of 4 values is at position 0.75, between the two smallest values
+	assert.InDeltaSlice([]float64{1.75, 17.5}, qVal.Data(), 1e-12)
+	assert.Equal(7.0, medVal.Data())
+	assert.Equal([]float64{7, 15.5, 11.5, 21}, colsVal.Data())
+	assert.InDeltaSlice([]float64{0, 0.25, 0, 0.75, 0.25, 0, 0.75, 0}, grads[0].Value().Data(), 1e-12)
+
+	_, err = Quantile(x, 1.5)
+	assert.Error(err)
+	_, err = Quantile(x, 0.5, 2)
+	assert.Error(err)
+	_, err = Median(x, 0, 0)
+	assert.Error(err)
+}
+
+func TestQuantileNaN(t *testing.T) {
+	assert := assert.New(t)
+	data := []float32{3, float32(math.NaN()), 1, 2}
+	op := quantileOp{q: 0.5, d: 1}
+
+	v, err := op.Do(tensor.New(tensor.WithBacking(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(math.IsNaN(float64(v.Data().(float32))))
+
+	e := StandardEngine{}.WithNaNMode(NaNIgnore)
+	v, err = op.Do(tensor.New(tensor.WithBacking(data), tensor.WithEngine(e)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(float32(2), v.Data())
+}
@@ -0,0 +1,117 @@
+package gorgonia
+
+import (
+	"fmt"
+	"hash"
+
+	"github.com/chewxy/hm"
+	"github.com/pkg/errors"
+	"gorgonia.org/tensor"
+)
+
+// stopGradOp is the identity function in the forward pass, but is not differentiable with regards to its input,
+// so no gradient flows through it.
+//
+// stopGradOp deliberately does not implement ADOp: the LispMachine does not backpropagate through it either.
+type stopGradOp struct{}
+
+func (op stopGradOp) Arity() int { return 1 }
+
+// stopGradOp has this type:
+//		stopGradOp :: a → a
+func (op stopGradOp) Type() hm.Type {
+	a := hm.TypeVariable('a')
+	return hm.NewFnType(a, a)
+}
+
+func (op stopGradOp) InferShape(inputs ...DimSizer) (tensor.Shape, error) {
+	if err := checkArity(op, len(inputs)); err != nil {
+		return nil, err
+	}
+	s, ok := inputs[0].(tensor.Shape)
+	if !ok {
+		return nil, errors.Errorf("Expected a shape. Got %v of %T instead", inputs[0], inputs[0])
+	}
+	return s.Clone(), nil
+}
+
+// Do returns a copy of the input, so that ops that overwrite their inputs downstream do not clobber the input.
+func (op stopGradOp) Do(inputs ...Value) (retVal Value, err error) {
+	if err = checkArity(op, len(inputs)); err != nil {
+		return
+	}
+	return CloneValue(inputs[0])
+}
+
+func (op stopGradOp) UsePreallocDo(prealloc Value, inputs ...Value) (retVal Value, err error) {
+	if err = checkArity(op, len(inputs)); err != nil {
+		return
+	}
+	return Copy(prealloc, inputs[0])
+}
+
+func (op stopGradOp) ReturnsPtr() bool     { return false }
+func (op stopGradOp) CallsExtern() bool    { return false }
+func (op stopGradOp) OverwritesInput() int { return -1 }
+
+func (op stopGradOp) WriteHash(h hash.Hash) { h.Write([]byte("StopGrad")) }
+
+func (op stopGradOp) Hashcode() uint32 { return simpleHash(op) }
+
+func (op stopGradOp) String() string { return "StopGrad" }
+
+func (op stopGradOp) DiffWRT(inputs int) []bool { return []bool{false} }
+
+func (op stopGradOp) SymDiff(inputs Nodes, output, grad *Node) (retVal Nodes, err error) {
+	return nil, nondiffErr(op)
+}
+
+// gradReversalOp is the identity function in the forward pass. In the backward pass, it multiplies the gradient by
+// -lambda. This is the gradient reversal layer of domain adversarial training (Ganin and Lempitsky, 2015).
+type gradReversalOp struct {
+	lambda float64
+}
+
+func (op gradReversalOp) Arity() int { return 1 }
+
+// gradReversalOp has this type:
+//		gradReversalOp :: a → a
+func (op gradReversalOp) Type() hm.Type { return stopGradOp{}.Type() }
+
+func (op gradReversalOp) InferShape(inputs ...DimSizer) (tensor.Shape, error) {
+	return stopGradOp{}.InferShape(inputs...)
+}
+
+func (op gradReversalOp) Do(inputs ...Value) (retVal Value, err error) {
+	return stopGradOp{}.Do(inputs...)
+}
+
+func (op gradReversalOp) UsePreallocDo(prealloc Value, inputs ...Value) (retVal Value, err error) {
+	return stopGradOp{}.UsePreallocDo(prealloc, inputs...)
+}
+
+func (op gradReversalOp) ReturnsPtr() bool     { return false }
+func (op gradReversalOp) CallsExtern() bool    { return false }
+func (op gradReversalOp) OverwritesInput() int { return -1 }
+
+func (op gradReversalOp) WriteHash(h hash.Hash) { fmt.Fprintf(h, "GradReversal{%v}", op.lambda) }
+
+func (op gradReversalOp) Hashcode() uint32 { return simpleHash(op) }
+
+func (op gradReversalOp) String() string { return fmt.Sprintf("GradReversal{%v}", op.lambda) }
+
+func (op gradReversalOp) DiffWRT(inputs int) []bool { return []bool{true} }
+
+func (op gradReversalOp) SymDiff(inputs Nodes, output, grad *Node) (retVal Nodes, err error) {
+	if err = checkArity(op, len(inputs)); err != nil {
+		return
+	}
+	var neg, ret *Node
+	if neg, err = floatConstant(grad.Dtype(), -op.lambda); err != nil {
+		return nil, err
+	}
+	if ret, err = HadamardProd(grad, neg); err != nil {
+		return nil, errors.Wrap(err, hadamardProdFail)
+	}
+	return Nodes{ret}, nil
+}
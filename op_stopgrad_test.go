@@ -0,0 +1,71 @@
+package gorgonia
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorgonia.org/tensor"
+)
+
+func stopGradGraph() (g *ExprGraph, x, y, z, sx, cost *Node) {
+	g = NewGraph()
+	x = NewVector(g, Float64, WithShape(3), WithName("x"), WithValue(tensor.New(tensor.WithBacking([]float64{1, 2, 3}))))
+	y = NewVector(g, Float64, WithShape(3), WithName("y"), WithValue(tensor.New(tensor.WithBacking([]float64{4, 5, 6}))))
+	z = NewVector(g, Float64, WithShape(3), WithName("z"), WithValue(tensor.New(tensor.WithBacking([]float64{7, 8, 9}))))
+	sx = Must(StopGrad(x))
+	cost = Must(Sum(Must(Add(Must(HadamardProd(x, y)), Must(HadamardProd(sx, z))))))
+	return
+}
+
+func TestStopGrad(t *testing.T) {
+	assert := assert.New(t)
+	g, x, y, z, sx, cost := stopGradGraph()
+	assert.True(sx.Shape().Eq(x.Shape()))
+	assert.Equal(x.Dtype(), sx.Dtype())
+
+	if _, err := Grad(cost, x, y, z); err != nil {
+		t.Fatal(err)
+	}
+	m := NewTapeMachine(g)
+	defer m.Close()
+	if err := m.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]float64{1, 2, 3}, sx.Value().Data())
+	assert.Equal(82.0, cost.Value().Data())
+
+	grads := map[*Node][]float64{
+		x: {4, 5, 6}, // only through x*y
+		y: {1, 2, 3},
+		z: {1, 2, 3},
+	}
+	for n, want := range grads {
+		grad, err := n.Grad()
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(want, grad.Data(), "gradient of %v", n)
+	}
+
+	// a node whose only path to the cost is stopped cannot be differentiated
+	g = NewGraph()
+	a := NewScalar(g, Float64, WithName("a"))
+	_, err := Grad(Must(Square(Must(StopGrad(a)))), a)
+	assert.Error(err)
+}
+
+func TestStopGrad_LispMachine(t *testing.T) {
+	assert := assert.New(t)
+	g, x, _, _, _, cost := stopGradGraph()
+	m := NewLispMachine(g)
+	defer m.Close()
+	if err := m.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(82.0, cost.Value().Data())
+	grad, err := x.Grad()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]float64{4, 5, 6}, grad.Data())
+}
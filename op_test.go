@@ -1,6 +1,11 @@
 package gorgonia
 
-import "testing"
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorgonia.org/tensor"
+)
 
 func TestStupid(t *testing.T) {
 	g := NewGraph()
@@ -49,3 +54,70 @@ func TestOpEquality(t *testing.T) {
 		t.Error("oops")
 	}
 }
+
+func TestUnsafeDoInto(t *testing.T) {
+	g := NewGraph()
+	x := NewVector(g, Float64, WithShape(3))
+	y := NewVector(g, Float64, WithShape(3))
+	xv := tensor.New(tensor.WithBacking([]float64{1, 2, 3}))
+	yv := tensor.New(tensor.WithBacking([]float64{4, 5, 6}))
+
+	// binary - UsePreallocDoer
+	dst := tensor.New(tensor.WithShape(3), tensor.Of(Float64))
+	ret, err := UnsafeDoInto(newElemBinOp(addOpType, x, y), dst, xv, yv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ret != dst {
+		t.Errorf("Expected result to be written into dst")
+	}
+	assert.Equal(t, []float64{5, 7, 9}, dst.Data())
+
+	// unary - UsePreallocDoer
+	ret, err = UnsafeDoInto(newElemUnaryOp(negOpType, x), dst, xv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ret != dst {
+		t.Errorf("Expected result to be written into dst")
+	}
+	assert.Equal(t, []float64{-1, -2, -3}, dst.Data())
+	assert.Equal(t, []float64{1, 2, 3}, xv.Data(), "inputs should not be clobbered")
+
+	// not a UsePreallocDoer - copy
+	sum := newSumOp([]int{0}, x.Shape(), 1)
+	sdst := newF64(0)
+	if ret, err = UnsafeDoInto(sum, sdst, xv); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 6.0, sdst.Data())
+
+	if _, err = UnsafeDoInto(sum, nil, xv); err == nil {
+		t.Errorf("Expected an error when dst is nil")
+	}
+
+	// the generated integer kernels write into dst
+	i := NewVector(g, Int32, WithShape(3))
+	iv := tensor.New(tensor.WithBacking([]int32{1, 2, 3}))
+	idst := tensor.New(tensor.WithShape(3), tensor.Of(Int32))
+	if ret, err = UnsafeDoInto(newBitwiseOp(bitOrOpType, i, i), idst, iv, iv); err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, ret == idst)
+	assert.Equal(t, []int32{1, 2, 3}, idst.Data())
+
+	j := NewVector(g, tensor.Int8, WithShape(3))
+	jv := tensor.New(tensor.WithBacking([]int8{100, -100, 3}))
+	jdst := tensor.New(tensor.WithShape(3), tensor.Of(tensor.Int8))
+	if ret, err = UnsafeDoInto(newIntArithOp(satAddOpType, j, j), jdst, jv, jv); err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, ret == jdst)
+	assert.Equal(t, []int8{127, -128, 6}, jdst.Data())
+
+	// the errors of the UsePreallocDoers are returned
+	_, err = UnsafeDoInto(newBitwiseOp(bitXorOpType, i, i), tensor.New(tensor.WithShape(2), tensor.Of(Int32)), iv, iv)
+	assert.Error(t, err, "dst is of the wrong shape")
+	_, err = UnsafeDoInto(newElemUnaryOp(negOpType, x), tensor.New(tensor.WithShape(2), tensor.Of(Float64)), xv)
+	assert.Error(t, err, "dst is of the wrong shape")
+}
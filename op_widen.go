@@ -0,0 +1,159 @@
+package gorgonia
+
+/*
+This file holds the Ops that accumulate small integers into wider dtypes. The kernels are generated by genapi into
+operatorWiden_gen.go
+*/
+
+import (
+	"fmt"
+	"hash"
+	"reflect"
+
+	"github.com/chewxy/hm"
+	"github.com/pkg/errors"
+	"gorgonia.org/tensor"
+)
+
+type wideOpType byte
+
+const (
+	wideSumOpType wideOpType = iota
+	wideDotOpType
+	wideAddScalarOpType
+)
+
+var wideOpNames = [...]string{
+	wideSumOpType:       "WideSum",
+	wideDotOpType:       "WideDot",
+	wideAddScalarOpType: "WideAddScalar",
+}
+
+func (o wideOpType) String() string { return wideOpNames[o] }
+
+// wideInputs are the dtypes that can be accumulated into the wideAccs.
+var (
+	wideInputs = []tensor.Dtype{tensor.Int8, tensor.Int16}
+	wideAccs   = []tensor.Dtype{tensor.Int32, tensor.Int64}
+)
+
+// wideOp computes on small integers with a wider accumulator dtype, so that the results do not overflow the dtype of
+// the inputs.
+type wideOp struct {
+	ʘ       wideOpType
+	in, acc tensor.Dtype
+	d       int // the dims of the first input
+}
+
+func (op wideOp) Arity() int {
+	if op.ʘ == wideSumOpType {
+		return 1
+	}
+	return 2
+}
+
+// wideOp has one of these types, where a is the input dtype and b the accumulator dtype:
+//		wideSum :: Tensor-n a → b
+//		wideDot :: Vector a → Vector a → b
+//		wideAddScalar :: Tensor-n a → b → Tensor-n b
+func (op wideOp) Type() hm.Type {
+	in := makeTensorType(op.d, op.in)
+	switch op.ʘ {
+	case wideSumOpType:
+		return hm.NewFnType(in, op.acc)
+	case wideDotOpType:
+		return hm.NewFnType(in, in, op.acc)
+	}
+	return hm.NewFnType(in, op.acc, makeTensorType(op.d, op.acc))
+}
+
+func (op wideOp) InferShape(inputs ...DimSizer) (tensor.Shape, error) {
+	if err := checkArity(op, len(inputs)); err != nil {
+		return nil, err
+	}
+	a, ok := inputs[0].(tensor.Shape)
+	if !ok {
+		return nil, errors.Errorf("Expected a shape. Got %v of %T instead", inputs[0], inputs[0])
+	}
+	switch op.ʘ {
+	case wideSumOpType:
+		return scalarShape, nil
+	case wideDotOpType:
+		if b, ok := inputs[1].(tensor.Shape); !ok || !a.Eq(b) {
+			return nil, errors.Errorf("Shape mismatch: %v and %v", a, inputs[1])
+		}
+		return scalarShape, nil
+	}
+	return a.Clone(), nil
+}
+
+func (op wideOp) Do(inputs ...Value) (retVal Value, err error) {
+	if err = checkArity(op, len(inputs)); err != nil {
+		return
+	}
+	var a, b reflect.Value
+	if a, err = valueSlice(inputs[0]); err != nil {
+		return nil, errors.Wrap(err, opDoFail)
+	}
+	if len(inputs) > 1 {
+		if b, err = valueSlice(inputs[1]); err != nil {
+			return nil, errors.Wrap(err, opDoFail)
+		}
+	}
+
+	var r interface{}
+	switch op.ʘ {
+	case wideSumOpType:
+		r, err = wideSumKernel(a.Interface(), op.acc)
+	case wideDotOpType:
+		if a.Len() != b.Len() {
+			return nil, errors.Errorf("Shape mismatch: %v and %v", inputs[0].Shape(), inputs[1].Shape())
+		}
+		r, err = wideDotKernel(a.Interface(), b.Interface(), op.acc)
+	case wideAddScalarOpType:
+		ret := reflect.MakeSlice(reflect.SliceOf(op.acc.Type), a.Len(), a.Len())
+		if err = wideAddScalarKernel(a.Interface(), b.Index(0).Interface(), ret.Interface()); err != nil {
+			return nil, errors.Wrap(err, opDoFail)
+		}
+		return tensor.New(tensor.WithShape(inputs[0].Shape().Clone()...), tensor.WithBacking(ret.Interface())), nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, opDoFail)
+	}
+	retVal, _ = anyToScalar(r)
+	return
+}
+
+func (op wideOp) ReturnsPtr() bool     { return false }
+func (op wideOp) CallsExtern() bool    { return false }
+func (op wideOp) OverwritesInput() int { return -1 }
+
+func (op wideOp) WriteHash(h hash.Hash) { fmt.Fprintf(h, "%v %v→%v %d", op.ʘ, op.in, op.acc, op.d) }
+
+func (op wideOp) Hashcode() uint32 { return simpleHash(op) }
+
+func (op wideOp) String() string { return fmt.Sprintf("%v→%v", op.ʘ, op.acc) }
+
+func (op wideOp) DiffWRT(inputs int) []bool { return make([]bool, inputs) }
+
+func (op wideOp) SymDiff(inputs Nodes, output, grad *Node) (retVal Nodes, err error) {
+	return nil, nondiffErr(op)
+}
+
+func wideOpNode(ot wideOpType, acc tensor.Dtype, a *Node, others ...*Node) (retVal *Node, err error) {
+	var in tensor.Dtype
+	if in, err = dtypeOf(a.t); err != nil {
+		return nil, errors.Wrap(err, dtypeOfFail)
+	}
+	if !dtypeIn(in, wideInputs) {
+		return nil, errors.Errorf("%v accumulates %v. Got %v of %v instead", ot, wideInputs, a, in)
+	}
+	if !dtypeIn(acc, wideAccs) {
+		return nil, errors.Errorf("%v accumulates into %v. Got %v instead", ot, wideAccs, acc)
+	}
+	if a.IsScalar() {
+		return nil, errors.Errorf("%v expects a tensor. Got the scalar %v instead", ot, a)
+	}
+	op := wideOp{ʘ: ot, in: in, acc: acc, d: a.Dims()}
+	return ApplyOp(op, append(Nodes{a}, others...)...)
+}
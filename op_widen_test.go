@@ -0,0 +1,40 @@
+package gorgonia
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorgonia.org/tensor"
+)
+
+func TestWideOps(t *testing.T) {
+	assert := assert.New(t)
+	g := NewGraph()
+	a := NewVector(g, tensor.Int8, WithShape(4), WithName("a"), WithValue(tensor.New(tensor.WithBacking([]int8{127, 127, 127, -1}))))
+	b := NewVector(g, tensor.Int8, WithShape(4), WithName("b"), WithValue(tensor.New(tensor.WithBacking([]int8{127, 127, -128, 2}))))
+	c := NewMatrix(g, tensor.Int16, WithShape(2, 2), WithName("c"), WithValue(tensor.New(tensor.WithShape(2, 2), tensor.WithBacking([]int16{32767, 32767, 32767, 32767}))))
+	bias := NewScalar(g, tensor.Int32, WithName("bias"), WithValue(newI32(1000)))
+
+	sum := Must(WideSum(a, tensor.Int32))
+	sum64 := Must(WideSum(c, tensor.Int64))
+	dot := Must(WideDot(a, b, tensor.Int32))
+	biased := Must(WideAddScalar(a, bias))
+
+	m := NewTapeMachine(g)
+	defer m.Close()
+	if err := m.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(int32(380), sum.Value().Data())
+	assert.Equal(int64(4*32767), sum64.Value().Data())
+	assert.Equal(int32(127*127*2-127*128-2), dot.Value().Data())
+	assert.Equal([]int32{1127, 1127, 1127, 999}, biased.Value().Data())
+	assert.Equal(tensor.Int32, biased.Dtype())
+
+	_, err := WideSum(a, tensor.Int16)
+	assert.Error(err, "the accumulator has to be wider")
+	_, err = WideSum(NewVector(g, Float64, WithShape(2), WithName("f")), tensor.Int64)
+	assert.Error(err)
+	_, err = WideDot(a, c, tensor.Int64)
+	assert.Error(err)
+}
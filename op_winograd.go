@@ -0,0 +1,504 @@
+package gorgonia
+
+import (
+	"fmt"
+	"hash"
+
+	"github.com/chewxy/hm"
+	"github.com/pkg/errors"
+	"gonum.org/v1/gonum/blas"
+	"gorgonia.org/tensor"
+)
+
+/*
+This file holds the Winograd convolution of 3x3 kernels. Instead of a matmul of the im2col columns, the tiles of the
+image and the filters are transformed so that the convolution of a m×m tile of the output is an elementwise product of
+(m+2)×(m+2) tiles: F(2x2, 3x3) needs 16 multiplications per 4 outputs and F(4x4, 3x3) needs 36 per 16 outputs, instead
+of 36 and 144. The elementwise products of all the tiles and channels are (m+2)² matmuls.
+
+Conv2d uses it for the float32 convolutions of NCHW images by 3x3 kernels of stride and dilation 1. The transforms
+are computed in float64, as the transforms of F(4x4, 3x3) lose too much precision in float32, so the results are as
+precise as the im2col ones. The float64 convolutions keep the im2col path, whose results are exact for the small
+integers, while the fractions of the transforms are not.
+*/
+
+// The transforms of F(2x2, 3x3), as in "Fast Algorithms for Convolutional Neural Networks" by Lavin and Gray.
+var (
+	winogradBT2 = makeWinogradMatrix(4, 4, []float64{
+		1, 0, -1, 0,
+		0, 1, 1, 0,
+		0, -1, 1, 0,
+		0, 1, 0, -1,
+	}).withTransform(winogradBT2Vec)
+	winogradG2 = makeWinogradMatrix(4, 3, []float64{
+		1, 0, 0,
+		0.5, 0.5, 0.5,
+		0.5, -0.5, 0.5,
+		0, 0, 1,
+	})
+	winogradAT2 = makeWinogradMatrix(2, 4, []float64{
+		1, 1, 1, 0,
+		0, 1, -1, -1,
+	}).withTransform(winogradAT2Vec)
+)
+
+// The transforms of F(4x4, 3x3).
+var (
+	winogradBT4 = makeWinogradMatrix(6, 6, []float64{
+		4, 0, -5, 0, 1, 0,
+		0, -4, -4, 1, 1, 0,
+		0, 4, -4, -1, 1, 0,
+		0, -2, -1, 2, 1, 0,
+		0, 2, -1, -2, 1, 0,
+		0, 4, 0, -5, 0, 1,
+	}).withTransform(winogradBT4Vec)
+	winogradG4 = makeWinogradMatrix(6, 3, []float64{
+		1.0 / 4, 0, 0,
+		-1.0 / 6, -1.0 / 6, -1.0 / 6,
+		-1.0 / 6, 1.0 / 6, -1.0 / 6,
+		1.0 / 24, 1.0 / 12, 1.0 / 6,
+		1.0 / 24, -1.0 / 12, 1.0 / 6,
+		0, 0, 1,
+	})
+	winogradAT4 = makeWinogradMatrix(4, 6, []float64{
+		1, 1, 1, 1, 1, 0,
+		0, 1, -1, 2, -2, 0,
+		0, 1, 1, 4, 4, 0,
+		0, 1, -1, 8, -8, 1,
+	}).withTransform(winogradAT4Vec)
+)
+
+// winogradOp is the convolution of a BCHW image by a (out channels, channels, 3, 3) filter, with a stride and a
+// dilation of 1, computed with F(m×m, 3×3) where m is 2 or 4.
+type winogradOp struct {
+	m          int
+	padH, padW int
+}
+
+// winogradTile picks F(4x4, 3x3) for the outputs that have at least four rows and columns, as the transforms of the
+// smaller outputs are mostly padding.
+func winogradTile(outH, outW int) int {
+	if outH >= 4 && outW >= 4 {
+		return 4
+	}
+	return 2
+}
+
+// canWinograd checks that the convolution can be computed by winogradOp.
+func canWinograd(im, filter *Node, kernelShape tensor.Shape, pad, stride, dilation []int) bool {
+	if im.Dims() != 4 || filter.Dims() != 4 || len(pad) != 2 || len(stride) != 2 || len(dilation) != 2 {
+		return false
+	}
+	if kernelShape.Dims() != 2 || kernelShape[0] != 3 || kernelShape[1] != 3 {
+		return false
+	}
+	if stride[0] != 1 || stride[1] != 1 || dilation[0] != 1 || dilation[1] != 1 {
+		return false
+	}
+	fs := filter.Shape()
+	if fs[1] != im.Shape()[1] || fs[2] != 3 || fs[3] != 3 || im.Dtype() != Float32 || filter.Dtype() != Float32 {
+		return false
+	}
+	return im.Shape()[2]+2*pad[0]-2 > 0 && im.Shape()[3]+2*pad[1]-2 > 0
+}
+
+func (op winogradOp) Arity() int { return 2 }
+
+// winogradOp has this type:
+//		winogradOp :: (Floats a) ⇒ Tensor-4 a → Tensor-4 a → Tensor-4 a
+func (op winogradOp) Type() hm.Type {
+	t := makeTensorType(4, hm.TypeVariable('a'))
+	return hm.NewFnType(t, t, t)
+}
+
+func (op winogradOp) InferShape(inputs ...DimSizer) (tensor.Shape, error) {
+	if err := checkArity(op, len(inputs)); err != nil {
+		return nil, err
+	}
+	im, ok := inputs[0].(tensor.Shape)
+	if !ok {
+		return nil, errors.Errorf("Expected a shape. Got %v of %T instead", inputs[0], inputs[0])
+	}
+	filter, ok := inputs[1].(tensor.Shape)
+	if !ok {
+		return nil, errors.Errorf("Expected a shape. Got %v of %T instead", inputs[1], inputs[1])
+	}
+	return op.calcShape(im, filter)
+}
+
+func (op winogradOp) calcShape(im, filter tensor.Shape) (tensor.Shape, error) {
+	if im.Dims() != 4 || filter.Dims() != 4 {
+		return nil, errors.Errorf("Expected a BCHW image and a 4D filter. Got %v and %v instead", im, filter)
+	}
+	if filter[1] != im[1] || filter[2] != 3 || filter[3] != 3 {
+		return nil, errors.Errorf("Expected a filter of shape (_, %d, 3, 3). Got %v instead", im[1], filter)
+	}
+	return tensor.Shape{im[0], filter[0], im[2] + 2*op.padH - 2, im[3] + 2*op.padW - 2}, nil
+}
+
+func (op winogradOp) Do(inputs ...Value) (retVal Value, err error) {
+	if err = checkArity(op, len(inputs)); err != nil {
+		return
+	}
+	im, filter := inputs[0], inputs[1]
+	var s tensor.Shape
+	if s, err = op.calcShape(im.Shape(), filter.Shape()); err != nil {
+		return nil, err
+	}
+	if im.Dtype() != filter.Dtype() {
+		return nil, errors.Errorf("Expected a filter of Dtype %v. Got %v instead", im.Dtype(), filter.Dtype())
+	}
+	ret := make([]float64, s.TotalSize())
+	switch im.Dtype() {
+	case tensor.Float64:
+		op.f64s(im.Shape(), s, im.Data().([]float64), filter.Data().([]float64), ret)
+		return tensor.New(tensor.WithShape(s...), tensor.WithBacking(ret)), nil
+	case tensor.Float32:
+		op.f64s(im.Shape(), s, widenF32s(im.Data().([]float32)), widenF32s(filter.Data().([]float32)), ret)
+		ret32 := make([]float32, len(ret))
+		for i, v := range ret {
+			ret32[i] = float32(v)
+		}
+		return tensor.New(tensor.WithShape(s...), tensor.WithBacking(ret32)), nil
+	}
+	return nil, errors.Errorf(nyiFail, "winograd", im.Dtype())
+}
+
+func widenF32s(a []float32) []float64 {
+	retVal := make([]float64, len(a))
+	for i, v := range a {
+		retVal[i] = float64(v)
+	}
+	return retVal
+}
+
+// f64s convolves the image x by the filter f into ret, of shape s.
+func (op winogradOp) f64s(imShape, s tensor.Shape, x, f, ret []float64) {
+	bt, g, at := winogradBT2, winogradG2, winogradAT2
+	if op.m == 4 {
+		bt, g, at = winogradBT4, winogradG4, winogradAT4
+	}
+	m := op.m
+	a := m + 2
+	batches, chans, h, w := imShape[0], imShape[1], imShape[2], imShape[3]
+	layers, outH, outW := s[1], s[2], s[3]
+	tilesH, tilesW := (outH+m-1)/m, (outW+m-1)/m
+	tiles := batches * tilesH * tilesW
+
+	// the filters: u[ξ][o][c] = (G f[o][c] Gᵀ)[ξ]
+	u := make([]float64, a*a*layers*chans)
+	gf := make([]float64, a*3)
+	tile := make([]float64, a*a)
+	for o := 0; o < layers; o++ {
+		for c := 0; c < chans; c++ {
+			g.mul(f[(o*chans+c)*9:(o*chans+c+1)*9], 3, gf)
+			g.mulT(gf, a, tile)
+			for xi, v := range tile {
+				u[(xi*layers+o)*chans+c] = v
+			}
+		}
+	}
+
+	// the tiles of the image: v[ξ][c][p] = (Bᵀ d Bᵀᵀ)[ξ], where d is the a×a patch of the tile p
+	v := make([]float64, a*a*chans*tiles)
+	d := make([]float64, a*a)
+	bd := make([]float64, a*a)
+	for b := 0; b < batches; b++ {
+		for c := 0; c < chans; c++ {
+			img := x[(b*chans+c)*h*w : (b*chans+c+1)*h*w]
+			for ty := 0; ty < tilesH; ty++ {
+				for tx := 0; tx < tilesW; tx++ {
+					for i := 0; i < a; i++ {
+						row := ty*m + i - op.padH
+						for j := 0; j < a; j++ {
+							col := tx*m + j - op.padW
+							if row < 0 || row >= h || col < 0 || col >= w {
+								d[i*a+j] = 0
+							} else {
+								d[i*a+j] = img[row*w+col]
+							}
+						}
+					}
+					bt.mul(d, a, bd)
+					bt.mulT(bd, a, tile)
+					p := (b*tilesH+ty)*tilesW + tx
+					for xi, val := range tile {
+						v[(xi*chans+c)*tiles+p] = val
+					}
+				}
+			}
+		}
+	}
+
+	// the elementwise products, summed over the channels: mm[ξ] = u[ξ] v[ξ]
+	mm := make([]float64, a*a*layers*tiles)
+	for xi := 0; xi < a*a; xi++ {
+		whichblas.Dgemm(blas.NoTrans, blas.NoTrans, layers, tiles, chans, 1,
+			u[xi*layers*chans:(xi+1)*layers*chans], chans,
+			v[xi*chans*tiles:(xi+1)*chans*tiles], tiles,
+			0, mm[xi*layers*tiles:(xi+1)*layers*tiles], tiles)
+	}
+
+	// the outputs: y = Aᵀ mm Aᵀᵀ
+	am := make([]float64, m*a)
+	y := make([]float64, m*m)
+	for o := 0; o < layers; o++ {
+		for p := 0; p < tiles; p++ {
+			for xi := range tile {
+				tile[xi] = mm[(xi*layers+o)*tiles+p]
+			}
+			at.mul(tile, a, am)
+			at.mulT(am, m, y)
+			b, t := p/(tilesH*tilesW), p%(tilesH*tilesW)
+			ty, tx := t/tilesW, t%tilesW
+			out := ret[(b*layers+o)*outH*outW : (b*layers+o+1)*outH*outW]
+			for i := 0; i < m && ty*m+i < outH; i++ {
+				for j := 0; j < m && tx*m+j < outW; j++ {
+					out[(ty*m+i)*outW+tx*m+j] = y[i*m+j]
+				}
+			}
+		}
+	}
+}
+
+// winogradMatrix is a sparse transform matrix: most of the coefficients of the transforms are 0.
+type winogradMatrix struct {
+	rows, cols int
+	index      [][]int     // the columns of the non-zero coefficients of every row
+	coef       [][]float64 // the non-zero coefficients of every row
+
+	// transform is the unrolled product of the matrix by a strided vector, used instead of the coefficients if set
+	transform func(x []float64, xs int, ret []float64, rs int)
+}
+
+func makeWinogradMatrix(rows, cols int, dense []float64) winogradMatrix {
+	retVal := winogradMatrix{rows: rows, cols: cols, index: make([][]int, rows), coef: make([][]float64, rows)}
+	for i := 0; i < rows; i++ {
+		for j, v := range dense[i*cols : (i+1)*cols] {
+			if v != 0 {
+				retVal.index[i] = append(retVal.index[i], j)
+				retVal.coef[i] = append(retVal.coef[i], v)
+			}
+		}
+	}
+	return retVal
+}
+
+func (t winogradMatrix) withTransform(fn func(x []float64, xs int, ret []float64, rs int)) winogradMatrix {
+	t.transform = fn
+	return t
+}
+
+// mul computes the (rows, cols) × (cols, c) matrix product t y into ret.
+func (t winogradMatrix) mul(y []float64, c int, ret []float64) {
+	if t.transform != nil {
+		for j := 0; j < c; j++ {
+			t.transform(y[j:], c, ret[j:], c)
+		}
+		return
+	}
+	for i := 0; i < t.rows; i++ {
+		r := ret[i*c : (i+1)*c]
+		for j := range r {
+			r[j] = 0
+		}
+		for k, l := range t.index[i] {
+			w := t.coef[i][k]
+			for j, v := range y[l*c : (l+1)*c] {
+				r[j] += w * v
+			}
+		}
+	}
+}
+
+// mulT computes the (r, cols) × (rows, cols)ᵀ matrix product x tᵀ into ret.
+func (t winogradMatrix) mulT(x []float64, r int, ret []float64) {
+	if t.transform != nil {
+		for i := 0; i < r; i++ {
+			t.transform(x[i*t.cols:], 1, ret[i*t.rows:], 1)
+		}
+		return
+	}
+	for i := 0; i < r; i++ {
+		row := x[i*t.cols : (i+1)*t.cols]
+		for j := 0; j < t.rows; j++ {
+			var sum float64
+			for k, l := range t.index[j] {
+				sum += row[l] * t.coef[j][k]
+			}
+			ret[i*t.rows+j] = sum
+		}
+	}
+}
+
+func (op winogradOp) ReturnsPtr() bool     { return false }
+func (op winogradOp) CallsExtern() bool    { return false }
+func (op winogradOp) OverwritesInput() int { return -1 }
+
+func (op winogradOp) WriteHash(h hash.Hash) {
+	fmt.Fprintf(h, "winograd:%d-%d-%d", op.m, op.padH, op.padW)
+}
+
+func (op winogradOp) Hashcode() uint32 { return simpleHash(op) }
+
+func (op winogradOp) String() string {
+	return fmt.Sprintf("Winograd F(%dx%d, 3x3)<(%d, %d)>", op.m, op.m, op.padH, op.padW)
+}
+
+func (op winogradOp) DiffWRT(inputs int) []bool { return []bool{true, true} }
+
+// SymDiff builds the gradients with the im2col operations: the gradient of the image is the transposed convolution
+// of the gradient, and the gradient of the filter is the product of the gradient and the columns of the image.
+func (op winogradOp) SymDiff(inputs Nodes, output, grad *Node) (retVal Nodes, err error) {
+	if err = checkArity(op, len(inputs)); err != nil {
+		return
+	}
+	im, filter := inputs[0], inputs[1]
+	kernel := tensor.Shape{3, 3}
+	pad := []int{op.padH, op.padW}
+	unit := []int{1, 1}
+
+	var dIm *Node
+	if dIm, err = ConvTranspose2d(grad, filter, kernel, pad, unit, unit, nil); err != nil {
+		return nil, errors.Wrap(err, operationError)
+	}
+
+	var cols, patch, g, dFilter *Node
+	if cols, err = Im2Col(im, kernel, tensor.Shape(pad), tensor.Shape(unit), tensor.Shape(unit)); err != nil {
+		return nil, errors.Wrap(err, operationError)
+	}
+	cs := cols.Shape()
+	if patch, err = Reshape(cols, tensor.Shape{cs[0] * cs[1] * cs[2], cs[3]}); err != nil {
+		return nil, errors.Wrap(err, operationError)
+	}
+	if g, err = Transpose(grad, 0, 2, 3, 1); err != nil {
+		return nil, errors.Wrap(err, operationError)
+	}
+	if g, err = Reshape(g, tensor.Shape{cs[0] * cs[1] * cs[2], filter.Shape()[0]}); err != nil {
+		return nil, errors.Wrap(err, operationError)
+	}
+	mm := linAlgBinOp{āBinaryOperator: matMulOperator, transA: true}
+	if dFilter, err = ApplyOp(mm, g, patch); err != nil {
+		return nil, errors.Wrap(err, operationError)
+	}
+	if dFilter, err = Reshape(dFilter, filter.Shape().Clone()); err != nil {
+		return nil, errors.Wrap(err, operationError)
+	}
+	return Nodes{dIm, dFilter}, nil
+}
+
+// DoDiff computes the gradients of SymDiff eagerly, for the LispMachine.
+func (op winogradOp) DoDiff(ctx ExecutionContext, inputs Nodes, output *Node) (err error) {
+	if err = checkArity(op, len(inputs)); err != nil {
+		return
+	}
+	imdv, fdv := getDV(inputs[0], inputs[1])
+	odv := output.boundTo.(*dualValue)
+	im, ok1 := imdv.Value.(tensor.Tensor)
+	filter, ok2 := fdv.Value.(tensor.Tensor)
+	grad, ok3 := odv.d.(tensor.Tensor)
+	if !ok1 || !ok2 || !ok3 {
+		return errors.Errorf("Expected the tensors of the image, the filter and the gradient. Got %T, %T and %T instead", imdv.Value, fdv.Value, odv.d)
+	}
+	var dIm, dFilter tensor.Tensor
+	if dIm, dFilter, err = op.grads(im, filter, grad); err != nil {
+		return errors.Wrapf(err, autodiffFail, op)
+	}
+	for _, d := range []struct {
+		dv   *dualValue
+		grad Value
+	}{{imdv, dIm}, {fdv, dFilter}} {
+		add := newEBOByType(addOpType, TypeOf(d.dv.d), TypeOf(d.grad))
+		if _, err = add.UnsafeDo(d.dv.d, d.grad); err != nil {
+			return errors.Wrapf(err, unsafeDoFail, add)
+		}
+	}
+	return nil
+}
+
+// grads computes the values of the gradients of SymDiff: the col2im of the product of the gradient and the filter, and
+// the product of the gradient and the columns of the image.
+func (op winogradOp) grads(im, filter, grad tensor.Tensor) (dIm, dFilter tensor.Tensor, err error) {
+	s, fs, gs := im.Shape(), filter.Shape(), grad.Shape()
+	rows, z := gs[0]*gs[2]*gs[3], fs[1]*fs[2]*fs[3]
+	cols := makeIm2ColOp(3, 3, op.padH, op.padW, 1, 1, 1, 1)
+
+	// the gradient as a matrix (b·h·w, out channels), and the filter as a matrix (out channels, c·3·3)
+	var g, f, dCols tensor.Tensor
+	if g, err = tensor.T(grad, 0, 2, 3, 1); err != nil {
+		return nil, nil, err
+	}
+	if err = g.Reshape(rows, fs[0]); err != nil {
+		return nil, nil, err
+	}
+	f = filter.Clone().(tensor.Tensor)
+	if err = f.Reshape(fs[0], z); err != nil {
+		return nil, nil, err
+	}
+	if dCols, err = tensor.MatMul(g, f); err != nil {
+		return nil, nil, err
+	}
+	if err = dCols.Reshape(gs[0], gs[2], gs[3], z); err != nil {
+		return nil, nil, err
+	}
+	c2i := col2imOp{unpaddedB: s[0], unpaddedC: s[1], unpaddedH: s[2], unpaddedW: s[3], im2colOp: cols}
+	var v Value
+	if v, err = c2i.Do(dCols); err != nil {
+		return nil, nil, err
+	}
+	dIm = v.(tensor.Tensor)
+
+	if v, err = cols.Do(im); err != nil {
+		return nil, nil, err
+	}
+	patch := v.(tensor.Tensor)
+	if err = patch.Reshape(rows, z); err != nil {
+		return nil, nil, err
+	}
+	var gT tensor.Tensor
+	if gT, err = tensor.T(g); err != nil {
+		return nil, nil, err
+	}
+	if dFilter, err = tensor.MatMul(gT, patch); err != nil {
+		return nil, nil, err
+	}
+	if err = dFilter.Reshape(fs...); err != nil {
+		return nil, nil, err
+	}
+	return dIm, dFilter, nil
+}
+
+// The unrolled transforms of the tiles of the images and of the outputs.
+
+func winogradBT2Vec(x []float64, xs int, ret []float64, rs int) {
+	x0, x1, x2, x3 := x[0], x[xs], x[2*xs], x[3*xs]
+	ret[0] = x0 - x2
+	ret[rs] = x1 + x2
+	ret[2*rs] = x2 - x1
+	ret[3*rs] = x1 - x3
+}
+
+func winogradAT2Vec(x []float64, xs int, ret []float64, rs int) {
+	x1, x2 := x[xs], x[2*xs]
+	ret[0] = x[0] + x1 + x2
+	ret[rs] = x1 - x2 - x[3*xs]
+}
+
+func winogradBT4Vec(x []float64, xs int, ret []float64, rs int) {
+	x0, x1, x2, x3, x4, x5 := x[0], x[xs], x[2*xs], x[3*xs], x[4*xs], x[5*xs]
+	ret[0] = 4*x0 - 5*x2 + x4
+	ret[rs] = x3 + x4 - 4*(x1+x2)
+	ret[2*rs] = x4 - x3 + 4*(x1-x2)
+	ret[3*rs] = x4 - x2 + 2*(x3-x1)
+	ret[4*rs] = x4 - x2 + 2*(x1-x3)
+	ret[5*rs] = 4*x1 - 5*x3 + x5
+}
+
+func winogradAT4Vec(x []float64, xs int, ret []float64, rs int) {
+	x1, x2, x3, x4 := x[xs], x[2*xs], x[3*xs], x[4*xs]
+	s12, d12, s34, d34 := x1+x2, x1-x2, x3+x4, x3-x4
+	ret[0] = x[0] + s12 + s34
+	ret[rs] = d12 + 2*d34
+	ret[2*rs] = s12 + 4*s34
+	ret[3*rs] = d12 + 8*d34 + x[5*xs]
+}
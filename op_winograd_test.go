@@ -0,0 +1,182 @@
+package gorgonia
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorgonia.org/tensor"
+)
+
+func TestWinograd(t *testing.T) {
+	assert := assert.New(t)
+	for _, tc := range []struct {
+		h, w, pad int
+	}{
+		{4, 4, 0},
+		{7, 9, 1},
+		{11, 6, 1},
+		{5, 13, 2},
+	} {
+		xT := tensor.New(tensor.WithShape(2, 3, tc.h, tc.w), tensor.WithBacking(tensor.Random(tensor.Float64, 2*3*tc.h*tc.w)))
+		filterT := tensor.New(tensor.WithShape(4, 3, 3, 3), tensor.WithBacking(tensor.Random(tensor.Float64, 4*3*9)))
+		pad := []int{tc.pad, tc.pad}
+		expected := naiveConv2d(xT, filterT, pad, []int{1, 1}, []int{1, 1}, 1)
+		for _, m := range []int{2, 4} {
+			out, err := winogradOp{m: m, padH: tc.pad, padW: tc.pad}.Do(xT, filterT)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(tensor.Shape{2, 4, tc.h + 2*tc.pad - 2, tc.w + 2*tc.pad - 2}, out.Shape())
+			assert.InDeltaSlice(expected, out.Data(), 1e-12, "F(%dx%d, 3x3) of %v", m, m, tc)
+		}
+	}
+	_, err := winogradOp{m: 2}.Do(tensor.New(tensor.WithShape(1, 2, 4, 4), tensor.Of(tensor.Float64)), tensor.New(tensor.WithShape(1, 3, 3, 3), tensor.Of(tensor.Float64)))
+	assert.Error(err)
+}
+
+func TestConv2dWinograd(t *testing.T) {
+	assert := assert.New(t)
+	xT := tensor.New(tensor.WithShape(2, 3, 7, 6), tensor.WithBacking(tensor.Random(tensor.Float64, 2*3*7*6)))
+	filterT := tensor.New(tensor.WithShape(4, 3, 3, 3), tensor.WithBacking(tensor.Random(tensor.Float64, 4*3*9)))
+	kernel, pad, unit := tensor.Shape{3, 3}, []int{1, 1}, []int{1, 1}
+
+	// the float64 convolution is the im2col one
+	g64 := NewGraph()
+	x64 := NodeFromAny(g64, xT, WithName("x"))
+	filter64 := NodeFromAny(g64, filterT, WithName("filter"))
+	conv64, err := Conv2d(x64, filter64, kernel, pad, unit, unit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	grads64, err := Grad(Must(Sum(Must(Square(conv64)))), x64, filter64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the gradient ops work in place, so the convolutions are read before
+	var conv64Val Value
+	Read(conv64, &conv64Val)
+
+	xT32, err := castValue(xT, Float32, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	filterT32, err := castValue(filterT, Float32, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g32 := NewGraph()
+	x32 := NodeFromAny(g32, xT32, WithName("x"))
+	filter32 := NodeFromAny(g32, filterT32, WithName("filter"))
+	conv32, err := Conv2d(x32, filter32, kernel, pad, unit, unit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(winogradOp{m: 4, padH: 1, padW: 1}, conv32.op)
+	grads32, err := Grad(Must(Sum(Must(Square(conv32)))), x32, filter32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var conv32Val Value
+	Read(conv32, &conv32Val)
+
+	for _, g := range []*ExprGraph{g64, g32} {
+		m := NewTapeMachine(g)
+		if err = m.RunAll(); err != nil {
+			t.Fatalf("%+v", err)
+		}
+		m.Close()
+	}
+	toF64 := func(v Value) []float64 {
+		data, err := floatData(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}
+	assert.InDeltaSlice(toF64(conv64Val), toF64(conv32Val), 1e-5)
+	assert.InDeltaSlice(toF64(grads64[0].Value()), toF64(grads32[0].Value()), 1e-4)
+	assert.InDeltaSlice(toF64(grads64[1].Value()), toF64(grads32[1].Value()), 1e-3)
+
+	// strided convolutions are not Winograd ones
+	strided, err := Conv2d(x32, filter32, kernel, pad, []int{2, 2}, unit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, ok := strided.op.(winogradOp)
+	assert.False(ok)
+}
+
+func TestConv2dWinograd_LispMachine(t *testing.T) {
+	assert := assert.New(t)
+	xT := tensor.New(tensor.WithShape(2, 2, 6, 5), tensor.WithBacking(tensor.Random(tensor.Float64, 2*2*6*5)))
+	filterT := tensor.New(tensor.WithShape(3, 2, 3, 3), tensor.WithBacking(tensor.Random(tensor.Float64, 3*2*9)))
+	kernel, pad, unit := tensor.Shape{3, 3}, []int{1, 1}, []int{1, 1}
+
+	// the gradients of the float64 im2col convolution and of the float32 Winograd one, on the LispMachine
+	var xs, filters [2]*Node
+	for i, dt := range []tensor.Dtype{Float64, Float32} {
+		xv, err := castValue(xT, dt, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fv, err := castValue(filterT, dt, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		g := NewGraph()
+		xs[i] = NodeFromAny(g, xv, WithName("x"))
+		filters[i] = NodeFromAny(g, fv, WithName("filter"))
+		conv, err := Conv2d(xs[i], filters[i], kernel, pad, unit, unit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if dt == Float32 {
+			assert.Equal(winogradOp{m: 4, padH: 1, padW: 1}, conv.op)
+		}
+		Must(Sum(Must(Square(conv))))
+		m := NewLispMachine(g)
+		if err = m.RunAll(); err != nil {
+			t.Fatalf("%+v", err)
+		}
+		m.Close()
+	}
+	for _, ns := range [][2]*Node{xs, filters} {
+		want, err := ns[0].Grad()
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := ns[1].Grad()
+		if err != nil {
+			t.Fatal(err)
+		}
+		w, _ := floatData(want)
+		g, _ := floatData(got)
+		assert.InDeltaSlice(w, g, 1e-3, "the gradient of %v", ns[1].Name())
+	}
+}
+
+func BenchmarkConv2d3x3(b *testing.B) {
+	x := tensor.New(tensor.WithShape(8, 64, 28, 28), tensor.WithBacking(tensor.Random(tensor.Float32, 8*64*28*28)))
+	filter := tensor.New(tensor.WithShape(64, 64, 3, 3), tensor.WithBacking(tensor.Random(tensor.Float32, 64*64*9)))
+	for _, dilation := range []int{1, 2} {
+		// a dilation of 2 takes the im2col path
+		g := NewGraph()
+		xn := NodeFromAny(g, x, WithName("x"))
+		fn := NodeFromAny(g, filter, WithName("filter"))
+		Must(Conv2d(xn, fn, tensor.Shape{3, 3}, []int{dilation, dilation}, []int{1, 1}, []int{dilation, dilation}))
+		m := NewTapeMachine(g)
+		name := "winograd"
+		if dilation > 1 {
+			name = "im2col"
+		}
+		b.Run(name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if err := m.RunAll(); err != nil {
+					b.Fatal(err)
+				}
+				m.Reset()
+			}
+		})
+		m.Close()
+	}
+}
@@ -2,6 +2,7 @@ package gorgonia
 
 import (
 	"fmt"
+	"math"
 
 	"github.com/pkg/errors"
 	"gorgonia.org/tensor"
@@ -16,6 +17,13 @@ func binOpNode(op BinaryOp, a, b *Node) (retVal *Node, err error) {
 	stabLogf("Creating node for %v, a: %p, b: %p", op, a, b)
 	enterLogScope()
 	defer leaveLogScope()
+
+	if a.g != nil && a.g.autoCast {
+		if op, a, b, err = autoCast(op, a, b); err != nil {
+			return nil, err
+		}
+	}
+
 	// maybe make stabilization a build tag?
 	if stabilization {
 		enterLogScope()
@@ -282,6 +290,29 @@ func Max(a *Node, along ...int) (retVal *Node, err error) {
 	return ApplyOp(op, a)
 }
 
+// MaxWithIndices returns the maximum values of a along an axis, and their indices. Both are computed in a single
+// pass. Gradients flow through the values.
+func MaxWithIndices(a *Node, axis int) (values, indices *Node, err error) {
+	if axis < 0 || axis >= a.Dims() {
+		return nil, nil, errors.Errorf("Cannot find the maximum along axis %d of %v", axis, a.Shape())
+	}
+	var outputs Nodes
+	if outputs, err = ApplyMultiOp(maxIndicesOp{axis: axis, d: a.Dims()}, a); err != nil {
+		return nil, nil, err
+	}
+	return outputs[0], outputs[1], nil
+}
+
+// SVD returns the singular value decomposition of the matrix a: u, s and v such that a = u·diag(s)·vᵀ. If full is
+// true, u and v are square. The decomposition is not differentiable.
+func SVD(a *Node, full bool) (u, s, v *Node, err error) {
+	var outputs Nodes
+	if outputs, err = ApplyMultiOp(svdOp{full: full}, a); err != nil {
+		return nil, nil, nil, err
+	}
+	return outputs[0], outputs[1], outputs[2], nil
+}
+
 // Mean performs a mean() on the input and the provided axes.
 func Mean(a *Node, along ...int) (retVal *Node, err error) {
 	if a.IsScalar() {
@@ -633,6 +664,410 @@ func Reshape(n *Node, to tensor.Shape) (retVal *Node, err error) {
 
 /* Contraction related operations */
 
+// Cast casts a node to the given Dtype. If the node is already of the given Dtype, it is returned as is.
+// Out of range values follow Go's conversion rules: integers wrap around and floats are truncated towards zero.
+// Use SaturatingCast if out of range values should be clamped instead.
+//
+// The gradient of a cast from one float Dtype to another is passed straight through. All other casts are not differentiable.
+func Cast(n *Node, to tensor.Dtype) (retVal *Node, err error) {
+	return cast(n, to, false)
+}
+
+// SaturatingCast casts a node to the given Dtype. Values that are out of range of an integer Dtype are clamped to
+// the minimum or maximum value of the Dtype, and NaNs become 0. For non-integer targets, SaturatingCast is the same as Cast.
+func SaturatingCast(n *Node, to tensor.Dtype) (retVal *Node, err error) {
+	return cast(n, to, true)
+}
+
+func cast(n *Node, to tensor.Dtype, saturate bool) (retVal *Node, err error) {
+	var dt tensor.Dtype
+	if dt, err = dtypeOf(n.t); err != nil {
+		return nil, errors.Wrap(err, dtypeOfFail)
+	}
+	if categoryOf(dt) == notPromotable || categoryOf(to) == notPromotable {
+		return nil, errors.Errorf("Cannot cast %v to %v", dt, to)
+	}
+	if n.IsScalar() {
+		var ok bool
+		for _, sdt := range acceptableDtypes {
+			if sdt == to {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return nil, errors.Errorf("Cannot cast a scalar to %v. Scalars of that Dtype are not supported", to)
+		}
+	}
+	if dt == to {
+		return n, nil
+	}
+
+	op := newCastOp(n, to)
+	op.saturate = saturate
+	return ApplyOp(op, n)
+}
+
+// And performs an elementwise logical and on bool nodes. Either node may be a scalar.
+func And(a, b *Node) (retVal *Node, err error) { return logicalOpNode(andOpType, a, b) }
+
+// Or performs an elementwise logical or on bool nodes. Either node may be a scalar.
+func Or(a, b *Node) (retVal *Node, err error) { return logicalOpNode(orOpType, a, b) }
+
+// Xor performs an elementwise logical exclusive or on bool nodes. Either node may be a scalar.
+func Xor(a, b *Node) (retVal *Node, err error) { return logicalOpNode(xorOpType, a, b) }
+
+// Not performs an elementwise logical negation on a bool node.
+func Not(a *Node) (retVal *Node, err error) {
+	if err = checkBoolNode(a); err != nil {
+		return nil, errors.Wrap(err, operationError)
+	}
+	return ApplyOp(notOp{d: a.Dims()}, a)
+}
+
+// Any returns true if any of the values of a bool node along the provided axes are true. If no axes are provided, all axes are reduced.
+func Any(a *Node, along ...int) (retVal *Node, err error) { return boolReduction(a, true, along) }
+
+// All returns true if all of the values of a bool node along the provided axes are true. If no axes are provided, all axes are reduced.
+func All(a *Node, along ...int) (retVal *Node, err error) { return boolReduction(a, false, along) }
+
+func logicalOpNode(ot logicalOpType, a, b *Node) (retVal *Node, err error) {
+	if err = checkBoolNode(a); err != nil {
+		return nil, errors.Wrap(err, operationError)
+	}
+	if err = checkBoolNode(b); err != nil {
+		return nil, errors.Wrap(err, operationError)
+	}
+	return ApplyOp(newLogicalOp(ot, a, b), a, b)
+}
+
+func boolReduction(a *Node, any bool, along []int) (retVal *Node, err error) {
+	if err = checkBoolNode(a); err != nil {
+		return nil, errors.Wrap(err, operationError)
+	}
+	if a.IsScalar() {
+		return a, nil
+	}
+
+	dims := a.Dims()
+	if len(along) == 0 {
+		along = intRange(0, dims)
+	}
+	for _, axis := range along {
+		if axis < 0 || axis >= dims {
+			return nil, errors.Errorf("Axis %d is out of range for a node with %d dims", axis, dims)
+		}
+	}
+	return ApplyOp(boolReductionOp{along: along, d: dims, any: any}, a)
+}
+
+func checkBoolNode(a *Node) error {
+	dt, err := dtypeOf(a.t)
+	if err != nil {
+		return errors.Wrap(err, dtypeOfFail)
+	}
+	if dt != Bool {
+		return errors.Errorf("Expected %v to be of %v. Got %v instead", a, Bool, dt)
+	}
+	return nil
+}
+
+// BitwiseAnd performs an elementwise bitwise and on integer nodes. Either node may be a scalar.
+func BitwiseAnd(a, b *Node) (retVal *Node, err error) { return bitwiseOpNode(bitAndOpType, a, b) }
+
+// BitwiseOr performs an elementwise bitwise or on integer nodes. Either node may be a scalar.
+func BitwiseOr(a, b *Node) (retVal *Node, err error) { return bitwiseOpNode(bitOrOpType, a, b) }
+
+// BitwiseXor performs an elementwise bitwise exclusive or on integer nodes. Either node may be a scalar.
+func BitwiseXor(a, b *Node) (retVal *Node, err error) { return bitwiseOpNode(bitXorOpType, a, b) }
+
+// ShiftLeft shifts the bits of each element of a to the left by the corresponding element of b. Either node may be a scalar.
+// Negative shift counts are treated as very large shift counts.
+func ShiftLeft(a, b *Node) (retVal *Node, err error) { return bitwiseOpNode(shlOpType, a, b) }
+
+// ShiftRight shifts the bits of each element of a to the right by the corresponding element of b. Either node may be a scalar.
+// Signed integers are shifted arithmetically. Negative shift counts are treated as very large shift counts.
+func ShiftRight(a, b *Node) (retVal *Node, err error) { return bitwiseOpNode(shrOpType, a, b) }
+
+// SaturatingAdd adds int8, uint8 or int16 nodes elementwise, clamping the results to the range of the dtype instead of
+// wrapping around. Either node may be a scalar.
+func SaturatingAdd(a, b *Node) (retVal *Node, err error) { return intArithOpNode(satAddOpType, a, b) }
+
+// SaturatingSub subtracts int8, uint8 or int16 nodes elementwise, clamping the results to the range of the dtype. Either
+// node may be a scalar.
+func SaturatingSub(a, b *Node) (retVal *Node, err error) { return intArithOpNode(satSubOpType, a, b) }
+
+// SaturatingMul multiplies int8, uint8 or int16 nodes elementwise, clamping the results to the range of the dtype. Either
+// node may be a scalar.
+func SaturatingMul(a, b *Node) (retVal *Node, err error) { return intArithOpNode(satMulOpType, a, b) }
+
+// WrappingAdd adds int8, uint8 or int16 nodes elementwise, wrapping around on overflow. Unlike Add, it is not reported by
+// the overflow check of the engine (see WithOverflowCheck), as the wrapping is intended. Either node may be a scalar.
+func WrappingAdd(a, b *Node) (retVal *Node, err error) { return intArithOpNode(wrapAddOpType, a, b) }
+
+// WrappingSub subtracts int8, uint8 or int16 nodes elementwise, wrapping around on overflow. Either node may be a scalar.
+func WrappingSub(a, b *Node) (retVal *Node, err error) { return intArithOpNode(wrapSubOpType, a, b) }
+
+// WrappingMul multiplies int8, uint8 or int16 nodes elementwise, wrapping around on overflow. Either node may be a scalar.
+func WrappingMul(a, b *Node) (retVal *Node, err error) { return intArithOpNode(wrapMulOpType, a, b) }
+
+// WideSum sums all the elements of an int8 or int16 node into a scalar of the accumulator dtype, Int32 or Int64, so
+// that the sum does not overflow the dtype of the node.
+func WideSum(a *Node, acc tensor.Dtype) (retVal *Node, err error) {
+	return wideOpNode(wideSumOpType, acc, a)
+}
+
+// WideDot is the dot product of two int8 or int16 vectors, accumulated into a scalar of the accumulator dtype, Int32 or
+// Int64.
+func WideDot(a, b *Node, acc tensor.Dtype) (retVal *Node, err error) {
+	if !a.IsVector() || !b.IsVector() {
+		return nil, errors.Errorf("WideDot expects vectors. Got %v and %v instead", a.Shape(), b.Shape())
+	}
+	return wideOpNode(wideDotOpType, acc, a, b)
+}
+
+// WideAddScalar adds the scalar s to the elements of an int8 or int16 node. The result has the dtype of s, Int32 or
+// Int64, which is typically used as the bias of a quantized layer.
+func WideAddScalar(a, s *Node) (retVal *Node, err error) {
+	if !s.IsScalar() {
+		return nil, errors.Errorf("WideAddScalar expects a scalar. Got %v instead", s.Shape())
+	}
+	var acc tensor.Dtype
+	if acc, err = dtypeOf(s.t); err != nil {
+		return nil, errors.Wrap(err, dtypeOfFail)
+	}
+	return wideOpNode(wideAddScalarOpType, acc, a, s)
+}
+
+func bitwiseOpNode(ot bitwiseOpType, a, b *Node) (retVal *Node, err error) {
+	for _, n := range []*Node{a, b} {
+		var dt tensor.Dtype
+		if dt, err = dtypeOf(n.t); err != nil {
+			return nil, errors.Wrap(err, dtypeOfFail)
+		}
+		if c := categoryOf(dt); c != intCategory && c != uintCategory {
+			return nil, errors.Errorf("Expected %v to be an integer. Got %v instead", n, dt)
+		}
+	}
+	return ApplyOp(newBitwiseOp(ot, a, b), a, b)
+}
+
+// IsNaN returns a bool node that is true where the values of a are NaN.
+func IsNaN(a *Node) (retVal *Node, err error) { return floatPredNode(isNaNPred, a) }
+
+// IsInf returns a bool node that is true where the values of a are positive or negative infinity.
+func IsInf(a *Node) (retVal *Node, err error) { return floatPredNode(isInfPred, a) }
+
+// IsFinite returns a bool node that is true where the values of a are neither NaN nor infinite.
+func IsFinite(a *Node) (retVal *Node, err error) { return floatPredNode(isFinitePred, a) }
+
+// NanToNum replaces the NaNs in a with nan, the positive infinities with posInf, and the negative infinities with negInf.
+// The gradient is passed through for finite values, and is 0 for the values that were replaced.
+func NanToNum(a *Node, nan, posInf, negInf float64) (retVal *Node, err error) {
+	if err = checkFloatNode(a); err != nil {
+		return nil, errors.Wrap(err, operationError)
+	}
+	op := nanToNumOp{nan: nan, posInf: posInf, negInf: negInf, d: a.Dims()}
+	return ApplyOp(op, a)
+}
+
+// StopGrad returns a node with the same value as a, through which no gradient flows: a is treated as a constant when
+// differentiating the nodes that use the result. This is useful for target networks and teacher models, for example:
+//		target := Must(StopGrad(teacherOut))
+//		cost := Must(Mean(Must(Square(Must(Sub(studentOut, target))))))
+func StopGrad(a *Node) (retVal *Node, err error) { return ApplyOp(stopGradOp{}, a) }
+
+// GradReversal returns a node with the same value as a. The gradient flowing through it is multiplied by -lambda: this
+// is the gradient reversal layer used in domain adversarial training. Unlike a ScaleGrad hook on a, which affects every
+// use of a, only the gradient flowing through the returned node is reversed.
+func GradReversal(a *Node, lambda float64) (retVal *Node, err error) {
+	if err = checkFloatNode(a); err != nil {
+		return nil, errors.Wrap(err, operationError)
+	}
+	return ApplyOp(gradReversalOp{lambda: lambda}, a)
+}
+
+// SampleCategorical samples an index along the last axis of a, which holds log-probabilities or logits (or
+// probabilities, with FromProbabilities). The result is an Int node with the shape of a without its last axis: a
+// scalar if a is a vector. Every execution draws new samples. For example, to sample the next token of a batch of
+// sequences using nucleus sampling:
+//		next, err := SampleCategorical(logits, WithTemperature(0.8), WithTopP(0.95))
+func SampleCategorical(a *Node, opts ...SamplingOpt) (retVal *Node, err error) {
+	if err = checkFloatNode(a); err != nil {
+		return nil, errors.Wrap(err, operationError)
+	}
+	var op *categoricalOp
+	if op, err = newCategoricalOp(a.Dims(), opts...); err != nil {
+		return nil, err
+	}
+	return ApplyOp(op, a)
+}
+
+// Histogram counts the values of a falling into bins, ignoring NaNs. It returns the counts, an Int vector of length
+// bins, and the edges of the bins, a vector of length bins+1 of the Dtype of a. All the bins but the last are half
+// open: the last bin includes its upper edge.
+//
+// The bins are equally wide over the range of the values, unless HistogramRange or HistogramBinEdges fixes them. If
+// bins is 0, the number of bins is chosen with Sturges' rule from the number of values. The histogram is not
+// differentiable. For example, to calibrate the quantization of an activation:
+//		counts, edges, err := Histogram(act, 2048, HistogramRange(-8, 8))
+func Histogram(a *Node, bins int, opts ...HistogramOpt) (counts, edges *Node, err error) {
+	if err = checkFloatNode(a); err != nil {
+		return nil, nil, errors.Wrap(err, operationError)
+	}
+	var op *histogramOp
+	if op, err = newHistogramOp(bins, a.Shape(), opts...); err != nil {
+		return nil, nil, err
+	}
+	var outputs Nodes
+	if outputs, err = ApplyMultiOp(op, a); err != nil {
+		return nil, nil, err
+	}
+	return outputs[0], outputs[1], nil
+}
+
+// Quantile computes the q-th quantile of the values of a along the given axes, or of all the values if no axis is
+// given. q is in [0, 1]. The quantile is interpolated linearly between the two nearest values, and the gradient flows
+// to these two values. A NaN among the values results in NaN, unless the NaNs are ignored by the engine (see NaNMode).
+func Quantile(a *Node, q float64, along ...int) (retVal *Node, err error) {
+	if err = checkFloatNode(a); err != nil {
+		return nil, errors.Wrap(err, operationError)
+	}
+	if q < 0 || q > 1 || math.IsNaN(q) {
+		return nil, errors.Errorf("Expected a quantile in [0, 1]. Got %v instead", q)
+	}
+	seen := make(map[int]bool)
+	for _, axis := range along {
+		if axis < 0 || axis >= a.Dims() || seen[axis] {
+			return nil, errors.Errorf("Cannot compute a quantile along the axes %v of %v", along, a.Shape())
+		}
+		seen[axis] = true
+	}
+	if len(along) == a.Dims() {
+		along = nil
+	}
+	return ApplyOp(quantileOp{q: q, along: along, d: a.Dims()}, a)
+}
+
+// Median computes the median of the values of a along the given axes, or of all the values if no axis is given. It is
+// Quantile(a, 0.5, along...).
+func Median(a *Node, along ...int) (retVal *Node, err error) { return Quantile(a, 0.5, along...) }
+
+// Bincount counts the occurrences of every value of indices, a node of non-negative integers: the i-th element of
+// the result is the number of elements of indices equal to i. If weights is not nil, the weights of the occurrences
+// are summed instead, and the result is differentiable with respect to the weights, which have the shape of indices.
+//
+// As the shapes of the nodes are static, the result is a vector of length minlength, and the indices must be less than
+// minlength.
+func Bincount(indices, weights *Node, minlength int) (retVal *Node, err error) {
+	if err = checkIntNode(indices); err != nil {
+		return nil, errors.Wrap(err, operationError)
+	}
+	if minlength <= 0 {
+		return nil, errors.Errorf("Expected a positive length. Got %d instead", minlength)
+	}
+	op := bincountOp{length: minlength, d: indices.Dims()}
+	if weights == nil {
+		return ApplyOp(op, indices)
+	}
+	if err = checkFloatNode(weights); err != nil {
+		return nil, errors.Wrap(err, operationError)
+	}
+	if !weights.Shape().Eq(indices.Shape()) {
+		return nil, errors.Errorf("Expected weights of shape %v. Got %v instead", indices.Shape(), weights.Shape())
+	}
+	op.weighted = true
+	return ApplyOp(op, indices, weights)
+}
+
+// ConfusionMatrix counts the pairs of actual and predicted classes, which are integer nodes of the same shape, with
+// values in [0, classes). The result is a (classes, classes) Int matrix whose element (i, j) is the number of elements
+// of class i that were predicted to be of class j. For example, with the logits of a classifier:
+//		_, predicted, err := MaxWithIndices(logits, 1)
+//		cm, err := ConfusionMatrix(predicted, labels, 10)
+func ConfusionMatrix(predicted, actual *Node, classes int) (retVal *Node, err error) {
+	for _, n := range []*Node{predicted, actual} {
+		if err = checkIntNode(n); err != nil {
+			return nil, errors.Wrap(err, operationError)
+		}
+	}
+	if !predicted.Shape().Eq(actual.Shape()) {
+		return nil, errors.Errorf("Expected predicted and actual classes of the same shape. Got %v and %v instead", predicted.Shape(), actual.Shape())
+	}
+	if classes <= 0 {
+		return nil, errors.Errorf("Expected a positive number of classes. Got %d instead", classes)
+	}
+	return ApplyOp(confusionMatrixOp{classes: classes, d: predicted.Dims()}, predicted, actual)
+}
+
+func floatPredNode(pred floatPredType, a *Node) (retVal *Node, err error) {
+	if err = checkFloatNode(a); err != nil {
+		return nil, errors.Wrap(err, operationError)
+	}
+	return ApplyOp(floatPredOp{pred: pred, d: a.Dims()}, a)
+}
+
+func checkIntNode(a *Node) error {
+	dt, err := dtypeOf(a.t)
+	if err != nil {
+		return errors.Wrap(err, dtypeOfFail)
+	}
+	if c := categoryOf(dt); c != intCategory && c != uintCategory {
+		return errors.Errorf("Expected %v to be an integer. Got %v instead", a, dt)
+	}
+	return nil
+}
+
+func checkFloatNode(a *Node) error {
+	dt, err := dtypeOf(a.t)
+	if err != nil {
+		return errors.Wrap(err, dtypeOfFail)
+	}
+	if dt != Float64 && dt != Float32 {
+		return errors.Errorf("Expected %v to be a float. Got %v instead", a, dt)
+	}
+	return nil
+}
+
+// CloseTo returns a bool node that is true where the values of a are close to the values of b, that is, where
+//		|a - b| <= atol + rtol * |b|
+// Either a or b may be a scalar. a and b must be floats or complex numbers of the same Dtype.
+func CloseTo(a, b *Node, rtol, atol float64) (retVal *Node, err error) {
+	for _, n := range []*Node{a, b} {
+		var dt tensor.Dtype
+		if dt, err = dtypeOf(n.t); err != nil {
+			return nil, errors.Wrap(err, dtypeOfFail)
+		}
+		if c := categoryOf(dt); c != floatCategory && c != complexCategory {
+			return nil, errors.Errorf("Expected %v to be a float or a complex number. Got %v instead", n, dt)
+		}
+	}
+	op := closeToOp{rtol: rtol, atol: atol, ad: a.Dims(), bd: b.Dims()}
+	return ApplyOp(op, a, b)
+}
+
+// FMA performs a fused a*b + c. a and c must be tensors of the same shape; b is either a tensor of the same shape or a scalar.
+// All of a, b and c must be of the same float Dtype.
+func FMA(a, b, c *Node) (retVal *Node, err error) {
+	for _, n := range []*Node{a, b, c} {
+		if err = checkFloatNode(n); err != nil {
+			return nil, errors.Wrap(err, operationError)
+		}
+	}
+	if a.IsScalar() || c.IsScalar() {
+		return nil, errors.Errorf("Expected a and c to be tensors. Got %v and %v instead", a.Shape(), c.Shape())
+	}
+	return ApplyOp(newFMAOp(a, b), a, b, c)
+}
+
+// Axpy performs alpha*x + y, where alpha is a scalar. It is equivalent to FMA(x, alpha, y).
+func Axpy(alpha, x, y *Node) (retVal *Node, err error) {
+	if !alpha.IsScalar() {
+		return nil, errors.Errorf("Expected alpha to be a scalar. Got %v instead", alpha.Shape())
+	}
+	return FMA(x, alpha, y)
+}
+
 // Tensordot performs a tensor contraction of a and b along specified axes.
 func Tensordot(aAxes []int, bAxes []int, a, b *Node) (retVal *Node, err error) {
 
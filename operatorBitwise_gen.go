@@ -0,0 +1,594 @@
+package gorgonia
+
+// Code generated by genapi, which is a API generation tool for Gorgonia. DO NOT EDIT.
+
+import "github.com/pkg/errors"
+
+// bitAndI performs a[i*as] & b[i*bs] for each element of retVal. as and bs are the strides of a and b, which are either 0 (scalar) or 1.
+func bitAndI(a, b, retVal []int, as, bs int) {
+	for i := range retVal {
+		retVal[i] = a[i*as] & b[i*bs]
+	}
+}
+
+// bitOrI performs a[i*as] | b[i*bs] for each element of retVal. as and bs are the strides of a and b, which are either 0 (scalar) or 1.
+func bitOrI(a, b, retVal []int, as, bs int) {
+	for i := range retVal {
+		retVal[i] = a[i*as] | b[i*bs]
+	}
+}
+
+// bitXorI performs a[i*as] ^ b[i*bs] for each element of retVal. as and bs are the strides of a and b, which are either 0 (scalar) or 1.
+func bitXorI(a, b, retVal []int, as, bs int) {
+	for i := range retVal {
+		retVal[i] = a[i*as] ^ b[i*bs]
+	}
+}
+
+// bitShlI performs a[i*as] << b[i*bs] for each element of retVal. as and bs are the strides of a and b, which are either 0 (scalar) or 1.
+func bitShlI(a, b, retVal []int, as, bs int) {
+	for i := range retVal {
+		retVal[i] = a[i*as] << uint(b[i*bs])
+	}
+}
+
+// bitShrI performs a[i*as] >> b[i*bs] for each element of retVal. as and bs are the strides of a and b, which are either 0 (scalar) or 1.
+func bitShrI(a, b, retVal []int, as, bs int) {
+	for i := range retVal {
+		retVal[i] = a[i*as] >> uint(b[i*bs])
+	}
+}
+
+// bitAndI8 performs a[i*as] & b[i*bs] for each element of retVal. as and bs are the strides of a and b, which are either 0 (scalar) or 1.
+func bitAndI8(a, b, retVal []int8, as, bs int) {
+	for i := range retVal {
+		retVal[i] = a[i*as] & b[i*bs]
+	}
+}
+
+// bitOrI8 performs a[i*as] | b[i*bs] for each element of retVal. as and bs are the strides of a and b, which are either 0 (scalar) or 1.
+func bitOrI8(a, b, retVal []int8, as, bs int) {
+	for i := range retVal {
+		retVal[i] = a[i*as] | b[i*bs]
+	}
+}
+
+// bitXorI8 performs a[i*as] ^ b[i*bs] for each element of retVal. as and bs are the strides of a and b, which are either 0 (scalar) or 1.
+func bitXorI8(a, b, retVal []int8, as, bs int) {
+	for i := range retVal {
+		retVal[i] = a[i*as] ^ b[i*bs]
+	}
+}
+
+// bitShlI8 performs a[i*as] << b[i*bs] for each element of retVal. as and bs are the strides of a and b, which are either 0 (scalar) or 1.
+func bitShlI8(a, b, retVal []int8, as, bs int) {
+	for i := range retVal {
+		retVal[i] = a[i*as] << uint(b[i*bs])
+	}
+}
+
+// bitShrI8 performs a[i*as] >> b[i*bs] for each element of retVal. as and bs are the strides of a and b, which are either 0 (scalar) or 1.
+func bitShrI8(a, b, retVal []int8, as, bs int) {
+	for i := range retVal {
+		retVal[i] = a[i*as] >> uint(b[i*bs])
+	}
+}
+
+// bitAndI16 performs a[i*as] & b[i*bs] for each element of retVal. as and bs are the strides of a and b, which are either 0 (scalar) or 1.
+func bitAndI16(a, b, retVal []int16, as, bs int) {
+	for i := range retVal {
+		retVal[i] = a[i*as] & b[i*bs]
+	}
+}
+
+// bitOrI16 performs a[i*as] | b[i*bs] for each element of retVal. as and bs are the strides of a and b, which are either 0 (scalar) or 1.
+func bitOrI16(a, b, retVal []int16, as, bs int) {
+	for i := range retVal {
+		retVal[i] = a[i*as] | b[i*bs]
+	}
+}
+
+// bitXorI16 performs a[i*as] ^ b[i*bs] for each element of retVal. as and bs are the strides of a and b, which are either 0 (scalar) or 1.
+func bitXorI16(a, b, retVal []int16, as, bs int) {
+	for i := range retVal {
+		retVal[i] = a[i*as] ^ b[i*bs]
+	}
+}
+
+// bitShlI16 performs a[i*as] << b[i*bs] for each element of retVal. as and bs are the strides of a and b, which are either 0 (scalar) or 1.
+func bitShlI16(a, b, retVal []int16, as, bs int) {
+	for i := range retVal {
+		retVal[i] = a[i*as] << uint(b[i*bs])
+	}
+}
+
+// bitShrI16 performs a[i*as] >> b[i*bs] for each element of retVal. as and bs are the strides of a and b, which are either 0 (scalar) or 1.
+func bitShrI16(a, b, retVal []int16, as, bs int) {
+	for i := range retVal {
+		retVal[i] = a[i*as] >> uint(b[i*bs])
+	}
+}
+
+// bitAndI32 performs a[i*as] & b[i*bs] for each element of retVal. as and bs are the strides of a and b, which are either 0 (scalar) or 1.
+func bitAndI32(a, b, retVal []int32, as, bs int) {
+	for i := range retVal {
+		retVal[i] = a[i*as] & b[i*bs]
+	}
+}
+
+// bitOrI32 performs a[i*as] | b[i*bs] for each element of retVal. as and bs are the strides of a and b, which are either 0 (scalar) or 1.
+func bitOrI32(a, b, retVal []int32, as, bs int) {
+	for i := range retVal {
+		retVal[i] = a[i*as] | b[i*bs]
+	}
+}
+
+// bitXorI32 performs a[i*as] ^ b[i*bs] for each element of retVal. as and bs are the strides of a and b, which are either 0 (scalar) or 1.
+func bitXorI32(a, b, retVal []int32, as, bs int) {
+	for i := range retVal {
+		retVal[i] = a[i*as] ^ b[i*bs]
+	}
+}
+
+// bitShlI32 performs a[i*as] << b[i*bs] for each element of retVal. as and bs are the strides of a and b, which are either 0 (scalar) or 1.
+func bitShlI32(a, b, retVal []int32, as, bs int) {
+	for i := range retVal {
+		retVal[i] = a[i*as] << uint(b[i*bs])
+	}
+}
+
+// bitShrI32 performs a[i*as] >> b[i*bs] for each element of retVal. as and bs are the strides of a and b, which are either 0 (scalar) or 1.
+func bitShrI32(a, b, retVal []int32, as, bs int) {
+	for i := range retVal {
+		retVal[i] = a[i*as] >> uint(b[i*bs])
+	}
+}
+
+// bitAndI64 performs a[i*as] & b[i*bs] for each element of retVal. as and bs are the strides of a and b, which are either 0 (scalar) or 1.
+func bitAndI64(a, b, retVal []int64, as, bs int) {
+	for i := range retVal {
+		retVal[i] = a[i*as] & b[i*bs]
+	}
+}
+
+// bitOrI64 performs a[i*as] | b[i*bs] for each element of retVal. as and bs are the strides of a and b, which are either 0 (scalar) or 1.
+func bitOrI64(a, b, retVal []int64, as, bs int) {
+	for i := range retVal {
+		retVal[i] = a[i*as] | b[i*bs]
+	}
+}
+
+// bitXorI64 performs a[i*as] ^ b[i*bs] for each element of retVal. as and bs are the strides of a and b, which are either 0 (scalar) or 1.
+func bitXorI64(a, b, retVal []int64, as, bs int) {
+	for i := range retVal {
+		retVal[i] = a[i*as] ^ b[i*bs]
+	}
+}
+
+// bitShlI64 performs a[i*as] << b[i*bs] for each element of retVal. as and bs are the strides of a and b, which are either 0 (scalar) or 1.
+func bitShlI64(a, b, retVal []int64, as, bs int) {
+	for i := range retVal {
+		retVal[i] = a[i*as] << uint(b[i*bs])
+	}
+}
+
+// bitShrI64 performs a[i*as] >> b[i*bs] for each element of retVal. as and bs are the strides of a and b, which are either 0 (scalar) or 1.
+func bitShrI64(a, b, retVal []int64, as, bs int) {
+	for i := range retVal {
+		retVal[i] = a[i*as] >> uint(b[i*bs])
+	}
+}
+
+// bitAndU performs a[i*as] & b[i*bs] for each element of retVal. as and bs are the strides of a and b, which are either 0 (scalar) or 1.
+func bitAndU(a, b, retVal []uint, as, bs int) {
+	for i := range retVal {
+		retVal[i] = a[i*as] & b[i*bs]
+	}
+}
+
+// bitOrU performs a[i*as] | b[i*bs] for each element of retVal. as and bs are the strides of a and b, which are either 0 (scalar) or 1.
+func bitOrU(a, b, retVal []uint, as, bs int) {
+	for i := range retVal {
+		retVal[i] = a[i*as] | b[i*bs]
+	}
+}
+
+// bitXorU performs a[i*as] ^ b[i*bs] for each element of retVal. as and bs are the strides of a and b, which are either 0 (scalar) or 1.
+func bitXorU(a, b, retVal []uint, as, bs int) {
+	for i := range retVal {
+		retVal[i] = a[i*as] ^ b[i*bs]
+	}
+}
+
+// bitShlU performs a[i*as] << b[i*bs] for each element of retVal. as and bs are the strides of a and b, which are either 0 (scalar) or 1.
+func bitShlU(a, b, retVal []uint, as, bs int) {
+	for i := range retVal {
+		retVal[i] = a[i*as] << uint(b[i*bs])
+	}
+}
+
+// bitShrU performs a[i*as] >> b[i*bs] for each element of retVal. as and bs are the strides of a and b, which are either 0 (scalar) or 1.
+func bitShrU(a, b, retVal []uint, as, bs int) {
+	for i := range retVal {
+		retVal[i] = a[i*as] >> uint(b[i*bs])
+	}
+}
+
+// bitAndU8 performs a[i*as] & b[i*bs] for each element of retVal. as and bs are the strides of a and b, which are either 0 (scalar) or 1.
+func bitAndU8(a, b, retVal []uint8, as, bs int) {
+	for i := range retVal {
+		retVal[i] = a[i*as] & b[i*bs]
+	}
+}
+
+// bitOrU8 performs a[i*as] | b[i*bs] for each element of retVal. as and bs are the strides of a and b, which are either 0 (scalar) or 1.
+func bitOrU8(a, b, retVal []uint8, as, bs int) {
+	for i := range retVal {
+		retVal[i] = a[i*as] | b[i*bs]
+	}
+}
+
+// bitXorU8 performs a[i*as] ^ b[i*bs] for each element of retVal. as and bs are the strides of a and b, which are either 0 (scalar) or 1.
+func bitXorU8(a, b, retVal []uint8, as, bs int) {
+	for i := range retVal {
+		retVal[i] = a[i*as] ^ b[i*bs]
+	}
+}
+
+// bitShlU8 performs a[i*as] << b[i*bs] for each element of retVal. as and bs are the strides of a and b, which are either 0 (scalar) or 1.
+func bitShlU8(a, b, retVal []uint8, as, bs int) {
+	for i := range retVal {
+		retVal[i] = a[i*as] << uint(b[i*bs])
+	}
+}
+
+// bitShrU8 performs a[i*as] >> b[i*bs] for each element of retVal. as and bs are the strides of a and b, which are either 0 (scalar) or 1.
+func bitShrU8(a, b, retVal []uint8, as, bs int) {
+	for i := range retVal {
+		retVal[i] = a[i*as] >> uint(b[i*bs])
+	}
+}
+
+// bitAndU16 performs a[i*as] & b[i*bs] for each element of retVal. as and bs are the strides of a and b, which are either 0 (scalar) or 1.
+func bitAndU16(a, b, retVal []uint16, as, bs int) {
+	for i := range retVal {
+		retVal[i] = a[i*as] & b[i*bs]
+	}
+}
+
+// bitOrU16 performs a[i*as] | b[i*bs] for each element of retVal. as and bs are the strides of a and b, which are either 0 (scalar) or 1.
+func bitOrU16(a, b, retVal []uint16, as, bs int) {
+	for i := range retVal {
+		retVal[i] = a[i*as] | b[i*bs]
+	}
+}
+
+// bitXorU16 performs a[i*as] ^ b[i*bs] for each element of retVal. as and bs are the strides of a and b, which are either 0 (scalar) or 1.
+func bitXorU16(a, b, retVal []uint16, as, bs int) {
+	for i := range retVal {
+		retVal[i] = a[i*as] ^ b[i*bs]
+	}
+}
+
+// bitShlU16 performs a[i*as] << b[i*bs] for each element of retVal. as and bs are the strides of a and b, which are either 0 (scalar) or 1.
+func bitShlU16(a, b, retVal []uint16, as, bs int) {
+	for i := range retVal {
+		retVal[i] = a[i*as] << uint(b[i*bs])
+	}
+}
+
+// bitShrU16 performs a[i*as] >> b[i*bs] for each element of retVal. as and bs are the strides of a and b, which are either 0 (scalar) or 1.
+func bitShrU16(a, b, retVal []uint16, as, bs int) {
+	for i := range retVal {
+		retVal[i] = a[i*as] >> uint(b[i*bs])
+	}
+}
+
+// bitAndU32 performs a[i*as] & b[i*bs] for each element of retVal. as and bs are the strides of a and b, which are either 0 (scalar) or 1.
+func bitAndU32(a, b, retVal []uint32, as, bs int) {
+	for i := range retVal {
+		retVal[i] = a[i*as] & b[i*bs]
+	}
+}
+
+// bitOrU32 performs a[i*as] | b[i*bs] for each element of retVal. as and bs are the strides of a and b, which are either 0 (scalar) or 1.
+func bitOrU32(a, b, retVal []uint32, as, bs int) {
+	for i := range retVal {
+		retVal[i] = a[i*as] | b[i*bs]
+	}
+}
+
+// bitXorU32 performs a[i*as] ^ b[i*bs] for each element of retVal. as and bs are the strides of a and b, which are either 0 (scalar) or 1.
+func bitXorU32(a, b, retVal []uint32, as, bs int) {
+	for i := range retVal {
+		retVal[i] = a[i*as] ^ b[i*bs]
+	}
+}
+
+// bitShlU32 performs a[i*as] << b[i*bs] for each element of retVal. as and bs are the strides of a and b, which are either 0 (scalar) or 1.
+func bitShlU32(a, b, retVal []uint32, as, bs int) {
+	for i := range retVal {
+		retVal[i] = a[i*as] << uint(b[i*bs])
+	}
+}
+
+// bitShrU32 performs a[i*as] >> b[i*bs] for each element of retVal. as and bs are the strides of a and b, which are either 0 (scalar) or 1.
+func bitShrU32(a, b, retVal []uint32, as, bs int) {
+	for i := range retVal {
+		retVal[i] = a[i*as] >> uint(b[i*bs])
+	}
+}
+
+// bitAndU64 performs a[i*as] & b[i*bs] for each element of retVal. as and bs are the strides of a and b, which are either 0 (scalar) or 1.
+func bitAndU64(a, b, retVal []uint64, as, bs int) {
+	for i := range retVal {
+		retVal[i] = a[i*as] & b[i*bs]
+	}
+}
+
+// bitOrU64 performs a[i*as] | b[i*bs] for each element of retVal. as and bs are the strides of a and b, which are either 0 (scalar) or 1.
+func bitOrU64(a, b, retVal []uint64, as, bs int) {
+	for i := range retVal {
+		retVal[i] = a[i*as] | b[i*bs]
+	}
+}
+
+// bitXorU64 performs a[i*as] ^ b[i*bs] for each element of retVal. as and bs are the strides of a and b, which are either 0 (scalar) or 1.
+func bitXorU64(a, b, retVal []uint64, as, bs int) {
+	for i := range retVal {
+		retVal[i] = a[i*as] ^ b[i*bs]
+	}
+}
+
+// bitShlU64 performs a[i*as] << b[i*bs] for each element of retVal. as and bs are the strides of a and b, which are either 0 (scalar) or 1.
+func bitShlU64(a, b, retVal []uint64, as, bs int) {
+	for i := range retVal {
+		retVal[i] = a[i*as] << uint(b[i*bs])
+	}
+}
+
+// bitShrU64 performs a[i*as] >> b[i*bs] for each element of retVal. as and bs are the strides of a and b, which are either 0 (scalar) or 1.
+func bitShrU64(a, b, retVal []uint64, as, bs int) {
+	for i := range retVal {
+		retVal[i] = a[i*as] >> uint(b[i*bs])
+	}
+}
+
+// bitwiseKernel dispatches to the correct kernel given the op and the dtype of the slices. a, b and retVal must be slices of the same type.
+func bitwiseKernel(op bitwiseOpType, a, b, retVal interface{}, as, bs int) error {
+	switch at := a.(type) {
+	case []int:
+		bt, ok := b.([]int)
+		if !ok {
+			return errors.Errorf(typeMismatchFail, a, b)
+		}
+		rt, ok := retVal.([]int)
+		if !ok {
+			return errors.Errorf(typeMismatchFail, a, retVal)
+		}
+		switch op {
+		case bitAndOpType:
+			bitAndI(at, bt, rt, as, bs)
+		case bitOrOpType:
+			bitOrI(at, bt, rt, as, bs)
+		case bitXorOpType:
+			bitXorI(at, bt, rt, as, bs)
+		case shlOpType:
+			bitShlI(at, bt, rt, as, bs)
+		case shrOpType:
+			bitShrI(at, bt, rt, as, bs)
+		default:
+			return errors.Errorf(nyiFail, "bitwiseKernel", op)
+		}
+	case []int8:
+		bt, ok := b.([]int8)
+		if !ok {
+			return errors.Errorf(typeMismatchFail, a, b)
+		}
+		rt, ok := retVal.([]int8)
+		if !ok {
+			return errors.Errorf(typeMismatchFail, a, retVal)
+		}
+		switch op {
+		case bitAndOpType:
+			bitAndI8(at, bt, rt, as, bs)
+		case bitOrOpType:
+			bitOrI8(at, bt, rt, as, bs)
+		case bitXorOpType:
+			bitXorI8(at, bt, rt, as, bs)
+		case shlOpType:
+			bitShlI8(at, bt, rt, as, bs)
+		case shrOpType:
+			bitShrI8(at, bt, rt, as, bs)
+		default:
+			return errors.Errorf(nyiFail, "bitwiseKernel", op)
+		}
+	case []int16:
+		bt, ok := b.([]int16)
+		if !ok {
+			return errors.Errorf(typeMismatchFail, a, b)
+		}
+		rt, ok := retVal.([]int16)
+		if !ok {
+			return errors.Errorf(typeMismatchFail, a, retVal)
+		}
+		switch op {
+		case bitAndOpType:
+			bitAndI16(at, bt, rt, as, bs)
+		case bitOrOpType:
+			bitOrI16(at, bt, rt, as, bs)
+		case bitXorOpType:
+			bitXorI16(at, bt, rt, as, bs)
+		case shlOpType:
+			bitShlI16(at, bt, rt, as, bs)
+		case shrOpType:
+			bitShrI16(at, bt, rt, as, bs)
+		default:
+			return errors.Errorf(nyiFail, "bitwiseKernel", op)
+		}
+	case []int32:
+		bt, ok := b.([]int32)
+		if !ok {
+			return errors.Errorf(typeMismatchFail, a, b)
+		}
+		rt, ok := retVal.([]int32)
+		if !ok {
+			return errors.Errorf(typeMismatchFail, a, retVal)
+		}
+		switch op {
+		case bitAndOpType:
+			bitAndI32(at, bt, rt, as, bs)
+		case bitOrOpType:
+			bitOrI32(at, bt, rt, as, bs)
+		case bitXorOpType:
+			bitXorI32(at, bt, rt, as, bs)
+		case shlOpType:
+			bitShlI32(at, bt, rt, as, bs)
+		case shrOpType:
+			bitShrI32(at, bt, rt, as, bs)
+		default:
+			return errors.Errorf(nyiFail, "bitwiseKernel", op)
+		}
+	case []int64:
+		bt, ok := b.([]int64)
+		if !ok {
+			return errors.Errorf(typeMismatchFail, a, b)
+		}
+		rt, ok := retVal.([]int64)
+		if !ok {
+			return errors.Errorf(typeMismatchFail, a, retVal)
+		}
+		switch op {
+		case bitAndOpType:
+			bitAndI64(at, bt, rt, as, bs)
+		case bitOrOpType:
+			bitOrI64(at, bt, rt, as, bs)
+		case bitXorOpType:
+			bitXorI64(at, bt, rt, as, bs)
+		case shlOpType:
+			bitShlI64(at, bt, rt, as, bs)
+		case shrOpType:
+			bitShrI64(at, bt, rt, as, bs)
+		default:
+			return errors.Errorf(nyiFail, "bitwiseKernel", op)
+		}
+	case []uint:
+		bt, ok := b.([]uint)
+		if !ok {
+			return errors.Errorf(typeMismatchFail, a, b)
+		}
+		rt, ok := retVal.([]uint)
+		if !ok {
+			return errors.Errorf(typeMismatchFail, a, retVal)
+		}
+		switch op {
+		case bitAndOpType:
+			bitAndU(at, bt, rt, as, bs)
+		case bitOrOpType:
+			bitOrU(at, bt, rt, as, bs)
+		case bitXorOpType:
+			bitXorU(at, bt, rt, as, bs)
+		case shlOpType:
+			bitShlU(at, bt, rt, as, bs)
+		case shrOpType:
+			bitShrU(at, bt, rt, as, bs)
+		default:
+			return errors.Errorf(nyiFail, "bitwiseKernel", op)
+		}
+	case []uint8:
+		bt, ok := b.([]uint8)
+		if !ok {
+			return errors.Errorf(typeMismatchFail, a, b)
+		}
+		rt, ok := retVal.([]uint8)
+		if !ok {
+			return errors.Errorf(typeMismatchFail, a, retVal)
+		}
+		switch op {
+		case bitAndOpType:
+			bitAndU8(at, bt, rt, as, bs)
+		case bitOrOpType:
+			bitOrU8(at, bt, rt, as, bs)
+		case bitXorOpType:
+			bitXorU8(at, bt, rt, as, bs)
+		case shlOpType:
+			bitShlU8(at, bt, rt, as, bs)
+		case shrOpType:
+			bitShrU8(at, bt, rt, as, bs)
+		default:
+			return errors.Errorf(nyiFail, "bitwiseKernel", op)
+		}
+	case []uint16:
+		bt, ok := b.([]uint16)
+		if !ok {
+			return errors.Errorf(typeMismatchFail, a, b)
+		}
+		rt, ok := retVal.([]uint16)
+		if !ok {
+			return errors.Errorf(typeMismatchFail, a, retVal)
+		}
+		switch op {
+		case bitAndOpType:
+			bitAndU16(at, bt, rt, as, bs)
+		case bitOrOpType:
+			bitOrU16(at, bt, rt, as, bs)
+		case bitXorOpType:
+			bitXorU16(at, bt, rt, as, bs)
+		case shlOpType:
+			bitShlU16(at, bt, rt, as, bs)
+		case shrOpType:
+			bitShrU16(at, bt, rt, as, bs)
+		default:
+			return errors.Errorf(nyiFail, "bitwiseKernel", op)
+		}
+	case []uint32:
+		bt, ok := b.([]uint32)
+		if !ok {
+			return errors.Errorf(typeMismatchFail, a, b)
+		}
+		rt, ok := retVal.([]uint32)
+		if !ok {
+			return errors.Errorf(typeMismatchFail, a, retVal)
+		}
+		switch op {
+		case bitAndOpType:
+			bitAndU32(at, bt, rt, as, bs)
+		case bitOrOpType:
+			bitOrU32(at, bt, rt, as, bs)
+		case bitXorOpType:
+			bitXorU32(at, bt, rt, as, bs)
+		case shlOpType:
+			bitShlU32(at, bt, rt, as, bs)
+		case shrOpType:
+			bitShrU32(at, bt, rt, as, bs)
+		default:
+			return errors.Errorf(nyiFail, "bitwiseKernel", op)
+		}
+	case []uint64:
+		bt, ok := b.([]uint64)
+		if !ok {
+			return errors.Errorf(typeMismatchFail, a, b)
+		}
+		rt, ok := retVal.([]uint64)
+		if !ok {
+			return errors.Errorf(typeMismatchFail, a, retVal)
+		}
+		switch op {
+		case bitAndOpType:
+			bitAndU64(at, bt, rt, as, bs)
+		case bitOrOpType:
+			bitOrU64(at, bt, rt, as, bs)
+		case bitXorOpType:
+			bitXorU64(at, bt, rt, as, bs)
+		case shlOpType:
+			bitShlU64(at, bt, rt, as, bs)
+		case shrOpType:
+			bitShrU64(at, bt, rt, as, bs)
+		default:
+			return errors.Errorf(nyiFail, "bitwiseKernel", op)
+		}
+	default:
+		return errors.Errorf(nyiTypeFail, "bitwiseKernel", a)
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package gorgonia
+
+// Code generated by genapi, which is a API generation tool for Gorgonia. DO NOT EDIT.
+
+// fmaF32 computes retVal[i] = a[i]*b[i] + c[i]. All slices must be at least as long as retVal.
+func fmaF32(a, b, c, retVal []float32) {
+	n := len(retVal)
+	a, b, c = a[:n], b[:n], c[:n]
+	i := 0
+	for ; i+4 <= n; i += 4 {
+		retVal[i] = a[i]*b[i] + c[i]
+		retVal[i+1] = a[i+1]*b[i+1] + c[i+1]
+		retVal[i+2] = a[i+2]*b[i+2] + c[i+2]
+		retVal[i+3] = a[i+3]*b[i+3] + c[i+3]
+	}
+	for ; i < n; i++ {
+		retVal[i] = a[i]*b[i] + c[i]
+	}
+}
+
+// scaleAddF32 computes retVal[i] = a[i]*s + c[i]. All slices must be at least as long as retVal.
+func scaleAddF32(a []float32, s float32, c, retVal []float32) {
+	n := len(retVal)
+	a, c = a[:n], c[:n]
+	i := 0
+	for ; i+4 <= n; i += 4 {
+		retVal[i] = a[i]*s + c[i]
+		retVal[i+1] = a[i+1]*s + c[i+1]
+		retVal[i+2] = a[i+2]*s + c[i+2]
+		retVal[i+3] = a[i+3]*s + c[i+3]
+	}
+	for ; i < n; i++ {
+		retVal[i] = a[i]*s + c[i]
+	}
+}
+
+// axpyF32 computes y[i] += alpha*x[i] in place. x must be at least as long as y.
+func axpyF32(alpha float32, x, y []float32) {
+	n := len(y)
+	x = x[:n]
+	i := 0
+	for ; i+4 <= n; i += 4 {
+		y[i] += alpha * x[i]
+		y[i+1] += alpha * x[i+1]
+		y[i+2] += alpha * x[i+2]
+		y[i+3] += alpha * x[i+3]
+	}
+	for ; i < n; i++ {
+		y[i] += alpha * x[i]
+	}
+}
+
+// fmaF64 computes retVal[i] = a[i]*b[i] + c[i]. All slices must be at least as long as retVal.
+func fmaF64(a, b, c, retVal []float64) {
+	n := len(retVal)
+	a, b, c = a[:n], b[:n], c[:n]
+	i := 0
+	for ; i+4 <= n; i += 4 {
+		retVal[i] = a[i]*b[i] + c[i]
+		retVal[i+1] = a[i+1]*b[i+1] + c[i+1]
+		retVal[i+2] = a[i+2]*b[i+2] + c[i+2]
+		retVal[i+3] = a[i+3]*b[i+3] + c[i+3]
+	}
+	for ; i < n; i++ {
+		retVal[i] = a[i]*b[i] + c[i]
+	}
+}
+
+// scaleAddF64 computes retVal[i] = a[i]*s + c[i]. All slices must be at least as long as retVal.
+func scaleAddF64(a []float64, s float64, c, retVal []float64) {
+	n := len(retVal)
+	a, c = a[:n], c[:n]
+	i := 0
+	for ; i+4 <= n; i += 4 {
+		retVal[i] = a[i]*s + c[i]
+		retVal[i+1] = a[i+1]*s + c[i+1]
+		retVal[i+2] = a[i+2]*s + c[i+2]
+		retVal[i+3] = a[i+3]*s + c[i+3]
+	}
+	for ; i < n; i++ {
+		retVal[i] = a[i]*s + c[i]
+	}
+}
+
+// axpyF64 computes y[i] += alpha*x[i] in place. x must be at least as long as y.
+func axpyF64(alpha float64, x, y []float64) {
+	n := len(y)
+	x = x[:n]
+	i := 0
+	for ; i+4 <= n; i += 4 {
+		y[i] += alpha * x[i]
+		y[i+1] += alpha * x[i+1]
+		y[i+2] += alpha * x[i+2]
+		y[i+3] += alpha * x[i+3]
+	}
+	for ; i < n; i++ {
+		y[i] += alpha * x[i]
+	}
+}
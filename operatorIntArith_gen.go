@@ -0,0 +1,282 @@
+package gorgonia
+
+// Code generated by genapi, which is a API generation tool for Gorgonia. DO NOT EDIT.
+
+import (
+	"math"
+
+	"github.com/pkg/errors"
+)
+
+// satAddI8 performs a[i*as] + b[i*bs], clamped to the range of int8, for each element of retVal. as and bs are the strides of a and b, which are either 0 (scalar) or 1.
+func satAddI8(a, b, retVal []int8, as, bs int) {
+	for i := range retVal {
+		r := int32(a[i*as]) + int32(b[i*bs])
+		switch {
+		case r > math.MaxInt8:
+			r = math.MaxInt8
+		case r < math.MinInt8:
+			r = math.MinInt8
+		}
+		retVal[i] = int8(r)
+	}
+}
+
+// wrapAddI8 performs a[i*as] + b[i*bs], wrapping around on overflow, for each element of retVal. as and bs are the strides of a and b, which are either 0 (scalar) or 1.
+func wrapAddI8(a, b, retVal []int8, as, bs int) {
+	for i := range retVal {
+		retVal[i] = a[i*as] + b[i*bs]
+	}
+}
+
+// satSubI8 performs a[i*as] - b[i*bs], clamped to the range of int8, for each element of retVal. as and bs are the strides of a and b, which are either 0 (scalar) or 1.
+func satSubI8(a, b, retVal []int8, as, bs int) {
+	for i := range retVal {
+		r := int32(a[i*as]) - int32(b[i*bs])
+		switch {
+		case r > math.MaxInt8:
+			r = math.MaxInt8
+		case r < math.MinInt8:
+			r = math.MinInt8
+		}
+		retVal[i] = int8(r)
+	}
+}
+
+// wrapSubI8 performs a[i*as] - b[i*bs], wrapping around on overflow, for each element of retVal. as and bs are the strides of a and b, which are either 0 (scalar) or 1.
+func wrapSubI8(a, b, retVal []int8, as, bs int) {
+	for i := range retVal {
+		retVal[i] = a[i*as] - b[i*bs]
+	}
+}
+
+// satMulI8 performs a[i*as] * b[i*bs], clamped to the range of int8, for each element of retVal. as and bs are the strides of a and b, which are either 0 (scalar) or 1.
+func satMulI8(a, b, retVal []int8, as, bs int) {
+	for i := range retVal {
+		r := int32(a[i*as]) * int32(b[i*bs])
+		switch {
+		case r > math.MaxInt8:
+			r = math.MaxInt8
+		case r < math.MinInt8:
+			r = math.MinInt8
+		}
+		retVal[i] = int8(r)
+	}
+}
+
+// wrapMulI8 performs a[i*as] * b[i*bs], wrapping around on overflow, for each element of retVal. as and bs are the strides of a and b, which are either 0 (scalar) or 1.
+func wrapMulI8(a, b, retVal []int8, as, bs int) {
+	for i := range retVal {
+		retVal[i] = a[i*as] * b[i*bs]
+	}
+}
+
+// satAddU8 performs a[i*as] + b[i*bs], clamped to the range of uint8, for each element of retVal. as and bs are the strides of a and b, which are either 0 (scalar) or 1.
+func satAddU8(a, b, retVal []uint8, as, bs int) {
+	for i := range retVal {
+		r := int32(a[i*as]) + int32(b[i*bs])
+		switch {
+		case r > math.MaxUint8:
+			r = math.MaxUint8
+		case r < 0:
+			r = 0
+		}
+		retVal[i] = uint8(r)
+	}
+}
+
+// wrapAddU8 performs a[i*as] + b[i*bs], wrapping around on overflow, for each element of retVal. as and bs are the strides of a and b, which are either 0 (scalar) or 1.
+func wrapAddU8(a, b, retVal []uint8, as, bs int) {
+	for i := range retVal {
+		retVal[i] = a[i*as] + b[i*bs]
+	}
+}
+
+// satSubU8 performs a[i*as] - b[i*bs], clamped to the range of uint8, for each element of retVal. as and bs are the strides of a and b, which are either 0 (scalar) or 1.
+func satSubU8(a, b, retVal []uint8, as, bs int) {
+	for i := range retVal {
+		r := int32(a[i*as]) - int32(b[i*bs])
+		switch {
+		case r > math.MaxUint8:
+			r = math.MaxUint8
+		case r < 0:
+			r = 0
+		}
+		retVal[i] = uint8(r)
+	}
+}
+
+// wrapSubU8 performs a[i*as] - b[i*bs], wrapping around on overflow, for each element of retVal. as and bs are the strides of a and b, which are either 0 (scalar) or 1.
+func wrapSubU8(a, b, retVal []uint8, as, bs int) {
+	for i := range retVal {
+		retVal[i] = a[i*as] - b[i*bs]
+	}
+}
+
+// satMulU8 performs a[i*as] * b[i*bs], clamped to the range of uint8, for each element of retVal. as and bs are the strides of a and b, which are either 0 (scalar) or 1.
+func satMulU8(a, b, retVal []uint8, as, bs int) {
+	for i := range retVal {
+		r := int32(a[i*as]) * int32(b[i*bs])
+		switch {
+		case r > math.MaxUint8:
+			r = math.MaxUint8
+		case r < 0:
+			r = 0
+		}
+		retVal[i] = uint8(r)
+	}
+}
+
+// wrapMulU8 performs a[i*as] * b[i*bs], wrapping around on overflow, for each element of retVal. as and bs are the strides of a and b, which are either 0 (scalar) or 1.
+func wrapMulU8(a, b, retVal []uint8, as, bs int) {
+	for i := range retVal {
+		retVal[i] = a[i*as] * b[i*bs]
+	}
+}
+
+// satAddI16 performs a[i*as] + b[i*bs], clamped to the range of int16, for each element of retVal. as and bs are the strides of a and b, which are either 0 (scalar) or 1.
+func satAddI16(a, b, retVal []int16, as, bs int) {
+	for i := range retVal {
+		r := int32(a[i*as]) + int32(b[i*bs])
+		switch {
+		case r > math.MaxInt16:
+			r = math.MaxInt16
+		case r < math.MinInt16:
+			r = math.MinInt16
+		}
+		retVal[i] = int16(r)
+	}
+}
+
+// wrapAddI16 performs a[i*as] + b[i*bs], wrapping around on overflow, for each element of retVal. as and bs are the strides of a and b, which are either 0 (scalar) or 1.
+func wrapAddI16(a, b, retVal []int16, as, bs int) {
+	for i := range retVal {
+		retVal[i] = a[i*as] + b[i*bs]
+	}
+}
+
+// satSubI16 performs a[i*as] - b[i*bs], clamped to the range of int16, for each element of retVal. as and bs are the strides of a and b, which are either 0 (scalar) or 1.
+func satSubI16(a, b, retVal []int16, as, bs int) {
+	for i := range retVal {
+		r := int32(a[i*as]) - int32(b[i*bs])
+		switch {
+		case r > math.MaxInt16:
+			r = math.MaxInt16
+		case r < math.MinInt16:
+			r = math.MinInt16
+		}
+		retVal[i] = int16(r)
+	}
+}
+
+// wrapSubI16 performs a[i*as] - b[i*bs], wrapping around on overflow, for each element of retVal. as and bs are the strides of a and b, which are either 0 (scalar) or 1.
+func wrapSubI16(a, b, retVal []int16, as, bs int) {
+	for i := range retVal {
+		retVal[i] = a[i*as] - b[i*bs]
+	}
+}
+
+// satMulI16 performs a[i*as] * b[i*bs], clamped to the range of int16, for each element of retVal. as and bs are the strides of a and b, which are either 0 (scalar) or 1.
+func satMulI16(a, b, retVal []int16, as, bs int) {
+	for i := range retVal {
+		r := int32(a[i*as]) * int32(b[i*bs])
+		switch {
+		case r > math.MaxInt16:
+			r = math.MaxInt16
+		case r < math.MinInt16:
+			r = math.MinInt16
+		}
+		retVal[i] = int16(r)
+	}
+}
+
+// wrapMulI16 performs a[i*as] * b[i*bs], wrapping around on overflow, for each element of retVal. as and bs are the strides of a and b, which are either 0 (scalar) or 1.
+func wrapMulI16(a, b, retVal []int16, as, bs int) {
+	for i := range retVal {
+		retVal[i] = a[i*as] * b[i*bs]
+	}
+}
+
+// intArithKernel dispatches to the correct kernel given the op and the dtype of the slices. a, b and retVal must be slices of the same type.
+func intArithKernel(op intArithOpType, a, b, retVal interface{}, as, bs int) error {
+	switch at := a.(type) {
+	case []int8:
+		bt, ok := b.([]int8)
+		if !ok {
+			return errors.Errorf(typeMismatchFail, a, b)
+		}
+		rt, ok := retVal.([]int8)
+		if !ok {
+			return errors.Errorf(typeMismatchFail, a, retVal)
+		}
+		switch op {
+		case satAddOpType:
+			satAddI8(at, bt, rt, as, bs)
+		case wrapAddOpType:
+			wrapAddI8(at, bt, rt, as, bs)
+		case satSubOpType:
+			satSubI8(at, bt, rt, as, bs)
+		case wrapSubOpType:
+			wrapSubI8(at, bt, rt, as, bs)
+		case satMulOpType:
+			satMulI8(at, bt, rt, as, bs)
+		case wrapMulOpType:
+			wrapMulI8(at, bt, rt, as, bs)
+		default:
+			return errors.Errorf(nyiFail, "intArithKernel", op)
+		}
+	case []uint8:
+		bt, ok := b.([]uint8)
+		if !ok {
+			return errors.Errorf(typeMismatchFail, a, b)
+		}
+		rt, ok := retVal.([]uint8)
+		if !ok {
+			return errors.Errorf(typeMismatchFail, a, retVal)
+		}
+		switch op {
+		case satAddOpType:
+			satAddU8(at, bt, rt, as, bs)
+		case wrapAddOpType:
+			wrapAddU8(at, bt, rt, as, bs)
+		case satSubOpType:
+			satSubU8(at, bt, rt, as, bs)
+		case wrapSubOpType:
+			wrapSubU8(at, bt, rt, as, bs)
+		case satMulOpType:
+			satMulU8(at, bt, rt, as, bs)
+		case wrapMulOpType:
+			wrapMulU8(at, bt, rt, as, bs)
+		default:
+			return errors.Errorf(nyiFail, "intArithKernel", op)
+		}
+	case []int16:
+		bt, ok := b.([]int16)
+		if !ok {
+			return errors.Errorf(typeMismatchFail, a, b)
+		}
+		rt, ok := retVal.([]int16)
+		if !ok {
+			return errors.Errorf(typeMismatchFail, a, retVal)
+		}
+		switch op {
+		case satAddOpType:
+			satAddI16(at, bt, rt, as, bs)
+		case wrapAddOpType:
+			wrapAddI16(at, bt, rt, as, bs)
+		case satSubOpType:
+			satSubI16(at, bt, rt, as, bs)
+		case wrapSubOpType:
+			wrapSubI16(at, bt, rt, as, bs)
+		case satMulOpType:
+			satMulI16(at, bt, rt, as, bs)
+		case wrapMulOpType:
+			wrapMulI16(at, bt, rt, as, bs)
+		default:
+			return errors.Errorf(nyiFail, "intArithKernel", op)
+		}
+	default:
+		return errors.Errorf(nyiTypeFail, "intArithKernel", a)
+	}
+	return nil
+}
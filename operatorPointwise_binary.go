@@ -413,13 +413,37 @@ func (o tBinOp) do(vals []Value, opts ...tensor.FuncOpt) (retVal Value, err erro
 		if fn == nil {
 			return nil, errors.Errorf("nil function returned for %v", o.ʘBinaryOperatorType)
 		}
+		if checkOverflowOf(vals...) {
+			if err = binOpOverflow(o.ʘBinaryOperatorType, d0, a, b); err != nil {
+				return nil, err
+			}
+		}
 		retVal, err = (*fn)(a, b, opts...)
 	} else {
 		fn := cmpOps[o.ʘBinaryOperatorType]
 		if fn == nil {
 			return nil, errors.Errorf("nil function returned for %v", o.ʘBinaryOperatorType)
 		}
-		retVal, err = (*fn)(a, b, opts...)
+
+		var nans []bool
+		switch nanModeOf(vals...) {
+		case NaNError:
+			if hasNaN(vals[0], CPU) || hasNaN(vals[1], CPU) {
+				return nil, errors.Errorf("NaN encountered in %v", o.ʘBinaryOperatorType)
+			}
+		case NaNPropagate:
+			// the mask has to be computed before the op, as the op may overwrite its inputs
+			if nans, err = nanMask(vals[0], vals[1]); err != nil {
+				return nil, err
+			}
+		}
+
+		if retVal, err = (*fn)(a, b, opts...); err != nil {
+			return
+		}
+		if nans != nil {
+			err = setNaNs(retVal, nans)
+		}
 
 	}
 	return
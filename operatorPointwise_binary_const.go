@@ -1,18 +1,17 @@
 package gorgonia
 
+// Code generated by genapi, which is a API generation tool for Gorgonia. DO NOT EDIT.
+
 import "gorgonia.org/tensor"
 
 var (
 	/* scalar-tensor float64 and vice versa */
 
-	// arith
 	tadd = denseBinOp(tensor.Add)
 	tsub = denseBinOp(tensor.Sub)
 	tmul = denseBinOp(tensor.Mul)
 	tdiv = denseBinOp(tensor.Div)
 	tpow = denseBinOp(tensor.Pow)
-
-	// cmp
 	tlt  = denseCmpOp(tensor.Lt)
 	tgt  = denseCmpOp(tensor.Gt)
 	tlte = denseCmpOp(tensor.Lte)
@@ -27,14 +26,11 @@ type denseCmpOp func(a, b interface{}, opts ...tensor.FuncOpt) (tensor.Tensor, e
 type ʘBinaryOperatorType byte
 
 const (
-	// arith
 	addOpType ʘBinaryOperatorType = iota
 	subOpType
 	mulOpType
 	divOpType
 	powOpType
-
-	// cmp
 	ltOpType
 	gtOpType
 	lteOpType
@@ -49,17 +45,30 @@ func (op ʘBinaryOperatorType) String() string {
 	return ʘBinOpStrs[op]
 }
 
+// onnx returns the name of the equivalent ONNX operator. It returns "" if there is none.
+func (op ʘBinaryOperatorType) onnx() string {
+	if op >= maxʘBinaryOpType {
+		return ""
+	}
+	return ʘBinOpONNX[op]
+}
+
+// cudaKernel returns the name of the CUDA kernels (without the suffixes) in the elembinop module. It returns "" if there are none.
+func (op ʘBinaryOperatorType) cudaKernel() string {
+	if op >= maxʘBinaryOpType {
+		return ""
+	}
+	return ʘBinOpCUDA[op]
+}
+
 // ʘBinOpStrs is the string representation for a binOpType
 // It should be held constant.
 var ʘBinOpStrs = [maxʘBinaryOpType]string{
-	// arith ops
 	"+",
 	"-",
 	"⊙",
 	"÷",
 	"^",
-
-	// cmp ops
 	"<",
 	">",
 	"<=",
@@ -71,14 +80,43 @@ var ʘBinOpStrs = [maxʘBinaryOpType]string{
 // ʘBinOpNames is the string representation for a binOpType
 // It should be held constant.
 var ʘBinOpNames = [maxʘBinaryOpType]string{
-	// arith ops
 	"add",
 	"sub",
 	"mul",
 	"div",
 	"pow",
+	"lt",
+	"gt",
+	"lte",
+	"gte",
+	"eq",
+	"ne",
+}
+
+// ʘBinOpONNX holds the names of the equivalent ONNX operators
+// It should be held constant.
+var ʘBinOpONNX = [maxʘBinaryOpType]string{
+	"Add",
+	"Sub",
+	"Mul",
+	"Div",
+	"Pow",
+	"Less",
+	"Greater",
+	"LessOrEqual",
+	"GreaterOrEqual",
+	"Equal",
+	"",
+}
 
-	// cmp ops
+// ʘBinOpCUDA holds the names of the CUDA kernels generated for each binOpType
+// It should be held constant.
+var ʘBinOpCUDA = [maxʘBinaryOpType]string{
+	"add",
+	"sub",
+	"mul",
+	"div",
+	"pow",
 	"lt",
 	"gt",
 	"lte",
@@ -90,25 +128,56 @@ var ʘBinOpNames = [maxʘBinaryOpType]string{
 // ʘBinOpCommutative is the array that stores whether a binary operator is commutative
 // It should be held constant.
 var ʘBinOpCommutative = [maxʘBinaryOpType]bool{
-	true, false, true, false, false,
-	false, false, false, false, true, true,
+	true,
+	false,
+	true,
+	false,
+	false,
+	false,
+	false,
+	false,
+	false,
+	true,
+	true,
 }
 
 var ʘBinOpDiffExprs = [maxʘBinaryOpType]func(x, y, z, gradZ *Node) (Nodes, error){
-	addDiffExpr, subDiffExpr, hadamardProdDiffExpr, hadamardDivDiffExpr, hadamardPowDiffExpr,
-	nondiffBinOpExpr, nondiffBinOpExpr, nondiffBinOpExpr, nondiffBinOpExpr, nondiffBinOpExpr, nondiffBinOpExpr,
+	addDiffExpr,
+	subDiffExpr,
+	hadamardProdDiffExpr,
+	hadamardDivDiffExpr,
+	hadamardPowDiffExpr,
+	nondiffBinOpExpr,
+	nondiffBinOpExpr,
+	nondiffBinOpExpr,
+	nondiffBinOpExpr,
+	nondiffBinOpExpr,
+	nondiffBinOpExpr,
 }
 
 var ʘBinOpDiffFns = [maxʘBinaryOpType]func(ctx ExecutionContext, x, y, z *Node) error{
-	addDiff, subDiff, hadamardProdDiff, hadamardDivDiff, hadamardPowDiff,
-	nondiffBinOp, nondiffBinOp, nondiffBinOp, nondiffBinOp, nondiffBinOp, nondiffBinOp,
+	addDiff,
+	subDiff,
+	hadamardProdDiff,
+	hadamardDivDiff,
+	hadamardPowDiff,
+	nondiffBinOp,
+	nondiffBinOp,
+	nondiffBinOp,
+	nondiffBinOp,
+	nondiffBinOp,
+	nondiffBinOp,
 }
 
 // isCommutative gives info about whether the operator is commutative
 // For example:
-//		a + b == b + a
+//
+//	a + b == b + a
+//
 // will ALWAYS evaluate to true. The same cannot be said about subtraction:
-// 		a - b != b - a
+//
+//	a - b != b - a
+//
 // While a-b *may* be equal to b-a, it is not guaranteed. Therefore subtraction
 // is not commutative
 func (op ʘBinaryOperatorType) isCommutative() bool {
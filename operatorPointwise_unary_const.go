@@ -1,5 +1,7 @@
 package gorgonia
 
+// Code generated by genapi, which is a API generation tool for Gorgonia. DO NOT EDIT.
+
 import (
 	"fmt"
 	"math"
@@ -28,7 +30,7 @@ var (
 	inversef64     = sf64UnaryOperator(_inversef64)
 	inverseSqrtf64 = sf64UnaryOperator(_inverseSqrtf64)
 
-	// activation functions
+	// typically used in activation functions
 	cubef64    = sf64UnaryOperator(_cubef64)
 	tanhf64    = sf64UnaryOperator(_tanhf64)
 	sigmoidf64 = sf64UnaryOperator(_sigmoidf64)
@@ -37,10 +39,8 @@ var (
 	log1pf64    = sf64UnaryOperator(math.Log1p)
 	expm1f64    = sf64UnaryOperator(math.Expm1)
 	softplusf64 = sf64UnaryOperator(_softplusf64)
-	// softplus isn't necessarily only a numerical stabilization op
-	// (you can use it elsewhere), but I included it under numerical optimization
 
-	/* Float32 */
+	/* float32 */
 
 	// non differentiable
 	absf32   = sf32UnaryOperator(math32.Abs)
@@ -48,7 +48,7 @@ var (
 	ceilf32  = sf32UnaryOperator(math32.Ceil)
 	floorf32 = sf32UnaryOperator(math32.Floor)
 
-	// start differentiable
+	// differentiable
 	sinf32         = sf32UnaryOperator(math32.Sin)
 	cosf32         = sf32UnaryOperator(math32.Cos)
 	expf32         = sf32UnaryOperator(math32.Exp)
@@ -79,7 +79,7 @@ const (
 	ceilOpType
 	floorOpType
 
-	// start differentiable
+	// differentiable
 	sinOpType
 	cosOpType
 	expOpType
@@ -88,15 +88,15 @@ const (
 	negOpType
 	squareOpType
 	sqrtOpType
-	inverseOpType     // multiplicative inverse
-	inverseSqrtOpType // 1/sqrt(x)
+	inverseOpType
+	inverseSqrtOpType
 
 	// typically used in activation functions
 	cubeOpType
 	tanhOpType
 	sigmoidOpType
 
-	// optimization related
+	// numerical stabilization optimization
 	log1pOpType
 	expm1OpType
 	softplusOpType
@@ -112,46 +112,166 @@ func (u ʘUnaryOperatorType) String() string {
 	return ʘUnaryOpStrs[u]
 }
 
+// onnx returns the name of the equivalent ONNX operator. It returns "" if there is none.
+func (u ʘUnaryOperatorType) onnx() string {
+	if u >= maxʘUnaryOperator {
+		return ""
+	}
+	return ʘUnaryOpONNX[u]
+}
+
+// cudaKernel returns the name of the CUDA kernel (without the dtype suffix) in the elemunaryop module. It returns "" if there is none.
+func (u ʘUnaryOperatorType) cudaKernel() string {
+	if u >= maxʘUnaryOperator {
+		return ""
+	}
+	return ʘUnaryOpCUDA[u]
+}
+
 // ʘUnaryOpStrs is the string representation for a unaryOpType
 // It should be held constant.
 var ʘUnaryOpStrs = [maxʘUnaryOperator]string{
-	"abs", "sign", "ceil", "floor",
-	"sin", "cos", "exp",
-	"ln", "log2", "neg", "square", "sqrt",
-	"inv", "invSqrt",
-	"cube", "tanh", "sigmoid",
+	"abs",
+	"sign",
+	"ceil",
+	"floor",
+	"sin",
+	"cos",
+	"exp",
+	"ln",
+	"log2",
+	"neg",
+	"square",
+	"sqrt",
+	"inv",
+	"invSqrt",
+	"cube",
+	"tanh",
+	"sigmoid",
+	"log1p",
+	"expm1",
+	"softplus",
+}
+
+// ʘUnaryOpONNX holds the names of the equivalent ONNX operators
+// It should be held constant.
+var ʘUnaryOpONNX = [maxʘUnaryOperator]string{
+	"Abs",
+	"Sign",
+	"Ceil",
+	"Floor",
+	"Sin",
+	"Cos",
+	"Exp",
+	"Log",
+	"",
+	"Neg",
+	"",
+	"Sqrt",
+	"Reciprocal",
+	"",
+	"",
+	"Tanh",
+	"Sigmoid",
+	"",
+	"",
+	"Softplus",
+}
 
-	"log1p", "expm1", "softplus",
+// ʘUnaryOpCUDA holds the names of the CUDA kernels generated for each unaryOpType
+// It should be held constant.
+var ʘUnaryOpCUDA = [maxʘUnaryOperator]string{
+	"abs",
+	"sign",
+	"ceil",
+	"floor",
+	"sin",
+	"cos",
+	"exp",
+	"ln",
+	"log2",
+	"neg",
+	"square",
+	"sqrt",
+	"inverse",
+	"inverseSqrt",
+	"cube",
+	"tanh",
+	"sigmoid",
+	"log1p",
+	"expm1",
+	"softplus",
 }
 
 // ʘUnaryOpDifferentiable is the array of whether a unary operator is differentiable
 // It should be held constant
 var ʘUnaryOpDifferentiable = [maxʘUnaryOperator]bool{
-	true, false, false, false,
-	true, true, true,
-	true, true, true, true, true,
-	true, true,
-	true, true, true,
-
-	true, true, true,
+	true,
+	false,
+	false,
+	false,
+	true,
+	true,
+	true,
+	true,
+	true,
+	true,
+	true,
+	true,
+	true,
+	true,
+	true,
+	true,
+	true,
+	true,
+	true,
+	true,
 }
 
 var ʘUnaryOpDiffExprs = [maxʘUnaryOperator]func(x, y, gradY *Node) (*Node, error){
-	absDiffExpr, nondiffUnaryOpExpr, nondiffUnaryOpExpr, nondiffUnaryOpExpr,
-	sinDiffExpr, cosDiffExpr, expDiffExpr,
-	lnDiffExpr, log2DiffExpr, negDiffExpr, squareDiffExpr, sqrtDiffExpr,
-	inverseDiffExpr, inverseSqrtDiffExpr, cubeDiffExpr, tanhDiffExpr, sigmoidDiffExpr,
-
-	log1pDiffExpr, expm1DiffExpr, softplusDiffExpr,
+	absDiffExpr,
+	nondiffUnaryOpExpr,
+	nondiffUnaryOpExpr,
+	nondiffUnaryOpExpr,
+	sinDiffExpr,
+	cosDiffExpr,
+	expDiffExpr,
+	lnDiffExpr,
+	log2DiffExpr,
+	negDiffExpr,
+	squareDiffExpr,
+	sqrtDiffExpr,
+	inverseDiffExpr,
+	inverseSqrtDiffExpr,
+	cubeDiffExpr,
+	tanhDiffExpr,
+	sigmoidDiffExpr,
+	log1pDiffExpr,
+	expm1DiffExpr,
+	softplusDiffExpr,
 }
 
 var ʘUnaryOpDiffFns = [maxʘUnaryOperator]func(x, y *Node) error{
-	absDiff, nondiffUnaryOp, nondiffUnaryOp, nondiffUnaryOp,
-	sinDiff, cosDiff, expDiff,
-	lnDiff, log2Diff, negDiff, squareDiff, sqrtDiff,
-	inverseDiff, inverseSqrtDiff, cubeDiff, tanhDiff, sigmoidDiff,
-
-	log1pDiff, expm1Diff, softplusDiff,
+	absDiff,
+	nondiffUnaryOp,
+	nondiffUnaryOp,
+	nondiffUnaryOp,
+	sinDiff,
+	cosDiff,
+	expDiff,
+	lnDiff,
+	log2Diff,
+	negDiff,
+	squareDiff,
+	sqrtDiff,
+	inverseDiff,
+	inverseSqrtDiff,
+	cubeDiff,
+	tanhDiff,
+	sigmoidDiff,
+	log1pDiff,
+	expm1Diff,
+	softplusDiff,
 }
 
 var sf64UnaryOperators = [maxʘUnaryOperator]*sf64UnaryOperator{
@@ -172,7 +292,6 @@ var sf64UnaryOperators = [maxʘUnaryOperator]*sf64UnaryOperator{
 	&cubef64,
 	&tanhf64,
 	&sigmoidf64,
-
 	&log1pf64,
 	&expm1f64,
 	&softplusf64,
@@ -196,7 +315,6 @@ var sf32UnaryOperators = [maxʘUnaryOperator]*sf32UnaryOperator{
 	&cubef32,
 	&tanhf32,
 	&sigmoidf32,
-
 	&log1pf32,
 	&expm1f32,
 	&softplusf32,
@@ -3,7 +3,6 @@ package gorgonia
 // Code generated by genapi, which is a API generation tool for Gorgonia. DO NOT EDIT.
 
 func (f *sf32UnaryOperator) unaryOpType() ʘUnaryOperatorType {
-
 	switch f {
 	case &absf32:
 		return absOpType
@@ -48,10 +47,10 @@ func (f *sf32UnaryOperator) unaryOpType() ʘUnaryOperatorType {
 	}
 	return maxʘUnaryOperator
 }
+
 func (f *sf32UnaryOperator) String() string { return f.unaryOpType().String() }
 
 func (f *sf64UnaryOperator) unaryOpType() ʘUnaryOperatorType {
-
 	switch f {
 	case &absf64:
 		return absOpType
@@ -96,4 +95,5 @@ func (f *sf64UnaryOperator) unaryOpType() ʘUnaryOperatorType {
 	}
 	return maxʘUnaryOperator
 }
+
 func (f *sf64UnaryOperator) String() string { return f.unaryOpType().String() }
@@ -0,0 +1,199 @@
+package gorgonia
+
+// Code generated by genapi, which is a API generation tool for Gorgonia. DO NOT EDIT.
+
+import (
+	"github.com/pkg/errors"
+	"gorgonia.org/tensor"
+)
+
+// wideSumI8I32 sums the elements of a into a int32.
+func wideSumI8I32(a []int8) (retVal int32) {
+	for _, v := range a {
+		retVal += int32(v)
+	}
+	return
+}
+
+// wideDotI8I32 is the dot product of a and b, accumulated into a int32. b must be at least as long as a.
+func wideDotI8I32(a, b []int8) (retVal int32) {
+	b = b[:len(a)]
+	for i, v := range a {
+		retVal += int32(v) * int32(b[i])
+	}
+	return
+}
+
+// wideAddScalarI8I32 computes retVal[i] = int32(a[i]) + s. retVal must be at least as long as a.
+func wideAddScalarI8I32(a []int8, s int32, retVal []int32) {
+	retVal = retVal[:len(a)]
+	for i, v := range a {
+		retVal[i] = int32(v) + s
+	}
+}
+
+// wideSumI8I64 sums the elements of a into a int64.
+func wideSumI8I64(a []int8) (retVal int64) {
+	for _, v := range a {
+		retVal += int64(v)
+	}
+	return
+}
+
+// wideDotI8I64 is the dot product of a and b, accumulated into a int64. b must be at least as long as a.
+func wideDotI8I64(a, b []int8) (retVal int64) {
+	b = b[:len(a)]
+	for i, v := range a {
+		retVal += int64(v) * int64(b[i])
+	}
+	return
+}
+
+// wideAddScalarI8I64 computes retVal[i] = int64(a[i]) + s. retVal must be at least as long as a.
+func wideAddScalarI8I64(a []int8, s int64, retVal []int64) {
+	retVal = retVal[:len(a)]
+	for i, v := range a {
+		retVal[i] = int64(v) + s
+	}
+}
+
+// wideSumI16I32 sums the elements of a into a int32.
+func wideSumI16I32(a []int16) (retVal int32) {
+	for _, v := range a {
+		retVal += int32(v)
+	}
+	return
+}
+
+// wideDotI16I32 is the dot product of a and b, accumulated into a int32. b must be at least as long as a.
+func wideDotI16I32(a, b []int16) (retVal int32) {
+	b = b[:len(a)]
+	for i, v := range a {
+		retVal += int32(v) * int32(b[i])
+	}
+	return
+}
+
+// wideAddScalarI16I32 computes retVal[i] = int32(a[i]) + s. retVal must be at least as long as a.
+func wideAddScalarI16I32(a []int16, s int32, retVal []int32) {
+	retVal = retVal[:len(a)]
+	for i, v := range a {
+		retVal[i] = int32(v) + s
+	}
+}
+
+// wideSumI16I64 sums the elements of a into a int64.
+func wideSumI16I64(a []int16) (retVal int64) {
+	for _, v := range a {
+		retVal += int64(v)
+	}
+	return
+}
+
+// wideDotI16I64 is the dot product of a and b, accumulated into a int64. b must be at least as long as a.
+func wideDotI16I64(a, b []int16) (retVal int64) {
+	b = b[:len(a)]
+	for i, v := range a {
+		retVal += int64(v) * int64(b[i])
+	}
+	return
+}
+
+// wideAddScalarI16I64 computes retVal[i] = int64(a[i]) + s. retVal must be at least as long as a.
+func wideAddScalarI16I64(a []int16, s int64, retVal []int64) {
+	retVal = retVal[:len(a)]
+	for i, v := range a {
+		retVal[i] = int64(v) + s
+	}
+}
+
+// wideSumKernel sums the elements of the slice a into a scalar of the accumulator dtype.
+func wideSumKernel(a interface{}, acc tensor.Dtype) (interface{}, error) {
+	switch at := a.(type) {
+	case []int8:
+		switch acc {
+		case tensor.Int32:
+			return wideSumI8I32(at), nil
+		case tensor.Int64:
+			return wideSumI8I64(at), nil
+		}
+	case []int16:
+		switch acc {
+		case tensor.Int32:
+			return wideSumI16I32(at), nil
+		case tensor.Int64:
+			return wideSumI16I64(at), nil
+		}
+	}
+	return nil, errors.Errorf(wideAccFail, a, acc)
+}
+
+// wideDotKernel is the dot product of the slices a and b, which must be of the same type, accumulated into a scalar of the accumulator dtype.
+func wideDotKernel(a, b interface{}, acc tensor.Dtype) (interface{}, error) {
+	switch at := a.(type) {
+	case []int8:
+		bt, ok := b.([]int8)
+		if !ok {
+			return nil, errors.Errorf(typeMismatchFail, a, b)
+		}
+		switch acc {
+		case tensor.Int32:
+			return wideDotI8I32(at, bt), nil
+		case tensor.Int64:
+			return wideDotI8I64(at, bt), nil
+		}
+	case []int16:
+		bt, ok := b.([]int16)
+		if !ok {
+			return nil, errors.Errorf(typeMismatchFail, a, b)
+		}
+		switch acc {
+		case tensor.Int32:
+			return wideDotI16I32(at, bt), nil
+		case tensor.Int64:
+			return wideDotI16I64(at, bt), nil
+		}
+	}
+	return nil, errors.Errorf(wideAccFail, a, acc)
+}
+
+// wideAddScalarKernel adds the scalar s to the elements of the slice a, writing the results to retVal. s and retVal are of the accumulator type.
+func wideAddScalarKernel(a, s, retVal interface{}) error {
+	switch at := a.(type) {
+	case []int8:
+		switch st := s.(type) {
+		case int32:
+			rt, ok := retVal.([]int32)
+			if !ok {
+				return errors.Errorf(typeMismatchFail, s, retVal)
+			}
+			wideAddScalarI8I32(at, st, rt)
+			return nil
+		case int64:
+			rt, ok := retVal.([]int64)
+			if !ok {
+				return errors.Errorf(typeMismatchFail, s, retVal)
+			}
+			wideAddScalarI8I64(at, st, rt)
+			return nil
+		}
+	case []int16:
+		switch st := s.(type) {
+		case int32:
+			rt, ok := retVal.([]int32)
+			if !ok {
+				return errors.Errorf(typeMismatchFail, s, retVal)
+			}
+			wideAddScalarI16I32(at, st, rt)
+			return nil
+		case int64:
+			rt, ok := retVal.([]int64)
+			if !ok {
+				return errors.Errorf(typeMismatchFail, s, retVal)
+			}
+			wideAddScalarI16I64(at, st, rt)
+			return nil
+		}
+	}
+	return errors.Errorf(wideAccFail, a, s)
+}
@@ -0,0 +1,244 @@
+package gorgonia
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+	"reflect"
+
+	"gorgonia.org/tensor"
+)
+
+// OverflowChecker is any engine that checks integer arithmetic for overflow.
+type OverflowChecker interface {
+	CheckOverflow() bool
+}
+
+// WithOverflowCheck returns a copy of the engine that checks the integer arithmetic for overflow. When it is on, the
+// elementwise arithmetic ops (+, -, ×, ÷, and the negation, absolute value, square and cube) on integer tensors return an
+// *OverflowError instead of silently wrapping around. The check looks at every element, so it is meant for debugging:
+//		m := NewTapeMachine(g, WithEngine(StandardEngine{}.WithOverflowCheck(true)))
+func (e StandardEngine) WithOverflowCheck(check bool) StandardEngine {
+	e.overflowCheck = check
+	return e
+}
+
+// CheckOverflow returns true if the engine checks the integer arithmetic for overflow.
+func (e StandardEngine) CheckOverflow() bool { return e.overflowCheck }
+
+// OverflowError is the error of an integer op whose result does not fit in its dtype.
+type OverflowError struct {
+	Op       string
+	Dtype    tensor.Dtype
+	Indices  []int         // the indices of the elements that overflowed, in the flattened result
+	Operands []interface{} // the operands of the first element that overflowed
+}
+
+func (err *OverflowError) Error() string {
+	var expr string
+	switch len(err.Operands) {
+	case 1:
+		expr = fmt.Sprintf("%v(%v)", err.Op, err.Operands[0])
+	case 2:
+		expr = fmt.Sprintf("%v %v %v", err.Operands[0], err.Op, err.Operands[1])
+	}
+	more := ""
+	if len(err.Indices) > 1 {
+		more = fmt.Sprintf(" and %d more", len(err.Indices)-1)
+	}
+	return fmt.Sprintf("%v overflow in %v at element %d%s", err.Dtype, expr, err.Indices[0], more)
+}
+
+// checkOverflowOf returns true if the engine of any of the given values checks for overflow.
+func checkOverflowOf(vals ...Value) bool {
+	for _, v := range vals {
+		t, ok := v.(tensor.Tensor)
+		if !ok {
+			continue
+		}
+		if e, ok := t.Engine().(OverflowChecker); ok && e.CheckOverflow() {
+			return true
+		}
+	}
+	return false
+}
+
+// dtypeRange returns the range of an integer dtype. ok is false if the dtype is not an integer.
+func dtypeRange(dt tensor.Dtype) (min int64, max uint64, ok bool) {
+	switch dt {
+	case tensor.Int:
+		return math.MinInt64 >> (64 - bits.UintSize), math.MaxInt64 >> (64 - bits.UintSize), true
+	case tensor.Int8:
+		return math.MinInt8, math.MaxInt8, true
+	case tensor.Int16:
+		return math.MinInt16, math.MaxInt16, true
+	case tensor.Int32:
+		return math.MinInt32, math.MaxInt32, true
+	case tensor.Int64:
+		return math.MinInt64, math.MaxInt64, true
+	case tensor.Uint:
+		return 0, math.MaxUint64 >> (64 - bits.UintSize), true
+	case tensor.Uint8:
+		return 0, math.MaxUint8, true
+	case tensor.Uint16:
+		return 0, math.MaxUint16, true
+	case tensor.Uint32:
+		return 0, math.MaxUint32, true
+	case tensor.Uint64:
+		return 0, math.MaxUint64, true
+	}
+	return 0, 0, false
+}
+
+// operandElems returns the elements of an operand of an elementwise op, which is a tensor or a scalar.
+func operandElems(x interface{}) (elems reflect.Value, n int) {
+	if t, ok := x.(tensor.Tensor); ok {
+		x = t.Data()
+	}
+	elems = reflect.ValueOf(x)
+	if elems.Kind() != reflect.Slice {
+		// a scalar, or the data of a tensor of one element
+		one := reflect.MakeSlice(reflect.SliceOf(elems.Type()), 1, 1)
+		one.Index(0).Set(elems)
+		return one, 1
+	}
+	return elems, elems.Len()
+}
+
+// binOpOverflow checks that the arithmetic op on the integer operands a and b does not overflow. A scalar operand is
+// broadcast. It returns nil for operands that are not integers.
+func binOpOverflow(op ʘBinaryOperatorType, dt tensor.Dtype, a, b interface{}) error {
+	min, max, ok := dtypeRange(dt)
+	if !ok {
+		return nil
+	}
+	as, an := operandElems(a)
+	bs, bn := operandElems(b)
+	n := an
+	if bn > n {
+		n = bn
+	}
+
+	var err *OverflowError
+	for i := 0; i < n; i++ {
+		x, y := as.Index(i%an), bs.Index(i%bn)
+		var overflows bool
+		if min < 0 {
+			overflows = signedOverflows(op, x.Int(), y.Int(), min, int64(max))
+		} else {
+			overflows = unsignedOverflows(op, x.Uint(), y.Uint(), max)
+		}
+		if !overflows {
+			continue
+		}
+		if err == nil {
+			err = &OverflowError{Op: op.String(), Dtype: dt, Operands: []interface{}{x.Interface(), y.Interface()}}
+		}
+		err.Indices = append(err.Indices, i)
+	}
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func signedOverflows(op ʘBinaryOperatorType, a, b, min, max int64) bool {
+	var r int64
+	switch op {
+	case addOpType:
+		r = a + b
+		if (a > 0 && b > 0 && r < 0) || (a < 0 && b < 0 && r >= 0) {
+			return true
+		}
+	case subOpType:
+		r = a - b
+		if (b < 0 && r < a) || (b > 0 && r > a) {
+			return true
+		}
+	case mulOpType:
+		r = a * b
+		if a != 0 && (r/a != b || (a == -1 && b == math.MinInt64)) {
+			return true
+		}
+	case divOpType:
+		return a == min && b == -1
+	default:
+		return false
+	}
+	return r < min || r > max
+}
+
+func unsignedOverflows(op ʘBinaryOperatorType, a, b, max uint64) bool {
+	var r uint64
+	switch op {
+	case addOpType:
+		if r = a + b; r < a {
+			return true
+		}
+	case subOpType:
+		return b > a
+	case mulOpType:
+		if r = a * b; a != 0 && r/a != b {
+			return true
+		}
+	default:
+		return false
+	}
+	return r > max
+}
+
+// unaryOpOverflow checks that the unary op on the elements of the integer tensor t does not overflow. It returns nil
+// for the ops that cannot overflow and for tensors that are not integers.
+func unaryOpOverflow(op ʘUnaryOperatorType, t tensor.Tensor) error {
+	min, max, ok := dtypeRange(t.Dtype())
+	if !ok {
+		return nil
+	}
+	var power int
+	switch op {
+	case negOpType, absOpType:
+		power = 1
+	case squareOpType:
+		power = 2
+	case cubeOpType:
+		power = 3
+	default:
+		return nil
+	}
+
+	elems, n := operandElems(t)
+	var err *OverflowError
+	for i := 0; i < n; i++ {
+		x := elems.Index(i)
+		var overflows bool
+		switch {
+		case power == 1 && min < 0:
+			overflows = x.Int() == min // the negation of the minimum is one more than the maximum
+		case power == 1:
+			overflows = op == negOpType && x.Uint() != 0
+		case min < 0:
+			r, a := x.Int(), x.Int()
+			for p := 1; p < power && !overflows; p++ {
+				overflows = signedOverflows(mulOpType, r, a, min, int64(max))
+				r *= a
+			}
+		default:
+			r, a := x.Uint(), x.Uint()
+			for p := 1; p < power && !overflows; p++ {
+				overflows = unsignedOverflows(mulOpType, r, a, max)
+				r *= a
+			}
+		}
+		if !overflows {
+			continue
+		}
+		if err == nil {
+			err = &OverflowError{Op: op.String(), Dtype: t.Dtype(), Operands: []interface{}{x.Interface()}}
+		}
+		err.Indices = append(err.Indices, i)
+	}
+	if err != nil {
+		return err
+	}
+	return nil
+}
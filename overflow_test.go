@@ -0,0 +1,70 @@
+package gorgonia
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"gorgonia.org/tensor"
+)
+
+func TestWithOverflowCheck(t *testing.T) {
+	assert := assert.New(t)
+	run := func(check bool, backing []int8) error {
+		g := NewGraph()
+		x := NewVector(g, tensor.Int8, WithShape(len(backing)), WithName("x"), WithValue(tensor.New(tensor.WithBacking(backing))))
+		y := NewVector(g, tensor.Int8, WithShape(len(backing)), WithName("y"), WithValue(tensor.New(tensor.WithBacking([]int8{100, 100, 100}))))
+		Must(Add(x, y))
+		m := NewTapeMachine(g, WithEngine(StandardEngine{}.WithOverflowCheck(check)))
+		defer m.Close()
+		return m.RunAll()
+	}
+
+	assert.NoError(run(true, []int8{1, 2, 27}))
+	assert.NoError(run(false, []int8{1, 100, 100}), "the arithmetic wraps around by default")
+
+	err := run(true, []int8{1, 100, 100})
+	oe, ok := errors.Cause(err).(*OverflowError)
+	if !assert.True(ok, "%v", err) {
+		return
+	}
+	assert.Equal([]int{1, 2}, oe.Indices)
+	assert.Equal("int8 overflow in 100 + 100 at element 1 and 1 more", oe.Error())
+}
+
+func TestBinOpOverflow(t *testing.T) {
+	assert := assert.New(t)
+	i64 := func(vals ...int64) tensor.Tensor { return tensor.New(tensor.WithBacking(vals)) }
+	u8 := func(vals ...uint8) tensor.Tensor { return tensor.New(tensor.WithBacking(vals)) }
+
+	assert.NoError(binOpOverflow(addOpType, tensor.Int64, i64(1<<62, -1<<62), i64(1<<62-1, -1<<62)))
+	assert.Error(binOpOverflow(addOpType, tensor.Int64, i64(1<<62), i64(1<<62)))
+	assert.Error(binOpOverflow(subOpType, tensor.Int64, i64(-1<<63), i64(1)))
+	assert.Error(binOpOverflow(mulOpType, tensor.Int64, i64(1<<32), i64(1<<31)))
+	assert.NoError(binOpOverflow(mulOpType, tensor.Int64, i64(1<<31), i64(1<<31)))
+	assert.Error(binOpOverflow(divOpType, tensor.Int64, i64(-1<<63), i64(-1)))
+
+	assert.Error(binOpOverflow(subOpType, tensor.Uint8, u8(1), u8(2)))
+	assert.Error(binOpOverflow(mulOpType, tensor.Uint8, u8(16), u8(16)))
+	assert.NoError(binOpOverflow(mulOpType, tensor.Uint8, u8(15, 1), u8(17, 255)))
+
+	assert.NoError(binOpOverflow(addOpType, tensor.Float64, 1e308, 1e308), "only integers are checked")
+}
+
+func TestUnaryOpOverflow(t *testing.T) {
+	assert := assert.New(t)
+	i8 := tensor.New(tensor.WithBacking([]int8{-128, 11, 12, -5}))
+	err := unaryOpOverflow(negOpType, i8)
+	if assert.Error(err) {
+		assert.Equal([]int{0}, err.(*OverflowError).Indices)
+	}
+	err = unaryOpOverflow(squareOpType, i8)
+	if assert.Error(err) {
+		assert.Equal([]int{0, 2}, err.(*OverflowError).Indices)
+	}
+	err = unaryOpOverflow(cubeOpType, i8)
+	if assert.Error(err) {
+		assert.Equal([]int{0, 1, 2}, err.(*OverflowError).Indices)
+	}
+	assert.NoError(unaryOpOverflow(expOpType, i8))
+}
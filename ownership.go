@@ -0,0 +1,100 @@
+package gorgonia
+
+import "github.com/pkg/errors"
+
+/*
+This file holds the ownership of the values computed by the machines. The values bound to the nodes by a run are the
+machine's: they are borrowed by the user until the next run, which writes its results into the same memories where it
+can. A value is kept beyond the next run in one of two ways:
+
+	- copy on fetch: Fetch returns copies of the values, which are the caller's.
+	- retain and release: Retain returns the value of a node and keeps the machine from writing into it until it is
+	  released. The next runs write the results of the node, and of any op that would overwrite the value in place,
+	  into fresh memories instead. Release gives the value back to the machine.
+
+		v, err := m.Retain(y)
+		...
+		m.Reset()
+		m.RunAll() // v still holds the previous value of y, and y the new one
+		m.Release(v)
+
+A value may be retained many times, and is given back once it is released as many times. Only the values on the host
+may be retained.
+*/
+
+// retainSet counts the retainings of the values, by their identity.
+type retainSet map[Value]int
+
+func (s *retainSet) retain(v Value) {
+	if *s == nil {
+		*s = make(retainSet)
+	}
+	(*s)[v]++
+}
+
+func (s retainSet) release(v Value) {
+	if s[v] <= 1 {
+		delete(s, v)
+		return
+	}
+	s[v]--
+}
+
+// has returns true if the value is retained. nil is never retained.
+func (s retainSet) has(v Value) bool {
+	if v == nil || len(s) == 0 {
+		return false
+	}
+	if dv, ok := v.(*dualValue); ok {
+		v = dv.Value
+	}
+	return s[v] > 0
+}
+
+// any returns true if any of the values is retained.
+func (s retainSet) any(vs []Value) bool {
+	for _, v := range vs {
+		if s.has(v) {
+			return true
+		}
+	}
+	return false
+}
+
+func retainable(g *ExprGraph, n *Node) (Value, error) {
+	switch {
+	case n.g != g:
+		return nil, errors.Errorf("Cannot retain %v: it is not of the graph of the machine", n)
+	case n.Device() != CPU:
+		return nil, errors.Errorf("Cannot retain %v: its value is on %v. Fetch it instead", n, n.Device())
+	case n.Value() == nil:
+		return nil, errors.Errorf("Cannot retain %v: it holds no value", n)
+	}
+	return n.Value(), nil
+}
+
+// Retain returns the value of the node, which the machine does not write into until it is released.
+func (m *tapeMachine) Retain(n *Node) (Value, error) {
+	v, err := retainable(m.p.g, n)
+	if err != nil {
+		return nil, err
+	}
+	m.retained.retain(v)
+	return v, nil
+}
+
+// Release gives a retained value back to the machine.
+func (m *tapeMachine) Release(v Value) { m.retained.release(v) }
+
+// Retain returns the value of the node, which the machine does not write into until it is released.
+func (m *lispMachine) Retain(n *Node) (Value, error) {
+	v, err := retainable(m.g, n)
+	if err != nil {
+		return nil, err
+	}
+	m.retained.retain(v)
+	return v, nil
+}
+
+// Release gives a retained value back to the machine.
+func (m *lispMachine) Release(v Value) { m.retained.release(v) }
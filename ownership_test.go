@@ -0,0 +1,99 @@
+package gorgonia
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorgonia.org/tensor"
+)
+
+func TestRetain(t *testing.T) {
+	assert := assert.New(t)
+	g := NewGraph()
+	x := NewMatrix(g, Float64, WithShape(2, 2), WithName("x"))
+	w := NewMatrix(g, Float64, WithShape(2, 2), WithName("w"), WithInit(Ones()))
+	y := Must(Square(Must(Mul(x, w))))
+
+	xVal := func(vals ...float64) Value {
+		return tensor.New(tensor.WithShape(2, 2), tensor.WithBacking(vals))
+	}
+	machines := map[string]interface {
+		VM
+		Retain(*Node) (Value, error)
+		Release(Value)
+	}{
+		"tape": NewTapeMachine(g),
+		"lisp": NewLispMachine(g, ExecuteFwdOnly()),
+	}
+	for name, m := range machines {
+		if _, err := Run(m, map[*Node]Value{x: xVal(1, 2, 3, 4)}); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		held, err := m.Retain(y)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		assert.Equal([]float64{9, 9, 49, 49}, held.Data(), name)
+
+		if _, err = Run(m, map[*Node]Value{x: xVal(0, 0, 0, 1)}); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		assert.Equal([]float64{9, 9, 49, 49}, held.Data(), name)
+		assert.Equal([]float64{0, 0, 1, 1}, y.Value().Data(), name)
+
+		// once released, the machine may write into it again
+		m.Release(held)
+		_, err = m.Retain(NewScalar(NewGraph(), Float64))
+		assert.Error(err, name)
+		m.Close()
+	}
+}
+
+func TestRetain_reuse(t *testing.T) {
+	assert := assert.New(t)
+	g := NewGraph()
+	x := NewVector(g, Float64, WithShape(2), WithName("x"))
+	buf := tensor.New(tensor.WithShape(2), tensor.Of(Float64))
+	y := Must(Square(x))
+	WithReuse(buf)(y)
+
+	m := NewLispMachine(g, ExecuteFwdOnly())
+	defer m.Close()
+	if _, err := Run(m, map[*Node]Value{x: tensor.New(tensor.WithBacking([]float64{1, 2}))}); err != nil {
+		t.Fatal(err)
+	}
+	held, err := m.Retain(y)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]float64{1, 4}, held.Data())
+
+	// the buffer given to WithReuse is retained: the next run does not write into it
+	if _, err = Run(m, map[*Node]Value{x: tensor.New(tensor.WithBacking([]float64{3, 4}))}); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]float64{1, 4}, held.Data())
+	assert.Equal([]float64{1, 4}, buf.Data())
+	assert.Equal([]float64{9, 16}, y.Value().Data())
+}
+
+func TestRetainSet(t *testing.T) {
+	assert := assert.New(t)
+	var s retainSet
+	a, b := newF64(1), newF64(2)
+	assert.False(s.has(a))
+	s.retain(a)
+	s.retain(a)
+	s.retain(b)
+	s.release(a)
+	assert.True(s.has(a))
+	assert.True(s.any([]Value{nil, b}))
+	s.release(a)
+	s.release(b)
+	assert.False(s.has(a))
+	assert.False(s.any([]Value{a, b}))
+	assert.False(s.has(nil))
+
+	s.retain(a)
+	assert.True(s.has(dvUnit(a)), "the values of the dual values are retained")
+}
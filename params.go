@@ -0,0 +1,146 @@
+package gorgonia
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+	"gorgonia.org/tensor"
+)
+
+// ParamInfo describes a learnable. See Params.
+type ParamInfo struct {
+	Node   *Node
+	Layer  string // the scope of the name of the node (see Scope)
+	Shape  tensor.Shape
+	Dtype  tensor.Dtype
+	Device Device
+	Size   int   // the number of elements
+	Bytes  int64 // the memory of the value
+}
+
+// LayerParams is the total of the learnables of a layer.
+type LayerParams struct {
+	Name    string
+	Tensors int
+	Size    int
+	Bytes   int64
+}
+
+// ParamReport holds the learnables of a graph, in the order they were added to the graph.
+type ParamReport []ParamInfo
+
+// Params reports the learnables of the graph, or the given nodes if there are any.
+func (g *ExprGraph) Params(nodes ...*Node) ParamReport {
+	if len(nodes) == 0 {
+		nodes = g.Learnables()
+	}
+	retVal := make(ParamReport, 0, len(nodes))
+	for _, n := range nodes {
+		retVal = append(retVal, ParamInfo{
+			Node:   n,
+			Layer:  nameScope(n.name),
+			Shape:  n.Shape().Clone(),
+			Dtype:  n.Dtype(),
+			Device: n.Device(),
+			Size:   n.Shape().TotalSize(),
+			Bytes:  valueBytes(n),
+		})
+	}
+	return retVal
+}
+
+// LearnablesIn returns the learnables whose names are within the scope, including those within nested scopes, as in
+// ByScope.
+func (g *ExprGraph) LearnablesIn(scope string) Nodes {
+	in := g.ByScope(scope).mapSet()
+	var retVal Nodes
+	for _, n := range g.Learnables() {
+		if in.Contains(n) {
+			retVal = append(retVal, n)
+		}
+	}
+	return retVal
+}
+
+// LearnablesMatching returns the learnables whose names match the pattern, whose syntax is that of path.Match: as the
+// levels of the scopes are separated by slashes, "encoder/*/w" matches the w of every layer of the encoder.
+func (g *ExprGraph) LearnablesMatching(pattern string) (retVal Nodes, err error) {
+	if _, err = path.Match(pattern, ""); err != nil {
+		return nil, errors.Wrapf(err, "Bad pattern %q", pattern)
+	}
+	for _, n := range g.Learnables() {
+		if ok, _ := path.Match(pattern, n.name); ok {
+			retVal = append(retVal, n)
+		}
+	}
+	return retVal, nil
+}
+
+// Total returns the number of elements of the learnables.
+func (r ParamReport) Total() (retVal int) {
+	for _, p := range r {
+		retVal += p.Size
+	}
+	return
+}
+
+// Bytes returns the memory of the values of the learnables.
+func (r ParamReport) Bytes() (retVal int64) {
+	for _, p := range r {
+		retVal += p.Bytes
+	}
+	return
+}
+
+// ByLayer returns the totals of the layers, in the order of their first learnables. The learnables in no scope are in
+// the layer "".
+func (r ParamReport) ByLayer() []LayerParams {
+	var retVal []LayerParams
+	index := make(map[string]int)
+	for _, p := range r {
+		i, ok := index[p.Layer]
+		if !ok {
+			i = len(retVal)
+			index[p.Layer] = i
+			retVal = append(retVal, LayerParams{Name: p.Layer})
+		}
+		retVal[i].Tensors++
+		retVal[i].Size += p.Size
+		retVal[i].Bytes += p.Bytes
+	}
+	return retVal
+}
+
+// ByDtype returns the number of elements of the learnables of every dtype.
+func (r ParamReport) ByDtype() map[tensor.Dtype]int {
+	retVal := make(map[tensor.Dtype]int)
+	for _, p := range r {
+		retVal[p.Dtype] += p.Size
+	}
+	return retVal
+}
+
+// ByDevice returns the number of elements of the learnables on every device.
+func (r ParamReport) ByDevice() map[Device]int {
+	retVal := make(map[Device]int)
+	for _, p := range r {
+		retVal[p.Device] += p.Size
+	}
+	return retVal
+}
+
+// String returns the report as a table of the learnables, followed by the totals.
+func (r ParamReport) String() string {
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "Name\tShape\tDtype\tDevice\tParams\t")
+	for _, p := range r {
+		fmt.Fprintf(w, "%s\t%v\t%v\t%v\t%d\t\n", p.Node.name, p.Shape, p.Dtype, p.Device, p.Size)
+	}
+	w.Flush()
+	fmt.Fprintf(&buf, "Params: %d | Bytes: %d\n", r.Total(), r.Bytes())
+	return buf.String()
+}
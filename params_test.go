@@ -0,0 +1,57 @@
+package gorgonia
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorgonia.org/tensor"
+)
+
+func TestParams(t *testing.T) {
+	assert := assert.New(t)
+	g := NewGraph()
+	leave := g.Scope("encoder")
+	l1 := g.Scope("layer1")
+	w1 := NewMatrix(g, Float64, WithShape(3, 4), WithName("w"))
+	b1 := NewVector(g, Float64, WithShape(4), WithName("b"))
+	l1()
+	l2 := g.Scope("layer2")
+	w2 := NewMatrix(g, Float32, WithShape(4, 2), WithName("w"))
+	l2()
+	leave()
+	head := NewMatrix(g, Float64, WithShape(2, 5), WithName("head"))
+	NewVector(g, Int, WithShape(3), WithName("ids"))
+	NewConstant(tensor.New(tensor.WithShape(2), tensor.WithBacking([]float64{1, 2})), In(g))
+
+	assert.Equal(Nodes{w1, b1, w2, head}, g.Learnables())
+	assert.Equal(Nodes{w1, b1, w2}, g.LearnablesIn("encoder"))
+	assert.Equal(Nodes{w2}, g.LearnablesIn("encoder/layer2"))
+	matching, err := g.LearnablesMatching("encoder/*/w")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(Nodes{w1, w2}, matching)
+	_, err = g.LearnablesMatching("[")
+	assert.Error(err)
+
+	r := g.Params()
+	assert.Len(r, 4)
+	assert.Equal("encoder/layer1", r[0].Layer)
+	assert.Equal(tensor.Shape{3, 4}, r[0].Shape)
+	assert.Equal(12+4+8+10, r.Total())
+	assert.Equal(int64((12+4+10)*8+8*4), r.Bytes())
+	assert.Equal([]LayerParams{
+		{Name: "encoder/layer1", Tensors: 2, Size: 16, Bytes: 128},
+		{Name: "encoder/layer2", Tensors: 1, Size: 8, Bytes: 32},
+		{Name: "", Tensors: 1, Size: 10, Bytes: 80},
+	}, r.ByLayer())
+	assert.Equal(map[tensor.Dtype]int{Float64: 26, Float32: 8}, r.ByDtype())
+	assert.Equal(map[Device]int{CPU: 34}, r.ByDevice())
+	assert.Contains(r.String(), "encoder/layer2/w")
+	assert.Contains(r.String(), "Params: 34")
+
+	// the frozen layers are not learnables
+	Freeze(w1, b1)
+	assert.Equal(10+8, g.Params().Total())
+	assert.Equal(8, g.Params(w2).Total())
+}
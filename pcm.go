@@ -0,0 +1,152 @@
+package gorgonia
+
+import (
+	"math"
+
+	"github.com/pkg/errors"
+	"gorgonia.org/tensor"
+)
+
+// PCMLayout is the order of the axes of a tensor of audio samples.
+type PCMLayout byte
+
+const (
+	// Interleaved is the (frames, channels) order, in which the samples of a frame are next to each other, as in the PCM
+	// of WAV files. It is the default.
+	Interleaved PCMLayout = iota
+	// Planar is the (channels, frames) order, in which every channel is a row.
+	Planar
+)
+
+// PCMOpt is an option of PCMToTensor and TensorToPCM.
+type PCMOpt func(*pcmConv)
+
+type pcmConv struct {
+	layout PCMLayout
+	dt     tensor.Dtype
+}
+
+// WithPCMLayout sets the order of the axes of the tensor. By default it is Interleaved.
+func WithPCMLayout(l PCMLayout) PCMOpt { return func(c *pcmConv) { c.layout = l } }
+
+// WithPCMDtype sets the Dtype of the tensor: Float32 or Float64, whose values are in [-1, 1), or Int16 for the samples
+// as they are. By default it is Float32.
+func WithPCMDtype(dt tensor.Dtype) PCMOpt { return func(c *pcmConv) { c.dt = dt } }
+
+func newPCMConv(opts []PCMOpt) (c pcmConv, err error) {
+	c = pcmConv{layout: Interleaved, dt: tensor.Float32}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	switch {
+	case c.layout != Interleaved && c.layout != Planar:
+		return c, errors.Errorf("Unknown PCM layout %d", c.layout)
+	case c.dt != tensor.Float32 && c.dt != tensor.Float64 && c.dt != tensor.Int16:
+		return c, errors.Errorf(nyiTypeFail, "PCM conversion", c.dt)
+	}
+	return c, nil
+}
+
+// PCMToTensor converts interleaved 16-bit PCM samples of the given number of channels into a tensor of shape
+// (frames, channels), or (channels, frames) with Planar. The floats are the samples divided by 32768.
+func PCMToTensor(samples []int16, channels int, opts ...PCMOpt) (*tensor.Dense, error) {
+	c, err := newPCMConv(opts)
+	if err != nil {
+		return nil, err
+	}
+	if channels <= 0 || len(samples)%channels != 0 {
+		return nil, errors.Errorf("Expected a whole number of frames of %d channels. Got %d samples instead", channels, len(samples))
+	}
+	frames := len(samples) / channels
+	shape := tensor.Shape{frames, channels}
+	index := func(i int) int { return i }
+	if c.layout == Planar {
+		shape = tensor.Shape{channels, frames}
+		index = func(i int) int { return i%channels*frames + i/channels }
+	}
+
+	var backing interface{}
+	switch c.dt {
+	case tensor.Float32:
+		data := make([]float32, len(samples))
+		for i, s := range samples {
+			data[index(i)] = float32(s) / 32768
+		}
+		backing = data
+	case tensor.Float64:
+		data := make([]float64, len(samples))
+		for i, s := range samples {
+			data[index(i)] = float64(s) / 32768
+		}
+		backing = data
+	default:
+		data := make([]int16, len(samples))
+		for i, s := range samples {
+			data[index(i)] = s
+		}
+		backing = data
+	}
+	return tensor.New(tensor.WithShape(shape...), tensor.WithBacking(backing)), nil
+}
+
+// TensorToPCM converts a tensor of shape (frames, channels), or (channels, frames) with Planar, into interleaved 16-bit
+// PCM samples, and returns the number of channels. The floats are multiplied by 32768, rounded, and saturated. A vector
+// is one channel.
+func TensorToPCM(t tensor.Tensor, opts ...PCMOpt) (samples []int16, channels int, err error) {
+	var c pcmConv
+	if c, err = newPCMConv(opts); err != nil {
+		return nil, 0, err
+	}
+	var frames int
+	switch t.Dims() {
+	case 1:
+		frames, channels = t.Shape()[0], 1
+	case 2:
+		frames, channels = t.Shape()[0], t.Shape()[1]
+		if c.layout == Planar {
+			frames, channels = channels, frames
+		}
+	default:
+		return nil, 0, errors.Errorf("Expected a tensor of 1 or 2 dimensions. Got a tensor of shape %v instead", t.Shape())
+	}
+
+	s, err := valueSlice(t)
+	if err != nil {
+		return nil, 0, err
+	}
+	var at func(i int) int16
+	switch data := s.Interface().(type) {
+	case []float32:
+		at = func(i int) int16 { return floatToPCM(float64(data[i])) }
+	case []float64:
+		at = func(i int) int16 { return floatToPCM(data[i]) }
+	case []int16:
+		at = func(i int) int16 { return data[i] }
+	default:
+		return nil, 0, errors.Errorf(nyiTypeFail, "TensorToPCM", t.Dtype())
+	}
+
+	samples = make([]int16, frames*channels)
+	for i := range samples {
+		src := i
+		if c.layout == Planar {
+			src = i%channels*frames + i/channels
+		}
+		samples[i] = at(src)
+	}
+	return samples, channels, nil
+}
+
+// floatToPCM converts a sample in [-1, 1) to 16 bits.
+func floatToPCM(v float64) int16 {
+	v = math.Round(v * 32768)
+	switch {
+	case math.IsNaN(v):
+		return 0
+	case v >= math.MaxInt16:
+		return math.MaxInt16
+	case v <= math.MinInt16:
+		return math.MinInt16
+	}
+	return int16(v)
+}
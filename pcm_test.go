@@ -0,0 +1,56 @@
+package gorgonia
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorgonia.org/tensor"
+)
+
+func TestPCMToTensor(t *testing.T) {
+	assert := assert.New(t)
+	samples := []int16{0, 16384, -32768, math.MaxInt16, 1, -1}
+
+	inter, err := PCMToTensor(samples, 2)
+	if assert.NoError(err) {
+		assert.Equal(tensor.Shape{3, 2}, inter.Shape())
+		assert.Equal([]float32{0, 0.5, -1, float32(math.MaxInt16) / 32768, 1.0 / 32768, -1.0 / 32768}, inter.Data())
+	}
+	planar, err := PCMToTensor(samples, 2, WithPCMLayout(Planar), WithPCMDtype(tensor.Int16))
+	if assert.NoError(err) {
+		assert.Equal(tensor.Shape{2, 3}, planar.Shape())
+		assert.Equal([]int16{0, -32768, 1, 16384, math.MaxInt16, -1}, planar.Data())
+	}
+
+	for _, l := range []PCMLayout{Interleaved, Planar} {
+		for _, dt := range []tensor.Dtype{tensor.Float32, tensor.Float64, tensor.Int16} {
+			tt, err := PCMToTensor(samples, 3, WithPCMLayout(l), WithPCMDtype(dt))
+			if err != nil {
+				t.Fatal(err)
+			}
+			back, ch, err := TensorToPCM(tt, WithPCMLayout(l))
+			if assert.NoError(err) {
+				assert.Equal(3, ch)
+				assert.Equal(samples, back, "%v in layout %d", dt, l)
+			}
+		}
+	}
+
+	_, err = PCMToTensor(samples, 4)
+	assert.Error(err, "not a whole number of frames")
+	_, err = PCMToTensor(samples, 2, WithPCMDtype(tensor.Int))
+	assert.Error(err)
+}
+
+func TestTensorToPCM(t *testing.T) {
+	assert := assert.New(t)
+	mono := tensor.New(tensor.WithShape(4), tensor.WithBacking([]float64{2, -2, 0.25, math.NaN()}))
+	samples, ch, err := TensorToPCM(mono)
+	if assert.NoError(err) {
+		assert.Equal(1, ch)
+		assert.Equal([]int16{math.MaxInt16, math.MinInt16, 8192, 0}, samples, "the samples are saturated")
+	}
+	_, _, err = TensorToPCM(tensor.New(tensor.WithShape(1, 1, 1), tensor.WithBacking([]float64{0})))
+	assert.Error(err)
+}
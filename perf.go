@@ -8,10 +8,10 @@ import (
 )
 
 var nodePool = &sync.Pool{
-	New: func() interface{} { return new(Node) },
+	New: func() interface{} { runtimeMetrics.poolMiss(); return allocNode() },
 }
 
-func borrowNode() *Node { return nodePool.Get().(*Node) }
+func borrowNode() *Node { runtimeMetrics.poolGet(); return nodePool.Get().(*Node) }
 
 func returnNode(n *Node) {
 	// if the node is being returned to the pool then it should be removed from the graph that it is linked too as well
@@ -32,14 +32,22 @@ func returnNode(n *Node) {
 	n.groups = nil
 	n.g = nil
 	n.boundTo = nil
+	n.reuse = nil
 	n.derivOf = nil
 	n.deriv = nil
+	n.gradHooks = nil
 	n.hash = 0
 	n.hashed = false
 	n.inferredShape = false
 	n.unchanged = false
 	n.isStmt = false
 	n.ofInterest = false
+	n.placed = false
+	n.layout = NCHW
+	n.frozen = false
+	n.hyper = false
+	n.stack = nil
+	n.backwardOf = nil
 
 	nodePool.Put(n)
 }
@@ -53,10 +61,10 @@ func ReturnNode(n *Node) {
 // handles Returning of Values
 
 var dvpool = &sync.Pool{
-	New: func() interface{} { return new(dualValue) },
+	New: func() interface{} { runtimeMetrics.poolMiss(); return new(dualValue) },
 }
 
-func borrowDV() *dualValue { return dvpool.Get().(*dualValue) }
+func borrowDV() *dualValue { runtimeMetrics.poolGet(); return dvpool.Get().(*dualValue) }
 
 func returnDV(dv *dualValue) {
 	returnValue(dv.d)
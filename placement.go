@@ -0,0 +1,183 @@
+package gorgonia
+
+import (
+	"bytes"
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pkg/errors"
+	"gorgonia.org/gorgonia/internal/logging"
+)
+
+// The cost model of the automatic placement. The numbers are those of a common desktop: they only have to be right within
+// an order of magnitude, as the costs of the ops the choice matters for differ by several.
+var (
+	cpuFLOPS        = 1e10                  // floating point operations per second on the CPU
+	deviceFLOPS     = 1e12                  // floating point operations per second on a GPU
+	launchOverhead  = 10 * time.Microsecond // the cost of launching a kernel
+	deviceBandwidth = 1e10                  // bytes per second between the host and a device
+)
+
+// Placement is the plan of the devices the nodes of a graph are executed on, in the order they are executed.
+type Placement []NodePlacement
+
+// NodePlacement is the device a node is executed on, with the estimated costs it was chosen for.
+type NodePlacement struct {
+	Node     *Node
+	Device   Device
+	Explicit bool // the node was placed with WithDevice
+
+	FLOPs      float64       // the estimated number of floating point operations of the op
+	CPUCost    time.Duration // the estimated time of executing the node on the CPU, including the transfer of its inputs
+	DeviceCost time.Duration // the same on the device, or 0 if the node can only be executed on the CPU
+}
+
+// PlanPlacement returns the devices that the nodes of the graph would be executed on when it is compiled.
+//
+// The nodes placed with WithDevice stay where they are placed. Every other node is executed on the device where it is
+// estimated to be the fastest: its op has to support the device, and the cost of transferring its inputs and of
+// launching a kernel has to be worth it. Typically, the large matrix multiplications run on a GPU and the scalar ops on
+// the CPU. Without the cuda build tag, every node is executed on the CPU.
+func PlanPlacement(g *ExprGraph) (Placement, error) {
+	sorted, err := Sort(g)
+	if err != nil {
+		return nil, errors.Wrap(err, sortFail)
+	}
+	reverseNodes(sorted)
+	if err = checkPlacement(sorted); err != nil {
+		return nil, err
+	}
+	return planPlacement(sorted), nil
+}
+
+// planPlacement chooses the devices of the sorted nodes, which are sorted from the inputs to the outputs.
+func planPlacement(sorted Nodes) Placement {
+	devices := make(map[*Node]Device, len(sorted))
+	deviceOf := func(n *Node) Device {
+		if dev, ok := devices[n]; ok {
+			return dev
+		}
+		return n.dataOn
+	}
+
+	plan := make(Placement, 0, len(sorted))
+	for _, n := range sorted {
+		p := NodePlacement{Node: n, Device: CPU, Explicit: n.placed, FLOPs: estimateFLOPs(n)}
+
+		// only the first CUDA device is used for now
+		candidate := CUDADevice(0)
+		canUseDevice := n.op != nil && !n.isArg() && deviceAvailable(candidate) && candidate.supports(n.op)
+
+		p.CPUCost = computeCost(p.FLOPs, cpuFLOPS)
+		if canUseDevice {
+			p.DeviceCost = launchOverhead + computeCost(p.FLOPs, deviceFLOPS)
+		}
+		for _, child := range n.children {
+			if deviceOf(child) == CPU {
+				p.DeviceCost += transferCost(child)
+			} else {
+				p.CPUCost += transferCost(child)
+			}
+		}
+		if !canUseDevice {
+			p.DeviceCost = 0
+		}
+
+		switch {
+		case n.placed:
+			p.Device = n.dataOn
+		case canUseDevice && p.DeviceCost < p.CPUCost:
+			p.Device = candidate
+		}
+		devices[n] = p.Device
+		plan = append(plan, p)
+	}
+	return plan
+}
+
+// placeDevices sets the devices of the sorted nodes that are not placed with WithDevice.
+func (df *dataflow) placeDevices(sorted Nodes) {
+	plan := planPlacement(sorted)
+	var onDevice int
+	for _, p := range plan {
+		p.Node.dataOn = p.Device
+		if p.Device != CPU {
+			onDevice++
+		}
+	}
+	logging.Log(logging.Compile, logging.Debug, "placed the nodes", "nodes", len(plan), "onDevice", onDevice)
+}
+
+// Devices returns the number of nodes executed on every device.
+func (p Placement) Devices() map[Device]int {
+	retVal := make(map[Device]int)
+	for _, np := range p {
+		retVal[np.Device]++
+	}
+	return retVal
+}
+
+// String returns the plan as a table.
+func (p Placement) String() string {
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "Node\tDevice\tFLOPs\tCPU\tDevice Cost\t")
+	for _, np := range p {
+		dev := np.Device.String()
+		if np.Explicit {
+			dev += " (explicit)"
+		}
+		deviceCost := "-"
+		if np.DeviceCost > 0 {
+			deviceCost = np.DeviceCost.String()
+		}
+		fmt.Fprintf(w, "%v\t%v\t%g\t%v\t%v\t\n", np.Node, dev, np.FLOPs, np.CPUCost, deviceCost)
+	}
+	w.Flush()
+	return buf.String()
+}
+
+// estimateFLOPs estimates the number of floating point operations to execute the op of a node. The linear algebra ops
+// are counted exactly, and every other op is assumed to do one operation per element of its largest operand.
+func estimateFLOPs(n *Node) float64 {
+	if n.op == nil {
+		return 0
+	}
+	if op, ok := n.op.(linAlgBinOp); ok && len(n.children) == 2 {
+		a := n.children[0].Shape()
+		out := float64(logicalSize(n.Shape()))
+		switch op.āBinaryOperator {
+		case matMulOperator, batchedMatMulOperator:
+			// the inner dimension is the last one of a, or the one before if a is transposed
+			if len(a) >= 2 {
+				k := a[len(a)-1]
+				if op.transA {
+					k = a[len(a)-2]
+				}
+				return 2 * out * float64(k)
+			}
+		case matVecMulOperator, vecDotOperator:
+			return 2 * float64(logicalSize(a))
+		case outerProdOperator:
+			return out
+		}
+	}
+
+	size := logicalSize(n.Shape())
+	for _, child := range n.children {
+		if s := logicalSize(child.Shape()); s > size {
+			size = s
+		}
+	}
+	return float64(size)
+}
+
+func computeCost(flops, flopsPerSecond float64) time.Duration {
+	return time.Duration(flops / flopsPerSecond * float64(time.Second))
+}
+
+// transferCost is the estimated time of transferring the value of a node between the host and a device.
+func transferCost(n *Node) time.Duration {
+	return time.Duration(float64(valueBytes(n)) / deviceBandwidth * float64(time.Second))
+}
@@ -0,0 +1,85 @@
+package gorgonia
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorgonia.org/tensor"
+)
+
+func TestEstimateFLOPs(t *testing.T) {
+	assert := assert.New(t)
+	g := NewGraph()
+	a := NewMatrix(g, Float64, WithShape(4, 3), WithName("a"))
+	b := NewMatrix(g, Float64, WithShape(3, 5), WithName("b"))
+	v := NewVector(g, Float64, WithShape(3), WithName("v"))
+	c := NewTensor(g, Float64, 3, WithShape(2, 4, 3), WithName("c"))
+	d := NewTensor(g, Float64, 3, WithShape(2, 3, 5), WithName("d"))
+
+	assert.Equal(2.0*4*5*3, estimateFLOPs(Must(Mul(a, b))))
+	assert.Equal(2.0*3*4*5, estimateFLOPs(Must(Mul(Must(Transpose(b)), Must(Transpose(a))))))
+	assert.Equal(2.0*4*3, estimateFLOPs(Must(Mul(a, v))))
+	assert.Equal(2.0*3, estimateFLOPs(Must(Mul(v, v))))
+	assert.Equal(2.0*2*4*5*3, estimateFLOPs(Must(BatchedMatMul(c, d))))
+	assert.Equal(12.0, estimateFLOPs(Must(Sum(a))), "a reduction does one operation per element of its input")
+	assert.Equal(12.0, estimateFLOPs(Must(Tanh(a))))
+	assert.Equal(0.0, estimateFLOPs(a))
+}
+
+func TestPlanPlacement(t *testing.T) {
+	assert := assert.New(t)
+	g := NewGraph()
+	x := NewMatrix(g, Float64, WithShape(512, 512), WithName("x"))
+	y := NewMatrix(g, Float64, WithShape(512, 512), WithName("y"))
+	xy := Must(Mul(x, y))
+	s := Must(Sum(xy))
+	WithDevice(CPU)(s)
+
+	plan, err := PlanPlacement(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(plan, 4)
+	assert.Equal(s, plan[len(plan)-1].Node, "the plan is in the order of execution")
+	for _, p := range plan {
+		// only the large matrix multiplication is worth executing on a GPU
+		assert.Equal(p.Node == xy && deviceAvailable(CUDADevice(0)), p.Device != CPU, "%v is on %v", p.Node, p.Device)
+		assert.Equal(p.Node == s, p.Explicit)
+	}
+	assert.Equal(2*512*512*512.0, plan[2].FLOPs)
+	assert.True(plan[2].CPUCost > plan[3].CPUCost)
+	assert.Equal(len(plan), plan.Devices()[CPU]+plan.Devices()[CUDADevice(0)])
+
+	table := plan.String()
+	assert.Equal(len(plan)+1, strings.Count(table, "\n"))
+	assert.Contains(table, "CPU (explicit)")
+
+	WithDevice(MetalDevice)(s)
+	_, err = PlanPlacement(g)
+	assert.Error(err)
+}
+
+func TestPlanPlacement_run(t *testing.T) {
+	g := NewGraph()
+	x := NewScalar(g, Float64, WithName("x"), WithValue(2.0))
+	y := NewVector(g, Float64, WithShape(3), WithName("y"), WithValue(tensor.New(tensor.WithBacking([]float64{1, 2, 3}))))
+	z := Must(Mul(x, y))
+
+	plan, err := PlanPlacement(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := NewTapeMachine(g)
+	defer m.Close()
+	if err = m.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range plan {
+		if p.Node.Device() != p.Device {
+			t.Errorf("%v was planned on %v but executed on %v", p.Node, p.Device, p.Node.Device())
+		}
+	}
+	assert.Equal(t, CPU, z.Device(), "small ops are executed on the CPU")
+	assert.Equal(t, []float64{2, 4, 6}, z.Value().Data())
+}
@@ -0,0 +1,355 @@
+package gorgonia
+
+import (
+	"bytes"
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+	"gorgonia.org/tensor"
+)
+
+/*
+This file holds the programs: the compiled graphs that are independent of the graphs they were compiled from. A
+*Program holds its instructions, the values of the inputs at the time of the compilation (its constants) and a symbol
+table of the values that it reads and writes, and nothing of the graph, so the graph may be changed or discarded once
+it is compiled:
+
+		prog, err := gorgonia.NewProgram(g)
+		...
+		m := gorgonia.NewProgramMachine(prog)
+		if err = m.Set("x", xVal); err != nil { ... }
+		if err = m.RunAll(); err != nil { ... }
+		y, err := m.Get("y")
+
+A program is never changed once it is compiled, so one program may be run by many machines at once, each with its own
+registers. The programs only run on the CPU, and do not bind the values of the nodes (see Let and Read) nor accumulate
+the gradients into the dual values of their inputs: the gradients are read as the values of the gradient nodes.
+
+A program may be serialized with MarshalProgram, and run by another process once it is deserialized with
+UnmarshalProgram, so long as its ops are of those that have an encoding (see program_encoding.go).
+*/
+
+// Symbol is a value read or written by a Program.
+type Symbol struct {
+	Name  string
+	ID    int64 // the ID of the node in the graph the program was compiled from
+	Shape tensor.Shape
+	Dtype tensor.Dtype
+
+	reg int // the CPU register of the value
+}
+
+// Program is a compiled graph that does not refer to the graph. See NewProgram.
+type Program struct {
+	name      string
+	steps     []progStep
+	registers int
+
+	inputs    []Symbol
+	outputs   []Symbol
+	constants map[string]Value // the values of the inputs at the compilation, by name
+	byName    map[string]int   // the indices of the symbols by name: the inputs, then the outputs shifted by len(inputs)
+}
+
+// progStep is an instruction of a program: the loading of an input, the allocation of a register, or the execution of
+// an op.
+type progStep struct {
+	load   int    // the index of the input loaded, or -1
+	alloc  *alloc // the allocation, or nil
+	op     Op
+	id     int64
+	reads  []int
+	write  int
+	unsafe bool
+}
+
+// NewProgram compiles the graph into a Program. The inputs of the graph must have unique names. The outputs of the
+// program are the nodes whose values are still held in the registers once the program is run.
+func NewProgram(g *ExprGraph) (*Program, error) {
+	prog, locMap, err := Compile(g)
+	if err != nil {
+		return nil, err
+	}
+	if prog.gpulocs > 0 {
+		return nil, errors.Errorf("Cannot make a program of a graph placed on GPUs")
+	}
+
+	p := &Program{
+		name:      g.name,
+		registers: prog.cpulocs,
+		constants: make(map[string]Value),
+		byName:    make(map[string]int),
+	}
+	symbol := func(n *Node, reg int) Symbol {
+		dt, _ := dtypeOf(n.t)
+		return Symbol{Name: n.name, ID: n.id, Shape: n.shape.Clone(), Dtype: dt, reg: reg}
+	}
+
+	// the last instruction that writes every register
+	last := make(map[int]int)
+	for i, instr := range prog.instructions {
+		switch instr := instr.(type) {
+		case loadArg, *execOp:
+			if w := instr.writes(); w.device == CPU && w.id >= 0 {
+				last[w.id] = i
+			}
+		}
+	}
+
+	nodes := make(map[int64]*Node, len(locMap))
+	for n := range locMap {
+		nodes[n.id] = n
+	}
+	for i, instr := range prog.instructions {
+		switch instr := instr.(type) {
+		case loadArg:
+			n := nodes[instr.index]
+			if n == nil {
+				return nil, errors.Errorf("Cannot find the input %v of the program", instr.name)
+			}
+			if _, ok := p.byName[n.name]; ok {
+				return nil, errors.Errorf("Cannot make a program of a graph of two inputs named %q", n.name)
+			}
+			p.byName[n.name] = len(p.inputs)
+			p.steps = append(p.steps, progStep{load: len(p.inputs), id: n.id, write: instr.writeTo.id})
+			p.inputs = append(p.inputs, symbol(n, instr.writeTo.id))
+			if n.boundTo != nil {
+				v := n.boundTo
+				if dv, ok := v.(*dualValue); ok {
+					v = dv.Value
+				}
+				if p.constants[n.name], err = CloneValue(v); err != nil {
+					return nil, errors.Wrap(err, cloneFail)
+				}
+			}
+		case alloc:
+			if instr.writeTo.device != CPU {
+				return nil, errors.Errorf("Cannot make a program of a graph placed on GPUs")
+			}
+			instr.s = instr.s.Clone()
+			p.steps = append(p.steps, progStep{load: -1, alloc: &instr, id: instr.id, write: instr.writeTo.id})
+		case *execOp:
+			step := progStep{load: -1, op: instr.op, id: instr.id, write: instr.writeTo.id, unsafe: instr.useUnsafe}
+			for _, r := range instr.readFrom {
+				step.reads = append(step.reads, r.id)
+			}
+			p.steps = append(p.steps, step)
+			if n := nodes[instr.id]; n != nil && last[instr.writeTo.id] == i {
+				p.outputs = append(p.outputs, symbol(n, instr.writeTo.id))
+			}
+		}
+	}
+	for i, s := range p.outputs {
+		if _, ok := p.byName[s.Name]; !ok && s.Name != "" {
+			p.byName[s.Name] = len(p.inputs) + i
+		}
+	}
+	return p, nil
+}
+
+// Inputs returns the symbols of the inputs of the program, in the order they are loaded.
+func (p *Program) Inputs() []Symbol { return append([]Symbol(nil), p.inputs...) }
+
+// Outputs returns the symbols of the values held once the program is run, in the order they are computed.
+func (p *Program) Outputs() []Symbol { return append([]Symbol(nil), p.outputs...) }
+
+// Constant returns a clone of the value of the input at the compilation, or nil if it had none.
+func (p *Program) Constant(name string) (Value, error) {
+	v, ok := p.constants[name]
+	if !ok {
+		return nil, nil
+	}
+	return CloneValue(v)
+}
+
+// String returns the symbol table of the program, and its instructions.
+func (p *Program) String() string {
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "Symbol\tKind\tShape\tDtype\tRegister\t")
+	for _, s := range p.inputs {
+		kind := "input"
+		if _, ok := p.constants[s.Name]; ok {
+			kind = "constant"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%v\t%v\tCPU%d\t\n", s.Name, kind, s.Shape, s.Dtype, s.reg)
+	}
+	for _, s := range p.outputs {
+		fmt.Fprintf(w, "%s\toutput\t%v\t%v\tCPU%d\t\n", s.Name, s.Shape, s.Dtype, s.reg)
+	}
+	w.Flush()
+	for i, s := range p.steps {
+		switch {
+		case s.load >= 0:
+			fmt.Fprintf(&buf, "%d\tload %s\tCPU%d\n", i, p.inputs[s.load].Name, s.write)
+			continue
+		case s.alloc != nil:
+			fmt.Fprintf(&buf, "%d\talloc %v%v\tCPU%d\n", i, s.alloc.t, s.alloc.s, s.write)
+			continue
+		}
+		fmt.Fprintf(&buf, "%d\t%v\t%v\tCPU%d\n", i, s.op, s.reads, s.write)
+	}
+	return buf.String()
+}
+
+// symbol returns the symbol of the name, and whether it is an input.
+func (p *Program) symbol(name string) (Symbol, bool, error) {
+	i, ok := p.byName[name]
+	switch {
+	case !ok:
+		return Symbol{}, false, errors.Errorf("No symbol named %q in the program", name)
+	case i < len(p.inputs):
+		return p.inputs[i], true, nil
+	}
+	return p.outputs[i-len(p.inputs)], false, nil
+}
+
+// ProgramMachine runs a Program with its own registers. It is not safe for concurrent use, but many machines may run
+// the same program at once.
+type ProgramMachine struct {
+	p      *Program
+	values map[string]Value // the values of the inputs set
+	regs   []Value
+	loaded []bool // do the registers hold the values of inputs, which must not be written to
+}
+
+// NewProgramMachine returns a machine that runs the program. The inputs are the constants of the program until they
+// are set.
+func NewProgramMachine(p *Program) *ProgramMachine {
+	return &ProgramMachine{
+		p:      p,
+		values: make(map[string]Value),
+		regs:   make([]Value, p.registers),
+		loaded: make([]bool, p.registers),
+	}
+}
+
+// Program returns the program run by the machine.
+func (m *ProgramMachine) Program() *Program { return m.p }
+
+// Set sets the value of an input. The value must be of the shape and the dtype of the input.
+func (m *ProgramMachine) Set(name string, v Value) error {
+	s, input, err := m.p.symbol(name)
+	switch {
+	case err != nil:
+		return err
+	case !input:
+		return errors.Errorf("Cannot set %q: it is not an input of the program", name)
+	case v.Dtype() != s.Dtype:
+		return errors.Errorf("Cannot set %q of %v to a value of %v", name, s.Dtype, v.Dtype())
+	case !v.Shape().Eq(s.Shape):
+		return errors.Errorf("Cannot set %q of shape %v to a value of shape %v", name, s.Shape, v.Shape())
+	}
+	m.values[name] = v
+	return nil
+}
+
+// Get returns the value of a symbol once the program is run. The value is that of the register, which the next run
+// overwrites: clone it to keep it.
+func (m *ProgramMachine) Get(name string) (Value, error) {
+	s, _, err := m.p.symbol(name)
+	if err != nil {
+		return nil, err
+	}
+	if v := m.regs[s.reg]; v != nil {
+		return v, nil
+	}
+	return nil, errors.Errorf("No value for %q: the program has not been run", name)
+}
+
+// Output returns the value of the ith output of the program once it is run, as Get does. The outputs need not be
+// named.
+func (m *ProgramMachine) Output(i int) (Value, error) {
+	if i < 0 || i >= len(m.p.outputs) {
+		return nil, errors.Errorf("The program has no output %d: it has %d outputs", i, len(m.p.outputs))
+	}
+	if v := m.regs[m.p.outputs[i].reg]; v != nil {
+		return v, nil
+	}
+	return nil, errors.Errorf("No value for the output %d: the program has not been run", i)
+}
+
+// RunAll runs the program.
+func (m *ProgramMachine) RunAll() (err error) {
+	for i, s := range m.p.steps {
+		if s.load >= 0 {
+			name := m.p.inputs[s.load].Name
+			v, ok := m.values[name]
+			if !ok {
+				if v, ok = m.p.constants[name]; !ok {
+					return errors.Errorf("No value set for the input %q", name)
+				}
+				// the constants of the program are never written to
+				if v, err = CloneValue(v); err != nil {
+					return errors.Wrap(err, cloneFail)
+				}
+				m.values[name] = v
+			}
+			m.regs[s.write] = v
+			m.loaded[s.write] = true
+			continue
+		}
+		if s.alloc != nil {
+			if err = m.alloc(s); err != nil {
+				return errors.Wrapf(err, "Step %d", i)
+			}
+			continue
+		}
+
+		inputs := make([]Value, len(s.reads))
+		for j, r := range s.reads {
+			inputs[j] = m.regs[r]
+		}
+		var v Value
+		var prealloc Value
+		if !m.loaded[s.write] {
+			prealloc = m.regs[s.write]
+		}
+		pd, canPrealloc := s.op.(UsePreallocDoer)
+		ud, canUnsafe := s.op.(UnsafeDoer)
+		switch {
+		case prealloc != nil && canPrealloc:
+			if v, err = pd.UsePreallocDo(prealloc, inputs...); err != nil {
+				v, err = s.op.Do(inputs...)
+			}
+		case s.unsafe && canUnsafe:
+			v, err = ud.UnsafeDo(inputs...)
+		default:
+			v, err = s.op.Do(inputs...)
+		}
+		if err != nil {
+			return errors.Wrapf(err, "Step %d. Failed to execute %v", i, s.op)
+		}
+		m.regs[s.write] = v
+		m.loaded[s.write] = false
+	}
+	return nil
+}
+
+// alloc allocates the register of the step, unless it already holds a value of its dtype and shape.
+func (m *ProgramMachine) alloc(s progStep) error {
+	dt, err := dtypeOf(s.alloc.t)
+	if err != nil {
+		return errors.Wrapf(err, dtypeExtractionFail, s.alloc.t)
+	}
+	if v := m.regs[s.write]; v != nil && !m.loaded[s.write] && v.Dtype() == dt && v.Shape().Eq(s.alloc.s) {
+		return nil
+	}
+	v, err := makeValue(s.alloc.t, s.alloc.s)
+	if err != nil {
+		return err
+	}
+	m.regs[s.write] = v
+	m.loaded[s.write] = false
+	return nil
+}
+
+// Reset forgets the values set, and the values of the registers.
+func (m *ProgramMachine) Reset() {
+	m.values = make(map[string]Value)
+	for i := range m.regs {
+		m.regs[i] = nil
+		m.loaded[i] = false
+	}
+}
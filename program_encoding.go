@@ -0,0 +1,549 @@
+package gorgonia
+
+import (
+	"bytes"
+	"encoding/gob"
+
+	"github.com/chewxy/hm"
+	"github.com/pkg/errors"
+	"gorgonia.org/tensor"
+)
+
+// programVersion is the version of the encoding of the programs.
+const programVersion = 1
+
+// programState is what MarshalProgram writes: the fields of a Program, with its ops, types, dtypes and constants
+// encoded.
+type programState struct {
+	Version   int
+	Name      string
+	Registers int
+	Steps     []stepState
+	Inputs    []symbolState
+	Outputs   []symbolState
+	Constants map[string][]byte // in the ProtoEncoding of MarshalValue
+	ByName    map[string]int
+}
+
+type stepState struct {
+	Load   int
+	Alloc  *allocState
+	Op     *opState
+	ID     int64
+	Reads  []int
+	Write  int
+	Unsafe bool
+}
+
+type allocState struct {
+	Type  typeState
+	Shape []int
+}
+
+type symbolState struct {
+	Name  string
+	ID    int64
+	Shape []int
+	Dtype string
+	Reg   int
+}
+
+// typeState is a type of a value: a tensor of Dims dimensions, or a scalar if Dims is 0.
+type typeState struct {
+	Dims  int
+	Dtype string
+}
+
+// opState is an op, as the parameters of its constructor.
+type opState struct {
+	Kind   string
+	Ints   []int
+	Bools  []bool
+	Axes   [][]int
+	Shapes [][]int
+	Types  []typeState
+	Value  []byte // the value of a constant, in the ProtoEncoding of MarshalValue
+}
+
+// MarshalProgram serializes a program, so that it may be run without the graph it was compiled from, by another process
+// (see UnmarshalProgram). The ops of the arithmetic, of the linear algebra, of the reductions, of the shapes of the
+// tensors, of the casts, of the convolutions and of the max pools are supported, as are their gradients: the programs
+// that execute other ops cannot be serialized.
+func MarshalProgram(p *Program) ([]byte, error) {
+	st := programState{
+		Version:   programVersion,
+		Name:      p.name,
+		Registers: p.registers,
+		Steps:     make([]stepState, len(p.steps)),
+		Constants: make(map[string][]byte, len(p.constants)),
+		ByName:    p.byName,
+	}
+	var err error
+	if st.Inputs, err = encodeSymbols(p.inputs); err != nil {
+		return nil, err
+	}
+	if st.Outputs, err = encodeSymbols(p.outputs); err != nil {
+		return nil, err
+	}
+	for name, v := range p.constants {
+		if st.Constants[name], err = MarshalValue(v, ProtoEncoding); err != nil {
+			return nil, errors.Wrapf(err, "The constant %q", name)
+		}
+	}
+
+	enc := opEncoder{masks: make(map[tensor.Tensor]int)}
+	for i, s := range p.steps {
+		ss := stepState{Load: s.load, ID: s.id, Reads: s.reads, Write: s.write, Unsafe: s.unsafe}
+		switch {
+		case s.alloc != nil:
+			ss.Alloc = &allocState{Shape: s.alloc.s}
+			if ss.Alloc.Type, err = encodeType(s.alloc.t); err != nil {
+				return nil, errors.Wrapf(err, "Step %d", i)
+			}
+		case s.op != nil:
+			var o opState
+			if o, err = enc.encode(s.op); err != nil {
+				return nil, errors.Wrapf(err, "Step %d", i)
+			}
+			ss.Op = &o
+		}
+		st.Steps[i] = ss
+	}
+
+	var buf bytes.Buffer
+	if err = gob.NewEncoder(&buf).Encode(st); err != nil {
+		return nil, errors.Wrap(err, "Failed to encode the program")
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalProgram deserializes a program serialized by MarshalProgram.
+func UnmarshalProgram(p []byte) (*Program, error) {
+	var st programState
+	if err := gob.NewDecoder(bytes.NewReader(p)).Decode(&st); err != nil {
+		return nil, errors.Wrap(err, "Failed to decode the program")
+	}
+	if st.Version != programVersion {
+		return nil, errors.Errorf("Cannot decode a program of version %d", st.Version)
+	}
+	// every register is written by a step
+	if st.Registers < 0 || st.Registers > len(st.Steps) {
+		return nil, errors.Errorf("The program has %d registers and %d steps", st.Registers, len(st.Steps))
+	}
+	reg := func(r int) error {
+		if r < 0 || r >= st.Registers {
+			return errors.Errorf("The register %d is not one of the %d registers of the program", r, st.Registers)
+		}
+		return nil
+	}
+
+	prog := &Program{
+		name:      st.Name,
+		registers: st.Registers,
+		steps:     make([]progStep, len(st.Steps)),
+		constants: make(map[string]Value, len(st.Constants)),
+		byName:    st.ByName,
+	}
+	if prog.byName == nil {
+		prog.byName = make(map[string]int)
+	}
+	var err error
+	if prog.inputs, err = decodeSymbols(st.Inputs, reg); err != nil {
+		return nil, errors.Wrap(err, "An input")
+	}
+	if prog.outputs, err = decodeSymbols(st.Outputs, reg); err != nil {
+		return nil, errors.Wrap(err, "An output")
+	}
+	for name, i := range prog.byName {
+		if i < 0 || i >= len(prog.inputs)+len(prog.outputs) {
+			return nil, errors.Errorf("The symbol %q is not one of the symbols of the program", name)
+		}
+	}
+	for name, c := range st.Constants {
+		if prog.constants[name], err = UnmarshalValue(c, ProtoEncoding); err != nil {
+			return nil, errors.Wrapf(err, "The constant %q", name)
+		}
+	}
+
+	dec := opDecoder{masks: make(map[int]tensor.Tensor)}
+	for i, ss := range st.Steps {
+		s := progStep{load: ss.Load, id: ss.ID, reads: ss.Reads, write: ss.Write, unsafe: ss.Unsafe}
+		if err = reg(s.write); err != nil {
+			return nil, errors.Wrapf(err, "Step %d", i)
+		}
+		for _, r := range s.reads {
+			if err = reg(r); err != nil {
+				return nil, errors.Wrapf(err, "Step %d", i)
+			}
+		}
+		switch {
+		case ss.Load >= len(prog.inputs) || ss.Load < -1:
+			return nil, errors.Errorf("Step %d loads the input %d of %d inputs", i, ss.Load, len(prog.inputs))
+		case ss.Load >= 0:
+		case ss.Alloc != nil:
+			a := &alloc{id: ss.ID, writeTo: register{id: ss.Write, device: CPU}}
+			if a.t, err = ss.Alloc.Type.typ(); err != nil {
+				return nil, errors.Wrapf(err, "Step %d", i)
+			}
+			if a.s, err = decodeShape(ss.Alloc.Shape); err != nil {
+				return nil, errors.Wrapf(err, "Step %d", i)
+			}
+			s.alloc = a
+		case ss.Op != nil:
+			if s.op, err = dec.decode(*ss.Op); err != nil {
+				return nil, errors.Wrapf(err, "Step %d", i)
+			}
+		default:
+			return nil, errors.Errorf("Step %d neither loads an input, nor allocates a register, nor executes an op", i)
+		}
+		prog.steps[i] = s
+	}
+	return prog, nil
+}
+
+func encodeSymbols(syms []Symbol) ([]symbolState, error) {
+	retVal := make([]symbolState, len(syms))
+	for i, s := range syms {
+		if _, err := dtypeByName(s.Dtype.String()); err != nil {
+			return nil, errors.Wrapf(err, "The symbol %q", s.Name)
+		}
+		retVal[i] = symbolState{Name: s.Name, ID: s.ID, Shape: s.Shape, Dtype: s.Dtype.String(), Reg: s.reg}
+	}
+	return retVal, nil
+}
+
+func decodeSymbols(syms []symbolState, reg func(int) error) ([]Symbol, error) {
+	retVal := make([]Symbol, len(syms))
+	for i, s := range syms {
+		dt, err := dtypeByName(s.Dtype)
+		if err != nil {
+			return nil, err
+		}
+		shape, err := decodeShape(s.Shape)
+		if err != nil {
+			return nil, err
+		}
+		if err = reg(s.Reg); err != nil {
+			return nil, err
+		}
+		retVal[i] = Symbol{Name: s.Name, ID: s.ID, Shape: shape, Dtype: dt, reg: s.Reg}
+	}
+	return retVal, nil
+}
+
+// decodeShape returns the shape of the dimensions. The shapes of the scalars are empty, rather than nil.
+func decodeShape(dims []int) (tensor.Shape, error) {
+	for _, d := range dims {
+		if d < 0 {
+			return nil, errors.Errorf("Cannot decode the shape %v", dims)
+		}
+	}
+	return append(tensor.Shape{}, dims...), nil
+}
+
+func encodeType(t hm.Type) (typeState, error) {
+	switch t := t.(type) {
+	case tensor.Dtype:
+		return typeState{Dtype: t.String()}, nil
+	case TensorType:
+		if dt, ok := t.Of.(tensor.Dtype); ok && t.Dims > 0 {
+			return typeState{Dims: t.Dims, Dtype: dt.String()}, nil
+		}
+	}
+	return typeState{}, errors.Errorf("Cannot encode the type %v", t)
+}
+
+func (st typeState) typ() (hm.Type, error) {
+	dt, err := dtypeByName(st.Dtype)
+	switch {
+	case err != nil:
+		return nil, err
+	case st.Dims < 0:
+		return nil, errors.Errorf("Cannot decode a tensor of %d dimensions", st.Dims)
+	case st.Dims == 0:
+		return dt, nil
+	}
+	return makeTensorType(st.Dims, dt), nil
+}
+
+// opEncoder encodes the ops of a program. A max pool shares its mask with the op of its gradient, so the masks are
+// numbered.
+type opEncoder struct {
+	masks map[tensor.Tensor]int
+}
+
+func (e *opEncoder) encode(op Op) (st opState, err error) {
+	switch op := op.(type) {
+	case elemBinOp:
+		st = opState{Kind: "elemBinOp", Ints: []int{int(op.binOpType())}, Bools: []bool{op.retSame}, Types: make([]typeState, 2)}
+		for i, t := range []hm.Type{op.arg0, op.arg1} {
+			if st.Types[i], err = encodeType(t); err != nil {
+				return opState{}, err
+			}
+		}
+	case elemUnaryOp:
+		var bits int
+		switch op.ʘUnaryOperator.(type) {
+		case *sf32UnaryOperator:
+			bits = 32
+		case *sf64UnaryOperator:
+			bits = 64
+		default:
+			return opState{}, errors.Errorf(nyiFail, "MarshalProgram", op)
+		}
+		st = opState{Kind: "elemUnaryOp", Ints: []int{int(op.unaryOpType()), bits}, Bools: []bool{op.argTensor, op.numericResult}}
+	case linAlgBinOp:
+		st = opState{Kind: "linAlgBinOp", Ints: []int{int(op.āBinaryOperator)}, Bools: []bool{op.transA, op.transB}}
+	case sumOp:
+		st = opState{Kind: "sumOp", Ints: []int{op.d}, Axes: [][]int{op.along}, Shapes: [][]int{op.inputShape}}
+	case *maxOp:
+		st = opState{Kind: "maxOp", Ints: []int{op.d}, Axes: [][]int{op.along}}
+	case reshapeOp:
+		st = opState{Kind: "reshapeOp", Shapes: [][]int{op.from, op.to}}
+	case transposeOp:
+		st = opState{Kind: "transposeOp", Ints: []int{op.d}, Axes: [][]int{op.pattern}}
+	case *sliceOp:
+		st = opState{Kind: "sliceOp", Ints: []int{op.along, op.a, op.d}, Bools: []bool{op.Slice != nil}}
+		if op.Slice != nil {
+			st.Ints = append(st.Ints, op.Start(), op.End(), op.Step())
+		}
+	case concatOp:
+		st = opState{Kind: "concatOp", Ints: []int{op.axis, op.d, op.children}}
+	case *repeatOp:
+		st = opState{Kind: "repeatOp", Ints: []int{op.along}, Shapes: [][]int{op.inputShape}}
+	case sizeOp:
+		st = opState{Kind: "sizeOp", Ints: []int{op.axis, op.d, op.val}}
+	case im2colOp:
+		st = opState{Kind: "im2colOp", Ints: im2colInts(op)}
+	case col2imOp:
+		st = opState{Kind: "col2imOp", Ints: append([]int{op.unpaddedB, op.unpaddedC, op.unpaddedH, op.unpaddedW}, im2colInts(op.im2colOp)...)}
+	case winogradOp:
+		st = opState{Kind: "winogradOp", Ints: []int{op.m, op.padH, op.padW}}
+	case *maxPoolOp:
+		st = e.maxPool("maxPoolOp", op)
+	case *maxPoolDiffOp:
+		st = e.maxPool("maxPoolDiffOp", &op.maxPoolOp)
+	case tensordotOp:
+		st = opState{Kind: "tensordotOp", Ints: []int{op.aDims, op.bDims, op.retDims}, Axes: [][]int{op.aAxes, op.bAxes}}
+	case castOp:
+		st = opState{Kind: "castOp", Ints: []int{op.d}, Bools: []bool{op.saturate}, Types: []typeState{{Dtype: op.from.String()}, {Dtype: op.to.String()}}}
+	case stopGradOp:
+		st = opState{Kind: "stopGradOp"}
+	case constantScalar:
+		st = opState{Kind: "constantScalar"}
+		st.Value, err = MarshalValue(op.v, ProtoEncoding)
+	case constantTensor:
+		st = opState{Kind: "constantTensor"}
+		st.Value, err = MarshalValue(op.v, ProtoEncoding)
+	default:
+		return opState{}, errors.Errorf(nyiFail, "MarshalProgram", op)
+	}
+	return st, err
+}
+
+func im2colInts(op im2colOp) []int {
+	return []int{op.h, op.w, op.padH, op.padW, op.strideH, op.strideW, op.dilationH, op.dilationW}
+}
+
+func (e *opEncoder) maxPool(kind string, op *maxPoolOp) opState {
+	mask, ok := e.masks[op.mask]
+	if !ok {
+		mask = len(e.masks)
+		e.masks[op.mask] = mask
+	}
+	return opState{
+		Kind: kind,
+		Ints: []int{
+			op.unpaddedB, op.unpaddedC, op.unpaddedH, op.unpaddedW,
+			op.h, op.w, op.padNorth, op.padWest, op.padSouth, op.padEast, op.strideH, op.strideW,
+			mask,
+		},
+		Bools: []bool{op.explicitPadding},
+	}
+}
+
+// opDecoder decodes the ops of a program, sharing the masks of the max pools as they were.
+type opDecoder struct {
+	masks map[int]tensor.Tensor
+}
+
+// opArities are the numbers of Ints, Bools, Axes, Shapes and Types of the encoded ops. The Ints of a sliceOp are
+// counted without its slice.
+var opArities = map[string][5]int{
+	"elemBinOp":      {1, 1, 0, 0, 2},
+	"elemUnaryOp":    {2, 2, 0, 0, 0},
+	"linAlgBinOp":    {1, 2, 0, 0, 0},
+	"sumOp":          {1, 0, 1, 1, 0},
+	"maxOp":          {1, 0, 1, 0, 0},
+	"reshapeOp":      {0, 0, 0, 2, 0},
+	"transposeOp":    {1, 0, 1, 0, 0},
+	"sliceOp":        {3, 1, 0, 0, 0},
+	"concatOp":       {3, 0, 0, 0, 0},
+	"repeatOp":       {1, 0, 0, 1, 0},
+	"sizeOp":         {3, 0, 0, 0, 0},
+	"im2colOp":       {8, 0, 0, 0, 0},
+	"col2imOp":       {12, 0, 0, 0, 0},
+	"winogradOp":     {3, 0, 0, 0, 0},
+	"maxPoolOp":      {13, 1, 0, 0, 0},
+	"maxPoolDiffOp":  {13, 1, 0, 0, 0},
+	"tensordotOp":    {3, 0, 2, 0, 0},
+	"castOp":         {1, 1, 0, 0, 2},
+	"stopGradOp":     {0, 0, 0, 0, 0},
+	"constantScalar": {0, 0, 0, 0, 0},
+	"constantTensor": {0, 0, 0, 0, 0},
+}
+
+func (d *opDecoder) decode(st opState) (op Op, err error) {
+	ar, ok := opArities[st.Kind]
+	if !ok {
+		return nil, errors.Errorf("Cannot decode an op of the kind %q", st.Kind)
+	}
+	ints := len(st.Ints)
+	if st.Kind == "sliceOp" && len(st.Bools) == 1 && st.Bools[0] {
+		ints -= 3
+	}
+	if ints != ar[0] || len(st.Bools) != ar[1] || len(st.Axes) != ar[2] || len(st.Shapes) != ar[3] || len(st.Types) != ar[4] {
+		return nil, errors.Errorf("The encoded %v is corrupted", st.Kind)
+	}
+	shapes := make([]tensor.Shape, len(st.Shapes))
+	for i, s := range st.Shapes {
+		if shapes[i], err = decodeShape(s); err != nil {
+			return nil, err
+		}
+	}
+	types := make([]hm.Type, len(st.Types))
+	for i, t := range st.Types {
+		if types[i], err = t.typ(); err != nil {
+			return nil, err
+		}
+	}
+	n := st.Ints
+
+	switch st.Kind {
+	case "elemBinOp":
+		if n[0] < 0 || n[0] >= int(maxʘBinaryOpType) {
+			break
+		}
+		ebo := newEBOByType(ʘBinaryOperatorType(n[0]), types[0], types[1])
+		ebo.retSame = st.Bools[0]
+		return ebo, nil
+	case "elemUnaryOp":
+		if n[0] < 0 || n[0] >= int(maxʘUnaryOperator) {
+			break
+		}
+		var operator ʘUnaryOperator
+		switch n[1] {
+		case 32:
+			if f := sf32UnaryOperators[n[0]]; f != nil {
+				operator = f
+			}
+		case 64:
+			if f := sf64UnaryOperators[n[0]]; f != nil {
+				operator = f
+			}
+		}
+		if operator == nil {
+			break
+		}
+		return elemUnaryOp{ʘUnaryOperator: operator, argTensor: st.Bools[0], numericResult: st.Bools[1]}, nil
+	case "linAlgBinOp":
+		if n[0] < 0 || n[0] >= int(maxĀBinaryOperator) {
+			break
+		}
+		return linAlgBinOp{āBinaryOperator: āBinaryOperator(n[0]), transA: st.Bools[0], transB: st.Bools[1]}, nil
+	case "sumOp":
+		return sumOp{along: axes(st.Axes[0]), d: n[0], inputShape: shapes[0]}, nil
+	case "maxOp":
+		return &maxOp{along: axes(st.Axes[0]), d: n[0]}, nil
+	case "reshapeOp":
+		return reshapeOp{from: shapes[0], to: shapes[1]}, nil
+	case "transposeOp":
+		return transposeOp{pattern: st.Axes[0], d: n[0]}, nil
+	case "sliceOp":
+		op := &sliceOp{along: n[0], a: n[1], d: n[2]}
+		if st.Bools[0] {
+			op.Slice = &sli{start: n[3], end: n[4], step: n[5]}
+		}
+		return op, nil
+	case "concatOp":
+		return concatOp{axis: n[0], d: n[1], children: n[2]}, nil
+	case "repeatOp":
+		return &repeatOp{along: n[0], inputShape: shapes[0]}, nil
+	case "sizeOp":
+		return sizeOp{axis: n[0], d: n[1], val: n[2]}, nil
+	case "im2colOp":
+		return makeIm2ColOp(n[0], n[1], n[2], n[3], n[4], n[5], n[6], n[7]), nil
+	case "col2imOp":
+		return col2imOp{
+			unpaddedB: n[0], unpaddedC: n[1], unpaddedH: n[2], unpaddedW: n[3],
+			im2colOp: makeIm2ColOp(n[4], n[5], n[6], n[7], n[8], n[9], n[10], n[11]),
+		}, nil
+	case "winogradOp":
+		return winogradOp{m: n[0], padH: n[1], padW: n[2]}, nil
+	case "maxPoolOp", "maxPoolDiffOp":
+		var pool *maxPoolOp
+		if pool, err = d.maxPool(n, st.Bools[0]); err != nil {
+			return nil, err
+		}
+		if st.Kind == "maxPoolDiffOp" {
+			return &maxPoolDiffOp{*pool}, nil
+		}
+		return pool, nil
+	case "tensordotOp":
+		return tensordotOp{aAxes: st.Axes[0], bAxes: st.Axes[1], aDims: n[0], bDims: n[1], retDims: n[2]}, nil
+	case "castOp":
+		return castOp{from: types[0].(tensor.Dtype), to: types[1].(tensor.Dtype), d: n[0], saturate: st.Bools[0]}, nil
+	case "stopGradOp":
+		return stopGradOp{}, nil
+	case "constantScalar", "constantTensor":
+		var v Value
+		if v, err = UnmarshalValue(st.Value, ProtoEncoding); err != nil {
+			return nil, err
+		}
+		switch v := v.(type) {
+		case Scalar:
+			if st.Kind == "constantScalar" {
+				return constantScalar{v}, nil
+			}
+		case tensor.Tensor:
+			if st.Kind == "constantTensor" {
+				return constantTensor{v}, nil
+			}
+		}
+	}
+	return nil, errors.Errorf("The encoded %v is corrupted", st.Kind)
+}
+
+// maxPool decodes a max pool. The max pools of the same mask share it, as the max pool and the op of its gradient do.
+func (d *opDecoder) maxPool(n []int, explicitPadding bool) (*maxPoolOp, error) {
+	for _, v := range n {
+		if v < 0 {
+			return nil, errors.New("The encoded max pool is corrupted")
+		}
+	}
+	if n[10] == 0 || n[11] == 0 {
+		return nil, errors.New("The encoded max pool has a stride of 0")
+	}
+	op := &maxPoolOp{
+		unpaddedB: n[0], unpaddedC: n[1], unpaddedH: n[2], unpaddedW: n[3],
+		h: n[4], w: n[5],
+		padNorth: n[6], padWest: n[7], padSouth: n[8], padEast: n[9],
+		explicitPadding: explicitPadding,
+		strideH:         n[10], strideW: n[11],
+	}
+	if mask, ok := d.masks[n[12]]; ok {
+		op.mask = mask
+		return op, nil
+	}
+	s := op.calcShape(tensor.Shape{n[0], n[1], n[2], n[3]})
+	size := 1
+	for _, v := range s {
+		if v <= 0 || size > int(^uint(0)>>1)/v {
+			return nil, errors.Errorf("The encoded max pool has a mask of shape %v", s)
+		}
+		size *= v
+	}
+	op.mask = tensor.New(tensor.Of(tensor.Int), tensor.WithShape(s...))
+	d.masks[n[12]] = op.mask
+	return op, nil
+}
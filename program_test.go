@@ -0,0 +1,170 @@
+package gorgonia
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorgonia.org/tensor"
+)
+
+func TestProgram(t *testing.T) {
+	assert := assert.New(t)
+	g := NewGraph()
+	x := NewMatrix(g, Float64, WithShape(2, 3), WithName("x"))
+	w := NewMatrix(g, Float64, WithShape(3, 1), WithName("w"), WithValue(tensor.New(tensor.WithShape(3, 1), tensor.WithBacking([]float64{1, 2, 3}))))
+	y := Must(Mul(x, w))
+	WithName("y")(y)
+	cost := Must(Sum(Must(Square(y))))
+	WithName("cost")(cost)
+	if _, err := Grad(cost, w); err != nil {
+		t.Fatal(err)
+	}
+	WithName("dw")(w.deriv)
+
+	p, err := NewProgram(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(p.Inputs(), 2)
+	assert.Contains(p.String(), "constant")
+
+	// the graph may be changed once it is compiled
+	Let(w, tensor.New(tensor.WithShape(3, 1), tensor.WithBacking([]float64{0, 0, 0})))
+	Must(Exp(cost))
+
+	xVal := tensor.New(tensor.WithShape(2, 3), tensor.WithBacking([]float64{1, 0, 0, 0, 1, 1}))
+	m := NewProgramMachine(p)
+	assert.Error(m.Set("x", tensor.New(tensor.WithShape(3, 2), tensor.WithBacking(make([]float64, 6)))))
+	assert.Error(m.Set("cost", xVal))
+	_, err = m.Get("y")
+	assert.Error(err)
+	if err = m.Set("x", xVal); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if err = m.RunAll(); err != nil {
+			t.Fatal(err)
+		}
+		v, err := m.Get("cost")
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(1.0+25.0, v.Data())
+		dw, err := m.Get("dw")
+		if err != nil {
+			t.Fatal(err)
+		}
+		// dcost/dw = 2 xᵀy
+		assert.Equal([]float64{2, 10, 10}, dw.Data())
+	}
+
+	// the constants are those of the compilation
+	c, err := p.Constant("w")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]float64{1, 2, 3}, c.Data())
+	_, err = m.Get("nope")
+	assert.Error(err)
+
+	// the outputs are also read by their indices
+	for i, out := range p.Outputs() {
+		if out.Name == "cost" {
+			v, err := m.Output(i)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(1.0+25.0, v.Data())
+		}
+	}
+	_, err = m.Output(len(p.Outputs()))
+	assert.Error(err)
+	_, err = NewProgramMachine(p).Output(0)
+	assert.Error(err, "not run")
+}
+
+func TestMarshalProgram(t *testing.T) {
+	assert := assert.New(t)
+	g := NewGraph()
+	x := NewTensor(g, Float64, 4, WithShape(2, 1, 6, 6), WithName("x"))
+	filter := NewTensor(g, Float64, 4, WithShape(3, 1, 3, 3), WithName("filter"), WithInit(GlorotN(1)))
+	w := NewMatrix(g, Float64, WithShape(27, 4), WithName("w"), WithInit(GlorotN(1)))
+	b := NewVector(g, Float64, WithShape(4), WithName("b"), WithInit(Zeroes()))
+
+	conv := Must(Conv2d(x, filter, tensor.Shape{3, 3}, []int{1, 1}, []int{1, 1}, []int{1, 1}))
+	pooled := Must(MaxPool2D(Must(Rectify(conv)), tensor.Shape{2, 2}, []int{0, 0}, []int{2, 2}))
+	flat := Must(Reshape(pooled, tensor.Shape{2, 27}))
+	y := Must(SoftMax(Must(BroadcastAdd(Must(Mul(flat, w)), b, nil, []byte{0}))))
+	WithName("y")(y)
+	cost := Must(Mean(Must(Square(y))))
+	WithName("cost")(cost)
+	if _, err := Grad(cost, filter, w); err != nil {
+		t.Fatal(err)
+	}
+	WithName("dfilter")(filter.deriv)
+	WithName("dw")(w.deriv)
+
+	p, err := NewProgram(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	enc, err := MarshalProgram(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q, err := UnmarshalProgram(enc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(p.String(), q.String())
+	assert.Equal(p.Inputs(), q.Inputs())
+	assert.Equal(p.Outputs(), q.Outputs())
+
+	xVal := tensor.New(tensor.WithShape(2, 1, 6, 6), tensor.WithBacking(tensor.Random(tensor.Float64, 72)))
+	run := func(p *Program) []Value {
+		m := NewProgramMachine(p)
+		if err := m.Set("x", xVal); err != nil {
+			t.Fatal(err)
+		}
+		// twice, so that the masks of the max pools are filled by the first run
+		for i := 0; i < 2; i++ {
+			if err := m.RunAll(); err != nil {
+				t.Fatal(err)
+			}
+		}
+		retVal := make([]Value, len(p.Outputs()))
+		for i := range retVal {
+			v, err := m.Output(i)
+			if err != nil {
+				t.Fatal(err)
+			}
+			retVal[i] = v
+		}
+		return retVal
+	}
+	want, got := run(p), run(q)
+	for i, v := range want {
+		assert.Equal(v.Data(), got[i].Data(), p.Outputs()[i].Name)
+	}
+
+	// the ops that have no encoding
+	g = NewGraph()
+	x = NewMatrix(g, Float64, WithShape(2, 3), WithName("x"))
+	Must(SampleCategorical(x))
+	if p, err = NewProgram(g); err != nil {
+		t.Fatal(err)
+	}
+	_, err = MarshalProgram(p)
+	assert.Error(err)
+
+	// a corrupted program is not decoded, but does not panic either
+	for i := range enc {
+		for _, c := range []byte{0xff, 0x7f, 0} {
+			corrupt := append([]byte(nil), enc...)
+			corrupt[i] = c
+			if q, err := UnmarshalProgram(corrupt); err == nil {
+				_ = q.String()
+			}
+		}
+	}
+}
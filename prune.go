@@ -0,0 +1,448 @@
+package gorgonia
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+
+	"github.com/pkg/errors"
+	"gorgonia.org/tensor"
+)
+
+/*
+This file holds the pruning utilities. A typical workflow for structured pruning of a hidden layer is:
+		mask, keep, err := ChannelMask(w1.Value().(tensor.Tensor), 1, 0.5) // prune half the columns of w1
+		...
+		// fine-tune with the mask applied
+		masked, err := ApplyMask(w1, mask)
+		...
+		// physically remove the pruned units: the columns of w1, the elements of b1 and the rows of w2
+		pruned, err := PruneChannels(keep, PruneSpec{w1, 1}, PruneSpec{b1, 0}, PruneSpec{w2, 0})
+
+The masks and the pruning work on the values of float tensors.
+*/
+
+// MagnitudeMask returns a mask of the same shape as w that zeroes out the given fraction of w's elements with the
+// smallest magnitudes. The other elements of the mask are ones.
+func MagnitudeMask(w tensor.Tensor, sparsity float64) (tensor.Tensor, error) {
+	data, err := pruneFloats(w, sparsity)
+	if err != nil {
+		return nil, err
+	}
+	mags := make([]float64, len(data))
+	for i, v := range data {
+		mags[i] = math.Abs(v)
+	}
+	mask := make([]float64, len(data))
+	for _, i := range largest(mags, len(data)-int(sparsity*float64(len(data)))) {
+		mask[i] = 1
+	}
+	return maskOf(w, mask)
+}
+
+// ChannelMask prunes whole channels (the slices of w along the axis) with the smallest L2 norms. It returns a mask of the same
+// shape as w that zeroes out the pruned channels, as well as the sorted indices of the channels that are kept.
+func ChannelMask(w tensor.Tensor, axis int, sparsity float64) (mask tensor.Tensor, keep []int, err error) {
+	var data []float64
+	if data, err = pruneFloats(w, sparsity); err != nil {
+		return nil, nil, err
+	}
+	shp := w.Shape()
+	if axis < 0 || axis >= shp.Dims() {
+		return nil, nil, errors.Errorf("Axis %d is out of range for shape %v", axis, shp)
+	}
+	channel := channelOf(shp, axis)
+	norms := make([]float64, shp[axis])
+	for i, v := range data {
+		norms[channel(i)] += v * v
+	}
+
+	keep = largest(norms, shp[axis]-int(sparsity*float64(shp[axis])))
+	sort.Ints(keep)
+	kept := make([]bool, shp[axis])
+	for _, c := range keep {
+		kept[c] = true
+	}
+	m := make([]float64, len(data))
+	for i := range m {
+		if kept[channel(i)] {
+			m[i] = 1
+		}
+	}
+	if mask, err = maskOf(w, m); err != nil {
+		return nil, nil, err
+	}
+	return mask, keep, nil
+}
+
+// ApplyMask multiplies w with the mask, which is added to the graph as a constant. Every node that used w uses the
+// masked w instead. It returns the masked node.
+func ApplyMask(w *Node, mask tensor.Tensor) (*Node, error) {
+	if w == nil || w.g == nil {
+		return nil, errors.New("Cannot mask a node that is not in a graph")
+	}
+	if !mask.Shape().Eq(w.Shape()) || mask.Dtype() != w.Dtype() {
+		return nil, errors.Errorf("Cannot mask %v of %v %v with a mask of %v %v", w, w.Dtype(), w.Shape(), mask.Dtype(), mask.Shape())
+	}
+	return w.g.InsertAfter(w, func(w *Node) (*Node, error) {
+		return HadamardProd(w, NewConstant(mask, WithName(w.Name()+".mask")))
+	})
+}
+
+// FoldMask folds a mask applied by ApplyMask into the value of the weights, and removes the masking from the graph.
+// This is typically done once training is done, so that inference does not pay for the masking.
+func FoldMask(masked *Node) error {
+	op, ok := masked.op.(elemBinOp)
+	if !ok || op.binOpType() != mulOpType || !masked.children[1].isConstant() || !masked.children[0].isInput() {
+		return errors.Errorf("Expected %v to be a masked input, as returned by ApplyMask", masked)
+	}
+	w, mask := masked.children[0], masked.children[1]
+	wv, ok := w.Value().(tensor.Tensor)
+	if !ok {
+		return errors.Errorf("Expected %v to have a tensor value. Got %T instead", w, w.Value())
+	}
+	if _, err := tensor.Mul(wv, mask.Value(), tensor.UseUnsafe()); err != nil {
+		return errors.Wrap(err, pointWiseMulFail)
+	}
+	return w.g.ReplaceNode(masked, w)
+}
+
+// PruneSpec designates the channels of a parameter along an axis.
+type PruneSpec struct {
+	Node *Node
+	Axis int
+}
+
+// PruneChannels physically removes the channels that are not kept from the parameters, which must be input nodes with
+// tensor values. Each parameter is replaced by a smaller input node of the same name, and the shapes of all the nodes that depend
+// on the parameters are inferred anew. All the parameters that share the pruned channels must be pruned together, otherwise
+// the shapes of the graph will not be consistent, which is an error. It returns the new parameter nodes.
+//
+// The hyperparameters of the ops that derive from the shapes of their inputs are updated for broadcasting, reshapes that only
+// add or remove dimensions of size 1, sums and max pooling. Other ops that hold shapes fail the shape inference.
+//
+// PruneChannels must be called before the gradients are computed, and before the graph is compiled.
+func PruneChannels(keep []int, specs ...PruneSpec) (Nodes, error) {
+	if len(keep) == 0 {
+		return nil, errors.New("Cannot prune all the channels")
+	}
+	if len(specs) == 0 {
+		return nil, nil
+	}
+	g := specs[0].Node.g
+
+	replacements := make(map[*Node]*Node)
+	retVal := make(Nodes, len(specs))
+	for i, spec := range specs {
+		n := spec.Node
+		if n == nil || n.g != g || !n.isInput() {
+			return nil, errors.Errorf("Expected %v to be an input node of the graph", n)
+		}
+		v, ok := n.Value().(tensor.Tensor)
+		if !ok {
+			return nil, errors.Errorf("Expected %v to have a tensor value. Got %T instead", n, n.Value())
+		}
+		pruned, err := selectChannels(v, spec.Axis, keep)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Failed to prune %v", n)
+		}
+		retVal[i] = newNode(In(g), WithType(n.t), WithShape(pruned.Shape()...), WithName(n.name), WithValue(pruned))
+		retVal[i].groups = n.groups
+		replacements[n] = retVal[i]
+	}
+
+	// infer the new shapes before mutating anything, so that a failure leaves the graph untouched
+	affected := NewNodeSet()
+	for n := range replacements {
+		for _, p := range g.to.get(n) {
+			walkUsers(g, p, affected)
+		}
+	}
+	users := affected.ToSlice()
+	sort.Slice(users, func(i, j int) bool { return users[i].id < users[j].id }) // children are added before their parents
+	shapes := make(map[*Node]tensor.Shape)
+	ops := make(map[*Node]Op)
+	shapeOf := func(n *Node) tensor.Shape {
+		if r, ok := replacements[n]; ok {
+			return r.shape
+		}
+		if s, ok := shapes[n]; ok {
+			return s
+		}
+		return n.shape
+	}
+	for _, n := range users {
+		childShapes := make([]tensor.Shape, len(n.children))
+		for i, child := range n.children {
+			childShapes[i] = shapeOf(child)
+		}
+		op := resizedOp(n.op, childShapes)
+		ops[n] = op
+
+		ds := make([]DimSizer, len(n.children))
+		for i, child := range n.children {
+			if so, ok := ops[child].(sizeOp); ok {
+				ds[i] = so
+			} else if so, ok := child.op.(sizeOp); ok {
+				ds[i] = so
+			} else {
+				ds[i] = childShapes[i]
+			}
+		}
+		s, err := op.InferShape(ds...)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Failed to infer the shape of %v after pruning", n)
+		}
+		shapes[n] = s
+	}
+
+	for _, n := range users {
+		g.unindex(n)
+	}
+	for old, repl := range replacements {
+		g.unindex(old)
+		g.addToAll(repl)
+		g.reindex(repl)
+		parents := g.to.get(old).Set()
+		for _, p := range parents {
+			p.children = p.children.replace(old, repl)
+		}
+		g.to.set(repl, parents)
+		g.to.del(old)
+		g.RemoveNode(old)
+	}
+	for _, n := range users {
+		n.op = ops[n]
+		n.shape = shapes[n]
+		n.hashed = false
+		if !n.isInput() {
+			// the values may share memory, so they are not returned to the pool
+			n.boundTo = nil
+		}
+	}
+	for _, n := range users {
+		g.reindex(n)
+	}
+	g.surgeryDone()
+	return retVal, nil
+}
+
+// resizedOp returns the op, with the hyperparameters that derive from the shapes of its children updated to the given shapes.
+func resizedOp(op Op, children []tensor.Shape) Op {
+	switch o := op.(type) {
+	case sizeOp:
+		if o.val != 0 && o.axis < children[0].Dims() {
+			o.val = children[0][o.axis]
+		}
+		return o
+	case *repeatOp:
+		return &repeatOp{along: o.along, inputShape: children[0].Clone()}
+	case sumOp:
+		o.inputShape = children[0].Clone()
+		return o
+	case *maxPoolOp:
+		if children[0].Dims() == 4 {
+			resized := *o
+			resized.unpaddedB, resized.unpaddedC, resized.unpaddedH, resized.unpaddedW = children[0][0], children[0][1], children[0][2], children[0][3]
+			resized.mask = nil
+			return &resized
+		}
+	case reshapeOp:
+		// only reshapes that add or remove dimensions of size 1 (such as those inserted by broadcasting) can be resized
+		if to, ok := reshapeOnes(o.from, o.to, children[0]); ok {
+			return reshapeOp{from: children[0].Clone(), to: to}
+		}
+	}
+	return op
+}
+
+// reshapeOnes returns the shape that newFrom has to be reshaped into, given that from is reshaped into to by adding or
+// removing dimensions of size 1.
+func reshapeOnes(from, to, newFrom tensor.Shape) (tensor.Shape, bool) {
+	var fs, ns []int
+	for _, d := range from {
+		if d != 1 {
+			fs = append(fs, d)
+		}
+	}
+	for _, d := range newFrom {
+		if d != 1 {
+			ns = append(ns, d)
+		}
+	}
+	if len(fs) != len(ns) {
+		return nil, false
+	}
+	retVal := to.Clone()
+	j := 0
+	for i, d := range to {
+		if d == 1 {
+			continue
+		}
+		if j >= len(fs) || d != fs[j] {
+			return nil, false
+		}
+		retVal[i] = ns[j]
+		j++
+	}
+	return retVal, j == len(fs)
+}
+
+// SparsityInfo holds the number of zeroes in the value of a node.
+type SparsityInfo struct {
+	Name  string
+	Zeros int
+	Total int
+}
+
+// Sparsity returns the fraction of elements that are zero.
+func (s SparsityInfo) Sparsity() float64 {
+	if s.Total == 0 {
+		return 0
+	}
+	return float64(s.Zeros) / float64(s.Total)
+}
+
+// SparsityReport holds the sparsity of a set of nodes.
+type SparsityReport []SparsityInfo
+
+// Sparsity returns the overall fraction of elements that are zero.
+func (r SparsityReport) Sparsity() float64 {
+	var total SparsityInfo
+	for _, s := range r {
+		total.Zeros += s.Zeros
+		total.Total += s.Total
+	}
+	return total.Sparsity()
+}
+
+func (r SparsityReport) String() string {
+	var buf bytes.Buffer
+	for _, s := range r {
+		fmt.Fprintf(&buf, "%v\t%d/%d\t%.2f%%\n", s.Name, s.Zeros, s.Total, 100*s.Sparsity())
+	}
+	fmt.Fprintf(&buf, "total\t\t%.2f%%\n", 100*r.Sparsity())
+	return buf.String()
+}
+
+// Sparsity reports the sparsity of the values of the nodes. Nodes without values are skipped.
+func Sparsity(ns ...*Node) (SparsityReport, error) {
+	var retVal SparsityReport
+	for _, n := range ns {
+		v := n.Value()
+		if v == nil {
+			continue
+		}
+		data, err := valueSlice(v)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Failed to get the data of %v", n)
+		}
+		info := SparsityInfo{Name: n.Name(), Total: data.Len()}
+		for i := 0; i < data.Len(); i++ {
+			if isZeroValue(data.Index(i)) {
+				info.Zeros++
+			}
+		}
+		retVal = append(retVal, info)
+	}
+	return retVal, nil
+}
+
+func isZeroValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return v.Uint() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	}
+	return false
+}
+
+// pruneFloats returns the data of w as float64s, after checking the sparsity.
+func pruneFloats(w tensor.Tensor, sparsity float64) ([]float64, error) {
+	if sparsity < 0 || sparsity >= 1 {
+		return nil, errors.Errorf("Expected a sparsity in [0, 1). Got %v instead", sparsity)
+	}
+	switch data := tensor.Materialize(w).Data().(type) {
+	case []float64:
+		return data, nil
+	case []float32:
+		retVal := make([]float64, len(data))
+		for i, v := range data {
+			retVal[i] = float64(v)
+		}
+		return retVal, nil
+	}
+	return nil, errors.Errorf(nyiFail, "pruning", w.Dtype())
+}
+
+// maskOf creates a mask of the same shape and dtype as w.
+func maskOf(w tensor.Tensor, mask []float64) (tensor.Tensor, error) {
+	var backing interface{} = mask
+	if w.Dtype() == tensor.Float32 {
+		m := make([]float32, len(mask))
+		for i, v := range mask {
+			m[i] = float32(v)
+		}
+		backing = m
+	}
+	return tensor.New(tensor.WithShape(w.Shape().Clone()...), tensor.WithBacking(backing)), nil
+}
+
+// largest returns the indices of the k largest values.
+func largest(vals []float64, k int) []int {
+	idx := make([]int, len(vals))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(i, j int) bool { return vals[idx[i]] > vals[idx[j]] })
+	return idx[:k]
+}
+
+// channelOf returns a function that returns the index along the axis of the ith element of a row major tensor of the shape.
+func channelOf(shp tensor.Shape, axis int) func(i int) int {
+	stride := 1
+	for _, d := range shp[axis+1:] {
+		stride *= d
+	}
+	size := shp[axis]
+	return func(i int) int { return (i / stride) % size }
+}
+
+// selectChannels returns a new tensor with only the channels of t along the axis that are kept.
+func selectChannels(t tensor.Tensor, axis int, keep []int) (tensor.Tensor, error) {
+	shp := t.Shape()
+	if axis < 0 || axis >= shp.Dims() {
+		return nil, errors.Errorf("Axis %d is out of range for shape %v", axis, shp)
+	}
+	for _, c := range keep {
+		if c < 0 || c >= shp[axis] {
+			return nil, errors.Errorf("Channel %d is out of range for axis %d of shape %v", c, axis, shp)
+		}
+	}
+
+	src := reflect.ValueOf(tensor.Materialize(t).Data())
+	newShape := shp.Clone()
+	newShape[axis] = len(keep)
+	dst := reflect.MakeSlice(src.Type(), newShape.TotalSize(), newShape.TotalSize())
+
+	inner := 1
+	for _, d := range shp[axis+1:] {
+		inner *= d
+	}
+	outer := shp.TotalSize() / (inner * shp[axis])
+	for o := 0; o < outer; o++ {
+		for j, c := range keep {
+			from := (o*shp[axis] + c) * inner
+			to := (o*len(keep) + j) * inner
+			reflect.Copy(dst.Slice(to, to+inner), src.Slice(from, from+inner))
+		}
+	}
+	return tensor.New(tensor.WithShape(newShape...), tensor.WithBacking(dst.Interface())), nil
+}
@@ -0,0 +1,168 @@
+package gorgonia
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorgonia.org/tensor"
+)
+
+func TestMagnitudeMask(t *testing.T) {
+	assert := assert.New(t)
+	w := tensor.New(tensor.WithShape(2, 3), tensor.WithBacking([]float64{0.1, -5, 3, -0.2, 4, 0.05}))
+	mask, err := MagnitudeMask(w, 0.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]float64{0, 1, 1, 0, 1, 0}, mask.Data())
+
+	w32 := tensor.New(tensor.WithShape(4), tensor.WithBacking([]float32{1, -2, 3, -4}))
+	if mask, err = MagnitudeMask(w32, 0.25); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]float32{0, 1, 1, 1}, mask.Data())
+
+	_, err = MagnitudeMask(w, 1)
+	assert.Error(err)
+	_, err = MagnitudeMask(tensor.New(tensor.WithShape(2), tensor.WithBacking([]int{1, 2})), 0.5)
+	assert.Error(err)
+}
+
+func TestChannelMask(t *testing.T) {
+	assert := assert.New(t)
+	w := tensor.New(tensor.WithShape(2, 3), tensor.WithBacking([]float64{1, 0.1, -3, 1, 0.1, 3}))
+
+	mask, keep, err := ChannelMask(w, 1, 0.34)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]int{0, 2}, keep)
+	assert.Equal([]float64{1, 0, 1, 1, 0, 1}, mask.Data())
+
+	if mask, keep, err = ChannelMask(w, 0, 0.5); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]int{0}, keep) // the rows have the same norms, the first is kept
+	assert.Equal([]float64{1, 1, 1, 0, 0, 0}, mask.Data())
+
+	_, _, err = ChannelMask(w, 2, 0.5)
+	assert.Error(err)
+}
+
+func pruneGraph() (g *ExprGraph, x, w1, b1, w2, cost *Node) {
+	g = NewGraph()
+	x = NewMatrix(g, Float64, WithShape(2, 3), WithName("x"), WithInit(RangedFrom(0)))
+	w1 = NewMatrix(g, Float64, WithShape(3, 4), WithName("w1"), WithValue(tensor.New(tensor.WithShape(3, 4), tensor.WithBacking([]float64{
+		1, 0.01, -1, 0.02,
+		1, 0.01, 1, -0.01,
+		1, -0.01, 1, 0.01,
+	}))))
+	b1 = NewVector(g, Float64, WithShape(4), WithName("b1"), WithValue(tensor.New(tensor.WithBacking([]float64{0, 0.1, 0, 0.1}))))
+	w2 = NewMatrix(g, Float64, WithShape(4, 2), WithName("w2"), WithInit(RangedFrom(1)))
+	h := Must(Rectify(Must(BroadcastAdd(Must(Mul(x, w1)), b1, nil, []byte{0}))))
+	cost = Must(Sum(Must(Mul(h, w2))))
+	return
+}
+
+func TestApplyMask(t *testing.T) {
+	assert := assert.New(t)
+	g, _, w1, _, _, cost := pruneGraph()
+	mask, keep, err := ChannelMask(w1.Value().(tensor.Tensor), 1, 0.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]int{0, 2}, keep)
+
+	masked, err := ApplyMask(w1, mask)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(Nodes{masked}, g.to.get(w1))
+
+	m := NewTapeMachine(g)
+	if err = m.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+	m.Close()
+	masked1 := cost.Value().Data().(float64)
+
+	if err = FoldMask(masked); err != nil {
+		t.Fatal(err)
+	}
+	assert.False(g.AllNodes().Contains(masked))
+	report, err := Sparsity(w1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(0.5, report.Sparsity())
+	assert.Equal(6, report[0].Zeros)
+	assert.Contains(report.String(), "w1\t6/12\t50.00%")
+
+	m = NewTapeMachine(g)
+	if err = m.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+	m.Close()
+	assert.Equal(masked1, cost.Value().Data())
+
+	_, err = ApplyMask(w1, tensor.New(tensor.WithShape(3), tensor.WithBacking([]float64{1, 1, 1})))
+	assert.Error(err)
+	assert.Error(FoldMask(cost))
+}
+
+func TestPruneChannels(t *testing.T) {
+	assert := assert.New(t)
+	g, x, w1, b1, w2, cost := pruneGraph()
+	// channels 1 and 3 are dead anyway
+	mask, keep, err := ChannelMask(w1.Value().(tensor.Tensor), 1, 0.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	masked, err := ApplyMask(w1, mask)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = FoldMask(masked); err != nil {
+		t.Fatal(err)
+	}
+	b1.Value().(tensor.Tensor).Zero()
+
+	m := NewTapeMachine(g)
+	if err = m.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+	m.Close()
+	expected := cost.Value().Data()
+
+	// w1 alone cannot be pruned
+	_, err = PruneChannels(keep, PruneSpec{w1, 1})
+	assert.Error(err)
+	assert.True(g.AllNodes().Contains(w1), "the graph is left untouched")
+
+	pruned, err := PruneChannels(keep, PruneSpec{w1, 1}, PruneSpec{b1, 0}, PruneSpec{w2, 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.False(g.AllNodes().Contains(w1))
+	assert.Equal("w1", pruned[0].Name())
+	assert.Equal(tensor.Shape{3, 2}, pruned[0].Shape())
+	assert.Equal(tensor.Shape{2}, pruned[1].Shape())
+	assert.Equal(tensor.Shape{2, 2}, pruned[2].Shape())
+	assert.Equal([]float64{1, -1, 1, 1, 1, 1}, pruned[0].Value().Data())
+	assert.Equal([]float64{1, 2, 5, 6}, pruned[2].Value().Data())
+	assert.Equal(tensor.Shape{2, 2}, g.to.get(pruned[0])[0].Shape())
+	assert.Equal(Nodes{pruned[0]}, g.ByName("w1"))
+	assert.Equal(x, g.ByName("x")[0])
+
+	m = NewTapeMachine(g)
+	if err = m.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+	m.Close()
+	assert.Equal(expected, cost.Value().Data())
+
+	_, err = PruneChannels(nil, PruneSpec{pruned[0], 1})
+	assert.Error(err)
+	_, err = PruneChannels([]int{5}, PruneSpec{pruned[0], 1})
+	assert.Error(err)
+}
@@ -0,0 +1,327 @@
+package gorgonia
+
+/*
+This file holds the quantized weights. A quantized tensor stores int8 quants, in groups of consecutive elements along
+its last axis that share a scale and, for the asymmetric quantizations, a min:
+
+		w[i] = scale[i/group]·q[i] + min[i/group]
+
+which is the layout of the block quantizations of GGML (Q4_0, Q8_0, Q4_K...; see the gguf package). The groups may also
+span the trailing axes, such as the c·kh·kw elements of an output channel of a conv filter: the per-channel quantization
+of QuantizePerChannel. The asymmetric quantization of WithZeroPoints has integer zero-points, whose mins are
+-scale·zero-point. The weights are multiplied by QuantizedMatMul and QuantizedConv2d, which dequantize them on the fly
+instead of materializing a float matrix.
+*/
+
+import (
+	"math"
+
+	"github.com/pkg/errors"
+	"gorgonia.org/tensor"
+)
+
+// QuantizedTensor is a tensor quantized by groups of Group consecutive elements. Quants is an Int8 tensor of the shape
+// of the tensor. Scales and Mins are Float32 tensors of the same shape, but for the last axis, which is divided by
+// Group; or, if the groups span the trailing axes, of the shape of the leading axes followed by ones, such as
+// (out, 1, 1, 1) for a conv filter quantized by output channel. Mins is nil if the quantization is symmetric.
+type QuantizedTensor struct {
+	Quants *tensor.Dense
+	Scales *tensor.Dense
+	Mins   *tensor.Dense
+	Group  int
+}
+
+// QuantizeOpt is an option of Quantize and QuantizePerChannel.
+type QuantizeOpt func(*quantizeOpts)
+
+type quantizeOpts struct {
+	zeroPoints bool
+}
+
+// WithZeroPoints quantizes the groups asymmetrically: the range of a group, extended to include 0, is mapped to the 256
+// quants, and the zero-point is the quant of 0. This is the int8 quantization of the weights whose values are mostly of
+// one sign, such as those following a ReLU.
+func WithZeroPoints() QuantizeOpt { return func(o *quantizeOpts) { o.zeroPoints = true } }
+
+// Quantize quantizes a float tensor by groups of consecutive elements, which must divide the last axis or be the
+// product of trailing axes. By default the quantization is symmetric: the scale of a group is its largest absolute
+// value divided by 127, as in Q8_0.
+func Quantize(t tensor.Tensor, group int, opts ...QuantizeOpt) (*QuantizedTensor, error) {
+	var o quantizeOpts
+	for _, opt := range opts {
+		opt(&o)
+	}
+	shape := t.Shape()
+	scaleShape, err := groupShape(shape, group)
+	if err != nil {
+		return nil, errors.Wrap(err, "Cannot quantize")
+	}
+	var data []float64
+	switch d := t.Data().(type) {
+	case []float64:
+		data = d
+	case []float32:
+		data = make([]float64, len(d))
+		for i, v := range d {
+			data[i] = float64(v)
+		}
+	default:
+		return nil, errors.Errorf("Cannot quantize a tensor of %v: expected a float tensor", t.Dtype())
+	}
+
+	quants := make([]int8, len(data))
+	scales := make([]float32, len(data)/group)
+	var mins []float32
+	if o.zeroPoints {
+		mins = make([]float32, len(scales))
+	}
+	for g := range scales {
+		vs := data[g*group : (g+1)*group]
+		if o.zeroPoints {
+			scales[g], mins[g] = quantizeZeroPoint(vs, quants[g*group:])
+			continue
+		}
+		var amax float64
+		for _, v := range vs {
+			amax = math.Max(amax, math.Abs(v))
+		}
+		if amax == 0 {
+			continue
+		}
+		scale := amax / 127
+		for i, v := range vs {
+			quants[g*group+i] = int8(math.Round(v / scale))
+		}
+		scales[g] = float32(scale)
+	}
+	retVal := &QuantizedTensor{
+		Quants: tensor.New(tensor.WithShape(shape.Clone()...), tensor.WithBacking(quants)),
+		Scales: tensor.New(tensor.WithShape(scaleShape...), tensor.WithBacking(scales)),
+		Group:  group,
+	}
+	if mins != nil {
+		retVal.Mins = tensor.New(tensor.WithShape(scaleShape.Clone()...), tensor.WithBacking(mins))
+	}
+	return retVal, nil
+}
+
+// QuantizePerChannel quantizes a float tensor by output channel, its first axis: the rows of a matrix (out, in), or the
+// filters of a conv filter (out, c, kh, kw). The int8 accuracy of the per-channel quantization is that of the channels
+// of the smallest ranges, instead of being that of the whole tensor.
+func QuantizePerChannel(t tensor.Tensor, opts ...QuantizeOpt) (*QuantizedTensor, error) {
+	shape := t.Shape()
+	if len(shape) < 2 {
+		return nil, errors.Errorf("Cannot quantize a tensor of the shape %v by output channel", shape)
+	}
+	return Quantize(t, tensor.Shape(shape[1:]).TotalSize(), opts...)
+}
+
+// quantizeZeroPoint quantizes the values of a group asymmetrically into q, and returns the scale and the min, which is
+// -scale·zero-point.
+func quantizeZeroPoint(vs []float64, q []int8) (scale, min float32) {
+	lo, hi := 0.0, 0.0
+	for _, v := range vs {
+		lo, hi = math.Min(lo, v), math.Max(hi, v)
+	}
+	if lo == hi {
+		return 0, 0
+	}
+	s := (hi - lo) / 255
+	zp := math.Round(-128 - lo/s)
+	for i, v := range vs {
+		q[i] = int8(math.Max(-128, math.Min(127, math.Round(v/s)+zp)))
+	}
+	scale = float32(s)
+	return scale, -scale * float32(zp)
+}
+
+// groupShape is the shape of the scales of a tensor of the shape, quantized by groups of group elements.
+func groupShape(shape tensor.Shape, group int) (tensor.Shape, error) {
+	if len(shape) == 0 || group <= 0 {
+		return nil, errors.Errorf("Cannot divide the shape %v into groups of %d", shape, group)
+	}
+	retVal := shape.Clone()
+	last := len(retVal) - 1
+	if retVal[last]%group == 0 {
+		retVal[last] /= group
+		return retVal, nil
+	}
+	for size, i := 1, last; i >= 0; i-- {
+		size *= retVal[i]
+		retVal[i] = 1
+		if size == group {
+			return retVal, nil
+		}
+		if size > group {
+			break
+		}
+	}
+	return nil, errors.Errorf("The groups of %d neither divide the last axis of %v nor span its trailing axes", group, shape)
+}
+
+// Shape returns the shape of the tensor.
+func (q *QuantizedTensor) Shape() tensor.Shape { return q.Quants.Shape() }
+
+func (q *QuantizedTensor) check() error {
+	if q.Quants == nil || q.Scales == nil || q.Quants.Dtype() != tensor.Int8 || q.Scales.Dtype() != tensor.Float32 {
+		return errors.New("Expected the Int8 quants and the Float32 scales of a quantized tensor")
+	}
+	want, err := groupShape(q.Shape(), q.Group)
+	if err != nil {
+		return err
+	}
+	if !q.Scales.Shape().Eq(want) {
+		return errors.Errorf("Expected the scales of the shape %v. Got %v instead", want, q.Scales.Shape())
+	}
+	if q.Mins != nil && (q.Mins.Dtype() != tensor.Float32 || !q.Mins.Shape().Eq(want)) {
+		return errors.Errorf("Expected the Float32 mins of the shape %v. Got %v of %v instead", want, q.Mins.Shape(), q.Mins.Dtype())
+	}
+	return nil
+}
+
+// Dequantize returns the Float32 tensor of the quants.
+func (q *QuantizedTensor) Dequantize() (*tensor.Dense, error) {
+	if err := q.check(); err != nil {
+		return nil, err
+	}
+	quants := q.Quants.Int8s()
+	scales := q.Scales.Float32s()
+	var mins []float32
+	if q.Mins != nil {
+		mins = q.Mins.Float32s()
+	}
+	data := make([]float32, len(quants))
+	for i, v := range quants {
+		data[i] = scales[i/q.Group] * float32(v)
+		if mins != nil {
+			data[i] += mins[i/q.Group]
+		}
+	}
+	return tensor.New(tensor.WithShape(q.Shape().Clone()...), tensor.WithBacking(data)), nil
+}
+
+// QuantizedWeight is a quantized matrix of the shape (out, in) in a graph, such as Wq of an attention layer loaded
+// from a GGUF file, or a quantized conv filter of the shape (out, c, kh, kw). Mins is nil if the quantization is
+// symmetric.
+type QuantizedWeight struct {
+	Quants, Scales, Mins *Node
+	Group                int
+}
+
+// NewQuantizedWeight adds the nodes of a quantized matrix or conv filter to the graph, named after the name:
+// "wq.quants", "wq.scales" and "wq.mins". The scales and the mins are frozen, so that they are not among the Learnables.
+func NewQuantizedWeight(g *ExprGraph, q *QuantizedTensor, name string) (*QuantizedWeight, error) {
+	if err := q.check(); err != nil {
+		return nil, errors.Wrapf(err, "Cannot add the quantized weight %v", name)
+	}
+	dims := q.Quants.Dims()
+	if dims != 2 && dims != 4 {
+		return nil, errors.Errorf("Expected a quantized matrix or conv filter. Got the shape %v instead", q.Shape())
+	}
+	w := &QuantizedWeight{
+		Quants: NewTensor(g, tensor.Int8, dims, WithShape(q.Shape()...), WithName(name+".quants"), WithValue(q.Quants)),
+		Scales: NewTensor(g, Float32, dims, WithShape(q.Scales.Shape()...), WithName(name+".scales"), WithValue(q.Scales)),
+		Group:  q.Group,
+	}
+	Freeze(w.Scales)
+	if q.Mins != nil {
+		w.Mins = NewTensor(g, Float32, dims, WithShape(q.Mins.Shape()...), WithName(name+".mins"), WithValue(q.Mins))
+		Freeze(w.Mins)
+	}
+	return w, nil
+}
+
+// QuantizedMatMulOpt is an option of QuantizedMatMul and QuantizedConv2d.
+type QuantizedMatMulOpt func(*quantMatMulOp)
+
+// WithActivationGroups quantizes the activations x dynamically, when the op is run, symmetrically by groups of the given
+// size of their last axis, which must divide the groups of the weight or be a multiple of them. The dot products of the
+// quants are then accumulated in int32, as in the int8 kernels of the hardware. The gradient of x is that of the float
+// multiplication (the straight-through estimator).
+func WithActivationGroups(group int) QuantizedMatMulOpt {
+	return func(op *quantMatMulOp) { op.actGroup = group }
+}
+
+// QuantizedMatMul multiplies x, a Float32 vector of length in or matrix of shape (m, in), by the transpose of the
+// quantized weight: the result is of length out, or of shape (m, out). The weight is dequantized on the fly, group by
+// group, so that the float matrix is never materialized. The result is differentiable with regards to x only.
+func QuantizedMatMul(x *Node, w *QuantizedWeight, opts ...QuantizedMatMulOpt) (retVal *Node, err error) {
+	if w == nil || w.Quants == nil || w.Scales == nil {
+		return nil, errors.New("QuantizedMatMul expects the quants and the scales of a weight")
+	}
+	if !w.Quants.IsMatrix() {
+		return nil, errors.Errorf("QuantizedMatMul expects a quantized matrix. Got %v instead: use QuantizedConv2d for conv filters", w.Quants.Shape())
+	}
+	if x.Dtype() != Float32 || (!x.IsVector() && !x.IsMatrix()) {
+		return nil, errors.Errorf("QuantizedMatMul expects a Float32 vector or matrix. Got %v of %v instead", x.Shape(), x.Dtype())
+	}
+	op := quantMatMulOp{group: w.Group, mins: w.Mins != nil, d: x.Dims()}
+	for _, opt := range opts {
+		opt(&op)
+	}
+	if ag := op.actGroup; ag != 0 {
+		in := w.Quants.Shape()[1]
+		if ag < 0 || in%ag != 0 || (ag%w.Group != 0 && w.Group%ag != 0) {
+			return nil, errors.Errorf("Cannot quantize the activations of %d by groups of %d for the weight groups of %d", in, ag, w.Group)
+		}
+	}
+	inputs := Nodes{x, w.Quants, w.Scales}
+	if w.Mins != nil {
+		inputs = append(inputs, w.Mins)
+	}
+	return ApplyOp(op, inputs...)
+}
+
+// QuantizedConv2d is the Conv2d of a NCHW image by a quantized filter of the shape (out, c, kh, kw), such as one of
+// QuantizePerChannel. The patches of the image are multiplied by the flattened filter with QuantizedMatMul, with the
+// options.
+func QuantizedConv2d(im *Node, w *QuantizedWeight, pad, stride, dilation []int, opts ...QuantizedMatMulOpt) (retVal *Node, err error) {
+	if w == nil || w.Quants == nil || w.Scales == nil || w.Quants.Dims() != 4 {
+		return nil, errors.New("QuantizedConv2d expects the quants and the scales of a conv filter")
+	}
+	if im.Dims() != 4 || im.layout == NHWC {
+		return nil, errors.Errorf("QuantizedConv2d expects a NCHW image. Got %v instead", im.Shape())
+	}
+	if pad == nil {
+		pad = []int{0, 0}
+	}
+	if dilation == nil {
+		dilation = []int{1, 1}
+	}
+	fs := w.Quants.Shape()
+	layer, z := fs[0], fs[1]*fs[2]*fs[3]
+	if im.Shape()[1] != fs[1] {
+		return nil, errors.Errorf("Shape mismatch: the image %v has %d channels, the filter %v %d", im.Shape(), im.Shape()[1], fs, fs[1])
+	}
+
+	// the filter is flattened into a matrix (out, c·kh·kw), whose groups are the same consecutive elements
+	flattened := &QuantizedWeight{Group: w.Group}
+	groups := tensor.Shape{layer, z / w.Group}
+	if flattened.Quants, err = Reshape(w.Quants, tensor.Shape{layer, z}); err != nil {
+		return nil, err
+	}
+	if flattened.Scales, err = Reshape(w.Scales, groups); err != nil {
+		return nil, err
+	}
+	if w.Mins != nil {
+		if flattened.Mins, err = Reshape(w.Mins, groups.Clone()); err != nil {
+			return nil, err
+		}
+	}
+
+	var colIm, patch, res *Node
+	if colIm, err = Im2Col(im, tensor.Shape{fs[2], fs[3]}, pad, stride, dilation); err != nil {
+		return nil, err
+	}
+	batch, m, n := colIm.Shape()[0], colIm.Shape()[1], colIm.Shape()[2]
+	if patch, err = Reshape(colIm, tensor.Shape{batch * m * n, z}); err != nil {
+		return nil, err
+	}
+	if res, err = QuantizedMatMul(patch, flattened, opts...); err != nil {
+		return nil, err
+	}
+	if res, err = Reshape(res, tensor.Shape{batch, m, n, layer}); err != nil {
+		return nil, err
+	}
+	return Transpose(res, 0, 3, 1, 2)
+}
@@ -0,0 +1,179 @@
+package gorgonia
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorgonia.org/tensor"
+)
+
+func TestQuantize(t *testing.T) {
+	assert := assert.New(t)
+	data := []float32{0.5, -1, 0.25, 0, 0, 0, 0, 0, 3, 2, 1, -0.1}
+	q, err := Quantize(tensor.New(tensor.WithShape(3, 4), tensor.WithBacking(data)), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(tensor.Shape{3, 4}, q.Shape())
+	assert.Equal(tensor.Shape{3, 2}, q.Scales.Shape())
+	assert.Equal([]int8{64, -127, 127, 0, 0, 0, 0, 0, 127, 85, 127, -13}, q.Quants.Data())
+	assert.Equal(float32(3.0/127), q.Scales.Data().([]float32)[4])
+	assert.Equal(float32(0), q.Scales.Data().([]float32)[2], "a group of zeroes")
+
+	d, err := q.Dequantize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, v := range d.Data().([]float32) {
+		assert.InDelta(data[i], v, float64(q.Scales.Data().([]float32)[i/2])/2+1e-7, "%d", i)
+	}
+
+	// the mins are added to the groups
+	q.Mins = tensor.New(tensor.WithShape(3, 2), tensor.WithBacking([]float32{1, 0, 0, 0, 0, 0}))
+	d, _ = q.Dequantize()
+	assert.InDelta(1.5, d.Data().([]float32)[0], 1e-2)
+
+	_, err = Quantize(tensor.New(tensor.WithShape(3, 4), tensor.WithBacking(data)), 3)
+	assert.Error(err, "the groups do not divide the last axis")
+	_, err = Quantize(tensor.New(tensor.WithShape(2), tensor.WithBacking([]int{1, 2})), 2)
+	assert.Error(err, "not a float tensor")
+	q.Mins = tensor.New(tensor.WithShape(3), tensor.WithBacking([]float32{1, 0, 0}))
+	_, err = q.Dequantize()
+	assert.Error(err, "the mins are of the wrong shape")
+}
+
+func TestNewQuantizedWeight(t *testing.T) {
+	assert := assert.New(t)
+	g := NewGraph()
+	q, err := Quantize(tensor.New(tensor.WithShape(2, 4), tensor.WithBacking(tensor.Range(tensor.Float32, 0, 8))), 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q.Mins = tensor.New(tensor.WithShape(2, 1), tensor.WithBacking([]float32{0, 1}))
+	w, err := NewQuantizedWeight(g, q, "wq")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal("wq.quants", w.Quants.Name())
+	assert.Equal("wq.scales", w.Scales.Name())
+	assert.Equal("wq.mins", w.Mins.Name())
+	assert.Equal(4, w.Group)
+	x := NewMatrix(g, Float32, WithShape(1, 4), WithName("x"), WithInit(Zeroes()))
+	learnables := g.Learnables()
+	assert.True(len(learnables) == 1 && learnables[0] == x, "the quantized weight is not learnable")
+
+	_, err = NewQuantizedWeight(g, &QuantizedTensor{Quants: q.Quants, Scales: q.Quants, Group: 4}, "bad")
+	assert.Error(err)
+	v, _ := Quantize(tensor.New(tensor.WithShape(4), tensor.WithBacking(make([]float32, 4))), 4)
+	_, err = NewQuantizedWeight(g, v, "vector")
+	assert.Error(err)
+	assert.False(math.IsNaN(float64(v.Scales.Float32s()[0])), "a group of zeroes has a zero scale")
+}
+
+func TestQuantize_zeroPoints(t *testing.T) {
+	assert := assert.New(t)
+	// a group of positive values and a group of mixed values
+	data := []float32{0, 0.5, 1, 2.55, -1, 0, 1, 3}
+	q, err := Quantize(tensor.New(tensor.WithShape(2, 4), tensor.WithBacking(data)), 4, WithZeroPoints())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !assert.NotNil(q.Mins) {
+		return
+	}
+	scales, mins := q.Scales.Float32s(), q.Mins.Float32s()
+	assert.InDelta(0.01, scales[0], 1e-7)
+	assert.InDelta(-scales[0]*-128, mins[0], 1e-6, "0 is the quant -128")
+	assert.Equal([]int8{-128, -78, -28, 127}, q.Quants.Int8s()[:4])
+	// the zero-point is an integer, so that 0 is exact
+	zp := -mins[1] / scales[1]
+	assert.InDelta(math.Round(float64(zp)), zp, 1e-4)
+	d, err := q.Dequantize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, v := range d.Float32s() {
+		assert.InDelta(data[i], v, float64(scales[i/4])/2+1e-6, "%d", i)
+	}
+	assert.Equal(float32(0), d.Float32s()[5])
+}
+
+func TestQuantizePerChannel(t *testing.T) {
+	assert := assert.New(t)
+	// a conv filter (out, c, kh, kw) = (2, 2, 1, 2), whose channels are of different ranges
+	data := []float32{1, -2, 0.5, 4, 0.01, 0.02, -0.03, 0}
+	q, err := QuantizePerChannel(tensor.New(tensor.WithShape(2, 2, 1, 2), tensor.WithBacking(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(4, q.Group)
+	assert.Equal(tensor.Shape{2, 1, 1, 1}, q.Scales.Shape())
+	assert.Equal([]float32{4.0 / 127, 0.03 / 127}, q.Scales.Float32s())
+	assert.Equal([]int8{32, -64, 16, 127, 42, 85, -127, 0}, q.Quants.Int8s())
+
+	m, err := QuantizePerChannel(tensor.New(tensor.WithShape(2, 4), tensor.WithBacking(data)), WithZeroPoints())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(tensor.Shape{2, 1}, m.Scales.Shape())
+	assert.Equal(tensor.Shape{2, 1}, m.Mins.Shape())
+
+	_, err = QuantizePerChannel(tensor.New(tensor.WithShape(4), tensor.WithBacking(data[:4])))
+	assert.Error(err, "a vector has no channels")
+	_, err = Quantize(tensor.New(tensor.WithShape(2, 2, 1, 2), tensor.WithBacking(data)), 3)
+	assert.Error(err, "the groups span no trailing axes")
+}
+
+func TestQuantizedConv2d(t *testing.T) {
+	assert := assert.New(t)
+	for _, withZeroPoints := range []bool{false, true} {
+		g := NewGraph()
+		filter := tensor.New(tensor.WithShape(3, 2, 2, 2), tensor.WithBacking(tensor.Range(tensor.Float32, -12, 12)))
+		var opts []QuantizeOpt
+		if withZeroPoints {
+			opts = append(opts, WithZeroPoints())
+		}
+		q, err := QuantizePerChannel(filter, opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w, err := NewQuantizedWeight(g, q, "conv")
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(4, w.Scales.Dims())
+		wd, err := q.Dequantize()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		imv := tensor.New(tensor.WithShape(1, 2, 3, 3), tensor.WithBacking(tensor.Range(tensor.Float32, 0, 18)))
+		im := NewTensor(g, Float32, 4, WithShape(1, 2, 3, 3), WithName("im"), WithValue(imv))
+		fd := NewTensor(g, Float32, 4, WithShape(3, 2, 2, 2), WithName("fd"), WithValue(wd))
+		y, err := QuantizedConv2d(im, w, []int{1, 1}, []int{1, 1}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, err := Conv2d(im, fd, tensor.Shape{2, 2}, []int{1, 1}, []int{1, 1}, []int{1, 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		m := NewTapeMachine(g)
+		if err = m.RunAll(); err != nil {
+			t.Fatal(err)
+		}
+		m.Close()
+		assert.Equal(tensor.Shape{1, 3, 4, 4}, y.Shape())
+		assert.InDeltaSlice(want.Value().Data(), y.Value().Data(), 1e-3, "zero-points: %t", withZeroPoints)
+	}
+
+	g := NewGraph()
+	q, _ := Quantize(tensor.New(tensor.WithShape(3, 4), tensor.WithBacking(make([]float32, 12))), 4)
+	w, _ := NewQuantizedWeight(g, q, "w")
+	im := NewTensor(g, Float32, 4, WithShape(1, 2, 3, 3), WithName("im"))
+	_, err := QuantizedConv2d(im, w, nil, []int{1, 1}, nil)
+	assert.Error(err, "not a conv filter")
+	_, err = QuantizedMatMul(NewMatrix(g, Float32, WithShape(2, 8)), nil)
+	assert.Error(err)
+}
@@ -0,0 +1,227 @@
+package gorgonia
+
+import (
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/chewxy/hm"
+	"github.com/pkg/errors"
+	"gorgonia.org/tensor"
+)
+
+// rngSource is the seeded source of randomness of a random node. It is shared by the copies of the op, and locked
+// because the lisp machine may run several nodes concurrently.
+type rngSource struct {
+	sync.Mutex
+	seed   int64
+	r      *rand.Rand
+	draws  *countingSource
+	offset uint64 // the number of values drawn on a device so far. See random.cu
+}
+
+func newRNGSource(seed int64) *rngSource {
+	src := &rngSource{}
+	src.reseed(seed, 0)
+	return src
+}
+
+// reseed restarts the source from the seed, and draws n values from it.
+func (src *rngSource) reseed(seed int64, n uint64) {
+	src.seed = seed
+	src.draws = &countingSource{Source64: rand.NewSource(seed).(rand.Source64)}
+	src.r = rand.New(src.draws)
+	for i := uint64(0); i < n; i++ {
+		src.draws.Int63()
+	}
+}
+
+// countingSource counts the values drawn from a source, which is how far the sources of math/rand, whose states cannot
+// be read, are replayed to restore them. Int63 and Uint64 both advance the sources of math/rand by one value.
+type countingSource struct {
+	rand.Source64
+	n uint64
+}
+
+func (s *countingSource) Int63() int64 {
+	s.n++
+	return s.Source64.Int63()
+}
+
+func (s *countingSource) Uint64() uint64 {
+	s.n++
+	return s.Source64.Uint64()
+}
+
+func (s *countingSource) Seed(seed int64) {
+	s.n = 0
+	s.Source64.Seed(seed)
+}
+
+// RandomState is the state of the source of randomness of a random node. See RandomStates.
+type RandomState struct {
+	Seed   int64
+	Draws  uint64 // the number of values drawn on the host
+	Offset uint64 // the number of values drawn on a device
+}
+
+// RandomStates returns the states of the sources of the random nodes created by RandomNormal, RandomUniform and
+// RandomBernoulli, in the order of the nodes in the graph. The nodes whose values are drawn when they are created, such
+// as those of UniformRandomNode, have no state to restore.
+func (g *ExprGraph) RandomStates() []RandomState {
+	var retVal []RandomState
+	for _, src := range g.rngSources() {
+		src.Lock()
+		retVal = append(retVal, RandomState{Seed: src.seed, Draws: src.draws.n, Offset: src.offset})
+		src.Unlock()
+	}
+	return retVal
+}
+
+// SetRandomStates restores the states returned by RandomStates, so that the random nodes draw the values they would
+// have drawn next. The graph must have the same random nodes as the graph of the states, such as the same graph built
+// again by the same code.
+func (g *ExprGraph) SetRandomStates(states []RandomState) error {
+	srcs := g.rngSources()
+	if len(srcs) != len(states) {
+		return errors.Errorf("Expected the states of %d random nodes. Got %d instead", len(srcs), len(states))
+	}
+	for i, src := range srcs {
+		src.Lock()
+		src.reseed(states[i].Seed, states[i].Draws)
+		src.offset = states[i].Offset
+		src.Unlock()
+	}
+	return nil
+}
+
+// rngSources returns the sources of the random nodes of the graph, in the order of the nodes. A source shared by
+// several nodes is returned once.
+func (g *ExprGraph) rngSources() []*rngSource {
+	var retVal []*rngSource
+	seen := make(map[*rngSource]struct{})
+	for _, n := range g.AllNodes() {
+		op, ok := n.op.(randomOp)
+		if !ok || op.src == nil {
+			continue
+		}
+		if _, ok := seen[op.src]; ok {
+			continue
+		}
+		seen[op.src] = struct{}{}
+		retVal = append(retVal, op.src)
+	}
+	return retVal
+}
+
+// RandomOpt is a function that provides construction options for RandomNormal, RandomUniform and RandomBernoulli.
+type RandomOpt func(*randomOp)
+
+// WithRandomSeed seeds the random node. By default, it is seeded from the graph seed (see WithGraphSeed), or from the
+// current time if the graph has none.
+func WithRandomSeed(seed int64) RandomOpt {
+	return func(op *randomOp) { op.src = newRNGSource(seed) }
+}
+
+// RandomNormal creates a node whose value is drawn from a normal distribution of the given mean and standard deviation.
+// The values are drawn again every time the node is executed by a VM, so nothing has to be generated on the host nor
+// Let() at every iteration. A nil or scalar shape creates a scalar node.
+func RandomNormal(g *ExprGraph, dt tensor.Dtype, mean, stdev float64, shape tensor.Shape, opts ...RandomOpt) (*Node, error) {
+	if stdev < 0 {
+		return nil, errors.Errorf("Expected a non-negative standard deviation. Got %v instead", stdev)
+	}
+	return newRandomNode(g, makeRandomOp(gaussian, dt, mean, stdev, shape...), opts)
+}
+
+// RandomUniform creates a node whose value is drawn from a uniform distribution over [low, high). The values are drawn
+// again every time the node is executed.
+func RandomUniform(g *ExprGraph, dt tensor.Dtype, low, high float64, shape tensor.Shape, opts ...RandomOpt) (*Node, error) {
+	if low > high {
+		return nil, errors.Errorf("Expected low <= high. Got %v and %v instead", low, high)
+	}
+	return newRandomNode(g, makeRandomOp(uniform, dt, low, high, shape...), opts)
+}
+
+// RandomBernoulli creates a node whose values are 1 with probability p and 0 otherwise, such as a dropout mask. The
+// values are drawn again every time the node is executed.
+func RandomBernoulli(g *ExprGraph, dt tensor.Dtype, p float64, shape tensor.Shape, opts ...RandomOpt) (*Node, error) {
+	if p < 0 || p > 1 {
+		return nil, errors.Errorf("Expected a probability in [0, 1]. Got %v instead", p)
+	}
+	return newRandomNode(g, makeRandomOp(bernoulli, dt, p, 0, shape...), opts)
+}
+
+func newRandomNode(g *ExprGraph, op randomOp, opts []RandomOpt) (*Node, error) {
+	if op.dt != Float64 && op.dt != Float32 {
+		return nil, errors.Errorf(nyiTypeFail, "random node", op.dt)
+	}
+	for _, opt := range opts {
+		opt(&op)
+	}
+	if op.src == nil {
+		op.src = newRNGSource(g.nextSeed())
+	}
+
+	var t hm.Type = op.dt
+	if !op.shape.IsScalar() {
+		t = makeTensorType(op.shape.Dims(), op.dt)
+	}
+	// inputs are told apart by their names, which the seed keeps unique
+	return NewUniqueNode(WithType(t), WithOp(op), In(g), WithShape(op.shape...), WithName(op.String())), nil
+}
+
+// nextSeed returns the seed of a new random node.
+func (g *ExprGraph) nextSeed() int64 {
+	if g.seed == nil {
+		return time.Now().UnixNano() + int64(atomic.AddUint64(&uniqueOps, 1))
+	}
+	return splitMix64(*g.seed + atomic.AddInt64(&g.random, 1))
+}
+
+// splitMix64 scrambles x, so that seeds derived from consecutive integers give unrelated sequences.
+func splitMix64(x int64) int64 {
+	z := uint64(x) + 0x9e3779b97f4a7c15
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return int64(z ^ (z >> 31))
+}
+
+// draw returns new values drawn from the seeded source of the op.
+func (op randomOp) draw() (Value, error) {
+	op.src.Lock()
+	defer op.src.Unlock()
+	r := op.src.r
+
+	data := make([]float64, logicalSize(op.shape))
+	for i := range data {
+		switch op.which {
+		case uniform:
+			data[i] = op.a + (op.b-op.a)*r.Float64()
+		case gaussian:
+			data[i] = op.a + op.b*r.NormFloat64()
+		case bernoulli:
+			if r.Float64() < op.a {
+				data[i] = 1
+			}
+		}
+	}
+
+	switch op.dt {
+	case Float64:
+		if op.shape.IsScalar() {
+			return newF64(data[0]), nil
+		}
+		return tensor.New(tensor.WithShape(op.shape.Clone()...), tensor.WithBacking(data)), nil
+	case Float32:
+		if op.shape.IsScalar() {
+			return newF32(float32(data[0])), nil
+		}
+		f32 := make([]float32, len(data))
+		for i, v := range data {
+			f32[i] = float32(v)
+		}
+		return tensor.New(tensor.WithShape(op.shape.Clone()...), tensor.WithBacking(f32)), nil
+	}
+	return nil, errors.Errorf(nyiFail, "randomOp.draw()", op.dt)
+}
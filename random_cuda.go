@@ -0,0 +1,84 @@
+// +build cuda
+
+package gorgonia
+
+import (
+	"fmt"
+	"time"
+	"unsafe"
+
+	"github.com/pkg/errors"
+	"gorgonia.org/cu"
+	"gorgonia.org/tensor"
+)
+
+const randomMod = "random"
+
+// CUDADo generates the values on the device with the Philox kernels of random.cu, so that random nodes feeding ops on
+// the device, such as dropout masks, are not generated on the host and copied at every run. If the kernels are not
+// loaded, the values are generated on the host and copied to the device.
+func (op randomOp) CUDADo(extern External, dev Device, prealloc Value, inputs ...Value) (retVal Value, err error) {
+	if err = checkArity(op, len(inputs)); err != nil {
+		return
+	}
+	machine := extern.(CUDAMachine)
+	eng := &machine.Engines()[int(dev)]
+	ctx := machine.Contexts()[int(dev)]
+
+	if prealloc == nil || !prealloc.Shape().Eq(op.shape) || prealloc.Dtype() != op.dt {
+		memsize := calcMemSize(op.dt, op.shape)
+		var mem tensor.Memory
+		if mem, err = extern.Get(dev, memsize); err != nil {
+			return nil, errors.Wrapf(err, "Unable to allocate %v bytes from %v", memsize, dev)
+		}
+		if prealloc, err = makeValueFromMem(op.Type(), op.shape, mem); err != nil {
+			return nil, err
+		}
+	}
+	mem := cu.DevicePtr(prealloc.Uintptr())
+	size := logicalSize(op.shape)
+
+	name := fmt.Sprintf("%v.%v_f%d", randomMod, op.which, int(op.dt.Size())*8)
+	if !eng.HasFunc(name) {
+		cudaLogf("extern does not have func %q", name)
+		extern.Signal()
+		var v Value
+		if v, err = op.Do(); err != nil {
+			return nil, err
+		}
+		ctx.MemcpyHtoD(mem, v.Pointer(), int64(v.MemSize()))
+		return prealloc, nil
+	}
+
+	// every launch draws the counters [offset, offset+size) of the stream of the seed
+	seed := uint64(time.Now().UnixNano())
+	var offset uint64
+	if op.src != nil {
+		op.src.Lock()
+		seed, offset = uint64(op.src.seed), op.src.offset
+		op.src.offset += uint64(size)
+		op.src.Unlock()
+	}
+
+	fn := eng.Functions()[name]
+	args := []unsafe.Pointer{
+		unsafe.Pointer(&mem),
+		unsafe.Pointer(&size),
+		unsafe.Pointer(&seed),
+		unsafe.Pointer(&offset),
+	}
+	a32, b32 := float32(op.a), float32(op.b)
+	switch op.dt {
+	case Float64:
+		args = append(args, unsafe.Pointer(&op.a), unsafe.Pointer(&op.b))
+	case Float32:
+		args = append(args, unsafe.Pointer(&a32), unsafe.Pointer(&b32))
+	default:
+		return nil, errors.Errorf(nyiFail, "randomOp.CUDADo()", op.dt)
+	}
+
+	gridDimX, gridDimY, gridDimZ, blockDimX, blockDimY, blockDimZ := machine.ElemGridSize(size, int(dev))
+	cudaLogf("CUDADO %q, Mem: %v size %v, seed %v offset %v", name, mem, size, seed, offset)
+	ctx.LaunchAndSync(fn, gridDimX, gridDimY, gridDimZ, blockDimX, blockDimY, blockDimZ, 0, cu.NoStream, args)
+	return prealloc, nil
+}
@@ -0,0 +1,52 @@
+// +build cuda
+
+package gorgonia
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorgonia.org/tensor"
+)
+
+func TestCUDARandom(t *testing.T) {
+	defer runtime.GC()
+
+	assert := assert.New(t)
+	g := NewGraph(WithGraphSeed(1))
+	u := Must(RandomUniform(g, Float32, -1, 3, tensor.Shape{64, 16}))
+	b := Must(RandomBernoulli(g, Float64, 0.25, tensor.Shape{1024}))
+	y := Must(Cube(u))
+	var uVal, bVal Value
+	Read(u, &uVal)
+	Read(b, &bVal)
+	Must(Sum(b))
+
+	m := NewTapeMachine(g)
+	defer m.Close()
+	if err := m.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+	first := append([]float32(nil), uVal.Data().([]float32)...)
+	for _, v := range first {
+		if v < -1 || v >= 3 {
+			t.Fatalf("%v is not in [-1, 3)", v)
+		}
+	}
+	var ones float64
+	for _, v := range bVal.Data().([]float64) {
+		if v != 0 && v != 1 {
+			t.Fatalf("%v is not a Bernoulli trial", v)
+		}
+		ones += v
+	}
+	assert.InDelta(256, ones, 60)
+	assert.NotNil(y.Value())
+
+	m.Reset()
+	if err := m.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+	assert.NotEqual(first, uVal.Data().([]float32), "every run draws new numbers")
+}
@@ -0,0 +1,143 @@
+package gorgonia
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorgonia.org/tensor"
+)
+
+func TestRandomNodes(t *testing.T) {
+	assert := assert.New(t)
+	g := NewGraph()
+	n, err := RandomNormal(g, Float64, 2, 0.5, tensor.Shape{1000}, WithRandomSeed(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := RandomUniform(g, Float32, -1, 3, tensor.Shape{10, 100}, WithRandomSeed(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := RandomBernoulli(g, Float64, 0.3, tensor.Shape{1000}, WithRandomSeed(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := RandomNormal(g, Float64, 0, 1, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := Must(Sum(n))
+
+	m := NewTapeMachine(g)
+	defer m.Close()
+	if err = m.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+	first := append([]float64(nil), n.Value().Data().([]float64)...)
+	assert.InDelta(2000, sum.Value().Data().(float64), 100)
+	for _, v := range u.Value().Data().([]float32) {
+		if v < -1 || v >= 3 {
+			t.Fatalf("%v is not in [-1, 3)", v)
+		}
+	}
+	var ones float64
+	for _, v := range b.Value().Data().([]float64) {
+		if v != 0 && v != 1 {
+			t.Fatalf("%v is not a Bernoulli trial", v)
+		}
+		ones += v
+	}
+	assert.InDelta(300, ones, 60)
+	_, ok := s.Value().(*F64)
+	assert.True(ok, "a nil shape is a scalar")
+
+	// the values are drawn again on every run
+	m.Reset()
+	if err = m.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+	assert.NotEqual(first, n.Value().Data().([]float64))
+
+	// the same seed draws the same values
+	h := NewGraph()
+	n2, _ := RandomNormal(h, Float64, 2, 0.5, tensor.Shape{1000}, WithRandomSeed(1))
+	m2 := NewLispMachine(h, ExecuteFwdOnly())
+	defer m2.Close()
+	if err = m2.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(first, n2.Value().Data().([]float64))
+}
+
+func TestWithGraphSeed(t *testing.T) {
+	assert := assert.New(t)
+	draw := func(seed int64) (a, b []float64) {
+		g := NewGraph(WithGraphSeed(seed))
+		x, _ := RandomUniform(g, Float64, 0, 1, tensor.Shape{5})
+		y, _ := RandomUniform(g, Float64, 0, 1, tensor.Shape{5})
+		m := NewTapeMachine(g)
+		defer m.Close()
+		if err := m.RunAll(); err != nil {
+			t.Fatal(err)
+		}
+		return x.Value().Data().([]float64), y.Value().Data().([]float64)
+	}
+	a1, b1 := draw(42)
+	a2, b2 := draw(42)
+	a3, _ := draw(43)
+	assert.Equal(a1, a2)
+	assert.Equal(b1, b2)
+	assert.NotEqual(a1, b1, "every node gets its own seed")
+	assert.NotEqual(a1, a3)
+}
+
+func TestRandomNodes_errors(t *testing.T) {
+	assert := assert.New(t)
+	g := NewGraph()
+	_, err := RandomNormal(g, Float64, 0, -1, nil)
+	assert.Error(err)
+	_, err = RandomUniform(g, Float64, 1, 0, nil)
+	assert.Error(err)
+	_, err = RandomBernoulli(g, Float64, 1.5, nil)
+	assert.Error(err)
+	_, err = RandomBernoulli(g, Int, 0.5, nil)
+	assert.Error(err)
+}
+
+func TestRandomStates(t *testing.T) {
+	assert := assert.New(t)
+	build := func() (*ExprGraph, *Node, *Node) {
+		g := NewGraph()
+		x, _ := RandomNormal(g, Float64, 0, 1, tensor.Shape{5})
+		y, _ := RandomBernoulli(g, Float64, 0.5, tensor.Shape{5})
+		return g, x, y
+	}
+	run := func(g *ExprGraph, x, y *Node) (a, b []float64) {
+		m := NewTapeMachine(g)
+		defer m.Close()
+		if err := m.RunAll(); err != nil {
+			t.Fatal(err)
+		}
+		a = append(a, x.Value().Data().([]float64)...)
+		b = append(b, y.Value().Data().([]float64)...)
+		return a, b
+	}
+
+	g, x, y := build()
+	run(g, x, y)
+	states := g.RandomStates()
+	assert.Equal(2, len(states))
+	assert.True(states[0].Draws >= 5, "a value is drawn from one or more values of the source")
+	a, b := run(g, x, y)
+
+	// the same graph, built again, seeded with another time
+	h, x2, y2 := build()
+	if err := h.SetRandomStates(states); err != nil {
+		t.Fatal(err)
+	}
+	a2, b2 := run(h, x2, y2)
+	assert.Equal(a, a2)
+	assert.Equal(b, b2)
+
+	assert.Error(h.SetRandomStates(states[:1]))
+}
@@ -199,30 +199,25 @@ func (ra *regalloc) allocMutableOp(node *Node, nInterv *interval) {
 	}
 
 	overwrites := node.op.OverwritesInput()
-	var onDev bool
-	switch node.op.(type) {
-	case CUDADoer:
-		onDev = true
-	case CLDoer:
-		onDev = true
-	default:
-	}
+	dev := node.dataOn
+	onDev := dev != CPU
 
 	if overwrites >= 0 {
 		overwriteReg := reads[overwrites].result
 		overwriteDev := overwriteReg.device
 		overwrittenIsLive := reads[overwrites].liveAt(ra.instructionID)
+		overwrittenIsReused := children[overwrites].reuse != nil // never clobber a caller provided buffer
 		compileLogf("Overwrites : %v ", overwrites)
 		compileLogf("Overwritten (%v) is live at %d? %t", reads[overwrites], ra.instructionID, overwrittenIsLive)
 		compileLogf("Let Statements: %d | %v", len(letStmts), reads[overwrites])
 
 		// If the overwritten is not live, and the node does not call external processes (obiviating the need to prealloc)
 		// then we can directly overwrite the register.
-		if len(letStmts) == 1 || !overwrittenIsLive {
+		if (len(letStmts) == 1 || !overwrittenIsLive) && !overwrittenIsReused {
 
 			switch {
-			case onDev && overwriteDev != CPU:
-				// if overwritten reg is on external device and op will execute on external device
+			case onDev && overwriteDev == dev:
+				// if overwritten reg is on the external device the op will execute on
 				// then safe to overwrite
 				writeTo = overwriteReg
 			case !node.op.CallsExtern() && overwriteDev == CPU:
@@ -230,28 +225,17 @@ func (ra *regalloc) allocMutableOp(node *Node, nInterv *interval) {
 				// if the op doesn't call an extern, and is executed on CPU
 				// safe to overwrite
 				writeTo = overwriteReg
-			case onDev:
+			default:
 				// new register otherwise
-				writeTo = ra.newReg(Device(0))
-			case !onDev:
-				// new register otherwise
-				writeTo = ra.newReg(CPU)
+				writeTo = ra.newReg(dev)
 			}
 
 		} else {
-			if onDev {
-				writeTo = ra.newReg(Device(0))
-			} else {
-				writeTo = ra.newReg(CPU)
-			}
+			writeTo = ra.newReg(dev)
 		}
 	} else {
 		compileLogf("New register")
-		if onDev {
-			writeTo = ra.newReg(Device(0))
-		} else {
-			writeTo = ra.newReg(CPU)
-		}
+		writeTo = ra.newReg(dev)
 	}
 
 	for _, r := range reads {
@@ -281,11 +265,7 @@ func (ra *regalloc) allocImmutableOp(node *Node, nInterv *interval) {
 	}
 
 	compileLogf("NodeID: %x does not returns pointer", node.ID())
-	if _, ok := node.op.(CUDADoer); ok {
-		writeTo = ra.newReg(Device(0))
-	} else {
-		writeTo = ra.newReg(CPU)
-	}
+	writeTo = ra.newReg(node.dataOn)
 
 	for _, r := range reads {
 		nInterv.reads = append(nInterv.reads, r.result)
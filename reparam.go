@@ -0,0 +1,360 @@
+package gorgonia
+
+import (
+	"fmt"
+	"hash"
+	"math"
+	"sync"
+
+	"github.com/chewxy/hm"
+	"github.com/pkg/errors"
+	"gorgonia.org/tensor"
+)
+
+/*
+This file holds the reparameterizations of the weights: the nodes that used a weight use a function of new parameters
+instead, so that the solvers train the new parameters without knowing about it.
+
+	WeightNorm (Salimans and Kingma, 2016) splits the weight into a direction V and a norm G: w = G·V/|V|.
+	SpectralNorm (Miyato et al., 2018) divides the weight by its largest singular value, which is estimated by a step of
+	power iteration every time the graph is run. It is the usual way to keep the discriminators of GANs Lipschitz.
+*/
+
+// WeightNorm is the weight normalization of a weight W. The nodes that used W use G·V/|V| instead, where the norms
+// are those of the slices of V along the axis: there is one G for every output unit.
+//
+// V and G are initialized so that the reparameterized weight is the value of W, and W is frozen (see Freeze).
+type WeightNorm struct {
+	W, V, G    *Node
+	Normalized *Node // G·V/|V|
+	Axis       int
+}
+
+// ApplyWeightNorm reparameterizes the weight w, which must have a value. The axis is the axis of the output units: 1
+// for the (inputs, outputs) weights of Mul, 0 for the (out channels, channels, height, width) filters of Conv2d.
+// The names of the new parameters are derived from the name of the weight.
+func ApplyWeightNorm(w *Node, axis int) (*WeightNorm, error) {
+	if err := checkReparam(w); err != nil {
+		return nil, err
+	}
+	s := w.Shape()
+	if axis < 0 || axis >= s.Dims() {
+		return nil, errors.Errorf("Axis %d is out of range for shape %v", axis, s)
+	}
+	data, err := floatData(w.Value())
+	if err != nil {
+		return nil, err
+	}
+	if s.TotalSize() == 0 {
+		return nil, errors.Errorf("Cannot normalize the empty weight %v", w)
+	}
+	inner := 1
+	for _, d := range s[axis+1:] {
+		inner *= d
+	}
+	norms := make([]float64, s[axis])
+	for f, v := range data {
+		norms[(f/inner)%s[axis]] += v * v
+	}
+	for i := range norms {
+		norms[i] = math.Sqrt(norms[i])
+	}
+
+	kept := make(tensor.Shape, s.Dims())
+	var along []int
+	for i := range kept {
+		kept[i] = 1
+		if i != axis {
+			along = append(along, i)
+		}
+	}
+	kept[axis] = s[axis]
+	var vVal, gVal Value
+	if vVal, err = valueLike(w.Value(), append([]float64(nil), data...)); err != nil {
+		return nil, err
+	}
+	if gVal, err = valueLike(tensor.New(tensor.WithShape(kept...), tensor.Of(w.Dtype())), norms); err != nil {
+		return nil, err
+	}
+
+	g := w.g
+	wn := &WeightNorm{W: w, Axis: axis}
+	wn.V = NewTensor(g, w.Dtype(), s.Dims(), WithShape(s.Clone()...), WithName(w.Name()+".weight_v"), WithValue(vVal))
+	wn.G = NewTensor(g, w.Dtype(), s.Dims(), WithShape(kept...), WithName(w.Name()+".weight_g"), WithValue(gVal))
+	if wn.Normalized, err = g.InsertAfter(w, func(*Node) (*Node, error) { return wn.normalize(kept, along) }); err != nil {
+		return nil, err
+	}
+	Freeze(w)
+	return wn, nil
+}
+
+func (wn *WeightNorm) normalize(kept tensor.Shape, along []int) (retVal *Node, err error) {
+	pattern := make([]byte, len(along))
+	for i, a := range along {
+		pattern[i] = byte(a)
+	}
+	var sq, norm, scale *Node
+	if sq, err = Square(wn.V); err != nil {
+		return nil, err
+	}
+	if norm, err = Sum(sq, along...); err != nil {
+		return nil, errors.Wrap(err, operationError)
+	}
+	if norm, err = Sqrt(norm); err != nil {
+		return nil, err
+	}
+	if norm, err = Reshape(norm, kept); err != nil {
+		return nil, err
+	}
+	if scale, err = HadamardDiv(wn.G, norm); err != nil {
+		return nil, errors.Wrap(err, hadamardDivFail)
+	}
+	return BroadcastHadamardProd(wn.V, scale, nil, pattern)
+}
+
+// Learnables returns the parameters of the weight normalization.
+func (wn *WeightNorm) Learnables() Nodes { return Nodes{wn.V, wn.G} }
+
+// SpectralNorm is the spectral normalization of a weight W. The nodes that used W use W/σ(W) instead, where σ(W) is
+// the largest singular value of W seen as a (W.Shape()[0], rest) matrix.
+//
+// σ(W) = uᵀWv is estimated by power iteration: the singular vectors u and v are buffers, which are refined by a
+// number of iterations every time the graph is run, starting from their previous values. As in the paper, u and v are
+// constants of the gradients. W remains the parameter that is trained.
+type SpectralNorm struct {
+	W          *Node
+	Sigma      *Node // the estimate of σ(W)
+	Normalized *Node // W/σ(W)
+	Iterations int
+
+	mu   sync.Mutex
+	u, v []float64
+}
+
+// ApplySpectralNorm reparameterizes the weight w with the given number of power iterations per run (1 is the usual).
+func ApplySpectralNorm(w *Node, iterations int) (*SpectralNorm, error) {
+	if err := checkReparam(w); err != nil {
+		return nil, err
+	}
+	if iterations <= 0 {
+		return nil, errors.Errorf("Expected a positive number of power iterations. Got %d instead", iterations)
+	}
+	if w.Dims() < 2 {
+		return nil, errors.Errorf("Expected a weight of at least 2 dimensions. Got %v instead", w.Shape())
+	}
+	rows := w.Shape()[0]
+	sn := &SpectralNorm{W: w, Iterations: iterations, u: Gaussian64(0, 1, rows)}
+	normalizeF64s(sn.u)
+
+	var err error
+	if sn.Normalized, err = w.g.InsertAfter(w, func(w *Node) (retVal *Node, err error) {
+		if sn.Sigma, err = ApplyOp(spectralNormOp{sn}, w); err != nil {
+			return nil, err
+		}
+		return HadamardDiv(w, sn.Sigma)
+	}); err != nil {
+		return nil, err
+	}
+	return sn, nil
+}
+
+// U returns a copy of the current estimate of the left singular vector.
+func (sn *SpectralNorm) U() []float64 {
+	sn.mu.Lock()
+	defer sn.mu.Unlock()
+	return append([]float64(nil), sn.u...)
+}
+
+// SetU sets the estimate of the left singular vector, such as one saved with a checkpoint of W.
+func (sn *SpectralNorm) SetU(u []float64) error {
+	sn.mu.Lock()
+	defer sn.mu.Unlock()
+	if len(u) != len(sn.u) {
+		return errors.Errorf("Expected %d elements. Got %d instead", len(sn.u), len(u))
+	}
+	copy(sn.u, u)
+	return nil
+}
+
+// estimate refines u and v with the power iterations on the (rows, cols) matrix w, and returns σ(w) = uᵀwv.
+func (sn *SpectralNorm) estimate(w []float64) float64 {
+	sn.mu.Lock()
+	defer sn.mu.Unlock()
+	rows := len(sn.u)
+	cols := len(w) / rows
+	if len(sn.v) != cols {
+		sn.v = make([]float64, cols)
+	}
+	wv := make([]float64, rows)
+	for it := 0; it < sn.Iterations; it++ {
+		// v = wᵀu/|wᵀu|, u = wv/|wv|
+		for j := range sn.v {
+			sn.v[j] = 0
+		}
+		for i, ui := range sn.u {
+			for j, wij := range w[i*cols : (i+1)*cols] {
+				sn.v[j] += wij * ui
+			}
+		}
+		normalizeF64s(sn.v)
+		for i := range wv {
+			wv[i] = dot(w[i*cols:(i+1)*cols], sn.v)
+		}
+		copy(sn.u, wv)
+		normalizeF64s(sn.u)
+	}
+	return dot(sn.u, wv)
+}
+
+// outer returns u·vᵀ, the gradient of σ(w) with regards to w.
+func (sn *SpectralNorm) outer() []float64 {
+	sn.mu.Lock()
+	defer sn.mu.Unlock()
+	retVal := make([]float64, 0, len(sn.u)*len(sn.v))
+	for _, ui := range sn.u {
+		for _, vj := range sn.v {
+			retVal = append(retVal, ui*vj)
+		}
+	}
+	return retVal
+}
+
+func normalizeF64s(a []float64) {
+	norm := math.Sqrt(dot(a, a))
+	if norm == 0 {
+		return
+	}
+	for i := range a {
+		a[i] /= norm
+	}
+}
+
+func checkReparam(w *Node) error {
+	if w == nil || w.g == nil {
+		return errors.New("Cannot reparameterize a node that is not in a graph")
+	}
+	if !w.isInput() || w.Value() == nil {
+		return errors.Errorf("Cannot reparameterize %v: expected an input with a value", w)
+	}
+	return errors.Wrapf(checkFloatNode(w), "Cannot reparameterize %v", w)
+}
+
+// spectralNormOp estimates the largest singular value of its input, refining the power iterations of a SpectralNorm.
+type spectralNormOp struct{ sn *SpectralNorm }
+
+func (op spectralNormOp) Arity() int { return 1 }
+
+// spectralNormOp has this type:
+//		spectralNormOp :: (Floats a) ⇒ Tensor-d a → a
+func (op spectralNormOp) Type() hm.Type {
+	a := hm.TypeVariable('a')
+	return hm.NewFnType(makeTensorType(op.sn.W.Dims(), a), a)
+}
+
+func (op spectralNormOp) InferShape(inputs ...DimSizer) (tensor.Shape, error) {
+	if err := checkArity(op, len(inputs)); err != nil {
+		return nil, err
+	}
+	return scalarShape, nil
+}
+
+func (op spectralNormOp) Do(inputs ...Value) (retVal Value, err error) {
+	if err = checkArity(op, len(inputs)); err != nil {
+		return
+	}
+	var w []float64
+	if w, err = floatData(inputs[0]); err != nil {
+		return nil, errors.Wrap(err, opDoFail)
+	}
+	if len(w) != op.sn.W.Shape().TotalSize() {
+		return nil, errors.Errorf("Expected %v to have the shape %v. Got %v instead", op, op.sn.W.Shape(), inputs[0].Shape())
+	}
+	sigma := op.sn.estimate(w)
+	switch inputs[0].Dtype() {
+	case tensor.Float64:
+		return newF64(sigma), nil
+	case tensor.Float32:
+		return newF32(float32(sigma)), nil
+	}
+	return nil, errors.Errorf(nyiFail, op, inputs[0].Dtype())
+}
+
+func (op spectralNormOp) ReturnsPtr() bool     { return false }
+func (op spectralNormOp) CallsExtern() bool    { return false }
+func (op spectralNormOp) OverwritesInput() int { return -1 }
+
+func (op spectralNormOp) WriteHash(h hash.Hash) { fmt.Fprintf(h, "SpectralNorm(%p)", op.sn) }
+
+func (op spectralNormOp) Hashcode() uint32 { return simpleHash(op) }
+
+func (op spectralNormOp) String() string { return "SpectralNorm" }
+
+func (op spectralNormOp) DiffWRT(inputs int) []bool { return []bool{true} }
+
+func (op spectralNormOp) SymDiff(inputs Nodes, output, grad *Node) (retVal Nodes, err error) {
+	if err = checkArity(op, len(inputs)); err != nil {
+		return
+	}
+	var ret *Node
+	if ret, err = ApplyOp(spectralNormDiffOp{op}, inputs[0], grad); err != nil {
+		return nil, errors.Wrap(err, operationError)
+	}
+	return Nodes{ret}, nil
+}
+
+// spectralNormDiffOp is the derivative of a spectralNormOp: the gradient times u·vᵀ, with u and v of the last run.
+type spectralNormDiffOp struct{ spectralNormOp }
+
+func (op spectralNormDiffOp) Arity() int { return 2 }
+
+// spectralNormDiffOp has this type:
+//		spectralNormDiffOp :: (Floats a) ⇒ Tensor-d a → a → Tensor-d a
+func (op spectralNormDiffOp) Type() hm.Type {
+	a := hm.TypeVariable('a')
+	t := makeTensorType(op.sn.W.Dims(), a)
+	return hm.NewFnType(t, a, t)
+}
+
+func (op spectralNormDiffOp) InferShape(inputs ...DimSizer) (tensor.Shape, error) {
+	if err := checkArity(op, len(inputs)); err != nil {
+		return nil, err
+	}
+	s, ok := inputs[0].(tensor.Shape)
+	if !ok {
+		return nil, errors.Errorf("Expected a shape. Got %v of %T instead", inputs[0], inputs[0])
+	}
+	return s.Clone(), nil
+}
+
+func (op spectralNormDiffOp) Do(inputs ...Value) (retVal Value, err error) {
+	if err = checkArity(op, len(inputs)); err != nil {
+		return
+	}
+	var g []float64
+	if g, err = floatData(inputs[1]); err != nil {
+		return nil, errors.Wrap(err, opDoFail)
+	}
+	if len(g) != 1 {
+		return nil, errors.Errorf("%v expects a scalar gradient. Got %v instead", op, inputs[1].Shape())
+	}
+	data := op.sn.outer()
+	if len(data) != inputs[0].Shape().TotalSize() {
+		return nil, errors.Errorf("%v has no estimate of the singular vectors of %v. Was the forward pass run?", op, inputs[0].Shape())
+	}
+	for i := range data {
+		data[i] *= g[0]
+	}
+	return valueLike(inputs[0], data)
+}
+
+func (op spectralNormDiffOp) WriteHash(h hash.Hash) { fmt.Fprintf(h, "SpectralNormDiff(%p)", op.sn) }
+
+func (op spectralNormDiffOp) Hashcode() uint32 { return simpleHash(op) }
+
+func (op spectralNormDiffOp) String() string { return "SpectralNorm'" }
+
+func (op spectralNormDiffOp) DiffWRT(inputs int) []bool { return []bool{false, false} }
+
+func (op spectralNormDiffOp) SymDiff(inputs Nodes, output, grad *Node) (retVal Nodes, err error) {
+	return nil, nondiffErr(op)
+}
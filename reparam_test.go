@@ -0,0 +1,137 @@
+package gorgonia
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorgonia.org/tensor"
+)
+
+func TestApplyWeightNorm(t *testing.T) {
+	assert := assert.New(t)
+	g := NewGraph()
+	x := NewMatrix(g, Float64, WithShape(2, 3), WithName("x"), WithInit(RangedFrom(0)))
+	w := NewMatrix(g, Float64, WithShape(3, 2), WithName("w"), WithValue(tensor.New(tensor.WithShape(3, 2), tensor.WithBacking([]float64{3, 1, 0, 2, 4, 2}))))
+	y := Must(Mul(x, w))
+	cost := Must(Sum(y))
+
+	wn, err := ApplyWeightNorm(w, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal("w.weight_v", wn.V.Name())
+	assert.Equal(tensor.Shape{1, 2}, wn.G.Shape())
+	assert.Equal([]float64{5, 3}, wn.G.Value().Data())
+	assert.Equal(Nodes{x, wn.V, wn.G}, g.Learnables())
+	assert.Equal(wn.Normalized, y.children[1])
+
+	var wv Value
+	Read(wn.Normalized, &wv)
+	if _, err = Grad(cost, wn.Learnables()...); err != nil {
+		t.Fatal(err)
+	}
+	m := NewTapeMachine(g)
+	defer m.Close()
+	if err = m.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+	assert.InDeltaSlice(w.Value().Data(), wv.Data(), 1e-12)
+	// sum(x×w) = (3, 5, 7)·w·(1, 1)
+	assert.InDelta(64.0, cost.Value().Data(), 1e-12)
+
+	// the projections of the gradient of w, (3, 5, 7) in every column, on the columns, divided by the norms
+	gGrad, err := wn.G.Grad()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.InDeltaSlice([]float64{37.0 / 5, 27.0 / 3}, gGrad.Data(), 1e-12)
+	vGrad, err := wn.V.Grad()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(tensor.Shape{3, 2}, vGrad.Shape())
+
+	solver := NewVanillaSolver(WithLearnRate(0.1))
+	if err = solver.Step(NodesToValueGrads(wn.Learnables())); err != nil {
+		t.Fatal(err)
+	}
+	assert.InDeltaSlice([]float64{5 - 0.74, 3 - 0.9}, wn.G.Value().Data(), 1e-12)
+
+	// errors
+	_, err = ApplyWeightNorm(w, 2)
+	assert.Error(err)
+	z := NewMatrix(g, Float64, WithShape(3, 2), WithName("z"))
+	_, err = ApplyWeightNorm(z, 0)
+	assert.Error(err, "z has no value")
+}
+
+func TestApplySpectralNorm(t *testing.T) {
+	assert := assert.New(t)
+	g := NewGraph()
+	// the singular values of w are 5 and 3
+	w := NewMatrix(g, Float64, WithShape(2, 3), WithName("w"), WithValue(tensor.New(tensor.WithShape(2, 3), tensor.WithBacking([]float64{0, 3, 0, 5, 0, 0}))))
+	x := NewVector(g, Float64, WithShape(3), WithName("x"), WithInit(RangedFrom(1)))
+	cost := Must(Sum(Must(Mul(w, x))))
+
+	sn, err := ApplySpectralNorm(w, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(Nodes{w, x}, g.Learnables())
+	if err = sn.SetU([]float64{1, 1}); err != nil {
+		t.Fatal(err)
+	}
+	assert.Error(sn.SetU([]float64{1}))
+
+	var normalized Value
+	Read(sn.Normalized, &normalized)
+	if _, err = Grad(cost, w); err != nil {
+		t.Fatal(err)
+	}
+	m := NewTapeMachine(g)
+	defer m.Close()
+	if err = m.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+	assert.InDelta(5.0, sn.Sigma.Value().Data(), 1e-6)
+	assert.InDeltaSlice([]float64{0, 1, 0}, sn.U(), 1e-6)
+	assert.InDeltaSlice([]float64{0, 0.6, 0, 1, 0, 0}, normalized.Data(), 1e-6)
+
+	// d/dw sum((w/σ)x) = (1ᵀ ⊗ x)/σ - sum(wx)/σ² u vᵀ, with u = (0, 1) and v = (1, 0, 0)
+	wGrad, err := w.Grad()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.InDeltaSlice([]float64{0.2, 0.4, 0.6, 0.2 - 11.0/25, 0.4, 0.6}, wGrad.Data(), 1e-6)
+
+	// errors
+	_, err = ApplySpectralNorm(w, 0)
+	assert.Error(err)
+	v := NewVector(g, Float64, WithShape(3), WithName("v"), WithInit(Zeroes()))
+	_, err = ApplySpectralNorm(v, 1)
+	assert.Error(err)
+}
+
+func TestSpectralNormOp(t *testing.T) {
+	sn := &SpectralNorm{Iterations: 50, u: []float64{0.6, 0.8}}
+	g := NewGraph()
+	sn.W = NewMatrix(g, Float64, WithShape(2, 2), WithName("w"))
+	op := spectralNormOp{sn}
+	// symmetric, with the eigenvalues 3 and 1
+	w := tensor.New(tensor.WithShape(2, 2), tensor.WithBacking([]float64{2, 1, 1, 2}))
+	sigma, err := op.Do(w)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.InDelta(t, 3.0, sigma.Data(), 1e-9)
+	u := sn.U()
+	assert.InDelta(t, math.Sqrt(0.5), u[0], 1e-9)
+	assert.InDelta(t, math.Sqrt(0.5), u[1], 1e-9)
+
+	grad, err := spectralNormDiffOp{op}.Do(w, newF64(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.InDeltaSlice(t, []float64{1, 1, 1, 1}, grad.Data(), 1e-9)
+}
@@ -0,0 +1,55 @@
+package gorgonia
+
+import "strings"
+
+// ScopeSep separates the levels of a naming scope.
+const ScopeSep = "/"
+
+// Scope enters a naming scope. Until the returned function is called, the names of the input nodes created in the
+// graph (including the learnables) are prefixed with the scope. Scopes nest:
+//		leave := g.Scope("encoder")
+//		l1 := g.Scope("layer1")
+//		w := NewMatrix(g, Float64, WithShape(3, 3), WithName("w")) // w is named "encoder/layer1/w"
+//		l1()
+//		leave()
+// which is more conveniently written with defer in functions that build a part of a model:
+//		defer g.Scope("encoder/layer1")()
+//
+// Names given with ApplyOpWithName are prefixed as well. Autogenerated names are not.
+func (g *ExprGraph) Scope(name string) (leave func()) {
+	prev := g.scope
+	if name = strings.Trim(name, ScopeSep); name != "" {
+		g.scope = joinScope(g.scope, name)
+	}
+	return func() { g.scope = prev }
+}
+
+// CurrentScope returns the current naming scope of the graph. It returns "" if no scope has been entered.
+func (g *ExprGraph) CurrentScope() string { return g.scope }
+
+// ByScope returns the nodes whose names are within the scope, including those within nested scopes.
+// For example, "encoder/layer1/w" is within both "encoder" and "encoder/layer1", but not "enc".
+func (g *ExprGraph) ByScope(scope string) (retVal Nodes) {
+	prefix := strings.Trim(scope, ScopeSep) + ScopeSep
+	for _, n := range g.all {
+		if strings.HasPrefix(n.name, prefix) {
+			retVal = append(retVal, n)
+		}
+	}
+	return
+}
+
+// scopedName returns the name prefixed with the current scope. Names that already are in the current scope are left as is.
+func (g *ExprGraph) scopedName(name string) string {
+	if g.scope == "" || name == "" || strings.HasPrefix(name, g.scope+ScopeSep) {
+		return name
+	}
+	return joinScope(g.scope, name)
+}
+
+func joinScope(scope, name string) string {
+	if scope == "" {
+		return name
+	}
+	return scope + ScopeSep + name
+}
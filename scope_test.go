@@ -0,0 +1,54 @@
+package gorgonia
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExprGraph_Scope(t *testing.T) {
+	assert := assert.New(t)
+	g := NewGraph()
+
+	x := NewMatrix(g, Float64, WithShape(2, 3), WithName("x"))
+	leave := g.Scope("encoder")
+	assert.Equal("encoder", g.CurrentScope())
+	l1 := g.Scope("/layer1/")
+	assert.Equal("encoder/layer1", g.CurrentScope())
+	w1 := NewMatrix(g, Float64, WithShape(3, 3), WithName("w"))
+	xw1 := Must(Mul(x, w1))
+	l1()
+
+	func() {
+		defer g.Scope("layer2")()
+		w2 := NewMatrix(g, Float64, WithShape(3, 3), WithName("w"))
+		assert.Equal("encoder/layer2/w", w2.Name())
+		h, err := ApplyOpWithName(newElemUnaryOp(tanhOpType, xw1), "h", xw1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal("encoder/layer2/h", h.Name())
+	}()
+	assert.Equal("encoder", g.CurrentScope())
+	leave()
+	assert.Equal("", g.CurrentScope())
+
+	// scopes do not affect unscoped names, nor autogenerated ones
+	assert.Equal("x", x.Name())
+	assert.Equal("encoder/layer1/w", w1.Name())
+	assert.NotContains(xw1.Name(), "encoder")
+
+	// the same name in different scopes does not clash, and re-entering a scope finds its nodes again
+	w := NewMatrix(g, Float64, WithShape(3, 3), WithName("w"))
+	assert.NotEqual(w1, w)
+	defer g.Scope("encoder/layer1")()
+	assert.Equal(w1, NewMatrix(g, Float64, WithShape(3, 3), WithName("w")))
+	assert.Equal(w1, NewMatrix(g, Float64, WithShape(3, 3), WithName("encoder/layer1/w")))
+
+	assert.Equal(3, len(g.ByScope("encoder")))
+	assert.Equal(2, len(g.ByScope("encoder/layer2")))
+	assert.Equal(Nodes{w1}, g.ByScope("encoder/layer1/"))
+	assert.Equal(0, len(g.ByScope("enc")))
+
+	assert.Equal("encoder/layer1", g.Clone().(*ExprGraph).CurrentScope())
+}
@@ -1,10 +1,12 @@
 package gorgonia
 
 import (
+	"fmt"
 	"math"
 
 	"github.com/chewxy/math32"
 	"github.com/pkg/errors"
+	"gorgonia.org/gorgonia/internal/logging"
 	"gorgonia.org/tensor"
 )
 
@@ -250,6 +252,7 @@ func NewRMSPropSolver(opts ...SolverOpt) *RMSPropSolver {
 //
 // This function will error out if the nodes do not have an associated Grad value.
 func (s *RMSPropSolver) Step(model []ValueGrad) (err error) {
+	logStep(s, model)
 	if s.cache == nil {
 		s.cache = make([]*dualValue, len(model))
 	}
@@ -438,6 +441,7 @@ func NewAdamSolver(opts ...SolverOpt) *AdamSolver {
 //
 // This function will error out if the nodes do not have an associated Grad value.
 func (s *AdamSolver) Step(model []ValueGrad) (err error) {
+	logStep(s, model)
 	if s.cache == nil {
 		s.cache = make([]*dualValue, len(model))
 	}
@@ -757,6 +761,7 @@ func NewVanillaSolver(opts ...SolverOpt) *VanillaSolver {
 //
 // This function will error out if the nodes do not have an associated Grad value.
 func (s *VanillaSolver) Step(model []ValueGrad) (err error) {
+	logStep(s, model)
 	for _, n := range model {
 		var weights, grad Value
 		if weights, grad, err = extractWeightGrad(n); err != nil {
@@ -952,6 +957,7 @@ func NewMomentum(opts ...SolverOpt) *Momentum {
 //
 // This function will error out if the nodes do not have an associated Grad value.
 func (s *Momentum) Step(model []ValueGrad) (err error) {
+	logStep(s, model)
 	if s.cache == nil {
 		s.cache = make([]*dualValue, len(model))
 	}
@@ -1180,6 +1186,7 @@ func NewAdaGradSolver(opts ...SolverOpt) *AdaGradSolver {
 //
 // This function will error out if the nodes do not have an associated Grad value.
 func (s *AdaGradSolver) Step(model []ValueGrad) (err error) {
+	logStep(s, model)
 	if s.cache == nil {
 		s.cache = make([]*dualValue, len(model))
 	}
@@ -1385,6 +1392,7 @@ func NewBarzilaiBorweinSolver(opts ...SolverOpt) *BarzilaiBorweinSolver {
 //
 // This function will error out if the nodes do not have an associated Grad value.
 func (s *BarzilaiBorweinSolver) Step(model []ValueGrad) (err error) {
+	logStep(s, model)
 
 	firstRun := false
 	if s.prevDV == nil {
@@ -1526,3 +1534,10 @@ func (s *BarzilaiBorweinSolver) Step(model []ValueGrad) (err error) {
 
 	return nil
 }
+
+// logStep logs a step of a solver, at the debug level.
+func logStep(s Solver, model []ValueGrad) {
+	if logging.Enabled(logging.Solver, logging.Debug) {
+		logging.Log(logging.Solver, logging.Debug, "step", "solver", fmt.Sprintf("%T", s), "params", len(model))
+	}
+}
@@ -0,0 +1,169 @@
+package gorgonia
+
+import (
+	"bytes"
+	"encoding/gob"
+
+	"github.com/pkg/errors"
+)
+
+// solverState is the state a solver accumulates over its steps: the number of steps, and the caches of the elements of
+// the model, in the ProtoEncoding of MarshalValue. The nil caches and values are empty.
+type solverState struct {
+	Iter   int
+	Values [][]byte
+	Derivs [][]byte
+	Eta    float64 // the learn rate a BarzilaiBorweinSolver computed at its last step
+	Steps  int     // the steps accounted for by the privacy accountant of a DPSGDSolver
+	Inner  []byte
+}
+
+// MarshalSolver serializes the state that a solver of this package accumulates over its steps, such as the moments of
+// an AdamSolver, so that the training goes on as it would have after UnmarshalSolver. The options of the solver, such
+// as its learn rate, are not serialized, except the learn rate that a BarzilaiBorweinSolver computes at every step. The source of the noise of a DPSGDSolver is not serialized either.
+func MarshalSolver(s Solver) ([]byte, error) {
+	var st solverState
+	var err error
+	switch s := s.(type) {
+	case *RMSPropSolver:
+		err = st.setCache(s.cache)
+	case *AdamSolver:
+		st.Iter = s.iter
+		err = st.setCache(s.cache)
+	case *VanillaSolver:
+	case *Momentum:
+		err = st.setCache(s.cache)
+	case *AdaGradSolver:
+		err = st.setCache(s.cache)
+	case *BarzilaiBorweinSolver:
+		st.Eta = s.eta
+		err = st.setCache(s.prevDV)
+	case *DPSGDSolver:
+		st.Steps = s.accountant.steps
+		st.Inner, err = MarshalSolver(s.Solver)
+	default:
+		return nil, errors.Errorf(nyiFail, "MarshalSolver", s)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err = gob.NewEncoder(&buf).Encode(st); err != nil {
+		return nil, errors.Wrap(err, "Failed to encode the state of the solver")
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalSolver restores the state serialized by MarshalSolver into a solver of the same type. Nothing is restored
+// if the state cannot be decoded.
+func UnmarshalSolver(s Solver, p []byte) error {
+	restore, err := unmarshalSolver(s, p)
+	if err != nil {
+		return err
+	}
+	restore()
+	return nil
+}
+
+// unmarshalSolver decodes the state of the solver, and returns the function that restores it into the solver.
+func unmarshalSolver(s Solver, p []byte) (restore func(), err error) {
+	var st solverState
+	if err = gob.NewDecoder(bytes.NewReader(p)).Decode(&st); err != nil {
+		return nil, errors.Wrap(err, "Failed to decode the state of the solver")
+	}
+	var cache []*dualValue
+	switch s.(type) {
+	case *RMSPropSolver, *AdamSolver, *Momentum, *AdaGradSolver, *BarzilaiBorweinSolver:
+		if cache, err = st.cache(); err != nil {
+			return nil, err
+		}
+	}
+	switch s := s.(type) {
+	case *RMSPropSolver:
+		return func() { s.cache = cache }, nil
+	case *AdamSolver:
+		return func() { s.iter, s.cache = st.Iter, cache }, nil
+	case *VanillaSolver:
+		return func() {}, nil
+	case *Momentum:
+		return func() { s.cache = cache }, nil
+	case *AdaGradSolver:
+		return func() { s.cache = cache }, nil
+	case *BarzilaiBorweinSolver:
+		return func() {
+			if s.prevDV = cache; cache != nil {
+				s.eta = st.Eta
+			}
+		}, nil
+	case *DPSGDSolver:
+		if st.Inner == nil {
+			return nil, errors.New("The state is not that of a DPSGDSolver")
+		}
+		var inner func()
+		if inner, err = unmarshalSolver(s.Solver, st.Inner); err != nil {
+			return nil, err
+		}
+		return func() {
+			s.accountant.steps = st.Steps
+			inner()
+		}, nil
+	}
+	return nil, errors.Errorf(nyiFail, "UnmarshalSolver", s)
+}
+
+func (st *solverState) setCache(cache []*dualValue) (err error) {
+	st.Values = make([][]byte, len(cache))
+	st.Derivs = make([][]byte, len(cache))
+	for i, dv := range cache {
+		if dv == nil {
+			continue
+		}
+		if st.Values[i], err = marshalCached(dv.Value); err != nil {
+			return err
+		}
+		if st.Derivs[i], err = marshalCached(dv.d); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cache returns the cache of the state. A solver that has not stepped has no cache.
+func (st *solverState) cache() ([]*dualValue, error) {
+	if st.Values == nil {
+		return nil, nil
+	}
+	if len(st.Derivs) != len(st.Values) {
+		return nil, errors.Errorf("The state of the solver has %d values and %d derivatives", len(st.Values), len(st.Derivs))
+	}
+	retVal := make([]*dualValue, len(st.Values))
+	for i := range st.Values {
+		if len(st.Values[i]) == 0 && len(st.Derivs[i]) == 0 {
+			continue
+		}
+		dv := new(dualValue)
+		var err error
+		if dv.Value, err = unmarshalCached(st.Values[i]); err != nil {
+			return nil, errors.Wrapf(err, "Element %d", i)
+		}
+		if dv.d, err = unmarshalCached(st.Derivs[i]); err != nil {
+			return nil, errors.Wrapf(err, "Element %d", i)
+		}
+		retVal[i] = dv
+	}
+	return retVal, nil
+}
+
+func marshalCached(v Value) ([]byte, error) {
+	if v == nil {
+		return nil, nil
+	}
+	return MarshalValue(v, ProtoEncoding)
+}
+
+func unmarshalCached(p []byte) (Value, error) {
+	if len(p) == 0 {
+		return nil, nil
+	}
+	return UnmarshalValue(p, ProtoEncoding)
+}
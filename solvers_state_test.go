@@ -0,0 +1,120 @@
+package gorgonia
+
+import (
+	"bytes"
+	"encoding/gob"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorgonia.org/tensor"
+)
+
+type nopSolver struct{}
+
+func (nopSolver) Step([]ValueGrad) error { return nil }
+
+func TestMarshalSolver(t *testing.T) {
+	assert := assert.New(t)
+	solvers := map[string]func() Solver{
+		"rmsprop":  func() Solver { return NewRMSPropSolver(WithLearnRate(0.01)) },
+		"adam":     func() Solver { return NewAdamSolver(WithLearnRate(0.01)) },
+		"vanilla":  func() Solver { return NewVanillaSolver(WithLearnRate(0.01)) },
+		"momentum": func() Solver { return NewMomentum(WithLearnRate(0.01)) },
+		"adagrad":  func() Solver { return NewAdaGradSolver(WithLearnRate(0.01)) },
+		"barzilai": func() Solver { return NewBarzilaiBorweinSolver(WithLearnRate(0.01)) },
+	}
+	// the gradients change at every step, as the Barzilai-Borwein step size is undefined otherwise
+	step := func(s Solver, model []ValueGrad, i int) error {
+		grad, _ := model[0].Grad()
+		for j, d := range []float64{0.5, -10, 10, 0.5} {
+			grad.(*tensor.Dense).Set(j, d/float64(i+1))
+		}
+		return s.Step(model)
+	}
+	for name, newSolver := range solvers {
+		model := tf64Node()
+		s := newSolver()
+		for i := 0; i < 2; i++ {
+			if err := step(s, model, i); err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+		}
+		p, err := MarshalSolver(s)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		// a new solver of a new model, restored to the state of the first
+		restored := tf64Node()
+		if _, err = Copy(restored[0].Value(), model[0].Value()); err != nil {
+			t.Fatal(err)
+		}
+		r := newSolver()
+		if err = UnmarshalSolver(r, p); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		if err = step(s, model, 2); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if err = step(r, restored, 2); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		assert.Equal(model[0].Value().Data(), restored[0].Value().Data(), name)
+	}
+
+	// the state of a solver that has not stepped
+	p, err := MarshalSolver(NewAdamSolver())
+	if err != nil {
+		t.Fatal(err)
+	}
+	adam := NewAdamSolver()
+	assert.NoError(UnmarshalSolver(adam, p))
+	assert.Nil(adam.cache)
+
+	_, err = MarshalSolver(nopSolver{})
+	assert.Error(err)
+	assert.Error(UnmarshalSolver(NewAdamSolver(), []byte("garbage")))
+}
+
+func TestMarshalSolver_DPSGD(t *testing.T) {
+	assert := assert.New(t)
+	s, err := NewDPSGDSolver(NewAdamSolver(), 1, 1, 0.01)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.accountant.steps = 7
+	s.Solver.(*AdamSolver).iter = 3
+	s.Solver.(*AdamSolver).cache = []*dualValue{{Value: tensor.New(tensor.WithBacking([]float64{1, 2})), d: tensor.New(tensor.WithBacking([]float64{3, 4}))}}
+	p, err := MarshalSolver(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, _ := NewDPSGDSolver(NewAdamSolver(), 1, 1, 0.01)
+	if err = UnmarshalSolver(r, p); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(7, r.accountant.steps)
+	adam := r.Solver.(*AdamSolver)
+	assert.Equal(3, adam.iter)
+	if assert.Equal(1, len(adam.cache)) {
+		assert.Equal([]float64{1, 2}, adam.cache[0].Value.Data())
+		assert.Equal([]float64{3, 4}, adam.cache[0].d.Data())
+	}
+
+	// the state of the solver it wraps is not that of a DPSGDSolver
+	inner, _ := MarshalSolver(NewAdamSolver())
+	assert.Error(UnmarshalSolver(r, inner))
+
+	// nothing is restored from a state that fails to decode
+	var buf bytes.Buffer
+	gob.NewEncoder(&buf).Encode(solverState{Iter: 9, Values: [][]byte{[]byte("garbage")}, Derivs: [][]byte{nil}})
+	inner = append([]byte{}, buf.Bytes()...)
+	buf.Reset()
+	gob.NewEncoder(&buf).Encode(solverState{Steps: 11, Inner: inner})
+	assert.Error(UnmarshalSolver(r, buf.Bytes()))
+	assert.Equal(7, r.accountant.steps)
+	assert.Equal(3, adam.iter)
+	assert.Equal(1, len(adam.cache))
+}
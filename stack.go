@@ -0,0 +1,102 @@
+package gorgonia
+
+import (
+	"bytes"
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+/*
+This file holds the capture of the construction sites of the nodes. A graph created with WithConstructionStacks records
+where every one of its nodes was constructed, so that the errors of programmatically built graphs point to the code
+that built the failing node:
+
+		g := gorgonia.NewGraph(gorgonia.WithConstructionStacks())
+
+The shape and type errors of the ops applied in the graph then end with the stack of the failed application, and the
+runtime errors of the machines end with the stack of the node that failed to execute. Only the frames of the callers of
+the package are kept, and at most maxStackFrames of them.
+*/
+
+const (
+	maxStackDepth  = 32 // the number of program counters recorded
+	maxStackFrames = 8  // the number of frames formatted
+)
+
+// WithConstructionStacks is a ExprGraph construction option that records where every node of the graph is
+// constructed. See (*Node).ConstructionStack.
+func WithConstructionStacks() graphconopt {
+	f := func(g *ExprGraph) {
+		g.stacks = true
+	}
+	return f
+}
+
+// recordsStacks returns true if the nodes of the graph record their construction.
+func (g *ExprGraph) recordsStacks() bool { return g.stacks || detectAnomaly() }
+
+// callers returns the program counters of the callers of the function that calls it.
+func callers() []uintptr {
+	pcs := make([]uintptr, maxStackDepth)
+	return pcs[:runtime.Callers(3, pcs)]
+}
+
+// ConstructionStack returns where the node was constructed, as one "function\n\tfile:line" frame per line, from the
+// caller of the function of the package that constructed it. It returns "" if the node did not record it.
+func (n *Node) ConstructionStack() string { return formatStack(n.stack) }
+
+// formatStack formats the frames of the program counters, skipping the frames of the package itself (but not of its
+// tests) and stopping at the frames of the runtime and of the testing package.
+func formatStack(pcs []uintptr) string {
+	if len(pcs) == 0 {
+		return ""
+	}
+	var buf bytes.Buffer
+	frames := runtime.CallersFrames(pcs)
+	pkg := true
+	for n := 0; n < maxStackFrames; {
+		f, more := frames.Next()
+		internal := strings.HasPrefix(f.Function, "gorgonia.org/gorgonia.") && !strings.HasSuffix(f.File, "_test.go")
+		switch {
+		case strings.HasPrefix(f.Function, "runtime.") || strings.HasPrefix(f.Function, "testing."):
+			more = false
+		case pkg && internal:
+		default:
+			pkg = false
+			fmt.Fprintf(&buf, "%s\n\t%s:%d\n", f.Function, f.File, f.Line)
+			n++
+		}
+		if !more {
+			break
+		}
+	}
+	return buf.String()
+}
+
+// stackError is an error followed by where the node that caused it was constructed.
+type stackError struct {
+	error
+	stack string
+}
+
+func (err stackError) Error() string { return err.error.Error() + "\nconstructed at:\n" + err.stack }
+
+// Cause returns the error, for errors.Cause.
+func (err stackError) Cause() error { return err.error }
+
+// withStack appends where the node was constructed to the error, if the node recorded it.
+func withStack(err error, n *Node) error {
+	if err == nil || n == nil || len(n.stack) == 0 {
+		return err
+	}
+	return stackError{err, formatStack(n.stack)}
+}
+
+// applyStack appends where an op failed to be applied to the error, if the graph records the construction stacks.
+func (g *ExprGraph) applyStack(err error) error {
+	if !g.recordsStacks() {
+		return err
+	}
+	return stackError{err, formatStack(callers())}
+}
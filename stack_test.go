@@ -0,0 +1,41 @@
+package gorgonia
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorgonia.org/tensor"
+)
+
+func TestConstructionStacks(t *testing.T) {
+	assert := assert.New(t)
+	g := NewGraph(WithConstructionStacks())
+	x := NewMatrix(g, Float64, WithShape(2, 3), WithName("x"))
+	w := NewMatrix(g, Float64, WithShape(3, 2), WithName("w"), WithInit(Ones()))
+	xw := Must(Mul(x, w))
+	assert.Contains(xw.ConstructionStack(), "stack_test.go:15")
+	assert.Contains(xw.ConstructionStack(), "TestConstructionStacks")
+	assert.NotContains(xw.ConstructionStack(), "op.go")
+
+	// the shape errors end with the failed application
+	_, err := Mul(x, x)
+	if assert.Error(err) {
+		assert.Contains(err.Error(), "stack_test.go:21")
+	}
+
+	// the runtime errors end with the construction of the failing node
+	Let(x, tensor.New(tensor.WithShape(2, 2), tensor.WithBacking(make([]float64, 4))))
+	m := NewLispMachine(g, ExecuteFwdOnly())
+	defer m.Close()
+	err = m.RunAll()
+	if assert.Error(err) {
+		assert.Contains(err.Error(), "stack_test.go:15")
+	}
+
+	// without the option, the nodes do not record their construction
+	g = NewGraph()
+	x = NewMatrix(g, Float64, WithShape(2, 3))
+	assert.Equal("", Must(Exp(x)).ConstructionStack())
+	_, err = Mul(x, x)
+	assert.NotContains(err.Error(), "constructed at")
+}
@@ -0,0 +1,179 @@
+package gorgonia
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+	"gorgonia.org/tensor"
+)
+
+// GraphSummary is a report of the ops of a graph, with their parameters, output shapes and numbers of floating point
+// operations. See Summarize.
+type GraphSummary struct {
+	Ops []OpSummary // the ops of the forward pass, in the order they are executed
+
+	Params        int     // the number of elements of the parameters
+	FLOPs         float64 // the estimated FLOPs of the forward pass
+	BackwardFLOPs float64 // the estimated FLOPs of the ops computing the gradients
+}
+
+// OpSummary is a row of a GraphSummary.
+type OpSummary struct {
+	Node  *Node
+	Layer string // the scope of the node, or of the parameters it reads, or of the op that computes its input
+	Shape tensor.Shape
+	// Params is the number of elements of the parameters the op reads. A parameter read by several ops is counted for the
+	// first one.
+	Params int
+	FLOPs  float64
+}
+
+// LayerSummary is the total of the consecutive ops of a layer.
+type LayerSummary struct {
+	Name   string
+	Ops    int
+	Shape  tensor.Shape // the output shape of the last op of the layer
+	Params int
+	FLOPs  float64
+}
+
+// Summarize reports the parameter counts, output shapes and FLOPs of the ops of the graph, in the order they are
+// executed, like the summaries of models in other frameworks.
+//
+// The parameters are the given nodes, or the inputs that have a gradient if there are none. The ops of the forward pass
+// are those the roots of the graph that are not gradients depend on; the others compute the gradients, and only their
+// total FLOPs is reported. The FLOPs are estimated as for PlanPlacement: exactly for the linear algebra ops, and one per
+// element of the largest operand for every other op.
+//
+// The layers are named by the scopes of the nodes (see Scope). An op that is not named in a scope belongs to the scope of
+// the parameters it reads, or else to the layer of the op that computes its first input.
+func Summarize(g *ExprGraph, params ...*Node) (GraphSummary, error) {
+	sorted, err := Sort(g)
+	if err != nil {
+		return GraphSummary{}, errors.Wrap(err, sortFail)
+	}
+	reverseNodes(sorted)
+
+	isParam := make(map[*Node]struct{})
+	if len(params) > 0 {
+		for _, p := range params {
+			isParam[p] = struct{}{}
+		}
+	} else {
+		isParam = parameters(g)
+	}
+
+	// the forward pass is what the outputs that are not gradients depend on
+	var outputs Nodes
+	for _, n := range g.Roots() {
+		if len(n.derivOf) == 0 {
+			outputs = append(outputs, n)
+		}
+	}
+	forward := make(map[*Node]struct{})
+	var walk func(n *Node)
+	walk = func(n *Node) {
+		if _, ok := forward[n]; ok {
+			return
+		}
+		forward[n] = struct{}{}
+		for _, child := range n.children {
+			walk(child)
+		}
+	}
+	for _, n := range outputs {
+		walk(n)
+	}
+
+	var retVal GraphSummary
+	for p := range isParam {
+		retVal.Params += p.Shape().TotalSize()
+	}
+	counted := make(map[*Node]struct{})
+	layers := make(map[*Node]string)
+	for _, n := range sorted {
+		if n.isInput() || n.isConstant() {
+			continue
+		}
+		flops := estimateFLOPs(n)
+		if _, ok := forward[n]; !ok {
+			retVal.BackwardFLOPs += flops
+			continue
+		}
+
+		row := OpSummary{Node: n, Shape: n.Shape(), FLOPs: flops, Layer: nameScope(n.name)}
+		for _, child := range n.children {
+			if _, ok := isParam[child]; !ok {
+				continue
+			}
+			if row.Layer == "" {
+				row.Layer = nameScope(child.name)
+			}
+			if _, ok := counted[child]; !ok {
+				counted[child] = struct{}{}
+				row.Params += child.Shape().TotalSize()
+			}
+		}
+		if row.Layer == "" {
+			for _, child := range n.children {
+				if layer, ok := layers[child]; ok {
+					row.Layer = layer
+					break
+				}
+			}
+		}
+		layers[n] = row.Layer
+		retVal.FLOPs += flops
+		retVal.Ops = append(retVal.Ops, row)
+	}
+	return retVal, nil
+}
+
+// nameScope returns the scope a name is in, or "" if it is not in one.
+func nameScope(name string) string {
+	if i := strings.LastIndex(name, ScopeSep); i > 0 {
+		return name[:i]
+	}
+	return ""
+}
+
+// Layers returns the totals of the runs of consecutive ops of the same layer. The ops that are in no layer are each
+// reported as their own.
+func (s GraphSummary) Layers() []LayerSummary {
+	var retVal []LayerSummary
+	for i, op := range s.Ops {
+		if n := len(retVal); n == 0 || op.Layer == "" || s.Ops[i-1].Layer != op.Layer {
+			name := op.Layer
+			if name == "" {
+				name = op.Node.op.String()
+			}
+			retVal = append(retVal, LayerSummary{Name: name})
+		}
+		l := &retVal[len(retVal)-1]
+		l.Ops++
+		l.Shape = op.Shape
+		l.Params += op.Params
+		l.FLOPs += op.FLOPs
+	}
+	return retVal
+}
+
+// String returns the summary as a table of the ops, followed by the totals.
+func (s GraphSummary) String() string {
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "Layer\tOp\tOutput Shape\tParams\tFLOPs\t")
+	for _, op := range s.Ops {
+		layer := op.Layer
+		if layer == "" {
+			layer = "-"
+		}
+		fmt.Fprintf(w, "%s\t%v\t%v\t%d\t%g\t\n", layer, op.Node.op, op.Shape, op.Params, op.FLOPs)
+	}
+	w.Flush()
+	fmt.Fprintf(&buf, "Params: %d | Forward FLOPs: %g | Backward FLOPs: %g\n", s.Params, s.FLOPs, s.BackwardFLOPs)
+	return buf.String()
+}
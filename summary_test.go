@@ -0,0 +1,71 @@
+package gorgonia
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorgonia.org/tensor"
+)
+
+func TestSummarize(t *testing.T) {
+	assert := assert.New(t)
+	g := NewGraph()
+	x := NewMatrix(g, Float64, WithShape(4, 3), WithName("x"), WithInit(RangedFrom(0)))
+	var w1, w2 *Node
+	leave := g.Scope("layer1")
+	w1 = NewMatrix(g, Float64, WithShape(3, 5), WithName("w"), WithInit(RangedFrom(0)))
+	h := Must(Tanh(Must(Mul(x, w1))))
+	leave()
+	leave = g.Scope("layer2")
+	w2 = NewMatrix(g, Float64, WithShape(5, 2), WithName("w"), WithInit(RangedFrom(0)))
+	leave()
+	y := Must(Mul(h, w2))
+	cost := Must(Sum(y))
+
+	s, err := Summarize(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(0, s.Params, "there are no gradients, so no parameters")
+	if s, err = Summarize(g, w1, w2); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(25, s.Params)
+	assert.Equal(4, len(s.Ops))
+	assert.Equal(0.0, s.BackwardFLOPs)
+
+	if _, err = Grad(cost, w1, w2); err != nil {
+		t.Fatal(err)
+	}
+	if s, err = Summarize(g); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(25, s.Params)
+	if !assert.Equal(4, len(s.Ops), "the gradients are not in the forward pass") {
+		t.Fatal(s)
+	}
+	assert.True(s.BackwardFLOPs > 0)
+
+	matmul := s.Ops[0]
+	assert.Equal("layer1", matmul.Layer)
+	assert.Equal(tensor.Shape{4, 5}, matmul.Shape)
+	assert.Equal(15, matmul.Params)
+	assert.Equal(2.0*4*5*3, matmul.FLOPs)
+	assert.Equal("layer1", s.Ops[1].Layer, "tanh inherits the layer of its input")
+	assert.Equal("layer2", s.Ops[2].Layer, "the second product reads a parameter of layer2")
+	assert.Equal(10, s.Ops[2].Params)
+	assert.Equal("layer2", s.Ops[3].Layer, "the sum inherits the layer of its input")
+	assert.Equal(matmul.FLOPs+s.Ops[1].FLOPs+s.Ops[2].FLOPs+s.Ops[3].FLOPs, s.FLOPs)
+
+	layers := s.Layers()
+	if assert.Equal(2, len(layers)) {
+		assert.Equal(LayerSummary{Name: "layer1", Ops: 2, Shape: tensor.Shape{4, 5}, Params: 15, FLOPs: matmul.FLOPs + s.Ops[1].FLOPs}, layers[0])
+		assert.Equal("layer2", layers[1].Name)
+		assert.Equal(10, layers[1].Params)
+	}
+
+	out := s.String()
+	assert.True(strings.HasPrefix(out, "Layer"), out)
+	assert.Contains(out, "Params: 25 | Forward FLOPs: ")
+}
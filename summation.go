@@ -0,0 +1,270 @@
+package gorgonia
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/pkg/errors"
+	"gorgonia.org/tensor"
+)
+
+// Summation is the algorithm that the float reductions sum with.
+type Summation byte
+
+const (
+	// SumNaive adds the values one after the other. Its error grows linearly with the number of values, so the sums of
+	// large float32 tensors lose a lot of precision. It is the fastest, and the default.
+	SumNaive Summation = iota
+	// SumPairwise splits the values in halves recursively and adds the sums of the halves. Its error grows with the
+	// logarithm of the number of values, for about the cost of SumNaive.
+	SumPairwise
+	// SumKahan compensates the rounding error of every addition in the next one. Its error does not grow with the number
+	// of values, for about four times the cost of SumNaive.
+	SumKahan
+)
+
+func (s Summation) String() string {
+	switch s {
+	case SumNaive:
+		return "SumNaive"
+	case SumPairwise:
+		return "SumPairwise"
+	case SumKahan:
+		return "SumKahan"
+	}
+	return fmt.Sprintf("Summation(%d)", byte(s))
+}
+
+// Summationer is any engine that specifies the summation algorithm of the float reductions.
+type Summationer interface {
+	Summation() Summation
+}
+
+// WithSummation returns a copy of the engine whose float reductions (Sum, and Mean which is computed from it) use the
+// given summation algorithm:
+//		m := NewTapeMachine(g, WithEngine(StandardEngine{}.WithSummation(SumKahan)))
+func (e StandardEngine) WithSummation(s Summation) StandardEngine {
+	e.summation = s
+	return e
+}
+
+// Summation returns the summation algorithm of the engine.
+func (e StandardEngine) Summation() Summation { return e.summation }
+
+// summationOf returns the summation algorithm of the engines of the given values. The first one that is not SumNaive is
+// returned.
+func summationOf(vals ...Value) Summation {
+	for _, v := range vals {
+		t, ok := v.(tensor.Tensor)
+		if !ok {
+			continue
+		}
+		if e, ok := t.Engine().(Summationer); ok && e.Summation() != SumNaive {
+			return e.Summation()
+		}
+	}
+	return SumNaive
+}
+
+// WithWorkers returns a copy of the engine whose float reductions run on n goroutines. The results do not depend on n:
+// the values are summed in chunks of a fixed size, whose sums are combined in a fixed binary tree, so enabling the
+// parallelism or changing the number of workers does not change the results from run to run. They may differ from the
+// results of the sequential reductions, which sum in a different order. n <= 0 turns the parallelism off.
+func (e StandardEngine) WithWorkers(n int) StandardEngine {
+	e.workers = n
+	return e
+}
+
+// Workers returns the number of goroutines that the reductions of the engine run on, or 0 if they are sequential.
+func (e StandardEngine) Workers() int { return e.workers }
+
+// Parallelizer is any engine that runs the reductions in parallel.
+type Parallelizer interface {
+	Workers() int
+}
+
+// workersOf returns the number of workers of the engines of the given values. The first one that is positive is returned.
+func workersOf(vals ...Value) int {
+	for _, v := range vals {
+		t, ok := v.(tensor.Tensor)
+		if !ok {
+			continue
+		}
+		if e, ok := t.Engine().(Parallelizer); ok && e.Workers() > 0 {
+			return e.Workers()
+		}
+	}
+	return 0
+}
+
+const (
+	// pairwiseBlock is the number of values that the pairwise summation adds naively, which amortizes the recursion.
+	pairwiseBlock = 8
+	// reductionChunk is the number of values that a worker of a parallel reduction sums at once. It is fixed so that the
+	// results do not depend on the number of workers.
+	reductionChunk = 1 << 12
+)
+
+// sumAlong sums the float values along the given axes with the given algorithm. If workers is positive, the reduction
+// runs on that many goroutines, in the order of sumChunks.
+func sumAlong(v Value, along []int, alg Summation, workers int) (retVal Value, err error) {
+	t, ok := v.(tensor.Tensor)
+	if !ok {
+		return nil, errors.Errorf(nyiTypeFail, "sumAlong", v)
+	}
+	if t.RequiresIterator() {
+		t = tensor.Materialize(t)
+	}
+	idx, size := reductionIndices(t.Shape(), along)
+
+	var backing interface{}
+	switch data := t.Data().(type) {
+	case []float64:
+		groups := make([][]float64, size)
+		for i, a := range data {
+			groups[idx[i]] = append(groups[idx[i]], a)
+		}
+		ret := make([]float64, size)
+		if workers > 0 {
+			chunks := make([][]float64, size)
+			lens := make([]int, size)
+			for j, g := range groups {
+				lens[j] = len(g)
+				chunks[j] = make([]float64, chunksOf(len(g)))
+			}
+			parallelChunks(lens, workers, func(j, c, lo, hi int) { chunks[j][c] = sumF64(groups[j][lo:hi], alg) })
+			for j, c := range chunks {
+				ret[j] = sumF64(c, SumPairwise)
+			}
+		} else {
+			for j, g := range groups {
+				ret[j] = sumF64(g, alg)
+			}
+		}
+		backing = ret
+	case []float32:
+		groups := make([][]float32, size)
+		for i, a := range data {
+			groups[idx[i]] = append(groups[idx[i]], a)
+		}
+		ret := make([]float32, size)
+		if workers > 0 {
+			chunks := make([][]float32, size)
+			lens := make([]int, size)
+			for j, g := range groups {
+				lens[j] = len(g)
+				chunks[j] = make([]float32, chunksOf(len(g)))
+			}
+			parallelChunks(lens, workers, func(j, c, lo, hi int) { chunks[j][c] = sumF32(groups[j][lo:hi], alg) })
+			for j, c := range chunks {
+				ret[j] = sumF32(c, SumPairwise)
+			}
+		} else {
+			for j, g := range groups {
+				ret[j] = sumF32(g, alg)
+			}
+		}
+		backing = ret
+	default:
+		return nil, errors.Errorf(nyiTypeFail, "sumAlong", t.Data())
+	}
+
+	var shape tensor.Shape
+	if shape, err = reductionInferShape(along, t.Shape()); err != nil {
+		return nil, err
+	}
+	if shape.IsScalar() {
+		switch b := backing.(type) {
+		case []float64:
+			return newF64(b[0]), nil
+		case []float32:
+			return newF32(b[0]), nil
+		}
+	}
+	return tensor.New(tensor.WithShape(shape...), tensor.WithBacking(backing)), nil
+}
+
+// chunksOf is the number of chunks of a parallel reduction of n values.
+func chunksOf(n int) int { return (n + reductionChunk - 1) / reductionChunk }
+
+// parallelChunks calls sum on the given number of goroutines for every chunk of reductionChunk values of groups of the
+// given lengths, with the indices of the group and of the chunk, and the range of the chunk in the group. The caller
+// then combines the sums of the chunks of every group pairwise, which is the fixed binary tree of the parallel reductions.
+func parallelChunks(lens []int, workers int, sum func(group, chunk, lo, hi int)) {
+	type job struct{ group, chunk, lo, hi int }
+	var jobs []job
+	for j, n := range lens {
+		for c := 0; c < chunksOf(n); c++ {
+			hi := (c + 1) * reductionChunk
+			if hi > n {
+				hi = n
+			}
+			jobs = append(jobs, job{j, c, c * reductionChunk, hi})
+		}
+	}
+
+	ch := make(chan job, len(jobs))
+	for _, jb := range jobs {
+		ch <- jb
+	}
+	close(ch)
+	var wg sync.WaitGroup
+	for w := 0; w < workers && w < len(jobs); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for jb := range ch {
+				sum(jb.group, jb.chunk, jb.lo, jb.hi)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func sumF64(a []float64, alg Summation) float64 {
+	switch alg {
+	case SumPairwise:
+		if len(a) > pairwiseBlock {
+			half := len(a) / 2
+			return sumF64(a[:half], alg) + sumF64(a[half:], alg)
+		}
+	case SumKahan:
+		var sum, c float64 // c is the rounding error of sum, which is taken off the next value
+		for _, v := range a {
+			y := v - c
+			t := sum + y
+			c = (t - sum) - y
+			sum = t
+		}
+		return sum
+	}
+	var sum float64
+	for _, v := range a {
+		sum += v
+	}
+	return sum
+}
+
+func sumF32(a []float32, alg Summation) float32 {
+	switch alg {
+	case SumPairwise:
+		if len(a) > pairwiseBlock {
+			half := len(a) / 2
+			return sumF32(a[:half], alg) + sumF32(a[half:], alg)
+		}
+	case SumKahan:
+		var sum, c float32 // c is the rounding error of sum, which is taken off the next value
+		for _, v := range a {
+			y := v - c
+			t := sum + y
+			c = (t - sum) - y
+			sum = t
+		}
+		return sum
+	}
+	var sum float32
+	for _, v := range a {
+		sum += v
+	}
+	return sum
+}
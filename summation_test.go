@@ -0,0 +1,86 @@
+package gorgonia
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorgonia.org/tensor"
+)
+
+func TestWithSummation(t *testing.T) {
+	assert := assert.New(t)
+	const n = 1 << 20
+	backing := make([]float32, n)
+	var exact float64
+	for i := range backing {
+		backing[i] = 0.1
+		exact += float64(backing[i])
+	}
+
+	sum := func(alg Summation) float64 {
+		g := NewGraph()
+		x := NewVector(g, Float32, WithShape(n), WithName("x"), WithValue(tensor.New(tensor.WithBacking(backing))))
+		s := Must(Sum(x))
+		m := NewTapeMachine(g, WithEngine(StandardEngine{}.WithSummation(alg)))
+		defer m.Close()
+		if err := m.RunAll(); err != nil {
+			t.Fatal(err)
+		}
+		return float64(s.Value().Data().(float32))
+	}
+
+	naive := math.Abs(sum(SumNaive) - exact)
+	pairwise := math.Abs(sum(SumPairwise) - exact)
+	kahan := math.Abs(sum(SumKahan) - exact)
+	assert.True(naive > 100, "naive error %v", naive)
+	assert.True(pairwise < 0.1, "pairwise error %v", pairwise)
+	assert.True(kahan < 0.01, "kahan error %v", kahan)
+}
+
+func TestSumAlong(t *testing.T) {
+	assert := assert.New(t)
+	x := tensor.New(tensor.WithShape(2, 3), tensor.WithBacking([]float64{1, 2, 3, 4, 5, 6}))
+	for _, alg := range []Summation{SumPairwise, SumKahan} {
+		v, err := sumAlong(x, []int{1}, alg, 0)
+		if assert.NoError(err) {
+			assert.Equal([]float64{6, 15}, v.Data(), "%v", alg)
+		}
+		if v, err = sumAlong(x, []int{0}, alg, 0); assert.NoError(err) {
+			assert.Equal([]float64{5, 7, 9}, v.Data(), "%v", alg)
+		}
+		if v, err = sumAlong(x, []int{0, 1}, alg, 0); assert.NoError(err) {
+			assert.Equal(newF64(21), v, "%v", alg)
+		}
+	}
+	_, err := sumAlong(tensor.New(tensor.WithBacking([]int{1, 2})), []int{0}, SumKahan, 0)
+	assert.Error(err)
+}
+
+func TestWithWorkers(t *testing.T) {
+	assert := assert.New(t)
+	const rows, cols = 3, 50000
+	backing := make([]float32, rows*cols)
+	for i := range backing {
+		backing[i] = float32(math.Sin(float64(i))) * float32(i%977)
+	}
+
+	sum := func(workers int, along ...int) interface{} {
+		g := NewGraph()
+		x := NewMatrix(g, Float32, WithShape(rows, cols), WithName("x"), WithValue(tensor.New(tensor.WithShape(rows, cols), tensor.WithBacking(backing))))
+		s := Must(Sum(x, along...))
+		m := NewTapeMachine(g, WithEngine(StandardEngine{}.WithWorkers(workers)))
+		defer m.Close()
+		if err := m.RunAll(); err != nil {
+			t.Fatal(err)
+		}
+		return s.Value().Data()
+	}
+
+	for _, along := range [][]int{nil, {1}, {0}} {
+		want := sum(1, along...)
+		for _, workers := range []int{2, 3, 8, 1, 8} {
+			assert.Equal(want, sum(workers, along...), "along %v with %d workers", along, workers)
+		}
+	}
+}
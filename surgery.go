@@ -0,0 +1,219 @@
+package gorgonia
+
+import (
+	"github.com/pkg/errors"
+)
+
+/*
+This file holds the graph surgery API: operations that mutate an existing graph while keeping its bookkeeping
+(the edges, the hash consing tables, the groups and the derivatives) consistent. These are the building blocks of
+graph transformations such as pruning, adapter insertion or quantization.
+
+All of them must be called before the graph is compiled into a machine.
+*/
+
+// ReplaceNode replaces the uses of old with repl: every node that has old as a child has repl as a child instead.
+// Parents of old that repl itself depends on keep old as a child, so that repl may be computed from old (see InsertAfter).
+// repl must be in the same graph, and have the same type and shape as old.
+//
+// repl takes over the derivative of old, if it does not have one, and old's groups, if it does not have any.
+// old is removed from the graph if it is no longer used and is not an input.
+func (g *ExprGraph) ReplaceNode(old, repl *Node) error {
+	if err := g.checkReplacement(old, repl); err != nil {
+		return err
+	}
+
+	deps := NewNodeSet()
+	walkDeps(repl, deps)
+
+	var rewired Nodes
+	for _, p := range g.to.get(old).Set() {
+		if deps.Contains(p) {
+			continue
+		}
+		rewired = append(rewired, p)
+	}
+	g.rewire(old, repl, rewired)
+	transferDeriv(old, repl)
+	if len(repl.groups) == 0 {
+		repl.groups = old.groups
+	}
+
+	if len(g.to.get(old)) == 0 && !old.isInput() {
+		g.detach(old)
+	}
+	g.surgeryDone()
+	return nil
+}
+
+// InsertAfter inserts the result of fn(n) after n: every node that used n uses the result instead. The result must have
+// the same type and shape as n. For example, to scale the output of a layer:
+//		scaled, err := g.InsertAfter(h, func(h *Node) (*Node, error) { return HadamardProd(h, scale) })
+func (g *ExprGraph) InsertAfter(n *Node, fn func(*Node) (*Node, error)) (*Node, error) {
+	if n == nil || n.g != g {
+		return nil, errors.Errorf("Cannot insert after %v: it is not in the graph", n)
+	}
+	retVal, err := fn(n)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to insert after %v", n)
+	}
+	if err = g.ReplaceNode(n, retVal); err != nil {
+		return nil, err
+	}
+	return retVal, nil
+}
+
+// RemoveWithRewire removes n from the graph, and rewires its users to n's only child. It is meant for ops that may be
+// elided, such as dropout at inference time, so n must have exactly one child, of the same type and shape as n.
+func (g *ExprGraph) RemoveWithRewire(n *Node) error {
+	if n == nil || n.g != g {
+		return errors.Errorf("Cannot remove %v: it is not in the graph", n)
+	}
+	if len(n.children) != 1 {
+		return errors.Errorf("Cannot remove %v: it has %d children. Expected 1", n, len(n.children))
+	}
+	child := n.children[0]
+	if err := g.checkReplacement(n, child); err != nil {
+		return err
+	}
+	g.rewire(n, child, g.to.get(n).Set())
+	transferDeriv(n, child)
+	if len(g.to.get(n)) == 0 {
+		g.detach(n)
+	}
+	g.surgeryDone()
+	return nil
+}
+
+func (g *ExprGraph) checkReplacement(old, repl *Node) error {
+	if old == nil || repl == nil {
+		return errors.New("Cannot replace nil nodes")
+	}
+	if old.g != g || repl.g != g {
+		return errors.Errorf("Cannot replace %v with %v: both nodes must be in the graph", old, repl)
+	}
+	if old == repl {
+		return errors.Errorf("Cannot replace %v with itself", old)
+	}
+	if !old.t.Eq(repl.t) {
+		return errors.Errorf("Cannot replace %v with %v: type mismatch %v and %v", old, repl, old.t, repl.t)
+	}
+	if !old.shape.Eq(repl.shape) {
+		return errors.Errorf("Cannot replace %v with %v: shape mismatch %v and %v", old, repl, old.shape, repl.shape)
+	}
+	return nil
+}
+
+// rewire replaces old with repl in the children of the parents. The hashes of the parents, and of all the nodes that
+// depend on them, change, so they are reindexed.
+func (g *ExprGraph) rewire(old, repl *Node, parents Nodes) {
+	if len(parents) == 0 {
+		return
+	}
+	affected := NewNodeSet()
+	for _, p := range parents {
+		walkUsers(g, p, affected)
+	}
+	nodes := affected.ToSlice()
+	for _, n := range nodes {
+		g.unindex(n)
+	}
+
+	g.sorted = nil
+	for _, p := range parents {
+		p.children = p.children.replace(old, repl)
+		g.to.set(old, g.to.get(old).remove(p))
+		if !g.to.get(repl).Contains(p) {
+			g.to.set(repl, append(g.to.get(repl), p))
+		}
+	}
+
+	for _, n := range nodes {
+		n.hashed = false
+	}
+	for _, n := range nodes {
+		g.reindex(n)
+	}
+}
+
+// transferDeriv makes repl take over the derivative bookkeeping of old: the derivative of old (unless repl has one),
+// and the nodes that old is the derivative of.
+func transferDeriv(old, repl *Node) {
+	if repl.deriv == nil && old.deriv != nil {
+		repl.deriv = old.deriv
+		old.deriv.derivOf = old.deriv.derivOf.replace(old, repl)
+		old.deriv = nil
+	}
+	for _, of := range old.derivOf {
+		if of.deriv == old {
+			of.deriv = repl
+		}
+		if !repl.derivOf.Contains(of) {
+			repl.derivOf = append(repl.derivOf, of)
+		}
+	}
+	old.derivOf = nil
+}
+
+// detach removes n from the graph, along with the edges to its children.
+func (g *ExprGraph) detach(n *Node) {
+	for _, child := range n.children.Set() {
+		g.to.set(child, g.to.get(child).remove(n))
+	}
+	g.RemoveNode(n)
+}
+
+// surgeryDone resets the caches that are invalidated by mutating the graph.
+func (g *ExprGraph) surgeryDone() {
+	g.byID = make(map[int64]int)
+	g.roots = nil
+}
+
+// unindex removes n from the hash consing tables, using its current hash.
+func (g *ExprGraph) unindex(n *Node) {
+	h := n.Hashcode()
+	if g.byHash[h] == n {
+		delete(g.byHash, h)
+	}
+	if evac, ok := g.evac[h]; ok {
+		g.evac[h] = evac.remove(n)
+	}
+}
+
+// reindex adds n to the hash consing tables, using its current hash.
+func (g *ExprGraph) reindex(n *Node) {
+	h := n.Hashcode()
+	existing, ok := g.byHash[h]
+	switch {
+	case !ok:
+		g.byHash[h] = n
+	case existing == n:
+	case existing == nil:
+		g.evac[h] = append(g.evac[h], n)
+	default:
+		g.evac[h] = Nodes{existing, n}
+		g.byHash[h] = nil
+	}
+}
+
+// walkDeps adds n and everything n depends on to the set.
+func walkDeps(n *Node, set NodeSet) {
+	if set.Contains(n) {
+		return
+	}
+	set.Add(n)
+	for _, child := range n.children {
+		walkDeps(child, set)
+	}
+}
+
+// walkUsers adds n and everything that depends on n to the set.
+func walkUsers(g *ExprGraph, n *Node, set NodeSet) {
+	if set.Contains(n) {
+		return
+	}
+	set.Add(n)
+	for _, p := range g.to.get(n) {
+		walkUsers(g, p, set)
+	}
+}
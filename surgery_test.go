@@ -0,0 +1,113 @@
+package gorgonia
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorgonia.org/tensor"
+)
+
+func surgeryGraph() (g *ExprGraph, x, h, cost *Node) {
+	g = NewGraph()
+	x = NewVector(g, Float64, WithShape(3), WithName("x"), WithValue(tensor.New(tensor.WithBacking([]float64{1, 2, 3}))))
+	h = Must(Square(x))
+	cost = Must(Sum(h))
+	return
+}
+
+func runSurgeryGraph(t *testing.T, g *ExprGraph) {
+	m := NewTapeMachine(g)
+	defer m.Close()
+	if err := m.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestExprGraph_InsertAfter(t *testing.T) {
+	assert := assert.New(t)
+	g, x, h, cost := surgeryGraph()
+
+	scaled, err := g.InsertAfter(h, func(h *Node) (*Node, error) { return HadamardProd(h, NewConstant(2.0)) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(Nodes{scaled}, g.to.get(h))
+	assert.Equal(Nodes{scaled}, cost.children)
+	assert.True(g.to.get(scaled).Contains(cost))
+
+	// the hashes have been updated: building the same expression again finds the rewired nodes
+	assert.Equal(cost, Must(Sum(scaled)))
+
+	if _, err = Grad(cost, x); err != nil {
+		t.Fatal(err)
+	}
+	runSurgeryGraph(t, g)
+	assert.Equal(28.0, cost.Value().Data())
+	xGrad, err := x.Grad()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]float64{4, 8, 12}, xGrad.Data())
+
+	// shapes must match
+	_, err = g.InsertAfter(h, func(h *Node) (*Node, error) { return Sum(h) })
+	assert.Error(err)
+}
+
+func TestExprGraph_ReplaceNode(t *testing.T) {
+	assert := assert.New(t)
+	g, x, h, cost := surgeryGraph()
+	y := NewVector(g, Float64, WithShape(3), WithName("y"), WithValue(tensor.New(tensor.WithBacking([]float64{1, 1, 1}))))
+
+	if err := g.ReplaceNode(x, y); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(Nodes{y}, h.children)
+	assert.Empty(g.to.get(x))
+	assert.True(g.AllNodes().Contains(x), "inputs are not removed")
+
+	// op nodes that are no longer used are removed
+	c := Must(Cube(y))
+	if err := g.ReplaceNode(h, c); err != nil {
+		t.Fatal(err)
+	}
+	assert.False(g.AllNodes().Contains(h))
+	assert.False(g.to.get(y).Contains(h))
+	runSurgeryGraph(t, g)
+	assert.Equal(3.0, cost.Value().Data())
+
+	// derivatives are taken over
+	g, x, h, cost = surgeryGraph()
+	if _, err := Grad(cost, x); err != nil {
+		t.Fatal(err)
+	}
+	hGrad := h.deriv
+	c = Must(Cube(x))
+	if err := g.ReplaceNode(h, c); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(hGrad, c.deriv)
+	assert.Equal(Nodes{c}, hGrad.derivOf)
+
+	// errors
+	v := NewVector(g, Float64, WithShape(4), WithName("v"))
+	assert.Error(g.ReplaceNode(x, v))
+	assert.Error(g.ReplaceNode(x, x))
+	assert.Error(g.ReplaceNode(x, NewVector(NewGraph(), Float64, WithShape(3), WithName("z"))))
+}
+
+func TestExprGraph_RemoveWithRewire(t *testing.T) {
+	assert := assert.New(t)
+	g, x, h, cost := surgeryGraph()
+	assert.Error(g.RemoveWithRewire(cost), "a scalar cannot replace a vector")
+	assert.Error(g.RemoveWithRewire(x), "inputs have no children")
+
+	if err := g.RemoveWithRewire(h); err != nil {
+		t.Fatal(err)
+	}
+	assert.False(g.AllNodes().Contains(h))
+	assert.Equal(Nodes{x}, cost.children)
+	assert.Equal(Nodes{cost}, g.to.get(x))
+	runSurgeryGraph(t, g)
+	assert.Equal(6.0, cost.Value().Data())
+}
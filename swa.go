@@ -0,0 +1,229 @@
+package gorgonia
+
+import (
+	"github.com/pkg/errors"
+	"gorgonia.org/tensor"
+)
+
+// WeightAverager averages the weights of a model along its training, as in the stochastic weight averaging (SWA) of
+// Izmailov et al. (2018), or the Polyak averaging with an exponential decay. The averaged weights usually generalize
+// better than the last weights:
+//		avg := NewWeightAverager(NodesToValueGrads(learnables), WithAveragingSchedule(swaStart, stepsPerEpoch))
+//		for i := 0; i < steps; i++ {
+//			// run the machine and step the solver
+//			if err := avg.Update(); err != nil {
+//				...
+//			}
+//		}
+//		if err := avg.Apply(); err != nil {
+//			...
+//		}
+//		if err := RecomputeBatchNorm(m, bnOps, batches, letBatch); err != nil {
+//			...
+//		}
+//
+// The BatchNorm statistics of the averaged weights are not the averages of the statistics of the snapshots, so they
+// must be recomputed with a pass over the training data (see RecomputeBatchNorm).
+type WeightAverager struct {
+	model []ValueGrad
+	avgs  []Value
+	count int // the number of snapshots averaged
+	steps int // the number of calls of Update
+
+	start, every int
+	decay        float64
+	swapped      bool
+}
+
+// WeightAveragerOpt is a function that provides construction options for a WeightAverager.
+type WeightAveragerOpt func(*WeightAverager)
+
+// WithAveragingSchedule makes the WeightAverager take a snapshot every given number of steps, once the given number of
+// steps have been done. By default a snapshot is taken at every step.
+func WithAveragingSchedule(start, every int) WeightAveragerOpt {
+	return func(a *WeightAverager) {
+		a.start = start
+		a.every = every
+	}
+}
+
+// WithEMADecay makes the WeightAverager keep an exponential moving average of the snapshots, the average being
+// multiplied by the decay (such as 0.999) at every snapshot, instead of averaging them equally.
+func WithEMADecay(decay float64) WeightAveragerOpt {
+	return func(a *WeightAverager) { a.decay = decay }
+}
+
+// NewWeightAverager creates a WeightAverager for the weights of the model.
+func NewWeightAverager(model []ValueGrad, opts ...WeightAveragerOpt) *WeightAverager {
+	a := &WeightAverager{model: model, every: 1}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
+}
+
+// Update counts a step of training, and takes a snapshot of the weights if the schedule says so. It is to be called
+// after every step of the solver.
+func (a *WeightAverager) Update() error {
+	a.steps++
+	if a.steps < a.start || a.every <= 0 || (a.steps-a.start)%a.every != 0 {
+		return nil
+	}
+	return a.Collect()
+}
+
+// Collect adds the current weights of the model to the average, whatever the schedule.
+func (a *WeightAverager) Collect() (err error) {
+	if a.swapped {
+		return errors.New("Cannot collect the weights: the averages are swapped in")
+	}
+	if a.avgs == nil {
+		a.avgs = make([]Value, len(a.model))
+	}
+	alpha := 1 / float64(a.count+1)
+	if a.decay > 0 && a.count > 0 {
+		alpha = 1 - a.decay
+	}
+	for i, n := range a.model {
+		w := n.Value()
+		if a.avgs[i] == nil {
+			if a.avgs[i], err = CloneValue(w); err != nil {
+				return errors.Wrapf(err, cloneFail, w)
+			}
+			continue
+		}
+		if a.count == 0 {
+			if _, err = Copy(a.avgs[i], w); err != nil {
+				return errors.Wrap(err, "Failed to copy weights")
+			}
+			continue
+		}
+		// avg = (1-α)avg + αw
+		if err = scaleInPlace(a.avgs[i], (1-alpha)/alpha); err != nil {
+			return err
+		}
+		if err = addInPlace(a.avgs[i], w); err != nil {
+			return err
+		}
+		if err = scaleInPlace(a.avgs[i], alpha); err != nil {
+			return err
+		}
+	}
+	a.count++
+	return nil
+}
+
+// Count returns the number of snapshots averaged.
+func (a *WeightAverager) Count() int { return a.count }
+
+// Averages returns the averaged weights, in the order of the model. The values are owned by the WeightAverager, and nil
+// is returned if no snapshot has been taken.
+func (a *WeightAverager) Averages() []Value {
+	if a.count == 0 {
+		return nil
+	}
+	return a.avgs
+}
+
+// Apply copies the averaged weights into the weights of the model, for good.
+func (a *WeightAverager) Apply() (err error) {
+	if a.count == 0 {
+		return errors.New("Cannot apply the averages: no snapshot has been taken")
+	}
+	if a.swapped {
+		a.swapped = false
+		return nil
+	}
+	for i, n := range a.model {
+		if _, err = Copy(n.Value(), a.avgs[i]); err != nil {
+			return errors.Wrap(err, "Failed to copy weights")
+		}
+	}
+	return nil
+}
+
+// Swap exchanges the weights of the model and the averaged weights, so that the averaged weights may be evaluated
+// during the training. Calling it again swaps the weights back; the snapshots may only be taken when they are.
+func (a *WeightAverager) Swap() (err error) {
+	if a.count == 0 {
+		return errors.New("Cannot swap the averages: no snapshot has been taken")
+	}
+	for i, n := range a.model {
+		var tmp Value
+		if tmp, err = CloneValue(n.Value()); err != nil {
+			return errors.Wrapf(err, cloneFail, n.Value())
+		}
+		if _, err = Copy(n.Value(), a.avgs[i]); err != nil {
+			return errors.Wrap(err, "Failed to copy weights")
+		}
+		if _, err = Copy(a.avgs[i], tmp); err != nil {
+			return errors.Wrap(err, "Failed to copy weights")
+		}
+	}
+	a.swapped = !a.swapped
+	return nil
+}
+
+// RecomputeBatchNorm recomputes the moving statistics of the BatchNorm ops at the current weights, as the equal
+// averages of the statistics of the given number of batches. let is called with the index of every batch to set the
+// inputs of the machine, which is then run once, without stepping any solver.
+//
+// The ops are left in the mode they were in. In the training mode, the next batches update the statistics with the
+// momentum of the ops, as usual.
+func RecomputeBatchNorm(m VM, ops []*BatchNormOp, batches int, let func(batch int) error) (err error) {
+	if batches <= 0 {
+		return errors.Errorf("Expected a positive number of batches. Got %d instead", batches)
+	}
+	momenta := make([]float64, len(ops))
+	training := make([]bool, len(ops))
+	for i, op := range ops {
+		momenta[i], training[i] = op.momentum, op.training
+		// a momentum of 1 sums the statistics of the batches, and counts them in ma
+		op.momentum = 1
+		op.SetTraining()
+	}
+	defer func() {
+		for i, op := range ops {
+			op.momentum, op.training = momenta[i], training[i]
+		}
+	}()
+
+	for i := 0; i < batches; i++ {
+		if err = let(i); err != nil {
+			return err
+		}
+		if err = m.RunAll(); err != nil {
+			return err
+		}
+		m.Reset()
+	}
+	for _, op := range ops {
+		if err = op.averageStats(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// averageStats divides the sums of the statistics by their count.
+func (op *BatchNormOp) averageStats() error {
+	var count float64
+	switch op.ma.Dtype() {
+	case tensor.Float64:
+		count = op.ma.Float64s()[0]
+	case tensor.Float32:
+		count = float64(op.ma.Float32s()[0])
+	default:
+		return errors.Errorf(nyiFail, "averageStats", op.ma.Dtype())
+	}
+	if count == 0 {
+		return errors.New("The BatchNorm was not run")
+	}
+	if err := scaleInPlace(op.mean, 1/count); err != nil {
+		return err
+	}
+	if err := scaleInPlace(op.variance, 1/count); err != nil {
+		return err
+	}
+	return scaleInPlace(op.ma, 1/count)
+}
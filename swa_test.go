@@ -0,0 +1,86 @@
+package gorgonia
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorgonia.org/tensor"
+)
+
+func TestWeightAverager(t *testing.T) {
+	assert := assert.New(t)
+	g := NewGraph()
+	w := NewVector(g, Float64, WithShape(2), WithName("w"), WithInit(Zeroes()))
+	model := NodesToValueGrads(Nodes{w})
+	set := func(xs ...float64) {
+		copy(w.Value().Data().([]float64), xs)
+	}
+
+	avg := NewWeightAverager(model, WithAveragingSchedule(2, 2))
+	assert.Nil(avg.Averages())
+	assert.Error(avg.Apply())
+	assert.Error(avg.Swap())
+	for i, xs := range [][]float64{{100, 100}, {1, 2}, {7, 7}, {3, 4}} {
+		set(xs...)
+		if err := avg.Update(); err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal((i+1)/2, avg.Count(), "the snapshots are taken at steps 2 and 4")
+	}
+	assert.Equal([]float64{2, 3}, avg.Averages()[0].Data())
+
+	if err := avg.Swap(); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]float64{2, 3}, w.Value().Data())
+	assert.Error(avg.Collect(), "the averages are swapped in")
+	if err := avg.Swap(); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]float64{3, 4}, w.Value().Data())
+	assert.Equal([]float64{2, 3}, avg.Averages()[0].Data())
+
+	if err := avg.Apply(); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]float64{2, 3}, w.Value().Data())
+
+	ema := NewWeightAverager(model, WithEMADecay(0.5))
+	for _, xs := range [][]float64{{4, 0}, {0, 4}, {2, 0}} {
+		set(xs...)
+		if err := ema.Update(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	assert.Equal(3, ema.Count())
+	assert.Equal([]float64{2, 1}, ema.Averages()[0].Data())
+}
+
+func TestRecomputeBatchNorm(t *testing.T) {
+	assert := assert.New(t)
+	g := NewGraph()
+	x := NewTensor(g, Float64, 4, WithShape(2, 1, 1, 1), WithName("x"), WithInit(Zeroes()))
+	_, _, _, op, err := BatchNorm(x, nil, nil, 0.9, 1e-5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := NewTapeMachine(g)
+	defer m.Close()
+
+	batches := [][]float64{{1, 3}, {5, 7}}
+	let := func(i int) error {
+		return Let(x, tensor.New(tensor.WithShape(2, 1, 1, 1), tensor.WithBacking(batches[i])))
+	}
+	op.SetTesting()
+	if err = RecomputeBatchNorm(m, []*BatchNormOp{op}, len(batches), let); err != nil {
+		t.Fatal(err)
+	}
+	// the means are 2 and 6, and the (unbiased) variances are both 2
+	assert.Equal([]float64{4}, op.mean.Float64s())
+	assert.Equal([]float64{2}, op.variance.Float64s())
+	assert.Equal([]float64{1}, op.ma.Float64s())
+	assert.Equal(0.9, op.momentum)
+	assert.False(op.training)
+
+	assert.Error(RecomputeBatchNorm(m, []*BatchNormOp{op}, 0, let))
+}
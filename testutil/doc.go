@@ -0,0 +1,11 @@
+// Package testutil provides golden-file tests for models: a test runs a model on fixed inputs, with a fixed graph seed
+// (see gorgonia.WithGraphSeed) for the random nodes, and checks the outputs of the named nodes against the values
+// recorded in a golden file by an earlier run. The values are compared within tolerances, so that the tests guard
+// against the numerical regressions of kernel changes without breaking on every change of the rounding.
+//
+// The golden files are written instead of compared when the test binary is run with the -update-golden flag:
+//
+//	go test ./mymodel -run TestMyModel -update-golden
+//
+// The written files should be reviewed, then committed along with the tests.
+package testutil
@@ -0,0 +1,289 @@
+package testutil
+
+import (
+	"bytes"
+	"encoding/gob"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/pkg/errors"
+	G "gorgonia.org/gorgonia"
+	"gorgonia.org/tensor"
+)
+
+// Update rewrites the golden files instead of comparing the outputs against them. It is set by the -update-golden flag.
+var Update = flag.Bool("update-golden", false, "rewrite the golden files instead of comparing against them")
+
+// DefaultRTol and DefaultATol are the default tolerances of the comparisons. See gorgonia.CompareValues.
+const (
+	DefaultRTol = 1e-5
+	DefaultATol = 1e-8
+)
+
+// Opt is an option of New.
+type Opt func(*Golden)
+
+// WithTolerance sets the relative and absolute tolerances of the comparisons of all the nodes.
+func WithTolerance(rtol, atol float64) Opt {
+	return func(g *Golden) { g.rtol, g.atol = rtol, atol }
+}
+
+// WithNodeTolerance sets the tolerances of the comparisons of the node of the given name, such as an output that
+// accumulates more rounding than the others.
+func WithNodeTolerance(name string, rtol, atol float64) Opt {
+	return func(g *Golden) { g.nodeTols[name] = [2]float64{rtol, atol} }
+}
+
+// WithCompareOpts passes the given options to gorgonia.CompareValues, e.g. NaNEqual for the outputs that may be NaN.
+func WithCompareOpts(opts ...G.CompareOpt) Opt {
+	return func(g *Golden) { g.compareOpts = append(g.compareOpts, opts...) }
+}
+
+// Golden is a directory of golden files. Each golden file holds the outputs of the named nodes of a test.
+type Golden struct {
+	dir         string
+	rtol, atol  float64
+	nodeTols    map[string][2]float64
+	compareOpts []G.CompareOpt
+}
+
+// New creates a Golden of the golden files in dir, which is conventionally "testdata".
+func New(dir string, opts ...Opt) *Golden {
+	g := &Golden{
+		dir:      dir,
+		rtol:     DefaultRTol,
+		atol:     DefaultATol,
+		nodeTols: make(map[string][2]float64),
+	}
+	for _, opt := range opts {
+		opt(g)
+	}
+	return g
+}
+
+// record is the content of a golden file.
+type record struct {
+	Values []recorded
+}
+
+// recorded is a recorded output.
+type recorded struct {
+	Name  string
+	Shape []int
+	Data  interface{} // a slice of the elements in row-major order, of one element for the scalars
+}
+
+// Path returns the path of the golden file of the given name. The slashes of the names of subtests make subdirectories.
+func (g *Golden) Path(name string) string {
+	return filepath.Join(g.dir, filepath.FromSlash(name)+".golden")
+}
+
+// Outputs returns the values of the given nodes by their names. The nodes must have been executed by a VM.
+func Outputs(nodes ...*G.Node) (map[string]G.Value, error) {
+	retVal := make(map[string]G.Value, len(nodes))
+	for _, n := range nodes {
+		if _, ok := retVal[n.Name()]; ok {
+			return nil, errors.Errorf("Two outputs are named %q. Name the nodes with gorgonia.WithName", n.Name())
+		}
+		if n.Value() == nil {
+			return nil, errors.Errorf("%v has no value. Run the graph first", n)
+		}
+		retVal[n.Name()] = n.Value()
+	}
+	return retVal, nil
+}
+
+// Record writes the outputs to the golden file of the given name.
+func (g *Golden) Record(name string, outputs map[string]G.Value) (err error) {
+	var names []string
+	for n := range outputs {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	var rec record
+	for _, n := range names {
+		var r recorded
+		if r, err = flatten(n, outputs[n]); err != nil {
+			return errors.Wrapf(err, "Cannot record %q", n)
+		}
+		rec.Values = append(rec.Values, r)
+	}
+
+	var buf bytes.Buffer
+	if err = gob.NewEncoder(&buf).Encode(rec); err != nil {
+		return errors.Wrapf(err, "Cannot encode the golden file %q", name)
+	}
+	path := g.Path(name)
+	if err = os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return errors.Wrapf(err, "Cannot create the directory of %v", path)
+	}
+	return errors.Wrapf(ioutil.WriteFile(path, buf.Bytes(), 0644), "Cannot write %v", path)
+}
+
+// Load reads the outputs in the golden file of the given name.
+func (g *Golden) Load(name string) (map[string]G.Value, error) {
+	path := g.Path(name)
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Cannot read %v. Run the test with -update-golden to create it", path)
+	}
+	var rec record
+	if err = gob.NewDecoder(bytes.NewReader(b)).Decode(&rec); err != nil {
+		return nil, errors.Wrapf(err, "Cannot decode %v", path)
+	}
+	retVal := make(map[string]G.Value, len(rec.Values))
+	for _, r := range rec.Values {
+		d, size := reflect.ValueOf(r.Data), 1
+		for _, n := range r.Shape {
+			size *= n
+		}
+		if d.Kind() != reflect.Slice || d.Len() != size {
+			return nil, errors.Errorf("%v is corrupt: %q has %v elements of shape %v", path, r.Name, d.Len(), r.Shape)
+		}
+		if len(r.Shape) == 0 {
+			retVal[r.Name] = tensor.New(tensor.FromScalar(d.Index(0).Interface()))
+			continue
+		}
+		retVal[r.Name] = tensor.New(tensor.WithShape(r.Shape...), tensor.WithBacking(r.Data))
+	}
+	return retVal, nil
+}
+
+// Mismatch is an output that does not match its golden value.
+type Mismatch struct {
+	Name string
+	Diff G.ValueDiff
+	Err  error // why the output could not be compared, such as a shape mismatch or a missing output
+}
+
+func (m Mismatch) String() string {
+	if m.Err != nil {
+		return fmt.Sprintf("%q: %v", m.Name, m.Err)
+	}
+	return fmt.Sprintf("%q: %v", m.Name, m.Diff)
+}
+
+// MismatchError is returned by Compare when some outputs do not match their golden values.
+type MismatchError struct {
+	File       string
+	Mismatches []Mismatch
+}
+
+func (e *MismatchError) Error() string {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%d outputs do not match %v:", len(e.Mismatches), e.File)
+	for _, m := range e.Mismatches {
+		fmt.Fprintf(&buf, "\n\t%v", m)
+	}
+	return buf.String()
+}
+
+// Compare compares the outputs with the golden file of the given name. All the recorded outputs must be given, and no
+// other. The mismatches are returned as a *MismatchError.
+func (g *Golden) Compare(name string, outputs map[string]G.Value) error {
+	golden, err := g.Load(name)
+	if err != nil {
+		return err
+	}
+
+	var names []string
+	for n := range golden {
+		names = append(names, n)
+	}
+	for n := range outputs {
+		if _, ok := golden[n]; !ok {
+			names = append(names, n)
+		}
+	}
+	sort.Strings(names)
+
+	var mismatches []Mismatch
+	for _, n := range names {
+		want, ok := golden[n]
+		got, ok2 := outputs[n]
+		switch {
+		case !ok:
+			mismatches = append(mismatches, Mismatch{Name: n, Err: errors.New("not in the golden file")})
+			continue
+		case !ok2:
+			mismatches = append(mismatches, Mismatch{Name: n, Err: errors.New("missing output")})
+			continue
+		}
+		rtol, atol := g.rtol, g.atol
+		if tol, ok := g.nodeTols[n]; ok {
+			rtol, atol = tol[0], tol[1]
+		}
+		diff, err := G.CompareValues(got, want, rtol, atol, g.compareOpts...)
+		if err != nil || !diff.Equal() {
+			mismatches = append(mismatches, Mismatch{Name: n, Diff: diff, Err: err})
+		}
+	}
+	if len(mismatches) > 0 {
+		return &MismatchError{File: g.Path(name), Mismatches: mismatches}
+	}
+	return nil
+}
+
+// Check compares the outputs with the golden file of the given name, or records them with -update-golden, and fails
+// the test on any mismatch.
+func (g *Golden) Check(t testing.TB, name string, outputs map[string]G.Value) {
+	t.Helper()
+	if *Update {
+		if err := g.Record(name, outputs); err != nil {
+			t.Fatal(err)
+		}
+		t.Logf("Updated %v", g.Path(name))
+		return
+	}
+	if err := g.Compare(name, outputs); err != nil {
+		t.Error(err)
+	}
+}
+
+// CheckNodes checks the values of the given nodes, named by the name of the test. See Check.
+func (g *Golden) CheckNodes(t testing.TB, nodes ...*G.Node) {
+	t.Helper()
+	outputs, err := Outputs(nodes...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.Check(t, t.Name(), outputs)
+}
+
+// flatten returns the shape and a copy of the elements of the named value.
+func flatten(name string, v G.Value) (retVal recorded, err error) {
+	retVal = recorded{Name: name, Shape: []int(v.Shape().Clone())}
+	if v.Shape().IsScalar() {
+		retVal.Shape = nil
+	}
+	var data interface{}
+	switch t := v.(type) {
+	case tensor.Tensor:
+		if t.RequiresIterator() {
+			t = tensor.Materialize(t)
+		}
+		data = t.Data()
+	case G.Scalar:
+		data = t.Data()
+	default:
+		return retVal, errors.Errorf("Cannot record a value of %T", v)
+	}
+
+	d := reflect.ValueOf(data)
+	if d.Kind() != reflect.Slice {
+		s := reflect.MakeSlice(reflect.SliceOf(d.Type()), 1, 1)
+		s.Index(0).Set(d)
+		retVal.Data = s.Interface()
+		return retVal, nil
+	}
+	s := reflect.MakeSlice(d.Type(), d.Len(), d.Len())
+	reflect.Copy(s, d)
+	retVal.Data = s.Interface()
+	return retVal, nil
+}
@@ -0,0 +1,92 @@
+package testutil
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	G "gorgonia.org/gorgonia"
+	"gorgonia.org/tensor"
+)
+
+// model runs a small model with a random weight and returns its named outputs.
+func model(t *testing.T) G.Nodes {
+	g := G.NewGraph(G.WithGraphSeed(1337))
+	x := G.NewMatrix(g, G.Float64, G.WithShape(2, 3), G.WithName("x"), G.WithValue(tensor.New(tensor.WithShape(2, 3), tensor.WithBacking([]float64{1, 2, 3, 4, 5, 6}))))
+	w, err := G.RandomNormal(g, G.Float64, 0, 1, tensor.Shape{3, 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	xw := G.Must(G.Mul(x, w))
+	y := G.Must(G.Tanh(xw))
+	G.WithName("y")(y)
+	loss := G.Must(G.Sum(y))
+	G.WithName("loss")(loss)
+
+	m := G.NewTapeMachine(g)
+	defer m.Close()
+	if err = m.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+	return G.Nodes{y, loss}
+}
+
+func i(v int) G.Value {
+	r := G.I(v)
+	return &r
+}
+
+func TestGolden_model(t *testing.T) {
+	New("testdata").CheckNodes(t, model(t)...)
+}
+
+func TestGolden(t *testing.T) {
+	assert := assert.New(t)
+	dir, err := ioutil.TempDir("", "golden")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	a := tensor.New(tensor.WithShape(2, 2), tensor.WithBacking([]float64{1, 2, 3, 4}))
+	golden := New(dir)
+	assert.Error(golden.Compare("sub/test", map[string]G.Value{"a": a}), "there is no golden file yet")
+	if err = golden.Record("sub/test", map[string]G.Value{"a": a, "b": i(3)}); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := golden.Load("sub/test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(loaded, 2)
+	assert.NoError(golden.Compare("sub/test", map[string]G.Value{"a": a.Clone().(G.Value), "b": i(3)}))
+
+	close := tensor.New(tensor.WithShape(2, 2), tensor.WithBacking([]float64{1, 2, 3, 4.000001}))
+	assert.NoError(golden.Compare("sub/test", map[string]G.Value{"a": close, "b": i(3)}), "within the tolerances")
+
+	far := tensor.New(tensor.WithShape(2, 2), tensor.WithBacking([]float64{1, 2.5, 3, 4}))
+	err = golden.Compare("sub/test", map[string]G.Value{"a": far, "c": i(3)})
+	me, ok := errors.Cause(err).(*MismatchError)
+	if !assert.True(ok, "%v", err) {
+		return
+	}
+	if assert.Len(me.Mismatches, 3) {
+		assert.Equal("a", me.Mismatches[0].Name)
+		assert.NoError(me.Mismatches[0].Err)
+		assert.Equal(1, me.Mismatches[0].Diff.First)
+		assert.Equal("b", me.Mismatches[1].Name)
+		assert.Error(me.Mismatches[1].Err, "missing output")
+		assert.Equal("c", me.Mismatches[2].Name)
+		assert.Error(me.Mismatches[2].Err, "not in the golden file")
+	}
+	assert.Contains(err.Error(), "3 outputs do not match")
+
+	loose := New(dir, WithNodeTolerance("a", 0.3, 0))
+	assert.NoError(loose.Compare("sub/test", map[string]G.Value{"a": far, "b": i(3)}))
+	assert.Error(loose.Compare("sub/test", map[string]G.Value{"a": far, "b": i(4)}), "integers are compared exactly")
+
+	_, err = Outputs(G.NewScalar(G.NewGraph(), G.Float64, G.WithName("z")))
+	assert.Error(err, "the node has no value")
+}
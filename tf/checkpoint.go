@@ -0,0 +1,221 @@
+package tf
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gorgonia.org/gorgonia/internal/weights"
+	"gorgonia.org/tensor"
+)
+
+// the DataTypes of types.proto that are read
+var dtypes = map[int]weights.Type{
+	1:  {Dtype: tensor.Float32, Size: 4},                         // DT_FLOAT
+	2:  {Dtype: tensor.Float64, Size: 8},                         // DT_DOUBLE
+	3:  {Dtype: tensor.Int32, Size: 4},                           // DT_INT32
+	4:  {Dtype: tensor.Uint8, Size: 1},                           // DT_UINT8
+	5:  {Dtype: tensor.Int16, Size: 2},                           // DT_INT16
+	6:  {Dtype: tensor.Int8, Size: 1},                            // DT_INT8
+	9:  {Dtype: tensor.Int64, Size: 8},                           // DT_INT64
+	10: {Dtype: tensor.Bool, Size: 1},                            // DT_BOOL
+	14: {Dtype: tensor.Float32, Size: 2, Conv: weights.BFloat16}, // DT_BFLOAT16
+	19: {Dtype: tensor.Float32, Size: 2, Conv: weights.Float16},  // DT_HALF
+}
+
+const dtString = 7 // DT_STRING, such as the object graph of tf.train.Checkpoint, which is skipped
+
+// the suffix of the names of the variables of tf.train.Checkpoint
+const variableValue = "/.ATTRIBUTES/VARIABLE_VALUE"
+
+// ReadCheckpoint reads the variables of the checkpoint of a prefix, which is the path of the files of the checkpoint
+// without their extensions: the prefix "model.ckpt" reads model.ckpt.index and model.ckpt.data-00000-of-00001, and
+// the prefix "model/variables/variables" reads the variables of a SavedModel. The variables are in the order of their
+// names.
+//
+// The variables of tf.train.Checkpoint are named by the paths of the objects that track them, without the suffix
+// "/.ATTRIBUTES/VARIABLE_VALUE", such as "layer_with_weights-0/kernel"; the strings are skipped.
+func ReadCheckpoint(prefix string) (*Variables, error) {
+	vs, err := readCheckpoint(prefix)
+	return vs, errors.Wrapf(err, "Cannot read the checkpoint %v", prefix)
+}
+
+func readCheckpoint(prefix string) (*Variables, error) {
+	index, err := ioutil.ReadFile(prefix + ".index")
+	if err != nil {
+		return nil, err
+	}
+	var header *bundleHeader
+	var names []string
+	entries := make(map[string]*bundleEntry)
+	err = readTable(index, func(key string, value []byte) error {
+		if key == "" {
+			header = new(bundleHeader)
+			return errors.Wrap(header.unmarshal(value), "The header of the index")
+		}
+		e := new(bundleEntry)
+		if err := e.unmarshal(value); err != nil {
+			return errors.Wrapf(err, "Variable %q", key)
+		}
+		names = append(names, key)
+		entries[key] = e
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if header == nil {
+		return nil, errors.New("The index has no header: it is not the index of a checkpoint of the V2 format")
+	}
+
+	order := binary.ByteOrder(binary.LittleEndian)
+	if header.bigEndian {
+		order = binary.BigEndian
+	}
+	shards := make(map[int]*os.File)
+	defer func() {
+		for _, f := range shards {
+			f.Close()
+		}
+	}()
+	vs := &Variables{Tensors: make(map[string]*tensor.Dense)}
+	for _, key := range names {
+		e := entries[key]
+		if e.dtype == dtString {
+			continue
+		}
+		if e.slices > 0 {
+			return nil, errors.Errorf("The variable %q is partitioned: the partitioned variables are not supported", key)
+		}
+		typ, ok := dtypes[e.dtype]
+		if !ok {
+			return nil, errors.Errorf("The variable %q is of the DataType %d, which is not supported", key, e.dtype)
+		}
+		if e.shard < 0 || e.shard >= header.numShards {
+			return nil, errors.Errorf("The variable %q is in the shard %d of %d", key, e.shard, header.numShards)
+		}
+		f, ok := shards[e.shard]
+		if !ok {
+			if f, err = os.Open(fmt.Sprintf("%s.data-%05d-of-%05d", prefix, e.shard, header.numShards)); err != nil {
+				return nil, err
+			}
+			shards[e.shard] = f
+		}
+		p := make([]byte, e.size)
+		if _, err = f.ReadAt(p, e.offset); err != nil {
+			return nil, errors.Wrapf(err, "Cannot read the variable %q", key)
+		}
+		if e.crc32c != 0 && mask(crc32.Checksum(p, castagnoli)) != e.crc32c {
+			return nil, errors.Errorf("The checksum of the variable %q does not match", key)
+		}
+		t, err := typ.Decode(p, order, e.shape)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Variable %q", key)
+		}
+		vs.add(strings.TrimSuffix(key, variableValue), t)
+	}
+	if len(vs.Names) == 0 {
+		return nil, errors.New("The checkpoint has no variables")
+	}
+	return vs, nil
+}
+
+/* the tables of the indices, which are those of LevelDB */
+
+const (
+	tableMagic  = 0xdb4775248b80fb57
+	footerSize  = 48
+	trailerSize = 5 // of a block: the type of its compression, and its checksum
+)
+
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// mask masks a checksum, as the checksums of the tables and of the bundles are stored.
+func mask(crc uint32) uint32 { return (crc>>15 | crc<<17) + 0xa282ead8 }
+
+// readTable calls fn with the keys and the values of a table, in order.
+func readTable(p []byte, fn func(key string, value []byte) error) error {
+	if len(p) < footerSize || binary.LittleEndian.Uint64(p[len(p)-8:]) != tableMagic {
+		return errors.New("The index is not a table")
+	}
+	footer := p[len(p)-footerSize:]
+	// the handle of the metaindex block, which is not read, then the handle of the index block
+	var handles [4]uint64
+	for i := range handles {
+		v, n := binary.Uvarint(footer)
+		if n <= 0 {
+			return errors.New("The footer of the table is malformed")
+		}
+		handles[i], footer = v, footer[n:]
+	}
+	index, err := block(p, handles[2], handles[3])
+	if err != nil {
+		return errors.Wrap(err, "The index block")
+	}
+	return blockEntries(index, func(_ string, handle []byte) error {
+		off, n := binary.Uvarint(handle)
+		if n <= 0 {
+			return errors.New("The handle of a block is malformed")
+		}
+		size, m := binary.Uvarint(handle[n:])
+		if m <= 0 {
+			return errors.New("The handle of a block is malformed")
+		}
+		data, err := block(p, off, size)
+		if err != nil {
+			return err
+		}
+		return blockEntries(data, fn)
+	})
+}
+
+// block returns the block of the handle, whose checksum is verified.
+func block(p []byte, off, size uint64) ([]byte, error) {
+	if off+size+trailerSize > uint64(len(p)) {
+		return nil, errors.Errorf("The block of %d bytes at %d is out of the table", size, off)
+	}
+	b := p[off : off+size+1]
+	if c := b[size]; c != 0 {
+		return nil, errors.Errorf("The block is compressed (%d): the compressed tables are not supported", c)
+	}
+	if mask(crc32.Checksum(b, castagnoli)) != binary.LittleEndian.Uint32(p[off+size+1:]) {
+		return nil, errors.New("The checksum of the block does not match")
+	}
+	return b[:size], nil
+}
+
+// blockEntries calls fn with the entries of a block, whose keys share their prefixes with the keys before them.
+func blockEntries(b []byte, fn func(key string, value []byte) error) error {
+	if len(b) < 4 {
+		return errors.New("The block is truncated")
+	}
+	restarts := uint64(binary.LittleEndian.Uint32(b[len(b)-4:]))
+	if (restarts+1)*4 > uint64(len(b)) {
+		return errors.New("The block is truncated")
+	}
+	b = b[:uint64(len(b))-(restarts+1)*4]
+	var key []byte
+	for len(b) > 0 {
+		var hdr [3]uint64 // the length of the shared prefix, of the rest of the key, and of the value
+		for i := range hdr {
+			v, n := binary.Uvarint(b)
+			if n <= 0 {
+				return errors.New("The entry of a block is malformed")
+			}
+			hdr[i], b = v, b[n:]
+		}
+		if hdr[0] > uint64(len(key)) || hdr[1]+hdr[2] > uint64(len(b)) {
+			return errors.New("The entry of a block is malformed")
+		}
+		key = append(key[:hdr[0]], b[:hdr[1]]...)
+		if err := fn(string(key), b[hdr[1]:hdr[1]+hdr[2]]); err != nil {
+			return err
+		}
+		b = b[hdr[1]+hdr[2]:]
+	}
+	return nil
+}
@@ -0,0 +1,273 @@
+package tf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorgonia.org/tensor"
+)
+
+// message encodes the fields of a protobuf message, of the given numbers: the uint64s are varints, the uint32s are
+// fixed32s, and the []bytes are length-delimited.
+func message(fields ...interface{}) []byte {
+	var buf bytes.Buffer
+	for i := 0; i < len(fields); i += 2 {
+		n := uint64(fields[i].(int))
+		switch v := fields[i+1].(type) {
+		case uint64:
+			uvarint(&buf, n<<3)
+			uvarint(&buf, v)
+		case uint32:
+			uvarint(&buf, n<<3|5)
+			binary.Write(&buf, binary.LittleEndian, v)
+		case []byte:
+			uvarint(&buf, n<<3|2)
+			uvarint(&buf, uint64(len(v)))
+			buf.Write(v)
+		}
+	}
+	return buf.Bytes()
+}
+
+func uvarint(buf *bytes.Buffer, v uint64) {
+	p := make([]byte, binary.MaxVarintLen64)
+	buf.Write(p[:binary.PutUvarint(p, v)])
+}
+
+type entry struct {
+	key   string
+	value []byte
+}
+
+// writeBlock writes a block of the entries, with a restart point every two entries, and returns its handle.
+func writeBlock(buf *bytes.Buffer, entries []entry, compression byte) []byte {
+	var b bytes.Buffer
+	var restarts []uint32
+	last := ""
+	for i, e := range entries {
+		shared := 0
+		if i%2 == 0 {
+			restarts = append(restarts, uint32(b.Len()))
+		} else {
+			for shared < len(last) && shared < len(e.key) && last[shared] == e.key[shared] {
+				shared++
+			}
+		}
+		uvarint(&b, uint64(shared))
+		uvarint(&b, uint64(len(e.key)-shared))
+		uvarint(&b, uint64(len(e.value)))
+		b.WriteString(e.key[shared:])
+		b.Write(e.value)
+		last = e.key
+	}
+	if len(restarts) == 0 {
+		restarts = []uint32{0}
+	}
+	binary.Write(&b, binary.LittleEndian, restarts)
+	binary.Write(&b, binary.LittleEndian, uint32(len(restarts)))
+
+	var handle bytes.Buffer
+	uvarint(&handle, uint64(buf.Len()))
+	uvarint(&handle, uint64(b.Len()))
+	b.WriteByte(compression)
+	buf.Write(b.Bytes())
+	binary.Write(buf, binary.LittleEndian, mask(crc32.Checksum(b.Bytes(), castagnoli)))
+	return handle.Bytes()
+}
+
+// table returns the table of the entries, whose keys are sorted.
+func table(entries []entry, compression byte) []byte {
+	var buf bytes.Buffer
+	data := writeBlock(&buf, entries, compression)
+	meta := writeBlock(&buf, nil, 0)
+	index := writeBlock(&buf, []entry{{key: entries[len(entries)-1].key, value: data}}, 0)
+	footer := append(append([]byte{}, meta...), index...)
+	footer = append(footer, make([]byte, footerSize-8-len(footer))...)
+	buf.Write(footer)
+	binary.Write(&buf, binary.LittleEndian, uint64(tableMagic))
+	return buf.Bytes()
+}
+
+// savedVariable is a variable of a checkpoint.
+type savedVariable struct {
+	name   string
+	dtype  int
+	shape  []int
+	shard  int
+	data   []byte
+	sliced bool
+}
+
+// writeCheckpoint writes the files of a checkpoint of the variables, and returns its prefix.
+func writeCheckpoint(t *testing.T, dir string, shards int, vars ...savedVariable) string {
+	prefix := filepath.Join(dir, "model.ckpt")
+	data := make([]bytes.Buffer, shards)
+	entries := []entry{{value: message(1, uint64(shards))}}
+	for _, v := range vars {
+		var shape []interface{}
+		for _, d := range v.shape {
+			shape = append(shape, 2, message(1, uint64(d)))
+		}
+		fields := []interface{}{
+			1, uint64(v.dtype),
+			2, message(shape...),
+			3, uint64(v.shard),
+			4, uint64(data[v.shard].Len()),
+			5, uint64(len(v.data)),
+			6, mask(crc32.Checksum(v.data, castagnoli)),
+		}
+		if v.sliced {
+			fields = append(fields, 7, []byte{})
+		}
+		entries = append(entries, entry{key: v.name, value: message(fields...)})
+		data[v.shard].Write(v.data)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].key < entries[j].key })
+	if err := ioutil.WriteFile(prefix+".index", table(entries, 0), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for i := range data {
+		if err := ioutil.WriteFile(fmt.Sprintf("%s.data-%05d-of-%05d", prefix, i, shards), data[i].Bytes(), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return prefix
+}
+
+func encode(order binary.ByteOrder, vs interface{}) []byte {
+	var buf bytes.Buffer
+	binary.Write(&buf, order, vs)
+	return buf.Bytes()
+}
+
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "tf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestReadCheckpoint(t *testing.T) {
+	assert := assert.New(t)
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	le := binary.LittleEndian
+	prefix := writeCheckpoint(t, dir, 2,
+		savedVariable{name: "dense/kernel", dtype: 1, shape: []int{2, 3}, data: encode(le, []float32{1, 2, 3, 4, 5, 6})},
+		savedVariable{name: "dense/bias", dtype: 1, shape: []int{3}, shard: 1, data: encode(le, []float32{0.5, -0.5, 0})},
+		savedVariable{name: "global_step", dtype: 9, data: encode(le, int64(42))},
+		savedVariable{name: "half", dtype: 19, shape: []int{2}, data: []byte{0x00, 0x3c, 0x00, 0xc0}},
+		savedVariable{name: "layer_with_weights-0/kernel/.ATTRIBUTES/VARIABLE_VALUE", dtype: 2, shape: []int{2}, data: encode(le, []float64{math.Pi, 1})},
+		savedVariable{name: "_CHECKPOINTABLE_OBJECT_GRAPH", dtype: dtString, data: []byte{3, 'a', 'b', 'c'}},
+	)
+
+	vs, err := ReadCheckpoint(prefix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]string{"dense/bias", "dense/kernel", "global_step", "half", "layer_with_weights-0/kernel"}, vs.Names)
+	assert.Equal(tensor.Shape{2, 3}, vs.Tensor("dense/kernel").Shape())
+	assert.Equal([]float32{1, 2, 3, 4, 5, 6}, vs.Tensor("dense/kernel").Data())
+	assert.Equal([]float32{0.5, -0.5, 0}, vs.Tensor("dense/bias").Data())
+	assert.Equal(int64(42), vs.Tensor("global_step").Data())
+	assert.Equal([]float32{1, -2}, vs.Tensor("half").Data())
+	assert.Equal([]float64{math.Pi, 1}, vs.Tensor("layer_with_weights-0/kernel").Data())
+	assert.Nil(vs.Tensor("_CHECKPOINTABLE_OBJECT_GRAPH"))
+}
+
+func TestReadCheckpoint_errors(t *testing.T) {
+	assert := assert.New(t)
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	w := savedVariable{name: "w", dtype: 1, shape: []int{2}, data: encode(binary.LittleEndian, []float32{1, 2})}
+
+	_, err := ReadCheckpoint(filepath.Join(dir, "nope"))
+	assert.Error(err, "no index")
+
+	prefix := writeCheckpoint(t, dir, 1, w)
+	index, _ := ioutil.ReadFile(prefix + ".index")
+	broken := append([]byte{}, index...)
+	broken[3] ^= 0xff
+	ioutil.WriteFile(prefix+".index", broken, 0644)
+	_, err = ReadCheckpoint(prefix)
+	assert.Error(err, "a corrupted block")
+	ioutil.WriteFile(prefix+".index", index[:len(index)-1], 0644)
+	_, err = ReadCheckpoint(prefix)
+	assert.Error(err, "not a table")
+	ioutil.WriteFile(prefix+".index", table([]entry{{key: "w", value: message(1, uint64(1))}}, 1), 0644)
+	_, err = ReadCheckpoint(prefix)
+	if assert.Error(err, "a compressed table") {
+		assert.Contains(err.Error(), "compressed")
+	}
+	ioutil.WriteFile(prefix+".index", table([]entry{{key: "w", value: message(1, uint64(1))}}, 0), 0644)
+	_, err = ReadCheckpoint(prefix)
+	assert.Error(err, "no header")
+
+	prefix = writeCheckpoint(t, dir, 1, w)
+	ioutil.WriteFile(prefix+".data-00000-of-00001", encode(binary.LittleEndian, []float32{1, 3}), 0644)
+	_, err = ReadCheckpoint(prefix)
+	assert.Error(err, "a corrupted variable")
+	os.Remove(prefix + ".data-00000-of-00001")
+	_, err = ReadCheckpoint(prefix)
+	assert.Error(err, "no data")
+
+	sliced := w
+	sliced.sliced = true
+	_, err = ReadCheckpoint(writeCheckpoint(t, dir, 1, sliced))
+	if assert.Error(err) {
+		assert.Contains(err.Error(), "partitioned")
+	}
+	complex := w
+	complex.dtype = 8
+	_, err = ReadCheckpoint(writeCheckpoint(t, dir, 1, complex))
+	assert.Error(err, "an unsupported DataType")
+	short := w
+	short.shape = []int{3}
+	_, err = ReadCheckpoint(writeCheckpoint(t, dir, 1, short))
+	assert.Error(err, "a short variable")
+}
+
+func TestReadCheckpoint_corrupt(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	le := binary.LittleEndian
+	prefix := writeCheckpoint(t, dir, 1,
+		savedVariable{name: "dense/kernel", dtype: 1, shape: []int{2, 3}, data: encode(le, []float32{1, 2, 3, 4, 5, 6})},
+		savedVariable{name: "global_step", dtype: 9, data: encode(le, int64(42))},
+	)
+	// every byte of the index and of the data is corrupted in turn: the errors are returned, and nothing is
+	// allocated from the corrupted sizes
+	for _, name := range []string{prefix + ".index", prefix + ".data-00000-of-00001"} {
+		p, err := ioutil.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := range p {
+			for _, b := range []byte{0xff, 0x7f, 0} {
+				c := append([]byte{}, p...)
+				c[i] = b
+				if err = ioutil.WriteFile(name, c, 0644); err != nil {
+					t.Fatal(err)
+				}
+				if vs, err := ReadCheckpoint(prefix); err == nil {
+					for _, n := range vs.Names {
+						vs.Tensor(n)
+					}
+				}
+			}
+		}
+		if err = ioutil.WriteFile(name, p, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,20 @@
+// Package tf reads the weights of TensorFlow and Keras models, to assign them to the parameters of the same models
+// rebuilt with Gorgonia. The files are the checkpoints of tf.train.Saver and tf.train.Checkpoint, such as the
+// variables/variables.index of a SavedModel, and the HDF5 files of the weights of Keras, such as the .h5 files of
+// model.save_weights:
+//
+//	vars, err := tf.ReadCheckpoint("model/variables/variables")
+//	...
+//	// the graph rebuilds the architecture, with the parameters named as the variables: "dense/kernel", ...
+//	a, err := vars.Assign(g.Learnables(), tf.Strict())
+//
+// The variables are named as in TensorFlow, whose scopes are those of Gorgonia: the variable "dense/kernel" of a
+// checkpoint, or the weight "dense/kernel:0" of a Keras file, is assigned to the parameter "dense/kernel" (see
+// gorgonia.Scope).
+// The kernels of the Dense layers are of the shape (in, out), as the weights of x×W.
+//
+// Only the checkpoints of the V2 format, whose tables are not compressed, and of the variables that are not
+// partitioned, are read. Only the datasets of the HDF5 files that are contiguous or compact are read: the chunked,
+// and so the compressed, datasets are not supported. The tensors of half precision and of bfloat16 are read as
+// float32.
+package tf
@@ -0,0 +1,644 @@
+package tf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gorgonia.org/gorgonia/internal/weights"
+	"gorgonia.org/tensor"
+)
+
+// ReadH5 reads the weights of a Keras HDF5 file, such as the .h5 file of model.save_weights or of model.save. The
+// weights are named as in Keras, such as "dense/kernel:0", in the order of the groups of the file, which are sorted by
+// name; the optimizer weights of the files of model.save are skipped.
+func ReadH5(path string) (*Variables, error) {
+	p, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	vs, err := readH5(p)
+	return vs, errors.Wrapf(err, "Cannot read the weights of %v", path)
+}
+
+// the datasets of the layers of Keras are in the groups of the layers: the weight "dense/kernel:0" is the dataset
+// "dense/dense/kernel:0", which is in "model_weights" in the files of model.save.
+const modelWeights = "model_weights"
+
+func readH5(p []byte) (*Variables, error) {
+	f, root, err := openH5(p)
+	if err != nil {
+		return nil, err
+	}
+	if o, err := f.object(root); err != nil {
+		return nil, err
+	} else if links, err := f.links(o); err != nil {
+		return nil, err
+	} else {
+		for _, l := range links {
+			if l.name == modelWeights {
+				root = l.addr
+			}
+		}
+	}
+
+	vs := &Variables{Tensors: make(map[string]*tensor.Dense)}
+	err = f.walk(root, "", make(map[uint64]bool), func(path string, o *h5Object) error {
+		t, err := f.dataset(o)
+		if err != nil {
+			return errors.Wrapf(err, "Dataset %q", path)
+		}
+		if parts := strings.SplitN(path, "/", 3); len(parts) == 3 && parts[0] == parts[1] {
+			path = parts[1] + "/" + parts[2]
+		}
+		vs.add(path, t)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(vs.Names) == 0 {
+		return nil, errors.New("The file has no datasets")
+	}
+	return vs, nil
+}
+
+/* HDF5 */
+
+var h5Signature = []byte("\x89HDF\r\n\x1a\n")
+
+// h5File is an HDF5 file. The addresses of the file are relative to its base.
+type h5File struct {
+	p                      []byte
+	base                   uint64
+	offsetSize, lengthSize int
+}
+
+// openH5 reads the superblock of an HDF5 file, and returns the address of the object header of its root group.
+func openH5(p []byte) (*h5File, uint64, error) {
+	// the superblock is after the user block, at 0, 512, 1024, 2048...
+	start := -1
+	for off := 0; off+len(h5Signature) <= len(p); off = max(512, off*2) {
+		if bytes.Equal(p[off:off+len(h5Signature)], h5Signature) {
+			start = off
+			break
+		}
+	}
+	if start < 0 {
+		return nil, 0, errors.New("The file is not an HDF5 file")
+	}
+	f := &h5File{p: p}
+	r := &h5Reader{f: f, p: p[start+len(h5Signature):]}
+	version := r.u8()
+	var root uint64
+	switch version {
+	case 0, 1:
+		r.skip(4) // the versions of the free-space storage, of the root group symbol table, and of the shared messages
+		f.offsetSize, f.lengthSize = int(r.u8()), int(r.u8())
+		r.skip(1 + 4 + 4) // the Ks of the B-trees of the groups, and the flags
+		if version == 1 {
+			r.skip(4)
+		}
+		if !f.validSizes() {
+			return nil, 0, errors.Errorf("The sizes of the offsets and lengths %d and %d are not supported", f.offsetSize, f.lengthSize)
+		}
+		f.base = r.offset()
+		r.skip(3 * f.offsetSize) // the addresses of the free space, of the end of the file, and of the driver information
+		// the symbol table entry of the root group
+		r.offset()
+		root = r.offset()
+	case 2, 3:
+		f.offsetSize, f.lengthSize = int(r.u8()), int(r.u8())
+		r.skip(1)
+		if !f.validSizes() {
+			return nil, 0, errors.Errorf("The sizes of the offsets and lengths %d and %d are not supported", f.offsetSize, f.lengthSize)
+		}
+		f.base = r.offset()
+		r.skip(2 * f.offsetSize) // the addresses of the superblock extension, and of the end of the file
+		root = r.offset()
+	default:
+		return nil, 0, errors.Errorf("The version %d of the superblock is not supported", version)
+	}
+	if r.err != nil {
+		return nil, 0, errors.Wrap(r.err, "The superblock")
+	}
+	if f.base == 0 && start > 0 {
+		f.base = uint64(start)
+	}
+	return f, root, nil
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func (f *h5File) validSizes() bool {
+	valid := func(n int) bool { return n == 2 || n == 4 || n == 8 }
+	return valid(f.offsetSize) && valid(f.lengthSize)
+}
+
+func (f *h5File) undefined(addr uint64) bool {
+	return addr == ^uint64(0)>>(64-8*uint(f.offsetSize))
+}
+
+// at returns the n bytes at an address.
+func (f *h5File) at(addr, n uint64) ([]byte, error) {
+	off := f.base + addr
+	if f.undefined(addr) || off < addr || off+n < off || off+n > uint64(len(f.p)) {
+		return nil, errors.Errorf("The %d bytes at %#x are out of the file", n, addr)
+	}
+	return f.p[off : off+n], nil
+}
+
+// reader returns a reader of the bytes from an address to the end of the file.
+func (f *h5File) reader(addr uint64) *h5Reader {
+	p, err := f.at(addr, 0)
+	if err == nil {
+		p = f.p[f.base+addr:]
+	}
+	return &h5Reader{f: f, p: p, err: err}
+}
+
+// h5Reader reads the little endian fields of a structure of an HDF5 file. The first error sticks, and the fields read
+// after it are zeroes.
+type h5Reader struct {
+	f   *h5File
+	p   []byte
+	err error
+}
+
+func (r *h5Reader) bytes(n uint64) []byte {
+	if r.err == nil && n > uint64(len(r.p)) {
+		r.err = errors.New("The structure is truncated")
+	}
+	if r.err != nil {
+		return nil
+	}
+	b := r.p[:n]
+	r.p = r.p[n:]
+	return b
+}
+
+func (r *h5Reader) skip(n int) { r.bytes(uint64(n)) }
+
+// uint reads an unsigned integer of n bytes.
+func (r *h5Reader) uint(n int) uint64 {
+	var v uint64
+	for i, b := range r.bytes(uint64(n)) {
+		v |= uint64(b) << (8 * uint(i))
+	}
+	return v
+}
+
+func (r *h5Reader) u8() int        { return int(r.uint(1)) }
+func (r *h5Reader) u16() int       { return int(r.uint(2)) }
+func (r *h5Reader) offset() uint64 { return r.uint(r.f.offsetSize) }
+func (r *h5Reader) length() uint64 { return r.uint(r.f.lengthSize) }
+
+func (r *h5Reader) signature(s string) {
+	if b := r.bytes(uint64(len(s))); r.err == nil && string(b) != s {
+		r.err = errors.Errorf("Expected the signature %q. Got %q instead", s, b)
+	}
+}
+
+/* object headers */
+
+// the types of the header messages that are read
+const (
+	msgDataspace    = 0x01
+	msgLinkInfo     = 0x02
+	msgDatatype     = 0x03
+	msgLink         = 0x06
+	msgLayout       = 0x08
+	msgContinuation = 0x10
+	msgSymbolTable  = 0x11
+)
+
+type h5Message struct {
+	typ, flags int
+	data       []byte
+}
+
+// h5Object is the header of an object, such as a group or a dataset.
+type h5Object struct {
+	addr          uint64
+	messages      []h5Message
+	continuations int
+}
+
+func (o *h5Object) message(typ int) *h5Message {
+	for i := range o.messages {
+		if o.messages[i].typ == typ {
+			return &o.messages[i]
+		}
+	}
+	return nil
+}
+
+// object reads the messages of the object header at an address, of the version 1 or 2, and of its continuations.
+func (f *h5File) object(addr uint64) (*h5Object, error) {
+	o := &h5Object{addr: addr}
+	r := f.reader(addr)
+	if len(r.p) >= 4 && string(r.p[:4]) == "OHDR" {
+		r.skip(4)
+		if v := r.u8(); v != 2 {
+			return nil, errors.Errorf("The version %d of the object header at %#x is not supported", v, addr)
+		}
+		flags := r.u8()
+		if flags&0x20 != 0 {
+			r.skip(16) // the times
+		}
+		if flags&0x10 != 0 {
+			r.skip(4) // the phase change values of the attributes
+		}
+		size := r.uint(1 << uint(flags&3))
+		if err := o.read2(f, r.bytes(size), flags); err != nil {
+			return nil, err
+		}
+	} else {
+		if v := r.u8(); v != 1 && r.err == nil {
+			return nil, errors.Errorf("The version %d of the object header at %#x is not supported", v, addr)
+		}
+		r.skip(1 + 2 + 4) // the number of messages, and the reference count
+		size := r.uint(4)
+		r.skip(4) // aligned to 8 bytes
+		if err := o.read1(f, r.bytes(size)); err != nil {
+			return nil, err
+		}
+	}
+	if r.err != nil {
+		return nil, errors.Wrapf(r.err, "The object header at %#x", addr)
+	}
+	return o, nil
+}
+
+func (o *h5Object) read1(f *h5File, p []byte) error {
+	r := &h5Reader{f: f, p: p}
+	for len(r.p) >= 8 && r.err == nil {
+		typ := r.u16()
+		size := r.u16()
+		flags := r.u8()
+		r.skip(3)
+		if err := o.add(f, typ, flags, r.bytes(uint64(size)), false); err != nil {
+			return err
+		}
+	}
+	return r.err
+}
+
+func (o *h5Object) read2(f *h5File, p []byte, flags int) error {
+	r := &h5Reader{f: f, p: p}
+	hdr := 4
+	if flags&0x04 != 0 {
+		hdr += 2
+	}
+	for len(r.p) >= hdr && r.err == nil {
+		typ := r.u8()
+		size := r.u16()
+		mflags := r.u8()
+		if flags&0x04 != 0 {
+			r.skip(2) // the creation order
+		}
+		if err := o.add(f, typ, mflags, r.bytes(uint64(size)), true); err != nil {
+			return err
+		}
+	}
+	return r.err
+}
+
+// add adds a message, and reads the continuation of the messages.
+func (o *h5Object) add(f *h5File, typ, flags int, data []byte, v2 bool) error {
+	if typ != msgContinuation {
+		o.messages = append(o.messages, h5Message{typ: typ, flags: flags, data: data})
+		return nil
+	}
+	if o.continuations++; o.continuations > 1024 {
+		return errors.Errorf("The object header at %#x has too many continuations", o.addr)
+	}
+	r := &h5Reader{f: f, p: data}
+	addr, size := r.offset(), r.length()
+	if r.err != nil {
+		return r.err
+	}
+	p, err := f.at(addr, size)
+	if err != nil {
+		return errors.Wrap(err, "The continuation of an object header")
+	}
+	if !v2 {
+		return o.read1(f, p)
+	}
+	if len(p) < 8 || string(p[:4]) != "OCHK" {
+		return errors.Errorf("The continuation at %#x is not an OCHK", addr)
+	}
+	return o.read2(f, p[4:len(p)-4], 0) // the flags of the creation order are those of the header
+}
+
+/* groups */
+
+type h5Link struct {
+	name string
+	addr uint64
+}
+
+// links returns the hard links of a group, which are either in a symbol table, or in the link messages. It returns
+// nothing if the object is not a group.
+func (f *h5File) links(o *h5Object) ([]h5Link, error) {
+	if m := o.message(msgSymbolTable); m != nil {
+		r := &h5Reader{f: f, p: m.data}
+		btree, heap := r.offset(), r.offset()
+		if r.err != nil {
+			return nil, r.err
+		}
+		names, err := f.localHeap(heap)
+		if err != nil {
+			return nil, err
+		}
+		var links []h5Link
+		err = f.groupTree(btree, names, func(l h5Link) { links = append(links, l) }, 0)
+		return links, err
+	}
+
+	if m := o.message(msgLinkInfo); m != nil {
+		r := &h5Reader{f: f, p: m.data}
+		r.skip(1)
+		if flags := r.u8(); flags&1 != 0 {
+			r.skip(8)
+		}
+		if heap := r.offset(); r.err == nil && !f.undefined(heap) {
+			return nil, errors.Errorf("The group at %#x stores its links in a fractal heap, which is not supported", o.addr)
+		}
+	}
+	var links []h5Link
+	for _, m := range o.messages {
+		if m.typ != msgLink {
+			continue
+		}
+		r := &h5Reader{f: f, p: m.data}
+		r.skip(1) // the version
+		flags := r.u8()
+		typ := 0
+		if flags&0x08 != 0 {
+			typ = r.u8()
+		}
+		if flags&0x04 != 0 {
+			r.skip(8) // the creation order
+		}
+		if flags&0x10 != 0 {
+			r.skip(1) // the character set
+		}
+		name := string(r.bytes(r.uint(1 << uint(flags&3))))
+		addr := r.offset()
+		if r.err != nil {
+			return nil, errors.Wrapf(r.err, "A link of the group at %#x", o.addr)
+		}
+		if typ == 0 { // the soft and the external links are not followed
+			links = append(links, h5Link{name: name, addr: addr})
+		}
+	}
+	return links, nil
+}
+
+// localHeap returns the data segment of a local heap, which holds the names of the links of a symbol table.
+func (f *h5File) localHeap(addr uint64) ([]byte, error) {
+	r := f.reader(addr)
+	r.signature("HEAP")
+	r.skip(4)
+	size := r.length()
+	r.length() // the free list
+	data := r.offset()
+	if r.err != nil {
+		return nil, errors.Wrapf(r.err, "The local heap at %#x", addr)
+	}
+	return f.at(data, size)
+}
+
+// groupTree calls fn with the links of the symbol table nodes of a B-tree of the version 1.
+func (f *h5File) groupTree(addr uint64, names []byte, fn func(h5Link), depth int) error {
+	if depth > 64 {
+		return errors.New("The B-tree of a group is too deep")
+	}
+	r := f.reader(addr)
+	r.signature("TREE")
+	if typ := r.u8(); typ != 0 && r.err == nil {
+		return errors.Errorf("The B-tree at %#x is not the B-tree of a group", addr)
+	}
+	level := r.u8()
+	entries := r.u16()
+	r.skip(2 * f.offsetSize) // the siblings
+	children := make([]uint64, 0, entries)
+	for i := 0; i < entries; i++ {
+		r.length() // the key: the offset of a name in the heap
+		children = append(children, r.offset())
+	}
+	if r.err != nil {
+		return errors.Wrapf(r.err, "The B-tree at %#x", addr)
+	}
+	for _, child := range children {
+		var err error
+		if level > 0 {
+			err = f.groupTree(child, names, fn, depth+1)
+		} else {
+			err = f.symbolNode(child, names, fn)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// symbolNode calls fn with the links of the entries of a symbol table node.
+func (f *h5File) symbolNode(addr uint64, names []byte, fn func(h5Link)) error {
+	r := f.reader(addr)
+	r.signature("SNOD")
+	r.skip(2)
+	n := r.u16()
+	for i := 0; i < n; i++ {
+		name := r.length()
+		obj := r.offset()
+		r.skip(4 + 4 + 16) // the cache type, and the scratch-pad
+		if r.err != nil {
+			break
+		}
+		if name >= uint64(len(names)) {
+			return errors.Errorf("The name of an entry of the symbol table node at %#x is out of the heap", addr)
+		}
+		s := names[name:]
+		if i := bytes.IndexByte(s, 0); i >= 0 {
+			s = s[:i]
+		}
+		fn(h5Link{name: string(s), addr: obj})
+	}
+	return errors.Wrapf(r.err, "The symbol table node at %#x", addr)
+}
+
+// walk calls fn with the datasets of the group at an address, and of its groups, named by their paths.
+func (f *h5File) walk(addr uint64, path string, visited map[uint64]bool, fn func(string, *h5Object) error) error {
+	if visited[addr] {
+		return nil
+	}
+	visited[addr] = true
+	o, err := f.object(addr)
+	if err != nil {
+		return err
+	}
+	if o.message(msgLayout) != nil {
+		return fn(path, o)
+	}
+	links, err := f.links(o)
+	if err != nil {
+		return err
+	}
+	for _, l := range links {
+		name := l.name
+		if path != "" {
+			name = path + "/" + name
+		}
+		if err = f.walk(l.addr, name, visited, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/* datasets */
+
+// dataset reads the tensor of a dataset.
+func (f *h5File) dataset(o *h5Object) (*tensor.Dense, error) {
+	for _, typ := range []int{msgDataspace, msgDatatype, msgLayout} {
+		m := o.message(typ)
+		if m == nil {
+			return nil, errors.Errorf("The dataset has no message %#x", typ)
+		}
+		if m.flags&0x02 != 0 {
+			return nil, errors.Errorf("The message %#x of the dataset is shared, which is not supported", typ)
+		}
+	}
+	shape, err := f.dataspace(o.message(msgDataspace).data)
+	if err != nil {
+		return nil, err
+	}
+	typ, order, err := datatype(o.message(msgDatatype).data)
+	if err != nil {
+		return nil, err
+	}
+	p, err := f.layout(o.message(msgLayout).data)
+	if err != nil {
+		return nil, err
+	}
+	size := uint64(typ.Size)
+	for _, d := range shape {
+		size *= uint64(d)
+	}
+	if uint64(len(p)) < size {
+		return nil, errors.Errorf("The dataset has %d bytes of data. Expected %d", len(p), size)
+	}
+	return typ.Decode(p[:size], order, shape)
+}
+
+func (f *h5File) dataspace(p []byte) ([]int, error) {
+	r := &h5Reader{f: f, p: p}
+	version := r.u8()
+	rank := r.u8()
+	r.skip(1) // the flags
+	switch version {
+	case 1:
+		r.skip(5)
+	case 2:
+		if typ := r.u8(); typ == 2 {
+			return nil, errors.New("The dataspace is null")
+		}
+	default:
+		return nil, errors.Errorf("The version %d of the dataspace is not supported", version)
+	}
+	shape := make([]int, rank)
+	for i := range shape {
+		shape[i] = int(r.length())
+	}
+	return shape, r.err
+}
+
+// datatype returns the type of the elements of a dataset, and their byte order.
+func datatype(p []byte) (weights.Type, binary.ByteOrder, error) {
+	if len(p) < 8 {
+		return weights.Type{}, nil, errors.New("The datatype is truncated")
+	}
+	class, bits := p[0]&0x0f, p[1]
+	size := int(binary.LittleEndian.Uint32(p[4:]))
+	order := binary.ByteOrder(binary.LittleEndian)
+	if bits&1 != 0 {
+		order = binary.BigEndian
+	}
+	signed := bits&0x08 != 0
+	switch {
+	case class == 1 && size == 8: // floating point
+		return weights.Type{Dtype: tensor.Float64, Size: 8}, order, nil
+	case class == 1 && size == 4:
+		return weights.Type{Dtype: tensor.Float32, Size: 4}, order, nil
+	case class == 1 && size == 2:
+		return weights.Type{Dtype: tensor.Float32, Size: 2, Conv: weights.Float16}, order, nil
+	case class == 0 && size == 8 && signed: // fixed point
+		return weights.Type{Dtype: tensor.Int64, Size: 8}, order, nil
+	case class == 0 && size == 4 && signed:
+		return weights.Type{Dtype: tensor.Int32, Size: 4}, order, nil
+	case class == 0 && size == 2 && signed:
+		return weights.Type{Dtype: tensor.Int16, Size: 2}, order, nil
+	case class == 0 && size == 1 && signed:
+		return weights.Type{Dtype: tensor.Int8, Size: 1}, order, nil
+	case class == 0 && size == 1:
+		return weights.Type{Dtype: tensor.Uint8, Size: 1}, order, nil
+	case class == 8 && size == 1: // the enums of the bools of h5py
+		return weights.Type{Dtype: tensor.Bool, Size: 1}, order, nil
+	}
+	return weights.Type{}, nil, errors.Errorf("The datatype of the class %d of %d bytes is not supported", class, size)
+}
+
+// layout returns the data of a dataset whose layout is compact or contiguous.
+func (f *h5File) layout(p []byte) ([]byte, error) {
+	r := &h5Reader{f: f, p: p}
+	version := r.u8()
+	var class int
+	switch version {
+	case 1, 2:
+		rank := r.u8()
+		class = r.u8()
+		r.skip(5)
+		var addr uint64
+		if class != 0 {
+			addr = r.offset()
+		}
+		r.skip(4 * rank)
+		if class == 0 {
+			return r.bytes(r.uint(4)), r.err
+		}
+		if class == 1 && r.err == nil {
+			return f.at(addr, uint64(len(f.p))-f.base-addr)
+		}
+	case 3, 4:
+		class = r.u8()
+		switch class {
+		case 0:
+			return r.bytes(r.uint(2)), r.err
+		case 1:
+			addr, size := r.offset(), r.length()
+			if r.err != nil {
+				return nil, r.err
+			}
+			return f.at(addr, size)
+		}
+	default:
+		return nil, errors.Errorf("The version %d of the data layout is not supported", version)
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+	if class == 2 {
+		return nil, errors.New("The dataset is chunked: only the contiguous and compact datasets are supported")
+	}
+	return nil, errors.Errorf("The layout class %d of the dataset is not supported", class)
+}
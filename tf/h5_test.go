@@ -0,0 +1,391 @@
+package tf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorgonia.org/tensor"
+)
+
+// h5Node is a group, or a dataset if it has a datatype, of the files of the tests.
+type h5Node struct {
+	name     string
+	children []*h5Node
+
+	dtype  []byte
+	shape  []int
+	data   []byte
+	layout byte // compact (0), contiguous (1) or chunked (2)
+	dense  bool // the links of the group are in a fractal heap
+}
+
+func group(name string, children ...*h5Node) *h5Node { return &h5Node{name: name, children: children} }
+
+func dataset(name string, dtype []byte, shape []int, data []byte) *h5Node {
+	return &h5Node{name: name, dtype: dtype, shape: shape, data: data, layout: 1}
+}
+
+// datatype returns a datatype message of a class, of its first bit field and of a size.
+func dtype(class, bits byte, size int) []byte {
+	p := []byte{0x10 | class, bits, 0, 0}
+	p = append(p, encode(binary.LittleEndian, uint32(size))...)
+	props := 4
+	if class == 1 {
+		props = 12
+	}
+	return append(p, make([]byte, props)...)
+}
+
+var (
+	h5Float32   = dtype(1, 0x20, 4)
+	h5Float64BE = dtype(1, 0x21, 8)
+	h5Float16   = dtype(1, 0x20, 2)
+	h5Int64     = dtype(0, 0x08, 8)
+	h5String    = dtype(3, 0, 8)
+)
+
+// h5Writer writes the HDF5 files of the tests: either of the superblock of the version 0 and of the symbol tables, as
+// h5py writes them by default, or of the version 2 and of the link messages, whose object headers are of the version 2.
+type h5Writer struct {
+	bytes.Buffer
+	v2 bool
+}
+
+func (w *h5Writer) u(n int, v uint64) {
+	for i := 0; i < n; i++ {
+		w.WriteByte(byte(v >> (8 * uint(i))))
+	}
+}
+
+func (w *h5Writer) addr() uint64 { return uint64(w.Len()) }
+
+func (w *h5Writer) align() {
+	for w.Len()%8 != 0 {
+		w.WriteByte(0)
+	}
+}
+
+const undef = ^uint64(0)
+
+type h5Msg struct {
+	typ  int
+	data []byte
+}
+
+func le(n int, v uint64) []byte {
+	var w h5Writer
+	w.u(n, v)
+	return w.Bytes()
+}
+
+func cat(ps ...[]byte) []byte { return bytes.Join(ps, nil) }
+
+// header writes an object header of the messages. The headers of the version 1 continue after their first message.
+func (w *h5Writer) header(msgs []h5Msg) uint64 {
+	if w.v2 {
+		var b h5Writer
+		for _, m := range msgs {
+			b.u(1, uint64(m.typ))
+			b.u(2, uint64(len(m.data)))
+			b.u(1, 0)
+			b.Write(m.data)
+		}
+		w.align()
+		addr := w.addr()
+		w.WriteString("OHDR")
+		w.u(1, 2)
+		w.u(1, 1) // the size of the chunk is of 2 bytes
+		w.u(2, uint64(b.Len()))
+		w.Write(b.Bytes())
+		w.u(4, 0) // the checksum, which is not verified
+		return addr
+	}
+
+	var cont uint64
+	var contSize int
+	if len(msgs) > 1 {
+		w.align()
+		cont = w.addr()
+		w.Write(w.messages1(msgs[1:]))
+		contSize = int(w.addr() - cont)
+		msgs = append(msgs[:1:1], h5Msg{typ: msgContinuation, data: cat(le(8, cont), le(8, uint64(contSize)))})
+	}
+	b := w.messages1(msgs)
+	w.align()
+	addr := w.addr()
+	w.u(1, 1)
+	w.u(1, 0)
+	w.u(2, uint64(len(msgs)))
+	w.u(4, 1)
+	w.u(4, uint64(len(b)))
+	w.u(4, 0)
+	w.Write(b)
+	return addr
+}
+
+func (w *h5Writer) messages1(msgs []h5Msg) []byte {
+	var b h5Writer
+	for _, m := range msgs {
+		data := m.data
+		for len(data)%8 != 0 {
+			data = append(data, 0)
+		}
+		b.u(2, uint64(m.typ))
+		b.u(2, uint64(len(data)))
+		b.u(4, 0)
+		b.Write(data)
+	}
+	return b.Bytes()
+}
+
+// write writes a node, after its children, and returns the address of its object header.
+func (w *h5Writer) write(n *h5Node) uint64 {
+	if n.dtype != nil {
+		return w.writeDataset(n)
+	}
+	addrs := make([]uint64, len(n.children))
+	for i, c := range n.children {
+		addrs[i] = w.write(c)
+	}
+
+	if w.v2 {
+		heap := uint64(undef)
+		if n.dense {
+			heap = 0
+		}
+		msgs := []h5Msg{{typ: msgLinkInfo, data: cat([]byte{0, 0}, le(8, heap), le(8, undef))}}
+		for i, c := range n.children {
+			msgs = append(msgs, h5Msg{typ: msgLink, data: cat([]byte{1, 0x08, 0, byte(len(c.name))}, []byte(c.name), le(8, addrs[i]))})
+		}
+		return w.header(msgs)
+	}
+
+	// the local heap of the names, whose offset 0 is the empty name
+	w.align()
+	segment := w.addr()
+	offsets := make([]uint64, len(n.children))
+	w.u(8, 0)
+	for i, c := range n.children {
+		offsets[i] = w.addr() - segment
+		w.WriteString(c.name)
+		w.WriteByte(0)
+		w.align()
+	}
+	size := w.addr() - segment
+	heap := w.addr()
+	w.WriteString("HEAP")
+	w.u(4, 0)
+	w.u(8, size)
+	w.u(8, undef)
+	w.u(8, segment)
+
+	snod := w.addr()
+	w.WriteString("SNOD")
+	w.u(2, 1)
+	w.u(2, uint64(len(n.children)))
+	for i := range n.children {
+		w.u(8, offsets[i])
+		w.u(8, addrs[i])
+		w.u(8, 0)
+		w.Write(make([]byte, 16))
+	}
+
+	tree := w.addr()
+	w.WriteString("TREE")
+	w.u(1, 0)
+	w.u(1, 0)
+	w.u(2, 1)
+	w.u(8, undef)
+	w.u(8, undef)
+	w.u(8, 0)
+	w.u(8, snod)
+	w.u(8, offsets[len(offsets)-1])
+	return w.header([]h5Msg{{typ: msgSymbolTable, data: cat(le(8, tree), le(8, heap))}})
+}
+
+func (w *h5Writer) writeDataset(n *h5Node) uint64 {
+	var space []byte
+	if w.v2 {
+		simple := byte(1)
+		if len(n.shape) == 0 {
+			simple = 0
+		}
+		space = []byte{2, byte(len(n.shape)), 0, simple}
+	} else {
+		space = []byte{1, byte(len(n.shape)), 0, 0, 0, 0, 0, 0}
+	}
+	for _, d := range n.shape {
+		space = append(space, le(8, uint64(d))...)
+	}
+	var layout []byte
+	switch n.layout {
+	case 0:
+		layout = cat([]byte{3, 0}, le(2, uint64(len(n.data))), n.data)
+	case 1:
+		w.align()
+		layout = cat([]byte{3, 1}, le(8, w.addr()), le(8, uint64(len(n.data))))
+		w.Write(n.data)
+	default:
+		layout = cat([]byte{3, n.layout, 1}, le(8, undef))
+	}
+	return w.header([]h5Msg{{typ: msgDataspace, data: space}, {typ: msgDatatype, data: n.dtype}, {typ: msgLayout, data: layout}})
+}
+
+// h5 returns an HDF5 file of the root group.
+func h5(root *h5Node, v2 bool) []byte {
+	w := &h5Writer{v2: v2}
+	w.Write(h5Signature)
+	var rootAt int
+	if v2 {
+		w.u(1, 2)
+		w.u(1, 8)
+		w.u(1, 8)
+		w.u(1, 0)
+		w.u(8, 0)
+		w.u(8, undef)
+		w.u(8, 0) // the end of the file, which is not read
+		rootAt = w.Len()
+		w.u(8, 0)
+		w.u(4, 0)
+	} else {
+		w.Write([]byte{0, 0, 0, 0, 0, 8, 8, 0})
+		w.u(2, 4)
+		w.u(2, 16)
+		w.u(4, 0)
+		w.u(8, 0)
+		w.u(8, undef)
+		w.u(8, 0)
+		w.u(8, undef)
+		w.u(8, 0)
+		rootAt = w.Len()
+		w.u(8, 0)
+		w.u(8, 0)
+		w.Write(make([]byte, 16))
+	}
+	addr := w.write(root)
+	p := w.Bytes()
+	binary.LittleEndian.PutUint64(p[rootAt:], addr)
+	return p
+}
+
+func TestReadH5(t *testing.T) {
+	assert := assert.New(t)
+	// model.save_weights, as h5py writes it
+	p := h5(group("",
+		group("dense",
+			group("dense",
+				dataset("kernel:0", h5Float32, []int{2, 3}, encode(binary.LittleEndian, []float32{1, 2, 3, 4, 5, 6})),
+				dataset("bias:0", h5Float32, []int{3}, encode(binary.LittleEndian, []float32{0.5, -0.5, 0})),
+			),
+		),
+		group("dense_1",
+			group("dense_1",
+				dataset("kernel:0", h5Float64BE, []int{3, 2}, encode(binary.BigEndian, []float64{1, 2, 3, 4, 5, 6})),
+			),
+		),
+	), false)
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "model.h5")
+	if err := ioutil.WriteFile(path, p, 0644); err != nil {
+		t.Fatal(err)
+	}
+	vs, err := ReadH5(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]string{"dense/kernel:0", "dense/bias:0", "dense_1/kernel:0"}, vs.Names)
+	assert.Equal(tensor.Shape{2, 3}, vs.Tensor("dense/kernel:0").Shape())
+	assert.Equal([]float32{1, 2, 3, 4, 5, 6}, vs.Tensor("dense/kernel:0").Data())
+	assert.Equal([]float32{0.5, -0.5, 0}, vs.Tensor("dense/bias:0").Data())
+	assert.Equal([]float64{1, 2, 3, 4, 5, 6}, vs.Tensor("dense_1/kernel:0").Data())
+
+	// model.save, of the newer format
+	kernel := dataset("kernel:0", h5Float16, []int{2}, []byte{0x00, 0x3c, 0x00, 0xc0})
+	kernel.layout = 0
+	p = h5(group("",
+		group("model_weights",
+			group("conv",
+				group("conv", kernel, dataset("step", h5Int64, nil, encode(binary.LittleEndian, int64(7)))),
+			),
+		),
+		group("optimizer_weights",
+			group("Adam", dataset("iterations:0", h5Int64, nil, encode(binary.LittleEndian, int64(7)))),
+		),
+	), true)
+	vs, err = readH5(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]string{"conv/kernel:0", "conv/step"}, vs.Names)
+	assert.Equal([]float32{1, -2}, vs.Tensor("conv/kernel:0").Data())
+	assert.Equal(int64(7), vs.Tensor("conv/step").Data())
+}
+
+func TestReadH5_errors(t *testing.T) {
+	assert := assert.New(t)
+	w := func() *h5Node {
+		return dataset("w:0", h5Float32, []int{2}, encode(binary.LittleEndian, []float32{1, 2}))
+	}
+
+	_, err := ReadH5("nope.h5")
+	assert.Error(err, "no file")
+	_, err = readH5([]byte("\x89PNG\r\n\x1a\n"))
+	assert.Error(err, "not HDF5")
+	_, err = readH5(h5(group("", w()), false)[:200])
+	assert.Error(err, "a truncated file")
+
+	chunked := w()
+	chunked.layout = 2
+	_, err = readH5(h5(group("", chunked), false))
+	if assert.Error(err) {
+		assert.Contains(err.Error(), "chunked")
+	}
+	s := w()
+	s.dtype = h5String
+	_, err = readH5(h5(group("", s), true))
+	assert.Error(err, "an unsupported datatype")
+	short := w()
+	short.shape = []int{3}
+	_, err = readH5(h5(group("", short), false))
+	assert.Error(err, "a short dataset")
+
+	dense := group("", w())
+	dense.dense = true
+	_, err = readH5(h5(dense, true))
+	if assert.Error(err) {
+		assert.Contains(err.Error(), "fractal heap")
+	}
+}
+
+func TestReadH5_corrupt(t *testing.T) {
+	for _, v2 := range []bool{false, true} {
+		p := h5(group("",
+			group("dense",
+				group("dense",
+					dataset("kernel:0", h5Float32, []int{2, 3}, encode(binary.LittleEndian, []float32{1, 2, 3, 4, 5, 6})),
+					dataset("bias:0", h5Float64BE, []int{3}, encode(binary.BigEndian, []float64{0.5, -0.5, 0})),
+				),
+			),
+		), v2)
+		// every byte is corrupted in turn: the errors are returned, and the reads of the corrupted sizes end
+		for i := range p {
+			for _, b := range []byte{0xff, 0x7f, 0} {
+				c := append([]byte{}, p...)
+				c[i] = b
+				vs, err := readH5(c)
+				if err != nil {
+					continue
+				}
+				for _, name := range vs.Names {
+					vs.Tensor(name)
+				}
+			}
+		}
+	}
+}
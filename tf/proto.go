@@ -0,0 +1,133 @@
+package tf
+
+import (
+	"encoding/binary"
+
+	"github.com/pkg/errors"
+)
+
+// The messages of tensor_bundle.proto that ReadCheckpoint reads, decoded by hand as in the onnx package. Only the
+// fields that it uses are decoded; the others are skipped.
+
+// bundleHeader is the BundleHeaderProto of the empty key of the index of a checkpoint.
+type bundleHeader struct {
+	numShards int  // 1
+	bigEndian bool // 2: endianness
+}
+
+// bundleEntry is the BundleEntryProto of a variable: where its tensor is in the data files.
+type bundleEntry struct {
+	dtype  int    // 1
+	shape  []int  // 2: shape.dim.size
+	shard  int    // 3: shard_id
+	offset int64  // 4
+	size   int64  // 5
+	crc32c uint32 // 6, masked
+	slices int    // 7: the number of slices of the partitioned variables
+}
+
+func (h *bundleHeader) unmarshal(p []byte) error {
+	return fields(p, func(f field) error {
+		switch f.n {
+		case 1:
+			h.numShards = int(f.v)
+		case 2:
+			h.bigEndian = f.v == 1
+		}
+		return nil
+	})
+}
+
+func (e *bundleEntry) unmarshal(p []byte) error {
+	return fields(p, func(f field) error {
+		switch f.n {
+		case 1:
+			e.dtype = int(f.v)
+		case 2:
+			// TensorShapeProto: dim = 2, of TensorShapeProto.Dim: size = 1
+			return fields(f.b, func(f field) error {
+				if f.n != 2 {
+					return nil
+				}
+				size := -1
+				err := fields(f.b, func(f field) error {
+					if f.n == 1 {
+						size = int(int64(f.v))
+					}
+					return nil
+				})
+				if err == nil && size < 0 {
+					err = errors.Errorf("The dimension of the size %d is not supported", size)
+				}
+				e.shape = append(e.shape, size)
+				return err
+			})
+		case 3:
+			e.shard = int(f.v)
+		case 4:
+			e.offset = int64(f.v)
+		case 5:
+			e.size = int64(f.v)
+		case 6:
+			e.crc32c = uint32(f.v)
+		case 7:
+			e.slices++
+		}
+		return nil
+	})
+}
+
+/* decoding */
+
+// field is a decoded field. Only one of v and b is set, depending on the wire type.
+type field struct {
+	n, wire int
+	v       uint64
+	b       []byte
+}
+
+// fields calls fn with every field of the message p.
+func fields(p []byte, fn func(f field) error) error {
+	for len(p) > 0 {
+		tag, n := binary.Uvarint(p)
+		if n <= 0 {
+			return errors.New("Malformed varint")
+		}
+		p = p[n:]
+		f := field{n: int(tag >> 3), wire: int(tag & 7)}
+		switch f.wire {
+		case 0:
+			if f.v, n = binary.Uvarint(p); n <= 0 {
+				return errors.New("Malformed varint")
+			}
+			p = p[n:]
+		case 1, 5:
+			size := 8
+			if f.wire == 5 {
+				size = 4
+			}
+			if len(p) < size {
+				return errors.New("Truncated fixed-size field")
+			}
+			if size == 4 {
+				f.v = uint64(binary.LittleEndian.Uint32(p))
+			} else {
+				f.v = binary.LittleEndian.Uint64(p)
+			}
+			p = p[size:]
+		case 2:
+			l, n := binary.Uvarint(p)
+			if n <= 0 || l > uint64(len(p)-n) {
+				return errors.New("Truncated length-delimited field")
+			}
+			f.b = p[n : n+int(l)]
+			p = p[n+int(l):]
+		default:
+			return errors.Errorf("Unsupported wire type %d of field %d", f.wire, f.n)
+		}
+		if err := fn(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package tf
+
+import (
+	"strings"
+
+	G "gorgonia.org/gorgonia"
+	"gorgonia.org/gorgonia/internal/weights"
+	"gorgonia.org/tensor"
+)
+
+// Variables are the named tensors of a checkpoint or of a Keras file, in the order they were read.
+type Variables struct {
+	Names   []string
+	Tensors map[string]*tensor.Dense
+}
+
+// Tensor returns the tensor of the given variable, or nil if there is none.
+func (vs *Variables) Tensor(name string) *tensor.Dense { return vs.Tensors[name] }
+
+func (vs *Variables) add(name string, t *tensor.Dense) {
+	if _, ok := vs.Tensors[name]; !ok {
+		vs.Names = append(vs.Names, name)
+	}
+	vs.Tensors[name] = t
+}
+
+// AssignOpt is an option of Assign.
+type AssignOpt func(*assigner)
+
+type assigner struct {
+	rename  func(string) string
+	byShape bool
+	strict  bool
+}
+
+// WithRename renames the variables before they are matched with the parameters, such as to strip the name of the model
+// of the variables of the Keras files.
+func WithRename(fn func(name string) string) AssignOpt {
+	return func(a *assigner) { a.rename = fn }
+}
+
+// MatchByShape pairs the parameters whose names match no variable with the variables whose names match no parameter,
+// in their orders, as long as their shapes are the same. It is for the models rebuilt with other names, whose
+// parameters are created in the order of the layers of TensorFlow.
+func MatchByShape() AssignOpt {
+	return func(a *assigner) { a.byShape = true }
+}
+
+// Strict fails the assignment if a parameter is not assigned, or a variable is not used.
+func Strict() AssignOpt {
+	return func(a *assigner) { a.strict = true }
+}
+
+// Assignment tells which variable was assigned to each parameter.
+type Assignment struct {
+	Params  G.Nodes
+	Names   []string // the names of the variables of the Params
+	Missing G.Nodes  // the parameters that were not assigned
+	Unused  []string // the variables that were not assigned
+}
+
+// Assign assigns the variables to the parameters, such as the learnables of a graph that rebuilds the architecture of
+// the model. A variable is assigned to the parameter of the same name, without the output index of TensorFlow: the
+// variable "dense/kernel:0" is assigned to the parameter "dense/kernel". The tensors are copied into the values of the
+// parameters, or bound to the parameters that have none, and must have their shapes; the floating point tensors are
+// converted to the types of the parameters.
+//
+// Nothing is assigned if an error is returned.
+func (vs *Variables) Assign(params G.Nodes, opts ...AssignOpt) (*Assignment, error) {
+	a := &assigner{rename: func(s string) string { return s }}
+	for _, opt := range opts {
+		opt(a)
+	}
+	res, err := weights.Assign(vs.Names, vs.Tensors, params, weights.Options{
+		Match:   func(name string) string { return trimIndex(a.rename(name)) },
+		ByShape: a.byShape,
+		Strict:  a.strict,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return (*Assignment)(res), nil
+}
+
+// trimIndex trims the output index of the name of a tensor, such as the ":0" of "dense/kernel:0".
+func trimIndex(name string) string {
+	i := strings.LastIndexByte(name, ':')
+	if i < 0 || i == len(name)-1 {
+		return name
+	}
+	for _, c := range name[i+1:] {
+		if c < '0' || c > '9' {
+			return name
+		}
+	}
+	return name[:i]
+}
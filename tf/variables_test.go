@@ -0,0 +1,78 @@
+package tf
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	G "gorgonia.org/gorgonia"
+	"gorgonia.org/tensor"
+)
+
+func dense() *Variables {
+	return &Variables{
+		Names: []string{"dense/kernel:0", "dense/bias:0"},
+		Tensors: map[string]*tensor.Dense{
+			"dense/kernel:0": tensor.New(tensor.WithShape(2, 3), tensor.WithBacking([]float32{1, 2, 3, 4, 5, 6})),
+			"dense/bias:0":   tensor.New(tensor.WithShape(3), tensor.WithBacking([]float32{0.5, -0.5, 0})),
+		},
+	}
+}
+
+func TestAssign(t *testing.T) {
+	assert := assert.New(t)
+	g := G.NewGraph()
+	leave := g.Scope("dense")
+	w := G.NewMatrix(g, G.Float64, G.WithShape(2, 3), G.WithName("kernel"), G.WithInit(G.Zeroes()))
+	b := G.NewVector(g, G.Float32, G.WithShape(3), G.WithName("bias"))
+	leave()
+	other := G.NewVector(g, G.Float32, G.WithShape(4), G.WithName("other"), G.WithInit(G.Zeroes()))
+
+	a, err := dense().Assign(G.Nodes{w, b, other})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]string{"dense/kernel:0", "dense/bias:0"}, a.Names)
+	assert.True(a.Params[0] == w && a.Params[1] == b)
+	assert.True(len(a.Missing) == 1 && a.Missing[0] == other)
+	assert.Nil(a.Unused)
+	assert.Equal([]float64{1, 2, 3, 4, 5, 6}, w.Value().Data(), "converted to float64")
+	assert.Equal([]float32{0.5, -0.5, 0}, b.Value().Data(), "bound to the parameter without a value")
+
+	_, err = dense().Assign(G.Nodes{w, b, other}, Strict())
+	assert.Error(err)
+
+	// the variables of a model named "encoder"
+	vs := dense()
+	vs.Names = []string{"encoder/dense/kernel:0", "encoder/dense/bias:0"}
+	vs.Tensors = map[string]*tensor.Dense{"encoder/dense/kernel:0": vs.Tensors["dense/kernel:0"], "encoder/dense/bias:0": vs.Tensors["dense/bias:0"]}
+	a, err = vs.Assign(G.Nodes{w, b}, WithRename(func(s string) string { return strings.TrimPrefix(s, "encoder/") }), Strict())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]string{"encoder/dense/kernel:0", "encoder/dense/bias:0"}, a.Names)
+
+	// the other names are matched by their shapes, in order
+	g = G.NewGraph()
+	w = G.NewMatrix(g, G.Float32, G.WithShape(2, 3), G.WithName("fc/weight"), G.WithInit(G.Zeroes()))
+	b = G.NewVector(g, G.Float32, G.WithShape(3), G.WithName("fc/offset"), G.WithInit(G.Zeroes()))
+	a, err = dense().Assign(G.Nodes{w, b}, MatchByShape(), Strict())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]string{"dense/kernel:0", "dense/bias:0"}, a.Names)
+	assert.Equal([]float32{0.5, -0.5, 0}, b.Value().Data())
+}
+
+func TestTrimIndex(t *testing.T) {
+	assert := assert.New(t)
+	for name, want := range map[string]string{
+		"dense/kernel:0":  "dense/kernel",
+		"dense/kernel:12": "dense/kernel",
+		"dense/kernel":    "dense/kernel",
+		"dense/kernel:":   "dense/kernel:",
+		"scope:x/kernel":  "scope:x/kernel",
+	} {
+		assert.Equal(want, trimIndex(name), name)
+	}
+}
@@ -0,0 +1,71 @@
+package torch
+
+import (
+	"strings"
+
+	G "gorgonia.org/gorgonia"
+	"gorgonia.org/gorgonia/internal/weights"
+)
+
+// AssignOpt is an option of Assign.
+type AssignOpt func(*assigner)
+
+type assigner struct {
+	rename  func(string) string
+	byShape bool
+	strict  bool
+}
+
+// WithRename renames the tensors before they are matched with the parameters, such as to strip the "module." prefix
+// of the state_dicts of the models wrapped in torch.nn.DataParallel.
+func WithRename(fn func(name string) string) AssignOpt {
+	return func(a *assigner) { a.rename = fn }
+}
+
+// MatchByShape pairs the parameters whose names match no tensor with the tensors whose names match no parameter, in
+// their orders, as long as their shapes are the same. It is for the models rebuilt with other names, whose parameters
+// are created in the order of the modules of PyTorch.
+func MatchByShape() AssignOpt {
+	return func(a *assigner) { a.byShape = true }
+}
+
+// Strict fails the assignment if a parameter is not assigned, or a tensor is not used, as load_state_dict does.
+func Strict() AssignOpt {
+	return func(a *assigner) { a.strict = true }
+}
+
+// Assignment tells which tensor was assigned to each parameter.
+type Assignment struct {
+	Params  G.Nodes
+	Names   []string // the names of the tensors of the Params
+	Missing G.Nodes  // the parameters that were not assigned
+	Unused  []string // the tensors that were not assigned
+}
+
+// Assign assigns the tensors of the state_dict to the parameters, such as the learnables of a graph that rebuilds the
+// architecture of the model. A tensor is assigned to the parameter of the same name, where the dots of the names of
+// PyTorch are scopes: the tensor "encoder.fc1.weight" is assigned to the parameter "encoder/fc1/weight" (see
+// gorgonia.Scope). The tensors are copied into the values of the parameters, or bound to the parameters that have
+// none, and must have their shapes; the floating point tensors are converted to the types of the parameters.
+//
+// The weights of torch.nn.Linear are of the shape (out, in), which is the transpose of the weights of x×W. Such
+// tensors must be transposed before they are assigned.
+//
+// Nothing is assigned if an error is returned.
+func (sd *StateDict) Assign(params G.Nodes, opts ...AssignOpt) (*Assignment, error) {
+	a := &assigner{rename: func(s string) string { return s }}
+	for _, opt := range opts {
+		opt(a)
+	}
+	res, err := weights.Assign(sd.Names, sd.Tensors, params, weights.Options{
+		Match:   func(name string) string { return matchName(a.rename(name)) },
+		ByShape: a.byShape,
+		Strict:  a.strict,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return (*Assignment)(res), nil
+}
+
+func matchName(name string) string { return strings.Replace(name, ".", G.ScopeSep, -1) }
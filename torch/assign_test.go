@@ -0,0 +1,89 @@
+package torch
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	G "gorgonia.org/gorgonia"
+	"gorgonia.org/tensor"
+)
+
+func linear() *StateDict {
+	return &StateDict{
+		Names: []string{"fc.weight", "fc.bias"},
+		Tensors: map[string]*tensor.Dense{
+			"fc.weight": tensor.New(tensor.WithShape(2, 3), tensor.WithBacking([]float32{1, 2, 3, 4, 5, 6})),
+			"fc.bias":   tensor.New(tensor.WithShape(2), tensor.WithBacking([]float32{0.5, -0.5})),
+		},
+	}
+}
+
+func TestAssign(t *testing.T) {
+	assert := assert.New(t)
+	g := G.NewGraph()
+	leave := g.Scope("fc")
+	w := G.NewMatrix(g, G.Float64, G.WithShape(2, 3), G.WithName("weight"), G.WithInit(G.Zeroes()))
+	b := G.NewVector(g, G.Float32, G.WithShape(2), G.WithName("bias"))
+	leave()
+	other := G.NewVector(g, G.Float32, G.WithShape(4), G.WithName("other"), G.WithInit(G.Zeroes()))
+
+	a, err := linear().Assign(G.Nodes{w, b, other})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]string{"fc.weight", "fc.bias"}, a.Names)
+	assert.True(a.Params[0] == w && a.Params[1] == b)
+	assert.True(len(a.Missing) == 1 && a.Missing[0] == other)
+	assert.Nil(a.Unused)
+	assert.Equal([]float64{1, 2, 3, 4, 5, 6}, w.Value().Data(), "converted to float64")
+	assert.Equal([]float32{0.5, -0.5}, b.Value().Data(), "bound to the parameter without a value")
+
+	_, err = linear().Assign(G.Nodes{w, b, other}, Strict())
+	assert.Error(err)
+}
+
+func TestAssign_options(t *testing.T) {
+	assert := assert.New(t)
+	g := G.NewGraph()
+	w := G.NewMatrix(g, G.Float32, G.WithShape(2, 3), G.WithName("dense/kernel"), G.WithInit(G.Zeroes()))
+	b := G.NewVector(g, G.Float32, G.WithShape(2), G.WithName("dense/offset"), G.WithInit(G.Zeroes()))
+
+	// the names of the DataParallel models
+	sd := linear()
+	sd.Names = []string{"module.dense.kernel", "module.fc.bias"}
+	sd.Tensors = map[string]*tensor.Dense{"module.dense.kernel": sd.Tensors["fc.weight"], "module.fc.bias": sd.Tensors["fc.bias"]}
+	a, err := sd.Assign(G.Nodes{w, b}, WithRename(func(s string) string { return strings.TrimPrefix(s, "module.") }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]string{"module.dense.kernel"}, a.Names)
+	assert.Equal([]string{"module.fc.bias"}, a.Unused)
+
+	// the other names are matched by their shapes, in order
+	a, err = linear().Assign(G.Nodes{w, b}, MatchByShape(), Strict())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]string{"fc.weight", "fc.bias"}, a.Names)
+	assert.Equal([]float32{0.5, -0.5}, b.Value().Data())
+}
+
+func TestAssign_errors(t *testing.T) {
+	assert := assert.New(t)
+	g := G.NewGraph()
+	leave := g.Scope("fc")
+	wt := G.NewMatrix(g, G.Float32, G.WithShape(3, 2), G.WithName("weight"), G.WithInit(G.Zeroes()))
+	b := G.NewVector(g, G.Float32, G.WithShape(2), G.WithName("bias"), G.WithInit(G.Zeroes()))
+	leave()
+
+	_, err := linear().Assign(G.Nodes{b, wt})
+	if assert.Error(err) {
+		assert.Contains(err.Error(), "transpose")
+	}
+	assert.Equal([]float32{0, 0}, b.Value().Data(), "nothing is assigned")
+
+	i := G.NewVector(g, G.Int, G.WithShape(2), G.WithName("fc/bias"), G.WithInit(G.Zeroes()))
+	_, err = linear().Assign(G.Nodes{i})
+	assert.Error(err, "float32 is not converted to int")
+}
@@ -0,0 +1,13 @@
+// Package torch reads the weights of PyTorch models, to assign them to the parameters of the same models rebuilt with
+// Gorgonia. The files are the zip archives that torch.save writes, such as the .pt and .pth files of
+// torch.save(model.state_dict(), path):
+//
+//	sd, err := torch.ReadFile("model.pth")
+//	...
+//	// the graph rebuilds the architecture, with the parameters named as the modules: "fc1/weight", ...
+//	a, err := sd.Assign(g.Learnables(), torch.Strict())
+//
+// Only the pickles of the state_dicts of tensors, possibly nested in dicts, are read: the pickle protocol is decoded by
+// a small reader that resolves none of the globals but those of the tensors, so the pickled modules, and the legacy
+// format of torch.save, are not supported. The tensors of half precision and of bfloat16 are read as float32.
+package torch
@@ -0,0 +1,365 @@
+package torch
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"math"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gorgonia.org/gorgonia/internal/weights"
+	"gorgonia.org/tensor"
+)
+
+// StateDict is a state_dict of named tensors, in the order they were saved.
+type StateDict struct {
+	Names   []string
+	Tensors map[string]*tensor.Dense
+}
+
+// Tensor returns the tensor of the given name, or nil if there is none.
+func (sd *StateDict) Tensor(name string) *tensor.Dense { return sd.Tensors[name] }
+
+// ReadFile reads the state_dict of a file written by torch.save, such as a .pt or a .pth file.
+func ReadFile(path string) (*StateDict, error) {
+	p, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	sd, err := Read(bytes.NewReader(p), int64(len(p)))
+	return sd, errors.Wrapf(err, "Cannot read the state_dict of %v", path)
+}
+
+// Read reads the state_dict of the zip archive written by torch.save. The state_dict may be nested in a dict, as in the
+// checkpoints of {"model": model.state_dict(), ...}, in which case the tensors are named by their paths, such as
+// "model.fc.weight"; the values that are not tensors are skipped.
+func Read(r io.ReaderAt, size int64) (*StateDict, error) {
+	z, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, errors.Wrap(err, "The file is not the zip archive of torch.save. The legacy format of torch.save is not supported")
+	}
+	a := &archive{files: make(map[string]*zip.File), storages: make(map[string]*storage)}
+	var pkl *zip.File
+	for _, f := range z.File {
+		a.files[f.Name] = f
+		if strings.HasSuffix(f.Name, "/data.pkl") && strings.Count(f.Name, "/") == 1 {
+			pkl = f
+			a.prefix = strings.TrimSuffix(f.Name, "data.pkl")
+		}
+	}
+	if pkl == nil {
+		return nil, errors.New("The archive has no data.pkl")
+	}
+	if bo, ok := a.files[a.prefix+"byteorder"]; ok {
+		var p []byte
+		if p, err = readZipFile(bo); err != nil {
+			return nil, err
+		}
+		if s := strings.TrimSpace(string(p)); s != "little" {
+			return nil, errors.Errorf("The byte order %q is not supported", s)
+		}
+	}
+
+	rc, err := pkl.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	v, err := unpickle(rc, pkl.UncompressedSize64, a.persistentLoad, call)
+	if err != nil {
+		return nil, err
+	}
+
+	sd := &StateDict{Tensors: make(map[string]*tensor.Dense)}
+	if err = sd.add("", v); err != nil {
+		return nil, err
+	}
+	if len(sd.Names) == 0 {
+		return nil, errors.New("The archive has no tensors")
+	}
+	return sd, nil
+}
+
+// add adds the tensors of a value of the pickle, named by their paths from the name.
+func (sd *StateDict) add(name string, v interface{}) error {
+	switch v := v.(type) {
+	case *rebuiltTensor:
+		t, err := v.dense()
+		if err != nil {
+			return errors.Wrapf(err, "Tensor %q", name)
+		}
+		sd.Names = append(sd.Names, name)
+		sd.Tensors[name] = t
+	case *dict:
+		for _, k := range v.keys {
+			key, ok := k.(string)
+			if !ok {
+				continue
+			}
+			if name != "" {
+				key = name + "." + key
+			}
+			if err := sd.add(key, v.values[k]); err != nil {
+				return err
+			}
+		}
+	case *object:
+		return errors.Errorf("The value %q is a %v: only the state_dicts of tensors are supported, not the pickled modules", name, v.class)
+	}
+	return nil
+}
+
+func readZipFile(f *zip.File) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return ioutil.ReadAll(rc)
+}
+
+// archive is the zip archive of torch.save: a data.pkl, and the storages of the tensors under data/.
+type archive struct {
+	prefix   string
+	files    map[string]*zip.File
+	storages map[string]*storage
+}
+
+// storage is the storage of tensors, which may be shared by several tensors, such as views.
+type storage struct {
+	dtype tensor.Dtype
+	size  int // of an element of the file
+	data  []byte
+	conv  func(p []byte) float32 // the conversion of the elements of the formats that tensor does not support
+}
+
+// the storage types of torch.save
+var storageTypes = map[string]storage{
+	"DoubleStorage":   {dtype: tensor.Float64, size: 8},
+	"FloatStorage":    {dtype: tensor.Float32, size: 4},
+	"HalfStorage":     {dtype: tensor.Float32, size: 2, conv: weights.Float16},
+	"BFloat16Storage": {dtype: tensor.Float32, size: 2, conv: weights.BFloat16},
+	"LongStorage":     {dtype: tensor.Int64, size: 8},
+	"IntStorage":      {dtype: tensor.Int32, size: 4},
+	"ShortStorage":    {dtype: tensor.Int16, size: 2},
+	"CharStorage":     {dtype: tensor.Int8, size: 1},
+	"ByteStorage":     {dtype: tensor.Uint8, size: 1},
+	"BoolStorage":     {dtype: tensor.Bool, size: 1},
+}
+
+// persistentLoad loads the storages of the persistent IDs ('storage', storage_type, key, location, numel).
+func (a *archive) persistentLoad(pid interface{}) (interface{}, error) {
+	t, ok := pid.(tuple)
+	if !ok || len(t) < 5 || t[0] != "storage" {
+		return nil, errors.Errorf("The persistent ID %v is not that of a storage", pid)
+	}
+	typ, ok1 := t[1].(global)
+	key, ok2 := t[2].(string)
+	numel, ok3 := t[4].(int64)
+	if !ok1 || !ok2 || !ok3 || numel < 0 {
+		return nil, errors.Errorf("The persistent ID %v is not that of a storage", pid)
+	}
+	if s, ok := a.storages[key]; ok {
+		return s, nil
+	}
+	st, ok := storageTypes[typ.name]
+	if !ok || typ.module != "torch" {
+		return nil, errors.Errorf("The storage type %v is not supported", typ)
+	}
+	f, ok := a.files[a.prefix+"data/"+key]
+	if !ok {
+		return nil, errors.Errorf("The archive has no storage %q", key)
+	}
+	s := st
+	var err error
+	if s.data, err = readZipFile(f); err != nil {
+		return nil, err
+	}
+	if int64(len(s.data)) < numel*int64(s.size) {
+		return nil, errors.Errorf("The storage %q has %d bytes. Expected %d elements of %d bytes", key, len(s.data), numel, s.size)
+	}
+	a.storages[key] = &s
+	return &s, nil
+}
+
+// rebuiltTensor is a tensor of the pickle: a view of a storage.
+type rebuiltTensor struct {
+	storage *storage
+	offset  int
+	shape   []int
+	strides []int
+}
+
+// object is an instance of a class that is not supported, such as a pickled module.
+type object struct{ class global }
+
+// call calls the globals of the pickles of the state_dicts.
+func call(fn global, args tuple) (interface{}, error) {
+	switch fn.String() {
+	case "collections.OrderedDict", "builtins.dict":
+		return newDict(), nil
+	case "torch._utils._rebuild_tensor_v2", "torch._utils._rebuild_tensor":
+		return rebuildTensor(args)
+	case "torch._utils._rebuild_parameter", "torch._utils._rebuild_parameter_with_state":
+		if len(args) == 0 {
+			return nil, errors.New("_rebuild_parameter has no arguments")
+		}
+		return args[0], nil
+	case "torch.nn.parameter.Parameter":
+		if len(args) == 0 {
+			return nil, errors.New("Parameter has no arguments")
+		}
+		return args[0], nil
+	}
+	return &object{class: fn}, nil
+}
+
+// rebuildTensor rebuilds the tensor of _rebuild_tensor_v2(storage, storage_offset, size, stride, ...).
+func rebuildTensor(args tuple) (interface{}, error) {
+	if len(args) < 4 {
+		return nil, errors.Errorf("Expected the storage, offset, size and stride of a tensor. Got %v instead", args)
+	}
+	s, ok := args[0].(*storage)
+	if !ok {
+		return nil, errors.Errorf("Expected the storage of a tensor. Got a %T instead", args[0])
+	}
+	offset, ok := args[1].(int64)
+	if !ok {
+		return nil, errors.Errorf("Expected the offset of a tensor. Got a %T instead", args[1])
+	}
+	shape, err := intTuple(args[2])
+	if err != nil {
+		return nil, err
+	}
+	strides, err := intTuple(args[3])
+	if err != nil {
+		return nil, err
+	}
+	if len(shape) != len(strides) {
+		return nil, errors.Errorf("The tensor has the size %v and the stride %v", shape, strides)
+	}
+	return &rebuiltTensor{storage: s, offset: int(offset), shape: shape, strides: strides}, nil
+}
+
+func intTuple(v interface{}) ([]int, error) {
+	t, ok := v.(tuple)
+	if !ok {
+		return nil, errors.Errorf("Expected a tuple of integers. Got a %T instead", v)
+	}
+	retVal := make([]int, len(t))
+	for i, e := range t {
+		n, ok := e.(int64)
+		if !ok || n < 0 {
+			return nil, errors.Errorf("Expected a tuple of non-negative integers. Got %v instead", t)
+		}
+		retVal[i] = int(n)
+	}
+	return retVal, nil
+}
+
+// dense copies the elements of the view into a new tensor, whose elements are contiguous.
+func (rt *rebuiltTensor) dense() (*tensor.Dense, error) {
+	s := rt.storage
+	elems := len(s.data) / s.size
+	// the size of a corrupted shape is not allocated: the elements of the view are those of its storage
+	size := 1
+	for _, d := range rt.shape {
+		if d == 0 {
+			size = 0
+		}
+	}
+	for _, d := range rt.shape {
+		if size == 0 {
+			break
+		}
+		if size > elems/d {
+			return nil, errors.Errorf("The tensor of the size %v has more elements than its storage of %d elements", rt.shape, elems)
+		}
+		size *= d
+	}
+	idx := make([]int, len(rt.shape))
+	offsets := make([]int, 0, size)
+	for i := 0; i < size; i++ {
+		off := rt.offset
+		for j, k := range idx {
+			off += k * rt.strides[j]
+		}
+		if off < 0 || off >= elems {
+			return nil, errors.Errorf("The element %d is out of the storage of %d elements", off, elems)
+		}
+		offsets = append(offsets, off)
+		// the next index, in row-major order
+		for j := len(idx) - 1; j >= 0; j-- {
+			if idx[j]++; idx[j] < rt.shape[j] {
+				break
+			}
+			idx[j] = 0
+		}
+	}
+
+	le := binary.LittleEndian
+	var backing interface{}
+	switch {
+	case s.conv != nil:
+		data := make([]float32, size)
+		for i, off := range offsets {
+			data[i] = s.conv(s.data[off*s.size:])
+		}
+		backing = data
+	case s.dtype == tensor.Float64:
+		data := make([]float64, size)
+		for i, off := range offsets {
+			data[i] = math.Float64frombits(le.Uint64(s.data[off*8:]))
+		}
+		backing = data
+	case s.dtype == tensor.Float32:
+		data := make([]float32, size)
+		for i, off := range offsets {
+			data[i] = math.Float32frombits(le.Uint32(s.data[off*4:]))
+		}
+		backing = data
+	case s.dtype == tensor.Int64:
+		data := make([]int64, size)
+		for i, off := range offsets {
+			data[i] = int64(le.Uint64(s.data[off*8:]))
+		}
+		backing = data
+	case s.dtype == tensor.Int32:
+		data := make([]int32, size)
+		for i, off := range offsets {
+			data[i] = int32(le.Uint32(s.data[off*4:]))
+		}
+		backing = data
+	case s.dtype == tensor.Int16:
+		data := make([]int16, size)
+		for i, off := range offsets {
+			data[i] = int16(le.Uint16(s.data[off*2:]))
+		}
+		backing = data
+	case s.dtype == tensor.Int8:
+		data := make([]int8, size)
+		for i, off := range offsets {
+			data[i] = int8(s.data[off])
+		}
+		backing = data
+	case s.dtype == tensor.Uint8:
+		data := make([]uint8, size)
+		for i, off := range offsets {
+			data[i] = s.data[off]
+		}
+		backing = data
+	case s.dtype == tensor.Bool:
+		data := make([]bool, size)
+		for i, off := range offsets {
+			data[i] = s.data[off] != 0
+		}
+		backing = data
+	}
+	if len(rt.shape) == 0 {
+		return tensor.New(tensor.WithShape(), tensor.WithBacking(backing)), nil
+	}
+	return tensor.New(tensor.WithShape(rt.shape...), tensor.WithBacking(backing)), nil
+}
@@ -0,0 +1,280 @@
+package torch
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorgonia.org/tensor"
+)
+
+// pickler writes the pickles of torch.save, with the protocol 2.
+type pickler struct {
+	bytes.Buffer
+	memo byte
+}
+
+func (p *pickler) op(ops ...byte) { p.Write(ops) }
+
+func (p *pickler) put() {
+	p.op(opBinPut, p.memo)
+	p.memo++
+}
+
+func (p *pickler) str(s string) {
+	p.op(opBinUnicode)
+	binary.Write(p, binary.LittleEndian, uint32(len(s)))
+	p.WriteString(s)
+	p.put()
+}
+
+func (p *pickler) int(i int) {
+	if i >= 0 && i < 256 {
+		p.op(opBinInt1, byte(i))
+		return
+	}
+	p.op(opBinInt)
+	binary.Write(p, binary.LittleEndian, int32(i))
+}
+
+func (p *pickler) ints(is ...int) {
+	p.op(opMark)
+	for _, i := range is {
+		p.int(i)
+	}
+	p.op(opTuple)
+	p.put()
+}
+
+func (p *pickler) global(module, name string) {
+	p.op(opGlobal)
+	p.WriteString(module + "\n" + name + "\n")
+	p.put()
+}
+
+// orderedDict starts an OrderedDict, whose items are written before end.
+func (p *pickler) orderedDict() {
+	p.global("collections", "OrderedDict")
+	p.op(opEmptyTuple, opReduce)
+	p.put()
+	p.op(opMark)
+}
+
+func (p *pickler) end() { p.op(opSetItems) }
+
+// savedTensor is a tensor of a storage of the archive.
+type savedTensor struct {
+	name           string
+	storage, key   string
+	numel          int
+	offset         int
+	shape, strides []int
+}
+
+func (p *pickler) tensor(t savedTensor) {
+	p.str(t.name)
+	p.global("torch._utils", "_rebuild_tensor_v2")
+	p.op(opMark)
+	p.op(opMark)
+	p.str("storage")
+	p.global("torch", t.storage)
+	p.str(t.key)
+	p.str("cpu")
+	p.int(t.numel)
+	p.op(opTuple)
+	p.put()
+	p.op(opBinPersID)
+	p.int(t.offset)
+	p.ints(t.shape...)
+	p.ints(t.strides...)
+	p.op(opNewFalse)
+	p.global("collections", "OrderedDict")
+	p.op(opEmptyTuple, opReduce)
+	p.put()
+	p.op(opTuple, opReduce)
+	p.put()
+}
+
+// stateDict returns the pickle of an OrderedDict of the tensors, with the _metadata that torch.save writes.
+func stateDict(ts ...savedTensor) []byte {
+	p := new(pickler)
+	p.op(opProto, 2)
+	p.orderedDict()
+	for _, t := range ts {
+		p.tensor(t)
+	}
+	p.end()
+	p.op(opEmptyDict)
+	p.put()
+	p.str("_metadata")
+	p.orderedDict()
+	p.str("")
+	p.op(opEmptyDict)
+	p.str("version")
+	p.int(1)
+	p.op(opSetItem)
+	p.end()
+	p.op(opSetItem, opBuild, opStop)
+	return p.Bytes()
+}
+
+// archiveOf returns the zip archive of torch.save of a pickle and its storages.
+func archiveOf(t *testing.T, pkl []byte, storages map[string][]byte) []byte {
+	var buf bytes.Buffer
+	z := zip.NewWriter(&buf)
+	files := map[string][]byte{"archive/data.pkl": pkl, "archive/version": []byte("3\n"), "archive/byteorder": []byte("little")}
+	for key, data := range storages {
+		files["archive/data/"+key] = data
+	}
+	for name, data := range files {
+		w, err := z.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Store})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = w.Write(data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := z.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func float32Bytes(vs ...float32) []byte {
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, vs)
+	return buf.Bytes()
+}
+
+func read(t *testing.T, p []byte) (*StateDict, error) {
+	return Read(bytes.NewReader(p), int64(len(p)))
+}
+
+func TestRead(t *testing.T) {
+	assert := assert.New(t)
+	pkl := stateDict(
+		savedTensor{name: "fc.weight", storage: "FloatStorage", key: "0", numel: 6, shape: []int{2, 3}, strides: []int{3, 1}},
+		savedTensor{name: "fc.bias", storage: "FloatStorage", key: "1", numel: 2, shape: []int{2}, strides: []int{1}},
+		// a transposed view of the storage of the weight, and a view of its last row
+		savedTensor{name: "fc.weight_t", storage: "FloatStorage", key: "0", numel: 6, shape: []int{3, 2}, strides: []int{1, 3}},
+		savedTensor{name: "fc.row", storage: "FloatStorage", key: "0", numel: 6, offset: 3, shape: []int{3}, strides: []int{1}},
+		savedTensor{name: "half", storage: "HalfStorage", key: "2", numel: 3, shape: []int{3}, strides: []int{1}},
+		savedTensor{name: "steps", storage: "LongStorage", key: "3", numel: 1, shape: []int{}, strides: []int{}},
+	)
+	half := []byte{0x00, 0x3c, 0x00, 0xc0, 0x00, 0x7c} // 1, -2, +Inf
+	steps := make([]byte, 8)
+	binary.LittleEndian.PutUint64(steps, 42)
+	p := archiveOf(t, pkl, map[string][]byte{"0": float32Bytes(1, 2, 3, 4, 5, 6), "1": float32Bytes(0.5, -0.5), "2": half, "3": steps})
+
+	dir, err := ioutil.TempDir("", "torch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "model.pth")
+	if err = ioutil.WriteFile(path, p, 0644); err != nil {
+		t.Fatal(err)
+	}
+	sd, err := ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]string{"fc.weight", "fc.bias", "fc.weight_t", "fc.row", "half", "steps"}, sd.Names)
+	w := sd.Tensor("fc.weight")
+	assert.Equal(tensor.Shape{2, 3}, w.Shape())
+	assert.Equal([]float32{1, 2, 3, 4, 5, 6}, w.Data())
+	assert.Equal([]float32{0.5, -0.5}, sd.Tensor("fc.bias").Data())
+	assert.Equal([]float32{1, 4, 2, 5, 3, 6}, sd.Tensor("fc.weight_t").Data())
+	assert.Equal([]float32{4, 5, 6}, sd.Tensor("fc.row").Data())
+	h := sd.Tensor("half").Data().([]float32)
+	assert.Equal([]float32{1, -2}, h[:2])
+	assert.True(math.IsInf(float64(h[2]), 1))
+	assert.Equal(int64(42), sd.Tensor("steps").Data())
+	assert.Nil(sd.Tensor("nope"))
+}
+
+func TestRead_nested(t *testing.T) {
+	assert := assert.New(t)
+	// {"model": OrderedDict(...), "epoch": 3}, as the checkpoints of the training loops
+	p := new(pickler)
+	p.op(opProto, 2, opEmptyDict)
+	p.put()
+	p.op(opMark)
+	p.str("model")
+	p.orderedDict()
+	p.tensor(savedTensor{name: "w", storage: "DoubleStorage", key: "0", numel: 2, shape: []int{2}, strides: []int{1}})
+	p.end()
+	p.str("epoch")
+	p.int(3)
+	p.op(opSetItems, opStop)
+
+	var data bytes.Buffer
+	binary.Write(&data, binary.LittleEndian, []float64{1.5, -2})
+	sd, err := read(t, archiveOf(t, p.Bytes(), map[string][]byte{"0": data.Bytes()}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]string{"model.w"}, sd.Names)
+	assert.Equal([]float64{1.5, -2}, sd.Tensor("model.w").Data())
+}
+
+func TestRead_errors(t *testing.T) {
+	assert := assert.New(t)
+	weight := savedTensor{name: "w", storage: "FloatStorage", key: "0", numel: 2, shape: []int{2}, strides: []int{1}}
+	storages := map[string][]byte{"0": float32Bytes(1, 2)}
+
+	_, err := read(t, []byte("\x80\x02}q\x00."))
+	assert.Error(err, "the legacy format")
+	_, err = read(t, archiveOf(t, stateDict(weight), nil))
+	assert.Error(err, "no storage")
+	_, err = read(t, archiveOf(t, stateDict(weight), map[string][]byte{"0": float32Bytes(1)}))
+	assert.Error(err, "a short storage")
+	_, err = read(t, archiveOf(t, stateDict(savedTensor{name: "w", storage: "ComplexFloatStorage", key: "0", numel: 2, shape: []int{2}, strides: []int{1}}), storages))
+	assert.Error(err, "an unsupported storage")
+	_, err = read(t, archiveOf(t, stateDict(savedTensor{name: "w", storage: "FloatStorage", key: "0", numel: 2, shape: []int{3}, strides: []int{1}}), storages))
+	assert.Error(err, "a view out of its storage")
+	_, err = read(t, archiveOf(t, stateDict(), nil))
+	assert.Error(err, "no tensors")
+	_, err = read(t, archiveOf(t, []byte("\x80\x02}q\x00"), nil))
+	assert.Error(err, "no STOP")
+	_, err = read(t, archiveOf(t, []byte("\x80\x02I1\n."), nil))
+	assert.Error(err, "an unsupported opcode")
+
+	// the corrupted sizes are not allocated
+	_, err = read(t, archiveOf(t, stateDict(savedTensor{name: "w", storage: "FloatStorage", key: "0", numel: 2, shape: []int{1 << 30, 1 << 30, 1 << 30}, strides: []int{0, 0, 0}}), storages))
+	assert.Error(err, "more elements than the storage")
+	_, err = read(t, archiveOf(t, []byte("\x80\x02X\xff\xff\xff\x7f."), nil))
+	assert.Error(err, "a string longer than the pickle")
+
+	// torch.save(model): the modules are pickled with their classes
+	p := new(pickler)
+	p.op(opProto, 2)
+	p.global("__main__", "Net")
+	p.op(opEmptyTuple, opNewObj, opEmptyDict, opBuild, opStop)
+	_, err = read(t, archiveOf(t, p.Bytes(), nil))
+	if assert.Error(err) {
+		assert.Contains(err.Error(), "__main__.Net")
+	}
+}
+
+func TestRead_corrupt(t *testing.T) {
+	weight := savedTensor{name: "w", storage: "FloatStorage", key: "0", numel: 4, shape: []int{2, 2}, strides: []int{2, 1}}
+	storages := map[string][]byte{"0": float32Bytes(1, 2, 3, 4)}
+	pkl := stateDict(weight)
+	// every byte of the pickle is corrupted in turn: the errors are returned, and nothing is allocated from the
+	// corrupted sizes
+	for i := range pkl {
+		for _, b := range []byte{0xff, 0x7f, 0} {
+			c := append([]byte{}, pkl...)
+			c[i] = b
+			read(t, archiveOf(t, c, storages))
+		}
+	}
+}
@@ -0,0 +1,497 @@
+package torch
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"math"
+	"math/big"
+
+	"github.com/pkg/errors"
+)
+
+// This file holds the unpickler of the subset of the pickle protocol that torch.save writes for the state_dicts. Only
+// the globals that are known, such as collections.OrderedDict and the rebuilders of the tensors, are resolved, so that
+// a file cannot run any code.
+
+// the opcodes of the pickle protocol, up to the version 5
+const (
+	opMark            = '('
+	opStop            = '.'
+	opPop             = '0'
+	opPopMark         = '1'
+	opDup             = '2'
+	opBinFloat        = 'G'
+	opInt             = 'I'
+	opBinInt          = 'J'
+	opBinInt1         = 'K'
+	opBinInt2         = 'M'
+	opNone            = 'N'
+	opBinPersID       = 'Q'
+	opReduce          = 'R'
+	opBinString       = 'T'
+	opShortBinString  = 'U'
+	opBinUnicode      = 'X'
+	opAppend          = 'a'
+	opBuild           = 'b'
+	opGlobal          = 'c'
+	opDict            = 'd'
+	opEmptyDict       = '}'
+	opAppends         = 'e'
+	opBinGet          = 'h'
+	opLongBinGet      = 'j'
+	opList            = 'l'
+	opEmptyList       = ']'
+	opBinPut          = 'q'
+	opLongBinPut      = 'r'
+	opSetItem         = 's'
+	opTuple           = 't'
+	opEmptyTuple      = ')'
+	opSetItems        = 'u'
+	opProto           = 0x80
+	opNewObj          = 0x81
+	opTuple1          = 0x85
+	opTuple2          = 0x86
+	opTuple3          = 0x87
+	opNewTrue         = 0x88
+	opNewFalse        = 0x89
+	opLong1           = 0x8a
+	opLong4           = 0x8b
+	opBinBytes        = 'B'
+	opShortBinBytes   = 'C'
+	opShortBinUnicode = 0x8c
+	opBinUnicode8     = 0x8d
+	opBinBytes8       = 0x8e
+	opEmptySet        = 0x8f
+	opAddItems        = 0x90
+	opFrozenSet       = 0x91
+	opNewObjEx        = 0x92
+	opStackGlobal     = 0x93
+	opMemoize         = 0x94
+	opFrame           = 0x95
+)
+
+// the values of the pickles
+type (
+	tuple []interface{}
+	list  []interface{}
+
+	// dict is a dict, or an OrderedDict, with the order of its keys.
+	dict struct {
+		keys   []interface{}
+		values map[interface{}]interface{}
+	}
+
+	// global is a class or a function of a module.
+	global struct{ module, name string }
+
+	mark struct{}
+)
+
+func newDict() *dict { return &dict{values: make(map[interface{}]interface{})} }
+
+func (d *dict) set(k, v interface{}) error {
+	switch k.(type) {
+	case string, int64, bool, float64, nil, global:
+	default:
+		return errors.Errorf("Cannot use a %T as the key of a dict", k)
+	}
+	if _, ok := d.values[k]; !ok {
+		d.keys = append(d.keys, k)
+	}
+	d.values[k] = v
+	return nil
+}
+
+func (g global) String() string { return g.module + "." + g.name }
+
+// unpickler reads a pickle of size bytes. persistent loads the values of the persistent IDs, and call calls the globals.
+type unpickler struct {
+	r          *bufio.Reader
+	size       uint64
+	stack      []interface{}
+	memo       map[int64]interface{}
+	persistent func(pid interface{}) (interface{}, error)
+	call       func(fn global, args tuple) (interface{}, error)
+}
+
+func unpickle(r io.Reader, size uint64, persistent func(interface{}) (interface{}, error), call func(global, tuple) (interface{}, error)) (interface{}, error) {
+	u := &unpickler{r: bufio.NewReader(r), size: size, memo: make(map[int64]interface{}), persistent: persistent, call: call}
+	return u.load()
+}
+
+func (u *unpickler) push(v interface{}) { u.stack = append(u.stack, v) }
+
+func (u *unpickler) pop() (interface{}, error) {
+	if len(u.stack) == 0 {
+		return nil, errors.New("The pickle pops an empty stack")
+	}
+	v := u.stack[len(u.stack)-1]
+	u.stack = u.stack[:len(u.stack)-1]
+	if _, ok := v.(mark); ok {
+		return nil, errors.New("The pickle pops a mark")
+	}
+	return v, nil
+}
+
+func (u *unpickler) top() (interface{}, error) {
+	if len(u.stack) == 0 {
+		return nil, errors.New("The pickle reads an empty stack")
+	}
+	return u.stack[len(u.stack)-1], nil
+}
+
+// popMark pops the values down to the last mark.
+func (u *unpickler) popMark() ([]interface{}, error) {
+	for i := len(u.stack) - 1; i >= 0; i-- {
+		if _, ok := u.stack[i].(mark); ok {
+			retVal := append([]interface{}(nil), u.stack[i+1:]...)
+			u.stack = u.stack[:i]
+			return retVal, nil
+		}
+	}
+	return nil, errors.New("The pickle has no mark")
+}
+
+func (u *unpickler) bytes(n uint64) ([]byte, error) {
+	// a corrupted length is not allocated: a value is within the pickle
+	if n > math.MaxInt32 || n > u.size {
+		return nil, errors.Errorf("The pickle of %d bytes has a value of %d bytes", u.size, n)
+	}
+	p := make([]byte, n)
+	_, err := io.ReadFull(u.r, p)
+	return p, err
+}
+
+func (u *unpickler) uint(n int) (uint64, error) {
+	p, err := u.bytes(uint64(n))
+	if err != nil {
+		return 0, err
+	}
+	var retVal uint64
+	for i := n - 1; i >= 0; i-- {
+		retVal = retVal<<8 | uint64(p[i])
+	}
+	return retVal, nil
+}
+
+// line reads a line of the text opcodes, such as those of GLOBAL
+func (u *unpickler) line() (string, error) {
+	s, err := u.r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return s[:len(s)-1], nil
+}
+
+func (u *unpickler) load() (interface{}, error) {
+	for {
+		op, err := u.r.ReadByte()
+		if err != nil {
+			return nil, errors.Wrap(err, "The pickle ends before its STOP")
+		}
+		if op == opStop {
+			return u.pop()
+		}
+		if err = u.exec(op); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (u *unpickler) exec(op byte) (err error) {
+	var n uint64
+	var v interface{}
+	switch op {
+	case opProto:
+		_, err = u.r.ReadByte()
+	case opFrame:
+		_, err = u.uint(8)
+	case opMark:
+		u.push(mark{})
+	case opPop:
+		_, err = u.pop()
+	case opPopMark:
+		_, err = u.popMark()
+	case opDup:
+		if v, err = u.top(); err == nil {
+			u.push(v)
+		}
+
+	case opNone:
+		u.push(nil)
+	case opNewTrue:
+		u.push(true)
+	case opNewFalse:
+		u.push(false)
+	case opBinInt:
+		if n, err = u.uint(4); err == nil {
+			u.push(int64(int32(uint32(n))))
+		}
+	case opBinInt1:
+		if n, err = u.uint(1); err == nil {
+			u.push(int64(n))
+		}
+	case opBinInt2:
+		if n, err = u.uint(2); err == nil {
+			u.push(int64(n))
+		}
+	case opLong1, opLong4:
+		size := 1
+		if op == opLong4 {
+			size = 4
+		}
+		if n, err = u.uint(size); err != nil {
+			return err
+		}
+		var p []byte
+		if p, err = u.bytes(n); err != nil {
+			return err
+		}
+		if v, err = long(p); err == nil {
+			u.push(v)
+		}
+	case opBinFloat:
+		var p []byte
+		if p, err = u.bytes(8); err == nil {
+			u.push(math.Float64frombits(binary.BigEndian.Uint64(p)))
+		}
+
+	case opBinUnicode, opBinString, opBinBytes:
+		err = u.pushBytes(4, op != opBinBytes)
+	case opShortBinUnicode, opShortBinString, opShortBinBytes:
+		err = u.pushBytes(1, op != opShortBinBytes)
+	case opBinUnicode8, opBinBytes8:
+		err = u.pushBytes(8, op == opBinUnicode8)
+
+	case opEmptyTuple:
+		u.push(tuple{})
+	case opTuple1, opTuple2, opTuple3:
+		t := make(tuple, op-opTuple1+1)
+		for i := len(t) - 1; i >= 0; i-- {
+			if t[i], err = u.pop(); err != nil {
+				return err
+			}
+		}
+		u.push(t)
+	case opTuple:
+		var items []interface{}
+		if items, err = u.popMark(); err == nil {
+			u.push(tuple(items))
+		}
+	case opEmptyList:
+		u.push(&list{})
+	case opList:
+		var items []interface{}
+		if items, err = u.popMark(); err == nil {
+			l := list(items)
+			u.push(&l)
+		}
+	case opAppend:
+		if v, err = u.pop(); err != nil {
+			return err
+		}
+		err = u.appendTo([]interface{}{v})
+	case opAppends:
+		var items []interface{}
+		if items, err = u.popMark(); err != nil {
+			return err
+		}
+		err = u.appendTo(items)
+	case opEmptyDict:
+		u.push(newDict())
+	case opDict:
+		var items []interface{}
+		if items, err = u.popMark(); err != nil {
+			return err
+		}
+		d := newDict()
+		if err = setItems(d, items); err == nil {
+			u.push(d)
+		}
+	case opSetItem:
+		var k interface{}
+		if v, err = u.pop(); err != nil {
+			return err
+		}
+		if k, err = u.pop(); err != nil {
+			return err
+		}
+		err = u.setItems([]interface{}{k, v})
+	case opSetItems:
+		var items []interface{}
+		if items, err = u.popMark(); err != nil {
+			return err
+		}
+		err = u.setItems(items)
+	case opEmptySet:
+		u.push(&list{}) // the sets are only read as lists
+	case opAddItems:
+		var items []interface{}
+		if items, err = u.popMark(); err != nil {
+			return err
+		}
+		err = u.appendTo(items)
+	case opFrozenSet:
+		var items []interface{}
+		if items, err = u.popMark(); err == nil {
+			l := list(items)
+			u.push(&l)
+		}
+
+	case opBinPut, opLongBinPut, opMemoize:
+		if v, err = u.top(); err != nil {
+			return err
+		}
+		key := int64(len(u.memo))
+		switch op {
+		case opBinPut:
+			n, err = u.uint(1)
+			key = int64(n)
+		case opLongBinPut:
+			n, err = u.uint(4)
+			key = int64(n)
+		}
+		u.memo[key] = v
+	case opBinGet, opLongBinGet:
+		size := 1
+		if op == opLongBinGet {
+			size = 4
+		}
+		if n, err = u.uint(size); err != nil {
+			return err
+		}
+		var ok bool
+		if v, ok = u.memo[int64(n)]; !ok {
+			return errors.Errorf("The pickle gets the memo %d, which was not put", n)
+		}
+		u.push(v)
+
+	case opGlobal:
+		var module, name string
+		if module, err = u.line(); err != nil {
+			return err
+		}
+		if name, err = u.line(); err != nil {
+			return err
+		}
+		u.push(global{module, name})
+	case opStackGlobal:
+		var module, name interface{}
+		if name, err = u.pop(); err != nil {
+			return err
+		}
+		if module, err = u.pop(); err != nil {
+			return err
+		}
+		m, ok1 := module.(string)
+		nm, ok2 := name.(string)
+		if !ok1 || !ok2 {
+			return errors.New("The pickle has a STACK_GLOBAL of values that are not strings")
+		}
+		u.push(global{m, nm})
+	case opReduce, opNewObj:
+		var fn, args interface{}
+		if args, err = u.pop(); err != nil {
+			return err
+		}
+		if fn, err = u.pop(); err != nil {
+			return err
+		}
+		g, ok := fn.(global)
+		t, ok2 := args.(tuple)
+		if !ok || !ok2 {
+			return errors.Errorf("The pickle calls a %T with a %T", fn, args)
+		}
+		if v, err = u.call(g, t); err == nil {
+			u.push(v)
+		}
+	case opBuild:
+		// the states of the objects, such as the _metadata of the state_dicts, are not needed
+		_, err = u.pop()
+	case opBinPersID:
+		var pid interface{}
+		if pid, err = u.pop(); err != nil {
+			return err
+		}
+		if v, err = u.persistent(pid); err == nil {
+			u.push(v)
+		}
+	default:
+		return errors.Errorf("The pickle opcode %#x is not supported", op)
+	}
+	return err
+}
+
+func (u *unpickler) pushBytes(size int, text bool) error {
+	n, err := u.uint(size)
+	if err != nil {
+		return err
+	}
+	p, err := u.bytes(n)
+	if err != nil {
+		return err
+	}
+	if text {
+		u.push(string(p))
+	} else {
+		u.push(p)
+	}
+	return nil
+}
+
+func (u *unpickler) appendTo(items []interface{}) error {
+	v, err := u.top()
+	if err != nil {
+		return err
+	}
+	l, ok := v.(*list)
+	if !ok {
+		return errors.Errorf("The pickle appends to a %T", v)
+	}
+	*l = append(*l, items...)
+	return nil
+}
+
+func (u *unpickler) setItems(items []interface{}) error {
+	v, err := u.top()
+	if err != nil {
+		return err
+	}
+	d, ok := v.(*dict)
+	if !ok {
+		return errors.Errorf("The pickle sets the items of a %T", v)
+	}
+	return setItems(d, items)
+}
+
+func setItems(d *dict, items []interface{}) error {
+	if len(items)%2 != 0 {
+		return errors.New("The pickle sets a key without a value")
+	}
+	for i := 0; i < len(items); i += 2 {
+		if err := d.set(items[i], items[i+1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// long decodes the little endian two's complement integers of LONG1 and LONG4, which must fit in an int64.
+func long(p []byte) (int64, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	be := make([]byte, len(p))
+	for i := range p {
+		be[len(p)-1-i] = p[i]
+	}
+	x := new(big.Int).SetBytes(be)
+	if p[len(p)-1]&0x80 != 0 {
+		x.Sub(x, new(big.Int).Lsh(big.NewInt(1), uint(8*len(p))))
+	}
+	if !x.IsInt64() {
+		return 0, errors.Errorf("The integer %v of the pickle does not fit in an int64", x)
+	}
+	return x.Int64(), nil
+}
@@ -0,0 +1,279 @@
+package gorgonia
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	"gorgonia.org/tensor"
+)
+
+/*
+This file holds the tracing API. With it, a model is written as a plain Go function over Tracers:
+		model := func(in ...Tracer) []Tracer {
+			x, w, b := in[0], in[1], in[2]
+			y := x.MatMul(w).Add(b).Relu()
+			return []Tracer{y.Mul(0.5).Sum()}
+		}
+		traced, err := Trace(model, xVal, wVal, bVal)
+
+Trace calls the function once with Tracers that stand in for the example values. Each operation on a Tracer is
+recorded into an *ExprGraph, so the traced graph is only valid for the shapes and dtypes of the example values. The
+traced graph may then be run with other values of the same shapes and dtypes, or used like any other graph.
+
+Like the fluent API, failed operations do not return errors. The first error is recorded by the trace and returned by Trace.
+*/
+
+// trace is the state shared by all the Tracers of one call to Trace.
+type trace struct {
+	g   *ExprGraph
+	err error
+}
+
+// Tracer is a value that stands in for a *Node while tracing a function. Operations on Tracers are recorded into the
+// traced graph.
+//
+// The arithmetic methods accept any of the following as their operand: Tracer, *Node, Value, and Go numbers. Go numbers
+// are converted to constants of the Tracer's dtype, so expressions like x.Mul(2).Add(1) work for any numeric dtype.
+type Tracer struct {
+	n  *Node
+	tr *trace
+}
+
+// Node returns the node the Tracer stands for. It returns a node holding an error if the operation failed.
+func (t Tracer) Node() *Node { return t.n }
+
+// Shape returns the shape of the traced value.
+func (t Tracer) Shape() tensor.Shape { return t.n.Shape() }
+
+// Dtype returns the dtype of the traced value.
+func (t Tracer) Dtype() tensor.Dtype { return t.n.Dtype() }
+
+// Err returns the error of the operation that produced the Tracer, if any.
+func (t Tracer) Err() error { return t.n.Err() }
+
+// Const creates a constant in the trace. Go numbers are converted to the Tracer's dtype.
+func (t Tracer) Const(v interface{}) Tracer { return t.wrap(t.lift(v)) }
+
+// Apply records the result of a function that does not have a Tracer method:
+//		y := x.Apply(func(n *Node) (*Node, error) { return Conv2d(n, filter, kernelShape, pad, stride, dilation) })
+func (t Tracer) Apply(fn func(*Node) (*Node, error)) Tracer { return t.wrap(fluent1(fn, t.n)) }
+
+// Add performs a pointwise t + u.
+func (t Tracer) Add(u interface{}) Tracer { return t.binary(Add, u) }
+
+// Sub performs a pointwise t - u.
+func (t Tracer) Sub(u interface{}) Tracer { return t.binary(Sub, u) }
+
+// Mul performs a pointwise t * u. Use MatMul for matrix multiplication.
+func (t Tracer) Mul(u interface{}) Tracer { return t.binary(HadamardProd, u) }
+
+// Div performs a pointwise t / u.
+func (t Tracer) Div(u interface{}) Tracer { return t.binary(HadamardDiv, u) }
+
+// Pow performs a pointwise t ^ u.
+func (t Tracer) Pow(u interface{}) Tracer { return t.binary(Pow, u) }
+
+// MatMul performs matrix-matrix, matrix-vector and vector-vector multiplication. See Mul (the function) for details.
+func (t Tracer) MatMul(u interface{}) Tracer { return t.binary(Mul, u) }
+
+// Neg performs a pointwise -t.
+func (t Tracer) Neg() Tracer { return t.wrap(fluent1(Neg, t.n)) }
+
+// Exp performs a pointwise exp.
+func (t Tracer) Exp() Tracer { return t.wrap(fluent1(Exp, t.n)) }
+
+// Log performs a pointwise natural log.
+func (t Tracer) Log() Tracer { return t.wrap(fluent1(Log, t.n)) }
+
+// Sqrt performs a pointwise square root.
+func (t Tracer) Sqrt() Tracer { return t.wrap(fluent1(Sqrt, t.n)) }
+
+// Square performs a pointwise square.
+func (t Tracer) Square() Tracer { return t.wrap(fluent1(Square, t.n)) }
+
+// Tanh performs a pointwise tanh.
+func (t Tracer) Tanh() Tracer { return t.wrap(fluent1(Tanh, t.n)) }
+
+// Sigmoid performs a pointwise sigmoid.
+func (t Tracer) Sigmoid() Tracer { return t.wrap(fluent1(Sigmoid, t.n)) }
+
+// Relu performs a pointwise max(0, t).
+func (t Tracer) Relu() Tracer { return t.wrap(fluent1(Rectify, t.n)) }
+
+// Sum sums the traced value along the axes. All axes are summed if none are given.
+func (t Tracer) Sum(axes ...int) Tracer { return t.wrap(fluentAxial(Sum, t.n, axes)) }
+
+// Mean computes the mean of the traced value along the axes. The mean of all the elements is computed if no axes are given.
+func (t Tracer) Mean(axes ...int) Tracer { return t.wrap(fluentAxial(Mean, t.n, axes)) }
+
+// Max computes the max of the traced value along the axes.
+func (t Tracer) Max(axes ...int) Tracer { return t.wrap(fluentAxial(Max, t.n, axes)) }
+
+// SoftMax performs a softmax along the axes. The last axis is used if none are given.
+func (t Tracer) SoftMax(axes ...int) Tracer { return t.wrap(fluentAxial(SoftMax, t.n, axes)) }
+
+// T transposes the traced value. The axes are reversed if none are given.
+func (t Tracer) T(axes ...int) Tracer { return t.wrap(fluentAxial(Transpose, t.n, axes)) }
+
+// Reshape reshapes the traced value.
+func (t Tracer) Reshape(shape ...int) Tracer {
+	return t.wrap(fluentShape(Reshape, t.n, tensor.Shape(shape)))
+}
+
+func (t Tracer) binary(fn func(a, b *Node) (*Node, error), u interface{}) Tracer {
+	return t.wrap(fluent2(fn, t.n, t.lift(u)))
+}
+
+// wrap records the error held by n, if any, and returns n as a Tracer of the same trace.
+func (t Tracer) wrap(n *Node) Tracer {
+	if err := n.Err(); err != nil && t.tr.err == nil {
+		t.tr.err = err
+	}
+	return Tracer{n: n, tr: t.tr}
+}
+
+// lift converts an operand into a node.
+func (t Tracer) lift(v interface{}) *Node {
+	switch u := v.(type) {
+	case Tracer:
+		if u.tr != t.tr {
+			return errNode(errors.New("Cannot mix Tracers from different traces"))
+		}
+		return u.n
+	case *Node:
+		if u != nil && u.g != nil && u.g != t.tr.g {
+			return errNode(errors.Errorf("Cannot use %v in a trace: it belongs to another graph", u))
+		}
+		return u
+	case Value:
+		return NewConstant(u)
+	}
+
+	if t.n.Err() != nil {
+		// the operation is skipped anyway
+		return t.n
+	}
+	f, ok := traceNumber(v)
+	if !ok {
+		return errNode(errors.Errorf(nyiTypeFail, "Tracer operand", v))
+	}
+	dt := t.Dtype()
+	switch dt {
+	case Float64:
+		return NewConstant(f)
+	case Float32:
+		return NewConstant(float32(f))
+	case Int:
+		return NewConstant(int(f))
+	case Int64:
+		return NewConstant(int64(f))
+	case Int32:
+		return NewConstant(int32(f))
+	}
+	return errNode(errors.Errorf(unsupportedDtype, dt))
+}
+
+// traceNumber converts a Go number into a float64.
+func traceNumber(v interface{}) (float64, bool) {
+	switch x := v.(type) {
+	case float64:
+		return x, true
+	case float32:
+		return float64(x), true
+	case int:
+		return float64(x), true
+	case int64:
+		return float64(x), true
+	case int32:
+		return float64(x), true
+	}
+	return 0, false
+}
+
+// Traced is the result of tracing a function. It holds the graph the function was recorded into.
+type Traced struct {
+	g       *ExprGraph
+	inputs  Nodes
+	outputs Nodes
+	vals    []Value // read from the outputs, as the machine may reuse their memory
+}
+
+// Trace calls fn with one Tracer per example value, and records the operations performed by fn into a new graph.
+// The inputs of the graph have the shapes and dtypes of the example values. The example values are not bound to
+// the inputs. The values of the outputs are read back by Run, so the graph also holds a Read for every output.
+func Trace(fn func(inputs ...Tracer) []Tracer, examples ...Value) (*Traced, error) {
+	tr := &trace{g: NewGraph()}
+	in := make([]Tracer, len(examples))
+	inputs := make(Nodes, len(examples))
+	for i, ex := range examples {
+		if ex == nil {
+			return nil, errors.Errorf("Example %d is nil", i)
+		}
+		name := fmt.Sprintf("in%d", i)
+		var n *Node
+		if ex.Shape().IsScalar() {
+			n = NewScalar(tr.g, ex.Dtype(), WithName(name))
+		} else {
+			shp := ex.Shape().Clone()
+			n = NewTensor(tr.g, ex.Dtype(), shp.Dims(), WithShape(shp...), WithName(name))
+		}
+		inputs[i] = n
+		in[i] = Tracer{n: n, tr: tr}
+	}
+
+	out := fn(in...)
+	if tr.err != nil {
+		return nil, errors.Wrap(tr.err, "Tracing failed")
+	}
+	retVal := &Traced{g: tr.g, inputs: inputs, outputs: make(Nodes, len(out)), vals: make([]Value, len(out))}
+	for i, o := range out {
+		if o.tr != tr {
+			return nil, errors.Errorf("Output %d was not produced by this trace", i)
+		}
+		retVal.outputs[i] = o.n
+		Read(o.n, &retVal.vals[i])
+	}
+	return retVal, nil
+}
+
+// Graph returns the traced graph.
+func (t *Traced) Graph() *ExprGraph { return t.g }
+
+// Inputs returns the input nodes of the traced graph, in the order of the example values.
+func (t *Traced) Inputs() Nodes { return t.inputs }
+
+// Outputs returns the nodes of the values returned by the traced function.
+func (t *Traced) Outputs() Nodes { return t.outputs }
+
+// Run binds the values to the inputs, runs the traced graph and returns the values of the outputs.
+// The values must have the same shapes and dtypes as the example values the function was traced with.
+func (t *Traced) Run(values ...Value) ([]Value, error) {
+	if len(values) != len(t.inputs) {
+		return nil, errors.Errorf("Expected %d values. Got %d instead", len(t.inputs), len(values))
+	}
+	for i, v := range values {
+		in := t.inputs[i]
+		if !v.Shape().Eq(in.Shape()) {
+			return nil, errors.Errorf("Value %d has shape %v. The function was traced with shape %v", i, v.Shape(), in.Shape())
+		}
+		if err := Let(in, v); err != nil {
+			return nil, err
+		}
+	}
+
+	m := NewTapeMachine(t.g)
+	defer m.Close()
+	if err := m.RunAll(); err != nil {
+		return nil, err
+	}
+
+	retVal := make([]Value, len(t.outputs))
+	for i, o := range t.outputs {
+		v, err := CloneValue(t.vals[i])
+		if err != nil {
+			return nil, errors.Wrapf(err, cloneFail, o)
+		}
+		retVal[i] = v
+	}
+	return retVal, nil
+}
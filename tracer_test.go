@@ -0,0 +1,118 @@
+package gorgonia
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorgonia.org/tensor"
+)
+
+func TestTrace(t *testing.T) {
+	assert := assert.New(t)
+	model := func(in ...Tracer) []Tracer {
+		x, w, b := in[0], in[1], in[2]
+		y := x.MatMul(w).Add(b).Relu()
+		return []Tracer{y, y.Mul(2).Sum()}
+	}
+
+	x := tensor.New(tensor.WithShape(2, 3), tensor.WithBacking([]float64{1, 2, 3, 4, 5, 6}))
+	w := tensor.New(tensor.WithShape(3, 2), tensor.WithBacking([]float64{1, 0, 0, 1, 1, -1}))
+	b := tensor.New(tensor.WithShape(2, 2), tensor.WithBacking([]float64{0, 0, 0, -10}))
+	traced, err := Trace(model, x, w, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(3, len(traced.Inputs()))
+	assert.Equal(2, len(traced.Outputs()))
+	assert.True(tensor.Shape{2, 3}.Eq(traced.Inputs()[0].Shape()))
+	assert.True(tensor.Shape{2, 2}.Eq(traced.Outputs()[0].Shape()))
+	assert.True(traced.Outputs()[1].IsScalar())
+	for _, n := range traced.Outputs() {
+		assert.Equal(traced.Graph(), n.g)
+	}
+
+	vals, err := traced.Run(x, w, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// x×w = [[4 -1] [10 -1]]
+	assert.Equal([]float64{4, 0, 10, 0}, vals[0].Data())
+	assert.Equal(28.0, vals[1].Data())
+
+	// run again with different values of the same shapes
+	x2 := tensor.New(tensor.WithShape(2, 3), tensor.WithBacking([]float64{0, 0, 1, 0, 0, 2}))
+	if vals, err = traced.Run(x2, w, b); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]float64{1, 0, 2, 0}, vals[0].Data())
+	assert.Equal(6.0, vals[1].Data())
+
+	// the traced graph is specialized on the shapes of the examples
+	x3 := tensor.New(tensor.WithShape(1, 3), tensor.WithBacking([]float64{1, 2, 3}))
+	_, err = traced.Run(x3, w, b)
+	assert.Error(err)
+	_, err = traced.Run(x)
+	assert.Error(err)
+}
+
+func TestTrace_Literals(t *testing.T) {
+	assert := assert.New(t)
+	model := func(in ...Tracer) []Tracer {
+		x := in[0]
+		return []Tracer{x.Mul(3).Sub(1).Div(x.Const(2.0))}
+	}
+
+	x := tensor.New(tensor.WithShape(3), tensor.WithBacking([]float32{1, 2, 3}))
+	traced, err := Trace(model, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(Float32, traced.Outputs()[0].Dtype())
+
+	vals, err := traced.Run(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]float32{1, 2.5, 4}, vals[0].Data())
+
+	// scalar examples
+	traced, err = Trace(func(in ...Tracer) []Tracer { return []Tracer{in[0].Square().Add(in[1])} }, newF64(3), newF64(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if vals, err = traced.Run(newF64(2), newF64(1)); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(5.0, vals[0].Data())
+}
+
+func TestTrace_Errors(t *testing.T) {
+	assert := assert.New(t)
+	x := tensor.New(tensor.WithShape(2, 3), tensor.WithBacking([]float64{1, 2, 3, 4, 5, 6}))
+
+	// shape mismatch; the error is recorded even though the result is discarded
+	_, err := Trace(func(in ...Tracer) []Tracer {
+		in[0].MatMul(in[0])
+		return []Tracer{in[0].Sum()}
+	}, x)
+	assert.Error(err)
+
+	// unsupported operand
+	_, err = Trace(func(in ...Tracer) []Tracer { return []Tracer{in[0].Add("hello")} }, x)
+	assert.Error(err)
+
+	// Tracers from another trace
+	var other Tracer
+	Trace(func(in ...Tracer) []Tracer { other = in[0]; return nil }, x)
+	_, err = Trace(func(in ...Tracer) []Tracer { return []Tracer{in[0].Add(other)} }, x)
+	assert.Error(err)
+	_, err = Trace(func(in ...Tracer) []Tracer { return []Tracer{other} }, x)
+	assert.Error(err)
+
+	// nodes from another graph
+	g := NewGraph()
+	n := NewMatrix(g, Float64, WithShape(2, 3), WithName("n"))
+	_, err = Trace(func(in ...Tracer) []Tracer { return []Tracer{in[0].Add(n)} }, x)
+	assert.Error(err)
+}
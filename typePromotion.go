@@ -0,0 +1,217 @@
+package gorgonia
+
+import (
+	"reflect"
+
+	"github.com/pkg/errors"
+	"gorgonia.org/tensor"
+)
+
+// dtypeCategory is the rough "kind" of a Dtype used for promotion. The order matters: a higher category can represent
+// (possibly with loss of precision) every value of a lower category.
+type dtypeCategory byte
+
+const (
+	notPromotable dtypeCategory = iota
+	boolCategory
+	uintCategory
+	intCategory
+	floatCategory
+	complexCategory
+)
+
+func categoryOf(dt tensor.Dtype) dtypeCategory {
+	if dt.Type == nil {
+		return notPromotable
+	}
+	switch dt.Kind() {
+	case reflect.Bool:
+		return boolCategory
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return uintCategory
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return intCategory
+	case reflect.Float32, reflect.Float64:
+		return floatCategory
+	case reflect.Complex64, reflect.Complex128:
+		return complexCategory
+	}
+	return notPromotable
+}
+
+// sized returns the Dtype with the given category and size (in bytes), preferring the explicitly sized types
+func sized(c dtypeCategory, size uintptr) tensor.Dtype {
+	switch c {
+	case uintCategory:
+		switch size {
+		case 1:
+			return tensor.Uint8
+		case 2:
+			return tensor.Uint16
+		case 4:
+			return tensor.Uint32
+		}
+		return tensor.Uint64
+	case intCategory:
+		switch size {
+		case 1:
+			return tensor.Int8
+		case 2:
+			return tensor.Int16
+		case 4:
+			return tensor.Int32
+		}
+		return tensor.Int64
+	case floatCategory:
+		if size <= 4 {
+			return tensor.Float32
+		}
+		return tensor.Float64
+	case complexCategory:
+		if size <= 8 {
+			return tensor.Complex64
+		}
+		return tensor.Complex128
+	}
+	panic("Unreachable")
+}
+
+// floatSizeFor returns the smallest float size (in bytes) that is considered to safely hold a value of the given Dtype.
+// This follows NumPy: 8 and 16 bit integers fit in a float32, anything larger needs a float64.
+func floatSizeFor(dt tensor.Dtype) uintptr {
+	switch categoryOf(dt) {
+	case boolCategory:
+		return 4
+	case uintCategory, intCategory:
+		if dt.Size() <= 2 {
+			return 4
+		}
+		return 8
+	case floatCategory:
+		return dt.Size()
+	case complexCategory:
+		return dt.Size() / 2
+	}
+	return 8
+}
+
+// PromoteTypes returns the Dtype that both a and b should be cast to before a binary operation is applied to them.
+// The rules follow NumPy's type promotion table:
+//		bool < uint < int < float < complex
+//		uint8 + int8 → int16, uint64 + int64 → float64
+//		int16 + float32 → float32, int32 + float32 → float64
+//		float64 + complex64 → complex128
+// Int and Uint are treated as Int64 and Uint64 respectively. PromoteTypes is commutative.
+func PromoteTypes(a, b tensor.Dtype) (tensor.Dtype, error) {
+	if a == b {
+		return a, nil
+	}
+
+	ca, cb := categoryOf(a), categoryOf(b)
+	if ca == notPromotable || cb == notPromotable {
+		return tensor.Dtype{}, errors.Errorf("Cannot promote %v and %v", a, b)
+	}
+
+	// order a and b such that a is of the lower category
+	if ca > cb || (ca == cb && a.Size() > b.Size()) {
+		a, b = b, a
+		ca, cb = cb, ca
+	}
+
+	switch {
+	case ca == boolCategory:
+		return b, nil
+	case ca == cb:
+		// same category: the larger one wins
+		return sized(cb, b.Size()), nil
+	case ca == uintCategory && cb == intCategory:
+		if b.Size() > a.Size() {
+			return b, nil
+		}
+		if a.Size() < 8 {
+			return sized(intCategory, a.Size()*2), nil
+		}
+		return tensor.Float64, nil
+	case cb == floatCategory:
+		size := floatSizeFor(a)
+		if b.Size() > size {
+			size = b.Size()
+		}
+		return sized(floatCategory, size), nil
+	case cb == complexCategory:
+		size := floatSizeFor(a)
+		if b.Size()/2 > size {
+			size = b.Size() / 2
+		}
+		return sized(complexCategory, size*2), nil
+	}
+	panic("Unreachable")
+}
+
+// promoteNodeTypes returns the Dtype that both a and b should be cast to. It differs from PromoteTypes in that
+// when a scalar meets a tensor and the scalar is of the same or lower category, the tensor's Dtype wins.
+// This keeps expressions like `x * 2.0` in the Dtype of x.
+func promoteNodeTypes(a, b *Node) (tensor.Dtype, error) {
+	adt, err := dtypeOf(a.t)
+	if err != nil {
+		return tensor.Dtype{}, errors.Wrap(err, dtypeOfFail)
+	}
+	bdt, err := dtypeOf(b.t)
+	if err != nil {
+		return tensor.Dtype{}, errors.Wrap(err, dtypeOfFail)
+	}
+	if adt == bdt {
+		return adt, nil
+	}
+
+	ca, cb := categoryOf(adt), categoryOf(bdt)
+	switch {
+	case a.IsScalar() && !b.IsScalar() && ca != notPromotable && ca <= cb:
+		return bdt, nil
+	case b.IsScalar() && !a.IsScalar() && cb != notPromotable && cb <= ca:
+		return adt, nil
+	}
+	return PromoteTypes(adt, bdt)
+}
+
+// autoCast promotes the inputs of a binary operation to a common Dtype, inserting cast nodes where required.
+// If the op is an elemBinOp, a new op with the correct types is returned. If no casting is required, the inputs are returned as is.
+func autoCast(op BinaryOp, a, b *Node) (BinaryOp, *Node, *Node, error) {
+	to, err := promoteNodeTypes(a, b)
+	if err != nil {
+		return nil, nil, nil, errors.Wrapf(err, "Unable to promote types of %v and %v", a, b)
+	}
+
+	a2, err := castTo(a, to)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	b2, err := castTo(b, to)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if a2 == a && b2 == b {
+		return op, a, b, nil
+	}
+	a, b = a2, b2
+
+	if ebo, ok := op.(elemBinOp); ok {
+		retSame := ebo.retSame
+		ebo = newElemBinOp(ebo.binOpType(), a, b)
+		ebo.retSame = retSame
+		op = ebo
+	}
+	return op, a, b, nil
+}
+
+// castTo casts a to the given Dtype. If a is already of that Dtype, a is returned.
+func castTo(a *Node, to tensor.Dtype) (*Node, error) {
+	dt, err := dtypeOf(a.t)
+	if err != nil {
+		return nil, errors.Wrap(err, dtypeOfFail)
+	}
+	if dt == to {
+		return a, nil
+	}
+	return ApplyOp(newCastOp(a, to), a)
+}
@@ -0,0 +1,111 @@
+package gorgonia
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorgonia.org/tensor"
+)
+
+var promoteTypesTests = []struct {
+	a, b    tensor.Dtype
+	correct tensor.Dtype
+	err     bool
+}{
+	{Float64, Float64, Float64, false},
+	{Float32, Float64, Float64, false},
+	{Bool, Int, Int, false},
+	{Bool, Float32, Float32, false},
+	{tensor.Int8, tensor.Int32, tensor.Int32, false},
+	{tensor.Int, tensor.Int64, tensor.Int64, false},
+	{tensor.Uint8, tensor.Uint16, tensor.Uint16, false},
+	{tensor.Uint8, tensor.Int8, tensor.Int16, false},
+	{tensor.Uint32, tensor.Int32, tensor.Int64, false},
+	{tensor.Uint8, tensor.Int32, tensor.Int32, false},
+	{tensor.Uint64, tensor.Int64, Float64, false},
+	{tensor.Int16, Float32, Float32, false},
+	{tensor.Int32, Float32, Float64, false},
+	{tensor.Uint8, Float64, Float64, false},
+	{Float32, tensor.Complex64, tensor.Complex64, false},
+	{Float64, tensor.Complex64, tensor.Complex128, false},
+	{tensor.Int64, tensor.Complex64, tensor.Complex128, false},
+	{tensor.Int8, tensor.Complex64, tensor.Complex64, false},
+
+	{tensor.String, Float64, tensor.Dtype{}, true},
+	{Ptr, Int, tensor.Dtype{}, true},
+}
+
+func TestPromoteTypes(t *testing.T) {
+	assert := assert.New(t)
+	for _, pts := range promoteTypesTests {
+		dt, err := PromoteTypes(pts.a, pts.b)
+		if pts.err {
+			assert.Error(err, "Expected an error promoting %v and %v", pts.a, pts.b)
+			continue
+		}
+		if assert.NoError(err) {
+			assert.Equal(pts.correct, dt, "Promoting %v and %v", pts.a, pts.b)
+		}
+
+		// commutativity
+		dt, err = PromoteTypes(pts.b, pts.a)
+		if assert.NoError(err) {
+			assert.Equal(pts.correct, dt, "Promoting %v and %v", pts.b, pts.a)
+		}
+	}
+}
+
+func TestAutoCast(t *testing.T) {
+	assert := assert.New(t)
+
+	// without autocast, mixing dtypes fails
+	g := NewGraph()
+	x := NewVector(g, Float32, WithShape(3), WithName("x"))
+	y := NewVector(g, Float64, WithShape(3), WithName("y"))
+	_, err := Add(x, y)
+	assert.Error(err)
+
+	g = NewGraph(WithAutoCast())
+	x = NewVector(g, Float32, WithShape(3), WithName("x"), WithValue(tensor.New(tensor.WithBacking([]float32{1, 2, 3}))))
+	y = NewVector(g, Float64, WithShape(3), WithName("y"), WithValue(tensor.New(tensor.WithBacking([]float64{1, 2, 3}))))
+	z, err := Add(x, y)
+	if !assert.NoError(err) {
+		t.FailNow()
+	}
+	assert.Equal(Float64, z.Dtype())
+	assert.Equal(tensor.Shape{3}, z.Shape())
+	var zv Value
+	Read(z, &zv)
+
+	// scalars do not upcast tensors of the same category
+	two := NewConstant(2.0)
+	w, err := Mul(z, two)
+	if !assert.NoError(err) {
+		t.FailNow()
+	}
+	assert.Equal(Float64, w.Dtype())
+
+	i := NewVector(g, Int, WithShape(3), WithName("i"), WithValue(tensor.New(tensor.WithBacking([]int{1, 1, 1}))))
+	v, err := Sub(x, i)
+	if !assert.NoError(err) {
+		t.FailNow()
+	}
+	assert.Equal(Float64, v.Dtype())
+
+	k := NewScalar(g, Float64, WithName("k"), WithValue(2.0))
+	u, err := Mul(x, k)
+	if !assert.NoError(err) {
+		t.FailNow()
+	}
+	assert.Equal(Float32, u.Dtype())
+
+	m := NewTapeMachine(g)
+	defer m.Close()
+	if err = m.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]float64{2, 4, 6}, zv.Data())
+	assert.Equal([]float64{4, 8, 12}, w.Value().Data())
+	assert.Equal([]float64{0, 1, 2}, v.Value().Data())
+	assert.Equal([]float32{2, 4, 6}, u.Value().Data())
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"hash/fnv"
 	"math"
+	"reflect"
+	"sync/atomic"
 
 	"github.com/chewxy/math32"
 	"github.com/pkg/errors"
@@ -151,6 +153,17 @@ func ones(dt tensor.Dtype, sizes ...int) (retVal Value) {
 	return tensor.Ones(dt, sizes...)
 }
 
+// floatConstant creates a scalar constant of the float dtype.
+func floatConstant(dt tensor.Dtype, v float64) (*Node, error) {
+	switch dt {
+	case tensor.Float64:
+		return NewConstant(v), nil
+	case tensor.Float32:
+		return NewConstant(float32(v)), nil
+	}
+	return nil, errors.Errorf(nyiFail, "floatConstant", dt)
+}
+
 func hasInf(v Value, dev Device) bool {
 	switch vt := v.(type) {
 	case *F64:
@@ -271,6 +284,13 @@ func ceilDivInt(a, b int) int {
 	return (a + b - 1) / b
 }
 
+// uniqueOps counts the ops that must never be hash consed with another op, such as ops holding a callback or a source
+// of randomness.
+var uniqueOps uint64
+
+// uniqueOpID returns a new id, to be written in the hash of such an op.
+func uniqueOpID() uint64 { return atomic.AddUint64(&uniqueOps, 1) }
+
 func simpleHash(op hashWriter) uint32 {
 	h := fnv.New32a()
 	op.WriteHash(h)
@@ -310,3 +330,27 @@ func scalarEquiv(s tensor.Shape) bool {
 
 	return prod == 1
 }
+
+// valueSlice returns the data of a Value as a slice. Views are materialized, and scalars are returned as slices of 1 element.
+func valueSlice(v Value) (retVal reflect.Value, err error) {
+	var data interface{}
+	switch vt := v.(type) {
+	case Scalar:
+		data = vt.Data()
+	case tensor.Tensor:
+		if vt.RequiresIterator() {
+			vt = tensor.Materialize(vt)
+		}
+		data = vt.Data()
+	default:
+		return retVal, errors.Errorf(nyiTypeFail, "valueSlice", v)
+	}
+
+	retVal = reflect.ValueOf(data)
+	if retVal.Kind() != reflect.Slice {
+		s := reflect.MakeSlice(reflect.SliceOf(retVal.Type()), 1, 1)
+		s.Index(0).Set(retVal)
+		retVal = s
+	}
+	return retVal, nil
+}
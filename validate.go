@@ -0,0 +1,131 @@
+package gorgonia
+
+import (
+	"bytes"
+	"fmt"
+)
+
+/*
+This file holds the validation of the graphs. Validate checks the whole graph before it is compiled, and reports every
+problem that it finds rather than the first one, so that a graph built programmatically can be fixed in one go:
+
+		if err := g.Validate(); err != nil {
+			for _, p := range err.(ValidationError) {
+				log.Printf("%v: %v", p.Kind, p.Msg)
+			}
+		}
+*/
+
+// ProblemKind is the kind of a Problem found by Validate.
+type ProblemKind byte
+
+// Problem kinds
+const (
+	DanglingNode           ProblemKind = iota // a variable that no node uses, or a node using a node removed from the graph
+	DtypeMismatch                             // a value or a gradient whose dtype is not that of its node
+	UninitializedLearnable                    // a differentiated learnable with no value
+	Cycle                                     // the graph is not acyclic
+	UnreachableDevice                         // a node that cannot be executed on its device
+	GradientMismatch                          // a gradient of a node that is not a learnable, or of the wrong shape
+)
+
+func (k ProblemKind) String() string {
+	switch k {
+	case DanglingNode:
+		return "dangling node"
+	case DtypeMismatch:
+		return "dtype mismatch"
+	case UninitializedLearnable:
+		return "uninitialized learnable"
+	case Cycle:
+		return "cycle"
+	case UnreachableDevice:
+		return "unreachable device"
+	case GradientMismatch:
+		return "gradient mismatch"
+	}
+	return fmt.Sprintf("ProblemKind(%d)", byte(k))
+}
+
+// Problem is a problem of a graph found by Validate.
+type Problem struct {
+	Kind ProblemKind
+	Node *Node // the node at fault, or nil if the problem is that of the whole graph
+	Msg  string
+}
+
+func (p Problem) String() string { return fmt.Sprintf("%v: %s", p.Kind, p.Msg) }
+
+// ValidationError is the error of Validate: the problems of the graph, in the order of the nodes of the graph.
+type ValidationError []Problem
+
+func (err ValidationError) Error() string {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%d problems found in the graph:", len(err))
+	for _, p := range err {
+		fmt.Fprintf(&buf, "\n\t%v", p)
+	}
+	return buf.String()
+}
+
+// Validate checks the graph before it is compiled. It returns a ValidationError holding all the problems that it
+// finds, or nil if there are none:
+//   - the variables that no node uses, and the nodes using nodes removed from the graph
+//   - the values and the gradients whose dtypes are not those of their nodes
+//   - the learnables that are differentiated but have no value
+//   - the cycles
+//   - the nodes placed on devices where they cannot be executed
+//   - the gradients of the nodes that are not learnables (see Learnables), and the gradients of the wrong shapes
+func (g *ExprGraph) Validate() error {
+	var problems ValidationError
+	add := func(kind ProblemKind, n *Node, format string, args ...interface{}) {
+		problems = append(problems, Problem{Kind: kind, Node: n, Msg: fmt.Sprintf(format, args...)})
+	}
+
+	if _, err := Sort(g); err != nil {
+		add(Cycle, nil, "the graph is not acyclic: %v", err)
+	}
+
+	learnables := g.Learnables().mapSet()
+	all := g.AllNodes()
+	for _, n := range all {
+		for _, child := range n.children {
+			if !g.Has(child.ID()) {
+				add(DanglingNode, n, "%v uses %v, which is not in the graph", n, child)
+			}
+		}
+		if n.isInput() && !n.isConstant() && len(g.to.get(n)) == 0 && len(all) > 1 {
+			add(DanglingNode, n, "%v is not used by any node", n)
+		}
+
+		if n.boundTo != nil && n.boundTo.Dtype() != n.Dtype() {
+			add(DtypeMismatch, n, "the value of %v is of %v, but the node is of %v", n, n.boundTo.Dtype(), n.Dtype())
+		}
+		if n.deriv != nil && n.deriv.Dtype() != n.Dtype() {
+			add(DtypeMismatch, n, "the gradient %v of %v is of %v, but the node is of %v", n.deriv, n, n.deriv.Dtype(), n.Dtype())
+		}
+
+		if n.deriv != nil && learnables.Contains(n) && n.boundTo == nil {
+			add(UninitializedLearnable, n, "%v is differentiated but has no value", n)
+		}
+
+		if err := placementError(n); err != nil {
+			add(UnreachableDevice, n, "%v", err)
+		}
+
+		if n.deriv == nil || !n.isInput() {
+			continue
+		}
+		if !learnables.Contains(n) {
+			add(GradientMismatch, n, "%v has the gradient %v, but is not a learnable", n, n.deriv)
+		}
+		if !n.deriv.Shape().Eq(n.Shape()) {
+			add(GradientMismatch, n, "the gradient %v of %v is of shape %v, but the node is of shape %v", n.deriv, n, n.deriv.Shape(), n.Shape())
+		}
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+	return problems
+}
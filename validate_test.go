@@ -0,0 +1,73 @@
+package gorgonia
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	assert := assert.New(t)
+	g := NewGraph()
+	x := NewMatrix(g, Float64, WithShape(2, 3), WithName("x"), WithInit(Zeroes()))
+	w := NewMatrix(g, Float64, WithShape(3, 2), WithName("w"), WithInit(Zeroes()))
+	cost := Must(Sum(Must(Mul(x, w))))
+	if _, err := Grad(cost, w); err != nil {
+		t.Fatal(err)
+	}
+	assert.NoError(g.Validate())
+
+	// every problem is reported at once
+	unused := NewVector(g, Float64, WithShape(3), WithName("unused"))
+	b := NewScalar(g, Float64, WithName("b"))
+	cost2 := Must(Sum(Must(Add(Must(Mul(x, w)), b))))
+	if _, err := Grad(cost2, b); err != nil {
+		t.Fatal(err)
+	}
+	Freeze(x)
+	x.deriv = w.deriv // a gradient of a frozen node, of the wrong shape
+	w.boundTo = newF32(1)
+	err := g.Validate()
+	problems, ok := err.(ValidationError)
+	if !ok {
+		t.Fatalf("expected a ValidationError, got %v", err)
+	}
+	kinds := make(map[ProblemKind]Nodes)
+	for _, p := range problems {
+		kinds[p.Kind] = append(kinds[p.Kind], p.Node)
+	}
+	assert.Len(kinds[DanglingNode], 1)
+	assert.True(kinds[DanglingNode][0] == unused)
+	assert.Len(kinds[DtypeMismatch], 1)
+	assert.True(kinds[DtypeMismatch][0] == w)
+	assert.Len(kinds[UninitializedLearnable], 1)
+	assert.True(kinds[UninitializedLearnable][0] == b)
+	assert.Len(kinds[GradientMismatch], 2)
+	assert.True(kinds[GradientMismatch][0] == x)
+	assert.Contains(err.Error(), "5 problems found")
+	assert.Contains(err.Error(), "unused")
+
+	// cycles and the nodes removed from the graph
+	g = NewGraph()
+	x = NewScalar(g, Float64, WithName("x"))
+	y := Must(Exp(x))
+	z := Must(Log(y))
+	y.children = append(y.children, z)
+	g.SetEdge(edge{from: y, to: z})
+	removed := Must(Neg(x))
+	uses := Must(Exp(removed))
+	g.RemoveNode(removed)
+	err = g.Validate()
+	problems, ok = err.(ValidationError)
+	if !ok {
+		t.Fatalf("expected a ValidationError, got %v", err)
+	}
+	kinds = make(map[ProblemKind]Nodes)
+	for _, p := range problems {
+		kinds[p.Kind] = append(kinds[p.Kind], p.Node)
+	}
+	assert.Len(kinds[Cycle], 1)
+	assert.Nil(kinds[Cycle][0])
+	assert.Len(kinds[DanglingNode], 1)
+	assert.True(kinds[DanglingNode][0] == uses)
+}
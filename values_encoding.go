@@ -0,0 +1,540 @@
+package gorgonia
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"gorgonia.org/tensor"
+)
+
+func init() {
+	// so that the scalars can be sent as Values
+	gob.Register(new(F64))
+	gob.Register(new(F32))
+	gob.Register(new(I))
+	gob.Register(new(I64))
+	gob.Register(new(I32))
+	gob.Register(new(U8))
+	gob.Register(new(B))
+}
+
+// ValueEncoding is a serialization format of MarshalValue and UnmarshalValue.
+type ValueEncoding byte
+
+const (
+	// GobEncoding is the gob of a struct with the fields of the Value message of ProtoEncoding.
+	GobEncoding ValueEncoding = iota
+	// JSONEncoding is an object such as
+	//		{"kind": "dense", "dtype": "float32", "shape": [2], "data": [1.5, "NaN"]}
+	// The Inf and NaN floats are strings, and the complex numbers are [real, imag] arrays. The masked tensors have a
+	// "mask" array.
+	JSONEncoding
+	// ProtoEncoding is the protobuf message Value of values.proto. The elements are little-endian, with the ints and
+	// uints as 64 bits and the bools as a byte.
+	ProtoEncoding
+)
+
+func (e ValueEncoding) String() string {
+	switch e {
+	case GobEncoding:
+		return "gob"
+	case JSONEncoding:
+		return "JSON"
+	case ProtoEncoding:
+		return "protobuf"
+	}
+	return fmt.Sprintf("ValueEncoding(%d)", byte(e))
+}
+
+// encodedValue is the common content of the encodings.
+type encodedValue struct {
+	Scalar bool // a Scalar, rather than a tensor of scalar shape
+	Dtype  string
+	Shape  []int
+	Data   []byte
+	Mask   []bool
+}
+
+// serializableDtypes are the Dtypes that MarshalValue supports.
+var serializableDtypes = []tensor.Dtype{
+	tensor.Bool, tensor.Int, tensor.Int8, tensor.Int16, tensor.Int32, tensor.Int64,
+	tensor.Uint, tensor.Uint8, tensor.Uint16, tensor.Uint32, tensor.Uint64,
+	tensor.Float32, tensor.Float64, tensor.Complex64, tensor.Complex128,
+}
+
+func dtypeByName(name string) (tensor.Dtype, error) {
+	for _, dt := range serializableDtypes {
+		if dt.String() == name {
+			return dt, nil
+		}
+	}
+	return tensor.Dtype{}, errors.Errorf("Cannot decode a value of dtype %q", name)
+}
+
+// MarshalValue serializes a value. The scalars stay scalars, the tensors stay tensors of the same Dtype and shape, and
+// the views are serialized as their contiguous copies. Masked tensors keep their masks. The Dtypes of
+// serializableDtypes are supported.
+func MarshalValue(v Value, enc ValueEncoding) ([]byte, error) {
+	ev, s, err := newEncodedValue(v)
+	if err != nil {
+		return nil, err
+	}
+	switch enc {
+	case GobEncoding, ProtoEncoding:
+		if ev.Data, err = elemBytes(s); err != nil {
+			return nil, err
+		}
+		if enc == ProtoEncoding {
+			return ev.proto(), nil
+		}
+		var buf bytes.Buffer
+		if err = gob.NewEncoder(&buf).Encode(ev); err != nil {
+			return nil, errors.Wrapf(err, "Cannot encode %v", v)
+		}
+		return buf.Bytes(), nil
+	case JSONEncoding:
+		return ev.json(s)
+	}
+	return nil, errors.Errorf(nyiFail, "MarshalValue", enc)
+}
+
+// UnmarshalValue deserializes a value serialized by MarshalValue with the same encoding.
+func UnmarshalValue(p []byte, enc ValueEncoding) (Value, error) {
+	var ev encodedValue
+	var s reflect.Value
+	var err error
+	switch enc {
+	case GobEncoding, ProtoEncoding:
+		if enc == ProtoEncoding {
+			err = ev.unproto(p)
+		} else {
+			err = gob.NewDecoder(bytes.NewReader(p)).Decode(&ev)
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "Cannot decode a %v value", enc)
+		}
+		var dt tensor.Dtype
+		if dt, err = dtypeByName(ev.Dtype); err != nil {
+			return nil, err
+		}
+		if s, err = bytesElems(ev.Data, dt); err != nil {
+			return nil, err
+		}
+	case JSONEncoding:
+		if s, err = ev.unjson(p); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errors.Errorf(nyiFail, "UnmarshalValue", enc)
+	}
+	return ev.value(s)
+}
+
+func newEncodedValue(v Value) (ev encodedValue, s reflect.Value, err error) {
+	if dv, ok := v.(*dualValue); ok {
+		v = dv.Value
+	}
+	if !dtypeIn(v.Dtype(), serializableDtypes) {
+		return ev, s, errors.Errorf(nyiTypeFail, "MarshalValue", v.Dtype())
+	}
+	ev.Dtype = v.Dtype().String()
+	switch vt := v.(type) {
+	case Scalar:
+		ev.Scalar = true
+	case tensor.Tensor:
+		ev.Shape = []int(vt.Shape().Clone())
+		if vt.Shape().IsScalar() {
+			ev.Shape = nil
+		}
+		if d, ok := vt.(*tensor.Dense); ok && d.IsMasked() {
+			if d.RequiresIterator() {
+				d = tensor.Materialize(d).(*tensor.Dense)
+			}
+			ev.Mask = append([]bool(nil), d.Mask()...)
+		}
+	default:
+		return ev, s, errors.Errorf(nyiTypeFail, "MarshalValue", v)
+	}
+	s, err = valueSlice(v)
+	return ev, s, err
+}
+
+// value creates the value of ev with the elements of s.
+func (ev encodedValue) value(s reflect.Value) (Value, error) {
+	size := 1
+	for _, d := range ev.Shape {
+		size *= d
+	}
+	if s.Len() != size || (ev.Mask != nil && len(ev.Mask) != size) {
+		return nil, errors.Errorf("Cannot decode %d elements of shape %v", s.Len(), ev.Shape)
+	}
+	switch {
+	case ev.Scalar:
+		if ev.Shape != nil {
+			return nil, errors.Errorf("Cannot decode a scalar of shape %v", ev.Shape)
+		}
+		switch e := s.Index(0).Interface().(type) {
+		case float64, float32, int, int64, int32, byte, bool:
+			sv, _ := anyToScalar(e)
+			return sv, nil
+		}
+		return nil, errors.Errorf("There is no scalar Value of dtype %v", ev.Dtype)
+	case len(ev.Shape) == 0:
+		return tensor.New(tensor.FromScalar(s.Index(0).Interface())), nil
+	case ev.Mask != nil:
+		return tensor.New(tensor.WithShape(ev.Shape...), tensor.WithBacking(s.Interface(), ev.Mask)), nil
+	}
+	return tensor.New(tensor.WithShape(ev.Shape...), tensor.WithBacking(s.Interface())), nil
+}
+
+/* binary elements */
+
+// elemBytes returns the little-endian bytes of the elements of s. The ints and uints are written as 64 bits.
+func elemBytes(s reflect.Value) ([]byte, error) {
+	data := s.Interface()
+	switch d := data.(type) {
+	case []int:
+		c := make([]int64, len(d))
+		for i, x := range d {
+			c[i] = int64(x)
+		}
+		data = c
+	case []uint:
+		c := make([]uint64, len(d))
+		for i, x := range d {
+			c[i] = uint64(x)
+		}
+		data = c
+	}
+	var buf bytes.Buffer
+	if err := binary.Write(&buf, binary.LittleEndian, data); err != nil {
+		return nil, errors.Wrapf(err, "Cannot encode the elements of %T", s.Interface())
+	}
+	return buf.Bytes(), nil
+}
+
+// bytesElems decodes the elements written by elemBytes into a slice of the given dtype.
+func bytesElems(p []byte, dt tensor.Dtype) (reflect.Value, error) {
+	wire := dt.Type
+	switch dt {
+	case tensor.Int:
+		wire = reflect.TypeOf(int64(0))
+	case tensor.Uint:
+		wire = reflect.TypeOf(uint64(0))
+	}
+	elemSize := int(wire.Size())
+	if len(p)%elemSize != 0 {
+		return reflect.Value{}, errors.Errorf("Cannot decode %d bytes of %v elements", len(p), dt)
+	}
+	s := reflect.MakeSlice(reflect.SliceOf(wire), len(p)/elemSize, len(p)/elemSize)
+	if err := binary.Read(bytes.NewReader(p), binary.LittleEndian, s.Interface()); err != nil {
+		return reflect.Value{}, errors.Wrapf(err, "Cannot decode the elements of %v", dt)
+	}
+	if wire == dt.Type {
+		return s, nil
+	}
+	ret := reflect.MakeSlice(reflect.SliceOf(dt.Type), s.Len(), s.Len())
+	for i := 0; i < s.Len(); i++ {
+		ret.Index(i).Set(s.Index(i).Convert(dt.Type))
+	}
+	return ret, nil
+}
+
+/* protobuf */
+
+// the field numbers of the Value message of values.proto
+const (
+	pbKind  = 1
+	pbDtype = 2
+	pbShape = 3
+	pbData  = 4
+	pbMask  = 5
+
+	pbVarint = 0
+	pbBytes  = 2
+)
+
+func (ev encodedValue) proto() []byte {
+	var buf []byte
+	varint := func(v uint64) {
+		var b [binary.MaxVarintLen64]byte
+		buf = append(buf, b[:binary.PutUvarint(b[:], v)]...)
+	}
+	field := func(n, wire int) { varint(uint64(n<<3 | wire)) }
+	bytesField := func(n int, p []byte) {
+		if len(p) == 0 {
+			return
+		}
+		field(n, pbBytes)
+		varint(uint64(len(p)))
+		buf = append(buf, p...)
+	}
+
+	if ev.Scalar {
+		field(pbKind, pbVarint)
+		varint(1)
+	}
+	bytesField(pbDtype, []byte(ev.Dtype))
+	if len(ev.Shape) > 0 {
+		var packed []byte
+		for _, d := range ev.Shape {
+			var b [binary.MaxVarintLen64]byte
+			packed = append(packed, b[:binary.PutUvarint(b[:], uint64(d))]...)
+		}
+		bytesField(pbShape, packed)
+	}
+	bytesField(pbData, ev.Data)
+	if len(ev.Mask) > 0 {
+		packed := make([]byte, len(ev.Mask))
+		for i, m := range ev.Mask {
+			if m {
+				packed[i] = 1
+			}
+		}
+		bytesField(pbMask, packed)
+	}
+	return buf
+}
+
+func (ev *encodedValue) unproto(p []byte) error {
+	varint := func() (uint64, error) {
+		v, n := binary.Uvarint(p)
+		if n <= 0 {
+			return 0, errors.New("Malformed varint")
+		}
+		p = p[n:]
+		return v, nil
+	}
+	for len(p) > 0 {
+		tag, err := varint()
+		if err != nil {
+			return err
+		}
+		n, wire := int(tag>>3), int(tag&7)
+		var v uint64
+		var field []byte
+		switch wire {
+		case pbVarint:
+			if v, err = varint(); err != nil {
+				return err
+			}
+		case pbBytes:
+			if v, err = varint(); err != nil {
+				return err
+			}
+			if v > uint64(len(p)) {
+				return errors.Errorf("Field %d is truncated", n)
+			}
+			field, p = p[:v], p[v:]
+		case 1, 5: // fixed64 and fixed32 fields are not in the schema, and skipped
+			size := 8
+			if wire == 5 {
+				size = 4
+			}
+			if len(p) < size {
+				return errors.Errorf("Field %d is truncated", n)
+			}
+			p = p[size:]
+			continue
+		default:
+			return errors.Errorf("Unsupported wire type %d", wire)
+		}
+
+		switch n {
+		case pbKind:
+			ev.Scalar = v == 1
+		case pbDtype:
+			ev.Dtype = string(field)
+		case pbShape:
+			for len(field) > 0 {
+				d, k := binary.Uvarint(field)
+				if k <= 0 {
+					return errors.New("Malformed shape")
+				}
+				ev.Shape, field = append(ev.Shape, int(d)), field[k:]
+			}
+		case pbData:
+			ev.Data = field
+		case pbMask:
+			for _, b := range field {
+				ev.Mask = append(ev.Mask, b != 0)
+			}
+		}
+	}
+	return nil
+}
+
+/* JSON */
+
+type jsonValue struct {
+	Kind  string            `json:"kind"`
+	Dtype string            `json:"dtype"`
+	Shape []int             `json:"shape"`
+	Data  []json.RawMessage `json:"data"`
+	Mask  []bool            `json:"mask,omitempty"`
+}
+
+func (ev encodedValue) json(s reflect.Value) ([]byte, error) {
+	jv := jsonValue{Kind: "dense", Dtype: ev.Dtype, Shape: ev.Shape, Mask: ev.Mask}
+	if ev.Scalar {
+		jv.Kind = "scalar"
+	}
+	if jv.Shape == nil {
+		jv.Shape = []int{}
+	}
+	jv.Data = make([]json.RawMessage, s.Len())
+	for i := range jv.Data {
+		jv.Data[i] = jsonElem(s.Index(i))
+	}
+	return json.Marshal(jv)
+}
+
+func (ev *encodedValue) unjson(p []byte) (s reflect.Value, err error) {
+	var jv jsonValue
+	if err = json.Unmarshal(p, &jv); err != nil {
+		return s, errors.Wrap(err, "Cannot decode a JSON value")
+	}
+	switch jv.Kind {
+	case "scalar":
+		ev.Scalar = true
+	case "dense":
+	default:
+		return s, errors.Errorf("Unknown kind of value %q", jv.Kind)
+	}
+	var dt tensor.Dtype
+	if dt, err = dtypeByName(jv.Dtype); err != nil {
+		return s, err
+	}
+	ev.Dtype, ev.Mask = jv.Dtype, jv.Mask
+	if len(jv.Shape) > 0 {
+		ev.Shape = jv.Shape
+	}
+	s = reflect.MakeSlice(reflect.SliceOf(dt.Type), len(jv.Data), len(jv.Data))
+	for i, raw := range jv.Data {
+		if err = unjsonElem(raw, s.Index(i)); err != nil {
+			return s, errors.Wrapf(err, "Cannot decode element %d as %v", i, dt)
+		}
+	}
+	return s, nil
+}
+
+// jsonElem returns the JSON of an element. The floats are formatted with the precision of their type, so that a float32
+// decodes to the same float32.
+func jsonElem(e reflect.Value) json.RawMessage {
+	switch e.Kind() {
+	case reflect.Bool:
+		return json.RawMessage(strconv.FormatBool(e.Bool()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return json.RawMessage(strconv.FormatInt(e.Int(), 10))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return json.RawMessage(strconv.FormatUint(e.Uint(), 10))
+	case reflect.Float32:
+		return jsonFloat(e.Float(), 32)
+	case reflect.Float64:
+		return jsonFloat(e.Float(), 64)
+	case reflect.Complex64, reflect.Complex128:
+		bits := 64
+		if e.Kind() == reflect.Complex64 {
+			bits = 32
+		}
+		c := e.Complex()
+		return json.RawMessage("[" + string(jsonFloat(real(c), bits)) + "," + string(jsonFloat(imag(c), bits)) + "]")
+	}
+	panic("Unreachable")
+}
+
+func jsonFloat(f float64, bits int) json.RawMessage {
+	switch {
+	case math.IsNaN(f):
+		return json.RawMessage(`"NaN"`)
+	case math.IsInf(f, 1):
+		return json.RawMessage(`"Inf"`)
+	case math.IsInf(f, -1):
+		return json.RawMessage(`"-Inf"`)
+	}
+	return json.RawMessage(strconv.FormatFloat(f, 'g', -1, bits))
+}
+
+// unjsonElem decodes the JSON of an element into e.
+func unjsonElem(raw json.RawMessage, e reflect.Value) (err error) {
+	text := string(raw)
+	switch e.Kind() {
+	case reflect.Bool:
+		var b bool
+		b, err = strconv.ParseBool(text)
+		e.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var i int64
+		i, err = strconv.ParseInt(text, 10, e.Type().Bits())
+		e.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var u uint64
+		u, err = strconv.ParseUint(text, 10, e.Type().Bits())
+		e.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		f, err = unjsonFloat(raw, e.Type().Bits())
+		e.SetFloat(f)
+	case reflect.Complex64, reflect.Complex128:
+		var parts []json.RawMessage
+		if err = json.Unmarshal(raw, &parts); err != nil {
+			return err
+		}
+		if len(parts) != 2 {
+			return errors.Errorf("Expected [real, imag]. Got %s instead", raw)
+		}
+		var re, im float64
+		if re, err = unjsonFloat(parts[0], e.Type().Bits()/2); err != nil {
+			return err
+		}
+		if im, err = unjsonFloat(parts[1], e.Type().Bits()/2); err != nil {
+			return err
+		}
+		e.SetComplex(complex(re, im))
+	}
+	return err
+}
+
+func unjsonFloat(raw json.RawMessage, bits int) (float64, error) {
+	switch string(raw) {
+	case `"NaN"`:
+		return math.NaN(), nil
+	case `"Inf"`:
+		return math.Inf(1), nil
+	case `"-Inf"`:
+		return math.Inf(-1), nil
+	}
+	return strconv.ParseFloat(string(raw), bits)
+}
+
+/* MarshalJSON() and UnmarshalJSON() */
+
+// The scalars marshal to JSON as their element. The float scalars write the Infs and NaNs, which JSON has no numbers
+// for, as the strings of JSONEncoding.
+
+// MarshalJSON implements json.Marshaler
+func (v *F64) MarshalJSON() ([]byte, error) { return jsonFloat(float64(*v), 64), nil }
+
+// MarshalJSON implements json.Marshaler
+func (v *F32) MarshalJSON() ([]byte, error) { return jsonFloat(float64(*v), 32), nil }
+
+// UnmarshalJSON implements json.Unmarshaler
+func (v *F64) UnmarshalJSON(p []byte) error {
+	f, err := unjsonFloat(p, 64)
+	*v = F64(f)
+	return err
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (v *F32) UnmarshalJSON(p []byte) error {
+	f, err := unjsonFloat(p, 32)
+	*v = F32(f)
+	return err
+}
@@ -0,0 +1,147 @@
+package gorgonia
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorgonia.org/tensor"
+)
+
+// encodedValues are the values of the conformance tests of the encodings.
+func encodedValues() map[string]Value {
+	view := tensor.New(tensor.WithShape(2, 3), tensor.WithBacking([]float32{1, 2, 3, 4, 5, 6}))
+	if err := view.T(); err != nil {
+		panic(err)
+	}
+	vals := map[string]Value{
+		"F64":          newF64(math.Inf(-1)),
+		"F64 NaN":      newF64(math.NaN()),
+		"F32":          newF32(0.1),
+		"I":            newI(-1 << 40),
+		"I64":          newI64(math.MaxInt64),
+		"I32":          newI32(math.MinInt32),
+		"U8":           newU8(255),
+		"B":            newB(true),
+		"scalar Dense": tensor.New(tensor.FromScalar(float32(2.5))),
+		"view":         view,
+		"masked":       tensor.New(tensor.WithShape(3), tensor.WithBacking([]float64{1, math.NaN(), 3}, []bool{false, true, false})),
+		"dual":         &dualValue{Value: newF32(3), d: newF32(1)},
+	}
+	for _, dt := range serializableDtypes {
+		data := reflect.MakeSlice(reflect.SliceOf(dt.Type), 4, 4)
+		for i := 0; i < 4; i++ {
+			switch dt {
+			case tensor.Bool:
+				data.Index(i).SetBool(i%2 == 0)
+			case tensor.Complex64, tensor.Complex128:
+				data.Index(i).SetComplex(complex(float64(i), -1))
+			default:
+				data.Index(i).Set(reflect.ValueOf(i * 3).Convert(dt.Type))
+			}
+		}
+		vals[dt.String()] = tensor.New(tensor.WithShape(2, 1, 2), tensor.WithBacking(data.Interface()))
+	}
+	vals["float32 extremes"] = tensor.New(tensor.WithShape(4), tensor.WithBacking([]float32{math.MaxFloat32, math.SmallestNonzeroFloat32, float32(math.Inf(1)), float32(math.NaN())}))
+	vals["complex64"] = tensor.New(tensor.WithShape(2), tensor.WithBacking([]complex64{complex(0.1, -2), complex(float32(math.NaN()), 1)}))
+	return vals
+}
+
+func TestMarshalValue(t *testing.T) {
+	for _, enc := range []ValueEncoding{GobEncoding, JSONEncoding, ProtoEncoding} {
+		for name, v := range encodedValues() {
+			p, err := MarshalValue(v, enc)
+			if err != nil {
+				t.Errorf("%v %v: %v", enc, name, err)
+				continue
+			}
+			got, err := UnmarshalValue(p, enc)
+			if err != nil {
+				t.Errorf("%v %v: %v", enc, name, err)
+				continue
+			}
+			want := v
+			if dv, ok := v.(*dualValue); ok {
+				want = dv.Value
+			}
+			assert.IsType(t, want, got, "%v %v", enc, name)
+			assert.Equal(t, want.Dtype(), got.Dtype(), "%v %v", enc, name)
+			assert.True(t, want.Shape().Eq(got.Shape()), "%v %v: %v and %v", enc, name, want.Shape(), got.Shape())
+			ok, err := ValuesEqual(want, got, NaNEqual())
+			assert.NoError(t, err, "%v %v", enc, name)
+			assert.True(t, ok, "%v %v: %v and %v", enc, name, want, got)
+			if wd, ok := want.(*tensor.Dense); ok && wd.IsMasked() {
+				assert.Equal(t, wd.Mask(), got.(*tensor.Dense).Mask(), "%v %v", enc, name)
+			}
+		}
+	}
+}
+
+func TestMarshalValue_errors(t *testing.T) {
+	assert := assert.New(t)
+	_, err := MarshalValue(tensor.New(tensor.WithBacking([]string{"a"})), JSONEncoding)
+	assert.Error(err, "strings are not supported")
+	_, err = MarshalValue(newF64(1), ValueEncoding(9))
+	assert.Error(err)
+
+	p, err := MarshalValue(newI(1), ProtoEncoding)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = UnmarshalValue(p[:len(p)-1], ProtoEncoding)
+	assert.Error(err, "truncated")
+	_, err = UnmarshalValue([]byte(`{"kind": "dense", "dtype": "int8", "shape": [2], "data": [1, 200]}`), JSONEncoding)
+	assert.Error(err, "200 overflows int8")
+	_, err = UnmarshalValue([]byte(`{"kind": "dense", "dtype": "int8", "shape": [3], "data": [1, 2]}`), JSONEncoding)
+	assert.Error(err, "the shape does not match the data")
+	_, err = UnmarshalValue([]byte(`{"kind": "scalar", "dtype": "int8", "shape": [], "data": [1]}`), JSONEncoding)
+	assert.Error(err, "there is no Int8 scalar")
+}
+
+func TestValueEncoding_formats(t *testing.T) {
+	assert := assert.New(t)
+	v := tensor.New(tensor.WithShape(2), tensor.WithBacking([]float32{1.5, float32(math.NaN())}))
+	p, err := MarshalValue(v, JSONEncoding)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.JSONEq(`{"kind": "dense", "dtype": "float32", "shape": [2], "data": [1.5, "NaN"]}`, string(p))
+
+	// A Value message written by any other protobuf encoder, with an unknown field 9 that must be skipped.
+	pb := []byte{
+		1<<3 | 0, 1, // kind: SCALAR
+		2<<3 | 2, 5, 'i', 'n', 't', '3', '2', // dtype
+		4<<3 | 2, 4, 0xfe, 0xff, 0xff, 0xff, // data: -2
+		9<<3 | 0, 42,
+	}
+	got, err := UnmarshalValue(pb, ProtoEncoding)
+	if assert.NoError(err) {
+		assert.Equal(newI32(-2), got)
+	}
+	p, err = MarshalValue(newI32(-2), ProtoEncoding)
+	if assert.NoError(err) {
+		assert.Equal(pb[:len(pb)-2], p)
+	}
+
+	// the scalars are also encoded by the standard encoders
+	var buf bytes.Buffer
+	var in Value = newF32(0.25)
+	if err = gob.NewEncoder(&buf).Encode(&in); err != nil {
+		t.Fatal(err)
+	}
+	var out Value
+	if err = gob.NewDecoder(&buf).Decode(&out); assert.NoError(err) {
+		assert.Equal(in, out)
+	}
+	if p, err = json.Marshal([]*F64{newF64(1), newF64(math.Inf(1))}); assert.NoError(err) {
+		assert.Equal(`[1,"Inf"]`, string(p))
+		var back []*F64
+		if assert.NoError(json.Unmarshal(p, &back)) {
+			assert.True(math.IsInf(float64(*back[1]), 1))
+		}
+	}
+}
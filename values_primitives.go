@@ -8,6 +8,7 @@ import (
 
 	"github.com/chewxy/hm"
 	"github.com/pkg/errors"
+	"gonum.org/v1/gonum/mat"
 	"gorgonia.org/tensor"
 )
 
@@ -345,6 +346,9 @@ func anyToValue(any interface{}) (val Value, t hm.Type, dt tensor.Dtype, err err
 		t = TypeOf(a)
 		dt = a.Dtype()
 		return
+	case mat.Matrix:
+		val = DenseFromMat(a)
+		return val, TypeOf(val), tensor.Float64, nil
 	default:
 		err = errors.Errorf("value %v of %T not yet handled", any, any)
 		return
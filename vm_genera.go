@@ -37,6 +37,10 @@ type lispMachine struct {
 
 	runFlags     byte // supposed to go into state stuff.  Placed here for better compacting of struct
 	checkedRoots bool // supposed to go into state stuff.
+
+	retained  retainSet // the values the machine must not write into (see Retain)
+	observers []ExecObserver
+	crash     *crashDumper // non-nil when dumping the failed runs
 }
 
 // NewLispMachine creates a VM that executes the graph as it is traversed. Depending on the VMOpts passed in
@@ -120,6 +124,9 @@ func (m *lispMachine) RunAll() (err error) {
 	if err = m.checkRoots(); err != nil {
 		return errors.Wrap(err, "Could not checkRoots()")
 	}
+	if err = checkPlacement(m.sorted); err != nil {
+		return err
+	}
 
 	if m.runBwd() {
 		defer func() {
@@ -171,9 +178,13 @@ func (m *lispMachine) RunAll() (err error) {
 					node:  m.sorted[m.fwd],
 					instr: m.fwd,
 				}
-				return
+			} else {
+				err = errors.Wrap(err, "RunAll")
+			}
+			if m.crash != nil {
+				err = m.dumpCrash(err)
 			}
-			return errors.Wrap(err, "RunAll")
+			return err
 		case <-doneChan:
 			err := m.ExternMetadata.DoWork()
 			if err != nil {
@@ -259,7 +270,20 @@ func (m *lispMachine) runall(errChan chan error, doneChan chan struct{}) {
 	}
 
 	for err = nil; err == nil && m.fwd < len(m.sorted); m.fwd++ {
-		err = m.forward()
+		var ev *ExecEvent
+		if len(m.observers) > 0 {
+			if ev = m.execEvent(); ev != nil {
+				if err = beforeExec(m.observers, ev); err != nil {
+					continue
+				}
+			}
+		}
+		if err = m.forward(); err != nil {
+			err = withStack(err, m.sorted[m.fwd])
+		} else if ev != nil {
+			ev.Output = ev.Node.Value()
+			err = afterExec(m.observers, ev)
+		}
 	}
 
 	if err != nil {
@@ -310,8 +334,9 @@ func (m *lispMachine) forward() (err error) {
 			return
 		case n.isRandom():
 			machineLogf("binding value of random node")
+			// on a device, the values are generated there rather than copied from the host
 			var v Value
-			if v, err = n.op.Do(); err != nil {
+			if v, err = NewExternalOp(n.op, ExecutionContext{m, n.dataOn}, nil).Do(); err != nil {
 				return errors.Wrapf(err, execFail, n.op, n)
 			}
 
@@ -329,8 +354,16 @@ func (m *lispMachine) forward() (err error) {
 
 	// other wise it's time to execute the op
 	m.logf("execute Op")
+	if !n.isStmt {
+		defer runtimeMetrics.exec(n.op, runtimeMetrics.start())
+	}
 	dev := n.dataOn
-	op := NewExternalOp(n.op, ExecutionContext{m, dev}, nil)
+	// a retained buffer given to WithReuse is not written into either
+	reuse := n.reuse
+	if m.retained.has(reuse) {
+		reuse = nil
+	}
+	op := NewExternalOp(n.op, ExecutionContext{m, dev}, reuse)
 
 	// m.watchedLogf("Result of execution of this node would reside in %v", dev)
 	var output *dualValue
@@ -381,6 +414,12 @@ func (m *lispMachine) forward() (err error) {
 	m.watchedLogf("Before:")
 	m.watchedLogf(m.valueFmt, n.boundTo)
 
+	// a retained value is not written into: the node is bound to a fresh value instead. It is not returned to the pools
+	// either, as unbind would.
+	if !n.isStmt && m.retained.has(n.boundTo) {
+		n.boundTo = nil
+	}
+
 	switch {
 	case (m.g.roots.Contains(n) || n.isRoot()) && !n.isStmt:
 		machineLogf("Applying op %v to root", op)
@@ -495,6 +534,12 @@ func (m *lispMachine) forward() (err error) {
 	}
 	m.watchedLogf("Added to Queue")
 
+	if detectAnomaly() && !n.isStmt {
+		if err = checkAnomaly(n, n.backwardOf, n.boundTo, dev); err != nil {
+			return err
+		}
+	}
+
 	if m.watchNaN() && !n.isStmt {
 		if hasNaN(n.boundTo, dev) {
 			return errors.New("NaN found in value")
@@ -543,6 +588,14 @@ func (m *lispMachine) backward() (err error) {
 
 	m.leaveLogScope()
 
+	if detectAnomaly() {
+		for _, in := range instr.inputs {
+			if err = checkAnomaly(in, instr.output, in.boundTo.(*dualValue).d, instr.ctx.Device); err != nil {
+				return err
+			}
+		}
+	}
+
 	if m.watchNaN() {
 		if hasNaN(instr.output.boundTo, instr.ctx.Device) {
 			return errors.New("NaN found in value")
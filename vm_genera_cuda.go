@@ -3,9 +3,8 @@
 package gorgonia
 
 import (
-	"log"
-
 	"github.com/pkg/errors"
+	"gorgonia.org/gorgonia/internal/logging"
 	"gorgonia.org/tensor"
 )
 
@@ -24,7 +23,7 @@ func (m *lispMachine) init() error {
 	m.df = df
 
 	if err := m.calcMemSize(); err != nil {
-		log.Printf("err1")
+		logging.Log(logging.VM, logging.Error, "failed to compute the memory of the lisp machine", "err", err)
 		return err
 	}
 
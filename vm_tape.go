@@ -9,6 +9,7 @@ import (
 
 	"github.com/chewxy/hm"
 	"github.com/pkg/errors"
+	"gorgonia.org/gorgonia/internal/logging"
 	"gorgonia.org/tensor"
 )
 
@@ -36,6 +37,12 @@ type tapeMachine struct {
 	logFlags    byte
 
 	runFlags byte //  spare2: trace(copy values and put into nodes)
+
+	incr      *incrementalState // non-nil when executing incrementally
+	spill     *spillState       // non-nil when spilling values under a memory budget
+	retained  retainSet         // the values the machine must not write into (see Retain)
+	observers []ExecObserver
+	crash     *crashDumper // non-nil when dumping the failed runs
 }
 
 // NewTapeMachine creates a VM that compiles a graph into a prog.
@@ -116,10 +123,16 @@ func (m *tapeMachine) Reset() {
 		returnValue(m.gpumem[i])
 		m.gpumem[i] = nil //
 	}
+	if m.spill != nil {
+		m.spill.reset()
+	}
 }
 
 func (m *tapeMachine) Close() error {
 	finalizeTapeMachine(m)
+	if m.spill != nil {
+		m.spill.reset()
+	}
 	return nil
 }
 
@@ -135,7 +148,10 @@ func (m *tapeMachine) Let(n *Node, be interface{}) (err error) {
 		return errors.Errorf("Node %v does not exist in this graph", n)
 	}
 
-	return Let(n, be)
+	if err = Let(n, be); err != nil {
+		return err
+	}
+	return m.MarkDirty(n)
 }
 
 // Set wraps the Set() function of this package, with additional checks that both a and b are in the machine
@@ -147,6 +163,9 @@ func (m *tapeMachine) Set(a, b *Node) (err error) {
 		return errors.Errorf("Node %v does not exist in this graph", b)
 	}
 
+	if err = m.MarkDirty(a); err != nil {
+		return err
+	}
 	if b.Value() != nil {
 		return a.bind(b.Value())
 	}
@@ -205,6 +224,9 @@ func (m *tapeMachine) RunAll() (err error) {
 	errChan := make(chan error)
 	doneChan := make(chan struct{})
 
+	if m.incr != nil && m.pc == 0 {
+		m.planIncremental()
+	}
 	go m.runall(errChan, doneChan)
 	for {
 		select {
@@ -217,12 +239,18 @@ func (m *tapeMachine) RunAll() (err error) {
 				syncChan <- struct{}{}
 			}
 		case err := <-errChan:
-			return errors.Wrapf(err, "PC: %d", m.pc)
+			logging.Log(logging.VM, logging.Error, "tape machine failed", "pc", m.pc, "err", err)
+			err = errors.Wrapf(err, "PC: %d", m.pc)
+			if m.crash != nil {
+				err = m.dumpCrash(err)
+			}
+			return err
 		case <-doneChan:
 			err := m.ExternMetadata.DoWork()
 			if err != nil {
 				return err
 			}
+			logging.Log(logging.VM, logging.Debug, "ran the tape machine", "instructions", len(m.p.instructions))
 			return nil
 		}
 	}
@@ -232,16 +260,67 @@ func (m *tapeMachine) runall(errChan chan error, doneChan chan struct{}) {
 	for ; m.pc < len(m.p.instructions); m.pc++ {
 		instr := m.p.instructions[m.pc]
 		m.logf("PC %d", m.pc)
+		if m.spill != nil {
+			if err := m.spill.beforeExec(m, instr); err != nil {
+				errChan <- errors.Wrapf(err, "PC %d", m.pc)
+				return
+			}
+		}
+		if m.canRestore(instr) {
+			if err := m.restore(instr.(*execOp)); err != nil {
+				errChan <- errors.Wrapf(err, "PC %d", m.pc)
+				return
+			}
+			continue
+		}
+		var ev *ExecEvent
+		if len(m.observers) > 0 {
+			if ev = m.execEvent(instr); ev != nil {
+				if err := beforeExec(m.observers, ev); err != nil {
+					errChan <- errors.Wrapf(err, "PC %d", m.pc)
+					return
+				}
+			}
+		}
 		if err := instr.exec(m); err != nil {
 			err = errors.Wrapf(err, "PC %d. Failed to execute instruction %v", m.pc, instr)
+			if op, ok := instr.(*execOp); ok && op.id > 0 {
+				n, _ := m.p.g.Node(op.id).(*Node)
+				err = withStack(err, n)
+			}
 			errChan <- err
 			return
 		}
+		if ev != nil {
+			ev.Output = m.getValue(instr.writes())
+			if err := afterExec(m.observers, ev); err != nil {
+				errChan <- errors.Wrapf(err, "PC %d", m.pc)
+				return
+			}
+		}
+		if m.incr != nil {
+			if err := m.remember(instr); err != nil {
+				errChan <- errors.Wrapf(err, "PC %d", m.pc)
+				return
+			}
+		}
 		// only proceed to check NaNs and Infs for execOp
 		if _, ok := instr.(*execOp); !ok {
 			continue
 		}
 
+		if detectAnomaly() {
+			if writeTo, id := instr.writes().id, instr.ID(); writeTo > 0 && id > 0 {
+				if v := m.getValue(instr.writes()); v != nil {
+					n := m.p.g.Node(id).(*Node)
+					if err := checkAnomaly(n, n.backwardOf, v, CPU); err != nil {
+						errChan <- err
+						return
+					}
+				}
+			}
+		}
+
 		if m.watchNaN() {
 			writeTo := instr.writes().id
 			id := instr.ID()
@@ -282,6 +361,15 @@ func (m *tapeMachine) runall(errChan chan error, doneChan chan struct{}) {
 			}
 		}
 	}
+	if m.incr != nil {
+		m.doneIncremental()
+	}
+	if m.spill != nil {
+		if err := m.spill.afterRun(m); err != nil {
+			errChan <- err
+			return
+		}
+	}
 	doneChan <- struct{}{}
 }
 
@@ -507,7 +595,7 @@ func (instr alloc) exec(m *tapeMachine) (err error) {
 	}
 
 	reg := m.getValue(instr.writeTo)
-	if reg != nil && reg.Dtype() == dt && reg.Shape().Eq(instr.s) {
+	if reg != nil && reg.Dtype() == dt && reg.Shape().Eq(instr.s) && !m.retained.has(reg) {
 		return nil
 	}
 
@@ -529,6 +617,7 @@ func (instr alloc) exec(m *tapeMachine) (err error) {
 	if err != nil {
 		return
 	}
+	runtimeMetrics.alloc(calcMemSize(dt, instr.s))
 	setEngine(v, m.getEngine(dev))
 	if vt, ok := v.(tensor.Tensor); ok {
 		m.watchedLogf("%x | %T", v.Uintptr(), vt.Engine())
@@ -559,6 +648,10 @@ func (instr free) exec(m *tapeMachine) error {
 	default:
 		m.logf("instr.read from not CPU - %v %v %d", instr.readsFrom, instr.readsFrom.device == CPU, instr.readsFrom.device)
 		mem := m.gpumem[instr.readsFrom.id]
+		if mem == nil {
+			// spilled to the host
+			return nil
+		}
 		size := int64(mem.MemSize())
 
 		m.Put(instr.readsFrom.device, mem, size)
@@ -626,13 +719,16 @@ func (instr *execOp) reads() []register { return instr.readFrom }
 func (instr *execOp) writes() register  { return instr.writeTo }
 
 func newExecOp(n *Node) *execOp {
-	_, useGPU := n.op.(CUDADoer)
+	useGPU := n.dataOn != CPU
 	compileLogf("op %v uses GPU %v", n.op, useGPU)
-	dt, err := dtypeOf(n.t)
-	if err != nil {
-		panic(err)
+	var size int64
+	if !isTuple(n) {
+		dt, err := dtypeOf(n.t)
+		if err != nil {
+			panic(err)
+		}
+		size = calcMemSize(dt, n.Shape())
 	}
-	size := calcMemSize(dt, n.Shape())
 
 	return &execOp{
 		op:     n.op,
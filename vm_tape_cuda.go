@@ -18,11 +18,9 @@ func (m *tapeMachine) init() {
 	var initCUDA bool
 	cudaLogf("instructions %v", len(m.p.instructions))
 	for _, instr := range m.p.instructions {
-		if eo, ok := instr.(*execOp); ok {
-			if _, ok := eo.op.(CUDADoer); ok {
-				initCUDA = true
-				break
-			}
+		if eo, ok := instr.(*execOp); ok && eo.writeTo.device != CPU {
+			initCUDA = true
+			break
 		}
 	}
 
@@ -67,6 +65,7 @@ func (instr *execOp) exec(m *tapeMachine) (err error) {
 	m.logf("Executing %v. Node is: %x", instr, instr.id)
 	m.enterLogScope()
 	defer m.leaveLogScope()
+	defer runtimeMetrics.exec(instr.op, runtimeMetrics.start())
 
 	enterLogScope()
 	defer leaveLogScope()
@@ -81,19 +80,23 @@ func (instr *execOp) exec(m *tapeMachine) (err error) {
 	}
 	m.leaveLogScope()
 
+	node := m.p.g.Node(instr.id).(*Node)
 	toDev := instr.writeTo.device
 	var v Value
-	switch op := instr.op.(type) {
-	case CUDADoer:
+	switch op, ok := instr.op.(CUDADoer); {
+	case ok && toDev != CPU:
 		prealloc := m.getValue(instr.writeTo)
 		if v, err = op.CUDADo(m, toDev, prealloc, inputs...); err != nil {
 			return errors.Wrapf(err, "Happened while attempting to use CUDA to execute %v. Node is %x. Register was %v", instr, instr.id, instr.writeTo.id)
 		}
 		e := &m.Engines()[int(toDev)]
 		setEngine(v, e)
-	case CLDoer:
 	default:
 		switch {
+		case node.reuse != nil && !m.retained.has(node.reuse):
+			if v, err = UnsafeDoInto(instr.op, node.reuse, inputs...); err != nil {
+				return errors.Wrapf(err, "Happened while attempting to execute %v into the reused value. Node is %x", instr, instr.id)
+			}
 		case instr.preAllocated:
 			if pd, ok := instr.op.(UsePreallocDoer); ok {
 				p := m.cpumem[instr.writeTo.id]
@@ -106,7 +109,7 @@ func (instr *execOp) exec(m *tapeMachine) (err error) {
 					return errors.Wrap(err, opDoFail)
 				}
 			}
-		case instr.useUnsafe:
+		case instr.useUnsafe && !m.retained.any(inputs):
 			if ud, ok := instr.op.(UnsafeDoer); ok {
 				if v, err = ud.UnsafeDo(inputs...); err != nil {
 					return errors.Wrap(err, "Failed to carry UnsafeDo()")
@@ -137,7 +140,6 @@ func (instr *execOp) exec(m *tapeMachine) (err error) {
 
 	// Write
 	m.writeValue(instr.writeTo, v)
-	node := m.p.g.Node(instr.id).(*Node)
 
 	if m.trace() && (len(m.watchNodes) == 0 || m.watchNodes.Contains(node)) {
 		m.Signal()
@@ -0,0 +1,185 @@
+package gorgonia
+
+import (
+	"github.com/pkg/errors"
+	"gorgonia.org/tensor"
+)
+
+// incrementalState is the state of a *tapeMachine that executes incrementally: it keeps a copy of the results of the
+// ops, and only executes the ops whose inputs have changed since the last run.
+type incrementalState struct {
+	dirty map[*Node]struct{} // nodes marked dirty since the last complete run
+	stale map[int64]struct{} // the nodes to recompute in the current run
+	cache map[int64]Value    // copies of the results of the ops
+}
+
+func newIncrementalState() *incrementalState {
+	return &incrementalState{
+		dirty: make(map[*Node]struct{}),
+		stale: make(map[int64]struct{}),
+		cache: make(map[int64]Value),
+	}
+}
+
+// WithIncrementalExec is an option for *tapeMachine only. The machine keeps a copy of the result of every op, and on
+// subsequent runs only recomputes the nodes downstream of the nodes marked dirty (see MarkDirty); the results of the
+// other nodes are restored from their copies. This benefits interactive workloads, where only some of the inputs change
+// between runs, at the cost of the memory for a copy of every intermediate value, on the CPU.
+//
+// Let and Set of the machine mark the node dirty. A node bound with the package-level Let has to be marked dirty with
+// MarkDirty, as does an input whose value is modified in place. Ops that implement StatefulOp, such as random ops, are
+// always recomputed.
+func WithIncrementalExec() VMOpt {
+	f := func(m VM) {
+		switch v := m.(type) {
+		case *tapeMachine:
+			v.incr = newIncrementalState()
+		default:
+			panic(nyi("WithIncrementalExec", v))
+		}
+	}
+	return f
+}
+
+// MarkDirty marks nodes as changed, so that the next run of an incremental machine (see WithIncrementalExec)
+// recomputes the nodes that depend on them. It does nothing if the machine does not execute incrementally.
+func (m *tapeMachine) MarkDirty(nodes ...*Node) error {
+	for _, n := range nodes {
+		if !m.p.g.Has(n.ID()) {
+			return errors.Errorf("Node %v does not exist in this graph", n)
+		}
+	}
+	if m.incr == nil {
+		return nil
+	}
+	for _, n := range nodes {
+		m.incr.dirty[n] = struct{}{}
+	}
+	return nil
+}
+
+// InvalidateCache drops the copies of the results kept by an incremental machine, so that the next run recomputes
+// every node.
+func (m *tapeMachine) InvalidateCache() {
+	if m.incr == nil {
+		return
+	}
+	for id, v := range m.incr.cache {
+		returnValue(v)
+		delete(m.incr.cache, id)
+	}
+}
+
+// planIncremental finds the nodes to recompute in the upcoming run: the nodes marked dirty, the nodes that are always
+// recomputed, and every node that depends on them.
+func (m *tapeMachine) planIncremental() {
+	s := m.incr
+	for id := range s.stale {
+		delete(s.stale, id)
+	}
+	for _, n := range m.p.sorted {
+		if m.mustRecompute(n) {
+			s.stale[n.ID()] = struct{}{}
+			continue
+		}
+		for _, child := range n.children {
+			if _, ok := s.stale[child.ID()]; ok {
+				s.stale[n.ID()] = struct{}{}
+				break
+			}
+		}
+	}
+}
+
+func (m *tapeMachine) mustRecompute(n *Node) bool {
+	if _, ok := m.incr.dirty[n]; ok {
+		return true
+	}
+	if op, ok := n.op.(StatefulOp); ok && op.IsStateful() {
+		return true
+	}
+	// gradients are accumulated into the dual values on every run
+	return m.bindDV() && n.derivOf != nil
+}
+
+// canRestore returns true if the result of the instruction may be restored from its copy rather than recomputed.
+func (m *tapeMachine) canRestore(instr tapeInstr) bool {
+	if m.incr == nil {
+		return false
+	}
+	op, ok := instr.(*execOp)
+	if !ok || op.writeTo.device != CPU {
+		return false
+	}
+	if _, ok := m.incr.stale[op.id]; ok {
+		return false
+	}
+	_, ok = m.incr.cache[op.id]
+	return ok
+}
+
+// restore writes a copy of the cached result of the instruction into its register, as executing it would.
+func (m *tapeMachine) restore(instr *execOp) (err error) {
+	m.logf("Restoring %v. Node is: %x", instr, instr.id)
+	cached := m.incr.cache[instr.id]
+	node := m.p.g.Node(instr.id).(*Node)
+
+	dst := m.cpumem[instr.writeTo.id]
+	if node.reuse != nil {
+		dst = node.reuse
+	} else if _, ok := instr.op.(UsePreallocDoer); !ok {
+		dst = nil
+	}
+	var v Value
+	if dst != nil && sameValueType(dst, cached) && !m.retained.has(dst) {
+		v, err = Copy(dst, cached)
+	} else {
+		v, err = CloneValue(cached)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "Failed to restore the result of %v", node)
+	}
+	setEngine(v, m.Engine)
+	m.cpumem[instr.writeTo.id] = v
+
+	if m.trace() && (len(m.watchNodes) == 0 || m.watchNodes.Contains(node)) {
+		return node.bindCopy(v)
+	}
+	return node.bind(v)
+}
+
+// remember keeps a copy of the result of an executed instruction.
+func (m *tapeMachine) remember(instr tapeInstr) (err error) {
+	op, ok := instr.(*execOp)
+	if !ok || op.writeTo.device != CPU {
+		return nil
+	}
+	if o, ok := op.op.(StatefulOp); ok && o.IsStateful() {
+		return nil
+	}
+	v := m.cpumem[op.writeTo.id]
+	if cached, ok := m.incr.cache[op.id]; ok && sameValueType(cached, v) {
+		_, err = Copy(cached, v)
+		return err
+	}
+	var cached Value
+	if cached, err = CloneValue(v); err != nil {
+		return errors.Wrapf(err, cloneFail, v)
+	}
+	m.incr.cache[op.id] = cached
+	return nil
+}
+
+// doneIncremental clears the dirty marks after a complete run.
+func (m *tapeMachine) doneIncremental() {
+	for n := range m.incr.dirty {
+		delete(m.incr.dirty, n)
+	}
+}
+
+// sameValueType returns true if a may be copied into b.
+func sameValueType(a, b Value) bool {
+	_, aT := a.(tensor.Tensor)
+	_, bT := b.(tensor.Tensor)
+	return aT == bT && a.Dtype() == b.Dtype() && a.Shape().Eq(b.Shape())
+}
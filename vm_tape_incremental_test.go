@@ -0,0 +1,100 @@
+package gorgonia
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorgonia.org/tensor"
+)
+
+func TestIncrementalExec(t *testing.T) {
+	assert := assert.New(t)
+	g := NewGraph()
+	x := NewVector(g, Float64, WithShape(3), WithName("x"))
+	y := NewVector(g, Float64, WithShape(3), WithName("y"))
+	a := Must(Mul(x, NewConstant(2.0)))
+	b := Must(Square(y))
+	c := Must(Add(a, b))
+
+	yT := tensor.New(tensor.WithBacking([]float64{1, 2, 3}))
+	Let(x, tensor.New(tensor.WithBacking([]float64{1, 1, 1})))
+	Let(y, yT)
+
+	m := NewTapeMachine(g, WithIncrementalExec())
+	defer m.Close()
+	run := func() []float64 {
+		if err := m.RunAll(); err != nil {
+			t.Fatal(err)
+		}
+		m.Reset()
+		return append([]float64(nil), c.Value().Data().([]float64)...)
+	}
+	assert.Equal([]float64{3, 6, 11}, run())
+
+	// y is modified in place without being marked dirty: the cached squares are reused
+	copy(yT.Data().([]float64), []float64{2, 2, 2})
+	assert.Equal([]float64{3, 6, 11}, run())
+	assert.Equal([]float64{1, 4, 9}, b.Value().Data())
+
+	if err := m.MarkDirty(y); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]float64{6, 6, 6}, run())
+
+	// Let of the machine marks the node dirty
+	if err := m.Let(x, tensor.New(tensor.WithBacking([]float64{0, 1, 2}))); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]float64{4, 6, 8}, run())
+	assert.Equal([]float64{4, 6, 8}, run(), "nothing is dirty")
+
+	copy(yT.Data().([]float64), []float64{0, 0, 0})
+	m.InvalidateCache()
+	assert.Equal([]float64{0, 2, 4}, run())
+}
+
+func TestIncrementalExec_stateful(t *testing.T) {
+	assert := assert.New(t)
+	g := NewGraph()
+	x := NewVector(g, Float64, WithShape(4), WithName("x"))
+	r := GaussianRandomNode(g, Float64, 0, 1, 4)
+	y := Must(Add(x, r))
+	Let(x, tensor.New(tensor.WithBacking([]float64{1, 2, 3, 4})))
+
+	m := NewTapeMachine(g, WithIncrementalExec())
+	defer m.Close()
+	var prev []float64
+	for i := 0; i < 3; i++ {
+		if err := m.RunAll(); err != nil {
+			t.Fatal(err)
+		}
+		got := append([]float64(nil), y.Value().Data().([]float64)...)
+		assert.NotEqual(prev, got, "random nodes are always recomputed")
+		prev = got
+		m.Reset()
+	}
+}
+
+func TestIncrementalExec_off(t *testing.T) {
+	assert := assert.New(t)
+	g := NewGraph()
+	x := NewVector(g, Float64, WithShape(2), WithName("x"))
+	y := Must(Square(x))
+	xT := tensor.New(tensor.WithBacking([]float64{1, 2}))
+	Let(x, xT)
+
+	m := NewTapeMachine(g)
+	defer m.Close()
+	if err := m.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+	m.Reset()
+
+	// without incremental execution, every run recomputes every node
+	copy(xT.Data().([]float64), []float64{3, 4})
+	assert.NoError(m.MarkDirty(x))
+	if err := m.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]float64{9, 16}, y.Value().Data())
+}
@@ -20,6 +20,7 @@ func (instr *execOp) exec(m *tapeMachine) (err error) {
 	m.logf("Executing %v. Node is: %x", instr, instr.id)
 	m.enterLogScope()
 	defer m.leaveLogScope()
+	defer runtimeMetrics.exec(instr.op, runtimeMetrics.start())
 
 	// Read
 	m.watchedLogf("Inputs:")
@@ -35,13 +36,19 @@ func (instr *execOp) exec(m *tapeMachine) (err error) {
 	// check if the destination has already been allocated
 	var usePrealloc bool
 	dest := instr.writeTo.id
-	if m.cpumem[dest] != nil {
+	if m.cpumem[dest] != nil && !m.retained.has(m.cpumem[dest]) {
 		usePrealloc = true
 	}
 
+	node := m.p.g.Node(instr.id).(*Node)
+
 	// Execute
 	var v Value
 	switch {
+	case node.reuse != nil && !m.retained.has(node.reuse):
+		if v, err = UnsafeDoInto(instr.op, node.reuse, inputs...); err != nil {
+			return errors.Wrapf(err, "Happened while attempting to execute %v into the reused value. Node is %x", instr, instr.id)
+		}
 	case instr.preAllocated:
 		if pd, ok := instr.op.(UsePreallocDoer); ok {
 			p := m.cpumem[instr.writeTo.id]
@@ -67,7 +74,7 @@ func (instr *execOp) exec(m *tapeMachine) (err error) {
 				return errors.Wrap(err, opDoFail)
 			}
 		}
-	case instr.useUnsafe:
+	case instr.useUnsafe && !m.retained.any(inputs):
 		if ud, ok := instr.op.(UnsafeDoer); ok {
 			if v, err = ud.UnsafeDo(inputs...); err != nil {
 				return errors.Wrap(err, "Failed to carry UnsafeDo()")
@@ -94,7 +101,6 @@ func (instr *execOp) exec(m *tapeMachine) (err error) {
 	setEngine(v, m.Engine)
 
 	m.cpumem[dest] = v
-	node := m.p.g.Node(instr.id).(*Node)
 
 	if m.trace() && (len(m.watchNodes) == 0 || m.watchNodes.Contains(node)) {
 		if err = node.bindCopy(v); err != nil {
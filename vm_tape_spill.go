@@ -0,0 +1,335 @@
+package gorgonia
+
+import (
+	"io/ioutil"
+	"os"
+
+	"github.com/pkg/errors"
+	"gorgonia.org/gorgonia/internal/logging"
+	"gorgonia.org/tensor"
+)
+
+// minSpillSize is the size of the smallest value that is spilled. Smaller values are not worth the cost of a file.
+const minSpillSize = 64 << 10
+
+// spillState is the state of a *tapeMachine that keeps the values in its registers under a memory budget: when the
+// registers hold more than the budget, the values of the least recently used ones are spilled, to files for the CPU
+// registers and to the host memory for the device ones, and loaded back when an instruction reads them.
+type spillState struct {
+	budget       int64  // for the CPU registers. 0 means no budget
+	deviceBudget int64  // for the registers of every device. 0 means no budget
+	dir          string // where the files are created
+
+	lastUse map[register]int      // the PC of the last instruction that used a register
+	spilled map[register]*spilled // the registers whose values are spilled
+	nodes   map[register]Nodes    // the nodes written to each register
+
+	spills, restores int
+}
+
+// spilled is a spilled value.
+type spilled struct {
+	file  string // the file a CPU value is spilled to
+	host  Value  // the host copy of a device value
+	nodes Nodes  // the nodes that were bound to the value
+	size  int64
+}
+
+// WithSpilling is an option for *tapeMachine only. The machine keeps the values of its CPU registers under budget bytes
+// by spilling the least recently used large intermediate values to temporary files in dir, and reading them back when
+// they are needed. If dir is empty, the default directory for temporary files is used. A graph whose intermediate values
+// do not fit in memory then runs slowly instead of running out of memory.
+//
+// When the machine has run, the values of the roots of the graph and of the gradients are loaded back. The other
+// intermediate nodes whose values are spilled are left unbound.
+func WithSpilling(budget int64, dir string) VMOpt {
+	f := func(m VM) {
+		switch v := m.(type) {
+		case *tapeMachine:
+			v.spillState().budget = budget
+			v.spillState().dir = dir
+		default:
+			panic(nyi("WithSpilling", v))
+		}
+	}
+	return f
+}
+
+// WithDeviceSpilling is an option for *tapeMachine only. The machine keeps the values of the registers of every device
+// under budget bytes, by moving the least recently used large values to the host memory until they are needed. It
+// does nothing unless the program is built with the cuda tag.
+func WithDeviceSpilling(budget int64) VMOpt {
+	f := func(m VM) {
+		switch v := m.(type) {
+		case *tapeMachine:
+			v.spillState().deviceBudget = budget
+		default:
+			panic(nyi("WithDeviceSpilling", v))
+		}
+	}
+	return f
+}
+
+func (m *tapeMachine) spillState() *spillState {
+	if m.spill == nil {
+		m.spill = &spillState{
+			lastUse: make(map[register]int),
+			spilled: make(map[register]*spilled),
+		}
+	}
+	return m.spill
+}
+
+// beforeExec loads back the spilled values that instr reads, and spills the values of other registers until the
+// registers fit in the budgets.
+func (s *spillState) beforeExec(m *tapeMachine, instr tapeInstr) error {
+	if s.nodes == nil {
+		s.nodes = make(map[register]Nodes)
+		for n, r := range m.locMap {
+			s.nodes[r] = append(s.nodes[r], n)
+		}
+	}
+
+	reads := instr.reads()
+	write := instr.writes()
+	if _, ok := instr.(free); ok {
+		// there's no need to load a value back to free it. The nodes may still be bound to it: see afterRun
+		return nil
+	}
+	for _, r := range reads {
+		if err := s.restore(m, r); err != nil {
+			return err
+		}
+		s.lastUse[r] = m.pc
+	}
+	if write.id >= 0 {
+		// the register is written to, so the value spilled from it is dead
+		s.discard(write)
+		s.lastUse[write] = m.pc
+	}
+
+	var size int64
+	if eo, ok := instr.(*execOp); ok {
+		size = eo.size
+	}
+	if s.budget > 0 {
+		if err := s.fit(m, CPU, s.budget-size, reads, write); err != nil {
+			return err
+		}
+	}
+	if s.deviceBudget > 0 {
+		devices := make(map[Device]struct{})
+		for r := range s.lastUse {
+			if r.device != CPU {
+				devices[r.device] = struct{}{}
+			}
+		}
+		for dev := range devices {
+			budget := s.deviceBudget
+			if write.device == dev {
+				budget -= size
+			}
+			if err := s.fit(m, dev, budget, reads, write); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// fit spills the least recently used values of the registers of dev until they hold at most budget bytes. The registers
+// used by the current instruction are kept. If the other values cannot be spilled, the budget is exceeded.
+func (s *spillState) fit(m *tapeMachine, dev Device, budget int64, reads []register, write register) error {
+	mem := m.cpumem
+	if dev != CPU {
+		mem = m.gpumem
+	}
+	var held int64
+	for r := range s.lastUse {
+		if r.device != dev {
+			continue
+		}
+		if v := mem[r.id]; v != nil {
+			held += int64(v.MemSize())
+		}
+	}
+
+	for held > budget {
+		victim := register{-1, dev}
+		for r, pc := range s.lastUse {
+			if r.device != dev || r == write || registers(reads).contains(r) {
+				continue
+			}
+			v := mem[r.id]
+			if v == nil || v.MemSize() < minSpillSize {
+				continue
+			}
+			if victim.id < 0 || pc < s.lastUse[victim] {
+				victim = r
+			}
+		}
+		if victim.id < 0 {
+			logging.Log(logging.VM, logging.Warn, "the memory budget is exceeded", "device", dev, "held", held, "budget", budget)
+			return nil
+		}
+		size := int64(mem[victim.id].MemSize())
+		if err := s.spillReg(m, victim); err != nil {
+			return err
+		}
+		held -= size
+	}
+	return nil
+}
+
+// spillReg spills the value of a register, and unbinds the nodes bound to it so that the memory can be released. The
+// values of the inputs, which stay bound to them, and the values that are not dense tensors or are views are not spilled,
+// and no longer counted until they are used again.
+func (s *spillState) spillReg(m *tapeMachine, r register) (err error) {
+	v := m.getValue(r)
+	var bound Nodes
+	spillable := true
+	for _, n := range s.nodes[r] {
+		if n.boundTo == v {
+			bound = append(bound, n)
+		} else if dv, ok := n.boundTo.(*dualValue); ok && dv.Value == v {
+			bound = append(bound, n)
+		} else {
+			continue
+		}
+		if n.isInput() {
+			spillable = false
+		}
+	}
+	if t, ok := v.(*tensor.Dense); r.device == CPU && (!ok || t.IsView()) {
+		spillable = false // a view shares its memory, which spilling would not release
+	}
+	if !spillable {
+		delete(s.lastUse, r)
+		return nil
+	}
+
+	sp := &spilled{size: int64(v.MemSize()), nodes: bound}
+	switch r.device {
+	case CPU:
+		t := v.(*tensor.Dense)
+		var f *os.File
+		if f, err = ioutil.TempFile(s.dir, "gorgonia-spill-"); err != nil {
+			return errors.Wrap(err, "Failed to create a file to spill to")
+		}
+		sp.file = f.Name()
+		err = t.WriteNpy(f)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			os.Remove(sp.file)
+			return errors.Wrapf(err, "Failed to spill %v", r)
+		}
+	default:
+		if sp.host, err = m.Transfer(CPU, r.device, v, true); err != nil {
+			return errors.Wrapf(err, "Failed to spill %v to the host", r)
+		}
+		m.Put(r.device, v, sp.size)
+	}
+
+	for _, n := range bound {
+		if dv, ok := n.boundTo.(*dualValue); ok {
+			dv.Value = nil
+		} else {
+			n.boundTo = nil
+		}
+	}
+	m.writeValue(r, nil)
+	s.spilled[r] = sp
+	s.spills++
+	logging.Log(logging.VM, logging.Debug, "spilled", "register", r, "bytes", sp.size, "file", sp.file)
+	return nil
+}
+
+// restore loads back the spilled value of a register, if any, and binds it to the nodes it was bound to.
+func (s *spillState) restore(m *tapeMachine, r register) (err error) {
+	sp, ok := s.spilled[r]
+	if !ok {
+		return nil
+	}
+	var v Value
+	switch r.device {
+	case CPU:
+		var f *os.File
+		if f, err = os.Open(sp.file); err != nil {
+			return errors.Wrapf(err, "Failed to load %v back", r)
+		}
+		t := new(tensor.Dense)
+		err = t.ReadNpy(f)
+		f.Close()
+		if err != nil {
+			return errors.Wrapf(err, "Failed to load %v back", r)
+		}
+		setEngine(t, m.Engine)
+		v = t
+	default:
+		if v, err = m.Transfer(r.device, CPU, sp.host, false); err != nil {
+			return errors.Wrapf(err, "Failed to load %v back to %v", r, r.device)
+		}
+		setEngine(v, m.getEngine(r.device))
+	}
+
+	m.writeValue(r, v)
+	for _, n := range sp.nodes {
+		if err = n.bind(v); err != nil {
+			return err
+		}
+	}
+	s.discard(r)
+	s.restores++
+	logging.Log(logging.VM, logging.Debug, "restored", "register", r, "bytes", sp.size)
+	return nil
+}
+
+// discard forgets the spilled value of a register.
+func (s *spillState) discard(r register) {
+	if sp, ok := s.spilled[r]; ok {
+		if sp.file != "" {
+			os.Remove(sp.file)
+		}
+		delete(s.spilled, r)
+	}
+}
+
+// afterRun loads back the spilled values of the roots of the graph and of the gradients.
+func (s *spillState) afterRun(m *tapeMachine) error {
+	if len(s.spilled) == 0 {
+		return nil
+	}
+	roots := m.p.g.Roots()
+	for r, sp := range s.spilled {
+		for _, n := range sp.nodes {
+			if n.derivOf != nil || roots.Contains(n) {
+				if err := s.restore(m, r); err != nil {
+					return err
+				}
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// reset discards all the spilled values.
+func (s *spillState) reset() {
+	for r := range s.spilled {
+		s.discard(r)
+	}
+	s.lastUse = make(map[register]int)
+}
+
+type registers []register
+
+func (rs registers) contains(r register) bool {
+	for _, a := range rs {
+		if a == r {
+			return true
+		}
+	}
+	return false
+}
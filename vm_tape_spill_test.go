@@ -0,0 +1,89 @@
+package gorgonia
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorgonia.org/tensor"
+)
+
+// spillGraph is a graph with large intermediate values that are used long after they are computed.
+func spillGraph() (g *ExprGraph, x, cost *Node) {
+	g = NewGraph()
+	x = NewMatrix(g, Float64, WithShape(128, 128), WithName("x"), WithInit(RangedFrom(0)))
+	scaled := Must(Mul(x, NewConstant(1e-4)))
+	a := Must(Tanh(scaled))
+	b := Must(Exp(scaled))
+	c := Must(Sigmoid(scaled))
+	d := Must(Add(Must(Square(b)), c))
+	e := Must(HadamardProd(d, a))
+	cost = Must(Sum(Must(Add(e, b))))
+	return
+}
+
+func TestWithSpilling(t *testing.T) {
+	assert := assert.New(t)
+	dir, err := ioutil.TempDir("", "spill")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	run := func(opts ...VMOpt) (float64, []float64, *tapeMachine) {
+		g, x, cost := spillGraph()
+		grads, err := Grad(cost, x)
+		if err != nil {
+			t.Fatal(err)
+		}
+		m := NewTapeMachine(g, opts...)
+		if err = m.RunAll(); err != nil {
+			t.Fatalf("%+v", err)
+		}
+		return cost.Value().Data().(float64), append([]float64(nil), grads[0].Value().Data().([]float64)...), m
+	}
+
+	wantCost, wantGrad, m := run()
+	m.Close()
+
+	gotCost, gotGrad, m := run(WithSpilling(3*128*128*8, dir))
+	assert.InDelta(wantCost, gotCost, 1e-9)
+	assert.InDeltaSlice(wantGrad, gotGrad, 1e-12, "the roots are loaded back")
+	assert.True(m.spill.spills > 0, "nothing was spilled")
+	assert.True(m.spill.restores > 0, "nothing was loaded back")
+
+	// the values are spilled again on every run
+	spills := m.spill.spills
+	m.Reset()
+	if err = m.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+	assert.True(m.spill.spills > spills)
+	m.Close()
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Empty(files, "the files are removed when the machine is closed")
+}
+
+func TestWithSpilling_inputs(t *testing.T) {
+	g := NewGraph()
+	x := NewMatrix(g, Float64, WithShape(128, 128), WithName("x"), WithValue(tensor.New(tensor.WithShape(128, 128), tensor.Of(Float64))))
+	y := Must(Tanh(x))
+	Must(Sum(Must(Add(y, x))))
+
+	m := NewTapeMachine(g, WithSpilling(1, ""))
+	defer m.Close()
+	for i := 0; i < 2; i++ {
+		if err := m.RunAll(); err != nil {
+			t.Fatalf("%+v", err)
+		}
+		m.Reset()
+	}
+	if x.Value() == nil {
+		t.Error("The inputs are never spilled")
+	}
+}
@@ -38,20 +38,107 @@ func walkGraph(start *Node, ch chan *Node, walked NodeSet) {
 }
 
 // Sort topologically sorts a ExprGraph: root of graph will be first
-// nodes are sorted using gonum's SortStabilized function.
+// nodes are sorted in the order of gonum's SortStabilized function, with the reverse lexical order of the IDs.
 //
 // see https://godoc.org/gonum.org/v1/gonum/graph/topo#SortStabilized for more info
+//
+// The order is cached in the graph until the graph is next changed, so that the graphs of hundreds of thousands of nodes
+// are not sorted anew every time they are compiled or differentiated. The returned slice is the caller's.
 func Sort(g *ExprGraph) (sorted Nodes, err error) {
-	var sortedNodes []graph.Node
-	// if sortedNodes, err = topo.Sort(g); err != nil {
-	if sortedNodes, err = topo.SortStabilized(g, reverseLexical); err != nil {
-		return nil, errors.Wrap(err, sortFail)
+	g.lock()
+	defer g.unlock()
+	if g.sorted == nil {
+		if g.sorted, err = sortStabilized(g); err != nil {
+			return nil, err
+		}
 	}
-
-	sorted = graphNodeToNode(iterator.NewOrderedNodes(sortedNodes))
+	sorted = make(Nodes, len(g.sorted))
+	copy(sorted, g.sorted)
 	return
 }
 
+// sortStabilized is an iterative depth first search with preallocated buffers, which yields the same order as
+// topo.SortStabilized(g, reverseLexical) does for acyclic graphs: the nodes are the reverse of the post-order of a
+// search that starts from the nodes, and visits the children, in the increasing order of their IDs. The cycles, and the
+// nodes that are not of the graph, are left to topo.SortStabilized.
+func sortStabilized(g *ExprGraph) (sorted Nodes, err error) {
+	const (
+		unvisited byte = iota
+		visiting
+		visited
+	)
+	gonum := func() (Nodes, error) {
+		var sortedNodes []graph.Node
+		if sortedNodes, err = topo.SortStabilized(g, reverseLexical); err != nil {
+			return nil, errors.Wrap(err, sortFail)
+		}
+		return graphNodeToNode(iterator.NewOrderedNodes(sortedNodes)), nil
+	}
+
+	nodes := g.AllNodes()
+	if !sort.SliceIsSorted(nodes, func(i, j int) bool { return nodes[i].id < nodes[j].id }) {
+		nodes = append(Nodes(nil), nodes...)
+		sort.Slice(nodes, func(i, j int) bool { return nodes[i].id < nodes[j].id })
+	}
+	state := make([]byte, g.counter)
+	inGraph := func(n *Node) bool { return n.g == g && n.id >= 0 && n.id < int64(len(state)) }
+
+	type frame struct {
+		n        *Node
+		children Nodes // sorted by ID
+		next     int
+	}
+	var (
+		stack    = make([]frame, 0, 64)
+		children = make(Nodes, 0, len(nodes)) // the buffer of the sorted children of the frames
+	)
+	sorted = make(Nodes, len(nodes))
+	i := len(nodes)
+	push := func(n *Node) {
+		state[n.id] = visiting
+		start := len(children)
+		children = append(children, n.children...)
+		cs := children[start:len(children):len(children)]
+		sort.Slice(cs, func(i, j int) bool { return cs[i].id < cs[j].id })
+		stack = append(stack, frame{n: n, children: cs})
+	}
+	for _, root := range nodes {
+		if !inGraph(root) {
+			return gonum()
+		}
+		if state[root.id] != unvisited {
+			continue
+		}
+		push(root)
+		for len(stack) > 0 {
+			top := &stack[len(stack)-1]
+			if top.next < len(top.children) {
+				child := top.children[top.next]
+				top.next++
+				if !inGraph(child) || state[child.id] == visiting {
+					return gonum()
+				}
+				if state[child.id] == unvisited {
+					push(child)
+				}
+				continue
+			}
+			state[top.n.id] = visited
+			children = children[:len(children)-len(top.children)]
+			i--
+			if i < 0 {
+				return gonum()
+			}
+			sorted[i] = top.n
+			stack = stack[:len(stack)-1]
+		}
+	}
+	if i != 0 {
+		return gonum()
+	}
+	return sorted, nil
+}
+
 // UnstableSort performs a topological sort of the directed graph g returning the 'from' to 'to'
 // sort order. If a topological ordering is not possible, an Unorderable error is returned
 // listing cyclic components in g with each cyclic component's members sorted by ID. When
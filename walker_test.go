@@ -0,0 +1,80 @@
+package gorgonia
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gonum.org/v1/gonum/graph"
+	"gonum.org/v1/gonum/graph/iterator"
+	"gonum.org/v1/gonum/graph/topo"
+)
+
+func TestSort(t *testing.T) {
+	assert := assert.New(t)
+	r := rand.New(rand.NewSource(1337))
+	g := NewGraph()
+	nodes := Nodes{
+		NewScalar(g, Float64, WithName("a")),
+		NewScalar(g, Float64, WithName("b")),
+		NewScalar(g, Float64, WithName("c")),
+	}
+	for i := 0; i < 200; i++ {
+		a, b := nodes[r.Intn(len(nodes))], nodes[r.Intn(len(nodes))]
+		var n *Node
+		switch r.Intn(3) {
+		case 0:
+			n = Must(Add(a, b))
+		case 1:
+			n = Must(Mul(a, b))
+		default:
+			n = Must(Exp(a))
+		}
+		nodes = append(nodes, n)
+	}
+
+	// the order is that of gonum's stabilized sort
+	var gonum []graph.Node
+	var err error
+	if gonum, err = topo.SortStabilized(g, reverseLexical); err != nil {
+		t.Fatal(err)
+	}
+	sorted, err := Sort(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(graphNodeToNode(iterator.NewOrderedNodes(gonum)), sorted)
+
+	// the result is the caller's, and the cache is reset when the graph changes
+	sorted[0] = nil
+	again, _ := Sort(g)
+	assert.NotNil(again[0])
+	last := Must(Neg(nodes[len(nodes)-1]))
+	again, _ = Sort(g)
+	assert.True(again[0] == last)
+	g.RemoveNode(last)
+	again, _ = Sort(g)
+	assert.False(again.Contains(last))
+
+	// the cycles are reported
+	x := nodes[3]
+	x.children = append(x.children, nodes[len(nodes)-1])
+	g.SetEdge(edge{from: x, to: nodes[len(nodes)-1]})
+	_, err = Sort(g)
+	assert.Error(err)
+}
+
+func BenchmarkSort(b *testing.B) {
+	g := NewGraph()
+	n := NewScalar(g, Float64, WithName("x"))
+	for i := 0; i < 20000; i++ {
+		n = Must(Add(n, Must(Exp(n))))
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		g.sorted = nil
+		if _, err := Sort(g); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,335 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"gorgonia.org/gorgonia"
+	"gorgonia.org/tensor"
+)
+
+// Event is the progress of one step of the training, as streamed to the browsers.
+type Event struct {
+	Step         int
+	Time         time.Time
+	Loss         float64
+	LearningRate float64
+
+	// GradNorm is the L2 norm of all the gradients together, and GradNorms the L2 norm of the gradient of every
+	// parameter, by name.
+	GradNorm  float64
+	GradNorms map[string]float64
+
+	// Ops is the number of executions of every type of op and the time spent executing them since the previous step.
+	// It is empty unless gorgonia.EnableMetrics(true) was called.
+	Ops map[string]gorgonia.KernelStats
+}
+
+// MarshalJSON encodes the event for the page of the dashboard. The losses and norms that are not finite, as when the
+// training diverges, are encoded as null.
+func (e Event) MarshalJSON() ([]byte, error) {
+	type op struct {
+		Count   uint64  `json:"count"`
+		Seconds float64 `json:"seconds"`
+	}
+	norms := make(map[string]interface{}, len(e.GradNorms))
+	for name, v := range e.GradNorms {
+		norms[name] = finite(v)
+	}
+	ops := make(map[string]op, len(e.Ops))
+	for name, k := range e.Ops {
+		ops[name] = op{Count: k.Count, Seconds: k.Time.Seconds()}
+	}
+	return json.Marshal(struct {
+		Step      int                    `json:"step"`
+		Time      time.Time              `json:"time"`
+		Loss      interface{}            `json:"loss"`
+		LR        interface{}            `json:"lr"`
+		GradNorm  interface{}            `json:"grad_norm"`
+		GradNorms map[string]interface{} `json:"grad_norms"`
+		Ops       map[string]op          `json:"ops"`
+	}{e.Step, e.Time, finite(e.Loss), finite(e.LearningRate), finite(e.GradNorm), norms, ops})
+}
+
+func finite(v float64) interface{} {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return nil
+	}
+	return v
+}
+
+// Server is the dashboard. It is an http.Handler serving the page of the dashboard at "/", the events recorded so far
+// as JSON at "/events", and the stream of the events at "/ws".
+//
+// The methods of a Server are safe for concurrent use.
+type Server struct {
+	mux        *http.ServeMux
+	maxHistory int
+	buffer     int
+
+	sync.Mutex
+	history [][]byte // the encoded events
+	clients map[*client]struct{}
+	kernels map[string]gorgonia.KernelStats // the kernel metrics at the previous step
+	closed  bool
+}
+
+// client is a browser following the stream. The events are queued, so that a slow browser does not slow the
+// training down. A browser that falls too far behind is disconnected.
+type client struct {
+	conn *wsConn
+	send chan []byte
+}
+
+// Opt is an option of New.
+type Opt func(*Server)
+
+// WithHistory sets the number of events kept for the browsers that connect later. The oldest events are dropped
+// first. By default, the last 10000 events are kept.
+func WithHistory(n int) Opt {
+	return func(s *Server) { s.maxHistory = n }
+}
+
+// WithBuffer sets the number of events queued for a browser before it is disconnected. The default is 1024.
+func WithBuffer(n int) Opt {
+	return func(s *Server) { s.buffer = n }
+}
+
+// New creates a dashboard.
+func New(opts ...Opt) *Server {
+	s := &Server{
+		mux:        http.NewServeMux(),
+		maxHistory: 10000,
+		buffer:     1024,
+		clients:    make(map[*client]struct{}),
+		kernels:    gorgonia.ReadMetrics().Kernels,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.mux.HandleFunc("/", s.servePage)
+	s.mux.HandleFunc("/events", s.serveEvents)
+	s.mux.HandleFunc("/ws", s.serveWS)
+	return s
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) { s.mux.ServeHTTP(w, r) }
+
+// Step records a step of the training: the loss, which must be a scalar, the learning rate, and the gradients of the
+// model. It should be called after the machine has run and before the solver steps, while the gradients are there.
+// The model may be nil to report no gradients.
+func (s *Server) Step(step int, loss gorgonia.Value, lr float64, model []gorgonia.ValueGrad) error {
+	l, err := scalar(loss)
+	if err != nil {
+		return errors.Wrap(err, "Cannot read the loss")
+	}
+	e := Event{
+		Step:         step,
+		Loss:         l,
+		LearningRate: lr,
+		GradNorms:    make(map[string]float64, len(model)),
+	}
+	var total float64
+	for i, n := range model {
+		grad, err := n.Grad()
+		if err != nil {
+			return errors.Wrapf(err, "Cannot read the gradient of parameter %d", i)
+		}
+		ss, err := sumSquares(grad)
+		if err != nil {
+			return errors.Wrapf(err, "Cannot compute the norm of the gradient of parameter %d", i)
+		}
+		total += ss
+		e.GradNorms[paramName(n, i)] = math.Sqrt(ss)
+	}
+	e.GradNorm = math.Sqrt(total)
+	return s.Publish(e)
+}
+
+// Publish records an event and streams it to the browsers. Step builds the events from the values of a model;
+// Publish is for the loops that compute the losses and norms themselves. The time is set to now if it is zero, and
+// the timing of the ops is read from the runtime metrics if the event has none.
+func (s *Server) Publish(e Event) error {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+
+	s.Lock()
+	defer s.Unlock()
+	if s.closed {
+		return errors.New("The dashboard is closed")
+	}
+	kernels := gorgonia.ReadMetrics().Kernels
+	if e.Ops == nil {
+		e.Ops = make(map[string]gorgonia.KernelStats)
+		for name, k := range kernels {
+			prev := s.kernels[name]
+			if k.Count > prev.Count {
+				e.Ops[name] = gorgonia.KernelStats{Count: k.Count - prev.Count, Time: k.Time - prev.Time}
+			}
+		}
+	}
+	s.kernels = kernels
+
+	msg, err := json.Marshal(e)
+	if err != nil {
+		return errors.Wrap(err, "Cannot encode the event")
+	}
+	s.history = append(s.history, msg)
+	if s.maxHistory > 0 && len(s.history) > s.maxHistory {
+		drop := len(s.history) - s.maxHistory
+		s.history = append(s.history[:0], s.history[drop:]...)
+	}
+	for c := range s.clients {
+		select {
+		case c.send <- msg:
+		default:
+			// the browser is too slow
+			delete(s.clients, c)
+			close(c.send)
+		}
+	}
+	return nil
+}
+
+// Close disconnects the browsers. The events published afterwards are rejected.
+func (s *Server) Close() error {
+	s.Lock()
+	defer s.Unlock()
+	s.closed = true
+	for c := range s.clients {
+		delete(s.clients, c)
+		close(c.send)
+	}
+	return nil
+}
+
+func (s *Server) servePage(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write([]byte(page))
+}
+
+func (s *Server) serveEvents(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	history := make([]json.RawMessage, len(s.history))
+	for i, msg := range s.history {
+		history[i] = msg
+	}
+	s.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(history)
+}
+
+func (s *Server) serveWS(w http.ResponseWriter, r *http.Request) {
+	conn, err := upgrade(w, r)
+	if err != nil {
+		return // upgrade has answered the request
+	}
+
+	s.Lock()
+	if s.closed {
+		s.Unlock()
+		conn.close()
+		return
+	}
+	// the history is queued before the client is registered, so that no event is missed or sent twice
+	c := &client{conn: conn, send: make(chan []byte, len(s.history)+s.buffer)}
+	for _, msg := range s.history {
+		c.send <- msg
+	}
+	s.clients[c] = struct{}{}
+	s.Unlock()
+
+	// the reader closes the connection when the browser goes away
+	done := make(chan struct{})
+	go func() {
+		conn.readLoop()
+		close(done)
+	}()
+loop:
+	for {
+		select {
+		case msg, ok := <-c.send:
+			if !ok || conn.writeFrame(opText, msg) != nil {
+				break loop
+			}
+		case <-done:
+			break loop
+		}
+	}
+	s.Lock()
+	if _, ok := s.clients[c]; ok {
+		delete(s.clients, c)
+		close(c.send)
+	}
+	s.Unlock()
+	conn.close()
+}
+
+// paramName is the name of a parameter, or its index if it has no name.
+func paramName(n gorgonia.ValueGrad, i int) string {
+	if namer, ok := n.(gorgonia.Namer); ok && namer.Name() != "" {
+		return namer.Name()
+	}
+	return "param " + strconv.Itoa(i)
+}
+
+// scalar reads a value holding a single float.
+func scalar(v gorgonia.Value) (float64, error) {
+	if v == nil {
+		return 0, errors.New("Expected a scalar value. Got nil instead")
+	}
+	data, err := floats(v)
+	if err != nil {
+		return 0, err
+	}
+	if len(data) != 1 {
+		return 0, errors.Errorf("Expected a scalar value. Got a value of shape %v instead", v.Shape())
+	}
+	return data[0], nil
+}
+
+// sumSquares returns the sum of the squares of the elements of a float value.
+func sumSquares(v gorgonia.Value) (float64, error) {
+	data, err := floats(v)
+	if err != nil {
+		return 0, err
+	}
+	var retVal float64
+	for _, f := range data {
+		retVal += f * f
+	}
+	return retVal, nil
+}
+
+// floats returns the elements of a float value as float64s.
+func floats(v gorgonia.Value) ([]float64, error) {
+	if t, ok := v.(tensor.Tensor); ok && t.RequiresIterator() {
+		v = tensor.Materialize(t)
+	}
+	switch data := v.Data().(type) {
+	case float64:
+		return []float64{data}, nil
+	case float32:
+		return []float64{float64(data)}, nil
+	case []float64:
+		return data, nil
+	case []float32:
+		retVal := make([]float64, len(data))
+		for i, f := range data {
+			retVal[i] = float64(f)
+		}
+		return retVal, nil
+	}
+	return nil, errors.Errorf("Expected a float value. Got %v instead", v.Dtype())
+}
@@ -0,0 +1,158 @@
+package dashboard
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorgonia.org/gorgonia"
+	"gorgonia.org/tensor"
+)
+
+type param struct {
+	name string
+	grad gorgonia.Value
+}
+
+func (p param) Value() gorgonia.Value         { return p.grad }
+func (p param) Grad() (gorgonia.Value, error) { return p.grad, nil }
+func (p param) Name() string                  { return p.name }
+
+// dial opens a websocket to the server, with the key of the example of RFC 6455.
+func dial(t *testing.T, url string) (net.Conn, *bufio.Reader) {
+	conn, err := net.Dial("tcp", strings.TrimPrefix(url, "http://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	req := "GET /ws HTTP/1.1\r\nHost: localhost\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n" +
+		"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\nSec-WebSocket-Version: 13\r\n\r\n"
+	if _, err = conn.Write([]byte(req)); err != nil {
+		t.Fatal(err)
+	}
+	r := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(r, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("Expected the protocols to be switched. Got %v instead", resp.Status)
+	}
+	if got := resp.Header.Get("Sec-WebSocket-Accept"); got != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Fatalf("Unexpected Sec-WebSocket-Accept %q", got)
+	}
+	return conn, r
+}
+
+// readEvent reads a text frame sent by the server, whose frames are not masked.
+func readEvent(t *testing.T, r *bufio.Reader) map[string]interface{} {
+	var header [2]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		t.Fatal(err)
+	}
+	if header[0]&0x0F != opText {
+		t.Fatalf("Expected a text frame. Got op %d instead", header[0]&0x0F)
+	}
+	length := uint64(header[1] & 0x7F)
+	switch length {
+	case 126:
+		var ext [2]byte
+		io.ReadFull(r, ext[:])
+		length = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		io.ReadFull(r, ext[:])
+		length = binary.BigEndian.Uint64(ext[:])
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		t.Fatal(err)
+	}
+	var e map[string]interface{}
+	if err := json.Unmarshal(payload, &e); err != nil {
+		t.Fatal(err)
+	}
+	return e
+}
+
+func TestServer(t *testing.T) {
+	assert := assert.New(t)
+	dash := New(WithHistory(2))
+	srv := httptest.NewServer(dash)
+	defer srv.Close()
+
+	w := param{"w", tensor.New(tensor.WithBacking([]float64{3, 4}))}
+	b := param{"", f32(12)}
+	model := []gorgonia.ValueGrad{w, b}
+	assert.Error(dash.Step(0, tensor.New(tensor.WithBacking([]float64{1, 2})), 0.1, model))
+	for step := 0; step < 3; step++ {
+		if err := dash.Step(step, f64(float64(10-step)), 0.1, model); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the history only keeps the last two events
+	resp, err := http.Get(srv.URL + "/events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var history []map[string]interface{}
+	err = json.NewDecoder(resp.Body).Decode(&history)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(2, len(history))
+	assert.Equal(1.0, history[0]["step"])
+	assert.Equal(8.0, history[1]["loss"])
+	assert.Equal(0.1, history[1]["lr"])
+	assert.Equal(13.0, history[1]["grad_norm"])
+	assert.Equal(map[string]interface{}{"w": 5.0, "param 1": 12.0}, history[1]["grad_norms"])
+
+	// a browser gets the history, then the new events
+	conn, r := dial(t, srv.URL)
+	defer conn.Close()
+	assert.Equal(1.0, readEvent(t, r)["step"])
+	assert.Equal(2.0, readEvent(t, r)["step"])
+	if err = dash.Publish(Event{Step: 3, Loss: math.NaN(), LearningRate: 0.05}); err != nil {
+		t.Fatal(err)
+	}
+	e := readEvent(t, r)
+	assert.Equal(3.0, e["step"])
+	assert.Nil(e["loss"])
+	assert.Equal(0.05, e["lr"])
+
+	resp, err = http.Get(srv.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	assert.Equal(http.StatusOK, resp.StatusCode)
+	assert.Contains(resp.Header.Get("Content-Type"), "text/html")
+
+	dash.Close()
+	assert.Error(dash.Publish(Event{Step: 4}))
+}
+
+func TestUpgrade(t *testing.T) {
+	srv := httptest.NewServer(New())
+	defer srv.Close()
+	resp, err := http.Get(srv.URL + "/ws")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func f32(v float32) *gorgonia.F32 { r := gorgonia.F32(v); return &r }
+func f64(v float64) *gorgonia.F64 { r := gorgonia.F64(v); return &r }
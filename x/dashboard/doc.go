@@ -0,0 +1,17 @@
+// Package dashboard provides an embedded web dashboard of the training of a model, for the users who do not want to
+// run TensorBoard. It streams the loss, the learning rate, the norms of the gradients and the time spent in every type
+// of op to the browsers over websockets.
+//
+// There is no training loop in Gorgonia, so the loop reports its progress by calling the callbacks of a Server:
+//		dash := dashboard.New()
+//		go http.ListenAndServe("localhost:6006", dash)
+//		for step := 0; step < steps; step++ {
+//			if err := m.RunAll(); err != nil { ... }
+//			dash.Step(step, loss.Value(), lr, model)
+//			solver.Step(model)
+//			m.Reset()
+//		}
+//
+// The per-op timing is read from the metrics of the runtime, so it is only reported once the timing of the kernels
+// is turned on with gorgonia.EnableMetrics(true).
+package dashboard
@@ -0,0 +1,113 @@
+package dashboard
+
+// page is the page of the dashboard. It has no dependencies, so that it works offline: the curves are drawn on
+// canvases, and the timing of the ops of the last step is a table.
+const page = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Gorgonia dashboard</title>
+<style>
+body { font-family: sans-serif; margin: 1em 2em; color: #222; }
+.charts { display: flex; flex-wrap: wrap; }
+.chart { margin: 0 1em 1em 0; }
+.chart h2, h2 { font-size: 1em; margin: 0.5em 0; }
+canvas { border: 1px solid #ccc; }
+table { border-collapse: collapse; }
+td, th { padding: 2px 8px; text-align: right; border-bottom: 1px solid #eee; }
+td:first-child, th:first-child { text-align: left; }
+#status { color: #888; }
+</style>
+</head>
+<body>
+<h1>Training <span id="status">connecting…</span></h1>
+<div class="charts">
+<div class="chart"><h2>Loss <span id="loss"></span></h2><canvas id="c-loss" width="480" height="240"></canvas></div>
+<div class="chart"><h2>Learning rate <span id="lr"></span></h2><canvas id="c-lr" width="480" height="240"></canvas></div>
+<div class="chart"><h2>Gradient norm <span id="grad_norm"></span></h2><canvas id="c-grad_norm" width="480" height="240"></canvas></div>
+</div>
+<h2>Gradient norms of the last step</h2>
+<table id="norms"><thead><tr><th>Parameter</th><th>L2 norm</th></tr></thead><tbody></tbody></table>
+<h2>Ops of the last step</h2>
+<table id="ops"><thead><tr><th>Op</th><th>Executions</th><th>Time (ms)</th></tr></thead><tbody></tbody></table>
+<script>
+"use strict";
+var events = [];
+var keys = ["loss", "lr", "grad_norm"];
+
+function draw(key) {
+	var canvas = document.getElementById("c-" + key);
+	var ctx = canvas.getContext("2d");
+	var w = canvas.width, h = canvas.height, pad = 30;
+	ctx.clearRect(0, 0, w, h);
+	var pts = events.filter(function(e) { return e[key] !== null; });
+	if (pts.length === 0) { return; }
+	var x0 = pts[0].step, x1 = pts[pts.length - 1].step;
+	var y0 = Infinity, y1 = -Infinity;
+	pts.forEach(function(e) { y0 = Math.min(y0, e[key]); y1 = Math.max(y1, e[key]); });
+	if (x1 === x0) { x1 = x0 + 1; }
+	if (y1 === y0) { y1 = y0 + 1; }
+	ctx.fillStyle = "#888";
+	ctx.fillText(y1.toPrecision(4), 2, pad - 4);
+	ctx.fillText(y0.toPrecision(4), 2, h - 4);
+	ctx.fillText(String(x1), w - 40, h - 4);
+	ctx.strokeStyle = "#1f77b4";
+	ctx.beginPath();
+	pts.forEach(function(e, i) {
+		var x = pad + (e.step - x0) / (x1 - x0) * (w - 2 * pad);
+		var y = h - pad - (e[key] - y0) / (y1 - y0) * (h - 2 * pad);
+		if (i === 0) { ctx.moveTo(x, y); } else { ctx.lineTo(x, y); }
+	});
+	ctx.stroke();
+}
+
+function table(id, rows) {
+	var body = document.querySelector("#" + id + " tbody");
+	body.innerHTML = "";
+	rows.forEach(function(row) {
+		var tr = document.createElement("tr");
+		row.forEach(function(cell) {
+			var td = document.createElement("td");
+			td.textContent = cell;
+			tr.appendChild(td);
+		});
+		body.appendChild(tr);
+	});
+}
+
+var pending = false;
+function render() {
+	pending = false;
+	keys.forEach(draw);
+	var last = events[events.length - 1];
+	if (!last) { return; }
+	keys.forEach(function(key) {
+		document.getElementById(key).textContent = last[key] === null ? "(not finite)" : last[key].toPrecision(6);
+	});
+	var norms = Object.keys(last.grad_norms || {}).sort().map(function(name) {
+		var v = last.grad_norms[name];
+		return [name, v === null ? "(not finite)" : v.toPrecision(6)];
+	});
+	table("norms", norms);
+	var ops = Object.keys(last.ops || {}).map(function(name) { return [name, last.ops[name]]; });
+	ops.sort(function(a, b) { return b[1].seconds - a[1].seconds; });
+	table("ops", ops.map(function(o) { return [o[0], o[1].count, (o[1].seconds * 1000).toFixed(3)]; }));
+}
+
+function connect() {
+	var ws = new WebSocket((location.protocol === "https:" ? "wss://" : "ws://") + location.host + location.pathname.replace(/\/?$/, "/") + "ws");
+	ws.onopen = function() { events = []; document.getElementById("status").textContent = ""; };
+	ws.onmessage = function(msg) {
+		events.push(JSON.parse(msg.data));
+		if (!pending) { pending = true; requestAnimationFrame(render); }
+	};
+	ws.onclose = function() {
+		document.getElementById("status").textContent = "disconnected";
+		setTimeout(connect, 2000);
+	};
+}
+connect();
+</script>
+</body>
+</html>
+`
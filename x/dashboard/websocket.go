@@ -0,0 +1,191 @@
+package dashboard
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// The server side of the websockets of RFC 6455, as far as the dashboard needs them: the server sends text messages,
+// and the clients only close the connections or ping.
+
+const (
+	wsGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+	opText  = 0x1
+	opClose = 0x8
+	opPing  = 0x9
+	opPong  = 0xA
+
+	// the largest frame a client may send. The clients send no data, only the control frames
+	maxClientFrame = 1 << 16
+
+	writeTimeout = 10 * time.Second
+)
+
+// wsConn is a websocket connection. The writes are serialized, as the pongs are written by the reader.
+type wsConn struct {
+	conn net.Conn
+	rw   *bufio.ReadWriter
+
+	sync.Mutex
+	closed bool
+}
+
+// upgrade performs the opening handshake of a websocket, and takes over the connection of the request.
+func upgrade(w http.ResponseWriter, r *http.Request) (*wsConn, error) {
+	if r.Method != http.MethodGet || !headerContains(r.Header, "Connection", "upgrade") || !headerContains(r.Header, "Upgrade", "websocket") {
+		http.Error(w, "Expected a websocket handshake", http.StatusBadRequest)
+		return nil, errors.New("Not a websocket handshake")
+	}
+	if r.Header.Get("Sec-Websocket-Version") != "13" {
+		w.Header().Set("Sec-WebSocket-Version", "13")
+		http.Error(w, "Unsupported websocket version", http.StatusUpgradeRequired)
+		return nil, errors.Errorf("Unsupported websocket version %q", r.Header.Get("Sec-Websocket-Version"))
+	}
+	key := r.Header.Get("Sec-Websocket-Key")
+	if key == "" {
+		http.Error(w, "Missing Sec-WebSocket-Key", http.StatusBadRequest)
+		return nil, errors.New("Missing Sec-WebSocket-Key")
+	}
+	hj, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "Websockets are not supported", http.StatusInternalServerError)
+		return nil, errors.New("The connection cannot be hijacked")
+	}
+	conn, rw, err := hj.Hijack()
+	if err != nil {
+		return nil, errors.Wrap(err, "Cannot hijack the connection")
+	}
+
+	h := sha1.New()
+	io.WriteString(h, key+wsGUID)
+	accept := base64.StdEncoding.EncodeToString(h.Sum(nil))
+	rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Accept: " + accept + "\r\n\r\n")
+	if err = rw.Flush(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return &wsConn{conn: conn, rw: rw}, nil
+}
+
+// headerContains reports whether a header has a comma separated token, ignoring the case.
+func headerContains(h http.Header, name, token string) bool {
+	for _, v := range h[http.CanonicalHeaderKey(name)] {
+		for _, t := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(t), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// writeFrame writes an unmasked frame, as the servers do.
+func (c *wsConn) writeFrame(op byte, payload []byte) error {
+	c.Lock()
+	defer c.Unlock()
+	if c.closed {
+		return errors.New("The websocket is closed")
+	}
+	header := []byte{0x80 | op, 0, 0, 0, 0, 0, 0, 0, 0, 0}
+	n := 2
+	switch l := len(payload); {
+	case l < 126:
+		header[1] = byte(l)
+	case l <= 0xFFFF:
+		header[1] = 126
+		binary.BigEndian.PutUint16(header[2:], uint16(l))
+		n = 4
+	default:
+		header[1] = 127
+		binary.BigEndian.PutUint64(header[2:], uint64(l))
+		n = 10
+	}
+	c.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	c.rw.Write(header[:n])
+	c.rw.Write(payload)
+	return c.rw.Flush()
+}
+
+// readFrame reads a frame sent by a client, whose frames are masked.
+func (c *wsConn) readFrame() (op byte, payload []byte, err error) {
+	var header [2]byte
+	if _, err = io.ReadFull(c.rw, header[:]); err != nil {
+		return 0, nil, err
+	}
+	op = header[0] & 0x0F
+	if header[1]&0x80 == 0 {
+		return 0, nil, errors.New("The frames of the clients must be masked")
+	}
+	length := uint64(header[1] & 0x7F)
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err = io.ReadFull(c.rw, ext[:]); err != nil {
+			return 0, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err = io.ReadFull(c.rw, ext[:]); err != nil {
+			return 0, nil, err
+		}
+		length = binary.BigEndian.Uint64(ext[:])
+	}
+	if length > maxClientFrame {
+		return 0, nil, errors.Errorf("The frame of %d bytes is too large", length)
+	}
+	var mask [4]byte
+	if _, err = io.ReadFull(c.rw, mask[:]); err != nil {
+		return 0, nil, err
+	}
+	payload = make([]byte, length)
+	if _, err = io.ReadFull(c.rw, payload); err != nil {
+		return 0, nil, err
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+	return op, payload, nil
+}
+
+// readLoop answers the pings and the closing handshake of the client, until the connection is closed. The other
+// frames are ignored.
+func (c *wsConn) readLoop() {
+	defer c.close()
+	for {
+		op, payload, err := c.readFrame()
+		if err != nil {
+			return
+		}
+		switch op {
+		case opPing:
+			if c.writeFrame(opPong, payload) != nil {
+				return
+			}
+		case opClose:
+			return // close answers with a closing frame
+		}
+	}
+}
+
+// close closes the connection, after a closing frame if it is still open.
+func (c *wsConn) close() {
+	c.writeFrame(opClose, nil)
+	c.Lock()
+	defer c.Unlock()
+	if !c.closed {
+		c.closed = true
+		c.conn.Close()
+	}
+}
@@ -0,0 +1,20 @@
+// Package dataset provides the splitting of the datasets for the evaluation of models: train, validation and test
+// splits, the folds of k-fold cross-validation, both stratified by the labels when they are given, and the
+// aggregation of the metrics of the folds. For the imbalanced classifications, it computes the weights of the classes,
+// to be passed to gorgonia.ClassWeightedMean, and samples the rows in proportion to their weights with a
+// WeightedSampler.
+//
+// The scalers fit the standardization of the features in a streaming pass over the batches of a dataset, and the
+// fitted Scaling is saved along with the model to scale the inputs of the serving the same way.
+//
+// The splits are of the indices of the rows (the samples), so that any number of tensors may be split the same way
+// with Rows:
+//		folds, err := dataset.StratifiedKFold(labels, 5, dataset.WithSeed(0))
+//		...
+//		scores, err := dataset.CrossValidate(folds, func(i int, f dataset.Fold) (map[string]float64, error) {
+//			xTrain, err := dataset.Rows(x, f.Train)
+//			...
+//			return map[string]float64{"loss": loss, "accuracy": acc}, nil
+//		})
+//		fmt.Println(scores)
+package dataset
@@ -0,0 +1,41 @@
+package dataset
+
+import (
+	"math/rand"
+	"time"
+)
+
+type config struct {
+	rand      *rand.Rand
+	noShuffle bool
+}
+
+func newConfig(opts []Opt) config {
+	var c config
+	for _, opt := range opts {
+		opt(&c)
+	}
+	if c.rand == nil {
+		c.rand = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	return c
+}
+
+// Opt is an option of the splits.
+type Opt func(*config)
+
+// WithSeed seeds the source of randomness. By default, it is seeded with the current time.
+func WithSeed(seed int64) Opt {
+	return func(c *config) { c.rand = rand.New(rand.NewSource(seed)) }
+}
+
+// WithRand sets the source of randomness.
+func WithRand(r *rand.Rand) Opt {
+	return func(c *config) { c.rand = r }
+}
+
+// WithoutShuffle splits the rows in their order, as the time series must be: the validation and test rows are the
+// last ones (of every class, if stratified), and the folds are contiguous.
+func WithoutShuffle() Opt {
+	return func(c *config) { c.noShuffle = true }
+}
@@ -0,0 +1,89 @@
+package dataset
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/pkg/errors"
+)
+
+// WeightedSampler samples the rows of a dataset with replacement, with probabilities proportional to their weights.
+// With the SampleWeights of the BalancedClassWeights, the batches sampled have as many rows of every class on
+// average, which is an alternative to weighting the loss:
+//		weights, err := dataset.BalancedClassWeights(labels, classes)
+//		...
+//		rowWeights, err := dataset.SampleWeights(labels, weights)
+//		...
+//		sampler, err := dataset.NewWeightedSampler(rowWeights, dataset.WithSeed(0))
+//		...
+//		xBatch, err := dataset.Rows(x, sampler.Sample(batchSize))
+//
+// The samples are drawn in constant time by the alias method of Walker (1977). A WeightedSampler is not safe for
+// concurrent use.
+type WeightedSampler struct {
+	prob  []float64 // the probability of keeping a column rather than its alias
+	alias []int
+	rand  *rand.Rand
+}
+
+// NewWeightedSampler creates a sampler of the rows of the weights. The weights must be non-negative and finite, and
+// not all 0.
+func NewWeightedSampler(weights []float64, opts ...Opt) (*WeightedSampler, error) {
+	n := len(weights)
+	var total float64
+	for i, w := range weights {
+		if w < 0 || math.IsNaN(w) || math.IsInf(w, 0) {
+			return nil, errors.Errorf("Expected non-negative finite weights. The weight of row %d is %v", i, w)
+		}
+		total += w
+	}
+	if total == 0 {
+		return nil, errors.New("Expected a positive weight")
+	}
+	s := &WeightedSampler{prob: make([]float64, n), alias: make([]int, n), rand: newConfig(opts).rand}
+
+	// Vose's construction of the table
+	scaled := make([]float64, n)
+	var small, large []int
+	for i, w := range weights {
+		scaled[i] = w * float64(n) / total
+		if scaled[i] < 1 {
+			small = append(small, i)
+		} else {
+			large = append(large, i)
+		}
+	}
+	for len(small) > 0 && len(large) > 0 {
+		l, g := small[len(small)-1], large[len(large)-1]
+		small = small[:len(small)-1]
+		s.prob[l], s.alias[l] = scaled[l], g
+		scaled[g] += scaled[l] - 1
+		if scaled[g] < 1 {
+			large = large[:len(large)-1]
+			small = append(small, g)
+		}
+	}
+	// the rest are 1 up to rounding
+	for _, i := range append(small, large...) {
+		s.prob[i], s.alias[i] = 1, i
+	}
+	return s, nil
+}
+
+// Next returns the index of a row.
+func (s *WeightedSampler) Next() int {
+	i := s.rand.Intn(len(s.prob))
+	if s.rand.Float64() < s.prob[i] {
+		return i
+	}
+	return s.alias[i]
+}
+
+// Sample returns the indices of n rows.
+func (s *WeightedSampler) Sample(n int) []int {
+	retVal := make([]int, n)
+	for i := range retVal {
+		retVal[i] = s.Next()
+	}
+	return retVal
+}
@@ -0,0 +1,55 @@
+package dataset
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWeightedSampler(t *testing.T) {
+	assert := assert.New(t)
+	weights := []float64{1, 0, 3, 4}
+	s, err := NewWeightedSampler(weights, WithSeed(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	const n = 80000
+	counts := make([]float64, len(weights))
+	for _, i := range s.Sample(n) {
+		counts[i]++
+	}
+	assert.Equal(0.0, counts[1], "a row of weight 0 is never sampled")
+	for i, w := range weights {
+		assert.InDelta(w/8, counts[i]/n, 0.01)
+	}
+
+	// balancing an imbalanced dataset
+	labels := make([]int, 100)
+	for i := 0; i < 10; i++ {
+		labels[i] = 1
+	}
+	classWeights, err := BalancedClassWeights(labels, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rowWeights, err := SampleWeights(labels, classWeights)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s, err = NewWeightedSampler(rowWeights, WithSeed(2)); err != nil {
+		t.Fatal(err)
+	}
+	var ones float64
+	for _, i := range s.Sample(n) {
+		ones += float64(labels[i])
+	}
+	assert.InDelta(0.5, ones/n, 0.01)
+
+	// errors
+	_, err = NewWeightedSampler([]float64{0, 0})
+	assert.Error(err)
+	_, err = NewWeightedSampler([]float64{1, -1})
+	assert.Error(err)
+	_, err = NewWeightedSampler(nil)
+	assert.Error(err)
+}
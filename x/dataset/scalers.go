@@ -0,0 +1,286 @@
+package dataset
+
+import (
+	"encoding/json"
+	"io"
+	"math"
+	"math/rand"
+	"sort"
+
+	"github.com/pkg/errors"
+	"gorgonia.org/tensor"
+)
+
+// Scaling is a fitted scaling of the columns (the features) of a matrix: x' = (x - Center)/Scale. It is serialized as
+// JSON with Save and LoadScaling, so that the inputs are scaled the same way when the model is served.
+type Scaling struct {
+	Kind   string    `json:"kind"`
+	Center []float64 `json:"center"`
+	Scale  []float64 `json:"scale"`
+}
+
+// Scaler fits a Scaling in a streaming pass over the batches of a dataset, one Partial at a time. See Fit.
+type Scaler interface {
+	// Partial updates the statistics with the rows of a (rows, features) batch.
+	Partial(x *tensor.Dense) error
+	// Scaling returns the scaling of the rows seen so far.
+	Scaling() (*Scaling, error)
+}
+
+// Fit fits the scaler on the batches returned by next, until it returns io.EOF.
+func Fit(s Scaler, next func() (*tensor.Dense, error)) (*Scaling, error) {
+	for i := 0; ; i++ {
+		x, err := next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err = s.Partial(x); err != nil {
+			return nil, errors.Wrapf(err, "Batch %d", i)
+		}
+	}
+	return s.Scaling()
+}
+
+// Transform scales the columns of x, returning a new matrix of its dtype.
+func (s *Scaling) Transform(x *tensor.Dense) (*tensor.Dense, error) { return s.apply(x, false) }
+
+// InverseTransform undoes the scaling of the columns of x, such as the predicted targets of a regression.
+func (s *Scaling) InverseTransform(x *tensor.Dense) (*tensor.Dense, error) { return s.apply(x, true) }
+
+func (s *Scaling) apply(x *tensor.Dense, inverse bool) (*tensor.Dense, error) {
+	data, d, err := floatRows(x)
+	if err != nil {
+		return nil, err
+	}
+	if d != len(s.Center) || d != len(s.Scale) {
+		return nil, errors.Errorf("The scaling is of %d features. Got %d instead", len(s.Center), d)
+	}
+	for i, v := range data {
+		j := i % d
+		if inverse {
+			data[i] = v*s.Scale[j] + s.Center[j]
+		} else {
+			data[i] = (v - s.Center[j]) / s.Scale[j]
+		}
+	}
+	return newFloats(x.Dtype(), x.Shape(), data), nil
+}
+
+// Save writes the scaling as JSON.
+func (s *Scaling) Save(w io.Writer) error { return json.NewEncoder(w).Encode(s) }
+
+// LoadScaling reads a scaling written by Save.
+func LoadScaling(r io.Reader) (*Scaling, error) {
+	s := new(Scaling)
+	if err := json.NewDecoder(r).Decode(s); err != nil {
+		return nil, errors.Wrap(err, "Failed to decode the scaling")
+	}
+	if len(s.Center) != len(s.Scale) {
+		return nil, errors.Errorf("The scaling has %d centers and %d scales", len(s.Center), len(s.Scale))
+	}
+	return s, nil
+}
+
+// StandardScaler scales the features to a mean of 0 and a standard deviation of 1. The statistics of the batches are
+// merged exactly, as by Chan et al. (1979), so they do not depend on the batching.
+type StandardScaler struct {
+	count    float64
+	mean, m2 []float64
+}
+
+// NewStandardScaler creates a StandardScaler.
+func NewStandardScaler() *StandardScaler { return new(StandardScaler) }
+
+// Partial updates the means and variances with the rows of x.
+func (s *StandardScaler) Partial(x *tensor.Dense) error {
+	data, d, err := floatRows(x)
+	if err != nil {
+		return err
+	}
+	if s.mean == nil {
+		s.mean, s.m2 = make([]float64, d), make([]float64, d)
+	} else if d != len(s.mean) {
+		return errors.Errorf("Expected %d features. Got %d instead", len(s.mean), d)
+	}
+	n := float64(len(data) / d)
+	mean, m2 := make([]float64, d), make([]float64, d)
+	for i, v := range data {
+		mean[i%d] += v / n
+	}
+	for i, v := range data {
+		m2[i%d] += (v - mean[i%d]) * (v - mean[i%d])
+	}
+	total := s.count + n
+	for j := range mean {
+		delta := mean[j] - s.mean[j]
+		s.mean[j] += delta * n / total
+		s.m2[j] += m2[j] + delta*delta*s.count*n/total
+	}
+	s.count = total
+	return nil
+}
+
+// Scaling returns the scaling by the means and the (population) standard deviations. The constant features are only
+// centered.
+func (s *StandardScaler) Scaling() (*Scaling, error) {
+	if s.count == 0 {
+		return nil, errors.New("The scaler has seen no rows")
+	}
+	retVal := &Scaling{Kind: "standard", Center: append([]float64(nil), s.mean...), Scale: make([]float64, len(s.m2))}
+	for j, m2 := range s.m2 {
+		retVal.Scale[j] = nonZero(math.Sqrt(m2 / s.count))
+	}
+	return retVal, nil
+}
+
+// MinMaxScaler scales the features to [0, 1].
+type MinMaxScaler struct {
+	min, max []float64
+}
+
+// NewMinMaxScaler creates a MinMaxScaler.
+func NewMinMaxScaler() *MinMaxScaler { return new(MinMaxScaler) }
+
+// Partial updates the minimums and maximums with the rows of x.
+func (s *MinMaxScaler) Partial(x *tensor.Dense) error {
+	data, d, err := floatRows(x)
+	if err != nil {
+		return err
+	}
+	if s.min == nil {
+		s.min, s.max = make([]float64, d), make([]float64, d)
+		for j := range s.min {
+			s.min[j], s.max[j] = math.Inf(1), math.Inf(-1)
+		}
+	} else if d != len(s.min) {
+		return errors.Errorf("Expected %d features. Got %d instead", len(s.min), d)
+	}
+	for i, v := range data {
+		s.min[i%d] = math.Min(s.min[i%d], v)
+		s.max[i%d] = math.Max(s.max[i%d], v)
+	}
+	return nil
+}
+
+// Scaling returns the scaling by the ranges of the features. The constant features are only centered.
+func (s *MinMaxScaler) Scaling() (*Scaling, error) {
+	if s.min == nil {
+		return nil, errors.New("The scaler has seen no rows")
+	}
+	retVal := &Scaling{Kind: "minmax", Center: append([]float64(nil), s.min...), Scale: make([]float64, len(s.min))}
+	for j := range s.min {
+		retVal.Scale[j] = nonZero(s.max[j] - s.min[j])
+	}
+	return retVal, nil
+}
+
+// RobustScaler scales the features by their medians and interquartile ranges, which outliers hardly move. The
+// quantiles are those of a uniform sample (a reservoir) of the rows seen, kept in a bounded memory: they are exact as
+// long as the rows fit in the reservoir.
+type RobustScaler struct {
+	capacity int
+	rand     *rand.Rand
+	seen     int
+	sample   [][]float64 // the rows of the reservoir
+}
+
+// NewRobustScaler creates a RobustScaler with a reservoir of the given number of rows.
+func NewRobustScaler(capacity int, opts ...Opt) (*RobustScaler, error) {
+	if capacity < 1 {
+		return nil, errors.Errorf("Expected a positive capacity. Got %d instead", capacity)
+	}
+	return &RobustScaler{capacity: capacity, rand: newConfig(opts).rand}, nil
+}
+
+// Partial samples the rows of x into the reservoir.
+func (s *RobustScaler) Partial(x *tensor.Dense) error {
+	data, d, err := floatRows(x)
+	if err != nil {
+		return err
+	}
+	if len(s.sample) > 0 && d != len(s.sample[0]) {
+		return errors.Errorf("Expected %d features. Got %d instead", len(s.sample[0]), d)
+	}
+	for i := 0; i < len(data); i += d {
+		s.seen++
+		switch {
+		case len(s.sample) < s.capacity:
+			s.sample = append(s.sample, append([]float64(nil), data[i:i+d]...))
+		default:
+			if r := s.rand.Intn(s.seen); r < s.capacity {
+				s.sample[r] = append(s.sample[r][:0], data[i:i+d]...)
+			}
+		}
+	}
+	return nil
+}
+
+// Scaling returns the scaling by the medians and the interquartile ranges. The features whose quartiles are equal are
+// only centered.
+func (s *RobustScaler) Scaling() (*Scaling, error) {
+	if len(s.sample) == 0 {
+		return nil, errors.New("The scaler has seen no rows")
+	}
+	d := len(s.sample[0])
+	retVal := &Scaling{Kind: "robust", Center: make([]float64, d), Scale: make([]float64, d)}
+	col := make([]float64, len(s.sample))
+	for j := 0; j < d; j++ {
+		for i, row := range s.sample {
+			col[i] = row[j]
+		}
+		sort.Float64s(col)
+		retVal.Center[j] = quantile(col, 0.5)
+		retVal.Scale[j] = nonZero(quantile(col, 0.75) - quantile(col, 0.25))
+	}
+	return retVal, nil
+}
+
+// quantile interpolates the q-quantile of sorted values linearly.
+func quantile(sorted []float64, q float64) float64 {
+	pos := q * float64(len(sorted)-1)
+	lo := int(math.Floor(pos))
+	if lo+1 >= len(sorted) {
+		return sorted[lo]
+	}
+	return sorted[lo] + (pos-float64(lo))*(sorted[lo+1]-sorted[lo])
+}
+
+func nonZero(scale float64) float64 {
+	if scale == 0 {
+		return 1
+	}
+	return scale
+}
+
+// floatRows returns a float64 copy of the data of a non-empty float matrix, and its number of columns.
+func floatRows(x *tensor.Dense) ([]float64, int, error) {
+	if x == nil || x.Dims() != 2 || x.Shape()[0] == 0 || x.Shape()[1] == 0 {
+		return nil, 0, errors.New("Expected a non-empty (rows, features) matrix")
+	}
+	switch data := tensor.Materialize(x).Data().(type) {
+	case []float64:
+		return append([]float64(nil), data...), x.Shape()[1], nil
+	case []float32:
+		retVal := make([]float64, len(data))
+		for i, v := range data {
+			retVal[i] = float64(v)
+		}
+		return retVal, x.Shape()[1], nil
+	}
+	return nil, 0, errors.Errorf("Expected a Float64 or Float32 matrix. Got %v instead", x.Dtype())
+}
+
+// newFloats returns a tensor of the dtype and shape, holding the data.
+func newFloats(dt tensor.Dtype, shape tensor.Shape, data []float64) *tensor.Dense {
+	if dt == tensor.Float32 {
+		f32s := make([]float32, len(data))
+		for i, f := range data {
+			f32s[i] = float32(f)
+		}
+		return tensor.New(tensor.WithShape(shape.Clone()...), tensor.WithBacking(f32s))
+	}
+	return tensor.New(tensor.WithShape(shape.Clone()...), tensor.WithBacking(data))
+}
@@ -0,0 +1,149 @@
+package dataset
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorgonia.org/tensor"
+)
+
+// batches returns a function that returns the rows of x in batches of n rows.
+func batches(x [][]float64, n int) func() (*tensor.Dense, error) {
+	i := 0
+	return func() (*tensor.Dense, error) {
+		if i >= len(x) {
+			return nil, io.EOF
+		}
+		end := i + n
+		if end > len(x) {
+			end = len(x)
+		}
+		var data []float64
+		for _, row := range x[i:end] {
+			data = append(data, row...)
+		}
+		d := len(x[0])
+		batch := tensor.New(tensor.WithShape(end-i, d), tensor.WithBacking(data))
+		i = end
+		return batch, nil
+	}
+}
+
+var scalerRows = [][]float64{{1, 10, 5}, {2, 20, 5}, {3, 30, 5}, {4, 40, 5}, {100, 50, 5}}
+
+func TestStandardScaler(t *testing.T) {
+	assert := assert.New(t)
+	whole, err := Fit(NewStandardScaler(), batches(scalerRows, 5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	streamed, err := Fit(NewStandardScaler(), batches(scalerRows, 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal("standard", streamed.Kind)
+	assert.InDeltaSlice([]float64{22, 30, 5}, streamed.Center, 1e-12)
+	assert.InDeltaSlice(whole.Scale, streamed.Scale, 1e-12, "the statistics do not depend on the batching")
+	assert.InDelta(math.Sqrt(200), streamed.Scale[1], 1e-12)
+	assert.Equal(1.0, streamed.Scale[2], "a constant feature is only centered")
+
+	x, _ := batches(scalerRows, 5)()
+	scaled, err := streamed.Transform(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	col := make([]float64, 5)
+	for i := range col {
+		v, _ := scaled.At(i, 1)
+		col[i] = v.(float64)
+	}
+	assert.InDeltaSlice([]float64{-2 / math.Sqrt(2), -1 / math.Sqrt(2), 0, 1 / math.Sqrt(2), 2 / math.Sqrt(2)}, col, 1e-12)
+	back, err := streamed.InverseTransform(scaled)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.InDeltaSlice(x.Data(), back.Data(), 1e-12)
+
+	_, err = NewStandardScaler().Scaling()
+	assert.Error(err)
+	s := NewStandardScaler()
+	assert.NoError(s.Partial(x))
+	assert.Error(s.Partial(tensor.New(tensor.WithShape(1, 2), tensor.WithBacking([]float64{1, 2}))))
+	assert.Error(s.Partial(tensor.New(tensor.WithShape(1, 3), tensor.WithBacking([]int{1, 2, 3}))))
+}
+
+func TestMinMaxScaler(t *testing.T) {
+	assert := assert.New(t)
+	s, err := Fit(NewMinMaxScaler(), batches(scalerRows, 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]float64{1, 10, 5}, s.Center)
+	assert.Equal([]float64{99, 40, 1}, s.Scale)
+
+	x := tensor.New(tensor.WithShape(1, 3), tensor.WithBacking([]float32{100, 30, 5}))
+	scaled, err := s.Transform(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]float32{1, 0.5, 0}, scaled.Data(), "the dtype is kept")
+	_, err = s.Transform(tensor.New(tensor.WithShape(1, 2), tensor.WithBacking([]float64{1, 2})))
+	assert.Error(err)
+}
+
+func TestRobustScaler(t *testing.T) {
+	assert := assert.New(t)
+	rs, err := NewRobustScaler(100, WithSeed(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := Fit(rs, batches(scalerRows, 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the outlier 100 moves neither the median nor the quartiles of the first feature
+	assert.Equal([]float64{3, 30, 5}, s.Center)
+	assert.Equal([]float64{2, 20, 1}, s.Scale)
+
+	// a reservoir smaller than the stream samples it uniformly
+	var rows [][]float64
+	for i := 0; i < 10000; i++ {
+		rows = append(rows, []float64{float64(i % 1000)})
+	}
+	if rs, err = NewRobustScaler(2000, WithSeed(2)); err != nil {
+		t.Fatal(err)
+	}
+	if s, err = Fit(rs, batches(rows, 64)); err != nil {
+		t.Fatal(err)
+	}
+	assert.InDelta(500, s.Center[0], 30)
+	assert.InDelta(500, s.Scale[0], 50)
+
+	_, err = NewRobustScaler(0)
+	assert.Error(err)
+}
+
+func TestScalingSaveLoad(t *testing.T) {
+	assert := assert.New(t)
+	s, err := Fit(NewStandardScaler(), batches(scalerRows, 3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err = s.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadScaling(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(s, loaded)
+
+	_, err = LoadScaling(bytes.NewBufferString(`{"kind": "standard", "center": [1], "scale": []}`))
+	assert.Error(err)
+	_, err = LoadScaling(bytes.NewBufferString(`{`))
+	assert.Error(err)
+}
@@ -0,0 +1,102 @@
+package dataset
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+)
+
+// Scores are the metrics of the folds of a cross-validation, by name.
+type Scores struct {
+	Folds []map[string]float64
+}
+
+// Summary is the aggregation of a metric over the folds.
+type Summary struct {
+	Mean, Std float64 // Std is the sample standard deviation, 0 for a single fold
+	Min, Max  float64
+	Folds     int // the number of folds that reported the metric
+}
+
+// CrossValidate calls eval with every fold in turn, and collects the metrics it returns. It stops at the first error.
+func CrossValidate(folds []Fold, eval func(i int, f Fold) (map[string]float64, error)) (*Scores, error) {
+	retVal := &Scores{Folds: make([]map[string]float64, 0, len(folds))}
+	for i, f := range folds {
+		metrics, err := eval(i, f)
+		if err != nil {
+			return retVal, errors.Wrapf(err, "Fold %d", i)
+		}
+		retVal.Add(metrics)
+	}
+	return retVal, nil
+}
+
+// Add adds the metrics of a fold.
+func (s *Scores) Add(metrics map[string]float64) { s.Folds = append(s.Folds, metrics) }
+
+// Names returns the names of the metrics, sorted.
+func (s *Scores) Names() []string {
+	set := make(map[string]struct{})
+	for _, f := range s.Folds {
+		for name := range f {
+			set[name] = struct{}{}
+		}
+	}
+	retVal := make([]string, 0, len(set))
+	for name := range set {
+		retVal = append(retVal, name)
+	}
+	sort.Strings(retVal)
+	return retVal
+}
+
+// Values returns the values of the metric in the folds that reported it.
+func (s *Scores) Values(name string) []float64 {
+	var retVal []float64
+	for _, f := range s.Folds {
+		if v, ok := f[name]; ok {
+			retVal = append(retVal, v)
+		}
+	}
+	return retVal
+}
+
+// Summary aggregates the metric over the folds. The summary of a metric that no fold reported is all NaNs.
+func (s *Scores) Summary(name string) Summary {
+	vs := s.Values(name)
+	if len(vs) == 0 {
+		nan := math.NaN()
+		return Summary{Mean: nan, Std: nan, Min: nan, Max: nan}
+	}
+	retVal := Summary{Min: math.Inf(1), Max: math.Inf(-1), Folds: len(vs)}
+	for _, v := range vs {
+		retVal.Mean += v
+		retVal.Min = math.Min(retVal.Min, v)
+		retVal.Max = math.Max(retVal.Max, v)
+	}
+	retVal.Mean /= float64(len(vs))
+	if len(vs) > 1 {
+		for _, v := range vs {
+			retVal.Std += (v - retVal.Mean) * (v - retVal.Mean)
+		}
+		retVal.Std = math.Sqrt(retVal.Std / float64(len(vs)-1))
+	}
+	return retVal
+}
+
+// String returns a table of the summaries of the metrics.
+func (s *Scores) String() string {
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "Metric\tMean\tStd\tMin\tMax\tFolds\t")
+	for _, name := range s.Names() {
+		sum := s.Summary(name)
+		fmt.Fprintf(w, "%s\t%.4g\t%.4g\t%.4g\t%.4g\t%d\t\n", name, sum.Mean, sum.Std, sum.Min, sum.Max, sum.Folds)
+	}
+	w.Flush()
+	return buf.String()
+}
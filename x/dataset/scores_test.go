@@ -0,0 +1,50 @@
+package dataset
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCrossValidate(t *testing.T) {
+	assert := assert.New(t)
+	folds, err := KFold(9, 3, WithoutShuffle())
+	if err != nil {
+		t.Fatal(err)
+	}
+	scores, err := CrossValidate(folds, func(i int, f Fold) (map[string]float64, error) {
+		metrics := map[string]float64{"accuracy": 0.7 + 0.1*float64(i)}
+		if i > 0 {
+			metrics["loss"] = float64(f.Val[0])
+		}
+		return metrics, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]string{"accuracy", "loss"}, scores.Names())
+	acc := scores.Summary("accuracy")
+	assert.InDelta(0.8, acc.Mean, 1e-12)
+	assert.InDelta(0.1, acc.Std, 1e-12)
+	assert.InDelta(0.7, acc.Min, 1e-12)
+	assert.InDelta(0.9, acc.Max, 1e-12)
+	assert.Equal(3, acc.Folds)
+	assert.Equal([]float64{3, 6}, scores.Values("loss"))
+	assert.Equal(2, scores.Summary("loss").Folds)
+	assert.True(math.IsNaN(scores.Summary("f1").Mean))
+
+	lines := strings.Split(scores.String(), "\n")
+	assert.True(strings.HasPrefix(lines[1], "accuracy  0.8"))
+
+	_, err = CrossValidate(folds, func(i int, f Fold) (map[string]float64, error) {
+		return nil, errors.New("diverged")
+	})
+	assert.Error(err)
+
+	single := &Scores{}
+	single.Add(map[string]float64{"loss": 1})
+	assert.Equal(0.0, single.Summary("loss").Std)
+}
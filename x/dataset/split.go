@@ -0,0 +1,193 @@
+package dataset
+
+import (
+	"math"
+	"reflect"
+	"sort"
+
+	"github.com/pkg/errors"
+	"gorgonia.org/tensor"
+)
+
+// Split is a split of the rows of a dataset. The indices of every part are in increasing order.
+type Split struct {
+	Train, Val, Test []int
+}
+
+// Fold is a fold of a k-fold cross-validation: the model is trained on the Train rows and evaluated on the Val rows.
+type Fold struct {
+	Train, Val []int
+}
+
+// TrainValTestSplit splits n rows into the given fractions of validation and test rows, the rest being the training
+// rows.
+func TrainValTestSplit(n int, val, test float64, opts ...Opt) (Split, error) {
+	if n < 1 {
+		return Split{}, errors.Errorf("Expected a positive number of rows. Got %d instead", n)
+	}
+	return split([][]int{intRange(n)}, val, test, newConfig(opts))
+}
+
+// StratifiedSplit splits the rows of the labels into the given fractions of validation and test rows, the fractions
+// being of every class so that the parts have the same proportions of classes as the whole.
+func StratifiedSplit(labels []int, val, test float64, opts ...Opt) (Split, error) {
+	classes, err := byClass(labels)
+	if err != nil {
+		return Split{}, err
+	}
+	return split(classes, val, test, newConfig(opts))
+}
+
+func split(classes [][]int, val, test float64, c config) (retVal Split, err error) {
+	if val < 0 || test < 0 || val+test >= 1 {
+		return Split{}, errors.Errorf("Expected the fractions of validation and test rows to be non-negative and to total less than 1. Got %v and %v instead", val, test)
+	}
+	for _, rows := range classes {
+		shuffle(rows, c)
+		n := len(rows)
+		nTest := int(math.Round(test * float64(n)))
+		nVal := int(math.Round(val * float64(n)))
+		if nVal+nTest > n {
+			nVal = n - nTest
+		}
+		train := n - nVal - nTest
+		retVal.Train = append(retVal.Train, rows[:train]...)
+		retVal.Val = append(retVal.Val, rows[train:train+nVal]...)
+		retVal.Test = append(retVal.Test, rows[train+nVal:]...)
+	}
+	sort.Ints(retVal.Train)
+	sort.Ints(retVal.Val)
+	sort.Ints(retVal.Test)
+	if len(retVal.Train) == 0 {
+		return Split{}, errors.New("The split leaves no training rows")
+	}
+	return retVal, nil
+}
+
+// KFold splits n rows into k folds of sizes that differ by one at most.
+func KFold(n, k int, opts ...Opt) ([]Fold, error) {
+	if n < 1 {
+		return nil, errors.Errorf("Expected a positive number of rows. Got %d instead", n)
+	}
+	return kFold([][]int{intRange(n)}, n, k, newConfig(opts))
+}
+
+// StratifiedKFold splits the rows of the labels into k folds, dealing the rows of every class to the folds in turn so
+// that the folds have the same proportions of classes as the whole. A class of fewer than k rows is missing from some
+// of the folds.
+func StratifiedKFold(labels []int, k int, opts ...Opt) ([]Fold, error) {
+	classes, err := byClass(labels)
+	if err != nil {
+		return nil, err
+	}
+	return kFold(classes, len(labels), k, newConfig(opts))
+}
+
+func kFold(classes [][]int, n, k int, c config) ([]Fold, error) {
+	if k < 2 || k > n {
+		return nil, errors.Errorf("Expected between 2 and %d folds. Got %d instead", n, k)
+	}
+	fold := make([]int, n) // the fold of every row
+	next := 0
+	for _, rows := range classes {
+		shuffle(rows, c)
+		for i, row := range rows {
+			if c.noShuffle {
+				// contiguous folds, as long as the first rows have the lowest folds
+				fold[row] = (next + i*k/len(rows)) % k
+				continue
+			}
+			fold[row] = (next + i) % k
+		}
+		if !c.noShuffle {
+			next = (next + len(rows)) % k
+		}
+	}
+	retVal := make([]Fold, k)
+	for row, f := range fold {
+		for i := range retVal {
+			if i == f {
+				retVal[i].Val = append(retVal[i].Val, row)
+			} else {
+				retVal[i].Train = append(retVal[i].Train, row)
+			}
+		}
+	}
+	return retVal, nil
+}
+
+// byClass returns the rows of every class, in the order of the classes.
+func byClass(labels []int) ([][]int, error) {
+	if len(labels) == 0 {
+		return nil, errors.New("Expected labels")
+	}
+	index := make(map[int]int)
+	var classes []int
+	for _, l := range labels {
+		if _, ok := index[l]; !ok {
+			index[l] = 0
+			classes = append(classes, l)
+		}
+	}
+	sort.Ints(classes)
+	for i, l := range classes {
+		index[l] = i
+	}
+	retVal := make([][]int, len(classes))
+	for row, l := range labels {
+		retVal[index[l]] = append(retVal[index[l]], row)
+	}
+	return retVal, nil
+}
+
+func intRange(n int) []int {
+	retVal := make([]int, n)
+	for i := range retVal {
+		retVal[i] = i
+	}
+	return retVal
+}
+
+func shuffle(rows []int, c config) {
+	if c.noShuffle {
+		return
+	}
+	c.rand.Shuffle(len(rows), func(i, j int) { rows[i], rows[j] = rows[j], rows[i] })
+}
+
+// Rows returns a new tensor holding the rows of x (its slices along the first axis) at the indices, in order.
+func Rows(x *tensor.Dense, indices []int) (*tensor.Dense, error) {
+	if x == nil || x.Dims() == 0 {
+		return nil, errors.New("Expected a tensor of at least one dimension")
+	}
+	if len(indices) == 0 {
+		return nil, errors.New("Expected the indices of at least one row")
+	}
+	x = tensor.Materialize(x).(*tensor.Dense)
+	n := x.Shape()[0]
+	if n == 0 {
+		return nil, errors.Errorf("Expected a non-empty tensor. Got %v instead", x.Shape())
+	}
+	stride := x.Shape().TotalSize() / n
+	src := backing(x)
+	data := reflect.MakeSlice(src.Type(), len(indices)*stride, len(indices)*stride)
+	for i, idx := range indices {
+		if idx < 0 || idx >= n {
+			return nil, errors.Errorf("Row %d is out of range for shape %v", idx, x.Shape())
+		}
+		reflect.Copy(data.Slice(i*stride, (i+1)*stride), src.Slice(idx*stride, (idx+1)*stride))
+	}
+	shape := append(tensor.Shape{len(indices)}, x.Shape()[1:]...)
+	return tensor.New(tensor.WithShape(shape...), tensor.WithBacking(data.Interface())), nil
+}
+
+// backing returns the data of the tensor as a slice, including that of a tensor of shape (1).
+func backing(t *tensor.Dense) reflect.Value {
+	v := reflect.ValueOf(t.Data())
+	if v.Kind() == reflect.Slice {
+		return v
+	}
+	s := reflect.MakeSlice(reflect.SliceOf(v.Type()), 1, 1)
+	s.Index(0).Set(v)
+	return s
+}
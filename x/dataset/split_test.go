@@ -0,0 +1,141 @@
+package dataset
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorgonia.org/tensor"
+)
+
+// partition checks that the parts are sorted and cover the n rows once.
+func partition(t *testing.T, n int, parts ...[]int) {
+	var all []int
+	for _, p := range parts {
+		assert.True(t, sort.IntsAreSorted(p))
+		all = append(all, p...)
+	}
+	sort.Ints(all)
+	assert.Equal(t, intRange(n), all)
+}
+
+func countLabels(labels []int, rows []int) map[int]int {
+	retVal := make(map[int]int)
+	for _, r := range rows {
+		retVal[labels[r]]++
+	}
+	return retVal
+}
+
+func TestTrainValTestSplit(t *testing.T) {
+	assert := assert.New(t)
+	s, err := TrainValTestSplit(100, 0.2, 0.1, WithSeed(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(s.Train, 70)
+	assert.Len(s.Val, 20)
+	assert.Len(s.Test, 10)
+	partition(t, 100, s.Train, s.Val, s.Test)
+	assert.NotEqual(intRange(70), s.Train, "shuffled")
+
+	again, _ := TrainValTestSplit(100, 0.2, 0.1, WithSeed(1))
+	assert.Equal(s, again)
+
+	ordered, err := TrainValTestSplit(10, 0.2, 0.2, WithoutShuffle())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(Split{Train: intRange(6), Val: []int{6, 7}, Test: []int{8, 9}}, ordered)
+
+	_, err = TrainValTestSplit(10, 0.5, 0.5)
+	assert.Error(err)
+	_, err = TrainValTestSplit(0, 0.1, 0.1)
+	assert.Error(err)
+}
+
+func TestStratifiedSplit(t *testing.T) {
+	assert := assert.New(t)
+	labels := make([]int, 100)
+	for i := range labels {
+		if i%5 == 0 {
+			labels[i] = 1 // 20%
+		}
+	}
+	s, err := StratifiedSplit(labels, 0.25, 0.25, WithSeed(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	partition(t, 100, s.Train, s.Val, s.Test)
+	assert.Equal(map[int]int{0: 40, 1: 10}, countLabels(labels, s.Train))
+	assert.Equal(map[int]int{0: 20, 1: 5}, countLabels(labels, s.Val))
+	assert.Equal(map[int]int{0: 20, 1: 5}, countLabels(labels, s.Test))
+
+	_, err = StratifiedSplit(nil, 0.1, 0.1)
+	assert.Error(err)
+}
+
+func TestKFold(t *testing.T) {
+	assert := assert.New(t)
+	folds, err := KFold(10, 3, WithSeed(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(folds, 3)
+	var vals [][]int
+	for _, f := range folds {
+		partition(t, 10, f.Train, f.Val)
+		assert.True(len(f.Val) == 3 || len(f.Val) == 4)
+		vals = append(vals, f.Val)
+	}
+	partition(t, 10, vals...)
+
+	ordered, err := KFold(6, 3, WithoutShuffle())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]int{0, 1}, ordered[0].Val)
+	assert.Equal([]int{2, 3, 4, 5}, ordered[0].Train)
+	assert.Equal([]int{4, 5}, ordered[2].Val)
+
+	_, err = KFold(3, 4)
+	assert.Error(err)
+	_, err = KFold(3, 1)
+	assert.Error(err)
+}
+
+func TestStratifiedKFold(t *testing.T) {
+	assert := assert.New(t)
+	labels := []int{0, 0, 0, 0, 0, 0, 1, 1, 1, 2, 2, 2}
+	folds, err := StratifiedKFold(labels, 3, WithSeed(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range folds {
+		partition(t, len(labels), f.Train, f.Val)
+		assert.Equal(map[int]int{0: 2, 1: 1, 2: 1}, countLabels(labels, f.Val))
+	}
+}
+
+func TestRows(t *testing.T) {
+	assert := assert.New(t)
+	x := tensor.New(tensor.WithShape(3, 2), tensor.WithBacking([]float32{0, 1, 2, 3, 4, 5}))
+	rows, err := Rows(x, []int{2, 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(tensor.Shape{2, 2}, rows.Shape())
+	assert.Equal([]float32{4, 5, 0, 1}, rows.Data())
+
+	labels := tensor.New(tensor.WithBacking([]int{7, 8, 9}))
+	if rows, err = Rows(labels, []int{1}); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(tensor.Shape{1}, rows.Shape())
+	assert.Equal(8, rows.Data())
+
+	_, err = Rows(x, []int{3})
+	assert.Error(err)
+	_, err = Rows(x, nil)
+	assert.Error(err)
+}
@@ -0,0 +1,77 @@
+package dataset
+
+import (
+	"math"
+
+	"github.com/pkg/errors"
+)
+
+// ClassCounts returns the number of rows of each of the k classes. The labels must be in [0, k).
+func ClassCounts(labels []int, k int) ([]int, error) {
+	if k < 1 {
+		return nil, errors.Errorf("Expected a positive number of classes. Got %d instead", k)
+	}
+	retVal := make([]int, k)
+	for row, l := range labels {
+		if l < 0 || l >= k {
+			return nil, errors.Errorf("The label %d of row %d is out of the range of %d classes", l, row, k)
+		}
+		retVal[l]++
+	}
+	return retVal, nil
+}
+
+// BalancedClassWeights returns the weights of the k classes that give every class the same total weight: n/(k·nᵢ)
+// for a class of nᵢ of the n rows, so that the mean weight of the rows is 1. A class without rows has a weight of 0.
+func BalancedClassWeights(labels []int, k int) ([]float64, error) {
+	counts, err := ClassCounts(labels, k)
+	if err != nil {
+		return nil, err
+	}
+	retVal := make([]float64, k)
+	for i, c := range counts {
+		if c > 0 {
+			retVal[i] = float64(len(labels)) / float64(k*c)
+		}
+	}
+	return retVal, nil
+}
+
+// EffectiveNumberClassWeights returns the weights of the k classes of Cui et al. (2019), the inverses of the effective
+// numbers of rows (1-βⁿ)/(1-β), which grow slower than the numbers of rows as the rows of a class overlap. β in [0, 1)
+// goes from no weighting to the inverse frequencies, 0.999 being usual. The weights of the classes with rows total k.
+func EffectiveNumberClassWeights(labels []int, k int, beta float64) ([]float64, error) {
+	if beta < 0 || beta >= 1 {
+		return nil, errors.Errorf("Expected β in [0, 1). Got %v instead", beta)
+	}
+	counts, err := ClassCounts(labels, k)
+	if err != nil {
+		return nil, err
+	}
+	retVal := make([]float64, k)
+	var total float64
+	var present int
+	for i, c := range counts {
+		if c > 0 {
+			retVal[i] = (1 - beta) / (1 - math.Pow(beta, float64(c)))
+			total += retVal[i]
+			present++
+		}
+	}
+	for i := range retVal {
+		retVal[i] *= float64(present) / total
+	}
+	return retVal, nil
+}
+
+// SampleWeights returns the weight of every row, the weight of its class, as the weights of a WeightedSampler.
+func SampleWeights(labels []int, classWeights []float64) ([]float64, error) {
+	retVal := make([]float64, len(labels))
+	for row, l := range labels {
+		if l < 0 || l >= len(classWeights) {
+			return nil, errors.Errorf("The label %d of row %d has no class weight", l, row)
+		}
+		retVal[row] = classWeights[l]
+	}
+	return retVal, nil
+}
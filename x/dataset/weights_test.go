@@ -0,0 +1,56 @@
+package dataset
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassWeights(t *testing.T) {
+	assert := assert.New(t)
+	labels := []int{0, 0, 0, 0, 0, 0, 1, 1, 0, 1}
+
+	counts, err := ClassCounts(labels, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]int{7, 3, 0}, counts)
+
+	balanced, err := BalancedClassWeights(labels, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.InDeltaSlice([]float64{10.0 / 14, 10.0 / 6}, balanced, 1e-12)
+	rows, err := SampleWeights(labels, balanced)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var total float64
+	for _, w := range rows {
+		total += w
+	}
+	assert.InDelta(10, total, 1e-12, "the mean weight of the rows is 1")
+
+	effective, err := EffectiveNumberClassWeights(labels, 3, 0.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the effective numbers are (1-0.5⁷)/0.5 and (1-0.5³)/0.5
+	e0, e1 := 1/(2*(1-1.0/128)), 1/(2*(1-1.0/8))
+	assert.InDeltaSlice([]float64{2 * e0 / (e0 + e1), 2 * e1 / (e0 + e1), 0}, effective, 1e-12)
+	uniform, err := EffectiveNumberClassWeights(labels, 2, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]float64{1, 1}, uniform)
+
+	// errors
+	_, err = ClassCounts(labels, 1)
+	assert.Error(err)
+	_, err = BalancedClassWeights(labels, 0)
+	assert.Error(err)
+	_, err = EffectiveNumberClassWeights(labels, 2, 1)
+	assert.Error(err)
+	_, err = SampleWeights(labels, []float64{1})
+	assert.Error(err)
+}
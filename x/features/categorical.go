@@ -0,0 +1,132 @@
+package features
+
+import (
+	"sort"
+
+	"github.com/pkg/errors"
+	"gorgonia.org/tensor"
+)
+
+// Categorical encodes a categorical column into indices or one-hot rows. The categories are sorted, so that the
+// encoding does not depend on the order of the training rows.
+type Categorical struct {
+	categories []string
+	index      map[string]int
+	unknown    bool
+}
+
+// CategoricalOpt is an option of a Categorical encoder.
+type CategoricalOpt func(*Categorical)
+
+// WithUnknown makes the encoder accept the values that are not among its categories: they are encoded as the
+// index len(Categories()), and as a row of zeroes in one-hot encodings. Without it, they are errors.
+func WithUnknown() CategoricalOpt {
+	return func(c *Categorical) { c.unknown = true }
+}
+
+// FitCategorical creates an encoder of the distinct values of a column.
+func FitCategorical(values []string, opts ...CategoricalOpt) (*Categorical, error) {
+	if len(values) == 0 {
+		return nil, errors.New("Cannot fit a categorical encoder on an empty column")
+	}
+	seen := make(map[string]struct{})
+	var categories []string
+	for _, v := range values {
+		if _, ok := seen[v]; !ok {
+			seen[v] = struct{}{}
+			categories = append(categories, v)
+		}
+	}
+	sort.Strings(categories)
+	return NewCategorical(categories, opts...)
+}
+
+// NewCategorical creates an encoder of the given categories, in that order.
+func NewCategorical(categories []string, opts ...CategoricalOpt) (*Categorical, error) {
+	if len(categories) == 0 {
+		return nil, errors.New("A categorical encoder needs at least one category")
+	}
+	c := &Categorical{
+		categories: append([]string(nil), categories...),
+		index:      make(map[string]int, len(categories)),
+	}
+	for i, cat := range categories {
+		if _, ok := c.index[cat]; ok {
+			return nil, errors.Errorf("Duplicate category %q", cat)
+		}
+		c.index[cat] = i
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+// Categories returns the categories, by index.
+func (c *Categorical) Categories() []string { return c.categories }
+
+// Cardinality returns the number of distinct indices of the encoding: the number of categories, plus one for the
+// unknown values if they are accepted. It is the size of an embedding table of the indices.
+func (c *Categorical) Cardinality() int {
+	if c.unknown {
+		return len(c.categories) + 1
+	}
+	return len(c.categories)
+}
+
+// Index returns the index of a value.
+func (c *Categorical) Index(v string) (int, error) {
+	if i, ok := c.index[v]; ok {
+		return i, nil
+	}
+	if c.unknown {
+		return len(c.categories), nil
+	}
+	return -1, errors.Errorf("Unknown category %q", v)
+}
+
+// Indices encodes a column into a vector of Int indices.
+func (c *Categorical) Indices(values []string) (tensor.Tensor, error) {
+	indices := make([]int, len(values))
+	for i, v := range values {
+		var err error
+		if indices[i], err = c.Index(v); err != nil {
+			return nil, errors.Wrapf(err, "Failed to encode row %d", i)
+		}
+	}
+	return tensor.New(tensor.WithShape(len(values)), tensor.WithBacking(indices)), nil
+}
+
+// OneHot encodes a column into a (len(values), len(Categories())) matrix of the dtype, whose rows are one-hot.
+func (c *Categorical) OneHot(values []string, dt tensor.Dtype) (tensor.Tensor, error) {
+	if err := checkFloatDtype(dt); err != nil {
+		return nil, err
+	}
+	k := len(c.categories)
+	data := make([]float64, len(values)*k)
+	for i, v := range values {
+		j, err := c.Index(v)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Failed to encode row %d", i)
+		}
+		if j < k {
+			data[i*k+j] = 1
+		}
+	}
+	return newFloats(dt, tensor.Shape{len(values), k}, data), nil
+}
+
+// Decode returns the categories of indices. The unknown index decodes to "".
+func (c *Categorical) Decode(indices []int) ([]string, error) {
+	retVal := make([]string, len(indices))
+	for i, j := range indices {
+		switch {
+		case j >= 0 && j < len(c.categories):
+			retVal[i] = c.categories[j]
+		case c.unknown && j == len(c.categories):
+		default:
+			return nil, errors.Errorf("Index %d of row %d is out of range [0, %d)", j, i, c.Cardinality())
+		}
+	}
+	return retVal, nil
+}
@@ -0,0 +1,86 @@
+package features
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorgonia.org/tensor"
+)
+
+func TestCategorical(t *testing.T) {
+	assert := assert.New(t)
+	c, err := FitCategorical([]string{"red", "blue", "red", "green"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]string{"blue", "green", "red"}, c.Categories())
+	assert.Equal(3, c.Cardinality())
+
+	indices, err := c.Indices([]string{"red", "blue", "green"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(tensor.Shape{3}, indices.Shape())
+	assert.Equal([]int{2, 0, 1}, indices.Data())
+
+	oneHot, err := c.OneHot([]string{"green", "red"}, tensor.Float32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(tensor.Shape{2, 3}, oneHot.Shape())
+	assert.Equal([]float32{0, 1, 0, 0, 0, 1}, oneHot.Data())
+
+	decoded, err := c.Decode([]int{2, 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]string{"red", "blue"}, decoded)
+
+	_, err = c.Indices([]string{"purple"})
+	assert.Error(err)
+	_, err = c.OneHot([]string{"purple"}, tensor.Float64)
+	assert.Error(err)
+	_, err = c.OneHot([]string{"red"}, tensor.Int)
+	assert.Error(err)
+	_, err = c.Decode([]int{3})
+	assert.Error(err)
+}
+
+func TestCategorical_unknown(t *testing.T) {
+	assert := assert.New(t)
+	c, err := NewCategorical([]string{"b", "a"}, WithUnknown())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(3, c.Cardinality())
+	i, err := c.Index("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(1, i, "the given order is kept")
+
+	indices, err := c.Indices([]string{"c", "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]int{2, 0}, indices.Data())
+
+	oneHot, err := c.OneHot([]string{"c", "a"}, tensor.Float64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]float64{0, 0, 0, 1}, oneHot.Data())
+
+	decoded, err := c.Decode([]int{2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]string{""}, decoded)
+
+	_, err = NewCategorical([]string{"a", "a"})
+	assert.Error(err)
+	_, err = NewCategorical(nil)
+	assert.Error(err)
+	_, err = FitCategorical(nil)
+	assert.Error(err)
+}
@@ -0,0 +1,114 @@
+package features
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/pkg/errors"
+	"gorgonia.org/tensor"
+)
+
+// Period is a cycle of the calendar that a timestamp is encoded against.
+type Period byte
+
+const (
+	MinuteOfHour Period = iota
+	HourOfDay
+	DayOfWeek
+	DayOfMonth
+	DayOfYear
+	MonthOfYear
+)
+
+func (p Period) String() string {
+	switch p {
+	case MinuteOfHour:
+		return "MinuteOfHour"
+	case HourOfDay:
+		return "HourOfDay"
+	case DayOfWeek:
+		return "DayOfWeek"
+	case DayOfMonth:
+		return "DayOfMonth"
+	case DayOfYear:
+		return "DayOfYear"
+	case MonthOfYear:
+		return "MonthOfYear"
+	}
+	return fmt.Sprintf("Period(%d)", byte(p))
+}
+
+// phase returns the position of a timestamp within the period, in [0, 1). The position is continuous: 12:30 is halfway
+// between 12:00 and 13:00 on the HourOfDay cycle.
+func (p Period) phase(t time.Time) (float64, error) {
+	h, m, s := t.Clock()
+	seconds := float64(s) + float64(t.Nanosecond())/1e9
+	minutes := float64(m) + seconds/60
+	hours := float64(h) + minutes/60
+	days := float64(t.Day()-1) + hours/24
+	switch p {
+	case MinuteOfHour:
+		return minutes / 60, nil
+	case HourOfDay:
+		return hours / 24, nil
+	case DayOfWeek:
+		return (float64(t.Weekday()) + hours/24) / 7, nil
+	case DayOfMonth:
+		return days / float64(daysIn(t.Month(), t.Year())), nil
+	case DayOfYear:
+		yearDays := 365.0
+		if daysIn(time.February, t.Year()) == 29 {
+			yearDays = 366
+		}
+		return (float64(t.YearDay()-1) + hours/24) / yearDays, nil
+	case MonthOfYear:
+		return (float64(t.Month()-1) + days/float64(daysIn(t.Month(), t.Year()))) / 12, nil
+	}
+	return 0, errors.Errorf("Unknown period %v", p)
+}
+
+// daysIn returns the number of days of a month.
+func daysIn(m time.Month, year int) int {
+	return time.Date(year, m+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// Cyclical encodes timestamps into a (len(ts), 2·len(periods)) matrix of the dtype. Every period contributes two
+// columns, the sine and the cosine of the timestamp's phase in the period, so that the ends of a cycle (23:59 and
+// 00:00, December and January) are encoded close to each other. The timestamps are taken in their own location.
+func Cyclical(ts []time.Time, dt tensor.Dtype, periods ...Period) (tensor.Tensor, error) {
+	if err := checkFloatDtype(dt); err != nil {
+		return nil, err
+	}
+	if len(periods) == 0 {
+		return nil, errors.New("Expected at least one period")
+	}
+	cols := 2 * len(periods)
+	data := make([]float64, len(ts)*cols)
+	for i, t := range ts {
+		for j, p := range periods {
+			phase, err := p.phase(t)
+			if err != nil {
+				return nil, err
+			}
+			data[i*cols+2*j], data[i*cols+2*j+1] = math.Sincos(2 * math.Pi * phase)
+		}
+	}
+	return newFloats(dt, tensor.Shape{len(ts), cols}, data), nil
+}
+
+// CyclicalValues encodes values of a cycle of the given period, such as angles or hours, into a (len(values), 2)
+// matrix of the sine and cosine of their phase.
+func CyclicalValues(values []float64, period float64, dt tensor.Dtype) (tensor.Tensor, error) {
+	if err := checkFloatDtype(dt); err != nil {
+		return nil, err
+	}
+	if period <= 0 {
+		return nil, errors.Errorf("Expected a positive period. Got %v instead", period)
+	}
+	data := make([]float64, 2*len(values))
+	for i, v := range values {
+		data[2*i], data[2*i+1] = math.Sincos(2 * math.Pi * v / period)
+	}
+	return newFloats(dt, tensor.Shape{len(values), 2}, data), nil
+}
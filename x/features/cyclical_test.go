@@ -0,0 +1,88 @@
+package features
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorgonia.org/tensor"
+)
+
+func TestCyclical(t *testing.T) {
+	assert := assert.New(t)
+	ts := []time.Time{
+		time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC),    // Wednesday, midnight
+		time.Date(2020, time.March, 16, 18, 0, 0, 0, time.UTC),    // Monday, 18:00
+		time.Date(2021, time.December, 31, 12, 0, 0, 0, time.UTC), // Friday, noon
+	}
+	x, err := Cyclical(ts, tensor.Float64, HourOfDay, DayOfWeek, MonthOfYear)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(tensor.Shape{3, 6}, x.Shape())
+	data := x.Data().([]float64)
+
+	// midnight: sin 0, cos 1
+	assert.InDelta(0, data[0], 1e-12)
+	assert.InDelta(1, data[1], 1e-12)
+	// 18:00 is three quarters of a day
+	assert.InDelta(-1, data[6], 1e-12)
+	assert.InDelta(0, data[7], 1e-12)
+	// noon
+	assert.InDelta(0, data[12], 1e-12)
+	assert.InDelta(-1, data[13], 1e-12)
+
+	// Wednesday and Monday 18:00
+	assert.InDelta(math.Sin(2*math.Pi*3/7), data[2], 1e-12)
+	assert.InDelta(math.Cos(2*math.Pi*(1+0.75)/7), data[9], 1e-12)
+
+	// the last day of December is close to January
+	assert.InDelta(1, data[5], 1e-12)
+	assert.True(data[17] > 0.99)
+
+	for _, p := range []Period{MinuteOfHour, DayOfMonth, DayOfYear} {
+		phase, err := p.phase(ts[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(0.0, phase, p.String())
+	}
+	phase, _ := DayOfYear.phase(time.Date(2020, time.July, 2, 0, 0, 0, 0, time.UTC))
+	assert.Equal(183.0/366, phase, "2020 is a leap year")
+	phase, _ = DayOfMonth.phase(time.Date(2021, time.February, 15, 0, 0, 0, 0, time.UTC))
+	assert.Equal(0.5, phase)
+	phase, _ = MinuteOfHour.phase(time.Date(2021, time.February, 15, 0, 45, 0, 0, time.UTC))
+	assert.Equal(0.75, phase)
+
+	f32, err := Cyclical(ts[:1], tensor.Float32, HourOfDay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]float32{0, 1}, f32.Data())
+
+	_, err = Cyclical(ts, tensor.Float64)
+	assert.Error(err)
+	_, err = Cyclical(ts, tensor.Int, HourOfDay)
+	assert.Error(err)
+	_, err = Cyclical(ts, tensor.Float64, Period(42))
+	assert.Error(err)
+	assert.Equal("Period(42)", Period(42).String())
+}
+
+func TestCyclicalValues(t *testing.T) {
+	assert := assert.New(t)
+	x, err := CyclicalValues([]float64{0, 90, 180}, 360, tensor.Float64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(tensor.Shape{3, 2}, x.Shape())
+	data := x.Data().([]float64)
+	for i, want := range []float64{0, 1, 1, 0, 0, -1} {
+		assert.InDelta(want, data[i], 1e-12)
+	}
+	_, err = CyclicalValues([]float64{1}, 0, tensor.Float64)
+	assert.Error(err)
+	_, err = CyclicalValues([]float64{1}, 1, tensor.Bool)
+	assert.Error(err)
+}
@@ -0,0 +1,7 @@
+// Package features provides feature encoders for tabular data: categorical string columns are encoded into indices,
+// one-hot rows or target means, and timestamps into cyclical features. The encoders emit tensors that are ready to be
+// bound to the inputs of a graph.
+//
+// The encoders follow the fit/transform convention: an encoder is fitted on the training column, then transforms any
+// column, so that the training and inference data are encoded the same way.
+package features
@@ -0,0 +1,72 @@
+package features
+
+import (
+	"github.com/pkg/errors"
+	"gorgonia.org/tensor"
+)
+
+// TargetEncoder encodes a categorical column into the mean target of each category.
+//
+// The means are smoothed towards the mean of all the targets (the prior): a category seen n times is encoded as
+//		(n·mean + smoothing·prior) / (n + smoothing)
+// so that rare categories do not get extreme encodings. Unknown categories are encoded as the prior. Fitting and
+// transforming the same rows leaks the targets into the features; fit on held-out rows to avoid it.
+type TargetEncoder struct {
+	categories *Categorical
+	means      []float64
+	prior      float64
+}
+
+// FitTarget fits a target encoder on a categorical column and its targets.
+func FitTarget(values []string, targets []float64, smoothing float64) (*TargetEncoder, error) {
+	if len(values) != len(targets) {
+		return nil, errors.Errorf("Expected as many targets as values. Got %d targets for %d values", len(targets), len(values))
+	}
+	if smoothing < 0 {
+		return nil, errors.Errorf("Expected a non-negative smoothing. Got %v instead", smoothing)
+	}
+	categories, err := FitCategorical(values, WithUnknown())
+	if err != nil {
+		return nil, err
+	}
+	sums := make([]float64, len(categories.categories))
+	counts := make([]float64, len(categories.categories))
+	var total float64
+	for i, v := range values {
+		j := categories.index[v]
+		sums[j] += targets[i]
+		counts[j]++
+		total += targets[i]
+	}
+	e := &TargetEncoder{categories: categories, means: make([]float64, len(sums)), prior: total / float64(len(values))}
+	for j := range e.means {
+		e.means[j] = (sums[j] + smoothing*e.prior) / (counts[j] + smoothing)
+	}
+	return e, nil
+}
+
+// Prior returns the mean of all the targets, that unknown categories are encoded as.
+func (e *TargetEncoder) Prior() float64 { return e.prior }
+
+// Categories returns the categories seen during the fit.
+func (e *TargetEncoder) Categories() []string { return e.categories.categories }
+
+// Encode returns the encoding of a value.
+func (e *TargetEncoder) Encode(v string) float64 {
+	if j, ok := e.categories.index[v]; ok {
+		return e.means[j]
+	}
+	return e.prior
+}
+
+// Transform encodes a column into a vector of the dtype.
+func (e *TargetEncoder) Transform(values []string, dt tensor.Dtype) (tensor.Tensor, error) {
+	if err := checkFloatDtype(dt); err != nil {
+		return nil, err
+	}
+	data := make([]float64, len(values))
+	for i, v := range values {
+		data[i] = e.Encode(v)
+	}
+	return newFloats(dt, tensor.Shape{len(values)}, data), nil
+}
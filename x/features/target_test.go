@@ -0,0 +1,48 @@
+package features
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorgonia.org/tensor"
+)
+
+func TestTargetEncoder(t *testing.T) {
+	assert := assert.New(t)
+	values := []string{"a", "a", "b", "b", "b", "c"}
+	targets := []float64{1, 0, 1, 1, 1, 0}
+	e, err := FitTarget(values, targets, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]string{"a", "b", "c"}, e.Categories())
+	assert.Equal(4.0/6, e.Prior())
+	assert.Equal(0.5, e.Encode("a"))
+	assert.Equal(1.0, e.Encode("b"))
+	assert.Equal(e.Prior(), e.Encode("z"), "unknown categories are encoded as the prior")
+
+	// with smoothing the rare categories are pulled towards the prior
+	e, err = FitTarget(values, targets, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	prior := 4.0 / 6
+	assert.InDelta((0+2*prior)/3, e.Encode("c"), 1e-12)
+	assert.InDelta((3+2*prior)/5, e.Encode("b"), 1e-12)
+
+	v, err := e.Transform([]string{"c", "z"}, tensor.Float32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(tensor.Shape{2}, v.Shape())
+	assert.Equal([]float32{float32((0 + 2*prior) / 3), float32(prior)}, v.Data())
+
+	_, err = e.Transform(values, tensor.Int)
+	assert.Error(err)
+	_, err = FitTarget(values, targets[:2], 0)
+	assert.Error(err)
+	_, err = FitTarget(values, targets, -1)
+	assert.Error(err)
+	_, err = FitTarget(nil, nil, 0)
+	assert.Error(err)
+}
@@ -0,0 +1,25 @@
+package features
+
+import (
+	"github.com/pkg/errors"
+	"gorgonia.org/tensor"
+)
+
+func checkFloatDtype(dt tensor.Dtype) error {
+	if dt != tensor.Float64 && dt != tensor.Float32 {
+		return errors.Errorf("Expected a Float64 or Float32 dtype. Got %v instead", dt)
+	}
+	return nil
+}
+
+// newFloats returns a tensor of the dtype and shape, holding the data.
+func newFloats(dt tensor.Dtype, shape tensor.Shape, data []float64) tensor.Tensor {
+	if dt == tensor.Float32 {
+		f32s := make([]float32, len(data))
+		for i, f := range data {
+			f32s[i] = float32(f)
+		}
+		return tensor.New(tensor.WithShape(shape.Clone()...), tensor.WithBacking(f32s))
+	}
+	return tensor.New(tensor.WithShape(shape.Clone()...), tensor.WithBacking(data))
+}
@@ -0,0 +1,5 @@
+// Package rl provides utilities for reinforcement learning with Gorgonia: discounted returns and generalized advantage
+// estimation as graph ops, and a replay buffer that samples batches of transitions as tensors.
+//
+// The ops treat time as the first axis. The other axes, if any, are independent streams, such as parallel environments.
+package rl
@@ -0,0 +1,193 @@
+package rl
+
+import (
+	"math/rand"
+	"reflect"
+	"time"
+
+	"github.com/pkg/errors"
+	"gorgonia.org/tensor"
+)
+
+// Field describes one of the fields of the records of a ReplayBuffer: for instance the states, of dtype Float64 and
+// shape (4), or the actions, of dtype Int and a scalar shape.
+type Field struct {
+	Name  string
+	Dtype tensor.Dtype
+	Shape tensor.Shape
+}
+
+// ReplayBuffer is a fixed capacity buffer of records, such as the transitions observed by an agent. Once the buffer is
+// full, new records replace the oldest ones. Sampling a batch of B records returns one tensor per field, of shape
+// (B, shape of the field...), ready to be bound to the inputs of a graph.
+//
+// The data of each field is stored in a single preallocated tensor of shape (capacity, shape of the field...).
+type ReplayBuffer struct {
+	fields  []Field
+	storage []tensor.Tensor
+	strides []int
+	cap     int
+	next    int
+	len     int
+	rand    *rand.Rand
+}
+
+// NewReplayBuffer creates a replay buffer holding up to capacity records of the given fields.
+func NewReplayBuffer(capacity int, fields ...Field) (*ReplayBuffer, error) {
+	if capacity <= 0 {
+		return nil, errors.Errorf("Expected a positive capacity. Got %d instead", capacity)
+	}
+	if len(fields) == 0 {
+		return nil, errors.New("Expected at least one field")
+	}
+	b := &ReplayBuffer{
+		fields: fields,
+		cap:    capacity,
+		rand:   rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+	for _, f := range fields {
+		shape := append(tensor.Shape{capacity}, f.Shape...)
+		b.storage = append(b.storage, tensor.New(tensor.WithShape(shape...), tensor.Of(f.Dtype)))
+		b.strides = append(b.strides, rowSize(f.Shape))
+	}
+	return b, nil
+}
+
+// NewTransitionBuffer creates a replay buffer of (state, action, reward, next state, done) transitions, with discrete
+// actions. The fields are named "state", "action", "reward", "next_state" and "done".
+func NewTransitionBuffer(capacity int, stateShape tensor.Shape, dt tensor.Dtype) (*ReplayBuffer, error) {
+	return NewReplayBuffer(capacity,
+		Field{Name: "state", Dtype: dt, Shape: stateShape},
+		Field{Name: "action", Dtype: tensor.Int},
+		Field{Name: "reward", Dtype: dt},
+		Field{Name: "next_state", Dtype: dt, Shape: stateShape},
+		Field{Name: "done", Dtype: dt},
+	)
+}
+
+// SetRand sets the source of randomness of the sampling.
+func (b *ReplayBuffer) SetRand(r *rand.Rand) { b.rand = r }
+
+// Fields returns the fields of the records.
+func (b *ReplayBuffer) Fields() []Field { return b.fields }
+
+// Len returns the number of records in the buffer.
+func (b *ReplayBuffer) Len() int { return b.len }
+
+// Cap returns the capacity of the buffer.
+func (b *ReplayBuffer) Cap() int { return b.cap }
+
+// Add adds a record, with one value per field. Each value is either a tensor of the shape of the field, a slice of
+// the dtype of the field, or a single value of the dtype of the field for scalar fields.
+func (b *ReplayBuffer) Add(values ...interface{}) error {
+	if len(values) != len(b.fields) {
+		return errors.Errorf("Expected %d values. Got %d instead", len(b.fields), len(values))
+	}
+	rows := make([]reflect.Value, len(values))
+	for i, v := range values {
+		row, err := b.rowOf(i, v)
+		if err != nil {
+			return err
+		}
+		rows[i] = row
+	}
+	for i, row := range rows {
+		reflect.Copy(b.row(i, b.next), row)
+	}
+	b.next = (b.next + 1) % b.cap
+	if b.len < b.cap {
+		b.len++
+	}
+	return nil
+}
+
+// Sample returns batch records drawn uniformly at random, with replacement: one tensor per field.
+func (b *ReplayBuffer) Sample(batch int) ([]tensor.Tensor, error) {
+	if b.len == 0 {
+		return nil, errors.New("Cannot sample from an empty buffer")
+	}
+	indices := make([]int, batch)
+	for i := range indices {
+		indices[i] = b.rand.Intn(b.len)
+	}
+	return b.Gather(indices)
+}
+
+// Gather returns the records at the given indices, with 0 being the oldest record: one tensor per field.
+func (b *ReplayBuffer) Gather(indices []int) ([]tensor.Tensor, error) {
+	if len(indices) == 0 {
+		return nil, errors.New("Expected at least one index")
+	}
+	oldest := 0
+	if b.len == b.cap {
+		oldest = b.next
+	}
+	retVal := make([]tensor.Tensor, len(b.fields))
+	for i, f := range b.fields {
+		shape := append(tensor.Shape{len(indices)}, f.Shape...)
+		t := tensor.New(tensor.WithShape(shape...), tensor.Of(f.Dtype))
+		data := reflect.ValueOf(t.Data())
+		stride := b.strides[i]
+		for j, idx := range indices {
+			if idx < 0 || idx >= b.len {
+				return nil, errors.Errorf("Index %d is out of range: the buffer holds %d records", idx, b.len)
+			}
+			reflect.Copy(data.Slice(j*stride, (j+1)*stride), b.row(i, (oldest+idx)%b.cap))
+		}
+		retVal[i] = t
+	}
+	return retVal, nil
+}
+
+// Reset empties the buffer.
+func (b *ReplayBuffer) Reset() { b.next, b.len = 0, 0 }
+
+// row returns the storage of the given row of the field.
+func (b *ReplayBuffer) row(field, row int) reflect.Value {
+	stride := b.strides[field]
+	return reflect.ValueOf(b.storage[field].Data()).Slice(row*stride, (row+1)*stride)
+}
+
+// rowOf returns the value of a field as a slice of the field's dtype.
+func (b *ReplayBuffer) rowOf(field int, v interface{}) (reflect.Value, error) {
+	f := b.fields[field]
+	want := reflect.SliceOf(f.Dtype.Type)
+	var row reflect.Value
+	switch vt := v.(type) {
+	case tensor.Tensor:
+		if !vt.Shape().Eq(f.Shape) && !(f.Shape.IsScalar() && vt.Shape().TotalSize() == 1) {
+			return row, errors.Errorf("Expected %v of shape %v. Got %v instead", f.Name, f.Shape, vt.Shape())
+		}
+		if vt.RequiresIterator() {
+			vt = tensor.Materialize(vt)
+		}
+		row = reflect.ValueOf(vt.Data())
+		if row.Kind() != reflect.Slice {
+			row = reflect.Append(reflect.MakeSlice(want, 0, 1), row)
+		}
+	default:
+		row = reflect.ValueOf(v)
+		if row.Kind() != reflect.Slice {
+			if row.Type() != f.Dtype.Type {
+				return row, errors.Errorf("Expected %v to be a %v. Got %T instead", f.Name, f.Dtype, v)
+			}
+			row = reflect.Append(reflect.MakeSlice(want, 0, 1), row)
+		}
+	}
+	if row.Type() != want {
+		return row, errors.Errorf("Expected %v to be a %v. Got %v instead", f.Name, want, row.Type())
+	}
+	if row.Len() != b.strides[field] {
+		return row, errors.Errorf("Expected %d elements for %v. Got %d instead", b.strides[field], f.Name, row.Len())
+	}
+	return row, nil
+}
+
+// rowSize returns the number of elements of a record of the shape.
+func rowSize(s tensor.Shape) int {
+	size := 1
+	for _, d := range s {
+		size *= d
+	}
+	return size
+}
@@ -0,0 +1,66 @@
+package rl
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorgonia.org/tensor"
+)
+
+func TestReplayBuffer(t *testing.T) {
+	assert := assert.New(t)
+	_, err := NewReplayBuffer(0, Field{Name: "x", Dtype: tensor.Float64})
+	assert.Error(err)
+
+	b, err := NewTransitionBuffer(3, tensor.Shape{2}, tensor.Float32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.SetRand(rand.New(rand.NewSource(1)))
+	assert.Equal(5, len(b.Fields()))
+	_, err = b.Sample(2)
+	assert.Error(err)
+
+	for i := 0; i < 4; i++ {
+		f := float32(i)
+		state := tensor.New(tensor.WithBacking([]float32{f, -f}))
+		if err = b.Add(state, i, f, []float32{f + 1, -f - 1}, float32(0)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	assert.Equal(3, b.Len())
+	assert.Equal(3, b.Cap())
+
+	// the oldest record was replaced
+	got, err := b.Gather([]int{0, 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(tensor.Shape{2, 2}, got[0].Shape())
+	assert.Equal([]float32{1, -1, 3, -3}, got[0].Data())
+	assert.Equal([]int{1, 3}, got[1].Data())
+	assert.Equal([]float32{1, 3}, got[2].Data())
+	assert.Equal([]float32{2, -2, 4, -4}, got[3].Data())
+
+	sample, err := b.Sample(8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(tensor.Shape{8}, sample[1].Shape())
+	for i, a := range sample[1].Data().([]int) {
+		assert.True(a >= 1 && a <= 3)
+		assert.Equal(float32(a), sample[0].Data().([]float32)[2*i], "the fields of a record stay together")
+	}
+
+	// errors
+	assert.Error(b.Add(1, 2, 3))
+	assert.Error(b.Add([]float32{1}, 0, float32(0), []float32{1, 2}, float32(0)), "wrong number of elements")
+	assert.Error(b.Add([]float64{1, 2}, 0, float32(0), []float32{1, 2}, float32(0)), "wrong dtype")
+	assert.Error(b.Add([]float32{1, 2}, 0, 1.0, []float32{1, 2}, float32(0)), "wrong scalar dtype")
+	_, err = b.Gather([]int{3})
+	assert.Error(err)
+
+	b.Reset()
+	assert.Equal(0, b.Len())
+}
@@ -0,0 +1,259 @@
+package rl
+
+import (
+	"fmt"
+	"hash"
+
+	"github.com/chewxy/hm"
+	"github.com/pkg/errors"
+	"gorgonia.org/gorgonia"
+	"gorgonia.org/tensor"
+)
+
+// DiscountedReturns computes the discounted returns of a rollout of rewards:
+//		G[t] = rewards[t] + gamma·(1 - dones[t])·G[t+1]
+// where G[T] is bootstrap, the estimated value of the state following the rollout. rewards and dones have the shape
+// (T, streams...), and bootstrap has the shape (streams...); a nil bootstrap is 0. dones is 1 where an episode ends.
+//
+// The returns are targets, so no gradient flows through them.
+func DiscountedReturns(rewards, dones, bootstrap *gorgonia.Node, gamma float64) (*gorgonia.Node, error) {
+	if err := checkRollout(rewards, dones); err != nil {
+		return nil, err
+	}
+	if bootstrap == nil {
+		var err error
+		if bootstrap, err = zeroes(rewards); err != nil {
+			return nil, err
+		}
+	}
+	op := discountOp{gamma: gamma, d: rewards.Dims()}
+	return gorgonia.ApplyOp(op, rewards, dones, bootstrap)
+}
+
+// GAE computes the generalized advantage estimates of a rollout (Schulman et al., 2015 -
+// https://arxiv.org/abs/1506.02438):
+//		δ[t] = rewards[t] + gamma·(1 - dones[t])·values[t+1] - values[t]
+//		A[t] = δ[t] + gamma·lambda·(1 - dones[t])·A[t+1]
+// rewards and dones have the shape (T, streams...), and values has the shape (T+1, streams...): the last row holds the
+// estimated values of the states following the rollout. The returns used as targets of the value function are A + V.
+//
+// The advantages are treated as constants, so no gradient flows through them.
+func GAE(rewards, values, dones *gorgonia.Node, gamma, lambda float64) (*gorgonia.Node, error) {
+	if err := checkRollout(rewards, dones); err != nil {
+		return nil, err
+	}
+	if err := checkFloat(values); err != nil {
+		return nil, err
+	}
+	op := gaeOp{gamma: gamma, lambda: lambda, d: rewards.Dims()}
+	return gorgonia.ApplyOp(op, rewards, values, dones)
+}
+
+func checkRollout(rewards, dones *gorgonia.Node) error {
+	if err := checkFloat(rewards); err != nil {
+		return err
+	}
+	if rewards.Dims() < 1 {
+		return errors.Errorf("Expected the rewards to have a time axis. Got %v of shape %v instead", rewards, rewards.Shape())
+	}
+	if !rewards.Shape().Eq(dones.Shape()) {
+		return errors.Errorf("Expected the rewards and dones to have the same shape. Got %v and %v", rewards.Shape(), dones.Shape())
+	}
+	return nil
+}
+
+// zeroes returns a zero constant of the shape of a row of n.
+func zeroes(n *gorgonia.Node) (*gorgonia.Node, error) {
+	row := n.Shape()[1:]
+	if len(row) == 0 {
+		if n.Dtype() == tensor.Float32 {
+			return gorgonia.NewConstant(float32(0)), nil
+		}
+		return gorgonia.NewConstant(0.0), nil
+	}
+	return gorgonia.NewConstant(tensor.New(tensor.WithShape(row.Clone()...), tensor.Of(n.Dtype()))), nil
+}
+
+// rowType returns the type of a row of a tensor of d dims.
+func rowType(d int, a hm.Type) hm.Type {
+	if d == 1 {
+		return a
+	}
+	return gorgonia.TensorType{Dims: d - 1, Of: a}
+}
+
+// discountOp computes the discounted returns of a rollout.
+type discountOp struct {
+	gamma float64
+	d     int
+}
+
+func (op discountOp) Arity() int { return 3 }
+
+// discountOp has this type:
+//		discountOp :: (Floats a) ⇒ Tensor-d a → Tensor-d a → Tensor-(d-1) a → Tensor-d a
+func (op discountOp) Type() hm.Type {
+	a := hm.TypeVariable('a')
+	t := gorgonia.TensorType{Dims: op.d, Of: a}
+	return hm.NewFnType(t, t, rowType(op.d, a), t)
+}
+
+func (op discountOp) InferShape(inputs ...gorgonia.DimSizer) (tensor.Shape, error) {
+	if err := checkArity(op, len(inputs)); err != nil {
+		return nil, err
+	}
+	s, ok := inputs[0].(tensor.Shape)
+	if !ok {
+		return nil, errors.Errorf("Expected a shape. Got %v of %T instead", inputs[0], inputs[0])
+	}
+	if b, ok := inputs[2].(tensor.Shape); ok && !b.Eq(s[1:]) && !(b.IsScalar() && len(s) == 1) {
+		return nil, errors.Errorf("Expected the bootstrap values to have the shape %v. Got %v instead", s[1:], b)
+	}
+	return s.Clone(), nil
+}
+
+func (op discountOp) Do(inputs ...gorgonia.Value) (gorgonia.Value, error) {
+	if err := checkArity(op, len(inputs)); err != nil {
+		return nil, err
+	}
+	rewards, err := floatsOf(inputs[0])
+	if err != nil {
+		return nil, err
+	}
+	dones, err := floatsOf(inputs[1])
+	if err != nil {
+		return nil, err
+	}
+	next, err := rowOf(inputs[2])
+	if err != nil {
+		return nil, err
+	}
+
+	shape := inputs[0].Shape()
+	streams := len(rewards) / shape[0]
+	if len(next) != streams {
+		return nil, errors.Errorf("Expected %d bootstrap values. Got %d instead", streams, len(next))
+	}
+	retVal := make([]float64, len(rewards))
+	for t := shape[0] - 1; t >= 0; t-- {
+		for s := 0; s < streams; s++ {
+			i := t*streams + s
+			next[s] = rewards[i] + op.gamma*(1-dones[i])*next[s]
+			retVal[i] = next[s]
+		}
+	}
+	return newFloats(inputs[0].Dtype(), shape, retVal), nil
+}
+
+func (op discountOp) ReturnsPtr() bool     { return false }
+func (op discountOp) CallsExtern() bool    { return false }
+func (op discountOp) OverwritesInput() int { return -1 }
+
+func (op discountOp) WriteHash(h hash.Hash) {
+	fmt.Fprintf(h, "DiscountedReturns{%v, %d}", op.gamma, op.d)
+}
+
+func (op discountOp) Hashcode() uint32 { return simpleHash(op) }
+
+func (op discountOp) String() string { return fmt.Sprintf("DiscountedReturns{%v}", op.gamma) }
+
+func (op discountOp) DiffWRT(inputs int) []bool { return make([]bool, inputs) }
+
+func (op discountOp) SymDiff(inputs gorgonia.Nodes, output, grad *gorgonia.Node) (gorgonia.Nodes, error) {
+	return nil, errors.Errorf("%v is a non-differentiable function", op)
+}
+
+// gaeOp computes the generalized advantage estimates of a rollout.
+type gaeOp struct {
+	gamma, lambda float64
+	d             int
+}
+
+func (op gaeOp) Arity() int { return 3 }
+
+// gaeOp has this type:
+//		gaeOp :: (Floats a) ⇒ Tensor-d a → Tensor-d a → Tensor-d a → Tensor-d a
+func (op gaeOp) Type() hm.Type {
+	t := gorgonia.TensorType{Dims: op.d, Of: hm.TypeVariable('a')}
+	return hm.NewFnType(t, t, t, t)
+}
+
+func (op gaeOp) InferShape(inputs ...gorgonia.DimSizer) (tensor.Shape, error) {
+	if err := checkArity(op, len(inputs)); err != nil {
+		return nil, err
+	}
+	s, ok1 := inputs[0].(tensor.Shape)
+	v, ok2 := inputs[1].(tensor.Shape)
+	if !ok1 || !ok2 {
+		return nil, errors.Errorf("Expected shapes. Got %v and %v instead", inputs[0], inputs[1])
+	}
+	if len(v) != len(s) || v[0] != s[0]+1 || !v[1:].Eq(s[1:]) {
+		return nil, errors.Errorf("Expected the values to have one more row than the rewards %v. Got %v instead", s, v)
+	}
+	return s.Clone(), nil
+}
+
+func (op gaeOp) Do(inputs ...gorgonia.Value) (gorgonia.Value, error) {
+	if err := checkArity(op, len(inputs)); err != nil {
+		return nil, err
+	}
+	var data [3][]float64
+	for i := range data {
+		var err error
+		if data[i], err = floatsOf(inputs[i]); err != nil {
+			return nil, err
+		}
+	}
+	rewards, values, dones := data[0], data[1], data[2]
+
+	shape := inputs[0].Shape()
+	streams := len(rewards) / shape[0]
+	if len(values) != len(rewards)+streams {
+		return nil, errors.Errorf("Expected %d values. Got %d instead", len(rewards)+streams, len(values))
+	}
+	retVal := make([]float64, len(rewards))
+	adv := make([]float64, streams)
+	for t := shape[0] - 1; t >= 0; t-- {
+		for s := 0; s < streams; s++ {
+			i := t*streams + s
+			notDone := 1 - dones[i]
+			delta := rewards[i] + op.gamma*notDone*values[i+streams] - values[i]
+			adv[s] = delta + op.gamma*op.lambda*notDone*adv[s]
+			retVal[i] = adv[s]
+		}
+	}
+	return newFloats(inputs[0].Dtype(), shape, retVal), nil
+}
+
+func (op gaeOp) ReturnsPtr() bool     { return false }
+func (op gaeOp) CallsExtern() bool    { return false }
+func (op gaeOp) OverwritesInput() int { return -1 }
+
+func (op gaeOp) WriteHash(h hash.Hash) {
+	fmt.Fprintf(h, "GAE{%v, %v, %d}", op.gamma, op.lambda, op.d)
+}
+
+func (op gaeOp) Hashcode() uint32 { return simpleHash(op) }
+
+func (op gaeOp) String() string { return fmt.Sprintf("GAE{%v, %v}", op.gamma, op.lambda) }
+
+func (op gaeOp) DiffWRT(inputs int) []bool { return make([]bool, inputs) }
+
+func (op gaeOp) SymDiff(inputs gorgonia.Nodes, output, grad *gorgonia.Node) (gorgonia.Nodes, error) {
+	return nil, errors.Errorf("%v is a non-differentiable function", op)
+}
+
+// rowOf returns a copy of the data of a row value, which is a scalar for one dimensional rollouts.
+func rowOf(v gorgonia.Value) ([]float64, error) {
+	switch s := v.(type) {
+	case *gorgonia.F64:
+		return []float64{float64(*s)}, nil
+	case *gorgonia.F32:
+		return []float64{float64(*s)}, nil
+	}
+	data, err := floatsOf(v)
+	if err != nil {
+		return nil, err
+	}
+	return append([]float64(nil), data...), nil
+}
@@ -0,0 +1,96 @@
+package rl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorgonia.org/gorgonia"
+	"gorgonia.org/tensor"
+)
+
+func vec(g *gorgonia.ExprGraph, name string, data ...float64) *gorgonia.Node {
+	return gorgonia.NewVector(g, tensor.Float64, gorgonia.WithShape(len(data)), gorgonia.WithName(name), gorgonia.WithValue(tensor.New(tensor.WithBacking(data))))
+}
+
+func run(t *testing.T, g *gorgonia.ExprGraph, outs ...*gorgonia.Node) []gorgonia.Value {
+	vals := make([]gorgonia.Value, len(outs))
+	for i, n := range outs {
+		gorgonia.Read(n, &vals[i])
+	}
+	m := gorgonia.NewTapeMachine(g)
+	defer m.Close()
+	if err := m.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+	return vals
+}
+
+func TestDiscountedReturns(t *testing.T) {
+	assert := assert.New(t)
+	g := gorgonia.NewGraph()
+	rewards := vec(g, "rewards", 1, 1, 1, 1)
+	dones := vec(g, "dones", 0, 1, 0, 0)
+	bootstrap := gorgonia.NewScalar(g, tensor.Float64, gorgonia.WithName("bootstrap"), gorgonia.WithValue(10.0))
+
+	ret, err := DiscountedReturns(rewards, dones, nil, 0.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	boot, err := DiscountedReturns(rewards, dones, bootstrap, 0.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// two streams, along the second axis
+	r2 := gorgonia.NewMatrix(g, tensor.Float32, gorgonia.WithShape(2, 2), gorgonia.WithName("r2"), gorgonia.WithValue(tensor.New(tensor.WithShape(2, 2), tensor.WithBacking([]float32{1, 2, 3, 4}))))
+	d2 := gorgonia.NewMatrix(g, tensor.Float32, gorgonia.WithShape(2, 2), gorgonia.WithName("d2"), gorgonia.WithValue(tensor.New(tensor.WithShape(2, 2), tensor.WithBacking([]float32{0, 1, 0, 0}))))
+	ret2, err := DiscountedReturns(r2, d2, nil, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vals := run(t, g, ret, boot, ret2)
+	assert.Equal([]float64{1.5, 1, 1.5, 1}, vals[0].Data())
+	assert.Equal([]float64{1.5, 1, 4, 6}, vals[1].Data())
+	assert.Equal([]float32{4, 2, 3, 4}, vals[2].Data())
+
+	_, err = DiscountedReturns(rewards, vec(g, "short", 0, 0), nil, 0.5)
+	assert.Error(err)
+	_, err = DiscountedReturns(rewards, dones, vec(g, "badboot", 1, 2), 0.5)
+	assert.Error(err)
+
+	// no gradient flows through the returns
+	_, err = gorgonia.Grad(gorgonia.Must(gorgonia.Sum(ret)), rewards)
+	assert.Error(err)
+}
+
+func TestGAE(t *testing.T) {
+	assert := assert.New(t)
+	g := gorgonia.NewGraph()
+	rewards := vec(g, "rewards", 1, 0, 2)
+	values := vec(g, "values", 0.5, 1, 1, 4)
+	dones := vec(g, "dones", 0, 1, 0)
+
+	adv, err := GAE(rewards, values, dones, 0.5, 0.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// with lambda = 1, the advantages are the discounted returns minus the values
+	full, err := GAE(rewards, values, dones, 0.5, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ret, err := DiscountedReturns(rewards, dones, nil, 0.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vals := run(t, g, adv, full, ret)
+	// δ = [1 + 0.5 - 0.5, 0 - 1, 2 + 2 - 1] = [1, -1, 3]
+	assert.InDeltaSlice([]float64{0.75, -1, 3}, vals[0].Data(), 1e-12)
+	assert.InDeltaSlice([]float64{0.5, -1, 3}, vals[1].Data(), 1e-12)
+	assert.InDeltaSlice([]float64{1, 0, 2}, vals[2].Data(), 1e-12)
+
+	_, err = GAE(rewards, rewards, dones, 0.5, 0.5)
+	assert.Error(err, "values must have one more row")
+}
@@ -0,0 +1,63 @@
+package rl
+
+import (
+	"hash/fnv"
+
+	"github.com/pkg/errors"
+	"gorgonia.org/gorgonia"
+	"gorgonia.org/tensor"
+)
+
+func simpleHash(op gorgonia.Op) uint32 {
+	h := fnv.New32a()
+	op.WriteHash(h)
+	return h.Sum32()
+}
+
+func checkArity(op gorgonia.Op, inputs int) error {
+	if inputs != op.Arity() && op.Arity() >= 0 {
+		return errors.Errorf("%v has an arity of %d. Got %d instead", op, op.Arity(), inputs)
+	}
+	return nil
+}
+
+// floatsOf returns the data of a float tensor as float64s.
+func floatsOf(v gorgonia.Value) ([]float64, error) {
+	t, ok := v.(tensor.Tensor)
+	if !ok {
+		return nil, errors.Errorf("Expected a tensor. Got %T instead", v)
+	}
+	if t.RequiresIterator() {
+		t = tensor.Materialize(t)
+	}
+	switch data := t.Data().(type) {
+	case []float64:
+		return data, nil
+	case []float32:
+		retVal := make([]float64, len(data))
+		for i, f := range data {
+			retVal[i] = float64(f)
+		}
+		return retVal, nil
+	}
+	return nil, errors.Errorf("Expected a float tensor. Got %v instead", t.Dtype())
+}
+
+// newFloats returns a tensor of the dtype and shape, holding the data.
+func newFloats(dt tensor.Dtype, shape tensor.Shape, data []float64) tensor.Tensor {
+	if dt == tensor.Float32 {
+		f32s := make([]float32, len(data))
+		for i, f := range data {
+			f32s[i] = float32(f)
+		}
+		return tensor.New(tensor.WithShape(shape.Clone()...), tensor.WithBacking(f32s))
+	}
+	return tensor.New(tensor.WithShape(shape.Clone()...), tensor.WithBacking(data))
+}
+
+func checkFloat(n *gorgonia.Node) error {
+	if dt := n.Dtype(); dt != tensor.Float64 && dt != tensor.Float32 {
+		return errors.Errorf("Expected %v to be a float. Got %v instead", n, dt)
+	}
+	return nil
+}
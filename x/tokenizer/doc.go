@@ -0,0 +1,13 @@
+// Package tokenizer encodes text into int32 tensors of token ids, ready to be bound to the inputs of a graph, so that
+// NLP models can be run end-to-end in Go.
+//
+// A Tokenizer is loaded from a Hugging Face tokenizer.json file (see Load), from a BERT vocab.txt file (see
+// NewWordPiece), or from GPT-2 style vocab.json and merges.txt files (see NewByteLevelBPE). It follows the pipeline of
+// the Hugging Face tokenizers library: the text is split around the added tokens, normalized, pre-tokenized into words,
+// split into sub-word tokens by a WordPiece, BPE or WordLevel model, and wrapped in special tokens by a post-processor.
+//
+// The common components of the pipeline are supported: the BERT normalizer and pre-tokenizer, lowercasing, accents
+// stripping of Latin letters, byte-level BPE as used by GPT-2 and RoBERTa, and the metaspace and byte fallback
+// conventions of SentencePiece models such as Llama. Unicode normalization forms (NFC, NFKC...) are ignored, and only
+// single sequences are encoded, not pairs.
+package tokenizer
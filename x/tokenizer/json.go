@@ -0,0 +1,568 @@
+package tokenizer
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// jsonTokenizer is the schema of a Hugging Face tokenizer.json file.
+type jsonTokenizer struct {
+	AddedTokens []struct {
+		ID         int32  `json:"id"`
+		Content    string `json:"content"`
+		Lstrip     bool   `json:"lstrip"`
+		Rstrip     bool   `json:"rstrip"`
+		Normalized bool   `json:"normalized"`
+		Special    bool   `json:"special"`
+	} `json:"added_tokens"`
+	Normalizer    json.RawMessage `json:"normalizer"`
+	PreTokenizer  json.RawMessage `json:"pre_tokenizer"`
+	Model         json.RawMessage `json:"model"`
+	PostProcessor json.RawMessage `json:"post_processor"`
+	Decoder       json.RawMessage `json:"decoder"`
+	Truncation    *struct {
+		MaxLength int `json:"max_length"`
+	} `json:"truncation"`
+	Padding *struct {
+		Strategy json.RawMessage `json:"strategy"`
+		PadID    int32           `json:"pad_id"`
+	} `json:"padding"`
+}
+
+// jsonComponent holds the fields of all the normalizers, pre-tokenizers, post-processors and decoders.
+type jsonComponent struct {
+	Type string `json:"type"`
+
+	// normalizers
+	CleanText          *bool             `json:"clean_text"`
+	HandleChineseChars *bool             `json:"handle_chinese_chars"`
+	StripAccents       *bool             `json:"strip_accents"`
+	Lowercase          *bool             `json:"lowercase"`
+	StripLeft          bool              `json:"strip_left"`
+	StripRight         bool              `json:"strip_right"`
+	Prepend            string            `json:"prepend"`
+	Normalizers        []json.RawMessage `json:"normalizers"`
+
+	// pre-tokenizers
+	AddPrefixSpace   *bool             `json:"add_prefix_space"`
+	UseRegex         *bool             `json:"use_regex"`
+	Replacement      string            `json:"replacement"`
+	PrependScheme    string            `json:"prepend_scheme"`
+	Split            *bool             `json:"split"`
+	IndividualDigits bool              `json:"individual_digits"`
+	Behavior         string            `json:"behavior"`
+	Invert           bool              `json:"invert"`
+	Pretokenizers    []json.RawMessage `json:"pretokenizers"`
+
+	// post-processors
+	Sep           []json.RawMessage  `json:"sep"`
+	Cls           []json.RawMessage  `json:"cls"`
+	Single        []jsonTemplateItem `json:"single"`
+	SpecialTokens map[string]struct {
+		IDs    []int32  `json:"ids"`
+		Tokens []string `json:"tokens"`
+	} `json:"special_tokens"`
+	Processors []json.RawMessage `json:"processors"`
+
+	// decoders
+	Prefix   string            `json:"prefix"`
+	Cleanup  bool              `json:"cleanup"`
+	Suffix   string            `json:"suffix"`
+	Start    int               `json:"start"`
+	Stop     int               `json:"stop"`
+	Decoders []json.RawMessage `json:"decoders"`
+
+	// shared by Replace, Split and Strip
+	Pattern *struct {
+		String *string `json:"String"`
+		Regex  *string `json:"Regex"`
+	} `json:"pattern"`
+	Content string `json:"content"`
+}
+
+type jsonTemplateItem struct {
+	SpecialToken *struct {
+		ID     string `json:"id"`
+		TypeID int32  `json:"type_id"`
+	} `json:"SpecialToken"`
+	Sequence *struct {
+		TypeID int32 `json:"type_id"`
+	} `json:"Sequence"`
+}
+
+type jsonModel struct {
+	Type                    string            `json:"type"`
+	Vocab                   map[string]int32  `json:"vocab"`
+	Merges                  []json.RawMessage `json:"merges"`
+	UnkToken                *string           `json:"unk_token"`
+	ContinuingSubwordPrefix *string           `json:"continuing_subword_prefix"`
+	EndOfWordSuffix         *string           `json:"end_of_word_suffix"`
+	FuseUnk                 bool              `json:"fuse_unk"`
+	ByteFallback            bool              `json:"byte_fallback"`
+	MaxInputCharsPerWord    int               `json:"max_input_chars_per_word"`
+}
+
+// LoadFile loads a tokenizer from a Hugging Face tokenizer.json file.
+func LoadFile(path string) (*Tokenizer, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Load(f)
+}
+
+// Load loads a tokenizer from Hugging Face's tokenizer.json format.
+func Load(r io.Reader) (*Tokenizer, error) {
+	var j jsonTokenizer
+	if err := json.NewDecoder(r).Decode(&j); err != nil {
+		return nil, errors.Wrap(err, "Failed to decode the tokenizer")
+	}
+	t := new(Tokenizer)
+	var err error
+	if t.model, err = parseModel(j.Model); err != nil {
+		return nil, errors.Wrap(err, "Failed to parse the model")
+	}
+	if t.normalizer, err = parseNormalizer(j.Normalizer); err != nil {
+		return nil, errors.Wrap(err, "Failed to parse the normalizer")
+	}
+	if t.preTokenizer, err = parsePreTokenizer(j.PreTokenizer); err != nil {
+		return nil, errors.Wrap(err, "Failed to parse the pre-tokenizer")
+	}
+	if t.decoder, err = parseDecoder(j.Decoder); err != nil {
+		return nil, errors.Wrap(err, "Failed to parse the decoder")
+	}
+	for _, a := range j.AddedTokens {
+		t.added = append(t.added, addedToken{content: a.Content, id: a.ID, special: a.Special, lstrip: a.Lstrip, rstrip: a.Rstrip})
+	}
+	if t.post, err = t.parsePostProcessor(j.PostProcessor); err != nil {
+		return nil, errors.Wrap(err, "Failed to parse the post-processor")
+	}
+	if j.Truncation != nil {
+		t.maxLength = j.Truncation.MaxLength
+	}
+	if j.Padding != nil {
+		t.padID = j.Padding.PadID
+		var fixed struct {
+			Fixed int `json:"Fixed"`
+		}
+		if json.Unmarshal(j.Padding.Strategy, &fixed) == nil {
+			t.padLength = fixed.Fixed
+		}
+	} else {
+		t.guessPadID()
+	}
+	return t, nil
+}
+
+// NewWordPiece creates a BERT tokenizer from a vocab.txt file, that has one token per line.
+func NewWordPiece(vocabTxt io.Reader, lowercase bool) (*Tokenizer, error) {
+	ids := make(map[string]int32)
+	s := bufio.NewScanner(vocabTxt)
+	for s.Scan() {
+		tok := strings.TrimRight(s.Text(), "\r")
+		if _, ok := ids[tok]; !ok {
+			ids[tok] = int32(len(ids))
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, errors.Wrap(err, "Failed to read the vocabulary")
+	}
+	t := &Tokenizer{
+		normalizer:   bertNormalizer{cleanText: true, chineseChars: true, stripAccents: lowercase, lowercase: lowercase},
+		preTokenizer: bertPreTokenizer{},
+		model:        &wordPiece{vocab: newVocab(ids), unk: "[UNK]", prefix: "##", maxInputChars: 100},
+		decoder:      wordPieceDecoder{prefix: "##", cleanup: true},
+	}
+	for _, tok := range []string{"[PAD]", "[UNK]", "[CLS]", "[SEP]", "[MASK]"} {
+		if id, ok := ids[tok]; ok {
+			t.added = append(t.added, addedToken{content: tok, id: id, special: true})
+		}
+	}
+	cls, okCLS := ids["[CLS]"]
+	sep, okSEP := ids["[SEP]"]
+	if okCLS && okSEP {
+		t.post = template{{ids: []int32{cls}, tokens: []string{"[CLS]"}}, {}, {ids: []int32{sep}, tokens: []string{"[SEP]"}}}
+	}
+	t.guessPadID()
+	return t, nil
+}
+
+// NewByteLevelBPE creates a GPT-2 tokenizer from vocab.json and merges.txt files.
+func NewByteLevelBPE(vocabJSON, mergesTxt io.Reader) (*Tokenizer, error) {
+	var ids map[string]int32
+	if err := json.NewDecoder(vocabJSON).Decode(&ids); err != nil {
+		return nil, errors.Wrap(err, "Failed to decode the vocabulary")
+	}
+	ranks := make(map[[2]string]int)
+	s := bufio.NewScanner(mergesTxt)
+	for s.Scan() {
+		line := strings.TrimRight(s.Text(), "\r")
+		if line == "" || strings.HasPrefix(line, "#version") {
+			continue
+		}
+		pair := strings.Split(line, " ")
+		if len(pair) != 2 {
+			return nil, errors.Errorf("Invalid merge %q", line)
+		}
+		if _, ok := ranks[[2]string{pair[0], pair[1]}]; !ok {
+			ranks[[2]string{pair[0], pair[1]}] = len(ranks)
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, errors.Wrap(err, "Failed to read the merges")
+	}
+	t := &Tokenizer{
+		preTokenizer: byteLevel{},
+		model:        &bpe{vocab: newVocab(ids), ranks: ranks},
+		decoder:      byteLevelDecoder{},
+	}
+	if id, ok := ids["<|endoftext|>"]; ok {
+		t.added = append(t.added, addedToken{content: "<|endoftext|>", id: id, special: true})
+	}
+	t.guessPadID()
+	return t, nil
+}
+
+// guessPadID uses the first of the usual pad tokens that is in the vocabulary.
+func (t *Tokenizer) guessPadID() {
+	for _, tok := range []string{"[PAD]", "<pad>", "<|pad|>", "<|endoftext|>"} {
+		if id, ok := t.TokenID(tok); ok {
+			t.padID = id
+			return
+		}
+	}
+}
+
+func parseModel(raw json.RawMessage) (model, error) {
+	var j jsonModel
+	if err := json.Unmarshal(raw, &j); err != nil {
+		return nil, err
+	}
+	if j.Vocab == nil {
+		return nil, errors.New("The model has no vocabulary")
+	}
+	if j.Type == "" {
+		switch {
+		case j.Merges != nil:
+			j.Type = "BPE"
+		case j.ContinuingSubwordPrefix != nil:
+			j.Type = "WordPiece"
+		default:
+			j.Type = "WordLevel"
+		}
+	}
+	unk := stringOr(j.UnkToken, "")
+	switch j.Type {
+	case "WordPiece":
+		m := &wordPiece{vocab: newVocab(j.Vocab), unk: stringOr(j.UnkToken, "[UNK]"), prefix: stringOr(j.ContinuingSubwordPrefix, "##"), maxInputChars: j.MaxInputCharsPerWord}
+		if m.maxInputChars == 0 {
+			m.maxInputChars = 100
+		}
+		return m, nil
+	case "WordLevel":
+		return &wordLevel{vocab: newVocab(j.Vocab), unk: unk}, nil
+	case "BPE":
+		ranks := make(map[[2]string]int, len(j.Merges))
+		for i, raw := range j.Merges {
+			pair, err := parseMerge(raw)
+			if err != nil {
+				return nil, errors.Wrapf(err, "Failed to parse merge %d", i)
+			}
+			if _, ok := ranks[pair]; !ok {
+				ranks[pair] = i
+			}
+		}
+		return &bpe{
+			vocab:        newVocab(j.Vocab),
+			ranks:        ranks,
+			unk:          unk,
+			prefix:       stringOr(j.ContinuingSubwordPrefix, ""),
+			suffix:       stringOr(j.EndOfWordSuffix, ""),
+			fuseUnk:      j.FuseUnk,
+			byteFallback: j.ByteFallback,
+		}, nil
+	}
+	return nil, errors.Errorf("Unsupported model %q", j.Type)
+}
+
+// parseMerge parses a merge, written either as "a b" or as ["a", "b"].
+func parseMerge(raw json.RawMessage) ([2]string, error) {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		pair := strings.Split(s, " ")
+		if len(pair) != 2 {
+			return [2]string{}, errors.Errorf("Invalid merge %q", s)
+		}
+		return [2]string{pair[0], pair[1]}, nil
+	}
+	var pair []string
+	if err := json.Unmarshal(raw, &pair); err != nil {
+		return [2]string{}, err
+	}
+	if len(pair) != 2 {
+		return [2]string{}, errors.Errorf("Invalid merge %q", pair)
+	}
+	return [2]string{pair[0], pair[1]}, nil
+}
+
+// parseComponent decodes a component. It returns nil for a JSON null.
+func parseComponent(raw json.RawMessage) (*jsonComponent, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+	j := new(jsonComponent)
+	if err := json.Unmarshal(raw, j); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+func parseNormalizer(raw json.RawMessage) (normalizer, error) {
+	j, err := parseComponent(raw)
+	if err != nil || j == nil {
+		return nil, err
+	}
+	switch j.Type {
+	case "BertNormalizer":
+		n := bertNormalizer{
+			cleanText:    boolOr(j.CleanText, true),
+			chineseChars: boolOr(j.HandleChineseChars, true),
+			lowercase:    boolOr(j.Lowercase, true),
+		}
+		n.stripAccents = boolOr(j.StripAccents, n.lowercase)
+		return n, nil
+	case "Lowercase":
+		return lowercase{}, nil
+	case "StripAccents":
+		return accentsStripper{}, nil
+	case "Strip":
+		return strip{left: j.StripLeft, right: j.StripRight}, nil
+	case "Prepend":
+		return prepend{j.Prepend}, nil
+	case "Replace":
+		if j.Pattern == nil || j.Pattern.String == nil {
+			return nil, errors.New("Only the Replace normalizers with a string pattern are supported")
+		}
+		return replace{pattern: *j.Pattern.String, content: j.Content}, nil
+	case "NFC", "NFD", "NFKC", "NFKD":
+		return nil, nil
+	case "Sequence":
+		var retVal normalizers
+		for _, raw := range j.Normalizers {
+			n, err := parseNormalizer(raw)
+			if err != nil {
+				return nil, err
+			}
+			if n != nil {
+				retVal = append(retVal, n)
+			}
+		}
+		return retVal, nil
+	}
+	return nil, errors.Errorf("Unsupported normalizer %q", j.Type)
+}
+
+func parsePreTokenizer(raw json.RawMessage) (preTokenizer, error) {
+	j, err := parseComponent(raw)
+	if err != nil || j == nil {
+		return nil, err
+	}
+	switch j.Type {
+	case "BertPreTokenizer":
+		return bertPreTokenizer{}, nil
+	case "Whitespace":
+		return whitespace{}, nil
+	case "WhitespaceSplit":
+		return whitespaceSplit{}, nil
+	case "Punctuation":
+		return punctuation{}, nil
+	case "Digits":
+		return digits{individual: j.IndividualDigits}, nil
+	case "ByteLevel":
+		return byteLevel{addPrefixSpace: boolOr(j.AddPrefixSpace, true), noRegex: !boolOr(j.UseRegex, true)}, nil
+	case "Metaspace":
+		m := metaspace{replacement: j.Replacement, prefix: j.PrependScheme, noSplit: !boolOr(j.Split, true)}
+		if m.replacement == "" {
+			m.replacement = "▁"
+		}
+		if m.prefix == "" {
+			m.prefix = "never"
+			if boolOr(j.AddPrefixSpace, true) {
+				m.prefix = "always"
+			}
+		}
+		return m, nil
+	case "Split":
+		if j.Pattern == nil {
+			return nil, errors.New("The Split pre-tokenizer has no pattern")
+		}
+		var pattern *regexp.Regexp
+		switch {
+		case j.Pattern.String != nil:
+			pattern = regexp.MustCompile(regexp.QuoteMeta(*j.Pattern.String))
+		case j.Pattern.Regex != nil:
+			if pattern, err = regexp.Compile(*j.Pattern.Regex); err != nil {
+				return nil, errors.Wrapf(err, "Unsupported Split pattern %q", *j.Pattern.Regex)
+			}
+		}
+		return splitter{pattern: pattern, behavior: j.Behavior, invert: j.Invert}, nil
+	case "Sequence":
+		var retVal preTokenizers
+		for _, raw := range j.Pretokenizers {
+			p, err := parsePreTokenizer(raw)
+			if err != nil {
+				return nil, err
+			}
+			if p != nil {
+				retVal = append(retVal, p)
+			}
+		}
+		return retVal, nil
+	}
+	return nil, errors.Errorf("Unsupported pre-tokenizer %q", j.Type)
+}
+
+func (t *Tokenizer) parsePostProcessor(raw json.RawMessage) (template, error) {
+	j, err := parseComponent(raw)
+	if err != nil || j == nil {
+		return nil, err
+	}
+	switch j.Type {
+	case "BertProcessing", "RobertaProcessing":
+		cls, err := parseSpecialToken(j.Cls)
+		if err != nil {
+			return nil, err
+		}
+		sep, err := parseSpecialToken(j.Sep)
+		if err != nil {
+			return nil, err
+		}
+		return template{cls, {}, sep}, nil
+	case "TemplateProcessing":
+		var retVal template
+		for _, item := range j.Single {
+			switch {
+			case item.Sequence != nil:
+				retVal = append(retVal, templatePiece{typeID: item.Sequence.TypeID})
+			case item.SpecialToken != nil:
+				special, ok := j.SpecialTokens[item.SpecialToken.ID]
+				if !ok {
+					return nil, errors.Errorf("The special token %q of the template is not defined", item.SpecialToken.ID)
+				}
+				if len(special.IDs) != len(special.Tokens) {
+					return nil, errors.Errorf("The special token %q has %d ids and %d tokens", item.SpecialToken.ID, len(special.IDs), len(special.Tokens))
+				}
+				retVal = append(retVal, templatePiece{ids: special.IDs, tokens: special.Tokens, typeID: item.SpecialToken.TypeID})
+			}
+		}
+		return retVal, nil
+	case "ByteLevel":
+		return nil, nil
+	case "Sequence":
+		var retVal template
+		for _, raw := range j.Processors {
+			p, err := t.parsePostProcessor(raw)
+			if err != nil {
+				return nil, err
+			}
+			if p != nil && retVal != nil {
+				return nil, errors.New("Only one post-processor of a sequence may add special tokens")
+			}
+			if p != nil {
+				retVal = p
+			}
+		}
+		return retVal, nil
+	}
+	return nil, errors.Errorf("Unsupported post-processor %q", j.Type)
+}
+
+// parseSpecialToken parses a special token written as ["[CLS]", 101].
+func parseSpecialToken(raw []json.RawMessage) (templatePiece, error) {
+	if len(raw) != 2 {
+		return templatePiece{}, errors.Errorf("Expected a special token and its id. Got %d values instead", len(raw))
+	}
+	var tok string
+	var id int32
+	if err := json.Unmarshal(raw[0], &tok); err != nil {
+		return templatePiece{}, err
+	}
+	if err := json.Unmarshal(raw[1], &id); err != nil {
+		return templatePiece{}, err
+	}
+	return templatePiece{ids: []int32{id}, tokens: []string{tok}}, nil
+}
+
+func parseDecoder(raw json.RawMessage) (decoder, error) {
+	j, err := parseComponent(raw)
+	if err != nil || j == nil {
+		return nil, err
+	}
+	switch j.Type {
+	case "WordPiece":
+		d := wordPieceDecoder{prefix: j.Prefix, cleanup: j.Cleanup}
+		if d.prefix == "" {
+			d.prefix = "##"
+		}
+		return d, nil
+	case "ByteLevel":
+		return byteLevelDecoder{}, nil
+	case "Metaspace":
+		d := metaspaceDecoder{replacement: j.Replacement, prefix: j.PrependScheme != "never" && boolOr(j.AddPrefixSpace, true)}
+		if d.replacement == "" {
+			d.replacement = "▁"
+		}
+		return d, nil
+	case "BPEDecoder":
+		d := bpeDecoder{suffix: j.Suffix}
+		if d.suffix == "" {
+			d.suffix = "</w>"
+		}
+		return d, nil
+	case "Replace":
+		if j.Pattern == nil || j.Pattern.String == nil {
+			return nil, errors.New("Only the Replace decoders with a string pattern are supported")
+		}
+		return replaceDecoder{pattern: *j.Pattern.String, content: j.Content}, nil
+	case "ByteFallback":
+		return byteFallbackDecoder{}, nil
+	case "Fuse":
+		return fuseDecoder{}, nil
+	case "Strip":
+		return stripDecoder{content: j.Content, start: j.Start, stop: j.Stop}, nil
+	case "Sequence":
+		var retVal decoders
+		for _, raw := range j.Decoders {
+			d, err := parseDecoder(raw)
+			if err != nil {
+				return nil, err
+			}
+			if d != nil {
+				retVal = append(retVal, d)
+			}
+		}
+		return retVal, nil
+	}
+	return nil, errors.Errorf("Unsupported decoder %q", j.Type)
+}
+
+func stringOr(s *string, def string) string {
+	if s == nil {
+		return def
+	}
+	return *s
+}
+
+func boolOr(b *bool, def bool) bool {
+	if b == nil {
+		return def
+	}
+	return *b
+}
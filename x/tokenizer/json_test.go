@@ -0,0 +1,214 @@
+package tokenizer
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const gpt2JSON = `{
+  "version": "1.0",
+  "truncation": null,
+  "padding": null,
+  "added_tokens": [{"id": 13, "content": "<|endoftext|>", "single_word": false, "lstrip": false, "rstrip": false, "normalized": false, "special": true}],
+  "normalizer": null,
+  "pre_tokenizer": {"type": "ByteLevel", "add_prefix_space": false, "trim_offsets": true, "use_regex": true},
+  "post_processor": {"type": "ByteLevel", "add_prefix_space": true, "trim_offsets": false, "use_regex": true},
+  "decoder": {"type": "ByteLevel", "add_prefix_space": true, "trim_offsets": true, "use_regex": true},
+  "model": {
+    "type": "BPE",
+    "dropout": null,
+    "unk_token": null,
+    "continuing_subword_prefix": "",
+    "end_of_word_suffix": "",
+    "fuse_unk": false,
+    "byte_fallback": false,
+    "vocab": {"h": 0, "e": 1, "l": 2, "o": 3, "Ġ": 4, "w": 5, "r": 6, "d": 7, "he": 8, "ll": 9, "hell": 10, "hello": 11, "Ġw": 12, "or": 14, "Ġwor": 15, "ld": 16, "Ġworld": 17, "!": 18},
+    "merges": ["h e", "l l", "he ll", "hell o", ["Ġ", "w"], ["o", "r"], "Ġw or", "l d", "Ġwor ld"]
+  }
+}`
+
+const llamaJSON = `{
+  "added_tokens": [
+    {"id": 0, "content": "<unk>", "special": true},
+    {"id": 1, "content": "<s>", "special": true},
+    {"id": 2, "content": "</s>", "special": true}
+  ],
+  "normalizer": {"type": "Sequence", "normalizers": [
+    {"type": "Prepend", "prepend": "▁"},
+    {"type": "Replace", "pattern": {"String": " "}, "content": "▁"}
+  ]},
+  "pre_tokenizer": null,
+  "post_processor": {
+    "type": "TemplateProcessing",
+    "single": [{"SpecialToken": {"id": "<s>", "type_id": 0}}, {"Sequence": {"id": "A", "type_id": 0}}],
+    "pair": [],
+    "special_tokens": {"<s>": {"id": "<s>", "ids": [1], "tokens": ["<s>"]}}
+  },
+  "decoder": {"type": "Sequence", "decoders": [
+    {"type": "Replace", "pattern": {"String": "▁"}, "content": " "},
+    {"type": "ByteFallback"},
+    {"type": "Fuse"},
+    {"type": "Strip", "content": " ", "start": 1, "stop": 0}
+  ]},
+  "model": {
+    "type": "BPE",
+    "unk_token": "<unk>",
+    "fuse_unk": true,
+    "byte_fallback": true,
+    "vocab": {"<unk>": 0, "<s>": 1, "</s>": 2, "<0xC3>": 3, "<0xA9>": 4, "▁": 5, "h": 6, "i": 7, "▁h": 8, "▁hi": 9},
+    "merges": ["▁ h", "▁h i"]
+  }
+}`
+
+const wordPieceJSON = `{
+  "truncation": {"direction": "Right", "max_length": 5, "strategy": "LongestFirst", "stride": 0},
+  "padding": {"strategy": {"Fixed": 6}, "direction": "Right", "pad_to_multiple_of": null, "pad_id": 0, "pad_type_id": 0, "pad_token": "[PAD]"},
+  "added_tokens": [
+    {"id": 0, "content": "[PAD]", "special": true},
+    {"id": 1, "content": "[UNK]", "special": true},
+    {"id": 2, "content": "[CLS]", "special": true},
+    {"id": 3, "content": "[SEP]", "special": true}
+  ],
+  "normalizer": {"type": "BertNormalizer", "clean_text": true, "handle_chinese_chars": true, "strip_accents": null, "lowercase": true},
+  "pre_tokenizer": {"type": "BertPreTokenizer"},
+  "post_processor": {"type": "BertProcessing", "sep": ["[SEP]", 3], "cls": ["[CLS]", 2]},
+  "decoder": {"type": "WordPiece", "prefix": "##", "cleanup": true},
+  "model": {
+    "type": "WordPiece",
+    "unk_token": "[UNK]",
+    "continuing_subword_prefix": "##",
+    "max_input_chars_per_word": 100,
+    "vocab": {"[PAD]": 0, "[UNK]": 1, "[CLS]": 2, "[SEP]": 3, "play": 4, "##ing": 5, "好": 6, "a": 7, "b": 8}
+  }
+}`
+
+func TestLoad_byteLevelBPE(t *testing.T) {
+	assert := assert.New(t)
+	tok, err := Load(strings.NewReader(gpt2JSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := tok.Encode("hello world!<|endoftext|>")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]string{"hello", "Ġworld", "!", "<|endoftext|>"}, e.Tokens)
+	assert.Equal([]int32{11, 17, 18, 13}, e.IDs)
+
+	s, err := tok.Decode(e.IDs, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal("hello world!", s)
+
+	_, err = tok.Encode("hi")
+	assert.Error(err, "i is not in the vocabulary, and there is no unknown token")
+
+	// the pad token defaults to <|endoftext|>
+	b, err := tok.EncodeBatch([]string{"hello", "hello world"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]int32{11, 13, 11, 17}, b.IDs.Data())
+	assert.Equal([]int32{1, 0, 1, 1}, b.AttentionMask.Data())
+}
+
+func TestLoad_metaspaceByteFallback(t *testing.T) {
+	assert := assert.New(t)
+	tok, err := Load(strings.NewReader(llamaJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := tok.Encode("hi é hxx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]string{"<s>", "▁hi", "▁", "<0xC3>", "<0xA9>", "▁h", "<unk>"}, e.Tokens)
+	assert.Equal([]int32{1, 9, 5, 3, 4, 8, 0}, e.IDs)
+
+	e, err = tok.Encode("hi é")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := tok.Decode(e.IDs, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal("hi é", s)
+}
+
+func TestLoad_wordPiece(t *testing.T) {
+	assert := assert.New(t)
+	dir, err := ioutil.TempDir("", "tokenizer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "tokenizer.json")
+	if err := ioutil.WriteFile(path, []byte(wordPieceJSON), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tok, err := LoadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e, err := tok.Encode("PLAYING 好")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]string{"[CLS]", "play", "##ing", "好", "[SEP]"}, e.Tokens)
+
+	// truncated to 5 tokens, padded to 6
+	b, err := tok.EncodeBatch([]string{"a b a b", "a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]int32{2, 7, 8, 7, 3, 0, 2, 7, 3, 0, 0, 0}, b.IDs.Data())
+	assert.Equal([]int32{1, 1, 1, 1, 1, 0, 1, 1, 1, 0, 0, 0}, b.AttentionMask.Data())
+
+	_, err = LoadFile(filepath.Join(dir, "missing.json"))
+	assert.Error(err)
+}
+
+func TestLoad_errors(t *testing.T) {
+	assert := assert.New(t)
+	for _, s := range []string{
+		`{`,
+		`{"model": {"type": "BPE"}}`,
+		`{"model": {"type": "Unigram", "vocab": {}}}`,
+		`{"model": {"type": "BPE", "vocab": {}, "merges": ["a b c"]}}`,
+		`{"model": {"vocab": {}}, "normalizer": {"type": "Precompiled"}}`,
+		`{"model": {"vocab": {}}, "pre_tokenizer": {"type": "Split", "pattern": {"Regex": "\\s+(?!\\S)"}, "behavior": "Isolated"}}`,
+		`{"model": {"vocab": {}}, "post_processor": {"type": "TemplateProcessing", "single": [{"SpecialToken": {"id": "<s>"}}]}}`,
+		`{"model": {"vocab": {}}, "decoder": {"type": "CTC"}}`,
+	} {
+		_, err := Load(strings.NewReader(s))
+		assert.Error(err, s)
+	}
+}
+
+func TestNewByteLevelBPE(t *testing.T) {
+	assert := assert.New(t)
+	vocab := `{"a": 0, "b": 1, "ab": 2, "Ġ": 3, "Ġab": 4, "<|endoftext|>": 5}`
+	merges := "#version: 0.2\na b\nĠ ab\n"
+	tok, err := NewByteLevelBPE(strings.NewReader(vocab), strings.NewReader(merges))
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := tok.Encode("ab ab<|endoftext|>")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]int32{2, 4, 5}, e.IDs)
+
+	_, err = NewByteLevelBPE(strings.NewReader(vocab), strings.NewReader("a b c\n"))
+	assert.Error(err)
+	_, err = NewByteLevelBPE(strings.NewReader("["), strings.NewReader(merges))
+	assert.Error(err)
+}
@@ -0,0 +1,208 @@
+package tokenizer
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+// vocab maps the tokens to their ids, and back.
+type vocab struct {
+	ids    map[string]int32
+	tokens map[int32]string
+}
+
+func newVocab(ids map[string]int32) vocab {
+	tokens := make(map[int32]string, len(ids))
+	for tok, id := range ids {
+		tokens[id] = tok
+	}
+	return vocab{ids: ids, tokens: tokens}
+}
+
+func (v vocab) add(tok string, id int32) {
+	v.ids[tok] = id
+	v.tokens[id] = tok
+}
+
+// model splits a pre-tokenized word into tokens.
+type model interface {
+	tokenize(word string) ([]string, error)
+	vocabulary() vocab
+}
+
+// wordPiece is the model of BERT: every word is greedily split into the longest tokens of the vocabulary, the tokens
+// that do not start a word being prefixed by "##". Words that cannot be split are replaced by the unknown token.
+type wordPiece struct {
+	vocab
+	unk           string
+	prefix        string
+	maxInputChars int
+}
+
+func (m *wordPiece) vocabulary() vocab { return m.vocab }
+
+func (m *wordPiece) tokenize(word string) ([]string, error) {
+	if utf8.RuneCountInString(word) > m.maxInputChars {
+		return m.unknown(word)
+	}
+	var retVal []string
+	for start := 0; start < len(word); {
+		end := len(word)
+		var tok string
+		for ; end > start; end-- {
+			if end < len(word) && !utf8.RuneStart(word[end]) {
+				continue
+			}
+			tok = word[start:end]
+			if start > 0 {
+				tok = m.prefix + tok
+			}
+			if _, ok := m.ids[tok]; ok {
+				break
+			}
+		}
+		if end == start {
+			return m.unknown(word)
+		}
+		retVal = append(retVal, tok)
+		start = end
+	}
+	return retVal, nil
+}
+
+func (m *wordPiece) unknown(word string) ([]string, error) {
+	if _, ok := m.ids[m.unk]; !ok {
+		return nil, errors.Errorf("Cannot tokenize %q: the unknown token %q is not in the vocabulary", word, m.unk)
+	}
+	return []string{m.unk}, nil
+}
+
+// wordLevel maps every word to a token of the vocabulary, or to the unknown token.
+type wordLevel struct {
+	vocab
+	unk string
+}
+
+func (m *wordLevel) vocabulary() vocab { return m.vocab }
+
+func (m *wordLevel) tokenize(word string) ([]string, error) {
+	if _, ok := m.ids[word]; ok {
+		return []string{word}, nil
+	}
+	if _, ok := m.ids[m.unk]; !ok {
+		return nil, errors.Errorf("Cannot tokenize %q: the unknown token %q is not in the vocabulary", word, m.unk)
+	}
+	return []string{m.unk}, nil
+}
+
+// bpe is the byte-pair encoding model: every word starts as a sequence of characters, and the adjacent pairs of
+// symbols are merged by order of priority until no merge applies.
+type bpe struct {
+	vocab
+	ranks        map[[2]string]int
+	unk          string
+	prefix       string // continuing subword prefix
+	suffix       string // end of word suffix
+	fuseUnk      bool
+	byteFallback bool
+
+	mu    sync.Mutex
+	cache map[string][]string
+}
+
+// bpeCacheSize is the number of words whose tokens are cached.
+const bpeCacheSize = 10000
+
+func (m *bpe) vocabulary() vocab { return m.vocab }
+
+func (m *bpe) tokenize(word string) ([]string, error) {
+	if word == "" {
+		return nil, nil
+	}
+	m.mu.Lock()
+	toks, ok := m.cache[word]
+	m.mu.Unlock()
+	if ok {
+		return toks, nil
+	}
+	var symbols []string
+	for i, r := range word {
+		s := string(r)
+		if i > 0 {
+			s = m.prefix + s
+		}
+		symbols = append(symbols, s)
+	}
+	symbols[len(symbols)-1] += m.suffix
+
+	for len(symbols) > 1 {
+		best, rank := -1, 0
+		for i := 0; i+1 < len(symbols); i++ {
+			if r, ok := m.ranks[[2]string{symbols[i], symbols[i+1]}]; ok && (best < 0 || r < rank) {
+				best, rank = i, r
+			}
+		}
+		if best < 0 {
+			break
+		}
+		pair := [2]string{symbols[best], symbols[best+1]}
+		merged := symbols[:0]
+		for i := 0; i < len(symbols); i++ {
+			if i+1 < len(symbols) && symbols[i] == pair[0] && symbols[i+1] == pair[1] {
+				merged = append(merged, pair[0]+strings.TrimPrefix(pair[1], m.prefix))
+				i++
+				continue
+			}
+			merged = append(merged, symbols[i])
+		}
+		symbols = merged
+	}
+
+	var retVal []string
+	for _, s := range symbols {
+		if _, ok := m.ids[s]; ok {
+			retVal = append(retVal, s)
+			continue
+		}
+		if m.byteFallback {
+			if toks, ok := m.fallback(s); ok {
+				retVal = append(retVal, toks...)
+				continue
+			}
+		}
+		if _, ok := m.ids[m.unk]; !ok {
+			return nil, errors.Errorf("Cannot tokenize %q: %q is not in the vocabulary, and there is no unknown token", word, s)
+		}
+		if m.fuseUnk && len(retVal) > 0 && retVal[len(retVal)-1] == m.unk {
+			continue
+		}
+		retVal = append(retVal, m.unk)
+	}
+	m.mu.Lock()
+	if m.cache == nil {
+		m.cache = make(map[string][]string)
+	}
+	if len(m.cache) < bpeCacheSize {
+		m.cache[word] = retVal
+	}
+	m.mu.Unlock()
+	return retVal, nil
+}
+
+// fallback returns the byte tokens <0xNN> of the symbol, if they are all in the vocabulary.
+func (m *bpe) fallback(s string) ([]string, bool) {
+	s = strings.TrimSuffix(strings.TrimPrefix(s, m.prefix), m.suffix)
+	toks := make([]string, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		tok := fmt.Sprintf("<0x%02X>", s[i])
+		if _, ok := m.ids[tok]; !ok {
+			return nil, false
+		}
+		toks = append(toks, tok)
+	}
+	return toks, true
+}
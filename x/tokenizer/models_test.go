@@ -0,0 +1,101 @@
+package tokenizer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWordPiece(t *testing.T) {
+	assert := assert.New(t)
+	m := &wordPiece{
+		vocab:         newVocab(map[string]int32{"[UNK]": 0, "un": 1, "##aff": 2, "##able": 3, "é": 4, "##t": 5}),
+		unk:           "[UNK]",
+		prefix:        "##",
+		maxInputChars: 8,
+	}
+	toks, err := m.tokenize("unaffable")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]string{"[UNK]"}, toks, "too long")
+
+	m.maxInputChars = 100
+	toks, _ = m.tokenize("unaffable")
+	assert.Equal([]string{"un", "##aff", "##able"}, toks)
+	toks, _ = m.tokenize("ét")
+	assert.Equal([]string{"é", "##t"}, toks)
+	toks, _ = m.tokenize("unx")
+	assert.Equal([]string{"[UNK]"}, toks)
+
+	m.unk = "<unk>"
+	_, err = m.tokenize("unx")
+	assert.Error(err)
+}
+
+func TestWordLevel(t *testing.T) {
+	assert := assert.New(t)
+	m := &wordLevel{vocab: newVocab(map[string]int32{"<unk>": 0, "cat": 1}), unk: "<unk>"}
+	toks, _ := m.tokenize("cat")
+	assert.Equal([]string{"cat"}, toks)
+	toks, _ = m.tokenize("dog")
+	assert.Equal([]string{"<unk>"}, toks)
+	m.unk = ""
+	_, err := m.tokenize("dog")
+	assert.Error(err)
+}
+
+func TestBPE(t *testing.T) {
+	assert := assert.New(t)
+	m := &bpe{
+		vocab: newVocab(map[string]int32{"l": 0, "o": 1, "w": 2, "</w>": 3, "lo": 4, "low": 5, "w</w>": 6, "low</w>": 7, "e": 8, "r</w>": 9, "<unk>": 10}),
+		ranks: map[[2]string]int{{"l", "o"}: 0, {"lo", "w</w>"}: 1, {"lo", "w"}: 2},
+		unk:   "<unk>",
+	}
+	m.suffix = "</w>"
+	toks, err := m.tokenize("low")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]string{"low</w>"}, toks)
+	toks, _ = m.tokenize("lower")
+	assert.Equal([]string{"low", "e", "r</w>"}, toks)
+	toks, _ = m.tokenize("lowx")
+	assert.Equal([]string{"low", "<unk>"}, toks)
+	assert.Contains(m.cache, "lower", "the words are cached")
+
+	// the continuing subword prefix is dropped from the merged symbols
+	m = &bpe{
+		vocab:  newVocab(map[string]int32{"a": 0, "##b": 1, "##c": 2, "ab": 3, "##bc": 4, "[UNK]": 5}),
+		ranks:  map[[2]string]int{{"##b", "##c"}: 0},
+		unk:    "[UNK]",
+		prefix: "##",
+	}
+	toks, _ = m.tokenize("abc")
+	assert.Equal([]string{"a", "##bc"}, toks)
+	m.fuseUnk = true
+	toks, _ = m.tokenize("axx")
+	assert.Equal([]string{"a", "[UNK]"}, toks)
+	m.fuseUnk = false
+	m.cache = nil
+	toks, _ = m.tokenize("axx")
+	assert.Equal([]string{"a", "[UNK]", "[UNK]"}, toks)
+
+	m.unk = ""
+	_, err = m.tokenize("ay")
+	assert.Error(err)
+	toks, _ = m.tokenize("")
+	assert.Nil(toks)
+}
+
+func TestBPE_byteFallback(t *testing.T) {
+	assert := assert.New(t)
+	m := &bpe{vocab: newVocab(map[string]int32{"a": 0, "<0xE2>": 1, "<0x82>": 2, "<0xAC>": 3}), byteFallback: true}
+	toks, err := m.tokenize("a€")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]string{"a", "<0xE2>", "<0x82>", "<0xAC>"}, toks)
+	assert.Equal("a€", strings.Join(byteFallbackDecoder{}.decode(toks), ""))
+}
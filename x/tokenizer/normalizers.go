@@ -0,0 +1,167 @@
+package tokenizer
+
+import (
+	"strings"
+	"unicode"
+)
+
+// normalizer transforms the text before it is split into words.
+type normalizer interface {
+	normalize(s string) string
+}
+
+// normalizers applies its normalizers in order.
+type normalizers []normalizer
+
+func (ns normalizers) normalize(s string) string {
+	for _, n := range ns {
+		s = n.normalize(s)
+	}
+	return s
+}
+
+// bertNormalizer is the normalizer of BERT: it removes control characters, pads CJK characters with spaces so that they
+// are split into their own words, strips accents and lowercases the text.
+type bertNormalizer struct {
+	cleanText    bool
+	chineseChars bool
+	stripAccents bool
+	lowercase    bool
+}
+
+func (n bertNormalizer) normalize(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	for _, r := range s {
+		switch {
+		case n.cleanText && (r == 0 || r == unicode.ReplacementChar || isControl(r)):
+			continue
+		case n.cleanText && unicode.IsSpace(r):
+			b.WriteByte(' ')
+		case n.chineseChars && isChinese(r):
+			b.WriteByte(' ')
+			b.WriteRune(r)
+			b.WriteByte(' ')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	s = b.String()
+	if n.stripAccents {
+		s = stripAccents(s)
+	}
+	if n.lowercase {
+		s = strings.ToLower(s)
+	}
+	return s
+}
+
+type lowercase struct{}
+
+func (lowercase) normalize(s string) string { return strings.ToLower(s) }
+
+type accentsStripper struct{}
+
+func (accentsStripper) normalize(s string) string { return stripAccents(s) }
+
+// strip removes the leading and trailing whitespace.
+type strip struct {
+	left, right bool
+}
+
+func (n strip) normalize(s string) string {
+	if n.left {
+		s = strings.TrimLeftFunc(s, unicode.IsSpace)
+	}
+	if n.right {
+		s = strings.TrimRightFunc(s, unicode.IsSpace)
+	}
+	return s
+}
+
+// replace replaces every occurrence of a string.
+type replace struct {
+	pattern, content string
+}
+
+func (n replace) normalize(s string) string { return strings.Replace(s, n.pattern, n.content, -1) }
+
+// prepend adds a prefix to non-empty texts.
+type prepend struct {
+	prefix string
+}
+
+func (n prepend) normalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return n.prefix + s
+}
+
+// isControl reports whether r is a control character. Tabs and newlines are whitespace, not control characters.
+func isControl(r rune) bool {
+	if r == '\t' || r == '\n' || r == '\r' {
+		return false
+	}
+	return unicode.In(r, unicode.Cc, unicode.Cf, unicode.Co, unicode.Cs)
+}
+
+// isChinese reports whether r is in one of the CJK Unified Ideographs blocks.
+func isChinese(r rune) bool {
+	return (r >= 0x4E00 && r <= 0x9FFF) ||
+		(r >= 0x3400 && r <= 0x4DBF) ||
+		(r >= 0x20000 && r <= 0x2A6DF) ||
+		(r >= 0x2A700 && r <= 0x2B73F) ||
+		(r >= 0x2B740 && r <= 0x2B81F) ||
+		(r >= 0x2B820 && r <= 0x2CEAF) ||
+		(r >= 0xF900 && r <= 0xFAFF) ||
+		(r >= 0x2F800 && r <= 0x2FA1F)
+}
+
+// stripAccents removes the combining marks, and replaces the precomposed Latin letters by their base letter.
+func stripAccents(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	for _, r := range s {
+		if unicode.Is(unicode.Mn, r) {
+			continue
+		}
+		if base, ok := accented[r]; ok {
+			r = base
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// accented maps the precomposed letters of the Latin-1 Supplement and Latin Extended-A blocks to their base letter.
+var accented = func() map[rune]rune {
+	groups := map[rune]string{
+		'A': "ÀÁÂÃÄÅĀĂĄ", 'a': "àáâãäåāăą",
+		'C': "ÇĆĈĊČ", 'c': "çćĉċč",
+		'D': "Ď", 'd': "ď",
+		'E': "ÈÉÊËĒĔĖĘĚ", 'e': "èéêëēĕėęě",
+		'G': "ĜĞĠĢ", 'g': "ĝğġģ",
+		'H': "Ĥ", 'h': "ĥ",
+		'I': "ÌÍÎÏĨĪĬĮİ", 'i': "ìíîïĩīĭį",
+		'J': "Ĵ", 'j': "ĵ",
+		'K': "Ķ", 'k': "ķ",
+		'L': "ĹĻĽ", 'l': "ĺļľ",
+		'N': "ÑŃŅŇ", 'n': "ñńņň",
+		'O': "ÒÓÔÕÖŌŎŐ", 'o': "òóôõöōŏő",
+		'R': "ŔŖŘ", 'r': "ŕŗř",
+		'S': "ŚŜŞŠ", 's': "śŝşš",
+		'T': "ŢŤ", 't': "ţť",
+		'U': "ÙÚÛÜŨŪŬŮŰŲ", 'u': "ùúûüũūŭůűų",
+		'W': "Ŵ", 'w': "ŵ",
+		'Y': "ÝŶŸ", 'y': "ýÿŷ",
+		'Z': "ŹŻŽ", 'z': "źżž",
+	}
+	retVal := make(map[rune]rune)
+	for base, letters := range groups {
+		for _, r := range letters {
+			retVal[r] = base
+		}
+	}
+	return retVal
+}()
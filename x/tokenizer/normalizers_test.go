@@ -0,0 +1,27 @@
+package tokenizer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBertNormalizer(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal("hello wörld 中  国 ", bertNormalizer{cleanText: true, chineseChars: true}.normalize("hello\twörld\x00中国"))
+	assert.Equal("cafe naive 日 ", bertNormalizer{cleanText: true, chineseChars: true, stripAccents: true, lowercase: true}.normalize("Café NAÏVE\u0007日"))
+	assert.Equal("Café\tx", bertNormalizer{}.normalize("Café\tx"))
+}
+
+func TestNormalizers(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal("élan", lowercase{}.normalize("ÉLAN"))
+	assert.Equal("elan cooperation", accentsStripper{}.normalize("élan coöperation"))
+	assert.Equal("e", accentsStripper{}.normalize("é"), "combining marks are removed")
+	assert.Equal("a ", strip{left: true}.normalize("  a "))
+	assert.Equal("  a", strip{right: true}.normalize("  a "))
+	assert.Equal("a▁b", replace{" ", "▁"}.normalize("a b"))
+	assert.Equal("▁a", prepend{"▁"}.normalize("a"))
+	assert.Equal("", prepend{"▁"}.normalize(""))
+	assert.Equal("▁a▁b", normalizers{prepend{"▁"}, replace{" ", "▁"}}.normalize("a b"))
+}
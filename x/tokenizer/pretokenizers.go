@@ -0,0 +1,343 @@
+package tokenizer
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// preTokenizer splits a normalized text into words. first is true for the first piece of the text, that is not
+// preceded by an added token.
+type preTokenizer interface {
+	split(s string, first bool) []string
+}
+
+// preTokenizers applies its pre-tokenizers in order, each one splitting the words of the previous one.
+type preTokenizers []preTokenizer
+
+func (ps preTokenizers) split(s string, first bool) []string {
+	words := []string{s}
+	for _, p := range ps {
+		var next []string
+		for i, w := range words {
+			next = append(next, p.split(w, first && i == 0)...)
+		}
+		words = next
+	}
+	return words
+}
+
+// bertPreTokenizer splits on whitespace, and isolates every punctuation character.
+type bertPreTokenizer struct{}
+
+func (bertPreTokenizer) split(s string, first bool) []string {
+	var retVal []string
+	for _, w := range strings.Fields(s) {
+		start := 0
+		for i, r := range w {
+			if !isPunctuation(r) {
+				continue
+			}
+			if i > start {
+				retVal = append(retVal, w[start:i])
+			}
+			end := i + utf8.RuneLen(r)
+			retVal = append(retVal, w[i:end])
+			start = end
+		}
+		if start < len(w) {
+			retVal = append(retVal, w[start:])
+		}
+	}
+	return retVal
+}
+
+// whitespace splits the text into runs of word characters and runs of other non-whitespace characters, like the
+// regular expression \w+|[^\w\s]+.
+type whitespace struct{}
+
+func (whitespace) split(s string, first bool) []string {
+	return splitRuns(s, func(r rune) int {
+		switch {
+		case unicode.IsSpace(r):
+			return -1
+		case isWordChar(r):
+			return 0
+		}
+		return 1
+	})
+}
+
+// whitespaceSplit splits on whitespace.
+type whitespaceSplit struct{}
+
+func (whitespaceSplit) split(s string, first bool) []string { return strings.Fields(s) }
+
+// punctuation isolates every punctuation character.
+type punctuation struct{}
+
+func (punctuation) split(s string, first bool) []string {
+	var retVal []string
+	start := 0
+	for i, r := range s {
+		if !isPunctuation(r) {
+			continue
+		}
+		if i > start {
+			retVal = append(retVal, s[start:i])
+		}
+		end := i + utf8.RuneLen(r)
+		retVal = append(retVal, s[i:end])
+		start = end
+	}
+	if start < len(s) {
+		retVal = append(retVal, s[start:])
+	}
+	return retVal
+}
+
+// digits separates the digits from the other characters, optionally isolating every digit.
+type digits struct {
+	individual bool
+}
+
+func (p digits) split(s string, first bool) []string {
+	var retVal []string
+	start := 0
+	prevDigit := false
+	for i, r := range s {
+		isDigit := unicode.IsDigit(r)
+		if i > start && (isDigit != prevDigit || (isDigit && p.individual)) {
+			retVal = append(retVal, s[start:i])
+			start = i
+		}
+		prevDigit = isDigit
+	}
+	if start < len(s) {
+		retVal = append(retVal, s[start:])
+	}
+	return retVal
+}
+
+// metaspace replaces the spaces by a visible character, as SentencePiece does, and splits the text before each of
+// them.
+type metaspace struct {
+	replacement string
+	prefix      string // "always", "first" or "never"
+	noSplit     bool
+}
+
+func (p metaspace) split(s string, first bool) []string {
+	s = strings.Replace(s, " ", p.replacement, -1)
+	if (p.prefix == "always" || (p.prefix == "first" && first)) && !strings.HasPrefix(s, p.replacement) {
+		s = p.replacement + s
+	}
+	if p.noSplit || s == "" {
+		return []string{s}
+	}
+	var retVal []string
+	for {
+		i := strings.Index(s[1:], p.replacement)
+		if i < 0 {
+			return append(retVal, s)
+		}
+		retVal = append(retVal, s[:i+1])
+		s = s[i+1:]
+	}
+}
+
+// splitter splits the text on the matches of a regular expression. The behavior says what becomes of the matches:
+// "Removed", "Isolated", "MergedWithPrevious" or "MergedWithNext". When invert is true, the matches are the words and
+// the text between them is removed.
+type splitter struct {
+	pattern  *regexp.Regexp
+	behavior string
+	invert   bool
+}
+
+func (p splitter) split(s string, first bool) []string {
+	matches := p.pattern.FindAllStringIndex(s, -1)
+	if p.invert {
+		retVal := make([]string, 0, len(matches))
+		for _, m := range matches {
+			if m[1] > m[0] {
+				retVal = append(retVal, s[m[0]:m[1]])
+			}
+		}
+		return retVal
+	}
+	var retVal []string
+	var pending string // the match to merge with the next word
+	prev := 0
+	for _, m := range matches {
+		if m[1] == m[0] {
+			continue
+		}
+		before, match := s[prev:m[0]], s[m[0]:m[1]]
+		switch p.behavior {
+		case "Removed":
+			retVal = appendNonEmpty(retVal, pending+before)
+			pending = ""
+		case "MergedWithPrevious":
+			retVal = appendNonEmpty(retVal, pending+before+match)
+			pending = ""
+		case "MergedWithNext":
+			retVal = appendNonEmpty(retVal, pending+before)
+			pending = match
+		default:
+			retVal = appendNonEmpty(retVal, before)
+			retVal = append(retVal, match)
+		}
+		prev = m[1]
+	}
+	return appendNonEmpty(retVal, pending+s[prev:])
+}
+
+func appendNonEmpty(a []string, s string) []string {
+	if s == "" {
+		return a
+	}
+	return append(a, s)
+}
+
+// byteLevel is the pre-tokenizer of GPT-2: it splits the text like GPT-2's regular expression, then maps the bytes of
+// every word to printable characters, so that any text can be encoded without unknown tokens.
+type byteLevel struct {
+	addPrefixSpace bool
+	noRegex        bool
+}
+
+func (p byteLevel) split(s string, first bool) []string {
+	if p.addPrefixSpace && !strings.HasPrefix(s, " ") {
+		s = " " + s
+	}
+	words := []string{s}
+	if !p.noRegex {
+		words = splitGPT2(s)
+	}
+	for i, w := range words {
+		words[i] = bytesToChars(w)
+	}
+	return words
+}
+
+// splitGPT2 splits a text like GPT-2's regular expression:
+//		's|'t|'re|'ve|'m|'ll|'d| ?\p{L}+| ?\p{N}+| ?[^\s\p{L}\p{N}]+|\s+(?!\S)|\s+
+// Go's regexp does not support the negative lookahead, so the text is scanned by hand.
+func splitGPT2(s string) []string {
+	rs := []rune(s)
+	class := func(r rune) int {
+		switch {
+		case unicode.IsLetter(r):
+			return 0
+		case unicode.IsNumber(r):
+			return 1
+		case unicode.IsSpace(r):
+			return -1
+		}
+		return 2
+	}
+	var retVal []string
+	for i := 0; i < len(rs); {
+		if n := contraction(rs[i:]); n > 0 {
+			retVal = append(retVal, string(rs[i:i+n]))
+			i += n
+			continue
+		}
+		start := i
+		if rs[i] == ' ' && i+1 < len(rs) && class(rs[i+1]) >= 0 {
+			i++
+		}
+		if c := class(rs[i]); c >= 0 {
+			for i++; i < len(rs) && class(rs[i]) == c; i++ {
+			}
+			retVal = append(retVal, string(rs[start:i]))
+			continue
+		}
+		for i++; i < len(rs) && class(rs[i]) < 0; i++ {
+		}
+		// the last whitespace before a word is left to it
+		if i < len(rs) && i-start > 1 {
+			i--
+		}
+		retVal = append(retVal, string(rs[start:i]))
+	}
+	return retVal
+}
+
+// contraction returns the length of the English contraction at the start of rs, or 0.
+func contraction(rs []rune) int {
+	if len(rs) < 2 || rs[0] != '\'' {
+		return 0
+	}
+	switch rs[1] {
+	case 's', 't', 'm', 'd':
+		return 2
+	}
+	if len(rs) >= 3 {
+		switch string(rs[1:3]) {
+		case "re", "ve", "ll":
+			return 3
+		}
+	}
+	return 0
+}
+
+// splitRuns splits s into the runs of characters of the same class. Characters of a negative class are dropped.
+func splitRuns(s string, class func(rune) int) []string {
+	var retVal []string
+	start, prev := 0, -1
+	for i, r := range s {
+		c := class(r)
+		if c != prev {
+			if prev >= 0 {
+				retVal = append(retVal, s[start:i])
+			}
+			start, prev = i, c
+		}
+	}
+	if prev >= 0 {
+		retVal = append(retVal, s[start:])
+	}
+	return retVal
+}
+
+// isPunctuation reports whether r is a punctuation character. As in BERT, all the non-alphanumeric ASCII characters are
+// punctuation.
+func isPunctuation(r rune) bool {
+	if (r >= 33 && r <= 47) || (r >= 58 && r <= 64) || (r >= 91 && r <= 96) || (r >= 123 && r <= 126) {
+		return true
+	}
+	return unicode.IsPunct(r)
+}
+
+func isWordChar(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r) || unicode.Is(unicode.Pc, r)
+}
+
+// byteChars maps every byte to a printable character, as GPT-2 does: the printable bytes map to themselves, and the
+// others to the characters from U+0100 onwards.
+var byteChars, charBytes = func() ([256]rune, map[rune]byte) {
+	var chars [256]rune
+	bytes := make(map[rune]byte, 256)
+	n := 0
+	for b := 0; b < 256; b++ {
+		if (b >= '!' && b <= '~') || (b >= 0xA1 && b <= 0xAC) || (b >= 0xAE && b <= 0xFF) {
+			chars[b] = rune(b)
+		} else {
+			chars[b] = rune(256 + n)
+			n++
+		}
+		bytes[chars[b]] = byte(b)
+	}
+	return chars, bytes
+}()
+
+func bytesToChars(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		b.WriteRune(byteChars[s[i]])
+	}
+	return b.String()
+}
@@ -0,0 +1,54 @@
+package tokenizer
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitGPT2(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal([]string{"Hello", ",", " world", "!", " It", "'s", " 42", "  ", " spaces", "\n", "\n", "end", "  "},
+		splitGPT2("Hello, world! It's 42   spaces\n\nend  "))
+	assert.Equal([]string{"we", "'ll", " see", " ?!"}, splitGPT2("we'll see ?!"))
+	assert.Nil(splitGPT2(""))
+}
+
+func TestPreTokenizers(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal([]string{"Hello", ",", "wo", "-", "rld", "!"}, bertPreTokenizer{}.split(" Hello,  wo-rld!", true))
+	assert.Equal([]string{"Hey", "_friend", "!?", "ça", "va"}, whitespace{}.split("Hey _friend!? ça va", true))
+	assert.Equal([]string{"a,", "b"}, whitespaceSplit{}.split(" a,\tb ", true))
+	assert.Equal([]string{"a", ",", " b", "."}, punctuation{}.split("a, b.", true))
+	assert.Equal([]string{"abc", "123", "d", "4"}, digits{}.split("abc123d4", true))
+	assert.Equal([]string{"a", "1", "2", "b"}, digits{individual: true}.split("a12b", true))
+
+	m := metaspace{replacement: "▁", prefix: "first"}
+	assert.Equal([]string{"▁hello", "▁world"}, m.split("hello world", true))
+	assert.Equal([]string{"hello", "▁world"}, m.split("hello world", false))
+	m.noSplit = true
+	assert.Equal([]string{"▁hello▁world"}, m.split("hello world", true))
+
+	assert.Equal([]string{"Ġhi", "Ġthere"}, byteLevel{addPrefixSpace: true}.split("hi there", true))
+	assert.Equal([]string{"hiĠthere"}, byteLevel{noRegex: true}.split("hi there", true))
+	assert.Equal([]string{"Ã©"}, byteLevel{}.split("é", true))
+
+	seq := preTokenizers{whitespaceSplit{}, punctuation{}}
+	assert.Equal([]string{"a", ".", "b", "!"}, seq.split("a. b!", true))
+}
+
+func TestSplitter(t *testing.T) {
+	assert := assert.New(t)
+	p := splitter{pattern: regexp.MustCompile("-")}
+	assert.Equal([]string{"a", "-", "b", "-", "c"}, p.split("a-b-c", true))
+	p.behavior = "Removed"
+	assert.Equal([]string{"a", "b", "c"}, p.split("a-b--c", true))
+	p.behavior = "MergedWithPrevious"
+	assert.Equal([]string{"a-", "b-", "c"}, p.split("a-b-c", true))
+	p.behavior = "MergedWithNext"
+	assert.Equal([]string{"a", "-b", "-c"}, p.split("a-b-c", true))
+
+	p = splitter{pattern: regexp.MustCompile(`\d+`), invert: true}
+	assert.Equal([]string{"12", "3"}, p.split("a12b3", true))
+}
@@ -0,0 +1,223 @@
+package tokenizer
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// templatePiece is a piece of a post-processing template: either the special tokens, or the sequence when tokens is
+// nil.
+type templatePiece struct {
+	ids    []int32
+	tokens []string
+	typeID int32
+}
+
+// template wraps an encoded sequence in special tokens, like [CLS] $A [SEP].
+type template []templatePiece
+
+// specials returns the number of special tokens added by the template.
+func (t template) specials() int {
+	var n int
+	for _, p := range t {
+		n += len(p.ids)
+	}
+	return n
+}
+
+func (t template) apply(e *Encoding) *Encoding {
+	if len(t) == 0 {
+		return e
+	}
+	retVal := new(Encoding)
+	for _, p := range t {
+		if p.tokens == nil {
+			for i := range e.IDs {
+				retVal.append(e.IDs[i], e.Tokens[i], p.typeID, e.SpecialTokensMask[i] == 1)
+			}
+			continue
+		}
+		for i := range p.ids {
+			retVal.append(p.ids[i], p.tokens[i], p.typeID, true)
+		}
+	}
+	return retVal
+}
+
+// decoder turns the tokens back into strings, that are concatenated into the decoded text.
+type decoder interface {
+	decode(tokens []string) []string
+}
+
+type decoders []decoder
+
+func (ds decoders) decode(tokens []string) []string {
+	for _, d := range ds {
+		tokens = d.decode(tokens)
+	}
+	return tokens
+}
+
+// wordPieceDecoder glues the tokens prefixed by "##" to the previous one, and separates the others with spaces.
+type wordPieceDecoder struct {
+	prefix  string
+	cleanup bool
+}
+
+func (d wordPieceDecoder) decode(tokens []string) []string {
+	retVal := make([]string, len(tokens))
+	for i, tok := range tokens {
+		switch {
+		case strings.HasPrefix(tok, d.prefix):
+			tok = strings.TrimPrefix(tok, d.prefix)
+		case i > 0:
+			tok = " " + tok
+		}
+		if d.cleanup {
+			tok = cleanup(tok)
+		}
+		retVal[i] = tok
+	}
+	return retVal
+}
+
+// cleanup removes the spaces before the punctuation and in the English contractions.
+func cleanup(s string) string {
+	return cleanupReplacer.Replace(s)
+}
+
+var cleanupReplacer = strings.NewReplacer(
+	" .", ".", " ?", "?", " !", "!", " ,", ",", " ' ", "'",
+	" n't", "n't", " 'm", "'m", " do not", " don't", " 's", "'s", " 've", "'ve", " 're", "'re",
+)
+
+// byteLevelDecoder maps the characters of the tokens back to the bytes they stand for.
+type byteLevelDecoder struct{}
+
+func (byteLevelDecoder) decode(tokens []string) []string {
+	var bs []byte
+	for _, tok := range tokens {
+		for _, r := range tok {
+			if b, ok := charBytes[r]; ok {
+				bs = append(bs, b)
+				continue
+			}
+			bs = append(bs, string(r)...)
+		}
+	}
+	return []string{string(bs)}
+}
+
+// metaspaceDecoder replaces the metaspace character by spaces, removing the prefix space of the first token.
+type metaspaceDecoder struct {
+	replacement string
+	prefix      bool
+}
+
+func (d metaspaceDecoder) decode(tokens []string) []string {
+	retVal := make([]string, len(tokens))
+	for i, tok := range tokens {
+		tok = strings.Replace(tok, d.replacement, " ", -1)
+		if i == 0 && d.prefix {
+			tok = strings.TrimPrefix(tok, " ")
+		}
+		retVal[i] = tok
+	}
+	return retVal
+}
+
+// bpeDecoder replaces the end of word suffix by spaces.
+type bpeDecoder struct {
+	suffix string
+}
+
+func (d bpeDecoder) decode(tokens []string) []string {
+	retVal := make([]string, len(tokens))
+	for i, tok := range tokens {
+		sep := " "
+		if i == len(tokens)-1 {
+			sep = ""
+		}
+		retVal[i] = strings.Replace(tok, d.suffix, sep, -1)
+	}
+	return retVal
+}
+
+type replaceDecoder struct {
+	pattern, content string
+}
+
+func (d replaceDecoder) decode(tokens []string) []string {
+	retVal := make([]string, len(tokens))
+	for i, tok := range tokens {
+		retVal[i] = strings.Replace(tok, d.pattern, d.content, -1)
+	}
+	return retVal
+}
+
+// byteFallbackDecoder turns the byte tokens <0xNN> back into text. Sequences of bytes that are not valid UTF-8 are
+// replaced by U+FFFD.
+type byteFallbackDecoder struct{}
+
+func (byteFallbackDecoder) decode(tokens []string) []string {
+	var retVal []string
+	var bs []byte
+	flush := func() {
+		if bs == nil {
+			return
+		}
+		if utf8.Valid(bs) {
+			retVal = append(retVal, string(bs))
+		} else {
+			for range bs {
+				retVal = append(retVal, string(utf8.RuneError))
+			}
+		}
+		bs = nil
+	}
+	for _, tok := range tokens {
+		if b, ok := byteToken(tok); ok {
+			bs = append(bs, b)
+			continue
+		}
+		flush()
+		retVal = append(retVal, tok)
+	}
+	flush()
+	return retVal
+}
+
+// byteToken parses a byte token <0xNN>.
+func byteToken(tok string) (byte, bool) {
+	if len(tok) != 6 || !strings.HasPrefix(tok, "<0x") || tok[5] != '>' {
+		return 0, false
+	}
+	b, err := strconv.ParseUint(tok[3:5], 16, 8)
+	return byte(b), err == nil
+}
+
+// fuseDecoder concatenates the tokens.
+type fuseDecoder struct{}
+
+func (fuseDecoder) decode(tokens []string) []string { return []string{strings.Join(tokens, "")} }
+
+// stripDecoder removes up to start leading and stop trailing occurrences of content from every token.
+type stripDecoder struct {
+	content     string
+	start, stop int
+}
+
+func (d stripDecoder) decode(tokens []string) []string {
+	retVal := make([]string, len(tokens))
+	for i, tok := range tokens {
+		for j := 0; j < d.start && strings.HasPrefix(tok, d.content); j++ {
+			tok = tok[len(d.content):]
+		}
+		for j := 0; j < d.stop && strings.HasSuffix(tok, d.content); j++ {
+			tok = tok[:len(tok)-len(d.content)]
+		}
+		retVal[i] = tok
+	}
+	return retVal
+}
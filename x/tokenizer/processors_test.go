@@ -0,0 +1,47 @@
+package tokenizer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTemplate(t *testing.T) {
+	assert := assert.New(t)
+	tmpl := template{
+		{ids: []int32{0}, tokens: []string{"<s>"}},
+		{typeID: 1},
+		{ids: []int32{2, 3}, tokens: []string{"</s>", "</s>"}, typeID: 1},
+	}
+	assert.Equal(3, tmpl.specials())
+	e := new(Encoding)
+	e.append(7, "a", 0, false)
+	e.append(8, "b", 0, false)
+	got := tmpl.apply(e)
+	assert.Equal([]int32{0, 7, 8, 2, 3}, got.IDs)
+	assert.Equal([]string{"<s>", "a", "b", "</s>", "</s>"}, got.Tokens)
+	assert.Equal([]int32{0, 1, 1, 1, 1}, got.TypeIDs)
+	assert.Equal([]int32{1, 0, 0, 1, 1}, got.SpecialTokensMask)
+	assert.Equal(e, template(nil).apply(e))
+}
+
+func TestDecoders(t *testing.T) {
+	assert := assert.New(t)
+	decode := func(d decoder, tokens ...string) string { return strings.Join(d.decode(tokens), "") }
+
+	assert.Equal("hello, world.", decode(wordPieceDecoder{prefix: "##", cleanup: true}, "hel", "##lo", ",", "world", "."))
+	assert.Equal("hello , world", decode(wordPieceDecoder{prefix: "##"}, "hel", "##lo", ",", "world"))
+	assert.Equal("hi é", decode(byteLevelDecoder{}, "hi", "ĠÃ©"))
+	assert.Equal("hi there", decode(metaspaceDecoder{replacement: "▁", prefix: true}, "▁hi", "▁the", "re"))
+	assert.Equal(" hi", decode(metaspaceDecoder{replacement: "▁"}, "▁hi"))
+	assert.Equal("low er", decode(bpeDecoder{suffix: "</w>"}, "low</w>", "er</w>"))
+	assert.Equal("a b", decode(replaceDecoder{"_", " "}, "a_b"))
+	assert.Equal([]string{"ab"}, fuseDecoder{}.decode([]string{"a", "b"}))
+	assert.Equal([]string{" a"}, stripDecoder{content: " ", start: 1, stop: 1}.decode([]string{"  a "}))
+	assert.Equal([]string{"a", "é", "b"}, byteFallbackDecoder{}.decode([]string{"a", "<0xC3>", "<0xA9>", "b"}))
+	assert.Equal([]string{"�", "�"}, byteFallbackDecoder{}.decode([]string{"<0xA9>", "<0xC3>"}), "invalid UTF-8")
+
+	seq := decoders{replaceDecoder{"▁", " "}, byteFallbackDecoder{}, fuseDecoder{}, stripDecoder{content: " ", start: 1}}
+	assert.Equal([]string{"hi €"}, seq.decode([]string{"▁hi", "▁", "<0xE2>", "<0x82>", "<0xAC>"}))
+}
@@ -0,0 +1,289 @@
+package tokenizer
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+	"gorgonia.org/tensor"
+)
+
+// Tokenizer encodes texts into token ids.
+//
+// A Tokenizer is safe for concurrent use, but its setters are not.
+type Tokenizer struct {
+	normalizer   normalizer
+	preTokenizer preTokenizer
+	model        model
+	post         template
+	decoder      decoder
+	added        []addedToken
+
+	padID     int32
+	maxLength int
+	padLength int
+}
+
+// addedToken is a token that is matched in the raw text before any other tokenization, like [CLS] or <|endoftext|>.
+type addedToken struct {
+	content string
+	id      int32
+	special bool
+	lstrip  bool // strips the whitespace on the left of the token
+	rstrip  bool // strips the whitespace on the right of the token
+}
+
+// Encoding is an encoded text.
+type Encoding struct {
+	IDs               []int32
+	Tokens            []string
+	TypeIDs           []int32
+	AttentionMask     []int32
+	SpecialTokensMask []int32
+}
+
+// Len returns the number of tokens.
+func (e *Encoding) Len() int { return len(e.IDs) }
+
+func (e *Encoding) append(id int32, tok string, typeID int32, special bool) {
+	e.IDs = append(e.IDs, id)
+	e.Tokens = append(e.Tokens, tok)
+	e.TypeIDs = append(e.TypeIDs, typeID)
+	e.AttentionMask = append(e.AttentionMask, 1)
+	if special {
+		e.SpecialTokensMask = append(e.SpecialTokensMask, 1)
+	} else {
+		e.SpecialTokensMask = append(e.SpecialTokensMask, 0)
+	}
+}
+
+func (e *Encoding) truncate(n int) {
+	if n < 0 {
+		n = 0
+	}
+	if n >= len(e.IDs) {
+		return
+	}
+	e.IDs = e.IDs[:n]
+	e.Tokens = e.Tokens[:n]
+	e.TypeIDs = e.TypeIDs[:n]
+	e.AttentionMask = e.AttentionMask[:n]
+	e.SpecialTokensMask = e.SpecialTokensMask[:n]
+}
+
+// Batch is a batch of encoded texts, padded to the same length.
+type Batch struct {
+	IDs           *tensor.Dense // (batch, length) Int32 token ids
+	AttentionMask *tensor.Dense // (batch, length) Int32 mask: 1 for the tokens, 0 for the padding
+	TypeIDs       *tensor.Dense // (batch, length) Int32 token type ids
+	Encodings     []*Encoding   // the encodings, without the padding
+}
+
+// SetTruncation sets the maximum number of tokens of an encoding, special tokens included. 0 disables the truncation.
+func (t *Tokenizer) SetTruncation(maxLength int) { t.maxLength = maxLength }
+
+// SetPadding sets the length the batches are padded to. 0 pads to the longest encoding of each batch.
+func (t *Tokenizer) SetPadding(length int) { t.padLength = length }
+
+// SetPadToken sets the token the batches are padded with.
+func (t *Tokenizer) SetPadToken(tok string) error {
+	id, ok := t.TokenID(tok)
+	if !ok {
+		return errors.Errorf("The pad token %q is not in the vocabulary", tok)
+	}
+	t.padID = id
+	return nil
+}
+
+// VocabSize returns the number of tokens in the vocabulary, added tokens included.
+func (t *Tokenizer) VocabSize() int {
+	n := len(t.model.vocabulary().ids)
+	for _, a := range t.added {
+		if _, ok := t.model.vocabulary().ids[a.content]; !ok {
+			n++
+		}
+	}
+	return n
+}
+
+// TokenID returns the id of a token.
+func (t *Tokenizer) TokenID(tok string) (int32, bool) {
+	if id, ok := t.model.vocabulary().ids[tok]; ok {
+		return id, true
+	}
+	for _, a := range t.added {
+		if a.content == tok {
+			return a.id, true
+		}
+	}
+	return 0, false
+}
+
+// Token returns the token of an id.
+func (t *Tokenizer) Token(id int32) (string, bool) {
+	if tok, ok := t.model.vocabulary().tokens[id]; ok {
+		return tok, true
+	}
+	for _, a := range t.added {
+		if a.id == id {
+			return a.content, true
+		}
+	}
+	return "", false
+}
+
+// Encode encodes a text, truncating it to the maximum length.
+func (t *Tokenizer) Encode(text string) (*Encoding, error) {
+	e := new(Encoding)
+	for i, p := range t.splitAdded(text) {
+		if p.added != nil {
+			e.append(p.added.id, p.added.content, 0, p.added.special)
+			continue
+		}
+		s := p.text
+		if t.normalizer != nil {
+			s = t.normalizer.normalize(s)
+		}
+		words := []string{s}
+		if t.preTokenizer != nil {
+			words = t.preTokenizer.split(s, i == 0)
+		}
+		for _, w := range words {
+			if w == "" {
+				continue
+			}
+			toks, err := t.model.tokenize(w)
+			if err != nil {
+				return nil, err
+			}
+			for _, tok := range toks {
+				id, ok := t.TokenID(tok)
+				if !ok {
+					return nil, errors.Errorf("The token %q is not in the vocabulary", tok)
+				}
+				e.append(id, tok, 0, false)
+			}
+		}
+	}
+	if t.maxLength > 0 {
+		e.truncate(t.maxLength - t.post.specials())
+	}
+	return t.post.apply(e), nil
+}
+
+// EncodeBatch encodes texts into (len(texts), length) tensors of token ids, padded to the same length.
+func (t *Tokenizer) EncodeBatch(texts []string) (*Batch, error) {
+	if len(texts) == 0 {
+		return nil, errors.New("Cannot encode an empty batch")
+	}
+	encodings := make([]*Encoding, len(texts))
+	length := t.padLength
+	for i, text := range texts {
+		e, err := t.Encode(text)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Failed to encode text %d", i)
+		}
+		if t.padLength > 0 && e.Len() > t.padLength {
+			return nil, errors.Errorf("Text %d has %d tokens, more than the padding length %d", i, e.Len(), t.padLength)
+		}
+		if e.Len() > length {
+			length = e.Len()
+		}
+		encodings[i] = e
+	}
+
+	ids := make([]int32, len(texts)*length)
+	mask := make([]int32, len(texts)*length)
+	typeIDs := make([]int32, len(texts)*length)
+	for i, e := range encodings {
+		row := i * length
+		copy(ids[row:], e.IDs)
+		copy(mask[row:], e.AttentionMask)
+		copy(typeIDs[row:], e.TypeIDs)
+		for j := e.Len(); j < length; j++ {
+			ids[row+j] = t.padID
+		}
+	}
+	return &Batch{
+		IDs:           tensor.New(tensor.WithShape(len(texts), length), tensor.WithBacking(ids)),
+		AttentionMask: tensor.New(tensor.WithShape(len(texts), length), tensor.WithBacking(mask)),
+		TypeIDs:       tensor.New(tensor.WithShape(len(texts), length), tensor.WithBacking(typeIDs)),
+		Encodings:     encodings,
+	}, nil
+}
+
+// Decode decodes token ids back into text, optionally skipping the special tokens.
+func (t *Tokenizer) Decode(ids []int32, skipSpecial bool) (string, error) {
+	tokens := make([]string, 0, len(ids))
+	for _, id := range ids {
+		tok, ok := t.Token(id)
+		if !ok {
+			return "", errors.Errorf("The id %d is not in the vocabulary", id)
+		}
+		if skipSpecial && t.isSpecial(id) {
+			continue
+		}
+		tokens = append(tokens, tok)
+	}
+	if t.decoder == nil {
+		return strings.Join(tokens, " "), nil
+	}
+	return strings.Join(t.decoder.decode(tokens), ""), nil
+}
+
+func (t *Tokenizer) isSpecial(id int32) bool {
+	for _, a := range t.added {
+		if a.id == id {
+			return a.special
+		}
+	}
+	for _, p := range t.post {
+		for _, special := range p.ids {
+			if special == id {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// piece is a piece of a text: either an added token, or the text between them.
+type piece struct {
+	text  string
+	added *addedToken
+}
+
+// splitAdded splits a text around the added tokens. At any position the longest added token is matched.
+func (t *Tokenizer) splitAdded(text string) []piece {
+	var retVal []piece
+	for len(text) > 0 {
+		at, match := -1, -1
+		for i, a := range t.added {
+			j := strings.Index(text, a.content)
+			if j < 0 || a.content == "" {
+				continue
+			}
+			if at < 0 || j < at || (j == at && len(a.content) > len(t.added[match].content)) {
+				at, match = j, i
+			}
+		}
+		if at < 0 {
+			retVal = append(retVal, piece{text: text})
+			break
+		}
+		a := &t.added[match]
+		before := text[:at]
+		if a.lstrip {
+			before = strings.TrimRightFunc(before, unicode.IsSpace)
+		}
+		if before != "" {
+			retVal = append(retVal, piece{text: before})
+		}
+		retVal = append(retVal, piece{added: a})
+		text = text[at+len(a.content):]
+		if a.rstrip {
+			text = strings.TrimLeftFunc(text, unicode.IsSpace)
+		}
+	}
+	return retVal
+}
@@ -0,0 +1,155 @@
+package tokenizer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorgonia.org/tensor"
+)
+
+const bertVocab = `[PAD]
+[UNK]
+[CLS]
+[SEP]
+[MASK]
+the
+cat
+sat
+on
+mat
+un
+##believ
+##able
+.
+,
+!`
+
+func newBert(t *testing.T) *Tokenizer {
+	tok, err := NewWordPiece(strings.NewReader(bertVocab), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tok
+}
+
+func TestTokenizer_Encode(t *testing.T) {
+	assert := assert.New(t)
+	tok := newBert(t)
+	assert.Equal(16, tok.VocabSize())
+
+	e, err := tok.Encode("The cat sat on the Mât, unbelievable!")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]string{"[CLS]", "the", "cat", "sat", "on", "the", "mat", ",", "un", "##believ", "##able", "!", "[SEP]"}, e.Tokens)
+	assert.Equal([]int32{2, 5, 6, 7, 8, 5, 9, 14, 10, 11, 12, 15, 3}, e.IDs)
+	assert.Equal([]int32{1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1}, e.SpecialTokensMask)
+	assert.Equal(13, e.Len())
+	for _, m := range e.AttentionMask {
+		assert.Equal(int32(1), m)
+	}
+
+	// unknown words and added tokens
+	e, err = tok.Encode("the dog [MASK]")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]string{"[CLS]", "the", "[UNK]", "[MASK]", "[SEP]"}, e.Tokens)
+
+	// truncation keeps the special tokens
+	tok.SetTruncation(4)
+	e, err = tok.Encode("the cat sat on the mat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]string{"[CLS]", "the", "cat", "[SEP]"}, e.Tokens)
+}
+
+func TestTokenizer_EncodeBatch(t *testing.T) {
+	assert := assert.New(t)
+	tok := newBert(t)
+	b, err := tok.EncodeBatch([]string{"the cat sat", "the mat"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(tensor.Shape{2, 5}, b.IDs.Shape())
+	assert.Equal(tensor.Int32, b.IDs.Dtype())
+	assert.Equal([]int32{2, 5, 6, 7, 3, 2, 5, 9, 3, 0}, b.IDs.Data())
+	assert.Equal([]int32{1, 1, 1, 1, 1, 1, 1, 1, 1, 0}, b.AttentionMask.Data())
+	assert.Equal(make([]int32, 10), b.TypeIDs.Data())
+	assert.Len(b.Encodings, 2)
+	assert.Equal(4, b.Encodings[1].Len())
+
+	// fixed padding
+	tok.SetPadding(6)
+	b, err = tok.EncodeBatch([]string{"the cat"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(tensor.Shape{1, 6}, b.IDs.Shape())
+	assert.Equal([]int32{1, 1, 1, 1, 0, 0}, b.AttentionMask.Data())
+
+	if err := tok.SetPadToken("[MASK]"); err != nil {
+		t.Fatal(err)
+	}
+	b, err = tok.EncodeBatch([]string{"the cat"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]int32{2, 5, 6, 3, 4, 4}, b.IDs.Data())
+
+	_, err = tok.EncodeBatch([]string{"the cat sat on the mat"})
+	assert.Error(err, "longer than the padding")
+	_, err = tok.EncodeBatch(nil)
+	assert.Error(err)
+	assert.Error(tok.SetPadToken("<pad>"))
+}
+
+func TestTokenizer_Decode(t *testing.T) {
+	assert := assert.New(t)
+	tok := newBert(t)
+	e, err := tok.Encode("the cat sat on the mat, unbelievable!")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := tok.Decode(e.IDs, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal("the cat sat on the mat, unbelievable!", s)
+
+	s, err = tok.Decode(e.IDs[:3], false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal("[CLS] the cat", s)
+
+	_, err = tok.Decode([]int32{100}, false)
+	assert.Error(err)
+
+	id, ok := tok.TokenID("##able")
+	assert.True(ok)
+	assert.Equal(int32(12), id)
+	word, ok := tok.Token(12)
+	assert.True(ok)
+	assert.Equal("##able", word)
+}
+
+func TestTokenizer_splitAdded(t *testing.T) {
+	assert := assert.New(t)
+	tok := &Tokenizer{added: []addedToken{
+		{content: "<s>", id: 0},
+		{content: "<sep>", id: 1, lstrip: true, rstrip: true},
+		{content: "<sep><sep>", id: 2},
+	}}
+	var got []string
+	for _, p := range tok.splitAdded("<s>a b <sep> c<sep><sep>") {
+		if p.added != nil {
+			got = append(got, "#"+p.added.content)
+			continue
+		}
+		got = append(got, p.text)
+	}
+	assert.Equal([]string{"#<s>", "a b", "#<sep>", "c", "#<sep><sep>"}, got)
+}
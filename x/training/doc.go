@@ -0,0 +1,29 @@
+// Package training provides the snapshots of the state of a training, so that a long-running job that is preempted
+// may resume where it was, and train on as it would have: the weights of the model, the state of the solver, the
+// states of the random nodes of the graph, the step of the schedule and the cursor of the dataset.
+//
+// The state is that of the job, which is snapshotted between the steps:
+//		st := &training.State{Model: model, Solver: solver, Graph: g}
+//		for st.Step < steps {
+//			// run the machine on the batch at st.Cursor, and step the solver
+//			st.Step++
+//			st.Cursor += batchSize
+//			if st.Step%1000 == 0 {
+//				// write the snapshot to a temporary file, which is then renamed over the last one
+//				err = st.Snapshot(f)
+//				...
+//			}
+//		}
+//
+// and restored by the job that resumes it, with the same graph, model and solver built again by the same code:
+//		st := &training.State{Model: model, Solver: solver, Graph: g}
+//		if err = st.Restore(f); err != nil {
+//			...
+//		}
+//		for st.Step < steps {
+//
+// The step and the cursor are those of the job: the learn rate of its schedule, and the batch of its dataset, are
+// computed from them. The random nodes whose values are drawn when they are created, such as those of
+// gorgonia.UniformRandomNode, have no state to snapshot, and neither has the source of the noise of a
+// gorgonia.DPSGDSolver, which is the job's own.
+package training
@@ -0,0 +1,117 @@
+package training
+
+import (
+	"encoding/gob"
+	"io"
+
+	"github.com/pkg/errors"
+	"gorgonia.org/gorgonia"
+)
+
+// version is the version of the format of the snapshots.
+const version = 1
+
+// State is the state of a training.
+type State struct {
+	Model  []gorgonia.ValueGrad
+	Solver gorgonia.Solver     // may be nil
+	Graph  *gorgonia.ExprGraph // the graph of the random nodes, if any
+
+	Step   int   // the step of the schedule
+	Cursor int64 // the position in the dataset
+}
+
+// snapshot is what is written by Snapshot.
+type snapshot struct {
+	Version int
+	Weights [][]byte // in the ProtoEncoding of gorgonia.MarshalValue
+	Solver  []byte
+	Random  []gorgonia.RandomState
+	Step    int
+	Cursor  int64
+}
+
+// Snapshot writes the state to w. The snapshots of the same state are the same bytes.
+func (s *State) Snapshot(w io.Writer) (err error) {
+	snap := snapshot{Version: version, Weights: make([][]byte, len(s.Model)), Step: s.Step, Cursor: s.Cursor}
+	for i, vg := range s.Model {
+		v := vg.Value()
+		if v == nil {
+			return errors.Errorf("Element %d of the model has no value", i)
+		}
+		if snap.Weights[i], err = gorgonia.MarshalValue(v, gorgonia.ProtoEncoding); err != nil {
+			return errors.Wrapf(err, "Element %d of the model", i)
+		}
+	}
+	if s.Solver != nil {
+		if snap.Solver, err = gorgonia.MarshalSolver(s.Solver); err != nil {
+			return err
+		}
+	}
+	if s.Graph != nil {
+		snap.Random = s.Graph.RandomStates()
+	}
+	return errors.Wrap(gob.NewEncoder(w).Encode(snap), "Failed to write the snapshot")
+}
+
+// Restore reads a snapshot written by Snapshot into the state. The weights are copied into the values of the model,
+// which must have the shapes and types of the weights of the snapshot. Nothing is restored if the snapshot is not that
+// of the state.
+func (s *State) Restore(r io.Reader) error {
+	var snap snapshot
+	if err := gob.NewDecoder(r).Decode(&snap); err != nil {
+		return errors.Wrap(err, "Failed to read the snapshot")
+	}
+	if snap.Version != version {
+		return errors.Errorf("Cannot restore a snapshot of version %d", snap.Version)
+	}
+	if len(snap.Weights) != len(s.Model) {
+		return errors.Errorf("Expected a snapshot of a model of %d elements. Got %d instead", len(s.Model), len(snap.Weights))
+	}
+	if (snap.Solver != nil) != (s.Solver != nil) {
+		return errors.New("Expected the snapshot and the state to both have a solver, or both have none")
+	}
+
+	weights := make([]gorgonia.Value, len(snap.Weights))
+	for i, p := range snap.Weights {
+		w, err := gorgonia.UnmarshalValue(p, gorgonia.ProtoEncoding)
+		if err != nil {
+			return errors.Wrapf(err, "Element %d of the model", i)
+		}
+		v := s.Model[i].Value()
+		if v == nil {
+			return errors.Errorf("Element %d of the model has no value", i)
+		}
+		if !v.Shape().Eq(w.Shape()) || v.Dtype() != w.Dtype() {
+			return errors.Errorf("Element %d of the model is a %v of shape %v. The snapshot has a %v of shape %v", i, v.Dtype(), v.Shape(), w.Dtype(), w.Shape())
+		}
+		weights[i] = w
+	}
+	var randomNodes int
+	if s.Graph != nil {
+		randomNodes = len(s.Graph.RandomStates())
+	}
+	if len(snap.Random) != randomNodes {
+		return errors.Errorf("Expected the states of %d random nodes. The snapshot has %d", randomNodes, len(snap.Random))
+	}
+
+	// the solver is restored first, as it is the last part that may fail to decode: UnmarshalSolver restores nothing
+	// then
+	if s.Solver != nil {
+		if err := gorgonia.UnmarshalSolver(s.Solver, snap.Solver); err != nil {
+			return err
+		}
+	}
+	if s.Graph != nil {
+		if err := s.Graph.SetRandomStates(snap.Random); err != nil {
+			return err
+		}
+	}
+	for i, w := range weights {
+		if _, err := gorgonia.Copy(s.Model[i].Value(), w); err != nil {
+			return errors.Wrapf(err, "Element %d of the model", i)
+		}
+	}
+	s.Step, s.Cursor = snap.Step, snap.Cursor
+	return nil
+}
@@ -0,0 +1,141 @@
+package training
+
+import (
+	"bytes"
+	"encoding/gob"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorgonia.org/gorgonia"
+	"gorgonia.org/tensor"
+)
+
+// job is a training of a model with noisy inputs, built the same way by every run of the job.
+type job struct {
+	st *State
+	x  *gorgonia.Node
+	m  gorgonia.VM
+}
+
+func newJob(t *testing.T) *job {
+	g := gorgonia.NewGraph()
+	x := gorgonia.NewVector(g, gorgonia.Float64, gorgonia.WithShape(3), gorgonia.WithName("x"), gorgonia.WithValue(tensor.New(tensor.WithShape(3), tensor.Of(tensor.Float64))))
+	w := gorgonia.NewVector(g, gorgonia.Float64, gorgonia.WithShape(3), gorgonia.WithName("w"), gorgonia.WithValue(tensor.New(tensor.WithBacking([]float64{0.5, -0.5, 1}))))
+	noise, err := gorgonia.RandomNormal(g, gorgonia.Float64, 0, 0.1, tensor.Shape{3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	loss := gorgonia.Must(gorgonia.Sum(gorgonia.Must(gorgonia.Square(gorgonia.Must(gorgonia.Add(gorgonia.Must(gorgonia.HadamardProd(x, w)), noise))))))
+	if _, err = gorgonia.Grad(loss, w); err != nil {
+		t.Fatal(err)
+	}
+	model := gorgonia.NodesToValueGrads(gorgonia.Nodes{w})
+	solver := gorgonia.NewAdamSolver(gorgonia.WithLearnRate(0.1))
+	return &job{
+		st: &State{Model: model, Solver: solver, Graph: g},
+		x:  x,
+		m:  gorgonia.NewTapeMachine(g, gorgonia.BindDualValues(w)),
+	}
+}
+
+// step trains on the batch at the cursor.
+func (j *job) step(t *testing.T) {
+	batch := j.x.Value().(*tensor.Dense)
+	for i := 0; i < 3; i++ {
+		batch.Set(i, float64(j.st.Cursor+int64(i)))
+	}
+	if err := j.m.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+	if err := j.st.Solver.Step(j.st.Model); err != nil {
+		t.Fatal(err)
+	}
+	j.m.Reset()
+	j.st.Step++
+	j.st.Cursor = (j.st.Cursor + 3) % 10
+}
+
+func (j *job) snapshot(t *testing.T) []byte {
+	var buf bytes.Buffer
+	if err := j.st.Snapshot(&buf); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestState(t *testing.T) {
+	assert := assert.New(t)
+	j := newJob(t)
+	defer j.m.Close()
+	for i := 0; i < 5; i++ {
+		j.step(t)
+	}
+	snap := j.snapshot(t)
+	assert.Equal(snap, j.snapshot(t))
+	for i := 0; i < 3; i++ {
+		j.step(t)
+	}
+
+	// the job is preempted, and resumed by another
+	resumed := newJob(t)
+	defer resumed.m.Close()
+	if err := resumed.st.Restore(bytes.NewReader(snap)); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(5, resumed.st.Step)
+	assert.Equal(int64(5), resumed.st.Cursor)
+	assert.Equal(snap, resumed.snapshot(t))
+	for i := 0; i < 3; i++ {
+		resumed.step(t)
+	}
+	assert.Equal(j.st.Model[0].Value().Data(), resumed.st.Model[0].Value().Data())
+	assert.Equal(j.snapshot(t), resumed.snapshot(t))
+}
+
+func TestState_errors(t *testing.T) {
+	assert := assert.New(t)
+	j := newJob(t)
+	defer j.m.Close()
+	j.step(t)
+	snap := j.snapshot(t)
+
+	// a model of another shape
+	g := gorgonia.NewGraph()
+	w := gorgonia.NewVector(g, gorgonia.Float64, gorgonia.WithShape(2), gorgonia.WithName("w"), gorgonia.WithValue(tensor.New(tensor.WithBacking([]float64{1, 2}))))
+	other := &State{Model: gorgonia.NodesToValueGrads(gorgonia.Nodes{w}), Solver: gorgonia.NewAdamSolver()}
+	assert.Error(other.Restore(bytes.NewReader(snap)))
+	assert.Equal([]float64{1, 2}, w.Value().Data())
+
+	// no solver, and no graph of the random nodes
+	other.Solver = nil
+	assert.Error(other.Restore(bytes.NewReader(snap)))
+	assert.Error((&State{}).Restore(bytes.NewReader(snap)))
+	assert.Error((&State{}).Restore(bytes.NewReader([]byte("garbage"))))
+}
+
+func TestState_corruptSolver(t *testing.T) {
+	assert := assert.New(t)
+	j := newJob(t)
+	defer j.m.Close()
+	for i := 0; i < 3; i++ {
+		j.step(t)
+	}
+	var snap snapshot
+	if err := gob.NewDecoder(bytes.NewReader(j.snapshot(t))).Decode(&snap); err != nil {
+		t.Fatal(err)
+	}
+	snap.Solver = snap.Solver[:len(snap.Solver)/2]
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(snap); err != nil {
+		t.Fatal(err)
+	}
+
+	// nothing of the snapshot is restored: neither the random states, nor the solver, nor the weights
+	resumed := newJob(t)
+	defer resumed.m.Close()
+	random := resumed.st.Graph.RandomStates()
+	before := resumed.snapshot(t)
+	assert.Error(resumed.st.Restore(&buf))
+	assert.Equal(random, resumed.st.Graph.RandomStates())
+	assert.Equal(before, resumed.snapshot(t))
+}
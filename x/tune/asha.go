@@ -0,0 +1,64 @@
+package tune
+
+import (
+	"math"
+	"sort"
+	"sync"
+)
+
+// asha is the asynchronous successive halving of the trials. The rungs are at the resources (such as the epochs)
+// min·η^k. A trial that reaches a rung goes on if its score is in the best 1/η of the scores recorded at the rung so
+// far, and is stopped otherwise. As the first trials to reach a rung are compared to few others, they are seldom
+// stopped; the more trials are run, the more aggressive the stopping.
+type asha struct {
+	min      int
+	eta      float64
+	minimize bool
+
+	sync.Mutex
+	rungs [][]float64
+}
+
+// reporter returns the Reporter of a trial.
+func (a *asha) reporter() Reporter {
+	next := 0 // the next rung of the trial
+	return func(resource int, score float64) bool {
+		for a.rung(next) <= float64(resource) {
+			if !a.promote(next, score) {
+				return false
+			}
+			next++
+		}
+		return true
+	}
+}
+
+func (a *asha) rung(k int) float64 { return float64(a.min) * math.Pow(a.eta, float64(k)) }
+
+// promote records the score at the rung, and returns true if it is in the best 1/η.
+func (a *asha) promote(k int, score float64) bool {
+	a.Lock()
+	defer a.Unlock()
+	for len(a.rungs) <= k {
+		a.rungs = append(a.rungs, nil)
+	}
+	a.rungs[k] = append(a.rungs[k], score)
+	scores := append([]float64(nil), a.rungs[k]...)
+	if a.minimize {
+		sort.Float64s(scores)
+	} else {
+		sort.Sort(sort.Reverse(sort.Float64Slice(scores)))
+	}
+	kept := int(math.Ceil(float64(len(scores)) / a.eta))
+	return !a.worse(score, scores[kept-1])
+}
+
+func (a *asha) worse(score, than float64) bool {
+	if math.IsNaN(score) {
+		return true
+	}
+	if a.minimize {
+		return score > than
+	}
+	return score < than
+}
@@ -0,0 +1,34 @@
+package tune
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestASHA(t *testing.T) {
+	assert := assert.New(t)
+	a := &asha{min: 1, eta: 3, minimize: true}
+	assert.Equal([]float64{1, 3, 9}, []float64{a.rung(0), a.rung(1), a.rung(2)})
+
+	first := a.reporter()
+	assert.True(first(1, 10), "the first trial at a rung always goes on")
+	assert.True(first(2, 9), "no rung between 1 and 3")
+	second := a.reporter()
+	assert.True(second(1, 2))
+	third := a.reporter()
+	assert.False(third(1, 8), "only the best third goes on")
+	fourth := a.reporter()
+	assert.True(fourth(1, 2), "ties go on")
+
+	// a report past several rungs records the score at every one of them
+	fifth := a.reporter()
+	assert.True(fifth(9, 1))
+	assert.Len(a.rungs, 3)
+	assert.Equal([]float64{1}, a.rungs[2])
+
+	max := &asha{min: 1, eta: 2}
+	assert.True(max.reporter()(1, 0.5))
+	assert.False(max.reporter()(1, 0.4))
+	assert.True(max.reporter()(1, 0.9))
+}
@@ -0,0 +1,27 @@
+// Package tune provides a lightweight search of the hyperparameters of a model: the configurations of a grid or random
+// search are tried in parallel, and the unpromising trials are stopped early by the asynchronous successive halving
+// algorithm (ASHA) of Li et al. (2018).
+//
+// A trial is a function that builds the graph of the configuration, trains it, and reports its score along the way,
+// so that it may be stopped:
+//		space := tune.Space{
+//			"lr":     tune.LogUniform(1e-4, 1e-1),
+//			"hidden": tune.Choice(64, 128, 256),
+//		}
+//		trial := func(ctx context.Context, cfg tune.Config, report tune.Reporter) (float64, error) {
+//			g := gorgonia.NewGraph()
+//			// build the model with cfg.Int("hidden"), and a solver with cfg.Float("lr")
+//			var loss float64
+//			for epoch := 1; epoch <= epochs; epoch++ {
+//				// train for an epoch, then evaluate the loss
+//				if !report(epoch, loss) {
+//					break // pruned
+//				}
+//			}
+//			return loss, nil
+//		}
+//		res, err := tune.Run(ctx, tune.Random(space, 50, rand.New(rand.NewSource(0))), trial, tune.WithWorkers(4), tune.WithASHA(1, 3))
+//
+// The trials run in goroutines. A trial that must run in its own process, such as one that uses a GPU, may start the
+// process with os/exec and report the scores printed by it.
+package tune
@@ -0,0 +1,140 @@
+package tune
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// Param is the range of the values of a hyperparameter.
+type Param interface {
+	// Values returns the values of the grid search, or nil if the range is continuous.
+	Values() []interface{}
+	// Sample returns a value of the random search.
+	Sample(r *rand.Rand) interface{}
+}
+
+// Space is the ranges of the hyperparameters, by name.
+type Space map[string]Param
+
+// Choice is a hyperparameter that takes one of the values, such as the names of the solvers.
+func Choice(values ...interface{}) Param { return choice(values) }
+
+// Uniform is a float hyperparameter uniformly distributed in [lo, hi).
+func Uniform(lo, hi float64) Param { return uniform{lo, hi, false} }
+
+// LogUniform is a float hyperparameter whose logarithm is uniformly distributed, as is fitting for the learning rates
+// and the regularization strengths. lo and hi must be positive.
+func LogUniform(lo, hi float64) Param { return uniform{lo, hi, true} }
+
+// IntRange is an int hyperparameter uniformly distributed in [lo, hi]. Its grid is all of the ints of the range.
+func IntRange(lo, hi int) Param { return intRange{lo, hi} }
+
+type choice []interface{}
+
+func (p choice) Values() []interface{}           { return p }
+func (p choice) Sample(r *rand.Rand) interface{} { return p[r.Intn(len(p))] }
+
+type uniform struct {
+	lo, hi float64
+	log    bool
+}
+
+func (p uniform) Values() []interface{} { return nil }
+
+func (p uniform) Sample(r *rand.Rand) interface{} {
+	if p.log {
+		return math.Exp(math.Log(p.lo) + r.Float64()*(math.Log(p.hi)-math.Log(p.lo)))
+	}
+	return p.lo + r.Float64()*(p.hi-p.lo)
+}
+
+type intRange struct{ lo, hi int }
+
+func (p intRange) Values() []interface{} {
+	retVal := make([]interface{}, 0, p.hi-p.lo+1)
+	for i := p.lo; i <= p.hi; i++ {
+		retVal = append(retVal, i)
+	}
+	return retVal
+}
+
+func (p intRange) Sample(r *rand.Rand) interface{} { return p.lo + r.Intn(p.hi-p.lo+1) }
+
+// Config is a configuration of the hyperparameters, by name.
+type Config map[string]interface{}
+
+// Float returns the hyperparameter as a float64, converting the ints. It returns 0 if there is no such number.
+func (c Config) Float(name string) float64 {
+	switch v := c[name].(type) {
+	case float64:
+		return v
+	case float32:
+		return float64(v)
+	case int:
+		return float64(v)
+	}
+	return 0
+}
+
+// Int returns the hyperparameter as an int. It returns 0 if there is no such int.
+func (c Config) Int(name string) int {
+	v, _ := c[name].(int)
+	return v
+}
+
+// String returns the hyperparameter as a string. It returns "" if there is no such string.
+func (c Config) String(name string) string {
+	v, _ := c[name].(string)
+	return v
+}
+
+// names returns the names of the space, sorted so that the searches are deterministic.
+func (s Space) names() []string {
+	retVal := make([]string, 0, len(s))
+	for name := range s {
+		retVal = append(retVal, name)
+	}
+	sort.Strings(retVal)
+	return retVal
+}
+
+// Grid returns every combination of the values of the hyperparameters, the last of them in alphabetical order varying
+// the fastest. The continuous hyperparameters cannot be searched by a grid.
+func Grid(space Space) ([]Config, error) {
+	names := space.names()
+	values := make([][]interface{}, len(names))
+	total := 1
+	for i, name := range names {
+		if values[i] = space[name].Values(); len(values[i]) == 0 {
+			return nil, errors.Errorf("Cannot search the hyperparameter %q by a grid: it has no values", name)
+		}
+		total *= len(values[i])
+	}
+	retVal := make([]Config, total)
+	for c := range retVal {
+		cfg := make(Config, len(names))
+		for i, j := len(names)-1, c; i >= 0; i-- {
+			cfg[names[i]] = values[i][j%len(values[i])]
+			j /= len(values[i])
+		}
+		retVal[c] = cfg
+	}
+	return retVal, nil
+}
+
+// Random returns n configurations of hyperparameters sampled independently.
+func Random(space Space, n int, r *rand.Rand) []Config {
+	names := space.names()
+	retVal := make([]Config, n)
+	for c := range retVal {
+		cfg := make(Config, len(names))
+		for _, name := range names {
+			cfg[name] = space[name].Sample(r)
+		}
+		retVal[c] = cfg
+	}
+	return retVal
+}
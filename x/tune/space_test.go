@@ -0,0 +1,64 @@
+package tune
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGrid(t *testing.T) {
+	assert := assert.New(t)
+	configs, err := Grid(Space{
+		"solver": Choice("adam", "sgd"),
+		"layers": IntRange(1, 3),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(configs, 6)
+	assert.Equal(Config{"layers": 1, "solver": "adam"}, configs[0])
+	assert.Equal(Config{"layers": 1, "solver": "sgd"}, configs[1])
+	assert.Equal(Config{"layers": 3, "solver": "sgd"}, configs[5])
+
+	_, err = Grid(Space{"lr": Uniform(0, 1)})
+	assert.Error(err, "a continuous range has no grid")
+}
+
+func TestRandom(t *testing.T) {
+	assert := assert.New(t)
+	space := Space{
+		"lr":      LogUniform(1e-4, 1e-1),
+		"dropout": Uniform(0, 0.5),
+		"hidden":  Choice(64, 128),
+		"layers":  IntRange(1, 3),
+	}
+	configs := Random(space, 200, rand.New(rand.NewSource(1)))
+	assert.Len(configs, 200)
+	var small int
+	for _, cfg := range configs {
+		lr := cfg.Float("lr")
+		assert.True(lr >= 1e-4 && lr < 1e-1)
+		if lr < 1e-2 {
+			small++
+		}
+		assert.True(cfg.Float("dropout") >= 0 && cfg.Float("dropout") < 0.5)
+		assert.Contains([]int{64, 128}, cfg.Int("hidden"))
+		assert.True(cfg.Int("layers") >= 1 && cfg.Int("layers") <= 3)
+	}
+	// two decades out of three are below 1e-2
+	assert.InDelta(2.0/3, float64(small)/200, 0.1)
+
+	assert.Equal(configs, Random(space, 200, rand.New(rand.NewSource(1))), "the searches are deterministic")
+}
+
+func TestConfig(t *testing.T) {
+	assert := assert.New(t)
+	cfg := Config{"lr": 0.1, "layers": 2, "solver": "adam"}
+	assert.Equal(0.1, cfg.Float("lr"))
+	assert.Equal(2.0, cfg.Float("layers"))
+	assert.Equal(2, cfg.Int("layers"))
+	assert.Equal("adam", cfg.String("solver"))
+	assert.Equal(0, cfg.Int("lr"))
+	assert.Equal("", cfg.String("missing"))
+}
@@ -0,0 +1,195 @@
+package tune
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"runtime"
+	"sort"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Reporter reports the score of a trial after using the given resource, such as a number of epochs, and returns
+// false if the trial should stop. Without early stopping, it always returns true.
+type Reporter func(resource int, score float64) bool
+
+// Trial trains a model with the configuration, and returns its final score.
+type Trial func(ctx context.Context, cfg Config, report Reporter) (float64, error)
+
+// Result is the result of a trial.
+type Result struct {
+	ID       int // the index of the configuration
+	Config   Config
+	Score    float64
+	Resource int  // the last resource reported
+	Pruned   bool // stopped early
+	Err      error
+	Duration time.Duration
+}
+
+// Results is the results of a search, in the order of the configurations.
+type Results struct {
+	Trials   []Result
+	minimize bool
+}
+
+// Best returns the best of the trials that were neither stopped early nor failed, or nil if there is none.
+func (r *Results) Best() *Result {
+	var best *Result
+	for i := range r.Trials {
+		t := &r.Trials[i]
+		if t.Pruned || t.Err != nil || math.IsNaN(t.Score) {
+			continue
+		}
+		if best == nil || (r.minimize && t.Score < best.Score) || (!r.minimize && t.Score > best.Score) {
+			best = t
+		}
+	}
+	return best
+}
+
+// String returns a table of the trials, the best first.
+func (r *Results) String() string {
+	trials := append([]Result(nil), r.Trials...)
+	sort.SliceStable(trials, func(i, j int) bool {
+		a, b := trials[i], trials[j]
+		if ok := a.Err == nil && !a.Pruned; ok != (b.Err == nil && !b.Pruned) {
+			return ok
+		}
+		if r.minimize {
+			return a.Score < b.Score
+		}
+		return a.Score > b.Score
+	})
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "Trial\tScore\tResource\tStatus\tConfig\t")
+	for _, t := range trials {
+		status := "done"
+		switch {
+		case t.Err != nil:
+			status = "failed: " + t.Err.Error()
+		case t.Pruned:
+			status = "pruned"
+		}
+		fmt.Fprintf(w, "%d\t%g\t%d\t%s\t%v\t\n", t.ID, t.Score, t.Resource, status, map[string]interface{}(t.Config))
+	}
+	w.Flush()
+	return buf.String()
+}
+
+// Opt is an option of Run.
+type Opt func(*search)
+
+// WithWorkers sets the number of trials run at the same time. It defaults to the number of CPUs.
+func WithWorkers(n int) Opt {
+	return func(s *search) { s.workers = n }
+}
+
+// WithMaximize makes the search maximize the scores, such as accuracies. By default the scores are losses, which are
+// minimized.
+func WithMaximize() Opt {
+	return func(s *search) { s.maximize = true }
+}
+
+// WithASHA stops the unpromising trials early with the asynchronous successive halving algorithm: the trials are
+// compared when they report the resources min·η^k, and only the best 1/η of them go on at every rung. η is usually 3
+// or 4.
+func WithASHA(min int, eta float64) Opt {
+	return func(s *search) { s.asha = &asha{min: min, eta: eta} }
+}
+
+type search struct {
+	workers  int
+	maximize bool
+	asha     *asha
+}
+
+// Run runs the trial with every configuration, and returns their results. The search stops dispatching trials when
+// the context is done. An error is returned if no trial completed.
+func Run(ctx context.Context, configs []Config, trial Trial, opts ...Opt) (*Results, error) {
+	s := search{workers: runtime.NumCPU()}
+	for _, opt := range opts {
+		opt(&s)
+	}
+	if s.workers < 1 {
+		return nil, errors.Errorf("Expected a positive number of workers. Got %d instead", s.workers)
+	}
+	if s.asha != nil {
+		if s.asha.min < 1 || s.asha.eta <= 1 {
+			return nil, errors.Errorf("Expected a positive minimum resource and a reduction factor greater than 1. Got %d and %v instead", s.asha.min, s.asha.eta)
+		}
+		s.asha.minimize = !s.maximize
+	}
+
+	res := &Results{Trials: make([]Result, len(configs)), minimize: !s.maximize}
+	ids := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < s.workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for id := range ids {
+				res.Trials[id] = s.run(ctx, id, configs[id], trial)
+			}
+		}()
+	}
+	dispatched := len(configs)
+dispatch:
+	for id := range configs {
+		if ctx.Err() != nil {
+			dispatched = id
+			break
+		}
+		select {
+		case ids <- id:
+		case <-ctx.Done():
+			dispatched = id
+			break dispatch
+		}
+	}
+	close(ids)
+	wg.Wait()
+	res.Trials = res.Trials[:dispatched]
+
+	if res.Best() == nil {
+		if err := ctx.Err(); err != nil {
+			return res, err
+		}
+		return res, errors.Errorf("None of the %d trials completed", len(res.Trials))
+	}
+	return res, nil
+}
+
+func (s *search) run(ctx context.Context, id int, cfg Config, trial Trial) (retVal Result) {
+	retVal = Result{ID: id, Config: cfg}
+	var stop Reporter
+	if s.asha != nil {
+		stop = s.asha.reporter()
+	}
+	report := func(resource int, score float64) bool {
+		retVal.Resource, retVal.Score = resource, score
+		if stop != nil && !stop(resource, score) {
+			retVal.Pruned = true
+		}
+		return !retVal.Pruned
+	}
+	start := time.Now()
+	defer func() {
+		if r := recover(); r != nil {
+			retVal.Err = errors.Errorf("Trial panicked: %v", r)
+		}
+		retVal.Duration = time.Since(start)
+	}()
+	score, err := trial(ctx, cfg, report)
+	if !retVal.Pruned {
+		retVal.Score = score
+	}
+	retVal.Err = err
+	return retVal
+}
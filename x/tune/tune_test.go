@@ -0,0 +1,116 @@
+package tune
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+// quadratic is a trial whose loss is (x-3)², at the end of the training.
+func quadratic(ctx context.Context, cfg Config, report Reporter) (float64, error) {
+	x := cfg.Float("x")
+	return (x - 3) * (x - 3), nil
+}
+
+func TestRun(t *testing.T) {
+	assert := assert.New(t)
+	configs, err := Grid(Space{"x": IntRange(0, 6)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := Run(context.Background(), configs, quadratic, WithWorkers(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(res.Trials, 7)
+	for i, tr := range res.Trials {
+		assert.Equal(i, tr.ID)
+		assert.Equal(float64((i-3)*(i-3)), tr.Score)
+	}
+	assert.Equal(Config{"x": 3}, res.Best().Config)
+	assert.True(strings.HasPrefix(strings.Split(res.String(), "\n")[1], "3 "), "the best trial comes first")
+
+	// maximize, and the failed trials are not the best
+	failing := func(ctx context.Context, cfg Config, report Reporter) (float64, error) {
+		if cfg.Int("x") == 6 {
+			return 100, errors.New("diverged")
+		}
+		if cfg.Int("x") == 5 {
+			panic("oops")
+		}
+		return quadratic(ctx, cfg, report)
+	}
+	if res, err = Run(context.Background(), configs, failing, WithWorkers(2), WithMaximize()); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(Config{"x": 0}, res.Best().Config)
+	assert.Error(res.Trials[5].Err)
+	assert.Error(res.Trials[6].Err)
+	assert.Contains(res.String(), "failed: diverged")
+
+	// errors
+	_, err = Run(context.Background(), configs, quadratic, WithWorkers(0))
+	assert.Error(err)
+	_, err = Run(context.Background(), configs, quadratic, WithASHA(1, 1))
+	assert.Error(err)
+	res, err = Run(context.Background(), configs[:1], failing)
+	assert.NoError(err)
+	res, err = Run(context.Background(), configs[6:], failing)
+	assert.Error(err, "no trial completed")
+	assert.Len(res.Trials, 1)
+}
+
+func TestRunASHA(t *testing.T) {
+	assert := assert.New(t)
+	configs := []Config{{"x": 5.0}, {"x": 1.0}, {"x": 4.0}, {"x": 2.0}, {"x": 3.0}}
+	var epochs int64
+	// the losses decrease with the epochs, in the order of x
+	trial := func(ctx context.Context, cfg Config, report Reporter) (loss float64, err error) {
+		for epoch := 1; epoch <= 9; epoch++ {
+			atomic.AddInt64(&epochs, 1)
+			loss = cfg.Float("x") * (1 + 1/float64(epoch))
+			if !report(epoch, loss) {
+				break
+			}
+		}
+		return loss, nil
+	}
+	res, err := Run(context.Background(), configs, trial, WithWorkers(1), WithASHA(1, 3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var pruned []int
+	for _, tr := range res.Trials {
+		if tr.Pruned {
+			pruned = append(pruned, tr.ID)
+		}
+	}
+	assert.Equal([]int{2, 3, 4}, pruned)
+	assert.Equal(1, res.Trials[2].Resource)
+	assert.Equal(3, res.Trials[3].Resource)
+	assert.Equal(8.0, res.Trials[2].Score, "the score of a pruned trial is the last reported")
+	best := res.Best()
+	assert.Equal(1, best.ID)
+	assert.InDelta(1+1.0/9, best.Score, 1e-12)
+	assert.Equal(int64(9+9+1+3+1), epochs)
+}
+
+func TestRunCancel(t *testing.T) {
+	assert := assert.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	var started int64
+	trial := func(ctx context.Context, cfg Config, report Reporter) (float64, error) {
+		if atomic.AddInt64(&started, 1) == 2 {
+			cancel()
+		}
+		return cfg.Float("x"), nil
+	}
+	res, err := Run(ctx, []Config{{"x": 1.0}, {"x": 2.0}, {"x": 3.0}, {"x": 4.0}}, trial, WithWorkers(1))
+	assert.NoError(err, "some trials completed")
+	assert.Len(res.Trials, 2, "no trial is dispatched once the context is done")
+	assert.Equal(1.0, res.Best().Score)
+}
@@ -0,0 +1,8 @@
+// Package unsupervised provides classic unsupervised algorithms that work directly on *tensor.Dense, without building
+// a graph: mini-batch k-means clustering and randomized principal component analysis. They are commonly needed to
+// preprocess the inputs of a model.
+//
+// The heavy lifting is done by matrix multiplications of the tensor package, so the algorithms benefit from the BLAS
+// implementation it uses (see tensor.Use). Float64 and Float32 data are supported; the computations are carried out in
+// float64.
+package unsupervised
@@ -0,0 +1,305 @@
+package unsupervised
+
+import (
+	"math"
+
+	"github.com/pkg/errors"
+	"gorgonia.org/tensor"
+)
+
+// KMeans clusters the rows of a matrix around k centroids.
+//
+// The centroids are initialized with k-means++. Fit then runs Lloyd's algorithm, or the mini-batch algorithm of
+// Sculley (2010) when a batch size is given, which scales to matrices that are too big to be fully reassigned at every
+// iteration. PartialFit updates the centroids with one mini-batch, for data that arrives in a stream.
+type KMeans struct {
+	k   int
+	cfg config
+
+	centroids []float64 // (k, d)
+	counts    []float64 // the number of rows assigned to each centroid by the mini-batch updates
+	d         int
+	dt        tensor.Dtype
+}
+
+// NewKMeans creates a k-means clustering of k clusters.
+func NewKMeans(k int, opts ...Opt) (*KMeans, error) {
+	if k < 1 {
+		return nil, errors.Errorf("Expected at least one cluster. Got %d instead", k)
+	}
+	cfg := newConfig(opts)
+	if cfg.batchSize < 0 || cfg.maxIter < 1 {
+		return nil, errors.Errorf("Expected a non-negative batch size and a positive number of iterations. Got %d and %d instead", cfg.batchSize, cfg.maxIter)
+	}
+	return &KMeans{k: k, cfg: cfg}, nil
+}
+
+// Centroids returns the (k, d) matrix of the centroids, or nil before the first fit.
+func (km *KMeans) Centroids() *tensor.Dense {
+	if km.centroids == nil {
+		return nil
+	}
+	return newDense(km.dt, tensor.Shape{km.k, km.d}, km.centroids)
+}
+
+// Fit clusters the rows of x, starting from new centroids.
+func (km *KMeans) Fit(x *tensor.Dense) error {
+	x64, err := float64s(x)
+	if err != nil {
+		return err
+	}
+	n := x64.Shape()[0]
+	if n < km.k {
+		return errors.Errorf("Cannot cluster %d rows into %d clusters", n, km.k)
+	}
+	km.init(x64, x.Dtype())
+	data := x64.Data().([]float64)
+
+	for iter := 0; iter < km.cfg.maxIter; iter++ {
+		prev := append([]float64(nil), km.centroids...)
+		if km.cfg.batchSize > 0 && km.cfg.batchSize < n {
+			if err = km.update(km.batch(data, n)); err != nil {
+				return err
+			}
+		} else if err = km.lloyd(x64); err != nil {
+			return err
+		}
+		if sqDist(prev, km.centroids) < km.cfg.tol {
+			break
+		}
+	}
+	return nil
+}
+
+// PartialFit updates the centroids with a mini-batch of rows. The first mini-batch initializes the centroids, and must
+// have at least k rows.
+func (km *KMeans) PartialFit(x *tensor.Dense) error {
+	x64, err := float64s(x)
+	if err != nil {
+		return err
+	}
+	if km.centroids == nil {
+		if x64.Shape()[0] < km.k {
+			return errors.Errorf("Cannot initialize %d clusters from %d rows", km.k, x64.Shape()[0])
+		}
+		km.init(x64, x.Dtype())
+	}
+	return km.update(x64)
+}
+
+// Predict returns the Int vector of the indices of the nearest centroid of every row of x.
+func (km *KMeans) Predict(x *tensor.Dense) (*tensor.Dense, error) {
+	labels, _, err := km.assign(x)
+	if err != nil {
+		return nil, err
+	}
+	return tensor.New(tensor.WithShape(len(labels)), tensor.WithBacking(labels)), nil
+}
+
+// Transform returns the (n, k) matrix of the squared distances between the rows of x and the centroids.
+func (km *KMeans) Transform(x *tensor.Dense) (*tensor.Dense, error) {
+	x64, err := km.check(x)
+	if err != nil {
+		return nil, err
+	}
+	dist, err := km.distances(x64)
+	if err != nil {
+		return nil, err
+	}
+	return newDense(km.dt, tensor.Shape{x64.Shape()[0], km.k}, dist), nil
+}
+
+// Inertia returns the sum of the squared distances between the rows of x and their nearest centroid.
+func (km *KMeans) Inertia(x *tensor.Dense) (float64, error) {
+	_, dist, err := km.assign(x)
+	if err != nil {
+		return 0, err
+	}
+	var sum float64
+	for _, d := range dist {
+		sum += d
+	}
+	return sum, nil
+}
+
+func (km *KMeans) check(x *tensor.Dense) (*tensor.Dense, error) {
+	if km.centroids == nil {
+		return nil, errors.New("The k-means clustering has not been fitted")
+	}
+	x64, err := float64s(x)
+	if err != nil {
+		return nil, err
+	}
+	if x64.Shape()[1] != km.d {
+		return nil, errors.Errorf("Expected rows of %d features. Got %d instead", km.d, x64.Shape()[1])
+	}
+	return x64, nil
+}
+
+// assign returns the nearest centroid of every row, and the squared distance to it.
+func (km *KMeans) assign(x *tensor.Dense) (labels []int, dist []float64, err error) {
+	x64, err := km.check(x)
+	if err != nil {
+		return nil, nil, err
+	}
+	return km.nearest(x64)
+}
+
+func (km *KMeans) nearest(x64 *tensor.Dense) (labels []int, dist []float64, err error) {
+	all, err := km.distances(x64)
+	if err != nil {
+		return nil, nil, err
+	}
+	n := x64.Shape()[0]
+	labels, dist = make([]int, n), make([]float64, n)
+	for i := 0; i < n; i++ {
+		row := all[i*km.k : (i+1)*km.k]
+		best := 0
+		for c, d := range row {
+			if d < row[best] {
+				best = c
+			}
+		}
+		labels[i], dist[i] = best, row[best]
+	}
+	return labels, dist, nil
+}
+
+// distances returns the squared distances between the rows and the centroids, as |x|² - 2x·c + |c|².
+func (km *KMeans) distances(x64 *tensor.Dense) ([]float64, error) {
+	c := tensor.New(tensor.WithShape(km.k, km.d), tensor.WithBacking(km.centroids))
+	xc, err := matMulT(x64, c)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to compute the distances to the centroids")
+	}
+	data, prods := x64.Data().([]float64), xc.Data().([]float64)
+	cNorms := rowNorms(km.centroids, km.d)
+	n := x64.Shape()[0]
+	retVal := make([]float64, n*km.k)
+	for i := 0; i < n; i++ {
+		xNorm := dotRows(data[i*km.d:(i+1)*km.d], data[i*km.d:(i+1)*km.d])
+		for j := 0; j < km.k; j++ {
+			// rounding may make the distance slightly negative
+			retVal[i*km.k+j] = math.Max(0, xNorm-2*prods[i*km.k+j]+cNorms[j])
+		}
+	}
+	return retVal, nil
+}
+
+// init picks the initial centroids among the rows with k-means++: every centroid is drawn with a probability
+// proportional to its squared distance to the nearest centroid drawn so far.
+func (km *KMeans) init(x64 *tensor.Dense, dt tensor.Dtype) {
+	data := x64.Data().([]float64)
+	n, d := x64.Shape()[0], x64.Shape()[1]
+	km.d, km.dt = d, dt
+	km.centroids = make([]float64, 0, km.k*d)
+	km.counts = make([]float64, km.k)
+
+	first := km.cfg.rand.Intn(n)
+	km.centroids = append(km.centroids, data[first*d:(first+1)*d]...)
+	nearest := make([]float64, n)
+	for i := range nearest {
+		nearest[i] = sqDist(data[i*d:(i+1)*d], km.centroids)
+	}
+	for c := 1; c < km.k; c++ {
+		var total float64
+		for _, dist := range nearest {
+			total += dist
+		}
+		pick := km.cfg.rand.Intn(n)
+		if total > 0 {
+			r := km.cfg.rand.Float64() * total
+			for i, dist := range nearest {
+				if r -= dist; r < 0 {
+					pick = i
+					break
+				}
+			}
+		}
+		row := data[pick*d : (pick+1)*d]
+		km.centroids = append(km.centroids, row...)
+		for i, dist := range nearest {
+			nearest[i] = math.Min(dist, sqDist(data[i*d:(i+1)*d], row))
+		}
+	}
+}
+
+// lloyd moves every centroid to the mean of the rows assigned to it. Centroids without rows do not move.
+func (km *KMeans) lloyd(x64 *tensor.Dense) error {
+	labels, _, err := km.nearest(x64)
+	if err != nil {
+		return err
+	}
+	data := x64.Data().([]float64)
+	sums := make([]float64, km.k*km.d)
+	counts := make([]float64, km.k)
+	for i, c := range labels {
+		counts[c]++
+		for j := 0; j < km.d; j++ {
+			sums[c*km.d+j] += data[i*km.d+j]
+		}
+	}
+	for c, count := range counts {
+		if count == 0 {
+			continue
+		}
+		for j := 0; j < km.d; j++ {
+			km.centroids[c*km.d+j] = sums[c*km.d+j] / count
+		}
+	}
+	return nil
+}
+
+// update moves the centroids towards the rows of a mini-batch assigned to them, with a learning rate of 1 over the
+// number of rows assigned to the centroid so far.
+func (km *KMeans) update(batch *tensor.Dense) error {
+	labels, _, err := km.nearest(batch)
+	if err != nil {
+		return err
+	}
+	data := batch.Data().([]float64)
+	for i, c := range labels {
+		km.counts[c]++
+		lr := 1 / km.counts[c]
+		for j := 0; j < km.d; j++ {
+			km.centroids[c*km.d+j] += lr * (data[i*km.d+j] - km.centroids[c*km.d+j])
+		}
+	}
+	return nil
+}
+
+// batch samples a mini-batch of rows.
+func (km *KMeans) batch(data []float64, n int) *tensor.Dense {
+	rows := make([]float64, 0, km.cfg.batchSize*km.d)
+	for i := 0; i < km.cfg.batchSize; i++ {
+		r := km.cfg.rand.Intn(n)
+		rows = append(rows, data[r*km.d:(r+1)*km.d]...)
+	}
+	return tensor.New(tensor.WithShape(km.cfg.batchSize, km.d), tensor.WithBacking(rows))
+}
+
+// sqDist returns the squared distance between a and the first len(a) values of b.
+func sqDist(a, b []float64) (retVal float64) {
+	for i, v := range a {
+		diff := v - b[i]
+		retVal += diff * diff
+	}
+	return
+}
+
+func dotRows(a, b []float64) (retVal float64) {
+	for i, v := range a {
+		retVal += v * b[i]
+	}
+	return
+}
+
+// rowNorms returns the squared norms of the rows of a (n, d) matrix.
+func rowNorms(data []float64, d int) []float64 {
+	retVal := make([]float64, len(data)/d)
+	for i := range retVal {
+		row := data[i*d : (i+1)*d]
+		retVal[i] = dotRows(row, row)
+	}
+	return retVal
+}
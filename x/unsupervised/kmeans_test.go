@@ -0,0 +1,130 @@
+package unsupervised
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorgonia.org/tensor"
+)
+
+// blobs returns n rows around each of the centers, and their labels.
+func blobs(r *rand.Rand, n int, centers [][]float64, spread float64) (*tensor.Dense, []int) {
+	d := len(centers[0])
+	var data []float64
+	var labels []int
+	for c, center := range centers {
+		for i := 0; i < n; i++ {
+			for _, v := range center {
+				data = append(data, v+spread*r.NormFloat64())
+			}
+			labels = append(labels, c)
+		}
+	}
+	return tensor.New(tensor.WithShape(len(labels), d), tensor.WithBacking(data)), labels
+}
+
+func TestKMeans(t *testing.T) {
+	assert := assert.New(t)
+	centers := [][]float64{{0, 0}, {10, 10}, {-10, 10}}
+	x, labels := blobs(rand.New(rand.NewSource(1)), 50, centers, 0.5)
+
+	for _, batch := range []int{0, 32} {
+		km, err := NewKMeans(3, WithSeed(2), WithBatchSize(batch))
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Nil(km.Centroids())
+		if err := km.Fit(x); err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(tensor.Shape{3, 2}, km.Centroids().Shape())
+
+		pred, err := km.Predict(x)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(tensor.Int, pred.Dtype())
+		// the clusters match the blobs, up to a permutation
+		got := pred.Data().([]int)
+		mapping := make(map[int]int)
+		for i, l := range labels {
+			if m, ok := mapping[l]; ok {
+				assert.Equal(m, got[i], "batch size %d, row %d", batch, i)
+				continue
+			}
+			mapping[l] = got[i]
+		}
+		assert.Len(mapping, 3)
+
+		inertia, err := km.Inertia(x)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.True(inertia < 150*0.5*0.5*2*1.5, "inertia %v", inertia)
+
+		dist, err := km.Transform(x)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(tensor.Shape{150, 3}, dist.Shape())
+	}
+}
+
+func TestKMeans_PartialFit(t *testing.T) {
+	assert := assert.New(t)
+	r := rand.New(rand.NewSource(3))
+	centers := [][]float64{{0, 0, 0}, {5, 5, 5}}
+	km, err := NewKMeans(2, WithSeed(4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 20; i++ {
+		batch, _ := blobs(r, 10, centers, 0.3)
+		if err := km.PartialFit(batch); err != nil {
+			t.Fatal(err)
+		}
+	}
+	c := km.Centroids().Data().([]float64)
+	if c[0] > c[3] {
+		c = append(c[3:], c[:3]...)
+	}
+	assert.InDeltaSlice([]float64{0, 0, 0, 5, 5, 5}, c, 0.2)
+
+	// float32 data gives float32 centroids
+	x32 := tensor.New(tensor.WithShape(4, 1), tensor.WithBacking([]float32{0, 0.1, 9.9, 10}))
+	km, _ = NewKMeans(2, WithSeed(5))
+	if err := km.Fit(x32); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(tensor.Float32, km.Centroids().Dtype())
+	pred, _ := km.Predict(x32)
+	got := pred.Data().([]int)
+	assert.Equal(got[0], got[1])
+	assert.Equal(got[2], got[3])
+	assert.NotEqual(got[0], got[2])
+}
+
+func TestKMeans_errors(t *testing.T) {
+	assert := assert.New(t)
+	_, err := NewKMeans(0)
+	assert.Error(err)
+	_, err = NewKMeans(2, WithMaxIter(0))
+	assert.Error(err)
+
+	km, _ := NewKMeans(3)
+	x := tensor.New(tensor.WithShape(2, 2), tensor.WithBacking([]float64{0, 1, 2, 3}))
+	assert.Error(km.Fit(x), "fewer rows than clusters")
+	assert.Error(km.PartialFit(x))
+	_, err = km.Predict(x)
+	assert.Error(err, "not fitted")
+
+	km, _ = NewKMeans(1)
+	assert.Error(km.Fit(tensor.New(tensor.WithShape(2), tensor.WithBacking([]float64{0, 1}))))
+	assert.Error(km.Fit(tensor.New(tensor.WithShape(2, 1), tensor.WithBacking([]int{0, 1}))))
+	if err := km.Fit(x); err != nil {
+		t.Fatal(err)
+	}
+	_, err = km.Transform(tensor.New(tensor.WithShape(1, 3), tensor.WithBacking([]float64{0, 1, 2})))
+	assert.Error(err)
+}
@@ -0,0 +1,61 @@
+package unsupervised
+
+import (
+	"math/rand"
+	"time"
+)
+
+type config struct {
+	batchSize  int
+	maxIter    int
+	tol        float64
+	oversample int
+	powerIters int
+	rand       *rand.Rand
+}
+
+func newConfig(opts []Opt) config {
+	c := config{maxIter: 100, tol: 1e-4, oversample: 10, powerIters: 2}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	if c.rand == nil {
+		c.rand = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	return c
+}
+
+// Opt is an option of KMeans or PCA.
+type Opt func(*config)
+
+// WithSeed seeds the source of randomness. By default, it is seeded with the current time.
+func WithSeed(seed int64) Opt {
+	return func(c *config) { c.rand = rand.New(rand.NewSource(seed)) }
+}
+
+// WithBatchSize makes KMeans.Fit use mini-batches of n rows. By default, or with 0, every iteration uses all the rows.
+func WithBatchSize(n int) Opt {
+	return func(c *config) { c.batchSize = n }
+}
+
+// WithMaxIter sets the maximum number of iterations (or mini-batches) of KMeans.Fit. The default is 100.
+func WithMaxIter(n int) Opt {
+	return func(c *config) { c.maxIter = n }
+}
+
+// WithTolerance stops KMeans.Fit when the centroids move by less than tol, in squared distance. The default is 1e-4.
+func WithTolerance(tol float64) Opt {
+	return func(c *config) { c.tol = tol }
+}
+
+// WithOversampling sets the number of extra random directions that the randomized PCA samples beyond the number of
+// components. The default is 10.
+func WithOversampling(n int) Opt {
+	return func(c *config) { c.oversample = n }
+}
+
+// WithPowerIterations sets the number of power iterations of the randomized PCA, which improve the accuracy when the
+// spectrum of the data decays slowly. The default is 2.
+func WithPowerIterations(n int) Opt {
+	return func(c *config) { c.powerIters = n }
+}
@@ -0,0 +1,261 @@
+package unsupervised
+
+import (
+	"math"
+
+	"github.com/pkg/errors"
+	"gorgonia.org/tensor"
+)
+
+// PCA is a principal component analysis: a projection of the rows of a matrix onto the k directions of largest variance.
+//
+// The components are computed with the randomized algorithm of Halko, Martinsson and Tropp (2011): the centered data is
+// multiplied by a random matrix of k + oversampling columns, whose range is refined by power iterations, and the SVD of
+// the data projected onto that range gives the components. This costs O(n·d·k) rather than the O(n·d·min(n, d)) of a
+// full SVD.
+type PCA struct {
+	k   int
+	cfg config
+
+	mean       []float64
+	components *tensor.Dense // (k, d) Float64
+	variance   []float64
+	total      float64
+	dt         tensor.Dtype
+}
+
+// NewPCA creates a principal component analysis of k components.
+func NewPCA(k int, opts ...Opt) (*PCA, error) {
+	if k < 1 {
+		return nil, errors.Errorf("Expected at least one component. Got %d instead", k)
+	}
+	cfg := newConfig(opts)
+	if cfg.oversample < 0 || cfg.powerIters < 0 {
+		return nil, errors.Errorf("Expected a non-negative oversampling and number of power iterations. Got %d and %d instead", cfg.oversample, cfg.powerIters)
+	}
+	return &PCA{k: k, cfg: cfg}, nil
+}
+
+// Fit computes the components of the rows of x.
+func (p *PCA) Fit(x *tensor.Dense) error {
+	x64, err := float64s(x)
+	if err != nil {
+		return err
+	}
+	n, d := x64.Shape()[0], x64.Shape()[1]
+	if p.k > n || p.k > d {
+		return errors.Errorf("Cannot compute %d components of a %v matrix", p.k, x64.Shape())
+	}
+
+	// center the data
+	data := x64.Data().([]float64)
+	mean := make([]float64, d)
+	for i := 0; i < n; i++ {
+		for j := 0; j < d; j++ {
+			mean[j] += data[i*d+j]
+		}
+	}
+	var total float64
+	for j := range mean {
+		mean[j] /= float64(n)
+	}
+	for i := 0; i < n; i++ {
+		for j := 0; j < d; j++ {
+			data[i*d+j] -= mean[j]
+			total += data[i*d+j] * data[i*d+j]
+		}
+	}
+
+	l := p.k + p.cfg.oversample
+	if l > n {
+		l = n
+	}
+	if l > d {
+		l = d
+	}
+	omega := make([]float64, d*l)
+	for i := range omega {
+		omega[i] = p.cfg.rand.NormFloat64()
+	}
+	y, err := x64.MatMul(tensor.New(tensor.WithShape(d, l), tensor.WithBacking(omega)))
+	if err != nil {
+		return errors.Wrap(err, "Failed to sample the range of the data")
+	}
+	xT, err := transpose(x64)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < p.cfg.powerIters; i++ {
+		// y = x·xᵀ·y, orthonormalized at every step to keep the directions apart
+		if y, err = orthonormalize(y); err != nil {
+			return err
+		}
+		z, err := xT.MatMul(y)
+		if err != nil {
+			return errors.Wrap(err, "Failed to run a power iteration")
+		}
+		if z, err = orthonormalize(z); err != nil {
+			return err
+		}
+		if y, err = x64.MatMul(z); err != nil {
+			return errors.Wrap(err, "Failed to run a power iteration")
+		}
+	}
+	q, err := orthonormalize(y)
+	if err != nil {
+		return err
+	}
+
+	// b = qᵀ·x is small: (l, d)
+	qT, err := transpose(q)
+	if err != nil {
+		return err
+	}
+	b, err := qT.MatMul(x64)
+	if err != nil {
+		return errors.Wrap(err, "Failed to project the data")
+	}
+	sData, _, v, err := svd(b)
+	if err != nil {
+		return errors.Wrap(err, "Failed to decompose the projected data")
+	}
+
+	// the components are the first k right singular vectors, with the sign that makes their largest coordinate positive
+	vData := tensor.Materialize(v).Data().([]float64) // (d, l)
+	cols := v.Shape()[1]
+	components := make([]float64, p.k*d)
+	for c := 0; c < p.k; c++ {
+		var largest float64
+		for j := 0; j < d; j++ {
+			if a := vData[j*cols+c]; math.Abs(a) > math.Abs(largest) {
+				largest = a
+			}
+		}
+		sign := 1.0
+		if largest < 0 {
+			sign = -1
+		}
+		for j := 0; j < d; j++ {
+			components[c*d+j] = sign * vData[j*cols+c]
+		}
+	}
+	variance := make([]float64, p.k)
+	dof := math.Max(float64(n-1), 1)
+	for i := range variance {
+		variance[i] = sData[i] * sData[i] / dof
+	}
+
+	p.mean, p.variance, p.total, p.dt = mean, variance, total/dof, x.Dtype()
+	p.components = tensor.New(tensor.WithShape(p.k, d), tensor.WithBacking(components))
+	return nil
+}
+
+// Components returns the (k, d) matrix of the components, by decreasing variance, or nil before the fit.
+func (p *PCA) Components() *tensor.Dense {
+	if p.components == nil {
+		return nil
+	}
+	return newDense(p.dt, p.components.Shape(), p.components.Data().([]float64))
+}
+
+// Mean returns the mean of the rows the PCA was fitted on.
+func (p *PCA) Mean() []float64 { return p.mean }
+
+// ExplainedVariance returns the variance of the data along every component.
+func (p *PCA) ExplainedVariance() []float64 { return p.variance }
+
+// ExplainedVarianceRatio returns the fraction of the total variance of the data along every component.
+func (p *PCA) ExplainedVarianceRatio() []float64 {
+	retVal := make([]float64, len(p.variance))
+	for i, v := range p.variance {
+		if p.total > 0 {
+			retVal[i] = v / p.total
+		}
+	}
+	return retVal
+}
+
+// Transform projects the rows of x onto the components, returning a (n, k) matrix.
+func (p *PCA) Transform(x *tensor.Dense) (*tensor.Dense, error) {
+	if p.components == nil {
+		return nil, errors.New("The PCA has not been fitted")
+	}
+	x64, err := float64s(x)
+	if err != nil {
+		return nil, err
+	}
+	d := len(p.mean)
+	if x64.Shape()[1] != d {
+		return nil, errors.Errorf("Expected rows of %d features. Got %d instead", d, x64.Shape()[1])
+	}
+	data := x64.Data().([]float64)
+	for i := range data {
+		data[i] -= p.mean[i%d]
+	}
+	z, err := matMulT(x64, p.components)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to project the data")
+	}
+	return newDense(p.dt, z.Shape(), z.Data().([]float64)), nil
+}
+
+// InverseTransform maps (n, k) projections back to the original space.
+func (p *PCA) InverseTransform(z *tensor.Dense) (*tensor.Dense, error) {
+	if p.components == nil {
+		return nil, errors.New("The PCA has not been fitted")
+	}
+	z64, err := float64s(z)
+	if err != nil {
+		return nil, err
+	}
+	if z64.Shape()[1] != p.k {
+		return nil, errors.Errorf("Expected rows of %d components. Got %d instead", p.k, z64.Shape()[1])
+	}
+	x, err := z64.MatMul(p.components)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to reconstruct the data")
+	}
+	data := x.Data().([]float64)
+	d := len(p.mean)
+	for i := range data {
+		data[i] += p.mean[i%d]
+	}
+	return newDense(p.dt, x.Shape(), data), nil
+}
+
+// orthonormalize returns an orthonormal basis of the range of the columns of a, from its SVD.
+func orthonormalize(a *tensor.Dense) (*tensor.Dense, error) {
+	_, u, _, err := svd(a)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to orthonormalize")
+	}
+	return u, nil
+}
+
+// svd returns the thin SVD of a Float64 matrix. *tensor.Dense.SVD does not handle the matrices of a single row or
+// column, whose SVD is computed here.
+func svd(a *tensor.Dense) (s []float64, u, v *tensor.Dense, err error) {
+	m, n := a.Shape()[0], a.Shape()[1]
+	if m > 1 && n > 1 {
+		var sT *tensor.Dense
+		if sT, u, v, err = a.SVD(true, false); err != nil {
+			return nil, nil, nil, err
+		}
+		u = tensor.New(tensor.WithShape(u.Shape().Clone()...), tensor.WithBacking(tensor.Materialize(u).Data()))
+		v = tensor.New(tensor.WithShape(v.Shape().Clone()...), tensor.WithBacking(tensor.Materialize(v).Data()))
+		return sT.Data().([]float64), u, v, nil
+	}
+	data := append([]float64(nil), tensor.Materialize(a).Data().([]float64)...)
+	norm := math.Sqrt(dotRows(data, data))
+	if norm > 0 {
+		for i := range data {
+			data[i] /= norm
+		}
+	}
+	vec := tensor.New(tensor.WithShape(len(data), 1), tensor.WithBacking(data))
+	one := tensor.New(tensor.WithShape(1, 1), tensor.WithBacking([]float64{1}))
+	if n == 1 {
+		return []float64{norm}, vec, one, nil
+	}
+	return []float64{norm}, one, vec, nil
+}
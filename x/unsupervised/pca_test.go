@@ -0,0 +1,111 @@
+package unsupervised
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorgonia.org/tensor"
+)
+
+func TestPCA(t *testing.T) {
+	assert := assert.New(t)
+	// rows along the direction (1, 2, 2)/3 with a large variance, and (2, -2, 1)/3 with a small one
+	r := rand.New(rand.NewSource(1))
+	dirs := [][]float64{{1.0 / 3, 2.0 / 3, 2.0 / 3}, {2.0 / 3, -2.0 / 3, 1.0 / 3}}
+	n := 500
+	data := make([]float64, 0, n*3)
+	for i := 0; i < n; i++ {
+		a, b := 10*r.NormFloat64(), r.NormFloat64()
+		for j := 0; j < 3; j++ {
+			data = append(data, 1+a*dirs[0][j]+b*dirs[1][j])
+		}
+	}
+	x := tensor.New(tensor.WithShape(n, 3), tensor.WithBacking(data))
+
+	p, err := NewPCA(2, WithSeed(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Nil(p.Components())
+	if err := p.Fit(x); err != nil {
+		t.Fatal(err)
+	}
+	c := p.Components()
+	assert.Equal(tensor.Shape{2, 3}, c.Shape())
+	assert.InDeltaSlice([]float64{1.0 / 3, 2.0 / 3, 2.0 / 3}, c.Data().([]float64)[:3], 1e-2)
+	second := c.Data().([]float64)[3:]
+	assert.InDelta(1, math.Abs(dirs[1][0]*second[0]+dirs[1][1]*second[1]+dirs[1][2]*second[2]), 1e-3)
+	assert.InDeltaSlice([]float64{1, 1, 1}, p.Mean(), 1)
+
+	v := p.ExplainedVariance()
+	assert.InDelta(100, v[0], 15)
+	assert.InDelta(1, v[1], 0.2)
+	ratio := p.ExplainedVarianceRatio()
+	assert.True(ratio[0] > 0.98)
+	assert.InDelta(1, ratio[0]+ratio[1], 1e-9, "the data lies in the plane of the two components")
+
+	// the two components span the data, so the reconstruction is exact
+	z, err := p.Transform(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(tensor.Shape{n, 2}, z.Shape())
+	back, err := p.InverseTransform(z)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.InDeltaSlice(data, back.Data(), 1e-8)
+
+	// the projections are uncorrelated
+	zData := z.Data().([]float64)
+	var cov float64
+	for i := 0; i < n; i++ {
+		cov += zData[2*i] * zData[2*i+1]
+	}
+	assert.InDelta(0, cov/float64(n), 1e-8)
+}
+
+func TestPCA_float32(t *testing.T) {
+	assert := assert.New(t)
+	x := tensor.New(tensor.WithShape(4, 2), tensor.WithBacking([]float32{-2, -2, -1, -1, 1, 1, 2, 2}))
+	p, _ := NewPCA(1, WithSeed(3), WithOversampling(0), WithPowerIterations(0))
+	if err := p.Fit(x); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(tensor.Float32, p.Components().Dtype())
+	c := p.Components().Data().([]float32)
+	assert.InDelta(math.Sqrt2/2, c[0], 1e-6)
+	assert.InDelta(math.Sqrt2/2, c[1], 1e-6)
+	z, err := p.Transform(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.InDeltaSlice([]float32{float32(-2 * math.Sqrt2), float32(-math.Sqrt2), float32(math.Sqrt2), float32(2 * math.Sqrt2)}, z.Data(), 1e-5)
+}
+
+func TestPCA_errors(t *testing.T) {
+	assert := assert.New(t)
+	_, err := NewPCA(0)
+	assert.Error(err)
+	_, err = NewPCA(1, WithOversampling(-1))
+	assert.Error(err)
+
+	p, _ := NewPCA(3)
+	x := tensor.New(tensor.WithShape(4, 2), tensor.WithBacking([]float64{0, 1, 2, 3, 4, 5, 6, 7}))
+	assert.Error(p.Fit(x), "more components than features")
+	_, err = p.Transform(x)
+	assert.Error(err, "not fitted")
+	_, err = p.InverseTransform(x)
+	assert.Error(err)
+
+	p, _ = NewPCA(1)
+	if err := p.Fit(x); err != nil {
+		t.Fatal(err)
+	}
+	_, err = p.Transform(tensor.New(tensor.WithShape(1, 3), tensor.WithBacking([]float64{0, 1, 2})))
+	assert.Error(err)
+	_, err = p.InverseTransform(x)
+	assert.Error(err)
+}
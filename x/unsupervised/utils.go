@@ -0,0 +1,69 @@
+package unsupervised
+
+import (
+	"github.com/pkg/errors"
+	"gorgonia.org/tensor"
+)
+
+// float64s returns a Float64 copy of a matrix.
+func float64s(x *tensor.Dense) (*tensor.Dense, error) {
+	if x == nil || x.Dims() != 2 {
+		return nil, errors.Errorf("Expected a matrix. Got %v instead", shapeOf(x))
+	}
+	if x.Shape()[0] == 0 || x.Shape()[1] == 0 {
+		return nil, errors.Errorf("Expected a non-empty matrix. Got %v instead", x.Shape())
+	}
+	var data []float64
+	switch d := tensor.Materialize(x).Data().(type) {
+	case []float64:
+		data = append([]float64(nil), d...)
+	case []float32:
+		data = make([]float64, len(d))
+		for i, v := range d {
+			data[i] = float64(v)
+		}
+	default:
+		return nil, errors.Errorf("Expected a Float64 or Float32 matrix. Got %v instead", x.Dtype())
+	}
+	return tensor.New(tensor.WithShape(x.Shape().Clone()...), tensor.WithBacking(data)), nil
+}
+
+// newDense returns a tensor of the dtype and shape, holding the data.
+func newDense(dt tensor.Dtype, shape tensor.Shape, data []float64) *tensor.Dense {
+	if dt == tensor.Float32 {
+		f32s := make([]float32, len(data))
+		for i, f := range data {
+			f32s[i] = float32(f)
+		}
+		return tensor.New(tensor.WithShape(shape.Clone()...), tensor.WithBacking(f32s))
+	}
+	return tensor.New(tensor.WithShape(shape.Clone()...), tensor.WithBacking(data))
+}
+
+// transpose returns the transpose of a matrix, with its data moved.
+func transpose(x *tensor.Dense) (*tensor.Dense, error) {
+	t := x.Clone().(*tensor.Dense)
+	if err := t.T(); err != nil {
+		return nil, err
+	}
+	if err := t.Transpose(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// matMulT returns a·bᵀ.
+func matMulT(a, b *tensor.Dense) (*tensor.Dense, error) {
+	bT, err := transpose(b)
+	if err != nil {
+		return nil, err
+	}
+	return a.MatMul(bT)
+}
+
+func shapeOf(x *tensor.Dense) tensor.Shape {
+	if x == nil {
+		return nil
+	}
+	return x.Shape()
+}